@@ -9,16 +9,28 @@ import (
 	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/adapters/auth"
+	"github.com/careplus/pharmacy-backend/internal/adapters/email"
 	"github.com/careplus/pharmacy-backend/internal/adapters/http"
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/handlers"
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/middleware"
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/ws"
+	"github.com/careplus/pharmacy-backend/internal/adapters/llm"
 	"github.com/careplus/pharmacy-backend/internal/adapters/persistence"
+	"github.com/careplus/pharmacy-backend/internal/adapters/push"
+	"github.com/careplus/pharmacy-backend/internal/adapters/queue"
+	"github.com/careplus/pharmacy-backend/internal/adapters/ratelimit"
+	"github.com/careplus/pharmacy-backend/internal/adapters/sms"
 	"github.com/careplus/pharmacy-backend/internal/adapters/storage"
+	"github.com/careplus/pharmacy-backend/internal/adapters/ticketing"
+	"github.com/careplus/pharmacy-backend/internal/adapters/webhook"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/domain/services"
 	"github.com/careplus/pharmacy-backend/internal/infrastructure/config"
 	"github.com/careplus/pharmacy-backend/internal/infrastructure/database"
 	"github.com/careplus/pharmacy-backend/internal/infrastructure/logger"
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/scheduler"
 	"github.com/careplus/pharmacy-backend/internal/infrastructure/seed"
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/telemetry"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 	"go.uber.org/zap"
@@ -36,11 +48,27 @@ func main() {
 		log.Fatalf("Failed to create logger: %v", err)
 	}
 
+	shutdownTracing, err := telemetry.SetupTracing(context.Background(), cfg)
+	if err != nil {
+		zapLogger.Fatal("Failed to set up tracing", zap.Error(err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			zapLogger.Warn("Tracing shutdown failed", zap.Error(err))
+		}
+	}()
+
 	db, dbCleanup, err := database.NewPostgresConnection(cfg, zapLogger)
 	if err != nil {
 		zapLogger.Fatal("Failed to connect to database", zap.Error(err))
 	}
 	defer dbCleanup()
+	// Signed-in requests only see their own pharmacy's rows (outbound.WithTenant, set by the auth middleware).
+	if err := db.Use(persistence.TenantScope{}); err != nil {
+		zapLogger.Fatal("Failed to enable tenant scoping", zap.Error(err))
+	}
 
 	// Ensure demo users exist for quick login (idempotent)
 	ctx := context.Background()
@@ -53,6 +81,9 @@ func main() {
 
 	pharmacyRepo := persistence.NewPharmacyRepository(db)
 	configRepo := persistence.NewPharmacyConfigRepository(db)
+	configVersionRepo := persistence.NewPharmacyConfigVersionRepository(db)
+	subscriptionRepo := persistence.NewSubscriptionRepository(db)
+	roleRepo := persistence.NewRoleRepository(db)
 	userRepo := persistence.NewUserRepository(db)
 	productRepo := persistence.NewProductRepository(db)
 	productImageRepo := persistence.NewProductImageRepository(db)
@@ -65,10 +96,12 @@ func main() {
 	orderRepo := persistence.NewOrderRepository(db)
 	orderFeedbackRepo := persistence.NewOrderFeedbackRepository(db)
 	orderReturnRequestRepo := persistence.NewOrderReturnRequestRepository(db)
+	productReturnFlagRepo := persistence.NewProductReturnFlagRepository(db)
 	paymentRepo := persistence.NewPaymentRepository(db)
 	paymentGatewayRepo := persistence.NewPaymentGatewayRepository(db)
 	invoiceRepo := persistence.NewInvoiceRepository(db)
 	inventoryBatchRepo := persistence.NewInventoryBatchRepository(db)
+	inventoryAlertRepo := persistence.NewInventoryAlertRepository(db)
 	promoCodeRepo := persistence.NewPromoCodeRepository(db)
 	pointsTransactionRepo := persistence.NewPointsTransactionRepository(db)
 	referralPointsConfigRepo := persistence.NewReferralPointsConfigRepository(db)
@@ -78,7 +111,13 @@ func main() {
 	activityLogRepo := persistence.NewActivityLogRepository(db)
 	notificationRepo := persistence.NewNotificationRepository(db)
 	promoRepo := persistence.NewPromoRepository(db)
+	promoStatRepo := persistence.NewPromoStatRepository(db)
+	cartRepo := persistence.NewCartRepository(db)
+	deliveryRepo := persistence.NewDeliveryRepository(db)
 	dutyRosterRepo := persistence.NewDutyRosterRepository(db)
+	shiftSwapRepo := persistence.NewShiftSwapRepository(db)
+	branchRepo := persistence.NewBranchRepository(db)
+	stockTransferRepo := persistence.NewStockTransferRepository(db)
 	dailyLogRepo := persistence.NewDailyLogRepository(db)
 	conversationRepo := persistence.NewConversationRepository(db)
 	chatMessageRepo := persistence.NewChatMessageRepository(db)
@@ -91,38 +130,212 @@ func main() {
 	blogPostLikeRepo := persistence.NewBlogPostLikeRepository(db)
 	blogPostCommentRepo := persistence.NewBlogPostCommentRepository(db)
 	blogPostViewRepo := persistence.NewBlogPostViewRepository(db)
+	aiGenerationRepo := persistence.NewAIGenerationRepository(db)
+	reportRepo := persistence.NewReportRepository(db)
+	flashSaleRepo := persistence.NewFlashSaleRepository(db)
+	preorderRepo := persistence.NewPreorderRepository(db)
+	supplierRepo := persistence.NewSupplierRepository(db)
+	purchaseOrderRepo := persistence.NewPurchaseOrderRepository(db)
+	trainingRepo := persistence.NewTrainingRepository(db)
+	otpRepo := persistence.NewOTPRepository(db)
+	passwordResetTokenRepo := persistence.NewPasswordResetTokenRepository(db)
+	loginChallengeRepo := persistence.NewLoginChallengeRepository(db)
+	deviceTokenRepo := persistence.NewDeviceTokenRepository(db)
+	deliveryJobRepo := persistence.NewDeliveryJobRepository(db)
+	integrityRepo := persistence.NewIntegrityRepository(db)
+	giftCardRepo := persistence.NewGiftCardRepository(db)
+	storeCreditRepo := persistence.NewStoreCreditRepository(db)
+
+	// Outbound email: SMTP or log-only (EMAIL_PROVIDER)
+	var emailTransport outbound.EmailSender = email.NewLogSender(zapLogger)
+	if cfg.Email.Provider == "smtp" {
+		emailTransport = email.NewSMTPSender(cfg.Email)
+	}
+	// Outbound SMS: Twilio, Sparrow SMS or log-only (SMS_PROVIDER); "none" disables texts entirely
+	var smsTransport outbound.SMSSender
+	switch cfg.SMS.Provider {
+	case "log":
+		smsTransport = sms.NewLogSender(zapLogger)
+	case "twilio":
+		smsTransport = sms.NewTwilioSender(cfg.SMS)
+	case "sparrow":
+		smsTransport = sms.NewSparrowSender(cfg.SMS)
+	}
+	// Outgoing webhooks need their own WEBHOOK_SIGNING_SECRET; without it webhook jobs become dead letters
+	var webhookTransport outbound.WebhookSender
+	if cfg.Webhook.SigningSecret != "" {
+		webhookTransport = webhook.NewHTTPSender(cfg.Webhook)
+	} else {
+		zapLogger.Info("WEBHOOK_SIGNING_SECRET not set; outgoing webhooks are disabled")
+	}
+
+	// Email and SMS go through the durable delivery queue (DELIVERY_QUEUE=database) or, with
+	// DELIVERY_QUEUE=memory, through in-process queues that lose pending messages on exit. The queue also
+	// delivers webhook jobs stored with EnqueueWebhook, signed with WEBHOOK_SIGNING_SECRET.
+	deliveryQueue := services.NewDeliveryQueueService(deliveryJobRepo, emailTransport, smsTransport, webhookTransport, services.DeliveryQueueOptions{
+		Workers:      cfg.Queue.Workers,
+		PollInterval: cfg.Queue.PollInterval,
+		MaxAttempts:  cfg.Queue.MaxAttempts,
+		BackoffBase:  cfg.Queue.BackoffBase,
+		BackoffMax:   cfg.Queue.BackoffMax,
+		Lease:        cfg.Queue.Lease,
+	}, zapLogger)
+	var emailQueue *email.AsyncSender
+	var smsQueue *sms.AsyncSender
+	var emailSender outbound.EmailSender
+	var smsSender outbound.SMSSender
+	if cfg.Queue.Mode == "database" {
+		emailSender = queue.NewEmailSender(deliveryQueue)
+		if smsTransport != nil {
+			smsSender = queue.NewSMSSender(deliveryQueue)
+		}
+		deliveryQueue.Start()
+	} else {
+		emailQueue = email.NewAsyncSender(emailTransport, cfg.Email.Workers, cfg.Email.QueueSize, zapLogger)
+		emailSender = emailQueue
+		if smsTransport != nil {
+			smsQueue = sms.NewAsyncSender(smsTransport, cfg.SMS.Workers, cfg.SMS.QueueSize, zapLogger)
+			smsSender = smsQueue
+		}
+	}
+	mailerService := services.NewMailerService(emailSender, configRepo, pharmacyRepo, customerRepo, cfg.Server.PublicURL, zapLogger)
+	smsNotificationService := services.NewSMSNotificationService(smsSender, configRepo, pharmacyRepo, customerRepo, zapLogger)
+	// Push notifications: FCM or log-only (PUSH_PROVIDER); "none" disables sending but devices can still register
+	var pushTransport outbound.PushSender
+	switch cfg.Push.Provider {
+	case "log":
+		pushTransport = push.NewLogSender(zapLogger)
+	case "fcm":
+		fcmSender, err := push.NewFCMSender(cfg.Push)
+		if err != nil {
+			zapLogger.Fatal("Failed to set up FCM", zap.Error(err))
+		}
+		pushTransport = fcmSender
+	}
+	var pushQueue *push.AsyncSender
+	var pushSender outbound.PushSender
+	if pushTransport != nil {
+		forgetToken := func(token string) {
+			if err := deviceTokenRepo.DeleteByToken(context.Background(), token); err != nil {
+				zapLogger.Warn("failed to remove invalid push token", zap.Error(err))
+			}
+		}
+		pushQueue = push.NewAsyncSender(pushTransport, cfg.Push.Workers, cfg.Push.QueueSize, forgetToken, zapLogger)
+		pushSender = pushQueue
+	}
+	pushNotificationService := services.NewPushNotificationService(deviceTokenRepo, userRepo, pushSender, cfg.Server.PublicURL, zapLogger)
+	otpService := services.NewOTPService(otpRepo, smsSender, configRepo, pharmacyRepo, zapLogger)
+
+	var store storage.Store
+	var fileChecker outbound.FileChecker
+	var fileDeleter outbound.FileDeleter
+	var fileHandler *handlers.FileHandler
+	switch cfg.FS.Type {
+	case "s3":
+		s3Store, err := storage.NewS3Storage(cfg.FS)
+		if err != nil {
+			zapLogger.Fatal("Failed to create S3 storage", zap.Error(err))
+		}
+		store, fileChecker, fileDeleter = s3Store, s3Store, s3Store
+	default:
+		localStore := storage.NewLocalStorage(cfg.FS)
+		store, fileChecker, fileDeleter = localStore, localStore, localStore
+		// Private files need their own FS_SIGNING_SECRET; without it they are refused and the route is not served
+		if cfg.FS.SigningSecret != "" {
+			fileHandler = handlers.NewFileHandler(localStore, zapLogger)
+		} else {
+			zapLogger.Warn("FS_SIGNING_SECRET not set; private file uploads (CVs, prescriptions, return evidence) are disabled")
+		}
+	}
+	// Every file saved goes through the ledger so the orphan sweep can find files nothing refers to any more.
+	fileRefRepo := persistence.NewFileReferenceRepository(db)
+	trackedStore := storage.NewTrackedStorage(store, fileRefRepo, zapLogger)
+	var fileStorage outbound.FileStorage = trackedStore
+	var privateFiles outbound.PrivateFileStorage = trackedStore
+	fileCleanupService := services.NewFileCleanupService(fileRefRepo, fileDeleter, cfg.FS.OrphanGrace, zapLogger)
 
-	authService := services.NewAuthService(userRepo, pharmacyRepo, authProviderInterface, zapLogger)
+	unitOfWork := persistence.NewUnitOfWork(db)
+	authService := services.NewAuthService(userRepo, pharmacyRepo, authProviderInterface, passwordResetTokenRepo, configRepo, loginChallengeRepo, mailerService, smsNotificationService, cfg.Server.PublicURL, unitOfWork, zapLogger)
 	userAddressService := services.NewUserAddressService(userAddressRepo, zapLogger)
 	var userAddressServiceInterface inbound.UserAddressService = userAddressService
-	userService := services.NewUserService(userRepo, pharmacyRepo, zapLogger)
+	roleService := services.NewRoleService(roleRepo, userRepo, zapLogger)
+	userService := services.NewUserService(userRepo, pharmacyRepo, configRepo, roleService, mailerService, zapLogger)
 	pharmacyService := services.NewPharmacyService(pharmacyRepo, zapLogger)
-	configService := services.NewPharmacyConfigService(configRepo, pharmacyRepo, zapLogger)
-	productService := services.NewProductService(productRepo, productImageRepo, zapLogger)
-	categoryService := services.NewCategoryService(categoryRepo, zapLogger)
+	configService := services.NewPharmacyConfigService(configRepo, configVersionRepo, pharmacyRepo, subscriptionRepo, zapLogger)
+	productService := services.NewProductService(productRepo, productImageRepo, configRepo, fileCleanupService, zapLogger)
+	hashtagService := services.NewHashtagService(persistence.NewHashtagRepository(db), persistence.NewProductViewRepository(db), productRepo, zapLogger)
+	categoryService := services.NewCategoryService(categoryRepo, productRepo, zapLogger)
 	productUnitService := services.NewProductUnitService(productUnitRepo, zapLogger)
 	membershipService := services.NewMembershipService(membershipRepo, zapLogger)
-	reviewService := services.NewReviewService(productReviewRepo, reviewLikeRepo, reviewCommentRepo, productRepo, orderRepo, userRepo, zapLogger)
-	inventoryService := services.NewInventoryService(inventoryBatchRepo, productRepo)
+	reviewService := services.NewReviewService(productReviewRepo, reviewLikeRepo, reviewCommentRepo, productRepo, orderRepo, userRepo, pharmacyRepo, zapLogger)
+	inventoryService := services.NewInventoryService(inventoryBatchRepo, productRepo, branchRepo, unitOfWork)
 	promoCodeService := services.NewPromoCodeService(promoCodeRepo, orderRepo, zapLogger)
-	referralPointsService := services.NewReferralPointsService(customerRepo, customerMembershipRepo, pointsTransactionRepo, referralPointsConfigRepo, orderRepo, userRepo, zapLogger)
+	benefitsEngine := services.NewBenefitsEngine(customerRepo, customerMembershipRepo, configRepo, zapLogger)
+	referralPointsService := services.NewReferralPointsService(customerRepo, customerMembershipRepo, pointsTransactionRepo, referralPointsConfigRepo, orderRepo, userRepo, benefitsEngine, zapLogger)
 	var referralPointsServiceInterface inbound.ReferralPointsService = referralPointsService
 	paymentService := services.NewPaymentService(paymentRepo, zapLogger)
 	paymentGatewayService := services.NewPaymentGatewayService(paymentGatewayRepo, zapLogger)
-	orderService := services.NewOrderService(orderRepo, productRepo, inventoryService, promoCodeRepo, promoCodeService, customerRepo, customerMembershipRepo, referralPointsServiceInterface, paymentGatewayRepo, paymentService, userRepo, staffPointsConfigRepo, zapLogger)
-	orderFeedbackService := services.NewOrderFeedbackService(orderRepo, orderFeedbackRepo)
+	flashSaleService := services.NewFlashSaleService(flashSaleRepo, productRepo, zapLogger)
+	expiryDiscountService := services.NewExpiryDiscountService(inventoryBatchRepo, categoryRepo, configRepo, zapLogger)
+	giftCardService := services.NewGiftCardService(giftCardRepo, customerRepo, configRepo, zapLogger)
+	// Order creation, cancellation and invoicing run their writes in one database transaction.
+	invoiceService := services.NewInvoiceService(invoiceRepo, persistence.NewCreditNoteRepository(db), orderRepo, paymentRepo, deliveryRepo, configRepo, mailerService, unitOfWork, zapLogger)
+	staffPointsService := services.NewStaffPointsService(staffPointsConfigRepo, persistence.NewStaffPointsTransactionRepository(db), userRepo, zapLogger)
+	storeCreditService := services.NewStoreCreditService(storeCreditRepo, customerRepo, orderRepo, invoiceService, zapLogger)
+	warrantyService := services.NewWarrantyService(persistence.NewWarrantyRepository(db), smsNotificationService, mailerService, zapLogger)
+	drugInfoService := services.NewDrugInfoService(persistence.NewDrugInfoRepository(db), productRepo, pharmacyRepo, zapLogger)
+	serialService := services.NewSerialService(persistence.NewSerialRepository(db), productRepo, purchaseOrderRepo, orderRepo, warrantyService, unitOfWork, zapLogger)
+	// The WebSocket hub carries chat messages and in-app notification events.
+	chatHub := ws.NewHub(zapLogger)
+	consentService := services.NewConsentService(persistence.NewConsentRepository(db), customerRepo, userRepo, referralPointsService, zapLogger)
+	notificationService := services.NewNotificationService(notificationRepo, pushNotificationService, chatHub, userRepo, configRepo, consentService, zapLogger)
+	licenseComplianceService := services.NewLicenseComplianceService(userRepo, configRepo, pharmacyRepo, notificationService, zapLogger)
+	orderService := services.NewOrderService(orderRepo, productRepo, inventoryService, promoCodeRepo, promoCodeService, customerRepo, customerMembershipRepo, referralPointsServiceInterface, paymentGatewayRepo, paymentService, userRepo, configRepo, flashSaleService, mailerService, smsNotificationService, pushNotificationService, expiryDiscountService, giftCardService, storeCreditService, benefitsEngine, serialService, drugInfoService, licenseComplianceService, unitOfWork, zapLogger)
+	// Domain events are written to the outbox with the change that raised them and dispatched by the scheduler.
+	eventBus := services.NewEventBus(persistence.NewOutboxRepository(db), services.EventBusOptions{
+		MaxAttempts: cfg.Queue.MaxAttempts,
+		BackoffBase: cfg.Queue.BackoffBase,
+		BackoffMax:  cfg.Queue.BackoffMax,
+		Lease:       cfg.Queue.Lease,
+	}, zapLogger)
+	etaService := services.NewETAService(persistence.NewETARepository(db), orderRepo, deliveryRepo, dutyRosterRepo, zapLogger)
+	services.RegisterEventSubscribers(eventBus, orderRepo, productRepo, userRepo, referralPointsServiceInterface, staffPointsService, notificationService, etaService, zapLogger)
+	campaignService := services.NewCampaignService(persistence.NewCustomerSegmentRepository(db), persistence.NewCampaignRepository(db), notificationService, smsSender, mailerService, configRepo, pharmacyRepo, consentService, zapLogger)
+	orderFeedbackService := services.NewOrderFeedbackService(orderRepo, orderFeedbackRepo, userRepo, notificationService, zapLogger)
 	var orderFeedbackServiceInterface inbound.OrderFeedbackService = orderFeedbackService
-	orderReturnRequestService := services.NewOrderReturnRequestService(orderRepo, orderReturnRequestRepo)
+	orderReturnRequestService := services.NewOrderReturnRequestService(orderRepo, orderReturnRequestRepo, productReturnFlagRepo, configRepo, invoiceService, storeCreditService, zapLogger)
 	var orderReturnRequestServiceInterface inbound.OrderReturnRequestService = orderReturnRequestService
-	invoiceService := services.NewInvoiceService(invoiceRepo, orderRepo, paymentRepo, zapLogger)
-	activityLogService := services.NewActivityLogService(activityLogRepo, zapLogger)
-	notificationService := services.NewNotificationService(notificationRepo, zapLogger)
-	promoService := services.NewPromoService(promoRepo, zapLogger)
-	announcementService := services.NewAnnouncementService(announcementRepo, announcementAckRepo, zapLogger)
-	dutyRosterService := services.NewDutyRosterService(dutyRosterRepo, userRepo, zapLogger)
+	activityLogService := services.NewActivityLogService(activityLogRepo, configRepo, pharmacyRepo, zapLogger)
+	promoService := services.NewPromoService(promoRepo, promoStatRepo, zapLogger)
+	announcementService := services.NewAnnouncementService(announcementRepo, announcementAckRepo, trainingRepo, pushNotificationService, userRepo, zapLogger)
+	trainingService := services.NewTrainingService(trainingRepo, announcementRepo, announcementAckRepo, userRepo, zapLogger)
+	dutyRosterService := services.NewDutyRosterService(dutyRosterRepo, userRepo, notificationService, zapLogger)
 	dailyLogService := services.NewDailyLogService(dailyLogRepo, zapLogger)
-	chatService := services.NewChatService(conversationRepo, chatMessageRepo, configRepo, customerRepo, zapLogger)
-	blogService := services.NewBlogService(blogPostRepo, blogCategoryRepo, blogPostMediaRepo, blogPostLikeRepo, blogPostCommentRepo, blogPostViewRepo, zapLogger)
+	documentService := services.NewDocumentService(pharmacyRepo, configRepo, dutyRosterRepo, dailyLogRepo, invoiceService, zapLogger)
+	chatService := services.NewChatService(conversationRepo, chatMessageRepo, configRepo, customerRepo, pushNotificationService, fileCleanupService, zapLogger)
+	// Chat escalation to external ticketing (TICKETING_PROVIDER); "none" keeps escalation in-app only
+	var ticketingProvider outbound.TicketingProvider
+	switch cfg.Ticketing.Provider {
+	case "webhook":
+		ticketingProvider = ticketing.NewWebhookProvider(cfg.Ticketing)
+	case "jira":
+		ticketingProvider = ticketing.NewJiraProvider(cfg.Ticketing)
+	case "freshdesk":
+		ticketingProvider = ticketing.NewFreshdeskProvider(cfg.Ticketing)
+	}
+	chatEscalationService := services.NewChatEscalationService(conversationRepo, chatMessageRepo, userRepo, ticketingProvider, cfg.Ticketing.WebhookSecret, cfg.Server.PublicURL, zapLogger)
+	blogService := services.NewBlogService(blogPostRepo, blogCategoryRepo, blogPostMediaRepo, blogPostLikeRepo, blogPostCommentRepo, blogPostViewRepo, persistence.NewBlogPostRevisionRepository(db), persistence.NewBlogTagRepository(db), pharmacyRepo, cfg.Server.PublicURL, zapLogger)
+
+	// LLM provider for AI drafts; nil disables generation endpoints (LLM_PROVIDER=none)
+	var llmProvider outbound.LLMProvider
+	switch cfg.LLM.Provider {
+	case "openai":
+		llmProvider = llm.NewOpenAIProvider(cfg.LLM)
+	case "stub":
+		llmProvider = llm.NewStubProvider()
+	}
+	reportService := services.NewReportService(reportRepo, configRepo, zapLogger)
+	aiContentService := services.NewAIContentService(aiGenerationRepo, productRepo, blogService, llmProvider, cfg.LLM.Provider, cfg.LLM.MonthlyQuota, zapLogger)
 
 	var authServiceInterface inbound.AuthService = authService
 	var pharmacyServiceInterface inbound.PharmacyService = pharmacyService
@@ -131,59 +344,155 @@ func main() {
 	var categoryServiceInterface inbound.CategoryService = categoryService
 	var productUnitServiceInterface inbound.ProductUnitService = productUnitService
 	var orderServiceInterface inbound.OrderService = orderService
+	cartService := services.NewCartService(cartRepo, productRepo, userRepo, customerRepo, customerMembershipRepo, configRepo, paymentGatewayRepo, promoCodeService, referralPointsServiceInterface, flashSaleService, expiryDiscountService, benefitsEngine, orderServiceInterface, zapLogger)
 	var paymentServiceInterface inbound.PaymentService = paymentService
 	var inventoryServiceInterface inbound.InventoryService = inventoryService
 	var invoiceServiceInterface inbound.InvoiceService = invoiceService
 	var activityLogServiceInterface inbound.ActivityLogService = activityLogService
+	exportApprovalService := services.NewExportApprovalService(persistence.NewExportRequestRepository(db), userRepo, activityLogServiceInterface, zapLogger)
 	var notificationServiceInterface inbound.NotificationService = notificationService
+	sitemapService := services.NewSitemapService(persistence.NewSitemapRepository(db), pharmacyRepo, configRepo, cfg.Server.PublicURL, zapLogger)
+	moderationService := services.NewModerationService(persistence.NewContentReportRepository(db), productReviewRepo, reviewCommentRepo, blogPostRepo, blogPostCommentRepo, productRepo, conversationRepo, chatMessageRepo, userRepo, notificationServiceInterface, activityLogServiceInterface, zapLogger)
 
-	var fileStorage outbound.FileStorage
-	switch cfg.FS.Type {
-	case "s3":
-		s3Store, err := storage.NewS3Storage(cfg.FS)
-		if err != nil {
-			zapLogger.Fatal("Failed to create S3 storage", zap.Error(err))
+	supplierService := services.NewSupplierService(supplierRepo, zapLogger)
+	preorderService := services.NewPreorderService(preorderRepo, productRepo, purchaseOrderRepo, paymentGatewayRepo, orderServiceInterface, paymentServiceInterface, notificationServiceInterface, emailSender, zapLogger)
+	purchaseOrderService := services.NewPurchaseOrderService(purchaseOrderRepo, supplierRepo, productRepo, emailSender, notificationServiceInterface, inventoryServiceInterface, preorderService, cfg.Server.PublicURL, cfg.Server.LinkSigningSecret, zapLogger)
+
+	var webpEncoder outbound.WebPEncoder
+	if cfg.FS.Image.Format == "webp" {
+		if enc, err := storage.NewCWebPEncoder(cfg.FS.Image.CWebPPath); err != nil {
+			zapLogger.Warn("WebP encoder unavailable, product image variants will be JPEG", zap.Error(err))
+		} else {
+			webpEncoder = enc
 		}
-		fileStorage = s3Store
-	default:
-		fileStorage = storage.NewLocalStorage(cfg.FS)
 	}
+	productImageProcessor := services.NewProductImageProcessor(fileStorage, webpEncoder, models.ProductImageSizes{
+		Thumbnail: cfg.FS.Image.ThumbnailSize, Medium: cfg.FS.Image.MediumSize, Large: cfg.FS.Image.LargeSize,
+	}, cfg.FS.Image.Quality, zapLogger)
+	deliveryService := services.NewDeliveryService(deliveryRepo, orderRepo, userRepo, configRepo, pharmacyRepo, notificationServiceInterface, smsSender, fileStorage, zapLogger)
 
-	authHandler := handlers.NewAuthHandler(authServiceInterface, activityLogServiceInterface, zapLogger)
+	authHandler := handlers.NewAuthHandler(authServiceInterface, activityLogServiceInterface, privateFiles, zapLogger)
 	addressHandler := handlers.NewAddressHandler(userAddressServiceInterface, zapLogger)
 	pharmacyHandler := handlers.NewPharmacyHandler(pharmacyServiceInterface, zapLogger)
 	configHandler := handlers.NewConfigHandler(configServiceInterface, activityLogServiceInterface, zapLogger)
-	usersHandler := handlers.NewUsersHandler(userService, activityLogServiceInterface, zapLogger)
-	dutyRosterHandler := handlers.NewDutyRosterHandler(dutyRosterService, zapLogger)
-	dailyLogHandler := handlers.NewDailyLogHandler(dailyLogService, zapLogger)
+	usersHandler := handlers.NewUsersHandler(userService, activityLogServiceInterface, privateFiles, zapLogger)
+	dutyRosterHandler := handlers.NewDutyRosterHandler(dutyRosterService, documentService, zapLogger)
+	shiftSwapHandler := handlers.NewShiftSwapHandler(services.NewShiftSwapService(shiftSwapRepo, dutyRosterRepo, userRepo, notificationService, zapLogger), zapLogger)
+	branchHandler := handlers.NewBranchHandler(services.NewBranchService(branchRepo, inventoryBatchRepo, productRepo, userRepo, zapLogger), zapLogger)
+	dailyLogHandler := handlers.NewDailyLogHandler(dailyLogService, documentService, zapLogger)
 	dashboardHandler := handlers.NewDashboardHandler(orderServiceInterface, productServiceInterface, userService, dutyRosterService, dailyLogService, zapLogger)
-	productHandler := handlers.NewProductHandler(productServiceInterface, categoryServiceInterface, fileStorage, productReviewRepo, zapLogger)
+	currencyService := services.NewCurrencyService(persistence.NewExchangeRateRepository(db), configRepo, zapLogger)
+	productHandler := handlers.NewProductHandler(productServiceInterface, categoryServiceInterface, hashtagService, flashSaleService, preorderService, expiryDiscountService, currencyService, productImageProcessor, productReviewRepo, zapLogger)
 	categoryHandler := handlers.NewCategoryHandler(categoryServiceInterface, zapLogger)
 	productUnitHandler := handlers.NewProductUnitHandler(productUnitServiceInterface, zapLogger)
 	var membershipServiceInterface inbound.MembershipService = membershipService
 	membershipHandler := handlers.NewMembershipHandler(membershipServiceInterface, zapLogger)
 	var reviewServiceInterface inbound.ReviewService = reviewService
 	reviewHandler := handlers.NewReviewHandler(reviewServiceInterface, zapLogger)
-	orderHandler := handlers.NewOrderHandler(orderServiceInterface, orderFeedbackServiceInterface, orderReturnRequestServiceInterface, zapLogger)
+	orderHandler := handlers.NewOrderHandler(orderServiceInterface, orderFeedbackServiceInterface, orderReturnRequestServiceInterface, privateFiles, zapLogger)
 	promoCodeHandler := handlers.NewPromoCodeHandler(promoCodeService, zapLogger)
 	paymentHandler := handlers.NewPaymentHandler(paymentServiceInterface, zapLogger)
 	paymentGatewayHandler := handlers.NewPaymentGatewayHandler(paymentGatewayService, zapLogger)
-	inventoryHandler := handlers.NewInventoryHandler(inventoryServiceInterface)
-	invoiceHandler := handlers.NewInvoiceHandler(invoiceServiceInterface, zapLogger)
-	healthHandler := handlers.NewHealthHandler()
-	uploadHandler := handlers.NewUploadHandler(fileStorage, zapLogger)
+	inventoryAlertService := services.NewInventoryAlertService(productRepo, inventoryBatchRepo, inventoryAlertRepo, configRepo, pharmacyRepo, userRepo, notificationServiceInterface, zapLogger)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryServiceInterface, inventoryAlertService)
+	invoiceHandler := handlers.NewInvoiceHandler(invoiceServiceInterface, documentService, zapLogger)
+	jobs := scheduler.New(zapLogger)
+	healthHandler := handlers.NewHealthHandler(jobs)
+	uploadStaging, err := storage.NewLocalStaging(cfg.FS.Upload.StagingDir)
+	if err != nil {
+		zapLogger.Fatal("Failed to create upload staging directory", zap.Error(err))
+	}
+	var virusScanner outbound.VirusScanner
+	if cfg.FS.Upload.VirusScanner == "clamav" {
+		virusScanner = storage.NewClamAVScanner(cfg.FS.Upload.ClamAVAddr, cfg.FS.Upload.ClamAVTimeout)
+	}
+	uploadService := services.NewUploadService(persistence.NewUploadRepository(db), uploadStaging, fileStorage, privateFiles, virusScanner, cfg.FS.Upload.PharmacyQuotaMB<<20, zapLogger)
+	pharmacySignupHandler := handlers.NewPharmacySignupHandler(services.NewPharmacySignupService(pharmacyRepo, userRepo, configRepo, categoryRepo, paymentGatewayRepo, subscriptionRepo, uploadService, privateFiles, mailerService, unitOfWork, zapLogger), zapLogger)
+	subscriptionService := services.NewSubscriptionService(subscriptionRepo, zapLogger)
+	subscriptionHandler := handlers.NewSubscriptionHandler(subscriptionService, activityLogServiceInterface, zapLogger)
+	currencyHandler := handlers.NewCurrencyHandler(currencyService, zapLogger)
+	platformHandler := handlers.NewPlatformHandler(services.NewPlatformService(pharmacyRepo, userRepo, authProviderInterface, zapLogger), activityLogServiceInterface, zapLogger)
+	uploadHandler := handlers.NewUploadHandler(uploadService, zapLogger)
 	activityHandler := handlers.NewActivityHandler(activityLogServiceInterface, zapLogger)
+	hashtagHandler := handlers.NewHashtagHandler(hashtagService, zapLogger)
+	productImageImportHandler := handlers.NewProductImageImportHandler(services.NewProductImageImportService(productRepo, productServiceInterface, productImageProcessor, zapLogger), zapLogger)
+	giftCardHandler := handlers.NewGiftCardHandler(giftCardService, zapLogger)
+	staffPointsHandler := handlers.NewStaffPointsHandler(staffPointsService, zapLogger)
+	campaignHandler := handlers.NewCampaignHandler(campaignService, zapLogger)
+	moderationHandler := handlers.NewModerationHandler(moderationService, zapLogger)
+	sitemapHandler := handlers.NewSitemapHandler(sitemapService, zapLogger)
+	storeCreditHandler := handlers.NewStoreCreditHandler(storeCreditService, zapLogger)
+	walletService := services.NewWalletService(persistence.NewWalletTopUpRepository(db), storeCreditRepo, customerRepo, userRepo, paymentGatewayRepo, referralPointsServiceInterface, notificationService, configRepo, unitOfWork, zapLogger)
+	walletHandler := handlers.NewWalletHandler(walletService, zapLogger)
+	clearanceRepo := persistence.NewClearanceRepository(db)
+	clearanceService := services.NewClearanceService(clearanceRepo, reportRepo, productRepo, promoCodeRepo, promoCodeService, inventoryService, unitOfWork, zapLogger)
+	clearanceHandler := handlers.NewClearanceHandler(clearanceService, zapLogger)
+	inventoryEvidenceService := services.NewInventoryEvidenceService(persistence.NewInventoryEvidenceRepository(db), inventoryBatchRepo, productRepo, clearanceRepo, fileStorage, unitOfWork, zapLogger)
+	inventoryEvidenceHandler := handlers.NewInventoryEvidenceHandler(inventoryEvidenceService, zapLogger)
+	drugInfoHandler := handlers.NewDrugInfoHandler(drugInfoService, zapLogger)
+	exportRequestHandler := handlers.NewExportRequestHandler(exportApprovalService, zapLogger)
+	licenseComplianceHandler := handlers.NewLicenseComplianceHandler(licenseComplianceService, zapLogger)
+	wishlistService := services.NewWishlistService(persistence.NewWishlistRepository(db), productRepo, notificationService, zapLogger)
+	wishlistHandler := handlers.NewWishlistHandler(wishlistService, zapLogger)
+	backInStockService := services.NewBackInStockService(persistence.NewStockSubscriptionRepository(db), productRepo, notificationService, emailSender, smsSender, zapLogger)
+	backInStockHandler := handlers.NewBackInStockHandler(backInStockService, zapLogger)
+	recommendationService := services.NewRecommendationService(persistence.NewRecommendationRepository(db), productRepo, hashtagService, zapLogger)
+	recommendationHandler := handlers.NewRecommendationHandler(recommendationService, zapLogger)
+	serialHandler := handlers.NewSerialHandler(serialService, zapLogger)
+	warrantyHandler := handlers.NewWarrantyHandler(warrantyService, zapLogger)
+	consentHandler := handlers.NewConsentHandler(consentService, zapLogger)
+	etaHandler := handlers.NewETAHandler(etaService, zapLogger)
+	organizationHandler := handlers.NewOrganizationHandler(services.NewOrganizationService(persistence.NewOrganizationRepository(db), pharmacyRepo, userRepo, zapLogger), zapLogger)
+	stockTransferHandler := handlers.NewStockTransferHandler(services.NewStockTransferService(stockTransferRepo, pharmacyRepo, productRepo, inventoryBatchRepo, branchRepo, activityLogServiceInterface, zapLogger), zapLogger)
 	notificationHandler := handlers.NewNotificationHandler(notificationServiceInterface, zapLogger)
-	promoHandler := handlers.NewPromoHandler(promoService, zapLogger)
+	promoImageService := services.NewPromoImageService(promoRepo, announcementRepo, fileStorage, zapLogger)
+	promoHandler := handlers.NewPromoHandler(promoService, promoImageService, zapLogger)
 	var announcementServiceInterface inbound.AnnouncementService = announcementService
-	announcementHandler := handlers.NewAnnouncementHandler(announcementServiceInterface, zapLogger)
+	announcementHandler := handlers.NewAnnouncementHandler(announcementServiceInterface, promoImageService, zapLogger)
 	referralHandler := handlers.NewReferralHandler(referralPointsServiceInterface, zapLogger)
 	blogHandler := handlers.NewBlogHandler(blogService, zapLogger)
-	chatHandler := handlers.NewChatHandler(chatService, authProviderInterface, zapLogger)
-	chatHub := ws.NewHub(zapLogger)
-	chatWSHandler := ws.HandleWS(authProviderInterface, userRepo, chatService, conversationRepo, chatHub, zapLogger)
+	chatOrderService := services.NewChatOrderService(conversationRepo, chatMessageRepo, userRepo, productRepo, orderRepo, orderServiceInterface, zapLogger)
+	chatHandler := handlers.NewChatHandler(chatService, chatEscalationService, chatOrderService, authProviderInterface, chatHub, privateFiles, zapLogger)
+	aiContentHandler := handlers.NewAIContentHandler(aiContentService, zapLogger)
+	reportHandler := handlers.NewReportHandler(reportService, zapLogger)
+	supplierHandler := handlers.NewSupplierHandler(supplierService, zapLogger)
+	purchaseOrderHandler := handlers.NewPurchaseOrderHandler(purchaseOrderService, zapLogger)
+	flashSaleHandler := handlers.NewFlashSaleHandler(flashSaleService, zapLogger)
+	preorderHandler := handlers.NewPreorderHandler(preorderService, zapLogger)
+	cartHandler := handlers.NewCartHandler(cartService, zapLogger)
+	dispatchService := services.NewDispatchService(deliveryRepo, persistence.NewDispatchBatchRepository(db), orderRepo, userRepo, notificationServiceInterface, pushNotificationService, unitOfWork, zapLogger)
+	deliveryHandler := handlers.NewDeliveryHandler(deliveryService, dispatchService, zapLogger)
+	returnHandler := handlers.NewReturnHandler(orderReturnRequestServiceInterface, privateFiles, zapLogger)
+	deviceHandler := handlers.NewDeviceHandler(pushNotificationService, zapLogger)
+	versionMetrics := middleware.NewVersionMetrics()
+	apiVersionHandler := handlers.NewAPIVersionHandler(cfg.API, versionMetrics)
+	apiUsageService := services.NewAPIUsageService(persistence.NewAPIUsageRepository(db), zapLogger)
+	apiUsageHandler := handlers.NewAPIUsageHandler(apiUsageService, zapLogger)
+	deliveryQueueHandler := handlers.NewDeliveryQueueHandler(deliveryQueue, zapLogger)
+	storageHandler := handlers.NewStorageHandler(fileCleanupService, zapLogger)
+	integrityHandler := handlers.NewIntegrityHandler(services.NewIntegrityService(integrityRepo, fileChecker, zapLogger), zapLogger)
+	trainingHandler := handlers.NewTrainingHandler(trainingService, zapLogger)
+	otpHandler := handlers.NewOTPHandler(otpService, zapLogger)
+	roleHandler := handlers.NewRoleHandler(roleService, zapLogger)
+	chatWSHandler := ws.HandleWS(authProviderInterface, userRepo, chatService, notificationService, conversationRepo, privateFiles, chatHub, zapLogger)
 
-	router := http.NewRouter(cfg, authHandler, addressHandler, pharmacyHandler, productHandler, categoryHandler, productUnitHandler, membershipHandler, reviewHandler, orderHandler, promoCodeHandler, paymentHandler, paymentGatewayHandler, inventoryHandler, invoiceHandler, configHandler, usersHandler, uploadHandler, activityHandler, notificationHandler, promoHandler, announcementHandler, referralHandler, healthHandler, dutyRosterHandler, dailyLogHandler, dashboardHandler, blogHandler, chatHandler, chatWSHandler, authProviderInterface, userRepo, activityLogServiceInterface, zapLogger)
+	idempotencyService := services.NewIdempotencyService(persistence.NewIdempotencyKeyRepository(db), zapLogger)
+	// Rate limits count in process unless RATE_LIMIT_STORE=redis; an unreachable Redis is logged and the limits
+	// let requests through until it is back.
+	var rateLimiter outbound.RateLimiter
+	if cfg.RateLimit.Enabled {
+		if cfg.RateLimit.Store == "redis" {
+			redisLimiter := ratelimit.NewRedisLimiter(cfg.RateLimit.RedisAddr, cfg.RateLimit.RedisPassword, cfg.RateLimit.RedisDB, cfg.RateLimit.RedisTimeout)
+			if err := redisLimiter.Ping(context.Background()); err != nil {
+				zapLogger.Warn("Redis for rate limiting is unreachable", zap.String("addr", cfg.RateLimit.RedisAddr), zap.Error(err))
+			}
+			defer redisLimiter.Close()
+			rateLimiter = redisLimiter
+		} else {
+			rateLimiter = ratelimit.NewMemoryLimiter()
+		}
+	}
+	router := http.NewRouter(cfg, authHandler, addressHandler, pharmacyHandler, productHandler, categoryHandler, productUnitHandler, membershipHandler, reviewHandler, orderHandler, promoCodeHandler, paymentHandler, paymentGatewayHandler, inventoryHandler, invoiceHandler, configHandler, usersHandler, uploadHandler, activityHandler, notificationHandler, promoHandler, announcementHandler, referralHandler, healthHandler, dutyRosterHandler, dailyLogHandler, dashboardHandler, blogHandler, chatHandler, aiContentHandler, reportHandler, supplierHandler, purchaseOrderHandler, flashSaleHandler, preorderHandler, trainingHandler, otpHandler, roleHandler, cartHandler, deliveryHandler, returnHandler, deviceHandler, apiVersionHandler, apiUsageHandler, deliveryQueueHandler, integrityHandler, shiftSwapHandler, branchHandler, stockTransferHandler, hashtagHandler, productImageImportHandler, giftCardHandler, storeCreditHandler, walletHandler, clearanceHandler, inventoryEvidenceHandler, drugInfoHandler, exportRequestHandler, licenseComplianceHandler, wishlistHandler, backInStockHandler, recommendationHandler, serialHandler, warrantyHandler, organizationHandler, consentHandler, etaHandler, staffPointsHandler, campaignHandler, moderationHandler, sitemapHandler, fileHandler, storageHandler, pharmacySignupHandler, platformHandler, subscriptionHandler, currencyHandler, chatWSHandler, versionMetrics, authProviderInterface, userRepo, pharmacyRepo, configRepo, activityLogServiceInterface, roleService, idempotencyService, apiUsageService, exportApprovalService, subscriptionService, rateLimiter, zapLogger)
 	server := http.NewServer(router, cfg, zapLogger)
 
 	go func() {
@@ -195,6 +504,33 @@ func main() {
 	log.Printf("CarePlus Pharmacy API running on port %s", cfg.Server.Port)
 	log.Println("Press Ctrl+C to stop")
 
+	// API usage counters are buffered in memory per instance, so every instance writes them, even when the other
+	// jobs are disabled.
+	jobs.EveryInstance("api-usage-flush", time.Minute, apiUsageService.Flush)
+	if cfg.Scheduler.Enabled {
+		if cfg.Scheduler.LeaderElection {
+			sqlDB, err := db.DB()
+			if err != nil {
+				zapLogger.Fatal("Failed to get database handle for leader election", zap.Error(err))
+			}
+			jobs.SetElector(scheduler.NewPostgresElector(sqlDB, "jobs", cfg.Scheduler.LeaderCheckInterval, zapLogger))
+		}
+		jobs.Every("inventory-alerts", cfg.Scheduler.InventoryAlertInterval, inventoryAlertService.ScanAll)
+		jobs.Every("activity-log-purge", cfg.Scheduler.ActivityLogPurgeInterval, activityLogService.PurgeExpired)
+		jobs.Every("outbox-dispatch", cfg.Scheduler.OutboxDispatchInterval, eventBus.DispatchDue)
+		jobs.Every("outbox-purge", 24*time.Hour, eventBus.PurgeDispatched)
+		jobs.Every("idempotency-key-purge", time.Hour, idempotencyService.PurgeExpired)
+		jobs.Every("license-reminders", 6*time.Hour, licenseComplianceService.SendReminders)
+		jobs.Every("wishlist-alerts", 15*time.Minute, wishlistService.ScanAlerts)
+		jobs.Every("back-in-stock", 5*time.Minute, backInStockService.Dispatch)
+		jobs.Every("recent-views-purge", 24*time.Hour, recommendationService.PurgeViews)
+		jobs.Every("blog-scheduled-publish", time.Minute, blogService.PublishScheduled)
+		jobs.Every("sitemap-refresh", 15*time.Minute, sitemapService.RefreshAll)
+		jobs.Every("upload-expiry", time.Hour, uploadService.ExpireStale)
+		jobs.Every("file-orphan-sweep", time.Hour, fileCleanupService.SweepOrphans)
+	}
+	jobs.Start()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
@@ -204,5 +540,29 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		zapLogger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
+	if err := jobs.Stop(ctx); err != nil {
+		zapLogger.Warn("Scheduled jobs did not stop before shutdown", zap.Error(err))
+	}
+	if err := apiUsageService.Flush(ctx); err != nil {
+		zapLogger.Warn("API usage counters not saved before shutdown", zap.Error(err))
+	}
+	if err := deliveryQueue.Close(ctx); err != nil {
+		zapLogger.Warn("Delivery queue workers did not stop before shutdown", zap.Error(err))
+	}
+	if emailQueue != nil {
+		if err := emailQueue.Close(ctx); err != nil {
+			zapLogger.Warn("Email queue not drained before shutdown", zap.Error(err))
+		}
+	}
+	if smsQueue != nil {
+		if err := smsQueue.Close(ctx); err != nil {
+			zapLogger.Warn("SMS queue not drained before shutdown", zap.Error(err))
+		}
+	}
+	if pushQueue != nil {
+		if err := pushQueue.Close(ctx); err != nil {
+			zapLogger.Warn("Push queue not drained before shutdown", zap.Error(err))
+		}
+	}
 	zapLogger.Info("Server stopped gracefully")
 }
@@ -0,0 +1,142 @@
+// Command doctor scans the database for integrity problems and prints a report.
+//
+//	go run ./cmd/doctor                       # every pharmacy, report only
+//	go run ./cmd/doctor --pharmacy valley     # one pharmacy (tenant code, hostname slug or id)
+//	go run ./cmd/doctor --fix                 # also apply the safe automatic fixes
+//	go run ./cmd/doctor --json                # machine-readable report
+//
+// Checks: order items referencing another pharmacy's product, payments without an order, customer points
+// balances that differ from the points ledger, and product images whose file is missing from storage.
+// Only the last two have automatic fixes. The exit status is 1 when issues remain, so it can gate CI or cron.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/persistence"
+	"github.com/careplus/pharmacy-backend/internal/adapters/storage"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/domain/services"
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/config"
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/database"
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/logger"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func main() {
+	pharmacy := flag.String("pharmacy", "", "limit to one pharmacy: tenant code, hostname slug or id (default: all)")
+	fix := flag.Bool("fix", false, "apply the safe automatic fixes")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	skipFiles := flag.Bool("skip-files", false, "skip the storage check for product image files")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	zapLogger, err := logger.NewZapLogger(cfg.Server.Environment)
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
+	db, cleanup, err := database.NewPostgresConnection(cfg, zapLogger)
+	if err != nil {
+		zapLogger.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	var pharmacyID *uuid.UUID
+	if *pharmacy != "" {
+		id, err := resolvePharmacy(ctx, db, *pharmacy)
+		if err != nil {
+			zapLogger.Fatal("Unknown pharmacy", zap.String("pharmacy", *pharmacy), zap.Error(err))
+		}
+		pharmacyID = &id
+	}
+
+	var files outbound.FileChecker
+	if !*skipFiles {
+		switch cfg.FS.Type {
+		case "s3":
+			s3Store, err := storage.NewS3Storage(cfg.FS)
+			if err != nil {
+				zapLogger.Fatal("Failed to create S3 storage", zap.Error(err))
+			}
+			files = s3Store
+		default:
+			files = storage.NewLocalStorage(cfg.FS)
+		}
+	}
+
+	svc := services.NewIntegrityService(persistence.NewIntegrityRepository(db), files, zapLogger)
+	report, err := svc.Run(ctx, pharmacyID, *fix)
+	if err != nil {
+		zapLogger.Fatal("Integrity check failed", zap.Error(err))
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	} else {
+		printReport(os.Stdout, report)
+	}
+	if report.Issues > 0 {
+		cleanup()
+		os.Exit(1)
+	}
+}
+
+// resolvePharmacy accepts an id, tenant code or hostname slug.
+func resolvePharmacy(ctx context.Context, db *gorm.DB, ref string) (uuid.UUID, error) {
+	var p models.Pharmacy
+	q := db.WithContext(ctx).Where("tenant_code = ? OR hostname_slug = ?", ref, ref)
+	if id, err := uuid.Parse(ref); err == nil {
+		q = db.WithContext(ctx).Where("id = ?", id)
+	}
+	if err := q.First(&p).Error; err != nil {
+		return uuid.Nil, err
+	}
+	return p.ID, nil
+}
+
+func printReport(w io.Writer, r *models.IntegrityReport) {
+	scope := "all pharmacies"
+	if r.PharmacyID != nil {
+		scope = "pharmacy " + r.PharmacyID.String()
+	}
+	fmt.Fprintf(w, "Integrity report for %s (%s)\n\n", scope, r.GeneratedAt.Format("2006-01-02 15:04:05"))
+	for _, c := range r.Checks {
+		status := "ok"
+		if c.Count > 0 {
+			status = fmt.Sprintf("%d found", c.Count)
+			if c.Fixed > 0 {
+				status += fmt.Sprintf(", %d fixed", c.Fixed)
+			}
+		}
+		fmt.Fprintf(w, "[%s] %s: %s\n", c.Code, c.Description, status)
+		if c.Count == 0 {
+			continue
+		}
+		for _, s := range c.Samples {
+			fmt.Fprintf(w, "    %s  pharmacy=%s  %s\n", s.RecordID, s.PharmacyID, s.Detail)
+		}
+		if c.Count > len(c.Samples) {
+			fmt.Fprintf(w, "    ... and %d more\n", c.Count-len(c.Samples))
+		}
+		fix := c.Fix
+		if c.Fixable && !r.Applied {
+			fix += " Run with --fix to apply."
+		}
+		fmt.Fprintf(w, "    fix: %s\n", fix)
+	}
+	fmt.Fprintf(w, "\n%d issue(s) remaining\n", r.Issues)
+}
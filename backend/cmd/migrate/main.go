@@ -0,0 +1,167 @@
+// Command migrate applies and inspects the versioned schema migrations in
+// internal/infrastructure/database/migrations. The API, seed and doctor refuse to start while migrations
+// are pending (unless DB_MIGRATE_ON_START=true), so run `up` as part of every deploy.
+//
+//	go run ./cmd/migrate up              # apply all pending migrations
+//	go run ./cmd/migrate up-to 12        # apply pending migrations up to and including version 12
+//	go run ./cmd/migrate down            # roll back the latest migration
+//	go run ./cmd/migrate down-to 10      # roll back every migration after version 10
+//	go run ./cmd/migrate status          # list migrations with the time each was applied
+//	go run ./cmd/migrate version         # print the database's current version
+//	go run ./cmd/migrate create add_foo  # write NNNNN_add_foo.sql with the next version number
+//
+// Existing databases created by the old AutoMigrate startup are adopted by running `up` once: the baseline
+// migration (00001) only creates what is missing.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/config"
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/database"
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/database/migrations"
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/logger"
+	"github.com/pressly/goose/v3"
+	"go.uber.org/zap"
+)
+
+const migrationTemplate = `-- +goose Up
+
+
+-- +goose Down
+
+`
+
+var migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+func main() {
+	dir := flag.String("dir", "internal/infrastructure/database/migrations", "migrations directory, for create")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: migrate [--dir DIR] up | up-to VERSION | down | down-to VERSION | status | version | create NAME")
+	}
+	flag.Parse()
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	cmd := args[0]
+
+	if cmd == "create" {
+		if len(args) != 2 || !migrationName.MatchString(args[1]) {
+			log.Fatal("create needs a name of lowercase letters, digits and underscores")
+		}
+		path, err := create(*dir, args[1])
+		if err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		fmt.Println(path)
+		return
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	zapLogger, err := logger.NewZapLogger(cfg.Server.Environment)
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
+	db, cleanup, err := database.OpenPostgres(cfg, zapLogger)
+	if err != nil {
+		zapLogger.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer cleanup()
+	m, err := database.NewMigrator(db)
+	if err != nil {
+		zapLogger.Fatal("Failed to load migrations", zap.Error(err))
+	}
+
+	ctx := context.Background()
+	var results []*goose.MigrationResult
+	switch cmd {
+	case "up":
+		results, err = m.Up(ctx)
+	case "up-to", "down-to":
+		if len(args) != 2 {
+			log.Fatalf("%s needs a version", cmd)
+		}
+		version, perr := strconv.ParseInt(args[1], 10, 64)
+		if perr != nil {
+			log.Fatalf("invalid version %q", args[1])
+		}
+		if cmd == "up-to" {
+			results, err = m.UpTo(ctx, version)
+		} else {
+			results, err = m.DownTo(ctx, version)
+		}
+	case "down":
+		var r *goose.MigrationResult
+		if r, err = m.Down(ctx); r != nil {
+			results = append(results, r)
+		}
+	case "status":
+		err = printStatus(ctx, m)
+	case "version":
+		var v int64
+		if v, err = m.GetDBVersion(ctx); err == nil {
+			fmt.Println(v)
+		}
+	default:
+		flag.Usage()
+		cleanup()
+		os.Exit(2)
+	}
+	for _, r := range results {
+		fmt.Printf("%-4s %s (%s)\n", r.Direction, r.Source.Path, r.Duration.Round(1e6))
+	}
+	if err != nil {
+		cleanup()
+		log.Fatalf("%s failed: %v", cmd, err)
+	}
+	if (cmd == "up" || cmd == "up-to" || cmd == "down" || cmd == "down-to") && len(results) == 0 {
+		fmt.Println("nothing to do")
+	}
+}
+
+func printStatus(ctx context.Context, m *goose.Provider) error {
+	list, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	for _, s := range list {
+		applied := "pending"
+		if s.State == goose.StateApplied {
+			applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%-19s  %s\n", applied, filepath.Base(s.Source.Path))
+	}
+	return nil
+}
+
+// create writes an empty migration numbered one past the highest embedded version.
+func create(dir, name string) (string, error) {
+	var next int64 = 1
+	files, err := fs.Glob(migrations.FS, "*.sql")
+	if err != nil {
+		return "", err
+	}
+	for _, f := range files {
+		if v, err := goose.NumericComponent(f); err == nil && v >= next {
+			next = v + 1
+		}
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%05d_%s.sql", next, name))
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("%s already exists", path)
+	}
+	return path, os.WriteFile(path, []byte(migrationTemplate), 0o644)
+}
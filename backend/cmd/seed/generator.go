@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// volumes controls how much synthetic data generate creates.
+type volumes struct {
+	Products  int
+	Customers int
+	Orders    int
+	Seed      int64
+}
+
+func volumeFlags(fs *flag.FlagSet, defaults volumes) *volumes {
+	v := &volumes{}
+	fs.IntVar(&v.Products, "products", defaults.Products, "number of products to generate")
+	fs.IntVar(&v.Customers, "customers", defaults.Customers, "number of customers to generate")
+	fs.IntVar(&v.Orders, "orders", defaults.Orders, "number of orders to generate (needs products)")
+	fs.Int64Var(&v.Seed, "seed", 42, "random seed; the same seed and volumes reproduce the same data")
+	return v
+}
+
+// catalog is the category tree and base items products are drawn from.
+var catalog = []struct {
+	Category string
+	Children map[string][]string
+}{
+	{"Medicines", map[string][]string{
+		"Pain Relief":      {"Paracetamol", "Ibuprofen", "Diclofenac Gel", "Aspirin"},
+		"Antibiotics":      {"Amoxicillin", "Azithromycin", "Ciprofloxacin", "Cefixime"},
+		"Cold & Flu":       {"Cetirizine", "Loratadine", "Cough Syrup", "Nasal Spray"},
+		"Digestive Health": {"Omeprazole", "Pantoprazole", "ORS Sachet", "Antacid Suspension"},
+	}},
+	{"Wellness", map[string][]string{
+		"Vitamins & Supplements": {"Vitamin C", "Vitamin D3", "Multivitamin", "Calcium + D3", "Zinc"},
+		"Skin Care":              {"Sunscreen SPF 50", "Moisturizing Lotion", "Calamine Lotion"},
+	}},
+	{"Personal Care", map[string][]string{
+		"Oral Care": {"Toothpaste", "Mouthwash", "Toothbrush Soft"},
+		"Baby Care": {"Baby Wipes", "Diaper Rash Cream", "Baby Lotion"},
+	}},
+	{"Medical Devices", map[string][]string{
+		"Monitoring": {"Digital Thermometer", "Pulse Oximeter", "BP Monitor"},
+	}},
+}
+
+var (
+	brands      = []string{"Deurali-Janta", "Nepal Pharma", "Asian Pharma", "Lomus", "Quest", "Himalaya", "Cipla", "Sun Pharma"}
+	strengths   = []string{"250 mg", "500 mg", "10 mg", "20 mg", "100 ml", "60 ml", "30 g", ""}
+	packSizes   = []string{"10 tablets", "15 tablets", "1 bottle", "1 tube", "1 pack", "30 capsules"}
+	firstNames  = []string{"Sita", "Ram", "Gita", "Hari", "Anita", "Bikash", "Sunita", "Rajesh", "Puja", "Suman", "Kiran", "Asha"}
+	lastNames   = []string{"Sharma", "Shrestha", "Gurung", "Tamang", "Karki", "Adhikari", "Thapa", "Maharjan", "Rai", "Magar"}
+	orderStates = []struct {
+		Status models.OrderStatus
+		Weight int
+	}{
+		{models.OrderStatusCompleted, 50}, {models.OrderStatusPending, 15}, {models.OrderStatusConfirmed, 10},
+		{models.OrderStatusProcessing, 10}, {models.OrderStatusReady, 5}, {models.OrderStatusCancelled, 10},
+	}
+)
+
+// generate fills a tenant with synthetic catalog, stock, customers and orders. Generated rows use
+// slug-prefixed SKUs and order numbers; when they already exist generation is skipped, so run
+// wipe:tenant first to regenerate.
+func generate(ctx context.Context, db *gorm.DB, log *zap.Logger, t *seededTenant, v volumes) error {
+	if v.Products <= 0 && v.Customers <= 0 {
+		return nil
+	}
+	prefix := strings.ToUpper(t.Pharmacy.TenantCode)
+	var existing int64
+	if err := db.WithContext(ctx).Model(&models.Product{}).Where("sku LIKE ?", prefix+"-P%").Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		log.Info("Generated data already present; run wipe:tenant to regenerate", zap.String("slug", t.Pharmacy.TenantCode), zap.Int64("products", existing))
+		return nil
+	}
+	g := &generator{rnd: rand.New(rand.NewSource(v.Seed)), pharmacyID: t.Pharmacy.ID, prefix: prefix, now: time.Now()}
+	if t.Admin != nil {
+		g.createdBy = t.Admin.ID
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		subcategories, err := g.categories(tx)
+		if err != nil {
+			return err
+		}
+		products, err := g.products(tx, subcategories, v.Products)
+		if err != nil {
+			return err
+		}
+		customers, err := g.customers(tx, v.Customers)
+		if err != nil {
+			return err
+		}
+		orders, err := g.orders(tx, products, customers, v.Orders)
+		if err != nil {
+			return err
+		}
+		log.Info("Generated data",
+			zap.String("slug", t.Pharmacy.TenantCode),
+			zap.Int("products", len(products)),
+			zap.Int("customers", len(customers)),
+			zap.Int("orders", orders),
+			zap.Int64("seed", v.Seed))
+		return nil
+	})
+}
+
+type generator struct {
+	rnd        *rand.Rand
+	pharmacyID uuid.UUID
+	createdBy  uuid.UUID
+	prefix     string
+	now        time.Time
+}
+
+func (g *generator) pick(list []string) string { return list[g.rnd.Intn(len(list))] }
+
+// categories creates the catalog tree (reusing categories with the same name) and returns the subcategories.
+func (g *generator) categories(tx *gorm.DB) ([]*models.Category, error) {
+	var subs []*models.Category
+	for i, top := range catalog {
+		parent, err := g.category(tx, top.Category, nil, i)
+		if err != nil {
+			return nil, err
+		}
+		names := sortedKeys(top.Children)
+		for j, name := range names {
+			child, err := g.category(tx, name, &parent.ID, j)
+			if err != nil {
+				return nil, err
+			}
+			subs = append(subs, child)
+		}
+	}
+	return subs, nil
+}
+
+func (g *generator) category(tx *gorm.DB, name string, parentID *uuid.UUID, sortOrder int) (*models.Category, error) {
+	var c models.Category
+	err := tx.Where("pharmacy_id = ? AND name = ?", g.pharmacyID, name).First(&c).Error
+	if err == nil {
+		return &c, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	c = models.Category{PharmacyID: g.pharmacyID, ParentID: parentID, Name: name, SortOrder: sortOrder}
+	return &c, tx.Create(&c).Error
+}
+
+func (g *generator) products(tx *gorm.DB, subs []*models.Category, n int) ([]*models.Product, error) {
+	items := map[string][]string{}
+	for _, top := range catalog {
+		for name, list := range top.Children {
+			items[name] = list
+		}
+	}
+	products := make([]*models.Product, 0, n)
+	for i := 0; i < n; i++ {
+		sub := subs[i%len(subs)]
+		base := g.pick(items[sub.Name])
+		name := strings.TrimSpace(base + " " + g.pick(strengths))
+		price := math.Round((20+g.rnd.Float64()*980)*100) / 100
+		p := &models.Product{
+			PharmacyID:    g.pharmacyID,
+			Name:          name,
+			Description:   fmt.Sprintf("%s by %s. Generated demo product.", base, g.pick(brands)),
+			SKU:           fmt.Sprintf("%s-P%04d", g.prefix, i+1),
+			Category:      sub.Name,
+			CategoryID:    &sub.ID,
+			UnitPrice:     price,
+			Currency:      "NPR",
+			StockQuantity: 0,
+			Unit:          "units",
+			RequiresRx:    sub.Name == "Antibiotics",
+			IsActive:      true,
+			Brand:         g.pick(brands),
+			Barcode:       fmt.Sprintf("890%010d", g.rnd.Int63n(1e10)),
+			PackSize:      g.pick(packSizes),
+			GenericName:   base,
+		}
+		if err := tx.Create(p).Error; err != nil {
+			return nil, err
+		}
+		// One or two batches; some expire soon so near-expiry features have data to show.
+		for b := 0; b < 1+g.rnd.Intn(2); b++ {
+			expiry := g.now.AddDate(0, 0, 15+g.rnd.Intn(700))
+			batch := &models.InventoryBatch{
+				ProductID:   p.ID,
+				PharmacyID:  g.pharmacyID,
+				BatchNumber: fmt.Sprintf("%s-B%04d-%d", g.prefix, i+1, b+1),
+				Quantity:    5 + g.rnd.Intn(96),
+				ExpiryDate:  &expiry,
+			}
+			if err := tx.Create(batch).Error; err != nil {
+				return nil, err
+			}
+			p.StockQuantity += batch.Quantity
+			if p.ExpiryDate == nil || expiry.Before(*p.ExpiryDate) {
+				p.ExpiryDate = &expiry
+			}
+		}
+		if err := tx.Model(p).Updates(map[string]interface{}{"stock_quantity": p.StockQuantity, "expiry_date": p.ExpiryDate}).Error; err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	return products, nil
+}
+
+func (g *generator) customers(tx *gorm.DB, n int) ([]*models.Customer, error) {
+	customers := make([]*models.Customer, 0, n)
+	for i := 0; i < n; i++ {
+		first, last := g.pick(firstNames), g.pick(lastNames)
+		c := &models.Customer{
+			PharmacyID:   g.pharmacyID,
+			Name:         first + " " + last,
+			Phone:        fmt.Sprintf("98%08d", i+1),
+			Email:        fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(first), strings.ToLower(last), i+1),
+			ReferralCode: fmt.Sprintf("%s%04d", g.prefix, i+1),
+		}
+		if len(c.ReferralCode) > 20 {
+			c.ReferralCode = c.ReferralCode[len(c.ReferralCode)-20:]
+		}
+		if err := tx.Create(c).Error; err != nil {
+			return nil, err
+		}
+		customers = append(customers, c)
+	}
+	return customers, nil
+}
+
+// orders creates orders over the last 90 days with a realistic status mix. Stock is not decremented.
+func (g *generator) orders(tx *gorm.DB, products []*models.Product, customers []*models.Customer, n int) (int, error) {
+	if len(products) == 0 || len(customers) == 0 {
+		return 0, nil
+	}
+	for i := 0; i < n; i++ {
+		cust := customers[g.rnd.Intn(len(customers))]
+		createdAt := g.now.Add(-time.Duration(g.rnd.Intn(90*24)) * time.Hour)
+		o := &models.Order{
+			PharmacyID:    g.pharmacyID,
+			OrderNumber:   fmt.Sprintf("ORD-%s-%05d", g.prefix, i+1),
+			CustomerName:  cust.Name,
+			CustomerPhone: cust.Phone,
+			CustomerEmail: cust.Email,
+			CustomerID:    &cust.ID,
+			Status:        g.status(),
+			Currency:      "NPR",
+			CreatedBy:     g.createdBy,
+			CreatedAt:     createdAt,
+			UpdatedAt:     createdAt,
+		}
+		lines := 1 + g.rnd.Intn(4)
+		for l := 0; l < lines; l++ {
+			p := products[g.rnd.Intn(len(products))]
+			qty := 1 + g.rnd.Intn(3)
+			o.Items = append(o.Items, models.OrderItem{ProductID: p.ID, Quantity: qty, UnitPrice: p.UnitPrice, TotalPrice: p.UnitPrice * float64(qty)})
+			o.SubTotal += p.UnitPrice * float64(qty)
+		}
+		o.SubTotal = math.Round(o.SubTotal*100) / 100
+		o.TotalAmount = o.SubTotal
+		if o.Status == models.OrderStatusCompleted {
+			done := createdAt.Add(time.Duration(1+g.rnd.Intn(48)) * time.Hour)
+			o.CompletedAt = &done
+		}
+		if err := tx.Create(o).Error; err != nil {
+			return i, err
+		}
+	}
+	return n, nil
+}
+
+func (g *generator) status() models.OrderStatus {
+	total := 0
+	for _, s := range orderStates {
+		total += s.Weight
+	}
+	r := g.rnd.Intn(total)
+	for _, s := range orderStates {
+		if r < s.Weight {
+			return s.Status
+		}
+		r -= s.Weight
+	}
+	return models.OrderStatusPending
+}
+
+// sortedKeys keeps generation deterministic (map iteration order is random).
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,10 +1,21 @@
+// Command seed sets up local development and QA databases.
+//
+//	go run ./cmd/seed                      # same as seed:minimal
+//	go run ./cmd/seed seed:minimal         # demo pharmacy, config and quick-login users
+//	go run ./cmd/seed seed:full-demo       # minimal + generated catalog, stock, customers and orders
+//	go run ./cmd/seed seed:tenant --name "Valley Pharmacy" --slug valley --products 30
+//	go run ./cmd/seed wipe:tenant --slug valley --yes
+//
+// Generated data is deterministic for a given --seed, so environments can be reproduced.
 package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
+	"os"
 
-	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/infrastructure/config"
 	"github.com/careplus/pharmacy-backend/internal/infrastructure/database"
 	"github.com/careplus/pharmacy-backend/internal/infrastructure/logger"
@@ -23,7 +34,42 @@ const (
 	SeedTestEmail       = "test@careplus.com" // legacy; same as admin
 )
 
+const usage = `usage: seed [command] [flags]
+
+commands:
+  seed:minimal      demo pharmacy, config and quick-login users (default)
+  seed:full-demo    seed:minimal plus generated catalog, stock, customers and orders
+  seed:tenant       create a tenant: --name, --slug (required) plus volume flags
+  wipe:tenant       delete a tenant and all of its data: --slug, --yes
+
+volume flags (seed:full-demo, seed:tenant): --products, --customers, --orders, --seed
+`
+
+// command is one subcommand; run receives the remaining args after the command name.
+type command func(ctx context.Context, db *gorm.DB, log *zap.Logger, args []string) error
+
+var commands = map[string]command{
+	"seed:minimal":   runMinimal,
+	"seed:full-demo": runFullDemo,
+	"seed:tenant":    runTenant,
+	"wipe:tenant":    runWipeTenant,
+}
+
 func main() {
+	name, args := "seed:minimal", os.Args[1:]
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" || name == "-h" || name == "--help" {
+		fmt.Print(usage)
+		return
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", name, usage)
+		os.Exit(2)
+	}
+
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
@@ -40,116 +86,79 @@ func main() {
 	}
 	defer cleanup()
 
-	ctx := context.Background()
-	if err := runSeed(ctx, db, zapLogger); err != nil {
-		zapLogger.Fatal("Seed failed", zap.Error(err))
+	if err := cmd(context.Background(), db, zapLogger, args); err != nil {
+		zapLogger.Fatal("Seed failed", zap.String("command", name), zap.Error(err))
 	}
-	zapLogger.Info("Seed completed successfully",
+	zapLogger.Info("Seed completed successfully", zap.String("command", name))
+}
+
+func runMinimal(ctx context.Context, db *gorm.DB, log *zap.Logger, args []string) error {
+	fs := flag.NewFlagSet("seed:minimal", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if _, err := ensureTenant(ctx, db, log, demoTenant()); err != nil {
+		return err
+	}
+	log.Info("Quick-login users ready",
 		zap.String("admin", SeedAdminEmail),
 		zap.String("pharmacist", SeedPharmacistEmail),
 		zap.String("buyer", SeedBuyerEmail),
 		zap.String("password", SeedTestPassword))
+	return nil
 }
 
-func runSeed(ctx context.Context, db *gorm.DB, log *zap.Logger) error {
-	// Create demo pharmacy if not exists
-	var pharmacy models.Pharmacy
-	err := db.WithContext(ctx).Where("license_no = ?", "DEMO-LICENSE-001").First(&pharmacy).Error
-	if err == gorm.ErrRecordNotFound {
-		pharmacy = models.Pharmacy{
-			Name:          "CarePlus Demo Pharmacy",
-			LicenseNo:     "DEMO-LICENSE-001",
-			TenantCode:    "careplus",
-			HostnameSlug:  "careplus",
-			BusinessType:  models.BusinessTypePharmacy,
-			Address:       "123 Demo Street, Kathmandu",
-			Phone:         "+977 1 2345678",
-			Email:         "demo@careplus.com",
-			IsActive:      true,
-		}
-		if err := db.WithContext(ctx).Create(&pharmacy).Error; err != nil {
-			return err
-		}
-		log.Info("Created demo pharmacy", zap.String("id", pharmacy.ID.String()))
-	} else if err != nil {
+func runFullDemo(ctx context.Context, db *gorm.DB, log *zap.Logger, args []string) error {
+	fs := flag.NewFlagSet("seed:full-demo", flag.ContinueOnError)
+	vols := volumeFlags(fs, volumes{Products: 60, Customers: 25, Orders: 80})
+	if err := fs.Parse(args); err != nil {
 		return err
-	} else if pharmacy.TenantCode == "" || pharmacy.HostnameSlug == "" || pharmacy.BusinessType == "" {
-		// Backfill for existing DBs after app-config migration
-		if pharmacy.TenantCode == "" {
-			pharmacy.TenantCode = "careplus"
-		}
-		if pharmacy.HostnameSlug == "" {
-			pharmacy.HostnameSlug = "careplus"
-		}
-		if pharmacy.BusinessType == "" {
-			pharmacy.BusinessType = models.BusinessTypePharmacy
-		}
-		if err := db.WithContext(ctx).Save(&pharmacy).Error; err != nil {
-			return err
-		}
-		log.Info("Updated demo pharmacy with tenant_code, hostname_slug, business_type")
-	}
-
-	// Ensure demo pharmacy has config for /app-config (company_name, theme, language, address)
-	var config models.PharmacyConfig
-	err = db.WithContext(ctx).Where("pharmacy_id = ?", pharmacy.ID).First(&config).Error
-	if err == gorm.ErrRecordNotFound {
-		config = models.PharmacyConfig{
-			PharmacyID:      pharmacy.ID,
-			DisplayName:     "Care+ Pharmacy",
-			Location:        pharmacy.Address,
-			PrimaryColor:    "#0d9488",
-			DefaultLanguage: "en",
-			WebsiteEnabled:  true,
-			FeatureFlags:    models.DefaultFeatureFlags(),
-		}
-		if err := db.WithContext(ctx).Create(&config).Error; err != nil {
-			return err
-		}
-		log.Info("Created demo pharmacy config for app-config")
-	} else if err != nil {
+	}
+	t, err := ensureTenant(ctx, db, log, demoTenant())
+	if err != nil {
 		return err
 	}
+	return generate(ctx, db, log, t, *vols)
+}
 
-	// Seed users for quick login: admin, manager, pharmacist, end user (buyer)
-	seedUsers := []struct {
-		email string
-		name  string
-		role  string
-	}{
-		{SeedAdminEmail, "Admin User", "admin"},
-		{SeedManagerEmail, "Manager User", "manager"},
-		{SeedPharmacistEmail, "Pharmacist User", "pharmacist"},
-		{SeedBuyerEmail, "End User (Buyer)", "staff"},
-		{SeedTestEmail, "Test User", "admin"}, // legacy
-	}
-	seen := make(map[string]bool)
-	for _, su := range seedUsers {
-		if seen[su.email] {
-			continue
-		}
-		seen[su.email] = true
-		var user models.User
-		err := db.WithContext(ctx).Where("email = ?", su.email).First(&user).Error
-		if err == gorm.ErrRecordNotFound {
-			user = models.User{
-				PharmacyID: pharmacy.ID,
-				Email:      su.email,
-				Name:       su.name,
-				Role:       su.role,
-				IsActive:   true,
-			}
-			if err := user.SetPassword(SeedTestPassword); err != nil {
-				return err
-			}
-			if err := db.WithContext(ctx).Create(&user).Error; err != nil {
-				return err
-			}
-			log.Info("Created seed user", zap.String("email", su.email), zap.String("role", su.role))
-		} else if err != nil {
-			return err
-		}
-	}
-	log.Info("Seed users ready", zap.String("password", SeedTestPassword))
+func runTenant(ctx context.Context, db *gorm.DB, log *zap.Logger, args []string) error {
+	fs := flag.NewFlagSet("seed:tenant", flag.ContinueOnError)
+	name := fs.String("name", "", "pharmacy name (required)")
+	slug := fs.String("slug", "", "tenant code and hostname slug, e.g. valley (required)")
+	vols := volumeFlags(fs, volumes{Products: 20, Customers: 10, Orders: 20})
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	spec, err := newTenantSpec(*name, *slug)
+	if err != nil {
+		return err
+	}
+	t, err := ensureTenant(ctx, db, log, spec)
+	if err != nil {
+		return err
+	}
+	if err := generate(ctx, db, log, t, *vols); err != nil {
+		return err
+	}
+	log.Info("Tenant ready",
+		zap.String("pharmacy_id", t.Pharmacy.ID.String()),
+		zap.String("admin", spec.Users[0].Email),
+		zap.String("password", SeedTestPassword))
 	return nil
 }
+
+func runWipeTenant(ctx context.Context, db *gorm.DB, log *zap.Logger, args []string) error {
+	fs := flag.NewFlagSet("wipe:tenant", flag.ContinueOnError)
+	slug := fs.String("slug", "", "tenant code or hostname slug of the pharmacy to delete (required)")
+	yes := fs.Bool("yes", false, "confirm permanent deletion")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *slug == "" {
+		return fmt.Errorf("--slug is required")
+	}
+	if !*yes {
+		return fmt.Errorf("wipe:tenant permanently deletes every row of tenant %q; re-run with --yes", *slug)
+	}
+	return wipeTenant(ctx, db, log, *slug)
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type seedUser struct {
+	Email string
+	Name  string
+	Role  string
+}
+
+// tenantSpec describes a pharmacy to create (or reuse) together with its config and users.
+type tenantSpec struct {
+	Name        string
+	DisplayName string
+	Slug        string
+	LicenseNo   string
+	Address     string
+	Phone       string
+	Email       string
+	Users       []seedUser // the first user is the admin and is recorded as creator of generated data
+	// PlatformAdmin is the email of the user made a platform admin (reviews pharmacy signups); empty = none.
+	PlatformAdmin string
+}
+
+// seededTenant is what ensureTenant found or created.
+type seededTenant struct {
+	Pharmacy *models.Pharmacy
+	Admin    *models.User
+}
+
+// demoTenant is the pharmacy used by frontend quick login.
+func demoTenant() tenantSpec {
+	return tenantSpec{
+		Name:        "CarePlus Demo Pharmacy",
+		DisplayName: "Care+ Pharmacy",
+		Slug:        "careplus",
+		LicenseNo:   "DEMO-LICENSE-001",
+		Address:     "123 Demo Street, Kathmandu",
+		Phone:       "+977 1 2345678",
+		Email:       "demo@careplus.com",
+		Users: []seedUser{
+			{SeedAdminEmail, "Admin User", "admin"},
+			{SeedManagerEmail, "Manager User", "manager"},
+			{SeedPharmacistEmail, "Pharmacist User", "pharmacist"},
+			{SeedBuyerEmail, "End User (Buyer)", "staff"},
+			{SeedTestEmail, "Test User", "admin"}, // legacy
+		},
+		PlatformAdmin: SeedAdminEmail,
+	}
+}
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+// newTenantSpec builds a tenant with one user per role; emails are <role>@<slug>.test.
+func newTenantSpec(name, slug string) (tenantSpec, error) {
+	name = strings.TrimSpace(name)
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if name == "" || slug == "" {
+		return tenantSpec{}, fmt.Errorf("--name and --slug are required")
+	}
+	if !slugPattern.MatchString(slug) {
+		return tenantSpec{}, fmt.Errorf("--slug must be 2-63 lowercase letters, digits or dashes")
+	}
+	domain := slug + ".test"
+	return tenantSpec{
+		Name:        name,
+		DisplayName: name,
+		Slug:        slug,
+		LicenseNo:   "DEV-" + strings.ToUpper(slug),
+		Address:     "Kathmandu, Nepal",
+		Phone:       "+977 1 0000000",
+		Email:       "info@" + domain,
+		Users: []seedUser{
+			{"admin@" + domain, "Admin User", "admin"},
+			{"manager@" + domain, "Manager User", "manager"},
+			{"pharmacist@" + domain, "Pharmacist User", "pharmacist"},
+			{"buyer@" + domain, "End User (Buyer)", "staff"},
+		},
+	}, nil
+}
+
+// ensureTenant creates the pharmacy, its config and users when missing. It is safe to run repeatedly.
+func ensureTenant(ctx context.Context, db *gorm.DB, log *zap.Logger, spec tenantSpec) (*seededTenant, error) {
+	var pharmacy models.Pharmacy
+	err := db.WithContext(ctx).Where("license_no = ?", spec.LicenseNo).First(&pharmacy).Error
+	if err == gorm.ErrRecordNotFound {
+		pharmacy = models.Pharmacy{
+			Name:         spec.Name,
+			LicenseNo:    spec.LicenseNo,
+			TenantCode:   spec.Slug,
+			HostnameSlug: spec.Slug,
+			BusinessType: models.BusinessTypePharmacy,
+			Address:      spec.Address,
+			Phone:        spec.Phone,
+			Email:        spec.Email,
+			IsActive:     true,
+		}
+		if err := db.WithContext(ctx).Create(&pharmacy).Error; err != nil {
+			return nil, err
+		}
+		log.Info("Created pharmacy", zap.String("id", pharmacy.ID.String()), zap.String("slug", spec.Slug))
+	} else if err != nil {
+		return nil, err
+	} else if pharmacy.TenantCode == "" || pharmacy.HostnameSlug == "" || pharmacy.BusinessType == "" {
+		// Backfill for existing DBs after app-config migration
+		if pharmacy.TenantCode == "" {
+			pharmacy.TenantCode = spec.Slug
+		}
+		if pharmacy.HostnameSlug == "" {
+			pharmacy.HostnameSlug = spec.Slug
+		}
+		if pharmacy.BusinessType == "" {
+			pharmacy.BusinessType = models.BusinessTypePharmacy
+		}
+		if err := db.WithContext(ctx).Save(&pharmacy).Error; err != nil {
+			return nil, err
+		}
+		log.Info("Updated pharmacy with tenant_code, hostname_slug, business_type", zap.String("slug", spec.Slug))
+	}
+
+	// Ensure the pharmacy has config for /app-config (company_name, theme, language, address)
+	var cfg models.PharmacyConfig
+	err = db.WithContext(ctx).Where("pharmacy_id = ?", pharmacy.ID).First(&cfg).Error
+	if err == gorm.ErrRecordNotFound {
+		cfg = models.PharmacyConfig{
+			PharmacyID:      pharmacy.ID,
+			DisplayName:     spec.DisplayName,
+			Location:        pharmacy.Address,
+			PrimaryColor:    "#0d9488",
+			DefaultLanguage: "en",
+			WebsiteEnabled:  true,
+			FeatureFlags:    models.DefaultFeatureFlags(),
+		}
+		if err := db.WithContext(ctx).Create(&cfg).Error; err != nil {
+			return nil, err
+		}
+		log.Info("Created pharmacy config for app-config", zap.String("slug", spec.Slug))
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Seed users for quick login: admin, manager, pharmacist, end user (buyer)
+	result := &seededTenant{Pharmacy: &pharmacy}
+	seen := make(map[string]bool)
+	for _, su := range spec.Users {
+		if seen[su.Email] {
+			continue
+		}
+		seen[su.Email] = true
+		var user models.User
+		err := db.WithContext(ctx).Where("email = ?", su.Email).First(&user).Error
+		if err == gorm.ErrRecordNotFound {
+			user = models.User{
+				PharmacyID: pharmacy.ID,
+				Email:      su.Email,
+				Name:       su.Name,
+				Role:       su.Role,
+				IsActive:   true,
+			}
+			if err := user.SetPassword(SeedTestPassword); err != nil {
+				return nil, err
+			}
+			if err := db.WithContext(ctx).Create(&user).Error; err != nil {
+				return nil, err
+			}
+			log.Info("Created seed user", zap.String("email", su.Email), zap.String("role", su.Role))
+		} else if err != nil {
+			return nil, err
+		}
+		if su.Email == spec.PlatformAdmin && !user.PlatformAdmin {
+			if err := db.WithContext(ctx).Model(&user).Update("platform_admin", true).Error; err != nil {
+				return nil, err
+			}
+			log.Info("Made seed user a platform admin", zap.String("email", su.Email))
+		}
+		if result.Admin == nil {
+			u := user
+			result.Admin = &u
+		}
+	}
+	log.Info("Seed users ready", zap.String("password", SeedTestPassword))
+	return result, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// foreignKey is a single-column FK from child.column to parent.id.
+type foreignKey struct {
+	Child  string
+	Column string
+	Parent string
+}
+
+// wipeTenant hard-deletes a pharmacy and everything that belongs to it. Tenant rows are found by
+// schema rather than a hand-kept list, so new tables are covered automatically:
+//   - every table with a pharmacy_id column, and
+//   - tables without one that reference a tenant table through a foreign key (e.g. order_items).
+//
+// Deletes run in dependency order inside one transaction; statements blocked by a foreign key are
+// retried after the rows referencing them are gone.
+func wipeTenant(ctx context.Context, db *gorm.DB, log *zap.Logger, slug string) error {
+	var pharmacy models.Pharmacy
+	err := db.WithContext(ctx).Unscoped().Where("tenant_code = ? OR hostname_slug = ?", slug, slug).First(&pharmacy).Error
+	if err == gorm.ErrRecordNotFound {
+		return fmt.Errorf("no pharmacy with tenant code or slug %q", slug)
+	}
+	if err != nil {
+		return err
+	}
+
+	var scoped []string
+	if err := db.WithContext(ctx).Raw(`
+		SELECT c.table_name FROM information_schema.columns c
+		JOIN information_schema.tables t ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+		WHERE c.table_schema = current_schema() AND c.column_name = 'pharmacy_id' AND t.table_type = 'BASE TABLE'
+		ORDER BY c.table_name`).Scan(&scoped).Error; err != nil {
+		return err
+	}
+	var fks []foreignKey
+	if err := db.WithContext(ctx).Raw(`
+		SELECT kcu.table_name AS child, kcu.column_name AS "column", ccu.table_name AS parent
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.key_column_usage kcu ON kcu.constraint_name = tc.constraint_name AND kcu.table_schema = tc.table_schema
+		JOIN information_schema.constraint_column_usage ccu ON ccu.constraint_name = tc.constraint_name AND ccu.table_schema = tc.table_schema
+		WHERE tc.constraint_type = 'FOREIGN KEY' AND tc.table_schema = current_schema() AND ccu.column_name = 'id'`).Scan(&fks).Error; err != nil {
+		return err
+	}
+
+	isScoped := make(map[string]bool, len(scoped))
+	for _, t := range scoped {
+		isScoped[t] = true
+	}
+	var stmts []string
+	// Children without pharmacy_id first: they only reach the tenant through their parent.
+	for _, fk := range fks {
+		if isScoped[fk.Child] || !isScoped[fk.Parent] || fk.Child == "pharmacies" {
+			continue
+		}
+		stmts = append(stmts, fmt.Sprintf(`DELETE FROM %q WHERE %q IN (SELECT id FROM %q WHERE pharmacy_id = @id)`, fk.Child, fk.Column, fk.Parent))
+	}
+	for _, t := range scoped {
+		stmts = append(stmts, fmt.Sprintf(`DELETE FROM %q WHERE pharmacy_id = @id`, t))
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		total := int64(0)
+		pending := stmts
+		for pass := 1; len(pending) > 0; pass++ {
+			var blocked []string
+			for i, stmt := range pending {
+				sp := fmt.Sprintf("wipe_%d_%d", pass, i)
+				tx.SavePoint(sp)
+				res := tx.Exec(stmt, map[string]interface{}{"id": pharmacy.ID})
+				if res.Error != nil {
+					tx.RollbackTo(sp)
+					blocked = append(blocked, stmt)
+					continue
+				}
+				total += res.RowsAffected
+			}
+			if len(blocked) == len(pending) {
+				return fmt.Errorf("could not delete tenant rows; still blocked: %v", blocked)
+			}
+			pending = blocked
+		}
+		if err := tx.Unscoped().Delete(&models.Pharmacy{}, "id = ?", pharmacy.ID).Error; err != nil {
+			return err
+		}
+		log.Info("Wiped tenant", zap.String("slug", slug), zap.String("pharmacy_id", pharmacy.ID.String()), zap.Int64("rows", total+1))
+		return nil
+	})
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// client calls the v1 API with the bearer token from login.
+type client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+func newClient(baseURL string, timeout time.Duration) *client {
+	return &client{baseURL: baseURL, http: &http.Client{Timeout: timeout}}
+}
+
+// apiError is a non-2xx response; Message comes from the API's {code, message} body when there is one.
+type apiError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *apiError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("HTTP %d %s: %s", e.Status, e.Code, e.Message)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.Status, e.Message)
+}
+
+// do sends body as JSON to path (relative to the base URL) and decodes a 2xx response into out when set.
+func (c *client) do(method, path string, body, out any) error {
+	raw, _, err := c.raw(method, path, body)
+	if err != nil {
+		return err
+	}
+	if out == nil || len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("decode %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// raw sends the request and returns the body and content type of a 2xx response.
+func (c *client) raw(method, path string, body any) ([]byte, string, error) {
+	var rd io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, "", err
+		}
+		rd = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, rd)
+	if err != nil {
+		return nil, "", err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		e := &apiError{Status: resp.StatusCode}
+		var msg struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+			Detail  string `json:"detail"`
+		}
+		if json.Unmarshal(raw, &msg) == nil && (msg.Message != "" || msg.Detail != "") {
+			e.Code, e.Message = msg.Code, msg.Message
+			if e.Message == "" {
+				e.Message = msg.Detail
+			}
+		} else {
+			e.Message = http.StatusText(resp.StatusCode)
+		}
+		return nil, "", e
+	}
+	return raw, resp.Header.Get("Content-Type"), nil
+}
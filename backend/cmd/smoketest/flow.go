@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type stepStatus string
+
+const (
+	stepPass stepStatus = "pass"
+	stepFail stepStatus = "fail"
+	stepSkip stepStatus = "skip"
+)
+
+type stepResult struct {
+	Name       string     `json:"name"`
+	Status     stepStatus `json:"status"`
+	Detail     string     `json:"detail,omitempty"`
+	DurationMs int64      `json:"duration_ms"`
+}
+
+type report struct {
+	BaseURL    string        `json:"base_url"`
+	StartedAt  time.Time     `json:"started_at"`
+	DurationMs int64         `json:"duration_ms"`
+	Passed     bool          `json:"passed"`
+	Steps      []*stepResult `json:"steps"`
+}
+
+// flow is one smoke test run. Each step records what it created for the steps after it.
+type flow struct {
+	client   *client
+	email    string
+	password string
+	points   bool
+	keep     bool
+	wait     time.Duration
+	run      string // timestamp making the product SKU and customer phone unique per run
+
+	productID string
+	phone     string
+	orderID   string
+	total     float64
+}
+
+// Run executes the steps in order. After a failure the remaining steps are skipped, except the cleanup of
+// the product when one was created.
+func (f *flow) Run() *report {
+	r := &report{BaseURL: f.client.baseURL, StartedAt: time.Now(), Passed: true}
+	steps := []struct {
+		name string
+		fn   func() (string, error)
+		skip bool
+	}{
+		{"health", f.health, false},
+		{"login", f.login, false},
+		{"create product", f.createProduct, false},
+		{"place order", f.placeOrder, false},
+		{"accept order", f.acceptOrder, false},
+		{"pay", f.pay, false},
+		{"complete order", f.completeOrder, false},
+		{"verify points", f.verifyPoints, !f.points},
+		{"invoice", f.invoice, false},
+	}
+	failed := false
+	for _, s := range steps {
+		res := &stepResult{Name: s.name}
+		r.Steps = append(r.Steps, res)
+		if failed || s.skip {
+			res.Status = stepSkip
+			continue
+		}
+		f.exec(res, s.fn)
+		if res.Status == stepFail {
+			failed = true
+		}
+	}
+	if f.productID != "" && !f.keep {
+		res := &stepResult{Name: "delete product"}
+		r.Steps = append(r.Steps, res)
+		f.exec(res, f.deleteProduct)
+		failed = failed || res.Status == stepFail
+	}
+	r.Passed = !failed
+	r.DurationMs = time.Since(r.StartedAt).Milliseconds()
+	return r
+}
+
+func (f *flow) exec(res *stepResult, fn func() (string, error)) {
+	start := time.Now()
+	detail, err := fn()
+	res.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		res.Status, res.Detail = stepFail, err.Error()
+		return
+	}
+	res.Status, res.Detail = stepPass, detail
+}
+
+func (f *flow) health() (string, error) {
+	if err := f.client.do(http.MethodGet, "/health", nil, nil); err != nil {
+		return "", err
+	}
+	return "instance is up", nil
+}
+
+func (f *flow) login() (string, error) {
+	var out struct {
+		AccessToken string `json:"access_token"`
+		User        struct {
+			Role       string `json:"role"`
+			PharmacyID string `json:"pharmacy_id"`
+		} `json:"user"`
+	}
+	if err := f.client.do(http.MethodPost, "/api/v1/auth/login", map[string]string{"email": f.email, "password": f.password}, &out); err != nil {
+		return "", err
+	}
+	if out.AccessToken == "" {
+		return "", errors.New("no access token in the response")
+	}
+	f.client.token = out.AccessToken
+	return fmt.Sprintf("%s (%s) of pharmacy %s", f.email, out.User.Role, out.User.PharmacyID), nil
+}
+
+func (f *flow) createProduct() (string, error) {
+	var out struct {
+		ID  string `json:"id"`
+		SKU string `json:"sku"`
+	}
+	body := map[string]any{
+		"name":           "Smoke test product " + f.run,
+		"sku":            "SMOKE-" + f.run,
+		"unit_price":     100,
+		"stock_quantity": 10,
+		"unit":           "pcs",
+		"is_active":      true,
+	}
+	if err := f.client.do(http.MethodPost, "/api/v1/products", body, &out); err != nil {
+		return "", err
+	}
+	f.productID = out.ID
+	return "sku " + out.SKU, nil
+}
+
+func (f *flow) placeOrder() (string, error) {
+	// A new 10-digit phone per run, so the customer starts without points.
+	f.phone = "98" + f.run[len(f.run)-8:]
+	var out orderView
+	body := map[string]any{
+		"customer_name":  "Smoke Test " + f.run,
+		"customer_phone": f.phone,
+		"notes":          "smoketest " + f.run,
+		"items":          []map[string]any{{"product_id": f.productID, "quantity": 2, "unit_price": 100}},
+	}
+	if err := f.client.do(http.MethodPost, "/api/v1/orders", body, &out); err != nil {
+		return "", err
+	}
+	if out.Status != "pending" {
+		return "", fmt.Errorf("new order is %q, want pending", out.Status)
+	}
+	if out.TotalAmount <= 0 {
+		return "", fmt.Errorf("order total is %.2f", out.TotalAmount)
+	}
+	f.orderID, f.total = out.ID, out.TotalAmount
+	return fmt.Sprintf("%s total %.2f %s", out.OrderNumber, out.TotalAmount, out.Currency), nil
+}
+
+type orderView struct {
+	ID               string           `json:"id"`
+	OrderNumber      string           `json:"order_number"`
+	Status           string           `json:"status"`
+	TotalAmount      float64          `json:"total_amount"`
+	Currency         string           `json:"currency"`
+	CompletedAt      *time.Time       `json:"completed_at"`
+	ClinicalWarnings []map[string]any `json:"clinical_warnings"` // only counted
+}
+
+func (f *flow) acceptOrder() (string, error) {
+	var o orderView
+	if err := f.client.do(http.MethodGet, "/api/v1/orders/"+f.orderID, nil, &o); err != nil {
+		return "", err
+	}
+	detail := "confirmed"
+	if len(o.ClinicalWarnings) > 0 {
+		if err := f.client.do(http.MethodPost, "/api/v1/orders/"+f.orderID+"/acknowledge-warnings", nil, nil); err != nil {
+			return "", fmt.Errorf("acknowledge warnings: %w", err)
+		}
+		detail = fmt.Sprintf("confirmed after acknowledging %d warning(s)", len(o.ClinicalWarnings))
+	}
+	if err := f.client.do(http.MethodPost, "/api/v1/orders/"+f.orderID+"/accept", nil, &o); err != nil {
+		return "", err
+	}
+	if o.Status != "confirmed" {
+		return "", fmt.Errorf("accepted order is %q, want confirmed", o.Status)
+	}
+	return detail, nil
+}
+
+func (f *flow) pay() (string, error) {
+	var p struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+	body := map[string]any{"order_id": f.orderID, "amount": f.total, "method": "cash", "reference": "smoketest-" + f.run}
+	if err := f.client.do(http.MethodPost, "/api/v1/payments", body, &p); err != nil {
+		return "", err
+	}
+	if err := f.client.do(http.MethodPost, "/api/v1/payments/"+p.ID+"/complete", nil, nil); err != nil {
+		return "", fmt.Errorf("complete payment: %w", err)
+	}
+	if err := f.client.do(http.MethodGet, "/api/v1/payments/"+p.ID, nil, &p); err != nil {
+		return "", err
+	}
+	if p.Status != "completed" {
+		return "", fmt.Errorf("payment is %q, want completed", p.Status)
+	}
+	return fmt.Sprintf("cash %.2f", f.total), nil
+}
+
+func (f *flow) completeOrder() (string, error) {
+	var o orderView
+	for _, status := range []string{"processing", "ready", "completed"} {
+		if err := f.client.do(http.MethodPatch, "/api/v1/orders/"+f.orderID+"/status", map[string]string{"status": status}, &o); err != nil {
+			return "", fmt.Errorf("move to %s: %w", status, err)
+		}
+	}
+	if o.Status != "completed" || o.CompletedAt == nil {
+		return "", fmt.Errorf("order is %q, want completed with completed_at", o.Status)
+	}
+	return "processing, ready, completed", nil
+}
+
+// verifyPoints polls the customer until the purchase points arrive; they are credited asynchronously by the
+// order.completed subscriber.
+func (f *flow) verifyPoints() (string, error) {
+	deadline := time.Now().Add(f.wait)
+	path := "/api/v1/customers/by-phone?phone=" + url.QueryEscape(f.phone)
+	for {
+		var c struct {
+			ID            string `json:"id"`
+			PointsBalance int    `json:"points_balance"`
+		}
+		err := f.client.do(http.MethodGet, path, nil, &c)
+		var apiErr *apiError
+		if err != nil && !(errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound) {
+			return "", err
+		}
+		if err == nil && c.PointsBalance > 0 {
+			return fmt.Sprintf("customer %s has %d point(s)", c.ID, c.PointsBalance), nil
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return "", fmt.Errorf("customer %s not found after %s", f.phone, f.wait)
+			}
+			return "", fmt.Errorf("no points credited after %s (use --no-points if the pharmacy awards none)", f.wait)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+func (f *flow) invoice() (string, error) {
+	var inv struct {
+		ID            string `json:"id"`
+		InvoiceNumber string `json:"invoice_number"`
+	}
+	if err := f.client.do(http.MethodPost, "/api/v1/orders/"+f.orderID+"/invoices", nil, &inv); err != nil {
+		return "", err
+	}
+	if err := f.client.do(http.MethodPost, "/api/v1/invoices/"+inv.ID+"/issue", nil, nil); err != nil {
+		return "", fmt.Errorf("issue: %w", err)
+	}
+	var view struct {
+		Invoice struct {
+			Status string `json:"status"`
+		} `json:"invoice"`
+		Payments []struct {
+			Status string `json:"status"`
+		} `json:"payments"`
+	}
+	if err := f.client.do(http.MethodGet, "/api/v1/invoices/"+inv.ID, nil, &view); err != nil {
+		return "", err
+	}
+	if view.Invoice.Status != "issued" {
+		return "", fmt.Errorf("invoice is %q, want issued", view.Invoice.Status)
+	}
+	if len(view.Payments) == 0 {
+		return "", errors.New("invoice lists no payments")
+	}
+	pdf, contentType, err := f.client.raw(http.MethodGet, "/api/v1/invoices/"+inv.ID+"/pdf", nil)
+	if err != nil {
+		return "", fmt.Errorf("pdf: %w", err)
+	}
+	if !strings.Contains(contentType, "pdf") || !bytes.HasPrefix(pdf, []byte("%PDF")) {
+		return "", fmt.Errorf("pdf: got %q (%d bytes)", contentType, len(pdf))
+	}
+	return fmt.Sprintf("%s issued, pdf %d bytes", inv.InvoiceNumber, len(pdf)), nil
+}
+
+func (f *flow) deleteProduct() (string, error) {
+	if err := f.client.do(http.MethodDelete, "/api/v1/products/"+f.productID, nil, nil); err != nil {
+		return "", err
+	}
+	return "removed " + f.productID, nil
+}
@@ -28,17 +28,28 @@ type customClaims struct {
 	PharmacyID string `json:"pharmacy_id"`
 	Role       string `json:"role"`
 	TokenType  string `json:"token_type"`
+	// ImpersonatorID is set on access tokens a platform admin uses to act as the user.
+	ImpersonatorID string `json:"impersonator_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
 func (j *JWTAuthProvider) GenerateAccessToken(userID, pharmacyID uuid.UUID, role string) (string, error) {
+	return j.accessToken(userID, pharmacyID, role, "", j.cfg.JWT.AccessExpiry)
+}
+
+func (j *JWTAuthProvider) GenerateImpersonationToken(userID, pharmacyID uuid.UUID, role string, impersonatorID uuid.UUID, ttl time.Duration) (string, error) {
+	return j.accessToken(userID, pharmacyID, role, impersonatorID.String(), ttl)
+}
+
+func (j *JWTAuthProvider) accessToken(userID, pharmacyID uuid.UUID, role, impersonatorID string, ttl time.Duration) (string, error) {
 	claims := customClaims{
-		UserID:     userID.String(),
-		PharmacyID: pharmacyID.String(),
-		Role:       role,
-		TokenType:  "access",
+		UserID:         userID.String(),
+		PharmacyID:     pharmacyID.String(),
+		Role:           role,
+		TokenType:      "access",
+		ImpersonatorID: impersonatorID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.cfg.JWT.AccessExpiry)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    j.cfg.JWT.Issuer,
 			Subject:   userID.String(),
@@ -83,10 +94,16 @@ func (j *JWTAuthProvider) ValidateAccessToken(tokenString string) (*outbound.Tok
 	if claims.ExpiresAt != nil {
 		exp = claims.ExpiresAt.Time
 	}
-	return &outbound.TokenClaims{UserID: uid, PharmacyID: pid, Role: claims.Role, ExpiresAt: exp}, nil
+	out := &outbound.TokenClaims{UserID: uid, PharmacyID: pid, Role: claims.Role, ExpiresAt: exp}
+	if claims.ImpersonatorID != "" {
+		if id, err := uuid.Parse(claims.ImpersonatorID); err == nil {
+			out.ImpersonatorID = &id
+		}
+	}
+	return out, nil
 }
 
-func (j *JWTAuthProvider) ValidateRefreshToken(tokenString string) (uuid.UUID, error) {
+func (j *JWTAuthProvider) ValidateRefreshToken(tokenString string) (uuid.UUID, time.Time, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &customClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -94,13 +111,21 @@ func (j *JWTAuthProvider) ValidateRefreshToken(tokenString string) (uuid.UUID, e
 		return []byte(j.cfg.JWT.RefreshSecret), nil
 	})
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("invalid token: %w", err)
+		return uuid.Nil, time.Time{}, fmt.Errorf("invalid token: %w", err)
 	}
 	claims, ok := token.Claims.(*customClaims)
 	if !ok || !token.Valid || claims.TokenType != "refresh" {
-		return uuid.Nil, errors.New("invalid token claims")
+		return uuid.Nil, time.Time{}, errors.New("invalid token claims")
+	}
+	uid, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
 	}
-	return uuid.Parse(claims.UserID)
+	return uid, issuedAt, nil
 }
 
 type chatCustomerClaims struct {
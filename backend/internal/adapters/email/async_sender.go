@@ -0,0 +1,95 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"go.uber.org/zap"
+)
+
+const asyncSendTimeout = 30 * time.Second
+
+// AsyncSender queues emails and delivers them from background workers so a slow mail server never adds
+// latency to API requests. Send only fails when the queue is full or closed; delivery errors are logged.
+type AsyncSender struct {
+	inner  outbound.EmailSender
+	queue  chan *outbound.EmailMessage
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+	logger *zap.Logger
+}
+
+func NewAsyncSender(inner outbound.EmailSender, workers, queueSize int, logger *zap.Logger) *AsyncSender {
+	if workers <= 0 {
+		workers = 1
+	}
+	if queueSize <= 0 {
+		queueSize = 100
+	}
+	s := &AsyncSender{inner: inner, queue: make(chan *outbound.EmailMessage, queueSize), logger: logger}
+	for i := 0; i < workers; i++ {
+		s.wg.Add(1)
+		go s.work()
+	}
+	return s
+}
+
+func (s *AsyncSender) Send(ctx context.Context, msg *outbound.EmailMessage) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return errEmailQueueClosed
+	}
+	cp := *msg
+	cp.To = append([]string(nil), msg.To...)
+	select {
+	case s.queue <- &cp:
+		return nil
+	default:
+		s.logger.Warn("email queue full, dropping message", zap.String("to", strings.Join(msg.To, ",")), zap.String("subject", msg.Subject))
+		return errEmailQueueFull
+	}
+}
+
+// Close stops accepting messages and waits for queued ones to be delivered, or for ctx to expire.
+func (s *AsyncSender) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *AsyncSender) work() {
+	defer s.wg.Done()
+	for msg := range s.queue {
+		// Detached from the request context, which is usually cancelled by the time the message is sent.
+		ctx, cancel := context.WithTimeout(context.Background(), asyncSendTimeout)
+		if err := s.inner.Send(ctx, msg); err != nil {
+			s.logger.Warn("email delivery failed", zap.Error(err), zap.String("to", strings.Join(msg.To, ",")), zap.String("subject", msg.Subject))
+		}
+		cancel()
+	}
+}
+
+var (
+	errEmailQueueFull   = errors.New("email queue is full")
+	errEmailQueueClosed = errors.New("email queue is closed")
+)
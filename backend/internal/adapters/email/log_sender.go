@@ -0,0 +1,27 @@
+package email
+
+import (
+	"context"
+	"strings"
+
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"go.uber.org/zap"
+)
+
+// LogSender writes emails to the application log instead of delivering them. Used when no mail server is configured.
+type LogSender struct {
+	logger *zap.Logger
+}
+
+func NewLogSender(logger *zap.Logger) *LogSender {
+	return &LogSender{logger: logger}
+}
+
+func (s *LogSender) Send(ctx context.Context, msg *outbound.EmailMessage) error {
+	s.logger.Info("email (log sender)",
+		zap.String("to", strings.Join(msg.To, ",")),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.TextBody),
+	)
+	return nil
+}
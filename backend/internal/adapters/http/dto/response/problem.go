@@ -0,0 +1,50 @@
+package response
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIVersionKey is the gin context key holding the API version of the matched route group ("v1", "v2").
+const APIVersionKey = "api_version"
+
+// ProblemContentType is the media type of RFC 9457 problem details.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details body. /api/v2 returns errors in this shape; /api/v1 keeps ErrorResponse.
+// Code and Fields carry the same machine-readable values as ErrorResponse.
+type Problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Code     string            `json:"code"`
+	Fields   map[string]string `json:"fields,omitempty"`
+}
+
+// NewProblem converts an ErrorResponse to problem details for the given HTTP status.
+func NewProblem(status int, e ErrorResponse, instance string) Problem {
+	return Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   e.Message,
+		Instance: instance,
+		Code:     e.Code,
+		Fields:   e.Fields,
+	}
+}
+
+// WriteError writes an error in the format of the request's API version: problem+json on v2,
+// ErrorResponse JSON otherwise. It does not abort the handler chain.
+func WriteError(c *gin.Context, status int, e ErrorResponse) {
+	if c.GetString(APIVersionKey) == "v2" {
+		// gin's JSON renderer keeps a Content-Type that is already set.
+		c.Header("Content-Type", ProblemContentType)
+		c.JSON(status, NewProblem(status, e, c.Request.URL.Path))
+		return
+	}
+	c.JSON(status, e)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
@@ -21,37 +22,86 @@ func NewActivityHandler(activityService inbound.ActivityLogService, logger *zap.
 	return &ActivityHandler{activityService: activityService, logger: logger}
 }
 
+// activityQuery reads the user_id, action, entity_type and from/to (YYYY-MM-DD, inclusive) filters.
+func activityQuery(c *gin.Context) (inbound.ActivityLogQuery, bool) {
+	q := inbound.ActivityLogQuery{Action: c.Query("action"), EntityType: c.Query("entity_type")}
+	if v := c.Query("user_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid user_id"})
+			return q, false
+		}
+		q.UserID = &id
+	}
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return q, false
+	}
+	if !from.IsZero() {
+		q.From = &from
+	}
+	if !to.IsZero() {
+		q.To = &to
+	}
+	return q, true
+}
+
+// List returns activity newest first. Filters: user_id, action (substring), entity_type, from, to.
 func (h *ActivityHandler) List(c *gin.Context) {
-	pharmacyIDStr, ok := c.Get("pharmacy_id")
+	pharmacyID, ok := getPharmacyID(c)
 	if !ok {
 		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "pharmacy_id not set"})
 		return
 	}
-	pharmacyID, err := uuid.Parse(pharmacyIDStr.(string))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid pharmacy_id"})
+	q, ok := activityQuery(c)
+	if !ok {
 		return
 	}
-	limit := 50
-	offset := 0
+	q.Limit = 50
 	if v := c.Query("limit"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
-			limit = n
-			if limit > 100 {
-				limit = 100
+			q.Limit = n
+			if q.Limit > 100 {
+				q.Limit = 100
 			}
 		}
 	}
 	if v := c.Query("offset"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
-			offset = n
+			q.Offset = n
 		}
 	}
-	list, err := h.activityService.ListByPharmacy(c.Request.Context(), pharmacyID, limit, offset)
+	list, err := h.activityService.List(c.Request.Context(), pharmacyID, q)
 	if err != nil {
-		h.logger.Warn("activity list failed", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "failed to list activity"})
+		writeServiceError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, list)
 }
+
+// Export downloads the matching activity, oldest first, as CSV for compliance audits.
+func (h *ActivityHandler) Export(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "pharmacy_id not set"})
+		return
+	}
+	q, ok := activityQuery(c)
+	if !ok {
+		return
+	}
+	list, err := h.activityService.Export(c.Request.Context(), pharmacyID, q)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	rows := make([][]string, 0, len(list))
+	for _, a := range list {
+		var name, email string
+		if a.User != nil {
+			name, email = a.User.Name, a.User.Email
+		}
+		rows = append(rows, []string{a.CreatedAt.UTC().Format(time.RFC3339), a.UserID.String(), name, email, a.Action, a.Description, a.EntityType, a.EntityID, a.IPAddress, a.Details})
+	}
+	writeCSV(c, "activity-log.csv", []string{"time", "user_id", "user_name", "user_email", "action", "description", "entity_type", "entity_id", "ip_address", "details"}, rows)
+}
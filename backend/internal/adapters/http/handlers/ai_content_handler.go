@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type AIContentHandler struct {
+	aiService inbound.AIContentService
+	logger    *zap.Logger
+}
+
+func NewAIContentHandler(aiService inbound.AIContentService, logger *zap.Logger) *AIContentHandler {
+	return &AIContentHandler{aiService: aiService, logger: logger}
+}
+
+type generateBlogOutlineRequest struct {
+	Topic    string `json:"topic" binding:"required"`
+	Audience string `json:"audience"`
+}
+
+type reviewAIGenerationRequest struct {
+	Approve bool    `json:"approve"`
+	Output  *string `json:"output"` // optional edited text to keep instead of the raw generation
+}
+
+// GenerateProductDescription creates a draft description from structured fields (or an existing product_id).
+func (h *AIContentHandler) GenerateProductDescription(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	var req inbound.ProductDescriptionInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	g, err := h.aiService.GenerateProductDescription(c.Request.Context(), pharmacyID, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, g)
+}
+
+// GenerateBlogOutline creates a draft blog outline for a topic.
+func (h *AIContentHandler) GenerateBlogOutline(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	var req generateBlogOutlineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	g, err := h.aiService.GenerateBlogOutline(c.Request.Context(), pharmacyID, userID, req.Topic, req.Audience)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, g)
+}
+
+// List returns generations for the pharmacy. Optional ?kind=product_description|blog_outline&status=draft|approved|rejected.
+func (h *AIContentHandler) List(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	var kind, status *string
+	if k := c.Query("kind"); k != "" {
+		kind = &k
+	}
+	if s := c.Query("status"); s != "" {
+		status = &s
+	}
+	limit, offset := 20, 0
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			offset = n
+		}
+	}
+	list, total, err := h.aiService.ListGenerations(c.Request.Context(), pharmacyID, kind, status, limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total})
+}
+
+func (h *AIContentHandler) GetByID(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	g, err := h.aiService.GetGeneration(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, g)
+}
+
+// Review approves or rejects a draft generation.
+func (h *AIContentHandler) Review(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	var req reviewAIGenerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	g, err := h.aiService.Review(c.Request.Context(), pharmacyID, userID, id, req.Approve, req.Output)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, g)
+}
+
+// Usage returns this month's generation count and quota for the pharmacy.
+func (h *AIContentHandler) Usage(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	u, err := h.aiService.GetUsage(c.Request.Context(), pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/middleware"
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
 	"github.com/careplus/pharmacy-backend/pkg/errors"
@@ -15,11 +16,12 @@ import (
 
 type AnnouncementHandler struct {
 	svc    inbound.AnnouncementService
+	images inbound.PromoImageService
 	logger *zap.Logger
 }
 
-func NewAnnouncementHandler(svc inbound.AnnouncementService, logger *zap.Logger) *AnnouncementHandler {
-	return &AnnouncementHandler{svc: svc, logger: logger}
+func NewAnnouncementHandler(svc inbound.AnnouncementService, images inbound.PromoImageService, logger *zap.Logger) *AnnouncementHandler {
+	return &AnnouncementHandler{svc: svc, images: images, logger: logger}
 }
 
 func getPharmacyID(c *gin.Context) (uuid.UUID, bool) {
@@ -110,7 +112,7 @@ func (h *AnnouncementHandler) ListActiveForUser(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "user_id not set"})
 		return
 	}
-	list, err := h.svc.ListActiveForUser(c.Request.Context(), pharmacyID, userID)
+	list, err := h.svc.ListActiveForUser(c.Request.Context(), pharmacyID, userID, c.GetString("role"))
 	if err != nil {
 		h.logger.Warn("announcement list active for user failed", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "failed to list announcements"})
@@ -119,6 +121,14 @@ func (h *AnnouncementHandler) ListActiveForUser(c *gin.Context) {
 	if list == nil {
 		list = []*models.Announcement{}
 	}
+	// Users without a preferred language (or a variant in it) get the one asked for in Accept-Language.
+	if lang := c.GetString(middleware.LanguageKey); lang != "" {
+		for _, a := range list {
+			if a.Language == "" {
+				a.Localize(lang)
+			}
+		}
+	}
 	c.JSON(http.StatusOK, list)
 }
 
@@ -138,6 +148,8 @@ type createAnnouncementRequest struct {
 	EndAt          *string `json:"end_at"`
 	SortOrder      int     `json:"sort_order"`
 	IsActive       *bool   `json:"is_active"`
+	// Translations: other language variants keyed by code (e.g. "ne"); omitted on update = keep, {} = clear.
+	Translations models.Translations `json:"translations"`
 }
 
 // Create creates an announcement. Staff (pharmacist, admin, manager) only.
@@ -168,6 +180,7 @@ func (h *AnnouncementHandler) Create(c *gin.Context) {
 		ShowTerms:     body.ShowTerms,
 		TermsText:     body.TermsText,
 		SortOrder:     body.SortOrder,
+		Translations:  body.Translations,
 	}
 	if body.AllowSkipAll != nil {
 		a.AllowSkipAll = *body.AllowSkipAll
@@ -197,6 +210,10 @@ func (h *AnnouncementHandler) Create(c *gin.Context) {
 	}
 	created, err := h.svc.Create(c.Request.Context(), pharmacyID, a)
 	if err != nil {
+		if errors.GetAppError(err) != nil && errors.GetAppError(err).Code == errors.ErrCodeValidation {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: errors.GetAppError(err).Message})
+			return
+		}
 		h.logger.Warn("announcement create failed", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "failed to create announcement"})
 		return
@@ -250,6 +267,10 @@ func (h *AnnouncementHandler) Update(c *gin.Context) {
 		SortOrder:      body.SortOrder,
 		StartAt:        existing.StartAt,
 		EndAt:          existing.EndAt,
+		Translations:   body.Translations,
+	}
+	if body.Translations == nil {
+		a.Translations = existing.Translations
 	}
 	if body.Type == "" {
 		a.Type = existing.Type
@@ -311,6 +332,10 @@ func (h *AnnouncementHandler) Update(c *gin.Context) {
 			c.JSON(http.StatusNotFound, response.ErrorResponse{Code: errors.ErrCodeNotFound, Message: "announcement not found"})
 			return
 		}
+		if errors.GetAppError(err) != nil && errors.GetAppError(err).Code == errors.ErrCodeValidation {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: errors.GetAppError(err).Message})
+			return
+		}
 		h.logger.Warn("announcement update failed", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "failed to update announcement"})
 		return
@@ -318,6 +343,32 @@ func (h *AnnouncementHandler) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, updated)
 }
 
+// UploadImage sets the announcement image from a multipart upload (field "file"), generating banner and
+// thumbnail renditions.
+func (h *AnnouncementHandler) UploadImage(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "pharmacy_id not set"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	f, ok := formImage(c)
+	if !ok {
+		return
+	}
+	defer f.Close()
+	a, err := h.images.SetAnnouncementImage(c.Request.Context(), pharmacyID, id, f)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, a)
+}
+
 // Delete deletes an announcement. Staff only.
 func (h *AnnouncementHandler) Delete(c *gin.Context) {
 	pharmacyID, ok := getPharmacyID(c)
@@ -364,7 +415,12 @@ func (h *AnnouncementHandler) Acknowledge(c *gin.Context) {
 	}
 	var body ackRequest
 	_ = c.ShouldBindJSON(&body)
-	if err := h.svc.Acknowledge(c.Request.Context(), userID, id, body.SkipAll); err != nil {
+	if err := h.svc.Acknowledge(c.Request.Context(), userID, id, c.GetString("role"), body.SkipAll); err != nil {
+		if errors.IsAppError(err) {
+			// e.g. a training quiz must be passed first
+			writeServiceError(c, err)
+			return
+		}
 		h.logger.Warn("announcement ack failed", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "failed to acknowledge"})
 		return
@@ -380,7 +436,7 @@ func (h *AnnouncementHandler) SkipAll(c *gin.Context) {
 		return
 	}
 	// Use nil UUID to indicate skip-all; service expects announcementID for single ack. So we need to call Acknowledge with skipAll=true and a dummy ID or change service. Service Acknowledge(userID, announcementID, skipAll): if skipAll, announcementID is not used and we store nil. So we can pass uuid.Nil for announcementID when skipAll is true.
-	if err := h.svc.Acknowledge(c.Request.Context(), userID, uuid.Nil, true); err != nil {
+	if err := h.svc.Acknowledge(c.Request.Context(), userID, uuid.Nil, c.GetString("role"), true); err != nil {
 		h.logger.Warn("announcement skip-all failed", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "failed to skip all"})
 		return
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type APIUsageHandler struct {
+	usageService inbound.APIUsageService
+	logger       *zap.Logger
+}
+
+func NewAPIUsageHandler(usageService inbound.APIUsageService, logger *zap.Logger) *APIUsageHandler {
+	return &APIUsageHandler{usageService: usageService, logger: logger}
+}
+
+// Report returns the pharmacy's API usage: requests, error rates and latency per day and the busiest endpoints
+// (query: from, to as YYYY-MM-DD; default the last 30 days). Counts reach the report within about a minute.
+func (h *APIUsageHandler) Report(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	to := time.Now()
+	from := to.AddDate(0, 0, -29)
+	if v := c.Query("from"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "from must be YYYY-MM-DD"})
+			return
+		}
+		from = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "to must be YYYY-MM-DD"})
+			return
+		}
+		to = t
+	}
+	report, err := h.usageService.Report(c.Request.Context(), pharmacyID, from, to)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/middleware"
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/config"
+	"github.com/gin-gonic/gin"
+)
+
+// APIVersionHandler reports supported API versions and per-version usage, to track client migration off /api/v1.
+type APIVersionHandler struct {
+	cfg     config.APIConfig
+	metrics *middleware.VersionMetrics
+}
+
+func NewAPIVersionHandler(cfg config.APIConfig, metrics *middleware.VersionMetrics) *APIVersionHandler {
+	return &APIVersionHandler{cfg: cfg, metrics: metrics}
+}
+
+type apiVersionInfo struct {
+	Version      string     `json:"version"`
+	BasePath     string     `json:"base_path"`
+	Status       string     `json:"status"` // "current" or "deprecated"
+	DeprecatedAt *time.Time `json:"deprecated_at,omitempty"`
+	SunsetAt     *time.Time `json:"sunset_at,omitempty"`
+}
+
+// Versions lists the API versions and their lifecycle dates (no auth).
+func (h *APIVersionHandler) Versions(c *gin.Context) {
+	v1 := apiVersionInfo{Version: "v1", BasePath: "/api/v1", Status: "current"}
+	if !h.cfg.V1DeprecatedAt.IsZero() {
+		deprecatedAt := h.cfg.V1DeprecatedAt
+		v1.Status, v1.DeprecatedAt = "deprecated", &deprecatedAt
+		if !h.cfg.V1SunsetAt.IsZero() {
+			sunsetAt := h.cfg.V1SunsetAt
+			v1.SunsetAt = &sunsetAt
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"items": []apiVersionInfo{v1, {Version: "v2", BasePath: "/api/v2", Status: "current"}}})
+}
+
+// Usage returns request counters per version and route since this instance started, with totals per version.
+func (h *APIVersionHandler) Usage(c *gin.Context) {
+	items := h.metrics.Snapshot()
+	totals := map[string]int64{}
+	for _, u := range items {
+		totals[u.Version] += u.Requests
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "totals": totals})
+}
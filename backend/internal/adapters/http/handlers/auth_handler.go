@@ -5,7 +5,9 @@ import (
 	"net/http"
 
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 	"github.com/careplus/pharmacy-backend/pkg/errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -15,11 +17,12 @@ import (
 type AuthHandler struct {
 	authService        inbound.AuthService
 	activityLogService inbound.ActivityLogService
+	files              outbound.PrivateFileStorage // signs the user's CV link
 	logger             *zap.Logger
 }
 
-func NewAuthHandler(authService inbound.AuthService, activityLogService inbound.ActivityLogService, logger *zap.Logger) *AuthHandler {
-	return &AuthHandler{authService: authService, activityLogService: activityLogService, logger: logger}
+func NewAuthHandler(authService inbound.AuthService, activityLogService inbound.ActivityLogService, files outbound.PrivateFileStorage, logger *zap.Logger) *AuthHandler {
+	return &AuthHandler{authService: authService, activityLogService: activityLogService, files: files, logger: logger}
 }
 
 type loginRequest struct {
@@ -39,6 +42,30 @@ type refreshRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
+type forgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type verifyLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+type changeExpiredPasswordRequest struct {
+	Email           string `json:"email" binding:"required,email"`
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+type passwordPolicyRequest struct {
+	Password string `json:"password"`
+}
+
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req loginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -46,31 +73,92 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 	accessToken, refreshToken, user, err := h.authService.Login(c.Request.Context(), req.Email, req.Password)
+	h.writeLogin(c, "POST /auth/login", accessToken, refreshToken, user, err)
+}
+
+// VerifyLogin completes a two-factor login with the emailed code.
+func (h *AuthHandler) VerifyLogin(c *gin.Context) {
+	var req verifyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	accessToken, refreshToken, user, err := h.authService.VerifyLoginCode(c.Request.Context(), req.ChallengeToken, req.Code)
+	h.writeLogin(c, "POST /auth/login/verify", accessToken, refreshToken, user, err)
+}
+
+// ChangeExpiredPassword sets a new password for an account whose login failed with PASSWORD_EXPIRED and logs in.
+func (h *AuthHandler) ChangeExpiredPassword(c *gin.Context) {
+	var req changeExpiredPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	accessToken, refreshToken, user, err := h.authService.ChangeExpiredPassword(c.Request.Context(), req.Email, req.CurrentPassword, req.NewPassword)
+	h.writeLogin(c, "POST /auth/change-expired-password", accessToken, refreshToken, user, err)
+}
+
+// writeLogin answers a login step: the tokens, or for two-factor roles the challenge to send the emailed code
+// back with (200, two_factor_required), or the error.
+func (h *AuthHandler) writeLogin(c *gin.Context, action, accessToken, refreshToken string, user *models.User, err error) {
 	if err != nil {
-		if errors.IsAppError(err) && errors.GetAppError(err).Code == errors.ErrCodeInvalidCredentials {
+		appErr := errors.GetAppError(err)
+		switch {
+		case appErr == nil:
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "Login failed"})
+		case appErr.Code == errors.ErrCodeTwoFactorRequired:
+			c.JSON(http.StatusOK, gin.H{
+				"two_factor_required": true,
+				"challenge_token":     appErr.Details["challenge_token"],
+				"expires_at":          appErr.Details["expires_at"],
+				"message":             appErr.Message,
+			})
+		case appErr.Code == errors.ErrCodeInvalidCredentials:
 			c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeInvalidCredentials, Message: "Invalid email or password"})
-			return
-		}
-		if errors.IsAppError(err) && errors.GetAppError(err).Code == errors.ErrCodeForbidden {
-			c.JSON(http.StatusForbidden, response.ErrorResponse{Code: errors.ErrCodeForbidden, Message: err.Error()})
-			return
+		case appErr.Code == errors.ErrCodePasswordExpired:
+			c.JSON(http.StatusForbidden, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+		case appErr.Code == errors.ErrCodeInternal:
+			h.logger.Error("login failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "Login failed"})
+		default:
+			writeServiceError(c, err)
 		}
-		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "Login failed"})
 		return
 	}
-	// Audit log: login (no middleware on this route)
+	// Audit log: login (no middleware on these routes)
 	if h.activityLogService != nil && user != nil {
 		details, _ := json.Marshal(map[string]string{"email": user.Email})
-		_ = h.activityLogService.Create(c.Request.Context(), user.PharmacyID, user.ID, "POST /auth/login", "User logged in", "user", user.ID.String(), string(details), c.ClientIP())
+		_ = h.activityLogService.Create(c.Request.Context(), user.PharmacyID, user.ID, action, "User logged in", "user", user.ID.String(), string(details), c.ClientIP())
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"access_token":  accessToken,
 		"refresh_token": refreshToken,
 		"expires_in":    900,
-		"user":          user,
+		"user":          signedUser(c.Request.Context(), h.files, h.logger, user),
 	})
 }
 
+// PasswordPolicy returns the pharmacy's password requirements, checked against the password when one is sent,
+// so sign-up and password forms can show them as the user types.
+func (h *AuthHandler) PasswordPolicy(c *gin.Context) {
+	pharmacyID, err := uuid.Parse(c.Param("pharmacyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid pharmacy id"})
+		return
+	}
+	var req passwordPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	check, err := h.authService.PasswordPolicy(c.Request.Context(), pharmacyID, req.Password)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, check)
+}
+
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req registerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -97,6 +185,10 @@ func (h *AuthHandler) Register(c *gin.Context) {
 				c.JSON(http.StatusNotFound, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
 				return
 			}
+			if appErr.Code == errors.ErrCodeValidation {
+				c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+				return
+			}
 		}
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "Registration failed"})
 		return
@@ -118,6 +210,32 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"access_token": accessToken, "expires_in": 900})
 }
 
+// ForgotPassword always answers 200 so callers cannot probe which emails are registered.
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req forgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	if err := h.authService.ForgotPassword(c.Request.Context(), req.Email); err != nil {
+		h.logger.Error("forgot password failed", zap.Error(err))
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "If the email is registered, a password reset link has been sent"})
+}
+
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req resetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	if err := h.authService.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset"})
+}
+
 func (h *AuthHandler) Logout(c *gin.Context) {
 	userIDStr, _ := c.Get("user_id")
 	pharmacyIDStr, _ := c.Get("pharmacy_id")
@@ -137,13 +255,14 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 		c.JSON(http.StatusNotFound, response.ErrorResponse{Code: errors.ErrCodeNotFound, Message: "User not found"})
 		return
 	}
-	c.JSON(http.StatusOK, user)
+	c.JSON(http.StatusOK, signedUser(c.Request.Context(), h.files, h.logger, user))
 }
 
 type updateProfileRequest struct {
-	Name     string  `json:"name"`
-	Phone    *string `json:"phone"`
-	PhotoURL *string `json:"photo_url"`
+	Name              string  `json:"name"`
+	Phone             *string `json:"phone"`
+	PhotoURL          *string `json:"photo_url"`
+	PreferredLanguage *string `json:"preferred_language"` // "en", "ne"; "" = pharmacy default
 }
 
 type changePasswordRequest struct {
@@ -175,6 +294,10 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 				c.JSON(http.StatusForbidden, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
 				return
 			}
+			if appErr.Code == errors.ErrCodeValidation {
+				c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+				return
+			}
 		}
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "Failed to change password"})
 		return
@@ -190,7 +313,7 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
 		return
 	}
-	user, err := h.authService.UpdateProfile(c.Request.Context(), userID, req.Name, req.Phone, req.PhotoURL)
+	user, err := h.authService.UpdateProfile(c.Request.Context(), userID, req.Name, req.Phone, req.PhotoURL, req.PreferredLanguage)
 	if err != nil {
 		if errors.IsAppError(err) {
 			appErr := errors.GetAppError(err)
@@ -202,9 +325,13 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 				c.JSON(http.StatusForbidden, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
 				return
 			}
+			if appErr.Code == errors.ErrCodeValidation {
+				c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+				return
+			}
 		}
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "Failed to update profile"})
 		return
 	}
-	c.JSON(http.StatusOK, user)
+	c.JSON(http.StatusOK, signedUser(c.Request.Context(), h.files, h.logger, user))
 }
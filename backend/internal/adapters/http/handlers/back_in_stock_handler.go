@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type BackInStockHandler struct {
+	backInStockService inbound.BackInStockService
+	logger             *zap.Logger
+}
+
+func NewBackInStockHandler(backInStockService inbound.BackInStockService, logger *zap.Logger) *BackInStockHandler {
+	return &BackInStockHandler{backInStockService: backInStockService, logger: logger}
+}
+
+// Subscribe asks to be notified when the out-of-stock product is back (body: phone and/or email; optional for a
+// signed-in user of the product's pharmacy).
+func (h *BackInStockHandler) Subscribe(c *gin.Context) {
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid product id"})
+		return
+	}
+	var in inbound.BackInStockInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&in); err != nil {
+			c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+			return
+		}
+	}
+	if userID, ok := getUserID(c); ok {
+		pharmacyID, _ := getPharmacyID(c)
+		in.UserID, in.PharmacyID = &userID, &pharmacyID
+	}
+	sub, err := h.backInStockService.Subscribe(c.Request.Context(), productID, in)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": sub.ID, "product_id": sub.ProductID, "status": sub.Status, "created_at": sub.CreatedAt})
+}
+
+// Subscribers lists who asked to be notified about the product (?status=pending|notified).
+func (h *BackInStockHandler) Subscribers(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "pharmacy_id not set"})
+		return
+	}
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid product id"})
+		return
+	}
+	list, err := h.backInStockService.Subscribers(c.Request.Context(), pharmacyID, productID, c.Query("status"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
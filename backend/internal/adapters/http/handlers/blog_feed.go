@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// blogFeedMaxAge is how long feed readers and proxies may cache the feed.
+const blogFeedMaxAge = 15 * time.Minute
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Description string   `xml:"description"`
+	Categories  []string `xml:"category"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Summary    string         `xml:"summary,omitempty"`
+	Author     *atomAuthor    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// Feed serves the pharmacy's published posts as RSS 2.0, or Atom with ?format=atom (no auth). Responses carry
+// Cache-Control, ETag and Last-Modified; conditional requests get 304 Not Modified.
+func (h *BlogHandler) Feed(c *gin.Context) {
+	pharmacyID, err := uuid.Parse(c.Param("pharmacyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid pharmacy id"})
+		return
+	}
+	atom := c.Query("format") == "atom"
+	feed, err := h.blogService.Feed(c.Request.Context(), pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	updated := feed.Updated.UTC().Truncate(time.Second)
+	etag := fmt.Sprintf(`W/"%x-%d-%t"`, updated.Unix(), len(feed.Items), atom)
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(blogFeedMaxAge.Seconds())))
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", updated.Format(http.TimeFormat))
+	if feedNotModified(c.Request, etag, updated) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	var body interface{}
+	contentType := "application/rss+xml; charset=utf-8"
+	if atom {
+		body, contentType = atomFromFeed(feed), "application/atom+xml; charset=utf-8"
+	} else {
+		body = rssFromFeed(feed)
+	}
+	out, err := xml.MarshalIndent(body, "", "  ")
+	if err != nil {
+		writeServiceError(c, errors.ErrInternal("failed to render feed", err))
+		return
+	}
+	c.Data(http.StatusOK, contentType, append([]byte(xml.Header), out...))
+}
+
+// feedNotModified reports whether the client's cached copy is current. If-None-Match wins over If-Modified-Since.
+func feedNotModified(r *http.Request, etag string, updated time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, t := range strings.Split(inm, ",") {
+			if t = strings.TrimSpace(t); t == etag || t == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		if t, err := http.ParseTime(ims); err == nil && !updated.After(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func rssFromFeed(feed *inbound.BlogFeed) rssFeed {
+	ch := rssChannel{
+		Title:         feed.Title,
+		Link:          feed.Link,
+		Description:   feed.Title + " blog",
+		LastBuildDate: feed.Updated.UTC().Format(time.RFC1123Z),
+		AtomLink:      atomLink{Href: feed.SelfLink, Rel: "self", Type: "application/rss+xml"},
+	}
+	for _, it := range feed.Items {
+		item := rssItem{
+			Title:       it.Post.Title,
+			Link:        it.Link,
+			GUID:        rssGUID{IsPermaLink: "false", Value: "urn:uuid:" + it.Post.ID.String()},
+			PubDate:     it.Published.UTC().Format(time.RFC1123Z),
+			Description: feedSummary(it),
+		}
+		for _, t := range it.Post.Tags {
+			item.Categories = append(item.Categories, t.Name)
+		}
+		ch.Items = append(ch.Items, item)
+	}
+	return rssFeed{Version: "2.0", AtomNS: "http://www.w3.org/2005/Atom", Channel: ch}
+}
+
+func atomFromFeed(feed *inbound.BlogFeed) atomFeed {
+	out := atomFeed{
+		Title:   feed.Title,
+		ID:      "urn:uuid:" + feed.PharmacyID.String(),
+		Updated: feed.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: feed.Link, Rel: "alternate", Type: "text/html"},
+			{Href: feed.SelfLink + "?format=atom", Rel: "self", Type: "application/atom+xml"},
+		},
+	}
+	for _, it := range feed.Items {
+		e := atomEntry{
+			Title:     it.Post.Title,
+			ID:        "urn:uuid:" + it.Post.ID.String(),
+			Link:      atomLink{Href: it.Link, Rel: "alternate", Type: "text/html"},
+			Published: it.Published.UTC().Format(time.RFC3339),
+			Updated:   it.Post.UpdatedAt.UTC().Format(time.RFC3339),
+			Summary:   feedSummary(it),
+		}
+		if it.Post.Author != nil {
+			e.Author = &atomAuthor{Name: it.Post.Author.Name}
+		}
+		for _, t := range it.Post.Tags {
+			e.Categories = append(e.Categories, atomCategory{Term: t.Name})
+		}
+		out.Entries = append(out.Entries, e)
+	}
+	return out
+}
+
+// feedSummary is the post's excerpt, or the start of its body when it has none.
+func feedSummary(it *inbound.BlogFeedItem) string {
+	if s := strings.TrimSpace(it.Post.Excerpt); s != "" {
+		return s
+	}
+	body := []rune(strings.TrimSpace(it.Post.Body))
+	if len(body) > 280 {
+		return strings.TrimSpace(string(body[:280])) + "…"
+	}
+	return string(body)
+}
@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/middleware"
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
 	"github.com/careplus/pharmacy-backend/pkg/errors"
@@ -119,7 +121,99 @@ func (h *BlogHandler) DeleteCategory(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
 }
 
-// ListPostsPublic returns published blog posts for a pharmacy (no auth; for public store).
+// ListTags returns the pharmacy's blog tags with the number of posts carrying each.
+func (h *BlogHandler) ListTags(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	list, err := h.blogService.ListTags(c.Request.Context(), pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// TagCloudPublic returns tags used by published posts, most used first (no auth; optional limit, default 30).
+func (h *BlogHandler) TagCloudPublic(c *gin.Context) {
+	pharmacyID, err := uuid.Parse(c.Param("pharmacyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid pharmacy id"})
+		return
+	}
+	limit := 0
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok {
+			limit = n
+		}
+	}
+	list, err := h.blogService.TagCloud(c.Request.Context(), pharmacyID, limit)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tags": list})
+}
+
+type blogTagRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// CreateTag creates a blog tag (staff).
+func (h *BlogHandler) CreateTag(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	var body blogTagRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	tag, err := h.blogService.CreateTag(c.Request.Context(), pharmacyID, body.Name)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, tag)
+}
+
+// UpdateTag renames a blog tag (staff).
+func (h *BlogHandler) UpdateTag(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	var body blogTagRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	tag, err := h.blogService.UpdateTag(c.Request.Context(), pharmacyID, id, body.Name)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTag deletes a blog tag and removes it from its posts (staff).
+func (h *BlogHandler) DeleteTag(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	if err := h.blogService.DeleteTag(c.Request.Context(), pharmacyID, id); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
+}
+
+// ListPostsPublic returns published blog posts for a pharmacy (no auth; for public store; optional category_id, tag).
 func (h *BlogHandler) ListPostsPublic(c *gin.Context) {
 	pharmacyIDStr := c.Param("pharmacyId")
 	if pharmacyIDStr == "" {
@@ -149,7 +243,7 @@ func (h *BlogHandler) ListPostsPublic(c *gin.Context) {
 			categoryID = &cid
 		}
 	}
-	list, total, err := h.blogService.ListPosts(c.Request.Context(), pharmacyID, &status, categoryID, limit, offset)
+	list, total, err := h.blogService.ListPosts(c.Request.Context(), pharmacyID, &status, categoryID, c.Query("tag"), limit, offset)
 	if err != nil {
 		writeServiceError(c, err)
 		return
@@ -211,7 +305,7 @@ func (h *BlogHandler) ListPosts(c *gin.Context) {
 			offset = n
 		}
 	}
-	list, total, err := h.blogService.ListPosts(c.Request.Context(), pharmacyID, statusPtr, categoryID, limit, offset)
+	list, total, err := h.blogService.ListPosts(c.Request.Context(), pharmacyID, statusPtr, categoryID, c.Query("tag"), limit, offset)
 	if err != nil {
 		writeServiceError(c, err)
 		return
@@ -255,6 +349,7 @@ func (h *BlogHandler) CreatePost(c *gin.Context) {
 		CategoryID *uuid.UUID                  `json:"category_id"`
 		Status     string                      `json:"status"`
 		Media      []inbound.BlogPostMediaInput `json:"media"`
+		Tags       []string                    `json:"tags"`
 	}
 	if err := c.ShouldBindJSON(&body); err != nil {
 		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
@@ -263,7 +358,7 @@ func (h *BlogHandler) CreatePost(c *gin.Context) {
 	if body.Status != models.BlogPostStatusDraft && body.Status != models.BlogPostStatusPendingApproval {
 		body.Status = models.BlogPostStatusDraft
 	}
-	post, err := h.blogService.CreatePost(c.Request.Context(), pharmacyID, authorID, body.Title, body.Excerpt, body.Body, body.CategoryID, body.Status, body.Media)
+	post, err := h.blogService.CreatePost(c.Request.Context(), pharmacyID, authorID, body.Title, body.Excerpt, body.Body, body.CategoryID, body.Status, body.Media, body.Tags)
 	if err != nil {
 		writeServiceError(c, err)
 		return
@@ -311,12 +406,13 @@ func (h *BlogHandler) UpdatePost(c *gin.Context) {
 		CategoryID *uuid.UUID                  `json:"category_id"`
 		Status     *string                     `json:"status"`
 		Media      []inbound.BlogPostMediaInput `json:"media"`
+		Tags       []string                    `json:"tags"` // omitted keeps the tags, [] clears them
 	}
 	if err := c.ShouldBindJSON(&body); err != nil {
 		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
 		return
 	}
-	post, err := h.blogService.UpdatePost(c.Request.Context(), pharmacyID, userID, postID, body.Title, body.Excerpt, body.Body, body.CategoryID, body.Status, body.Media)
+	post, err := h.blogService.UpdatePost(c.Request.Context(), pharmacyID, userID, postID, body.Title, body.Excerpt, body.Body, body.CategoryID, body.Status, body.Media, body.Tags)
 	if err != nil {
 		writeServiceError(c, err)
 		return
@@ -342,7 +438,65 @@ func (h *BlogHandler) DeletePost(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
 }
 
-// ApprovePost sets post status to published (manager/admin).
+// ListRevisions returns a post's revisions, newest first (author, or blog.approve for any post).
+func (h *BlogHandler) ListRevisions(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	limit, offset := 20, 0
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			offset = n
+		}
+	}
+	list, total, err := h.blogService.ListRevisions(c.Request.Context(), pharmacyID, userID, postID, middleware.HasPermission(c, models.PermBlogApprove), limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total})
+}
+
+// RestoreRevision puts an earlier revision's title, excerpt and body back (author on draft/pending, or blog.approve).
+func (h *BlogHandler) RestoreRevision(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	revID, err := uuid.Parse(c.Param("revId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid revision id"})
+		return
+	}
+	post, err := h.blogService.RestoreRevision(c.Request.Context(), pharmacyID, userID, postID, revID, middleware.HasPermission(c, models.PermBlogApprove))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, post)
+}
+
+type approvePostRequest struct {
+	PublishAt *time.Time `json:"publish_at"` // RFC 3339; omitted or past publishes now
+}
+
+// ApprovePost publishes a pending or scheduled post now, or schedules it (body: optional publish_at) (manager/admin).
 func (h *BlogHandler) ApprovePost(c *gin.Context) {
 	pharmacyIDStr, _ := c.Get("pharmacy_id")
 	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
@@ -351,7 +505,14 @@ func (h *BlogHandler) ApprovePost(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
 		return
 	}
-	post, err := h.blogService.ApprovePost(c.Request.Context(), pharmacyID, postID)
+	var req approvePostRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+			return
+		}
+	}
+	post, err := h.blogService.ApprovePost(c.Request.Context(), pharmacyID, postID, req.PublishAt)
 	if err != nil {
 		writeServiceError(c, err)
 		return
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type BranchHandler struct {
+	branchService inbound.BranchService
+	logger        *zap.Logger
+}
+
+func NewBranchHandler(branchService inbound.BranchService, logger *zap.Logger) *BranchHandler {
+	return &BranchHandler{branchService: branchService, logger: logger}
+}
+
+// branchIDQuery reads the optional ?branch_id= filter shared by the stock, order and report lists; an invalid id is ignored.
+func branchIDQuery(c *gin.Context) *uuid.UUID {
+	if s := c.Query("branch_id"); s != "" {
+		if id, err := uuid.Parse(s); err == nil {
+			return &id
+		}
+	}
+	return nil
+}
+
+// scope reads the pharmacy from the token and, when param is set, that path id. It writes the error itself.
+func (h *BranchHandler) scope(c *gin.Context, param string) (pharmacyID, id uuid.UUID, ok bool) {
+	pharmacyID, ok = getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "pharmacy_id not set"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	if param != "" {
+		parsed, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid " + param})
+			return uuid.Nil, uuid.Nil, false
+		}
+		id = parsed
+	}
+	return pharmacyID, id, true
+}
+
+// List returns the pharmacy's branches.
+func (h *BranchHandler) List(c *gin.Context) {
+	pharmacyID, _, ok := h.scope(c, "")
+	if !ok {
+		return
+	}
+	list, err := h.branchService.List(c.Request.Context(), pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": len(list)})
+}
+
+// Create adds a branch. The pharmacy's first branch becomes the default and takes over its existing stock.
+func (h *BranchHandler) Create(c *gin.Context) {
+	pharmacyID, _, ok := h.scope(c, "")
+	if !ok {
+		return
+	}
+	var input inbound.BranchInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	b, err := h.branchService.Create(c.Request.Context(), pharmacyID, input)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, b)
+}
+
+func (h *BranchHandler) Get(c *gin.Context) {
+	pharmacyID, id, ok := h.scope(c, "id")
+	if !ok {
+		return
+	}
+	b, err := h.branchService.Get(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, b)
+}
+
+func (h *BranchHandler) Update(c *gin.Context) {
+	pharmacyID, id, ok := h.scope(c, "id")
+	if !ok {
+		return
+	}
+	var input inbound.BranchInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	b, err := h.branchService.Update(c.Request.Context(), pharmacyID, id, input)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, b)
+}
+
+// Delete removes an empty branch and unassigns its team members.
+func (h *BranchHandler) Delete(c *gin.Context) {
+	pharmacyID, id, ok := h.scope(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.branchService.Delete(c.Request.Context(), pharmacyID, id); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Stock returns per-product batch stock at the branch.
+func (h *BranchHandler) Stock(c *gin.Context) {
+	pharmacyID, id, ok := h.scope(c, "id")
+	if !ok {
+		return
+	}
+	rows, err := h.branchService.Stock(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": rows, "total": len(rows)})
+}
+
+type assignBranchRequest struct {
+	BranchID *uuid.UUID `json:"branch_id"` // null unassigns
+}
+
+// AssignUser sets or clears a team member's branch. Body: {"branch_id": "<uuid>"|null}.
+func (h *BranchHandler) AssignUser(c *gin.Context) {
+	pharmacyID, userID, ok := h.scope(c, "userId")
+	if !ok {
+		return
+	}
+	var req assignBranchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	u, err := h.branchService.AssignUser(c.Request.Context(), pharmacyID, userID, req.BranchID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+// Transfer moves stock from one branch to another right away.
+func (h *BranchHandler) Transfer(c *gin.Context) {
+	pharmacyID, _, ok := h.scope(c, "")
+	if !ok {
+		return
+	}
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "user_id not set"})
+		return
+	}
+	var input inbound.BranchTransferInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	t, err := h.branchService.Transfer(c.Request.Context(), pharmacyID, userID, input)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, t)
+}
+
+// ListTransfers returns transfers newest first (query: branch_id for either side, limit, offset).
+func (h *BranchHandler) ListTransfers(c *gin.Context) {
+	pharmacyID, _, ok := h.scope(c, "")
+	if !ok {
+		return
+	}
+	limit, offset := 20, 0
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			offset = n
+		}
+	}
+	list, total, err := h.branchService.ListTransfers(c.Request.Context(), pharmacyID, branchIDQuery(c), limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total, "limit": limit, "offset": offset})
+}
+
+func (h *BranchHandler) GetTransfer(c *gin.Context) {
+	pharmacyID, id, ok := h.scope(c, "id")
+	if !ok {
+		return
+	}
+	t, err := h.branchService.GetTransfer(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
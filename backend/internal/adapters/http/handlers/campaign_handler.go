@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type CampaignHandler struct {
+	campaignService inbound.CampaignService
+	logger          *zap.Logger
+}
+
+func NewCampaignHandler(campaignService inbound.CampaignService, logger *zap.Logger) *CampaignHandler {
+	return &CampaignHandler{campaignService: campaignService, logger: logger}
+}
+
+// caller reads the pharmacy and user from the token and, when withID is set, the :id param. It writes the error itself.
+func (h *CampaignHandler) caller(c *gin.Context, withID bool) (pharmacyID, userID, id uuid.UUID, ok bool) {
+	pharmacyID, ok1 := getPharmacyID(c)
+	userID, ok2 := getUserID(c)
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	if withID {
+		parsed, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+			return uuid.Nil, uuid.Nil, uuid.Nil, false
+		}
+		id = parsed
+	}
+	return pharmacyID, userID, id, true
+}
+
+func pageParams(c *gin.Context) (limit, offset int) {
+	limit = 20
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			offset = n
+		}
+	}
+	return limit, offset
+}
+
+func (h *CampaignHandler) CreateSegment(c *gin.Context) {
+	pharmacyID, userID, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	var req inbound.SegmentInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	seg, err := h.campaignService.CreateSegment(c.Request.Context(), pharmacyID, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, seg)
+}
+
+func (h *CampaignHandler) ListSegments(c *gin.Context) {
+	pharmacyID, _, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	list, err := h.campaignService.ListSegments(c.Request.Context(), pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *CampaignHandler) UpdateSegment(c *gin.Context) {
+	pharmacyID, _, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	var req inbound.SegmentInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	seg, err := h.campaignService.UpdateSegment(c.Request.Context(), pharmacyID, id, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, seg)
+}
+
+func (h *CampaignHandler) DeleteSegment(c *gin.Context) {
+	pharmacyID, _, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	if err := h.campaignService.DeleteSegment(c.Request.Context(), pharmacyID, id); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// PreviewSegment returns how many customers the segment matches now and the first few of them.
+func (h *CampaignHandler) PreviewSegment(c *gin.Context) {
+	pharmacyID, _, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	preview, err := h.campaignService.PreviewSegment(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+func (h *CampaignHandler) Create(c *gin.Context) {
+	pharmacyID, userID, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	var req inbound.CampaignInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	campaign, err := h.campaignService.Create(c.Request.Context(), pharmacyID, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, campaign)
+}
+
+// List returns the pharmacy's campaigns with their delivery counts, newest first (query: limit, offset).
+func (h *CampaignHandler) List(c *gin.Context) {
+	pharmacyID, _, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	limit, offset := pageParams(c)
+	list, total, err := h.campaignService.List(c.Request.Context(), pharmacyID, limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total, "limit": limit, "offset": offset})
+}
+
+func (h *CampaignHandler) Get(c *gin.Context) {
+	pharmacyID, _, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	campaign, err := h.campaignService.Get(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, campaign)
+}
+
+func (h *CampaignHandler) Update(c *gin.Context) {
+	pharmacyID, _, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	var req inbound.CampaignInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	campaign, err := h.campaignService.Update(c.Request.Context(), pharmacyID, id, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, campaign)
+}
+
+func (h *CampaignHandler) Delete(c *gin.Context) {
+	pharmacyID, _, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	if err := h.campaignService.Delete(c.Request.Context(), pharmacyID, id); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Send starts sending a draft campaign; it responds 202 while the messages go out.
+func (h *CampaignHandler) Send(c *gin.Context) {
+	pharmacyID, _, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	campaign, err := h.campaignService.Send(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, campaign)
+}
+
+// Recipients pages through per-customer outcomes (query: status, limit, offset).
+func (h *CampaignHandler) Recipients(c *gin.Context) {
+	pharmacyID, _, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	limit, offset := pageParams(c)
+	list, total, err := h.campaignService.ListRecipients(c.Request.Context(), pharmacyID, id, c.Query("status"), limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total, "limit": limit, "offset": offset})
+}
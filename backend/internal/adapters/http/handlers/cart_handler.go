@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type CartHandler struct {
+	cartService inbound.CartService
+	logger      *zap.Logger
+}
+
+func NewCartHandler(cartService inbound.CartService, logger *zap.Logger) *CartHandler {
+	return &CartHandler{cartService: cartService, logger: logger}
+}
+
+// cartOwner reads the pharmacy and user from the JWT context.
+func cartOwner(c *gin.Context) (uuid.UUID, uuid.UUID) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	return pharmacyID, userID
+}
+
+// Get returns the current user's cart with totals.
+func (h *CartHandler) Get(c *gin.Context) {
+	pharmacyID, userID := cartOwner(c)
+	cart, err := h.cartService.Get(c.Request.Context(), pharmacyID, userID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+type addCartItemRequest struct {
+	ProductID uuid.UUID `json:"product_id" binding:"required"`
+	Quantity  int       `json:"quantity" binding:"required,min=1"`
+}
+
+// AddItem adds a product to the cart (quantities accumulate).
+func (h *CartHandler) AddItem(c *gin.Context) {
+	pharmacyID, userID := cartOwner(c)
+	var req addCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	cart, err := h.cartService.AddItem(c.Request.Context(), pharmacyID, userID, req.ProductID, req.Quantity)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+type updateCartItemRequest struct {
+	Quantity *int `json:"quantity" binding:"required,min=0"`
+}
+
+// UpdateItem sets the quantity of a cart line; 0 removes it.
+func (h *CartHandler) UpdateItem(c *gin.Context) {
+	pharmacyID, userID := cartOwner(c)
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid product id"})
+		return
+	}
+	var req updateCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	cart, err := h.cartService.UpdateItem(c.Request.Context(), pharmacyID, userID, productID, *req.Quantity)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// RemoveItem removes a product from the cart.
+func (h *CartHandler) RemoveItem(c *gin.Context) {
+	pharmacyID, userID := cartOwner(c)
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid product id"})
+		return
+	}
+	cart, err := h.cartService.RemoveItem(c.Request.Context(), pharmacyID, userID, productID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// Clear empties the cart and drops applied codes.
+func (h *CartHandler) Clear(c *gin.Context) {
+	pharmacyID, userID := cartOwner(c)
+	cart, err := h.cartService.Clear(c.Request.Context(), pharmacyID, userID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// ApplyCodes sets the promo code, referral code and points to redeem.
+func (h *CartHandler) ApplyCodes(c *gin.Context) {
+	pharmacyID, userID := cartOwner(c)
+	var req inbound.CartCodesInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	cart, err := h.cartService.ApplyCodes(c.Request.Context(), pharmacyID, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// Checkout converts the cart into an order.
+func (h *CartHandler) Checkout(c *gin.Context) {
+	pharmacyID, userID := cartOwner(c)
+	var req inbound.CartCheckoutInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+			return
+		}
+	}
+	order, err := h.cartService.Checkout(c.Request.Context(), pharmacyID, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, order)
+}
+
+// ValidateCheckout reports stock, prescription, promo, points, delivery-area and payment problems with the final
+// totals, so clients can show them all before placing the order. Body fields are optional; see
+// inbound.CheckoutValidationInput.
+func (h *CartHandler) ValidateCheckout(c *gin.Context) {
+	pharmacyID, userID := cartOwner(c)
+	var req inbound.CheckoutValidationInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+			return
+		}
+	}
+	result, err := h.cartService.ValidateCheckout(c.Request.Context(), pharmacyID, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
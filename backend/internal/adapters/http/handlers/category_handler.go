@@ -26,15 +26,18 @@ type categoryBody struct {
 	Description string  `json:"description"`
 	SortOrder   int     `json:"sort_order"`
 	ParentID    *string `json:"parent_id,omitempty"` // optional; nil = top-level category, set = subcategory
+	// Translations: other language variants keyed by code, title = name, body = description; omitted on update = keep.
+	Translations models.Translations `json:"translations,omitempty"`
 }
 
 func (b categoryBody) toCategory(id, pharmacyID uuid.UUID) models.Category {
 	cat := models.Category{
-		ID:          id,
-		PharmacyID:  pharmacyID,
-		Name:        b.Name,
-		Description: b.Description,
-		SortOrder:   b.SortOrder,
+		ID:           id,
+		PharmacyID:   pharmacyID,
+		Name:         b.Name,
+		Description:  b.Description,
+		SortOrder:    b.SortOrder,
+		Translations: b.Translations,
 	}
 	if b.ParentID != nil && *b.ParentID != "" {
 		if pid, err := uuid.Parse(*b.ParentID); err == nil {
@@ -71,6 +74,7 @@ func (h *CategoryHandler) GetByID(c *gin.Context) {
 		c.JSON(http.StatusNotFound, response.ErrorResponse{Code: errors.ErrCodeNotFound, Message: "category not found"})
 		return
 	}
+	localizeCategories(c, []*models.Category{cat})
 	c.JSON(http.StatusOK, cat)
 }
 
@@ -84,6 +88,7 @@ func (h *CategoryHandler) List(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: err.Error()})
 			return
 		}
+		localizeCategories(c, list)
 		c.JSON(http.StatusOK, list)
 		return
 	}
@@ -97,6 +102,7 @@ func (h *CategoryHandler) List(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: err.Error()})
 		return
 	}
+	localizeCategories(c, list)
 	c.JSON(http.StatusOK, list)
 }
 
@@ -114,6 +120,7 @@ func (h *CategoryHandler) ListByPharmacyID(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: err.Error()})
 			return
 		}
+		localizeCategories(c, list)
 		c.JSON(http.StatusOK, list)
 		return
 	}
@@ -127,6 +134,7 @@ func (h *CategoryHandler) ListByPharmacyID(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: err.Error()})
 		return
 	}
+	localizeCategories(c, list)
 	c.JSON(http.StatusOK, list)
 }
 
@@ -163,3 +171,12 @@ func (h *CategoryHandler) Delete(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
 }
+
+// localizeCategories shows each category's name and description in the content language, when it has a variant.
+func localizeCategories(c *gin.Context, list []*models.Category) {
+	if lang := contentLanguage(c); lang != "" {
+		for _, cat := range list {
+			cat.Localize(lang)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"io"
 	"net/http"
 	"strconv"
 
@@ -15,13 +16,40 @@ import (
 )
 
 type ChatHandler struct {
-	chatService  inbound.ChatService
-	authProvider outbound.AuthProvider
-	logger       *zap.Logger
+	chatService       inbound.ChatService
+	escalationService inbound.ChatEscalationService
+	orderService      inbound.ChatOrderService
+	authProvider      outbound.AuthProvider
+	presence          outbound.PresenceTracker
+	files             outbound.PrivateFileStorage // signs private attachment links (e.g. prescriptions)
+	logger            *zap.Logger
 }
 
-func NewChatHandler(chatService inbound.ChatService, authProvider outbound.AuthProvider, logger *zap.Logger) *ChatHandler {
-	return &ChatHandler{chatService: chatService, authProvider: authProvider, logger: logger}
+func NewChatHandler(chatService inbound.ChatService, escalationService inbound.ChatEscalationService, orderService inbound.ChatOrderService, authProvider outbound.AuthProvider, presence outbound.PresenceTracker, files outbound.PrivateFileStorage, logger *zap.Logger) *ChatHandler {
+	return &ChatHandler{chatService: chatService, escalationService: escalationService, orderService: orderService, authProvider: authProvider, presence: presence, files: files, logger: logger}
+}
+
+// conversationView adds live presence to a conversation: whether its customer or end user is connected,
+// and whether any pharmacy team member is.
+type conversationView struct {
+	*models.Conversation
+	ParticipantOnline bool `json:"participant_online"`
+	StaffOnline       bool `json:"staff_online"`
+}
+
+func (h *ChatHandler) withPresence(conv *models.Conversation, staffOnline bool) conversationView {
+	v := conversationView{Conversation: conv, StaffOnline: staffOnline}
+	switch {
+	case conv.CustomerID != nil:
+		v.ParticipantOnline = h.presence.IsCustomerOnline(*conv.CustomerID)
+	case conv.UserID != nil:
+		v.ParticipantOnline = h.presence.IsUserOnline(*conv.UserID)
+	}
+	return v
+}
+
+func (h *ChatHandler) conversationResponse(conv *models.Conversation) conversationView {
+	return h.withPresence(conv, len(h.presence.OnlineStaff(conv.PharmacyID)) > 0)
 }
 
 func (h *ChatHandler) getChatContext(c *gin.Context) (pharmacyID uuid.UUID, userID *uuid.UUID, customerID *uuid.UUID, role string, isCustomer bool, ok bool) {
@@ -92,7 +120,12 @@ func (h *ChatHandler) ListConversations(c *gin.Context) {
 		writeServiceError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"items": list, "total": total})
+	onlineStaff := h.presence.OnlineStaff(pharmacyID)
+	items := make([]conversationView, 0, len(list))
+	for _, conv := range list {
+		items = append(items, h.withPresence(conv, len(onlineStaff) > 0))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total, "online_staff": onlineStaff})
 }
 
 type createConversationRequest struct {
@@ -141,7 +174,7 @@ func (h *ChatHandler) GetMyConversation(c *gin.Context) {
 			writeServiceError(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, conv)
+		c.JSON(http.StatusOK, h.conversationResponse(conv))
 		return
 	}
 	if role == "staff" && userID != nil {
@@ -150,7 +183,7 @@ func (h *ChatHandler) GetMyConversation(c *gin.Context) {
 			writeServiceError(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, conv)
+		c.JSON(http.StatusOK, h.conversationResponse(conv))
 		return
 	}
 	c.JSON(http.StatusForbidden, response.ErrorResponse{Code: errors.ErrCodeForbidden, Message: "forbidden"})
@@ -173,7 +206,7 @@ func (h *ChatHandler) GetConversation(c *gin.Context) {
 		writeServiceError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, conv)
+	c.JSON(http.StatusOK, h.conversationResponse(conv))
 }
 
 // ListMessages - list messages for conversation (staff or customer with access)
@@ -207,6 +240,9 @@ func (h *ChatHandler) ListMessages(c *gin.Context) {
 		writeServiceError(c, err)
 		return
 	}
+	for i, m := range list {
+		list[i] = signedChatMessage(c.Request.Context(), h.files, h.logger, m)
+	}
 	c.JSON(http.StatusOK, gin.H{"items": list, "total": total})
 }
 
@@ -243,12 +279,13 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 		senderType = models.SenderTypeUser
 		senderID = *userID
 	}
-	msg, err := h.chatService.SendMessage(c.Request.Context(), id, senderType, senderID, req.Body, req.AttachmentURL, req.AttachmentName, req.AttachmentType)
+	attachmentURL := privateFileRef(h.files, req.AttachmentURL)
+	msg, err := h.chatService.SendMessage(c.Request.Context(), id, senderType, senderID, req.Body, attachmentURL, req.AttachmentName, req.AttachmentType)
 	if err != nil {
 		writeServiceError(c, err)
 		return
 	}
-	c.JSON(http.StatusCreated, msg)
+	c.JSON(http.StatusCreated, signedChatMessage(c.Request.Context(), h.files, h.logger, msg))
 }
 
 type issueCustomerTokenRequest struct {
@@ -322,7 +359,7 @@ func (h *ChatHandler) EditMessage(c *gin.Context) {
 		writeServiceError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, msg)
+	c.JSON(http.StatusOK, signedChatMessage(c.Request.Context(), h.files, h.logger, msg))
 }
 
 // DeleteMessage - delete own message (staff or customer)
@@ -367,3 +404,109 @@ func (h *ChatHandler) DeleteConversation(c *gin.Context) {
 	}
 	c.JSON(http.StatusNoContent, nil)
 }
+
+type escalateConversationRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// Escalate (pharmacy team only) hands the conversation to the external ticketing system.
+func (h *ChatHandler) Escalate(c *gin.Context) {
+	pharmacyID, userID, _, role, isCustomer, ok := h.getChatContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "invalid context"})
+		return
+	}
+	if isCustomer || role == "staff" {
+		c.JSON(http.StatusForbidden, response.ErrorResponse{Code: errors.ErrCodeForbidden, Message: "only the pharmacy team can escalate conversations"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	var req escalateConversationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	conv, err := h.escalationService.Escalate(c.Request.Context(), pharmacyID, id, *userID, req.Reason)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, conv)
+}
+
+// OrderDraft (pharmacy team only) returns the order the conversation suggests: its customer's details and the
+// products shared in the thread.
+func (h *ChatHandler) OrderDraft(c *gin.Context) {
+	pharmacyID, _, _, _, isCustomer, ok := h.getChatContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "invalid context"})
+		return
+	}
+	if isCustomer {
+		c.JSON(http.StatusForbidden, response.ErrorResponse{Code: errors.ErrCodeForbidden, Message: "only the pharmacy team can create orders from conversations"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	draft, err := h.orderService.Draft(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, draft)
+}
+
+// CreateOrder (pharmacy team only) places an order from the conversation and links it back to the thread.
+func (h *ChatHandler) CreateOrder(c *gin.Context) {
+	pharmacyID, userID, _, _, isCustomer, ok := h.getChatContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "invalid context"})
+		return
+	}
+	if isCustomer || userID == nil {
+		c.JSON(http.StatusForbidden, response.ErrorResponse{Code: errors.ErrCodeForbidden, Message: "only the pharmacy team can create orders from conversations"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	var req inbound.ChatOrderInput
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	order, err := h.orderService.CreateOrder(c.Request.Context(), pharmacyID, id, *userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, order)
+}
+
+// TicketWebhook receives status changes and comments from the ticketing system (no auth; shared secret in
+// the X-Ticketing-Secret header or ?secret= query, since not every help desk can set headers).
+func (h *ChatHandler) TicketWebhook(c *gin.Context) {
+	secret := c.GetHeader("X-Ticketing-Secret")
+	if secret == "" {
+		secret = c.Query("secret")
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid body"})
+		return
+	}
+	if err := h.escalationService.HandleTicketWebhook(c.Request.Context(), secret, body); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
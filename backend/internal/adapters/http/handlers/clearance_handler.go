@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ClearanceHandler struct {
+	clearanceService inbound.ClearanceService
+	logger           *zap.Logger
+}
+
+func NewClearanceHandler(clearanceService inbound.ClearanceService, logger *zap.Logger) *ClearanceHandler {
+	return &ClearanceHandler{clearanceService: clearanceService, logger: logger}
+}
+
+// caller reads the pharmacy and user from the token and, when withID is set, the action id. It writes the error itself.
+func (h *ClearanceHandler) caller(c *gin.Context, withID bool) (pharmacyID, userID, actionID uuid.UUID, ok bool) {
+	pharmacyID, ok1 := getPharmacyID(c)
+	userID, ok2 := getUserID(c)
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	if withID {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+			return uuid.Nil, uuid.Nil, uuid.Nil, false
+		}
+		actionID = id
+	}
+	return pharmacyID, userID, actionID, true
+}
+
+// SupplierCandidates groups dead stock by supplier for supplier returns (query: days, min_value).
+func (h *ClearanceHandler) SupplierCandidates(c *gin.Context) {
+	pharmacyID, _, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	days := 0
+	if d := c.Query("days"); d != "" {
+		if n, ok := parseInt(d); ok && n > 0 {
+			days = n
+		}
+	}
+	minValue := 0.0
+	if v := c.Query("min_value"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid min_value"})
+			return
+		}
+		minValue = f
+	}
+	list, err := h.clearanceService.SupplierCandidates(c.Request.Context(), pharmacyID, days, minValue)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list})
+}
+
+// Create opens a clearance promo or supplier return for the given products.
+func (h *ClearanceHandler) Create(c *gin.Context) {
+	pharmacyID, userID, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	var req inbound.ClearanceInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	a, err := h.clearanceService.Create(c.Request.Context(), pharmacyID, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, a)
+}
+
+// List returns clearance actions with their recovered value, newest first (query: kind, status, limit, offset).
+func (h *ClearanceHandler) List(c *gin.Context) {
+	pharmacyID, _, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	limit, offset := 20, 0
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			offset = n
+		}
+	}
+	kind := models.ClearanceKind(c.Query("kind"))
+	status := models.ClearanceStatus(c.Query("status"))
+	list, total, err := h.clearanceService.List(c.Request.Context(), pharmacyID, kind, status, limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total, "limit": limit, "offset": offset})
+}
+
+// Get returns an action with its items and recovered value.
+func (h *ClearanceHandler) Get(c *gin.Context) {
+	pharmacyID, _, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	a, err := h.clearanceService.Get(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, a)
+}
+
+// RecordReturn records units sent back on a supplier return and the credit received for them.
+func (h *ClearanceHandler) RecordReturn(c *gin.Context) {
+	pharmacyID, _, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	var req inbound.ClearanceReturnInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	a, err := h.clearanceService.RecordReturn(c.Request.Context(), pharmacyID, id, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, a)
+}
+
+// Complete closes an open action; a promo's code stops applying.
+func (h *ClearanceHandler) Complete(c *gin.Context) {
+	pharmacyID, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	a, err := h.clearanceService.Complete(c.Request.Context(), pharmacyID, id, userID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, a)
+}
+
+// Cancel closes an open action without completing it.
+func (h *ClearanceHandler) Cancel(c *gin.Context) {
+	pharmacyID, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	a, err := h.clearanceService.Cancel(c.Request.Context(), pharmacyID, id, userID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, a)
+}
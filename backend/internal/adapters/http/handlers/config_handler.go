@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
@@ -37,16 +38,32 @@ func (h *ConfigHandler) GetOrCreate(c *gin.Context) {
 }
 
 // Upsert creates or updates config for the authenticated user's pharmacy (protected).
+// With ?dry_run=true the config is only validated and the result (errors, warnings, changed fields) returned.
 func (h *ConfigHandler) Upsert(c *gin.Context) {
 	pharmacyIDStr, _ := c.Get("pharmacy_id")
 	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userIDVal, _ := c.Get("user_id")
+	userID, _ := uuid.Parse(userIDVal.(string))
 	var input models.PharmacyConfig
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
 		return
 	}
 	input.PharmacyID = pharmacyID
-	cfg, err := h.configService.Upsert(c.Request.Context(), pharmacyID, &input)
+	result, err := h.configService.Validate(c.Request.Context(), pharmacyID, &input)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if dryRun := c.Query("dry_run"); dryRun == "true" || dryRun == "1" {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+	if !result.Valid {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "Invalid config", Fields: result.Errors})
+		return
+	}
+	cfg, err := h.configService.Upsert(c.Request.Context(), pharmacyID, userID, &input)
 	if err != nil {
 		writeServiceError(c, err)
 		return
@@ -55,14 +72,66 @@ func (h *ConfigHandler) Upsert(c *gin.Context) {
 	if h.activityLogService != nil {
 		details, _ := json.Marshal(map[string]interface{}{
 			"display_name": cfg.DisplayName, "primary_color": cfg.PrimaryColor, "default_language": cfg.DefaultLanguage,
+			"changes": result.Changes,
 		})
-		userIDVal, _ := c.Get("user_id")
-		userID, _ := uuid.Parse(userIDVal.(string))
 		_ = h.activityLogService.Create(c.Request.Context(), pharmacyID, userID, "PUT /config", "Config updated", "config", pharmacyID.String(), string(details), c.ClientIP())
 	}
 	c.JSON(http.StatusOK, cfg)
 }
 
+// History lists saved config versions, newest first (?limit=&offset=).
+func (h *ConfigHandler) History(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	list, total, err := h.configService.ListHistory(c.Request.Context(), pharmacyID, limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total})
+}
+
+func (h *ConfigHandler) GetVersion(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid version"})
+		return
+	}
+	v, err := h.configService.GetVersion(c.Request.Context(), pharmacyID, version)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, v)
+}
+
+// Rollback restores the config saved as :version; the restore is itself recorded as a new version.
+func (h *ConfigHandler) Rollback(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userIDVal, _ := c.Get("user_id")
+	userID, _ := uuid.Parse(userIDVal.(string))
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid version"})
+		return
+	}
+	cfg, err := h.configService.Rollback(c.Request.Context(), pharmacyID, userID, version)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if h.activityLogService != nil {
+		details, _ := json.Marshal(map[string]interface{}{"version": version})
+		_ = h.activityLogService.Create(c.Request.Context(), pharmacyID, userID, "POST /config/history/:version/rollback", "Config rolled back", "config", pharmacyID.String(), string(details), c.ClientIP())
+	}
+	c.JSON(http.StatusOK, cfg)
+}
+
 // GetByPharmacyID returns config for a pharmacy by path param (public, no auth).
 func (h *ConfigHandler) GetByPharmacyID(c *gin.Context) {
 	pharmacyID, err := uuid.Parse(c.Param("pharmacyId"))
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ConsentHandler struct {
+	consentService inbound.ConsentService
+	logger         *zap.Logger
+}
+
+func NewConsentHandler(consentService inbound.ConsentService, logger *zap.Logger) *ConsentHandler {
+	return &ConsentHandler{consentService: consentService, logger: logger}
+}
+
+// caller reads the pharmacy and user from the token and, when withCustomer is set, the customer id. It writes
+// the error itself.
+func (h *ConsentHandler) caller(c *gin.Context, withCustomer bool) (pharmacyID, userID, customerID uuid.UUID, ok bool) {
+	pharmacyID, ok1 := getPharmacyID(c)
+	userID, ok2 := getUserID(c)
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	if withCustomer {
+		id, err := uuid.Parse(c.Param("customerId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid customer id"})
+			return uuid.Nil, uuid.Nil, uuid.Nil, false
+		}
+		customerID = id
+	}
+	return pharmacyID, userID, customerID, true
+}
+
+// Get returns a customer's consent per channel with the recent history.
+func (h *ConsentHandler) Get(c *gin.Context) {
+	pharmacyID, _, customerID, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	consent, err := h.consentService.Get(c.Request.Context(), pharmacyID, customerID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, consent)
+}
+
+// Record appends a customer's opt-in or opt-out (body: channel, granted, source, note).
+func (h *ConsentHandler) Record(c *gin.Context) {
+	pharmacyID, userID, customerID, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	var req inbound.ConsentInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	req.IPAddress = c.ClientIP()
+	rec, err := h.consentService.Record(c.Request.Context(), pharmacyID, customerID, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, rec)
+}
+
+// GetMine returns the signed-in user's own marketing preferences.
+func (h *ConsentHandler) GetMine(c *gin.Context) {
+	pharmacyID, userID, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	consent, err := h.consentService.GetForUser(c.Request.Context(), pharmacyID, userID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, consent)
+}
+
+// RecordMine records the signed-in user's own opt-in or opt-out (body: channel, granted).
+func (h *ConsentHandler) RecordMine(c *gin.Context) {
+	pharmacyID, userID, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	var req inbound.ConsentInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	req.IPAddress = c.ClientIP()
+	rec, err := h.consentService.RecordForUser(c.Request.Context(), pharmacyID, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, rec)
+}
+
+// Export returns consent records for an audit. Query: from, to (YYYY-MM-DD, default last 30 days), format=csv.
+func (h *ConsentHandler) Export(c *gin.Context) {
+	pharmacyID, _, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+	list, err := h.consentService.Export(c.Request.Context(), pharmacyID, from, to)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if wantsCSV(c) {
+		rows := make([][]string, 0, len(list))
+		for _, r := range list {
+			name, phone, email := "", "", ""
+			if r.Customer != nil {
+				name, phone, email = r.Customer.Name, r.Customer.Phone, r.Customer.Email
+			}
+			recordedBy := ""
+			if r.RecordedBy != nil {
+				recordedBy = r.RecordedBy.String()
+			}
+			rows = append(rows, []string{
+				r.CreatedAt.UTC().Format(time.RFC3339), r.CustomerID.String(), name, phone, email, string(r.Channel),
+				strconv.FormatBool(r.Granted), r.Source, recordedBy, r.IPAddress, r.Note,
+			})
+		}
+		writeCSV(c, "consent-records.csv", []string{"recorded_at", "customer_id", "name", "phone", "email", "channel", "granted", "source", "recorded_by", "ip_address", "note"}, rows)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list})
+}
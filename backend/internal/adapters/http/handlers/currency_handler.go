@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// CurrencyHandler lists the supported currencies and lets a pharmacy keep exchange rates from its base currency
+// for the storefront's display currency.
+type CurrencyHandler struct {
+	currencies inbound.CurrencyService
+	logger     *zap.Logger
+}
+
+func NewCurrencyHandler(currencies inbound.CurrencyService, logger *zap.Logger) *CurrencyHandler {
+	return &CurrencyHandler{currencies: currencies, logger: logger}
+}
+
+// List handles GET /currencies: the currencies a pharmacy can price or display in, by code.
+func (h *CurrencyHandler) List(c *gin.Context) {
+	list := make([]models.Currency, 0, len(models.Currencies))
+	for _, cur := range models.Currencies {
+		list = append(list, cur)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	c.JSON(http.StatusOK, list)
+}
+
+// ListRates handles GET /exchange-rates.
+func (h *CurrencyHandler) ListRates(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	list, err := h.currencies.ListRates(c.Request.Context(), pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// SetRate handles PUT /exchange-rates/:currency. Body: { "rate": 0.0075 }, the units of :currency one unit of the
+// base currency is worth.
+func (h *CurrencyHandler) SetRate(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	var body struct {
+		Rate float64 `json:"rate" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	rate, err := h.currencies.SetRate(c.Request.Context(), pharmacyID, userID, c.Param("currency"), body.Rate)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rate)
+}
+
+// DeleteRate handles DELETE /exchange-rates/:currency. The storefront stops showing prices in that currency.
+func (h *CurrencyHandler) DeleteRate(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	if err := h.currencies.DeleteRate(c.Request.Context(), pharmacyID, c.Param("currency")); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Storefront handles GET /public/pharmacies/:pharmacyId/currencies: the base currency, the default display
+// currency and the rates a storefront can show prices with (?currency= on the product list and detail).
+func (h *CurrencyHandler) Storefront(c *gin.Context) {
+	pharmacyID, err := uuid.Parse(c.Param("pharmacyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid pharmacy id"})
+		return
+	}
+	out, err := h.currencies.Storefront(c.Request.Context(), pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
@@ -14,12 +14,13 @@ import (
 )
 
 type DailyLogHandler struct {
-	logService inbound.DailyLogService
-	logger     *zap.Logger
+	logService      inbound.DailyLogService
+	documentService inbound.DocumentService
+	logger          *zap.Logger
 }
 
-func NewDailyLogHandler(logService inbound.DailyLogService, logger *zap.Logger) *DailyLogHandler {
-	return &DailyLogHandler{logService: logService, logger: logger}
+func NewDailyLogHandler(logService inbound.DailyLogService, documentService inbound.DocumentService, logger *zap.Logger) *DailyLogHandler {
+	return &DailyLogHandler{logService: logService, documentService: documentService, logger: logger}
 }
 
 type createDailyLogRequest struct {
@@ -128,3 +129,28 @@ func (h *DailyLogHandler) Delete(c *gin.Context) {
 	}
 	c.Status(http.StatusNoContent)
 }
+
+// DaySheet renders the logs of ?date= (YYYY-MM-DD, default today) as a printable PDF for the noticeboard (format=pdf).
+func (h *DailyLogHandler) DaySheet(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	if f := c.DefaultQuery("format", "pdf"); f != "pdf" {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "unsupported format (use pdf)"})
+		return
+	}
+	dateStr := c.Query("date")
+	if dateStr == "" {
+		dateStr = time.Now().Format("2006-01-02")
+	}
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid date (use YYYY-MM-DD)"})
+		return
+	}
+	data, err := h.documentService.DailyLogPDF(c.Request.Context(), pharmacyID, date)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	writePDF(c, "day-sheet-"+dateStr+".pdf", data)
+}
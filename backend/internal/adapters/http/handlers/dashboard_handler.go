@@ -75,7 +75,7 @@ func (h *DashboardHandler) GetStats(c *gin.Context) {
 			}
 		}
 	}
-	orders, err := h.orderService.List(ctx, pharmacyID, createdBy, nil)
+	orders, err := h.orderService.List(ctx, pharmacyID, createdBy, nil, nil)
 	if err != nil {
 		h.logger.Error("dashboard orders list failed", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "failed to load dashboard stats"})
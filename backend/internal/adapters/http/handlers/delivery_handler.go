@@ -0,0 +1,379 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/middleware"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type DeliveryHandler struct {
+	deliveryService inbound.DeliveryService
+	dispatchService inbound.DispatchService
+	logger          *zap.Logger
+}
+
+func NewDeliveryHandler(deliveryService inbound.DeliveryService, dispatchService inbound.DispatchService, logger *zap.Logger) *DeliveryHandler {
+	return &DeliveryHandler{deliveryService: deliveryService, dispatchService: dispatchService, logger: logger}
+}
+
+type createDeliveryRequest struct {
+	OrderID    uuid.UUID  `json:"order_id" binding:"required"`
+	AssignedTo *uuid.UUID `json:"assigned_to"`
+	Note       string     `json:"note"`
+}
+
+// Create starts delivery tracking for an order (stage "packed").
+func (h *DeliveryHandler) Create(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	var req createDeliveryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	d, err := h.deliveryService.Create(c.Request.Context(), pharmacyID, req.OrderID, userID, req.AssignedTo, req.Note)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, d)
+}
+
+// List returns the pharmacy's deliveries. Optional: status, assigned_to.
+func (h *DeliveryHandler) List(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	var status *models.DeliveryStatus
+	if v := c.Query("status"); v != "" {
+		st := models.DeliveryStatus(v)
+		status = &st
+	}
+	var assignedTo *uuid.UUID
+	if v := c.Query("assigned_to"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid assigned_to"})
+			return
+		}
+		assignedTo = &id
+	}
+	list, err := h.deliveryService.List(c.Request.Context(), pharmacyID, status, assignedTo)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// ListMine returns deliveries assigned to the current user that are not yet delivered, unless status is given.
+func (h *DeliveryHandler) ListMine(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	var status *models.DeliveryStatus
+	if v := c.Query("status"); v != "" {
+		st := models.DeliveryStatus(v)
+		status = &st
+	}
+	list, err := h.deliveryService.List(c.Request.Context(), pharmacyID, status, &userID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if status == nil {
+		open := make([]*models.Delivery, 0, len(list))
+		for _, d := range list {
+			if d.Status != models.DeliveryStatusDelivered {
+				open = append(open, d)
+			}
+		}
+		list = open
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// GetByOrder returns the full delivery record with its event timeline.
+func (h *DeliveryHandler) GetByOrder(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	orderID, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid order id"})
+		return
+	}
+	d, err := h.deliveryService.GetByOrder(c.Request.Context(), pharmacyID, orderID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+type assignDeliveryRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
+
+// Assign hands the delivery to a team member.
+func (h *DeliveryHandler) Assign(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	orderID, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid order id"})
+		return
+	}
+	var req assignDeliveryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	d, err := h.deliveryService.Assign(c.Request.Context(), pharmacyID, orderID, userID, req.UserID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+// UpdateStatus advances the delivery stage; the customer is notified.
+func (h *DeliveryHandler) UpdateStatus(c *gin.Context) {
+	h.update(c, true)
+}
+
+// UpdateLocation records the courier's position and/or a location note.
+func (h *DeliveryHandler) UpdateLocation(c *gin.Context) {
+	h.update(c, false)
+}
+
+func (h *DeliveryHandler) update(c *gin.Context, status bool) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	orderID, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid order id"})
+		return
+	}
+	var req inbound.DeliveryUpdateInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	canManage := middleware.HasPermission(c, models.PermDeliveriesManage)
+	var d *models.Delivery
+	if status {
+		d, err = h.deliveryService.UpdateStatus(c.Request.Context(), pharmacyID, orderID, userID, canManage, req)
+	} else {
+		d, err = h.deliveryService.UpdateLocation(c.Request.Context(), pharmacyID, orderID, userID, canManage, req)
+	}
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+// AttachProof uploads the recipient's signature or a doorstep photo (multipart field "file"); :kind is
+// "signature" or "photo".
+func (h *DeliveryHandler) AttachProof(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	orderID, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid order id"})
+		return
+	}
+	f, ok := formImage(c)
+	if !ok {
+		return
+	}
+	defer f.Close()
+	canManage := middleware.HasPermission(c, models.PermDeliveriesManage)
+	d, err := h.deliveryService.AttachProof(c.Request.Context(), pharmacyID, orderID, userID, canManage, c.Param("kind"), f)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+// SendOTP (re)sends the delivery code to the customer.
+func (h *DeliveryHandler) SendOTP(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	orderID, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid order id"})
+		return
+	}
+	canManage := middleware.HasPermission(c, models.PermDeliveriesManage)
+	d, err := h.deliveryService.SendOTP(c.Request.Context(), pharmacyID, orderID, userID, canManage)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+// Track returns the customer-facing delivery view. End users (role "staff") may only track their own orders.
+func (h *DeliveryHandler) Track(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	orderID, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid order id"})
+		return
+	}
+	var customer *uuid.UUID
+	if role, _ := c.Get("role"); role == models.RoleStaff {
+		customer = &userID
+	}
+	t, err := h.deliveryService.Track(c.Request.Context(), pharmacyID, orderID, customer)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// SetDestination records the drop-off coordinates and optional delivery window used by route planning.
+func (h *DeliveryHandler) SetDestination(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	orderID, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid order id"})
+		return
+	}
+	var req inbound.DeliveryDestinationInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	d, err := h.deliveryService.SetDestination(c.Request.Context(), pharmacyID, orderID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+// PlanBatches groups packed deliveries of ready orders into one route per rider. With dry_run the plan is only
+// returned (200); otherwise it is saved, riders are notified, and 201 is returned.
+func (h *DeliveryHandler) PlanBatches(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	var req inbound.DispatchPlanInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	plan, err := h.dispatchService.PlanBatches(c.Request.Context(), pharmacyID, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	status := http.StatusCreated
+	if req.DryRun {
+		status = http.StatusOK
+	}
+	c.JSON(status, plan)
+}
+
+// ListBatches returns dispatch batches newest first. Optional: rider_id, limit, offset.
+func (h *DeliveryHandler) ListBatches(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	var riderID *uuid.UUID
+	if v := c.Query("rider_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid rider_id"})
+			return
+		}
+		riderID = &id
+	}
+	h.listBatches(c, pharmacyID, riderID)
+}
+
+// ListMyBatches returns the caller's own dispatch batches.
+func (h *DeliveryHandler) ListMyBatches(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	h.listBatches(c, pharmacyID, &userID)
+}
+
+func (h *DeliveryHandler) listBatches(c *gin.Context, pharmacyID uuid.UUID, riderID *uuid.UUID) {
+	limit, offset := 20, 0
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			offset = n
+		}
+	}
+	list, total, err := h.dispatchService.ListBatches(c.Request.Context(), pharmacyID, riderID, limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total, "limit": limit, "offset": offset})
+}
+
+// GetBatch returns a batch's route manifest with per-stop ETAs.
+func (h *DeliveryHandler) GetBatch(c *gin.Context) {
+	h.getBatch(c, false)
+}
+
+// GetMyBatch returns the manifest of one of the caller's own batches.
+func (h *DeliveryHandler) GetMyBatch(c *gin.Context) {
+	h.getBatch(c, true)
+}
+
+func (h *DeliveryHandler) getBatch(c *gin.Context, mine bool) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	batchID, err := uuid.Parse(c.Param("batchId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid batch id"})
+		return
+	}
+	var riderID *uuid.UUID
+	if mine {
+		riderID = &userID
+	}
+	m, err := h.dispatchService.GetBatch(c.Request.Context(), pharmacyID, batchID, riderID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, m)
+}
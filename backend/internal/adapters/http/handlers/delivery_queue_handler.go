@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DeliveryQueueHandler exposes the delivery queue's dead letters (emails, SMS and webhooks that failed every
+// attempt) and lets an admin queue them again.
+type DeliveryQueueHandler struct {
+	queue  inbound.DeliveryQueueService
+	logger *zap.Logger
+}
+
+func NewDeliveryQueueHandler(queue inbound.DeliveryQueueService, logger *zap.Logger) *DeliveryQueueHandler {
+	return &DeliveryQueueHandler{queue: queue, logger: logger}
+}
+
+// ListDead returns the pharmacy's dead deliveries, newest first. Query: kind (email, sms, webhook), limit, offset.
+func (h *DeliveryQueueHandler) ListDead(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	limit, offset := 20, 0
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			offset = n
+		}
+	}
+	list, total, err := h.queue.ListDeadLetters(c.Request.Context(), pharmacyID, c.Query("kind"), limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total})
+}
+
+// Retry puts a dead delivery back in the queue with a fresh set of attempts.
+func (h *DeliveryQueueHandler) Retry(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	job, err := h.queue.Retry(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
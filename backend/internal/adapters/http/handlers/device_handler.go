@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DeviceHandler manages the current user's push notification devices.
+type DeviceHandler struct {
+	pushService inbound.PushNotificationService
+	logger      *zap.Logger
+}
+
+func NewDeviceHandler(pushService inbound.PushNotificationService, logger *zap.Logger) *DeviceHandler {
+	return &DeviceHandler{pushService: pushService, logger: logger}
+}
+
+func (h *DeviceHandler) List(c *gin.Context) {
+	userIDStr, _ := c.Get("user_id")
+	userID, _ := uuid.Parse(userIDStr.(string))
+	list, err := h.pushService.ListDevices(c.Request.Context(), userID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+type registerDeviceRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Platform string `json:"platform"` // web (default), android or ios
+}
+
+// Register stores an FCM token for the current user. Registering a known token refreshes it.
+func (h *DeviceHandler) Register(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	var req registerDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	d, err := h.pushService.RegisterDevice(c.Request.Context(), pharmacyID, userID, req.Token, req.Platform)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, d)
+}
+
+type unregisterDeviceRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Unregister removes a token, e.g. on logout or when the user turns notifications off.
+func (h *DeviceHandler) Unregister(c *gin.Context) {
+	userIDStr, _ := c.Get("user_id")
+	userID, _ := uuid.Parse(userIDStr.(string))
+	var req unregisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	if err := h.pushService.UnregisterDevice(c.Request.Context(), userID, req.Token); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type DrugInfoHandler struct {
+	drugInfoService inbound.DrugInfoService
+	logger          *zap.Logger
+}
+
+func NewDrugInfoHandler(drugInfoService inbound.DrugInfoService, logger *zap.Logger) *DrugInfoHandler {
+	return &DrugInfoHandler{drugInfoService: drugInfoService, logger: logger}
+}
+
+// caller reads the pharmacy and user from the token and the id in path parameter param (skipped when empty).
+// It writes the error itself.
+func (h *DrugInfoHandler) caller(c *gin.Context, param string) (pharmacyID, userID, id uuid.UUID, ok bool) {
+	pharmacyID, ok1 := getPharmacyID(c)
+	userID, ok2 := getUserID(c)
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	if param == "" {
+		return pharmacyID, userID, uuid.Nil, true
+	}
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	return pharmacyID, userID, id, true
+}
+
+// GetIngredients lists the product's active ingredients.
+func (h *DrugInfoHandler) GetIngredients(c *gin.Context) {
+	pharmacyID, _, productID, ok := h.caller(c, "id")
+	if !ok {
+		return
+	}
+	list, err := h.drugInfoService.Ingredients(c.Request.Context(), pharmacyID, productID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ingredients": list})
+}
+
+// SetIngredients replaces the product's active ingredients (body: ingredients [{name, strength}]).
+func (h *DrugInfoHandler) SetIngredients(c *gin.Context) {
+	pharmacyID, _, productID, ok := h.caller(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		Ingredients []inbound.IngredientInput `json:"ingredients" binding:"dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	list, err := h.drugInfoService.SetIngredients(c.Request.Context(), pharmacyID, productID, req.Ingredients)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ingredients": list})
+}
+
+func (h *DrugInfoHandler) ListInteractions(c *gin.Context) {
+	pharmacyID, _, _, ok := h.caller(c, "")
+	if !ok {
+		return
+	}
+	list, err := h.drugInfoService.ListInteractions(c.Request.Context(), pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"interactions": list})
+}
+
+// AddInteraction stores an interaction rule (body: ingredient_a, ingredient_b, severity, description); an existing
+// rule for the pair is updated.
+func (h *DrugInfoHandler) AddInteraction(c *gin.Context) {
+	pharmacyID, userID, _, ok := h.caller(c, "")
+	if !ok {
+		return
+	}
+	var req inbound.DrugInteractionInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	rule, err := h.drugInfoService.AddInteraction(c.Request.Context(), pharmacyID, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (h *DrugInfoHandler) DeleteInteraction(c *gin.Context) {
+	pharmacyID, _, id, ok := h.caller(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.drugInfoService.DeleteInteraction(c.Request.Context(), pharmacyID, id); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Check returns the warnings for products dispensed together (body: product_ids), without placing an order.
+func (h *DrugInfoHandler) Check(c *gin.Context) {
+	pharmacyID, _, _, ok := h.caller(c, "")
+	if !ok {
+		return
+	}
+	var req struct {
+		ProductIDs []uuid.UUID `json:"product_ids" binding:"required,min=1,max=50"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	warnings, err := h.drugInfoService.CheckProducts(c.Request.Context(), pharmacyID, req.ProductIDs)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"warnings": warnings})
+}
@@ -14,12 +14,13 @@ import (
 )
 
 type DutyRosterHandler struct {
-	rosterService inbound.DutyRosterService
-	logger       *zap.Logger
+	rosterService   inbound.DutyRosterService
+	documentService inbound.DocumentService
+	logger          *zap.Logger
 }
 
-func NewDutyRosterHandler(rosterService inbound.DutyRosterService, logger *zap.Logger) *DutyRosterHandler {
-	return &DutyRosterHandler{rosterService: rosterService, logger: logger}
+func NewDutyRosterHandler(rosterService inbound.DutyRosterService, documentService inbound.DocumentService, logger *zap.Logger) *DutyRosterHandler {
+	return &DutyRosterHandler{rosterService: rosterService, documentService: documentService, logger: logger}
 }
 
 type createDutyRosterRequest struct {
@@ -73,26 +74,8 @@ func (h *DutyRosterHandler) GetByID(c *gin.Context) {
 func (h *DutyRosterHandler) List(c *gin.Context) {
 	pharmacyIDStr, _ := c.Get("pharmacy_id")
 	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
-	fromStr := c.DefaultQuery("from", "")
-	toStr := c.DefaultQuery("to", "")
-	if fromStr == "" || toStr == "" {
-		// default: current week
-		now := time.Now()
-		weekday := int(now.Weekday())
-		if weekday == 0 {
-			weekday = 7
-		}
-		fromStr = now.AddDate(0, 0, -(weekday - 1)).Format("2006-01-02")
-		toStr = now.AddDate(0, 0, 7-weekday).Format("2006-01-02")
-	}
-	from, err := time.Parse("2006-01-02", fromStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid from date (use YYYY-MM-DD)"})
-		return
-	}
-	to, err := time.Parse("2006-01-02", toStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid to date (use YYYY-MM-DD)"})
+	from, to, ok := rosterRange(c)
+	if !ok {
 		return
 	}
 	list, err := h.rosterService.ListByDateRange(c.Request.Context(), pharmacyID, from, to)
@@ -154,3 +137,135 @@ func (h *DutyRosterHandler) Delete(c *gin.Context) {
 	}
 	c.Status(http.StatusNoContent)
 }
+
+// rosterRange reads ?from=&to= (YYYY-MM-DD), defaulting to the current week. It writes the 400 itself.
+func rosterRange(c *gin.Context) (time.Time, time.Time, bool) {
+	fromStr := c.DefaultQuery("from", "")
+	toStr := c.DefaultQuery("to", "")
+	if fromStr == "" || toStr == "" {
+		// default: current week
+		now := time.Now()
+		weekday := int(now.Weekday())
+		if weekday == 0 {
+			weekday = 7
+		}
+		fromStr = now.AddDate(0, 0, -(weekday - 1)).Format("2006-01-02")
+		toStr = now.AddDate(0, 0, 7-weekday).Format("2006-01-02")
+	}
+	from, err := time.Parse("2006-01-02", fromStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid from date (use YYYY-MM-DD)"})
+		return time.Time{}, time.Time{}, false
+	}
+	to, err := time.Parse("2006-01-02", toStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid to date (use YYYY-MM-DD)"})
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+// Export renders the published roster for ?from=&to= (default the current week) as a printable PDF (format=pdf).
+func (h *DutyRosterHandler) Export(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	if f := c.DefaultQuery("format", "pdf"); f != "pdf" {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "unsupported format (use pdf)"})
+		return
+	}
+	from, to, ok := rosterRange(c)
+	if !ok {
+		return
+	}
+	data, err := h.documentService.DutyRosterPDF(c.Request.Context(), pharmacyID, from, to)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	writePDF(c, "duty-roster-"+from.Format("2006-01-02")+"-to-"+to.Format("2006-01-02")+".pdf", data)
+}
+
+type publishDutyRosterRequest struct {
+	From string `json:"from" binding:"required"` // YYYY-MM-DD
+	To   string `json:"to" binding:"required"`   // YYYY-MM-DD
+}
+
+// Publish makes the drafts in a date range visible to the assigned pharmacists and notifies them.
+func (h *DutyRosterHandler) Publish(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	var req publishDutyRosterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	from, err := time.Parse("2006-01-02", req.From)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid from date (use YYYY-MM-DD)"})
+		return
+	}
+	to, err := time.Parse("2006-01-02", req.To)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid to date (use YYYY-MM-DD)"})
+		return
+	}
+	list, err := h.rosterService.Publish(c.Request.Context(), pharmacyID, from, to)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"published": len(list), "items": list})
+}
+
+// Unacknowledged lists upcoming published entries their pharmacist has not acknowledged yet.
+func (h *DutyRosterHandler) Unacknowledged(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	list, err := h.rosterService.ListUnacknowledged(c.Request.Context(), pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": len(list)})
+}
+
+// Mine lists the caller's published shifts (?from=&to=, default current week).
+func (h *DutyRosterHandler) Mine(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	userID, ok2 := getUserID(c)
+	if !ok || !ok2 {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	from, to, ok := rosterRange(c)
+	if !ok {
+		return
+	}
+	list, err := h.rosterService.ListMine(c.Request.Context(), pharmacyID, userID, from, to)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Acknowledge records that the caller has seen their (new or changed) shift.
+func (h *DutyRosterHandler) Acknowledge(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	userID, ok2 := getUserID(c)
+	if !ok || !ok2 {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	d, err := h.rosterService.Acknowledge(c.Request.Context(), pharmacyID, userID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
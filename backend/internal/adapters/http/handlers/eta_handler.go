@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ETAHandler struct {
+	etaService inbound.ETAService
+	logger     *zap.Logger
+}
+
+func NewETAHandler(etaService inbound.ETAService, logger *zap.Logger) *ETAHandler {
+	return &ETAHandler{etaService: etaService, logger: logger}
+}
+
+// Estimate returns the ETA of an order placed now, for checkout. Query: delivery=true for a delivery order.
+func (h *ETAHandler) Estimate(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	delivery := c.Query("delivery") == "true" || c.Query("delivery") == "1"
+	e, err := h.etaService.Estimate(c.Request.Context(), pharmacyID, delivery)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, e)
+}
+
+// ForOrder returns the current ETA of an order. End users only see their own orders.
+func (h *ETAHandler) ForOrder(c *gin.Context) {
+	pharmacyID, ok1 := getPharmacyID(c)
+	userID, ok2 := getUserID(c)
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	orderID, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid order id"})
+		return
+	}
+	var customer *uuid.UUID
+	if role, _ := c.Get("role"); role == models.RoleStaff {
+		customer = &userID
+	}
+	e, err := h.etaService.ForOrder(c.Request.Context(), pharmacyID, orderID, customer)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, e)
+}
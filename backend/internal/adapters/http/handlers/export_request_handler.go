@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ExportRequestHandler struct {
+	exportService inbound.ExportApprovalService
+	logger        *zap.Logger
+}
+
+func NewExportRequestHandler(exportService inbound.ExportApprovalService, logger *zap.Logger) *ExportRequestHandler {
+	return &ExportRequestHandler{exportService: exportService, logger: logger}
+}
+
+// caller reads the pharmacy and user from the token and, when withID is set, the request id. It writes the error itself.
+func (h *ExportRequestHandler) caller(c *gin.Context, withID bool) (pharmacyID, userID, id uuid.UUID, ok bool) {
+	pharmacyID, ok1 := getPharmacyID(c)
+	userID, ok2 := getUserID(c)
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	if withID {
+		parsed, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+			return uuid.Nil, uuid.Nil, uuid.Nil, false
+		}
+		id = parsed
+	}
+	return pharmacyID, userID, id, true
+}
+
+// Kinds lists the exports that need approval.
+func (h *ExportRequestHandler) Kinds(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"kinds": models.PIIExportKinds})
+}
+
+type createExportRequest struct {
+	Kind   string `json:"kind" binding:"required"`
+	Reason string `json:"reason" binding:"required,max=1000"`
+}
+
+// Create asks a manager to approve one download of a PII export (body: kind, reason).
+func (h *ExportRequestHandler) Create(c *gin.Context) {
+	pharmacyID, userID, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	var req createExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	r, err := h.exportService.Request(c.Request.Context(), pharmacyID, userID, req.Kind, req.Reason)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, r)
+}
+
+// Mine lists the caller's own requests.
+func (h *ExportRequestHandler) Mine(c *gin.Context) {
+	pharmacyID, userID, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	h.list(c, pharmacyID, &userID)
+}
+
+// List returns the pharmacy's requests for review (query: status=pending|approved|rejected|used).
+func (h *ExportRequestHandler) List(c *gin.Context) {
+	pharmacyID, _, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	h.list(c, pharmacyID, nil)
+}
+
+func (h *ExportRequestHandler) list(c *gin.Context, pharmacyID uuid.UUID, requestedBy *uuid.UUID) {
+	status := models.ExportRequestStatus(c.Query("status"))
+	switch status {
+	case "", models.ExportRequestPending, models.ExportRequestApproved, models.ExportRequestRejected, models.ExportRequestUsed:
+	default:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid status"})
+		return
+	}
+	list, err := h.exportService.List(c.Request.Context(), pharmacyID, requestedBy, string(status))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": len(list)})
+}
+
+// Approve allows the requester one download within 24 hours (body: optional note).
+func (h *ExportRequestHandler) Approve(c *gin.Context) {
+	h.review(c, true)
+}
+
+// Reject declines the request (body: optional note).
+func (h *ExportRequestHandler) Reject(c *gin.Context) {
+	h.review(c, false)
+}
+
+func (h *ExportRequestHandler) review(c *gin.Context, approve bool) {
+	pharmacyID, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	note, ok := bindNote(c)
+	if !ok {
+		return
+	}
+	r, err := h.exportService.Review(c.Request.Context(), pharmacyID, id, userID, approve, note)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
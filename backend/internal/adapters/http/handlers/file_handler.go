@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/careplus/pharmacy-backend/pkg/signing"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// FileHandler serves privately stored local files through the signed links the storage issues.
+type FileHandler struct {
+	files  outbound.PrivateFileOpener
+	logger *zap.Logger
+}
+
+func NewFileHandler(files outbound.PrivateFileOpener, logger *zap.Logger) *FileHandler {
+	return &FileHandler{files: files, logger: logger}
+}
+
+// ServePrivate handles GET /files/private/*path?token=... A missing, invalid or expired token is 403.
+func (h *FileHandler) ServePrivate(c *gin.Context) {
+	p := strings.TrimPrefix(c.Param("path"), "/")
+	f, err := h.files.OpenPrivate(p, c.Query("token"))
+	switch {
+	case err == signing.ErrInvalidToken || err == signing.ErrExpiredToken:
+		c.JSON(http.StatusForbidden, response.ErrorResponse{Code: errors.ErrCodeForbidden, Message: "link is invalid or has expired"})
+		return
+	case os.IsNotExist(err):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Code: errors.ErrCodeNotFound, Message: "file not found"})
+		return
+	case err != nil:
+		h.logger.Error("failed to open private file", zap.String("path", p), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "failed to open file"})
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Code: errors.ErrCodeNotFound, Message: "file not found"})
+		return
+	}
+	// The link is short-lived; keep caches from serving the file after it expires.
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	http.ServeContent(c.Writer, c.Request, path.Base(p), info.ModTime(), f)
+}
+
+// signedFileURL replaces a private file reference with a signed link; other URLs are returned unchanged. The
+// reference itself is never sent to clients, so a signing failure yields "".
+func signedFileURL(ctx context.Context, files outbound.PrivateFileStorage, logger *zap.Logger, ref string) string {
+	if files == nil || !strings.HasPrefix(ref, outbound.PrivateFileRefPrefix) {
+		return ref
+	}
+	url, err := files.SignedURL(ctx, ref)
+	if err != nil {
+		logger.Warn("failed to sign private file link", zap.Error(err))
+		return ""
+	}
+	return url
+}
+
+// privateFileRef maps a signed link sent back by a client (e.g. from an edit form filled from a response) to
+// the reference it stands for, so the link's expiry is not stored; other URLs are returned unchanged.
+func privateFileRef(files outbound.PrivateFileStorage, url string) string {
+	if files != nil {
+		if ref, ok := files.PrivateRef(url); ok {
+			return ref
+		}
+	}
+	return url
+}
+
+// signedUser returns u with its CV link signed, copying it rather than changing the service's value.
+func signedUser(ctx context.Context, files outbound.PrivateFileStorage, logger *zap.Logger, u *models.User) *models.User {
+	if u == nil || !strings.HasPrefix(u.CVURL, outbound.PrivateFileRefPrefix) {
+		return u
+	}
+	cp := *u
+	cp.CVURL = signedFileURL(ctx, files, logger, u.CVURL)
+	return &cp
+}
+
+// signedReturnRequest returns r with its video and photo links signed.
+func signedReturnRequest(ctx context.Context, files outbound.PrivateFileStorage, logger *zap.Logger, r *models.OrderReturnRequest) *models.OrderReturnRequest {
+	if r == nil {
+		return r
+	}
+	cp := *r
+	cp.VideoURL = signedFileURL(ctx, files, logger, r.VideoURL)
+	if r.PhotoURLs != nil {
+		cp.PhotoURLs = make(models.StringSlice, len(r.PhotoURLs))
+		for i, u := range r.PhotoURLs {
+			cp.PhotoURLs[i] = signedFileURL(ctx, files, logger, u)
+		}
+	}
+	return &cp
+}
+
+// signedChatMessage returns m with its attachment link signed.
+func signedChatMessage(ctx context.Context, files outbound.PrivateFileStorage, logger *zap.Logger, m *models.ChatMessage) *models.ChatMessage {
+	if m == nil || !strings.HasPrefix(m.AttachmentURL, outbound.PrivateFileRefPrefix) {
+		return m
+	}
+	cp := *m
+	cp.AttachmentURL = signedFileURL(ctx, files, logger, m.AttachmentURL)
+	return &cp
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type GiftCardHandler struct {
+	giftCardService inbound.GiftCardService
+	logger          *zap.Logger
+}
+
+func NewGiftCardHandler(giftCardService inbound.GiftCardService, logger *zap.Logger) *GiftCardHandler {
+	return &GiftCardHandler{giftCardService: giftCardService, logger: logger}
+}
+
+// caller reads the pharmacy and user from the token and, when withID is set, the card id. It writes the error itself.
+func (h *GiftCardHandler) caller(c *gin.Context, withID bool) (pharmacyID, userID, cardID uuid.UUID, ok bool) {
+	pharmacyID, ok1 := getPharmacyID(c)
+	userID, ok2 := getUserID(c)
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	if withID {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+			return uuid.Nil, uuid.Nil, uuid.Nil, false
+		}
+		cardID = id
+	}
+	return pharmacyID, userID, cardID, true
+}
+
+// Issue creates a gift card; the code is generated unless given.
+func (h *GiftCardHandler) Issue(c *gin.Context) {
+	pharmacyID, userID, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	var req inbound.GiftCardInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	card, err := h.giftCardService.Issue(c.Request.Context(), pharmacyID, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, card)
+}
+
+// List returns the pharmacy's gift cards, newest first (query: limit, offset).
+func (h *GiftCardHandler) List(c *gin.Context) {
+	pharmacyID, _, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	limit, offset := 20, 0
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			offset = n
+		}
+	}
+	list, total, err := h.giftCardService.List(c.Request.Context(), pharmacyID, limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total, "limit": limit, "offset": offset})
+}
+
+// Get returns a card with its transactions.
+func (h *GiftCardHandler) Get(c *gin.Context) {
+	pharmacyID, _, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	view, err := h.giftCardService.Get(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// Check shows a code's balance and whether it can be used (query: code).
+func (h *GiftCardHandler) Check(c *gin.Context) {
+	pharmacyID, _, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "code query param required"})
+		return
+	}
+	bal, err := h.giftCardService.Check(c.Request.Context(), pharmacyID, code)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, bal)
+}
+
+type redeemGiftCardRequest struct {
+	Code    string  `json:"code" binding:"required,max=40"`
+	Amount  float64 `json:"amount" binding:"required,gt=0"`
+	OrderID *string `json:"order_id"`
+}
+
+// Redeem takes an amount off a card at the counter, optionally against an existing order.
+func (h *GiftCardHandler) Redeem(c *gin.Context) {
+	pharmacyID, userID, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	var req redeemGiftCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	var orderID *uuid.UUID
+	if req.OrderID != nil && *req.OrderID != "" {
+		id, err := uuid.Parse(*req.OrderID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid order_id"})
+			return
+		}
+		orderID = &id
+	}
+	t, err := h.giftCardService.Redeem(c.Request.Context(), pharmacyID, req.Code, req.Amount, orderID, userID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// Deactivate stops a card from being redeemed; its balance is kept.
+func (h *GiftCardHandler) Deactivate(c *gin.Context) {
+	pharmacyID, _, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	card, err := h.giftCardService.Deactivate(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, card)
+}
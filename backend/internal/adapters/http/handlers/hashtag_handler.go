@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type HashtagHandler struct {
+	hashtagService inbound.HashtagService
+	logger         *zap.Logger
+}
+
+func NewHashtagHandler(hashtagService inbound.HashtagService, logger *zap.Logger) *HashtagHandler {
+	return &HashtagHandler{hashtagService: hashtagService, logger: logger}
+}
+
+// scope reads the pharmacy from the token and, when param is set, that path id. It writes the error itself.
+func (h *HashtagHandler) scope(c *gin.Context, param string) (pharmacyID, id uuid.UUID, ok bool) {
+	pharmacyID, ok = getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "pharmacy_id not set"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	if param != "" {
+		parsed, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid " + param})
+			return uuid.Nil, uuid.Nil, false
+		}
+		id = parsed
+	}
+	return pharmacyID, id, true
+}
+
+// List returns the registry with product counts, followed by tags used on products but not registered.
+func (h *HashtagHandler) List(c *gin.Context) {
+	pharmacyID, _, ok := h.scope(c, "")
+	if !ok {
+		return
+	}
+	list, err := h.hashtagService.List(c.Request.Context(), pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": len(list)})
+}
+
+// Create registers a tag; its aliases (and the tag itself, when blocked) are rewritten on products.
+func (h *HashtagHandler) Create(c *gin.Context) {
+	pharmacyID, _, ok := h.scope(c, "")
+	if !ok {
+		return
+	}
+	var input inbound.HashtagInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	tag, err := h.hashtagService.Create(c.Request.Context(), pharmacyID, input)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, tag)
+}
+
+func (h *HashtagHandler) Update(c *gin.Context) {
+	pharmacyID, id, ok := h.scope(c, "id")
+	if !ok {
+		return
+	}
+	var input inbound.HashtagInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	tag, err := h.hashtagService.Update(c.Request.Context(), pharmacyID, id, input)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
+
+// Delete removes a registry entry; products keep the tag.
+func (h *HashtagHandler) Delete(c *gin.Context) {
+	pharmacyID, id, ok := h.scope(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.hashtagService.Delete(c.Request.Context(), pharmacyID, id); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type mergeHashtagRequest struct {
+	IntoID uuid.UUID `json:"into_id" binding:"required"`
+}
+
+// Merge folds the hashtag into another. Body: {"into_id": "<uuid>"}.
+func (h *HashtagHandler) Merge(c *gin.Context) {
+	pharmacyID, id, ok := h.scope(c, "id")
+	if !ok {
+		return
+	}
+	var req mergeHashtagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	tag, n, err := h.hashtagService.Merge(c.Request.Context(), pharmacyID, id, req.IntoID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"hashtag": tag, "products_updated": n})
+}
+
+// Sync canonicalizes the hashtags on every product and registers new ones.
+func (h *HashtagHandler) Sync(c *gin.Context) {
+	pharmacyID, _, ok := h.scope(c, "")
+	if !ok {
+		return
+	}
+	n, err := h.hashtagService.Sync(c.Request.Context(), pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"products_updated": n})
+}
+
+// Trending returns the top tags for storefront discovery. No auth. Query: days (default 7, max 90),
+// by=views|sales, limit (default 10, max 50).
+func (h *HashtagHandler) Trending(c *gin.Context) {
+	pharmacyID, err := uuid.Parse(c.Param("pharmacyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid pharmacy id"})
+		return
+	}
+	days, limit := 0, 0
+	if d := c.Query("days"); d != "" {
+		if n, ok := parseInt(d); ok {
+			days = n
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok {
+			limit = n
+		}
+	}
+	list, err := h.hashtagService.Trending(c.Request.Context(), pharmacyID, days, c.Query("by"), limit)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": len(list)})
+}
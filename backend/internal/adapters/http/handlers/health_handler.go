@@ -3,14 +3,19 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/scheduler"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
 	"github.com/gin-gonic/gin"
 )
 
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+func NewHealthHandler(jobs *scheduler.Scheduler) *HealthHandler {
+	return &HealthHandler{jobs: jobs}
 }
 
-type HealthHandler struct{}
+type HealthHandler struct {
+	jobs *scheduler.Scheduler
+}
 
 func (h *HealthHandler) Check(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok", "service": "careplus-pharmacy"})
@@ -23,3 +28,14 @@ func (h *HealthHandler) Readiness(c *gin.Context) {
 func (h *HealthHandler) Liveness(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "alive"})
 }
+
+// Scheduler reports this instance's scheduled jobs and, with leader election, whether it is the leader and which
+// instance is.
+func (h *HealthHandler) Scheduler(c *gin.Context) {
+	status, err := h.jobs.Status(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "failed to read scheduler status"})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// IntegrityHandler runs the data doctor for the caller's pharmacy. The cmd/doctor tool runs the same checks
+// across every pharmacy.
+type IntegrityHandler struct {
+	integrityService inbound.IntegrityService
+	logger           *zap.Logger
+}
+
+func NewIntegrityHandler(integrityService inbound.IntegrityService, logger *zap.Logger) *IntegrityHandler {
+	return &IntegrityHandler{integrityService: integrityService, logger: logger}
+}
+
+// Report lists integrity problems without changing anything.
+func (h *IntegrityHandler) Report(c *gin.Context) {
+	h.run(c, false)
+}
+
+// Fix applies the safe automatic fixes and returns the report with what was fixed.
+func (h *IntegrityHandler) Fix(c *gin.Context) {
+	h.run(c, true)
+}
+
+func (h *IntegrityHandler) run(c *gin.Context, applyFixes bool) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	report, err := h.integrityService.Run(c.Request.Context(), &pharmacyID, applyFixes)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type InventoryEvidenceHandler struct {
+	evidenceService inbound.InventoryEvidenceService
+	logger          *zap.Logger
+}
+
+func NewInventoryEvidenceHandler(evidenceService inbound.InventoryEvidenceService, logger *zap.Logger) *InventoryEvidenceHandler {
+	return &InventoryEvidenceHandler{evidenceService: evidenceService, logger: logger}
+}
+
+// caller reads the pharmacy and user from the token and the id in path parameter param. It writes the error itself.
+func (h *InventoryEvidenceHandler) caller(c *gin.Context, param string) (pharmacyID, userID, id uuid.UUID, ok bool) {
+	pharmacyID, ok1 := getPharmacyID(c)
+	userID, ok2 := getUserID(c)
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	return pharmacyID, userID, id, true
+}
+
+// WriteOff takes damaged or otherwise unsellable units off a batch with a reason (body: quantity, reason, note).
+func (h *InventoryEvidenceHandler) WriteOff(c *gin.Context) {
+	pharmacyID, userID, batchID, ok := h.caller(c, "batchId")
+	if !ok {
+		return
+	}
+	var req inbound.StockWriteOffInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	a, err := h.evidenceService.WriteOff(c.Request.Context(), pharmacyID, batchID, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, a)
+}
+
+// AddPhoto uploads a photo of the batch (multipart field "file"; optional "caption" and "adjustment_id" to file it
+// as evidence for a write-off).
+func (h *InventoryEvidenceHandler) AddPhoto(c *gin.Context) {
+	pharmacyID, userID, batchID, ok := h.caller(c, "batchId")
+	if !ok {
+		return
+	}
+	var adjustmentID *uuid.UUID
+	if v := c.PostForm("adjustment_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid adjustment_id"})
+			return
+		}
+		adjustmentID = &id
+	}
+	f, ok := formImage(c)
+	if !ok {
+		return
+	}
+	defer f.Close()
+	p, err := h.evidenceService.AddPhoto(c.Request.Context(), pharmacyID, batchID, userID, adjustmentID, c.PostForm("caption"), f)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, p)
+}
+
+// Ledger returns the batch with its write-offs and photos.
+func (h *InventoryEvidenceHandler) Ledger(c *gin.Context) {
+	pharmacyID, _, batchID, ok := h.caller(c, "batchId")
+	if !ok {
+		return
+	}
+	l, err := h.evidenceService.Ledger(c.Request.Context(), pharmacyID, batchID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, l)
+}
+
+// AttachToReturn links batch photos to a supplier return as damage evidence (body: photo_ids).
+func (h *InventoryEvidenceHandler) AttachToReturn(c *gin.Context) {
+	pharmacyID, _, actionID, ok := h.caller(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		PhotoIDs []uuid.UUID `json:"photo_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	photos, err := h.evidenceService.AttachToReturn(c.Request.Context(), pharmacyID, actionID, req.PhotoIDs)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": photos})
+}
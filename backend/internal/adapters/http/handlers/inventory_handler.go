@@ -14,10 +14,23 @@ import (
 
 type InventoryHandler struct {
 	inventoryService inbound.InventoryService
+	alertService     inbound.InventoryAlertService
 }
 
-func NewInventoryHandler(inventoryService inbound.InventoryService) *InventoryHandler {
-	return &InventoryHandler{inventoryService: inventoryService}
+func NewInventoryHandler(inventoryService inbound.InventoryService, alertService inbound.InventoryAlertService) *InventoryHandler {
+	return &InventoryHandler{inventoryService: inventoryService, alertService: alertService}
+}
+
+// Alerts returns the pharmacy's low-stock and expiry alert digest, computed live.
+func (h *InventoryHandler) Alerts(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	digest, err := h.alertService.Digest(c.Request.Context(), pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, digest)
 }
 
 // ListBatchesByProduct returns inventory batches for a product (auth, product must belong to pharmacy).
@@ -47,7 +60,8 @@ func (h *InventoryHandler) AddBatch(c *gin.Context) {
 	var body struct {
 		BatchNumber string    `json:"batch_number" binding:"required"`
 		Quantity    int       `json:"quantity" binding:"required,min=1"`
-		ExpiryDate  *dateOnly `json:"expiry_date"`
+		ExpiryDate  *dateOnly  `json:"expiry_date"`
+		BranchID    *uuid.UUID `json:"branch_id"` // defaults to the pharmacy's default branch
 	}
 	if err := c.ShouldBindJSON(&body); err != nil {
 		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
@@ -57,7 +71,7 @@ func (h *InventoryHandler) AddBatch(c *gin.Context) {
 	if body.ExpiryDate != nil {
 		expiry = body.ExpiryDate.toTime()
 	}
-	b, err := h.inventoryService.AddBatch(c.Request.Context(), pharmacyID, productID, body.BatchNumber, body.Quantity, expiry)
+	b, err := h.inventoryService.AddBatch(c.Request.Context(), pharmacyID, productID, body.BatchNumber, body.Quantity, expiry, body.BranchID)
 	if err != nil {
 		writeServiceError(c, err)
 		return
@@ -65,11 +79,11 @@ func (h *InventoryHandler) AddBatch(c *gin.Context) {
 	c.JSON(http.StatusCreated, b)
 }
 
-// ListBatchesByPharmacy returns all inventory batches for the current pharmacy.
+// ListBatchesByPharmacy returns all inventory batches for the current pharmacy (optional query: branch_id).
 func (h *InventoryHandler) ListBatchesByPharmacy(c *gin.Context) {
 	pharmacyIDStr, _ := c.Get("pharmacy_id")
 	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
-	list, err := h.inventoryService.ListBatchesByPharmacy(c.Request.Context(), pharmacyID)
+	list, err := h.inventoryService.ListBatchesByPharmacy(c.Request.Context(), pharmacyID, branchIDQuery(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: err.Error()})
 		return
@@ -110,6 +124,23 @@ func (h *InventoryHandler) GetBatch(c *gin.Context) {
 	c.JSON(http.StatusOK, b)
 }
 
+// ListBatchAllocations returns the order items that drew from a batch (FEFO allocations), e.g. for a recall.
+func (h *InventoryHandler) ListBatchAllocations(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	id, err := uuid.Parse(c.Param("batchId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid batch id"})
+		return
+	}
+	list, err := h.inventoryService.ListBatchAllocations(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
 // UpdateBatch updates batch quantity and/or expiry date.
 func (h *InventoryHandler) UpdateBatch(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("batchId"))
@@ -12,12 +12,13 @@ import (
 )
 
 type InvoiceHandler struct {
-	invoiceService inbound.InvoiceService
-	logger         *zap.Logger
+	invoiceService  inbound.InvoiceService
+	documentService inbound.DocumentService
+	logger          *zap.Logger
 }
 
-func NewInvoiceHandler(invoiceService inbound.InvoiceService, logger *zap.Logger) *InvoiceHandler {
-	return &InvoiceHandler{invoiceService: invoiceService, logger: logger}
+func NewInvoiceHandler(invoiceService inbound.InvoiceService, documentService inbound.DocumentService, logger *zap.Logger) *InvoiceHandler {
+	return &InvoiceHandler{invoiceService: invoiceService, documentService: documentService, logger: logger}
 }
 
 func (h *InvoiceHandler) CreateFromOrder(c *gin.Context) {
@@ -76,3 +77,76 @@ func (h *InvoiceHandler) Issue(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, inv)
 }
+
+// PDF renders the invoice with its VAT breakdown and any credit notes as a printable PDF.
+func (h *InvoiceHandler) PDF(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	data, err := h.documentService.InvoicePDF(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	writePDF(c, "invoice-"+id.String()+".pdf", data)
+}
+
+// ListCreditNotes returns the pharmacy's credit notes, newest first (query: limit, offset).
+func (h *InvoiceHandler) ListCreditNotes(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	limit, offset := 20, 0
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			offset = n
+		}
+	}
+	list, total, err := h.invoiceService.ListCreditNotes(c.Request.Context(), pharmacyID, limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total, "limit": limit, "offset": offset})
+}
+
+func (h *InvoiceHandler) GetCreditNote(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	view, err := h.invoiceService.GetCreditNote(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// CreditNotePDF renders the credit note as a printable PDF.
+func (h *InvoiceHandler) CreditNotePDF(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	data, err := h.documentService.CreditNotePDF(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	writePDF(c, "credit-note-"+id.String()+".pdf", data)
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type LicenseComplianceHandler struct {
+	licenseService inbound.LicenseComplianceService
+	logger         *zap.Logger
+}
+
+func NewLicenseComplianceHandler(licenseService inbound.LicenseComplianceService, logger *zap.Logger) *LicenseComplianceHandler {
+	return &LicenseComplianceHandler{licenseService: licenseService, logger: logger}
+}
+
+// Overview lists pharmacists' license status (expired, expiring, missing, valid) with counts per status.
+func (h *LicenseComplianceHandler) Overview(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	overview, err := h.licenseService.Overview(c.Request.Context(), pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, overview)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/middleware"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ModerationHandler struct {
+	moderationService inbound.ModerationService
+	logger            *zap.Logger
+}
+
+func NewModerationHandler(moderationService inbound.ModerationService, logger *zap.Logger) *ModerationHandler {
+	return &ModerationHandler{moderationService: moderationService, logger: logger}
+}
+
+// caller reads the pharmacy and user from the token. It writes the error itself.
+func (h *ModerationHandler) caller(c *gin.Context) (pharmacyID, userID uuid.UUID, ok bool) {
+	pharmacyID, ok1 := getPharmacyID(c)
+	userID, ok2 := getUserID(c)
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return pharmacyID, userID, true
+}
+
+// Reasons lists the reasons a report can give.
+func (h *ModerationHandler) Reasons(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"reasons": models.ReportReasons})
+}
+
+type createContentReportRequest struct {
+	TargetType string `json:"target_type" binding:"required"`
+	TargetID   string `json:"target_id" binding:"required"`
+	Reason     string `json:"reason" binding:"required"`
+	Note       string `json:"note"`
+}
+
+// Report flags a review, review comment, blog comment or chat message (body: target_type, target_id, reason, note).
+func (h *ModerationHandler) Report(c *gin.Context) {
+	pharmacyID, userID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+	var req createContentReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	targetID, err := uuid.Parse(req.TargetID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid target_id"})
+		return
+	}
+	in := inbound.ContentReportInput{TargetType: req.TargetType, TargetID: targetID, Reason: req.Reason, Note: req.Note}
+	r, err := h.moderationService.Report(c.Request.Context(), pharmacyID, userID, c.GetString("role"), in)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, r)
+}
+
+// Queue lists reports for moderation (query: status=open|resolved|all, target_type, author_id, limit, offset).
+func (h *ModerationHandler) Queue(c *gin.Context) {
+	pharmacyID, _, ok := h.caller(c)
+	if !ok {
+		return
+	}
+	q := inbound.ModerationQuery{Status: c.Query("status"), TargetType: c.Query("target_type"), Limit: 50}
+	if s := c.Query("author_id"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid author_id"})
+			return
+		}
+		q.AuthorID = &id
+	}
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 200 {
+			q.Limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			q.Offset = n
+		}
+	}
+	list, total, err := h.moderationService.Queue(c.Request.Context(), pharmacyID, q)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total})
+}
+
+// Act resolves a report (body: action=dismiss|hide_content|warn_user|deactivate_user, note, message, hide_content).
+// Deactivating the author also needs users.manage.
+func (h *ModerationHandler) Act(c *gin.Context) {
+	pharmacyID, userID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	var in inbound.ModerationActionInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	r, err := h.moderationService.Act(c.Request.Context(), pharmacyID, id, userID, in, middleware.HasPermission(c, models.PermUsersManage), c.ClientIP())
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
 	"github.com/careplus/pharmacy-backend/pkg/errors"
 	"github.com/gin-gonic/gin"
@@ -129,6 +130,8 @@ type createNotificationRequest struct {
 	Title   string `json:"title" binding:"required"`
 	Message string `json:"message"`
 	Type    string `json:"type"`
+	// Translations: other language variants keyed by code (e.g. "ne"); the recipient gets the one matching their preferred language.
+	Translations models.Translations `json:"translations"`
 }
 
 func (h *NotificationHandler) Create(c *gin.Context) {
@@ -156,7 +159,11 @@ func (h *NotificationHandler) Create(c *gin.Context) {
 	if notifType == "" {
 		notifType = "info"
 	}
-	n, err := h.notificationService.Create(c.Request.Context(), pharmacyID, userID, body.Title, body.Message, notifType)
+	if msg := models.ValidateTranslations(body.Translations); msg != "" {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: msg})
+		return
+	}
+	n, err := h.notificationService.CreateLocalized(c.Request.Context(), pharmacyID, userID, body.Title, body.Message, body.Translations, notifType)
 	if err != nil {
 		h.logger.Warn("notification create failed", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "failed to create notification"})
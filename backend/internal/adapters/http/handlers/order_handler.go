@@ -6,6 +6,7 @@ import (
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 	"github.com/careplus/pharmacy-backend/pkg/errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -16,14 +17,16 @@ type OrderHandler struct {
 	orderService             inbound.OrderService
 	orderFeedbackService     inbound.OrderFeedbackService
 	orderReturnRequestService inbound.OrderReturnRequestService
+	files                    outbound.PrivateFileStorage // signs return request evidence links
 	logger                   *zap.Logger
 }
 
-func NewOrderHandler(orderService inbound.OrderService, orderFeedbackService inbound.OrderFeedbackService, orderReturnRequestService inbound.OrderReturnRequestService, logger *zap.Logger) *OrderHandler {
+func NewOrderHandler(orderService inbound.OrderService, orderFeedbackService inbound.OrderFeedbackService, orderReturnRequestService inbound.OrderReturnRequestService, files outbound.PrivateFileStorage, logger *zap.Logger) *OrderHandler {
 	return &OrderHandler{
 		orderService:             orderService,
 		orderFeedbackService:     orderFeedbackService,
 		orderReturnRequestService: orderReturnRequestService,
+		files:                    files,
 		logger:                   logger,
 	}
 }
@@ -40,6 +43,9 @@ type createOrderRequest struct {
 	ReferralCode      *string                  `json:"referral_code"`
 	PointsToRedeem    *int                     `json:"points_to_redeem"`
 	PaymentGatewayID  *string                  `json:"payment_gateway_id"` // optional; mock payment will be recorded
+	GiftCardCode      string                   `json:"gift_card_code" binding:"max=40"`
+	StoreCredit       float64                  `json:"store_credit" binding:"min=0"` // store credit to spend; needs customer_phone
+	CustomFields      map[string]any           `json:"custom_fields"`                // values for the pharmacy's order fields
 }
 
 func (h *OrderHandler) Create(c *gin.Context) {
@@ -49,16 +55,20 @@ func (h *OrderHandler) Create(c *gin.Context) {
 	userID, _ := uuid.Parse(userIDStr.(string))
 	var req createOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		response.WriteError(c, http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
 		return
 	}
+	customFields := req.CustomFields
+	if customFields == nil {
+		customFields = map[string]any{} // still enforce required fields
+	}
 	var paymentGatewayID *uuid.UUID
 	if req.PaymentGatewayID != nil && *req.PaymentGatewayID != "" {
 		if parsed, err := uuid.Parse(*req.PaymentGatewayID); err == nil {
 			paymentGatewayID = &parsed
 		}
 	}
-	o, err := h.orderService.Create(c.Request.Context(), pharmacyID, userID, req.CustomerName, req.CustomerPhone, req.CustomerEmail, req.Items, req.Notes, req.DeliveryAddress, req.DiscountAmount, req.PromoCode, req.ReferralCode, req.PointsToRedeem, paymentGatewayID)
+	o, err := h.orderService.Create(c.Request.Context(), pharmacyID, userID, req.CustomerName, req.CustomerPhone, req.CustomerEmail, req.Items, req.Notes, req.DeliveryAddress, req.DiscountAmount, req.PromoCode, req.ReferralCode, req.PointsToRedeem, paymentGatewayID, &inbound.OrderTender{GiftCardCode: req.GiftCardCode, StoreCredit: req.StoreCredit}, customFields)
 	if err != nil {
 		writeServiceError(c, err)
 		return
@@ -69,12 +79,12 @@ func (h *OrderHandler) Create(c *gin.Context) {
 func (h *OrderHandler) GetByID(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("orderId"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		response.WriteError(c, http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
 		return
 	}
 	o, err := h.orderService.GetByID(c.Request.Context(), id)
 	if err != nil || o == nil {
-		c.JSON(http.StatusNotFound, response.ErrorResponse{Code: errors.ErrCodeNotFound, Message: "order not found"})
+		response.WriteError(c, http.StatusNotFound, response.ErrorResponse{Code: errors.ErrCodeNotFound, Message: "order not found"})
 		return
 	}
 	// End users (role "staff") may only view their own orders.
@@ -83,7 +93,7 @@ func (h *OrderHandler) GetByID(c *gin.Context) {
 			userIDStr, _ := c.Get("user_id")
 			if userIDStr != nil {
 				if userID, parseErr := uuid.Parse(userIDStr.(string)); parseErr == nil && o.CreatedBy != userID {
-					c.JSON(http.StatusForbidden, response.ErrorResponse{Code: errors.ErrCodeForbidden, Message: "you can only view your own orders"})
+					response.WriteError(c, http.StatusForbidden, response.ErrorResponse{Code: errors.ErrCodeForbidden, Message: "you can only view your own orders"})
 					return
 				}
 			}
@@ -110,7 +120,7 @@ func (h *OrderHandler) List(c *gin.Context) {
 			}
 		}
 	}
-	list, err := h.orderService.List(c.Request.Context(), pharmacyID, createdBy, status)
+	list, err := h.orderService.List(c.Request.Context(), pharmacyID, createdBy, status, branchIDQuery(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: err.Error()})
 		return
@@ -118,6 +128,73 @@ func (h *OrderHandler) List(c *gin.Context) {
 	c.JSON(http.StatusOK, list)
 }
 
+// orderListQuery reads ?status=&from=&to=&q=&branch_id=&limit=&offset=; q searches order numbers and to is
+// inclusive. It writes the error itself.
+func orderListQuery(c *gin.Context) (inbound.OrderListQuery, bool) {
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return inbound.OrderListQuery{}, false
+	}
+	q := inbound.OrderListQuery{
+		BranchID:    branchIDQuery(c),
+		Status:      c.Query("status"),
+		From:        from,
+		To:          to,
+		OrderNumber: c.Query("q"),
+		Limit:       20,
+	}
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 100 {
+			q.Limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			q.Offset = n
+		}
+	}
+	return q, true
+}
+
+// ListPage is the /api/v2 order list (see orderListQuery for the filters) with an items/total envelope instead of
+// a bare array. End users (role "staff") see only their own orders.
+func (h *OrderHandler) ListPage(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	q, ok := orderListQuery(c)
+	if !ok {
+		return
+	}
+	q.CreatedBy = ownerScope(c)
+	list, total, err := h.orderService.ListPaginated(c.Request.Context(), pharmacyID, q)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total, "limit": q.Limit, "offset": q.Offset})
+}
+
+// ListMine is the caller's own order history as summaries (item count, total, status timeline), whatever their role.
+// Same filters as ListPage except branch_id.
+func (h *OrderHandler) ListMine(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	userID, ok2 := getUserID(c)
+	if !ok || !ok2 {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	q, ok := orderListQuery(c)
+	if !ok {
+		return
+	}
+	list, total, err := h.orderService.ListMine(c.Request.Context(), pharmacyID, userID, q)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total, "limit": q.Limit, "offset": q.Offset})
+}
+
 func (h *OrderHandler) Accept(c *gin.Context) {
 	// Only staff roles (admin/manager/pharmacist) may accept orders; end users (role "staff") may not.
 	if roleVal, ok := c.Get("role"); ok {
@@ -131,7 +208,34 @@ func (h *OrderHandler) Accept(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
 		return
 	}
-	o, err := h.orderService.Accept(c.Request.Context(), id)
+	actorID, _ := getUserID(c)
+	note, ok := bindNote(c)
+	if !ok {
+		return
+	}
+	o, err := h.orderService.Accept(c.Request.Context(), id, actorID, note)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, o)
+}
+
+// AcknowledgeWarnings records that the pharmacist reviewed the order's interaction and duplicate-therapy warnings.
+func (h *OrderHandler) AcknowledgeWarnings(c *gin.Context) {
+	if roleVal, ok := c.Get("role"); ok {
+		if roleStr, _ := roleVal.(string); roleStr == "staff" {
+			c.JSON(http.StatusForbidden, response.ErrorResponse{Code: errors.ErrCodeForbidden, Message: "end users cannot acknowledge warnings"})
+			return
+		}
+	}
+	id, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	actorID, _ := getUserID(c)
+	o, err := h.orderService.AcknowledgeWarnings(c.Request.Context(), id, actorID)
 	if err != nil {
 		writeServiceError(c, err)
 		return
@@ -154,13 +258,15 @@ func (h *OrderHandler) UpdateStatus(c *gin.Context) {
 	}
 	var body struct {
 		Status string `json:"status" binding:"required"`
+		Note   string `json:"note" binding:"max=500"`
 	}
 	if err := c.ShouldBindJSON(&body); err != nil {
 		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
 		return
 	}
 	status := models.OrderStatus(body.Status)
-	o, err := h.orderService.UpdateStatus(c.Request.Context(), id, status)
+	actorID, _ := getUserID(c)
+	o, err := h.orderService.UpdateStatus(c.Request.Context(), id, status, actorID, body.Note)
 	if err != nil {
 		writeServiceError(c, err)
 		return
@@ -168,6 +274,26 @@ func (h *OrderHandler) UpdateStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, o)
 }
 
+// History returns the order's status changes, oldest first. End users (role "staff") may only view their own orders.
+func (h *OrderHandler) History(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "pharmacy_id not set"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	list, err := h.orderService.History(c.Request.Context(), pharmacyID, id, ownerScope(c))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": len(list)})
+}
+
 type createOrderFeedbackRequest struct {
 	Rating  int    `json:"rating" binding:"required,min=1,max=5"`
 	Comment string `json:"comment"`
@@ -233,11 +359,91 @@ func (h *OrderHandler) GetFeedback(c *gin.Context) {
 	c.JSON(http.StatusOK, f)
 }
 
+// ListFeedback lists the pharmacy's order feedback, newest first.
+// Query: follow_up_status=none|pending|contacted|resolved, max_rating (1-5), limit (default 20, max 100), offset.
+func (h *OrderHandler) ListFeedback(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	q := inbound.FeedbackListQuery{FollowUpStatus: c.Query("follow_up_status"), Limit: 20}
+	if r := c.Query("max_rating"); r != "" {
+		n, ok := parseInt(r)
+		if !ok || n < 1 || n > 5 {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "max_rating must be between 1 and 5"})
+			return
+		}
+		q.MaxRating = n
+	}
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 100 {
+			q.Limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			q.Offset = n
+		}
+	}
+	list, total, err := h.orderFeedbackService.List(c.Request.Context(), pharmacyID, q)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total})
+}
+
+// FeedbackSummary returns rating aggregates: overall average, trend, by staff member and by delivery vs pickup.
+// Query: from, to (YYYY-MM-DD, default last 30 days), granularity=day|week|month.
+func (h *OrderHandler) FeedbackSummary(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+	summary, err := h.orderFeedbackService.Summary(c.Request.Context(), pharmacyID, c.Query("granularity"), from, to)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+type updateFeedbackFollowUpRequest struct {
+	Status string `json:"status" binding:"required"`
+	Note   string `json:"note"`
+}
+
+// UpdateFeedbackFollowUp records that staff contacted the customer (or resolved the complaint).
+func (h *OrderHandler) UpdateFeedbackFollowUp(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userIDStr, _ := c.Get("user_id")
+	userID, _ := uuid.Parse(userIDStr.(string))
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	var body updateFeedbackFollowUpRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	f, err := h.orderFeedbackService.UpdateFollowUp(c.Request.Context(), pharmacyID, id, userID, body.Status, body.Note)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, f)
+}
+
 type createReturnRequestBody struct {
-	VideoURL    string   `json:"video_url"`
-	PhotoURLs   []string `json:"photo_urls"`
-	Notes       string   `json:"notes"`
-	Description string   `json:"description"`
+	VideoURL    string      `json:"video_url"`
+	PhotoURLs   []string    `json:"photo_urls"`
+	Notes       string      `json:"notes"`
+	Description string      `json:"description"`
+	ReasonCode  string      `json:"reason_code"` // see GET /returns/reasons
+	ProductIDs  []uuid.UUID `json:"product_ids"` // affected products; omit for the whole order
 }
 
 func (h *OrderHandler) CreateReturnRequest(c *gin.Context) {
@@ -257,12 +463,22 @@ func (h *OrderHandler) CreateReturnRequest(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
 		return
 	}
-	req, err := h.orderReturnRequestService.Create(c.Request.Context(), orderID, userID, body.VideoURL, body.PhotoURLs, body.Notes, body.Description)
+	for i, u := range body.PhotoURLs {
+		body.PhotoURLs[i] = privateFileRef(h.files, u)
+	}
+	req, err := h.orderReturnRequestService.Create(c.Request.Context(), orderID, userID, inbound.ReturnRequestInput{
+		VideoURL:    privateFileRef(h.files, body.VideoURL),
+		PhotoURLs:   body.PhotoURLs,
+		Notes:       body.Notes,
+		Description: body.Description,
+		ReasonCode:  body.ReasonCode,
+		ProductIDs:  body.ProductIDs,
+	})
 	if err != nil {
 		writeServiceError(c, err)
 		return
 	}
-	c.JSON(http.StatusCreated, req)
+	c.JSON(http.StatusCreated, signedReturnRequest(c.Request.Context(), h.files, h.logger, req))
 }
 
 func (h *OrderHandler) GetReturnRequest(c *gin.Context) {
@@ -296,5 +512,5 @@ func (h *OrderHandler) GetReturnRequest(c *gin.Context) {
 		c.JSON(http.StatusOK, nil)
 		return
 	}
-	c.JSON(http.StatusOK, req)
+	c.JSON(http.StatusOK, signedReturnRequest(c.Request.Context(), h.files, h.logger, req))
 }
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type OrganizationHandler struct {
+	organizationService inbound.OrganizationService
+	logger              *zap.Logger
+}
+
+func NewOrganizationHandler(organizationService inbound.OrganizationService, logger *zap.Logger) *OrganizationHandler {
+	return &OrganizationHandler{organizationService: organizationService, logger: logger}
+}
+
+// caller reads the pharmacy and user from the token and, when withID is set, the organization id. It writes the
+// error itself.
+func (h *OrganizationHandler) caller(c *gin.Context, withID bool) (pharmacyID, userID, organizationID uuid.UUID, ok bool) {
+	pharmacyID, ok1 := getPharmacyID(c)
+	userID, ok2 := getUserID(c)
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	if withID {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+			return uuid.Nil, uuid.Nil, uuid.Nil, false
+		}
+		organizationID = id
+	}
+	return pharmacyID, userID, organizationID, true
+}
+
+// drillDown reads the optional ?pharmacy_id= tenant filter. It writes the error itself.
+func drillDown(c *gin.Context) (*uuid.UUID, bool) {
+	s := c.Query("pharmacy_id")
+	if s == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid pharmacy_id"})
+		return nil, false
+	}
+	return &id, true
+}
+
+// Create starts an organization with the caller's pharmacy as its first tenant.
+func (h *OrganizationHandler) Create(c *gin.Context) {
+	pharmacyID, userID, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	var req inbound.OrganizationInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	o, err := h.organizationService.Create(c.Request.Context(), pharmacyID, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, o)
+}
+
+// Join links the caller's pharmacy to an organization (body: join_code).
+func (h *OrganizationHandler) Join(c *gin.Context) {
+	pharmacyID, userID, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	var req struct {
+		JoinCode string `json:"join_code" binding:"required,max=32"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	o, err := h.organizationService.Join(c.Request.Context(), pharmacyID, userID, req.JoinCode)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, o)
+}
+
+// ListMine returns the organizations the caller is a member of.
+func (h *OrganizationHandler) ListMine(c *gin.Context) {
+	_, userID, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	list, err := h.organizationService.ListMine(c.Request.Context(), userID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list})
+}
+
+// Get returns an organization with its tenants.
+func (h *OrganizationHandler) Get(c *gin.Context) {
+	_, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	o, err := h.organizationService.Get(c.Request.Context(), id, userID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, o)
+}
+
+// RemovePharmacy takes a tenant out of the organization.
+func (h *OrganizationHandler) RemovePharmacy(c *gin.Context) {
+	_, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	pharmacyID, err := uuid.Parse(c.Param("pharmacyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid pharmacy id"})
+		return
+	}
+	if err := h.organizationService.RemovePharmacy(c.Request.Context(), id, userID, pharmacyID); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListMembers returns who has group-level access.
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	_, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	list, err := h.organizationService.ListMembers(c.Request.Context(), id, userID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list})
+}
+
+// AddMember gives a user of one of the tenants access (body: email, role=owner|viewer).
+func (h *OrganizationHandler) AddMember(c *gin.Context) {
+	_, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	var req inbound.OrganizationMemberInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	m, err := h.organizationService.AddMember(c.Request.Context(), id, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, m)
+}
+
+// RemoveMember revokes a member's access.
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	_, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	memberID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid user id"})
+		return
+	}
+	if err := h.organizationService.RemoveMember(c.Request.Context(), id, userID, memberID); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Dashboard returns sales per tenant and top products. Query: from, to, pharmacy_id.
+func (h *OrganizationHandler) Dashboard(c *gin.Context) {
+	_, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+	pharmacyID, ok := drillDown(c)
+	if !ok {
+		return
+	}
+	d, err := h.organizationService.Dashboard(c.Request.Context(), id, userID, from, to, pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+// Sales returns the consolidated sales summary. Query: from, to, granularity=day|week|month, pharmacy_id, format=csv.
+func (h *OrganizationHandler) Sales(c *gin.Context) {
+	_, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+	pharmacyID, ok := drillDown(c)
+	if !ok {
+		return
+	}
+	report, err := h.organizationService.Sales(c.Request.Context(), id, userID, c.Query("granularity"), from, to, pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if wantsCSV(c) {
+		rows := make([][]string, 0, len(report.Rows))
+		for _, r := range report.Rows {
+			rows = append(rows, []string{r.Period.Format("2006-01-02"), strconv.FormatInt(r.OrdersCount, 10), money(r.SubTotal), money(r.DiscountAmount), money(r.TaxAmount), money(r.Revenue)})
+		}
+		writeCSV(c, "organization-sales-"+report.Granularity+".csv", []string{"period", "orders", "sub_total", "discount", "tax", "revenue"}, rows)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// TopProducts ranks products across tenants. Query: from, to, pharmacy_id, limit.
+func (h *OrganizationHandler) TopProducts(c *gin.Context) {
+	_, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+	pharmacyID, ok := drillDown(c)
+	if !ok {
+		return
+	}
+	limit := 0
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok {
+			limit = n
+		}
+	}
+	rows, err := h.organizationService.TopProducts(c.Request.Context(), id, userID, from, to, pharmacyID, limit)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": rows})
+}
+
+// Stock lists sellable stock per product across tenants and branches. Query: q, pharmacy_id, limit.
+func (h *OrganizationHandler) Stock(c *gin.Context) {
+	_, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	pharmacyID, ok := drillDown(c)
+	if !ok {
+		return
+	}
+	limit := 0
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok {
+			limit = n
+		}
+	}
+	items, err := h.organizationService.Stock(c.Request.Context(), id, userID, c.Query("q"), pharmacyID, limit)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
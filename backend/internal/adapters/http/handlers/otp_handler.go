@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type OTPHandler struct {
+	otpService inbound.OTPService
+	logger     *zap.Logger
+}
+
+func NewOTPHandler(otpService inbound.OTPService, logger *zap.Logger) *OTPHandler {
+	return &OTPHandler{otpService: otpService, logger: logger}
+}
+
+type otpRequest struct {
+	Phone   string `json:"phone" binding:"required"`
+	Purpose string `json:"purpose"`
+	Code    string `json:"code"`
+}
+
+// Send texts a one-time code to the phone (no auth; rate limited per phone).
+func (h *OTPHandler) Send(c *gin.Context) {
+	pharmacyID, err := uuid.Parse(c.Param("pharmacyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid pharmacy id"})
+		return
+	}
+	var req otpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	result, err := h.otpService.Send(c.Request.Context(), pharmacyID, req.Phone, req.Purpose)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *OTPHandler) Verify(c *gin.Context) {
+	pharmacyID, err := uuid.Parse(c.Param("pharmacyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid pharmacy id"})
+		return
+	}
+	var req otpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	if err := h.otpService.Verify(c.Request.Context(), pharmacyID, req.Phone, req.Purpose, req.Code); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"verified": true})
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
@@ -27,6 +28,7 @@ type pharmacyBody struct {
 	TenantCode    string `json:"tenant_code"`
 	HostnameSlug  string `json:"hostname_slug"`
 	BusinessType  string `json:"business_type"` // pharmacy, retail, clinic, other
+	GroupCode     string `json:"group_code"`    // same value for pharmacies of one owner
 	Address       string `json:"address"`
 	Phone         string `json:"phone"`
 	Email         string `json:"email"`
@@ -45,6 +47,7 @@ func (b pharmacyBody) toPharmacy(id uuid.UUID) models.Pharmacy {
 		TenantCode:   b.TenantCode,
 		HostnameSlug: b.HostnameSlug,
 		BusinessType: bt,
+		GroupCode:    strings.TrimSpace(b.GroupCode),
 		Address:      b.Address,
 		Phone:        b.Phone,
 		Email:        b.Email,
@@ -105,7 +108,14 @@ func (h *PharmacyHandler) List(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, list)
+	// Signups awaiting approval or turned down and suspended pharmacies are not listed.
+	live := make([]*models.Pharmacy, 0, len(list))
+	for _, p := range list {
+		if p.Open() {
+			live = append(live, p)
+		}
+	}
+	c.JSON(http.StatusOK, live)
 }
 
 func writeServiceError(c *gin.Context, err error) {
@@ -113,18 +123,30 @@ func writeServiceError(c *gin.Context, err error) {
 		appErr := errors.GetAppError(err)
 		switch appErr.Code {
 		case errors.ErrCodeValidation:
-			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+			response.WriteError(c, http.StatusBadRequest, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
 			return
 		case errors.ErrCodeNotFound:
-			c.JSON(http.StatusNotFound, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+			response.WriteError(c, http.StatusNotFound, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
 			return
 		case errors.ErrCodeConflict:
-			c.JSON(http.StatusConflict, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+			response.WriteError(c, http.StatusConflict, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+			return
+		case errors.ErrCodeUnauthorized:
+			response.WriteError(c, http.StatusUnauthorized, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
 			return
 		case errors.ErrCodeForbidden:
-			c.JSON(http.StatusForbidden, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+			response.WriteError(c, http.StatusForbidden, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+			return
+		case errors.ErrCodeTooManyRequests:
+			response.WriteError(c, http.StatusTooManyRequests, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+			return
+		case errors.ErrCodePaymentRequired:
+			response.WriteError(c, http.StatusPaymentRequired, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+			return
+		case errors.ErrCodePlanLimit:
+			response.WriteError(c, http.StatusForbidden, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
 			return
 		}
 	}
-	c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: err.Error()})
+	response.WriteError(c, http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: err.Error()})
 }
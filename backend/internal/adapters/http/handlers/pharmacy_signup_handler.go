@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// PharmacySignupHandler serves the public pharmacy signup and the platform admins' review of signups.
+type PharmacySignupHandler struct {
+	signups inbound.PharmacySignupService
+	logger  *zap.Logger
+}
+
+func NewPharmacySignupHandler(signups inbound.PharmacySignupService, logger *zap.Logger) *PharmacySignupHandler {
+	return &PharmacySignupHandler{signups: signups, logger: logger}
+}
+
+// Signup handles POST /public/pharmacy-signup, multipart/form-data with the pharmacy (name, license_no,
+// hostname_slug, business_type, address, phone, email), the owner account (owner_name, owner_email,
+// owner_password) and the license document in "license_document" (PDF or image, max 10MB). The pharmacy stays
+// pending, and its owner cannot sign in, until a platform admin approves it.
+func (h *PharmacySignupHandler) Signup(c *gin.Context) {
+	file, err := c.FormFile("license_document")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "missing license_document in form"})
+		return
+	}
+	if file.Size > maxUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "license document too large (max 10MB)"})
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "failed to read file"})
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxUploadSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "failed to read file"})
+		return
+	}
+	signup, err := h.signups.Signup(c.Request.Context(), inbound.PharmacySignupInput{
+		Name:            c.PostForm("name"),
+		LicenseNo:       c.PostForm("license_no"),
+		HostnameSlug:    c.PostForm("hostname_slug"),
+		BusinessType:    c.PostForm("business_type"),
+		Address:         c.PostForm("address"),
+		Phone:           c.PostForm("phone"),
+		Email:           c.PostForm("email"),
+		OwnerName:       c.PostForm("owner_name"),
+		OwnerEmail:      c.PostForm("owner_email"),
+		OwnerPassword:   c.PostForm("owner_password"),
+		LicenseFilename: file.Filename,
+		License:         data,
+	})
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"pharmacy_id": signup.Pharmacy.ID,
+		"status":      signup.Pharmacy.Status,
+		"message":     "Thanks for signing up. We will email the owner once the pharmacy is approved.",
+	})
+}
+
+// List handles GET /platform/pharmacy-signups?status=pending|active|rejected (default pending).
+func (h *PharmacySignupHandler) List(c *gin.Context) {
+	list, err := h.signups.List(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *PharmacySignupHandler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	signup, err := h.signups.Get(c.Request.Context(), id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, signup)
+}
+
+// Approve activates the pharmacy and its owner and seeds its default config, categories and payment gateways.
+func (h *PharmacySignupHandler) Approve(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	reviewerID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	signup, err := h.signups.Approve(c.Request.Context(), id, reviewerID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, signup)
+}
+
+// Reject turns a signup down. Body: { "reason": "..." }.
+func (h *PharmacySignupHandler) Reject(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	reviewerID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	var body struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	signup, err := h.signups.Reject(c.Request.Context(), id, reviewerID, body.Reason)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, signup)
+}
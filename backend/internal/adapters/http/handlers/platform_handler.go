@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// PlatformHandler serves the platform admins' tenant management. Every change is audited into the target
+// pharmacy's activity log, so its own admins can see what the platform did.
+type PlatformHandler struct {
+	platform           inbound.PlatformService
+	activityLogService inbound.ActivityLogService
+	logger             *zap.Logger
+}
+
+func NewPlatformHandler(platform inbound.PlatformService, activityLogService inbound.ActivityLogService, logger *zap.Logger) *PlatformHandler {
+	return &PlatformHandler{platform: platform, activityLogService: activityLogService, logger: logger}
+}
+
+// ListTenants handles GET /platform/tenants: every pharmacy with its order, user and storage totals.
+func (h *PlatformHandler) ListTenants(c *gin.Context) {
+	list, err := h.platform.ListTenants(c.Request.Context())
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Suspend blocks the pharmacy's logins and hides its public catalog. Body: { "reason": "..." }.
+func (h *PlatformHandler) Suspend(c *gin.Context) {
+	id, actorID, ok := h.target(c)
+	if !ok {
+		return
+	}
+	var body struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	p, err := h.platform.Suspend(c.Request.Context(), id, actorID, body.Reason)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.audit(c, id, actorID, "POST /platform/tenants/:id/suspend", "Pharmacy suspended by platform admin", map[string]interface{}{"reason": p.SuspensionReason})
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *PlatformHandler) Reactivate(c *gin.Context) {
+	id, actorID, ok := h.target(c)
+	if !ok {
+		return
+	}
+	p, err := h.platform.Reactivate(c.Request.Context(), id, actorID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.audit(c, id, actorID, "POST /platform/tenants/:id/reactivate", "Pharmacy reactivated by platform admin", map[string]interface{}{})
+	c.JSON(http.StatusOK, p)
+}
+
+// Impersonate issues a 30-minute token acting as a staff member of the pharmacy, its owner admin by default.
+// Body: { "user_id": "...", "reason": "..." }; the reason is required and goes to the audit log.
+func (h *PlatformHandler) Impersonate(c *gin.Context) {
+	id, actorID, ok := h.target(c)
+	if !ok {
+		return
+	}
+	var body struct {
+		UserID *uuid.UUID `json:"user_id"`
+		Reason string     `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	imp, err := h.platform.Impersonate(c.Request.Context(), id, actorID, body.UserID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.audit(c, id, actorID, "POST /platform/tenants/:id/impersonate", "Platform admin impersonated "+imp.User.Email, map[string]interface{}{
+		"reason": body.Reason, "user_id": imp.User.ID, "expires_at": imp.ExpiresAt,
+	})
+	c.JSON(http.StatusOK, imp)
+}
+
+func (h *PlatformHandler) target(c *gin.Context) (id, actorID uuid.UUID, ok bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	actorID, ok = getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return id, actorID, true
+}
+
+// audit logs the action into the target pharmacy's log; the platform admin usually belongs to another pharmacy.
+func (h *PlatformHandler) audit(c *gin.Context, pharmacyID, actorID uuid.UUID, action, desc string, details map[string]interface{}) {
+	if h.activityLogService == nil {
+		return
+	}
+	details["platform_admin_id"] = actorID
+	raw, _ := json.Marshal(details)
+	if err := h.activityLogService.Create(c.Request.Context(), pharmacyID, actorID, action, desc, "pharmacy", pharmacyID.String(), string(raw), c.ClientIP()); err != nil {
+		h.logger.Warn("failed to audit platform action", zap.String("action", action), zap.Error(err))
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type PreorderHandler struct {
+	preorderService inbound.PreorderService
+	logger          *zap.Logger
+}
+
+func NewPreorderHandler(preorderService inbound.PreorderService, logger *zap.Logger) *PreorderHandler {
+	return &PreorderHandler{preorderService: preorderService, logger: logger}
+}
+
+// ownerScope returns the current user id for end users (role "staff"), who may only see their own preorders.
+func ownerScope(c *gin.Context) *uuid.UUID {
+	if roleVal, ok := c.Get("role"); ok {
+		if roleStr, _ := roleVal.(string); roleStr == "staff" {
+			if userIDStr, ok2 := c.Get("user_id"); ok2 {
+				if uid, err := uuid.Parse(userIDStr.(string)); err == nil {
+					return &uid
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (h *PreorderHandler) Create(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	var req inbound.PreorderInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	p, err := h.preorderService.Create(c.Request.Context(), pharmacyID, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, p)
+}
+
+// List returns preorders. Optional ?status=&product_id=&limit=&offset=. End users see only their own.
+func (h *PreorderHandler) List(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	var status *models.PreorderStatus
+	if s := c.Query("status"); s != "" {
+		st := models.PreorderStatus(s)
+		status = &st
+	}
+	var productID *uuid.UUID
+	if s := c.Query("product_id"); s != "" {
+		if id, err := uuid.Parse(s); err == nil {
+			productID = &id
+		}
+	}
+	limit, offset := 20, 0
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			offset = n
+		}
+	}
+	list, total, err := h.preorderService.List(c.Request.Context(), pharmacyID, status, productID, ownerScope(c), limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total})
+}
+
+func (h *PreorderHandler) GetByID(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	p, err := h.preorderService.GetByID(c.Request.Context(), pharmacyID, id, ownerScope(c))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *PreorderHandler) Cancel(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	p, err := h.preorderService.Cancel(c.Request.Context(), pharmacyID, id, ownerScope(c))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"path/filepath"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/middleware"
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
@@ -52,81 +54,231 @@ func (d *dateOnly) toTime() *time.Time {
 // productBody is used for Create/Update so expiry_date and manufacturing_date accept "YYYY-MM-DD".
 // Required: name, sku. Optional: unit_price (defaults to 0), description, category, category_id (FK; when set, category name is synced), etc.
 type productBody struct {
-	Name               string            `json:"name" binding:"required"`
-	Description        string            `json:"description"`
-	SKU                string            `json:"sku" binding:"required"`
-	Category           string            `json:"category"`
-	CategoryID         *string           `json:"category_id,omitempty"` // optional FK; product type = category (parent) + subcategory
-	UnitPrice          float64           `json:"unit_price" binding:"gte=0"`
-	DiscountPercent    float64           `json:"discount_percent" binding:"gte=0,lte=100"`
-	Currency           string            `json:"currency"`
-	StockQuantity      int               `json:"stock_quantity" binding:"gte=0"`
-	Unit               string            `json:"unit"`
-	RequiresRx         bool              `json:"requires_rx"`
-	IsActive           bool              `json:"is_active"`
-	ExpiryDate         *dateOnly         `json:"expiry_date,omitempty"`
-	ManufacturingDate  *dateOnly         `json:"manufacturing_date,omitempty"`
-	Brand              string            `json:"brand"`
-	Barcode            string            `json:"barcode"`
-	StorageConditions  string            `json:"storage_conditions"`
-	DosageForm         string            `json:"dosage_form"`
-	PackSize           string            `json:"pack_size"`
-	GenericName        string            `json:"generic_name"`
-	Hashtags           []string          `json:"hashtags,omitempty"`
-	Labels             map[string]string `json:"labels,omitempty"`
+	Name                   string            `json:"name" binding:"required"`
+	Description            string            `json:"description"`
+	SKU                    string            `json:"sku" binding:"required"`
+	Category               string            `json:"category"`
+	CategoryID             *string           `json:"category_id,omitempty"` // optional FK; product type = category (parent) + subcategory
+	SupplierID             *string           `json:"supplier_id,omitempty"` // optional mapped supplier for reorder requests
+	UnitPrice              float64           `json:"unit_price" binding:"gte=0"`
+	DiscountPercent        float64           `json:"discount_percent" binding:"gte=0,lte=100"`
+	Currency               string            `json:"currency"`
+	StockQuantity          int               `json:"stock_quantity" binding:"gte=0"`
+	ReorderLevel           *int              `json:"reorder_level,omitempty" binding:"omitempty,gte=0"` // nil = pharmacy default
+	ReorderQuantity        *int              `json:"reorder_quantity,omitempty" binding:"omitempty,gt=0"`
+	MaxStock               *int              `json:"max_stock,omitempty" binding:"omitempty,gt=0"`
+	Unit                   string            `json:"unit"`
+	RequiresRx             bool              `json:"requires_rx"`
+	IsActive               bool              `json:"is_active"`
+	ExpiryDate             *dateOnly         `json:"expiry_date,omitempty"`
+	ManufacturingDate      *dateOnly         `json:"manufacturing_date,omitempty"`
+	Brand                  string            `json:"brand"`
+	Barcode                string            `json:"barcode"`
+	StorageConditions      string            `json:"storage_conditions"`
+	DosageForm             string            `json:"dosage_form"`
+	PackSize               string            `json:"pack_size"`
+	GenericName            string            `json:"generic_name"`
+	TaxClass               string            `json:"tax_class"`
+	PreorderEnabled        bool              `json:"preorder_enabled"`
+	PreorderDepositPercent float64           `json:"preorder_deposit_percent" binding:"gte=0,lte=100"`
+	PreorderExpectedAt     *dateOnly         `json:"preorder_expected_at,omitempty"`
+	TracksSerials          bool              `json:"tracks_serials"`
+	WarrantyMonths         int               `json:"warranty_months" binding:"gte=0,lte=120"`
+	Hashtags               []string          `json:"hashtags,omitempty"`
+	Labels                 map[string]string `json:"labels,omitempty"`
+	Attributes             map[string]any    `json:"attributes,omitempty"` // values for the pharmacy's product attributes
+	// Translations: other language variants keyed by code (e.g. "ne"), title = name, body = description;
+	// omitted on update = keep, {} = clear.
+	Translations models.Translations `json:"translations,omitempty"`
 }
 
 func (b *productBody) toProduct(id uuid.UUID, pharmacyID uuid.UUID) models.Product {
 	p := models.Product{
-		ID:                id,
-		PharmacyID:        pharmacyID,
-		Name:              b.Name,
-		Description:       b.Description,
-		SKU:               b.SKU,
-		Category:          b.Category,
-		UnitPrice:         b.UnitPrice,
-		DiscountPercent:   b.DiscountPercent,
-		Currency:          b.Currency,
-		StockQuantity:     b.StockQuantity,
-		Unit:              b.Unit,
-		RequiresRx:        b.RequiresRx,
-		IsActive:          b.IsActive,
-		Brand:             b.Brand,
-		Barcode:           b.Barcode,
-		StorageConditions: b.StorageConditions,
-		DosageForm:        b.DosageForm,
-		PackSize:          b.PackSize,
-		GenericName:       b.GenericName,
-		Hashtags:          b.Hashtags,
-		Labels:            b.Labels,
+		ID:                     id,
+		PharmacyID:             pharmacyID,
+		Name:                   b.Name,
+		Description:            b.Description,
+		SKU:                    b.SKU,
+		Category:               b.Category,
+		UnitPrice:              b.UnitPrice,
+		DiscountPercent:        b.DiscountPercent,
+		Currency:               b.Currency,
+		StockQuantity:          b.StockQuantity,
+		ReorderLevel:           b.ReorderLevel,
+		ReorderQuantity:        b.ReorderQuantity,
+		MaxStock:               b.MaxStock,
+		Unit:                   b.Unit,
+		RequiresRx:             b.RequiresRx,
+		IsActive:               b.IsActive,
+		Brand:                  b.Brand,
+		Barcode:                b.Barcode,
+		StorageConditions:      b.StorageConditions,
+		DosageForm:             b.DosageForm,
+		PackSize:               b.PackSize,
+		GenericName:            b.GenericName,
+		TaxClass:               b.TaxClass,
+		PreorderEnabled:        b.PreorderEnabled,
+		PreorderDepositPercent: b.PreorderDepositPercent,
+		TracksSerials:          b.TracksSerials,
+		WarrantyMonths:         b.WarrantyMonths,
+		Hashtags:               b.Hashtags,
+		Labels:                 b.Labels,
+		Attributes:             models.CustomFieldValues(b.Attributes),
+		Translations:           b.Translations,
 	}
 	if b.CategoryID != nil && *b.CategoryID != "" {
 		if cid, err := uuid.Parse(*b.CategoryID); err == nil {
 			p.CategoryID = &cid
 		}
 	}
+	if b.SupplierID != nil && *b.SupplierID != "" {
+		if sid, err := uuid.Parse(*b.SupplierID); err == nil {
+			p.SupplierID = &sid
+		}
+	}
 	p.ExpiryDate = b.ExpiryDate.toTime()
 	p.ManufacturingDate = b.ManufacturingDate.toTime()
+	p.PreorderExpectedAt = b.PreorderExpectedAt.toTime()
 	return p
 }
 
 type ProductHandler struct {
-	productService  inbound.ProductService
-	categoryService inbound.CategoryService
-	storage         outbound.FileStorage
-	reviewRepo      outbound.ProductReviewRepository
-	logger          *zap.Logger
+	productService   inbound.ProductService
+	categoryService  inbound.CategoryService
+	hashtagService   inbound.HashtagService
+	flashSaleService inbound.FlashSaleService
+	preorderService  inbound.PreorderService
+	expiryDiscounts  inbound.ExpiryDiscountService
+	currencies       inbound.CurrencyService
+	images           inbound.ProductImageProcessor
+	reviewRepo       outbound.ProductReviewRepository
+	logger           *zap.Logger
 }
 
 // catalogProductResponse extends Product with optional rating stats for catalog listing.
 type catalogProductResponse struct {
 	models.Product
-	RatingAvg   float64 `json:"rating_avg,omitempty"`
-	ReviewCount int     `json:"review_count,omitempty"`
+	RatingAvg             float64                      `json:"rating_avg,omitempty"`
+	ReviewCount           int                          `json:"review_count,omitempty"`
+	FlashSale             *inbound.FlashSaleOffer      `json:"flash_sale,omitempty"`             // live flash-sale price with remaining quantity and countdown
+	ShortExpiry           *inbound.ExpiryDiscountOffer `json:"short_expiry,omitempty"`           // automatic near-expiry markdown and label
+	AlternativesAvailable bool                         `json:"alternatives_available,omitempty"` // a cheaper in-stock product has the same generic and form
+}
+
+// productLiteResponse is the trimmed list item returned with ?quality=low: enough for a product card, with the
+// primary image's low-bandwidth rendition (or the original when none was made).
+type productLiteResponse struct {
+	ID                    uuid.UUID            `json:"id"`
+	Name                  string               `json:"name"`
+	SKU                   string               `json:"sku"`
+	Category              string               `json:"category,omitempty"`
+	UnitPrice             float64              `json:"unit_price"`
+	DiscountPercent       float64              `json:"discount_percent,omitempty"`
+	OfferPrice            *float64             `json:"offer_price,omitempty"` // flash-sale or near-expiry price, when one applies
+	Currency              string               `json:"currency"`
+	Unit                  string               `json:"unit"`
+	StockQuantity         int                  `json:"stock_quantity"`
+	RequiresRx            bool                 `json:"requires_rx,omitempty"`
+	Display               *models.DisplayPrice `json:"display,omitempty"` // price in the storefront's display currency
+	Image                 string               `json:"image,omitempty"`
+	RatingAvg             float64              `json:"rating_avg,omitempty"`
+	AlternativesAvailable bool                 `json:"alternatives_available,omitempty"`
+}
+
+// contentLanguage returns the language to localize content in: the one asked for in Accept-Language, except for
+// pharmacy team members, who edit the default text and its translations.
+func contentLanguage(c *gin.Context) string {
+	if role := c.GetString("role"); role != "" && role != models.RoleStaff {
+		return ""
+	}
+	return c.GetString(middleware.LanguageKey)
+}
+
+// localizeProducts shows each product's name and description in the content language, when it has a variant.
+func localizeProducts(c *gin.Context, list []*models.Product) {
+	if lang := contentLanguage(c); lang != "" {
+		for _, p := range list {
+			p.Localize(lang)
+		}
+	}
+}
+
+// lowQuality reports whether the client asked for low-bandwidth payloads (?quality=low).
+func lowQuality(c *gin.Context) bool {
+	return strings.EqualFold(c.Query("quality"), "low")
+}
+
+func productLite(p *models.Product) productLiteResponse {
+	out := productLiteResponse{ID: p.ID, Name: p.Name, SKU: p.SKU, Category: p.Category, UnitPrice: p.UnitPrice, DiscountPercent: p.DiscountPercent,
+		Currency: p.Currency, Unit: p.Unit, StockQuantity: p.StockQuantity, RequiresRx: p.RequiresRx, Display: p.Display}
+	for _, img := range p.Images {
+		if img.IsPrimary || out.Image == "" {
+			out.Image = lowImageURL(img)
+		}
+	}
+	return out
+}
+
+func lowImageURL(img *models.ProductImage) string {
+	if img.LowURL != "" {
+		return img.LowURL
+	}
+	return img.URL
+}
+
+// useImageVariant points each image's url at its medium (catalog lists) or large (product detail) variant.
+// original_url keeps the upload. Images stored before variants existed, or in a format that could not be
+// resized, keep the original as url.
+func useImageVariant(list []*models.Product, large bool) {
+	for _, p := range list {
+		for _, img := range p.Images {
+			variant := img.MediumURL
+			if large {
+				variant = img.LargeURL
+			}
+			if variant != "" {
+				img.OriginalURL, img.URL = img.URL, variant
+			}
+		}
+	}
 }
 
-func NewProductHandler(productService inbound.ProductService, categoryService inbound.CategoryService, storage outbound.FileStorage, reviewRepo outbound.ProductReviewRepository, logger *zap.Logger) *ProductHandler {
-	return &ProductHandler{productService: productService, categoryService: categoryService, storage: storage, reviewRepo: reviewRepo, logger: logger}
+// productItems returns list with catalog-sized images, or trimmed with ?quality=low.
+func productItems(c *gin.Context, list []*models.Product) interface{} {
+	localizeProducts(c, list)
+	if !lowQuality(c) {
+		useImageVariant(list, false)
+		return list
+	}
+	out := make([]productLiteResponse, len(list))
+	for i, p := range list {
+		out[i] = productLite(p)
+	}
+	return out
+}
+
+func NewProductHandler(productService inbound.ProductService, categoryService inbound.CategoryService, hashtagService inbound.HashtagService, flashSaleService inbound.FlashSaleService, preorderService inbound.PreorderService, expiryDiscounts inbound.ExpiryDiscountService, currencies inbound.CurrencyService, images inbound.ProductImageProcessor, reviewRepo outbound.ProductReviewRepository, logger *zap.Logger) *ProductHandler {
+	return &ProductHandler{productService: productService, categoryService: categoryService, hashtagService: hashtagService, flashSaleService: flashSaleService, preorderService: preorderService, expiryDiscounts: expiryDiscounts, currencies: currencies, images: images, reviewRepo: reviewRepo, logger: logger}
+}
+
+// displayPrices sets Display on each product: its price in ?currency=, else in the pharmacy's display currency,
+// at the stored exchange rate. It writes the error and returns false when the requested currency has no rate.
+func (h *ProductHandler) displayPrices(c *gin.Context, pharmacyID uuid.UUID, list []*models.Product) bool {
+	if h.currencies == nil {
+		return true
+	}
+	rate, err := h.currencies.DisplayRate(c.Request.Context(), pharmacyID, c.Query("currency"))
+	if err != nil {
+		writeServiceError(c, err)
+		return false
+	}
+	if rate == nil {
+		return true
+	}
+	for _, p := range list {
+		p.Display = &models.DisplayPrice{Currency: rate.Currency, UnitPrice: rate.Convert(p.UnitPrice), Rate: rate.Rate, RateAt: rate.UpdatedAt}
+	}
+	return true
 }
 
 func (h *ProductHandler) Create(c *gin.Context) {
@@ -143,6 +295,14 @@ func (h *ProductHandler) Create(c *gin.Context) {
 			p.Category = cat.Name
 		}
 	}
+	if h.hashtagService != nil && len(p.Hashtags) > 0 {
+		tags, err := h.hashtagService.Normalize(c.Request.Context(), pharmacyID, p.Hashtags)
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		p.Hashtags = tags
+	}
 	if err := h.productService.Create(c.Request.Context(), &p); err != nil {
 		writeServiceError(c, err)
 		return
@@ -172,9 +332,44 @@ func (h *ProductHandler) GetByID(c *gin.Context) {
 		c.JSON(http.StatusNotFound, response.ErrorResponse{Code: errors.ErrCodeNotFound, Message: "product not found"})
 		return
 	}
+	localizeProducts(c, []*models.Product{p})
+	if !h.displayPrices(c, p.PharmacyID, []*models.Product{p}) {
+		return
+	}
+	if lowQuality(c) {
+		// Full detail, but images point at their low-bandwidth renditions.
+		for _, img := range p.Images {
+			img.URL = lowImageURL(img)
+		}
+	} else {
+		useImageVariant([]*models.Product{p}, true)
+	}
 	c.JSON(http.StatusOK, p)
 }
 
+// Alternatives lists cheaper in-stock substitutes for a product: same pharmacy, generic name and dosage form
+// (public; query: limit, default 10, max 50).
+func (h *ProductHandler) Alternatives(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	p, list, err := h.productService.Alternatives(c.Request.Context(), id, limit)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"product_id":   p.ID,
+		"generic_name": p.GenericName,
+		"dosage_form":  p.DosageForm,
+		"unit_price":   p.UnitPrice,
+		"items":        productItems(c, list),
+	})
+}
+
 // GetByBarcode returns the product for the current pharmacy with the given barcode (auth required).
 func (h *ProductHandler) GetByBarcode(c *gin.Context) {
 	pharmacyIDStr, _ := c.Get("pharmacy_id")
@@ -216,7 +411,7 @@ func (h *ProductHandler) List(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: err.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"items": list, "total": total})
+		c.JSON(http.StatusOK, gin.H{"items": productItems(c, list), "total": total})
 		return
 	}
 	if limit > 0 {
@@ -225,7 +420,10 @@ func (h *ProductHandler) List(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: err.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"items": list, "total": total})
+		if !h.displayPrices(c, pharmacyID, list) {
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"items": productItems(c, list), "total": total})
 		return
 	}
 	list, err := h.productService.List(c.Request.Context(), pharmacyID, category, inStock)
@@ -233,11 +431,12 @@ func (h *ProductHandler) List(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, list)
+	c.JSON(http.StatusOK, productItems(c, list))
 }
 
 // ListByPharmacyID lists products for a pharmacy by path param (public, no auth).
-// Supports catalog params: q (search), sort (name|price_asc|price_desc|newest), category, in_stock, limit, offset.
+// Supports catalog params: q (search), sort (name|price_asc|price_desc|newest), category, in_stock, limit, offset,
+// and currency (display prices in it instead of the pharmacy's display currency).
 func (h *ProductHandler) ListByPharmacyID(c *gin.Context) {
 	pharmacyID, err := uuid.Parse(c.Param("pharmacyId"))
 	if err != nil {
@@ -257,11 +456,8 @@ func (h *ProductHandler) ListByPharmacyID(c *gin.Context) {
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 	searchQ := strings.TrimSpace(c.Query("q"))
 	sortParam := strings.TrimSpace(strings.ToLower(c.Query("sort")))
-	hashtag := strings.TrimSpace(c.Query("hashtag"))
-	brand := strings.TrimSpace(c.Query("brand"))
-	labelKey := strings.TrimSpace(c.Query("label_key"))
-	labelValue := strings.TrimSpace(c.Query("label_value"))
-	useCatalog := searchQ != "" || sortParam != "" || hashtag != "" || brand != "" || (labelKey != "" && labelValue != "")
+	filters := catalogFilters(c)
+	useCatalog := searchQ != "" || sortParam != "" || filters != nil
 
 	if useCatalog {
 		sortVal := inbound.CatalogSortName
@@ -278,35 +474,66 @@ func (h *ProductHandler) ListByPharmacyID(c *gin.Context) {
 		if limit <= 0 {
 			limit = 12
 		}
-		var filters *inbound.CatalogFilters
-		if hashtag != "" || brand != "" || (labelKey != "" && labelValue != "") {
-			filters = &inbound.CatalogFilters{}
-			if hashtag != "" {
-				filters.Hashtag = &hashtag
-			}
-			if brand != "" {
-				filters.Brand = &brand
-			}
-			if labelKey != "" && labelValue != "" {
-				filters.LabelKey = &labelKey
-				filters.LabelValue = &labelValue
-			}
-		}
 		list, total, err := h.productService.ListCatalog(c.Request.Context(), pharmacyID, category, inStock, searchQ, sortVal, limit, offset, filters)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: err.Error()})
 			return
 		}
+		localizeProducts(c, list)
+		if !h.displayPrices(c, pharmacyID, list) {
+			return
+		}
 		// Enrich with rating stats for catalog display
 		ids := make([]uuid.UUID, len(list))
 		for i, p := range list {
 			ids[i] = p.ID
 		}
 		stats, _ := h.reviewRepo.GetRatingStatsByProductIDs(c.Request.Context(), ids)
+		offers := map[uuid.UUID]*inbound.FlashSaleOffer{}
+		if h.flashSaleService != nil && len(ids) > 0 {
+			live, _ := h.flashSaleService.ListLiveOffers(c.Request.Context(), pharmacyID, ids, 0)
+			for _, o := range live {
+				o.Product = nil
+				offers[o.ProductID] = o
+			}
+		}
+		shortExpiry := map[uuid.UUID]*inbound.ExpiryDiscountOffer{}
+		if h.expiryDiscounts != nil && len(ids) > 0 {
+			markdowns, _ := h.expiryDiscounts.ListOffers(c.Request.Context(), pharmacyID, ids)
+			for _, o := range markdowns {
+				o.Product = nil
+				shortExpiry[o.ProductID] = o
+			}
+		}
+		// The badge is best effort: a failed lookup only hides it.
+		alternatives, err := h.productService.AlternativeFlags(c.Request.Context(), ids)
+		if err != nil {
+			h.logger.Warn("failed to load alternative flags", zap.Error(err))
+		}
+		if lowQuality(c) {
+			items := make([]productLiteResponse, len(list))
+			for i, p := range list {
+				items[i] = productLite(p)
+				items[i].RatingAvg = stats[p.ID].Avg
+				items[i].AlternativesAvailable = alternatives[p.ID]
+				if o := offers[p.ID]; o != nil {
+					items[i].OfferPrice = &o.SalePrice
+				} else if o := shortExpiry[p.ID]; o != nil {
+					items[i].OfferPrice = &o.SalePrice
+				}
+			}
+			c.JSON(http.StatusOK, gin.H{"items": items, "total": total})
+			return
+		}
+		useImageVariant(list, false)
 		items := make([]catalogProductResponse, len(list))
 		for i, p := range list {
 			s := stats[p.ID]
-			items[i] = catalogProductResponse{Product: *p, RatingAvg: s.Avg, ReviewCount: s.Count}
+			items[i] = catalogProductResponse{Product: *p, RatingAvg: s.Avg, ReviewCount: s.Count, FlashSale: offers[p.ID], AlternativesAvailable: alternatives[p.ID]}
+			// A live flash sale takes precedence over the near-expiry markdown (same rule as order pricing).
+			if items[i].FlashSale == nil {
+				items[i].ShortExpiry = shortExpiry[p.ID]
+			}
 		}
 		c.JSON(http.StatusOK, gin.H{"items": items, "total": total})
 		return
@@ -318,7 +545,7 @@ func (h *ProductHandler) ListByPharmacyID(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: err.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"items": list, "total": total})
+		c.JSON(http.StatusOK, gin.H{"items": productItems(c, list), "total": total})
 		return
 	}
 	list, err := h.productService.List(c.Request.Context(), pharmacyID, category, inStock)
@@ -326,7 +553,146 @@ func (h *ProductHandler) ListByPharmacyID(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, list)
+	if !h.displayPrices(c, pharmacyID, list) {
+		return
+	}
+	c.JSON(http.StatusOK, productItems(c, list))
+}
+
+// catalogFilters reads the optional catalog filters (hashtag, brand, label_key+label_value, dosage_form, min_price,
+// max_price); nil when none is set. Unparseable prices are ignored.
+func catalogFilters(c *gin.Context) *inbound.CatalogFilters {
+	f := &inbound.CatalogFilters{}
+	set := false
+	str := func(key string) *string {
+		if v := strings.TrimSpace(c.Query(key)); v != "" {
+			set = true
+			return &v
+		}
+		return nil
+	}
+	price := func(key string) *float64 {
+		if v, err := strconv.ParseFloat(strings.TrimSpace(c.Query(key)), 64); err == nil && v >= 0 {
+			set = true
+			return &v
+		}
+		return nil
+	}
+	f.Hashtag = str("hashtag")
+	f.Brand = str("brand")
+	f.DosageForm = str("dosage_form")
+	f.MinPrice = price("min_price")
+	f.MaxPrice = price("max_price")
+	for key, values := range c.Request.URL.Query() {
+		if name, ok := strings.CutPrefix(key, "attr."); ok && name != "" && len(values) > 0 {
+			if f.Attributes == nil {
+				f.Attributes = map[string]string{}
+			}
+			f.Attributes[name] = values[0]
+			set = true
+		}
+	}
+	labelKey := strings.TrimSpace(c.Query("label_key"))
+	labelValue := strings.TrimSpace(c.Query("label_value"))
+	if labelKey != "" && labelValue != "" {
+		f.LabelKey, f.LabelValue = &labelKey, &labelValue
+		set = true
+	}
+	if !set {
+		return nil
+	}
+	return f
+}
+
+// Facets returns storefront filter-sidebar counts per category, brand, dosage form, price bucket and hashtag.
+// It takes the same query as the catalog list (category, in_stock, q and the catalog filters); each facet ignores
+// its own filter so the other values stay selectable.
+func (h *ProductHandler) Facets(c *gin.Context) {
+	pharmacyID, err := uuid.Parse(c.Param("pharmacyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid pharmacy id"})
+		return
+	}
+	var category *string
+	var inStock *bool
+	if v := c.Query("category"); v != "" {
+		category = &v
+	}
+	if v := c.Query("in_stock"); v == "true" || v == "1" {
+		t := true
+		inStock = &t
+	}
+	facets, err := h.productService.CatalogFacets(c.Request.Context(), pharmacyID, category, inStock, c.Query("q"), catalogFilters(c))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, facets)
+}
+
+// Delta returns id, price, stock and active-status changes since the cursor so offline catalogs (PWA, kiosk) can
+// poll with small payloads. Omit since for a full snapshot; then pass next_since/next_after back until has_more is false.
+func (h *ProductHandler) Delta(c *gin.Context) {
+	pharmacyID, err := uuid.Parse(c.Param("pharmacyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid pharmacy id"})
+		return
+	}
+	var since time.Time
+	if v := c.Query("since"); v != "" {
+		if since, err = time.Parse(time.RFC3339Nano, v); err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "since must be an RFC3339 timestamp"})
+			return
+		}
+	}
+	var after uuid.UUID
+	if v := c.Query("after"); v != "" {
+		if after, err = uuid.Parse(v); err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid after id"})
+			return
+		}
+	}
+	limit := 500
+	if n, ok := parseInt(c.Query("limit")); ok && n > 0 && n <= 1000 {
+		limit = n
+	}
+	page, err := h.productService.Delta(c.Request.Context(), pharmacyID, since, after, limit)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-cache")
+	c.JSON(http.StatusOK, page)
+}
+
+// ListShortExpiryPublic returns products currently marked down for near expiry (storefront clearance shelf).
+func (h *ProductHandler) ListShortExpiryPublic(c *gin.Context) {
+	pharmacyID, err := uuid.Parse(c.Param("pharmacyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid pharmacy id"})
+		return
+	}
+	h.listShortExpiry(c, pharmacyID)
+}
+
+// ListShortExpiry previews which products the pharmacy's expiry discount policy currently marks down.
+func (h *ProductHandler) ListShortExpiry(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	h.listShortExpiry(c, pharmacyID)
+}
+
+func (h *ProductHandler) listShortExpiry(c *gin.Context, pharmacyID uuid.UUID) {
+	if h.expiryDiscounts == nil {
+		c.JSON(http.StatusOK, gin.H{"items": []*inbound.ExpiryDiscountOffer{}, "total": 0})
+		return
+	}
+	offers, err := h.expiryDiscounts.ListOffers(c.Request.Context(), pharmacyID, nil)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": offers, "total": len(offers)})
 }
 
 func (h *ProductHandler) Update(c *gin.Context) {
@@ -337,21 +703,45 @@ func (h *ProductHandler) Update(c *gin.Context) {
 	}
 	pharmacyIDStr, _ := c.Get("pharmacy_id")
 	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
-	var body productBody
+	var body struct {
+		productBody
+		// Stock changes only through PATCH /products/:id/stock and inventory batches, which keep it in step with
+		// the batches; an update that sends it is rejected rather than silently ignored.
+		StockQuantity *int `json:"stock_quantity"`
+	}
 	if err := c.ShouldBindJSON(&body); err != nil {
 		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
 		return
 	}
+	if body.StockQuantity != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "stock_quantity cannot be updated here",
+			Fields: map[string]string{"stock_quantity": "use PATCH /products/:id/stock or an inventory batch"}})
+		return
+	}
 	p := body.toProduct(id, pharmacyID)
 	if p.CategoryID != nil {
 		if cat, err := h.categoryService.GetByID(c.Request.Context(), *p.CategoryID); err == nil && cat != nil && cat.PharmacyID == pharmacyID {
 			p.Category = cat.Name
 		}
 	}
+	if h.hashtagService != nil && len(p.Hashtags) > 0 {
+		tags, err := h.hashtagService.Normalize(c.Request.Context(), pharmacyID, p.Hashtags)
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		p.Hashtags = tags
+	}
 	if err := h.productService.Update(c.Request.Context(), &p); err != nil {
 		writeServiceError(c, err)
 		return
 	}
+	// Product ETA may have changed: update waiting pre-orders and notify customers
+	if h.preorderService != nil && p.PreorderEnabled {
+		if err := h.preorderService.RefreshETAs(c.Request.Context(), pharmacyID, p.ID); err != nil {
+			h.logger.Warn("failed to refresh preorder ETAs", zap.Error(err))
+		}
+	}
 	// Preload category_detail (with parent) for response
 	if p.CategoryID != nil {
 		if cat, _ := h.categoryService.GetByID(c.Request.Context(), *p.CategoryID); cat != nil {
@@ -386,6 +776,29 @@ func (h *ProductHandler) UpdateStock(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "stock updated"})
 }
 
+// RenameBrand renames a brand on all of the pharmacy's products. Body: {from, to}.
+func (h *ProductHandler) RenameBrand(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "pharmacy_id not set"})
+		return
+	}
+	var body struct {
+		From string `json:"from" binding:"required"`
+		To   string `json:"to" binding:"required,max=150"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	n, err := h.productService.RenameBrand(c.Request.Context(), pharmacyID, body.From, body.To)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"updated": n})
+}
+
 func (h *ProductHandler) Delete(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -443,14 +856,16 @@ func (h *ProductHandler) AddImage(c *gin.Context) {
 		return
 	}
 
-	ext := filepath.Ext(file.Filename)
+	ext := strings.ToLower(filepath.Ext(file.Filename))
 	if ext == "" {
 		ext = ".jpg"
 	}
-	path := filepath.Join("photos", "products", productID.String(), time.Now().Format("2006/01"), uuid.New().String()+ext)
-	path = filepath.ToSlash(path)
-
-	url, err := h.storage.Save(c.Request.Context(), path, f, contentType)
+	data, err := io.ReadAll(f)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "failed to read file"})
+		return
+	}
+	stored, err := h.images.Store(c.Request.Context(), productID, data, ext, contentType)
 	if err != nil {
 		h.logger.Error("product image save failed", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "upload failed"})
@@ -458,7 +873,7 @@ func (h *ProductHandler) AddImage(c *gin.Context) {
 	}
 
 	isPrimary := strings.EqualFold(c.PostForm("is_primary"), "true")
-	img, err := h.productService.AddImage(c.Request.Context(), productID, url, isPrimary)
+	img, err := h.productService.AddImage(c.Request.Context(), productID, stored, isPrimary)
 	if err != nil {
 		writeServiceError(c, err)
 		return
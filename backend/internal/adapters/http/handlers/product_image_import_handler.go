@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"archive/zip"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const productImageArchiveMaxSize = 200 << 20 // 200 MiB
+
+type ProductImageImportHandler struct {
+	importService inbound.ProductImageImportService
+	logger        *zap.Logger
+}
+
+func NewProductImageImportHandler(importService inbound.ProductImageImportService, logger *zap.Logger) *ProductImageImportHandler {
+	return &ProductImageImportHandler{importService: importService, logger: logger}
+}
+
+// Import attaches product photos named by SKU ("SKU123_1.jpg"). Multipart form: any number of "files" and/or one
+// "archive" zip; dry_run=true only reports the matches.
+func (h *ProductImageImportHandler) Import(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "pharmacy_id not set"})
+		return
+	}
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "expected a multipart form with files or an archive"})
+		return
+	}
+	var uploads []inbound.ImageUpload
+	for _, fh := range form.File["files"] {
+		fh := fh
+		uploads = append(uploads, inbound.ImageUpload{
+			Name: fh.Filename,
+			Size: fh.Size,
+			Open: func() (io.ReadCloser, error) { return fh.Open() },
+		})
+	}
+	for _, fh := range form.File["archive"] {
+		entries, closer, err := zipUploads(fh)
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		defer closer.Close()
+		uploads = append(uploads, entries...)
+	}
+	res, err := h.importService.Import(c.Request.Context(), pharmacyID, uploads, strings.EqualFold(c.PostForm("dry_run"), "true"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// zipUploads lists the files in an uploaded zip, skipping folders and macOS metadata. The archive stays open
+// until closer is closed.
+func zipUploads(fh *multipart.FileHeader) ([]inbound.ImageUpload, io.Closer, error) {
+	if fh.Size > productImageArchiveMaxSize {
+		return nil, nil, errors.ErrValidation("archive too large (max 200MB)")
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return nil, nil, errors.ErrValidation("failed to read archive")
+	}
+	zr, err := zip.NewReader(f, fh.Size)
+	if err != nil {
+		f.Close()
+		return nil, nil, errors.ErrValidation("archive is not a valid zip file")
+	}
+	var out []inbound.ImageUpload
+	for _, zf := range zr.File {
+		name := zf.Name
+		base := path.Base(name)
+		if zf.FileInfo().IsDir() || strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(base, ".") {
+			continue
+		}
+		zf := zf
+		out = append(out, inbound.ImageUpload{
+			Name: name,
+			Size: int64(zf.UncompressedSize64),
+			Open: func() (io.ReadCloser, error) { return zf.Open() },
+		})
+	}
+	return out, f, nil
+}
@@ -27,6 +27,8 @@ type validatePromoRequest struct {
 	SubTotal  float64 `json:"sub_total" binding:"required,min=0"`
 }
 
+// Validate checks a code against a subtotal. Codes scoped to products or categories, or with a minimum item
+// quantity, need the items and are rejected here; the cart checks them.
 func (h *PromoCodeHandler) Validate(c *gin.Context) {
 	pharmacyIDStr, _ := c.Get("pharmacy_id")
 	userIDStr, _ := c.Get("user_id")
@@ -42,7 +44,7 @@ func (h *PromoCodeHandler) Validate(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
 		return
 	}
-	result, err := h.promoCodeService.Validate(c.Request.Context(), pharmacyID, req.Code, req.SubTotal, userID)
+	result, err := h.promoCodeService.Validate(c.Request.Context(), pharmacyID, req.Code, req.SubTotal, nil, userID)
 	if err != nil {
 		writeServiceError(c, err)
 		return
@@ -71,7 +73,7 @@ func (h *PromoCodeHandler) ValidateQuery(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "sub_total must be a non-negative number"})
 		return
 	}
-	result, err := h.promoCodeService.Validate(c.Request.Context(), pharmacyID, code, subTotal, userID)
+	result, err := h.promoCodeService.Validate(c.Request.Context(), pharmacyID, code, subTotal, nil, userID)
 	if err != nil {
 		writeServiceError(c, err)
 		return
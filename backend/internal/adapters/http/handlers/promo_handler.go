@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,15 +16,18 @@ import (
 )
 
 type PromoHandler struct {
-	promoSvc inbound.PromoService
-	logger   *zap.Logger
+	promoSvc    inbound.PromoService
+	promoImages inbound.PromoImageService
+	logger      *zap.Logger
 }
 
-func NewPromoHandler(promoSvc inbound.PromoService, logger *zap.Logger) *PromoHandler {
-	return &PromoHandler{promoSvc: promoSvc, logger: logger}
+func NewPromoHandler(promoSvc inbound.PromoService, promoImages inbound.PromoImageService, logger *zap.Logger) *PromoHandler {
+	return &PromoHandler{promoSvc: promoSvc, promoImages: promoImages, logger: logger}
 }
 
-// ListPublic returns active promos for a pharmacy (offers, announcements, events). No auth.
+// ListPublic returns promos live right now for a pharmacy (offers, announcements, events). No auth.
+// Optional: type (comma-separated), placement, category_id, limit, offset. With limit > 0 the response is
+// {items, total}; otherwise a plain array.
 func (h *PromoHandler) ListPublic(c *gin.Context) {
 	pharmacyIDStr := c.Param("pharmacyId")
 	pharmacyID, err := uuid.Parse(pharmacyIDStr)
@@ -40,15 +44,127 @@ func (h *PromoHandler) ListPublic(c *gin.Context) {
 			}
 		}
 	}
-	list, err := h.promoSvc.ListByPharmacy(c.Request.Context(), pharmacyID, types, true)
+	q := inbound.PublicPromoQuery{Types: types, Placement: c.Query("placement")}
+	if q.Placement != "" && !models.IsPromoPlacement(q.Placement) {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "unknown placement"})
+		return
+	}
+	if cat := c.Query("category_id"); cat != "" {
+		id, err := uuid.Parse(cat)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid category_id"})
+			return
+		}
+		q.CategoryID = &id
+	}
+	q.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "0"))
+	q.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if q.Limit > 100 {
+		q.Limit = 100
+	}
+	list, total, err := h.promoSvc.ListPublic(c.Request.Context(), pharmacyID, q)
 	if err != nil {
 		h.logger.Warn("promo list public failed", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "failed to list promos"})
 		return
 	}
+	if q.Limit > 0 {
+		c.JSON(http.StatusOK, gin.H{"items": list, "total": total})
+		return
+	}
 	c.JSON(http.StatusOK, list)
 }
 
+type promoImpressionsRequest struct {
+	PromoIDs  []uuid.UUID `json:"promo_ids" binding:"required"`
+	Placement string      `json:"placement"`
+}
+
+// RecordImpressions counts one impression per promo shown on the storefront. No auth.
+func (h *PromoHandler) RecordImpressions(c *gin.Context) {
+	pharmacyID, err := uuid.Parse(c.Param("pharmacyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid pharmacy id"})
+		return
+	}
+	var body promoImpressionsRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	if err := h.promoSvc.RecordImpressions(c.Request.Context(), pharmacyID, body.PromoIDs, body.Placement); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type promoClickRequest struct {
+	Placement string `json:"placement"`
+}
+
+// RecordClick counts a click on a promo. Body is optional. No auth.
+func (h *PromoHandler) RecordClick(c *gin.Context) {
+	pharmacyID, err := uuid.Parse(c.Param("pharmacyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid pharmacy id"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid promo id"})
+		return
+	}
+	var body promoClickRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+			return
+		}
+	}
+	if err := h.promoSvc.RecordClick(c.Request.Context(), pharmacyID, id, body.Placement); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Performance returns impressions, clicks and CTR for a promo, per day and per placement.
+// Query: from, to (YYYY-MM-DD); defaults to the last 30 days.
+func (h *PromoHandler) Performance(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid promo id"})
+		return
+	}
+	to := time.Now()
+	from := to.AddDate(0, 0, -29)
+	if v := c.Query("from"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "from must be YYYY-MM-DD"})
+			return
+		}
+		from = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "to must be YYYY-MM-DD"})
+			return
+		}
+		to = t
+	}
+	report, err := h.promoSvc.Performance(c.Request.Context(), pharmacyID, id, from, to)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
 // List returns all promos for the current pharmacy (admin). Auth required.
 func (h *PromoHandler) List(c *gin.Context) {
 	pharmacyIDStr, ok := c.Get("pharmacy_id")
@@ -110,6 +226,12 @@ type createPromoRequest struct {
 	EndAt       *string `json:"end_at"`
 	SortOrder   int     `json:"sort_order"`
 	IsActive    *bool   `json:"is_active"`
+	// Targeting; on update a nil field keeps the current value and an empty list/string clears it.
+	Placements   []string    `json:"placements"`
+	CategoryIDs  []uuid.UUID `json:"category_ids"`
+	ScheduleDays []string    `json:"schedule_days"`
+	DailyStart   *string     `json:"daily_start"` // HH:MM
+	DailyEnd     *string     `json:"daily_end"`
 }
 
 // Create creates a promo. Admin only.
@@ -134,12 +256,21 @@ func (h *PromoHandler) Create(c *gin.Context) {
 		return
 	}
 	p := &models.Promo{
-		Type:        body.Type,
-		Title:       body.Title,
-		Description: body.Description,
-		ImageURL:    body.ImageURL,
-		LinkURL:     body.LinkURL,
-		SortOrder:   body.SortOrder,
+		Type:         body.Type,
+		Title:        body.Title,
+		Description:  body.Description,
+		ImageURL:     body.ImageURL,
+		LinkURL:      body.LinkURL,
+		SortOrder:    body.SortOrder,
+		Placements:   body.Placements,
+		CategoryIDs:  body.CategoryIDs,
+		ScheduleDays: body.ScheduleDays,
+	}
+	if body.DailyStart != nil {
+		p.DailyStart = *body.DailyStart
+	}
+	if body.DailyEnd != nil {
+		p.DailyEnd = *body.DailyEnd
 	}
 	if body.IsActive != nil {
 		p.IsActive = *body.IsActive
@@ -164,6 +295,10 @@ func (h *PromoHandler) Create(c *gin.Context) {
 	}
 	created, err := h.promoSvc.Create(c.Request.Context(), pharmacyID, p)
 	if err != nil {
+		if errors.GetAppError(err) != nil {
+			writeServiceError(c, err)
+			return
+		}
 		h.logger.Warn("promo create failed", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "failed to create promo"})
 		return
@@ -234,6 +369,9 @@ func (h *PromoHandler) Update(c *gin.Context) {
 	if body.ImageURL == "" {
 		p.ImageURL = existing.ImageURL
 	}
+	if p.ImageURL == existing.ImageURL {
+		p.Images = existing.Images
+	}
 	if body.LinkURL == "" {
 		p.LinkURL = existing.LinkURL
 	}
@@ -260,12 +398,33 @@ func (h *PromoHandler) Update(c *gin.Context) {
 	} else {
 		p.IsActive = existing.IsActive
 	}
+	p.Placements, p.CategoryIDs, p.ScheduleDays = existing.Placements, existing.CategoryIDs, existing.ScheduleDays
+	p.DailyStart, p.DailyEnd = existing.DailyStart, existing.DailyEnd
+	if body.Placements != nil {
+		p.Placements = body.Placements
+	}
+	if body.CategoryIDs != nil {
+		p.CategoryIDs = body.CategoryIDs
+	}
+	if body.ScheduleDays != nil {
+		p.ScheduleDays = body.ScheduleDays
+	}
+	if body.DailyStart != nil {
+		p.DailyStart = *body.DailyStart
+	}
+	if body.DailyEnd != nil {
+		p.DailyEnd = *body.DailyEnd
+	}
 	updated, err := h.promoSvc.Update(c.Request.Context(), pharmacyID, p)
 	if err != nil {
 		if errors.GetAppError(err) != nil && errors.GetAppError(err).Code == errors.ErrCodeNotFound {
 			c.JSON(http.StatusNotFound, response.ErrorResponse{Code: errors.ErrCodeNotFound, Message: "promo not found"})
 			return
 		}
+		if errors.GetAppError(err) != nil {
+			writeServiceError(c, err)
+			return
+		}
 		h.logger.Warn("promo update failed", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "failed to update promo"})
 		return
@@ -273,6 +432,29 @@ func (h *PromoHandler) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, updated)
 }
 
+// UploadImage sets the promo image from a multipart upload (field "file"), generating banner and thumbnail
+// renditions. Admin only.
+func (h *PromoHandler) UploadImage(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid promo id"})
+		return
+	}
+	f, ok := formImage(c)
+	if !ok {
+		return
+	}
+	defer f.Close()
+	p, err := h.promoImages.SetPromoImage(c.Request.Context(), pharmacyID, id, f)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
 // Delete deletes a promo. Admin only.
 func (h *PromoHandler) Delete(c *gin.Context) {
 	pharmacyIDStr, ok := c.Get("pharmacy_id")
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type PurchaseOrderHandler struct {
+	poService inbound.PurchaseOrderService
+	logger    *zap.Logger
+}
+
+func NewPurchaseOrderHandler(poService inbound.PurchaseOrderService, logger *zap.Logger) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{poService: poService, logger: logger}
+}
+
+type createReorderRequest struct {
+	SupplierID       string                           `json:"supplier_id" binding:"required"`
+	Items            []inbound.PurchaseOrderItemInput `json:"items" binding:"omitempty,dive"` // empty = use current suggestions for the supplier
+	ExpectedDelivery *dateOnly                        `json:"expected_delivery_date"`
+	Notes            string                           `json:"notes"`
+}
+
+type supplierResponseRequest struct {
+	Action       string    `json:"action" binding:"required,oneof=confirm decline"`
+	Note         string    `json:"note"`
+	DeliveryDate *dateOnly `json:"delivery_date"`
+}
+
+// ReorderSuggestions returns products at or below their reorder level grouped by supplier.
+// Optional ?threshold= (level for products without one, default 10) and ?days= (sales window, default 30).
+func (h *PurchaseOrderHandler) ReorderSuggestions(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	threshold, days := 0, 0
+	if t := c.Query("threshold"); t != "" {
+		if n, ok := parseInt(t); ok && n > 0 {
+			threshold = n
+		}
+	}
+	if d := c.Query("days"); d != "" {
+		if n, ok := parseInt(d); ok && n > 0 && n <= 365 {
+			days = n
+		}
+	}
+	list, err := h.poService.ReorderSuggestions(c.Request.Context(), pharmacyID, threshold, days)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// CreateReorderRequest records a draft purchase order and emails it to the supplier.
+func (h *PurchaseOrderHandler) CreateReorderRequest(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	var req createReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	supplierID, err := uuid.Parse(req.SupplierID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid supplier_id"})
+		return
+	}
+	po, err := h.poService.CreateReorderRequest(c.Request.Context(), pharmacyID, userID, supplierID, req.Items, req.ExpectedDelivery.toTime(), req.Notes)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, po)
+}
+
+type draftFromSuggestionsRequest struct {
+	SupplierID string `json:"supplier_id" binding:"required"`
+	Threshold  int    `json:"threshold" binding:"gte=0"`
+	Days       int    `json:"days" binding:"gte=0,lte=365"`
+	Notes      string `json:"notes"`
+}
+
+// DraftFromSuggestions saves a supplier's reorder suggestions as a draft purchase order (not emailed).
+func (h *PurchaseOrderHandler) DraftFromSuggestions(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	var req draftFromSuggestionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	supplierID, err := uuid.Parse(req.SupplierID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid supplier_id"})
+		return
+	}
+	po, err := h.poService.DraftFromSuggestions(c.Request.Context(), pharmacyID, userID, supplierID, req.Threshold, req.Days, req.Notes)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, po)
+}
+
+// List returns purchase orders. Optional ?status=&supplier_id=&limit=&offset=.
+func (h *PurchaseOrderHandler) List(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	var status *models.PurchaseOrderStatus
+	if s := c.Query("status"); s != "" {
+		st := models.PurchaseOrderStatus(s)
+		status = &st
+	}
+	var supplierID *uuid.UUID
+	if s := c.Query("supplier_id"); s != "" {
+		if id, err := uuid.Parse(s); err == nil {
+			supplierID = &id
+		}
+	}
+	limit, offset := 20, 0
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			offset = n
+		}
+	}
+	list, total, err := h.poService.List(c.Request.Context(), pharmacyID, status, supplierID, limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total})
+}
+
+func (h *PurchaseOrderHandler) GetByID(c *gin.Context) {
+	h.withPurchaseOrder(c, h.poService.GetByID)
+}
+
+func (h *PurchaseOrderHandler) Resend(c *gin.Context) {
+	h.withPurchaseOrder(c, h.poService.Resend)
+}
+
+func (h *PurchaseOrderHandler) Cancel(c *gin.Context) {
+	h.withPurchaseOrder(c, h.poService.Cancel)
+}
+
+func (h *PurchaseOrderHandler) MarkReceived(c *gin.Context) {
+	h.withPurchaseOrder(c, h.poService.MarkReceived)
+}
+
+// withPurchaseOrder parses pharmacy and :id and writes the result of fn.
+func (h *PurchaseOrderHandler) withPurchaseOrder(c *gin.Context, fn func(ctx context.Context, pharmacyID, id uuid.UUID) (*models.PurchaseOrder, error)) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	po, err := fn(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, po)
+}
+
+// GetForSupplier shows a purchase order to the supplier via the signed link (?token=). No auth.
+func (h *PurchaseOrderHandler) GetForSupplier(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	po, err := h.poService.GetForSupplier(c.Request.Context(), id, c.Query("token"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, po)
+}
+
+// RespondAsSupplier records the supplier's confirm/decline via the signed link (?token=). No auth.
+func (h *PurchaseOrderHandler) RespondAsSupplier(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	var req supplierResponseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	po, err := h.poService.RespondAsSupplier(c.Request.Context(), id, c.Query("token"), req.Action == "confirm", req.Note, req.DeliveryDate.toTime())
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, po)
+}
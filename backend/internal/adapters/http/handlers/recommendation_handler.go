@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// sessionIDPattern is what the storefront may send as X-Session-ID (a random id it keeps in local storage).
+var sessionIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{8,64}$`)
+
+type RecommendationHandler struct {
+	recommendationService inbound.RecommendationService
+	logger                *zap.Logger
+}
+
+func NewRecommendationHandler(recommendationService inbound.RecommendationService, logger *zap.Logger) *RecommendationHandler {
+	return &RecommendationHandler{recommendationService: recommendationService, logger: logger}
+}
+
+// viewer reads the path pharmacy and who is browsing: the signed-in user when their token is for that pharmacy,
+// otherwise the X-Session-ID header (or ?session_id=). It writes the error itself.
+func (h *RecommendationHandler) viewer(c *gin.Context) (uuid.UUID, inbound.StorefrontViewer, bool) {
+	var v inbound.StorefrontViewer
+	pharmacyID, err := uuid.Parse(c.Param("pharmacyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid pharmacy id"})
+		return uuid.Nil, v, false
+	}
+	if userID, ok := getUserID(c); ok {
+		if tokenPharmacy, _ := getPharmacyID(c); tokenPharmacy == pharmacyID {
+			v.UserID = &userID
+			return pharmacyID, v, true
+		}
+	}
+	session := c.GetHeader("X-Session-ID")
+	if session == "" {
+		session = c.Query("session_id")
+	}
+	if session != "" && !sessionIDPattern.MatchString(session) {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid session id"})
+		return uuid.Nil, v, false
+	}
+	v.SessionID = session
+	return pharmacyID, v, true
+}
+
+// RecordView counts a storefront product page view for trending and adds it to the viewer's recently viewed.
+// No auth; a bearer token or X-Session-ID identifies the viewer.
+func (h *RecommendationHandler) RecordView(c *gin.Context) {
+	pharmacyID, viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid product id"})
+		return
+	}
+	if err := h.recommendationService.RecordView(c.Request.Context(), pharmacyID, productID, viewer); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Recommended returns the viewer's recently viewed products and suggestions (?product_id= seeds them with the
+// product being shown; ?limit= up to 24, default 8).
+func (h *RecommendationHandler) Recommended(c *gin.Context) {
+	pharmacyID, viewer, ok := h.viewer(c)
+	if !ok {
+		return
+	}
+	var productID *uuid.UUID
+	if raw := c.Query("product_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid product id"})
+			return
+		}
+		productID = &id
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	out, err := h.recommendationService.Recommended(c.Request.Context(), pharmacyID, viewer, productID, limit)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
@@ -3,6 +3,8 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
@@ -144,6 +146,70 @@ func (h *ReferralHandler) ListPointsTransactions(c *gin.Context) {
 	c.JSON(http.StatusOK, list)
 }
 
+// SetCustomerLanguage sets the language of a customer's order emails and SMS. Body: {"preferred_language": "ne"}.
+func (h *ReferralHandler) SetCustomerLanguage(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "pharmacy_id required"})
+		return
+	}
+	customerID, err := uuid.Parse(c.Param("customerId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid customer id"})
+		return
+	}
+	var body struct {
+		PreferredLanguage string `json:"preferred_language"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	cust, err := h.referralPointsSvc.SetCustomerLanguage(c.Request.Context(), pharmacyID, customerID, body.PreferredLanguage)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cust)
+}
+
+// SetCustomerDateOfBirth records a customer's birthday for the membership birthday gift.
+// Body: {"date_of_birth": "1990-05-17"}; an empty value clears it.
+func (h *ReferralHandler) SetCustomerDateOfBirth(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "pharmacy_id required"})
+		return
+	}
+	customerID, err := uuid.Parse(c.Param("customerId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid customer id"})
+		return
+	}
+	var body struct {
+		DateOfBirth string `json:"date_of_birth"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	var dob *time.Time
+	if v := strings.TrimSpace(body.DateOfBirth); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "date_of_birth must be YYYY-MM-DD"})
+			return
+		}
+		dob = &t
+	}
+	cust, err := h.referralPointsSvc.SetCustomerDateOfBirth(c.Request.Context(), pharmacyID, customerID, dob)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cust)
+}
+
 // GetMyCustomerProfile returns the customer profile for the logged-in user (end-user profile: referral code, points, membership, earned from purchases).
 func (h *ReferralHandler) GetMyCustomerProfile(c *gin.Context) {
 	userIDStr, ok := c.Get("user_id")
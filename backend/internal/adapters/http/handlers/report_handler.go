@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/middleware"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ReportHandler struct {
+	reportService inbound.ReportService
+	logger        *zap.Logger
+}
+
+func NewReportHandler(reportService inbound.ReportService, logger *zap.Logger) *ReportHandler {
+	return &ReportHandler{reportService: reportService, logger: logger}
+}
+
+// parseReportRange reads ?from=YYYY-MM-DD&to=YYYY-MM-DD. "to" is inclusive, so one day is added for the [from, to) query.
+// Missing values are returned as zero time and defaulted by the service.
+func parseReportRange(c *gin.Context) (from, to time.Time, ok bool) {
+	if s := c.Query("from"); s != "" {
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid from date (use YYYY-MM-DD)"})
+			return from, to, false
+		}
+		from = t
+	}
+	if s := c.Query("to"); s != "" {
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid to date (use YYYY-MM-DD)"})
+			return from, to, false
+		}
+		to = t.AddDate(0, 0, 1)
+	}
+	return from, to, true
+}
+
+func wantsCSV(c *gin.Context) bool {
+	return c.Query("format") == "csv"
+}
+
+// writeCSV streams header + rows as a CSV attachment. On routes guarded by middleware.PIIExport the file starts
+// with the download's watermark line and every row carries its export_id, so a leaked copy names who took it.
+func writeCSV(c *gin.Context, filename string, header []string, rows [][]string) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	if g := middleware.ExportGrantFrom(c); g != nil {
+		g.Rows = len(rows)
+		_ = w.Write([]string{g.Watermark()})
+		header = append(header[:len(header):len(header)], "export_id")
+		id := g.ID.String()
+		for i, r := range rows {
+			rows[i] = append(r[:len(r):len(r)], id)
+		}
+	}
+	_ = w.Write(header)
+	_ = w.WriteAll(rows)
+	w.Flush()
+}
+
+// writePDF sends a PDF to open in the browser (and print) rather than download.
+func writePDF(c *gin.Context, filename string, data []byte) {
+	c.Header("Content-Disposition", `inline; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+func money(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+
+// Sales returns the sales summary. Query: from, to, granularity=day|week|month, branch_id, format=csv.
+func (h *ReportHandler) Sales(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+	report, err := h.reportService.SalesSummary(c.Request.Context(), pharmacyID, c.Query("granularity"), from, to, branchIDQuery(c))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if wantsCSV(c) {
+		rows := make([][]string, 0, len(report.Rows))
+		for _, r := range report.Rows {
+			rows = append(rows, []string{r.Period.Format("2006-01-02"), strconv.FormatInt(r.OrdersCount, 10), money(r.SubTotal), money(r.DiscountAmount), money(r.TaxAmount), money(r.Revenue)})
+		}
+		writeCSV(c, "sales-"+report.Granularity+".csv", []string{"period", "orders", "sub_total", "discount", "tax", "revenue"}, rows)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// TopProducts returns best sellers by quantity. Query: from, to, branch_id, limit (default 10, max 100), format=csv.
+func (h *ReportHandler) TopProducts(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+	limit := 10
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 {
+			limit = n
+		}
+	}
+	list, err := h.reportService.TopSellingProducts(c.Request.Context(), pharmacyID, from, to, branchIDQuery(c), limit)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if wantsCSV(c) {
+		rows := make([][]string, 0, len(list))
+		for _, r := range list {
+			rows = append(rows, []string{r.ProductID.String(), r.Name, r.SKU, strconv.FormatInt(r.QuantitySold, 10), strconv.FormatInt(r.OrdersCount, 10), money(r.Revenue)})
+		}
+		writeCSV(c, "top-products.csv", []string{"product_id", "name", "sku", "quantity_sold", "orders", "revenue"}, rows)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list})
+}
+
+// PaymentMethods returns completed payment totals per method. Query: from, to, format=csv.
+func (h *ReportHandler) PaymentMethods(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+	list, err := h.reportService.RevenueByPaymentMethod(c.Request.Context(), pharmacyID, from, to)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if wantsCSV(c) {
+		rows := make([][]string, 0, len(list))
+		for _, r := range list {
+			rows = append(rows, []string{r.Method, strconv.FormatInt(r.PaymentsCount, 10), money(r.Amount)})
+		}
+		writeCSV(c, "revenue-by-payment-method.csv", []string{"method", "payments", "amount"}, rows)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list})
+}
+
+// LowStock returns active products at or below ?threshold= (default 10). Query: format=csv.
+func (h *ReportHandler) LowStock(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	threshold := 0
+	if t := c.Query("threshold"); t != "" {
+		if n, ok := parseInt(t); ok && n >= 0 {
+			threshold = n
+		}
+	}
+	list, err := h.reportService.LowStock(c.Request.Context(), pharmacyID, threshold)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if wantsCSV(c) {
+		rows := make([][]string, 0, len(list))
+		for _, r := range list {
+			rows = append(rows, []string{r.ProductID.String(), r.Name, r.SKU, r.Category, strconv.Itoa(r.StockQuantity), money(r.UnitPrice)})
+		}
+		writeCSV(c, "low-stock.csv", []string{"product_id", "name", "sku", "category", "stock_quantity", "unit_price"}, rows)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list})
+}
+
+// ExpiringStock returns batches expiring in the range (default next 30 days) with value at risk. Query: from, to, format=csv.
+func (h *ReportHandler) ExpiringStock(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+	report, err := h.reportService.ExpiringStock(c.Request.Context(), pharmacyID, from, to)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if wantsCSV(c) {
+		rows := make([][]string, 0, len(report.Rows))
+		for _, r := range report.Rows {
+			rows = append(rows, []string{r.BatchID.String(), r.ProductID.String(), r.Name, r.SKU, r.BatchNumber, strconv.Itoa(r.Quantity), r.ExpiryDate.Format("2006-01-02"), money(r.UnitPrice), money(r.Value)})
+		}
+		writeCSV(c, "expiring-stock.csv", []string{"batch_id", "product_id", "name", "sku", "batch_number", "quantity", "expiry_date", "unit_price", "value"}, rows)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// DeadStock returns products with stock and no sales in ?days= (default 90), most valuable first.
+// Query: min_value, limit, format=csv.
+func (h *ReportHandler) DeadStock(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	days, limit := 0, 0
+	if d := c.Query("days"); d != "" {
+		if n, ok := parseInt(d); ok && n > 0 {
+			days = n
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 {
+			limit = n
+		}
+	}
+	minValue := 0.0
+	if v := c.Query("min_value"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid min_value"})
+			return
+		}
+		minValue = f
+	}
+	report, err := h.reportService.DeadStock(c.Request.Context(), pharmacyID, days, minValue, limit)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if wantsCSV(c) {
+		rows := make([][]string, 0, len(report.Rows))
+		for _, r := range report.Rows {
+			lastSold := ""
+			if r.LastSoldAt != nil {
+				lastSold = r.LastSoldAt.Format("2006-01-02")
+			}
+			rows = append(rows, []string{r.ProductID.String(), r.Name, r.SKU, r.Category, r.SupplierName, strconv.Itoa(r.StockQuantity), money(r.UnitPrice), money(r.StockValue), lastSold})
+		}
+		writeCSV(c, "dead-stock.csv", []string{"product_id", "name", "sku", "category", "supplier", "stock_quantity", "unit_price", "stock_value", "last_sold_at"}, rows)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// Tax returns VAT collected by class and rate. Query: from, to, format=csv.
+func (h *ReportHandler) Tax(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+	report, err := h.reportService.TaxSummary(c.Request.Context(), pharmacyID, from, to)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if wantsCSV(c) {
+		rows := make([][]string, 0, len(report.Rows))
+		for _, r := range report.Rows {
+			rows = append(rows, []string{r.TaxClass, money(r.Rate), money(r.TaxableAmount), money(r.TaxAmount)})
+		}
+		writeCSV(c, "tax.csv", []string{"tax_class", "rate", "taxable_amount", "tax_amount"}, rows)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// SalesRegister lists issued invoices and credit notes in the range; format=csv is the finance export.
+func (h *ReportHandler) SalesRegister(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+	report, err := h.reportService.SalesRegister(c.Request.Context(), pharmacyID, from, to)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if wantsCSV(c) {
+		header := []string{"date", "document_type", "document_number", "reference_number", "order_number", "customer_name", "taxable_amount", "tax_amount", "total_amount"}
+		for _, f := range report.OrderFields {
+			header = append(header, f.Key)
+		}
+		rows := make([][]string, 0, len(report.Rows))
+		for _, r := range report.Rows {
+			row := []string{r.IssuedAt.Format("2006-01-02"), r.DocumentType, r.DocumentNumber, r.ReferenceNumber, r.OrderNumber, r.CustomerName, money(r.TaxableAmount), money(r.TaxAmount), money(r.TotalAmount)}
+			for _, f := range report.OrderFields {
+				row = append(row, r.CustomFields.Text(f.Key))
+			}
+			rows = append(rows, row)
+		}
+		writeCSV(c, "sales-register.csv", header, rows)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
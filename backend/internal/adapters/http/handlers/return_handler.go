@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ReturnHandler serves the staff side of return requests: review, analytics and return-rate flags.
+// Customers submit requests through OrderHandler.
+type ReturnHandler struct {
+	returnService inbound.OrderReturnRequestService
+	files         outbound.PrivateFileStorage // signs evidence links
+	logger        *zap.Logger
+}
+
+func NewReturnHandler(returnService inbound.OrderReturnRequestService, files outbound.PrivateFileStorage, logger *zap.Logger) *ReturnHandler {
+	return &ReturnHandler{returnService: returnService, files: files, logger: logger}
+}
+
+// Reasons returns the defect-reason taxonomy.
+func (h *ReturnHandler) Reasons(c *gin.Context) {
+	c.JSON(http.StatusOK, h.returnService.Reasons())
+}
+
+// List returns the pharmacy's return requests, newest first.
+// Query: status=pending|approved|rejected, reason_code, limit (default 20, max 100), offset.
+func (h *ReturnHandler) List(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	q := inbound.ReturnRequestListQuery{Status: c.Query("status"), ReasonCode: c.Query("reason_code"), Limit: 20}
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 100 {
+			q.Limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			q.Offset = n
+		}
+	}
+	list, total, err := h.returnService.List(c.Request.Context(), pharmacyID, q)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	for i, r := range list {
+		list[i] = signedReturnRequest(c.Request.Context(), h.files, h.logger, r)
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total})
+}
+
+// Review updates a request's status, reason and staff note. Body: {status?, reason_code?, staff_note?}.
+func (h *ReturnHandler) Review(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	var body inbound.ReturnReviewInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	req, err := h.returnService.Review(c.Request.Context(), pharmacyID, id, userID, body)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, signedReturnRequest(c.Request.Context(), h.files, h.logger, req))
+}
+
+// Analytics returns return rates and the reason breakdown.
+// Query: from, to (YYYY-MM-DD, default last 30 days), group_by=product|brand|supplier.
+func (h *ReturnHandler) Analytics(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+	out, err := h.returnService.Analytics(c.Request.Context(), pharmacyID, c.Query("group_by"), from, to)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// ListFlags returns products flagged for a high return rate. Query: status=open|reviewed (default all).
+func (h *ReturnHandler) ListFlags(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	list, err := h.returnService.ListFlags(c.Request.Context(), pharmacyID, c.Query("status"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+type reviewReturnFlagRequest struct {
+	Note string `json:"note"`
+}
+
+// ReviewFlag marks a product's return-rate flag as reviewed.
+func (h *ReturnHandler) ReviewFlag(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	userIDStr, _ := c.Get("user_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	userID, _ := uuid.Parse(userIDStr.(string))
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid product id"})
+		return
+	}
+	var body reviewReturnFlagRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	f, err := h.returnService.ReviewFlag(c.Request.Context(), pharmacyID, productID, userID, body.Note)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, f)
+}
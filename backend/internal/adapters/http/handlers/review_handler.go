@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
 	"github.com/careplus/pharmacy-backend/pkg/errors"
 	"github.com/gin-gonic/gin"
@@ -260,3 +262,61 @@ func parseInt(s string) (int, bool) {
 	n, err := strconv.Atoi(s)
 	return n, err == nil
 }
+
+type reviewResponseRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// CreateResponse posts the pharmacy's official response to a review of one of its products.
+func (h *ReviewHandler) CreateResponse(c *gin.Context) {
+	h.writeResponse(c, http.StatusCreated, h.reviewService.Respond)
+}
+
+// UpdateResponse edits the pharmacy's response.
+func (h *ReviewHandler) UpdateResponse(c *gin.Context) {
+	h.writeResponse(c, http.StatusOK, h.reviewService.UpdateResponse)
+}
+
+func (h *ReviewHandler) writeResponse(c *gin.Context, status int, save func(ctx context.Context, pharmacyID, reviewID, actorID uuid.UUID, body string) (*models.ReviewResponse, error)) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "pharmacy_id not set"})
+		return
+	}
+	userID, _ := getUserID(c)
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	var req reviewResponseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	resp, err := save(c.Request.Context(), pharmacyID, id, userID, req.Body)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(status, resp)
+}
+
+// DeleteResponse removes the pharmacy's response.
+func (h *ReviewHandler) DeleteResponse(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "pharmacy_id not set"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	if err := h.reviewService.DeleteResponse(c.Request.Context(), pharmacyID, id); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/middleware"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type RoleHandler struct {
+	roleService inbound.RoleService
+	logger      *zap.Logger
+}
+
+func NewRoleHandler(roleService inbound.RoleService, logger *zap.Logger) *RoleHandler {
+	return &RoleHandler{roleService: roleService, logger: logger}
+}
+
+// MyPermissions returns the caller's role and permissions so the UI can hide actions it cannot perform.
+func (h *RoleHandler) MyPermissions(c *gin.Context) {
+	perms, err := middleware.Permissions(c, h.roleService)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	list := make([]string, 0, len(perms))
+	for p := range perms {
+		list = append(list, p)
+	}
+	sort.Strings(list)
+	c.JSON(http.StatusOK, gin.H{"role": c.GetString("role"), "permissions": list})
+}
+
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"items": h.roleService.ListPermissions()})
+}
+
+func (h *RoleHandler) List(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	list, err := h.roleService.ListRoles(c.Request.Context(), pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list})
+}
+
+func (h *RoleHandler) Create(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	var req inbound.RoleInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	role, err := h.roleService.CreateRole(c.Request.Context(), pharmacyID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, role)
+}
+
+func (h *RoleHandler) Update(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	var req inbound.RoleInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	role, err := h.roleService.UpdateRole(c.Request.Context(), pharmacyID, c.Param("name"), req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, role)
+}
+
+// Delete removes a custom role, or resets manager/pharmacist to their default permissions.
+func (h *RoleHandler) Delete(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	if err := h.roleService.DeleteRole(c.Request.Context(), pharmacyID, c.Param("name")); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
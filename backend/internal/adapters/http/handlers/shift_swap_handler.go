@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ShiftSwapHandler struct {
+	swapService inbound.ShiftSwapService
+	logger      *zap.Logger
+}
+
+func NewShiftSwapHandler(swapService inbound.ShiftSwapService, logger *zap.Logger) *ShiftSwapHandler {
+	return &ShiftSwapHandler{swapService: swapService, logger: logger}
+}
+
+// caller reads the pharmacy and user from the token and, when withID is set, the swap id. It writes the error itself.
+func (h *ShiftSwapHandler) caller(c *gin.Context, withID bool) (pharmacyID, userID, swapID uuid.UUID, ok bool) {
+	pharmacyID, ok1 := getPharmacyID(c)
+	userID, ok2 := getUserID(c)
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	if withID {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+			return uuid.Nil, uuid.Nil, uuid.Nil, false
+		}
+		swapID = id
+	}
+	return pharmacyID, userID, swapID, true
+}
+
+type swapNoteRequest struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
+// bindNote reads an optional {note} body; an empty body is allowed.
+func bindNote(c *gin.Context) (string, bool) {
+	var req swapNoteRequest
+	if c.Request.ContentLength == 0 {
+		return "", true
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return "", false
+	}
+	return req.Note, true
+}
+
+// List returns the pharmacy's shift swaps (query: status=open|approved|rejected|cancelled).
+func (h *ShiftSwapHandler) List(c *gin.Context) {
+	pharmacyID, _, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	status := models.ShiftSwapStatus(c.Query("status"))
+	switch status {
+	case "", models.ShiftSwapOpen, models.ShiftSwapApproved, models.ShiftSwapRejected, models.ShiftSwapCancelled:
+	default:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid status"})
+		return
+	}
+	list, err := h.swapService.List(c.Request.Context(), pharmacyID, status)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": len(list)})
+}
+
+// Get returns a swap with its requests and audit trail.
+func (h *ShiftSwapHandler) Get(c *gin.Context) {
+	pharmacyID, _, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	sw, err := h.swapService.Get(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sw)
+}
+
+type offerShiftSwapRequest struct {
+	RosterID uuid.UUID `json:"roster_id" binding:"required"`
+	Note     string    `json:"note" binding:"max=500"`
+}
+
+// Offer puts the caller's own published shift up for swap.
+func (h *ShiftSwapHandler) Offer(c *gin.Context) {
+	pharmacyID, userID, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	var req offerShiftSwapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	sw, err := h.swapService.Offer(c.Request.Context(), pharmacyID, userID, req.RosterID, req.Note)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, sw)
+}
+
+// Request asks to take an offered shift.
+func (h *ShiftSwapHandler) Request(c *gin.Context) {
+	pharmacyID, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	note, ok := bindNote(c)
+	if !ok {
+		return
+	}
+	sw, err := h.swapService.Request(c.Request.Context(), pharmacyID, userID, id, note)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sw)
+}
+
+// Withdraw takes back the caller's pending request.
+func (h *ShiftSwapHandler) Withdraw(c *gin.Context) {
+	pharmacyID, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	sw, err := h.swapService.Withdraw(c.Request.Context(), pharmacyID, userID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sw)
+}
+
+// Cancel closes the caller's own open offer.
+func (h *ShiftSwapHandler) Cancel(c *gin.Context) {
+	pharmacyID, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	sw, err := h.swapService.Cancel(c.Request.Context(), pharmacyID, userID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sw)
+}
+
+type approveShiftSwapRequest struct {
+	RequestID uuid.UUID `json:"request_id" binding:"required"`
+	Note      string    `json:"note" binding:"max=500"`
+}
+
+// Approve moves the shift to the chosen requester (roster.manage).
+func (h *ShiftSwapHandler) Approve(c *gin.Context) {
+	pharmacyID, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	var req approveShiftSwapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	sw, err := h.swapService.Approve(c.Request.Context(), pharmacyID, userID, id, req.RequestID, req.Note)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sw)
+}
+
+// Reject closes an open offer without changing the roster (roster.manage).
+func (h *ShiftSwapHandler) Reject(c *gin.Context) {
+	pharmacyID, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	note, ok := bindNote(c)
+	if !ok {
+		return
+	}
+	sw, err := h.swapService.Reject(c.Request.Context(), pharmacyID, userID, id, note)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sw)
+}
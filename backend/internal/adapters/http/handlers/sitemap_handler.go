@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type SitemapHandler struct {
+	sitemapService inbound.SitemapService
+	logger         *zap.Logger
+}
+
+func NewSitemapHandler(sitemapService inbound.SitemapService, logger *zap.Logger) *SitemapHandler {
+	return &SitemapHandler{sitemapService: sitemapService, logger: logger}
+}
+
+// Get serves the storefront's sitemap.xml (no auth). Crawlers may cache it for an hour.
+func (h *SitemapHandler) Get(c *gin.Context) {
+	pharmacyID, err := uuid.Parse(c.Param("pharmacyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid pharmacy id"})
+		return
+	}
+	sm, err := h.sitemapService.Get(c.Request.Context(), pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Header("Last-Modified", sm.GeneratedAt.UTC().Format(http.TimeFormat))
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", []byte(sm.XML))
+}
+
+// Regenerate rebuilds the pharmacy's sitemap now, e.g. after changing site_hostname, and returns its summary.
+func (h *SitemapHandler) Regenerate(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	sm, err := h.sitemapService.Regenerate(c.Request.Context(), pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sm)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type StaffPointsHandler struct {
+	staffPointsService inbound.StaffPointsService
+	logger             *zap.Logger
+}
+
+func NewStaffPointsHandler(staffPointsService inbound.StaffPointsService, logger *zap.Logger) *StaffPointsHandler {
+	return &StaffPointsHandler{staffPointsService: staffPointsService, logger: logger}
+}
+
+// MyHistory returns the caller's points balance and the sales that earned them, newest first (query: limit, offset).
+func (h *StaffPointsHandler) MyHistory(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	limit, offset := 20, 0
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			offset = n
+		}
+	}
+	history, err := h.staffPointsService.History(c.Request.Context(), userID, limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// MonthlyReport is the points leaderboard and per-month totals for payouts (query: from, to; format=csv).
+func (h *StaffPointsHandler) MonthlyReport(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+	report, err := h.staffPointsService.MonthlyReport(c.Request.Context(), pharmacyID, from, to)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if wantsCSV(c) {
+		rows := make([][]string, 0, len(report.Months))
+		for _, r := range report.Months {
+			rows = append(rows, []string{r.Month, strconv.Itoa(r.Rank), r.UserID.String(), r.Name, r.Email, r.Role,
+				strconv.FormatInt(r.SalesCount, 10), money(r.SaleAmount), strconv.FormatInt(r.Points, 10)})
+		}
+		writeCSV(c, "staff-points.csv", []string{"month", "rank", "user_id", "name", "email", "role", "sales_count", "sale_amount", "points"}, rows)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
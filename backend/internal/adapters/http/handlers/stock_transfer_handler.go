@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type StockTransferHandler struct {
+	transferService inbound.StockTransferService
+	logger          *zap.Logger
+}
+
+func NewStockTransferHandler(transferService inbound.StockTransferService, logger *zap.Logger) *StockTransferHandler {
+	return &StockTransferHandler{transferService: transferService, logger: logger}
+}
+
+// caller reads the pharmacy and user from the token and, when withID is set, the transfer id. It writes the error itself.
+func (h *StockTransferHandler) caller(c *gin.Context, withID bool) (pharmacyID, userID, transferID uuid.UUID, ok bool) {
+	pharmacyID, ok1 := getPharmacyID(c)
+	userID, ok2 := getUserID(c)
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	if withID {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+			return uuid.Nil, uuid.Nil, uuid.Nil, false
+		}
+		transferID = id
+	}
+	return pharmacyID, userID, transferID, true
+}
+
+// List returns transfers into or out of the pharmacy (query: direction=incoming|outgoing, status, limit, offset).
+func (h *StockTransferHandler) List(c *gin.Context) {
+	pharmacyID, _, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	status := models.StockTransferStatus(c.Query("status"))
+	switch status {
+	case "", models.StockTransferRequested, models.StockTransferApproved, models.StockTransferInTransit,
+		models.StockTransferReceived, models.StockTransferRejected, models.StockTransferCancelled:
+	default:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid status"})
+		return
+	}
+	limit, offset := 20, 0
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			offset = n
+		}
+	}
+	list, total, err := h.transferService.List(c.Request.Context(), pharmacyID, c.Query("direction"), status, limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total, "limit": limit, "offset": offset})
+}
+
+// Get returns a transfer with its items, batch records and audit trail.
+func (h *StockTransferHandler) Get(c *gin.Context) {
+	pharmacyID, _, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	t, err := h.transferService.Get(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// Request asks a pharmacy of the same group for stock of the caller's own products.
+func (h *StockTransferHandler) Request(c *gin.Context) {
+	pharmacyID, userID, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	var input inbound.StockTransferInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	t, err := h.transferService.Request(c.Request.Context(), pharmacyID, userID, input)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, t)
+}
+
+type transferStep func(ctx context.Context, pharmacyID, userID, id uuid.UUID, note string) (*models.StockTransfer, error)
+
+// step runs one workflow step with an optional {note} body.
+func (h *StockTransferHandler) step(c *gin.Context, run transferStep) {
+	pharmacyID, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	note, ok := bindNote(c)
+	if !ok {
+		return
+	}
+	t, err := run(c.Request.Context(), pharmacyID, userID, id, note)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// Approve is the source pharmacy agreeing to send the stock.
+func (h *StockTransferHandler) Approve(c *gin.Context) { h.step(c, h.transferService.Approve) }
+
+// Reject is the source pharmacy declining a transfer before dispatch.
+func (h *StockTransferHandler) Reject(c *gin.Context) { h.step(c, h.transferService.Reject) }
+
+// Dispatch marks an approved transfer as shipped by the source pharmacy.
+func (h *StockTransferHandler) Dispatch(c *gin.Context) { h.step(c, h.transferService.Dispatch) }
+
+// Receive books the shipped stock out of the source and into the requesting pharmacy.
+func (h *StockTransferHandler) Receive(c *gin.Context) { h.step(c, h.transferService.Receive) }
+
+// Cancel is the requesting pharmacy withdrawing a transfer before dispatch.
+func (h *StockTransferHandler) Cancel(c *gin.Context) { h.step(c, h.transferService.Cancel) }
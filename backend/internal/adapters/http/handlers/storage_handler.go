@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// StorageHandler reports on stored files. The orphan sweep itself runs as a background job.
+type StorageHandler struct {
+	files  inbound.FileCleanupService
+	logger *zap.Logger
+}
+
+func NewStorageHandler(files inbound.FileCleanupService, logger *zap.Logger) *StorageHandler {
+	return &StorageHandler{files: files, logger: logger}
+}
+
+// Orphans runs the orphan sweep as a dry run: it lists the files the next sweep would delete without deleting any.
+func (h *StorageHandler) Orphans(c *gin.Context) {
+	report, err := h.files.Sweep(c.Request.Context(), true)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type StoreCreditHandler struct {
+	storeCreditService inbound.StoreCreditService
+	logger             *zap.Logger
+}
+
+func NewStoreCreditHandler(storeCreditService inbound.StoreCreditService, logger *zap.Logger) *StoreCreditHandler {
+	return &StoreCreditHandler{storeCreditService: storeCreditService, logger: logger}
+}
+
+// caller reads the pharmacy and user from the token and the id in param. It writes the error itself.
+func (h *StoreCreditHandler) caller(c *gin.Context, param string) (pharmacyID, userID, id uuid.UUID, ok bool) {
+	pharmacyID, ok1 := getPharmacyID(c)
+	userID, ok2 := getUserID(c)
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	return pharmacyID, userID, id, true
+}
+
+// Ledger returns a customer's store credit balance and entries, newest first (query: limit, offset).
+func (h *StoreCreditHandler) Ledger(c *gin.Context) {
+	pharmacyID, _, customerID, ok := h.caller(c, "customerId")
+	if !ok {
+		return
+	}
+	limit, offset := 20, 0
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			offset = n
+		}
+	}
+	ledger, err := h.storeCreditService.Ledger(c.Request.Context(), pharmacyID, customerID, limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ledger)
+}
+
+type storeCreditAdjustRequest struct {
+	Amount float64 `json:"amount" binding:"required"` // positive credits, negative debits
+	Note   string  `json:"note" binding:"required,max=500"`
+}
+
+// Adjust records a manual credit or debit on a customer's store credit.
+func (h *StoreCreditHandler) Adjust(c *gin.Context) {
+	pharmacyID, userID, customerID, ok := h.caller(c, "customerId")
+	if !ok {
+		return
+	}
+	var req storeCreditAdjustRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	e, err := h.storeCreditService.Adjust(c.Request.Context(), pharmacyID, customerID, userID, req.Amount, req.Note)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, e)
+}
+
+type storeCreditRefundRequest struct {
+	Amount float64 `json:"amount" binding:"min=0"` // 0 or omitted refunds everything not yet refunded
+	Note   string  `json:"note" binding:"max=500"`
+}
+
+// RefundOrder refunds a completed order to its customer's store credit instead of through the payment gateway.
+func (h *StoreCreditHandler) RefundOrder(c *gin.Context) {
+	pharmacyID, userID, orderID, ok := h.caller(c, "orderId")
+	if !ok {
+		return
+	}
+	var req storeCreditRefundRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+			return
+		}
+	}
+	e, err := h.storeCreditService.RefundOrder(c.Request.Context(), pharmacyID, orderID, userID, req.Amount, req.Note)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, e)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// SubscriptionHandler shows a pharmacy its plan and usage, and lets platform admins renew subscriptions once billing
+// has been settled.
+type SubscriptionHandler struct {
+	subs               inbound.SubscriptionService
+	activityLogService inbound.ActivityLogService
+	logger             *zap.Logger
+}
+
+func NewSubscriptionHandler(subs inbound.SubscriptionService, activityLogService inbound.ActivityLogService, logger *zap.Logger) *SubscriptionHandler {
+	return &SubscriptionHandler{subs: subs, activityLogService: activityLogService, logger: logger}
+}
+
+// Get handles GET /subscription: the signed-in pharmacy's plan, state, grace period end and usage.
+func (h *SubscriptionHandler) Get(c *gin.Context) {
+	pharmacyID, ok := getPharmacyID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	h.status(c, pharmacyID)
+}
+
+// Plans handles GET /subscription/plans: the plan catalog, smallest first.
+func (h *SubscriptionHandler) Plans(c *gin.Context) {
+	list := make([]models.Plan, 0, len(models.Plans))
+	for _, p := range models.Plans {
+		list = append(list, p)
+	}
+	rank := map[string]int{models.PlanTrial: 0, models.PlanBasic: 1, models.PlanStandard: 2, models.PlanPro: 3}
+	sort.Slice(list, func(i, j int) bool { return rank[list[i].Code] < rank[list[j].Code] })
+	c.JSON(http.StatusOK, list)
+}
+
+// GetTenant handles GET /platform/tenants/:id/subscription.
+func (h *SubscriptionHandler) GetTenant(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	h.status(c, id)
+}
+
+// Renew handles POST /platform/tenants/:id/subscription/renew. Body: { "plan": "standard", "months": 12,
+// "reference": "INV-1042" }. Months are added to the current expiry, or to now when it has passed.
+func (h *SubscriptionHandler) Renew(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	actorID, ok := getUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	var body struct {
+		Plan      string `json:"plan" binding:"required"`
+		Months    int    `json:"months" binding:"required"`
+		Reference string `json:"reference"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	sub, err := h.subs.Renew(c.Request.Context(), id, actorID, body.Plan, body.Months, body.Reference)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if h.activityLogService != nil {
+		raw, _ := json.Marshal(map[string]interface{}{
+			"plan": sub.Plan, "months": body.Months, "reference": sub.Reference, "expires_at": sub.ExpiresAt, "platform_admin_id": actorID,
+		})
+		if err := h.activityLogService.Create(c.Request.Context(), id, actorID, "POST /platform/tenants/:id/subscription/renew",
+			"Subscription renewed on the "+sub.Plan+" plan", "subscription", sub.ID.String(), string(raw), c.ClientIP()); err != nil {
+			h.logger.Warn("failed to audit subscription renewal", zap.Error(err))
+		}
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+func (h *SubscriptionHandler) status(c *gin.Context, pharmacyID uuid.UUID) {
+	status, err := h.subs.Get(c.Request.Context(), pharmacyID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
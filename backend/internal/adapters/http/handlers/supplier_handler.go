@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type SupplierHandler struct {
+	supplierService inbound.SupplierService
+	logger          *zap.Logger
+}
+
+func NewSupplierHandler(supplierService inbound.SupplierService, logger *zap.Logger) *SupplierHandler {
+	return &SupplierHandler{supplierService: supplierService, logger: logger}
+}
+
+type supplierRequest struct {
+	Name         string `json:"name" binding:"required"`
+	ContactName  string `json:"contact_name"`
+	Email        string `json:"email" binding:"omitempty,email"`
+	Phone        string `json:"phone"`
+	Address      string `json:"address"`
+	LeadTimeDays int    `json:"lead_time_days" binding:"gte=0"`
+	IsActive     *bool  `json:"is_active"`
+}
+
+func (r *supplierRequest) toSupplier() *models.Supplier {
+	s := &models.Supplier{
+		Name:         r.Name,
+		ContactName:  r.ContactName,
+		Email:        r.Email,
+		Phone:        r.Phone,
+		Address:      r.Address,
+		LeadTimeDays: r.LeadTimeDays,
+		IsActive:     true,
+	}
+	if r.IsActive != nil {
+		s.IsActive = *r.IsActive
+	}
+	return s
+}
+
+func (h *SupplierHandler) Create(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	var req supplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	s, err := h.supplierService.Create(c.Request.Context(), pharmacyID, req.toSupplier())
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, s)
+}
+
+// List returns suppliers for the pharmacy. Optional ?active=true.
+func (h *SupplierHandler) List(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	list, err := h.supplierService.ListByPharmacy(c.Request.Context(), pharmacyID, c.Query("active") == "true")
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *SupplierHandler) GetByID(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	s, err := h.supplierService.GetByID(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
+
+func (h *SupplierHandler) Update(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	var req supplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	s := req.toSupplier()
+	s.ID = id
+	s, err = h.supplierService.Update(c.Request.Context(), pharmacyID, s)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
+
+func (h *SupplierHandler) Delete(c *gin.Context) {
+	pharmacyIDStr, _ := c.Get("pharmacy_id")
+	pharmacyID, _ := uuid.Parse(pharmacyIDStr.(string))
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	if err := h.supplierService.Delete(c.Request.Context(), pharmacyID, id); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
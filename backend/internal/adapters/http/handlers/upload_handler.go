@@ -1,13 +1,14 @@
 package handlers
 
 import (
+	"io"
+	"mime/multipart"
 	"net/http"
-	"path/filepath"
-	"strings"
-	"time"
+	"strconv"
 
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
-	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
 	"github.com/careplus/pharmacy-backend/pkg/errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -15,29 +16,46 @@ import (
 )
 
 const (
-	maxUploadSize = 10 << 20 // 10 MiB
+	maxUploadSize = 10 << 20 // 10 MiB; larger files go through the resumable upload
 )
 
-// Allowed MIME types for photos and general files.
-var allowedTypes = map[string]bool{
-	"image/jpeg": true, "image/png": true, "image/gif": true,
-	"image/webp": true, "image/svg+xml": true,
-	"application/pdf": true,
-	"application/msword": true, "application/vnd.openxmlformats-officedocument.wordprocessingml.document": true,
-}
-
 type UploadHandler struct {
-	storage outbound.FileStorage
+	uploads inbound.UploadService
 	logger  *zap.Logger
 }
 
-func NewUploadHandler(storage outbound.FileStorage, logger *zap.Logger) *UploadHandler {
-	return &UploadHandler{storage: storage, logger: logger}
+func NewUploadHandler(uploads inbound.UploadService, logger *zap.Logger) *UploadHandler {
+	return &UploadHandler{uploads: uploads, logger: logger}
+}
+
+// uploader returns the caller's pharmacy and id: the signed-in user, or the customer on chat routes.
+func uploader(c *gin.Context) (pharmacyID, uploaderID uuid.UUID, ok bool) {
+	pharmacyID, ok = getPharmacyID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	if id, ok := getUserID(c); ok {
+		return pharmacyID, id, true
+	}
+	if v, ok := c.Get("customer_id"); ok {
+		if id, err := uuid.Parse(v.(string)); err == nil {
+			return pharmacyID, id, true
+		}
+	}
+	return uuid.Nil, uuid.Nil, false
 }
 
-// Upload handles POST multipart/form-data with field "file" or "photo".
-// Returns { "url": "...", "path": "...", "filename": "..." }.
+// Upload handles POST multipart/form-data with field "file" or "photo" and an optional "purpose"
+// (file, cv, prescription, chat_video, return_video). The type is sniffed from the content.
+// Returns { "id": "...", "url": "...", "path": "...", "filename": "...", "content_type": "..." }. For private
+// purposes (cv, prescription, return_video) url is a "private:" reference to save on the owning record and
+// preview_url a short-lived signed link to show.
 func (h *UploadHandler) Upload(c *gin.Context) {
+	pharmacyID, uploaderID, ok := uploader(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
 	file, err := c.FormFile("file")
 	if err != nil {
 		file, err = c.FormFile("photo")
@@ -47,7 +65,7 @@ func (h *UploadHandler) Upload(c *gin.Context) {
 		return
 	}
 	if file.Size > maxUploadSize {
-		c.JSON(http.StatusRequestEntityTooLarge, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "file too large (max 10MB)"})
+		c.JSON(http.StatusRequestEntityTooLarge, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "file too large (max 10MB); use a resumable upload"})
 		return
 	}
 	f, err := file.Open()
@@ -56,37 +74,142 @@ func (h *UploadHandler) Upload(c *gin.Context) {
 		return
 	}
 	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxUploadSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "failed to read file"})
+		return
+	}
+
+	u, err := h.uploads.Upload(c.Request.Context(), pharmacyID, uploaderID, c.PostForm("purpose"), file.Filename, data)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"id":           u.ID,
+		"url":          u.URL,
+		"path":         u.Path,
+		"filename":     file.Filename,
+		"content_type": u.ContentType,
+		"preview_url":  u.PreviewURL,
+	})
+}
+
+// StartUpload opens a resumable upload. Body: { "filename", "size", "content_type", "purpose" }. Chunks are
+// then sent with PUT /uploads/:id.
+func (h *UploadHandler) StartUpload(c *gin.Context) {
+	pharmacyID, uploaderID, ok := uploader(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	var in inbound.StartUploadInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	u, err := h.uploads.StartUpload(c.Request.Context(), pharmacyID, uploaderID, in)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Header("Upload-Offset", "0")
+	c.JSON(http.StatusCreated, u)
+}
 
-	contentType := file.Header.Get("Content-Type")
-	if contentType == "" {
-		contentType = "application/octet-stream"
+// UploadChunk writes the raw request body (at most 8 MiB) at the Upload-Offset header, which must equal the
+// bytes received so far. A 409 carries the upload's current offset to resume from.
+func (h *UploadHandler) UploadChunk(c *gin.Context) {
+	pharmacyID, uploaderID, ok := uploader(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid upload id"})
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "Upload-Offset header must be a byte offset"})
+		return
 	}
-	if !allowedTypes[contentType] && !strings.HasPrefix(contentType, "image/") {
-		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "file type not allowed"})
+	if c.Request.ContentLength > models.UploadChunkMaxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "chunk too large (max 8MB)"})
 		return
 	}
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, models.UploadChunkMaxSize)
+	u, err := h.uploads.UploadChunk(c.Request.Context(), pharmacyID, uploaderID, id, offset, body)
+	if err != nil {
+		if appErr := errors.GetAppError(err); appErr != nil && appErr.Code == errors.ErrCodeConflict {
+			if cur, _ := h.uploads.GetUpload(c.Request.Context(), pharmacyID, uploaderID, id); cur != nil {
+				c.Header("Upload-Offset", strconv.FormatInt(cur.Received, 10))
+			}
+		}
+		writeServiceError(c, err)
+		return
+	}
+	c.Header("Upload-Offset", strconv.FormatInt(u.Received, 10))
+	c.JSON(http.StatusOK, u)
+}
 
-	ext := filepath.Ext(file.Filename)
-	if ext == "" {
-		ext = ".bin"
+// GetUpload returns a resumable upload's status and received offset.
+func (h *UploadHandler) GetUpload(c *gin.Context) {
+	pharmacyID, uploaderID, ok := uploader(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid upload id"})
+		return
 	}
-	now := time.Now()
-	subdir := "files"
-	if strings.HasPrefix(contentType, "image/") {
-		subdir = "photos"
+	u, err := h.uploads.GetUpload(c.Request.Context(), pharmacyID, uploaderID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
 	}
-	path := subdir + "/" + now.Format("2006/01") + "/" + uuid.New().String() + ext
+	c.Header("Upload-Offset", strconv.FormatInt(u.Received, 10))
+	c.JSON(http.StatusOK, u)
+}
 
-	url, err := h.storage.Save(c.Request.Context(), path, f, contentType)
+// CancelUpload drops an unfinished resumable upload.
+func (h *UploadHandler) CancelUpload(c *gin.Context) {
+	pharmacyID, uploaderID, ok := uploader(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		h.logger.Error("upload save failed", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "upload failed"})
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid upload id"})
+		return
+	}
+	if err := h.uploads.CancelUpload(c.Request.Context(), pharmacyID, uploaderID, id); err != nil {
+		writeServiceError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, gin.H{"message": "cancelled"})
+}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"url":      url,
-		"path":     path,
-		"filename": file.Filename,
-	})
+// formImage opens the image uploaded as form field "file". On failure it writes the error response and
+// returns false.
+func formImage(c *gin.Context) (multipart.File, bool) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "missing file in form"})
+		return nil, false
+	}
+	if file.Size > maxUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "file too large (max 10MB)"})
+		return nil, false
+	}
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "failed to read file"})
+		return nil, false
+	}
+	return f, true
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 	"github.com/careplus/pharmacy-backend/pkg/errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -16,11 +17,12 @@ import (
 type UsersHandler struct {
 	userService        inbound.UserService
 	activityLogService inbound.ActivityLogService
+	files              outbound.PrivateFileStorage // signs CV links
 	logger             *zap.Logger
 }
 
-func NewUsersHandler(userService inbound.UserService, activityLogService inbound.ActivityLogService, logger *zap.Logger) *UsersHandler {
-	return &UsersHandler{userService: userService, activityLogService: activityLogService, logger: logger}
+func NewUsersHandler(userService inbound.UserService, activityLogService inbound.ActivityLogService, files outbound.PrivateFileStorage, logger *zap.Logger) *UsersHandler {
+	return &UsersHandler{userService: userService, activityLogService: activityLogService, files: files, logger: logger}
 }
 
 // List returns users for the pharmacy; manager sees only pharmacists (enforced by service).
@@ -38,6 +40,9 @@ func (h *UsersHandler) List(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: err.Error()})
 		return
 	}
+	for i, u := range list {
+		list[i] = signedUser(c.Request.Context(), h.files, h.logger, u)
+	}
 	c.JSON(http.StatusOK, list)
 }
 
@@ -48,6 +53,7 @@ type createUserRequest struct {
 	Role     string `json:"role"` // manager, pharmacist, staff (admin only: manager; manager only: pharmacist)
 	// Pharmacist-only (optional when role is pharmacist)
 	LicenseNumber string `json:"license_number"`
+	LicenseExpiresAt string `json:"license_expires_at"` // ISO date YYYY-MM-DD, last valid day
 	Qualification  string `json:"qualification"`
 	CVURL         string `json:"cv_url"`
 	PhotoURL      string `json:"photo_url"`
@@ -75,10 +81,19 @@ func (h *UsersHandler) Create(c *gin.Context) {
 		if req.LicenseNumber != "" {
 			pharmacist.LicenseNumber = &req.LicenseNumber
 		}
+		if req.LicenseExpiresAt != "" {
+			t, err := time.Parse("2006-01-02", req.LicenseExpiresAt)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid license_expires_at (use YYYY-MM-DD)"})
+				return
+			}
+			pharmacist.LicenseExpiresAt = &t
+		}
 		if req.Qualification != "" {
 			pharmacist.Qualification = &req.Qualification
 		}
 		if req.CVURL != "" {
+			req.CVURL = privateFileRef(h.files, req.CVURL)
 			pharmacist.CVURL = &req.CVURL
 		}
 		if req.PhotoURL != "" {
@@ -112,12 +127,16 @@ func (h *UsersHandler) Create(c *gin.Context) {
 				c.JSON(http.StatusNotFound, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
 				return
 			}
+			if appErr.Code == errors.ErrCodeValidation {
+				c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+				return
+			}
 		}
 		h.logger.Error("create user failed", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "Failed to create user"})
 		return
 	}
-	c.JSON(http.StatusCreated, user)
+	c.JSON(http.StatusCreated, signedUser(c.Request.Context(), h.files, h.logger, user))
 }
 
 func (h *UsersHandler) GetByID(c *gin.Context) {
@@ -149,7 +168,7 @@ func (h *UsersHandler) GetByID(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, user)
+	c.JSON(http.StatusOK, signedUser(c.Request.Context(), h.files, h.logger, user))
 }
 
 type updateUserRequest struct {
@@ -158,6 +177,7 @@ type updateUserRequest struct {
 	IsActive *bool  `json:"is_active"`
 	// Pharmacist profile (optional when user is pharmacist)
 	LicenseNumber *string `json:"license_number"`
+	LicenseExpiresAt *string `json:"license_expires_at"` // ISO date YYYY-MM-DD; "" clears it
 	Qualification  *string `json:"qualification"`
 	CVURL         *string `json:"cv_url"`
 	PhotoURL      *string `json:"photo_url"`
@@ -188,8 +208,12 @@ func (h *UsersHandler) Update(c *gin.Context) {
 	if req.Role != "" {
 		rolePtr = &req.Role
 	}
+	if req.CVURL != nil {
+		ref := privateFileRef(h.files, *req.CVURL)
+		req.CVURL = &ref
+	}
 	var pharmacist *inbound.PharmacistProfileInput
-	if req.LicenseNumber != nil || req.Qualification != nil || req.CVURL != nil || req.PhotoURL != nil || req.DateOfBirth != nil || req.Gender != nil || req.Phone != nil {
+	if req.LicenseNumber != nil || req.LicenseExpiresAt != nil || req.Qualification != nil || req.CVURL != nil || req.PhotoURL != nil || req.DateOfBirth != nil || req.Gender != nil || req.Phone != nil {
 		pharmacist = &inbound.PharmacistProfileInput{
 			LicenseNumber: req.LicenseNumber,
 			Qualification: req.Qualification,
@@ -203,6 +227,18 @@ func (h *UsersHandler) Update(c *gin.Context) {
 				pharmacist.DateOfBirth = &t
 			}
 		}
+		if req.LicenseExpiresAt != nil {
+			var t time.Time
+			if *req.LicenseExpiresAt != "" {
+				parsed, err := time.Parse("2006-01-02", *req.LicenseExpiresAt)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid license_expires_at (use YYYY-MM-DD)"})
+					return
+				}
+				t = parsed
+			}
+			pharmacist.LicenseExpiresAt = &t
+		}
 	}
 	user, err := h.userService.Update(c.Request.Context(), pharmacyID, role.(string), userID, req.Name, rolePtr, req.IsActive, pharmacist)
 	if err != nil {
@@ -232,6 +268,9 @@ func (h *UsersHandler) Update(c *gin.Context) {
 		if req.IsActive != nil {
 			changes["is_active"] = *req.IsActive
 		}
+		if req.LicenseExpiresAt != nil {
+			changes["license_expires_at"] = *req.LicenseExpiresAt
+		}
 		if len(changes) > 0 {
 			details, _ := json.Marshal(map[string]interface{}{"user_id": userID.String(), "changes": changes})
 			actorIDVal, _ := c.Get("user_id")
@@ -239,7 +278,7 @@ func (h *UsersHandler) Update(c *gin.Context) {
 			_ = h.activityLogService.Create(c.Request.Context(), pharmacyID, actorID, "PUT /users/"+userID.String(), "User updated", "user", userID.String(), string(details), c.ClientIP())
 		}
 	}
-	c.JSON(http.StatusOK, user)
+	c.JSON(http.StatusOK, signedUser(c.Request.Context(), h.files, h.logger, user))
 }
 
 func (h *UsersHandler) Deactivate(c *gin.Context) {
@@ -278,5 +317,5 @@ func (h *UsersHandler) Deactivate(c *gin.Context) {
 		actorID, _ := uuid.Parse(actorIDVal.(string))
 		_ = h.activityLogService.Create(c.Request.Context(), pharmacyID, actorID, "POST /users/"+userID.String()+"/deactivate", "User deactivated", "user", userID.String(), string(details), c.ClientIP())
 	}
-	c.JSON(http.StatusOK, user)
+	c.JSON(http.StatusOK, signedUser(c.Request.Context(), h.files, h.logger, user))
 }
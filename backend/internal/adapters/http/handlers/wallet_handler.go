@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type WalletHandler struct {
+	walletService inbound.WalletService
+	logger        *zap.Logger
+}
+
+func NewWalletHandler(walletService inbound.WalletService, logger *zap.Logger) *WalletHandler {
+	return &WalletHandler{walletService: walletService, logger: logger}
+}
+
+// caller reads the pharmacy and user from the token. It writes the error itself.
+func (h *WalletHandler) caller(c *gin.Context) (pharmacyID, userID uuid.UUID, ok bool) {
+	pharmacyID, ok1 := getPharmacyID(c)
+	userID, ok2 := getUserID(c)
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return pharmacyID, userID, true
+}
+
+// walletPage reads limit (default 20, at most 100) and offset from the query.
+func walletPage(c *gin.Context) (limit, offset int) {
+	limit, offset = 20, 0
+	if n, ok := parseInt(c.Query("limit")); ok && n > 0 && n <= 100 {
+		limit = n
+	}
+	if n, ok := parseInt(c.Query("offset")); ok && n >= 0 {
+		offset = n
+	}
+	return limit, offset
+}
+
+// Get returns the signed-in user's wallet balance and ledger (query: limit, offset).
+func (h *WalletHandler) Get(c *gin.Context) {
+	pharmacyID, userID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+	limit, offset := walletPage(c)
+	ledger, err := h.walletService.Get(c.Request.Context(), pharmacyID, userID, limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ledger)
+}
+
+// StartTopUp records a pending top-up; the wallet is credited once the gateway payment is confirmed.
+func (h *WalletHandler) StartTopUp(c *gin.Context) {
+	pharmacyID, userID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+	var req inbound.WalletTopUpInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	t, err := h.walletService.StartTopUp(c.Request.Context(), pharmacyID, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, t)
+}
+
+// ListMyTopUps returns the signed-in user's top-ups, newest first.
+func (h *WalletHandler) ListMyTopUps(c *gin.Context) {
+	pharmacyID, userID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+	limit, offset := walletPage(c)
+	list, total, err := h.walletService.ListMyTopUps(c.Request.Context(), pharmacyID, userID, limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total, "limit": limit, "offset": offset})
+}
+
+// ListTopUps returns the pharmacy's top-ups (query: customer_id, status, limit, offset).
+func (h *WalletHandler) ListTopUps(c *gin.Context) {
+	pharmacyID, _, ok := h.caller(c)
+	if !ok {
+		return
+	}
+	q := inbound.WalletTopUpQuery{Status: models.WalletTopUpStatus(c.Query("status"))}
+	if s := c.Query("customer_id"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid customer_id"})
+			return
+		}
+		q.CustomerID = &id
+	}
+	q.Limit, q.Offset = walletPage(c)
+	list, total, err := h.walletService.ListTopUps(c.Request.Context(), pharmacyID, q)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total, "limit": q.Limit, "offset": q.Offset})
+}
+
+type walletTopUpSettleRequest struct {
+	Reference string `json:"reference" binding:"max=255"` // gateway transaction id, on completion
+	Reason    string `json:"reason" binding:"max=500"`    // on failure
+}
+
+// CompleteTopUp confirms a top-up's payment and credits the wallet.
+func (h *WalletHandler) CompleteTopUp(c *gin.Context) {
+	h.settle(c, true)
+}
+
+// FailTopUp closes a top-up whose payment did not go through.
+func (h *WalletHandler) FailTopUp(c *gin.Context) {
+	h.settle(c, false)
+}
+
+func (h *WalletHandler) settle(c *gin.Context, completed bool) {
+	pharmacyID, userID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+		return
+	}
+	var req walletTopUpSettleRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+			return
+		}
+	}
+	var t *models.WalletTopUp
+	if completed {
+		t, err = h.walletService.CompleteTopUp(c.Request.Context(), pharmacyID, id, userID, req.Reference)
+	} else {
+		t, err = h.walletService.FailTopUp(c.Request.Context(), pharmacyID, id, userID, req.Reason)
+	}
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
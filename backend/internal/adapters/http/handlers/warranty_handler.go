@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type WarrantyHandler struct {
+	warrantyService inbound.WarrantyService
+	logger          *zap.Logger
+}
+
+func NewWarrantyHandler(warrantyService inbound.WarrantyService, logger *zap.Logger) *WarrantyHandler {
+	return &WarrantyHandler{warrantyService: warrantyService, logger: logger}
+}
+
+// caller reads the pharmacy and user from the token and, when withID is set, the path id. It writes the error itself.
+func (h *WarrantyHandler) caller(c *gin.Context, withID bool) (pharmacyID, userID, id uuid.UUID, ok bool) {
+	pharmacyID, ok1 := getPharmacyID(c)
+	userID, ok2 := getUserID(c)
+	if !ok1 || !ok2 {
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	if withID {
+		parsed, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid id"})
+			return uuid.Nil, uuid.Nil, uuid.Nil, false
+		}
+		id = parsed
+	}
+	return pharmacyID, userID, id, true
+}
+
+// Check is the public warranty check by serial number (query: serial). Customer details are not returned.
+func (h *WarrantyHandler) Check(c *gin.Context) {
+	pharmacyID, err := uuid.Parse(c.Param("pharmacyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid pharmacy id"})
+		return
+	}
+	list, err := h.warrantyService.Check(c.Request.Context(), pharmacyID, c.Query("serial"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list})
+}
+
+// FindBySerial returns the warranties for a serial number with the customer and claims (query: serial).
+func (h *WarrantyHandler) FindBySerial(c *gin.Context) {
+	pharmacyID, _, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	list, err := h.warrantyService.FindBySerial(c.Request.Context(), pharmacyID, c.Query("serial"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list})
+}
+
+func (h *WarrantyHandler) Get(c *gin.Context) {
+	pharmacyID, _, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	w, err := h.warrantyService.Get(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, w)
+}
+
+// OpenClaim takes a device in on an active warranty.
+func (h *WarrantyHandler) OpenClaim(c *gin.Context) {
+	pharmacyID, userID, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	var req inbound.WarrantyClaimInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	claim, err := h.warrantyService.OpenClaim(c.Request.Context(), pharmacyID, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, claim)
+}
+
+// ListClaims returns claims newest first (query: status, limit, offset).
+func (h *WarrantyHandler) ListClaims(c *gin.Context) {
+	pharmacyID, _, _, ok := h.caller(c, false)
+	if !ok {
+		return
+	}
+	limit, offset := 20, 0
+	if l := c.Query("limit"); l != "" {
+		if n, ok := parseInt(l); ok && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if n, ok := parseInt(o); ok && n >= 0 {
+			offset = n
+		}
+	}
+	list, total, err := h.warrantyService.ListClaims(c.Request.Context(), pharmacyID, models.WarrantyClaimStatus(c.Query("status")), limit, offset)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total, "limit": limit, "offset": offset})
+}
+
+func (h *WarrantyHandler) GetClaim(c *gin.Context) {
+	pharmacyID, _, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	claim, err := h.warrantyService.GetClaim(c.Request.Context(), pharmacyID, id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, claim)
+}
+
+// SendToVendor records that the device went to the vendor, with their reference.
+func (h *WarrantyHandler) SendToVendor(c *gin.Context) {
+	pharmacyID, _, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	var req inbound.WarrantyVendorInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	claim, err := h.warrantyService.SendToVendor(c.Request.Context(), pharmacyID, id, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, claim)
+}
+
+// Resolve closes the claim as repaired, replaced, refunded or rejected.
+func (h *WarrantyHandler) Resolve(c *gin.Context) {
+	pharmacyID, userID, id, ok := h.caller(c, true)
+	if !ok {
+		return
+	}
+	var req inbound.WarrantyResolveInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	claim, err := h.warrantyService.Resolve(c.Request.Context(), pharmacyID, id, userID, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, claim)
+}
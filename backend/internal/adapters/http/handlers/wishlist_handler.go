@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type WishlistHandler struct {
+	wishlistService inbound.WishlistService
+	logger          *zap.Logger
+}
+
+func NewWishlistHandler(wishlistService inbound.WishlistService, logger *zap.Logger) *WishlistHandler {
+	return &WishlistHandler{wishlistService: wishlistService, logger: logger}
+}
+
+// List returns the current user's wishlist with current price and stock.
+func (h *WishlistHandler) List(c *gin.Context) {
+	pharmacyID, userID := cartOwner(c)
+	list, err := h.wishlistService.List(c.Request.Context(), pharmacyID, userID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": len(list)})
+}
+
+type addWishlistItemRequest struct {
+	ProductID uuid.UUID `json:"product_id" binding:"required"`
+}
+
+// Add puts a product on the wishlist; adding it again returns the existing entry.
+func (h *WishlistHandler) Add(c *gin.Context) {
+	pharmacyID, userID := cartOwner(c)
+	var req addWishlistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.BindValidationError(errors.ErrCodeValidation, "Invalid input", err))
+		return
+	}
+	entry, err := h.wishlistService.Add(c.Request.Context(), pharmacyID, userID, req.ProductID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+func (h *WishlistHandler) Remove(c *gin.Context) {
+	pharmacyID, userID := cartOwner(c)
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid product id"})
+		return
+	}
+	if err := h.wishlistService.Remove(c.Request.Context(), pharmacyID, userID, productID); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
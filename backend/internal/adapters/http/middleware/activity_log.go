@@ -93,7 +93,11 @@ func ActivityLog(svc inbound.ActivityLogService, logger *zap.Logger) gin.Handler
 			desc = action
 		}
 		ip := c.ClientIP()
-		if err := svc.Create(c.Request.Context(), pharmacyID, userID, action, desc, "", "", "", ip); err != nil {
+		details := ""
+		if impersonator, ok := c.Get("impersonator_id"); ok {
+			details = `{"impersonated_by":"` + impersonator.(string) + `"}`
+		}
+		if err := svc.Create(c.Request.Context(), pharmacyID, userID, action, desc, "", "", details, ip); err != nil {
 			logger.Debug("activity log create failed", zap.Error(err))
 		}
 		c.Next()
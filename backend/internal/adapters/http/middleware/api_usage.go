@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// APIUsage meters each request against its tenant: the pharmacy in the token, or the :pharmacyId of public
+// routes. Requests with neither, and unmatched paths (404), are not counted.
+func APIUsage(svc inbound.APIUsageService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if svc == nil || route == "" {
+			return
+		}
+		tenant := c.GetString("pharmacy_id")
+		if tenant == "" {
+			tenant = c.Param("pharmacyId")
+		}
+		pharmacyID, err := uuid.Parse(tenant)
+		if err != nil {
+			return
+		}
+		svc.Record(pharmacyID, c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/gin-gonic/gin"
+)
+
+// VersionUsage is the request count for one route of one API version since the process started.
+type VersionUsage struct {
+	Version    string    `json:"version"`
+	Method     string    `json:"method"`
+	Route      string    `json:"route"`
+	Requests   int64     `json:"requests"`
+	Errors     int64     `json:"errors"` // responses with status >= 400
+	Deprecated bool      `json:"deprecated"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+type versionKey struct {
+	version, method, route string
+}
+
+// VersionMetrics counts requests per API version and route, so the team can see which clients
+// still call deprecated endpoints before a version is removed. Counters are in memory and per process.
+type VersionMetrics struct {
+	mu    sync.Mutex
+	stats map[versionKey]*VersionUsage
+}
+
+func NewVersionMetrics() *VersionMetrics {
+	return &VersionMetrics{stats: make(map[versionKey]*VersionUsage)}
+}
+
+func (m *VersionMetrics) record(version, method, route string, status int, deprecated bool) {
+	k := versionKey{version: version, method: method, route: route}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.stats[k]
+	if !ok {
+		u = &VersionUsage{Version: version, Method: method, Route: route}
+		m.stats[k] = u
+	}
+	u.Requests++
+	if status >= http.StatusBadRequest {
+		u.Errors++
+	}
+	u.Deprecated = deprecated
+	u.LastSeenAt = time.Now()
+}
+
+// Snapshot returns a copy of the counters ordered by version, route and method.
+func (m *VersionMetrics) Snapshot() []VersionUsage {
+	m.mu.Lock()
+	out := make([]VersionUsage, 0, len(m.stats))
+	for _, u := range m.stats {
+		out = append(out, *u)
+	}
+	m.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Version != out[j].Version {
+			return out[i].Version < out[j].Version
+		}
+		if out[i].Route != out[j].Route {
+			return out[i].Route < out[j].Route
+		}
+		return out[i].Method < out[j].Method
+	})
+	return out
+}
+
+// APIVersion tags requests with the version of the route group (context key and API-Version header)
+// and records them in metrics. Unmatched paths (404) are not counted.
+func APIVersion(version string, metrics *VersionMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(response.APIVersionKey, version)
+		c.Header("API-Version", version)
+		c.Next()
+		if metrics == nil || c.FullPath() == "" {
+			return
+		}
+		deprecated := c.Writer.Header().Get("Deprecation") != ""
+		metrics.record(version, c.Request.Method, c.FullPath(), c.Writer.Status(), deprecated)
+	}
+}
+
+// Deprecation marks every route of a group as deprecated from deprecatedAt (RFC 9745 Deprecation header)
+// and, when sunsetAt is set, announces its removal date (RFC 8594 Sunset header). Routes that have a
+// counterpart under successorPrefix on the same engine also get a Link rel="successor-version".
+// A zero deprecatedAt disables the middleware.
+func Deprecation(engine *gin.Engine, deprecatedAt, sunsetAt time.Time, prefix, successorPrefix string) gin.HandlerFunc {
+	if deprecatedAt.IsZero() {
+		return func(c *gin.Context) { c.Next() }
+	}
+	deprecation := "@" + strconv.FormatInt(deprecatedAt.Unix(), 10)
+	sunset := ""
+	if !sunsetAt.IsZero() {
+		sunset = sunsetAt.UTC().Format(http.TimeFormat)
+	}
+	// Routes are all registered before the first request, so the successor set is built lazily once.
+	var once sync.Once
+	successors := map[string]bool{}
+	return func(c *gin.Context) {
+		once.Do(func() {
+			for _, r := range engine.Routes() {
+				if strings.HasPrefix(r.Path, successorPrefix+"/") {
+					successors[r.Method+" "+r.Path] = true
+				}
+			}
+		})
+		c.Header("Deprecation", deprecation)
+		if sunset != "" {
+			c.Header("Sunset", sunset)
+		}
+		if route := c.FullPath(); strings.HasPrefix(route, prefix+"/") {
+			next := successorPrefix + strings.TrimPrefix(route, prefix)
+			if successors[c.Request.Method+" "+next] {
+				c.Header("Link", "<"+successorLink(next, c)+">; rel=\"successor-version\"")
+			}
+		}
+		c.Next()
+	}
+}
+
+// successorLink fills route parameters (":id") with the values of the current request.
+func successorLink(route string, c *gin.Context) string {
+	parts := strings.Split(route, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") {
+			parts[i] = c.Param(p[1:])
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -1,9 +1,11 @@
 package middleware
 
 import (
+	"net/http"
 	"strings"
 
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 	"github.com/careplus/pharmacy-backend/pkg/errors"
 	"github.com/gin-gonic/gin"
@@ -14,32 +16,64 @@ func Auth(authProvider outbound.AuthProvider, userRepo outbound.UserRepository,
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(401, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "Missing authorization header"})
+			response.WriteError(c, http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "Missing authorization header"})
 			c.Abort()
 			return
 		}
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(401, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "Invalid authorization header"})
+			response.WriteError(c, http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "Invalid authorization header"})
 			c.Abort()
 			return
 		}
 		claims, err := authProvider.ValidateAccessToken(parts[1])
 		if err != nil {
 			logger.Warn("Invalid access token", zap.Error(err))
-			c.JSON(401, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "Invalid or expired token"})
+			response.WriteError(c, http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "Invalid or expired token"})
 			c.Abort()
 			return
 		}
 		user, err := userRepo.GetByID(c.Request.Context(), claims.UserID)
 		if err != nil || user == nil || !user.IsActive {
-			c.JSON(403, response.ErrorResponse{Code: errors.ErrCodeForbidden, Message: "User not found or inactive"})
+			response.WriteError(c, http.StatusForbidden, response.ErrorResponse{Code: errors.ErrCodeForbidden, Message: "User not found or inactive"})
+			c.Abort()
+			return
+		}
+		// A suspended pharmacy's sessions end at once; platform admins keep theirs, and may still impersonate its
+		// staff for support.
+		if user.Pharmacy != nil && user.Pharmacy.Status == models.PharmacyStatusSuspended && !user.PlatformAdmin && claims.ImpersonatorID == nil {
+			response.WriteError(c, http.StatusForbidden, response.ErrorResponse{Code: errors.ErrCodeForbidden, Message: "Pharmacy is suspended"})
 			c.Abort()
 			return
 		}
 		c.Set("user_id", claims.UserID.String())
 		c.Set("pharmacy_id", claims.PharmacyID.String())
 		c.Set("role", claims.Role)
+		// An impersonation token acts as the user, never with the platform admin's own rights.
+		c.Set("platform_admin", user.PlatformAdmin && claims.ImpersonatorID == nil)
+		if claims.ImpersonatorID != nil {
+			c.Set("impersonator_id", claims.ImpersonatorID.String())
+		}
+		// Repositories only see the signed-in pharmacy's rows from here on (see outbound.WithTenant).
+		c.Request = c.Request.WithContext(outbound.WithTenant(c.Request.Context(), claims.PharmacyID))
+		c.Next()
+	}
+}
+
+// OptionalAuth sets user_id, pharmacy_id and role like Auth when a valid bearer token is sent, and otherwise
+// lets the request through anonymously. For public routes that behave better for signed-in users.
+func OptionalAuth(authProvider outbound.AuthProvider, userRepo outbound.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			if claims, err := authProvider.ValidateAccessToken(parts[1]); err == nil && claims != nil {
+				if user, err := userRepo.GetByID(c.Request.Context(), claims.UserID); err == nil && user != nil && user.IsActive {
+					c.Set("user_id", claims.UserID.String())
+					c.Set("pharmacy_id", claims.PharmacyID.String())
+					c.Set("role", claims.Role)
+				}
+			}
+		}
 		c.Next()
 	}
 }
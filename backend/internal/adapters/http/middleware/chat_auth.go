@@ -42,6 +42,7 @@ func ChatAuth(authProvider outbound.AuthProvider, userRepo outbound.UserReposito
 			c.Set("pharmacy_id", claims.PharmacyID.String())
 			c.Set("role", claims.Role)
 			c.Set("chat_customer", false)
+			c.Request = c.Request.WithContext(outbound.WithTenant(c.Request.Context(), claims.PharmacyID))
 			c.Next()
 			return
 		}
@@ -52,6 +53,7 @@ func ChatAuth(authProvider outbound.AuthProvider, userRepo outbound.UserReposito
 			c.Set("pharmacy_id", chatClaims.PharmacyID.String())
 			c.Set("customer_id", chatClaims.CustomerID.String())
 			c.Set("chat_customer", true)
+			c.Request = c.Request.WithContext(outbound.WithTenant(c.Request.Context(), chatClaims.PharmacyID))
 			c.Next()
 			return
 		}
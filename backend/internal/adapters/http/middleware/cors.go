@@ -44,7 +44,7 @@ func getAllowedOrigin(cfg *config.Config, requestOrigin string) string {
 
 func getAllowedHeaders(cfg *config.Config) string {
 	if len(cfg.CORS.AllowedHeaders) == 0 {
-		return "Content-Type, Authorization"
+		return "Content-Type, Authorization, Idempotency-Key"
 	}
 	return strings.Join(cfg.CORS.AllowedHeaders, ", ")
 }
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// IdempotencyKeyHeader is the request header clients set to make a create request safe to retry.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// Idempotency replays the stored response when a request is retried with the same Idempotency-Key, so a client
+// that lost the first response (flaky mobile network) does not create a second order or payment. Requests
+// without the header run normally. The replayed response carries Idempotent-Replayed: true. Reusing a key with
+// a different body is rejected with 422, and a retry that arrives while the first request is still running
+// gets 409. Use after Auth middleware: keys are per user.
+func Idempotency(svc inbound.IdempotencyService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		userID, err := uuid.Parse(c.GetString("user_id"))
+		if err != nil {
+			c.Next()
+			return
+		}
+		pharmacyID, _ := uuid.Parse(c.GetString("pharmacy_id"))
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.WriteError(c, http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeBadRequest, Message: "failed to read request body"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(body)
+		scope := c.Request.Method + " " + c.FullPath()
+
+		ctx := c.Request.Context()
+		rec, replay, err := svc.Begin(ctx, pharmacyID, userID, scope, key, hex.EncodeToString(sum[:]))
+		if err != nil {
+			writeIdempotencyError(c, err)
+			c.Abort()
+			return
+		}
+		if replay {
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(rec.ResponseStatus, rec.ContentType, rec.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		completed := false
+		defer func() {
+			// The handler panicked: forget the key so the client can retry.
+			if !completed {
+				if err := svc.Release(ctx, rec); err != nil {
+					logger.Warn("failed to release idempotency key", zap.Error(err))
+				}
+			}
+		}()
+		c.Next()
+		completed = true
+		if err := svc.Complete(ctx, rec, w.Status(), w.Header().Get("Content-Type"), w.body.Bytes()); err != nil {
+			logger.Warn("failed to store idempotent response", zap.Error(err), zap.String("scope", scope))
+		}
+	}
+}
+
+func writeIdempotencyError(c *gin.Context, err error) {
+	appErr := errors.GetAppError(err)
+	switch {
+	case appErr != nil && appErr.Code == errors.ErrCodeValidation:
+		response.WriteError(c, http.StatusUnprocessableEntity, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+	case appErr != nil && appErr.Code == errors.ErrCodeConflict:
+		response.WriteError(c, http.StatusConflict, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+	default:
+		response.WriteError(c, http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "failed to check idempotency key"})
+	}
+}
+
+// recordingWriter passes the response through and keeps a copy of the body for replay.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/i18n"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// LanguageKey is the gin context key holding the supported language the client asked for in Accept-Language, or
+// "" when it asked for none. Handlers localize content (product and category names, ...) with it; without one the
+// content stays in the pharmacy's default language.
+const LanguageKey = "language"
+
+// pharmacyLanguageTTL is how long a pharmacy's default_language is cached.
+const pharmacyLanguageTTL = 5 * time.Minute
+
+// maxCachedPharmacyLanguages bounds the cache: public routes take any :pharmacyId, so without a bound a client
+// could grow it without limit. A full cache drops its expired entries, and everything when none are.
+const maxCachedPharmacyLanguages = 10000
+
+// Language sets LanguageKey and translates error messages (message, detail and fields of a JSON error body) into
+// the requested language, else the pharmacy's default_language (the signed-in user's pharmacy, or :pharmacyId on
+// public routes). Bodies of successful responses pass through untouched.
+func Language(configs outbound.PharmacyConfigRepository) gin.HandlerFunc {
+	type entry struct {
+		lang string
+		at   time.Time
+	}
+	var mu sync.Mutex
+	cache := map[uuid.UUID]entry{}
+	pharmacyLanguage := func(c *gin.Context) string {
+		id, err := uuid.Parse(c.GetString("pharmacy_id"))
+		if err != nil {
+			if id, err = uuid.Parse(c.Param("pharmacyId")); err != nil {
+				return i18n.DefaultLanguage
+			}
+		}
+		mu.Lock()
+		e, ok := cache[id]
+		mu.Unlock()
+		if !ok || time.Since(e.at) > pharmacyLanguageTTL {
+			e = entry{lang: i18n.DefaultLanguage, at: time.Now()}
+			if cfg, err := configs.GetByPharmacyID(c.Request.Context(), id); err == nil && cfg != nil {
+				e.lang = models.ResolveLanguage(cfg.DefaultLanguage, i18n.DefaultLanguage)
+			}
+			mu.Lock()
+			if len(cache) >= maxCachedPharmacyLanguages {
+				for k, v := range cache {
+					if time.Since(v.at) > pharmacyLanguageTTL {
+						delete(cache, k)
+					}
+				}
+				if len(cache) >= maxCachedPharmacyLanguages {
+					clear(cache)
+				}
+			}
+			cache[id] = e
+			mu.Unlock()
+		}
+		return e.lang
+	}
+	return func(c *gin.Context) {
+		requested := ""
+		for _, l := range i18n.ParseAcceptLanguage(c.GetHeader("Accept-Language")) {
+			if _, ok := models.SupportedLanguages[l]; ok {
+				requested = l
+				break
+			}
+		}
+		c.Set(LanguageKey, requested)
+		w := &languageWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		if !w.held {
+			return
+		}
+		body := w.buf
+		lang := requested
+		if lang == "" {
+			lang = pharmacyLanguage(c)
+		}
+		if lang != i18n.DefaultLanguage {
+			if translated, ok := translateErrorBody(lang, body); ok {
+				body = translated
+				c.Header("Content-Language", lang)
+			}
+		}
+		_, _ = w.ResponseWriter.Write(body)
+	}
+}
+
+// languageWriter holds back JSON error bodies until the handler is done, so their messages can be translated.
+type languageWriter struct {
+	gin.ResponseWriter
+	buf  []byte
+	held bool
+}
+
+func (w *languageWriter) Write(b []byte) (int, error) {
+	if !w.held && !w.ResponseWriter.Written() && w.Status() >= 400 && strings.Contains(w.Header().Get("Content-Type"), "json") {
+		w.held = true
+	}
+	if w.held {
+		w.buf = append(w.buf, b...)
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *languageWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// translateErrorBody translates an ErrorResponse (v1) or Problem (v2) body; false when it is neither.
+func translateErrorBody(lang string, body []byte) ([]byte, bool) {
+	var e map[string]json.RawMessage
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, false
+	}
+	var fields map[string]string
+	if raw, ok := e["fields"]; ok {
+		if err := json.Unmarshal(raw, &fields); err == nil {
+			for k, v := range fields {
+				fields[k] = i18n.Translate(lang, v)
+			}
+			e["fields"], _ = json.Marshal(fields)
+		}
+	}
+	changed := fields != nil
+	for _, key := range []string{"message", "detail"} {
+		var msg string
+		if raw, ok := e[key]; !ok || json.Unmarshal(raw, &msg) != nil {
+			continue
+		}
+		// Bind errors read "<field>: <rule>"; the field name is the JSON key and stays as is.
+		if field, _, ok := strings.Cut(msg, ": "); ok && fields[field] != "" {
+			msg = field + ": " + fields[field]
+		} else {
+			msg = i18n.Translate(lang, msg)
+		}
+		e[key], _ = json.Marshal(msg)
+		changed = true
+	}
+	if !changed {
+		return nil, false
+	}
+	out, err := json.Marshal(e)
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
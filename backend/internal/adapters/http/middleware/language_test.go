@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/i18n"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestLanguage_TranslatesErrorBodies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	pharmacyID := uuid.New()
+	configLookups := 0
+	configs := &mocks.MockPharmacyConfigRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, id uuid.UUID) (*models.PharmacyConfig, error) {
+			configLookups++
+			return &models.PharmacyConfig{PharmacyID: id, DefaultLanguage: "ne"}, nil
+		},
+	}
+	r := gin.New()
+	r.Use(Language(configs))
+	invalid := response.ErrorResponse{Code: "VALIDATION_ERROR", Message: "Invalid input", Fields: map[string]string{"email": "must be a valid email"}}
+	r.GET("/v1/orders", func(c *gin.Context) { response.WriteError(c, http.StatusBadRequest, invalid) })
+	r.GET("/v2/products", func(c *gin.Context) {
+		c.Set(response.APIVersionKey, "v2")
+		response.WriteError(c, http.StatusNotFound, response.ErrorResponse{Code: "NOT_FOUND", Message: "product not found"})
+	})
+	r.GET("/public/pharmacies/:pharmacyId/products", func(c *gin.Context) {
+		response.WriteError(c, http.StatusTooManyRequests, response.ErrorResponse{Code: "TOO_MANY_REQUESTS", Message: "too many requests, try again later"})
+	})
+	r.GET("/ok", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"message": "Invalid input"}) })
+
+	cases := []struct {
+		name         string
+		path         string
+		acceptLang   string
+		wantStatus   int
+		wantLanguage string
+		wantMessage  string // message (v1) or detail (v2)
+		wantField    string // translated fields["email"], when the body has fields
+	}{
+		{"v1 body", "/v1/orders", "ne-NP,ne;q=0.9,en;q=0.5", http.StatusBadRequest, "ne", i18n.Translate("ne", "Invalid input"), i18n.Translate("ne", "must be a valid email")},
+		{"v2 problem", "/v2/products", "ne", http.StatusNotFound, "ne", i18n.Translate("ne", "product not found"), ""},
+		{"english asked for", "/v1/orders", "en", http.StatusBadRequest, "", "Invalid input", "must be a valid email"},
+		{"pharmacy default language", "/public/pharmacies/" + pharmacyID.String() + "/products", "", http.StatusTooManyRequests, "ne", i18n.Translate("ne", "too many requests, try again later"), ""},
+		{"pharmacy default cached", "/public/pharmacies/" + pharmacyID.String() + "/products", "", http.StatusTooManyRequests, "ne", i18n.Translate("ne", "too many requests, try again later"), ""},
+		{"success passes through", "/ok", "ne", http.StatusOK, "", "Invalid input", ""},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.acceptLang != "" {
+			req.Header.Set("Accept-Language", tc.acceptLang)
+		}
+		r.ServeHTTP(w, req)
+
+		if w.Code != tc.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tc.name, w.Code, tc.wantStatus)
+		}
+		if got := w.Header().Get("Content-Language"); got != tc.wantLanguage {
+			t.Errorf("%s: Content-Language = %q, want %q", tc.name, got, tc.wantLanguage)
+		}
+		var body struct {
+			Message string            `json:"message"`
+			Detail  string            `json:"detail"`
+			Code    string            `json:"code"`
+			Fields  map[string]string `json:"fields"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: held body is not JSON: %q", tc.name, w.Body.String())
+		}
+		if got := body.Message + body.Detail; got != tc.wantMessage {
+			t.Errorf("%s: message = %q, want %q", tc.name, got, tc.wantMessage)
+		}
+		if tc.wantField != "" && body.Fields["email"] != tc.wantField {
+			t.Errorf("%s: fields = %v, want email %q", tc.name, body.Fields, tc.wantField)
+		}
+		if tc.wantStatus >= 400 && body.Code == "" {
+			t.Errorf("%s: code lost from %s", tc.name, w.Body.String())
+		}
+	}
+	if configLookups != 1 {
+		t.Errorf("pharmacy config looked up %d times, want once (cached)", configLookups)
+	}
+	if i18n.Translate("ne", "Invalid input") == "Invalid input" || i18n.Translate("ne", "product not found") == "product not found" {
+		t.Error("test messages have no Nepali translation")
+	}
+}
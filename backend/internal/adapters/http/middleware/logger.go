@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -16,12 +17,16 @@ func Logger(logger *zap.Logger) gin.HandlerFunc {
 		c.Next()
 		statusCode := c.Writer.Status()
 		latency := time.Since(start)
-		logger.Info("request",
+		fields := []zap.Field{
 			zap.Int("status", statusCode),
 			zap.Duration("latency", latency),
 			zap.String("method", method),
 			zap.String("path", path),
 			zap.String("client_ip", clientIP),
-		)
+		}
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			fields = append(fields, zap.String("trace_id", sc.TraceID().String()))
+		}
+		logger.Info("request", fields...)
 	}
 }
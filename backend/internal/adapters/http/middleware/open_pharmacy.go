@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// openPharmacyTTL is how long a pharmacy's status is cached, so a suspension reaches the storefront within it.
+const openPharmacyTTL = 30 * time.Second
+
+// OpenPharmacy answers 404 on public routes with a :pharmacyId of a pharmacy that is not open (awaiting
+// approval, rejected or suspended), hiding its catalog, blog and checkout. Routes without the param pass.
+func OpenPharmacy(pharmacies outbound.PharmacyRepository) gin.HandlerFunc {
+	type entry struct {
+		open bool
+		at   time.Time
+	}
+	var mu sync.Mutex
+	cache := map[uuid.UUID]entry{}
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("pharmacyId"))
+		if err != nil {
+			c.Next()
+			return
+		}
+		mu.Lock()
+		e, ok := cache[id]
+		mu.Unlock()
+		if !ok || time.Since(e.at) > openPharmacyTTL {
+			p, err := pharmacies.GetByID(c.Request.Context(), id)
+			if err != nil || p == nil {
+				// Unknown pharmacies are left to the handler.
+				c.Next()
+				return
+			}
+			e = entry{open: p.Open(), at: time.Now()}
+			mu.Lock()
+			cache[id] = e
+			mu.Unlock()
+		}
+		if !e.open {
+			response.WriteError(c, http.StatusNotFound, response.ErrorResponse{Code: errors.ErrCodeNotFound, Message: "pharmacy not found"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
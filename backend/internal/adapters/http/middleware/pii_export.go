@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// exportGrantKey holds the *inbound.ExportGrant of an authorized PII download.
+const exportGrantKey = "export_grant"
+
+// PIIExport guards an export containing personal data (see models.PIIExportKinds). Unless always is set it only
+// applies to ?format=csv downloads. Members with exports.approve download directly; others pass
+// ?export_request_id= naming their approved request, which covers this one download. The grant is stored for
+// the handler to watermark the file (see ExportGrantFrom), and a successful download is written to the activity
+// log with its row count. Use after a perm() check so HasPermission sees the caller's permissions.
+func PIIExport(svc inbound.ExportApprovalService, kind string, always bool, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !always && c.Query("format") != "csv" {
+			c.Next()
+			return
+		}
+		pharmacyID, err1 := uuid.Parse(c.GetString("pharmacy_id"))
+		userID, err2 := uuid.Parse(c.GetString("user_id"))
+		if err1 != nil || err2 != nil {
+			response.WriteError(c, http.StatusUnauthorized, response.ErrorResponse{Code: errors.ErrCodeUnauthorized, Message: "unauthorized"})
+			c.Abort()
+			return
+		}
+		var requestID *uuid.UUID
+		if s := c.Query("export_request_id"); s != "" {
+			id, err := uuid.Parse(s)
+			if err != nil {
+				response.WriteError(c, http.StatusBadRequest, response.ErrorResponse{Code: errors.ErrCodeValidation, Message: "invalid export_request_id"})
+				c.Abort()
+				return
+			}
+			requestID = &id
+		}
+		grant, err := svc.Authorize(c.Request.Context(), pharmacyID, userID, kind, HasPermission(c, models.PermExportsApprove), requestID)
+		if err != nil {
+			writeExportError(c, err)
+			c.Abort()
+			return
+		}
+		c.Set(exportGrantKey, grant)
+		c.Header("X-Export-Watermark", grant.ID.String())
+		c.Next()
+		if c.Writer.Status() != http.StatusOK {
+			return
+		}
+		filename := c.Request.URL.Path
+		if _, params, err := mime.ParseMediaType(c.Writer.Header().Get("Content-Disposition")); err == nil && params["filename"] != "" {
+			filename = params["filename"]
+		}
+		if err := svc.RecordDownload(c.Request.Context(), grant, filename, c.ClientIP()); err != nil {
+			logger.Warn("failed to log export download", zap.Error(err), zap.String("kind", kind), zap.String("export_id", grant.ID.String()))
+		}
+	}
+}
+
+// ExportGrantFrom returns the grant PIIExport stored for this request, or nil when the route is not guarded.
+func ExportGrantFrom(c *gin.Context) *inbound.ExportGrant {
+	if v, ok := c.Get(exportGrantKey); ok {
+		return v.(*inbound.ExportGrant)
+	}
+	return nil
+}
+
+func writeExportError(c *gin.Context, err error) {
+	appErr := errors.GetAppError(err)
+	switch {
+	case appErr != nil && appErr.Code == errors.ErrCodeForbidden:
+		response.WriteError(c, http.StatusForbidden, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+	case appErr != nil && appErr.Code == errors.ErrCodeNotFound:
+		response.WriteError(c, http.StatusNotFound, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+	default:
+		response.WriteError(c, http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "failed to authorize export"})
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/config"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RateLimitKey picks the bucket a request counts against.
+type RateLimitKey func(c *gin.Context) string
+
+// ByIP counts requests per client IP.
+func ByIP(c *gin.Context) string { return "ip:" + c.ClientIP() }
+
+// ByUser counts requests per signed-in user, or per chat customer on chat-token requests; anonymous requests
+// fall back to the client IP. Use after Auth or ChatAuth.
+func ByUser(c *gin.Context) string {
+	if id := c.GetString("user_id"); id != "" {
+		return "user:" + id
+	}
+	if id := c.GetString("customer_id"); id != "" {
+		return "customer:" + id
+	}
+	return ByIP(c)
+}
+
+// RateLimit allows rule.Limit requests per rule.Window for each key, as a token bucket named name. Over the limit
+// it answers 429 with Retry-After (seconds); allowed responses carry X-RateLimit-Limit and
+// X-RateLimit-Remaining. A nil limiter or zero rule lets everything through, and so does a limiter error, which
+// is logged: an unreachable Redis must not take the login page down with it.
+func RateLimit(limiter outbound.RateLimiter, name string, rule config.RateLimitRule, key RateLimitKey, logger *zap.Logger) gin.HandlerFunc {
+	if limiter == nil || rule.Limit <= 0 || rule.Window <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	limit := strconv.Itoa(rule.Limit)
+	return func(c *gin.Context) {
+		d, err := limiter.Take(c.Request.Context(), "rl:"+name+":"+key(c), rule.Limit, rule.Window)
+		if err != nil {
+			logger.Warn("rate limiter unavailable, allowing request", zap.String("limit", name), zap.Error(err))
+			c.Next()
+			return
+		}
+		c.Header("X-RateLimit-Limit", limit)
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(d.Remaining))
+		if !d.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Max(1, math.Ceil(d.RetryAfter.Seconds())))))
+			response.WriteError(c, http.StatusTooManyRequests, response.ErrorResponse{Code: errors.ErrCodeTooManyRequests, Message: "too many requests, try again later"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/config"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	apperrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// fakeLimiter answers every Take with decision and err, and records the keys it was asked for.
+type fakeLimiter struct {
+	decision outbound.RateLimitDecision
+	err      error
+	keys     []string
+}
+
+func (f *fakeLimiter) Take(ctx context.Context, key string, limit int, window time.Duration) (outbound.RateLimitDecision, error) {
+	f.keys = append(f.keys, key)
+	return f.decision, f.err
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rule := config.RateLimitRule{Limit: 5, Window: time.Minute}
+	cases := []struct {
+		name           string
+		limiter        *fakeLimiter
+		rule           config.RateLimitRule
+		wantStatus     int
+		wantLimit      string
+		wantRemaining  string
+		wantRetryAfter string
+	}{
+		{"allowed", &fakeLimiter{decision: outbound.RateLimitDecision{Allowed: true, Remaining: 3}}, rule, http.StatusOK, "5", "3", ""},
+		{"over the limit", &fakeLimiter{decision: outbound.RateLimitDecision{RetryAfter: 1500 * time.Millisecond}}, rule, http.StatusTooManyRequests, "5", "0", "2"},
+		{"retry under a second", &fakeLimiter{decision: outbound.RateLimitDecision{RetryAfter: 200 * time.Millisecond}}, rule, http.StatusTooManyRequests, "5", "0", "1"},
+		{"limiter error lets the request through", &fakeLimiter{err: errors.New("redis down")}, rule, http.StatusOK, "", "", ""},
+		{"zero rule is off", &fakeLimiter{}, config.RateLimitRule{}, http.StatusOK, "", "", ""},
+	}
+	for _, tc := range cases {
+		r := gin.New()
+		r.GET("/login", RateLimit(tc.limiter, "login", tc.rule, ByIP, zap.NewNop()), func(c *gin.Context) { c.Status(http.StatusOK) })
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/login", nil)
+		req.RemoteAddr = "203.0.113.7:4000"
+		r.ServeHTTP(w, req)
+
+		if w.Code != tc.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tc.name, w.Code, tc.wantStatus)
+		}
+		for header, want := range map[string]string{"X-RateLimit-Limit": tc.wantLimit, "X-RateLimit-Remaining": tc.wantRemaining, "Retry-After": tc.wantRetryAfter} {
+			if got := w.Header().Get(header); got != want {
+				t.Errorf("%s: %s = %q, want %q", tc.name, header, got, want)
+			}
+		}
+		if tc.wantStatus == http.StatusTooManyRequests {
+			var body response.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Code != apperrors.ErrCodeTooManyRequests {
+				t.Errorf("%s: body = %s", tc.name, w.Body.String())
+			}
+		}
+		if tc.rule.Limit > 0 && (len(tc.limiter.keys) != 1 || tc.limiter.keys[0] != "rl:login:ip:203.0.113.7") {
+			t.Errorf("%s: keys = %v", tc.name, tc.limiter.keys)
+		}
+	}
+}
+
+func TestRateLimit_ByIPIgnoresUntrustedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		name    string
+		trusted []string
+		want    []string
+	}{
+		// The router's default (no TRUSTED_PROXIES): a spoofed header cannot pick a fresh bucket.
+		{"no trusted proxies", nil, []string{"rl:login:ip:203.0.113.7", "rl:login:ip:203.0.113.7"}},
+		{"request through a trusted proxy", []string{"203.0.113.0/24"}, []string{"rl:login:ip:198.51.100.1", "rl:login:ip:198.51.100.2"}},
+	}
+	for _, tc := range cases {
+		limiter := &fakeLimiter{decision: outbound.RateLimitDecision{Allowed: true}}
+		r := gin.New()
+		if err := r.SetTrustedProxies(tc.trusted); err != nil {
+			t.Fatal(err)
+		}
+		r.GET("/login", RateLimit(limiter, "login", config.RateLimitRule{Limit: 5, Window: time.Minute}, ByIP, zap.NewNop()), func(c *gin.Context) { c.Status(http.StatusOK) })
+		for _, forwarded := range []string{"198.51.100.1", "198.51.100.2"} {
+			req := httptest.NewRequest(http.MethodGet, "/login", nil)
+			req.RemoteAddr = "203.0.113.7:4000"
+			req.Header.Set("X-Forwarded-For", forwarded)
+			r.ServeHTTP(httptest.NewRecorder(), req)
+		}
+		if len(limiter.keys) != 2 || limiter.keys[0] != tc.want[0] || limiter.keys[1] != tc.want[1] {
+			t.Errorf("%s: keys = %v, want %v", tc.name, limiter.keys, tc.want)
+		}
+	}
+}
@@ -14,7 +14,7 @@ func Recovery(logger *zap.Logger) gin.HandlerFunc {
 		defer func() {
 			if err := recover(); err != nil {
 				logger.Error("panic recovered", zap.Any("panic", err))
-				c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+				response.WriteError(c, http.StatusInternalServerError, response.ErrorResponse{
 					Code:    errors.ErrCodeInternal,
 					Message: "Internal server error",
 				})
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// permissionsKey holds the caller's permission set once resolved, so later checks in the same request reuse it.
+const permissionsKey = "permissions"
+
+// RequirePermission allows the request only if the user's role grants the permission in their pharmacy.
+// Use after Auth middleware. Returns 403 otherwise.
+func RequirePermission(roles inbound.RoleService, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		perms, err := Permissions(c, roles)
+		if err != nil {
+			response.WriteError(c, http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "failed to load permissions"})
+			c.Abort()
+			return
+		}
+		if !perms[permission] {
+			response.WriteError(c, http.StatusForbidden, response.ErrorResponse{Code: errors.ErrCodeForbidden, Message: "missing permission " + permission})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Permissions returns the permission set of the authenticated user, resolving it once per request.
+func Permissions(c *gin.Context, roles inbound.RoleService) (map[string]bool, error) {
+	if v, ok := c.Get(permissionsKey); ok {
+		return v.(map[string]bool), nil
+	}
+	pharmacyID, _ := uuid.Parse(c.GetString("pharmacy_id"))
+	perms, err := roles.Permissions(c.Request.Context(), pharmacyID, c.GetString("role"))
+	if err != nil {
+		return nil, err
+	}
+	c.Set(permissionsKey, perms)
+	return perms, nil
+}
+
+// HasPermission reports whether a permission check earlier in the request already loaded this permission.
+// Handlers use it for finer checks inside routes guarded by RequirePermission.
+func HasPermission(c *gin.Context, permission string) bool {
+	v, ok := c.Get(permissionsKey)
+	if !ok {
+		return false
+	}
+	return v.(map[string]bool)[permission]
+}
@@ -4,23 +4,23 @@ import (
 	"net/http"
 
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 	"github.com/careplus/pharmacy-backend/pkg/errors"
 	"github.com/gin-gonic/gin"
 )
 
 // Role constants used across middleware and handlers.
 const (
-	RoleAdmin      = "admin"
-	RoleManager    = "manager"
-	RolePharmacist = "pharmacist"
-	RoleStaff      = "staff" // end-user / buyer
+	RoleAdmin      = models.RoleAdmin
+	RoleManager    = models.RoleManager
+	RolePharmacist = models.RolePharmacist
+	RoleStaff      = models.RoleStaff // end-user / buyer
 )
 
-// StaffRoles are roles that can access management features (excludes end-user "staff").
-var StaffRoles = []string{RoleAdmin, RoleManager, RolePharmacist}
-
 // RequireAnyRole returns a middleware that allows only the given roles.
 // Use after Auth middleware. Returns 403 if the user's role is not in the list.
+// Management routes use RequirePermission instead, so pharmacies can configure who may do what.
 func RequireAnyRole(allowedRoles ...string) gin.HandlerFunc {
 	set := make(map[string]bool)
 	for _, r := range allowedRoles {
@@ -43,8 +43,16 @@ func RequireAnyRole(allowedRoles ...string) gin.HandlerFunc {
 	}
 }
 
-// RequireStaffRole allows admin, manager, or pharmacist (not end-user "staff").
-// Use for product/category/inventory/invoice/payment management and similar.
-func RequireStaffRole() gin.HandlerFunc {
-	return RequireAnyRole(StaffRoles...)
+// RequirePlatformAdmin allows only platform admins (models.User.PlatformAdmin), who run the platform rather than a
+// pharmacy, and lifts the tenant scope for them. Use after Auth middleware; no pharmacy role or permission grants it.
+func RequirePlatformAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if admin, _ := c.Get("platform_admin"); admin != true {
+			c.JSON(http.StatusForbidden, response.ErrorResponse{Code: errors.ErrCodeForbidden, Message: "platform admin only"})
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(outbound.WithoutTenant(c.Request.Context()))
+		c.Next()
+	}
 }
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/adapters/http/dto/response"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Subscription answers 402 on writes of a pharmacy whose subscription expired past its grace period; reads keep
+// working so the pharmacy can still see its data and renew. Platform admins are not blocked.
+func Subscription(subs inbound.SubscriptionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if admin, _ := c.Get("platform_admin"); admin == true {
+			c.Next()
+			return
+		}
+		checkPlan(c, func(pharmacyID uuid.UUID) error { return subs.CheckWritable(c.Request.Context(), pharmacyID) })
+	}
+}
+
+// PlanLimit answers 403 when creating one more of limit (models.PlanLimitProducts, ...) would exceed the plan.
+func PlanLimit(subs inbound.SubscriptionService, limit string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		checkPlan(c, func(pharmacyID uuid.UUID) error { return subs.CheckLimit(c.Request.Context(), pharmacyID, limit) })
+	}
+}
+
+// PlanFeature answers 403 on routes of a feature flag the pharmacy's plan does not include.
+func PlanFeature(subs inbound.SubscriptionService, feature string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		checkPlan(c, func(pharmacyID uuid.UUID) error { return subs.CheckFeature(c.Request.Context(), pharmacyID, feature) })
+	}
+}
+
+func checkPlan(c *gin.Context, check func(pharmacyID uuid.UUID) error) {
+	pharmacyID, err := uuid.Parse(c.GetString("pharmacy_id"))
+	if err != nil {
+		c.Next()
+		return
+	}
+	if err := check(pharmacyID); err != nil {
+		appErr := errors.GetAppError(err)
+		switch {
+		case appErr != nil && appErr.Code == errors.ErrCodePaymentRequired:
+			response.WriteError(c, http.StatusPaymentRequired, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+		case appErr != nil && appErr.Code == errors.ErrCodePlanLimit:
+			response.WriteError(c, http.StatusForbidden, response.ErrorResponse{Code: appErr.Code, Message: appErr.Message})
+		default:
+			response.WriteError(c, http.StatusInternalServerError, response.ErrorResponse{Code: errors.ErrCodeInternal, Message: "failed to check subscription"})
+		}
+		c.Abort()
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/gin-gonic/gin"
+)
+
+// CrossTenant lifts the tenant scope Auth puts on the request context, for routes that work across pharmacies by
+// design and check access themselves (organizations, stock transfers). Use after Auth.
+func CrossTenant() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(outbound.WithoutTenant(c.Request.Context()))
+		c.Next()
+	}
+}
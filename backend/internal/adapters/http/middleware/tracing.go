@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts a server span per request, named after the matched route and continuing the caller's trace
+// when a traceparent header is sent. Health checks are skipped. It also returns the trace id in X-Trace-Id so
+// a slow or failed request can be looked up in the tracing backend (see TraceID).
+func Tracing(service string) gin.HandlerFunc {
+	return otelgin.Middleware(service, otelgin.WithFilter(func(r *http.Request) bool {
+		return !strings.HasPrefix(r.URL.Path, "/health")
+	}))
+}
+
+// TraceID sets X-Trace-Id on responses to traced requests. Use after Tracing.
+func TraceID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			c.Header("X-Trace-Id", sc.TraceID().String())
+		}
+		c.Next()
+	}
+}
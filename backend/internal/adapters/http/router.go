@@ -3,6 +3,7 @@ package http
 import (
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/handlers"
 	"github.com/careplus/pharmacy-backend/internal/adapters/http/middleware"
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/infrastructure/config"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
@@ -40,56 +41,184 @@ func NewRouter(
 	dashboardHandler *handlers.DashboardHandler,
 	blogHandler *handlers.BlogHandler,
 	chatHandler *handlers.ChatHandler,
+	aiContentHandler *handlers.AIContentHandler,
+	reportHandler *handlers.ReportHandler,
+	supplierHandler *handlers.SupplierHandler,
+	purchaseOrderHandler *handlers.PurchaseOrderHandler,
+	flashSaleHandler *handlers.FlashSaleHandler,
+	preorderHandler *handlers.PreorderHandler,
+	trainingHandler *handlers.TrainingHandler,
+	otpHandler *handlers.OTPHandler,
+	roleHandler *handlers.RoleHandler,
+	cartHandler *handlers.CartHandler,
+	deliveryHandler *handlers.DeliveryHandler,
+	returnHandler *handlers.ReturnHandler,
+	deviceHandler *handlers.DeviceHandler,
+	apiVersionHandler *handlers.APIVersionHandler,
+	apiUsageHandler *handlers.APIUsageHandler,
+	deliveryQueueHandler *handlers.DeliveryQueueHandler,
+	integrityHandler *handlers.IntegrityHandler,
+	shiftSwapHandler *handlers.ShiftSwapHandler,
+	branchHandler *handlers.BranchHandler,
+	stockTransferHandler *handlers.StockTransferHandler,
+	hashtagHandler *handlers.HashtagHandler,
+	productImageImportHandler *handlers.ProductImageImportHandler,
+	giftCardHandler *handlers.GiftCardHandler,
+	storeCreditHandler *handlers.StoreCreditHandler,
+	walletHandler *handlers.WalletHandler,
+	clearanceHandler *handlers.ClearanceHandler,
+	inventoryEvidenceHandler *handlers.InventoryEvidenceHandler,
+	drugInfoHandler *handlers.DrugInfoHandler,
+	exportRequestHandler *handlers.ExportRequestHandler,
+	licenseComplianceHandler *handlers.LicenseComplianceHandler,
+	wishlistHandler *handlers.WishlistHandler,
+	backInStockHandler *handlers.BackInStockHandler,
+	recommendationHandler *handlers.RecommendationHandler,
+	serialHandler *handlers.SerialHandler,
+	warrantyHandler *handlers.WarrantyHandler,
+	organizationHandler *handlers.OrganizationHandler,
+	consentHandler *handlers.ConsentHandler,
+	etaHandler *handlers.ETAHandler,
+	staffPointsHandler *handlers.StaffPointsHandler,
+	campaignHandler *handlers.CampaignHandler,
+	moderationHandler *handlers.ModerationHandler,
+	sitemapHandler *handlers.SitemapHandler,
+	fileHandler *handlers.FileHandler,
+	storageHandler *handlers.StorageHandler,
+	pharmacySignupHandler *handlers.PharmacySignupHandler,
+	platformHandler *handlers.PlatformHandler,
+	subscriptionHandler *handlers.SubscriptionHandler,
+	currencyHandler *handlers.CurrencyHandler,
 	chatWSHandler gin.HandlerFunc,
+	versionMetrics *middleware.VersionMetrics,
 	authProvider outbound.AuthProvider,
 	userRepo outbound.UserRepository,
+	pharmacyRepo outbound.PharmacyRepository,
+	configRepo outbound.PharmacyConfigRepository,
 	activityLogService inbound.ActivityLogService,
+	roleService inbound.RoleService,
+	idempotencyService inbound.IdempotencyService,
+	apiUsageService inbound.APIUsageService,
+	exportApprovalService inbound.ExportApprovalService,
+	subscriptionService inbound.SubscriptionService,
+	rateLimiter outbound.RateLimiter,
 	logger *zap.Logger,
 ) *gin.Engine {
 	if cfg.IsProduction() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 	router := gin.New()
+	// Only TRUSTED_PROXIES may set X-Forwarded-For; otherwise any client could pick its own rate-limit bucket.
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.Error("invalid TRUSTED_PROXIES, trusting none", zap.Error(err))
+		_ = router.SetTrustedProxies(nil)
+	}
 	router.Use(middleware.Recovery(logger))
+	if cfg.Tracing.Enabled() {
+		router.Use(middleware.Tracing(cfg.Tracing.ServiceName), middleware.TraceID())
+	}
 	router.Use(middleware.Logger(logger))
+	router.Use(middleware.APIUsage(apiUsageService))
 	router.Use(middleware.CORS(cfg))
+	router.Use(middleware.Compress(cfg.Server.CompressMinSize))
+	// Error messages in the language of Accept-Language or the pharmacy's default_language.
+	router.Use(middleware.Language(configRepo))
 
 	// Serve local uploads when FS_TYPE=local
 	if cfg.FS.Type == "local" && cfg.FS.LocalBaseDir != "" && cfg.FS.LocalBaseURL != "" {
 		router.Static(cfg.FS.LocalBaseURL, cfg.FS.LocalBaseDir)
 	}
+	// Private local files (CVs, prescriptions, return evidence) are only served through signed links; nil on S3,
+	// which serves presigned URLs itself.
+	if fileHandler != nil {
+		router.GET(cfg.FS.LocalPrivateURL+"/*path", fileHandler.ServePrivate)
+	}
+
+	perm := func(permission string) gin.HandlerFunc { return middleware.RequirePermission(roleService, permission) }
+	// Retried creates with the same Idempotency-Key replay the first response instead of running again.
+	idempotent := middleware.Idempotency(idempotencyService, logger)
+	// Token-bucket limits on abuse-prone endpoints (see RATE_LIMIT_*); nil rateLimiter disables them.
+	limits := cfg.RateLimit
+	limitLogin := middleware.RateLimit(rateLimiter, "login", limits.Login, middleware.ByIP, logger)
+	limitRegister := middleware.RateLimit(rateLimiter, "register", limits.Register, middleware.ByIP, logger)
+	limitCatalog := middleware.RateLimit(rateLimiter, "catalog", limits.Catalog, middleware.ByIP, logger)
+	limitChat := middleware.RateLimit(rateLimiter, "chat", limits.Chat, middleware.ByUser, logger)
+	limitChatConnect := middleware.RateLimit(rateLimiter, "chat-ws", limits.Chat, middleware.ByIP, logger)
+	// Exports with personal data need exports.approve or a manager-approved request, and are watermarked and logged.
+	piiExport := func(kind string, always bool) gin.HandlerFunc {
+		return middleware.PIIExport(exportApprovalService, kind, always, logger)
+	}
+	// Plan limits and features of the pharmacy's subscription; writes answer 402 once it is past its grace period.
+	planLimit := func(limit string) gin.HandlerFunc { return middleware.PlanLimit(subscriptionService, limit) }
+	planFeature := func(feature string) gin.HandlerFunc { return middleware.PlanFeature(subscriptionService, feature) }
 
 	router.GET("/health", healthHandler.Check)
 	router.GET("/health/ready", healthHandler.Readiness)
 	router.GET("/health/live", healthHandler.Liveness)
+	router.GET("/health/scheduler", healthHandler.Scheduler)
 
-	v1 := router.Group("/api/v1")
+	// v1 is frozen: new response shapes go to v2. Once API_V1_DEPRECATED_AT is set, v1 responses carry
+	// Deprecation/Sunset headers and a successor-version link when the route exists under /api/v2.
+	v1 := router.Group("/api/v1", middleware.APIVersion("v1", versionMetrics), middleware.Deprecation(router, cfg.API.V1DeprecatedAt, cfg.API.V1SunsetAt, "/api/v1", "/api/v2"))
 	{
+		v1.GET("/versions", apiVersionHandler.Versions)
 		// Public app config by hostname (no auth): company_name, theme, language, address, tenant_code, pharmacy_id
 		v1.GET("/app-config", configHandler.GetAppConfig)
 
-		// Public routes (no auth): browse products and pharmacies
-		public := v1.Group("/public")
+		// Public routes (no auth): browse products and pharmacies. A pharmacy that is not open answers 404.
+		public := v1.Group("/public", middleware.OpenPharmacy(pharmacyRepo))
 		{
 			public.GET("/pharmacies", pharmacyHandler.List)
+			// Self-registration: the pharmacy stays pending until a platform admin approves it under /platform.
+			public.POST("/pharmacy-signup", limitRegister, pharmacySignupHandler.Signup)
 			public.GET("/pharmacies/:pharmacyId/config", configHandler.GetByPharmacyID)
-			public.GET("/pharmacies/:pharmacyId/products", productHandler.ListByPharmacyID)
+			public.GET("/pharmacies/:pharmacyId/currencies", currencyHandler.Storefront)
+			public.GET("/pharmacies/:pharmacyId/products", limitCatalog, productHandler.ListByPharmacyID)
+			public.GET("/pharmacies/:pharmacyId/products/facets", limitCatalog, productHandler.Facets)
+			public.GET("/pharmacies/:pharmacyId/products/delta", limitCatalog, productHandler.Delta)
+			public.GET("/pharmacies/:pharmacyId/products/recommended", limitCatalog, middleware.OptionalAuth(authProvider, userRepo), recommendationHandler.Recommended)
+			public.POST("/pharmacies/:pharmacyId/products/:productId/views", middleware.OptionalAuth(authProvider, userRepo), recommendationHandler.RecordView)
+			public.GET("/pharmacies/:pharmacyId/hashtags/trending", hashtagHandler.Trending)
 			public.GET("/pharmacies/:pharmacyId/categories", categoryHandler.ListByPharmacyID)
 			public.GET("/pharmacies/:pharmacyId", pharmacyHandler.GetByID)
 			public.GET("/pharmacies/:pharmacyId/promos", promoHandler.ListPublic)
+			public.POST("/pharmacies/:pharmacyId/promos/impressions", promoHandler.RecordImpressions)
+			public.POST("/pharmacies/:pharmacyId/promos/:id/click", promoHandler.RecordClick)
+			public.GET("/pharmacies/:pharmacyId/flash-sales", flashSaleHandler.ListLivePublic)
+			public.GET("/pharmacies/:pharmacyId/short-expiry", productHandler.ListShortExpiryPublic)
+			public.GET("/pharmacies/:pharmacyId/warranty", limitCatalog, warrantyHandler.Check)
 			public.GET("/pharmacies/:pharmacyId/referral/validate", referralHandler.ValidateReferralCode)
 			public.GET("/pharmacies/:pharmacyId/payment-gateways", paymentGatewayHandler.ListActiveByPharmacyID)
-			public.GET("/products/:id", productHandler.GetByID)
+			public.POST("/pharmacies/:pharmacyId/otp/send", otpHandler.Send)
+			public.POST("/pharmacies/:pharmacyId/otp/verify", otpHandler.Verify)
+			public.GET("/products/:id", limitCatalog, productHandler.GetByID)
+			public.GET("/products/:id/alternatives", limitCatalog, productHandler.Alternatives)
 			public.GET("/products/:id/reviews", reviewHandler.ListByProductID)
+			// Guests give a phone or email; a signed-in buyer's token is used when sent.
+			public.POST("/products/:id/notify-me", limitCatalog, middleware.OptionalAuth(authProvider, userRepo), backInStockHandler.Subscribe)
 			public.GET("/pharmacies/:pharmacyId/blog/posts", blogHandler.ListPostsPublic)
 			public.GET("/pharmacies/:pharmacyId/blog/posts/:slug", blogHandler.GetPostBySlugPublic)
+			public.GET("/pharmacies/:pharmacyId/blog/tags", blogHandler.TagCloudPublic)
+			public.GET("/pharmacies/:pharmacyId/blog/feed.xml", blogHandler.Feed)
+			public.GET("/pharmacies/:pharmacyId/sitemap.xml", sitemapHandler.Get)
+			// Password requirements of the pharmacy's security policy, checked against {password} when given
+			public.POST("/pharmacies/:pharmacyId/password-policy/check", authHandler.PasswordPolicy)
+			// Supplier reply to a purchase request via the signed link in the email (?token=)
+			public.GET("/purchase-orders/:id", purchaseOrderHandler.GetForSupplier)
+			public.POST("/purchase-orders/:id/respond", purchaseOrderHandler.RespondAsSupplier)
+			// Ticket status callbacks from the external help desk (shared secret, see TICKETING_WEBHOOK_SECRET)
+			public.POST("/ticketing/webhook", chatHandler.TicketWebhook)
 		}
 
 		auth := v1.Group("/auth")
 		{
-			auth.POST("/register", authHandler.Register)
-			auth.POST("/login", authHandler.Login)
+			auth.POST("/register", limitRegister, authHandler.Register)
+			auth.POST("/login", limitLogin, authHandler.Login)
+			auth.POST("/login/verify", limitLogin, authHandler.VerifyLogin)
+			auth.POST("/change-expired-password", limitLogin, authHandler.ChangeExpiredPassword)
 			auth.POST("/refresh", authHandler.RefreshToken)
+			auth.POST("/forgot-password", authHandler.ForgotPassword)
+			auth.POST("/reset-password", authHandler.ResetPassword)
 		}
 		authProtected := v1.Group("/auth")
 		authProtected.Use(middleware.Auth(authProvider, userRepo, logger))
@@ -104,15 +233,49 @@ func NewRouter(
 			authProtected.DELETE("/me/addresses/:id", addressHandler.Delete)
 			authProtected.PATCH("/me/addresses/:id/default", addressHandler.SetDefault)
 			authProtected.GET("/me/customer-profile", referralHandler.GetMyCustomerProfile)
+			authProtected.GET("/me/consents", consentHandler.GetMine)
+			authProtected.POST("/me/consents", consentHandler.RecordMine)
+			authProtected.GET("/me/devices", deviceHandler.List)
+			authProtected.POST("/me/devices", deviceHandler.Register)
+			authProtected.DELETE("/me/devices", deviceHandler.Unregister)
 		}
 
 		api := v1.Group("")
 		api.Use(middleware.Auth(authProvider, userRepo, logger))
 		api.Use(middleware.ActivityLog(activityLogService, logger))
+		api.Use(middleware.Subscription(subscriptionService))
 		{
 			// Upload: any authenticated user (profile picture, etc.); staff also use for products/CV
-			api.POST("/upload", uploadHandler.Upload)
+			api.POST("/upload", planLimit(models.PlanLimitStorage), uploadHandler.Upload)
+			// Resumable uploads for large files (CVs, videos): start, send chunks, check progress, cancel
+			api.POST("/uploads", planLimit(models.PlanLimitStorage), uploadHandler.StartUpload)
+			api.GET("/uploads/:id", uploadHandler.GetUpload)
+			api.PUT("/uploads/:id", uploadHandler.UploadChunk)
+			api.DELETE("/uploads/:id", uploadHandler.CancelUpload)
 			api.GET("/dashboard/stats", dashboardHandler.GetStats)
+			api.GET("/versions/usage", perm(models.PermReportsRead), apiVersionHandler.Usage)
+			// The pharmacy's own API traffic: requests, error rates, latency and top endpoints per day.
+			api.GET("/usage", perm(models.PermReportsRead), apiUsageHandler.Report)
+			api.GET("/delivery-queue/dead", perm(models.PermDeliveryQueueManage), deliveryQueueHandler.ListDead)
+			api.POST("/delivery-queue/dead/:id/retry", perm(models.PermDeliveryQueueManage), deliveryQueueHandler.Retry)
+			api.GET("/integrity", perm(models.PermIntegrityManage), integrityHandler.Report)
+			// Platform admins (users.platform_admin) run the platform across pharmacies; pharmacy roles never grant it.
+			platform := api.Group("/platform", middleware.RequirePlatformAdmin())
+			{
+				platform.GET("/pharmacy-signups", pharmacySignupHandler.List)
+				platform.GET("/pharmacy-signups/:id", pharmacySignupHandler.Get)
+				platform.POST("/pharmacy-signups/:id/approve", pharmacySignupHandler.Approve)
+				platform.POST("/pharmacy-signups/:id/reject", pharmacySignupHandler.Reject)
+				platform.GET("/tenants", platformHandler.ListTenants)
+				platform.POST("/tenants/:id/suspend", platformHandler.Suspend)
+				platform.POST("/tenants/:id/reactivate", platformHandler.Reactivate)
+				platform.POST("/tenants/:id/impersonate", platformHandler.Impersonate)
+				platform.GET("/tenants/:id/subscription", subscriptionHandler.GetTenant)
+				platform.POST("/tenants/:id/subscription/renew", subscriptionHandler.Renew)
+				// The orphan sweep spans every pharmacy's files.
+				platform.GET("/storage/orphans", storageHandler.Orphans)
+			}
+			api.POST("/integrity/fix", perm(models.PermIntegrityManage), integrityHandler.Fix)
 			api.GET("/config", configHandler.GetOrCreate) // any auth: read config for branding (sidebar/header)
 			api.GET("/announcements/active", announcementHandler.ListActiveForUser)
 			api.POST("/announcements/skip-all", announcementHandler.SkipAll)
@@ -129,21 +292,145 @@ func NewRouter(
 			// Orders: any auth can create/list/get own; handler restricts staff. Staff-only actions on staffRole below.
 			orders := api.Group("/orders")
 			{
-				orders.POST("", orderHandler.Create)
+				orders.POST("", idempotent, orderHandler.Create)
 				orders.GET("", orderHandler.List)
+				orders.GET("/my", orderHandler.ListMine)
 				orders.GET("/:orderId/feedback", orderHandler.GetFeedback)
 				orders.POST("/:orderId/feedback", orderHandler.CreateFeedback)
 				orders.GET("/:orderId/return-request", orderHandler.GetReturnRequest)
 				orders.POST("/:orderId/return-request", orderHandler.CreateReturnRequest)
 				orders.GET("/:orderId", orderHandler.GetByID)
+				orders.GET("/:orderId/history", orderHandler.History)
 				orders.GET("/:orderId/payments", paymentHandler.ListByOrder)
+				orders.GET("/:orderId/delivery", deliveryHandler.Track)
+				orders.GET("/:orderId/eta", etaHandler.ForOrder)
+			}
+			// ETA of an order placed now (checkout): queue depth, staff on duty and recent preparation times.
+			api.GET("/eta", etaHandler.Estimate)
+			// Deliveries: deliveries.manage creates, assigns and lists; the assigned delivery person may also
+			// update status and location, attach proof and send the delivery code of their own deliveries
+			// (checked in the service).
+			deliveries := api.Group("/deliveries")
+			{
+				deliveries.GET("/mine", deliveryHandler.ListMine)
+				deliveries.GET("/batches/mine", deliveryHandler.ListMyBatches)
+				deliveries.GET("/batches/mine/:batchId", deliveryHandler.GetMyBatch)
+				deliveries.POST("/:orderId/status", deliveryHandler.UpdateStatus)
+				deliveries.POST("/:orderId/location", deliveryHandler.UpdateLocation)
+				deliveries.POST("/:orderId/proof/:kind", deliveryHandler.AttachProof)
+				deliveries.POST("/:orderId/otp", deliveryHandler.SendOTP)
+				deliveries.GET("", perm(models.PermDeliveriesManage), deliveryHandler.List)
+				deliveries.POST("", perm(models.PermDeliveriesManage), deliveryHandler.Create)
+				deliveries.GET("/:orderId", perm(models.PermDeliveriesManage), deliveryHandler.GetByOrder)
+				deliveries.POST("/:orderId/assign", perm(models.PermDeliveriesManage), deliveryHandler.Assign)
+				deliveries.PUT("/:orderId/destination", perm(models.PermDeliveriesManage), deliveryHandler.SetDestination)
+				// Route planning: packed deliveries of ready orders are batched per rider with stop order and ETAs.
+				deliveries.POST("/batches", perm(models.PermDeliveriesManage), deliveryHandler.PlanBatches)
+				deliveries.GET("/batches", perm(models.PermDeliveriesManage), deliveryHandler.ListBatches)
+				deliveries.GET("/batches/:batchId", perm(models.PermDeliveriesManage), deliveryHandler.GetBatch)
+			}
+			// Order feedback: ratings analytics and follow-up of low ratings (feedback.manage).
+			feedback := api.Group("/feedback", perm(models.PermFeedbackManage))
+			{
+				feedback.GET("", orderHandler.ListFeedback)
+				feedback.GET("/summary", orderHandler.FeedbackSummary)
+				feedback.PATCH("/:id/follow-up", orderHandler.UpdateFeedbackFollowUp)
+			}
+			// Returns: the reason taxonomy is open to any signed-in user (customers pick a reason when
+			// submitting via /orders/:orderId/return-request); review, analytics and flags need returns.manage.
+			returns := api.Group("/returns")
+			{
+				returns.GET("/reasons", returnHandler.Reasons)
+				returns.GET("", perm(models.PermReturnsManage), returnHandler.List)
+				returns.PATCH("/:id", perm(models.PermReturnsManage), returnHandler.Review)
+				returns.GET("/analytics", perm(models.PermReturnsManage), returnHandler.Analytics)
+				returns.GET("/flags", perm(models.PermReturnsManage), returnHandler.ListFlags)
+				returns.POST("/flags/:productId/review", perm(models.PermReturnsManage), returnHandler.ReviewFlag)
+			}
+			// Cart: the logged-in user's server-side cart; checkout places an order.
+			cart := api.Group("/cart")
+			{
+				cart.GET("", cartHandler.Get)
+				cart.DELETE("", cartHandler.Clear)
+				cart.POST("/items", cartHandler.AddItem)
+				cart.PUT("/items/:productId", cartHandler.UpdateItem)
+				cart.DELETE("/items/:productId", cartHandler.RemoveItem)
+				cart.PUT("/codes", cartHandler.ApplyCodes)
+				cart.POST("/checkout", cartHandler.Checkout)
 			}
+			api.POST("/checkout/validate", cartHandler.ValidateCheckout)
+			// Wishlist: the logged-in user's favorites; restocks and price drops are notified by the scheduler.
+			wishlist := api.Group("/wishlist")
+			{
+				wishlist.GET("", wishlistHandler.List)
+				wishlist.POST("", wishlistHandler.Add)
+				wishlist.DELETE("/:productId", wishlistHandler.Remove)
+			}
+			// Wallet: the logged-in user's store credit; top-ups credit it once staff confirm the gateway payment.
+			wallet := api.Group("/wallet")
+			{
+				wallet.GET("", walletHandler.Get)
+				wallet.POST("/top-ups", idempotent, walletHandler.StartTopUp)
+				wallet.GET("/top-ups", walletHandler.ListMyTopUps)
+			}
+
+			// Pre-orders: any auth can place/list/cancel own (handler scopes end users); converted to orders when stock is received.
+			preorders := api.Group("/preorders")
+			{
+				preorders.POST("", preorderHandler.Create)
+				preorders.GET("", preorderHandler.List)
+				preorders.GET("/:id", preorderHandler.GetByID)
+				preorders.POST("/:id/cancel", preorderHandler.Cancel)
+			}
+			// Points the caller earned from completed sales (see reports/staff-points for the leaderboard)
+			api.GET("/staff-points/me", staffPointsHandler.MyHistory)
 			// Promo codes: validate for any auth (checkout); CRUD on staffRole below.
 			promoCodes := api.Group("/promo-codes")
 			{
 				promoCodes.POST("/validate", promoCodeHandler.Validate)
 				promoCodes.GET("/validate", promoCodeHandler.ValidateQuery)
 			}
+			// Gift cards: balance check for any auth (checkout); counter redemption needs payments.manage, the rest gift_cards.manage.
+			api.GET("/gift-cards/check", giftCardHandler.Check)
+			api.POST("/gift-cards/redeem", perm(models.PermPaymentsManage), giftCardHandler.Redeem)
+			giftCards := api.Group("/gift-cards", perm(models.PermGiftCardsManage))
+			{
+				giftCards.POST("", giftCardHandler.Issue)
+				giftCards.GET("", giftCardHandler.List)
+				giftCards.GET("/:id", giftCardHandler.Get)
+				giftCards.POST("/:id/deactivate", giftCardHandler.Deactivate)
+			}
+			clearance := api.Group("/clearance-actions", perm(models.PermClearanceManage))
+			{
+				clearance.GET("/supplier-candidates", clearanceHandler.SupplierCandidates)
+				clearance.POST("", clearanceHandler.Create)
+				clearance.GET("", clearanceHandler.List)
+				clearance.GET("/:id", clearanceHandler.Get)
+				clearance.POST("/:id/returns", clearanceHandler.RecordReturn)
+				clearance.POST("/:id/complete", clearanceHandler.Complete)
+				clearance.POST("/:id/cancel", clearanceHandler.Cancel)
+				clearance.POST("/:id/photos", inventoryEvidenceHandler.AttachToReturn)
+			}
+			serials := api.Group("/serials")
+			{
+				serials.POST("", perm(models.PermInventoryWrite), serialHandler.Receive)
+				serials.GET("", perm(models.PermInventoryRead), serialHandler.List)
+				serials.GET("/search", perm(models.PermInventoryRead), serialHandler.Search)
+				serials.POST("/assign", perm(models.PermInventoryWrite), serialHandler.Assign)
+			}
+			warranties := api.Group("/warranties", perm(models.PermWarrantiesManage))
+			{
+				warranties.GET("", warrantyHandler.FindBySerial)
+				warranties.GET("/:id", warrantyHandler.Get)
+			}
+			warrantyClaims := api.Group("/warranty-claims", perm(models.PermWarrantiesManage))
+			{
+				warrantyClaims.POST("", warrantyHandler.OpenClaim)
+				warrantyClaims.GET("", warrantyHandler.ListClaims)
+				warrantyClaims.GET("/:id", warrantyHandler.GetClaim)
+				warrantyClaims.POST("/:id/send-to-vendor", warrantyHandler.SendToVendor)
+				warrantyClaims.POST("/:id/resolve", warrantyHandler.Resolve)
+			}
 			// Product reviews: any auth can list and create (buyers can leave reviews)
 			api.GET("/products/:id/reviews", reviewHandler.ListByProductID)
 			api.POST("/products/:id/reviews", reviewHandler.Create)
@@ -157,15 +444,20 @@ func NewRouter(
 				reviews.DELETE("/:id/like", reviewHandler.Unlike)
 				reviews.GET("/:id/comments", reviewHandler.ListComments)
 				reviews.POST("/:id/comments", reviewHandler.CreateComment)
+				// The pharmacy's official response, pinned on the review: pharmacists post it, admins and managers edit it
+				reviews.POST("/:id/response", perm(models.PermReviewsRespond), reviewHandler.CreateResponse)
+				reviews.PUT("/:id/response", perm(models.PermReviewsManage), reviewHandler.UpdateResponse)
+				reviews.DELETE("/:id/response", perm(models.PermReviewsManage), reviewHandler.DeleteResponse)
 			}
 			api.DELETE("/comments/:id", reviewHandler.DeleteComment)
 
-			// Blog: list (published by default), get, like, comment, analytics — any auth; create/update/delete — staff; approve/pending — manager
+			// Blog: list (published by default), get, like, comment, analytics — any auth; create/update/delete — blog.write; approve/pending — blog.approve
 			blog := api.Group("/blog")
 			{
 				blog.GET("/categories", blogHandler.ListCategories)
+				blog.GET("/tags", blogHandler.ListTags)
 				blog.GET("/posts", blogHandler.ListPosts)
-				blog.GET("/posts/pending", middleware.RequireAdminOrManager(), blogHandler.ListPendingPosts)
+				blog.GET("/posts/pending", perm(models.PermBlogApprove), blogHandler.ListPendingPosts)
 				blog.GET("/posts/:id", blogHandler.GetPost)
 				blog.POST("/posts/:id/submit", blogHandler.SubmitForApproval)
 				blog.POST("/posts/:id/like", blogHandler.LikePost)
@@ -177,171 +469,443 @@ func NewRouter(
 				blog.GET("/analytics", blogHandler.GetAnalytics)
 			}
 			api.DELETE("/blog/comments/:id", blogHandler.DeleteComment)
-			blogStaff := api.Group("/blog").Use(middleware.RequireStaffRole())
+			blogStaff := api.Group("/blog").Use(perm(models.PermBlogWrite))
 			{
 				blogStaff.POST("/categories", blogHandler.CreateCategory)
 				blogStaff.GET("/categories/:id", blogHandler.GetCategory)
 				blogStaff.PUT("/categories/:id", blogHandler.UpdateCategory)
 				blogStaff.DELETE("/categories/:id", blogHandler.DeleteCategory)
+				blogStaff.POST("/tags", blogHandler.CreateTag)
+				blogStaff.PUT("/tags/:id", blogHandler.UpdateTag)
+				blogStaff.DELETE("/tags/:id", blogHandler.DeleteTag)
 				blogStaff.POST("/posts", blogHandler.CreatePost)
 				blogStaff.PUT("/posts/:id", blogHandler.UpdatePost)
 				blogStaff.DELETE("/posts/:id", blogHandler.DeletePost)
+				blogStaff.GET("/posts/:id/revisions", blogHandler.ListRevisions)
+				blogStaff.POST("/posts/:id/revisions/:revId/restore", blogHandler.RestoreRevision)
 			}
-			blogManager := api.Group("/blog").Use(middleware.RequireAdminOrManager())
+			blogManager := api.Group("/blog").Use(perm(models.PermBlogApprove))
 			{
 				blogManager.POST("/posts/:id/approve", blogHandler.ApprovePost)
 			}
 
-			// Admin-only: pharmacy create/update, config write, notifications create, promos, referral config, activity, payment gateways write
-			admin := api.Group("").Use(middleware.RequireAdmin())
-			{
-				admin.POST("/pharmacies", pharmacyHandler.Create)
-				admin.PUT("/pharmacies/:id", pharmacyHandler.Update)
-				admin.PUT("/config", configHandler.Upsert)
-				admin.POST("/notifications", notificationHandler.Create)
-				admin.GET("/activity", activityHandler.List)
-				admin.GET("/promos", promoHandler.List)
-				admin.POST("/promos", promoHandler.Create)
-				admin.GET("/promos/:id", promoHandler.GetByID)
-				admin.PUT("/promos/:id", promoHandler.Update)
-				admin.DELETE("/promos/:id", promoHandler.Delete)
-				admin.PUT("/referral/config", referralHandler.UpsertConfig)
-				admin.POST("/payment-gateways", paymentGatewayHandler.Create)
-				admin.PUT("/payment-gateways/:id", paymentGatewayHandler.Update)
-				admin.DELETE("/payment-gateways/:id", paymentGatewayHandler.Delete)
-			}
-			// Admin or Manager: users, duty roster, daily logs, inventory batch write
-			adminOrManager := api.Group("").Use(middleware.RequireAdminOrManager())
-			{
-				adminOrManager.POST("/products/:id/batches", inventoryHandler.AddBatch)
-				adminOrManager.PATCH("/inventory/batches/:batchId", inventoryHandler.UpdateBatch)
-				adminOrManager.DELETE("/inventory/batches/:batchId", inventoryHandler.DeleteBatch)
-				adminOrManager.GET("/users", usersHandler.List)
-				adminOrManager.POST("/users", usersHandler.Create)
-				adminOrManager.GET("/users/:id", usersHandler.GetByID)
-				adminOrManager.PUT("/users/:id", usersHandler.Update)
-				adminOrManager.PATCH("/users/:id/deactivate", usersHandler.Deactivate)
-				adminOrManager.GET("/duty-roster", dutyRosterHandler.List)
-				adminOrManager.POST("/duty-roster", dutyRosterHandler.Create)
-				adminOrManager.GET("/duty-roster/:id", dutyRosterHandler.GetByID)
-				adminOrManager.PUT("/duty-roster/:id", dutyRosterHandler.Update)
-				adminOrManager.DELETE("/duty-roster/:id", dutyRosterHandler.Delete)
-				adminOrManager.GET("/daily-logs", dailyLogHandler.List)
-				adminOrManager.POST("/daily-logs", dailyLogHandler.Create)
-				adminOrManager.GET("/daily-logs/:id", dailyLogHandler.GetByID)
-				adminOrManager.PUT("/daily-logs/:id", dailyLogHandler.Update)
-				adminOrManager.DELETE("/daily-logs/:id", dailyLogHandler.Delete)
+			// Management routes are guarded by permissions (models.PermissionRegistry). Built-in roles keep their
+			// previous access by default (models.DefaultRolePermissions); pharmacies can customize manager and
+			// pharmacist and add their own roles under /roles.
+			api.POST("/pharmacies", perm(models.PermPharmaciesWrite), pharmacyHandler.Create)
+			api.PUT("/pharmacies/:id", perm(models.PermPharmaciesWrite), pharmacyHandler.Update)
+			api.GET("/subscription", perm(models.PermConfigWrite), subscriptionHandler.Get)
+			api.GET("/subscription/plans", subscriptionHandler.Plans)
+			configAdmin := api.Group("/config", perm(models.PermConfigWrite))
+			{
+				configAdmin.PUT("", configHandler.Upsert) // ?dry_run=true validates without saving
+				configAdmin.GET("/history", configHandler.History)
+				configAdmin.GET("/history/:version", configHandler.GetVersion)
+				configAdmin.POST("/history/:version/rollback", configHandler.Rollback)
+				configAdmin.POST("/sitemap/regenerate", sitemapHandler.Regenerate)
 			}
-
-			// Staff role only (admin, manager, pharmacist): product/category/inventory/invoice/payment management, referral
-			staffRole := api.Group("", middleware.RequireStaffRole())
-			{
-				products := staffRole.Group("/products")
-				{
-					products.POST("", productHandler.Create)
-					products.GET("", productHandler.List)
-					products.GET("/by-barcode/:barcode", productHandler.GetByBarcode)
-					products.GET("/:id", productHandler.GetByID)
-					products.PUT("/:id", productHandler.Update)
-					products.PATCH("/:id/stock", productHandler.UpdateStock)
-					products.DELETE("/:id", productHandler.Delete)
-					products.POST("/:id/images", productHandler.AddImage)
-					products.PATCH("/:id/images/reorder", productHandler.ReorderImages)
-					products.PATCH("/:id/images/:imageId/primary", productHandler.SetPrimaryImage)
-					products.DELETE("/:id/images/:imageId", productHandler.DeleteImage)
-					products.GET("/:id/batches", inventoryHandler.ListBatchesByProduct)
-				}
-				categories := staffRole.Group("/categories")
-				{
-					categories.POST("", categoryHandler.Create)
-					categories.GET("", categoryHandler.List)
-					categories.GET("/:id", categoryHandler.GetByID)
-					categories.PUT("/:id", categoryHandler.Update)
-					categories.DELETE("/:id", categoryHandler.Delete)
-				}
-				productUnits := staffRole.Group("/product-units")
-				{
-					productUnits.POST("", productUnitHandler.Create)
-					productUnits.GET("", productUnitHandler.List)
-					productUnits.GET("/:id", productUnitHandler.GetByID)
-					productUnits.PUT("/:id", productUnitHandler.Update)
-					productUnits.DELETE("/:id", productUnitHandler.Delete)
-				}
-				memberships := staffRole.Group("/memberships")
-				{
-					memberships.POST("", membershipHandler.Create)
-					memberships.GET("", membershipHandler.List)
-					memberships.GET("/:id", membershipHandler.GetByID)
-					memberships.PUT("/:id", membershipHandler.Update)
-					memberships.DELETE("/:id", membershipHandler.Delete)
-				}
-				inventory := staffRole.Group("/inventory")
-				{
-					inventory.GET("/batches", inventoryHandler.ListBatchesByPharmacy)
-					inventory.GET("/expiring", inventoryHandler.ListExpiringSoon)
-					inventory.GET("/batches/:batchId", inventoryHandler.GetBatch)
-				}
-				staffRole.GET("/referral/config", referralHandler.GetConfig)
-				customers := staffRole.Group("/customers")
-				{
-					customers.GET("", referralHandler.ListCustomers)
-					customers.GET("/by-phone", referralHandler.GetCustomerByPhone)
-					customers.GET("/:customerId/points", referralHandler.ListPointsTransactions)
-				}
-				staffRole.GET("/referral/redeem-preview", referralHandler.ComputeRedeemPreview)
-				staffRole.POST("/orders/:orderId/accept", orderHandler.Accept)
-				staffRole.PATCH("/orders/:orderId/status", orderHandler.UpdateStatus)
-				staffRole.POST("/orders/:orderId/invoices", invoiceHandler.CreateFromOrder)
-				promoCodesStaff := staffRole.Group("/promo-codes")
-				{
-					promoCodesStaff.POST("", promoCodeHandler.Create)
-					promoCodesStaff.GET("", promoCodeHandler.List)
-					promoCodesStaff.GET("/:id", promoCodeHandler.GetByID)
-					promoCodesStaff.PUT("/:id", promoCodeHandler.Update)
-				}
-				invoices := staffRole.Group("/invoices")
-				{
-					invoices.GET("", invoiceHandler.List)
-					invoices.GET("/:id", invoiceHandler.GetByID)
-					invoices.POST("/:id/issue", invoiceHandler.Issue)
-				}
-				payments := staffRole.Group("/payments")
-				{
-					payments.POST("", paymentHandler.Create)
-					payments.GET("", paymentHandler.ListByPharmacy)
-					payments.GET("/:id", paymentHandler.GetByID)
-					payments.POST("/:id/complete", paymentHandler.Complete)
-				}
-				paymentGateways := staffRole.Group("/payment-gateways")
-				{
-					paymentGateways.GET("", paymentGatewayHandler.List)
-					paymentGateways.GET("/:id", paymentGatewayHandler.GetByID)
-				}
-				announcements := staffRole.Group("/announcements")
-				{
-					announcements.GET("", announcementHandler.List)
-					announcements.GET("/:id", announcementHandler.GetByID)
-					announcements.POST("", announcementHandler.Create)
-					announcements.PUT("/:id", announcementHandler.Update)
-					announcements.DELETE("/:id", announcementHandler.Delete)
-				}
+			// Base and display currencies are set in /config; rates convert storefront prices to the display currency.
+			api.GET("/currencies", currencyHandler.List)
+			exchangeRates := api.Group("/exchange-rates", perm(models.PermConfigWrite))
+			{
+				exchangeRates.GET("", currencyHandler.ListRates)
+				exchangeRates.PUT("/:currency", currencyHandler.SetRate)
+				exchangeRates.DELETE("/:currency", currencyHandler.DeleteRate)
+			}
+			api.POST("/notifications", perm(models.PermNotificationsSend), notificationHandler.Create)
+			api.GET("/activity", perm(models.PermActivityRead), activityHandler.List)
+			api.GET("/activity/export", perm(models.PermActivityRead), piiExport(models.ExportKindActivity, true), activityHandler.Export)
+			exportRequests := api.Group("/exports/requests")
+			{
+				exportRequests.GET("/kinds", exportRequestHandler.Kinds)
+				exportRequests.POST("", exportRequestHandler.Create)
+				exportRequests.GET("/mine", exportRequestHandler.Mine)
+				exportRequests.GET("", perm(models.PermExportsApprove), exportRequestHandler.List)
+				exportRequests.POST("/:id/approve", perm(models.PermExportsApprove), exportRequestHandler.Approve)
+				exportRequests.POST("/:id/reject", perm(models.PermExportsApprove), exportRequestHandler.Reject)
+			}
+			api.GET("/content-reports/reasons", moderationHandler.Reasons)
+			api.POST("/content-reports", moderationHandler.Report)
+			moderation := api.Group("/moderation/reports", perm(models.PermModerationManage))
+			{
+				moderation.GET("", moderationHandler.Queue)
+				moderation.POST("/:id/action", moderationHandler.Act)
+			}
+			promos := api.Group("/promos", perm(models.PermPromosManage), planFeature("promos"))
+			{
+				promos.GET("", promoHandler.List)
+				promos.POST("", promoHandler.Create)
+				promos.GET("/:id", promoHandler.GetByID)
+				promos.GET("/:id/performance", promoHandler.Performance)
+				promos.PUT("/:id", promoHandler.Update)
+				promos.POST("/:id/image", promoHandler.UploadImage)
+				promos.DELETE("/:id", promoHandler.Delete)
+			}
+			api.GET("/referral/config", perm(models.PermCustomersRead), referralHandler.GetConfig)
+			api.PUT("/referral/config", perm(models.PermReferralManage), referralHandler.UpsertConfig)
+			api.GET("/referral/redeem-preview", perm(models.PermCustomersRead), referralHandler.ComputeRedeemPreview)
+			paymentGateways := api.Group("/payment-gateways")
+			{
+				paymentGateways.GET("", perm(models.PermPaymentGatewaysRead), paymentGatewayHandler.List)
+				paymentGateways.GET("/:id", perm(models.PermPaymentGatewaysRead), paymentGatewayHandler.GetByID)
+				paymentGateways.POST("", perm(models.PermPaymentGatewaysManage), paymentGatewayHandler.Create)
+				paymentGateways.PUT("/:id", perm(models.PermPaymentGatewaysManage), paymentGatewayHandler.Update)
+				paymentGateways.DELETE("/:id", perm(models.PermPaymentGatewaysManage), paymentGatewayHandler.Delete)
+			}
+			// Roles: any auth can read its own permissions (to shape the UI); managing roles needs roles.manage
+			api.GET("/permissions/me", roleHandler.MyPermissions)
+			roles := api.Group("/roles", perm(models.PermRolesManage))
+			{
+				roles.GET("/permissions", roleHandler.ListPermissions)
+				roles.GET("", roleHandler.List)
+				roles.POST("", roleHandler.Create)
+				roles.PUT("/:name", roleHandler.Update)
+				roles.DELETE("/:name", roleHandler.Delete)
+			}
+			users := api.Group("/users", perm(models.PermUsersManage))
+			{
+				users.GET("", usersHandler.List)
+				users.POST("", planLimit(models.PlanLimitUsers), usersHandler.Create)
+				users.GET("/:id", usersHandler.GetByID)
+				users.PUT("/:id", usersHandler.Update)
+				users.PATCH("/:id/deactivate", usersHandler.Deactivate)
+			}
+			// Pharmacist license expiry (set with license_expires_at on /users); reminders run on the scheduler.
+			api.GET("/compliance/licenses", perm(models.PermUsersManage), licenseComplianceHandler.Overview)
+			// Own roster: any auth sees its published shifts and acknowledges them; the rest needs roster.manage
+			api.GET("/duty-roster/mine", dutyRosterHandler.Mine)
+			api.POST("/duty-roster/:id/acknowledge", dutyRosterHandler.Acknowledge)
+			dutyRoster := api.Group("/duty-roster", perm(models.PermRosterManage))
+			{
+				dutyRoster.GET("", dutyRosterHandler.List)
+				dutyRoster.POST("", dutyRosterHandler.Create)
+				dutyRoster.POST("/publish", dutyRosterHandler.Publish)
+				dutyRoster.GET("/unacknowledged", dutyRosterHandler.Unacknowledged)
+				dutyRoster.GET("/export", dutyRosterHandler.Export)
+				dutyRoster.GET("/:id", dutyRosterHandler.GetByID)
+				dutyRoster.PUT("/:id", dutyRosterHandler.Update)
+				dutyRoster.DELETE("/:id", dutyRosterHandler.Delete)
+			}
+			// Shift swaps: any staff offers its own shifts and requests colleagues'; roster.manage decides
+			shiftSwaps := api.Group("/shift-swaps")
+			{
+				shiftSwaps.GET("", shiftSwapHandler.List)
+				shiftSwaps.POST("", shiftSwapHandler.Offer)
+				shiftSwaps.GET("/:id", shiftSwapHandler.Get)
+				shiftSwaps.POST("/:id/request", shiftSwapHandler.Request)
+				shiftSwaps.POST("/:id/withdraw", shiftSwapHandler.Withdraw)
+				shiftSwaps.POST("/:id/cancel", shiftSwapHandler.Cancel)
+				shiftSwaps.POST("/:id/approve", perm(models.PermRosterManage), shiftSwapHandler.Approve)
+				shiftSwaps.POST("/:id/reject", perm(models.PermRosterManage), shiftSwapHandler.Reject)
+			}
+			dailyLogs := api.Group("/daily-logs", perm(models.PermDailyLogsManage))
+			{
+				dailyLogs.GET("", dailyLogHandler.List)
+				dailyLogs.POST("", dailyLogHandler.Create)
+				dailyLogs.GET("/day-sheet", dailyLogHandler.DaySheet)
+				dailyLogs.GET("/:id", dailyLogHandler.GetByID)
+				dailyLogs.PUT("/:id", dailyLogHandler.Update)
+				dailyLogs.DELETE("/:id", dailyLogHandler.Delete)
+			}
+			// Reports: JSON by default, ?format=csv for export
+			reports := api.Group("/reports", perm(models.PermReportsRead))
+			{
+				reports.GET("/sales", reportHandler.Sales)
+				reports.GET("/top-products", reportHandler.TopProducts)
+				reports.GET("/payment-methods", reportHandler.PaymentMethods)
+				reports.GET("/low-stock", reportHandler.LowStock)
+				reports.GET("/expiring-stock", reportHandler.ExpiringStock)
+				reports.GET("/dead-stock", reportHandler.DeadStock)
+				reports.GET("/tax", reportHandler.Tax)
+				reports.GET("/sales-register", piiExport(models.ExportKindSalesRegister, false), reportHandler.SalesRegister)
+				reports.GET("/staff-points", piiExport(models.ExportKindStaffPoints, false), staffPointsHandler.MonthlyReport)
+			}
+			// Organizations (pharmacy chains): admins create or join one for their pharmacy; everything else is
+			// gated by organization membership, whichever pharmacy the member signed in to, so they run without the
+			// tenant scope.
+			api.POST("/organizations", perm(models.PermOrganizationManage), middleware.CrossTenant(), organizationHandler.Create)
+			api.POST("/organizations/join", perm(models.PermOrganizationManage), middleware.CrossTenant(), organizationHandler.Join)
+			api.GET("/organizations", middleware.CrossTenant(), organizationHandler.ListMine)
+			organizations := api.Group("/organizations/:id", middleware.CrossTenant())
+			{
+				organizations.GET("", organizationHandler.Get)
+				organizations.DELETE("/pharmacies/:pharmacyId", organizationHandler.RemovePharmacy)
+				organizations.GET("/members", organizationHandler.ListMembers)
+				organizations.POST("/members", organizationHandler.AddMember)
+				organizations.DELETE("/members/:userId", organizationHandler.RemoveMember)
+				organizations.GET("/dashboard", organizationHandler.Dashboard)
+				organizations.GET("/reports/sales", organizationHandler.Sales)
+				organizations.GET("/reports/top-products", organizationHandler.TopProducts)
+				organizations.GET("/reports/stock", organizationHandler.Stock)
+			}
+			// Suppliers and purchase orders (reorder requests emailed to suppliers)
+			suppliers := api.Group("/suppliers", perm(models.PermSuppliersManage))
+			{
+				suppliers.GET("", supplierHandler.List)
+				suppliers.POST("", supplierHandler.Create)
+				suppliers.GET("/:id", supplierHandler.GetByID)
+				suppliers.PUT("/:id", supplierHandler.Update)
+				suppliers.DELETE("/:id", supplierHandler.Delete)
+			}
+			purchaseOrders := api.Group("/purchase-orders", perm(models.PermPurchaseOrdersManage))
+			{
+				purchaseOrders.GET("/reorder-suggestions", purchaseOrderHandler.ReorderSuggestions)
+				purchaseOrders.POST("/reorder-request", purchaseOrderHandler.CreateReorderRequest)
+				purchaseOrders.GET("", purchaseOrderHandler.List)
+				purchaseOrders.GET("/:id", purchaseOrderHandler.GetByID)
+				purchaseOrders.POST("/:id/resend", purchaseOrderHandler.Resend)
+				purchaseOrders.POST("/:id/cancel", purchaseOrderHandler.Cancel)
+				purchaseOrders.POST("/:id/receive", purchaseOrderHandler.MarkReceived)
+			}
+			flashSales := api.Group("/flash-sales", perm(models.PermFlashSalesManage))
+			{
+				flashSales.GET("", flashSaleHandler.List)
+				flashSales.POST("", flashSaleHandler.Create)
+				flashSales.GET("/:id", flashSaleHandler.GetByID)
+				flashSales.PUT("/:id", flashSaleHandler.Update)
+				flashSales.DELETE("/:id", flashSaleHandler.Delete)
+			}
+			hashtags := api.Group("/hashtags")
+			{
+				hashtags.GET("", perm(models.PermProductsRead), hashtagHandler.List)
+				hashtags.POST("", perm(models.PermProductsWrite), hashtagHandler.Create)
+				hashtags.POST("/sync", perm(models.PermProductsWrite), hashtagHandler.Sync)
+				hashtags.PUT("/:id", perm(models.PermProductsWrite), hashtagHandler.Update)
+				hashtags.DELETE("/:id", perm(models.PermProductsWrite), hashtagHandler.Delete)
+				hashtags.POST("/:id/merge", perm(models.PermProductsWrite), hashtagHandler.Merge)
+			}
+			products := api.Group("/products")
+			{
+				products.POST("", perm(models.PermProductsWrite), planLimit(models.PlanLimitProducts), productHandler.Create)
+				products.GET("", perm(models.PermProductsRead), productHandler.List)
+				products.GET("/by-barcode/:barcode", perm(models.PermProductsRead), productHandler.GetByBarcode)
+				products.GET("/short-expiry", perm(models.PermProductsRead), productHandler.ListShortExpiry)
+				products.POST("/brands/rename", perm(models.PermProductsWrite), productHandler.RenameBrand)
+				products.POST("/images/bulk", perm(models.PermProductsWrite), productImageImportHandler.Import)
+				products.GET("/:id", perm(models.PermProductsRead), productHandler.GetByID)
+				products.PUT("/:id", perm(models.PermProductsWrite), productHandler.Update)
+				products.PATCH("/:id/stock", perm(models.PermProductsWrite), productHandler.UpdateStock)
+				products.DELETE("/:id", perm(models.PermProductsWrite), productHandler.Delete)
+				products.POST("/:id/images", perm(models.PermProductsWrite), productHandler.AddImage)
+				products.PATCH("/:id/images/reorder", perm(models.PermProductsWrite), productHandler.ReorderImages)
+				products.PATCH("/:id/images/:imageId/primary", perm(models.PermProductsWrite), productHandler.SetPrimaryImage)
+				products.DELETE("/:id/images/:imageId", perm(models.PermProductsWrite), productHandler.DeleteImage)
+				products.GET("/:id/batches", perm(models.PermInventoryRead), inventoryHandler.ListBatchesByProduct)
+				products.POST("/:id/batches", perm(models.PermInventoryWrite), inventoryHandler.AddBatch)
+				products.GET("/:id/ingredients", perm(models.PermProductsRead), drugInfoHandler.GetIngredients)
+				products.GET("/:id/stock-subscribers", perm(models.PermProductsRead), backInStockHandler.Subscribers)
+				products.PUT("/:id/ingredients", perm(models.PermProductsWrite), drugInfoHandler.SetIngredients)
+			}
+			// Drug interaction rules; orders that trip them carry warnings to acknowledge before accepting.
+			interactions := api.Group("/drug-interactions")
+			{
+				interactions.GET("", perm(models.PermProductsRead), drugInfoHandler.ListInteractions)
+				interactions.POST("", perm(models.PermProductsWrite), drugInfoHandler.AddInteraction)
+				interactions.DELETE("/:id", perm(models.PermProductsWrite), drugInfoHandler.DeleteInteraction)
+				interactions.POST("/check", perm(models.PermProductsRead), drugInfoHandler.Check)
+			}
+			categories := api.Group("/categories", perm(models.PermCategoriesManage))
+			{
+				categories.POST("", categoryHandler.Create)
+				categories.GET("", categoryHandler.List)
+				categories.GET("/:id", categoryHandler.GetByID)
+				categories.PUT("/:id", categoryHandler.Update)
+				categories.DELETE("/:id", categoryHandler.Delete)
+			}
+			productUnits := api.Group("/product-units", perm(models.PermProductUnitsManage))
+			{
+				productUnits.POST("", productUnitHandler.Create)
+				productUnits.GET("", productUnitHandler.List)
+				productUnits.GET("/:id", productUnitHandler.GetByID)
+				productUnits.PUT("/:id", productUnitHandler.Update)
+				productUnits.DELETE("/:id", productUnitHandler.Delete)
+			}
+			memberships := api.Group("/memberships", perm(models.PermMembershipsManage), planFeature("memberships"))
+			{
+				memberships.POST("", membershipHandler.Create)
+				memberships.GET("", membershipHandler.List)
+				memberships.GET("/:id", membershipHandler.GetByID)
+				memberships.PUT("/:id", membershipHandler.Update)
+				memberships.DELETE("/:id", membershipHandler.Delete)
+			}
+			inventory := api.Group("/inventory")
+			{
+				inventory.GET("/batches", perm(models.PermInventoryRead), inventoryHandler.ListBatchesByPharmacy)
+				inventory.GET("/expiring", perm(models.PermInventoryRead), inventoryHandler.ListExpiringSoon)
+				inventory.GET("/alerts", perm(models.PermInventoryRead), inventoryHandler.Alerts)
+				inventory.GET("/reorder-suggestions", perm(models.PermInventoryRead), purchaseOrderHandler.ReorderSuggestions)
+				inventory.POST("/reorder-suggestions/purchase-order", perm(models.PermPurchaseOrdersManage), purchaseOrderHandler.DraftFromSuggestions)
+				inventory.GET("/batches/:batchId", perm(models.PermInventoryRead), inventoryHandler.GetBatch)
+				inventory.GET("/batches/:batchId/allocations", perm(models.PermInventoryRead), inventoryHandler.ListBatchAllocations)
+				inventory.PATCH("/batches/:batchId", perm(models.PermInventoryWrite), inventoryHandler.UpdateBatch)
+				inventory.DELETE("/batches/:batchId", perm(models.PermInventoryWrite), inventoryHandler.DeleteBatch)
+				// Stock ledger: write-offs with a reason and photo evidence (damaged cartons, broken seals).
+				inventory.GET("/batches/:batchId/ledger", perm(models.PermInventoryRead), inventoryEvidenceHandler.Ledger)
+				inventory.POST("/batches/:batchId/write-offs", perm(models.PermInventoryWrite), inventoryEvidenceHandler.WriteOff)
+				inventory.POST("/batches/:batchId/photos", perm(models.PermInventoryWrite), inventoryEvidenceHandler.AddPhoto)
+			}
+			// Branches: locations of the pharmacy with their own stock; transfers move batches between them
+			branches := api.Group("/branches")
+			{
+				branches.GET("", perm(models.PermInventoryRead), branchHandler.List)
+				branches.POST("", perm(models.PermBranchesManage), branchHandler.Create)
+				branches.GET("/transfers", perm(models.PermInventoryRead), branchHandler.ListTransfers)
+				branches.POST("/transfers", perm(models.PermInventoryWrite), branchHandler.Transfer)
+				branches.GET("/transfers/:id", perm(models.PermInventoryRead), branchHandler.GetTransfer)
+				branches.PUT("/users/:userId", perm(models.PermBranchesManage), branchHandler.AssignUser)
+				branches.GET("/:id", perm(models.PermInventoryRead), branchHandler.Get)
+				branches.PUT("/:id", perm(models.PermBranchesManage), branchHandler.Update)
+				branches.DELETE("/:id", perm(models.PermBranchesManage), branchHandler.Delete)
+				branches.GET("/:id/stock", perm(models.PermInventoryRead), branchHandler.Stock)
+			}
+			// Stock transfers between pharmacies of one group: the destination requests and receives, the source
+			// approves or rejects and dispatches. Each side reads the other's products, so no tenant scope.
+			stockTransfers := api.Group("/stock-transfers", middleware.CrossTenant())
+			{
+				stockTransfers.GET("", perm(models.PermInventoryRead), stockTransferHandler.List)
+				stockTransfers.POST("", perm(models.PermStockTransfersManage), stockTransferHandler.Request)
+				stockTransfers.GET("/:id", perm(models.PermInventoryRead), stockTransferHandler.Get)
+				stockTransfers.POST("/:id/approve", perm(models.PermStockTransfersManage), stockTransferHandler.Approve)
+				stockTransfers.POST("/:id/reject", perm(models.PermStockTransfersManage), stockTransferHandler.Reject)
+				stockTransfers.POST("/:id/dispatch", perm(models.PermStockTransfersManage), stockTransferHandler.Dispatch)
+				stockTransfers.POST("/:id/receive", perm(models.PermStockTransfersManage), stockTransferHandler.Receive)
+				stockTransfers.POST("/:id/cancel", perm(models.PermStockTransfersManage), stockTransferHandler.Cancel)
+			}
+			customers := api.Group("/customers", perm(models.PermCustomersRead))
+			{
+				customers.GET("", referralHandler.ListCustomers)
+				customers.GET("/by-phone", referralHandler.GetCustomerByPhone)
+				customers.GET("/:customerId/points", referralHandler.ListPointsTransactions)
+				customers.PUT("/:customerId/language", referralHandler.SetCustomerLanguage)
+				customers.PUT("/:customerId/date-of-birth", referralHandler.SetCustomerDateOfBirth)
+				customers.GET("/:customerId/store-credit", storeCreditHandler.Ledger)
+				customers.GET("/:customerId/consents", consentHandler.Get)
+			}
+			// Marketing consent: recorded per channel, enforced by campaigns and marketing notifications.
+			api.POST("/customers/:customerId/consents", perm(models.PermConsentManage), consentHandler.Record)
+			api.GET("/consents/export", perm(models.PermConsentManage), piiExport(models.ExportKindConsents, false), consentHandler.Export)
+			// Store credit changes move money, so they need payments.manage rather than customers.read.
+			api.POST("/customers/:customerId/store-credit/adjustments", perm(models.PermPaymentsManage), storeCreditHandler.Adjust)
+			api.POST("/orders/:orderId/store-credit-refunds", perm(models.PermPaymentsManage), storeCreditHandler.RefundOrder)
+			api.POST("/orders/:orderId/accept", perm(models.PermOrdersAccept), orderHandler.Accept)
+			api.POST("/orders/:orderId/acknowledge-warnings", perm(models.PermOrdersAccept), orderHandler.AcknowledgeWarnings)
+			api.PATCH("/orders/:orderId/status", perm(models.PermOrdersUpdateStatus), orderHandler.UpdateStatus)
+			api.POST("/orders/:orderId/invoices", perm(models.PermInvoicesManage), invoiceHandler.CreateFromOrder)
+			promoCodesStaff := api.Group("/promo-codes", perm(models.PermPromoCodesManage))
+			{
+				promoCodesStaff.POST("", promoCodeHandler.Create)
+				promoCodesStaff.GET("", promoCodeHandler.List)
+				promoCodesStaff.GET("/:id", promoCodeHandler.GetByID)
+				promoCodesStaff.PUT("/:id", promoCodeHandler.Update)
+			}
+			// Customer segments and bulk campaigns (notification, SMS, email)
+			segments := api.Group("/customer-segments", perm(models.PermCampaignsManage))
+			{
+				segments.POST("", campaignHandler.CreateSegment)
+				segments.GET("", campaignHandler.ListSegments)
+				segments.PUT("/:id", campaignHandler.UpdateSegment)
+				segments.DELETE("/:id", campaignHandler.DeleteSegment)
+				segments.GET("/:id/preview", campaignHandler.PreviewSegment)
+			}
+			campaigns := api.Group("/campaigns", perm(models.PermCampaignsManage))
+			{
+				campaigns.POST("", campaignHandler.Create)
+				campaigns.GET("", campaignHandler.List)
+				campaigns.GET("/:id", campaignHandler.Get)
+				campaigns.PUT("/:id", campaignHandler.Update)
+				campaigns.DELETE("/:id", campaignHandler.Delete)
+				campaigns.POST("/:id/send", campaignHandler.Send)
+				campaigns.GET("/:id/recipients", campaignHandler.Recipients)
+			}
+			invoices := api.Group("/invoices", perm(models.PermInvoicesManage))
+			{
+				invoices.GET("", invoiceHandler.List)
+				invoices.GET("/:id", invoiceHandler.GetByID)
+				invoices.POST("/:id/issue", invoiceHandler.Issue)
+				invoices.GET("/:id/pdf", invoiceHandler.PDF)
+			}
+			creditNotes := api.Group("/credit-notes", perm(models.PermInvoicesManage))
+			{
+				creditNotes.GET("", invoiceHandler.ListCreditNotes)
+				creditNotes.GET("/:id", invoiceHandler.GetCreditNote)
+				creditNotes.GET("/:id/pdf", invoiceHandler.CreditNotePDF)
+			}
+			payments := api.Group("/payments", perm(models.PermPaymentsManage))
+			{
+				payments.POST("", idempotent, paymentHandler.Create)
+				payments.GET("", paymentHandler.ListByPharmacy)
+				payments.GET("/:id", paymentHandler.GetByID)
+				payments.POST("/:id/complete", paymentHandler.Complete)
+			}
+			walletTopUps := api.Group("/wallet-top-ups", perm(models.PermPaymentsManage))
+			{
+				walletTopUps.GET("", walletHandler.ListTopUps)
+				walletTopUps.POST("/:id/complete", walletHandler.CompleteTopUp)
+				walletTopUps.POST("/:id/fail", walletHandler.FailTopUp)
+			}
+			announcements := api.Group("/announcements", perm(models.PermAnnouncementsManage))
+			{
+				announcements.GET("", announcementHandler.List)
+				announcements.GET("/:id", announcementHandler.GetByID)
+				announcements.POST("", announcementHandler.Create)
+				announcements.PUT("/:id", announcementHandler.Update)
+				announcements.POST("/:id/image", announcementHandler.UploadImage)
+				announcements.DELETE("/:id", announcementHandler.Delete)
+			}
+			// AI drafts: generate product descriptions / blog outlines; nothing is applied until a staff member approves
+			ai := api.Group("/ai", perm(models.PermAIUse))
+			{
+				ai.GET("/usage", aiContentHandler.Usage)
+				ai.POST("/product-description", aiContentHandler.GenerateProductDescription)
+				ai.POST("/blog-outline", aiContentHandler.GenerateBlogOutline)
+				ai.GET("/generations", aiContentHandler.List)
+				ai.GET("/generations/:id", aiContentHandler.GetByID)
+				ai.POST("/generations/:id/review", aiContentHandler.Review)
+			}
+			// Training: team members read SOPs, take quizzes attached to announcements/SOPs and see own compliance;
+			// training.manage authors SOPs and quizzes
+			training := api.Group("/training")
+			{
+				training.GET("/me", perm(models.PermTrainingTake), trainingHandler.MyTraining)
+				training.GET("/sops", perm(models.PermTrainingTake), trainingHandler.ListSOPs)
+				training.GET("/sops/:id", perm(models.PermTrainingTake), trainingHandler.GetSOP)
+				training.POST("/sops/:id/ack", perm(models.PermTrainingTake), trainingHandler.AcknowledgeSOP)
+				training.GET("/quizzes/:id/take", perm(models.PermTrainingTake), trainingHandler.TakeQuiz)
+				training.POST("/quizzes/:id/attempts", perm(models.PermTrainingTake), trainingHandler.SubmitAttempt)
+				training.POST("/sops", perm(models.PermTrainingManage), trainingHandler.CreateSOP)
+				training.PUT("/sops/:id", perm(models.PermTrainingManage), trainingHandler.UpdateSOP)
+				training.DELETE("/sops/:id", perm(models.PermTrainingManage), trainingHandler.DeleteSOP)
+				training.GET("/quizzes", perm(models.PermTrainingManage), trainingHandler.ListQuizzes)
+				training.POST("/quizzes", perm(models.PermTrainingManage), trainingHandler.CreateQuiz)
+				training.GET("/quizzes/:id", perm(models.PermTrainingManage), trainingHandler.GetQuiz)
+				training.PUT("/quizzes/:id", perm(models.PermTrainingManage), trainingHandler.UpdateQuiz)
+				training.DELETE("/quizzes/:id", perm(models.PermTrainingManage), trainingHandler.DeleteQuiz)
+				training.GET("/compliance", perm(models.PermTrainingManage), trainingHandler.Compliance)
 			}
 
 			// Chat WebSocket: token in query (?token=...), no Cookie/Bearer middleware
-			v1.GET("/chat/ws", chatWSHandler)
+			v1.GET("/chat/ws", limitChatConnect, chatWSHandler)
 
 			// Chat REST: staff (JWT) or customer (chat token); no ActivityLog
 			chat := v1.Group("/chat")
 			chat.Use(middleware.ChatAuth(authProvider, userRepo, logger))
+			chat.Use(limitChat, planFeature("chat"), middleware.Subscription(subscriptionService))
 			{
 				chat.GET("/settings", chatHandler.GetChatSettings)
-				chat.POST("/upload", uploadHandler.Upload)
+				chat.POST("/upload", planLimit(models.PlanLimitStorage), uploadHandler.Upload)
+				chat.POST("/uploads", planLimit(models.PlanLimitStorage), uploadHandler.StartUpload)
+				chat.GET("/uploads/:id", uploadHandler.GetUpload)
+				chat.PUT("/uploads/:id", uploadHandler.UploadChunk)
+				chat.DELETE("/uploads/:id", uploadHandler.CancelUpload)
 				chat.GET("/conversations", chatHandler.ListConversations)
 				chat.GET("/me", chatHandler.GetMyConversation)
 				chat.POST("/conversations", chatHandler.CreateConversation)
 				chat.POST("/customer-token", chatHandler.IssueCustomerToken)
 				chat.GET("/conversations/:id", chatHandler.GetConversation)
 				chat.DELETE("/conversations/:id", chatHandler.DeleteConversation)
+				chat.POST("/conversations/:id/escalate", chatHandler.Escalate)
+				chat.GET("/conversations/:id/order-draft", chatHandler.OrderDraft)
+				chat.POST("/conversations/:id/order", chatHandler.CreateOrder)
 				chat.GET("/conversations/:id/messages", chatHandler.ListMessages)
 				chat.POST("/conversations/:id/messages", chatHandler.SendMessage)
 				chat.PATCH("/conversations/:id/messages/:messageId", chatHandler.EditMessage)
@@ -349,5 +913,24 @@ func NewRouter(
 			}
 		}
 	}
+
+	// v2: errors are RFC 9457 problem+json and lists are paginated ({items, total, limit, offset}).
+	v2 := router.Group("/api/v2", middleware.APIVersion("v2", versionMetrics))
+	{
+		v2.GET("/versions", apiVersionHandler.Versions)
+
+		api := v2.Group("")
+		api.Use(middleware.Auth(authProvider, userRepo, logger))
+		api.Use(middleware.ActivityLog(activityLogService, logger))
+		api.Use(middleware.Subscription(subscriptionService))
+		{
+			orders := api.Group("/orders")
+			{
+				orders.POST("", idempotent, orderHandler.Create)
+				orders.GET("", orderHandler.ListPage)
+				orders.GET("/:orderId", orderHandler.GetByID)
+			}
+		}
+	}
 	return router
 }
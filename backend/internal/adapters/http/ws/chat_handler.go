@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
@@ -35,6 +36,9 @@ const (
 	MsgPing        = "ping"
 	MsgSendMessage = "send_message"
 	MsgTyping      = "typing"
+	// join/leave subscribe to a conversation's typing events; data is {conversation_id}.
+	MsgJoin  = "join"
+	MsgLeave = "leave"
 )
 
 // Outgoing server message types
@@ -43,6 +47,12 @@ const (
 	MsgNewMessage  = "new_message"
 	MsgTypingEvent = "typing"
 	MsgError       = "error"
+	// In-app notifications (staff/end-user connections only): "new_notification" carries
+	// {notification, unread_count}; "unread_count" carries {count}.
+	MsgNewNotification = "new_notification"
+	MsgUnreadCount     = "unread_count"
+	// "presence" carries {user_id|customer_id, kind, online}; see Hub.broadcastPresenceLocked for who receives it.
+	MsgPresence = "presence"
 )
 
 type wireMessage struct {
@@ -58,17 +68,24 @@ type sendMessageData struct {
 	AttachmentType string `json:"attachment_type"`
 }
 
+type roomData struct {
+	ConversationID string `json:"conversation_id"`
+}
+
 type typingData struct {
 	ConversationID string `json:"conversation_id"`
 	IsTyping       bool   `json:"is_typing"`
 }
 
 // HandleWS upgrades the connection and runs the chat loop. Token must be in query "token".
+// Users (not chat customers) also receive in-app notification events, starting with their unread count.
 func HandleWS(
 	authProvider outbound.AuthProvider,
 	userRepo outbound.UserRepository,
 	chatService inbound.ChatService,
+	notificationService inbound.NotificationService,
 	convRepo outbound.ConversationRepository,
+	files outbound.PrivateFileStorage,
 	hub *Hub,
 	logger *zap.Logger,
 ) gin.HandlerFunc {
@@ -79,7 +96,7 @@ func HandleWS(
 			return
 		}
 		ctx := c.Request.Context()
-		pharmacyID, userID, customerID, err := validateToken(ctx, authProvider, userRepo, token)
+		pharmacyID, userID, customerID, role, err := validateToken(ctx, authProvider, userRepo, token)
 		if err != nil {
 			logger.Warn("chat ws auth failed", zap.Error(err))
 			c.JSON(http.StatusUnauthorized, gin.H{"code": "UNAUTHORIZED", "message": "invalid token"})
@@ -94,11 +111,14 @@ func HandleWS(
 			PharmacyID: pharmacyID,
 			UserID:     userID,
 			CustomerID: customerID,
+			Role:       role,
 			Send:       make(chan []byte, 256),
 		}
 		hub.Register(client)
 		defer func() {
+			// Unregister before closing Send so no broadcast can write to a closed channel.
 			hub.Unregister(client)
+			close(client.Send)
 			conn.Close()
 		}()
 
@@ -110,24 +130,48 @@ func HandleWS(
 		})
 
 		go writePump(conn, client, logger)
-		readPump(ctx, conn, client, chatService, convRepo, hub, logger)
+		if userID != nil {
+			notificationService.SendUnreadCount(ctx, *userID)
+		}
+		readPump(ctx, conn, client, chatService, convRepo, files, hub, logger)
 	}
 }
 
-func validateToken(ctx context.Context, authProvider outbound.AuthProvider, userRepo outbound.UserRepository, token string) (pharmacyID uuid.UUID, userID *uuid.UUID, customerID *uuid.UUID, err error) {
+func validateToken(ctx context.Context, authProvider outbound.AuthProvider, userRepo outbound.UserRepository, token string) (pharmacyID uuid.UUID, userID *uuid.UUID, customerID *uuid.UUID, role string, err error) {
 	claims, err := authProvider.ValidateAccessToken(token)
 	if err == nil && claims != nil {
 		user, err := userRepo.GetByID(ctx, claims.UserID)
 		if err != nil || user == nil || !user.IsActive {
-			return uuid.Nil, nil, nil, err
+			return uuid.Nil, nil, nil, "", err
 		}
-		return claims.PharmacyID, &claims.UserID, nil, nil
+		return claims.PharmacyID, &claims.UserID, nil, user.Role, nil
 	}
 	chatClaims, err := authProvider.ValidateChatCustomerToken(token)
 	if err == nil && chatClaims != nil {
-		return chatClaims.PharmacyID, nil, &chatClaims.CustomerID, nil
+		return chatClaims.PharmacyID, nil, &chatClaims.CustomerID, "", nil
+	}
+	return uuid.Nil, nil, nil, "", err
+}
+
+// loadConversation returns the conversation when the client may take part in it: same pharmacy, and for
+// chat customers and end users only their own conversation. Team members can access every conversation.
+func loadConversation(ctx context.Context, convRepo outbound.ConversationRepository, client *Client, rawID string) (*models.Conversation, bool) {
+	convID, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, false
+	}
+	conv, err := convRepo.GetByID(ctx, convID)
+	if err != nil || conv == nil || conv.PharmacyID != client.PharmacyID {
+		return nil, false
+	}
+	switch {
+	case client.CustomerID != nil:
+		return conv, conv.CustomerID != nil && *conv.CustomerID == *client.CustomerID
+	case client.isTeam():
+		return conv, true
+	default:
+		return conv, conv.UserID != nil && *conv.UserID == *client.UserID
 	}
-	return uuid.Nil, nil, nil, err
 }
 
 func readPump(
@@ -136,10 +180,10 @@ func readPump(
 	client *Client,
 	chatService inbound.ChatService,
 	convRepo outbound.ConversationRepository,
+	files outbound.PrivateFileStorage,
 	hub *Hub,
 	logger *zap.Logger,
 ) {
-	defer close(client.Send)
 	for {
 		_, data, err := conn.ReadMessage()
 		if err != nil {
@@ -172,43 +216,72 @@ func readPump(
 				senderType = models.SenderTypeUser
 				senderID = *client.UserID
 			}
+			if ref, ok := files.PrivateRef(body.AttachmentURL); ok {
+				body.AttachmentURL = ref
+			}
 			message, err := chatService.SendMessage(ctx, convID, senderType, senderID, body.Body, body.AttachmentURL, body.AttachmentName, body.AttachmentType)
 			if err != nil {
 				sendError(client, err.Error())
 				continue
 			}
+			if strings.HasPrefix(message.AttachmentURL, outbound.PrivateFileRefPrefix) {
+				// Everyone in the conversation gets the same short-lived link; reloading the history signs afresh.
+				signed := *message
+				if signed.AttachmentURL, err = files.SignedURL(ctx, message.AttachmentURL); err != nil {
+					logger.Warn("failed to sign chat attachment", zap.Error(err))
+					signed.AttachmentURL = ""
+				}
+				message = &signed
+			}
 			conv, err := convRepo.GetByID(ctx, convID)
 			if err == nil {
+				hub.Join(client, conv.ID)
 				payload := mustMarshal(wireMessage{Type: MsgNewMessage, Data: mustMarshal(message)})
 				hub.BroadcastToConversation(conv.PharmacyID, conv.CustomerID, payload)
 			}
+		case MsgJoin, MsgLeave:
+			var body roomData
+			if err := json.Unmarshal(msg.Data, &body); err != nil {
+				sendError(client, "invalid "+msg.Type+" data")
+				continue
+			}
+			if msg.Type == MsgLeave {
+				if convID, err := uuid.Parse(body.ConversationID); err == nil {
+					hub.Leave(client, convID)
+				}
+				continue
+			}
+			conv, ok := loadConversation(ctx, convRepo, client, body.ConversationID)
+			if !ok {
+				sendError(client, "conversation not found")
+				continue
+			}
+			hub.Join(client, conv.ID)
 		case MsgTyping:
 			var body typingData
 			if err := json.Unmarshal(msg.Data, &body); err != nil {
 				continue
 			}
-			convID, _ := uuid.Parse(body.ConversationID)
-			conv, err := convRepo.GetByID(ctx, convID)
-			if err != nil {
+			conv, ok := loadConversation(ctx, convRepo, client, body.ConversationID)
+			if !ok {
 				continue
 			}
+			// Typing implies interest in the conversation; only participants in its room hear it.
+			hub.Join(client, conv.ID)
+			senderType, senderID := models.SenderTypeUser, ""
+			if client.CustomerID != nil {
+				senderType, senderID = models.SenderTypeCustomer, client.CustomerID.String()
+			} else if client.UserID != nil {
+				senderID = client.UserID.String()
+			}
 			payload := mustMarshal(map[string]interface{}{
-				"type":             MsgTypingEvent,
-				"conversation_id":  body.ConversationID,
-				"is_typing":        body.IsTyping,
-				"sender_type":      "user",
-				"sender_id":        "",
+				"type":            MsgTypingEvent,
+				"conversation_id": conv.ID.String(),
+				"is_typing":       body.IsTyping,
+				"sender_type":     senderType,
+				"sender_id":       senderID,
 			})
-			if client.CustomerID != nil {
-				payload = mustMarshal(map[string]interface{}{
-					"type":            MsgTypingEvent,
-					"conversation_id": body.ConversationID,
-					"is_typing":       body.IsTyping,
-					"sender_type":     "customer",
-					"sender_id":       client.CustomerID.String(),
-				})
-			}
-			hub.BroadcastToConversation(conv.PharmacyID, conv.CustomerID, payload)
+			hub.BroadcastToRoom(conv.ID, payload, client)
 		}
 	}
 }
@@ -7,12 +7,23 @@ import (
 	"go.uber.org/zap"
 )
 
+// endUserRole is the role of shop customers with a login; every other user role is pharmacy team.
+const endUserRole = "staff"
+
 // Client is a WebSocket client (staff or customer).
 type Client struct {
 	PharmacyID uuid.UUID
 	UserID     *uuid.UUID // staff
 	CustomerID *uuid.UUID // customer
+	Role       string     // user role from the access token; empty for customers
 	Send       chan []byte
+
+	rooms map[uuid.UUID]struct{} // conversations joined; guarded by Hub.mu
+}
+
+// isTeam reports whether the client is a pharmacy team member (admin, manager, pharmacist).
+func (c *Client) isTeam() bool {
+	return c.UserID != nil && c.Role != endUserRole
 }
 
 // Hub holds registered clients and broadcasts messages.
@@ -21,50 +32,109 @@ type Hub struct {
 	pharmacies map[uuid.UUID]map[*Client]struct{}
 	// customerID -> customer clients
 	customers map[uuid.UUID]map[*Client]struct{}
-	mu        sync.RWMutex
-	logger    *zap.Logger
+	// userID -> that user's staff clients (for per-user events such as notifications)
+	users map[uuid.UUID]map[*Client]struct{}
+	// conversationID -> clients that joined the conversation (typing is only sent to them)
+	rooms  map[uuid.UUID]map[*Client]struct{}
+	mu     sync.RWMutex
+	logger *zap.Logger
 }
 
 func NewHub(logger *zap.Logger) *Hub {
 	return &Hub{
 		pharmacies: make(map[uuid.UUID]map[*Client]struct{}),
 		customers:  make(map[uuid.UUID]map[*Client]struct{}),
+		users:      make(map[uuid.UUID]map[*Client]struct{}),
+		rooms:      make(map[uuid.UUID]map[*Client]struct{}),
 		logger:     logger,
 	}
 }
 
+func addClient(m map[uuid.UUID]map[*Client]struct{}, key uuid.UUID, client *Client) int {
+	if m[key] == nil {
+		m[key] = make(map[*Client]struct{})
+	}
+	m[key][client] = struct{}{}
+	return len(m[key])
+}
+
+func removeClient(m map[uuid.UUID]map[*Client]struct{}, key uuid.UUID, client *Client) int {
+	set := m[key]
+	if set == nil {
+		return 0
+	}
+	delete(set, client)
+	if len(set) == 0 {
+		delete(m, key)
+	}
+	return len(set)
+}
+
+// Register adds the client. The first connection of a user or customer announces them online.
 func (h *Hub) Register(client *Client) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	client.rooms = make(map[uuid.UUID]struct{})
 	if client.CustomerID != nil {
-		if h.customers[*client.CustomerID] == nil {
-			h.customers[*client.CustomerID] = make(map[*Client]struct{})
+		if addClient(h.customers, *client.CustomerID, client) == 1 {
+			h.broadcastPresenceLocked(client, true)
 		}
-		h.customers[*client.CustomerID][client] = struct{}{}
-	} else {
-		if h.pharmacies[client.PharmacyID] == nil {
-			h.pharmacies[client.PharmacyID] = make(map[*Client]struct{})
-		}
-		h.pharmacies[client.PharmacyID][client] = struct{}{}
+		return
+	}
+	addClient(h.pharmacies, client.PharmacyID, client)
+	if client.UserID != nil && addClient(h.users, *client.UserID, client) == 1 {
+		h.broadcastPresenceLocked(client, true)
 	}
 }
 
+// Unregister removes the client from the hub and its rooms. The last connection going away announces offline.
 func (h *Hub) Unregister(client *Client) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	for convID := range client.rooms {
+		removeClient(h.rooms, convID, client)
+	}
+	client.rooms = nil
 	if client.CustomerID != nil {
-		if m := h.customers[*client.CustomerID]; m != nil {
-			delete(m, client)
-			if len(m) == 0 {
-				delete(h.customers, *client.CustomerID)
-			}
+		if _, ok := h.customers[*client.CustomerID][client]; ok && removeClient(h.customers, *client.CustomerID, client) == 0 {
+			h.broadcastPresenceLocked(client, false)
 		}
-	} else {
-		if m := h.pharmacies[client.PharmacyID]; m != nil {
-			delete(m, client)
-			if len(m) == 0 {
-				delete(h.pharmacies, client.PharmacyID)
-			}
+		return
+	}
+	removeClient(h.pharmacies, client.PharmacyID, client)
+	if client.UserID != nil {
+		if _, ok := h.users[*client.UserID][client]; ok && removeClient(h.users, *client.UserID, client) == 0 {
+			h.broadcastPresenceLocked(client, false)
+		}
+	}
+}
+
+// Join adds the client to a conversation room. Callers check access to the conversation first.
+func (h *Hub) Join(client *Client, conversationID uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if client.rooms == nil {
+		return // unregistered
+	}
+	client.rooms[conversationID] = struct{}{}
+	addClient(h.rooms, conversationID, client)
+}
+
+// Leave removes the client from a conversation room.
+func (h *Hub) Leave(client *Client, conversationID uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(client.rooms, conversationID)
+	removeClient(h.rooms, conversationID, client)
+}
+
+// BroadcastToRoom sends payload to the clients that joined the conversation, except the sender.
+func (h *Hub) BroadcastToRoom(conversationID uuid.UUID, payload []byte, except *Client) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for c := range h.rooms[conversationID] {
+		if c != except {
+			h.send(c, payload)
 		}
 	}
 }
@@ -75,20 +145,100 @@ func (h *Hub) BroadcastToConversation(pharmacyID uuid.UUID, customerID *uuid.UUI
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 	for c := range h.pharmacies[pharmacyID] {
-		select {
-		case c.Send <- payload:
-		default:
-			h.logger.Debug("chat client send buffer full, skip")
-		}
+		h.send(c, payload)
 	}
 	if customerID != nil {
 		for c := range h.customers[*customerID] {
-			select {
-			case c.Send <- payload:
-			default:
-				h.logger.Debug("chat client send buffer full, skip")
+			h.send(c, payload)
+		}
+	}
+}
+
+// SendToUser sends an event to every open connection of the user. It implements outbound.RealtimeNotifier.
+func (h *Hub) SendToUser(userID uuid.UUID, event string, data interface{}) {
+	payload := mustMarshal(wireMessage{Type: event, Data: mustMarshal(data)})
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for c := range h.users[userID] {
+		h.send(c, payload)
+	}
+}
+
+// IsUserOnline reports whether the user has an open connection. It implements outbound.PresenceTracker.
+func (h *Hub) IsUserOnline(userID uuid.UUID) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.users[userID]) > 0
+}
+
+// IsCustomerOnline reports whether the chat customer has an open connection.
+func (h *Hub) IsCustomerOnline(customerID uuid.UUID) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.customers[customerID]) > 0
+}
+
+// OnlineStaff returns the pharmacy team members (not end users) with an open connection.
+func (h *Hub) OnlineStaff(pharmacyID uuid.UUID) []uuid.UUID {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	seen := make(map[uuid.UUID]struct{})
+	out := []uuid.UUID{}
+	for c := range h.pharmacies[pharmacyID] {
+		if !c.isTeam() {
+			continue
+		}
+		if _, ok := seen[*c.UserID]; !ok {
+			seen[*c.UserID] = struct{}{}
+			out = append(out, *c.UserID)
+		}
+	}
+	return out
+}
+
+type presenceData struct {
+	UserID     string `json:"user_id,omitempty"`
+	CustomerID string `json:"customer_id,omitempty"`
+	Kind       string `json:"kind"` // "staff" (team member), "user" (end user) or "customer"
+	Online     bool   `json:"online"`
+}
+
+// broadcastPresenceLocked announces a presence change. Team presence goes to everyone connected to the
+// pharmacy (team, end users and chat customers); end-user and customer presence goes to the team only.
+// Callers hold h.mu.
+func (h *Hub) broadcastPresenceLocked(client *Client, online bool) {
+	data := presenceData{Online: online}
+	switch {
+	case client.CustomerID != nil:
+		data.CustomerID, data.Kind = client.CustomerID.String(), "customer"
+	case client.isTeam():
+		data.UserID, data.Kind = client.UserID.String(), "staff"
+	default:
+		data.UserID, data.Kind = client.UserID.String(), "user"
+	}
+	payload := mustMarshal(wireMessage{Type: MsgPresence, Data: mustMarshal(data)})
+	for c := range h.pharmacies[client.PharmacyID] {
+		if c != client && (data.Kind == "staff" || c.isTeam()) {
+			h.send(c, payload)
+		}
+	}
+	if data.Kind != "staff" {
+		return
+	}
+	for _, set := range h.customers {
+		for c := range set {
+			if c.PharmacyID == client.PharmacyID {
+				h.send(c, payload)
 			}
 		}
 	}
 }
 
+// send queues payload without blocking; slow clients miss the message.
+func (h *Hub) send(c *Client, payload []byte) {
+	select {
+	case c.Send <- payload:
+	default:
+		h.logger.Debug("ws client send buffer full, skip")
+	}
+}
@@ -0,0 +1,33 @@
+package llm
+
+import (
+	"context"
+	"strings"
+
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+)
+
+// StubProvider returns canned text built from the prompt. Used in development and demos (LLM_PROVIDER=stub)
+// so the draft/review workflow can be exercised without an API key.
+type StubProvider struct{}
+
+func NewStubProvider() *StubProvider {
+	return &StubProvider{}
+}
+
+func (p *StubProvider) Complete(ctx context.Context, systemPrompt, userPrompt string) (*outbound.LLMCompletion, error) {
+	lines := strings.Split(strings.TrimSpace(userPrompt), "\n")
+	var b strings.Builder
+	b.WriteString("[Draft generated for review]\n")
+	for _, l := range lines {
+		if l = strings.TrimSpace(l); l != "" {
+			b.WriteString("- " + l + "\n")
+		}
+	}
+	return &outbound.LLMCompletion{
+		Text:             strings.TrimSpace(b.String()),
+		Model:            "stub",
+		PromptTokens:     len(strings.Fields(systemPrompt + " " + userPrompt)),
+		CompletionTokens: len(strings.Fields(b.String())),
+	}, nil
+}
@@ -2,6 +2,7 @@ package persistence
 
 import (
 	"context"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
@@ -18,10 +19,30 @@ func NewActivityLogRepository(db *gorm.DB) outbound.ActivityLogRepository {
 }
 
 func (r *activityLogRepo) Create(ctx context.Context, a *models.ActivityLog) error {
-	return r.db.WithContext(ctx).Create(a).Error
+	return dbFrom(ctx, r.db).Create(a).Error
 }
 
-func (r *activityLogRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.ActivityLog, error) {
+func (r *activityLogRepo) filtered(ctx context.Context, pharmacyID uuid.UUID, filter outbound.ActivityLogFilter) *gorm.DB {
+	q := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID)
+	if filter.UserID != nil {
+		q = q.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Action != "" {
+		q = q.Where("action ILIKE ?", "%"+filter.Action+"%")
+	}
+	if filter.EntityType != "" {
+		q = q.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.From != nil {
+		q = q.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		q = q.Where("created_at < ?", *filter.To)
+	}
+	return q
+}
+
+func (r *activityLogRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, filter outbound.ActivityLogFilter, limit, offset int) ([]*models.ActivityLog, error) {
 	if limit <= 0 {
 		limit = 50
 	}
@@ -29,8 +50,7 @@ func (r *activityLogRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UU
 		limit = 100
 	}
 	var list []*models.ActivityLog
-	err := r.db.WithContext(ctx).
-		Where("pharmacy_id = ?", pharmacyID).
+	err := r.filtered(ctx, pharmacyID, filter).
 		Preload("User").
 		Order("created_at DESC").
 		Limit(limit).
@@ -38,3 +58,18 @@ func (r *activityLogRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UU
 		Find(&list).Error
 	return list, err
 }
+
+func (r *activityLogRepo) ListForExport(ctx context.Context, pharmacyID uuid.UUID, filter outbound.ActivityLogFilter, limit int) ([]*models.ActivityLog, error) {
+	var list []*models.ActivityLog
+	err := r.filtered(ctx, pharmacyID, filter).
+		Preload("User").
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&list).Error
+	return list, err
+}
+
+func (r *activityLogRepo) DeleteBefore(ctx context.Context, pharmacyID uuid.UUID, cutoff time.Time) (int64, error) {
+	res := dbFrom(ctx, r.db).Where("pharmacy_id = ? AND created_at < ?", pharmacyID, cutoff).Delete(&models.ActivityLog{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,97 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type aiGenerationRepo struct {
+	db *gorm.DB
+}
+
+func NewAIGenerationRepository(db *gorm.DB) outbound.AIGenerationRepository {
+	return &aiGenerationRepo{db: db}
+}
+
+func (r *aiGenerationRepo) Create(ctx context.Context, g *models.AIGeneration, since time.Time, quota int) (bool, error) {
+	if quota <= 0 {
+		return true, dbFrom(ctx, r.db).Create(g).Error
+	}
+	created := false
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// One writer per pharmacy at a time, so two requests cannot both take the last generation of the month.
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "ai_generations:"+g.PharmacyID.String()).Error; err != nil {
+			return err
+		}
+		used, err := countAIGenerationsSince(tx, g.PharmacyID, since)
+		if err != nil {
+			return err
+		}
+		if used >= int64(quota) {
+			return nil
+		}
+		if err := tx.Create(g).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+func (r *aiGenerationRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.AIGeneration, error) {
+	var g models.AIGeneration
+	err := dbFrom(ctx, r.db).Preload("Requester").First(&g, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (r *aiGenerationRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, kind, status *string, limit, offset int) ([]*models.AIGeneration, int64, error) {
+	scope := func() *gorm.DB {
+		q := dbFrom(ctx, r.db).Model(&models.AIGeneration{}).Where("pharmacy_id = ?", pharmacyID)
+		if kind != nil && *kind != "" {
+			q = q.Where("kind = ?", *kind)
+		}
+		if status != nil && *status != "" {
+			q = q.Where("status = ?", *status)
+		}
+		return q
+	}
+	var total int64
+	if err := scope().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.AIGeneration
+	q := scope().Preload("Requester").Order("created_at DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	err := q.Find(&list).Error
+	return list, total, err
+}
+
+func (r *aiGenerationRepo) CountByPharmacySince(ctx context.Context, pharmacyID uuid.UUID, since time.Time) (int64, error) {
+	return countAIGenerationsSince(dbFrom(ctx, r.db), pharmacyID, since)
+}
+
+func countAIGenerationsSince(db *gorm.DB, pharmacyID uuid.UUID, since time.Time) (int64, error) {
+	var n int64
+	err := db.Model(&models.AIGeneration{}).
+		Where("pharmacy_id = ? AND created_at >= ? AND status <> ?", pharmacyID, since, models.AIGenerationStatusFailed).
+		Count(&n).Error
+	return n, err
+}
+
+func (r *aiGenerationRepo) Update(ctx context.Context, g *models.AIGeneration) error {
+	return dbFrom(ctx, r.db).Save(g).Error
+}
@@ -19,12 +19,12 @@ func NewAnnouncementAckRepository(db *gorm.DB) outbound.AnnouncementAckRepositor
 }
 
 func (r *announcementAckRepo) Create(ctx context.Context, a *models.AnnouncementAck) error {
-	return r.db.WithContext(ctx).Create(a).Error
+	return dbFrom(ctx, r.db).Create(a).Error
 }
 
 func (r *announcementAckRepo) HasAcked(ctx context.Context, userID, announcementID uuid.UUID) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.AnnouncementAck{}).
+	err := dbFrom(ctx, r.db).Model(&models.AnnouncementAck{}).
 		Where("user_id = ? AND announcement_id = ? AND skip_all = ?", userID, announcementID, false).
 		Count(&count).Error
 	if err != nil {
@@ -35,7 +35,7 @@ func (r *announcementAckRepo) HasAcked(ctx context.Context, userID, announcement
 
 func (r *announcementAckRepo) HasSkippedAllSince(ctx context.Context, userID uuid.UUID, since time.Time) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.AnnouncementAck{}).
+	err := dbFrom(ctx, r.db).Model(&models.AnnouncementAck{}).
 		Where("user_id = ? AND skip_all = ? AND acknowledged_at >= ?", userID, true, since).
 		Count(&count).Error
 	if err != nil {
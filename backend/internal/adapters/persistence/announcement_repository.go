@@ -19,12 +19,12 @@ func NewAnnouncementRepository(db *gorm.DB) outbound.AnnouncementRepository {
 }
 
 func (r *announcementRepo) Create(ctx context.Context, a *models.Announcement) error {
-	return r.db.WithContext(ctx).Create(a).Error
+	return dbFrom(ctx, r.db).Create(a).Error
 }
 
 func (r *announcementRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Announcement, error) {
 	var a models.Announcement
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&a).Error
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&a).Error
 	if err != nil {
 		return nil, err
 	}
@@ -33,7 +33,7 @@ func (r *announcementRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.A
 
 func (r *announcementRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, activeOnly bool) ([]*models.Announcement, error) {
 	now := time.Now()
-	q := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID)
+	q := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID)
 	if activeOnly {
 		q = q.Where("is_active = ?", true).
 			Where("(start_at IS NULL OR start_at <= ?)", now).
@@ -46,9 +46,9 @@ func (r *announcementRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.U
 }
 
 func (r *announcementRepo) Update(ctx context.Context, a *models.Announcement) error {
-	return r.db.WithContext(ctx).Save(a).Error
+	return dbFrom(ctx, r.db).Save(a).Error
 }
 
 func (r *announcementRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.Announcement{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.Announcement{}, "id = ?", id).Error
 }
@@ -0,0 +1,52 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type apiUsageRepo struct {
+	db *gorm.DB
+}
+
+func NewAPIUsageRepository(db *gorm.DB) outbound.APIUsageRepository {
+	return &apiUsageRepo{db: db}
+}
+
+func (r *apiUsageRepo) Add(ctx context.Context, rows []*models.APIUsageDaily) error {
+	// One transaction, so a failed flush writes nothing and can be retried whole.
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, row := range rows {
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "pharmacy_id"}, {Name: "day"}, {Name: "method"}, {Name: "route"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"requests":         gorm.Expr("api_usage_daily.requests + ?", row.Requests),
+					"client_errors":    gorm.Expr("api_usage_daily.client_errors + ?", row.ClientErrors),
+					"server_errors":    gorm.Expr("api_usage_daily.server_errors + ?", row.ServerErrors),
+					"latency_ms_total": gorm.Expr("api_usage_daily.latency_ms_total + ?", row.LatencyMsTotal),
+					"latency_ms_max":   gorm.Expr("GREATEST(api_usage_daily.latency_ms_max, ?)", row.LatencyMsMax),
+					"updated_at":       time.Now(),
+				}),
+			}).Create(row).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *apiUsageRepo) List(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.APIUsageDaily, error) {
+	var list []*models.APIUsageDaily
+	err := dbFrom(ctx, r.db).
+		Where("pharmacy_id = ? AND day >= ? AND day <= ?", pharmacyID, from, to).
+		Order("day ASC, route ASC, method ASC").
+		Find(&list).Error
+	return list, err
+}
@@ -18,12 +18,12 @@ func NewBlogCategoryRepository(db *gorm.DB) outbound.BlogCategoryRepository {
 }
 
 func (r *blogCategoryRepo) Create(ctx context.Context, c *models.BlogCategory) error {
-	return r.db.WithContext(ctx).Create(c).Error
+	return dbFrom(ctx, r.db).Create(c).Error
 }
 
 func (r *blogCategoryRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.BlogCategory, error) {
 	var cat models.BlogCategory
-	err := r.db.WithContext(ctx).First(&cat, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).First(&cat, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +32,7 @@ func (r *blogCategoryRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.B
 
 func (r *blogCategoryRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, parentID *uuid.UUID) ([]*models.BlogCategory, error) {
 	var list []*models.BlogCategory
-	q := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID)
+	q := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID)
 	if parentID == nil {
 		q = q.Where("parent_id IS NULL")
 	} else {
@@ -43,9 +43,9 @@ func (r *blogCategoryRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.U
 }
 
 func (r *blogCategoryRepo) Update(ctx context.Context, c *models.BlogCategory) error {
-	return r.db.WithContext(ctx).Save(c).Error
+	return dbFrom(ctx, r.db).Save(c).Error
 }
 
 func (r *blogCategoryRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.BlogCategory{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.BlogCategory{}, "id = ?", id).Error
 }
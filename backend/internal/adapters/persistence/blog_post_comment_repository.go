@@ -18,12 +18,12 @@ func NewBlogPostCommentRepository(db *gorm.DB) outbound.BlogPostCommentRepositor
 }
 
 func (r *blogPostCommentRepo) Create(ctx context.Context, c *models.BlogPostComment) error {
-	return r.db.WithContext(ctx).Create(c).Error
+	return dbFrom(ctx, r.db).Create(c).Error
 }
 
 func (r *blogPostCommentRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.BlogPostComment, error) {
 	var comment models.BlogPostComment
-	err := r.db.WithContext(ctx).Preload("User").First(&comment, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).Preload("User").First(&comment, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +32,7 @@ func (r *blogPostCommentRepo) GetByID(ctx context.Context, id uuid.UUID) (*model
 
 func (r *blogPostCommentRepo) ListByPostID(ctx context.Context, postID uuid.UUID, limit, offset int) ([]*models.BlogPostComment, error) {
 	var list []*models.BlogPostComment
-	q := r.db.WithContext(ctx).Where("post_id = ?", postID).Order("created_at ASC").Preload("User")
+	q := dbFrom(ctx, r.db).Where("post_id = ?", postID).Order("created_at ASC").Preload("User")
 	if limit > 0 {
 		q = q.Limit(limit)
 	}
@@ -45,10 +45,10 @@ func (r *blogPostCommentRepo) ListByPostID(ctx context.Context, postID uuid.UUID
 
 func (r *blogPostCommentRepo) CountByPostID(ctx context.Context, postID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.BlogPostComment{}).Where("post_id = ?", postID).Count(&count).Error
+	err := dbFrom(ctx, r.db).Model(&models.BlogPostComment{}).Where("post_id = ?", postID).Count(&count).Error
 	return count, err
 }
 
 func (r *blogPostCommentRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.BlogPostComment{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.BlogPostComment{}, "id = ?", id).Error
 }
@@ -18,21 +18,21 @@ func NewBlogPostLikeRepository(db *gorm.DB) outbound.BlogPostLikeRepository {
 }
 
 func (r *blogPostLikeRepo) Create(ctx context.Context, l *models.BlogPostLike) error {
-	return r.db.WithContext(ctx).Create(l).Error
+	return dbFrom(ctx, r.db).Create(l).Error
 }
 
 func (r *blogPostLikeRepo) DeleteByPostAndUser(ctx context.Context, postID, userID uuid.UUID) error {
-	return r.db.WithContext(ctx).Where("post_id = ? AND user_id = ?", postID, userID).Delete(&models.BlogPostLike{}).Error
+	return dbFrom(ctx, r.db).Where("post_id = ? AND user_id = ?", postID, userID).Delete(&models.BlogPostLike{}).Error
 }
 
 func (r *blogPostLikeRepo) CountByPostID(ctx context.Context, postID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.BlogPostLike{}).Where("post_id = ?", postID).Count(&count).Error
+	err := dbFrom(ctx, r.db).Model(&models.BlogPostLike{}).Where("post_id = ?", postID).Count(&count).Error
 	return count, err
 }
 
 func (r *blogPostLikeRepo) Exists(ctx context.Context, postID, userID uuid.UUID) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.BlogPostLike{}).Where("post_id = ? AND user_id = ?", postID, userID).Count(&count).Error
+	err := dbFrom(ctx, r.db).Model(&models.BlogPostLike{}).Where("post_id = ? AND user_id = ?", postID, userID).Count(&count).Error
 	return count > 0, err
 }
@@ -18,12 +18,12 @@ func NewBlogPostMediaRepository(db *gorm.DB) outbound.BlogPostMediaRepository {
 }
 
 func (r *blogPostMediaRepo) Create(ctx context.Context, m *models.BlogPostMedia) error {
-	return r.db.WithContext(ctx).Create(m).Error
+	return dbFrom(ctx, r.db).Create(m).Error
 }
 
 func (r *blogPostMediaRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.BlogPostMedia, error) {
 	var m models.BlogPostMedia
-	err := r.db.WithContext(ctx).First(&m, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).First(&m, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,18 +32,18 @@ func (r *blogPostMediaRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.
 
 func (r *blogPostMediaRepo) ListByPostID(ctx context.Context, postID uuid.UUID) ([]*models.BlogPostMedia, error) {
 	var list []*models.BlogPostMedia
-	err := r.db.WithContext(ctx).Where("post_id = ?", postID).Order("sort_order ASC, created_at ASC").Find(&list).Error
+	err := dbFrom(ctx, r.db).Where("post_id = ?", postID).Order("sort_order ASC, created_at ASC").Find(&list).Error
 	return list, err
 }
 
 func (r *blogPostMediaRepo) Update(ctx context.Context, m *models.BlogPostMedia) error {
-	return r.db.WithContext(ctx).Save(m).Error
+	return dbFrom(ctx, r.db).Save(m).Error
 }
 
 func (r *blogPostMediaRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.BlogPostMedia{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.BlogPostMedia{}, "id = ?", id).Error
 }
 
 func (r *blogPostMediaRepo) DeleteByPostID(ctx context.Context, postID uuid.UUID) error {
-	return r.db.WithContext(ctx).Where("post_id = ?", postID).Delete(&models.BlogPostMedia{}).Error
+	return dbFrom(ctx, r.db).Where("post_id = ?", postID).Delete(&models.BlogPostMedia{}).Error
 }
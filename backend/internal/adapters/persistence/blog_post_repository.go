@@ -2,6 +2,7 @@ package persistence
 
 import (
 	"context"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
@@ -18,12 +19,12 @@ func NewBlogPostRepository(db *gorm.DB) outbound.BlogPostRepository {
 }
 
 func (r *blogPostRepo) Create(ctx context.Context, p *models.BlogPost) error {
-	return r.db.WithContext(ctx).Create(p).Error
+	return dbFrom(ctx, r.db).Create(p).Error
 }
 
 func (r *blogPostRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.BlogPost, error) {
 	var post models.BlogPost
-	err := r.db.WithContext(ctx).Preload("Author").Preload("Category").Preload("Pharmacy").
+	err := dbFrom(ctx, r.db).Preload("Author").Preload("Category").Preload("Pharmacy").
 		First(&post, "id = ?", id).Error
 	if err != nil {
 		return nil, err
@@ -33,7 +34,7 @@ func (r *blogPostRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.BlogP
 
 func (r *blogPostRepo) GetByPharmacyAndSlug(ctx context.Context, pharmacyID uuid.UUID, slug string) (*models.BlogPost, error) {
 	var post models.BlogPost
-	err := r.db.WithContext(ctx).Where("pharmacy_id = ? AND slug = ?", pharmacyID, slug).
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ? AND slug = ?", pharmacyID, slug).
 		Preload("Author").Preload("Category").First(&post).Error
 	if err != nil {
 		return nil, err
@@ -41,26 +42,32 @@ func (r *blogPostRepo) GetByPharmacyAndSlug(ctx context.Context, pharmacyID uuid
 	return &post, nil
 }
 
-func (r *blogPostRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, status *string, categoryID *uuid.UUID, limit, offset int) ([]*models.BlogPost, int64, error) {
+func (r *blogPostRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, status *string, categoryID, tagID *uuid.UUID, limit, offset int) ([]*models.BlogPost, int64, error) {
 	var list []*models.BlogPost
-	q := r.db.WithContext(ctx).Model(&models.BlogPost{}).Where("pharmacy_id = ?", pharmacyID)
+	q := dbFrom(ctx, r.db).Model(&models.BlogPost{}).Where("pharmacy_id = ?", pharmacyID)
 	if status != nil && *status != "" {
 		q = q.Where("status = ?", *status)
 	}
 	if categoryID != nil {
 		q = q.Where("category_id = ?", *categoryID)
 	}
+	if tagID != nil {
+		q = q.Where("id IN (?)", dbFrom(ctx, r.db).Model(&models.BlogPostTag{}).Select("post_id").Where("tag_id = ?", *tagID))
+	}
 	var total int64
 	if err := q.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
-	q = r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID)
+	q = dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID)
 	if status != nil && *status != "" {
 		q = q.Where("status = ?", *status)
 	}
 	if categoryID != nil {
 		q = q.Where("category_id = ?", *categoryID)
 	}
+	if tagID != nil {
+		q = q.Where("id IN (?)", dbFrom(ctx, r.db).Model(&models.BlogPostTag{}).Select("post_id").Where("tag_id = ?", *tagID))
+	}
 	q = q.Order("created_at DESC").Preload("Author").Preload("Category")
 	if limit > 0 {
 		q = q.Limit(limit)
@@ -74,13 +81,24 @@ func (r *blogPostRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID,
 
 func (r *blogPostRepo) ListPendingByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.BlogPost, int64, error) {
 	status := models.BlogPostStatusPendingApproval
-	return r.ListByPharmacy(ctx, pharmacyID, &status, nil, limit, offset)
+	return r.ListByPharmacy(ctx, pharmacyID, &status, nil, nil, limit, offset)
 }
 
 func (r *blogPostRepo) Update(ctx context.Context, p *models.BlogPost) error {
-	return r.db.WithContext(ctx).Save(p).Error
+	return dbFrom(ctx, r.db).Save(p).Error
 }
 
 func (r *blogPostRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.BlogPost{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.BlogPost{}, "id = ?", id).Error
+}
+
+func (r *blogPostRepo) PublishScheduled(ctx context.Context, now time.Time) (int64, error) {
+	res := dbFrom(ctx, r.db).Model(&models.BlogPost{}).
+		Where("status = ? AND scheduled_at <= ?", models.BlogPostStatusScheduled, now).
+		Updates(map[string]interface{}{
+			"status":       models.BlogPostStatusPublished,
+			"published_at": gorm.Expr("scheduled_at"),
+			"updated_at":   now,
+		})
+	return res.RowsAffected, res.Error
 }
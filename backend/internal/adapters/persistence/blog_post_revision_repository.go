@@ -0,0 +1,72 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type blogPostRevisionRepo struct {
+	db *gorm.DB
+}
+
+func NewBlogPostRevisionRepository(db *gorm.DB) outbound.BlogPostRevisionRepository {
+	return &blogPostRevisionRepo{db: db}
+}
+
+func (r *blogPostRevisionRepo) Create(ctx context.Context, rev *models.BlogPostRevision) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Concurrent edits of one post wait here, so each gets its own number instead of hitting the unique index.
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "blog_post_revisions:"+rev.PostID.String()).Error; err != nil {
+			return err
+		}
+		var latest int
+		if err := tx.Model(&models.BlogPostRevision{}).Where("post_id = ?", rev.PostID).
+			Select("COALESCE(MAX(revision), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		if rev.Action == models.BlogRevisionBaseline && latest > 0 {
+			return nil
+		}
+		rev.Revision = latest + 1
+		return tx.Omit("Editor").Create(rev).Error
+	})
+}
+
+func (r *blogPostRevisionRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.BlogPostRevision, error) {
+	var rev models.BlogPostRevision
+	err := dbFrom(ctx, r.db).Preload("Editor").First(&rev, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+func (r *blogPostRevisionRepo) ListByPostID(ctx context.Context, postID uuid.UUID, limit, offset int) ([]*models.BlogPostRevision, int64, error) {
+	q := dbFrom(ctx, r.db).Model(&models.BlogPostRevision{}).Where("post_id = ?", postID)
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if limit > 0 {
+		q = q.Limit(limit).Offset(offset)
+	}
+	var list []*models.BlogPostRevision
+	err := q.Preload("Editor").Order("revision DESC").Find(&list).Error
+	return list, total, err
+}
+
+func (r *blogPostRevisionRepo) LatestRevision(ctx context.Context, postID uuid.UUID) (int, error) {
+	var latest int
+	err := dbFrom(ctx, r.db).Model(&models.BlogPostRevision{}).
+		Where("post_id = ?", postID).
+		Select("COALESCE(MAX(revision), 0)").Scan(&latest).Error
+	return latest, err
+}
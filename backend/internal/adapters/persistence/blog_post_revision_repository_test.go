@@ -0,0 +1,131 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// revisionServer is a stand-in for Postgres with what blogPostRevisionRepo.Create runs: it answers the
+// MAX(revision) query with latest and records each statement with whether it ran inside a transaction.
+type revisionServer struct {
+	latest     int64
+	statements []string
+	inTx       []bool
+	commits    int
+	tx         bool
+}
+
+func (s *revisionServer) Connect(context.Context) (driver.Conn, error) { return revisionConn{s}, nil }
+func (s *revisionServer) Driver() driver.Driver                        { return nil }
+
+type revisionConn struct{ s *revisionServer }
+
+func (c revisionConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (c revisionConn) Close() error { return nil }
+
+func (c revisionConn) Begin() (driver.Tx, error) {
+	c.s.tx = true
+	return revisionTx{c.s}, nil
+}
+
+func (c revisionConn) record(query string) {
+	c.s.statements = append(c.s.statements, query)
+	c.s.inTx = append(c.s.inTx, c.s.tx)
+}
+
+func (c revisionConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.record(query)
+	return driver.RowsAffected(1), nil
+}
+
+func (c revisionConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.record(query)
+	if strings.Contains(query, "MAX(revision)") {
+		return &lockedRows{cols: []string{"coalesce"}, rows: [][]driver.Value{{c.s.latest}}}, nil
+	}
+	return &lockedRows{}, nil
+}
+
+type revisionTx struct{ s *revisionServer }
+
+func (t revisionTx) Commit() error {
+	t.s.tx = false
+	t.s.commits++
+	return nil
+}
+
+func (t revisionTx) Rollback() error {
+	t.s.tx = false
+	return nil
+}
+
+type lockedRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *lockedRows) Columns() []string { return r.cols }
+func (r *lockedRows) Close() error      { return nil }
+
+func (r *lockedRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestBlogPostRevisionRepo_CreateNumbersUnderPostLock(t *testing.T) {
+	srv := &revisionServer{latest: 3}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(srv)}), &gorm.Config{
+		DisableAutomaticPing: true, Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	repo := NewBlogPostRevisionRepository(db)
+	ctx, postID := context.Background(), uuid.New()
+
+	rev := &models.BlogPostRevision{PostID: postID, Action: models.BlogRevisionUpdate, Title: "Winter flu tips", Body: "..."}
+	if err := repo.Create(ctx, rev); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if rev.Revision != 4 {
+		t.Errorf("revision = %d, want 4 (after the latest 3)", rev.Revision)
+	}
+	want := []string{"pg_advisory_xact_lock", "MAX(revision)", `INSERT INTO "blog_post_revisions"`}
+	if len(srv.statements) != len(want) || srv.commits != 1 {
+		t.Fatalf("statements = %q, commits = %d", srv.statements, srv.commits)
+	}
+	for i, w := range want {
+		if !strings.Contains(srv.statements[i], w) || !srv.inTx[i] {
+			t.Errorf("statement %d = %q (in transaction: %v), want %s inside the transaction", i, srv.statements[i], srv.inTx[i], w)
+		}
+	}
+
+	// Another edit recorded a revision since the service checked, so the baseline is no longer the first.
+	srv.statements = nil
+	baseline := &models.BlogPostRevision{PostID: postID, Action: models.BlogRevisionBaseline, Title: "Winter flu tips", Body: "..."}
+	if err := repo.Create(ctx, baseline); err != nil || baseline.Revision != 0 || len(srv.statements) != 2 {
+		t.Errorf("late baseline: revision = %d, err = %v, statements = %q; want it skipped", baseline.Revision, err, srv.statements)
+	}
+	srv.latest = 0
+	if err := repo.Create(ctx, baseline); err != nil || baseline.Revision != 1 {
+		t.Errorf("first baseline: revision = %d, err = %v; want 1", baseline.Revision, err)
+	}
+}
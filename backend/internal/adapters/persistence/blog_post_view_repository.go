@@ -19,17 +19,17 @@ func NewBlogPostViewRepository(db *gorm.DB) outbound.BlogPostViewRepository {
 }
 
 func (r *blogPostViewRepo) Create(ctx context.Context, v *models.BlogPostView) error {
-	return r.db.WithContext(ctx).Create(v).Error
+	return dbFrom(ctx, r.db).Create(v).Error
 }
 
 func (r *blogPostViewRepo) CountByPostID(ctx context.Context, postID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.BlogPostView{}).Where("post_id = ?", postID).Count(&count).Error
+	err := dbFrom(ctx, r.db).Model(&models.BlogPostView{}).Where("post_id = ?", postID).Count(&count).Error
 	return count, err
 }
 
 func (r *blogPostViewRepo) CountByPostIDSince(ctx context.Context, postID uuid.UUID, since time.Time) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.BlogPostView{}).Where("post_id = ? AND viewed_at >= ?", postID, since).Count(&count).Error
+	err := dbFrom(ctx, r.db).Model(&models.BlogPostView{}).Where("post_id = ? AND viewed_at >= ?", postID, since).Count(&count).Error
 	return count, err
 }
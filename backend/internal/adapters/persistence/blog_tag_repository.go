@@ -0,0 +1,121 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type blogTagRepo struct {
+	db *gorm.DB
+}
+
+func NewBlogTagRepository(db *gorm.DB) outbound.BlogTagRepository {
+	return &blogTagRepo{db: db}
+}
+
+func (r *blogTagRepo) Create(ctx context.Context, t *models.BlogTag) error {
+	return dbFrom(ctx, r.db).Create(t).Error
+}
+
+func (r *blogTagRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.BlogTag, error) {
+	var t models.BlogTag
+	err := dbFrom(ctx, r.db).First(&t, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *blogTagRepo) GetBySlug(ctx context.Context, pharmacyID uuid.UUID, slug string) (*models.BlogTag, error) {
+	var t models.BlogTag
+	err := dbFrom(ctx, r.db).First(&t, "pharmacy_id = ? AND slug = ?", pharmacyID, slug).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *blogTagRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, publishedOnly bool) ([]*models.BlogTag, error) {
+	var list []*models.BlogTag
+	if err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).Order("name").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	var counts []struct {
+		TagID uuid.UUID
+		Count int64
+	}
+	q := dbFrom(ctx, r.db).Table("blog_post_tags AS pt").
+		Select("pt.tag_id, COUNT(*) AS count").
+		Joins("JOIN blog_posts p ON p.id = pt.post_id AND p.deleted_at IS NULL").
+		Where("p.pharmacy_id = ?", pharmacyID)
+	if publishedOnly {
+		q = q.Where("p.status = ?", models.BlogPostStatusPublished)
+	}
+	if err := q.Group("pt.tag_id").Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	byTag := make(map[uuid.UUID]int64, len(counts))
+	for _, c := range counts {
+		byTag[c.TagID] = c.Count
+	}
+	out := list[:0]
+	for _, t := range list {
+		t.PostCount = byTag[t.ID]
+		if publishedOnly && t.PostCount == 0 {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func (r *blogTagRepo) Update(ctx context.Context, t *models.BlogTag) error {
+	return dbFrom(ctx, r.db).Save(t).Error
+}
+
+func (r *blogTagRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", id).Delete(&models.BlogPostTag{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.BlogTag{}, "id = ?", id).Error
+	})
+}
+
+func (r *blogTagRepo) ListByPostID(ctx context.Context, postID uuid.UUID) ([]*models.BlogTag, error) {
+	var list []*models.BlogTag
+	err := dbFrom(ctx, r.db).
+		Joins("JOIN blog_post_tags pt ON pt.tag_id = blog_tags.id").
+		Where("pt.post_id = ?", postID).
+		Order("blog_tags.name").Find(&list).Error
+	return list, err
+}
+
+func (r *blogTagRepo) SetPostTags(ctx context.Context, postID uuid.UUID, tagIDs []uuid.UUID) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ?", postID).Delete(&models.BlogPostTag{}).Error; err != nil {
+			return err
+		}
+		if len(tagIDs) == 0 {
+			return nil
+		}
+		now := time.Now()
+		links := make([]models.BlogPostTag, 0, len(tagIDs))
+		for _, id := range tagIDs {
+			links = append(links, models.BlogPostTag{PostID: postID, TagID: id, CreatedAt: now})
+		}
+		return tx.Create(&links).Error
+	})
+}
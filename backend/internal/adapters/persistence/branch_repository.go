@@ -0,0 +1,138 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type branchRepo struct {
+	db *gorm.DB
+}
+
+func NewBranchRepository(db *gorm.DB) outbound.BranchRepository {
+	return &branchRepo{db: db}
+}
+
+func (r *branchRepo) Create(ctx context.Context, b *models.Branch) error {
+	return dbFrom(ctx, r.db).Create(b).Error
+}
+
+func (r *branchRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Branch, error) {
+	var b models.Branch
+	if err := dbFrom(ctx, r.db).First(&b, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *branchRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Branch, error) {
+	var list []*models.Branch
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).
+		Order("is_default DESC, name ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *branchRepo) GetDefault(ctx context.Context, pharmacyID uuid.UUID) (*models.Branch, error) {
+	var b models.Branch
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ? AND is_default = ?", pharmacyID, true).First(&b).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *branchRepo) Update(ctx context.Context, b *models.Branch) error {
+	return dbFrom(ctx, r.db).Save(b).Error
+}
+
+func (r *branchRepo) SetDefault(ctx context.Context, pharmacyID, branchID uuid.UUID) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Branch{}).Where("pharmacy_id = ? AND id <> ? AND is_default = ?", pharmacyID, branchID, true).
+			Update("is_default", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Branch{}).Where("id = ?", branchID).Update("is_default", true).Error
+	})
+}
+
+func (r *branchRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("branch_id = ?", id).Update("branch_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Branch{}, "id = ?", id).Error
+	})
+}
+
+func (r *branchRepo) AdoptUnassignedBatches(ctx context.Context, pharmacyID, branchID uuid.UUID) (int64, error) {
+	res := dbFrom(ctx, r.db).Model(&models.InventoryBatch{}).
+		Where("pharmacy_id = ? AND branch_id IS NULL", pharmacyID).
+		Update("branch_id", branchID)
+	return res.RowsAffected, res.Error
+}
+
+func (r *branchRepo) Stock(ctx context.Context, branchID uuid.UUID, today time.Time) ([]*models.BranchStockRow, error) {
+	var rows []*models.BranchStockRow
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT b.product_id, p.name, p.sku,
+			COALESCE(SUM(b.quantity) FILTER (WHERE b.expiry_date IS NULL OR b.expiry_date >= ?), 0) AS quantity,
+			COALESCE(SUM(b.quantity) FILTER (WHERE b.expiry_date < ?), 0) AS expired
+		FROM inventory_batches b
+		JOIN products p ON p.id = b.product_id
+		WHERE b.branch_id = ? AND b.quantity > 0 AND p.deleted_at IS NULL
+		GROUP BY b.product_id, p.name, p.sku
+		ORDER BY p.name ASC`, today, today, branchID).
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *branchRepo) CreateTransfer(ctx context.Context, t *models.BranchTransfer, updated, created []*models.InventoryBatch) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, b := range updated {
+			if err := tx.Omit(clause.Associations).Save(b).Error; err != nil {
+				return err
+			}
+		}
+		for _, b := range created {
+			if err := tx.Omit(clause.Associations).Create(b).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Omit("FromBranch", "ToBranch", "Items.Product").Create(t).Error
+	})
+}
+
+func (r *branchRepo) GetTransfer(ctx context.Context, id uuid.UUID) (*models.BranchTransfer, error) {
+	var t models.BranchTransfer
+	err := dbFrom(ctx, r.db).Preload("FromBranch").Preload("ToBranch").Preload("Items.Product").
+		First(&t, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *branchRepo) ListTransfers(ctx context.Context, pharmacyID uuid.UUID, branchID *uuid.UUID, limit, offset int) ([]*models.BranchTransfer, int64, error) {
+	q := dbFrom(ctx, r.db).Model(&models.BranchTransfer{}).Where("pharmacy_id = ?", pharmacyID)
+	if branchID != nil {
+		q = q.Where("(from_branch_id = ? OR to_branch_id = ?)", *branchID, *branchID)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.BranchTransfer
+	err := q.Preload("FromBranch").Preload("ToBranch").Preload("Items").
+		Order("created_at DESC").Limit(limit).Offset(offset).Find(&list).Error
+	return list, total, err
+}
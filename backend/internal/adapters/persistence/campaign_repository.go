@@ -0,0 +1,238 @@
+package persistence
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type customerSegmentRepo struct {
+	db *gorm.DB
+}
+
+func NewCustomerSegmentRepository(db *gorm.DB) outbound.CustomerSegmentRepository {
+	return &customerSegmentRepo{db: db}
+}
+
+func (r *customerSegmentRepo) Create(ctx context.Context, s *models.CustomerSegment) error {
+	return dbFrom(ctx, r.db).Create(s).Error
+}
+
+func (r *customerSegmentRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.CustomerSegment, error) {
+	var s models.CustomerSegment
+	if err := dbFrom(ctx, r.db).First(&s, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *customerSegmentRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.CustomerSegment, error) {
+	var list []*models.CustomerSegment
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).Order("name ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *customerSegmentRepo) Update(ctx context.Context, s *models.CustomerSegment) error {
+	return dbFrom(ctx, r.db).Save(s).Error
+}
+
+func (r *customerSegmentRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFrom(ctx, r.db).Delete(&models.CustomerSegment{}, "id = ?", id).Error
+}
+
+func (r *customerSegmentRepo) Members(ctx context.Context, pharmacyID uuid.UUID, c models.SegmentCriteria, limit int) ([]*models.SegmentMember, int64, error) {
+	// Purchase figures come from completed orders linked to the customer; the app account is the user with
+	// the same pharmacy and phone.
+	base := `
+		FROM customers c
+		LEFT JOIN customer_memberships cm ON cm.customer_id = c.id AND cm.deleted_at IS NULL
+		LEFT JOIN (
+			SELECT customer_id, MAX(created_at) AS last_purchase_at,
+				SUM(total_amount + gift_card_amount + store_credit_amount) AS total_spend
+			FROM orders
+			WHERE pharmacy_id = ? AND status = ? AND deleted_at IS NULL AND customer_id IS NOT NULL
+			GROUP BY customer_id
+		) p ON p.customer_id = c.id
+		WHERE c.pharmacy_id = ? AND c.deleted_at IS NULL`
+	args := []interface{}{pharmacyID, models.OrderStatusCompleted, pharmacyID}
+	var where []string
+	if c.MinPoints != nil {
+		where = append(where, "c.points_balance >= ?")
+		args = append(args, *c.MinPoints)
+	}
+	if c.MaxPoints != nil {
+		where = append(where, "c.points_balance <= ?")
+		args = append(args, *c.MaxPoints)
+	}
+	switch {
+	case len(c.MembershipIDs) > 0 && c.NoMembership:
+		where = append(where, "(cm.membership_id IN ? OR cm.membership_id IS NULL)")
+		args = append(args, c.MembershipIDs)
+	case len(c.MembershipIDs) > 0:
+		where = append(where, "cm.membership_id IN ?")
+		args = append(args, c.MembershipIDs)
+	case c.NoMembership:
+		where = append(where, "cm.membership_id IS NULL")
+	}
+	if c.LastPurchaseAfter != nil {
+		where = append(where, "p.last_purchase_at >= ?")
+		args = append(args, *c.LastPurchaseAfter)
+	}
+	if c.LastPurchaseBefore != nil {
+		where = append(where, "p.last_purchase_at < ?")
+		args = append(args, *c.LastPurchaseBefore)
+	}
+	if c.MinTotalSpend != nil {
+		where = append(where, "COALESCE(p.total_spend, 0) >= ?")
+		args = append(args, *c.MinTotalSpend)
+	}
+	if c.MaxTotalSpend != nil {
+		where = append(where, "COALESCE(p.total_spend, 0) <= ?")
+		args = append(args, *c.MaxTotalSpend)
+	}
+	if len(where) > 0 {
+		base += " AND " + strings.Join(where, " AND ")
+	}
+
+	var total int64
+	if err := dbFrom(ctx, r.db).Raw("SELECT COUNT(*) "+base, args...).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	q := `SELECT c.id AS customer_id, c.name, c.phone, c.email, c.preferred_language, c.points_balance,
+			cm.membership_id, p.last_purchase_at, COALESCE(p.total_spend, 0) AS total_spend,
+			(SELECT u.id FROM users u WHERE u.pharmacy_id = c.pharmacy_id AND u.phone = c.phone AND u.phone <> ''
+				AND u.is_active AND u.deleted_at IS NULL ORDER BY u.created_at LIMIT 1) AS user_id ` + base + `
+		ORDER BY c.created_at ASC, c.id ASC`
+	if limit > 0 {
+		q += " LIMIT ?"
+		args = append(args, limit)
+	}
+	var members []*models.SegmentMember
+	err := dbFrom(ctx, r.db).Raw(q, args...).Scan(&members).Error
+	return members, total, err
+}
+
+type campaignRepo struct {
+	db *gorm.DB
+}
+
+func NewCampaignRepository(db *gorm.DB) outbound.CampaignRepository {
+	return &campaignRepo{db: db}
+}
+
+func (r *campaignRepo) Create(ctx context.Context, c *models.Campaign) error {
+	return dbFrom(ctx, r.db).Create(c).Error
+}
+
+func (r *campaignRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Campaign, error) {
+	var c models.Campaign
+	if err := dbFrom(ctx, r.db).Preload("Segment").First(&c, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *campaignRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.Campaign, int64, error) {
+	scope := func() *gorm.DB {
+		return dbFrom(ctx, r.db).Model(&models.Campaign{}).Where("pharmacy_id = ?", pharmacyID)
+	}
+	var total int64
+	if err := scope().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.Campaign
+	q := scope().Order("created_at DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	err := q.Find(&list).Error
+	return list, total, err
+}
+
+func (r *campaignRepo) Update(ctx context.Context, c *models.Campaign) error {
+	return dbFrom(ctx, r.db).Omit("Segment").Save(c).Error
+}
+
+func (r *campaignRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFrom(ctx, r.db).Delete(&models.Campaign{}, "id = ?", id).Error
+}
+
+func (r *campaignRepo) StartSending(ctx context.Context, c *models.Campaign) (bool, error) {
+	res := dbFrom(ctx, r.db).Model(&models.Campaign{}).
+		Where("id = ? AND status = ?", c.ID, models.CampaignStatusDraft).
+		Updates(map[string]interface{}{
+			"status":          models.CampaignStatusSending,
+			"criteria":        c.Criteria,
+			"recipient_count": c.RecipientCount,
+			"sent_at":         c.SentAt,
+		})
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *campaignRepo) RecordRecipients(ctx context.Context, campaignID uuid.UUID, recipients []*models.CampaignRecipient) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+	var sent, failed, skipped int
+	for _, rc := range recipients {
+		rc.CampaignID = campaignID
+		switch rc.Status {
+		case models.CampaignRecipientSent:
+			sent++
+		case models.CampaignRecipientFailed:
+			failed++
+		default:
+			skipped++
+		}
+	}
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(recipients, 500).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`UPDATE campaigns SET sent_count = sent_count + ?, failed_count = failed_count + ?,
+			skipped_count = skipped_count + ?, updated_at = NOW() WHERE id = ?`, sent, failed, skipped, campaignID).Error
+	})
+}
+
+func (r *campaignRepo) FinishSending(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return dbFrom(ctx, r.db).Model(&models.Campaign{}).Where("id = ? AND status = ?", id, models.CampaignStatusSending).
+		Updates(map[string]interface{}{"status": models.CampaignStatusSent, "completed_at": at}).Error
+}
+
+func (r *campaignRepo) ListRecipients(ctx context.Context, campaignID uuid.UUID, status string, limit, offset int) ([]*models.CampaignRecipient, int64, error) {
+	scope := func() *gorm.DB {
+		q := dbFrom(ctx, r.db).Model(&models.CampaignRecipient{}).Where("campaign_id = ?", campaignID)
+		if status != "" {
+			q = q.Where("status = ?", status)
+		}
+		return q
+	}
+	var total int64
+	if err := scope().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.CampaignRecipient
+	q := scope().Order("created_at ASC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	err := q.Find(&list).Error
+	return list, total, err
+}
@@ -0,0 +1,77 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type cartRepo struct {
+	db *gorm.DB
+}
+
+func NewCartRepository(db *gorm.DB) outbound.CartRepository {
+	return &cartRepo{db: db}
+}
+
+func (r *cartRepo) Create(ctx context.Context, c *models.Cart) error {
+	return dbFrom(ctx, r.db).Create(c).Error
+}
+
+func (r *cartRepo) GetOpen(ctx context.Context, pharmacyID, userID uuid.UUID) (*models.Cart, error) {
+	var c models.Cart
+	err := dbFrom(ctx, r.db).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Items.Product").
+		Preload("Items.Product.CategoryDetail").
+		Where("pharmacy_id = ? AND user_id = ? AND status = ?", pharmacyID, userID, models.CartStatusOpen).
+		Order("created_at DESC").
+		First(&c).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *cartRepo) UpdateCodes(ctx context.Context, c *models.Cart) error {
+	return dbFrom(ctx, r.db).Model(&models.Cart{}).Where("id = ?", c.ID).Updates(map[string]interface{}{
+		"promo_code":       c.PromoCode,
+		"referral_code":    c.ReferralCode,
+		"points_to_redeem": c.PointsToRedeem,
+		"updated_at":       time.Now(),
+	}).Error
+}
+
+func (r *cartRepo) SetItemQuantity(ctx context.Context, cartID, productID uuid.UUID, quantity int) error {
+	item := &models.CartItem{CartID: cartID, ProductID: productID, Quantity: quantity}
+	return dbFrom(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cart_id"}, {Name: "product_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"quantity": quantity, "updated_at": time.Now()}),
+	}).Create(item).Error
+}
+
+func (r *cartRepo) DeleteItem(ctx context.Context, cartID, productID uuid.UUID) error {
+	return dbFrom(ctx, r.db).Where("cart_id = ? AND product_id = ?", cartID, productID).Delete(&models.CartItem{}).Error
+}
+
+func (r *cartRepo) DeleteItems(ctx context.Context, cartID uuid.UUID) error {
+	return dbFrom(ctx, r.db).Where("cart_id = ?", cartID).Delete(&models.CartItem{}).Error
+}
+
+func (r *cartRepo) TransitionStatus(ctx context.Context, cartID uuid.UUID, from, to string, orderID *uuid.UUID) (bool, error) {
+	updates := map[string]interface{}{"status": to, "updated_at": time.Now()}
+	if orderID != nil {
+		updates["order_id"] = *orderID
+	}
+	res := dbFrom(ctx, r.db).Model(&models.Cart{}).Where("id = ? AND status = ?", cartID, from).Updates(updates)
+	return res.RowsAffected == 1, res.Error
+}
@@ -18,12 +18,12 @@ func NewCategoryRepository(db *gorm.DB) outbound.CategoryRepository {
 }
 
 func (r *categoryRepo) Create(ctx context.Context, c *models.Category) error {
-	return r.db.WithContext(ctx).Create(c).Error
+	return dbFrom(ctx, r.db).Create(c).Error
 }
 
 func (r *categoryRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Category, error) {
 	var c models.Category
-	err := r.db.WithContext(ctx).First(&c, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).First(&c, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,13 +32,13 @@ func (r *categoryRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Categ
 
 func (r *categoryRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Category, error) {
 	var list []*models.Category
-	err := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID).Order("sort_order ASC, name ASC").Find(&list).Error
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).Order("sort_order ASC, name ASC").Find(&list).Error
 	return list, err
 }
 
 func (r *categoryRepo) ListByParentID(ctx context.Context, pharmacyID uuid.UUID, parentID *uuid.UUID) ([]*models.Category, error) {
 	var list []*models.Category
-	q := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID)
+	q := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID)
 	if parentID == nil {
 		q = q.Where("parent_id IS NULL")
 	} else {
@@ -49,9 +49,9 @@ func (r *categoryRepo) ListByParentID(ctx context.Context, pharmacyID uuid.UUID,
 }
 
 func (r *categoryRepo) Update(ctx context.Context, c *models.Category) error {
-	return r.db.WithContext(ctx).Save(c).Error
+	return dbFrom(ctx, r.db).Save(c).Error
 }
 
 func (r *categoryRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.Category{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.Category{}, "id = ?", id).Error
 }
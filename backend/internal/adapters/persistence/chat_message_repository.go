@@ -18,12 +18,12 @@ func NewChatMessageRepository(db *gorm.DB) outbound.ChatMessageRepository {
 }
 
 func (r *chatMessageRepo) Create(ctx context.Context, m *models.ChatMessage) error {
-	return r.db.WithContext(ctx).Create(m).Error
+	return dbFrom(ctx, r.db).Create(m).Error
 }
 
 func (r *chatMessageRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.ChatMessage, error) {
 	var m models.ChatMessage
-	err := r.db.WithContext(ctx).First(&m, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).First(&m, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -31,20 +31,20 @@ func (r *chatMessageRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Ch
 }
 
 func (r *chatMessageRepo) Update(ctx context.Context, m *models.ChatMessage) error {
-	return r.db.WithContext(ctx).Save(m).Error
+	return dbFrom(ctx, r.db).Save(m).Error
 }
 
 func (r *chatMessageRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.ChatMessage{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.ChatMessage{}, "id = ?", id).Error
 }
 
 func (r *chatMessageRepo) DeleteByConversationID(ctx context.Context, conversationID uuid.UUID) error {
-	return r.db.WithContext(ctx).Where("conversation_id = ?", conversationID).Delete(&models.ChatMessage{}).Error
+	return dbFrom(ctx, r.db).Where("conversation_id = ?", conversationID).Delete(&models.ChatMessage{}).Error
 }
 
 func (r *chatMessageRepo) ListByConversationID(ctx context.Context, conversationID uuid.UUID, limit, offset int) ([]*models.ChatMessage, int64, error) {
 	var total int64
-	if err := r.db.WithContext(ctx).Model(&models.ChatMessage{}).Where("conversation_id = ?", conversationID).Count(&total).Error; err != nil {
+	if err := dbFrom(ctx, r.db).Model(&models.ChatMessage{}).Where("conversation_id = ?", conversationID).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 	if limit <= 0 {
@@ -54,7 +54,7 @@ func (r *chatMessageRepo) ListByConversationID(ctx context.Context, conversation
 		limit = 100
 	}
 	var list []*models.ChatMessage
-	err := r.db.WithContext(ctx).Where("conversation_id = ?", conversationID).
+	err := dbFrom(ctx, r.db).Where("conversation_id = ?", conversationID).
 		Order("created_at DESC").
 		Limit(limit).
 		Offset(offset).
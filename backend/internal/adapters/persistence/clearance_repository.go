@@ -0,0 +1,104 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type clearanceRepo struct {
+	db *gorm.DB
+}
+
+func NewClearanceRepository(db *gorm.DB) outbound.ClearanceRepository {
+	return &clearanceRepo{db: db}
+}
+
+func (r *clearanceRepo) Create(ctx context.Context, a *models.ClearanceAction) error {
+	return dbFrom(ctx, r.db).Omit("PromoCode", "Supplier", "Photos").Create(a).Error
+}
+
+func (r *clearanceRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.ClearanceAction, error) {
+	var a models.ClearanceAction
+	err := dbFrom(ctx, r.db).Preload("Items").Preload("PromoCode").Preload("Supplier").
+		Preload("Photos", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).First(&a, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *clearanceRepo) List(ctx context.Context, pharmacyID uuid.UUID, f outbound.ClearanceFilter, limit, offset int) ([]*models.ClearanceAction, int64, error) {
+	q := dbFrom(ctx, r.db).Model(&models.ClearanceAction{}).Where("pharmacy_id = ?", pharmacyID)
+	if f.Kind != "" {
+		q = q.Where("kind = ?", f.Kind)
+	}
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.ClearanceAction
+	err := q.Preload("Items").Preload("PromoCode").Preload("Supplier").
+		Order("created_at DESC").Limit(limit).Offset(offset).Find(&list).Error
+	return list, total, err
+}
+
+func (r *clearanceRepo) OpenProductIDs(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := dbFrom(ctx, r.db).Model(&models.ClearanceItem{}).
+		Joins("JOIN clearance_actions a ON a.id = clearance_items.action_id").
+		Where("a.pharmacy_id = ? AND a.status = ? AND clearance_items.product_id IN ?", pharmacyID, models.ClearanceOpen, productIDs).
+		Distinct().Pluck("clearance_items.product_id", &ids).Error
+	return ids, err
+}
+
+func (r *clearanceRepo) RecordReturn(ctx context.Context, itemID uuid.UUID, quantity int, credit float64) (bool, error) {
+	res := dbFrom(ctx, r.db).Exec(
+		`UPDATE clearance_items SET returned_quantity = returned_quantity + ?, credit_amount = credit_amount + ?
+		WHERE id = ? AND returned_quantity + ? <= quantity`,
+		quantity, credit, itemID, quantity)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *clearanceRepo) Close(ctx context.Context, a *models.ClearanceAction) (bool, error) {
+	res := dbFrom(ctx, r.db).Model(&models.ClearanceAction{}).Where("id = ? AND status = ?", a.ID, models.ClearanceOpen).
+		Updates(map[string]interface{}{
+			"status":     a.Status,
+			"closed_by":  a.ClosedBy,
+			"closed_at":  a.ClosedAt,
+			"updated_at": gorm.Expr("NOW()"),
+		})
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *clearanceRepo) PromoRecovery(ctx context.Context, actionIDs []uuid.UUID) ([]*models.ClearanceRecoveryRow, error) {
+	var rows []*models.ClearanceRecoveryRow
+	if len(actionIDs) == 0 {
+		return rows, nil
+	}
+	// Revenue is each line after its pro-rata share of the order discount, as in the tax report.
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT a.id AS action_id, COALESCE(SUM(oi.quantity), 0) AS units_sold,
+			COALESCE(SUM(
+				oi.total_price * CASE WHEN o.sub_total > 0 THEN 1 - LEAST(o.discount_amount / o.sub_total, 1) ELSE 1 END
+			), 0) AS revenue
+		FROM clearance_actions a
+		JOIN clearance_items ci ON ci.action_id = a.id
+		JOIN orders o ON o.promo_code_id = a.promo_code_id AND o.created_at >= a.created_at
+		JOIN order_items oi ON oi.order_id = o.id AND oi.product_id = ci.product_id
+		WHERE a.id IN ? AND a.kind = ? AND o.deleted_at IS NULL AND o.status <> ?
+		GROUP BY a.id`,
+		actionIDs, models.ClearanceKindPromo, models.OrderStatusCancelled,
+	).Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,65 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type consentRepo struct {
+	db *gorm.DB
+}
+
+func NewConsentRepository(db *gorm.DB) outbound.ConsentRepository {
+	return &consentRepo{db: db}
+}
+
+func (r *consentRepo) Create(ctx context.Context, rec *models.ConsentRecord) error {
+	return dbFrom(ctx, r.db).Omit("Customer").Create(rec).Error
+}
+
+func (r *consentRepo) Current(ctx context.Context, customerID uuid.UUID) ([]*models.ConsentRecord, error) {
+	var list []*models.ConsentRecord
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT DISTINCT ON (channel) *
+		FROM consent_records
+		WHERE customer_id = ?
+		ORDER BY channel, created_at DESC`, customerID).
+		Scan(&list).Error
+	return list, err
+}
+
+func (r *consentRepo) History(ctx context.Context, customerID uuid.UUID, limit int) ([]*models.ConsentRecord, error) {
+	var list []*models.ConsentRecord
+	err := dbFrom(ctx, r.db).Where("customer_id = ?", customerID).Order("created_at DESC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (r *consentRepo) OptedIn(ctx context.Context, pharmacyID uuid.UUID, channel models.ConsentChannel, customerIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if len(customerIDs) == 0 {
+		return nil, nil
+	}
+	var ids []uuid.UUID
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT customer_id FROM (
+			SELECT DISTINCT ON (customer_id) customer_id, granted
+			FROM consent_records
+			WHERE pharmacy_id = ? AND channel = ? AND customer_id IN ?
+			ORDER BY customer_id, created_at DESC
+		) latest
+		WHERE granted`, pharmacyID, channel, customerIDs).
+		Scan(&ids).Error
+	return ids, err
+}
+
+func (r *consentRepo) List(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.ConsentRecord, error) {
+	var list []*models.ConsentRecord
+	err := dbFrom(ctx, r.db).Preload("Customer").
+		Where("pharmacy_id = ? AND created_at >= ? AND created_at < ?", pharmacyID, from, to).
+		Order("created_at ASC").Find(&list).Error
+	return list, err
+}
@@ -0,0 +1,81 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type contentReportRepo struct {
+	db *gorm.DB
+}
+
+func NewContentReportRepository(db *gorm.DB) outbound.ContentReportRepository {
+	return &contentReportRepo{db: db}
+}
+
+func (r *contentReportRepo) Create(ctx context.Context, rep *models.ContentReport) error {
+	return dbFrom(ctx, r.db).Omit("Reporter", "Author").Create(rep).Error
+}
+
+func (r *contentReportRepo) ExistsByReporter(ctx context.Context, reporterID uuid.UUID, targetType string, targetID uuid.UUID) (bool, error) {
+	var count int64
+	err := dbFrom(ctx, r.db).Model(&models.ContentReport{}).
+		Where("reporter_id = ? AND target_type = ? AND target_id = ?", reporterID, targetType, targetID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *contentReportRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.ContentReport, error) {
+	var rep models.ContentReport
+	err := dbFrom(ctx, r.db).Preload("Reporter").Preload("Author").First(&rep, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rep, nil
+}
+
+func (r *contentReportRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, filter outbound.ContentReportFilter, limit, offset int) ([]*models.ContentReport, int64, error) {
+	q := dbFrom(ctx, r.db).Model(&models.ContentReport{}).Where("pharmacy_id = ?", pharmacyID)
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.TargetType != "" {
+		q = q.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.AuthorID != nil {
+		q = q.Where("author_id = ?", *filter.AuthorID)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.ContentReport
+	err := q.Preload("Reporter").Preload("Author").
+		Order("created_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&list).Error
+	return list, total, err
+}
+
+func (r *contentReportRepo) ResolveTarget(ctx context.Context, pharmacyID uuid.UUID, targetType string, targetID uuid.UUID, action, note string, resolvedBy uuid.UUID, at time.Time) (int64, error) {
+	res := dbFrom(ctx, r.db).Model(&models.ContentReport{}).
+		Where("pharmacy_id = ? AND target_type = ? AND target_id = ? AND status = ?", pharmacyID, targetType, targetID, models.ContentReportOpen).
+		Updates(map[string]interface{}{
+			"status":          models.ContentReportResolved,
+			"action":          action,
+			"resolution_note": note,
+			"resolved_by":     resolvedBy,
+			"resolved_at":     at,
+			"updated_at":      at,
+		})
+	return res.RowsAffected, res.Error
+}
@@ -18,12 +18,12 @@ func NewConversationRepository(db *gorm.DB) outbound.ConversationRepository {
 }
 
 func (r *conversationRepo) Create(ctx context.Context, c *models.Conversation) error {
-	return r.db.WithContext(ctx).Create(c).Error
+	return dbFrom(ctx, r.db).Create(c).Error
 }
 
 func (r *conversationRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Conversation, error) {
 	var c models.Conversation
-	err := r.db.WithContext(ctx).Preload("Pharmacy").Preload("Customer").First(&c, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).Preload("Pharmacy").Preload("Customer").First(&c, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +32,7 @@ func (r *conversationRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.C
 
 func (r *conversationRepo) GetByPharmacyAndCustomer(ctx context.Context, pharmacyID, customerID uuid.UUID) (*models.Conversation, error) {
 	var c models.Conversation
-	err := r.db.WithContext(ctx).Where("pharmacy_id = ? AND customer_id = ?", pharmacyID, customerID).
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ? AND customer_id = ?", pharmacyID, customerID).
 		Preload("Customer").First(&c).Error
 	if err != nil {
 		return nil, err
@@ -42,7 +42,17 @@ func (r *conversationRepo) GetByPharmacyAndCustomer(ctx context.Context, pharmac
 
 func (r *conversationRepo) GetByPharmacyAndUser(ctx context.Context, pharmacyID, userID uuid.UUID) (*models.Conversation, error) {
 	var c models.Conversation
-	err := r.db.WithContext(ctx).Where("pharmacy_id = ? AND user_id = ?", pharmacyID, userID).
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ? AND user_id = ?", pharmacyID, userID).
+		First(&c).Error
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *conversationRepo) GetByTicket(ctx context.Context, provider, ticketID string) (*models.Conversation, error) {
+	var c models.Conversation
+	err := dbFrom(ctx, r.db).Where("ticket_provider = ? AND ticket_id = ?", provider, ticketID).
 		First(&c).Error
 	if err != nil {
 		return nil, err
@@ -51,7 +61,7 @@ func (r *conversationRepo) GetByPharmacyAndUser(ctx context.Context, pharmacyID,
 }
 
 func (r *conversationRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, userID *uuid.UUID, limit, offset int) ([]*models.Conversation, int64, error) {
-	base := r.db.WithContext(ctx).Model(&models.Conversation{}).Where("pharmacy_id = ?", pharmacyID)
+	base := dbFrom(ctx, r.db).Model(&models.Conversation{}).Where("pharmacy_id = ?", pharmacyID)
 	if userID != nil {
 		base = base.Where("user_id = ?", *userID)
 	}
@@ -65,7 +75,7 @@ func (r *conversationRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.U
 	if limit > 100 {
 		limit = 100
 	}
-	q := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID)
+	q := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID)
 	if userID != nil {
 		q = q.Where("user_id = ?", *userID)
 	}
@@ -79,9 +89,9 @@ func (r *conversationRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.U
 }
 
 func (r *conversationRepo) Update(ctx context.Context, c *models.Conversation) error {
-	return r.db.WithContext(ctx).Save(c).Error
+	return dbFrom(ctx, r.db).Save(c).Error
 }
 
 func (r *conversationRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.Conversation{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.Conversation{}, "id = ?", id).Error
 }
@@ -0,0 +1,95 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type creditNoteRepo struct {
+	db *gorm.DB
+}
+
+func NewCreditNoteRepository(db *gorm.DB) outbound.CreditNoteRepository {
+	return &creditNoteRepo{db: db}
+}
+
+func (r *creditNoteRepo) Create(ctx context.Context, n *models.CreditNote, maxTotal float64) (bool, error) {
+	created := false
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// One writer per pharmacy at a time keeps the sequence gap-free and the per-order cap exact.
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "credit_notes:"+n.PharmacyID.String()).Error; err != nil {
+			return err
+		}
+		var credited float64
+		if err := tx.Model(&models.CreditNote{}).Where("order_id = ?", n.OrderID).
+			Select("COALESCE(SUM(amount), 0)").Scan(&credited).Error; err != nil {
+			return err
+		}
+		if credited+n.Amount > maxTotal+0.005 {
+			return nil
+		}
+		var last int
+		if err := tx.Model(&models.CreditNote{}).Where("pharmacy_id = ?", n.PharmacyID).
+			Select("COALESCE(MAX(sequence), 0)").Scan(&last).Error; err != nil {
+			return err
+		}
+		n.Sequence = last + 1
+		n.CreditNoteNumber = fmt.Sprintf("CN-%06d", n.Sequence)
+		if err := tx.Create(n).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+func (r *creditNoteRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.CreditNote, error) {
+	var n models.CreditNote
+	if err := dbFrom(ctx, r.db).Preload("Invoice").First(&n, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &n, nil
+}
+
+func (r *creditNoteRepo) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]*models.CreditNote, error) {
+	var list []*models.CreditNote
+	err := dbFrom(ctx, r.db).Where("order_id = ?", orderID).Order("sequence ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *creditNoteRepo) SumByOrder(ctx context.Context, orderID uuid.UUID) (float64, error) {
+	var sum float64
+	err := dbFrom(ctx, r.db).Model(&models.CreditNote{}).Where("order_id = ?", orderID).
+		Select("COALESCE(SUM(amount), 0)").Scan(&sum).Error
+	return sum, err
+}
+
+func (r *creditNoteRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.CreditNote, int64, error) {
+	scope := func() *gorm.DB {
+		return dbFrom(ctx, r.db).Model(&models.CreditNote{}).Where("pharmacy_id = ?", pharmacyID)
+	}
+	var total int64
+	if err := scope().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.CreditNote
+	q := scope().Preload("Invoice").Order("sequence DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	err := q.Find(&list).Error
+	return list, total, err
+}
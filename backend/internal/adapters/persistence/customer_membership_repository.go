@@ -18,12 +18,12 @@ func NewCustomerMembershipRepository(db *gorm.DB) outbound.CustomerMembershipRep
 }
 
 func (r *customerMembershipRepo) Create(ctx context.Context, cm *models.CustomerMembership) error {
-	return r.db.WithContext(ctx).Create(cm).Error
+	return dbFrom(ctx, r.db).Create(cm).Error
 }
 
 func (r *customerMembershipRepo) GetByCustomerID(ctx context.Context, customerID uuid.UUID) (*models.CustomerMembership, error) {
 	var cm models.CustomerMembership
-	err := r.db.WithContext(ctx).Where("customer_id = ?", customerID).Preload("Membership").First(&cm).Error
+	err := dbFrom(ctx, r.db).Where("customer_id = ?", customerID).Preload("Membership").First(&cm).Error
 	if err != nil {
 		return nil, err
 	}
@@ -31,9 +31,9 @@ func (r *customerMembershipRepo) GetByCustomerID(ctx context.Context, customerID
 }
 
 func (r *customerMembershipRepo) Update(ctx context.Context, cm *models.CustomerMembership) error {
-	return r.db.WithContext(ctx).Save(cm).Error
+	return dbFrom(ctx, r.db).Save(cm).Error
 }
 
 func (r *customerMembershipRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.CustomerMembership{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.CustomerMembership{}, "id = ?", id).Error
 }
@@ -18,12 +18,12 @@ func NewCustomerRepository(db *gorm.DB) outbound.CustomerRepository {
 }
 
 func (r *customerRepo) Create(ctx context.Context, c *models.Customer) error {
-	return r.db.WithContext(ctx).Create(c).Error
+	return dbFrom(ctx, r.db).Create(c).Error
 }
 
 func (r *customerRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Customer, error) {
 	var c models.Customer
-	err := r.db.WithContext(ctx).First(&c, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).First(&c, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +32,7 @@ func (r *customerRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Custo
 
 func (r *customerRepo) GetByPharmacyAndPhone(ctx context.Context, pharmacyID uuid.UUID, phone string) (*models.Customer, error) {
 	var c models.Customer
-	err := r.db.WithContext(ctx).Where("pharmacy_id = ? AND phone = ?", pharmacyID, phone).First(&c).Error
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ? AND phone = ?", pharmacyID, phone).First(&c).Error
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +41,7 @@ func (r *customerRepo) GetByPharmacyAndPhone(ctx context.Context, pharmacyID uui
 
 func (r *customerRepo) GetByPharmacyAndReferralCode(ctx context.Context, pharmacyID uuid.UUID, code string) (*models.Customer, error) {
 	var c models.Customer
-	err := r.db.WithContext(ctx).Where("pharmacy_id = ? AND referral_code = ?", pharmacyID, code).First(&c).Error
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ? AND referral_code = ?", pharmacyID, code).First(&c).Error
 	if err != nil {
 		return nil, err
 	}
@@ -50,11 +50,11 @@ func (r *customerRepo) GetByPharmacyAndReferralCode(ctx context.Context, pharmac
 
 func (r *customerRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.Customer, int64, error) {
 	var total int64
-	if err := r.db.WithContext(ctx).Model(&models.Customer{}).Where("pharmacy_id = ?", pharmacyID).Count(&total).Error; err != nil {
+	if err := dbFrom(ctx, r.db).Model(&models.Customer{}).Where("pharmacy_id = ?", pharmacyID).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 	var list []*models.Customer
-	q := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID).Order("created_at DESC")
+	q := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).Order("created_at DESC")
 	if limit > 0 {
 		q = q.Limit(limit)
 	}
@@ -66,5 +66,20 @@ func (r *customerRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID,
 }
 
 func (r *customerRepo) Update(ctx context.Context, c *models.Customer) error {
-	return r.db.WithContext(ctx).Save(c).Error
+	return dbFrom(ctx, r.db).Save(c).Error
+}
+
+func (r *customerRepo) AdjustPoints(ctx context.Context, customerID uuid.UUID, delta int) (bool, error) {
+	res := execScoped(ctx, dbFrom(ctx, r.db), "UPDATE customers SET points_balance = points_balance + ?, updated_at = NOW() WHERE id = ? AND points_balance + ? >= 0",
+		delta, customerID, delta)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *customerRepo) ClaimBirthdayGift(ctx context.Context, customerID uuid.UUID, year int) (bool, error) {
+	res := execScoped(ctx, dbFrom(ctx, r.db), "UPDATE customers SET birthday_gift_year = ? WHERE id = ? AND birthday_gift_year <> ?", year, customerID, year)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *customerRepo) ReleaseBirthdayGift(ctx context.Context, customerID uuid.UUID, year int) error {
+	return execScoped(ctx, dbFrom(ctx, r.db), "UPDATE customers SET birthday_gift_year = ? WHERE id = ? AND birthday_gift_year = ?", year-1, customerID, year).Error
 }
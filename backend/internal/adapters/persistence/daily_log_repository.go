@@ -19,12 +19,12 @@ func NewDailyLogRepository(db *gorm.DB) outbound.DailyLogRepository {
 }
 
 func (r *dailyLogRepo) Create(ctx context.Context, d *models.DailyLog) error {
-	return r.db.WithContext(ctx).Create(d).Error
+	return dbFrom(ctx, r.db).Create(d).Error
 }
 
 func (r *dailyLogRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.DailyLog, error) {
 	var d models.DailyLog
-	err := r.db.WithContext(ctx).Preload("Creator").First(&d, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).Preload("Creator").First(&d, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +35,7 @@ func (r *dailyLogRepo) ListByPharmacyAndDate(ctx context.Context, pharmacyID uui
 	var list []*models.DailyLog
 	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 	end := start.Add(24 * time.Hour)
-	err := r.db.WithContext(ctx).Preload("Creator").
+	err := dbFrom(ctx, r.db).Preload("Creator").
 		Where("pharmacy_id = ? AND date >= ? AND date < ?", pharmacyID, start, end).
 		Order("created_at ASC").
 		Find(&list).Error
@@ -44,7 +44,7 @@ func (r *dailyLogRepo) ListByPharmacyAndDate(ctx context.Context, pharmacyID uui
 
 func (r *dailyLogRepo) ListByPharmacyAndDateRange(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.DailyLog, error) {
 	var list []*models.DailyLog
-	err := r.db.WithContext(ctx).Preload("Creator").
+	err := dbFrom(ctx, r.db).Preload("Creator").
 		Where("pharmacy_id = ? AND date >= ? AND date <= ?", pharmacyID, from, to).
 		Order("date ASC, created_at ASC").
 		Find(&list).Error
@@ -52,9 +52,9 @@ func (r *dailyLogRepo) ListByPharmacyAndDateRange(ctx context.Context, pharmacyI
 }
 
 func (r *dailyLogRepo) Update(ctx context.Context, d *models.DailyLog) error {
-	return r.db.WithContext(ctx).Save(d).Error
+	return dbFrom(ctx, r.db).Save(d).Error
 }
 
 func (r *dailyLogRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.DailyLog{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.DailyLog{}, "id = ?", id).Error
 }
@@ -0,0 +1,88 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type deliveryJobRepo struct {
+	db *gorm.DB
+}
+
+func NewDeliveryJobRepository(db *gorm.DB) outbound.DeliveryJobRepository {
+	return &deliveryJobRepo{db: db}
+}
+
+func (r *deliveryJobRepo) Create(ctx context.Context, j *models.DeliveryJob) error {
+	return dbFrom(ctx, r.db).Create(j).Error
+}
+
+func (r *deliveryJobRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.DeliveryJob, error) {
+	var j models.DeliveryJob
+	if err := dbFrom(ctx, r.db).Where("id = ?", id).First(&j).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &j, nil
+}
+
+// ClaimDue locks due rows with FOR UPDATE SKIP LOCKED so workers on several instances split the queue.
+func (r *deliveryJobRepo) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.DeliveryJob, error) {
+	var jobs []*models.DeliveryJob
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?)",
+				models.DeliveryJobStatusPending, now, models.DeliveryJobStatusRunning, now).
+			Order("next_attempt_at ASC").Limit(limit).Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+		ids := make([]uuid.UUID, len(jobs))
+		lockedUntil := now.Add(lease)
+		for i, j := range jobs {
+			ids[i] = j.ID
+			j.Status = models.DeliveryJobStatusRunning
+			j.LockedUntil = &lockedUntil
+		}
+		return tx.Model(&models.DeliveryJob{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":       models.DeliveryJobStatusRunning,
+			"locked_until": lockedUntil,
+			"updated_at":   now,
+		}).Error
+	})
+	return jobs, err
+}
+
+func (r *deliveryJobRepo) Update(ctx context.Context, j *models.DeliveryJob) error {
+	return dbFrom(ctx, r.db).Save(j).Error
+}
+
+func (r *deliveryJobRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFrom(ctx, r.db).Where("id = ?", id).Delete(&models.DeliveryJob{}).Error
+}
+
+func (r *deliveryJobRepo) ListDead(ctx context.Context, pharmacyID uuid.UUID, kind string, limit, offset int) ([]*models.DeliveryJob, int64, error) {
+	q := dbFrom(ctx, r.db).Model(&models.DeliveryJob{}).
+		Where("pharmacy_id = ? AND status = ?", pharmacyID, models.DeliveryJobStatusDead)
+	if kind != "" {
+		q = q.Where("kind = ?", kind)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.DeliveryJob
+	err := q.Order("updated_at DESC").Limit(limit).Offset(offset).Find(&list).Error
+	return list, total, err
+}
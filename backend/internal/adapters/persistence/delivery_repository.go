@@ -0,0 +1,60 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type deliveryRepo struct {
+	db *gorm.DB
+}
+
+func NewDeliveryRepository(db *gorm.DB) outbound.DeliveryRepository {
+	return &deliveryRepo{db: db}
+}
+
+func (r *deliveryRepo) Create(ctx context.Context, d *models.Delivery) error {
+	return dbFrom(ctx, r.db).Create(d).Error
+}
+
+func (r *deliveryRepo) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.Delivery, error) {
+	var d models.Delivery
+	err := dbFrom(ctx, r.db).
+		Preload("Assignee").
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Where("order_id = ?", orderID).
+		First(&d).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *deliveryRepo) Update(ctx context.Context, d *models.Delivery) error {
+	return dbFrom(ctx, r.db).Omit("Assignee", "Events").Save(d).Error
+}
+
+func (r *deliveryRepo) CreateEvent(ctx context.Context, e *models.DeliveryEvent) error {
+	return dbFrom(ctx, r.db).Create(e).Error
+}
+
+func (r *deliveryRepo) List(ctx context.Context, pharmacyID uuid.UUID, status *models.DeliveryStatus, assignedTo *uuid.UUID) ([]*models.Delivery, error) {
+	q := dbFrom(ctx, r.db).Preload("Assignee").Where("pharmacy_id = ?", pharmacyID)
+	if status != nil {
+		q = q.Where("status = ?", *status)
+	}
+	if assignedTo != nil {
+		q = q.Where("assigned_to = ?", *assignedTo)
+	}
+	var list []*models.Delivery
+	err := q.Order("created_at DESC").Find(&list).Error
+	return list, err
+}
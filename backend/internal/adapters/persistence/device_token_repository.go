@@ -0,0 +1,49 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type deviceTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewDeviceTokenRepository(db *gorm.DB) outbound.DeviceTokenRepository {
+	return &deviceTokenRepo{db: db}
+}
+
+func (r *deviceTokenRepo) Upsert(ctx context.Context, d *models.DeviceToken) error {
+	now := time.Now()
+	d.LastSeenAt = now
+	return dbFrom(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "token"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"pharmacy_id":  d.PharmacyID,
+			"user_id":      d.UserID,
+			"platform":     d.Platform,
+			"last_seen_at": now,
+			"updated_at":   now,
+		}),
+	}).Create(d).Error
+}
+
+func (r *deviceTokenRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.DeviceToken, error) {
+	var list []*models.DeviceToken
+	err := dbFrom(ctx, r.db).Where("user_id = ?", userID).Order("last_seen_at DESC").Find(&list).Error
+	return list, err
+}
+
+func (r *deviceTokenRepo) Delete(ctx context.Context, userID uuid.UUID, token string) error {
+	return dbFrom(ctx, r.db).Where("user_id = ? AND token = ?", userID, token).Delete(&models.DeviceToken{}).Error
+}
+
+func (r *deviceTokenRepo) DeleteByToken(ctx context.Context, token string) error {
+	return dbFrom(ctx, r.db).Where("token = ?", token).Delete(&models.DeviceToken{}).Error
+}
@@ -0,0 +1,53 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type dispatchBatchRepo struct {
+	db *gorm.DB
+}
+
+func NewDispatchBatchRepository(db *gorm.DB) outbound.DispatchBatchRepository {
+	return &dispatchBatchRepo{db: db}
+}
+
+func (r *dispatchBatchRepo) Create(ctx context.Context, b *models.DispatchBatch) error {
+	return dbFrom(ctx, r.db).Omit("Rider", "Deliveries").Create(b).Error
+}
+
+func (r *dispatchBatchRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.DispatchBatch, error) {
+	var b models.DispatchBatch
+	err := dbFrom(ctx, r.db).
+		Preload("Rider").
+		Preload("Deliveries", func(db *gorm.DB) *gorm.DB { return db.Order("stop_sequence ASC") }).
+		Where("id = ?", id).
+		First(&b).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *dispatchBatchRepo) List(ctx context.Context, pharmacyID uuid.UUID, riderID *uuid.UUID, limit, offset int) ([]*models.DispatchBatch, int64, error) {
+	q := dbFrom(ctx, r.db).Model(&models.DispatchBatch{}).Where("pharmacy_id = ?", pharmacyID)
+	if riderID != nil {
+		q = q.Where("rider_id = ?", *riderID)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.DispatchBatch
+	err := q.Preload("Rider").Order("created_at DESC").Limit(limit).Offset(offset).Find(&list).Error
+	return list, total, err
+}
@@ -0,0 +1,69 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type drugInfoRepo struct {
+	db *gorm.DB
+}
+
+func NewDrugInfoRepository(db *gorm.DB) outbound.DrugInfoRepository {
+	return &drugInfoRepo{db: db}
+}
+
+func (r *drugInfoRepo) ReplaceIngredients(ctx context.Context, productID uuid.UUID, rows []*models.ProductIngredient) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", productID).Delete(&models.ProductIngredient{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.Create(&rows).Error
+	})
+}
+
+func (r *drugInfoRepo) ListIngredients(ctx context.Context, productIDs []uuid.UUID) ([]*models.ProductIngredient, error) {
+	var list []*models.ProductIngredient
+	if len(productIDs) == 0 {
+		return list, nil
+	}
+	err := dbFrom(ctx, r.db).Where("product_id IN ?", productIDs).Order("ingredient").Find(&list).Error
+	return list, err
+}
+
+func (r *drugInfoRepo) CreateInteraction(ctx context.Context, rule *models.DrugInteraction) error {
+	return dbFrom(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "pharmacy_id"}, {Name: "ingredient_a"}, {Name: "ingredient_b"}},
+		DoUpdates: clause.AssignmentColumns([]string{"severity", "description", "updated_at"}),
+	}).Create(rule).Error
+}
+
+func (r *drugInfoRepo) ListInteractions(ctx context.Context, pharmacyID uuid.UUID) ([]*models.DrugInteraction, error) {
+	var list []*models.DrugInteraction
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).Order("ingredient_a, ingredient_b").Find(&list).Error
+	return list, err
+}
+
+func (r *drugInfoRepo) FindInteractions(ctx context.Context, pharmacyID uuid.UUID, ingredients []string) ([]*models.DrugInteraction, error) {
+	var list []*models.DrugInteraction
+	if len(ingredients) < 2 {
+		return list, nil
+	}
+	err := dbFrom(ctx, r.db).
+		Where("pharmacy_id = ? AND ingredient_a IN ? AND ingredient_b IN ?", pharmacyID, ingredients, ingredients).
+		Find(&list).Error
+	return list, err
+}
+
+func (r *drugInfoRepo) DeleteInteraction(ctx context.Context, pharmacyID, id uuid.UUID) (bool, error) {
+	res := dbFrom(ctx, r.db).Where("pharmacy_id = ? AND id = ?", pharmacyID, id).Delete(&models.DrugInteraction{})
+	return res.RowsAffected > 0, res.Error
+}
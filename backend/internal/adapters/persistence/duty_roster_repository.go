@@ -19,12 +19,12 @@ func NewDutyRosterRepository(db *gorm.DB) outbound.DutyRosterRepository {
 }
 
 func (r *dutyRosterRepo) Create(ctx context.Context, d *models.DutyRoster) error {
-	return r.db.WithContext(ctx).Create(d).Error
+	return dbFrom(ctx, r.db).Create(d).Error
 }
 
 func (r *dutyRosterRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.DutyRoster, error) {
 	var d models.DutyRoster
-	err := r.db.WithContext(ctx).Preload("User").First(&d, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).Preload("User").First(&d, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -33,17 +33,44 @@ func (r *dutyRosterRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Dut
 
 func (r *dutyRosterRepo) ListByPharmacyAndDateRange(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.DutyRoster, error) {
 	var list []*models.DutyRoster
-	err := r.db.WithContext(ctx).Preload("User").
+	err := dbFrom(ctx, r.db).Preload("User").
 		Where("pharmacy_id = ? AND date >= ? AND date <= ?", pharmacyID, from, to).
 		Order("date ASC, user_id ASC").
 		Find(&list).Error
 	return list, err
 }
 
+func (r *dutyRosterRepo) ListByStatusAndDateRange(ctx context.Context, pharmacyID uuid.UUID, status models.RosterStatus, from, to time.Time) ([]*models.DutyRoster, error) {
+	var list []*models.DutyRoster
+	err := dbFrom(ctx, r.db).Preload("User").
+		Where("pharmacy_id = ? AND status = ? AND date >= ? AND date <= ?", pharmacyID, status, from, to).
+		Order("date ASC, user_id ASC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *dutyRosterRepo) ListByUserAndDateRange(ctx context.Context, pharmacyID, userID uuid.UUID, status models.RosterStatus, from, to time.Time) ([]*models.DutyRoster, error) {
+	var list []*models.DutyRoster
+	err := dbFrom(ctx, r.db).
+		Where("pharmacy_id = ? AND user_id = ? AND status = ? AND date >= ? AND date <= ?", pharmacyID, userID, status, from, to).
+		Order("date ASC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *dutyRosterRepo) ListUnacknowledged(ctx context.Context, pharmacyID uuid.UUID, from time.Time) ([]*models.DutyRoster, error) {
+	var list []*models.DutyRoster
+	err := dbFrom(ctx, r.db).Preload("User").
+		Where("pharmacy_id = ? AND status = ? AND acknowledged_at IS NULL AND date >= ?", pharmacyID, models.RosterPublished, from).
+		Order("published_at ASC, date ASC").
+		Find(&list).Error
+	return list, err
+}
+
 func (r *dutyRosterRepo) Update(ctx context.Context, d *models.DutyRoster) error {
-	return r.db.WithContext(ctx).Save(d).Error
+	return dbFrom(ctx, r.db).Save(d).Error
 }
 
 func (r *dutyRosterRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.DutyRoster{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.DutyRoster{}, "id = ?", id).Error
 }
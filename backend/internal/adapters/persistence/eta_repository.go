@@ -0,0 +1,79 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type etaRepo struct {
+	db *gorm.DB
+}
+
+func NewETARepository(db *gorm.DB) outbound.ETARepository {
+	return &etaRepo{db: db}
+}
+
+func (r *etaRepo) Stats(ctx context.Context, pharmacyID uuid.UUID, since time.Time) (*models.ETAStats, error) {
+	var s models.ETAStats
+	// Prep: placement to the first move to ready. Delivery: leaving the pharmacy to delivered.
+	err := dbFrom(ctx, r.db).Raw(`
+		WITH prep AS (
+			SELECT EXTRACT(EPOCH FROM MIN(h.created_at) - o.created_at) / 60 AS minutes
+			FROM orders o
+			JOIN order_status_histories h ON h.order_id = o.id AND h.to_status = ?
+			WHERE o.pharmacy_id = ? AND o.deleted_at IS NULL AND o.created_at >= ?
+			GROUP BY o.id, o.created_at
+		), delivery AS (
+			SELECT EXTRACT(EPOCH FROM d.delivered_at - COALESCE(d.out_for_delivery_at, d.dispatched_at)) / 60 AS minutes
+			FROM deliveries d
+			WHERE d.pharmacy_id = ? AND d.delivered_at IS NOT NULL AND d.delivered_at >= ?
+				AND COALESCE(d.out_for_delivery_at, d.dispatched_at) IS NOT NULL
+		)
+		SELECT
+			(SELECT COUNT(*) FROM prep WHERE minutes BETWEEN 0 AND 240) AS prep_samples,
+			(SELECT COALESCE(AVG(minutes), 0) FROM prep WHERE minutes BETWEEN 0 AND 240) AS prep_minutes,
+			(SELECT COUNT(*) FROM delivery WHERE minutes BETWEEN 0 AND 240) AS delivery_samples,
+			(SELECT COALESCE(AVG(minutes), 0) FROM delivery WHERE minutes BETWEEN 0 AND 240) AS delivery_minutes`,
+		models.OrderStatusReady, pharmacyID, since, pharmacyID, since,
+	).Scan(&s).Error
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *etaRepo) OpenOrders(ctx context.Context, pharmacyID uuid.UUID, limit int) ([]*models.Order, error) {
+	var list []*models.Order
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ? AND status IN ?", pharmacyID, models.ETAOpenStatuses).
+		Order("created_at ASC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (r *etaRepo) OrderVolume(ctx context.Context, pharmacyID uuid.UUID, at time.Time, window time.Duration, days int) (recent, previous int64, err error) {
+	var row struct {
+		Recent   int64
+		Previous int64
+	}
+	err = dbFrom(ctx, r.db).Raw(`
+		SELECT
+			(SELECT COUNT(*) FROM orders
+				WHERE pharmacy_id = ? AND deleted_at IS NULL AND created_at > ? AND created_at <= ?) AS recent,
+			(SELECT COUNT(*) FROM orders o
+				JOIN generate_series(1, ?) AS d(k)
+					ON o.created_at > CAST(? AS timestamptz) - d.k * INTERVAL '1 day'
+					AND o.created_at <= CAST(? AS timestamptz) - d.k * INTERVAL '1 day'
+				WHERE o.pharmacy_id = ? AND o.deleted_at IS NULL) AS previous`,
+		pharmacyID, at.Add(-window), at, days, at.Add(-window), at, pharmacyID,
+	).Scan(&row).Error
+	return row.Recent, row.Previous, err
+}
+
+func (r *etaRepo) SetEstimates(ctx context.Context, orderID uuid.UUID, readyAt, deliveryAt *time.Time) error {
+	return dbFrom(ctx, r.db).Model(&models.Order{}).Where("id = ?", orderID).
+		UpdateColumns(map[string]interface{}{"estimated_ready_at": readyAt, "estimated_delivery_at": deliveryAt}).Error
+}
@@ -0,0 +1,45 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type exchangeRateRepo struct {
+	db *gorm.DB
+}
+
+func NewExchangeRateRepository(db *gorm.DB) outbound.ExchangeRateRepository {
+	return &exchangeRateRepo{db: db}
+}
+
+func (r *exchangeRateRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.ExchangeRate, error) {
+	var list []*models.ExchangeRate
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).Order("currency").Find(&list).Error
+	return list, err
+}
+
+func (r *exchangeRateRepo) Get(ctx context.Context, pharmacyID uuid.UUID, currency string) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ? AND currency = ?", pharmacyID, currency).First(&rate).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+func (r *exchangeRateRepo) Save(ctx context.Context, rate *models.ExchangeRate) error {
+	return dbFrom(ctx, r.db).Save(rate).Error
+}
+
+func (r *exchangeRateRepo) Delete(ctx context.Context, pharmacyID uuid.UUID, currency string) error {
+	return dbFrom(ctx, r.db).Where("pharmacy_id = ? AND currency = ?", pharmacyID, currency).Delete(&models.ExchangeRate{}).Error
+}
@@ -0,0 +1,60 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type exportRequestRepo struct {
+	db *gorm.DB
+}
+
+func NewExportRequestRepository(db *gorm.DB) outbound.ExportRequestRepository {
+	return &exportRequestRepo{db: db}
+}
+
+func (r *exportRequestRepo) Create(ctx context.Context, req *models.ExportRequest) error {
+	return dbFrom(ctx, r.db).Omit("Requester").Create(req).Error
+}
+
+func (r *exportRequestRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.ExportRequest, error) {
+	var req models.ExportRequest
+	err := dbFrom(ctx, r.db).Preload("Requester").First(&req, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (r *exportRequestRepo) List(ctx context.Context, pharmacyID uuid.UUID, requestedBy *uuid.UUID, status string, limit int) ([]*models.ExportRequest, error) {
+	var list []*models.ExportRequest
+	q := dbFrom(ctx, r.db).Preload("Requester").Where("pharmacy_id = ?", pharmacyID)
+	if requestedBy != nil {
+		q = q.Where("requested_by = ?", *requestedBy)
+	}
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	err := q.Order("created_at DESC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (r *exportRequestRepo) Update(ctx context.Context, req *models.ExportRequest) error {
+	return dbFrom(ctx, r.db).Omit("Requester").Save(req).Error
+}
+
+func (r *exportRequestRepo) Consume(ctx context.Context, id uuid.UUID, now time.Time) (bool, error) {
+	res := dbFrom(ctx, r.db).Model(&models.ExportRequest{}).
+		Where("id = ? AND status = ? AND expires_at > ?", id, models.ExportRequestApproved, now).
+		Updates(map[string]interface{}{"status": models.ExportRequestUsed, "used_at": now, "updated_at": now})
+	return res.RowsAffected > 0, res.Error
+}
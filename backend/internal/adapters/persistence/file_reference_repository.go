@@ -0,0 +1,131 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// fileReferenceIgnoredTables never keep a file alive: the ledger itself, the upload records every stored upload
+// has, and audit entries that merely mention a file.
+var fileReferenceIgnoredTables = []string{"file_references", "uploads", "activity_logs", "goose_db_version"}
+
+type fileReferenceRepo struct {
+	db *gorm.DB
+}
+
+func NewFileReferenceRepository(db *gorm.DB) outbound.FileReferenceRepository {
+	return &fileReferenceRepo{db: db}
+}
+
+func (r *fileReferenceRepo) Record(ctx context.Context, f *models.FileReference) error {
+	return dbFrom(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "url"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"path":         f.Path,
+			"private":      f.Private,
+			"content_type": f.ContentType,
+			"size":         f.Size,
+			"released_at":  nil,
+			"checked_at":   nil,
+		}),
+	}).Create(f).Error
+}
+
+func (r *fileReferenceRepo) Release(ctx context.Context, urls []string, at time.Time) error {
+	if len(urls) == 0 {
+		return nil
+	}
+	return dbFrom(ctx, r.db).Model(&models.FileReference{}).
+		Where("url IN ?", urls).
+		Update("released_at", at).Error
+}
+
+func (r *fileReferenceRepo) ListSweepCandidates(ctx context.Context, createdBefore time.Time, limit int) ([]*models.FileReference, error) {
+	var list []*models.FileReference
+	err := dbFrom(ctx, r.db).
+		Where("released_at IS NOT NULL OR created_at < ?", createdBefore).
+		Order("released_at IS NULL, checked_at ASC NULLS FIRST, created_at ASC").
+		Limit(limit).Find(&list).Error
+	return list, err
+}
+
+// Referenced scans every text, varchar and JSON column of the schema once per column for the URLs, since files
+// are referenced from many tables (product images, chat attachments, CVs, promo image sets, blog content...)
+// and a column missed by a hand-kept list would mean deleting a file still in use.
+func (r *fileReferenceRepo) Referenced(ctx context.Context, urls []string) (map[string]bool, error) {
+	found := make(map[string]bool, len(urls))
+	if len(urls) == 0 {
+		return found, nil
+	}
+	db := dbFrom(ctx, r.db)
+	var columns []struct {
+		TableName  string
+		ColumnName string
+		Soft       bool
+	}
+	err := db.Raw(`
+		SELECT c.table_name, c.column_name,
+			EXISTS (SELECT 1 FROM information_schema.columns d
+				WHERE d.table_schema = c.table_schema AND d.table_name = c.table_name AND d.column_name = 'deleted_at') AS soft
+		FROM information_schema.columns c
+		JOIN information_schema.tables t ON t.table_schema = c.table_schema AND t.table_name = c.table_name AND t.table_type = 'BASE TABLE'
+		WHERE c.table_schema = current_schema()
+			AND c.data_type IN ('text', 'character varying', 'json', 'jsonb')
+			AND c.table_name NOT IN ?
+		ORDER BY c.table_name, c.column_name`, fileReferenceIgnoredTables).Scan(&columns).Error
+	if err != nil {
+		return nil, fmt.Errorf("list text columns: %w", err)
+	}
+	for _, col := range columns {
+		pending := make([]string, 0, len(urls))
+		for _, u := range urls {
+			if !found[u] {
+				pending = append(pending, u)
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+		live := ""
+		if col.Soft {
+			live = " WHERE r.deleted_at IS NULL"
+		}
+		var hits []string
+		err := db.Raw(fmt.Sprintf(`
+			SELECT DISTINCT u.url FROM %s AS r
+			JOIN unnest(ARRAY[?]::text[]) AS u(url) ON strpos(r.%s::text, u.url) > 0%s`,
+			quoteIdent(col.TableName), quoteIdent(col.ColumnName), live), pending).Scan(&hits).Error
+		if err != nil {
+			return nil, fmt.Errorf("scan %s.%s: %w", col.TableName, col.ColumnName, err)
+		}
+		for _, u := range hits {
+			found[u] = true
+		}
+	}
+	return found, nil
+}
+
+func (r *fileReferenceRepo) MarkChecked(ctx context.Context, ids []uuid.UUID, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return dbFrom(ctx, r.db).Model(&models.FileReference{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{"checked_at": at, "released_at": nil}).Error
+}
+
+func (r *fileReferenceRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFrom(ctx, r.db).Delete(&models.FileReference{}, "id = ?", id).Error
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,153 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type flashSaleRepo struct {
+	db *gorm.DB
+}
+
+func NewFlashSaleRepository(db *gorm.DB) outbound.FlashSaleRepository {
+	return &flashSaleRepo{db: db}
+}
+
+func (r *flashSaleRepo) Create(ctx context.Context, f *models.FlashSale) error {
+	return dbFrom(ctx, r.db).Create(f).Error
+}
+
+func (r *flashSaleRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.FlashSale, error) {
+	var f models.FlashSale
+	err := dbFrom(ctx, r.db).Preload("Items.Product").First(&f, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *flashSaleRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.FlashSale, int64, error) {
+	scope := func() *gorm.DB {
+		return dbFrom(ctx, r.db).Model(&models.FlashSale{}).Where("pharmacy_id = ?", pharmacyID)
+	}
+	var total int64
+	if err := scope().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.FlashSale
+	q := scope().Preload("Items.Product").Order("starts_at DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	err := q.Find(&list).Error
+	return list, total, err
+}
+
+func (r *flashSaleRepo) Update(ctx context.Context, f *models.FlashSale) error {
+	return dbFrom(ctx, r.db).Omit("Items").Save(f).Error
+}
+
+func (r *flashSaleRepo) ReplaceItems(ctx context.Context, flashSaleID uuid.UUID, items []models.FlashSaleItem) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("flash_sale_id = ?", flashSaleID).Delete(&models.FlashSaleItem{}).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		for i := range items {
+			items[i].FlashSaleID = flashSaleID
+		}
+		return tx.Create(&items).Error
+	})
+}
+
+func (r *flashSaleRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFrom(ctx, r.db).Delete(&models.FlashSale{}, "id = ?", id).Error
+}
+
+func (r *flashSaleRepo) ListLiveItems(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID, now, startsBy time.Time) ([]*models.FlashSaleItem, error) {
+	var list []*models.FlashSaleItem
+	q := dbFrom(ctx, r.db).
+		Joins("JOIN flash_sales fs ON fs.id = flash_sale_items.flash_sale_id").
+		Where("fs.pharmacy_id = ? AND fs.deleted_at IS NULL AND fs.is_active = ? AND fs.starts_at <= ? AND fs.ends_at > ?", pharmacyID, true, startsBy, now)
+	if len(productIDs) > 0 {
+		q = q.Where("flash_sale_items.product_id IN ?", productIDs)
+	}
+	err := q.Preload("FlashSale").Preload("Product").Order("fs.ends_at ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *flashSaleRepo) CountOverlappingItems(ctx context.Context, pharmacyID, productID uuid.UUID, from, to time.Time, excludeSaleID uuid.UUID) (int64, error) {
+	var n int64
+	err := dbFrom(ctx, r.db).Model(&models.FlashSaleItem{}).
+		Joins("JOIN flash_sales fs ON fs.id = flash_sale_items.flash_sale_id").
+		Where("fs.pharmacy_id = ? AND fs.deleted_at IS NULL AND fs.is_active = ? AND fs.id <> ?", pharmacyID, true, excludeSaleID).
+		Where("flash_sale_items.product_id = ? AND fs.starts_at < ? AND fs.ends_at > ?", productID, to, from).
+		Count(&n).Error
+	return n, err
+}
+
+func (r *flashSaleRepo) ReserveItem(ctx context.Context, itemID uuid.UUID, qty int) (bool, error) {
+	res := dbFrom(ctx, r.db).Model(&models.FlashSaleItem{}).
+		Where("id = ? AND (max_quantity = 0 OR sold_quantity + ? <= max_quantity)", itemID, qty).
+		UpdateColumn("sold_quantity", gorm.Expr("sold_quantity + ?", qty))
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *flashSaleRepo) ReleaseItem(ctx context.Context, itemID uuid.UUID, qty int) error {
+	return dbFrom(ctx, r.db).Model(&models.FlashSaleItem{}).
+		Where("id = ?", itemID).
+		UpdateColumn("sold_quantity", gorm.Expr("GREATEST(sold_quantity - ?, 0)", qty)).Error
+}
+
+func (r *flashSaleRepo) ReserveCustomerQuantity(ctx context.Context, itemID uuid.UUID, customerKey string, qty, limit int) (bool, error) {
+	if qty > limit {
+		return false, nil
+	}
+	usage := &models.FlashSaleCustomerUsage{FlashSaleItemID: itemID, CustomerKey: customerKey, Quantity: qty}
+	res := dbFrom(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "flash_sale_item_id"}, {Name: "customer_key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"quantity":   gorm.Expr("flash_sale_customer_usage.quantity + ?", qty),
+			"updated_at": time.Now(),
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{gorm.Expr("flash_sale_customer_usage.quantity + ? <= ?", qty, limit)}},
+	}).Create(usage)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *flashSaleRepo) ReleaseCustomerQuantity(ctx context.Context, itemID uuid.UUID, customerKey string, qty int) error {
+	return dbFrom(ctx, r.db).Model(&models.FlashSaleCustomerUsage{}).
+		Where("flash_sale_item_id = ? AND customer_key = ?", itemID, customerKey).
+		UpdateColumns(map[string]interface{}{"quantity": gorm.Expr("GREATEST(quantity - ?, 0)", qty), "updated_at": time.Now()}).Error
+}
+
+func (r *flashSaleRepo) CreateRedemption(ctx context.Context, rd *models.FlashSaleRedemption) error {
+	return dbFrom(ctx, r.db).Create(rd).Error
+}
+
+func (r *flashSaleRepo) ListRedemptionsByOrder(ctx context.Context, orderID uuid.UUID) ([]*models.FlashSaleRedemption, error) {
+	var list []*models.FlashSaleRedemption
+	err := dbFrom(ctx, r.db).Where("order_id = ?", orderID).Find(&list).Error
+	return list, err
+}
+
+func (r *flashSaleRepo) DeleteRedemptionsByOrder(ctx context.Context, orderID uuid.UUID) error {
+	return dbFrom(ctx, r.db).Where("order_id = ?", orderID).Delete(&models.FlashSaleRedemption{}).Error
+}
@@ -0,0 +1,43 @@
+package persistence
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestFlashSaleRepo_ReserveCustomerQuantityIsConditional(t *testing.T) {
+	pool := &resultPool{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), &gorm.Config{
+		DisableAutomaticPing: true, SkipDefaultTransaction: true, Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	repo := NewFlashSaleRepository(db)
+	ctx, itemID := context.Background(), uuid.New()
+
+	pool.affected = 1
+	if ok, err := repo.ReserveCustomerQuantity(ctx, itemID, "9800000000", 2, 3); err != nil || !ok {
+		t.Fatalf("within the limit: ok = %v, err = %v", ok, err)
+	}
+	want := `ON CONFLICT ("flash_sale_item_id","customer_key") DO UPDATE SET`
+	if got := pool.sql[0]; !strings.Contains(got, want) || !strings.Contains(got, "WHERE flash_sale_customer_usage.quantity + $") {
+		t.Errorf("sql = %s, want a conditional upsert", got)
+	}
+
+	// The row exists and the increment would go over the limit, so the upsert's WHERE leaves it untouched.
+	pool.affected = 0
+	if ok, err := repo.ReserveCustomerQuantity(ctx, itemID, "9800000000", 2, 3); err != nil || ok {
+		t.Errorf("over the limit: ok = %v, err = %v; want false", ok, err)
+	}
+	n := len(pool.sql)
+	if ok, _ := repo.ReserveCustomerQuantity(ctx, itemID, "9800000000", 4, 3); ok || len(pool.sql) != n {
+		t.Errorf("a single order above the limit reached the database")
+	}
+}
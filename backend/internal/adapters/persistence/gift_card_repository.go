@@ -0,0 +1,106 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type giftCardRepo struct {
+	db *gorm.DB
+}
+
+func NewGiftCardRepository(db *gorm.DB) outbound.GiftCardRepository {
+	return &giftCardRepo{db: db}
+}
+
+func (r *giftCardRepo) Create(ctx context.Context, card *models.GiftCard, issue *models.GiftCardTransaction) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(card).Error; err != nil {
+			return err
+		}
+		if issue == nil {
+			return nil
+		}
+		issue.GiftCardID = card.ID
+		return tx.Create(issue).Error
+	})
+}
+
+func (r *giftCardRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.GiftCard, error) {
+	var g models.GiftCard
+	if err := dbFrom(ctx, r.db).First(&g, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (r *giftCardRepo) GetByCode(ctx context.Context, pharmacyID uuid.UUID, code string) (*models.GiftCard, error) {
+	var g models.GiftCard
+	if err := dbFrom(ctx, r.db).First(&g, "pharmacy_id = ? AND code = ?", pharmacyID, code).Error; err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (r *giftCardRepo) List(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.GiftCard, int64, error) {
+	scope := func() *gorm.DB {
+		return dbFrom(ctx, r.db).Model(&models.GiftCard{}).Where("pharmacy_id = ?", pharmacyID)
+	}
+	var total int64
+	if err := scope().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.GiftCard
+	q := scope().Order("created_at DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	err := q.Find(&list).Error
+	return list, total, err
+}
+
+func (r *giftCardRepo) SetActive(ctx context.Context, id uuid.UUID, active bool) error {
+	return dbFrom(ctx, r.db).Model(&models.GiftCard{}).Where("id = ?", id).Update("is_active", active).Error
+}
+
+func (r *giftCardRepo) Adjust(ctx context.Context, t *models.GiftCardTransaction) (bool, error) {
+	applied := false
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		res := execScoped(ctx, tx, "UPDATE gift_cards SET balance = balance + ?, updated_at = NOW() WHERE id = ? AND balance + ? >= 0",
+			t.Amount, t.GiftCardID, t.Amount)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return nil
+		}
+		if err := tx.Model(&models.GiftCard{}).Where("id = ?", t.GiftCardID).Pluck("balance", &t.BalanceAfter).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(t).Error; err != nil {
+			return err
+		}
+		applied = true
+		return nil
+	})
+	return applied, err
+}
+
+func (r *giftCardRepo) ListTransactions(ctx context.Context, giftCardID uuid.UUID) ([]*models.GiftCardTransaction, error) {
+	var list []*models.GiftCardTransaction
+	err := dbFrom(ctx, r.db).Where("gift_card_id = ?", giftCardID).Order("created_at DESC").Find(&list).Error
+	return list, err
+}
+
+func (r *giftCardRepo) ListTransactionsByOrder(ctx context.Context, orderID uuid.UUID) ([]*models.GiftCardTransaction, error) {
+	var list []*models.GiftCardTransaction
+	err := dbFrom(ctx, r.db).Where("order_id = ?", orderID).Order("created_at ASC").Find(&list).Error
+	return list, err
+}
@@ -0,0 +1,158 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type hashtagRepo struct {
+	db *gorm.DB
+}
+
+func NewHashtagRepository(db *gorm.DB) outbound.HashtagRepository {
+	return &hashtagRepo{db: db}
+}
+
+func (r *hashtagRepo) Create(ctx context.Context, h *models.Hashtag) error {
+	return dbFrom(ctx, r.db).Create(h).Error
+}
+
+func (r *hashtagRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Hashtag, error) {
+	var h models.Hashtag
+	err := dbFrom(ctx, r.db).First(&h, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+func (r *hashtagRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Hashtag, error) {
+	var list []*models.Hashtag
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).Order("tag ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *hashtagRepo) Update(ctx context.Context, h *models.Hashtag) error {
+	return dbFrom(ctx, r.db).Save(h).Error
+}
+
+func (r *hashtagRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFrom(ctx, r.db).Delete(&models.Hashtag{}, "id = ?", id).Error
+}
+
+// productTags unnests product hashtags, skipping rows where the column is not a JSON array.
+const productTags = `CROSS JOIN LATERAL jsonb_array_elements_text(CASE WHEN jsonb_typeof(p.hashtags) = 'array' THEN p.hashtags ELSE '[]'::jsonb END) AS tag(value)`
+
+func (r *hashtagRepo) Usage(ctx context.Context, pharmacyID uuid.UUID) ([]*models.HashtagUsageRow, error) {
+	var rows []*models.HashtagUsageRow
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT tag.value AS tag, COUNT(DISTINCT p.id) AS product_count
+		FROM products p `+productTags+`
+		WHERE p.pharmacy_id = ? AND p.deleted_at IS NULL
+		GROUP BY tag.value
+		ORDER BY product_count DESC, tag.value ASC`, pharmacyID).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *hashtagRepo) ReplaceOnProducts(ctx context.Context, pharmacyID uuid.UUID, from []string, to string) (int64, error) {
+	return replaceTags(dbFrom(ctx, r.db), pharmacyID, from, to)
+}
+
+// replaceTags rewrites from to `to` in product hashtags, keeping each tag's first position.
+func replaceTags(db *gorm.DB, pharmacyID uuid.UUID, from []string, to string) (int64, error) {
+	if len(from) == 0 {
+		return 0, nil
+	}
+	res := db.Exec(`
+		UPDATE products p SET updated_at = NOW(), hashtags = COALESCE((
+			SELECT jsonb_agg(t.tag ORDER BY t.pos) FROM (
+				SELECT DISTINCT ON (m.tag) m.tag, m.pos FROM (
+					SELECT CASE WHEN e.value IN ? THEN ? ELSE e.value END AS tag, e.pos
+					FROM jsonb_array_elements_text(p.hashtags) WITH ORDINALITY AS e(value, pos)
+				) m
+				WHERE m.tag <> ''
+				ORDER BY m.tag, m.pos
+			) t
+		), '[]'::jsonb)
+		WHERE p.pharmacy_id = ? AND p.deleted_at IS NULL AND jsonb_typeof(p.hashtags) = 'array'
+			AND EXISTS (SELECT 1 FROM jsonb_array_elements_text(p.hashtags) AS x(value) WHERE x.value IN ?)`,
+		from, to, pharmacyID, from)
+	return res.RowsAffected, res.Error
+}
+
+func (r *hashtagRepo) Merge(ctx context.Context, source, target *models.Hashtag) (int64, error) {
+	var n int64
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(target).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.Hashtag{}, "id = ?", source.ID).Error; err != nil {
+			return err
+		}
+		var err error
+		n, err = replaceTags(tx, target.PharmacyID, append([]string{source.Tag}, source.Aliases...), target.Tag)
+		return err
+	})
+	return n, err
+}
+
+func (r *hashtagRepo) Trending(ctx context.Context, pharmacyID uuid.UUID, since time.Time) ([]*models.HashtagTrend, error) {
+	var rows []*models.HashtagTrend
+	err := dbFrom(ctx, r.db).Raw(`
+		WITH tags AS (
+			SELECT p.id AS product_id, tag.value AS tag
+			FROM products p `+productTags+`
+			WHERE p.pharmacy_id = ? AND p.deleted_at IS NULL AND p.is_active
+		),
+		counts AS (SELECT tag, COUNT(DISTINCT product_id) AS product_count FROM tags GROUP BY tag),
+		views AS (
+			SELECT t.tag, SUM(v.views) AS views
+			FROM product_daily_views v JOIN tags t ON t.product_id = v.product_id
+			WHERE v.pharmacy_id = ? AND v.day >= ?
+			GROUP BY t.tag
+		),
+		sales AS (
+			SELECT t.tag, SUM(oi.quantity) AS units_sold
+			FROM order_items oi
+			JOIN orders o ON o.id = oi.order_id AND o.deleted_at IS NULL AND o.status <> ?
+			JOIN tags t ON t.product_id = oi.product_id
+			WHERE o.pharmacy_id = ? AND o.created_at >= ?
+			GROUP BY t.tag
+		)
+		SELECT c.tag, c.product_count, COALESCE(v.views, 0) AS views, COALESCE(s.units_sold, 0) AS units_sold
+		FROM counts c
+		LEFT JOIN views v ON v.tag = c.tag
+		LEFT JOIN sales s ON s.tag = c.tag
+		WHERE v.views > 0 OR s.units_sold > 0`,
+		pharmacyID, pharmacyID, since, models.OrderStatusCancelled, pharmacyID, since).Scan(&rows).Error
+	return rows, err
+}
+
+type productViewRepo struct {
+	db *gorm.DB
+}
+
+func NewProductViewRepository(db *gorm.DB) outbound.ProductViewRepository {
+	return &productViewRepo{db: db}
+}
+
+func (r *productViewRepo) Increment(ctx context.Context, pharmacyID, productID uuid.UUID, day time.Time) error {
+	row := &models.ProductDailyView{PharmacyID: pharmacyID, ProductID: productID, Day: day, Views: 1}
+	return dbFrom(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "product_id"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"views":      gorm.Expr("product_daily_views.views + 1"),
+			"updated_at": time.Now(),
+		}),
+	}).Create(row).Error
+}
@@ -0,0 +1,51 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type idempotencyKeyRepo struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyKeyRepository(db *gorm.DB) outbound.IdempotencyKeyRepository {
+	return &idempotencyKeyRepo{db: db}
+}
+
+// Create relies on the (user, scope, key) unique index: when the key is taken nothing is written.
+func (r *idempotencyKeyRepo) Create(ctx context.Context, k *models.IdempotencyKey) (bool, error) {
+	res := dbFrom(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(k)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *idempotencyKeyRepo) Get(ctx context.Context, userID uuid.UUID, scope, key string) (*models.IdempotencyKey, error) {
+	var k models.IdempotencyKey
+	if err := dbFrom(ctx, r.db).Where("user_id = ? AND scope = ? AND key = ?", userID, scope, key).First(&k).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *idempotencyKeyRepo) Update(ctx context.Context, k *models.IdempotencyKey) error {
+	return dbFrom(ctx, r.db).Save(k).Error
+}
+
+func (r *idempotencyKeyRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFrom(ctx, r.db).Where("id = ?", id).Delete(&models.IdempotencyKey{}).Error
+}
+
+func (r *idempotencyKeyRepo) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	res := dbFrom(ctx, r.db).Where("expires_at < ?", now).Delete(&models.IdempotencyKey{})
+	return res.RowsAffected, res.Error
+}
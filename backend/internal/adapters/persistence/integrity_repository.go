@@ -0,0 +1,115 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type integrityRepo struct {
+	db *gorm.DB
+}
+
+func NewIntegrityRepository(db *gorm.DB) outbound.IntegrityRepository {
+	return &integrityRepo{db: db}
+}
+
+// integrityScope appends "AND <column> = ?" when a pharmacy is given.
+func integrityScope(query string, column string, pharmacyID *uuid.UUID, args ...interface{}) (string, []interface{}) {
+	if pharmacyID != nil {
+		query += " AND " + column + " = ?"
+		args = append(args, *pharmacyID)
+	}
+	return query, args
+}
+
+func (r *integrityRepo) issues(ctx context.Context, query string, args []interface{}) ([]models.IntegrityIssue, error) {
+	var rows []models.IntegrityIssue
+	err := dbFrom(ctx, r.db).Raw(query+" ORDER BY 1", args...).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *integrityRepo) CrossTenantOrderItems(ctx context.Context, pharmacyID *uuid.UUID) ([]models.IntegrityIssue, error) {
+	q, args := integrityScope(`
+		SELECT oi.id AS record_id, o.pharmacy_id,
+			'order ' || o.order_number || ' has product ' || p.id || ' of pharmacy ' || p.pharmacy_id AS detail
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		JOIN products p ON p.id = oi.product_id
+		WHERE p.pharmacy_id <> o.pharmacy_id`, "o.pharmacy_id", pharmacyID)
+	return r.issues(ctx, q, args)
+}
+
+func (r *integrityRepo) PaymentsWithoutOrder(ctx context.Context, pharmacyID *uuid.UUID) ([]models.IntegrityIssue, error) {
+	q, args := integrityScope(`
+		SELECT pay.id AS record_id, pay.pharmacy_id,
+			CASE WHEN o.id IS NULL THEN 'order ' || pay.order_id || ' does not exist'
+				ELSE 'order ' || o.order_number || ' is deleted' END
+			|| ' (' || pay.status || ' payment of ' || pay.amount || ' ' || pay.currency || ')' AS detail
+		FROM payments pay
+		LEFT JOIN orders o ON o.id = pay.order_id
+		WHERE pay.deleted_at IS NULL AND (o.id IS NULL OR o.deleted_at IS NOT NULL)`, "pay.pharmacy_id", pharmacyID)
+	return r.issues(ctx, q, args)
+}
+
+func (r *integrityRepo) PointsLedgerMismatches(ctx context.Context, pharmacyID *uuid.UUID) ([]models.IntegrityIssue, error) {
+	q, args := integrityScope(`
+		SELECT c.id AS record_id, c.pharmacy_id,
+			'customer ' || c.phone || ' has balance ' || c.points_balance || ', ledger sums to ' || COALESCE(l.total, 0) AS detail
+		FROM customers c
+		LEFT JOIN (SELECT customer_id, SUM(amount) AS total FROM points_transactions GROUP BY customer_id) l ON l.customer_id = c.id
+		WHERE c.deleted_at IS NULL AND c.points_balance <> COALESCE(l.total, 0)`, "c.pharmacy_id", pharmacyID)
+	return r.issues(ctx, q, args)
+}
+
+func (r *integrityRepo) ProductImages(ctx context.Context, pharmacyID *uuid.UUID) ([]models.IntegrityIssue, error) {
+	q, args := integrityScope(`
+		SELECT pi.id AS record_id, p.pharmacy_id, pi.url AS detail
+		FROM product_images pi
+		JOIN products p ON p.id = pi.product_id
+		WHERE pi.deleted_at IS NULL`, "p.pharmacy_id", pharmacyID)
+	return r.issues(ctx, q, args)
+}
+
+func (r *integrityRepo) ProductCategoryDrift(ctx context.Context, pharmacyID *uuid.UUID) ([]models.IntegrityIssue, error) {
+	q, args := integrityScope(`
+		SELECT p.id AS record_id, p.pharmacy_id,
+			'product ' || p.sku || ' has category "' || COALESCE(p.category, '') || '", category is named "' || c.name || '"' AS detail
+		FROM products p
+		JOIN categories c ON c.id = p.category_id AND c.deleted_at IS NULL
+		WHERE p.deleted_at IS NULL AND p.category IS DISTINCT FROM c.name`, "p.pharmacy_id", pharmacyID)
+	return r.issues(ctx, q, args)
+}
+
+func (r *integrityRepo) ResetPointsFromLedger(ctx context.Context, customerIDs []uuid.UUID) (int64, error) {
+	if len(customerIDs) == 0 {
+		return 0, nil
+	}
+	res := dbFrom(ctx, r.db).Exec(`
+		UPDATE customers c SET points_balance = COALESCE((SELECT SUM(amount) FROM points_transactions pt WHERE pt.customer_id = c.id), 0),
+			updated_at = NOW()
+		WHERE c.id IN ?`, customerIDs)
+	return res.RowsAffected, res.Error
+}
+
+func (r *integrityRepo) SoftDeleteProductImages(ctx context.Context, imageIDs []uuid.UUID) (int64, error) {
+	if len(imageIDs) == 0 {
+		return 0, nil
+	}
+	res := dbFrom(ctx, r.db).Where("id IN ?", imageIDs).Delete(&models.ProductImage{})
+	return res.RowsAffected, res.Error
+}
+
+func (r *integrityRepo) ResyncProductCategories(ctx context.Context, productIDs []uuid.UUID) (int64, error) {
+	if len(productIDs) == 0 {
+		return 0, nil
+	}
+	res := dbFrom(ctx, r.db).Exec(`
+		UPDATE products p SET category = c.name, updated_at = NOW()
+		FROM categories c
+		WHERE c.id = p.category_id AND p.id IN ?`, productIDs)
+	return res.RowsAffected, res.Error
+}
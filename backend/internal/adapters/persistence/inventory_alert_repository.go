@@ -0,0 +1,48 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type inventoryAlertRepo struct {
+	db *gorm.DB
+}
+
+func NewInventoryAlertRepository(db *gorm.DB) outbound.InventoryAlertRepository {
+	return &inventoryAlertRepo{db: db}
+}
+
+func (r *inventoryAlertRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.InventoryAlert, error) {
+	var list []*models.InventoryAlert
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).Find(&list).Error
+	return list, err
+}
+
+func (r *inventoryAlertRepo) Create(ctx context.Context, a *models.InventoryAlert, events ...*models.OutboxEvent) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(a).Error; err != nil {
+			return err
+		}
+		return appendOutbox(tx, events)
+	})
+}
+
+func (r *inventoryAlertRepo) MarkSeen(ctx context.Context, ids []uuid.UUID, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return dbFrom(ctx, r.db).Model(&models.InventoryAlert{}).Where("id IN ?", ids).Update("last_seen_at", at).Error
+}
+
+func (r *inventoryAlertRepo) Delete(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return dbFrom(ctx, r.db).Delete(&models.InventoryAlert{}, "id IN ?", ids).Error
+}
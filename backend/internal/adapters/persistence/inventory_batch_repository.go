@@ -19,12 +19,12 @@ func NewInventoryBatchRepository(db *gorm.DB) outbound.InventoryBatchRepository
 }
 
 func (r *inventoryBatchRepo) Create(ctx context.Context, b *models.InventoryBatch) error {
-	return r.db.WithContext(ctx).Create(b).Error
+	return dbFrom(ctx, r.db).Create(b).Error
 }
 
 func (r *inventoryBatchRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.InventoryBatch, error) {
 	var b models.InventoryBatch
-	err := r.db.WithContext(ctx).First(&b, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).First(&b, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -34,7 +34,7 @@ func (r *inventoryBatchRepo) GetByID(ctx context.Context, id uuid.UUID) (*models
 func (r *inventoryBatchRepo) ListByProductID(ctx context.Context, productID uuid.UUID) ([]*models.InventoryBatch, error) {
 	var list []*models.InventoryBatch
 	// Order by expiry: nulls last, then ascending (FEFO order)
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Where("product_id = ? AND quantity > 0", productID).
 		Order("expiry_date IS NULL ASC, expiry_date ASC").
 		Find(&list).Error
@@ -43,8 +43,8 @@ func (r *inventoryBatchRepo) ListByProductID(ctx context.Context, productID uuid
 
 func (r *inventoryBatchRepo) ListByPharmacyID(ctx context.Context, pharmacyID uuid.UUID) ([]*models.InventoryBatch, error) {
 	var list []*models.InventoryBatch
-	err := r.db.WithContext(ctx).
-		Where("pharmacy_id = ?", pharmacyID).
+	err := dbFrom(ctx, r.db).
+		Where("pharmacy_id = ? AND quantity > 0", pharmacyID).
 		Preload("Product").
 		Order("expiry_date IS NULL ASC, expiry_date ASC").
 		Find(&list).Error
@@ -53,7 +53,7 @@ func (r *inventoryBatchRepo) ListByPharmacyID(ctx context.Context, pharmacyID uu
 
 func (r *inventoryBatchRepo) ListExpiringByPharmacy(ctx context.Context, pharmacyID uuid.UUID, beforeOrOn time.Time) ([]*models.InventoryBatch, error) {
 	var list []*models.InventoryBatch
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Where("pharmacy_id = ? AND quantity > 0 AND expiry_date IS NOT NULL AND expiry_date <= ?", pharmacyID, beforeOrOn).
 		Order("expiry_date ASC").
 		Preload("Product").
@@ -62,9 +62,29 @@ func (r *inventoryBatchRepo) ListExpiringByPharmacy(ctx context.Context, pharmac
 }
 
 func (r *inventoryBatchRepo) Update(ctx context.Context, b *models.InventoryBatch) error {
-	return r.db.WithContext(ctx).Save(b).Error
+	return dbFrom(ctx, r.db).Save(b).Error
+}
+
+func (r *inventoryBatchRepo) Draw(ctx context.Context, id uuid.UUID, quantity int) (bool, error) {
+	res := execScoped(ctx, dbFrom(ctx, r.db), "UPDATE inventory_batches SET quantity = quantity - ?, updated_at = NOW() WHERE id = ? AND quantity >= ?",
+		quantity, id, quantity)
+	return res.RowsAffected == 1, res.Error
 }
 
 func (r *inventoryBatchRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.InventoryBatch{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.InventoryBatch{}, "id = ?", id).Error
+}
+
+func (r *inventoryBatchRepo) CreateAllocation(ctx context.Context, a *models.OrderItemBatch) error {
+	return dbFrom(ctx, r.db).Create(a).Error
+}
+
+func (r *inventoryBatchRepo) ListAllocationsByBatch(ctx context.Context, batchID uuid.UUID) ([]*models.OrderItemBatch, error) {
+	var list []*models.OrderItemBatch
+	err := dbFrom(ctx, r.db).
+		Where("batch_id = ?", batchID).
+		Preload("OrderItem").
+		Order("created_at DESC").
+		Find(&list).Error
+	return list, err
 }
@@ -0,0 +1,65 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type inventoryEvidenceRepo struct {
+	db *gorm.DB
+}
+
+func NewInventoryEvidenceRepository(db *gorm.DB) outbound.InventoryEvidenceRepository {
+	return &inventoryEvidenceRepo{db: db}
+}
+
+func (r *inventoryEvidenceRepo) CreateAdjustment(ctx context.Context, a *models.StockAdjustment) error {
+	return dbFrom(ctx, r.db).Omit("Photos").Create(a).Error
+}
+
+func (r *inventoryEvidenceRepo) GetAdjustment(ctx context.Context, id uuid.UUID) (*models.StockAdjustment, error) {
+	var a models.StockAdjustment
+	err := dbFrom(ctx, r.db).Preload("Photos", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at")
+	}).First(&a, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *inventoryEvidenceRepo) ListAdjustments(ctx context.Context, batchID uuid.UUID) ([]*models.StockAdjustment, error) {
+	var list []*models.StockAdjustment
+	err := dbFrom(ctx, r.db).Preload("Photos", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at")
+	}).Where("batch_id = ?", batchID).Order("created_at DESC").Find(&list).Error
+	return list, err
+}
+
+func (r *inventoryEvidenceRepo) CreatePhoto(ctx context.Context, p *models.InventoryPhoto) error {
+	return dbFrom(ctx, r.db).Create(p).Error
+}
+
+func (r *inventoryEvidenceRepo) ListPhotos(ctx context.Context, batchID uuid.UUID) ([]*models.InventoryPhoto, error) {
+	var list []*models.InventoryPhoto
+	err := dbFrom(ctx, r.db).Where("batch_id = ? AND adjustment_id IS NULL", batchID).Order("created_at DESC").Find(&list).Error
+	return list, err
+}
+
+func (r *inventoryEvidenceRepo) GetPhotos(ctx context.Context, pharmacyID uuid.UUID, ids []uuid.UUID) ([]*models.InventoryPhoto, error) {
+	var list []*models.InventoryPhoto
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ? AND id IN ?", pharmacyID, ids).Find(&list).Error
+	return list, err
+}
+
+func (r *inventoryEvidenceRepo) LinkPhotos(ctx context.Context, ids []uuid.UUID, actionID uuid.UUID) error {
+	return dbFrom(ctx, r.db).Model(&models.InventoryPhoto{}).Where("id IN ?", ids).Update("clearance_action_id", actionID).Error
+}
@@ -18,12 +18,12 @@ func NewInvoiceRepository(db *gorm.DB) outbound.InvoiceRepository {
 }
 
 func (r *invoiceRepo) Create(ctx context.Context, inv *models.Invoice) error {
-	return r.db.WithContext(ctx).Create(inv).Error
+	return dbFrom(ctx, r.db).Create(inv).Error
 }
 
 func (r *invoiceRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Invoice, error) {
 	var inv models.Invoice
-	err := r.db.WithContext(ctx).First(&inv, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).First(&inv, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +32,7 @@ func (r *invoiceRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Invoic
 
 func (r *invoiceRepo) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.Invoice, error) {
 	var inv models.Invoice
-	err := r.db.WithContext(ctx).Where("order_id = ?", orderID).First(&inv).Error
+	err := dbFrom(ctx, r.db).Where("order_id = ?", orderID).First(&inv).Error
 	if err != nil {
 		return nil, err
 	}
@@ -41,10 +41,10 @@ func (r *invoiceRepo) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*mod
 
 func (r *invoiceRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Invoice, error) {
 	var list []*models.Invoice
-	err := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID).Order("created_at DESC").Find(&list).Error
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).Order("created_at DESC").Find(&list).Error
 	return list, err
 }
 
 func (r *invoiceRepo) Update(ctx context.Context, inv *models.Invoice) error {
-	return r.db.WithContext(ctx).Save(inv).Error
+	return dbFrom(ctx, r.db).Save(inv).Error
 }
@@ -0,0 +1,47 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type loginChallengeRepo struct {
+	db *gorm.DB
+}
+
+func NewLoginChallengeRepository(db *gorm.DB) outbound.LoginChallengeRepository {
+	return &loginChallengeRepo{db: db}
+}
+
+func (r *loginChallengeRepo) Create(ctx context.Context, c *models.LoginChallenge) error {
+	return dbFrom(ctx, r.db).Create(c).Error
+}
+
+func (r *loginChallengeRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*models.LoginChallenge, error) {
+	var c models.LoginChallenge
+	if err := dbFrom(ctx, r.db).First(&c, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *loginChallengeRepo) CountSince(ctx context.Context, userID uuid.UUID, since time.Time) (int64, error) {
+	var n int64
+	err := dbFrom(ctx, r.db).Model(&models.LoginChallenge{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&n).Error
+	return n, err
+}
+
+func (r *loginChallengeRepo) Update(ctx context.Context, c *models.LoginChallenge) error {
+	return dbFrom(ctx, r.db).Save(c).Error
+}
@@ -18,12 +18,12 @@ func NewMembershipRepository(db *gorm.DB) outbound.MembershipRepository {
 }
 
 func (r *membershipRepo) Create(ctx context.Context, m *models.Membership) error {
-	return r.db.WithContext(ctx).Create(m).Error
+	return dbFrom(ctx, r.db).Create(m).Error
 }
 
 func (r *membershipRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Membership, error) {
 	var m models.Membership
-	err := r.db.WithContext(ctx).First(&m, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).First(&m, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,15 +32,15 @@ func (r *membershipRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Mem
 
 func (r *membershipRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Membership, error) {
 	var list []*models.Membership
-	err := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID).
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).
 		Order("sort_order ASC, name ASC").Find(&list).Error
 	return list, err
 }
 
 func (r *membershipRepo) Update(ctx context.Context, m *models.Membership) error {
-	return r.db.WithContext(ctx).Save(m).Error
+	return dbFrom(ctx, r.db).Save(m).Error
 }
 
 func (r *membershipRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.Membership{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.Membership{}, "id = ?", id).Error
 }
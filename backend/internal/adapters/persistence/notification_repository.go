@@ -19,12 +19,12 @@ func NewNotificationRepository(db *gorm.DB) outbound.NotificationRepository {
 }
 
 func (r *notificationRepo) Create(ctx context.Context, n *models.Notification) error {
-	return r.db.WithContext(ctx).Create(n).Error
+	return dbFrom(ctx, r.db).Create(n).Error
 }
 
 func (r *notificationRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Notification, error) {
 	var n models.Notification
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&n).Error
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&n).Error
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +38,7 @@ func (r *notificationRepo) ListByUser(ctx context.Context, userID uuid.UUID, unr
 	if limit > 100 {
 		limit = 100
 	}
-	q := r.db.WithContext(ctx).
+	q := dbFrom(ctx, r.db).
 		Where("user_id = ?", userID).
 		Preload("User").
 		Order("created_at DESC").
@@ -54,7 +54,7 @@ func (r *notificationRepo) ListByUser(ctx context.Context, userID uuid.UUID, unr
 
 func (r *notificationRepo) CountUnreadByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.Notification{}).
+	err := dbFrom(ctx, r.db).Model(&models.Notification{}).
 		Where("user_id = ? AND read_at IS NULL", userID).
 		Count(&count).Error
 	return count, err
@@ -62,14 +62,14 @@ func (r *notificationRepo) CountUnreadByUser(ctx context.Context, userID uuid.UU
 
 func (r *notificationRepo) MarkRead(ctx context.Context, id, userID uuid.UUID) error {
 	now := time.Now()
-	return r.db.WithContext(ctx).Model(&models.Notification{}).
+	return dbFrom(ctx, r.db).Model(&models.Notification{}).
 		Where("id = ? AND user_id = ?", id, userID).
 		Update("read_at", now).Error
 }
 
 func (r *notificationRepo) MarkAllRead(ctx context.Context, userID uuid.UUID) error {
 	now := time.Now()
-	return r.db.WithContext(ctx).Model(&models.Notification{}).
+	return dbFrom(ctx, r.db).Model(&models.Notification{}).
 		Where("user_id = ? AND read_at IS NULL", userID).
 		Update("read_at", now).Error
 }
@@ -2,6 +2,7 @@ package persistence
 
 import (
 	"context"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
@@ -18,12 +19,12 @@ func NewOrderFeedbackRepository(db *gorm.DB) outbound.OrderFeedbackRepository {
 }
 
 func (r *orderFeedbackRepo) Create(ctx context.Context, f *models.OrderFeedback) error {
-	return r.db.WithContext(ctx).Create(f).Error
+	return dbFrom(ctx, r.db).Create(f).Error
 }
 
 func (r *orderFeedbackRepo) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.OrderFeedback, error) {
 	var f models.OrderFeedback
-	err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Preload("User").First(&f).Error
+	err := dbFrom(ctx, r.db).Where("order_id = ?", orderID).Preload("User").First(&f).Error
 	if err == gorm.ErrRecordNotFound {
 		return nil, nil
 	}
@@ -32,3 +33,110 @@ func (r *orderFeedbackRepo) GetByOrderID(ctx context.Context, orderID uuid.UUID)
 	}
 	return &f, nil
 }
+
+func (r *orderFeedbackRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.OrderFeedback, error) {
+	var f models.OrderFeedback
+	err := dbFrom(ctx, r.db).Where("id = ?", id).Preload("Order").First(&f).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *orderFeedbackRepo) Update(ctx context.Context, f *models.OrderFeedback) error {
+	return dbFrom(ctx, r.db).Omit("Order", "User").Save(f).Error
+}
+
+func (r *orderFeedbackRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, filter outbound.FeedbackFilter, limit, offset int) ([]*models.OrderFeedback, int64, error) {
+	q := dbFrom(ctx, r.db).Model(&models.OrderFeedback{}).
+		Joins("JOIN orders ON orders.id = order_feedbacks.order_id").
+		Where("orders.pharmacy_id = ?", pharmacyID)
+	if filter.FollowUpStatus != "" {
+		q = q.Where("order_feedbacks.follow_up_status = ?", filter.FollowUpStatus)
+	}
+	if filter.MaxRating > 0 {
+		q = q.Where("order_feedbacks.rating <= ?", filter.MaxRating)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.OrderFeedback
+	err := q.Preload("Order").Preload("User").
+		Order("order_feedbacks.created_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&list).Error
+	return list, total, err
+}
+
+func (r *orderFeedbackRepo) RatingTrend(ctx context.Context, pharmacyID uuid.UUID, granularity string, from, to time.Time) ([]*models.FeedbackTrendRow, error) {
+	switch granularity {
+	case "day", "week", "month":
+	default:
+		granularity = "day"
+	}
+	var rows []*models.FeedbackTrendRow
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT date_trunc(?, f.created_at) AS period,
+			COUNT(*) AS count,
+			AVG(f.rating) AS average_rating
+		FROM order_feedbacks f
+		JOIN orders o ON o.id = f.order_id
+		WHERE o.pharmacy_id = ? AND f.deleted_at IS NULL
+			AND f.created_at >= ? AND f.created_at < ?
+		GROUP BY period
+		ORDER BY period ASC`,
+		granularity, pharmacyID, from, to,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *orderFeedbackRepo) RatingByStaff(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.FeedbackGroupRow, error) {
+	var rows []*models.FeedbackGroupRow
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT t.staff_id::text AS key, u.name AS label,
+			COUNT(*) AS count,
+			AVG(t.rating) AS average_rating,
+			SUM(CASE WHEN t.rating <= ? THEN 1 ELSE 0 END) AS low_count
+		FROM (
+			SELECT f.rating, COALESCE(d.assigned_to, CASE WHEN cu.role <> ? THEN o.created_by END) AS staff_id
+			FROM order_feedbacks f
+			JOIN orders o ON o.id = f.order_id
+			LEFT JOIN deliveries d ON d.order_id = o.id
+			LEFT JOIN users cu ON cu.id = o.created_by
+			WHERE o.pharmacy_id = ? AND f.deleted_at IS NULL
+				AND f.created_at >= ? AND f.created_at < ?
+		) t
+		JOIN users u ON u.id = t.staff_id
+		GROUP BY t.staff_id, u.name
+		ORDER BY count DESC, average_rating ASC`,
+		models.LowFeedbackRating, models.RoleStaff, pharmacyID, from, to,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *orderFeedbackRepo) RatingByFulfilment(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.FeedbackGroupRow, error) {
+	var rows []*models.FeedbackGroupRow
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT t.fulfilment AS key, t.fulfilment AS label,
+			COUNT(*) AS count,
+			AVG(t.rating) AS average_rating,
+			SUM(CASE WHEN t.rating <= ? THEN 1 ELSE 0 END) AS low_count
+		FROM (
+			SELECT f.rating,
+				CASE WHEN d.id IS NOT NULL OR COALESCE(o.delivery_address, '') <> '' THEN 'delivery' ELSE 'pickup' END AS fulfilment
+			FROM order_feedbacks f
+			JOIN orders o ON o.id = f.order_id
+			LEFT JOIN deliveries d ON d.order_id = o.id
+			WHERE o.pharmacy_id = ? AND f.deleted_at IS NULL
+				AND f.created_at >= ? AND f.created_at < ?
+		) t
+		GROUP BY t.fulfilment
+		ORDER BY t.fulfilment ASC`,
+		models.LowFeedbackRating, pharmacyID, from, to,
+	).Scan(&rows).Error
+	return rows, err
+}
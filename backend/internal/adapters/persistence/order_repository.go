@@ -17,17 +17,22 @@ func NewOrderRepository(db *gorm.DB) outbound.OrderRepository {
 	return &orderRepo{db: db}
 }
 
-func (r *orderRepo) Create(ctx context.Context, o *models.Order) error {
-	return r.db.WithContext(ctx).Create(o).Error
+func (r *orderRepo) Create(ctx context.Context, o *models.Order, events ...*models.OutboxEvent) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(o).Error; err != nil {
+			return err
+		}
+		return appendOutbox(tx, events)
+	})
 }
 
 func (r *orderRepo) CreateItem(ctx context.Context, item *models.OrderItem) error {
-	return r.db.WithContext(ctx).Create(item).Error
+	return dbFrom(ctx, r.db).Create(item).Error
 }
 
 func (r *orderRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Order, error) {
 	var o models.Order
-	err := r.db.WithContext(ctx).Preload("Items").Preload("Items.Product").Preload("Items.Product.Images").Preload("PromoCode").First(&o, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).Preload("Items").Preload("Items.Product").Preload("Items.Product.Images").Preload("Items.Batches").Preload("Items.Serials").Preload("PromoCode").First(&o, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -36,7 +41,7 @@ func (r *orderRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Order, e
 
 func (r *orderRepo) GetByOrderNumber(ctx context.Context, pharmacyID uuid.UUID, orderNumber string) (*models.Order, error) {
 	var o models.Order
-	err := r.db.WithContext(ctx).Where("pharmacy_id = ? AND order_number = ?", pharmacyID, orderNumber).First(&o).Error
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ? AND order_number = ?", pharmacyID, orderNumber).First(&o).Error
 	if err != nil {
 		return nil, err
 	}
@@ -44,7 +49,7 @@ func (r *orderRepo) GetByOrderNumber(ctx context.Context, pharmacyID uuid.UUID,
 }
 
 func (r *orderRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, status *string) ([]*models.Order, error) {
-	q := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID)
+	q := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID)
 	if status != nil && *status != "" {
 		q = q.Where("status = ?", *status)
 	}
@@ -54,7 +59,7 @@ func (r *orderRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, st
 }
 
 func (r *orderRepo) ListByPharmacyAndCreatedBy(ctx context.Context, pharmacyID uuid.UUID, createdBy uuid.UUID, status *string) ([]*models.Order, error) {
-	q := r.db.WithContext(ctx).Where("pharmacy_id = ? AND created_by = ?", pharmacyID, createdBy)
+	q := dbFrom(ctx, r.db).Where("pharmacy_id = ? AND created_by = ?", pharmacyID, createdBy)
 	if status != nil && *status != "" {
 		q = q.Where("status = ?", *status)
 	}
@@ -63,31 +68,118 @@ func (r *orderRepo) ListByPharmacyAndCreatedBy(ctx context.Context, pharmacyID u
 	return list, err
 }
 
+func (r *orderRepo) ListPage(ctx context.Context, pharmacyID uuid.UUID, createdBy *uuid.UUID, status *string, branchID *uuid.UUID, limit, offset int) ([]*models.Order, int64, error) {
+	filter := outbound.OrderFilter{CreatedBy: createdBy, BranchID: branchID}
+	if status != nil {
+		filter.Status = *status
+	}
+	return r.ListPaginated(ctx, pharmacyID, filter, limit, offset)
+}
+
+func (r *orderRepo) ListPaginated(ctx context.Context, pharmacyID uuid.UUID, filter outbound.OrderFilter, limit, offset int) ([]*models.Order, int64, error) {
+	q := dbFrom(ctx, r.db).Model(&models.Order{}).Where("pharmacy_id = ?", pharmacyID)
+	if filter.CreatedBy != nil {
+		q = q.Where("created_by = ?", *filter.CreatedBy)
+	}
+	if filter.BranchID != nil {
+		q = q.Where("branch_id = ?", *filter.BranchID)
+	}
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if !filter.From.IsZero() {
+		q = q.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		q = q.Where("created_at < ?", filter.To)
+	}
+	if filter.OrderNumber != "" {
+		q = q.Where("order_number ILIKE ?", "%"+filter.OrderNumber+"%")
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.Order
+	err := q.Order("created_at DESC").Limit(limit).Offset(offset).Find(&list).Error
+	return list, total, err
+}
+
+func (r *orderRepo) ItemCounts(ctx context.Context, orderIDs []uuid.UUID) ([]*models.OrderItemCountRow, error) {
+	var rows []*models.OrderItemCountRow
+	if len(orderIDs) == 0 {
+		return rows, nil
+	}
+	err := dbFrom(ctx, r.db).Model(&models.OrderItem{}).
+		Select("order_id, COUNT(*) AS lines, COALESCE(SUM(quantity), 0) AS units").
+		Where("order_id IN ?", orderIDs).
+		Group("order_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
 func (r *orderRepo) Update(ctx context.Context, o *models.Order) error {
-	return r.db.WithContext(ctx).Save(o).Error
+	return dbFrom(ctx, r.db).Save(o).Error
+}
+
+func (r *orderRepo) SetConversation(ctx context.Context, orderID, conversationID uuid.UUID) error {
+	return dbFrom(ctx, r.db).Model(&models.Order{}).Where("id = ?", orderID).Update("conversation_id", conversationID).Error
+}
+
+func (r *orderRepo) UpdateStatus(ctx context.Context, o *models.Order, h *models.OrderStatusHistory, events ...*models.OutboxEvent) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(o).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(h).Error; err != nil {
+			return err
+		}
+		return appendOutbox(tx, events)
+	})
+}
+
+func (r *orderRepo) CreateStatusHistory(ctx context.Context, h *models.OrderStatusHistory) error {
+	return dbFrom(ctx, r.db).Create(h).Error
+}
+
+func (r *orderRepo) ListStatusHistory(ctx context.Context, orderIDs []uuid.UUID) ([]*models.OrderStatusHistory, error) {
+	var list []*models.OrderStatusHistory
+	if len(orderIDs) == 0 {
+		return list, nil
+	}
+	err := dbFrom(ctx, r.db).Where("order_id IN ?", orderIDs).Order("created_at ASC").Find(&list).Error
+	return list, err
 }
 
 func (r *orderRepo) GetItemsByOrderID(ctx context.Context, orderID uuid.UUID) ([]*models.OrderItem, error) {
 	var list []*models.OrderItem
-	err := r.db.WithContext(ctx).Preload("Product").Preload("Product.Images").Where("order_id = ?", orderID).Find(&list).Error
+	err := dbFrom(ctx, r.db).Preload("Product").Preload("Product.Images").Where("order_id = ?", orderID).Find(&list).Error
 	return list, err
 }
 
 func (r *orderRepo) CountByCustomerIDAndStatus(ctx context.Context, customerID uuid.UUID, status string) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.Order{}).Where("customer_id = ? AND status = ?", customerID, status).Count(&count).Error
+	err := dbFrom(ctx, r.db).Model(&models.Order{}).Where("customer_id = ? AND status = ?", customerID, status).Count(&count).Error
 	return count, err
 }
 
 func (r *orderRepo) CountByCreatedByAndPharmacy(ctx context.Context, createdBy, pharmacyID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.Order{}).Where("created_by = ? AND pharmacy_id = ?", createdBy, pharmacyID).Count(&count).Error
+	err := dbFrom(ctx, r.db).Model(&models.Order{}).Where("created_by = ? AND pharmacy_id = ?", createdBy, pharmacyID).Count(&count).Error
+	return count, err
+}
+
+func (r *orderRepo) CountByPromoCodeAndUser(ctx context.Context, promoCodeID, createdBy uuid.UUID) (int64, error) {
+	var count int64
+	err := dbFrom(ctx, r.db).Model(&models.Order{}).
+		Where("promo_code_id = ? AND created_by = ? AND status <> ?", promoCodeID, createdBy, models.OrderStatusCancelled).
+		Count(&count).Error
 	return count, err
 }
 
 func (r *orderRepo) GetLatestCompletedOrderWithProduct(ctx context.Context, pharmacyID, userID, productID uuid.UUID) (*models.Order, error) {
 	var o models.Order
-	err := r.db.WithContext(ctx).
+	err := dbFrom(ctx, r.db).
 		Joins("INNER JOIN order_items ON order_items.order_id = orders.id AND order_items.product_id = ?", productID).
 		Where("orders.pharmacy_id = ? AND orders.created_by = ? AND orders.status = ?", pharmacyID, userID, models.OrderStatusCompleted).
 		Order("COALESCE(orders.completed_at, orders.updated_at) DESC").
@@ -2,6 +2,7 @@ package persistence
 
 import (
 	"context"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
@@ -18,12 +19,12 @@ func NewOrderReturnRequestRepository(db *gorm.DB) outbound.OrderReturnRequestRep
 }
 
 func (r *orderReturnRequestRepo) Create(ctx context.Context, req *models.OrderReturnRequest) error {
-	return r.db.WithContext(ctx).Create(req).Error
+	return dbFrom(ctx, r.db).Create(req).Error
 }
 
 func (r *orderReturnRequestRepo) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.OrderReturnRequest, error) {
 	var req models.OrderReturnRequest
-	err := r.db.WithContext(ctx).Where("order_id = ?", orderID).First(&req).Error
+	err := dbFrom(ctx, r.db).Where("order_id = ?", orderID).First(&req).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -32,3 +33,95 @@ func (r *orderReturnRequestRepo) GetByOrderID(ctx context.Context, orderID uuid.
 	}
 	return &req, nil
 }
+
+func (r *orderReturnRequestRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.OrderReturnRequest, error) {
+	var req models.OrderReturnRequest
+	err := dbFrom(ctx, r.db).Preload("Order").Where("id = ?", id).First(&req).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (r *orderReturnRequestRepo) Update(ctx context.Context, req *models.OrderReturnRequest) error {
+	return dbFrom(ctx, r.db).Omit("Order", "User").Save(req).Error
+}
+
+func (r *orderReturnRequestRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, filter outbound.ReturnRequestFilter, limit, offset int) ([]*models.OrderReturnRequest, int64, error) {
+	q := dbFrom(ctx, r.db).Model(&models.OrderReturnRequest{}).
+		Joins("JOIN orders ON orders.id = order_return_requests.order_id").
+		Where("orders.pharmacy_id = ? AND orders.deleted_at IS NULL", pharmacyID)
+	if filter.Status != "" {
+		q = q.Where("order_return_requests.status = ?", filter.Status)
+	}
+	if filter.ReasonCode != "" {
+		q = q.Where("order_return_requests.reason_code = ?", filter.ReasonCode)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.OrderReturnRequest
+	err := q.Preload("Order").Preload("User").
+		Order("order_return_requests.created_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&list).Error
+	return list, total, err
+}
+
+// returnGroupColumns maps a ReturnRates groupBy to its key and label expressions.
+var returnGroupColumns = map[string][2]string{
+	"product":  {"p.id::text", "p.name"},
+	"brand":    {"COALESCE(NULLIF(p.brand, ''), '')", "COALESCE(NULLIF(p.brand, ''), '(no brand)')"},
+	"supplier": {"COALESCE(p.supplier_id::text, '')", "COALESCE(s.name, '(no supplier)')"},
+}
+
+func (r *orderReturnRequestRepo) ReturnRates(ctx context.Context, pharmacyID uuid.UUID, groupBy string, from, to time.Time, productIDs []uuid.UUID) ([]*models.ReturnRateRow, error) {
+	cols, ok := returnGroupColumns[groupBy]
+	if !ok {
+		cols = returnGroupColumns["product"]
+	}
+	// A request with no product_ids covers every line of its order.
+	sql := `
+		SELECT ` + cols[0] + ` AS key, ` + cols[1] + ` AS label,
+			COALESCE(SUM(oi.quantity), 0) AS units_sold,
+			COALESCE(SUM(oi.quantity) FILTER (WHERE rr.id IS NOT NULL), 0) AS units_returned,
+			COUNT(DISTINCT rr.id) AS return_requests
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		JOIN products p ON p.id = oi.product_id
+		LEFT JOIN suppliers s ON s.id = p.supplier_id
+		LEFT JOIN order_return_requests rr ON rr.order_id = o.id AND rr.status <> ?
+			AND (COALESCE(rr.product_ids, '') IN ('', '[]', 'null') OR jsonb_exists(rr.product_ids::jsonb, oi.product_id::text))
+		WHERE o.pharmacy_id = ? AND o.deleted_at IS NULL AND o.status = ?
+			AND o.created_at >= ? AND o.created_at < ?`
+	args := []interface{}{models.ReturnRequestStatusRejected, pharmacyID, models.OrderStatusCompleted, from, to}
+	if len(productIDs) > 0 {
+		sql += ` AND oi.product_id IN ?`
+		args = append(args, productIDs)
+	}
+	sql += `
+		GROUP BY 1, 2
+		ORDER BY units_returned DESC, units_sold DESC`
+	var rows []*models.ReturnRateRow
+	err := dbFrom(ctx, r.db).Raw(sql, args...).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *orderReturnRequestRepo) ReasonBreakdown(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.ReturnReasonRow, error) {
+	var rows []*models.ReturnReasonRow
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT COALESCE(rr.reason_code, '') AS reason_code, COUNT(*) AS count
+		FROM order_return_requests rr
+		JOIN orders o ON o.id = rr.order_id
+		WHERE o.pharmacy_id = ? AND o.deleted_at IS NULL AND rr.status <> ?
+			AND rr.created_at >= ? AND rr.created_at < ?
+		GROUP BY 1
+		ORDER BY count DESC`,
+		pharmacyID, models.ReturnRequestStatusRejected, from, to,
+	).Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,209 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type organizationRepo struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) outbound.OrganizationRepository {
+	return &organizationRepo{db: db}
+}
+
+func (r *organizationRepo) Create(ctx context.Context, o *models.Organization, owner *models.OrganizationMember, pharmacyID uuid.UUID) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Pharmacies").Create(o).Error; err != nil {
+			return err
+		}
+		owner.OrganizationID = o.ID
+		if err := tx.Omit("User").Create(owner).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Pharmacy{}).Where("id = ?", pharmacyID).
+			Updates(map[string]interface{}{"organization_id": o.ID, "group_code": o.Code}).Error
+	})
+}
+
+func (r *organizationRepo) first(ctx context.Context, query string, arg interface{}) (*models.Organization, error) {
+	var o models.Organization
+	err := dbFrom(ctx, r.db).Preload("Pharmacies", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name ASC")
+	}).Where(query, arg).First(&o).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *organizationRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Organization, error) {
+	return r.first(ctx, "id = ?", id)
+}
+
+func (r *organizationRepo) GetByCode(ctx context.Context, code string) (*models.Organization, error) {
+	return r.first(ctx, "code = ?", code)
+}
+
+func (r *organizationRepo) GetByJoinCode(ctx context.Context, joinCode string) (*models.Organization, error) {
+	return r.first(ctx, "join_code = ?", joinCode)
+}
+
+func (r *organizationRepo) ListByMember(ctx context.Context, userID uuid.UUID) ([]*models.Organization, error) {
+	var list []*models.Organization
+	err := dbFrom(ctx, r.db).Preload("Pharmacies", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name ASC")
+	}).Where("id IN (SELECT organization_id FROM organization_members WHERE user_id = ?)", userID).
+		Order("name ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *organizationRepo) LinkPharmacy(ctx context.Context, pharmacyID, organizationID uuid.UUID, groupCode string) error {
+	return dbFrom(ctx, r.db).Model(&models.Pharmacy{}).Where("id = ?", pharmacyID).
+		Updates(map[string]interface{}{"organization_id": organizationID, "group_code": groupCode}).Error
+}
+
+func (r *organizationRepo) UnlinkPharmacy(ctx context.Context, pharmacyID, organizationID uuid.UUID) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Pharmacy{}).Where("id = ? AND organization_id = ?", pharmacyID, organizationID).
+			Updates(map[string]interface{}{"organization_id": nil, "group_code": ""}).Error; err != nil {
+			return err
+		}
+		return tx.Where("organization_id = ? AND user_id IN (SELECT id FROM users WHERE pharmacy_id = ?)", organizationID, pharmacyID).
+			Delete(&models.OrganizationMember{}).Error
+	})
+}
+
+func (r *organizationRepo) GetMember(ctx context.Context, organizationID, userID uuid.UUID) (*models.OrganizationMember, error) {
+	var m models.OrganizationMember
+	err := dbFrom(ctx, r.db).Where("organization_id = ? AND user_id = ?", organizationID, userID).First(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *organizationRepo) ListMembers(ctx context.Context, organizationID uuid.UUID) ([]*models.OrganizationMember, error) {
+	var list []*models.OrganizationMember
+	err := dbFrom(ctx, r.db).Preload("User").Where("organization_id = ?", organizationID).
+		Order("CASE WHEN role = 'owner' THEN 0 ELSE 1 END, created_at ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *organizationRepo) AddMember(ctx context.Context, m *models.OrganizationMember) error {
+	return dbFrom(ctx, r.db).Omit("User").Create(m).Error
+}
+
+func (r *organizationRepo) RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error {
+	return dbFrom(ctx, r.db).Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Delete(&models.OrganizationMember{}).Error
+}
+
+func (r *organizationRepo) SalesByPeriod(ctx context.Context, pharmacyIDs []uuid.UUID, granularity string, from, to time.Time) ([]*models.SalesPeriodRow, error) {
+	switch granularity {
+	case "day", "week", "month":
+	default:
+		granularity = "day"
+	}
+	var rows []*models.SalesPeriodRow
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT date_trunc(?, created_at) AS period,
+			COUNT(*) AS orders_count,
+			COALESCE(SUM(sub_total), 0) AS sub_total,
+			COALESCE(SUM(discount_amount), 0) AS discount_amount,
+			COALESCE(SUM(tax_amount), 0) AS tax_amount,
+			COALESCE(SUM(total_amount), 0) AS revenue
+		FROM orders
+		WHERE pharmacy_id IN ? AND deleted_at IS NULL AND status <> ?
+			AND created_at >= ? AND created_at < ?
+		GROUP BY period
+		ORDER BY period ASC`,
+		granularity, pharmacyIDs, models.OrderStatusCancelled, from, to,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *organizationRepo) SalesByTenant(ctx context.Context, pharmacyIDs []uuid.UUID, from, to time.Time) ([]*models.OrgTenantSalesRow, error) {
+	var rows []*models.OrgTenantSalesRow
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT ph.id AS pharmacy_id, ph.name AS pharmacy_name,
+			COUNT(o.id) AS orders_count,
+			COALESCE(SUM(o.total_amount), 0) AS revenue
+		FROM pharmacies ph
+		LEFT JOIN orders o ON o.pharmacy_id = ph.id AND o.deleted_at IS NULL AND o.status <> ?
+			AND o.created_at >= ? AND o.created_at < ?
+		WHERE ph.id IN ?
+		GROUP BY ph.id, ph.name
+		ORDER BY revenue DESC, ph.name ASC`,
+		models.OrderStatusCancelled, from, to, pharmacyIDs,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *organizationRepo) TopProducts(ctx context.Context, pharmacyIDs []uuid.UUID, from, to time.Time, limit int) ([]*models.OrgTopProductRow, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	var rows []*models.OrgTopProductRow
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT MIN(p.sku) AS sku, MIN(p.name) AS name,
+			SUM(oi.quantity) AS quantity_sold,
+			COALESCE(SUM(oi.total_price), 0) AS revenue,
+			COUNT(DISTINCT o.id) AS orders_count,
+			COUNT(DISTINCT o.pharmacy_id) AS tenants
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		JOIN products p ON p.id = oi.product_id
+		WHERE o.pharmacy_id IN ? AND o.deleted_at IS NULL AND o.status <> ?
+			AND o.created_at >= ? AND o.created_at < ?
+		GROUP BY COALESCE(NULLIF(p.sku, ''), LOWER(p.name))
+		ORDER BY quantity_sold DESC, revenue DESC
+		LIMIT ?`,
+		pharmacyIDs, models.OrderStatusCancelled, from, to, limit,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *organizationRepo) Stock(ctx context.Context, pharmacyIDs []uuid.UUID, query string, today time.Time, limit int) ([]*models.OrgStockRow, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	like := "%" + query + "%"
+	var rows []*models.OrgStockRow
+	err := dbFrom(ctx, r.db).Raw(`
+		WITH stock AS (
+			SELECT COALESCE(NULLIF(p.sku, ''), LOWER(p.name)) AS product_key, p.sku, p.name,
+				b.pharmacy_id, b.branch_id, SUM(b.quantity) AS quantity, SUM(b.quantity) * p.unit_price AS value
+			FROM inventory_batches b
+			JOIN products p ON p.id = b.product_id
+			WHERE b.pharmacy_id IN ? AND b.quantity > 0 AND (b.expiry_date IS NULL OR b.expiry_date >= ?)
+				AND p.deleted_at IS NULL AND p.is_active = true
+				AND (? = '' OR p.name ILIKE ? OR p.sku ILIKE ?)
+			GROUP BY p.id, p.sku, p.name, p.unit_price, b.pharmacy_id, b.branch_id
+		), top AS (
+			SELECT product_key FROM stock GROUP BY product_key ORDER BY SUM(quantity) DESC, product_key ASC LIMIT ?
+		)
+		SELECT s.sku, s.name, s.pharmacy_id, ph.name AS pharmacy_name, s.branch_id, COALESCE(br.name, '') AS branch_name,
+			s.quantity, s.value
+		FROM stock s
+		JOIN top t ON t.product_key = s.product_key
+		JOIN pharmacies ph ON ph.id = s.pharmacy_id
+		LEFT JOIN branches br ON br.id = s.branch_id
+		ORDER BY s.product_key ASC, ph.name ASC, branch_name ASC`,
+		pharmacyIDs, today, query, like, like, limit,
+	).Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,50 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type otpRepo struct {
+	db *gorm.DB
+}
+
+func NewOTPRepository(db *gorm.DB) outbound.OTPRepository {
+	return &otpRepo{db: db}
+}
+
+func (r *otpRepo) Create(ctx context.Context, o *models.OTPCode) error {
+	return dbFrom(ctx, r.db).Create(o).Error
+}
+
+func (r *otpRepo) GetLatest(ctx context.Context, pharmacyID uuid.UUID, phone, purpose string) (*models.OTPCode, error) {
+	var o models.OTPCode
+	err := dbFrom(ctx, r.db).
+		Where("pharmacy_id = ? AND phone = ? AND purpose = ? AND consumed_at IS NULL", pharmacyID, phone, purpose).
+		Order("created_at DESC").First(&o).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *otpRepo) CountSince(ctx context.Context, pharmacyID uuid.UUID, phone string, since time.Time) (int64, error) {
+	var n int64
+	err := dbFrom(ctx, r.db).Model(&models.OTPCode{}).
+		Where("pharmacy_id = ? AND phone = ? AND created_at >= ?", pharmacyID, phone, since).
+		Count(&n).Error
+	return n, err
+}
+
+func (r *otpRepo) Update(ctx context.Context, o *models.OTPCode) error {
+	return dbFrom(ctx, r.db).Save(o).Error
+}
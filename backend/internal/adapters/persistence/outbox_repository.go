@@ -0,0 +1,78 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type outboxRepo struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) outbound.OutboxRepository {
+	return &outboxRepo{db: db}
+}
+
+// appendOutbox inserts events with tx so they commit or roll back together with the caller's change.
+func appendOutbox(tx *gorm.DB, events []*models.OutboxEvent) error {
+	for _, e := range events {
+		if e == nil {
+			continue
+		}
+		if err := tx.Create(e).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *outboxRepo) Append(ctx context.Context, events ...*models.OutboxEvent) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return appendOutbox(tx, events)
+	})
+}
+
+// ClaimDue locks due rows with FOR UPDATE SKIP LOCKED so dispatchers on several instances split the outbox.
+func (r *outboxRepo) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.OutboxEvent, error) {
+	var events []*models.OutboxEvent
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?)",
+				models.OutboxStatusPending, now, models.OutboxStatusRunning, now).
+			Order("created_at ASC").Limit(limit).Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		ids := make([]uuid.UUID, len(events))
+		lockedUntil := now.Add(lease)
+		for i, e := range events {
+			ids[i] = e.ID
+			e.Status = models.OutboxStatusRunning
+			e.LockedUntil = &lockedUntil
+		}
+		return tx.Model(&models.OutboxEvent{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":       models.OutboxStatusRunning,
+			"locked_until": lockedUntil,
+			"updated_at":   now,
+		}).Error
+	})
+	return events, err
+}
+
+func (r *outboxRepo) Update(ctx context.Context, e *models.OutboxEvent) error {
+	return dbFrom(ctx, r.db).Save(e).Error
+}
+
+func (r *outboxRepo) DeleteDispatchedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	res := dbFrom(ctx, r.db).Where("status = ? AND dispatched_at < ?", models.OutboxStatusDone, cutoff).
+		Delete(&models.OutboxEvent{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,59 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type passwordResetTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetTokenRepository(db *gorm.DB) outbound.PasswordResetTokenRepository {
+	return &passwordResetTokenRepo{db: db}
+}
+
+func (r *passwordResetTokenRepo) Create(ctx context.Context, t *models.PasswordResetToken) error {
+	return dbFrom(ctx, r.db).Create(t).Error
+}
+
+func (r *passwordResetTokenRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	var t models.PasswordResetToken
+	if err := dbFrom(ctx, r.db).First(&t, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *passwordResetTokenRepo) CountSince(ctx context.Context, userID uuid.UUID, since time.Time) (int64, error) {
+	var n int64
+	err := dbFrom(ctx, r.db).Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&n).Error
+	return n, err
+}
+
+func (r *passwordResetTokenRepo) Claim(ctx context.Context, id uuid.UUID, usedAt time.Time) (bool, error) {
+	res := dbFrom(ctx, r.db).Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", usedAt)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *passwordResetTokenRepo) MarkAllUsed(ctx context.Context, userID uuid.UUID, usedAt time.Time) error {
+	return dbFrom(ctx, r.db).Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", usedAt).Error
+}
@@ -18,12 +18,12 @@ func NewPaymentGatewayRepository(db *gorm.DB) outbound.PaymentGatewayRepository
 }
 
 func (r *paymentGatewayRepository) Create(ctx context.Context, pg *models.PaymentGateway) error {
-	return r.db.WithContext(ctx).Create(pg).Error
+	return dbFrom(ctx, r.db).Create(pg).Error
 }
 
 func (r *paymentGatewayRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PaymentGateway, error) {
 	var pg models.PaymentGateway
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&pg).Error
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&pg).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +32,7 @@ func (r *paymentGatewayRepository) GetByID(ctx context.Context, id uuid.UUID) (*
 
 func (r *paymentGatewayRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, activeOnly bool) ([]*models.PaymentGateway, error) {
 	var list []*models.PaymentGateway
-	q := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID)
+	q := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID)
 	if activeOnly {
 		q = q.Where("is_active = ?", true)
 	}
@@ -41,9 +41,9 @@ func (r *paymentGatewayRepository) ListByPharmacy(ctx context.Context, pharmacyI
 }
 
 func (r *paymentGatewayRepository) Update(ctx context.Context, pg *models.PaymentGateway) error {
-	return r.db.WithContext(ctx).Save(pg).Error
+	return dbFrom(ctx, r.db).Save(pg).Error
 }
 
 func (r *paymentGatewayRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.PaymentGateway{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.PaymentGateway{}, "id = ?", id).Error
 }
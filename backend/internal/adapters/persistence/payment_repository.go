@@ -18,12 +18,12 @@ func NewPaymentRepository(db *gorm.DB) outbound.PaymentRepository {
 }
 
 func (r *paymentRepo) Create(ctx context.Context, p *models.Payment) error {
-	return r.db.WithContext(ctx).Create(p).Error
+	return dbFrom(ctx, r.db).Create(p).Error
 }
 
 func (r *paymentRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Payment, error) {
 	var p models.Payment
-	err := r.db.WithContext(ctx).First(&p, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).First(&p, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,16 +32,21 @@ func (r *paymentRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Paymen
 
 func (r *paymentRepo) ListByOrderID(ctx context.Context, orderID uuid.UUID) ([]*models.Payment, error) {
 	var list []*models.Payment
-	err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Find(&list).Error
+	err := dbFrom(ctx, r.db).Where("order_id = ?", orderID).Find(&list).Error
 	return list, err
 }
 
 func (r *paymentRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Payment, error) {
 	var list []*models.Payment
-	err := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID).Order("created_at DESC").Find(&list).Error
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).Order("created_at DESC").Find(&list).Error
 	return list, err
 }
 
-func (r *paymentRepo) Update(ctx context.Context, p *models.Payment) error {
-	return r.db.WithContext(ctx).Save(p).Error
+func (r *paymentRepo) Update(ctx context.Context, p *models.Payment, events ...*models.OutboxEvent) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(p).Error; err != nil {
+			return err
+		}
+		return appendOutbox(tx, events)
+	})
 }
@@ -19,7 +19,7 @@ func NewPharmacyConfigRepository(db *gorm.DB) outbound.PharmacyConfigRepository
 
 func (r *pharmacyConfigRepo) GetByPharmacyID(ctx context.Context, pharmacyID uuid.UUID) (*models.PharmacyConfig, error) {
 	var c models.PharmacyConfig
-	err := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID).First(&c).Error
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).First(&c).Error
 	if err != nil {
 		return nil, err
 	}
@@ -27,9 +27,9 @@ func (r *pharmacyConfigRepo) GetByPharmacyID(ctx context.Context, pharmacyID uui
 }
 
 func (r *pharmacyConfigRepo) Create(ctx context.Context, c *models.PharmacyConfig) error {
-	return r.db.WithContext(ctx).Create(c).Error
+	return dbFrom(ctx, r.db).Create(c).Error
 }
 
 func (r *pharmacyConfigRepo) Update(ctx context.Context, c *models.PharmacyConfig) error {
-	return r.db.WithContext(ctx).Save(c).Error
+	return dbFrom(ctx, r.db).Save(c).Error
 }
@@ -0,0 +1,56 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type pharmacyConfigVersionRepo struct {
+	db *gorm.DB
+}
+
+func NewPharmacyConfigVersionRepository(db *gorm.DB) outbound.PharmacyConfigVersionRepository {
+	return &pharmacyConfigVersionRepo{db: db}
+}
+
+func (r *pharmacyConfigVersionRepo) Create(ctx context.Context, v *models.PharmacyConfigVersion) error {
+	return dbFrom(ctx, r.db).Create(v).Error
+}
+
+func (r *pharmacyConfigVersionRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.PharmacyConfigVersion, int64, error) {
+	q := dbFrom(ctx, r.db).Model(&models.PharmacyConfigVersion{}).Where("pharmacy_id = ?", pharmacyID)
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if limit > 0 {
+		q = q.Limit(limit).Offset(offset)
+	}
+	var list []*models.PharmacyConfigVersion
+	err := q.Order("version DESC").Find(&list).Error
+	return list, total, err
+}
+
+func (r *pharmacyConfigVersionRepo) GetByVersion(ctx context.Context, pharmacyID uuid.UUID, version int) (*models.PharmacyConfigVersion, error) {
+	var v models.PharmacyConfigVersion
+	if err := dbFrom(ctx, r.db).First(&v, "pharmacy_id = ? AND version = ?", pharmacyID, version).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *pharmacyConfigVersionRepo) LatestVersion(ctx context.Context, pharmacyID uuid.UUID) (int, error) {
+	var latest int
+	err := dbFrom(ctx, r.db).Model(&models.PharmacyConfigVersion{}).
+		Where("pharmacy_id = ?", pharmacyID).
+		Select("COALESCE(MAX(version), 0)").Scan(&latest).Error
+	return latest, err
+}
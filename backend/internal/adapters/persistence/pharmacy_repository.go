@@ -2,6 +2,7 @@ package persistence
 
 import (
 	"context"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
@@ -18,12 +19,12 @@ func NewPharmacyRepository(db *gorm.DB) outbound.PharmacyRepository {
 }
 
 func (r *pharmacyRepo) Create(ctx context.Context, p *models.Pharmacy) error {
-	return r.db.WithContext(ctx).Create(p).Error
+	return dbFrom(ctx, r.db).Create(p).Error
 }
 
 func (r *pharmacyRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Pharmacy, error) {
 	var p models.Pharmacy
-	err := r.db.WithContext(ctx).First(&p, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).First(&p, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +33,7 @@ func (r *pharmacyRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Pharm
 
 func (r *pharmacyRepo) GetByHostnameSlug(ctx context.Context, hostnameSlug string) (*models.Pharmacy, error) {
 	var p models.Pharmacy
-	err := r.db.WithContext(ctx).Where("hostname_slug = ? AND is_active = ?", hostnameSlug, true).First(&p).Error
+	err := dbFrom(ctx, r.db).Where("hostname_slug = ? AND is_active = ?", hostnameSlug, true).First(&p).Error
 	if err != nil {
 		return nil, err
 	}
@@ -40,11 +41,65 @@ func (r *pharmacyRepo) GetByHostnameSlug(ctx context.Context, hostnameSlug strin
 }
 
 func (r *pharmacyRepo) Update(ctx context.Context, p *models.Pharmacy) error {
-	return r.db.WithContext(ctx).Save(p).Error
+	return dbFrom(ctx, r.db).Save(p).Error
 }
 
 func (r *pharmacyRepo) List(ctx context.Context) ([]*models.Pharmacy, error) {
 	var list []*models.Pharmacy
-	err := r.db.WithContext(ctx).Find(&list).Error
+	err := dbFrom(ctx, r.db).Find(&list).Error
 	return list, err
 }
+
+func (r *pharmacyRepo) ListByStatus(ctx context.Context, status string) ([]*models.Pharmacy, error) {
+	var list []*models.Pharmacy
+	err := dbFrom(ctx, r.db).Where("status = ?", status).Order("created_at ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *pharmacyRepo) Taken(ctx context.Context, licenseNo, hostnameSlug string) (bool, error) {
+	var n int64
+	err := dbFrom(ctx, r.db).Unscoped().Model(&models.Pharmacy{}).
+		Where("license_no = ? OR hostname_slug = ? OR tenant_code = ?", licenseNo, hostnameSlug, hostnameSlug).
+		Count(&n).Error
+	return n > 0, err
+}
+
+func (r *pharmacyRepo) ListUsage(ctx context.Context) ([]*models.TenantUsage, error) {
+	db := dbFrom(ctx, r.db)
+	var pharmacies []*models.Pharmacy
+	if err := db.Order("created_at DESC").Find(&pharmacies).Error; err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		PharmacyID   uuid.UUID
+		Orders       int64
+		LastOrderAt  *time.Time
+		Users        int64
+		StorageBytes int64
+	}
+	err := db.Raw(`
+		SELECT p.id AS pharmacy_id,
+			(SELECT COUNT(*) FROM orders o WHERE o.pharmacy_id = p.id AND o.deleted_at IS NULL) AS orders,
+			(SELECT MAX(o.created_at) FROM orders o WHERE o.pharmacy_id = p.id AND o.deleted_at IS NULL) AS last_order_at,
+			(SELECT COUNT(*) FROM users u WHERE u.pharmacy_id = p.id AND u.deleted_at IS NULL) AS users,
+			(SELECT COALESCE(SUM(up.size), 0) FROM uploads up WHERE up.pharmacy_id = p.id AND up.status IN ?) AS storage_bytes
+		FROM pharmacies p WHERE p.deleted_at IS NULL`,
+		[]string{models.UploadStatusPending, models.UploadStatusCompleted}).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	usage := make(map[uuid.UUID]*models.TenantUsage, len(rows))
+	for _, row := range rows {
+		usage[row.PharmacyID] = &models.TenantUsage{Orders: row.Orders, LastOrderAt: row.LastOrderAt, Users: row.Users, StorageBytes: row.StorageBytes}
+	}
+	list := make([]*models.TenantUsage, 0, len(pharmacies))
+	for _, p := range pharmacies {
+		u := usage[p.ID]
+		if u == nil {
+			u = &models.TenantUsage{}
+		}
+		u.Pharmacy = p
+		list = append(list, u)
+	}
+	return list, nil
+}
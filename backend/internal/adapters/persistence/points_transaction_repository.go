@@ -18,12 +18,12 @@ func NewPointsTransactionRepository(db *gorm.DB) outbound.PointsTransactionRepos
 }
 
 func (r *pointsTransactionRepo) Create(ctx context.Context, p *models.PointsTransaction) error {
-	return r.db.WithContext(ctx).Create(p).Error
+	return dbFrom(ctx, r.db).Create(p).Error
 }
 
 func (r *pointsTransactionRepo) ListByCustomer(ctx context.Context, customerID uuid.UUID, limit, offset int) ([]*models.PointsTransaction, error) {
 	var list []*models.PointsTransaction
-	q := r.db.WithContext(ctx).Where("customer_id = ?", customerID).Order("created_at DESC")
+	q := dbFrom(ctx, r.db).Where("customer_id = ?", customerID).Order("created_at DESC")
 	if limit > 0 {
 		q = q.Limit(limit)
 	}
@@ -0,0 +1,73 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type preorderRepo struct {
+	db *gorm.DB
+}
+
+func NewPreorderRepository(db *gorm.DB) outbound.PreorderRepository {
+	return &preorderRepo{db: db}
+}
+
+func (r *preorderRepo) Create(ctx context.Context, p *models.Preorder) error {
+	return dbFrom(ctx, r.db).Create(p).Error
+}
+
+func (r *preorderRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Preorder, error) {
+	var p models.Preorder
+	err := dbFrom(ctx, r.db).Preload("Product").First(&p, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *preorderRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, status *models.PreorderStatus, productID, createdBy *uuid.UUID, limit, offset int) ([]*models.Preorder, int64, error) {
+	scope := func() *gorm.DB {
+		q := dbFrom(ctx, r.db).Model(&models.Preorder{}).Where("pharmacy_id = ?", pharmacyID)
+		if status != nil && *status != "" {
+			q = q.Where("status = ?", *status)
+		}
+		if productID != nil {
+			q = q.Where("product_id = ?", *productID)
+		}
+		if createdBy != nil {
+			q = q.Where("created_by = ?", *createdBy)
+		}
+		return q
+	}
+	var total int64
+	if err := scope().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.Preorder
+	q := scope().Preload("Product").Order("created_at DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	err := q.Find(&list).Error
+	return list, total, err
+}
+
+func (r *preorderRepo) ListPendingByProduct(ctx context.Context, productID uuid.UUID) ([]*models.Preorder, error) {
+	var list []*models.Preorder
+	err := dbFrom(ctx, r.db).Preload("Product").
+		Where("product_id = ? AND status = ?", productID, models.PreorderStatusPending).
+		Order("created_at ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *preorderRepo) Update(ctx context.Context, p *models.Preorder) error {
+	return dbFrom(ctx, r.db).Omit("Product").Save(p).Error
+}
@@ -18,12 +18,12 @@ func NewProductImageRepository(db *gorm.DB) outbound.ProductImageRepository {
 }
 
 func (r *productImageRepo) Create(ctx context.Context, img *models.ProductImage) error {
-	return r.db.WithContext(ctx).Create(img).Error
+	return dbFrom(ctx, r.db).Create(img).Error
 }
 
 func (r *productImageRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.ProductImage, error) {
 	var img models.ProductImage
-	err := r.db.WithContext(ctx).First(&img, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).First(&img, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,14 +32,14 @@ func (r *productImageRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.P
 
 func (r *productImageRepo) ListByProductID(ctx context.Context, productID uuid.UUID) ([]*models.ProductImage, error) {
 	var list []*models.ProductImage
-	err := r.db.WithContext(ctx).Where("product_id = ?", productID).Order("sort_order ASC, created_at ASC").Find(&list).Error
+	err := dbFrom(ctx, r.db).Where("product_id = ?", productID).Order("sort_order ASC, created_at ASC").Find(&list).Error
 	return list, err
 }
 
 func (r *productImageRepo) Update(ctx context.Context, img *models.ProductImage) error {
-	return r.db.WithContext(ctx).Save(img).Error
+	return dbFrom(ctx, r.db).Save(img).Error
 }
 
 func (r *productImageRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.ProductImage{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.ProductImage{}, "id = ?", id).Error
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
@@ -20,12 +21,12 @@ func NewProductRepository(db *gorm.DB) outbound.ProductRepository {
 }
 
 func (r *productRepo) Create(ctx context.Context, p *models.Product) error {
-	return r.db.WithContext(ctx).Create(p).Error
+	return dbFrom(ctx, r.db).Create(p).Error
 }
 
 func (r *productRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Product, error) {
 	var p models.Product
-	err := r.db.WithContext(ctx).Preload("Images").Preload("CategoryDetail.Parent").First(&p, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).Preload("Images").Preload("CategoryDetail.Parent").First(&p, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -34,7 +35,7 @@ func (r *productRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Produc
 
 func (r *productRepo) GetBySKU(ctx context.Context, pharmacyID uuid.UUID, sku string) (*models.Product, error) {
 	var p models.Product
-	err := r.db.WithContext(ctx).Where("pharmacy_id = ? AND sku = ?", pharmacyID, sku).First(&p).Error
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ? AND sku = ?", pharmacyID, sku).First(&p).Error
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +47,7 @@ func (r *productRepo) GetByBarcode(ctx context.Context, pharmacyID uuid.UUID, ba
 		return nil, gorm.ErrRecordNotFound
 	}
 	var p models.Product
-	err := r.db.WithContext(ctx).Preload("Images").Preload("CategoryDetail.Parent").Where("pharmacy_id = ? AND barcode = ?", pharmacyID, barcode).First(&p).Error
+	err := dbFrom(ctx, r.db).Preload("Images").Preload("CategoryDetail.Parent").Where("pharmacy_id = ? AND barcode = ?", pharmacyID, barcode).First(&p).Error
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +60,7 @@ func (r *productRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID,
 }
 
 func (r *productRepo) ListByPharmacyPaginated(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, limit, offset int) ([]*models.Product, int64, error) {
-	q := r.db.WithContext(ctx).Model(&models.Product{}).Where("pharmacy_id = ?", pharmacyID)
+	q := dbFrom(ctx, r.db).Model(&models.Product{}).Where("pharmacy_id = ?", pharmacyID)
 	if category != nil && *category != "" {
 		q = q.Where("category = ?", *category)
 	}
@@ -70,7 +71,7 @@ func (r *productRepo) ListByPharmacyPaginated(ctx context.Context, pharmacyID uu
 	if err := q.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
-	query := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID)
+	query := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID)
 	if category != nil && *category != "" {
 		query = query.Where("category = ?", *category)
 	}
@@ -88,9 +89,22 @@ func (r *productRepo) ListByPharmacyPaginated(ctx context.Context, pharmacyID uu
 	return list, total, err
 }
 
-func (r *productRepo) ListByPharmacyCatalog(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, searchQ string, sort outbound.CatalogSort, limit, offset int, filters *outbound.CatalogFilters) ([]*models.Product, int64, error) {
-	q := r.db.WithContext(ctx).Model(&models.Product{}).Where("pharmacy_id = ? AND is_active = ?", pharmacyID, true)
-	if category != nil && *category != "" {
+// catalogFacet names a catalog filter that a facet query leaves out, so its own values stay selectable.
+type catalogFacet int
+
+const (
+	facetNone catalogFacet = iota
+	facetCategory
+	facetBrand
+	facetDosageForm
+	facetPrice
+	facetHashtag
+)
+
+// catalogScope applies the public catalog selection (active products of the pharmacy) to q, except the skip filter.
+func catalogScope(q *gorm.DB, pharmacyID uuid.UUID, category *string, inStockOnly *bool, searchQ string, filters *outbound.CatalogFilters, skip catalogFacet) *gorm.DB {
+	q = q.Where("products.pharmacy_id = ? AND products.is_active = ? AND products.deleted_at IS NULL", pharmacyID, true)
+	if skip != facetCategory && category != nil && *category != "" {
 		q = q.Where("category = ?", *category)
 	}
 	if inStockOnly != nil && *inStockOnly {
@@ -103,50 +117,45 @@ func (r *productRepo) ListByPharmacyCatalog(ctx context.Context, pharmacyID uuid
 			term, term, term, term, term,
 		)
 	}
-	if filters != nil {
-		if filters.Hashtag != nil && *filters.Hashtag != "" {
-			hashtagArr, _ := json.Marshal([]string{*filters.Hashtag})
-			q = q.Where("hashtags @> ?::jsonb", string(hashtagArr))
-		}
-		if filters.Brand != nil && *filters.Brand != "" {
-			q = q.Where("brand ILIKE ?", "%"+*filters.Brand+"%")
-		}
-		if filters.LabelKey != nil && *filters.LabelKey != "" && filters.LabelValue != nil {
-			labelJSON, _ := json.Marshal(map[string]string{*filters.LabelKey: *filters.LabelValue})
-			q = q.Where("labels @> ?::jsonb", string(labelJSON))
-		}
+	if filters == nil {
+		return q
 	}
-	var total int64
-	if err := q.Count(&total).Error; err != nil {
-		return nil, 0, err
+	if skip != facetHashtag && filters.Hashtag != nil && *filters.Hashtag != "" {
+		hashtagArr, _ := json.Marshal([]string{*filters.Hashtag})
+		q = q.Where("hashtags @> ?::jsonb", string(hashtagArr))
 	}
-	query := r.db.WithContext(ctx).Where("pharmacy_id = ? AND is_active = ?", pharmacyID, true)
-	if category != nil && *category != "" {
-		query = query.Where("category = ?", *category)
+	if skip != facetBrand && filters.Brand != nil && *filters.Brand != "" {
+		q = q.Where("brand ILIKE ?", "%"+*filters.Brand+"%")
 	}
-	if inStockOnly != nil && *inStockOnly {
-		query = query.Where("stock_quantity > 0")
+	if filters.LabelKey != nil && *filters.LabelKey != "" && filters.LabelValue != nil {
+		labelJSON, _ := json.Marshal(map[string]string{*filters.LabelKey: *filters.LabelValue})
+		q = q.Where("labels @> ?::jsonb", string(labelJSON))
 	}
-	if searchQ != "" {
-		term := "%" + strings.TrimSpace(searchQ) + "%"
-		query = query.Where(
-			"name ILIKE ? OR description ILIKE ? OR sku ILIKE ? OR brand ILIKE ? OR generic_name ILIKE ?",
-			term, term, term, term, term,
-		)
+	if len(filters.Attributes) > 0 {
+		attrJSON, _ := json.Marshal(filters.Attributes)
+		q = q.Where("attributes @> ?::jsonb", string(attrJSON)) // served by the GIN index on attributes
 	}
-	if filters != nil {
-		if filters.Hashtag != nil && *filters.Hashtag != "" {
-			hashtagArr, _ := json.Marshal([]string{*filters.Hashtag})
-			query = query.Where("hashtags @> ?::jsonb", string(hashtagArr))
-		}
-		if filters.Brand != nil && *filters.Brand != "" {
-			query = query.Where("brand ILIKE ?", "%"+*filters.Brand+"%")
+	if skip != facetDosageForm && filters.DosageForm != nil && *filters.DosageForm != "" {
+		q = q.Where("LOWER(dosage_form) = LOWER(?)", *filters.DosageForm)
+	}
+	if skip != facetPrice {
+		if filters.MinPrice != nil {
+			q = q.Where("unit_price >= ?", *filters.MinPrice)
 		}
-		if filters.LabelKey != nil && *filters.LabelKey != "" && filters.LabelValue != nil {
-			labelJSON, _ := json.Marshal(map[string]string{*filters.LabelKey: *filters.LabelValue})
-			query = query.Where("labels @> ?::jsonb", string(labelJSON))
+		if filters.MaxPrice != nil {
+			q = q.Where("unit_price < ?", *filters.MaxPrice)
 		}
 	}
+	return q
+}
+
+func (r *productRepo) ListByPharmacyCatalog(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, searchQ string, sort outbound.CatalogSort, limit, offset int, filters *outbound.CatalogFilters) ([]*models.Product, int64, error) {
+	q := catalogScope(dbFrom(ctx, r.db).Model(&models.Product{}), pharmacyID, category, inStockOnly, searchQ, filters, facetNone)
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	query := catalogScope(dbFrom(ctx, r.db), pharmacyID, category, inStockOnly, searchQ, filters, facetNone)
 	switch sort {
 	case outbound.CatalogSortPriceAsc:
 		query = query.Order("unit_price ASC, name ASC")
@@ -168,10 +177,205 @@ func (r *productRepo) ListByPharmacyCatalog(ctx context.Context, pharmacyID uuid
 	return list, total, err
 }
 
+func (r *productRepo) CatalogFacets(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, searchQ string, filters *outbound.CatalogFilters, priceEdges []float64, facetLimit int) (*models.CatalogFacets, error) {
+	scope := func(skip catalogFacet) *gorm.DB {
+		return catalogScope(dbFrom(ctx, r.db).Table("products"), pharmacyID, category, inStockOnly, searchQ, filters, skip)
+	}
+	out := &models.CatalogFacets{}
+	if err := scope(facetNone).Count(&out.Total).Error; err != nil {
+		return nil, err
+	}
+	// Text facets: the trimmed value, empty values dropped, most common first.
+	text := []struct {
+		skip catalogFacet
+		expr string
+		dst  *[]models.FacetCount
+	}{
+		{facetCategory, "TRIM(category)", &out.Categories},
+		{facetBrand, "TRIM(brand)", &out.Brands},
+		{facetDosageForm, "LOWER(TRIM(dosage_form))", &out.DosageForms},
+	}
+	for _, f := range text {
+		rows := []models.FacetCount{}
+		err := scope(f.skip).
+			Select(f.expr + " AS value, COUNT(*) AS count").
+			Where(f.expr + " <> ''").
+			Group(f.expr).
+			Order("count DESC, value ASC").
+			Limit(facetLimit).
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		*f.dst = rows
+	}
+
+	out.Hashtags = []models.FacetCount{}
+	err := scope(facetHashtag).
+		Joins("CROSS JOIN LATERAL jsonb_array_elements_text(CASE WHEN jsonb_typeof(products.hashtags) = 'array' THEN products.hashtags ELSE '[]'::jsonb END) AS tag(value)").
+		Select("tag.value AS value, COUNT(*) AS count").
+		Group("tag.value").
+		Order("count DESC, value ASC").
+		Limit(facetLimit).
+		Scan(&out.Hashtags).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// width_bucket gives 0 below the first edge and len(priceEdges) at or above the last.
+	out.PriceBuckets = make([]models.PriceBucketCount, len(priceEdges)+1)
+	for i := range out.PriceBuckets {
+		if i > 0 {
+			out.PriceBuckets[i].Min = priceEdges[i-1]
+		}
+		if i < len(priceEdges) {
+			upper := priceEdges[i]
+			out.PriceBuckets[i].Max = &upper
+		}
+	}
+	if len(priceEdges) == 0 {
+		out.PriceBuckets[0].Count = out.Total
+		return out, nil
+	}
+	args := make([]interface{}, len(priceEdges))
+	for i, e := range priceEdges {
+		args[i] = e
+	}
+	bucket := "width_bucket(unit_price, ARRAY[" + strings.TrimSuffix(strings.Repeat("?,", len(priceEdges)), ",") + "]::numeric[])"
+	var rows []struct {
+		Bucket int
+		Count  int64
+	}
+	err = scope(facetPrice).
+		Select(bucket+" AS bucket, COUNT(*) AS count", args...).
+		Group("bucket").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if row.Bucket >= 0 && row.Bucket < len(out.PriceBuckets) {
+			out.PriceBuckets[row.Bucket].Count = row.Count
+		}
+	}
+	return out, nil
+}
+
+func (r *productRepo) ListLowStock(ctx context.Context, pharmacyID uuid.UUID, threshold int) ([]*models.Product, error) {
+	var list []*models.Product
+	err := dbFrom(ctx, r.db).Preload("Supplier").
+		Where("pharmacy_id = ? AND is_active = ? AND stock_quantity <= ?", pharmacyID, true, threshold).
+		Order("stock_quantity ASC, name ASC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *productRepo) ListBelowReorderLevel(ctx context.Context, pharmacyID uuid.UUID, defaultLevel int) ([]*models.Product, error) {
+	var list []*models.Product
+	err := dbFrom(ctx, r.db).Preload("Supplier").
+		Where("pharmacy_id = ? AND is_active = ? AND stock_quantity <= COALESCE(reorder_level, ?)", pharmacyID, true, defaultLevel).
+		Order("stock_quantity ASC, name ASC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *productRepo) UnitsSold(ctx context.Context, pharmacyID uuid.UUID, since time.Time) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		ProductID uuid.UUID
+		Units     int
+	}
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT oi.product_id, COALESCE(SUM(oi.quantity), 0) AS units
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		WHERE o.pharmacy_id = ? AND o.deleted_at IS NULL AND o.status = ? AND o.created_at >= ?
+		GROUP BY oi.product_id`, pharmacyID, models.OrderStatusCompleted, since).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		out[row.ProductID] = row.Units
+	}
+	return out, nil
+}
+
+func (r *productRepo) SyncCategoryName(ctx context.Context, categoryID uuid.UUID, name string) (int64, error) {
+	res := dbFrom(ctx, r.db).Model(&models.Product{}).
+		Where("category_id = ? AND category IS DISTINCT FROM ?", categoryID, name).
+		Update("category", name)
+	return res.RowsAffected, res.Error
+}
+
+func (r *productRepo) RenameBrand(ctx context.Context, pharmacyID uuid.UUID, from, to string) (int64, error) {
+	res := dbFrom(ctx, r.db).Model(&models.Product{}).
+		Where("pharmacy_id = ? AND LOWER(TRIM(brand)) = LOWER(?) AND brand <> ?", pharmacyID, strings.TrimSpace(from), to).
+		Update("brand", to)
+	return res.RowsAffected, res.Error
+}
+
+// alternativeOf matches products "a" that can replace products "p": same pharmacy and generic, same dosage form,
+// active, in stock and cheaper. unit_price is already the sale price when a discount is set.
+const alternativeOf = `a.pharmacy_id = p.pharmacy_id AND a.id <> p.id AND a.deleted_at IS NULL
+	AND a.is_active AND a.stock_quantity > 0
+	AND LOWER(TRIM(a.generic_name)) = LOWER(TRIM(p.generic_name))
+	AND LOWER(TRIM(COALESCE(a.dosage_form, ''))) = LOWER(TRIM(COALESCE(p.dosage_form, '')))
+	AND a.unit_price < p.unit_price`
+
+func (r *productRepo) ListAlternatives(ctx context.Context, p *models.Product, limit int) ([]*models.Product, error) {
+	var list []*models.Product
+	if strings.TrimSpace(p.GenericName) == "" {
+		return list, nil
+	}
+	err := dbFrom(ctx, r.db).Table("products AS a").Select("a.*").
+		Joins("JOIN products p ON p.id = ?", p.ID).
+		Where(alternativeOf).
+		Order("a.unit_price, a.name").Limit(limit).
+		Preload("Images").Find(&list).Error
+	return list, err
+}
+
+func (r *productRepo) WithAlternatives(ctx context.Context, productIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if len(productIDs) == 0 {
+		return ids, nil
+	}
+	err := dbFrom(ctx, r.db).Table("products AS p").
+		Where("p.id IN ? AND TRIM(COALESCE(p.generic_name, '')) <> ''", productIDs).
+		Where("EXISTS (SELECT 1 FROM products a WHERE "+alternativeOf+")").
+		Pluck("p.id", &ids).Error
+	return ids, err
+}
+
 func (r *productRepo) Update(ctx context.Context, p *models.Product) error {
-	return r.db.WithContext(ctx).Save(p).Error
+	return dbFrom(ctx, r.db).Omit("stock_quantity").Save(p).Error
+}
+
+func (r *productRepo) AdjustStock(ctx context.Context, id uuid.UUID, delta int) (bool, error) {
+	res := execScoped(ctx, dbFrom(ctx, r.db), "UPDATE products SET stock_quantity = stock_quantity + ?, updated_at = NOW() WHERE id = ? AND stock_quantity + ? >= 0",
+		delta, id, delta)
+	return res.RowsAffected == 1, res.Error
 }
 
 func (r *productRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.Product{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.Product{}, "id = ?", id).Error
+}
+
+func (r *productRepo) ListChangedSince(ctx context.Context, pharmacyID uuid.UUID, since time.Time, afterID uuid.UUID, until time.Time, limit int) ([]*models.ProductDelta, error) {
+	var rows []*models.ProductDelta
+	// A soft delete does not touch updated_at, so the change time is the later of the two.
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT * FROM (
+			SELECT id, unit_price, discount_percent, stock_quantity, is_active, deleted_at IS NOT NULL AS deleted,
+				GREATEST(updated_at, COALESCE(deleted_at, updated_at)) AS changed_at
+			FROM products
+			WHERE pharmacy_id = ?
+		) p
+		WHERE p.changed_at < ? AND (p.changed_at, p.id) > (?, ?)
+		ORDER BY p.changed_at ASC, p.id ASC
+		LIMIT ?`,
+		pharmacyID, until, since, afterID, limit,
+	).Scan(&rows).Error
+	return rows, err
 }
@@ -0,0 +1,48 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type productReturnFlagRepo struct {
+	db *gorm.DB
+}
+
+func NewProductReturnFlagRepository(db *gorm.DB) outbound.ProductReturnFlagRepository {
+	return &productReturnFlagRepo{db: db}
+}
+
+func (r *productReturnFlagRepo) GetByProductID(ctx context.Context, productID uuid.UUID) (*models.ProductReturnFlag, error) {
+	var f models.ProductReturnFlag
+	err := dbFrom(ctx, r.db).Where("product_id = ?", productID).First(&f).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *productReturnFlagRepo) Create(ctx context.Context, f *models.ProductReturnFlag) error {
+	return dbFrom(ctx, r.db).Create(f).Error
+}
+
+func (r *productReturnFlagRepo) Update(ctx context.Context, f *models.ProductReturnFlag) error {
+	return dbFrom(ctx, r.db).Omit("Product").Save(f).Error
+}
+
+func (r *productReturnFlagRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, status string) ([]*models.ProductReturnFlag, error) {
+	q := dbFrom(ctx, r.db).Preload("Product").Where("pharmacy_id = ?", pharmacyID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var list []*models.ProductReturnFlag
+	err := q.Order("return_rate DESC, flagged_at DESC").Find(&list).Error
+	return list, err
+}
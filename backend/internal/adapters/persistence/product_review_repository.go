@@ -2,6 +2,7 @@ package persistence
 
 import (
 	"context"
+	"errors"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
@@ -17,13 +18,18 @@ func NewProductReviewRepository(db *gorm.DB) outbound.ProductReviewRepository {
 	return &productReviewRepo{db: db}
 }
 
-func (r *productReviewRepo) Create(ctx context.Context, rev *models.ProductReview) error {
-	return r.db.WithContext(ctx).Create(rev).Error
+func (r *productReviewRepo) Create(ctx context.Context, rev *models.ProductReview, events ...*models.OutboxEvent) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(rev).Error; err != nil {
+			return err
+		}
+		return appendOutbox(tx, events)
+	})
 }
 
 func (r *productReviewRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.ProductReview, error) {
 	var rev models.ProductReview
-	err := r.db.WithContext(ctx).Preload("User").First(&rev, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).Preload("User").First(&rev, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +38,7 @@ func (r *productReviewRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.
 
 func (r *productReviewRepo) ListByProductID(ctx context.Context, productID uuid.UUID, limit, offset int) ([]*models.ProductReview, error) {
 	var list []*models.ProductReview
-	q := r.db.WithContext(ctx).Where("product_id = ?", productID).Order("created_at DESC")
+	q := dbFrom(ctx, r.db).Where("product_id = ?", productID).Order("created_at DESC")
 	if limit > 0 {
 		q = q.Limit(limit)
 	}
@@ -44,16 +50,16 @@ func (r *productReviewRepo) ListByProductID(ctx context.Context, productID uuid.
 }
 
 func (r *productReviewRepo) Update(ctx context.Context, rev *models.ProductReview) error {
-	return r.db.WithContext(ctx).Save(rev).Error
+	return dbFrom(ctx, r.db).Save(rev).Error
 }
 
 func (r *productReviewRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.ProductReview{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.ProductReview{}, "id = ?", id).Error
 }
 
 func (r *productReviewRepo) ExistsByProductAndUser(ctx context.Context, productID, userID uuid.UUID) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.ProductReview{}).Where("product_id = ? AND user_id = ?", productID, userID).Count(&count).Error
+	err := dbFrom(ctx, r.db).Model(&models.ProductReview{}).Where("product_id = ? AND user_id = ?", productID, userID).Count(&count).Error
 	return count > 0, err
 }
 
@@ -67,7 +73,7 @@ func (r *productReviewRepo) GetRatingStatsByProductIDs(ctx context.Context, prod
 		Count     int64
 	}
 	var rows []row
-	err := r.db.WithContext(ctx).Model(&models.ProductReview{}).
+	err := dbFrom(ctx, r.db).Model(&models.ProductReview{}).
 		Select("product_id, COALESCE(AVG(rating), 0) as avg, COUNT(*) as count").
 		Where("product_id IN ?", productIDs).
 		Group("product_id").
@@ -81,3 +87,32 @@ func (r *productReviewRepo) GetRatingStatsByProductIDs(ctx context.Context, prod
 	}
 	return out, nil
 }
+
+func (r *productReviewRepo) GetResponse(ctx context.Context, reviewID uuid.UUID) (*models.ReviewResponse, error) {
+	var resp models.ReviewResponse
+	if err := dbFrom(ctx, r.db).First(&resp, "review_id = ?", reviewID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (r *productReviewRepo) ListResponses(ctx context.Context, reviewIDs []uuid.UUID) ([]*models.ReviewResponse, error) {
+	if len(reviewIDs) == 0 {
+		return nil, nil
+	}
+	var list []*models.ReviewResponse
+	err := dbFrom(ctx, r.db).Where("review_id IN ?", reviewIDs).Find(&list).Error
+	return list, err
+}
+
+func (r *productReviewRepo) SaveResponse(ctx context.Context, resp *models.ReviewResponse) error {
+	return dbFrom(ctx, r.db).Save(resp).Error
+}
+
+func (r *productReviewRepo) DeleteResponse(ctx context.Context, reviewID uuid.UUID) (bool, error) {
+	res := dbFrom(ctx, r.db).Where("review_id = ?", reviewID).Delete(&models.ReviewResponse{})
+	return res.RowsAffected > 0, res.Error
+}
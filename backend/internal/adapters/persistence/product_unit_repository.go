@@ -18,12 +18,12 @@ func NewProductUnitRepository(db *gorm.DB) outbound.ProductUnitRepository {
 }
 
 func (r *productUnitRepo) Create(ctx context.Context, u *models.ProductUnit) error {
-	return r.db.WithContext(ctx).Create(u).Error
+	return dbFrom(ctx, r.db).Create(u).Error
 }
 
 func (r *productUnitRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.ProductUnit, error) {
 	var u models.ProductUnit
-	err := r.db.WithContext(ctx).First(&u, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).First(&u, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,14 +32,14 @@ func (r *productUnitRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Pr
 
 func (r *productUnitRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.ProductUnit, error) {
 	var list []*models.ProductUnit
-	err := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID).Order("sort_order ASC, name ASC").Find(&list).Error
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).Order("sort_order ASC, name ASC").Find(&list).Error
 	return list, err
 }
 
 func (r *productUnitRepo) Update(ctx context.Context, u *models.ProductUnit) error {
-	return r.db.WithContext(ctx).Save(u).Error
+	return dbFrom(ctx, r.db).Save(u).Error
 }
 
 func (r *productUnitRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.ProductUnit{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.ProductUnit{}, "id = ?", id).Error
 }
@@ -18,12 +18,12 @@ func NewPromoCodeRepository(db *gorm.DB) outbound.PromoCodeRepository {
 }
 
 func (r *promoCodeRepo) Create(ctx context.Context, p *models.PromoCode) error {
-	return r.db.WithContext(ctx).Create(p).Error
+	return dbFrom(ctx, r.db).Create(p).Error
 }
 
 func (r *promoCodeRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.PromoCode, error) {
 	var p models.PromoCode
-	err := r.db.WithContext(ctx).First(&p, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).First(&p, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +32,7 @@ func (r *promoCodeRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Prom
 
 func (r *promoCodeRepo) GetByPharmacyAndCode(ctx context.Context, pharmacyID uuid.UUID, code string) (*models.PromoCode, error) {
 	var p models.PromoCode
-	err := r.db.WithContext(ctx).Where("pharmacy_id = ? AND code = ?", pharmacyID, code).First(&p).Error
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ? AND code = ?", pharmacyID, code).First(&p).Error
 	if err != nil {
 		return nil, err
 	}
@@ -41,14 +41,14 @@ func (r *promoCodeRepo) GetByPharmacyAndCode(ctx context.Context, pharmacyID uui
 
 func (r *promoCodeRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.PromoCode, error) {
 	var list []*models.PromoCode
-	err := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID).Order("created_at DESC").Find(&list).Error
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).Order("created_at DESC").Find(&list).Error
 	return list, err
 }
 
 func (r *promoCodeRepo) Update(ctx context.Context, p *models.PromoCode) error {
-	return r.db.WithContext(ctx).Save(p).Error
+	return dbFrom(ctx, r.db).Save(p).Error
 }
 
 func (r *promoCodeRepo) IncrementUsedCount(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Model(&models.PromoCode{}).Where("id = ?", id).UpdateColumn("used_count", gorm.Expr("used_count + ?", 1)).Error
+	return dbFrom(ctx, r.db).Model(&models.PromoCode{}).Where("id = ?", id).UpdateColumn("used_count", gorm.Expr("used_count + ?", 1)).Error
 }
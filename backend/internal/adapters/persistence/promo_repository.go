@@ -19,12 +19,12 @@ func NewPromoRepository(db *gorm.DB) outbound.PromoRepository {
 }
 
 func (r *promoRepo) Create(ctx context.Context, p *models.Promo) error {
-	return r.db.WithContext(ctx).Create(p).Error
+	return dbFrom(ctx, r.db).Create(p).Error
 }
 
 func (r *promoRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Promo, error) {
 	var p models.Promo
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&p).Error
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&p).Error
 	if err != nil {
 		return nil, err
 	}
@@ -33,7 +33,7 @@ func (r *promoRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Promo, e
 
 func (r *promoRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, types []string, activeOnly bool) ([]*models.Promo, error) {
 	now := time.Now()
-	q := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID)
+	q := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID)
 	if len(types) > 0 {
 		q = q.Where("type IN ?", types)
 	}
@@ -49,9 +49,9 @@ func (r *promoRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, ty
 }
 
 func (r *promoRepo) Update(ctx context.Context, p *models.Promo) error {
-	return r.db.WithContext(ctx).Save(p).Error
+	return dbFrom(ctx, r.db).Save(p).Error
 }
 
 func (r *promoRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.Promo{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.Promo{}, "id = ?", id).Error
 }
@@ -0,0 +1,48 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type promoStatRepo struct {
+	db *gorm.DB
+}
+
+func NewPromoStatRepository(db *gorm.DB) outbound.PromoStatRepository {
+	return &promoStatRepo{db: db}
+}
+
+func (r *promoStatRepo) Increment(ctx context.Context, pharmacyID, promoID uuid.UUID, day time.Time, placement string, impressions, clicks int64) error {
+	row := &models.PromoDailyStat{
+		PharmacyID:  pharmacyID,
+		PromoID:     promoID,
+		Day:         day,
+		Placement:   placement,
+		Impressions: impressions,
+		Clicks:      clicks,
+	}
+	return dbFrom(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "promo_id"}, {Name: "day"}, {Name: "placement"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"impressions": gorm.Expr("promo_daily_stats.impressions + ?", impressions),
+			"clicks":      gorm.Expr("promo_daily_stats.clicks + ?", clicks),
+			"updated_at":  time.Now(),
+		}),
+	}).Create(row).Error
+}
+
+func (r *promoStatRepo) ListByPromo(ctx context.Context, promoID uuid.UUID, from, to time.Time) ([]*models.PromoDailyStat, error) {
+	var list []*models.PromoDailyStat
+	err := dbFrom(ctx, r.db).
+		Where("promo_id = ? AND day >= ? AND day <= ?", promoID, from, to).
+		Order("day ASC, placement ASC").
+		Find(&list).Error
+	return list, err
+}
@@ -0,0 +1,63 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type purchaseOrderRepo struct {
+	db *gorm.DB
+}
+
+func NewPurchaseOrderRepository(db *gorm.DB) outbound.PurchaseOrderRepository {
+	return &purchaseOrderRepo{db: db}
+}
+
+func (r *purchaseOrderRepo) Create(ctx context.Context, po *models.PurchaseOrder) error {
+	return dbFrom(ctx, r.db).Create(po).Error
+}
+
+func (r *purchaseOrderRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.PurchaseOrder, error) {
+	var po models.PurchaseOrder
+	err := dbFrom(ctx, r.db).Preload("Supplier").Preload("Items.Product").First(&po, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &po, nil
+}
+
+func (r *purchaseOrderRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, status *models.PurchaseOrderStatus, supplierID *uuid.UUID, limit, offset int) ([]*models.PurchaseOrder, int64, error) {
+	scope := func() *gorm.DB {
+		q := dbFrom(ctx, r.db).Model(&models.PurchaseOrder{}).Where("pharmacy_id = ?", pharmacyID)
+		if status != nil && *status != "" {
+			q = q.Where("status = ?", *status)
+		}
+		if supplierID != nil {
+			q = q.Where("supplier_id = ?", *supplierID)
+		}
+		return q
+	}
+	var total int64
+	if err := scope().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.PurchaseOrder
+	q := scope().Preload("Supplier").Preload("Items.Product").Order("created_at DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	err := q.Find(&list).Error
+	return list, total, err
+}
+
+// Update saves the purchase order row only; items are written once on Create.
+func (r *purchaseOrderRepo) Update(ctx context.Context, po *models.PurchaseOrder) error {
+	return dbFrom(ctx, r.db).Omit("Items", "Supplier").Save(po).Error
+}
@@ -0,0 +1,95 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type recommendationRepo struct {
+	db *gorm.DB
+}
+
+func NewRecommendationRepository(db *gorm.DB) outbound.RecommendationRepository {
+	return &recommendationRepo{db: db}
+}
+
+func (r *recommendationRepo) TouchView(ctx context.Context, v *models.RecentView) error {
+	return dbFrom(ctx, r.db).Omit("Product").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "viewer"}, {Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"viewed_at", "user_id"}),
+	}).Create(v).Error
+}
+
+func (r *recommendationRepo) PruneViews(ctx context.Context, viewer string, keep int) error {
+	return dbFrom(ctx, r.db).Exec(`
+		DELETE FROM recent_views WHERE viewer = ? AND id NOT IN (
+			SELECT id FROM recent_views WHERE viewer = ? ORDER BY viewed_at DESC LIMIT ?
+		)`, viewer, viewer, keep).Error
+}
+
+func (r *recommendationRepo) ListViews(ctx context.Context, pharmacyID uuid.UUID, viewer string, limit int) ([]*models.RecentView, error) {
+	var list []*models.RecentView
+	err := dbFrom(ctx, r.db).Preload("Product").Preload("Product.Images").
+		Joins("JOIN products p ON p.id = recent_views.product_id AND p.deleted_at IS NULL AND p.is_active").
+		Where("recent_views.pharmacy_id = ? AND recent_views.viewer = ?", pharmacyID, viewer).
+		Order("recent_views.viewed_at DESC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (r *recommendationRepo) DeleteViewsBefore(ctx context.Context, before time.Time) (int64, error) {
+	res := dbFrom(ctx, r.db).Where("viewed_at < ?", before).Delete(&models.RecentView{})
+	return res.RowsAffected, res.Error
+}
+
+// uuidsOrNil keeps NOT IN valid for an empty exclusion list.
+func uuidsOrNil(ids []uuid.UUID) []uuid.UUID {
+	if len(ids) == 0 {
+		return []uuid.UUID{uuid.Nil}
+	}
+	return ids
+}
+
+func (r *recommendationRepo) BoughtTogether(ctx context.Context, pharmacyID uuid.UUID, productIDs, exclude []uuid.UUID, since time.Time, limit int) ([]*models.ProductScore, error) {
+	var rows []*models.ProductScore
+	if len(productIDs) == 0 {
+		return rows, nil
+	}
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT other.product_id, COUNT(DISTINCT other.order_id) AS score
+		FROM order_items seed
+		JOIN orders o ON o.id = seed.order_id AND o.deleted_at IS NULL AND o.status <> ?
+		JOIN order_items other ON other.order_id = seed.order_id
+		JOIN products p ON p.id = other.product_id AND p.deleted_at IS NULL AND p.is_active AND p.stock_quantity > 0
+		WHERE o.pharmacy_id = ? AND o.created_at >= ? AND seed.product_id IN ?
+			AND other.product_id NOT IN ? AND other.product_id NOT IN ?
+		GROUP BY other.product_id
+		ORDER BY score DESC, other.product_id
+		LIMIT ?`,
+		models.OrderStatusCancelled, pharmacyID, since, productIDs, productIDs, uuidsOrNil(exclude), limit).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *recommendationRepo) SameCategory(ctx context.Context, pharmacyID uuid.UUID, categories []string, exclude []uuid.UUID, since time.Time, limit int) ([]*models.Product, error) {
+	var list []*models.Product
+	if len(categories) == 0 {
+		return list, nil
+	}
+	sold := dbFrom(ctx, r.db).Table("order_items oi").
+		Select("oi.product_id, SUM(oi.quantity) AS units").
+		Joins("JOIN orders o ON o.id = oi.order_id AND o.deleted_at IS NULL AND o.status <> ?", models.OrderStatusCancelled).
+		Where("o.pharmacy_id = ? AND o.created_at >= ?", pharmacyID, since).
+		Group("oi.product_id")
+	err := dbFrom(ctx, r.db).Table("products").Select("products.*").
+		Joins("LEFT JOIN (?) sold ON sold.product_id = products.id", sold).
+		Where("products.pharmacy_id = ? AND products.deleted_at IS NULL AND products.is_active AND products.stock_quantity > 0", pharmacyID).
+		Where("products.category IN ? AND products.id NOT IN ?", categories, uuidsOrNil(exclude)).
+		Order("COALESCE(sold.units, 0) DESC, products.name").Limit(limit).
+		Preload("Images").Find(&list).Error
+	return list, err
+}
@@ -18,12 +18,12 @@ func NewReferralPointsConfigRepository(db *gorm.DB) outbound.ReferralPointsConfi
 }
 
 func (r *referralPointsConfigRepo) Create(ctx context.Context, c *models.ReferralPointsConfig) error {
-	return r.db.WithContext(ctx).Create(c).Error
+	return dbFrom(ctx, r.db).Create(c).Error
 }
 
 func (r *referralPointsConfigRepo) GetByPharmacyID(ctx context.Context, pharmacyID uuid.UUID) (*models.ReferralPointsConfig, error) {
 	var c models.ReferralPointsConfig
-	err := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID).First(&c).Error
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).First(&c).Error
 	if err != nil {
 		return nil, err
 	}
@@ -31,5 +31,5 @@ func (r *referralPointsConfigRepo) GetByPharmacyID(ctx context.Context, pharmacy
 }
 
 func (r *referralPointsConfigRepo) Update(ctx context.Context, c *models.ReferralPointsConfig) error {
-	return r.db.WithContext(ctx).Save(c).Error
+	return dbFrom(ctx, r.db).Save(c).Error
 }
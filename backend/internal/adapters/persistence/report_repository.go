@@ -0,0 +1,192 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type reportRepo struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) outbound.ReportRepository {
+	return &reportRepo{db: db}
+}
+
+func (r *reportRepo) SalesByPeriod(ctx context.Context, pharmacyID uuid.UUID, granularity string, from, to time.Time, branchID *uuid.UUID) ([]*models.SalesPeriodRow, error) {
+	switch granularity {
+	case "day", "week", "month":
+	default:
+		granularity = "day"
+	}
+	var rows []*models.SalesPeriodRow
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT date_trunc(?, created_at) AS period,
+			COUNT(*) AS orders_count,
+			COALESCE(SUM(sub_total), 0) AS sub_total,
+			COALESCE(SUM(discount_amount), 0) AS discount_amount,
+			COALESCE(SUM(tax_amount), 0) AS tax_amount,
+			COALESCE(SUM(total_amount), 0) AS revenue
+		FROM orders
+		WHERE pharmacy_id = ? AND deleted_at IS NULL AND status <> ?
+			AND created_at >= ? AND created_at < ?
+			AND (CAST(? AS uuid) IS NULL OR branch_id = ?)
+		GROUP BY period
+		ORDER BY period ASC`,
+		granularity, pharmacyID, models.OrderStatusCancelled, from, to, branchID, branchID,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *reportRepo) TopSellingProducts(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time, branchID *uuid.UUID, limit int) ([]*models.TopProductRow, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	var rows []*models.TopProductRow
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT oi.product_id, p.name, p.sku,
+			SUM(oi.quantity) AS quantity_sold,
+			COALESCE(SUM(oi.total_price), 0) AS revenue,
+			COUNT(DISTINCT o.id) AS orders_count
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		JOIN products p ON p.id = oi.product_id
+		WHERE o.pharmacy_id = ? AND o.deleted_at IS NULL AND o.status <> ?
+			AND o.created_at >= ? AND o.created_at < ?
+			AND (CAST(? AS uuid) IS NULL OR o.branch_id = ?)
+		GROUP BY oi.product_id, p.name, p.sku
+		ORDER BY quantity_sold DESC, revenue DESC
+		LIMIT ?`,
+		pharmacyID, models.OrderStatusCancelled, from, to, branchID, branchID, limit,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *reportRepo) RevenueByPaymentMethod(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.PaymentMethodRow, error) {
+	var rows []*models.PaymentMethodRow
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT method,
+			COUNT(*) AS payments_count,
+			COALESCE(SUM(amount), 0) AS amount
+		FROM payments
+		WHERE pharmacy_id = ? AND deleted_at IS NULL AND status = ?
+			AND COALESCE(paid_at, created_at) >= ? AND COALESCE(paid_at, created_at) < ?
+		GROUP BY method
+		ORDER BY amount DESC`,
+		pharmacyID, models.PaymentStatusCompleted, from, to,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *reportRepo) LowStock(ctx context.Context, pharmacyID uuid.UUID, threshold int) ([]*models.LowStockRow, error) {
+	var rows []*models.LowStockRow
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT id AS product_id, name, sku, category, stock_quantity, unit_price
+		FROM products
+		WHERE pharmacy_id = ? AND deleted_at IS NULL AND is_active = true AND stock_quantity <= ?
+		ORDER BY stock_quantity ASC, name ASC`,
+		pharmacyID, threshold,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *reportRepo) ExpiringStock(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.ExpiringStockRow, error) {
+	var rows []*models.ExpiringStockRow
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT b.id AS batch_id, b.product_id, p.name, p.sku, b.batch_number, b.quantity, b.expiry_date,
+			p.unit_price, b.quantity * p.unit_price AS value
+		FROM inventory_batches b
+		JOIN products p ON p.id = b.product_id
+		WHERE b.pharmacy_id = ? AND p.deleted_at IS NULL AND b.quantity > 0
+			AND b.expiry_date IS NOT NULL AND b.expiry_date >= ? AND b.expiry_date < ?
+		ORDER BY b.expiry_date ASC`,
+		pharmacyID, from, to,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *reportRepo) TaxByRate(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.TaxLine, error) {
+	var rows []*models.TaxLine
+	// Taxable amount = line total after its pro-rata share of the order discount, minus VAT when prices include it.
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT COALESCE(NULLIF(oi.tax_class, ''), ?) AS tax_class, oi.tax_rate AS rate,
+			COALESCE(SUM(
+				oi.total_price * CASE WHEN o.sub_total > 0 THEN 1 - LEAST(o.discount_amount / o.sub_total, 1) ELSE 1 END
+				- CASE WHEN o.tax_inclusive THEN oi.tax_amount ELSE 0 END
+			), 0) AS taxable_amount,
+			COALESCE(SUM(oi.tax_amount), 0) AS tax_amount
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		WHERE o.pharmacy_id = ? AND o.deleted_at IS NULL AND o.status <> ?
+			AND o.created_at >= ? AND o.created_at < ?
+		GROUP BY 1, 2
+		ORDER BY rate DESC, tax_class ASC`,
+		models.TaxClassStandard, pharmacyID, models.OrderStatusCancelled, from, to,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *reportRepo) SalesRegister(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.SalesRegisterRow, error) {
+	var rows []*models.SalesRegisterRow
+	// An invoice covers everything the customer paid, including gift card and store credit.
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT 'invoice' AS document_type, i.invoice_number AS document_number, i.issued_at, '' AS reference_number,
+			o.order_number, o.customer_name,
+			o.total_amount + o.gift_card_amount + o.store_credit_amount - o.tax_amount AS taxable_amount,
+			o.tax_amount,
+			o.total_amount + o.gift_card_amount + o.store_credit_amount AS total_amount,
+			o.custom_fields
+		FROM invoices i
+		JOIN orders o ON o.id = i.order_id
+		WHERE i.pharmacy_id = ? AND i.deleted_at IS NULL AND i.status = ? AND i.issued_at >= ? AND i.issued_at < ?
+		UNION ALL
+		SELECT 'credit_note', cn.credit_note_number, cn.issued_at, i.invoice_number,
+			o.order_number, o.customer_name, -cn.taxable_amount, -cn.tax_amount, -cn.amount, o.custom_fields
+		FROM credit_notes cn
+		JOIN invoices i ON i.id = cn.invoice_id
+		JOIN orders o ON o.id = cn.order_id
+		WHERE cn.pharmacy_id = ? AND cn.issued_at >= ? AND cn.issued_at < ?
+		ORDER BY issued_at ASC, document_number ASC`,
+		pharmacyID, models.InvoiceStatusIssued, from, to, pharmacyID, from, to,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *reportRepo) DeadStock(ctx context.Context, pharmacyID uuid.UUID, since time.Time, minValue float64, limit int) ([]*models.DeadStockRow, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	var rows []*models.DeadStockRow
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT p.id AS product_id, p.name, p.sku, p.category, p.supplier_id, COALESCE(s.name, '') AS supplier_name,
+			p.stock_quantity, p.unit_price, p.stock_quantity * p.unit_price AS stock_value,
+			ls.last_sold_at, ca.action_id AS clearance_action_id
+		FROM products p
+		LEFT JOIN suppliers s ON s.id = p.supplier_id
+		LEFT JOIN LATERAL (
+			SELECT MAX(o.created_at) AS last_sold_at
+			FROM order_items oi
+			JOIN orders o ON o.id = oi.order_id
+			WHERE oi.product_id = p.id AND o.deleted_at IS NULL AND o.status <> ?
+		) ls ON true
+		LEFT JOIN LATERAL (
+			SELECT ci.action_id
+			FROM clearance_items ci
+			JOIN clearance_actions a ON a.id = ci.action_id
+			WHERE ci.product_id = p.id AND a.status = ?
+			LIMIT 1
+		) ca ON true
+		WHERE p.pharmacy_id = ? AND p.deleted_at IS NULL AND p.is_active = true AND p.stock_quantity > 0
+			AND p.created_at < ? AND (ls.last_sold_at IS NULL OR ls.last_sold_at < ?)
+			AND p.stock_quantity * p.unit_price >= ?
+		ORDER BY stock_value DESC, p.name ASC
+		LIMIT ?`,
+		models.OrderStatusCancelled, models.ClearanceOpen, pharmacyID, since, since, minValue, limit,
+	).Scan(&rows).Error
+	return rows, err
+}
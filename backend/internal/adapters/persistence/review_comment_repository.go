@@ -18,12 +18,12 @@ func NewReviewCommentRepository(db *gorm.DB) outbound.ReviewCommentRepository {
 }
 
 func (r *reviewCommentRepo) Create(ctx context.Context, c *models.ReviewComment) error {
-	return r.db.WithContext(ctx).Create(c).Error
+	return dbFrom(ctx, r.db).Create(c).Error
 }
 
 func (r *reviewCommentRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.ReviewComment, error) {
 	var c models.ReviewComment
-	err := r.db.WithContext(ctx).First(&c, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).First(&c, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +32,7 @@ func (r *reviewCommentRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.
 
 func (r *reviewCommentRepo) ListByReviewID(ctx context.Context, reviewID uuid.UUID, limit, offset int) ([]*models.ReviewComment, error) {
 	var list []*models.ReviewComment
-	q := r.db.WithContext(ctx).Where("review_id = ?", reviewID).Order("created_at ASC")
+	q := dbFrom(ctx, r.db).Where("review_id = ?", reviewID).Order("created_at ASC")
 	if limit > 0 {
 		q = q.Limit(limit)
 	}
@@ -45,10 +45,10 @@ func (r *reviewCommentRepo) ListByReviewID(ctx context.Context, reviewID uuid.UU
 
 func (r *reviewCommentRepo) CountByReviewID(ctx context.Context, reviewID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.ReviewComment{}).Where("review_id = ?", reviewID).Count(&count).Error
+	err := dbFrom(ctx, r.db).Model(&models.ReviewComment{}).Where("review_id = ?", reviewID).Count(&count).Error
 	return count, err
 }
 
 func (r *reviewCommentRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.ReviewComment{}, "id = ?", id).Error
+	return dbFrom(ctx, r.db).Delete(&models.ReviewComment{}, "id = ?", id).Error
 }
@@ -18,21 +18,21 @@ func NewReviewLikeRepository(db *gorm.DB) outbound.ReviewLikeRepository {
 }
 
 func (r *reviewLikeRepo) Create(ctx context.Context, l *models.ReviewLike) error {
-	return r.db.WithContext(ctx).Create(l).Error
+	return dbFrom(ctx, r.db).Create(l).Error
 }
 
 func (r *reviewLikeRepo) DeleteByReviewAndUser(ctx context.Context, reviewID, userID uuid.UUID) error {
-	return r.db.WithContext(ctx).Where("review_id = ? AND user_id = ?", reviewID, userID).Delete(&models.ReviewLike{}).Error
+	return dbFrom(ctx, r.db).Where("review_id = ? AND user_id = ?", reviewID, userID).Delete(&models.ReviewLike{}).Error
 }
 
 func (r *reviewLikeRepo) CountByReviewID(ctx context.Context, reviewID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.ReviewLike{}).Where("review_id = ?", reviewID).Count(&count).Error
+	err := dbFrom(ctx, r.db).Model(&models.ReviewLike{}).Where("review_id = ?", reviewID).Count(&count).Error
 	return count, err
 }
 
 func (r *reviewLikeRepo) Exists(ctx context.Context, reviewID, userID uuid.UUID) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.ReviewLike{}).Where("review_id = ? AND user_id = ?", reviewID, userID).Count(&count).Error
+	err := dbFrom(ctx, r.db).Model(&models.ReviewLike{}).Where("review_id = ? AND user_id = ?", reviewID, userID).Count(&count).Error
 	return count > 0, err
 }
@@ -0,0 +1,48 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type roleRepo struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) outbound.RoleRepository {
+	return &roleRepo{db: db}
+}
+
+func (r *roleRepo) Create(ctx context.Context, role *models.Role) error {
+	return dbFrom(ctx, r.db).Create(role).Error
+}
+
+func (r *roleRepo) GetByName(ctx context.Context, pharmacyID uuid.UUID, name string) (*models.Role, error) {
+	var role models.Role
+	if err := dbFrom(ctx, r.db).First(&role, "pharmacy_id = ? AND name = ?", pharmacyID, name).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Role, error) {
+	var list []*models.Role
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).Order("name ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *roleRepo) Update(ctx context.Context, role *models.Role) error {
+	return dbFrom(ctx, r.db).Save(role).Error
+}
+
+func (r *roleRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFrom(ctx, r.db).Delete(&models.Role{}, "id = ?", id).Error
+}
@@ -0,0 +1,76 @@
+package persistence
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type serialRepo struct {
+	db *gorm.DB
+}
+
+func NewSerialRepository(db *gorm.DB) outbound.SerialRepository {
+	return &serialRepo{db: db}
+}
+
+func (r *serialRepo) CreateBatch(ctx context.Context, serials []*models.ProductSerial) error {
+	if len(serials) == 0 {
+		return nil
+	}
+	return dbFrom(ctx, r.db).Omit("Product", "Order").Create(&serials).Error
+}
+
+func (r *serialRepo) FindByNumbers(ctx context.Context, pharmacyID, productID uuid.UUID, numbers []string) ([]*models.ProductSerial, error) {
+	var list []*models.ProductSerial
+	if len(numbers) == 0 {
+		return list, nil
+	}
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ? AND product_id = ? AND serial_number IN ?", pharmacyID, productID, numbers).
+		Find(&list).Error
+	return list, err
+}
+
+func (r *serialRepo) CountInStock(ctx context.Context, pharmacyID, productID uuid.UUID) (int64, error) {
+	var n int64
+	err := dbFrom(ctx, r.db).Model(&models.ProductSerial{}).
+		Where("pharmacy_id = ? AND product_id = ? AND status = ?", pharmacyID, productID, models.SerialInStock).Count(&n).Error
+	return n, err
+}
+
+func (r *serialRepo) List(ctx context.Context, pharmacyID uuid.UUID, f outbound.SerialFilter, limit, offset int) ([]*models.ProductSerial, int64, error) {
+	q := dbFrom(ctx, r.db).Model(&models.ProductSerial{}).Where("pharmacy_id = ?", pharmacyID)
+	if f.ProductID != nil {
+		q = q.Where("product_id = ?", *f.ProductID)
+	}
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if s := strings.TrimSpace(f.Query); s != "" {
+		q = q.Where("serial_number ILIKE ?", "%"+s+"%")
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.ProductSerial
+	err := q.Preload("Product").Preload("Order").Order("received_at DESC, serial_number").Limit(limit).Offset(offset).Find(&list).Error
+	return list, total, err
+}
+
+func (r *serialRepo) MarkSold(ctx context.Context, pharmacyID, productID uuid.UUID, numbers []string, orderID, orderItemID uuid.UUID, at time.Time) (int64, error) {
+	res := dbFrom(ctx, r.db).Model(&models.ProductSerial{}).
+		Where("pharmacy_id = ? AND product_id = ? AND serial_number IN ? AND status = ?", pharmacyID, productID, numbers, models.SerialInStock).
+		Updates(map[string]interface{}{"status": models.SerialSold, "order_id": orderID, "order_item_id": orderItemID, "sold_at": at, "updated_at": at})
+	return res.RowsAffected, res.Error
+}
+
+func (r *serialRepo) ReleaseOrder(ctx context.Context, orderID uuid.UUID) error {
+	return dbFrom(ctx, r.db).Model(&models.ProductSerial{}).Where("order_id = ?", orderID).
+		Updates(map[string]interface{}{"status": models.SerialInStock, "order_id": nil, "order_item_id": nil, "sold_at": nil, "updated_at": time.Now()}).Error
+}
@@ -0,0 +1,88 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type shiftSwapRepo struct {
+	db *gorm.DB
+}
+
+func NewShiftSwapRepository(db *gorm.DB) outbound.ShiftSwapRepository {
+	return &shiftSwapRepo{db: db}
+}
+
+func (r *shiftSwapRepo) Create(ctx context.Context, s *models.ShiftSwap, e *models.ShiftSwapEvent) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(s).Error; err != nil {
+			return err
+		}
+		e.SwapID = s.ID
+		return tx.Create(e).Error
+	})
+}
+
+func (r *shiftSwapRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.ShiftSwap, error) {
+	var s models.ShiftSwap
+	err := dbFrom(ctx, r.db).
+		Preload("Roster").Preload("Offerer").Preload("Requests", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Preload("Requests.User").Preload("Events", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).
+		First(&s, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *shiftSwapRepo) List(ctx context.Context, pharmacyID uuid.UUID, status models.ShiftSwapStatus) ([]*models.ShiftSwap, error) {
+	var list []*models.ShiftSwap
+	q := dbFrom(ctx, r.db).Preload("Roster").Preload("Offerer").Preload("Requests").Where("pharmacy_id = ?", pharmacyID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	err := q.Order("created_at DESC").Find(&list).Error
+	return list, err
+}
+
+func (r *shiftSwapRepo) GetOpenByRoster(ctx context.Context, rosterID uuid.UUID) (*models.ShiftSwap, error) {
+	var s models.ShiftSwap
+	err := dbFrom(ctx, r.db).Where("roster_id = ? AND status = ?", rosterID, models.ShiftSwapOpen).First(&s).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *shiftSwapRepo) Save(ctx context.Context, s *models.ShiftSwap, roster *models.DutyRoster, e *models.ShiftSwapEvent) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(s).Error; err != nil {
+			return err
+		}
+		for _, req := range s.Requests {
+			req.SwapID = s.ID
+			if err := tx.Omit(clause.Associations).Save(req).Error; err != nil {
+				return err
+			}
+		}
+		if roster != nil {
+			if err := tx.Omit(clause.Associations).Save(roster).Error; err != nil {
+				return err
+			}
+		}
+		e.SwapID = s.ID
+		return tx.Create(e).Error
+	})
+}
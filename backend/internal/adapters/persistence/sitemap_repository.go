@@ -0,0 +1,126 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type sitemapRepo struct {
+	db *gorm.DB
+}
+
+func NewSitemapRepository(db *gorm.DB) outbound.SitemapRepository {
+	return &sitemapRepo{db: db}
+}
+
+func (r *sitemapRepo) Get(ctx context.Context, pharmacyID uuid.UUID) (*models.StorefrontSitemap, error) {
+	var s models.StorefrontSitemap
+	err := dbFrom(ctx, r.db).First(&s, "pharmacy_id = ?", pharmacyID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *sitemapRepo) Save(ctx context.Context, s *models.StorefrontSitemap) error {
+	return dbFrom(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "pharmacy_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"xml":          s.XML,
+			"url_count":    s.URLCount,
+			"base_url":     s.BaseURL,
+			"fingerprint":  s.Fingerprint,
+			"generated_at": s.GeneratedAt,
+			"updated_at":   time.Now(),
+		}),
+	}).Create(s).Error
+}
+
+func (r *sitemapRepo) Fingerprint(ctx context.Context, pharmacyID uuid.UUID) (string, error) {
+	var row struct {
+		Products     int64
+		ProductsAt   *time.Time
+		Categories   int64
+		CategoriesAt *time.Time
+		Posts        int64
+		PostsAt      *time.Time
+		ConfigAt     *time.Time
+	}
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT
+			(SELECT COUNT(*) FROM products WHERE pharmacy_id = @p AND is_active AND deleted_at IS NULL) AS products,
+			(SELECT MAX(updated_at) FROM products WHERE pharmacy_id = @p AND deleted_at IS NULL) AS products_at,
+			(SELECT COUNT(*) FROM categories WHERE pharmacy_id = @p AND deleted_at IS NULL) AS categories,
+			(SELECT MAX(updated_at) FROM categories WHERE pharmacy_id = @p AND deleted_at IS NULL) AS categories_at,
+			(SELECT COUNT(*) FROM blog_posts WHERE pharmacy_id = @p AND status = @published AND deleted_at IS NULL) AS posts,
+			(SELECT MAX(updated_at) FROM blog_posts WHERE pharmacy_id = @p AND deleted_at IS NULL) AS posts_at,
+			(SELECT MAX(updated_at) FROM pharmacy_configs WHERE pharmacy_id = @p AND deleted_at IS NULL) AS config_at`,
+		map[string]interface{}{"p": pharmacyID, "published": models.BlogPostStatusPublished}).
+		Scan(&row).Error
+	if err != nil {
+		return "", err
+	}
+	unix := func(t *time.Time) int64 {
+		if t == nil {
+			return 0
+		}
+		return t.Unix()
+	}
+	return fmt.Sprintf("p%d:%d c%d:%d b%d:%d cfg%d", row.Products, unix(row.ProductsAt), row.Categories, unix(row.CategoriesAt),
+		row.Posts, unix(row.PostsAt), unix(row.ConfigAt)), nil
+}
+
+func (r *sitemapRepo) Sources(ctx context.Context, pharmacyID uuid.UUID, limit int) ([]*models.SitemapSource, error) {
+	var out []*models.SitemapSource
+	var rows []struct {
+		Key       string
+		UpdatedAt time.Time
+	}
+	if err := dbFrom(ctx, r.db).Model(&models.Category{}).
+		Select("id::text AS key, updated_at").
+		Where("pharmacy_id = ?", pharmacyID).
+		Order("updated_at DESC").Limit(limit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		out = append(out, &models.SitemapSource{Kind: models.SitemapKindCategory, Key: row.Key, UpdatedAt: row.UpdatedAt})
+	}
+	if limit-len(out) <= 0 {
+		return out, nil
+	}
+	rows = rows[:0]
+	if err := dbFrom(ctx, r.db).Model(&models.BlogPost{}).
+		Select("slug AS key, updated_at").
+		Where("pharmacy_id = ? AND status = ?", pharmacyID, models.BlogPostStatusPublished).
+		Order("updated_at DESC").Limit(limit - len(out)).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		out = append(out, &models.SitemapSource{Kind: models.SitemapKindBlogPost, Key: row.Key, UpdatedAt: row.UpdatedAt})
+	}
+	if limit-len(out) <= 0 {
+		return out, nil
+	}
+	rows = rows[:0]
+	if err := dbFrom(ctx, r.db).Model(&models.Product{}).
+		Select("id::text AS key, updated_at").
+		Where("pharmacy_id = ? AND is_active", pharmacyID).
+		Order("updated_at DESC").Limit(limit - len(out)).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		out = append(out, &models.SitemapSource{Kind: models.SitemapKindProduct, Key: row.Key, UpdatedAt: row.UpdatedAt})
+	}
+	return out, nil
+}
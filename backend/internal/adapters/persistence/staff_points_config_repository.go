@@ -19,7 +19,7 @@ func NewStaffPointsConfigRepository(db *gorm.DB) outbound.StaffPointsConfigRepos
 
 func (r *staffPointsConfigRepo) GetByPharmacyID(ctx context.Context, pharmacyID uuid.UUID) (*models.StaffPointsConfig, error) {
 	var c models.StaffPointsConfig
-	err := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID).First(&c).Error
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).First(&c).Error
 	if err != nil {
 		return nil, err
 	}
@@ -39,12 +39,12 @@ func (r *staffPointsConfigRepo) GetOrCreateByPharmacyID(ctx context.Context, pha
 		PointsPerCurrencyUnit: 1,
 		CurrencyUnitForPoints: 100,
 	}
-	if err := r.db.WithContext(ctx).Create(c).Error; err != nil {
+	if err := dbFrom(ctx, r.db).Create(c).Error; err != nil {
 		return nil, err
 	}
 	return c, nil
 }
 
 func (r *staffPointsConfigRepo) Update(ctx context.Context, c *models.StaffPointsConfig) error {
-	return r.db.WithContext(ctx).Save(c).Error
+	return dbFrom(ctx, r.db).Save(c).Error
 }
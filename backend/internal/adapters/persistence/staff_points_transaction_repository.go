@@ -0,0 +1,75 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type staffPointsTransactionRepo struct {
+	db *gorm.DB
+}
+
+func NewStaffPointsTransactionRepository(db *gorm.DB) outbound.StaffPointsTransactionRepository {
+	return &staffPointsTransactionRepo{db: db}
+}
+
+func (r *staffPointsTransactionRepo) Credit(ctx context.Context, t *models.StaffPointsTransaction) (bool, error) {
+	credited := false
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "order_id"}}, DoNothing: true}).Create(t)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Exec("UPDATE users SET points_balance = points_balance + ?, updated_at = NOW() WHERE id = ?", t.Points, t.UserID).Error; err != nil {
+			return err
+		}
+		credited = true
+		return nil
+	})
+	return credited, err
+}
+
+func (r *staffPointsTransactionRepo) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.StaffPointsTransaction, int64, error) {
+	scope := func() *gorm.DB {
+		return dbFrom(ctx, r.db).Model(&models.StaffPointsTransaction{}).Where("user_id = ?", userID)
+	}
+	var total int64
+	if err := scope().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.StaffPointsTransaction
+	q := scope().Order("created_at DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	err := q.Find(&list).Error
+	return list, total, err
+}
+
+func (r *staffPointsTransactionRepo) MonthlyTotals(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.StaffPointsMonthRow, error) {
+	var rows []*models.StaffPointsMonthRow
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT to_char(date_trunc('month', t.created_at), 'YYYY-MM') AS month, t.user_id,
+			COALESCE(u.name, '') AS name, COALESCE(u.email, '') AS email, COALESCE(u.role, '') AS role,
+			COUNT(*) AS sales_count, COALESCE(SUM(t.sale_amount), 0) AS sale_amount, COALESCE(SUM(t.points), 0) AS points
+		FROM staff_points_transactions t
+		LEFT JOIN users u ON u.id = t.user_id
+		WHERE t.pharmacy_id = ? AND t.created_at >= ? AND t.created_at < ?
+		GROUP BY 1, t.user_id, u.name, u.email, u.role
+		ORDER BY month ASC, points DESC, name ASC`,
+		pharmacyID, from, to,
+	).Scan(&rows).Error
+	return rows, err
+}
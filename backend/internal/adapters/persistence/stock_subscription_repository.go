@@ -0,0 +1,72 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type stockSubscriptionRepo struct {
+	db *gorm.DB
+}
+
+func NewStockSubscriptionRepository(db *gorm.DB) outbound.StockSubscriptionRepository {
+	return &stockSubscriptionRepo{db: db}
+}
+
+func (r *stockSubscriptionRepo) Create(ctx context.Context, s *models.StockSubscription) error {
+	return dbFrom(ctx, r.db).Omit("Product").Create(s).Error
+}
+
+func (r *stockSubscriptionRepo) FindPending(ctx context.Context, productID uuid.UUID, userID *uuid.UUID, phone, email string) (*models.StockSubscription, error) {
+	match := r.db.Where("1 = 0")
+	if userID != nil {
+		match = match.Or("user_id = ?", *userID)
+	}
+	if phone != "" {
+		match = match.Or("phone = ?", phone)
+	}
+	if email != "" {
+		match = match.Or("LOWER(email) = LOWER(?)", email)
+	}
+	var s models.StockSubscription
+	err := dbFrom(ctx, r.db).Where("product_id = ? AND status = ?", productID, models.StockSubscriptionPending).
+		Where(match).Order("created_at ASC").First(&s).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *stockSubscriptionRepo) ListByProduct(ctx context.Context, pharmacyID, productID uuid.UUID, status string) ([]*models.StockSubscription, error) {
+	var list []*models.StockSubscription
+	q := dbFrom(ctx, r.db).Where("pharmacy_id = ? AND product_id = ?", pharmacyID, productID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	err := q.Order("created_at DESC").Find(&list).Error
+	return list, err
+}
+
+func (r *stockSubscriptionRepo) ListDue(ctx context.Context, limit int) ([]*models.StockSubscription, error) {
+	var list []*models.StockSubscription
+	err := dbFrom(ctx, r.db).Preload("Product").
+		Joins("JOIN products p ON p.id = stock_subscriptions.product_id AND p.deleted_at IS NULL AND p.is_active AND p.stock_quantity > 0").
+		Where("stock_subscriptions.status = ?", models.StockSubscriptionPending).
+		Order("stock_subscriptions.created_at ASC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (r *stockSubscriptionRepo) MarkNotified(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	res := dbFrom(ctx, r.db).Model(&models.StockSubscription{}).
+		Where("id = ? AND status = ?", id, models.StockSubscriptionPending).
+		Updates(map[string]interface{}{"status": models.StockSubscriptionNotified, "notified_at": at})
+	return res.RowsAffected > 0, res.Error
+}
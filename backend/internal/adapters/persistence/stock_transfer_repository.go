@@ -0,0 +1,135 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type stockTransferRepo struct {
+	db *gorm.DB
+}
+
+func NewStockTransferRepository(db *gorm.DB) outbound.StockTransferRepository {
+	return &stockTransferRepo{db: db}
+}
+
+func (r *stockTransferRepo) Create(ctx context.Context, t *models.StockTransfer, e *models.StockTransferEvent) error {
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("SourcePharmacy", "DestPharmacy", "Events").Create(t).Error; err != nil {
+			return err
+		}
+		e.TransferID = t.ID
+		return tx.Create(e).Error
+	})
+}
+
+func (r *stockTransferRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.StockTransfer, error) {
+	var t models.StockTransfer
+	err := dbFrom(ctx, r.db).
+		Preload("SourcePharmacy").Preload("DestPharmacy").Preload("Items.Batches").
+		Preload("Events", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		First(&t, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *stockTransferRepo) List(ctx context.Context, pharmacyID uuid.UUID, direction string, status models.StockTransferStatus, limit, offset int) ([]*models.StockTransfer, int64, error) {
+	q := dbFrom(ctx, r.db).Model(&models.StockTransfer{})
+	switch direction {
+	case "incoming":
+		q = q.Where("dest_pharmacy_id = ?", pharmacyID)
+	case "outgoing":
+		q = q.Where("source_pharmacy_id = ?", pharmacyID)
+	default:
+		q = q.Where("(source_pharmacy_id = ? OR dest_pharmacy_id = ?)", pharmacyID, pharmacyID)
+	}
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.StockTransfer
+	err := q.Preload("SourcePharmacy").Preload("DestPharmacy").Preload("Items").
+		Order("created_at DESC").Limit(limit).Offset(offset).Find(&list).Error
+	return list, total, err
+}
+
+// transition moves the transfer out of from, writing status and actor fields; false when another request won.
+func transition(tx *gorm.DB, t *models.StockTransfer, from models.StockTransferStatus, e *models.StockTransferEvent) (bool, error) {
+	res := tx.Model(&models.StockTransfer{}).Where("id = ? AND status = ?", t.ID, from).Updates(map[string]interface{}{
+		"status":        t.Status,
+		"approved_by":   t.ApprovedBy,
+		"approved_at":   t.ApprovedAt,
+		"dispatched_by": t.DispatchedBy,
+		"dispatched_at": t.DispatchedAt,
+		"received_by":   t.ReceivedBy,
+		"received_at":   t.ReceivedAt,
+		"closed_by":     t.ClosedBy,
+		"closed_at":     t.ClosedAt,
+		"updated_at":    gorm.Expr("NOW()"),
+	})
+	if res.Error != nil || res.RowsAffected != 1 {
+		return false, res.Error
+	}
+	e.TransferID = t.ID
+	return true, tx.Create(e).Error
+}
+
+func (r *stockTransferRepo) Transition(ctx context.Context, t *models.StockTransfer, from models.StockTransferStatus, e *models.StockTransferEvent) (bool, error) {
+	var ok bool
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var err error
+		ok, err = transition(tx, t, from, e)
+		return err
+	})
+	return ok, err
+}
+
+func (r *stockTransferRepo) Receive(ctx context.Context, t *models.StockTransfer, e *models.StockTransferEvent, debited, created []*models.InventoryBatch, stockDeltas map[uuid.UUID]int) (bool, error) {
+	var ok bool
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if ok, err = transition(tx, t, models.StockTransferInTransit, e); err != nil || !ok {
+			return err
+		}
+		for _, b := range debited {
+			if err := tx.Omit(clause.Associations).Save(b).Error; err != nil {
+				return err
+			}
+		}
+		if len(created) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&created).Error; err != nil {
+				return err
+			}
+		}
+		for productID, delta := range stockDeltas {
+			if err := tx.Model(&models.Product{}).Where("id = ?", productID).
+				Update("stock_quantity", gorm.Expr("stock_quantity + ?", delta)).Error; err != nil {
+				return err
+			}
+		}
+		for _, it := range t.Items {
+			for i := range it.Batches {
+				it.Batches[i].ItemID = it.ID
+			}
+			if len(it.Batches) > 0 {
+				if err := tx.Create(&it.Batches).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	return ok, err
+}
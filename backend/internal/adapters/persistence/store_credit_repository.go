@@ -0,0 +1,67 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type storeCreditRepo struct {
+	db *gorm.DB
+}
+
+func NewStoreCreditRepository(db *gorm.DB) outbound.StoreCreditRepository {
+	return &storeCreditRepo{db: db}
+}
+
+func (r *storeCreditRepo) Adjust(ctx context.Context, e *models.StoreCreditEntry) (bool, error) {
+	applied := false
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		res := execScoped(ctx, tx, "UPDATE customers SET store_credit_balance = store_credit_balance + ?, updated_at = NOW() WHERE id = ? AND store_credit_balance + ? >= 0",
+			e.Amount, e.CustomerID, e.Amount)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return nil
+		}
+		if err := tx.Model(&models.Customer{}).Where("id = ?", e.CustomerID).Pluck("store_credit_balance", &e.BalanceAfter).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(e).Error; err != nil {
+			return err
+		}
+		applied = true
+		return nil
+	})
+	return applied, err
+}
+
+func (r *storeCreditRepo) ListByCustomer(ctx context.Context, customerID uuid.UUID, limit, offset int) ([]*models.StoreCreditEntry, int64, error) {
+	scope := func() *gorm.DB {
+		return dbFrom(ctx, r.db).Model(&models.StoreCreditEntry{}).Where("customer_id = ?", customerID)
+	}
+	var total int64
+	if err := scope().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.StoreCreditEntry
+	q := scope().Order("created_at DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	err := q.Find(&list).Error
+	return list, total, err
+}
+
+func (r *storeCreditRepo) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]*models.StoreCreditEntry, error) {
+	var list []*models.StoreCreditEntry
+	err := dbFrom(ctx, r.db).Where("order_id = ?", orderID).Order("created_at ASC").Find(&list).Error
+	return list, err
+}
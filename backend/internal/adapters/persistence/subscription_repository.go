@@ -0,0 +1,52 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type subscriptionRepo struct {
+	db *gorm.DB
+}
+
+func NewSubscriptionRepository(db *gorm.DB) outbound.SubscriptionRepository {
+	return &subscriptionRepo{db: db}
+}
+
+func (r *subscriptionRepo) GetByPharmacyID(ctx context.Context, pharmacyID uuid.UUID) (*models.Subscription, error) {
+	var s models.Subscription
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).First(&s).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *subscriptionRepo) Save(ctx context.Context, s *models.Subscription) error {
+	return dbFrom(ctx, r.db).Save(s).Error
+}
+
+func (r *subscriptionRepo) Usage(ctx context.Context, pharmacyID uuid.UUID) (*models.SubscriptionUsage, error) {
+	var u models.SubscriptionUsage
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT
+			(SELECT COUNT(*) FROM products WHERE pharmacy_id = @id AND deleted_at IS NULL) AS products,
+			(SELECT COUNT(*) FROM users WHERE pharmacy_id = @id AND deleted_at IS NULL AND is_active AND role <> @buyer) AS users,
+			(SELECT COALESCE(SUM(size), 0) FROM uploads WHERE pharmacy_id = @id AND status IN @statuses) AS storage_bytes`,
+		map[string]interface{}{
+			"id": pharmacyID, "buyer": models.RoleStaff,
+			"statuses": []string{models.UploadStatusPending, models.UploadStatusCompleted},
+		}).Scan(&u).Error
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
@@ -0,0 +1,49 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type supplierRepo struct {
+	db *gorm.DB
+}
+
+func NewSupplierRepository(db *gorm.DB) outbound.SupplierRepository {
+	return &supplierRepo{db: db}
+}
+
+func (r *supplierRepo) Create(ctx context.Context, s *models.Supplier) error {
+	return dbFrom(ctx, r.db).Create(s).Error
+}
+
+func (r *supplierRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Supplier, error) {
+	var s models.Supplier
+	err := dbFrom(ctx, r.db).First(&s, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *supplierRepo) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, activeOnly bool) ([]*models.Supplier, error) {
+	var list []*models.Supplier
+	q := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID)
+	if activeOnly {
+		q = q.Where("is_active = ?", true)
+	}
+	err := q.Order("name ASC").Find(&list).Error
+	return list, err
+}
+
+func (r *supplierRepo) Update(ctx context.Context, s *models.Supplier) error {
+	return dbFrom(ctx, r.db).Save(s).Error
+}
+
+func (r *supplierRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFrom(ctx, r.db).Delete(&models.Supplier{}, "id = ?", id).Error
+}
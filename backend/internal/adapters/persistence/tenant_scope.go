@@ -0,0 +1,135 @@
+package persistence
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var uuidType = reflect.TypeOf(uuid.UUID{})
+
+// TenantScope is a gorm plugin enforcing outbound.WithTenant in every repository at once: queries, updates and
+// deletes on a model with a pharmacy_id column get "pharmacy_id = <tenant>" added, preloads included, and creating
+// or saving a row of another pharmacy fails with outbound.ErrCrossTenant. Contexts without a scope are untouched.
+// A scoped Save that updates no row fails with gorm.ErrRecordNotFound instead of falling back to an insert. Raw SQL
+// is not rewritten; those queries take the pharmacy as an argument, or run through execScoped.
+type TenantScope struct{}
+
+func (TenantScope) Name() string { return "tenant_scope" }
+
+func (TenantScope) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Query().Before("gorm:query").Register("tenant_scope:query", filterTenant); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("tenant_scope:row", filterTenant); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tenant_scope:update", func(db *gorm.DB) {
+		checkTenant(db)
+		filterTenant(db)
+	}); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("tenant_scope:saved", rejectMissedSave); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("tenant_scope:delete", filterTenant); err != nil {
+		return err
+	}
+	return cb.Create().Before("gorm:create").Register("tenant_scope:create", checkTenant)
+}
+
+// tenantField returns the statement's pharmacy scope and its model's pharmacy_id field; nil when either is missing.
+// Nullable pharmacy ids (rows shared by every pharmacy) are left alone.
+func tenantField(db *gorm.DB) (uuid.UUID, *schema.Field) {
+	tenant, ok := outbound.TenantFrom(db.Statement.Context)
+	if !ok || db.Error != nil || db.Statement.Schema == nil || db.Statement.SQL.Len() > 0 {
+		return uuid.Nil, nil
+	}
+	f := db.Statement.Schema.LookUpField("pharmacy_id")
+	if f == nil || f.FieldType != uuidType {
+		return uuid.Nil, nil
+	}
+	return tenant, f
+}
+
+func filterTenant(db *gorm.DB) {
+	tenant, f := tenantField(db)
+	if f == nil {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: f.DBName}, Value: tenant},
+	}})
+}
+
+// checkTenant fails the statement when a row being written carries another pharmacy's id.
+func checkTenant(db *gorm.DB) {
+	tenant, f := tenantField(db)
+	if f == nil {
+		return
+	}
+	rv := reflect.Indirect(db.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if !ownRow(db, f, reflect.Indirect(rv.Index(i)), tenant) {
+				_ = db.AddError(outbound.ErrCrossTenant)
+				return
+			}
+		}
+	case reflect.Struct:
+		if !ownRow(db, f, rv, tenant) {
+			_ = db.AddError(outbound.ErrCrossTenant)
+		}
+	}
+}
+
+// ownRow reports whether the row belongs to the tenant; a row without a pharmacy id yet is left to the database.
+func ownRow(db *gorm.DB, f *schema.Field, row reflect.Value, tenant uuid.UUID) bool {
+	if row.Kind() != reflect.Struct || row.Type() != db.Statement.Schema.ModelType {
+		return true
+	}
+	v, zero := f.ValueOf(db.Statement.Context, row)
+	if zero {
+		return true
+	}
+	id, ok := v.(uuid.UUID)
+	return !ok || id == tenant
+}
+
+// rejectMissedSave fails a scoped Save whose UPDATE matched no row: the row is another pharmacy's or gone, and gorm
+// would otherwise insert it (or, on a key conflict, overwrite the other pharmacy's row).
+func rejectMissedSave(db *gorm.DB) {
+	if _, ok := outbound.TenantFrom(db.Statement.Context); !ok || db.Error != nil || db.DryRun || db.RowsAffected != 0 {
+		return
+	}
+	if db.Statement.Schema == nil || db.Statement.Schema.LookUpField("pharmacy_id") == nil {
+		return
+	}
+	if reflect.Indirect(db.Statement.ReflectValue).Kind() != reflect.Struct {
+		return
+	}
+	for _, s := range db.Statement.Selects {
+		if s == "*" {
+			_ = db.AddError(gorm.ErrRecordNotFound)
+			return
+		}
+	}
+}
+
+// execScoped runs a raw UPDATE keyed by id, adding "AND pharmacy_id = <tenant>" when ctx is scoped. sql must end
+// in its WHERE clause.
+func execScoped(ctx context.Context, db *gorm.DB, sql string, vars ...interface{}) *gorm.DB {
+	if tenant, ok := outbound.TenantFrom(ctx); ok {
+		sql += " AND pharmacy_id = ?"
+		vars = append(vars, tenant)
+	}
+	return db.Exec(sql, vars...)
+}
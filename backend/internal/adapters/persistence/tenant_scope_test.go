@@ -0,0 +1,244 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// statements is the SQL a dry-run database would have sent, with the bind values of each statement.
+type statements struct {
+	sql  []string
+	vars [][]interface{}
+}
+
+// on returns the statements on table, for checking their WHERE clause.
+func (s *statements) on(table string) (sql []string, vars [][]interface{}) {
+	for i, q := range s.sql {
+		if strings.Contains(q, `"`+table+`"`) {
+			sql, vars = append(sql, q), append(vars, s.vars[i])
+		}
+	}
+	return sql, vars
+}
+
+// newTenantDB returns a dry-run database with TenantScope that records every statement instead of running it.
+func newTenantDB(t *testing.T) (*gorm.DB, *statements) {
+	t.Helper()
+	db, err := gorm.Open(postgres.Open("host=localhost user=test dbname=test sslmode=disable"), &gorm.Config{
+		DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true, Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.Use(TenantScope{}); err != nil {
+		t.Fatalf("use TenantScope: %v", err)
+	}
+	rec := &statements{}
+	record := func(db *gorm.DB) {
+		rec.sql = append(rec.sql, db.Statement.SQL.String())
+		rec.vars = append(rec.vars, db.Statement.Vars)
+	}
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Query().After("gorm:query").Register("test:record", record),
+		cb.Update().After("gorm:update").Register("test:record", record),
+		cb.Delete().After("gorm:delete").Register("test:record", record),
+		cb.Create().After("gorm:create").Register("test:record", record),
+		cb.Raw().After("gorm:raw").Register("test:record", record),
+	} {
+		if err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	return db, rec
+}
+
+// scopedTo reports whether every statement filters on the pharmacy.
+func scopedTo(t *testing.T, sql []string, vars [][]interface{}, pharmacyID uuid.UUID) {
+	t.Helper()
+	if len(sql) == 0 {
+		t.Fatal("no statements recorded")
+	}
+	for i, q := range sql {
+		if !strings.Contains(q, `."pharmacy_id" = $`) {
+			t.Errorf("statement not scoped to the tenant: %s", q)
+			continue
+		}
+		found := false
+		for _, v := range vars[i] {
+			if v == pharmacyID {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("statement %s binds %v, want the tenant %s", q, vars[i], pharmacyID)
+		}
+	}
+}
+
+func TestTenantScope_Reads(t *testing.T) {
+	db, rec := newTenantDB(t)
+	tenant := uuid.New()
+	ctx := outbound.WithTenant(context.Background(), tenant)
+
+	_, _ = NewProductRepository(db).GetByID(ctx, uuid.New())
+	sql, vars := rec.on("products")
+	scopedTo(t, sql, vars, tenant)
+
+	rec.sql, rec.vars = nil, nil
+	_, _ = NewOrderRepository(db).GetByID(ctx, uuid.New())
+	sql, vars = rec.on("orders")
+	scopedTo(t, sql, vars, tenant)
+
+	rec.sql, rec.vars = nil, nil
+	_, _ = NewCategoryRepository(db).GetByID(ctx, uuid.New())
+	sql, vars = rec.on("categories")
+	scopedTo(t, sql, vars, tenant)
+}
+
+func TestTenantScope_Writes(t *testing.T) {
+	db, rec := newTenantDB(t)
+	tenant, other := uuid.New(), uuid.New()
+	ctx := outbound.WithTenant(context.Background(), tenant)
+	products, orders := NewProductRepository(db), NewOrderRepository(db)
+
+	if err := products.Create(ctx, &models.Product{PharmacyID: other, Name: "Paracetamol"}); !errors.Is(err, outbound.ErrCrossTenant) {
+		t.Errorf("create another pharmacy's product: err = %v, want ErrCrossTenant", err)
+	}
+	if err := products.Update(ctx, &models.Product{ID: uuid.New(), PharmacyID: other, Name: "Paracetamol"}); !errors.Is(err, outbound.ErrCrossTenant) {
+		t.Errorf("save another pharmacy's product: err = %v, want ErrCrossTenant", err)
+	}
+	if err := orders.Update(ctx, &models.Order{ID: uuid.New(), PharmacyID: other}); !errors.Is(err, outbound.ErrCrossTenant) {
+		t.Errorf("save another pharmacy's order: err = %v, want ErrCrossTenant", err)
+	}
+	if sql, _ := rec.on("products"); len(sql) > 0 {
+		t.Errorf("cross-tenant writes reached the database: %v", sql)
+	}
+
+	rec.sql, rec.vars = nil, nil
+	if err := products.Update(ctx, &models.Product{ID: uuid.New(), PharmacyID: tenant, Name: "Paracetamol"}); err != nil {
+		t.Fatalf("save own product: %v", err)
+	}
+	sql, vars := rec.on("products")
+	scopedTo(t, sql[:1], vars[:1], tenant)
+
+	rec.sql, rec.vars = nil, nil
+	_ = products.Delete(ctx, uuid.New())
+	_ = orders.SetConversation(ctx, uuid.New(), uuid.New())
+	sql, vars = rec.on("products")
+	scopedTo(t, sql, vars, tenant)
+	sql, vars = rec.on("orders")
+	scopedTo(t, sql, vars, tenant)
+}
+
+func TestTenantScope_Unscoped(t *testing.T) {
+	db, rec := newTenantDB(t)
+	ctx := context.Background()
+	_, _ = NewProductRepository(db).GetByID(ctx, uuid.New())
+	_, _ = NewProductRepository(db).GetByID(outbound.WithoutTenant(outbound.WithTenant(ctx, uuid.New())), uuid.New())
+	for _, q := range rec.sql {
+		if strings.Contains(q, "pharmacy_id") {
+			t.Errorf("unscoped statement filtered on a pharmacy: %s", q)
+		}
+	}
+	if err := NewProductRepository(db).Create(ctx, &models.Product{PharmacyID: uuid.New(), Name: "Paracetamol"}); err != nil {
+		t.Errorf("unscoped create: %v", err)
+	}
+}
+
+func TestTenantScope_RawUpdates(t *testing.T) {
+	db, rec := newTenantDB(t)
+	tenant := uuid.New()
+	ctx := outbound.WithTenant(context.Background(), tenant)
+
+	_, _ = NewProductRepository(db).AdjustStock(ctx, uuid.New(), -2)
+	_, _ = NewInventoryBatchRepository(db).Draw(ctx, uuid.New(), 2)
+	_, _ = NewCustomerRepository(db).AdjustPoints(ctx, uuid.New(), -10)
+	if len(rec.sql) != 3 {
+		t.Fatalf("recorded %d statements, want 3", len(rec.sql))
+	}
+	for i, q := range rec.sql {
+		vars := rec.vars[i]
+		if !strings.HasSuffix(q, " AND pharmacy_id = $"+strconv.Itoa(len(vars))) || vars[len(vars)-1] != tenant {
+			t.Errorf("raw update not scoped to the tenant: %s %v", q, vars)
+		}
+	}
+
+	rec.sql, rec.vars = nil, nil
+	_, _ = NewProductRepository(db).AdjustStock(context.Background(), uuid.New(), -2)
+	if len(rec.sql) != 1 || strings.Contains(rec.sql[0], "pharmacy_id") {
+		t.Errorf("unscoped raw update filtered on a pharmacy: %v", rec.sql)
+	}
+}
+
+// resultPool is a connection that answers every statement with affected rows and records the SQL it was sent.
+type resultPool struct {
+	affected int64
+	sql      []string
+}
+
+func (p *resultPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (p *resultPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	p.sql = append(p.sql, query)
+	return driver.RowsAffected(p.affected), nil
+}
+
+func (p *resultPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	p.sql = append(p.sql, query)
+	return nil, errors.New("query not supported")
+}
+
+func (p *resultPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	p.sql = append(p.sql, query)
+	return nil
+}
+
+func TestTenantScope_SaveMatchingNoRow(t *testing.T) {
+	pool := &resultPool{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), &gorm.Config{
+		DisableAutomaticPing: true, SkipDefaultTransaction: true, Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := db.Use(TenantScope{}); err != nil {
+		t.Fatalf("use TenantScope: %v", err)
+	}
+	tenant := uuid.New()
+	ctx := outbound.WithTenant(context.Background(), tenant)
+	products := NewProductRepository(db)
+
+	// A row the scoped UPDATE does not match (another pharmacy's, or deleted) must not be inserted instead.
+	err = products.Update(ctx, &models.Product{ID: uuid.New(), Name: "Paracetamol"})
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("save matching no row: err = %v, want ErrRecordNotFound", err)
+	}
+	for _, q := range pool.sql {
+		if strings.HasPrefix(q, "INSERT") {
+			t.Errorf("scoped save fell back to an insert: %s", q)
+		}
+	}
+
+	pool.affected, pool.sql = 1, nil
+	if err := products.Update(ctx, &models.Product{ID: uuid.New(), PharmacyID: tenant, Name: "Paracetamol"}); err != nil {
+		t.Errorf("save own product: %v", err)
+	}
+	if len(pool.sql) != 1 || !strings.HasPrefix(pool.sql[0], "UPDATE") {
+		t.Errorf("save own product sent %v, want one UPDATE", pool.sql)
+	}
+}
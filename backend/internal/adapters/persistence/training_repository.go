@@ -0,0 +1,147 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type trainingRepo struct {
+	db *gorm.DB
+}
+
+func NewTrainingRepository(db *gorm.DB) outbound.TrainingRepository {
+	return &trainingRepo{db: db}
+}
+
+func (r *trainingRepo) CreateSOP(ctx context.Context, d *models.SOPDocument) error {
+	return dbFrom(ctx, r.db).Create(d).Error
+}
+
+func (r *trainingRepo) GetSOPByID(ctx context.Context, id uuid.UUID) (*models.SOPDocument, error) {
+	var d models.SOPDocument
+	if err := dbFrom(ctx, r.db).First(&d, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *trainingRepo) ListSOPs(ctx context.Context, pharmacyID uuid.UUID, activeOnly bool) ([]*models.SOPDocument, error) {
+	q := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID)
+	if activeOnly {
+		q = q.Where("is_active = ?", true)
+	}
+	var list []*models.SOPDocument
+	err := q.Order("created_at DESC").Find(&list).Error
+	return list, err
+}
+
+func (r *trainingRepo) UpdateSOP(ctx context.Context, d *models.SOPDocument) error {
+	return dbFrom(ctx, r.db).Save(d).Error
+}
+
+func (r *trainingRepo) DeleteSOP(ctx context.Context, id uuid.UUID) error {
+	return dbFrom(ctx, r.db).Delete(&models.SOPDocument{}, "id = ?", id).Error
+}
+
+func (r *trainingRepo) CreateSOPAck(ctx context.Context, a *models.SOPAck) error {
+	return dbFrom(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(a).Error
+}
+
+func (r *trainingRepo) HasAckedSOP(ctx context.Context, userID, sopID uuid.UUID) (bool, error) {
+	var count int64
+	err := dbFrom(ctx, r.db).Model(&models.SOPAck{}).
+		Where("user_id = ? AND sop_document_id = ?", userID, sopID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *trainingRepo) ListSOPAcks(ctx context.Context, sopIDs []uuid.UUID) ([]*models.SOPAck, error) {
+	var list []*models.SOPAck
+	if len(sopIDs) == 0 {
+		return list, nil
+	}
+	err := dbFrom(ctx, r.db).Where("sop_document_id IN ?", sopIDs).Find(&list).Error
+	return list, err
+}
+
+func (r *trainingRepo) CreateQuiz(ctx context.Context, q *models.TrainingQuiz) error {
+	return dbFrom(ctx, r.db).Create(q).Error
+}
+
+func (r *trainingRepo) GetQuizByID(ctx context.Context, id uuid.UUID) (*models.TrainingQuiz, error) {
+	var q models.TrainingQuiz
+	if err := dbFrom(ctx, r.db).First(&q, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+func (r *trainingRepo) GetActiveQuizFor(ctx context.Context, announcementID, sopID *uuid.UUID) (*models.TrainingQuiz, error) {
+	q := dbFrom(ctx, r.db).Where("is_active = ?", true)
+	switch {
+	case announcementID != nil:
+		q = q.Where("announcement_id = ?", *announcementID)
+	case sopID != nil:
+		q = q.Where("sop_document_id = ?", *sopID)
+	default:
+		return nil, nil
+	}
+	var quiz models.TrainingQuiz
+	if err := q.Order("created_at DESC").First(&quiz).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &quiz, nil
+}
+
+func (r *trainingRepo) ListQuizzes(ctx context.Context, pharmacyID uuid.UUID, activeOnly bool) ([]*models.TrainingQuiz, error) {
+	q := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID)
+	if activeOnly {
+		q = q.Where("is_active = ?", true)
+	}
+	var list []*models.TrainingQuiz
+	err := q.Order("created_at DESC").Find(&list).Error
+	return list, err
+}
+
+func (r *trainingRepo) UpdateQuiz(ctx context.Context, q *models.TrainingQuiz) error {
+	return dbFrom(ctx, r.db).Save(q).Error
+}
+
+func (r *trainingRepo) DeleteQuiz(ctx context.Context, id uuid.UUID) error {
+	return dbFrom(ctx, r.db).Delete(&models.TrainingQuiz{}, "id = ?", id).Error
+}
+
+func (r *trainingRepo) CreateAttempt(ctx context.Context, a *models.TrainingAttempt) error {
+	return dbFrom(ctx, r.db).Create(a).Error
+}
+
+func (r *trainingRepo) HasPassed(ctx context.Context, quizID, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := dbFrom(ctx, r.db).Model(&models.TrainingAttempt{}).
+		Where("quiz_id = ? AND user_id = ? AND passed = ?", quizID, userID, true).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *trainingRepo) AttemptStats(ctx context.Context, quizIDs []uuid.UUID) ([]*models.TrainingAttemptStat, error) {
+	var rows []*models.TrainingAttemptStat
+	if len(quizIDs) == 0 {
+		return rows, nil
+	}
+	err := dbFrom(ctx, r.db).Raw(`
+		SELECT quiz_id, user_id, COUNT(*) AS attempts, MAX(score_percent) AS best_score,
+			BOOL_OR(passed) AS passed, MAX(created_at) AS last_attempt_at
+		FROM training_attempts
+		WHERE quiz_id IN ?
+		GROUP BY quiz_id, user_id`, quizIDs).Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,68 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type uploadRepo struct {
+	db *gorm.DB
+}
+
+func NewUploadRepository(db *gorm.DB) outbound.UploadRepository {
+	return &uploadRepo{db: db}
+}
+
+func (r *uploadRepo) Create(ctx context.Context, u *models.Upload) error {
+	return dbFrom(ctx, r.db).Create(u).Error
+}
+
+func (r *uploadRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Upload, error) {
+	var u models.Upload
+	err := dbFrom(ctx, r.db).First(&u, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *uploadRepo) Update(ctx context.Context, u *models.Upload) error {
+	return dbFrom(ctx, r.db).Save(u).Error
+}
+
+func (r *uploadRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return dbFrom(ctx, r.db).Delete(&models.Upload{}, "id = ?", id).Error
+}
+
+func (r *uploadRepo) Advance(ctx context.Context, id uuid.UUID, from, to int64) (bool, error) {
+	res := dbFrom(ctx, r.db).Model(&models.Upload{}).
+		Where("id = ? AND status = ? AND received = ?", id, models.UploadStatusPending, from).
+		Updates(map[string]interface{}{"received": to, "updated_at": time.Now()})
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *uploadRepo) UsageBytes(ctx context.Context, pharmacyID uuid.UUID) (int64, error) {
+	var total int64
+	err := dbFrom(ctx, r.db).Model(&models.Upload{}).
+		Select("COALESCE(SUM(size), 0)").
+		Where("pharmacy_id = ? AND status IN ?", pharmacyID, []string{models.UploadStatusPending, models.UploadStatusCompleted}).
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *uploadRepo) ListExpired(ctx context.Context, before time.Time, limit int) ([]*models.Upload, error) {
+	var list []*models.Upload
+	err := dbFrom(ctx, r.db).
+		Where("status = ? AND expires_at < ?", models.UploadStatusPending, before).
+		Order("expires_at ASC").Limit(limit).Find(&list).Error
+	return list, err
+}
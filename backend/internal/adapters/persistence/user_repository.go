@@ -18,12 +18,12 @@ func NewUserRepository(db *gorm.DB) outbound.UserRepository {
 }
 
 func (r *userRepo) Create(ctx context.Context, u *models.User) error {
-	return r.db.WithContext(ctx).Create(u).Error
+	return dbFrom(ctx, r.db).Create(u).Error
 }
 
 func (r *userRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	var u models.User
-	err := r.db.WithContext(ctx).Preload("Pharmacy").First(&u, "id = ?", id).Error
+	err := dbFrom(ctx, r.db).Preload("Pharmacy").First(&u, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +32,7 @@ func (r *userRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.User, err
 
 func (r *userRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	var u models.User
-	err := r.db.WithContext(ctx).Preload("Pharmacy").Where("email = ?", email).First(&u).Error
+	err := dbFrom(ctx, r.db).Preload("Pharmacy").Where("email = ?", email).First(&u).Error
 	if err != nil {
 		return nil, err
 	}
@@ -41,10 +41,14 @@ func (r *userRepo) GetByEmail(ctx context.Context, email string) (*models.User,
 
 func (r *userRepo) GetByPharmacyID(ctx context.Context, pharmacyID uuid.UUID) ([]*models.User, error) {
 	var list []*models.User
-	err := r.db.WithContext(ctx).Where("pharmacy_id = ?", pharmacyID).Find(&list).Error
+	err := dbFrom(ctx, r.db).Where("pharmacy_id = ?", pharmacyID).Find(&list).Error
 	return list, err
 }
 
 func (r *userRepo) Update(ctx context.Context, u *models.User) error {
-	return r.db.WithContext(ctx).Save(u).Error
+	return dbFrom(ctx, r.db).Save(u).Error
+}
+
+func (r *userRepo) SetLicenseReminderDays(ctx context.Context, id uuid.UUID, days int) error {
+	return dbFrom(ctx, r.db).Model(&models.User{}).Where("id = ?", id).UpdateColumn("license_reminder_days", days).Error
 }
@@ -0,0 +1,81 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type walletTopUpRepo struct {
+	db *gorm.DB
+}
+
+func NewWalletTopUpRepository(db *gorm.DB) outbound.WalletTopUpRepository {
+	return &walletTopUpRepo{db: db}
+}
+
+func (r *walletTopUpRepo) Create(ctx context.Context, t *models.WalletTopUp) error {
+	return dbFrom(ctx, r.db).Omit("PaymentGateway").Create(t).Error
+}
+
+func (r *walletTopUpRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.WalletTopUp, error) {
+	var t models.WalletTopUp
+	err := dbFrom(ctx, r.db).Preload("PaymentGateway").Where("id = ?", id).First(&t).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *walletTopUpRepo) List(ctx context.Context, pharmacyID uuid.UUID, f outbound.WalletTopUpFilter, limit, offset int) ([]*models.WalletTopUp, int64, error) {
+	scope := func() *gorm.DB {
+		q := dbFrom(ctx, r.db).Model(&models.WalletTopUp{}).Where("pharmacy_id = ?", pharmacyID)
+		if f.CustomerID != nil {
+			q = q.Where("customer_id = ?", *f.CustomerID)
+		}
+		if f.UserID != nil {
+			q = q.Where("user_id = ?", *f.UserID)
+		}
+		if f.Status != "" {
+			q = q.Where("status = ?", f.Status)
+		}
+		return q
+	}
+	var total int64
+	if err := scope().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.WalletTopUp
+	q := scope().Preload("PaymentGateway").Order("created_at DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	err := q.Find(&list).Error
+	return list, total, err
+}
+
+func (r *walletTopUpRepo) Settle(ctx context.Context, t *models.WalletTopUp) (bool, error) {
+	res := dbFrom(ctx, r.db).Model(&models.WalletTopUp{}).
+		Where("id = ? AND status = ?", t.ID, models.WalletTopUpPending).
+		Updates(map[string]interface{}{
+			"status":         t.Status,
+			"reference":      t.Reference,
+			"failure_reason": t.FailureReason,
+			"entry_id":       t.EntryID,
+			"settled_by":     t.SettledBy,
+			"settled_at":     t.SettledAt,
+			"updated_at":     time.Now(),
+		})
+	return res.RowsAffected == 1, res.Error
+}
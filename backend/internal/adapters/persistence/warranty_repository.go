@@ -0,0 +1,99 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type warrantyRepo struct {
+	db *gorm.DB
+}
+
+func NewWarrantyRepository(db *gorm.DB) outbound.WarrantyRepository {
+	return &warrantyRepo{db: db}
+}
+
+func (r *warrantyRepo) CreateBatch(ctx context.Context, warranties []*models.Warranty) error {
+	if len(warranties) == 0 {
+		return nil
+	}
+	return dbFrom(ctx, r.db).Omit("Product", "Claims").Create(&warranties).Error
+}
+
+func (r *warrantyRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Warranty, error) {
+	var w models.Warranty
+	err := dbFrom(ctx, r.db).Preload("Product").Preload("Claims", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at DESC")
+	}).First(&w, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (r *warrantyRepo) FindBySerial(ctx context.Context, pharmacyID uuid.UUID, serialNumber string) ([]*models.Warranty, error) {
+	var list []*models.Warranty
+	err := dbFrom(ctx, r.db).Preload("Product").Preload("Claims", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at DESC")
+	}).Where("pharmacy_id = ? AND serial_number = ?", pharmacyID, serialNumber).Order("starts_at DESC").Find(&list).Error
+	return list, err
+}
+
+func (r *warrantyRepo) VoidOrder(ctx context.Context, orderID uuid.UUID, at time.Time) error {
+	return dbFrom(ctx, r.db).Model(&models.Warranty{}).Where("order_id = ? AND voided_at IS NULL", orderID).
+		Updates(map[string]interface{}{"voided_at": at, "updated_at": at}).Error
+}
+
+func (r *warrantyRepo) CreateClaim(ctx context.Context, c *models.WarrantyClaim) error {
+	return dbFrom(ctx, r.db).Omit("Warranty").Create(c).Error
+}
+
+func (r *warrantyRepo) GetClaim(ctx context.Context, id uuid.UUID) (*models.WarrantyClaim, error) {
+	var c models.WarrantyClaim
+	err := dbFrom(ctx, r.db).Preload("Warranty").Preload("Warranty.Product").First(&c, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *warrantyRepo) ListClaims(ctx context.Context, pharmacyID uuid.UUID, status models.WarrantyClaimStatus, limit, offset int) ([]*models.WarrantyClaim, int64, error) {
+	q := dbFrom(ctx, r.db).Model(&models.WarrantyClaim{}).Where("pharmacy_id = ?", pharmacyID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []*models.WarrantyClaim
+	err := q.Preload("Warranty").Preload("Warranty.Product").Order("created_at DESC").Limit(limit).Offset(offset).Find(&list).Error
+	return list, total, err
+}
+
+func (r *warrantyRepo) UpdateClaim(ctx context.Context, c *models.WarrantyClaim, from models.WarrantyClaimStatus) (bool, error) {
+	res := dbFrom(ctx, r.db).Model(&models.WarrantyClaim{}).Where("id = ? AND status = ?", c.ID, from).
+		Updates(map[string]interface{}{
+			"status":            c.Status,
+			"vendor_reference":  c.VendorReference,
+			"resolution":        c.Resolution,
+			"note":              c.Note,
+			"sent_to_vendor_at": c.SentToVendorAt,
+			"resolved_by":       c.ResolvedBy,
+			"resolved_at":       c.ResolvedAt,
+			"updated_at":        gorm.Expr("NOW()"),
+		})
+	return res.RowsAffected == 1, res.Error
+}
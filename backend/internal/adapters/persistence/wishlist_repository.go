@@ -0,0 +1,58 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type wishlistRepo struct {
+	db *gorm.DB
+}
+
+func NewWishlistRepository(db *gorm.DB) outbound.WishlistRepository {
+	return &wishlistRepo{db: db}
+}
+
+func (r *wishlistRepo) Add(ctx context.Context, w *models.WishlistItem) error {
+	return dbFrom(ctx, r.db).Omit("Product").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "product_id"}},
+		DoNothing: true,
+	}).Create(w).Error
+}
+
+func (r *wishlistRepo) Remove(ctx context.Context, userID, productID uuid.UUID) (bool, error) {
+	res := dbFrom(ctx, r.db).Where("user_id = ? AND product_id = ?", userID, productID).Delete(&models.WishlistItem{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *wishlistRepo) ListByUser(ctx context.Context, pharmacyID, userID uuid.UUID) ([]*models.WishlistItem, error) {
+	var list []*models.WishlistItem
+	// Unscoped so a deleted product still shows by name, as unavailable.
+	err := dbFrom(ctx, r.db).Preload("Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).Preload("Product.Images").
+		Where("pharmacy_id = ? AND user_id = ?", pharmacyID, userID).
+		Order("created_at DESC").Find(&list).Error
+	return list, err
+}
+
+func (r *wishlistRepo) ListChanged(ctx context.Context, limit int) ([]*models.WishlistItem, error) {
+	var list []*models.WishlistItem
+	err := dbFrom(ctx, r.db).Preload("Product").
+		Joins("JOIN products p ON p.id = wishlist_items.product_id AND p.deleted_at IS NULL AND p.is_active").
+		Where("(p.stock_quantity > 0) <> wishlist_items.seen_in_stock OR p.unit_price <> wishlist_items.seen_price").
+		Order("wishlist_items.created_at ASC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (r *wishlistRepo) SetSeen(ctx context.Context, id uuid.UUID, inStock bool, price float64, notifiedAt *time.Time) error {
+	updates := map[string]interface{}{"seen_in_stock": inStock, "seen_price": price}
+	if notifiedAt != nil {
+		updates["notified_at"] = *notifiedAt
+	}
+	return dbFrom(ctx, r.db).Model(&models.WishlistItem{}).Where("id = ?", id).Updates(updates).Error
+}
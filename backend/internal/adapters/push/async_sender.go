@@ -0,0 +1,101 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"go.uber.org/zap"
+)
+
+const asyncSendTimeout = 30 * time.Second
+
+var (
+	errPushQueueFull   = errors.New("push queue is full")
+	errPushQueueClosed = errors.New("push queue is closed")
+)
+
+// AsyncSender queues push notifications and sends them from background workers, like sms.AsyncSender.
+// Delivery errors are logged; tokens the provider rejects as invalid are passed to onInvalidToken so
+// they can be forgotten.
+type AsyncSender struct {
+	inner          outbound.PushSender
+	onInvalidToken func(token string)
+	queue          chan *outbound.PushMessage
+	wg             sync.WaitGroup
+	mu             sync.RWMutex
+	closed         bool
+	logger         *zap.Logger
+}
+
+func NewAsyncSender(inner outbound.PushSender, workers, queueSize int, onInvalidToken func(token string), logger *zap.Logger) *AsyncSender {
+	if workers <= 0 {
+		workers = 1
+	}
+	if queueSize <= 0 {
+		queueSize = 100
+	}
+	s := &AsyncSender{inner: inner, onInvalidToken: onInvalidToken, queue: make(chan *outbound.PushMessage, queueSize), logger: logger}
+	for i := 0; i < workers; i++ {
+		s.wg.Add(1)
+		go s.work()
+	}
+	return s
+}
+
+func (s *AsyncSender) Send(ctx context.Context, msg *outbound.PushMessage) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return errPushQueueClosed
+	}
+	cp := *msg
+	select {
+	case s.queue <- &cp:
+		return nil
+	default:
+		s.logger.Warn("push queue full, dropping message", zap.String("title", msg.Title))
+		return errPushQueueFull
+	}
+}
+
+// Close stops accepting messages and waits for queued ones to be sent, or for ctx to expire.
+func (s *AsyncSender) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *AsyncSender) work() {
+	defer s.wg.Done()
+	for msg := range s.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), asyncSendTimeout)
+		err := s.inner.Send(ctx, msg)
+		cancel()
+		switch {
+		case err == nil:
+		case errors.Is(err, outbound.ErrPushTokenInvalid):
+			if s.onInvalidToken != nil {
+				s.onInvalidToken(msg.Token)
+			}
+		default:
+			s.logger.Warn("push delivery failed", zap.Error(err), zap.String("title", msg.Title))
+		}
+	}
+}
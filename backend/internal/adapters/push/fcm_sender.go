@@ -0,0 +1,205 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/config"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	fcmScope       = "https://www.googleapis.com/auth/firebase.messaging"
+	fcmSendURL     = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+)
+
+// serviceAccount is the subset of a Google service-account key file the sender needs.
+type serviceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// FCMSender sends push notifications through the Firebase Cloud Messaging HTTP v1 API. It signs a
+// service-account JWT, exchanges it for an OAuth access token and caches the token until shortly
+// before it expires.
+type FCMSender struct {
+	client      *http.Client
+	projectID   string
+	clientEmail string
+	tokenURL    string
+	key         *rsa.PrivateKey
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCMSender reads the service-account key file named by FCM_CREDENTIALS_FILE.
+func NewFCMSender(cfg config.PushConfig) (*FCMSender, error) {
+	raw, err := os.ReadFile(cfg.FCMCredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("read fcm credentials: %w", err)
+	}
+	var sa serviceAccount
+	if err := json.Unmarshal(raw, &sa); err != nil {
+		return nil, fmt.Errorf("parse fcm credentials: %w", err)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(sa.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("parse fcm private key: %w", err)
+	}
+	projectID := cfg.FCMProjectID
+	if projectID == "" {
+		projectID = sa.ProjectID
+	}
+	if projectID == "" || sa.ClientEmail == "" {
+		return nil, fmt.Errorf("fcm credentials need project_id and client_email")
+	}
+	tokenURL := sa.TokenURI
+	if tokenURL == "" {
+		tokenURL = googleTokenURL
+	}
+	return &FCMSender{
+		client:      &http.Client{Timeout: cfg.Timeout},
+		projectID:   projectID,
+		clientEmail: sa.ClientEmail,
+		tokenURL:    tokenURL,
+		key:         key,
+	}, nil
+}
+
+type fcmNotification struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+type fcmWebpush struct {
+	FCMOptions struct {
+		Link string `json:"link"`
+	} `json:"fcm_options"`
+}
+
+type fcmMessage struct {
+	Token        string            `json:"token"`
+	Notification fcmNotification   `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+	Webpush      *fcmWebpush       `json:"webpush,omitempty"`
+}
+
+type fcmError struct {
+	Error struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+func (s *FCMSender) Send(ctx context.Context, msg *outbound.PushMessage) error {
+	token, err := s.token(ctx)
+	if err != nil {
+		return err
+	}
+	m := fcmMessage{Token: msg.Token, Notification: fcmNotification{Title: msg.Title, Body: msg.Body}, Data: msg.Data}
+	if msg.Link != "" {
+		m.Webpush = &fcmWebpush{}
+		m.Webpush.FCMOptions.Link = msg.Link
+	}
+	payload, err := json.Marshal(map[string]fcmMessage{"message": m})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(fcmSendURL, url.PathEscape(s.projectID)), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fcm request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+	var fe fcmError
+	_ = json.Unmarshal(body, &fe)
+	if resp.StatusCode == http.StatusNotFound {
+		return outbound.ErrPushTokenInvalid
+	}
+	for _, d := range fe.Error.Details {
+		if d.ErrorCode == "UNREGISTERED" {
+			return outbound.ErrPushTokenInvalid
+		}
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		s.mu.Lock()
+		s.accessToken = ""
+		s.mu.Unlock()
+	}
+	return fmt.Errorf("fcm: status %d: %s", resp.StatusCode, strings.TrimSpace(fe.Error.Message))
+}
+
+// token returns a cached OAuth access token, fetching a new one when it is missing or about to expire.
+func (s *FCMSender) token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.accessToken != "" && time.Now().Before(s.expiresAt.Add(-time.Minute)) {
+		return s.accessToken, nil
+	}
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   s.clientEmail,
+		"scope": fcmScope,
+		"aud":   s.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(s.key)
+	if err != nil {
+		return "", fmt.Errorf("sign fcm assertion: %w", err)
+	}
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fcm token request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("fcm token: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("fcm token: %w", err)
+	}
+	s.accessToken = tok.AccessToken
+	s.expiresAt = now.Add(time.Duration(tok.ExpiresIn) * time.Second)
+	return s.accessToken, nil
+}
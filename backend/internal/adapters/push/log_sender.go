@@ -0,0 +1,22 @@
+package push
+
+import (
+	"context"
+
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"go.uber.org/zap"
+)
+
+// LogSender writes push notifications to the application log instead of sending them. Used in development (PUSH_PROVIDER=log).
+type LogSender struct {
+	logger *zap.Logger
+}
+
+func NewLogSender(logger *zap.Logger) *LogSender {
+	return &LogSender{logger: logger}
+}
+
+func (s *LogSender) Send(ctx context.Context, msg *outbound.PushMessage) error {
+	s.logger.Info("push (log sender)", zap.String("title", msg.Title), zap.String("body", msg.Body), zap.Any("data", msg.Data))
+	return nil
+}
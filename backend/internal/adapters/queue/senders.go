@@ -0,0 +1,28 @@
+// Package queue adapts the durable delivery queue to the outbound sender ports, so services keep calling
+// EmailSender and SMSSender while the actual sending happens in the queue's workers.
+package queue
+
+import (
+	"context"
+
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+)
+
+// EmailSender stores emails in the delivery queue. Send fails only when the job cannot be stored.
+type EmailSender struct{ queue inbound.DeliveryQueueService }
+
+func NewEmailSender(q inbound.DeliveryQueueService) *EmailSender { return &EmailSender{queue: q} }
+
+func (s *EmailSender) Send(ctx context.Context, msg *outbound.EmailMessage) error {
+	return s.queue.EnqueueEmail(ctx, msg)
+}
+
+// SMSSender stores text messages in the delivery queue.
+type SMSSender struct{ queue inbound.DeliveryQueueService }
+
+func NewSMSSender(q inbound.DeliveryQueueService) *SMSSender { return &SMSSender{queue: q} }
+
+func (s *SMSSender) Send(ctx context.Context, msg *outbound.SMSMessage) error {
+	return s.queue.EnqueueSMS(ctx, msg)
+}
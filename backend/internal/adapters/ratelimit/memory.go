@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+)
+
+// sweepEvery is how many Take calls pass between removals of full (idle) buckets.
+const sweepEvery = 1024
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time // when the bucket is back to limit tokens and can be dropped
+}
+
+// MemoryLimiter keeps buckets in process memory. Each API instance counts separately, so use Redis when running
+// more than one.
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	calls   int
+	now     func() time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (m *MemoryLimiter) Take(ctx context.Context, key string, limit int, window time.Duration) (outbound.RateLimitDecision, error) {
+	now := m.now()
+	rate := float64(limit) / window.Seconds() // tokens per second
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+	if m.calls%sweepEvery == 0 {
+		for k, b := range m.buckets {
+			if !now.Before(b.full) {
+				delete(m.buckets, k)
+			}
+		}
+	}
+	b := m.buckets[key]
+	if b == nil {
+		b = &bucket{tokens: float64(limit), updated: now}
+		m.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+	d := outbound.RateLimitDecision{}
+	if b.tokens >= 1 {
+		b.tokens--
+		d.Allowed = true
+	} else {
+		d.RetryAfter = time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	d.Remaining = int(b.tokens)
+	b.full = now.Add(time.Duration((float64(limit) - b.tokens) / rate * float64(time.Second)))
+	return d, nil
+}
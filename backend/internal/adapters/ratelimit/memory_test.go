@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiter_Take(t *testing.T) {
+	type step struct {
+		key           string
+		at            time.Duration // since the start
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}
+	// Two requests per second: one token every 500ms.
+	cases := []struct {
+		name  string
+		steps []step
+	}{
+		{"burst then refill", []step{
+			{"a", 0, true, 1, 0},
+			{"a", 0, true, 0, 0},
+			{"a", 0, false, 0, 500 * time.Millisecond},
+			{"a", 250 * time.Millisecond, false, 0, 250 * time.Millisecond},
+			{"a", 500 * time.Millisecond, true, 0, 0},
+		}},
+		{"idle bucket caps at the limit", []step{
+			{"a", 0, true, 1, 0},
+			{"a", 0, true, 0, 0},
+			{"a", time.Minute, true, 1, 0},
+			{"a", time.Minute, true, 0, 0},
+			{"a", time.Minute, false, 0, 500 * time.Millisecond},
+		}},
+		{"keys count separately", []step{
+			{"a", 0, true, 1, 0},
+			{"a", 0, true, 0, 0},
+			{"b", 0, true, 1, 0},
+			{"a", 0, false, 0, 500 * time.Millisecond},
+		}},
+	}
+	for _, tc := range cases {
+		start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+		now := start
+		m := NewMemoryLimiter()
+		m.now = func() time.Time { return now }
+		for i, s := range tc.steps {
+			now = start.Add(s.at)
+			d, err := m.Take(context.Background(), s.key, 2, time.Second)
+			if err != nil {
+				t.Fatalf("%s step %d: %v", tc.name, i, err)
+			}
+			if d.Allowed != s.wantAllowed || d.Remaining != s.wantRemaining || d.RetryAfter != s.wantRetry {
+				t.Errorf("%s step %d: got allowed=%v remaining=%d retry=%v, want %v %d %v",
+					tc.name, i, d.Allowed, d.Remaining, d.RetryAfter, s.wantAllowed, s.wantRemaining, s.wantRetry)
+			}
+		}
+	}
+}
+
+func TestMemoryLimiter_SweepsFullBuckets(t *testing.T) {
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	m := NewMemoryLimiter()
+	m.now = func() time.Time { return now }
+	_, _ = m.Take(context.Background(), "idle", 2, time.Second)
+	now = now.Add(time.Second)
+	for i := 1; i < sweepEvery; i++ {
+		_, _ = m.Take(context.Background(), "busy", 1000, time.Second)
+	}
+	if _, ok := m.buckets["idle"]; ok {
+		t.Error("full bucket was not swept")
+	}
+	if _, ok := m.buckets["busy"]; !ok {
+		t.Error("bucket in use was swept")
+	}
+}
@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript takes one token from the bucket at KEYS[1] (ARGV: limit, window in ms) and returns
+// {allowed, remaining, retry_after_ms}. It reads the Redis clock so instances with skewed clocks agree, and lets
+// the key expire once the bucket would be full again.
+var tokenBucketScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local rate = limit / window
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = limit
+	ts = now
+end
+tokens = math.min(limit, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((limit - tokens) / rate) + 1000)
+return {allowed, math.floor(tokens), retry}
+`)
+
+// maxIdleConns is how many connections are kept open between calls.
+const maxIdleConns = 8
+
+// RedisLimiter keeps buckets in Redis so all API instances share them. The script runs with EVALSHA, falling back
+// to EVAL after a restart flushed the script cache.
+type RedisLimiter struct {
+	client *redis.Client
+}
+
+// NewRedisLimiter returns a limiter for the server at addr (host:port). Connections are opened on first use;
+// call Ping to check the server at startup. timeout bounds dialing and each call.
+func NewRedisLimiter(addr, password string, db int, timeout time.Duration) *RedisLimiter {
+	return &RedisLimiter{client: redis.NewClient(&redis.Options{
+		Addr:         addr,
+		Password:     password,
+		DB:           db,
+		DialTimeout:  timeout,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
+		MaxIdleConns: maxIdleConns,
+	})}
+}
+
+func (r *RedisLimiter) Take(ctx context.Context, key string, limit int, window time.Duration) (outbound.RateLimitDecision, error) {
+	nums, err := tokenBucketScript.Run(ctx, r.client, []string{key}, limit, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return outbound.RateLimitDecision{}, err
+	}
+	if len(nums) != 3 {
+		return outbound.RateLimitDecision{}, fmt.Errorf("redis rate limit: unexpected reply %v", nums)
+	}
+	return outbound.RateLimitDecision{
+		Allowed:    nums[0] == 1,
+		Remaining:  int(nums[1]),
+		RetryAfter: time.Duration(nums[2]) * time.Millisecond,
+	}, nil
+}
+
+// Ping checks that the server is reachable and the credentials work.
+func (r *RedisLimiter) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+// Close closes the connections.
+func (r *RedisLimiter) Close() error {
+	return r.client.Close()
+}
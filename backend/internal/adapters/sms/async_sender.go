@@ -0,0 +1,92 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"go.uber.org/zap"
+)
+
+const asyncSendTimeout = 30 * time.Second
+
+var (
+	errSMSQueueFull   = errors.New("sms queue is full")
+	errSMSQueueClosed = errors.New("sms queue is closed")
+)
+
+// AsyncSender queues SMS and sends them from background workers, like email.AsyncSender, so gateway
+// latency never reaches API requests. Delivery errors are logged.
+type AsyncSender struct {
+	inner  outbound.SMSSender
+	queue  chan *outbound.SMSMessage
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+	logger *zap.Logger
+}
+
+func NewAsyncSender(inner outbound.SMSSender, workers, queueSize int, logger *zap.Logger) *AsyncSender {
+	if workers <= 0 {
+		workers = 1
+	}
+	if queueSize <= 0 {
+		queueSize = 100
+	}
+	s := &AsyncSender{inner: inner, queue: make(chan *outbound.SMSMessage, queueSize), logger: logger}
+	for i := 0; i < workers; i++ {
+		s.wg.Add(1)
+		go s.work()
+	}
+	return s
+}
+
+func (s *AsyncSender) Send(ctx context.Context, msg *outbound.SMSMessage) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return errSMSQueueClosed
+	}
+	cp := *msg
+	select {
+	case s.queue <- &cp:
+		return nil
+	default:
+		s.logger.Warn("sms queue full, dropping message", zap.String("to", msg.To))
+		return errSMSQueueFull
+	}
+}
+
+// Close stops accepting messages and waits for queued ones to be sent, or for ctx to expire.
+func (s *AsyncSender) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *AsyncSender) work() {
+	defer s.wg.Done()
+	for msg := range s.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), asyncSendTimeout)
+		if err := s.inner.Send(ctx, msg); err != nil {
+			s.logger.Warn("sms delivery failed", zap.Error(err), zap.String("to", msg.To))
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,22 @@
+package sms
+
+import (
+	"context"
+
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"go.uber.org/zap"
+)
+
+// LogSender writes SMS to the application log instead of sending them. Used in development (SMS_PROVIDER=log).
+type LogSender struct {
+	logger *zap.Logger
+}
+
+func NewLogSender(logger *zap.Logger) *LogSender {
+	return &LogSender{logger: logger}
+}
+
+func (s *LogSender) Send(ctx context.Context, msg *outbound.SMSMessage) error {
+	s.logger.Info("sms (log sender)", zap.String("to", msg.To), zap.String("body", msg.Body))
+	return nil
+}
@@ -0,0 +1,68 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/config"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+)
+
+const sparrowEndpoint = "https://api.sparrowsms.com/v2/sms/"
+
+// SparrowSender sends SMS through Sparrow SMS (Nepal). Sparrow expects 10-digit local numbers, so a
+// leading +977 / 977 country code is stripped.
+type SparrowSender struct {
+	client *http.Client
+	token  string
+	from   string
+}
+
+func NewSparrowSender(cfg config.SMSConfig) *SparrowSender {
+	return &SparrowSender{
+		client: &http.Client{Timeout: cfg.Timeout},
+		token:  cfg.SparrowToken,
+		from:   cfg.From,
+	}
+}
+
+func (s *SparrowSender) Send(ctx context.Context, msg *outbound.SMSMessage) error {
+	to := digitsOnly(msg.To)
+	if len(to) == 13 && strings.HasPrefix(to, "977") {
+		to = to[3:]
+	}
+	form := url.Values{}
+	form.Set("token", s.token)
+	form.Set("from", s.from)
+	form.Set("to", to)
+	form.Set("text", msg.Body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sparrowEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sparrow request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sparrow: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func digitsOnly(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,56 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/config"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+)
+
+const twilioBaseURL = "https://api.twilio.com/2010-04-01"
+
+// TwilioSender sends SMS through the Twilio Messages API. Numbers must be in E.164 form (+9779800000000).
+type TwilioSender struct {
+	client     *http.Client
+	accountSID string
+	authToken  string
+	from       string
+}
+
+func NewTwilioSender(cfg config.SMSConfig) *TwilioSender {
+	return &TwilioSender{
+		client:     &http.Client{Timeout: cfg.Timeout},
+		accountSID: cfg.TwilioAccountSID,
+		authToken:  cfg.TwilioAuthToken,
+		from:       cfg.From,
+	}
+}
+
+func (s *TwilioSender) Send(ctx context.Context, msg *outbound.SMSMessage) error {
+	form := url.Values{}
+	form.Set("To", msg.To)
+	form.Set("From", s.from)
+	form.Set("Body", msg.Body)
+	endpoint := twilioBaseURL + "/Accounts/" + url.PathEscape(s.accountSID) + "/Messages.json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.accountSID, s.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("twilio request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("twilio: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CWebPEncoder encodes images as WebP by running libwebp's cwebp on a lossless PNG copy.
+type CWebPEncoder struct {
+	bin string
+}
+
+// NewCWebPEncoder resolves the cwebp binary (a path, or a name looked up on PATH). It fails when cwebp is not
+// installed, so callers can fall back to JPEG.
+func NewCWebPEncoder(path string) (*CWebPEncoder, error) {
+	bin, err := exec.LookPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("cwebp not found: %w", err)
+	}
+	return &CWebPEncoder{bin: bin}, nil
+}
+
+func (e *CWebPEncoder) EncodeWebP(ctx context.Context, img image.Image, quality int) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "cwebp-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	in, out := filepath.Join(dir, "in.png"), filepath.Join(dir, "out.webp")
+	var buf bytes.Buffer
+	if err := (&png.Encoder{CompressionLevel: png.BestSpeed}).Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(in, buf.Bytes(), 0600); err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, e.bin, "-quiet", "-metadata", "none", "-q", strconv.Itoa(quality), in, "-o", out)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("cwebp: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return os.ReadFile(out)
+}
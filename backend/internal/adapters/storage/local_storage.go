@@ -2,45 +2,158 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"io"
+	"io/fs"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/infrastructure/config"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/signing"
 )
 
-// LocalStorage saves files to the local filesystem under FS.LocalBaseDir.
+// ErrPrivateFilesDisabled is returned for private files when FS_SIGNING_SECRET is not set.
+var ErrPrivateFilesDisabled = errors.New("private files are disabled: FS_SIGNING_SECRET is not set")
+
+// LocalStorage saves files to the local filesystem under FS.LocalBaseDir, and private files under
+// FS.LocalPrivateDir, which is only reachable through signed links to FS.LocalPrivateURL. Without a signing
+// secret private files are refused, since a link signed with an empty key could be forged by anyone.
 type LocalStorage struct {
-	baseDir string
-	baseURL string
+	baseDir    string
+	baseURL    string
+	privateDir string
+	privateURL string
+	secret     []byte
+	ttl        time.Duration
 }
 
 func NewLocalStorage(cfg config.FSConfig) *LocalStorage {
 	return &LocalStorage{
-		baseDir: cfg.LocalBaseDir,
-		baseURL: cfg.LocalBaseURL,
+		baseDir:    cfg.LocalBaseDir,
+		baseURL:    cfg.LocalBaseURL,
+		privateDir: cfg.LocalPrivateDir,
+		privateURL: cfg.LocalPrivateURL,
+		secret:     []byte(cfg.SigningSecret),
+		ttl:        cfg.SignedURLTTL,
 	}
 }
 
 func (s *LocalStorage) Save(ctx context.Context, path string, body io.Reader, _ string) (string, error) {
-	fullPath := filepath.Join(s.baseDir, path)
+	if err := writeFile(filepath.Join(s.baseDir, path), body); err != nil {
+		return "", err
+	}
+	// Return URL path for serving (e.g. /uploads/photos/2025/02/uuid.jpg)
+	url := s.baseURL + "/" + path
+	if filepath.Separator == '\\' {
+		url = s.baseURL + "/" + filepath.ToSlash(path)
+	}
+	return url, nil
+}
+
+// SavePrivate stores the file under the private directory, which is not served statically.
+func (s *LocalStorage) SavePrivate(ctx context.Context, path string, body io.Reader, _ string) (string, error) {
+	if len(s.secret) == 0 {
+		return "", ErrPrivateFilesDisabled
+	}
+	if !filepath.IsLocal(path) {
+		return "", errors.New("invalid private file path")
+	}
+	if err := writeFile(filepath.Join(s.privateDir, path), body); err != nil {
+		return "", err
+	}
+	return outbound.PrivateFileRefPrefix + filepath.ToSlash(path), nil
+}
+
+// SignedURL links a private reference to the private file route with a token binding its path to an expiry.
+func (s *LocalStorage) SignedURL(ctx context.Context, ref string) (string, error) {
+	path, ok := strings.CutPrefix(ref, outbound.PrivateFileRefPrefix)
+	if !ok {
+		return ref, nil
+	}
+	if len(s.secret) == 0 {
+		return "", ErrPrivateFilesDisabled
+	}
+	token := signing.Sign(s.secret, path, time.Now().Add(s.ttl))
+	return s.privateURL + "/" + path + "?token=" + url.QueryEscape(token), nil
+}
+
+// PrivateRef recognises links issued by SignedURL, whether or not their token is still valid.
+func (s *LocalStorage) PrivateRef(link string) (string, bool) {
+	path, ok := strings.CutPrefix(link, s.privateURL+"/")
+	if !ok {
+		return "", false
+	}
+	path, _, _ = strings.Cut(path, "?")
+	return outbound.PrivateFileRefPrefix + path, path != ""
+}
+
+// OpenPrivate opens the private file at path when token is a valid, unexpired signature for it.
+func (s *LocalStorage) OpenPrivate(path, token string) (*os.File, error) {
+	if len(s.secret) == 0 {
+		return nil, ErrPrivateFilesDisabled
+	}
+	if err := signing.Verify(s.secret, path, token, time.Now()); err != nil {
+		return nil, err
+	}
+	rel := filepath.FromSlash(path)
+	if !filepath.IsLocal(rel) {
+		return nil, signing.ErrInvalidToken
+	}
+	return os.Open(filepath.Join(s.privateDir, rel))
+}
+
+// Exists checks the file behind a URL returned by Save. Other URLs are not ours and count as existing.
+func (s *LocalStorage) Exists(ctx context.Context, url string) (bool, error) {
+	prefix := s.baseURL + "/"
+	if !strings.HasPrefix(url, prefix) {
+		return true, nil
+	}
+	rel := filepath.FromSlash(strings.TrimPrefix(url, prefix))
+	if rel == "" || strings.HasPrefix(filepath.Clean(rel), "..") {
+		return true, nil
+	}
+	_, err := os.Stat(filepath.Join(s.baseDir, rel))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Delete removes a file issued by Save or SavePrivate.
+func (s *LocalStorage) Delete(ctx context.Context, url string) error {
+	dir, rel := s.privateDir, ""
+	if path, ok := strings.CutPrefix(url, outbound.PrivateFileRefPrefix); ok {
+		rel = path
+	} else if path, ok := strings.CutPrefix(url, s.baseURL+"/"); ok {
+		dir, rel = s.baseDir, path
+	}
+	rel = filepath.FromSlash(rel)
+	if !filepath.IsLocal(rel) {
+		return errors.New("not a stored file: " + url)
+	}
+	if err := os.Remove(filepath.Join(dir, rel)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func writeFile(fullPath string, body io.Reader) error {
 	dir := filepath.Dir(fullPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return "", err
+		return err
 	}
 	f, err := os.Create(fullPath)
 	if err != nil {
-		return "", err
+		return err
 	}
 	defer f.Close()
 	if _, err := io.Copy(f, body); err != nil {
 		_ = os.Remove(fullPath)
-		return "", err
+		return err
 	}
-	// Return URL path for serving (e.g. /uploads/photos/2025/02/uuid.jpg)
-	url := s.baseURL + "/" + path
-	if filepath.Separator == '\\' {
-		url = s.baseURL + "/" + filepath.ToSlash(path)
-	}
-	return url, nil
+	return nil
 }
@@ -2,20 +2,33 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/careplus/pharmacy-backend/internal/infrastructure/config"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 )
 
-// S3Storage saves files to an S3-compatible bucket (AWS S3 or MinIO).
+// s3PrivatePrefix keeps private objects apart from public ones when they share a bucket.
+const s3PrivatePrefix = "private/"
+
+// S3Storage saves files to an S3-compatible bucket (AWS S3 or MinIO). Private files go under private/ in
+// S3.PrivateBucket (or the same bucket) and are only handed out as presigned URLs.
 type S3Storage struct {
-	client *s3.Client
-	bucket string
-	region string
+	client        *s3.Client
+	presign       *s3.PresignClient
+	bucket        string
+	privateBucket string
+	region        string
+	ttl           time.Duration
 }
 
 func NewS3Storage(cfg config.FSConfig) (*S3Storage, error) {
@@ -43,7 +56,12 @@ func NewS3Storage(cfg config.FSConfig) (*S3Storage, error) {
 		opts = append(opts, func(o *s3.Options) { o.UsePathStyle = true })
 	}
 	client := s3.NewFromConfig(awsCfg, opts...)
-	return &S3Storage{client: client, bucket: cfg.S3.Bucket, region: cfg.S3.Region}, nil
+	privateBucket := cfg.S3.PrivateBucket
+	if privateBucket == "" {
+		privateBucket = cfg.S3.Bucket
+	}
+	return &S3Storage{client: client, presign: s3.NewPresignClient(client), bucket: cfg.S3.Bucket,
+		privateBucket: privateBucket, region: cfg.S3.Region, ttl: cfg.SignedURLTTL}, nil
 }
 
 func (s *S3Storage) Save(ctx context.Context, path string, body io.Reader, contentType string) (string, error) {
@@ -60,3 +78,78 @@ func (s *S3Storage) Save(ctx context.Context, path string, body io.Reader, conte
 	url := fmt.Sprintf("/%s/%s", s.bucket, path)
 	return url, nil
 }
+
+// Exists checks the object behind a URL returned by Save ("/<bucket>/<key>"). Other URLs count as existing.
+func (s *S3Storage) Exists(ctx context.Context, url string) (bool, error) {
+	prefix := "/" + s.bucket + "/"
+	if !strings.HasPrefix(url, prefix) {
+		return true, nil
+	}
+	_, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(strings.TrimPrefix(url, prefix)),
+	})
+	var notFound *types.NotFound
+	if errors.As(err, &notFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *S3Storage) SavePrivate(ctx context.Context, path string, body io.Reader, contentType string) (string, error) {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.privateBucket),
+		Key:         aws.String(s3PrivatePrefix + path),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", err
+	}
+	return outbound.PrivateFileRefPrefix + path, nil
+}
+
+// SignedURL presigns a GET of the private object for the configured TTL.
+func (s *S3Storage) SignedURL(ctx context.Context, ref string) (string, error) {
+	path, ok := strings.CutPrefix(ref, outbound.PrivateFileRefPrefix)
+	if !ok {
+		return ref, nil
+	}
+	req, err := s.presign.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.privateBucket),
+		Key:    aws.String(s3PrivatePrefix + path),
+	}, s3.WithPresignExpires(s.ttl))
+	if err != nil {
+		return "", err
+	}
+	return req.URL, nil
+}
+
+// PrivateRef recognises presigned URLs (path-style or virtual-hosted) of objects under private/.
+func (s *S3Storage) PrivateRef(link string) (string, bool) {
+	u, err := url.Parse(link)
+	if err != nil || u.Query().Get("X-Amz-Signature") == "" {
+		return "", false
+	}
+	_, path, ok := strings.Cut(u.Path, "/"+s3PrivatePrefix)
+	if !ok || path == "" {
+		return "", false
+	}
+	return outbound.PrivateFileRefPrefix + path, true
+}
+
+// Delete removes an object issued by Save ("/<bucket>/<key>") or SavePrivate.
+func (s *S3Storage) Delete(ctx context.Context, url string) error {
+	bucket, key := s.privateBucket, ""
+	if path, ok := strings.CutPrefix(url, outbound.PrivateFileRefPrefix); ok {
+		key = s3PrivatePrefix + path
+	} else if path, ok := strings.CutPrefix(url, "/"+s.bucket+"/"); ok {
+		bucket, key = s.bucket, path
+	}
+	if key == "" {
+		return errors.New("not a stored object: " + url)
+	}
+	// S3 answers a delete of a missing key with success.
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	return err
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"go.uber.org/zap"
+)
+
+// Store is a file storage with private files: LocalStorage or S3Storage.
+type Store interface {
+	outbound.FileStorage
+	outbound.PrivateFileStorage
+}
+
+// TrackedStorage records every file saved through it in the file ledger, so the orphan sweep can find the files
+// nothing refers to any more. A ledger failure is logged; the file stays stored but is never swept.
+type TrackedStorage struct {
+	store  Store
+	refs   outbound.FileReferenceRepository
+	logger *zap.Logger
+}
+
+func NewTrackedStorage(store Store, refs outbound.FileReferenceRepository, logger *zap.Logger) *TrackedStorage {
+	return &TrackedStorage{store: store, refs: refs, logger: logger}
+}
+
+func (s *TrackedStorage) Save(ctx context.Context, path string, body io.Reader, contentType string) (string, error) {
+	size := bodySize(body)
+	url, err := s.store.Save(ctx, path, body, contentType)
+	if err != nil {
+		return "", err
+	}
+	s.record(ctx, url, path, contentType, size)
+	return url, nil
+}
+
+func (s *TrackedStorage) SavePrivate(ctx context.Context, path string, body io.Reader, contentType string) (string, error) {
+	size := bodySize(body)
+	ref, err := s.store.SavePrivate(ctx, path, body, contentType)
+	if err != nil {
+		return "", err
+	}
+	s.record(ctx, ref, path, contentType, size)
+	return ref, nil
+}
+
+func (s *TrackedStorage) SignedURL(ctx context.Context, ref string) (string, error) {
+	return s.store.SignedURL(ctx, ref)
+}
+
+func (s *TrackedStorage) PrivateRef(url string) (string, bool) {
+	return s.store.PrivateRef(url)
+}
+
+func (s *TrackedStorage) record(ctx context.Context, url, path, contentType string, size int64) {
+	f := &models.FileReference{URL: url, Path: path, ContentType: contentType, Size: size,
+		Private: strings.HasPrefix(url, outbound.PrivateFileRefPrefix)}
+	if err := s.refs.Record(ctx, f); err != nil {
+		s.logger.Warn("failed to record stored file", zap.String("url", url), zap.Error(err))
+	}
+}
+
+// bodySize returns the bytes left in body without reading it, so S3 still gets a seekable body; 0 when it
+// cannot tell.
+func bodySize(body io.Reader) int64 {
+	switch b := body.(type) {
+	case interface{ Len() int }:
+		return int64(b.Len())
+	case io.Seeker:
+		cur, err := b.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0
+		}
+		end, err := b.Seek(0, io.SeekEnd)
+		if _, serr := b.Seek(cur, io.SeekStart); serr != nil || err != nil {
+			return 0
+		}
+		return end - cur
+	}
+	return 0
+}
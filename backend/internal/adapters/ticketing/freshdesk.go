@@ -0,0 +1,132 @@
+package ticketing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/config"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+)
+
+// Freshdesk ticket status and priority codes.
+const (
+	freshdeskStatusOpen     = 2
+	freshdeskStatusPending  = 3
+	freshdeskStatusResolved = 4
+	freshdeskStatusClosed   = 5
+	freshdeskPriorityMedium = 2
+)
+
+// FreshdeskProvider creates tickets through the Freshdesk API v2 (API key as basic-auth user).
+// Status updates come from a Freshdesk automation rule that calls the webhook with
+// {"ticket_id": "{{ticket.id}}", "status": "{{ticket.status}}", "comment": "{{ticket.latest_public_comment}}"}.
+type FreshdeskProvider struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+func NewFreshdeskProvider(cfg config.TicketingConfig) *FreshdeskProvider {
+	return &FreshdeskProvider{client: &http.Client{Timeout: cfg.Timeout}, baseURL: cfg.FreshdeskURL, apiKey: cfg.FreshdeskAPIKey}
+}
+
+func (p *FreshdeskProvider) Name() string { return "freshdesk" }
+
+func (p *FreshdeskProvider) CreateTicket(ctx context.Context, req *outbound.TicketRequest) (*outbound.TicketRef, error) {
+	payload := map[string]interface{}{
+		"subject":     req.Subject,
+		"description": "<pre>" + html.EscapeString(req.Description) + "</pre>",
+		"status":      freshdeskStatusOpen,
+		"priority":    freshdeskPriorityMedium,
+		"tags":        []string{"careplus", "chat-escalation"},
+	}
+	if req.RequesterName != "" {
+		payload["name"] = req.RequesterName
+	}
+	// Freshdesk needs a requester identifier: email, phone, or else a stable external id.
+	switch {
+	case req.RequesterEmail != "":
+		payload["email"] = req.RequesterEmail
+	case req.RequesterPhone != "":
+		payload["phone"] = req.RequesterPhone
+	default:
+		payload["unique_external_id"] = "careplus-" + req.ConversationID.String()
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/api/v2/tickets", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.SetBasicAuth(p.apiKey, "X")
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("freshdesk request: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("freshdesk: status %d: %s", resp.StatusCode, truncate(string(respBody)))
+	}
+	var out struct {
+		ID     int64 `json:"id"`
+		Status int   `json:"status"`
+	}
+	if err := json.Unmarshal(respBody, &out); err != nil || out.ID == 0 {
+		return nil, fmt.Errorf("freshdesk: unexpected response: %s", truncate(string(respBody)))
+	}
+	id := strconv.FormatInt(out.ID, 10)
+	return &outbound.TicketRef{ID: id, URL: p.baseURL + "/a/tickets/" + id, Status: freshdeskStatusName(out.Status)}, nil
+}
+
+// ParseWebhook accepts the automation payload; status may be a name ("Resolved") or a numeric code.
+func (p *FreshdeskProvider) ParseWebhook(body []byte) (*outbound.TicketUpdate, error) {
+	var in struct {
+		TicketID json.RawMessage `json:"ticket_id"`
+		Status   json.RawMessage `json:"status"`
+		Comment  string          `json:"comment"`
+	}
+	if err := json.Unmarshal(body, &in); err != nil {
+		return nil, fmt.Errorf("invalid freshdesk webhook body: %w", err)
+	}
+	id := rawID(in.TicketID)
+	if id == "" {
+		return nil, fmt.Errorf("freshdesk webhook: missing ticket_id")
+	}
+	status := rawID(in.Status)
+	if code, err := strconv.Atoi(status); err == nil {
+		status = freshdeskStatusName(code)
+	}
+	comment := strings.TrimSpace(in.Comment)
+	if status == "" && comment == "" {
+		return nil, nil
+	}
+	return &outbound.TicketUpdate{TicketID: id, Status: status, Comment: comment, Resolved: isResolvedStatus(status)}, nil
+}
+
+func freshdeskStatusName(code int) string {
+	switch code {
+	case freshdeskStatusOpen:
+		return "Open"
+	case freshdeskStatusPending:
+		return "Pending"
+	case freshdeskStatusResolved:
+		return "Resolved"
+	case freshdeskStatusClosed:
+		return "Closed"
+	}
+	if code == 0 {
+		return "Open"
+	}
+	return strconv.Itoa(code)
+}
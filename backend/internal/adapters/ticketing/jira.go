@@ -0,0 +1,117 @@
+package ticketing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/config"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+)
+
+// JiraProvider creates issues through the Jira REST API v2 (basic auth with an account email and API token).
+// Status updates come from a Jira webhook on issue updated and comment created events.
+type JiraProvider struct {
+	client     *http.Client
+	baseURL    string
+	email      string
+	apiToken   string
+	projectKey string
+	issueType  string
+}
+
+func NewJiraProvider(cfg config.TicketingConfig) *JiraProvider {
+	return &JiraProvider{
+		client:     &http.Client{Timeout: cfg.Timeout},
+		baseURL:    cfg.JiraBaseURL,
+		email:      cfg.JiraEmail,
+		apiToken:   cfg.JiraAPIToken,
+		projectKey: cfg.JiraProjectKey,
+		issueType:  cfg.JiraIssueType,
+	}
+}
+
+func (p *JiraProvider) Name() string { return "jira" }
+
+func (p *JiraProvider) CreateTicket(ctx context.Context, req *outbound.TicketRequest) (*outbound.TicketRef, error) {
+	payload := map[string]interface{}{
+		"fields": map[string]interface{}{
+			"project":     map[string]string{"key": p.projectKey},
+			"issuetype":   map[string]string{"name": p.issueType},
+			"summary":     req.Subject,
+			"description": req.Description,
+			"labels":      []string{"careplus", "chat-escalation"},
+		},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/rest/api/2/issue", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.SetBasicAuth(p.email, p.apiToken)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("jira request: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("jira: status %d: %s", resp.StatusCode, truncate(string(respBody)))
+	}
+	var out struct {
+		Key string `json:"key"`
+	}
+	if err := json.Unmarshal(respBody, &out); err != nil || out.Key == "" {
+		return nil, fmt.Errorf("jira: unexpected response: %s", truncate(string(respBody)))
+	}
+	return &outbound.TicketRef{ID: out.Key, URL: p.baseURL + "/browse/" + out.Key, Status: "open"}, nil
+}
+
+// ParseWebhook reads the issue key, status name (statusCategory "done" means resolved) and, for comment
+// events, the comment body.
+func (p *JiraProvider) ParseWebhook(body []byte) (*outbound.TicketUpdate, error) {
+	var in struct {
+		WebhookEvent string `json:"webhookEvent"`
+		Issue        struct {
+			Key    string `json:"key"`
+			Fields struct {
+				Status struct {
+					Name           string `json:"name"`
+					StatusCategory struct {
+						Key string `json:"key"`
+					} `json:"statusCategory"`
+				} `json:"status"`
+			} `json:"fields"`
+		} `json:"issue"`
+		Comment *struct {
+			Body string `json:"body"`
+		} `json:"comment"`
+	}
+	if err := json.Unmarshal(body, &in); err != nil {
+		return nil, fmt.Errorf("invalid jira webhook body: %w", err)
+	}
+	if in.Issue.Key == "" {
+		return nil, fmt.Errorf("jira webhook: missing issue key")
+	}
+	update := &outbound.TicketUpdate{TicketID: in.Issue.Key}
+	status := in.Issue.Fields.Status
+	if status.Name != "" {
+		update.Status = status.Name
+		update.Resolved = status.StatusCategory.Key == "done" || isResolvedStatus(status.Name)
+	}
+	if in.Comment != nil {
+		update.Comment = in.Comment.Body
+	}
+	if update.Status == "" && update.Comment == "" {
+		return nil, nil
+	}
+	return update, nil
+}
@@ -0,0 +1,157 @@
+// Package ticketing implements outbound.TicketingProvider for escalating chat conversations to
+// external help desks: a generic JSON webhook, Jira and Freshdesk.
+package ticketing
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/config"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+)
+
+// SignatureHeader carries "sha256=<hex HMAC of the body>" on outgoing webhook calls.
+const SignatureHeader = "X-CarePlus-Signature"
+
+// WebhookProvider POSTs a JSON "chat.escalated" event to a configured URL. The receiver may answer with
+// {"ticket_id", "ticket_url", "status"}; without a ticket_id the conversation id is used as the reference.
+type WebhookProvider struct {
+	client *http.Client
+	url    string
+	secret string
+}
+
+func NewWebhookProvider(cfg config.TicketingConfig) *WebhookProvider {
+	return &WebhookProvider{client: &http.Client{Timeout: cfg.Timeout}, url: cfg.WebhookURL, secret: cfg.WebhookSecret}
+}
+
+func (p *WebhookProvider) Name() string { return "webhook" }
+
+type webhookRequester struct {
+	Name  string `json:"name,omitempty"`
+	Phone string `json:"phone,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+type webhookEvent struct {
+	Event           string           `json:"event"`
+	ConversationID  string           `json:"conversation_id"`
+	PharmacyID      string           `json:"pharmacy_id"`
+	PharmacyName    string           `json:"pharmacy_name"`
+	Subject         string           `json:"subject"`
+	Description     string           `json:"description"`
+	Reason          string           `json:"reason"`
+	Requester       webhookRequester `json:"requester"`
+	EscalatedBy     string           `json:"escalated_by"`
+	ConversationURL string           `json:"conversation_url"`
+}
+
+func (p *WebhookProvider) CreateTicket(ctx context.Context, req *outbound.TicketRequest) (*outbound.TicketRef, error) {
+	body, err := json.Marshal(webhookEvent{
+		Event:           "chat.escalated",
+		ConversationID:  req.ConversationID.String(),
+		PharmacyID:      req.PharmacyID.String(),
+		PharmacyName:    req.PharmacyName,
+		Subject:         req.Subject,
+		Description:     req.Description,
+		Reason:          req.Reason,
+		Requester:       webhookRequester{Name: req.RequesterName, Phone: req.RequesterPhone, Email: req.RequesterEmail},
+		EscalatedBy:     req.EscalatedBy,
+		ConversationURL: req.ConversationURL,
+	})
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(SignatureHeader, "sha256="+Sign([]byte(p.secret), body))
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("ticket webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("ticket webhook: status %d: %s", resp.StatusCode, truncate(string(respBody)))
+	}
+	var out struct {
+		TicketID  json.RawMessage `json:"ticket_id"`
+		TicketURL string          `json:"ticket_url"`
+		Status    string          `json:"status"`
+	}
+	_ = json.Unmarshal(respBody, &out)
+	ref := &outbound.TicketRef{ID: rawID(out.TicketID), URL: out.TicketURL, Status: out.Status}
+	if ref.ID == "" {
+		ref.ID = req.ConversationID.String()
+	}
+	if ref.Status == "" {
+		ref.Status = "open"
+	}
+	return ref, nil
+}
+
+// ParseWebhook accepts {"ticket_id", "status", "comment"}; ticket_id may be a string or a number.
+func (p *WebhookProvider) ParseWebhook(body []byte) (*outbound.TicketUpdate, error) {
+	var in struct {
+		TicketID json.RawMessage `json:"ticket_id"`
+		Status   string          `json:"status"`
+		Comment  string          `json:"comment"`
+	}
+	if err := json.Unmarshal(body, &in); err != nil {
+		return nil, fmt.Errorf("invalid ticket webhook body: %w", err)
+	}
+	id := rawID(in.TicketID)
+	if id == "" {
+		return nil, fmt.Errorf("ticket_id is required")
+	}
+	if in.Status == "" && in.Comment == "" {
+		return nil, nil
+	}
+	return &outbound.TicketUpdate{TicketID: id, Status: in.Status, Comment: in.Comment, Resolved: isResolvedStatus(in.Status)}, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of body, as sent in SignatureHeader.
+func Sign(secret, body []byte) string {
+	h := hmac.New(sha256.New, secret)
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// rawID reads a JSON string or number as a string id.
+func rawID(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return strings.TrimSpace(s)
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+func isResolvedStatus(status string) bool {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "resolved", "closed", "done":
+		return true
+	}
+	return false
+}
+
+func truncate(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > 300 {
+		return s[:300]
+	}
+	return s
+}
@@ -0,0 +1,67 @@
+// Package webhook delivers outgoing webhook events over HTTP.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/config"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+)
+
+// Headers sent with every event. The signature is "sha256=<hex HMAC of the body>" with WEBHOOK_SIGNING_SECRET.
+const (
+	SignatureHeader = "X-CarePlus-Signature"
+	EventHeader     = "X-CarePlus-Event"
+	DeliveryHeader  = "X-CarePlus-Delivery"
+)
+
+// HTTPSender POSTs signed JSON events.
+type HTTPSender struct {
+	client *http.Client
+	secret []byte
+}
+
+func NewHTTPSender(cfg config.WebhookConfig) *HTTPSender {
+	return &HTTPSender{client: &http.Client{Timeout: cfg.Timeout}, secret: []byte(cfg.SigningSecret)}
+}
+
+type envelope struct {
+	ID    string          `json:"id"`
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+func (s *HTTPSender) Send(ctx context.Context, msg *outbound.WebhookMessage) error {
+	body, err := json.Marshal(envelope{ID: msg.ID.String(), Event: msg.Event, Data: msg.Data})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	req.Header.Set(EventHeader, msg.Event)
+	req.Header.Set(DeliveryHeader, msg.ID.String())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook: status %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AIGenerationKind is what was generated.
+const (
+	AIGenerationKindProductDescription = "product_description"
+	AIGenerationKindBlogOutline        = "blog_outline"
+)
+
+// AIGenerationStatus: pending (provider call in flight), failed (provider call failed), draft (awaiting staff
+// review), approved (applied to product / kept as blog draft), rejected.
+const (
+	AIGenerationStatusPending  = "pending"
+	AIGenerationStatusFailed   = "failed"
+	AIGenerationStatusDraft    = "draft"
+	AIGenerationStatusApproved = "approved"
+	AIGenerationStatusRejected = "rejected"
+)
+
+// AIGeneration records one LLM generation per tenant: input, output, token usage and review state.
+// Rows double as the usage log for per-pharmacy monthly quotas; failed rows do not count against it.
+type AIGeneration struct {
+	ID               uuid.UUID              `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID       uuid.UUID              `gorm:"type:uuid;not null;index:idx_ai_generation_pharmacy_created" json:"pharmacy_id"`
+	RequestedBy      uuid.UUID              `gorm:"type:uuid;not null;index" json:"requested_by"`
+	Kind             string                 `gorm:"size:40;not null;index" json:"kind"` // product_description, blog_outline
+	Input            map[string]interface{} `gorm:"type:jsonb;serializer:json" json:"input"`
+	Output           string                 `gorm:"type:text" json:"output"`
+	Status           string                 `gorm:"size:20;not null;default:draft;index" json:"status"` // pending, failed, draft, approved, rejected
+	Provider         string                 `gorm:"size:40" json:"provider"`
+	Model            string                 `gorm:"size:100" json:"model"`
+	PromptTokens     int                    `gorm:"default:0" json:"prompt_tokens"`
+	CompletionTokens int                    `gorm:"default:0" json:"completion_tokens"`
+	ProductID        *uuid.UUID             `gorm:"type:uuid;index" json:"product_id,omitempty"`   // product to update on approval
+	BlogPostID       *uuid.UUID             `gorm:"type:uuid;index" json:"blog_post_id,omitempty"` // draft post created from the outline
+	ReviewedBy       *uuid.UUID             `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt       *time.Time             `json:"reviewed_at,omitempty"`
+	CreatedAt        time.Time              `gorm:"index:idx_ai_generation_pharmacy_created" json:"created_at"`
+	UpdatedAt        time.Time              `json:"updated_at"`
+
+	Requester *User `gorm:"foreignKey:RequestedBy" json:"requester,omitempty"`
+}
+
+func (AIGeneration) TableName() string { return "ai_generations" }
+
+func (g *AIGeneration) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == uuid.Nil {
+		g.ID = uuid.New()
+	}
+	return nil
+}
@@ -35,6 +35,7 @@ type Announcement struct {
 	Title           string     `gorm:"size:255;not null" json:"title"`
 	Body            string     `gorm:"type:text" json:"body"`
 	ImageURL        string     `gorm:"size:512" json:"image_url"`
+	Images          *ImageSet  `gorm:"type:jsonb;serializer:json" json:"images,omitempty"`
 	LinkURL         string     `gorm:"size:512" json:"link_url"`
 	DisplaySeconds  int        `gorm:"default:5;not null" json:"display_seconds"`       // 1–30, how long popup is visible before auto-close option
 	ValidDays       int        `gorm:"default:7;not null" json:"valid_days"`           // how many days to show (from start_at or from now)
@@ -44,11 +45,17 @@ type Announcement struct {
 	StartAt         *time.Time `gorm:"index" json:"start_at"`
 	EndAt           *time.Time `gorm:"index" json:"end_at"`
 	SortOrder       int        `gorm:"default:0" json:"sort_order"`
+	// Translations holds other language variants of Title/Body; Title/Body are the pharmacy-default text.
+	Translations Translations `gorm:"type:jsonb;serializer:json" json:"translations,omitempty"`
 	IsActive        bool       `gorm:"default:true" json:"is_active"`
 	CreatedAt       time.Time  `json:"created_at"`
 	UpdatedAt       time.Time  `json:"updated_at"`
 
 	Pharmacy *Pharmacy `gorm:"foreignKey:PharmacyID" json:"pharmacy,omitempty"`
+	// QuizID is set on announcements returned to team members when a training quiz must be passed to acknowledge.
+	QuizID *uuid.UUID `gorm:"-" json:"quiz_id,omitempty"`
+	// Language is set on announcements localized for a user: the variant shown in Title/Body.
+	Language string `gorm:"-" json:"language,omitempty"`
 }
 
 func (Announcement) TableName() string { return "announcements" }
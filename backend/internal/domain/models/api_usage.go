@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIUsageDaily counts one tenant's API requests for one day and route (the route pattern, e.g.
+// /api/v1/orders/:orderId). Latencies are in milliseconds; the average is LatencyMsTotal / Requests.
+type APIUsageDaily struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	PharmacyID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_api_usage_day_route" json:"pharmacy_id"`
+	Day            time.Time `gorm:"type:date;not null;uniqueIndex:idx_api_usage_day_route" json:"day"`
+	Method         string    `gorm:"size:10;not null;uniqueIndex:idx_api_usage_day_route" json:"method"`
+	Route          string    `gorm:"size:255;not null;uniqueIndex:idx_api_usage_day_route" json:"route"`
+	Requests       int64     `gorm:"not null;default:0" json:"requests"`
+	ClientErrors   int64     `gorm:"not null;default:0" json:"client_errors"` // 4xx responses
+	ServerErrors   int64     `gorm:"not null;default:0" json:"server_errors"` // 5xx responses
+	LatencyMsTotal int64     `gorm:"not null;default:0" json:"latency_ms_total"`
+	LatencyMsMax   int64     `gorm:"not null;default:0" json:"latency_ms_max"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (APIUsageDaily) TableName() string { return "api_usage_daily" }
+
+func (u *APIUsageDaily) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == uuid.Nil {
+		u.ID = uuid.New()
+	}
+	return nil
+}
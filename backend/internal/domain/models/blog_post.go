@@ -7,10 +7,12 @@ import (
 	"gorm.io/gorm"
 )
 
-// BlogPostStatus: draft (author only), pending_approval (awaiting manager), published (visible to all).
+// BlogPostStatus: draft (author only), pending_approval (awaiting manager), scheduled (approved, published at
+// ScheduledAt), published (visible to all).
 const (
 	BlogPostStatusDraft          = "draft"
 	BlogPostStatusPendingApproval = "pending_approval"
+	BlogPostStatusScheduled      = "scheduled"
 	BlogPostStatusPublished      = "published"
 )
 
@@ -23,8 +25,9 @@ type BlogPost struct {
 	Slug         string         `gorm:"size:520;not null;uniqueIndex:idx_blog_post_pharmacy_slug" json:"slug"`
 	Excerpt      string         `gorm:"type:text" json:"excerpt"`
 	Body         string         `gorm:"type:text;not null" json:"body"`
-	Status       string         `gorm:"size:32;not null;default:draft;index" json:"status"` // draft, pending_approval, published
+	Status       string         `gorm:"size:32;not null;default:draft;index" json:"status"` // draft, pending_approval, scheduled, published
 	PublishedAt  *time.Time     `json:"published_at,omitempty"`
+	ScheduledAt  *time.Time     `gorm:"index" json:"scheduled_at,omitempty"` // when a scheduled post goes live
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
@@ -32,6 +35,8 @@ type BlogPost struct {
 	Pharmacy *Pharmacy    `gorm:"foreignKey:PharmacyID" json:"pharmacy,omitempty"`
 	Category *BlogCategory `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
 	Author   *User        `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+
+	Tags []*BlogTag `gorm:"-" json:"tags,omitempty"` // filled by the service
 }
 
 func (BlogPost) TableName() string { return "blog_posts" }
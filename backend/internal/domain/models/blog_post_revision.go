@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Blog post revision actions.
+const (
+	BlogRevisionBaseline = "baseline" // content before the first recorded edit of a post written before revisions existed
+	BlogRevisionCreate   = "create"
+	BlogRevisionUpdate   = "update"
+	BlogRevisionRestore  = "restore"
+)
+
+// BlogPostRevision is a snapshot of a post's content after a change. Revisions count up per post and are never
+// edited, so any of them can be restored.
+type BlogPostRevision struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PostID       uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_blog_post_revision" json:"post_id"`
+	Revision     int        `gorm:"not null;uniqueIndex:idx_blog_post_revision" json:"revision"`
+	Action       string     `gorm:"size:20;not null" json:"action"`
+	RestoredFrom *int       `json:"restored_from,omitempty"` // revision restored by a restore
+	Title        string     `gorm:"size:500;not null" json:"title"`
+	Excerpt      string     `gorm:"type:text" json:"excerpt"`
+	Body         string     `gorm:"type:text;not null" json:"body"`
+	EditorID     *uuid.UUID `gorm:"type:uuid" json:"editor_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+
+	Editor *User `gorm:"foreignKey:EditorID" json:"editor,omitempty"`
+}
+
+func (BlogPostRevision) TableName() string { return "blog_post_revisions" }
+
+func (r *BlogPostRevision) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxBlogPostTags caps how many tags one post can carry.
+const MaxBlogPostTags = 10
+
+// BlogTag is a pharmacy's blog tag. Posts are linked to tags through BlogPostTag.
+type BlogTag struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_blog_tag_pharmacy_slug" json:"pharmacy_id"`
+	Name       string    `gorm:"size:100;not null" json:"name"`
+	Slug       string    `gorm:"size:120;not null;uniqueIndex:idx_blog_tag_pharmacy_slug" json:"slug"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	PostCount int64 `gorm:"-" json:"post_count"` // posts carrying the tag, filled by the list
+}
+
+func (BlogTag) TableName() string { return "blog_tags" }
+
+func (t *BlogTag) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// BlogPostTag links a post to a tag.
+type BlogPostTag struct {
+	PostID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"post_id"`
+	TagID     uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"tag_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (BlogPostTag) TableName() string { return "blog_post_tags" }
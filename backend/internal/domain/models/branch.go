@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Branch is one location of a pharmacy. A pharmacy without branches keeps working as a single location:
+// stock, orders and staff with no branch_id are pharmacy-wide. The first branch created becomes the default
+// and takes over the pharmacy's unassigned batches.
+type Branch struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_branch_pharmacy_code" json:"pharmacy_id"`
+	Code       string         `gorm:"size:30;not null;uniqueIndex:idx_branch_pharmacy_code" json:"code"` // short code, e.g. "KTM-01"
+	Name       string         `gorm:"size:150;not null" json:"name"`
+	Address    string         `gorm:"type:text" json:"address"`
+	Phone      string         `gorm:"size:50" json:"phone"`
+	IsDefault  bool           `gorm:"default:false" json:"is_default"` // receives batches added without a branch
+	IsActive   bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Branch) TableName() string { return "branches" }
+
+func (b *Branch) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
+
+// BranchStockRow is a product's sellable and expired batch stock at one branch.
+type BranchStockRow struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Name      string    `json:"name"`
+	SKU       string    `json:"sku"`
+	Quantity  int       `json:"quantity"`
+	Expired   int       `json:"expired"` // units in batches past their expiry date
+}
+
+// BranchTransfer moves stock between two branches of the same pharmacy. It is applied immediately:
+// source batches are drawn FEFO and matching batches (same number and expiry) are credited at the destination.
+type BranchTransfer struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID   uuid.UUID `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	FromBranchID uuid.UUID `gorm:"type:uuid;not null;index" json:"from_branch_id"`
+	ToBranchID   uuid.UUID `gorm:"type:uuid;not null;index" json:"to_branch_id"`
+	Note         string    `gorm:"type:text" json:"note,omitempty"`
+	CreatedBy    uuid.UUID `gorm:"type:uuid;index" json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	FromBranch *Branch              `gorm:"foreignKey:FromBranchID" json:"from_branch,omitempty"`
+	ToBranch   *Branch              `gorm:"foreignKey:ToBranchID" json:"to_branch,omitempty"`
+	Items      []BranchTransferItem `gorm:"foreignKey:TransferID" json:"items,omitempty"`
+}
+
+func (BranchTransfer) TableName() string { return "branch_transfers" }
+
+func (t *BranchTransfer) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// BranchTransferItem records one batch draw of a transfer.
+type BranchTransferItem struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	TransferID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"transfer_id"`
+	ProductID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"product_id"`
+	FromBatchID uuid.UUID  `gorm:"type:uuid;not null" json:"from_batch_id"`
+	ToBatchID   uuid.UUID  `gorm:"type:uuid;not null" json:"to_batch_id"`
+	BatchNumber string     `gorm:"size:100" json:"batch_number"`
+	ExpiryDate  *time.Time `json:"expiry_date,omitempty"`
+	Quantity    int        `gorm:"not null" json:"quantity"`
+
+	Product *Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+func (BranchTransferItem) TableName() string { return "branch_transfer_items" }
+
+func (i *BranchTransferItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
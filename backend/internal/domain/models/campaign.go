@@ -0,0 +1,135 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SegmentCriteria selects customers for a campaign. Every set criterion must match; purchases are completed
+// orders, and spend is what they were paid with (including gift cards and store credit).
+type SegmentCriteria struct {
+	MinPoints          *int        `json:"min_points,omitempty"`
+	MaxPoints          *int        `json:"max_points,omitempty"`
+	MembershipIDs      []uuid.UUID `json:"membership_ids,omitempty"` // any of these tiers
+	NoMembership       bool        `json:"no_membership,omitempty"`  // customers without a tier
+	LastPurchaseAfter  *time.Time  `json:"last_purchase_after,omitempty"`
+	LastPurchaseBefore *time.Time  `json:"last_purchase_before,omitempty"` // lapsed customers; never-purchased ones do not match
+	MinTotalSpend      *float64    `json:"min_total_spend,omitempty"`
+	MaxTotalSpend      *float64    `json:"max_total_spend,omitempty"`
+}
+
+// CustomerSegment is a saved set of criteria that campaigns target.
+type CustomerSegment struct {
+	ID          uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID  uuid.UUID       `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	Name        string          `gorm:"size:100;not null" json:"name"`
+	Description string          `gorm:"size:500" json:"description"`
+	Criteria    SegmentCriteria `gorm:"type:jsonb;serializer:json" json:"criteria"`
+	CreatedBy   uuid.UUID       `gorm:"type:uuid" json:"created_by"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt  `gorm:"index" json:"-"`
+}
+
+func (CustomerSegment) TableName() string { return "customer_segments" }
+
+func (s *CustomerSegment) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// SegmentMember is a customer matched by a segment, with the figures the criteria use.
+type SegmentMember struct {
+	CustomerID        uuid.UUID  `json:"customer_id"`
+	Name              string     `json:"name"`
+	Phone             string     `json:"phone"`
+	Email             string     `json:"email"`
+	PreferredLanguage string     `json:"-"`
+	PointsBalance     int        `json:"points_balance"`
+	MembershipID      *uuid.UUID `json:"membership_id,omitempty"`
+	LastPurchaseAt    *time.Time `json:"last_purchase_at,omitempty"`
+	TotalSpend        float64    `json:"total_spend"`
+	// UserID is the customer's app account (same pharmacy and phone), which receives in-app notifications.
+	UserID *uuid.UUID `json:"-"`
+}
+
+type CampaignChannel string
+
+const (
+	CampaignChannelNotification CampaignChannel = "notification" // in-app and push, to customers with an app account
+	CampaignChannelSMS          CampaignChannel = "sms"
+	CampaignChannelEmail        CampaignChannel = "email"
+)
+
+type CampaignStatus string
+
+const (
+	CampaignStatusDraft   CampaignStatus = "draft"
+	CampaignStatusSending CampaignStatus = "sending"
+	CampaignStatusSent    CampaignStatus = "sent"
+)
+
+// Campaign is a message sent once to the customers of a segment. Criteria is copied from the segment when
+// sending starts, so later segment edits do not change who it went to.
+type Campaign struct {
+	ID             uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID     uuid.UUID       `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	SegmentID      uuid.UUID       `gorm:"type:uuid;not null;index" json:"segment_id"`
+	Name           string          `gorm:"size:100;not null" json:"name"`
+	Channel        CampaignChannel `gorm:"size:20;not null" json:"channel"`
+	Subject        string          `gorm:"size:255" json:"subject,omitempty"` // email subject and notification title
+	Message        string          `gorm:"type:text;not null" json:"message"`
+	Status         CampaignStatus  `gorm:"size:20;not null;default:draft;index" json:"status"`
+	Criteria       SegmentCriteria `gorm:"type:jsonb;serializer:json" json:"criteria"`
+	RecipientCount int             `gorm:"not null;default:0" json:"recipient_count"`
+	SentCount      int             `gorm:"not null;default:0" json:"sent_count"`
+	FailedCount    int             `gorm:"not null;default:0" json:"failed_count"`
+	SkippedCount   int             `gorm:"not null;default:0" json:"skipped_count"` // no phone, email or app account for the channel
+	CreatedBy      uuid.UUID       `gorm:"type:uuid" json:"created_by"`
+	SentAt         *time.Time      `json:"sent_at,omitempty"`
+	CompletedAt    *time.Time      `json:"completed_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+
+	Segment *CustomerSegment `gorm:"foreignKey:SegmentID" json:"segment,omitempty"`
+}
+
+func (Campaign) TableName() string { return "campaigns" }
+
+func (c *Campaign) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+type CampaignRecipientStatus string
+
+const (
+	CampaignRecipientSent    CampaignRecipientStatus = "sent" // handed to the delivery queue or stored as a notification
+	CampaignRecipientFailed  CampaignRecipientStatus = "failed"
+	CampaignRecipientSkipped CampaignRecipientStatus = "skipped"
+)
+
+// CampaignRecipient is the outcome of a campaign for one customer.
+type CampaignRecipient struct {
+	ID         uuid.UUID               `gorm:"type:uuid;primaryKey" json:"id"`
+	CampaignID uuid.UUID               `gorm:"type:uuid;not null;uniqueIndex:idx_campaign_recipient" json:"campaign_id"`
+	CustomerID uuid.UUID               `gorm:"type:uuid;not null;uniqueIndex:idx_campaign_recipient" json:"customer_id"`
+	Status     CampaignRecipientStatus `gorm:"size:20;not null;index" json:"status"`
+	Error      string                  `gorm:"size:500" json:"error,omitempty"`
+	CreatedAt  time.Time               `json:"created_at"`
+}
+
+func (CampaignRecipient) TableName() string { return "campaign_recipients" }
+
+func (r *CampaignRecipient) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Cart status: a user has at most one open cart per pharmacy; checkout claims it (checking_out)
+// and marks it converted once the order exists.
+const (
+	CartStatusOpen        = "open"
+	CartStatusCheckingOut = "checking_out"
+	CartStatusConverted   = "converted"
+)
+
+// Cart is a server-side shopping cart for a logged-in user at one pharmacy. Prices are not stored;
+// totals are computed from current product prices whenever the cart is read.
+type Cart struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID     uuid.UUID  `gorm:"type:uuid;not null;index:idx_cart_owner" json:"pharmacy_id"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;index:idx_cart_owner" json:"user_id"`
+	Status         string     `gorm:"size:20;not null;default:open;index:idx_cart_owner" json:"status"`
+	PromoCode      string     `gorm:"size:50" json:"promo_code"`
+	ReferralCode   string     `gorm:"size:50" json:"referral_code"`
+	PointsToRedeem int        `gorm:"default:0" json:"points_to_redeem"`
+	OrderID        *uuid.UUID `gorm:"type:uuid" json:"order_id,omitempty"` // set when converted
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	Items []*CartItem `gorm:"foreignKey:CartID" json:"items,omitempty"`
+}
+
+func (Cart) TableName() string { return "carts" }
+
+func (c *Cart) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// CartItem is one product line in a cart.
+type CartItem struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	CartID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_cart_item_product" json:"cart_id"`
+	ProductID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_cart_item_product" json:"product_id"`
+	Quantity  int       `gorm:"not null" json:"quantity"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Product *Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+func (CartItem) TableName() string { return "cart_items" }
+
+func (i *CartItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
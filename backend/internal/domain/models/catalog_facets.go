@@ -0,0 +1,26 @@
+package models
+
+// FacetCount is one filter value of a catalog facet and the number of matching products.
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// PriceBucketCount is the number of matching products with unit_price in [Min, Max); Max is nil for the top bucket.
+type PriceBucketCount struct {
+	Label string   `json:"label"`
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max,omitempty"`
+	Count int64    `json:"count"`
+}
+
+// CatalogFacets are filter-sidebar counts for the public catalog. Each facet applies every selected filter except
+// its own, so the storefront can show how many products each alternative value would give.
+type CatalogFacets struct {
+	Total        int64              `json:"total"` // products matching the full selection
+	Categories   []FacetCount       `json:"categories"`
+	Brands       []FacetCount       `json:"brands"`
+	DosageForms  []FacetCount       `json:"dosage_forms"`
+	PriceBuckets []PriceBucketCount `json:"price_buckets"`
+	Hashtags     []FacetCount       `json:"hashtags"`
+}
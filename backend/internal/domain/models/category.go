@@ -8,15 +8,17 @@ import (
 )
 
 type Category struct {
-	ID          uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
-	PharmacyID  uuid.UUID      `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
-	ParentID    *uuid.UUID     `gorm:"type:uuid;index" json:"parent_id,omitempty"` // nil = top-level (category), set = subcategory
-	Name        string         `gorm:"size:100;not null" json:"name"`
-	Description string         `gorm:"type:text" json:"description"`
-	SortOrder   int            `gorm:"default:0" json:"sort_order"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	ParentID    *uuid.UUID `gorm:"type:uuid;index" json:"parent_id,omitempty"` // nil = top-level (category), set = subcategory
+	Name        string     `gorm:"size:100;not null" json:"name"`
+	Description string     `gorm:"type:text" json:"description"`
+	SortOrder   int        `gorm:"default:0" json:"sort_order"`
+	// Translations holds other language variants of the name (Title) and description (Body).
+	Translations Translations   `gorm:"type:jsonb;serializer:json" json:"translations,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
 
 	Pharmacy *Pharmacy `gorm:"foreignKey:PharmacyID" json:"pharmacy,omitempty"`
 	Parent   *Category `gorm:"foreignKey:ParentID" json:"parent,omitempty"`
@@ -10,12 +10,18 @@ import (
 const (
 	SenderTypeUser     = "user"
 	SenderTypeCustomer = "customer"
+	// SenderTypeSystem marks messages posted by the app (e.g. escalation and ticket updates); SenderID is nil.
+	SenderTypeSystem = "system"
 )
 
+// ChatProductCardType is the attachment type of a product card shared in chat: AttachmentURL is the storefront
+// link (/products/<id>) and AttachmentName the product name.
+const ChatProductCardType = "application/vnd.careplus.product"
+
 type ChatMessage struct {
 	ID             uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
 	ConversationID uuid.UUID `gorm:"type:uuid;not null;index" json:"conversation_id"`
-	SenderType     string    `gorm:"size:20;not null" json:"sender_type"` // "user" | "customer"
+	SenderType     string    `gorm:"size:20;not null" json:"sender_type"` // "user" | "customer" | "system"
 	SenderID       uuid.UUID `gorm:"type:uuid;not null" json:"sender_id"`
 	Body           string    `gorm:"type:text" json:"body"`
 	AttachmentURL  string    `gorm:"size:1024" json:"attachment_url,omitempty"`
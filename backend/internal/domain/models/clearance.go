@@ -0,0 +1,95 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ClearanceKind is how dead stock is cleared.
+type ClearanceKind string
+
+const (
+	ClearanceKindPromo          ClearanceKind = "promo"           // discounted through a promo code scoped to the products
+	ClearanceKindSupplierReturn ClearanceKind = "supplier_return" // sent back to the supplier for credit
+)
+
+// ClearanceStatus: an action is open until staff complete or cancel it. A product is in at most one open action.
+type ClearanceStatus string
+
+const (
+	ClearanceOpen      ClearanceStatus = "open"
+	ClearanceCompleted ClearanceStatus = "completed"
+	ClearanceCancelled ClearanceStatus = "cancelled"
+)
+
+// ClearanceAction bundles dead-stock products into a discount promo or a supplier return. StockValue is the
+// bundle's value at unit price when the action was created; RecoveredValue and UnitsCleared are filled on read
+// from promo sales (non-cancelled orders using the promo code) or recorded supplier credits.
+type ClearanceAction struct {
+	ID              uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID      uuid.UUID       `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	Kind            ClearanceKind   `gorm:"size:20;not null;index" json:"kind"`
+	Status          ClearanceStatus `gorm:"size:20;not null;default:open;index" json:"status"`
+	Name            string          `gorm:"size:255;not null" json:"name"`
+	Note            string          `gorm:"size:500" json:"note"`
+	PromoCodeID     *uuid.UUID      `gorm:"type:uuid" json:"promo_code_id,omitempty"` // promo actions
+	DiscountPercent float64         `gorm:"type:decimal(5,2);default:0" json:"discount_percent"`
+	SupplierID      *uuid.UUID      `gorm:"type:uuid;index" json:"supplier_id,omitempty"` // supplier return actions
+	StockValue      float64         `gorm:"type:decimal(12,2);not null;default:0" json:"stock_value"`
+	CreatedBy       uuid.UUID       `gorm:"type:uuid;not null" json:"created_by"`
+	ClosedBy        *uuid.UUID      `gorm:"type:uuid" json:"closed_by,omitempty"`
+	ClosedAt        *time.Time      `json:"closed_at,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+
+	RecoveredValue float64 `gorm:"-" json:"recovered_value"`
+	UnitsCleared   int     `gorm:"-" json:"units_cleared"`
+
+	Items     []*ClearanceItem  `gorm:"foreignKey:ActionID" json:"items,omitempty"`
+	PromoCode *PromoCode        `gorm:"foreignKey:PromoCodeID" json:"promo_code,omitempty"`
+	Supplier  *Supplier         `gorm:"foreignKey:SupplierID" json:"supplier,omitempty"`
+	Photos    []*InventoryPhoto `gorm:"foreignKey:ClearanceActionID" json:"photos,omitempty"` // damage evidence for supplier returns
+}
+
+func (ClearanceAction) TableName() string { return "clearance_actions" }
+
+func (a *ClearanceAction) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// ClearanceItem is one product in an action, with its stock and value when the action was created. ReturnedQuantity
+// and CreditAmount accumulate as supplier returns are recorded.
+type ClearanceItem struct {
+	ID               uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	ActionID         uuid.UUID `gorm:"type:uuid;not null;index" json:"action_id"`
+	ProductID        uuid.UUID `gorm:"type:uuid;not null;index" json:"product_id"`
+	Name             string    `gorm:"size:255" json:"name"`
+	SKU              string    `gorm:"size:100" json:"sku"`
+	Quantity         int       `gorm:"not null" json:"quantity"`
+	UnitPrice        float64   `gorm:"type:decimal(12,2);not null" json:"unit_price"`
+	StockValue       float64   `gorm:"type:decimal(12,2);not null" json:"stock_value"`
+	ReturnedQuantity int       `gorm:"default:0" json:"returned_quantity"`
+	CreditAmount     float64   `gorm:"type:decimal(12,2);default:0" json:"credit_amount"`
+}
+
+func (ClearanceItem) TableName() string { return "clearance_items" }
+
+func (i *ClearanceItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// ClearanceRecoveryRow is what a promo action has recovered: units of its products sold with its promo code since
+// the action was created, and their revenue after the order discount.
+type ClearanceRecoveryRow struct {
+	ActionID  uuid.UUID `json:"action_id"`
+	UnitsSold int       `json:"units_sold"`
+	Revenue   float64   `json:"revenue"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ConsentChannel is a marketing channel a customer opts in to or out of.
+type ConsentChannel string
+
+const (
+	ConsentChannelSMS      ConsentChannel = "sms"
+	ConsentChannelEmail    ConsentChannel = "email"
+	ConsentChannelWhatsApp ConsentChannel = "whatsapp"
+	ConsentChannelPush     ConsentChannel = "push"
+)
+
+// ConsentChannels lists every channel, in display order.
+var ConsentChannels = []ConsentChannel{ConsentChannelSMS, ConsentChannelEmail, ConsentChannelWhatsApp, ConsentChannelPush}
+
+// ConsentSource is where a consent decision was captured.
+const (
+	ConsentSourceInStore   = "in_store"   // told staff at the counter
+	ConsentSourcePaperForm = "paper_form" // signed form kept by the pharmacy
+	ConsentSourcePhone     = "phone"
+	ConsentSourceWebsite   = "website"
+	ConsentSourceApp       = "app" // the customer's own account settings
+	ConsentSourceImport    = "import"
+)
+
+// MarketingNotificationTypes are notification types that count as marketing: they are only pushed to devices of
+// customers who opted in to push.
+var MarketingNotificationTypes = map[string]bool{"campaign": true, "promo": true, "marketing": true}
+
+// ConsentRecord is one opt-in or opt-out of a customer for a channel. Records are never updated or deleted:
+// the latest record per customer and channel is the current consent, and the whole history is kept for audits.
+// Without any record a customer has not opted in.
+type ConsentRecord struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID uuid.UUID      `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	CustomerID uuid.UUID      `gorm:"type:uuid;not null;index:idx_consent_customer_channel" json:"customer_id"`
+	Channel    ConsentChannel `gorm:"size:20;not null;index:idx_consent_customer_channel" json:"channel"`
+	Granted    bool           `gorm:"not null" json:"granted"` // true = opted in, false = opted out
+	Source     string         `gorm:"size:30;not null" json:"source"`
+	Note       string         `gorm:"size:500" json:"note,omitempty"`
+	RecordedBy *uuid.UUID     `gorm:"type:uuid" json:"recorded_by,omitempty"` // staff member, or the customer's own account
+	IPAddress  string         `gorm:"size:64" json:"ip_address,omitempty"`
+	CreatedAt  time.Time      `gorm:"index" json:"created_at"`
+
+	Customer *Customer `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
+}
+
+func (ConsentRecord) TableName() string { return "consent_records" }
+
+func (r *ConsentRecord) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
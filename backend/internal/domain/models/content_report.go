@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Content users can report for moderation.
+const (
+	ReportTargetReview        = "review"
+	ReportTargetReviewComment = "review_comment"
+	ReportTargetBlogComment   = "blog_comment"
+	ReportTargetChatMessage   = "chat_message"
+)
+
+// ReportReasons describes each reason a user can give when reporting content.
+var ReportReasons = map[string]string{
+	"spam":           "Advertising, links or repeated posts",
+	"harassment":     "Insults, threats or hate aimed at someone",
+	"misinformation": "Unsafe or false medical advice",
+	"offensive":      "Sexual, violent or otherwise offensive content",
+	"privacy":        "Shares someone's personal or health information",
+	"other":          "Something else; explain in the note",
+}
+
+type ContentReportStatus string
+
+const (
+	ContentReportOpen     ContentReportStatus = "open"
+	ContentReportResolved ContentReportStatus = "resolved"
+)
+
+// Moderation actions. Every action resolves all open reports on the same content.
+const (
+	ModerationDismiss        = "dismiss"         // the content is fine
+	ModerationHideContent    = "hide_content"    // remove the content from view
+	ModerationWarnUser       = "warn_user"       // notify the author
+	ModerationDeactivateUser = "deactivate_user" // deactivate the author's account
+)
+
+// ContentReport is a user's report of a review, review comment, blog comment or chat message. A user reports a
+// piece of content once; Excerpt keeps what it said at the time, so the report survives the content being hidden.
+type ContentReport struct {
+	ID             uuid.UUID           `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID     uuid.UUID           `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	TargetType     string              `gorm:"size:30;not null;index:idx_content_reports_target;uniqueIndex:idx_content_reports_reporter" json:"target_type"`
+	TargetID       uuid.UUID           `gorm:"type:uuid;not null;index:idx_content_reports_target;uniqueIndex:idx_content_reports_reporter" json:"target_id"`
+	ReporterID     uuid.UUID           `gorm:"type:uuid;not null;uniqueIndex:idx_content_reports_reporter" json:"reporter_id"`
+	AuthorID       *uuid.UUID          `gorm:"type:uuid;index" json:"author_id,omitempty"` // nil for chat messages from customers without an account
+	Reason         string              `gorm:"size:30;not null" json:"reason"`
+	Note           string              `gorm:"type:text" json:"note,omitempty"`
+	Excerpt        string              `gorm:"type:text" json:"excerpt"`
+	Status         ContentReportStatus `gorm:"size:20;not null;default:open;index" json:"status"`
+	Action         string              `gorm:"size:30" json:"action,omitempty"` // resolved reports only
+	ResolvedBy     *uuid.UUID          `gorm:"type:uuid" json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time          `json:"resolved_at,omitempty"`
+	ResolutionNote string              `gorm:"type:text" json:"resolution_note,omitempty"`
+	CreatedAt      time.Time           `json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+
+	Reporter *User `gorm:"foreignKey:ReporterID" json:"reporter,omitempty"`
+	Author   *User `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+}
+
+func (ContentReport) TableName() string { return "content_reports" }
+
+func (r *ContentReport) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
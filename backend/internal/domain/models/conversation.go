@@ -7,14 +7,34 @@ import (
 	"gorm.io/gorm"
 )
 
+// Conversation statuses. Staff escalate a conversation to the external ticketing system; it is resolved
+// when the ticket is resolved or closed there.
+const (
+	ConversationStatusOpen      = "open"
+	ConversationStatusEscalated = "escalated"
+	ConversationStatusResolved  = "resolved"
+)
+
 type Conversation struct {
 	ID            uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
 	PharmacyID    uuid.UUID  `gorm:"type:uuid;not null" json:"pharmacy_id"`
 	CustomerID    *uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_conversations_pharmacy_customer" json:"customer_id,omitempty"`
 	UserID        *uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_conversations_pharmacy_user" json:"user_id,omitempty"`
 	LastMessageAt *time.Time `json:"last_message_at,omitempty"`
-	CreatedAt     time.Time  `json:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at"`
+	Status        string     `gorm:"size:20;not null;default:open" json:"status"`
+
+	// Escalation to external ticketing. TicketError holds the last failed ticket creation (cleared on success).
+	EscalatedAt      *time.Time `json:"escalated_at,omitempty"`
+	EscalatedBy      *uuid.UUID `gorm:"type:uuid" json:"escalated_by,omitempty"`
+	EscalationReason string     `gorm:"size:500" json:"escalation_reason,omitempty"`
+	TicketProvider   string     `gorm:"size:20;index:idx_conversations_ticket" json:"ticket_provider,omitempty"`
+	TicketID         string     `gorm:"size:100;index:idx_conversations_ticket" json:"ticket_id,omitempty"`
+	TicketURL        string     `gorm:"size:500" json:"ticket_url,omitempty"`
+	TicketStatus     string     `gorm:"size:50" json:"ticket_status,omitempty"`
+	TicketError      string     `gorm:"size:500" json:"ticket_error,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 
 	Pharmacy *Pharmacy `gorm:"foreignKey:PharmacyID" json:"pharmacy,omitempty"`
 	Customer *Customer `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
@@ -27,5 +47,8 @@ func (c *Conversation) BeforeCreate(tx *gorm.DB) error {
 	if c.ID == uuid.Nil {
 		c.ID = uuid.New()
 	}
+	if c.Status == "" {
+		c.Status = ConversationStatusOpen
+	}
 	return nil
 }
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreditNoteSource is what a credit note was issued for.
+type CreditNoteSource string
+
+const (
+	CreditNoteSourceReturn CreditNoteSource = "return" // approved return request
+	CreditNoteSourceRefund CreditNoteSource = "refund" // refund paid into store credit
+)
+
+// CreditNote reduces the amount of an issued invoice after a return or refund. Numbers run in their own
+// per-pharmacy sequence (CN-000001, CN-000002, ...) assigned by CreditNoteRepository.Create. Amounts are
+// positive; Amount = TaxableAmount + TaxAmount.
+type CreditNote struct {
+	ID               uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID       uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_credit_note_sequence;uniqueIndex:idx_credit_note_number" json:"pharmacy_id"`
+	InvoiceID        uuid.UUID        `gorm:"type:uuid;not null;index" json:"invoice_id"`
+	OrderID          uuid.UUID        `gorm:"type:uuid;not null;index" json:"order_id"`
+	Sequence         int              `gorm:"not null;uniqueIndex:idx_credit_note_sequence" json:"sequence"`
+	CreditNoteNumber string           `gorm:"size:50;not null;uniqueIndex:idx_credit_note_number" json:"credit_note_number"`
+	Source           CreditNoteSource `gorm:"size:20;not null;index" json:"source"`
+	SourceID         *uuid.UUID       `gorm:"type:uuid;index" json:"source_id,omitempty"` // return request or store credit entry
+	Reason           string           `gorm:"type:text" json:"reason,omitempty"`
+	TaxableAmount    float64          `gorm:"type:decimal(12,2);not null" json:"taxable_amount"`
+	TaxAmount        float64          `gorm:"type:decimal(12,2);not null;default:0" json:"tax_amount"`
+	Amount           float64          `gorm:"type:decimal(12,2);not null" json:"amount"`
+	IssuedAt         time.Time        `gorm:"not null;index" json:"issued_at"`
+	CreatedBy        uuid.UUID        `gorm:"type:uuid" json:"created_by"`
+	CreatedAt        time.Time        `json:"created_at"`
+
+	Invoice *Invoice `gorm:"foreignKey:InvoiceID" json:"invoice,omitempty"`
+}
+
+func (CreditNote) TableName() string { return "credit_notes" }
+
+func (n *CreditNote) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	return nil
+}
+
+// SalesRegisterRow is one invoice or credit note in the sales register. Credit notes carry negative amounts
+// and the number of the invoice they reduce in ReferenceNumber.
+type SalesRegisterRow struct {
+	DocumentType    string            `json:"document_type"` // "invoice" or "credit_note"
+	DocumentNumber  string            `json:"document_number"`
+	IssuedAt        time.Time         `json:"issued_at"`
+	ReferenceNumber string            `json:"reference_number,omitempty"`
+	OrderNumber     string            `json:"order_number"`
+	CustomerName    string            `json:"customer_name"`
+	TaxableAmount   float64           `json:"taxable_amount"`
+	TaxAmount       float64           `json:"tax_amount"`
+	TotalAmount     float64           `json:"total_amount"`
+	CustomFields    CustomFieldValues `json:"custom_fields,omitempty"` // the order's custom field values
+}
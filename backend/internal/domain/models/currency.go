@@ -0,0 +1,99 @@
+package models
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultCurrency is the base currency of pharmacies that have not set one.
+const DefaultCurrency = "NPR"
+
+// Currency is an ISO 4217 currency the platform prices in. MinorUnits is the number of decimals amounts are
+// rounded to.
+type Currency struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	Symbol     string `json:"symbol"`
+	MinorUnits int    `json:"minor_units"`
+}
+
+// Currencies are the supported base and display currencies.
+var Currencies = map[string]Currency{
+	"NPR": {Code: "NPR", Name: "Nepalese rupee", Symbol: "Rs.", MinorUnits: 2},
+	"INR": {Code: "INR", Name: "Indian rupee", Symbol: "₹", MinorUnits: 2},
+	"BDT": {Code: "BDT", Name: "Bangladeshi taka", Symbol: "৳", MinorUnits: 2},
+	"USD": {Code: "USD", Name: "US dollar", Symbol: "$", MinorUnits: 2},
+	"EUR": {Code: "EUR", Name: "Euro", Symbol: "€", MinorUnits: 2},
+	"GBP": {Code: "GBP", Name: "Pound sterling", Symbol: "£", MinorUnits: 2},
+	"AUD": {Code: "AUD", Name: "Australian dollar", Symbol: "A$", MinorUnits: 2},
+	"AED": {Code: "AED", Name: "UAE dirham", Symbol: "AED", MinorUnits: 2},
+	"CNY": {Code: "CNY", Name: "Chinese yuan", Symbol: "¥", MinorUnits: 2},
+	"JPY": {Code: "JPY", Name: "Japanese yen", Symbol: "¥", MinorUnits: 0},
+	"KWD": {Code: "KWD", Name: "Kuwaiti dinar", Symbol: "KD", MinorUnits: 3},
+}
+
+// NormalizeCurrency upper-cases and trims a currency code.
+func NormalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// RoundAmount is the one rounding rule for money: half away from zero at the currency's minor unit (cents for
+// unknown currencies). Float noise below a millionth of the minor unit is dropped first, so 1.005 rounds to 1.01
+// and not to 1.00 as 100.49999… would.
+func RoundAmount(amount float64, currency string) float64 {
+	minor := 2
+	if c, ok := Currencies[NormalizeCurrency(currency)]; ok {
+		minor = c.MinorUnits
+	}
+	scale := math.Pow10(minor)
+	v := math.Round(amount*scale*1e6) / 1e6
+	return math.Round(v) / scale
+}
+
+// Currency returns the pharmacy's base currency: product prices, orders, invoices and reports are in it.
+func (c *PharmacyConfig) Currency() string {
+	if c == nil || c.BaseCurrency == "" {
+		return DefaultCurrency
+	}
+	return c.BaseCurrency
+}
+
+// ExchangeRate is a pharmacy's stored rate from its base currency: one unit of Base is Rate units of Currency.
+// Staff set rates by hand; rates stored for a former base currency are ignored.
+type ExchangeRate struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_exchange_rates_pharmacy_currency" json:"pharmacy_id"`
+	Base       string     `gorm:"size:3;not null" json:"base"`
+	Currency   string     `gorm:"size:3;not null;uniqueIndex:idx_exchange_rates_pharmacy_currency" json:"currency"`
+	Rate       float64    `gorm:"type:decimal(18,8);not null" json:"rate"`
+	UpdatedBy  *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (ExchangeRate) TableName() string { return "exchange_rates" }
+
+func (r *ExchangeRate) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// Convert turns an amount in the base currency into the rate's currency, rounded by RoundAmount.
+func (r *ExchangeRate) Convert(amount float64) float64 {
+	return RoundAmount(amount*r.Rate, r.Currency)
+}
+
+// DisplayPrice is a product's price converted to the storefront's display currency, for showing only: carts and
+// orders are always priced in the base currency.
+type DisplayPrice struct {
+	Currency  string    `json:"currency"`
+	UnitPrice float64   `json:"unit_price"`
+	Rate      float64   `json:"rate"`
+	RateAt    time.Time `json:"rate_at"` // when the rate was last set
+}
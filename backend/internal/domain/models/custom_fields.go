@@ -0,0 +1,60 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strconv"
+)
+
+// Custom field types, shared by tenant-defined order fields and product attributes.
+const (
+	CustomFieldText    = "text"
+	CustomFieldNumber  = "number"
+	CustomFieldDate    = "date" // YYYY-MM-DD
+	CustomFieldSelect  = "select"
+	CustomFieldBoolean = "boolean"
+)
+
+// CustomFieldValues holds values of tenant-defined fields keyed by field key, stored as jsonb. Text, date and
+// select values are strings, numbers are float64 and booleans bool.
+type CustomFieldValues map[string]any
+
+func (f CustomFieldValues) Value() (driver.Value, error) {
+	if len(f) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(f)
+}
+
+func (f *CustomFieldValues) Scan(value interface{}) error {
+	if value == nil {
+		*f = nil
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		s, ok := value.(string)
+		if !ok {
+			return nil
+		}
+		b = []byte(s)
+	}
+	return json.Unmarshal(b, f)
+}
+
+// Text formats one value for documents and exports; empty when the field is not set.
+func (f CustomFieldValues) Text(key string) string {
+	switch v := f[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "Yes"
+		}
+		return "No"
+	default:
+		return ""
+	}
+}
@@ -16,8 +16,18 @@ type Customer struct {
 	Phone         string         `gorm:"size:50;not null;uniqueIndex:idx_customers_pharmacy_phone" json:"phone"`
 	Email         string         `gorm:"size:255" json:"email"`
 	ReferralCode  string         `gorm:"size:20;not null;uniqueIndex:idx_customers_pharmacy_referral" json:"referral_code"`
-	PointsBalance int            `gorm:"not null;default:0" json:"points_balance"`
+	// PointsBalance is written on create (opening or imported balance); after that it only changes through
+	// CustomerRepository.AdjustPoints, next to its PointsTransaction, and updates skip it.
+	PointsBalance int            `gorm:"not null;default:0;<-:create" json:"points_balance"`
+	// StoreCreditBalance only changes through StoreCreditEntry rows (see StoreCreditRepository.Adjust); saves skip it.
+	StoreCreditBalance float64 `gorm:"type:decimal(12,2);not null;default:0;<-:false" json:"store_credit_balance"`
 	ReferredByID  *uuid.UUID     `gorm:"type:uuid;index" json:"referred_by_id,omitempty"`
+	// PreferredLanguage for order emails and SMS; empty = pharmacy default.
+	PreferredLanguage string `gorm:"size:16" json:"preferred_language,omitempty"`
+	// DateOfBirth enables the birthday gift of the customer's membership tier.
+	DateOfBirth *time.Time `gorm:"type:date" json:"date_of_birth,omitempty"`
+	// BirthdayGiftYear is the last year the birthday gift was used; only changed by CustomerRepository.ClaimBirthdayGift.
+	BirthdayGiftYear int `gorm:"not null;default:0;<-:false" json:"birthday_gift_year,omitempty"`
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
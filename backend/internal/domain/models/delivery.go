@@ -0,0 +1,146 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DeliveryStatus is the delivery stage of an order. Stages only move forward.
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPacked         DeliveryStatus = "packed"
+	DeliveryStatusDispatched     DeliveryStatus = "dispatched"
+	DeliveryStatusOutForDelivery DeliveryStatus = "out_for_delivery"
+	DeliveryStatusDelivered      DeliveryStatus = "delivered"
+)
+
+// DeliveryStatusRank orders the stages; an unknown status has rank 0.
+func DeliveryStatusRank(s DeliveryStatus) int {
+	switch s {
+	case DeliveryStatusPacked:
+		return 1
+	case DeliveryStatusDispatched:
+		return 2
+	case DeliveryStatusOutForDelivery:
+		return 3
+	case DeliveryStatusDelivered:
+		return 4
+	}
+	return 0
+}
+
+// Delivery tracks getting one order to the customer. Latitude/Longitude and LocationNote hold the latest
+// reported position; the full trail is in Events. ReceivedBy through OTPVerifiedAt are the proof of delivery
+// captured by the courier; the code is only checked when PharmacyConfig.DeliveryOTPRequired is set.
+// DestLatitude/DestLongitude and the optional window are the drop-off used for route planning; BatchID,
+// StopSequence and ETA are set when the delivery is planned into a rider's dispatch batch.
+type Delivery struct {
+	ID               uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID       uuid.UUID      `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	OrderID          uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"order_id"`
+	Status           DeliveryStatus `gorm:"size:32;not null;index" json:"status"`
+	AssignedTo       *uuid.UUID     `gorm:"type:uuid;index" json:"assigned_to,omitempty"` // delivery person (user)
+	LocationNote     string         `gorm:"size:500" json:"location_note"`
+	Latitude         *float64       `json:"latitude,omitempty"`
+	Longitude        *float64       `json:"longitude,omitempty"`
+	LocationAt       *time.Time     `json:"location_at,omitempty"`
+	PackedAt         *time.Time     `json:"packed_at,omitempty"`
+	DispatchedAt     *time.Time     `json:"dispatched_at,omitempty"`
+	OutForDeliveryAt *time.Time     `json:"out_for_delivery_at,omitempty"`
+	DeliveredAt      *time.Time     `json:"delivered_at,omitempty"`
+	ReceivedBy       string         `gorm:"size:255" json:"received_by,omitempty"`
+	ReceiverRelation string         `gorm:"size:20" json:"receiver_relation,omitempty"`
+	SignatureURL     string         `gorm:"size:500" json:"signature_url,omitempty"`
+	PhotoURL         string         `gorm:"size:500" json:"photo_url,omitempty"`
+	OTPHash          string         `gorm:"size:128" json:"-"`
+	OTPAttempts      int            `gorm:"not null;default:0" json:"-"`
+	OTPSentAt        *time.Time     `json:"otp_sent_at,omitempty"`
+	OTPVerifiedAt    *time.Time     `json:"otp_verified_at,omitempty"`
+	DestLatitude     *float64       `json:"dest_latitude,omitempty"`
+	DestLongitude    *float64       `json:"dest_longitude,omitempty"`
+	WindowStart      *time.Time     `json:"window_start,omitempty"`
+	WindowEnd        *time.Time     `json:"window_end,omitempty"`
+	BatchID          *uuid.UUID     `gorm:"type:uuid;index" json:"batch_id,omitempty"`
+	StopSequence     int            `gorm:"not null;default:0" json:"stop_sequence,omitempty"`
+	ETA              *time.Time     `json:"eta,omitempty"`
+	CreatedBy        uuid.UUID      `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+
+	Assignee *User            `gorm:"foreignKey:AssignedTo" json:"assignee,omitempty"`
+	Events   []*DeliveryEvent `gorm:"foreignKey:DeliveryID" json:"events,omitempty"`
+}
+
+func (Delivery) TableName() string { return "deliveries" }
+
+func (d *Delivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// DeliveryEvent is one entry in a delivery's timeline: a status change, assignment or location update.
+type DeliveryEvent struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	DeliveryID uuid.UUID      `gorm:"type:uuid;not null;index" json:"delivery_id"`
+	Kind       string         `gorm:"size:20;not null" json:"kind"` // status, assigned, location
+	Status     DeliveryStatus `gorm:"size:32;not null" json:"status"`
+	Note       string         `gorm:"size:500" json:"note"`
+	Latitude   *float64       `json:"latitude,omitempty"`
+	Longitude  *float64       `json:"longitude,omitempty"`
+	CreatedBy  uuid.UUID      `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+func (DeliveryEvent) TableName() string { return "delivery_events" }
+
+func (e *DeliveryEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// Delivery event kinds.
+const (
+	DeliveryEventStatus   = "status"
+	DeliveryEventAssigned = "assigned"
+	DeliveryEventLocation = "location"
+	DeliveryEventProof    = "proof"  // signature or doorstep photo captured
+	DeliveryEventOTP      = "otp"    // delivery code sent to the customer
+	DeliveryEventRouted   = "routed" // planned into a dispatch batch
+)
+
+// Receiver relations recorded with the proof of delivery.
+var DeliveryReceiverRelations = map[string]bool{
+	"self": true, "family": true, "friend": true, "neighbour": true, "colleague": true, "security": true, "other": true,
+}
+
+// DeliveryProof is the proof of delivery shown on the order tracking view and the invoice.
+type DeliveryProof struct {
+	DeliveredAt      *time.Time `json:"delivered_at,omitempty"`
+	ReceivedBy       string     `json:"received_by,omitempty"`
+	ReceiverRelation string     `json:"receiver_relation,omitempty"`
+	SignatureURL     string     `json:"signature_url,omitempty"`
+	PhotoURL         string     `json:"photo_url,omitempty"`
+	OTPVerified      bool       `json:"otp_verified"`
+}
+
+// Proof returns the delivery's proof, or nil when nothing was captured.
+func (d *Delivery) Proof() *DeliveryProof {
+	if d.ReceivedBy == "" && d.ReceiverRelation == "" && d.SignatureURL == "" && d.PhotoURL == "" && d.OTPVerifiedAt == nil {
+		return nil
+	}
+	return &DeliveryProof{
+		DeliveredAt:      d.DeliveredAt,
+		ReceivedBy:       d.ReceivedBy,
+		ReceiverRelation: d.ReceiverRelation,
+		SignatureURL:     d.SignatureURL,
+		PhotoURL:         d.PhotoURL,
+		OTPVerified:      d.OTPVerifiedAt != nil,
+	}
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Delivery job kinds: one per outbound channel handled by the delivery queue.
+const (
+	DeliveryJobKindEmail   = "email"
+	DeliveryJobKindSMS     = "sms"
+	DeliveryJobKindWebhook = "webhook"
+)
+
+// Delivery job statuses. Delivered jobs are deleted, so the table only holds pending, running and dead jobs.
+const (
+	DeliveryJobStatusPending = "pending"
+	DeliveryJobStatusRunning = "running"
+	DeliveryJobStatusDead    = "dead" // gave up after the maximum attempts; retried manually
+)
+
+// DeliveryJob is a queued email, SMS or webhook call. Payload holds the message as JSON and is never
+// returned by the API (it may contain one-time codes or reset links); Target and Summary describe it instead.
+type DeliveryJob struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID    uuid.UUID  `gorm:"type:uuid;index" json:"pharmacy_id"` // uuid.Nil when the sender did not know the pharmacy
+	Kind          string     `gorm:"size:20;not null;index" json:"kind"`
+	Target        string     `gorm:"size:500" json:"target"`  // recipients or webhook URL
+	Summary       string     `gorm:"size:255" json:"summary"` // email subject or webhook event
+	Payload       string     `gorm:"type:text;not null" json:"-"`
+	Status        string     `gorm:"size:20;not null;default:pending;index:idx_delivery_jobs_due,priority:1" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts   int        `gorm:"not null" json:"max_attempts"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_delivery_jobs_due,priority:2" json:"next_attempt_at"`
+	LockedUntil   *time.Time `json:"-"`
+	LastError     string     `gorm:"size:1000" json:"last_error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (DeliveryJob) TableName() string { return "delivery_jobs" }
+
+func (j *DeliveryJob) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == uuid.Nil {
+		j.ID = uuid.New()
+	}
+	if j.Status == "" {
+		j.Status = DeliveryJobStatusPending
+	}
+	return nil
+}
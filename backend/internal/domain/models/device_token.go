@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Device platforms accepted at registration.
+const (
+	DevicePlatformWeb     = "web"
+	DevicePlatformAndroid = "android"
+	DevicePlatformIOS     = "ios"
+)
+
+// DeviceToken is a push registration (FCM token) of one of a user's browsers or app installs.
+// A token belongs to at most one user; registering it again moves it to the caller.
+type DeviceToken struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID uuid.UUID `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Token      string    `gorm:"size:512;not null;uniqueIndex" json:"token"`
+	Platform   string    `gorm:"size:20;not null;default:web" json:"platform"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (DeviceToken) TableName() string { return "device_tokens" }
+
+func (d *DeviceToken) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DispatchBatch is one rider's planned run: the packed deliveries in Deliveries, visited in StopSequence order
+// from the start point at DepartAt. FinishAt is the estimated time the last stop is done and DistanceKm the
+// straight-line length of the route.
+type DispatchBatch struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID     uuid.UUID `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	RiderID        uuid.UUID `gorm:"type:uuid;not null;index" json:"rider_id"`
+	StartLatitude  float64   `json:"start_latitude"`
+	StartLongitude float64   `json:"start_longitude"`
+	DepartAt       time.Time `json:"depart_at"`
+	FinishAt       time.Time `json:"finish_at"`
+	DistanceKm     float64   `json:"distance_km"`
+	Stops          int       `gorm:"not null;default:0" json:"stops"`
+	CreatedBy      uuid.UUID `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	Rider      *User       `gorm:"foreignKey:RiderID" json:"rider,omitempty"`
+	Deliveries []*Delivery `gorm:"foreignKey:BatchID" json:"-"`
+}
+
+func (DispatchBatch) TableName() string { return "dispatch_batches" }
+
+func (b *DispatchBatch) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProductIngredient is one active ingredient of a product (pharmacy business type). Ingredient is stored
+// normalized (trimmed, lowercase) so interaction rules and duplicate-therapy checks match across brands.
+type ProductIngredient struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID uuid.UUID `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	ProductID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_product_ingredient" json:"product_id"`
+	Ingredient string    `gorm:"size:255;not null;uniqueIndex:idx_product_ingredient;index" json:"ingredient"`
+	Strength   string    `gorm:"size:100" json:"strength,omitempty"` // e.g. 500 mg
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (ProductIngredient) TableName() string { return "product_ingredients" }
+
+func (i *ProductIngredient) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+type InteractionSeverity string
+
+const (
+	InteractionSeverityMinor           InteractionSeverity = "minor"
+	InteractionSeverityModerate        InteractionSeverity = "moderate"
+	InteractionSeverityMajor           InteractionSeverity = "major"
+	InteractionSeverityContraindicated InteractionSeverity = "contraindicated"
+)
+
+func ValidInteractionSeverity(s InteractionSeverity) bool {
+	switch s {
+	case InteractionSeverityMinor, InteractionSeverityModerate, InteractionSeverityMajor, InteractionSeverityContraindicated:
+		return true
+	}
+	return false
+}
+
+// DrugInteraction is a pharmacy's rule that two ingredients interact. IngredientA < IngredientB so each
+// pair is stored once.
+type DrugInteraction struct {
+	ID          uuid.UUID           `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID  uuid.UUID           `gorm:"type:uuid;not null;uniqueIndex:idx_drug_interaction_pair" json:"pharmacy_id"`
+	IngredientA string              `gorm:"size:255;not null;uniqueIndex:idx_drug_interaction_pair" json:"ingredient_a"`
+	IngredientB string              `gorm:"size:255;not null;uniqueIndex:idx_drug_interaction_pair" json:"ingredient_b"`
+	Severity    InteractionSeverity `gorm:"size:20;not null" json:"severity"`
+	Description string              `gorm:"type:text" json:"description,omitempty"`
+	CreatedBy   uuid.UUID           `gorm:"type:uuid" json:"created_by"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+func (DrugInteraction) TableName() string { return "drug_interactions" }
+
+func (d *DrugInteraction) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+type ClinicalWarningType string
+
+const (
+	ClinicalWarningInteraction      ClinicalWarningType = "interaction"
+	ClinicalWarningDuplicateTherapy ClinicalWarningType = "duplicate_therapy"
+)
+
+// ClinicalWarning is raised on an order when two of its products interact or share an ingredient.
+// It is stored on the order; the pharmacist acknowledges the order's warnings before accepting it.
+type ClinicalWarning struct {
+	Type         ClinicalWarningType `json:"type"`
+	Severity     InteractionSeverity `json:"severity"`
+	Ingredients  []string            `json:"ingredients"`
+	ProductIDs   []uuid.UUID         `json:"product_ids"`
+	ProductNames []string            `json:"product_names"`
+	Message      string              `json:"message"`
+}
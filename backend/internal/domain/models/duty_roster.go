@@ -16,16 +16,30 @@ const (
 	ShiftFull    ShiftType = "full"
 )
 
+// RosterStatus: draft entries are only visible to managers; published entries are shown to the assigned staff.
+type RosterStatus string
+
+const (
+	RosterDraft     RosterStatus = "draft"
+	RosterPublished RosterStatus = "published"
+)
+
 type DutyRoster struct {
-	ID         uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
-	PharmacyID uuid.UUID      `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
-	UserID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"` // pharmacist
-	Date       time.Time      `gorm:"type:date;not null;index" json:"date"`
-	ShiftType  ShiftType      `gorm:"size:20;not null" json:"shift_type"`
-	Notes      string         `gorm:"size:500" json:"notes"`
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID uuid.UUID `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"` // pharmacist
+	Date       time.Time `gorm:"type:date;not null;index" json:"date"`
+	ShiftType  ShiftType `gorm:"size:20;not null" json:"shift_type"`
+	Notes      string    `gorm:"size:500" json:"notes"`
+	// Rows created before publishing existed default to published so staff keep seeing them.
+	Status         RosterStatus   `gorm:"size:20;not null;default:published;index" json:"status"`
+	PublishedAt    *time.Time     `json:"published_at,omitempty"`
+	Revision       int            `gorm:"not null;default:0" json:"revision"` // 1 when first published, +1 for every change after that
+	ChangeNote     string         `gorm:"size:500" json:"change_note,omitempty"`
+	AcknowledgedAt *time.Time     `json:"acknowledged_at,omitempty"` // cleared on every publish or change
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 
 	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
@@ -0,0 +1,13 @@
+package models
+
+// ETAStats are a pharmacy's recent average handling times, the basis of order ETAs.
+// PrepMinutes runs from order placement to ready; DeliveryMinutes from dispatch to delivered.
+type ETAStats struct {
+	PrepSamples     int     `json:"prep_samples"`
+	PrepMinutes     float64 `json:"prep_minutes"`
+	DeliverySamples int     `json:"delivery_samples"`
+	DeliveryMinutes float64 `json:"delivery_minutes"`
+}
+
+// ETAOpenStatuses are the order statuses still waiting to be prepared.
+var ETAOpenStatuses = []OrderStatus{OrderStatusPending, OrderStatusConfirmed, OrderStatusProcessing}
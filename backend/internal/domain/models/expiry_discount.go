@@ -0,0 +1,61 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// ExpiryDiscountTier discounts a product whose earliest batch expires within DaysBeforeExpiry days.
+type ExpiryDiscountTier struct {
+	DaysBeforeExpiry int     `json:"days_before_expiry"`
+	DiscountPercent  float64 `json:"discount_percent"`
+}
+
+// ExpiryDiscountPolicy is the per-pharmacy near-expiry markdown policy (stored as JSONB on PharmacyConfig),
+// e.g. 20% at 60 days and 40% at 30 days. Products in excluded categories (or their subcategories) are never discounted.
+type ExpiryDiscountPolicy struct {
+	Enabled             bool                 `json:"enabled"`
+	Tiers               []ExpiryDiscountTier `json:"tiers"`
+	ExcludedCategoryIDs []uuid.UUID          `json:"excluded_category_ids,omitempty"`
+}
+
+// PercentFor returns the discount for a batch expiring in daysLeft days: the largest discount among
+// tiers whose window covers daysLeft, or 0 when none does.
+func (p *ExpiryDiscountPolicy) PercentFor(daysLeft int) float64 {
+	if p == nil || !p.Enabled || daysLeft < 0 {
+		return 0
+	}
+	best := 0.0
+	for _, t := range p.Tiers {
+		if daysLeft <= t.DaysBeforeExpiry && t.DiscountPercent > best {
+			best = t.DiscountPercent
+		}
+	}
+	return best
+}
+
+// MaxDays is the widest tier window; batches expiring later are never discounted.
+func (p *ExpiryDiscountPolicy) MaxDays() int {
+	max := 0
+	if p == nil {
+		return max
+	}
+	for _, t := range p.Tiers {
+		if t.DaysBeforeExpiry > max {
+			max = t.DaysBeforeExpiry
+		}
+	}
+	return max
+}
+
+// IsExcluded reports whether a category (or its parent) is excluded from expiry discounts.
+func (p *ExpiryDiscountPolicy) IsExcluded(categoryID, parentID *uuid.UUID) bool {
+	if p == nil {
+		return false
+	}
+	for _, id := range p.ExcludedCategoryIDs {
+		if (categoryID != nil && *categoryID == id) || (parentID != nil && *parentID == id) {
+			return true
+		}
+	}
+	return false
+}
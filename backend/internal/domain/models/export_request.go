@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Exports that contain personal data. Downloading one needs PermExportsApprove or an approved ExportRequest.
+const (
+	ExportKindConsents      = "consents"       // customer names, phones and emails with consent history
+	ExportKindActivity      = "activity"       // team member names, emails and IP addresses
+	ExportKindSalesRegister = "sales_register" // customer names per invoice
+	ExportKindStaffPoints   = "staff_points"   // team member names and emails
+)
+
+// PIIExportKinds describes each export kind that needs approval.
+var PIIExportKinds = map[string]string{
+	ExportKindConsents:      "Marketing consent records (GET /consents/export)",
+	ExportKindActivity:      "Activity log (GET /activity/export)",
+	ExportKindSalesRegister: "Sales register CSV (GET /reports/sales-register?format=csv)",
+	ExportKindStaffPoints:   "Staff points CSV (GET /reports/staff-points?format=csv)",
+}
+
+type ExportRequestStatus string
+
+const (
+	ExportRequestPending  ExportRequestStatus = "pending"
+	ExportRequestApproved ExportRequestStatus = "approved"
+	ExportRequestRejected ExportRequestStatus = "rejected"
+	ExportRequestUsed     ExportRequestStatus = "used"
+)
+
+// ExportRequest asks a manager to approve one download of a PII export. An approval is good for one
+// download until ExpiresAt.
+type ExportRequest struct {
+	ID          uuid.UUID           `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID  uuid.UUID           `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	Kind        string              `gorm:"size:50;not null" json:"kind"`
+	Reason      string              `gorm:"type:text" json:"reason"`
+	Status      ExportRequestStatus `gorm:"size:20;not null;default:pending;index" json:"status"`
+	RequestedBy uuid.UUID           `gorm:"type:uuid;not null;index" json:"requested_by"`
+	ReviewedBy  *uuid.UUID          `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time          `json:"reviewed_at,omitempty"`
+	ReviewNote  string              `gorm:"type:text" json:"review_note,omitempty"`
+	ExpiresAt   *time.Time          `json:"expires_at,omitempty"` // approved requests only
+	UsedAt      *time.Time          `json:"used_at,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+
+	Requester *User `gorm:"foreignKey:RequestedBy" json:"requester,omitempty"`
+}
+
+func (ExportRequest) TableName() string { return "export_requests" }
+
+func (r *ExportRequest) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FileReference is the ledger entry for a file written to storage. The orphan sweep deletes files nothing in
+// the database points at any more; files stored before the ledger existed are never touched.
+type FileReference struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	URL         string     `gorm:"size:512;not null;uniqueIndex" json:"url"` // as returned by Save, or "private:<path>"
+	Path        string     `gorm:"size:512;not null" json:"path"`
+	Private     bool       `gorm:"not null;default:false" json:"private"`
+	ContentType string     `gorm:"size:120" json:"content_type,omitempty"`
+	Size        int64      `gorm:"not null;default:0" json:"size"`
+	ReleasedAt  *time.Time `gorm:"index" json:"released_at,omitempty"` // its owner was deleted; swept without a grace period
+	CheckedAt   *time.Time `json:"checked_at,omitempty"`               // last time the sweep found it still referenced
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (FileReference) TableName() string { return "file_references" }
+
+func (f *FileReference) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return nil
+}
+
+// FileSweepReport is the outcome of one orphan sweep. In a dry run nothing is deleted and Files lists what
+// would be.
+type FileSweepReport struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	DryRun      bool             `json:"dry_run"`
+	Checked     int              `json:"checked"` // ledger entries looked at in this batch
+	Orphans     int              `json:"orphans"`
+	Bytes       int64            `json:"bytes"`   // total size of the orphans
+	Deleted     int              `json:"deleted"` // orphans removed from storage and the ledger
+	Files       []*FileReference `json:"files"`   // the orphans, at most FileSweepSampleSize
+}
+
+// FileSweepSampleSize caps the orphans listed in a sweep report; the counts always cover all of them.
+const FileSweepSampleSize = 100
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FlashSale is a time-boxed price drop on a set of products. Prices are applied at order time while the
+// sale is live; product unit prices are never changed, so they revert automatically when the sale ends.
+type FlashSale struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID  uuid.UUID      `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	Name        string         `gorm:"size:255;not null" json:"name"`
+	Description string         `gorm:"type:text" json:"description,omitempty"`
+	StartsAt    time.Time      `gorm:"not null;index" json:"starts_at"`
+	EndsAt      time.Time      `gorm:"not null;index" json:"ends_at"`
+	IsActive    bool           `gorm:"default:true;index" json:"is_active"`
+	CreatedBy   uuid.UUID      `gorm:"type:uuid" json:"created_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Items []FlashSaleItem `gorm:"foreignKey:FlashSaleID" json:"items,omitempty"`
+}
+
+func (FlashSale) TableName() string { return "flash_sales" }
+
+func (f *FlashSale) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsLive reports whether the sale is enabled and now is within [StartsAt, EndsAt).
+func (f *FlashSale) IsLive(now time.Time) bool {
+	return f.IsActive && !now.Before(f.StartsAt) && now.Before(f.EndsAt)
+}
+
+// FlashSaleItem is one product in a flash sale. SoldQuantity is incremented atomically at order time.
+type FlashSaleItem struct {
+	ID               uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	FlashSaleID      uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_flash_sale_product" json:"flash_sale_id"`
+	ProductID        uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_flash_sale_product" json:"product_id"`
+	SalePrice        float64   `gorm:"type:decimal(12,2);not null" json:"sale_price"`
+	MaxQuantity      int       `gorm:"default:0" json:"max_quantity"`       // total units for the sale; 0 = unlimited
+	PerCustomerLimit int       `gorm:"default:0" json:"per_customer_limit"` // units per customer; 0 = unlimited
+	SoldQuantity     int       `gorm:"default:0" json:"sold_quantity"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+
+	FlashSale *FlashSale `gorm:"foreignKey:FlashSaleID" json:"flash_sale,omitempty"`
+	Product   *Product   `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+func (FlashSaleItem) TableName() string { return "flash_sale_items" }
+
+func (i *FlashSaleItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// FlashSaleRedemption records units bought at a flash-sale price, so a cancelled order can return them.
+// CustomerKey is the order's customer phone when the units also count in FlashSaleCustomerUsage; empty otherwise.
+type FlashSaleRedemption struct {
+	ID              uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	FlashSaleItemID uuid.UUID `gorm:"type:uuid;not null;index:idx_flash_redemption_customer" json:"flash_sale_item_id"`
+	CustomerKey     string    `gorm:"size:100;not null;index:idx_flash_redemption_customer" json:"customer_key"`
+	OrderID         uuid.UUID `gorm:"type:uuid;not null;index" json:"order_id"`
+	Quantity        int       `gorm:"not null" json:"quantity"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func (FlashSaleRedemption) TableName() string { return "flash_sale_redemptions" }
+
+func (r *FlashSaleRedemption) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// FlashSaleCustomerUsage counts the units of a sale item held by one customer, for the per-customer limit. The
+// count is raised with a conditional upsert at reservation time, so concurrent orders cannot both pass the limit.
+type FlashSaleCustomerUsage struct {
+	FlashSaleItemID uuid.UUID `gorm:"type:uuid;primaryKey" json:"flash_sale_item_id"`
+	CustomerKey     string    `gorm:"size:100;primaryKey" json:"customer_key"`
+	Quantity        int       `gorm:"not null;default:0" json:"quantity"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func (FlashSaleCustomerUsage) TableName() string { return "flash_sale_customer_usage" }
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GiftCard is prepaid store value redeemable against orders at one pharmacy. Balance only changes through
+// GiftCardTransaction rows, written together with the balance update.
+type GiftCard struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_gift_card_pharmacy_code" json:"pharmacy_id"`
+	Code          string     `gorm:"size:32;not null;uniqueIndex:idx_gift_card_pharmacy_code" json:"code"`
+	InitialAmount float64    `gorm:"type:decimal(12,2);not null" json:"initial_amount"`
+	Balance       float64    `gorm:"type:decimal(12,2);not null;default:0;<-:create" json:"balance"`
+	Currency      string     `gorm:"size:10;default:NPR" json:"currency"`
+	ExpiresAt     *time.Time `gorm:"index" json:"expires_at,omitempty"` // nil = never expires
+	IsActive      bool       `gorm:"default:true" json:"is_active"`
+	CustomerID    *uuid.UUID `gorm:"type:uuid;index" json:"customer_id,omitempty"` // optional recipient
+	Note          string     `gorm:"type:text" json:"note,omitempty"`
+	IssuedBy      uuid.UUID  `gorm:"type:uuid" json:"issued_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (GiftCard) TableName() string { return "gift_cards" }
+
+func (g *GiftCard) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == uuid.Nil {
+		g.ID = uuid.New()
+	}
+	return nil
+}
+
+// Usable reports whether the card can be redeemed at now, with the reason when it cannot.
+func (g *GiftCard) Usable(now time.Time) (bool, string) {
+	switch {
+	case !g.IsActive:
+		return false, "gift card is deactivated"
+	case g.ExpiresAt != nil && now.After(*g.ExpiresAt):
+		return false, "gift card has expired"
+	case g.Balance <= 0:
+		return false, "gift card has no balance left"
+	}
+	return true, ""
+}
+
+// StoredValueEntryType is the kind of a gift card or store credit movement.
+type StoredValueEntryType string
+
+const (
+	StoredValueIssue      StoredValueEntryType = "issue"      // gift card issued
+	StoredValueRedeem     StoredValueEntryType = "redeem"     // spent on an order or at the counter
+	StoredValueReversal   StoredValueEntryType = "reversal"   // redemption returned (order cancelled or not created)
+	StoredValueRefund     StoredValueEntryType = "refund"     // order refunded to store credit
+	StoredValueAdjustment StoredValueEntryType = "adjustment" // manual correction by staff
+	StoredValueTopUp      StoredValueEntryType = "top_up"     // wallet topped up through a payment gateway
+)
+
+// GiftCardTransaction is one balance movement. Amount is positive for issue and reversal, negative for redeem.
+type GiftCardTransaction struct {
+	ID           uuid.UUID            `gorm:"type:uuid;primaryKey" json:"id"`
+	GiftCardID   uuid.UUID            `gorm:"type:uuid;not null;index" json:"gift_card_id"`
+	Type         StoredValueEntryType `gorm:"size:20;not null" json:"type"`
+	Amount       float64              `gorm:"type:decimal(12,2);not null" json:"amount"`
+	BalanceAfter float64              `gorm:"type:decimal(12,2);not null" json:"balance_after"`
+	OrderID      *uuid.UUID           `gorm:"type:uuid;index" json:"order_id,omitempty"`
+	CreatedBy    uuid.UUID            `gorm:"type:uuid" json:"created_by"`
+	CreatedAt    time.Time            `json:"created_at"`
+}
+
+func (GiftCardTransaction) TableName() string { return "gift_card_transactions" }
+
+func (t *GiftCardTransaction) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// StoreCreditEntry is one movement on a customer's store credit (Customer.StoreCreditBalance). Amount is
+// positive for refunds, reversals and credits, negative for redemptions.
+type StoreCreditEntry struct {
+	ID           uuid.UUID            `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID   uuid.UUID            `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	CustomerID   uuid.UUID            `gorm:"type:uuid;not null;index" json:"customer_id"`
+	Type         StoredValueEntryType `gorm:"size:20;not null" json:"type"`
+	Amount       float64              `gorm:"type:decimal(12,2);not null" json:"amount"`
+	BalanceAfter float64              `gorm:"type:decimal(12,2);not null" json:"balance_after"`
+	OrderID      *uuid.UUID           `gorm:"type:uuid;index" json:"order_id,omitempty"`
+	Note         string               `gorm:"type:text" json:"note,omitempty"`
+	CreatedBy    uuid.UUID            `gorm:"type:uuid" json:"created_by"`
+	CreatedAt    time.Time            `json:"created_at"`
+}
+
+func (StoreCreditEntry) TableName() string { return "store_credit_entries" }
+
+func (e *StoreCreditEntry) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Hashtag is a pharmacy's registered product hashtag. Tag is the canonical form stored on products; Aliases are
+// spellings merged into it, rewritten to Tag when a product is saved. A blocked tag is stripped from products.
+type Hashtag struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_hashtag_pharmacy_tag" json:"pharmacy_id"`
+	Tag        string    `gorm:"size:50;not null;uniqueIndex:idx_hashtag_pharmacy_tag" json:"tag"`
+	Label      string    `gorm:"size:100" json:"label,omitempty"` // display text for storefront sections; defaults to the tag
+	Aliases    []string  `gorm:"type:jsonb;serializer:json" json:"aliases"`
+	IsBlocked  bool      `gorm:"default:false;index" json:"is_blocked"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	ProductCount int64 `gorm:"-" json:"product_count"` // products carrying the tag, filled by the list
+}
+
+func (Hashtag) TableName() string { return "hashtags" }
+
+func (h *Hashtag) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
+
+// CanonicalHashtag lower-cases a tag, drops leading '#', joins words with single '-' and removes anything
+// but letters (with their combining marks) and digits, keeping at most 50 characters. It returns "" when nothing is left.
+func CanonicalHashtag(s string) string {
+	s = strings.ToLower(strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(s), "#")))
+	var b strings.Builder
+	dash := false
+	for _, r := range s {
+		switch {
+		case r == ' ' || r == '_' || r == '-':
+			dash = b.Len() > 0
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) && b.Len() > 0:
+			if dash {
+				b.WriteByte('-')
+				dash = false
+			}
+			b.WriteRune(r)
+		}
+	}
+	out := b.String()
+	if r := []rune(out); len(r) > 50 {
+		out = strings.TrimRight(string(r[:50]), "-")
+	}
+	return out
+}
+
+// ProductDailyView counts storefront views of one product on one day.
+type ProductDailyView struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID uuid.UUID `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	ProductID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_product_view_day" json:"product_id"`
+	Day        time.Time `gorm:"type:date;not null;uniqueIndex:idx_product_view_day" json:"day"`
+	Views      int64     `gorm:"not null;default:0" json:"views"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (ProductDailyView) TableName() string { return "product_daily_views" }
+
+func (v *ProductDailyView) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
+
+// HashtagUsageRow is one tag value as stored on products (not canonicalized) with the number of products carrying it.
+type HashtagUsageRow struct {
+	Tag          string `json:"tag"`
+	ProductCount int64  `json:"product_count"`
+}
+
+// HashtagTrend is a tag's storefront views and units sold over the trending window, on active products only.
+type HashtagTrend struct {
+	Tag          string `json:"tag"`
+	Label        string `json:"label"`
+	ProductCount int64  `json:"product_count"`
+	Views        int64  `json:"views"`
+	UnitsSold    int64  `json:"units_sold"`
+}
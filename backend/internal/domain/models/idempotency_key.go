@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Idempotency key statuses.
+const (
+	IdempotencyStatusInProgress = "in_progress"
+	IdempotencyStatusCompleted  = "completed"
+)
+
+// IdempotencyKey remembers a request sent with an Idempotency-Key header and, once it finished, the response
+// that was returned, so a retry with the same key gets that response instead of repeating the request.
+// Keys are per user and per route (Scope, e.g. "POST /api/v1/orders"); RequestHash is the SHA-256 of the
+// body, so reusing a key for a different request is rejected.
+type IdempotencyKey struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID     uuid.UUID `gorm:"type:uuid;index" json:"pharmacy_id"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_idempotency_user_scope_key,priority:1" json:"user_id"`
+	Scope          string    `gorm:"size:120;not null;uniqueIndex:idx_idempotency_user_scope_key,priority:2" json:"scope"`
+	Key            string    `gorm:"size:255;not null;uniqueIndex:idx_idempotency_user_scope_key,priority:3" json:"key"`
+	RequestHash    string    `gorm:"size:64;not null" json:"-"`
+	Status         string    `gorm:"size:20;not null;default:in_progress" json:"status"`
+	ResponseStatus int       `json:"response_status,omitempty"`
+	ContentType    string    `gorm:"size:100" json:"-"`
+	ResponseBody   []byte    `json:"-"`
+	ExpiresAt      time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (IdempotencyKey) TableName() string { return "idempotency_keys" }
+
+func (k *IdempotencyKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	if k.Status == "" {
+		k.Status = IdempotencyStatusInProgress
+	}
+	return nil
+}
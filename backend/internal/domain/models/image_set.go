@@ -0,0 +1,33 @@
+package models
+
+// Rendition sizes generated for uploaded promo and announcement images.
+const (
+	BannerImageWidth     = 1200
+	BannerImageHeight    = 400
+	ThumbnailImageWidth  = 400
+	ThumbnailImageHeight = 200
+)
+
+// Low-bandwidth product image rendition (?quality=low): fits in LowImageSize x LowImageSize, JPEG at LowImageQuality.
+const (
+	LowImageSize    = 320
+	LowImageQuality = 60
+)
+
+// ProductImageSizes bounds the variants made for each product image upload, in pixels; each fits within
+// Size x Size. Thumbnails are for small lists, medium for catalog cards, large for the product detail page.
+type ProductImageSizes struct {
+	Thumbnail int
+	Medium    int
+	Large     int
+}
+
+// ImageSet is an uploaded image with its generated renditions. Banner (3:1) is for carousels and popups,
+// Thumbnail (2:1) for lists and cards; Original keeps the file as uploaded.
+type ImageSet struct {
+	Original  string `json:"original"`
+	Banner    string `json:"banner"`
+	Thumbnail string `json:"thumbnail"`
+	Width     int    `json:"width"`  // original width in pixels
+	Height    int    `json:"height"` // original height in pixels
+}
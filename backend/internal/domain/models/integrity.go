@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Integrity check codes reported by the data doctor.
+const (
+	IntegrityCheckCrossTenantOrderItem = "order_item_cross_tenant"    // order line for a product of another pharmacy
+	IntegrityCheckPaymentWithoutOrder  = "payment_without_order"      // payment whose order is missing or deleted
+	IntegrityCheckPointsLedgerMismatch = "points_balance_mismatch"    // customer balance differs from the sum of the ledger
+	IntegrityCheckImageMissingFile     = "product_image_missing_file" // product image whose file is gone from storage
+	IntegrityCheckCategoryDrift        = "product_category_drift"     // product category string differs from its category's name
+)
+
+// IntegrityIssue is one record found by an integrity check; it has no table.
+type IntegrityIssue struct {
+	RecordID   uuid.UUID `json:"record_id"`
+	PharmacyID uuid.UUID `json:"pharmacy_id"`
+	Detail     string    `json:"detail"`
+}
+
+// IntegrityCheckResult is the outcome of one check. Samples holds at most a page of the issues; Count is the total.
+type IntegrityCheckResult struct {
+	Code        string           `json:"code"`
+	Description string           `json:"description"`
+	Fixable     bool             `json:"fixable"` // a safe automatic fix exists
+	Fix         string           `json:"fix"`     // what the automatic fix does, or what to do by hand
+	Count       int              `json:"count"`
+	Fixed       int              `json:"fixed"`
+	Samples     []IntegrityIssue `json:"samples"`
+}
+
+// IntegrityReport lists every check for one pharmacy, or for all pharmacies when PharmacyID is nil.
+type IntegrityReport struct {
+	PharmacyID  *uuid.UUID              `json:"pharmacy_id,omitempty"`
+	GeneratedAt time.Time               `json:"generated_at"`
+	Applied     bool                    `json:"applied"` // fixes were applied
+	Issues      int                     `json:"issues"`  // issues left after fixes
+	Checks      []*IntegrityCheckResult `json:"checks"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// InventoryAlertPolicy holds a pharmacy's defaults for the inventory alert scan. A product's own
+// reorder_level overrides ReorderLevel.
+type InventoryAlertPolicy struct {
+	Enabled      bool `json:"enabled"`
+	ReorderLevel int  `json:"reorder_level"` // products at or below this stock are low
+	ExpiryDays   int  `json:"expiry_days"`   // batches expiring within this many days are reported
+}
+
+// DefaultInventoryAlertPolicy applies when a pharmacy has not configured inventory_alerts.
+func DefaultInventoryAlertPolicy() *InventoryAlertPolicy {
+	return &InventoryAlertPolicy{Enabled: true, ReorderLevel: 10, ExpiryDays: 30}
+}
+
+// Inventory alert kinds.
+const (
+	InventoryAlertLowStock = "low_stock"
+	InventoryAlertExpiring = "expiring"
+	InventoryAlertExpired  = "expired"
+)
+
+// InventoryAlert is an open alert seen by the scheduled scan: RefID is the product (low stock) or the batch
+// (expiring/expired). Managers are notified when a row is created; the row is deleted once a scan no longer
+// sees the problem, so a later recurrence alerts again.
+type InventoryAlert struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID  uuid.UUID `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	Kind        string    `gorm:"size:20;not null;uniqueIndex:idx_inventory_alert_ref" json:"kind"`
+	RefID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_inventory_alert_ref" json:"ref_id"`
+	FirstSeenAt time.Time `gorm:"not null" json:"first_seen_at"`
+	LastSeenAt  time.Time `gorm:"not null" json:"last_seen_at"`
+}
+
+func (InventoryAlert) TableName() string { return "inventory_alerts" }
+
+func (a *InventoryAlert) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// LowStockAlert is an active product at or below its reorder level.
+type LowStockAlert struct {
+	ProductID     uuid.UUID  `json:"product_id"`
+	Name          string     `json:"name"`
+	SKU           string     `json:"sku"`
+	StockQuantity int        `json:"stock_quantity"`
+	ReorderLevel  int        `json:"reorder_level"`
+	Since         *time.Time `json:"since,omitempty"` // first scan that saw it; nil until the scheduler has run
+}
+
+// ExpiryAlert is a batch with stock that expires within the alert window or has already expired.
+type ExpiryAlert struct {
+	BatchID      uuid.UUID  `json:"batch_id"`
+	ProductID    uuid.UUID  `json:"product_id"`
+	ProductName  string     `json:"product_name"`
+	BatchNumber  string     `json:"batch_number"`
+	Quantity     int        `json:"quantity"`
+	ExpiryDate   time.Time  `json:"expiry_date"`
+	DaysToExpiry int        `json:"days_to_expiry"` // negative once expired
+	Expired      bool       `json:"expired"`
+	Since        *time.Time `json:"since,omitempty"`
+}
+
+// InventoryAlertDigest is the current inventory alert state of one pharmacy.
+type InventoryAlertDigest struct {
+	GeneratedAt  time.Time        `json:"generated_at"`
+	ReorderLevel int              `json:"reorder_level"` // pharmacy default
+	ExpiryDays   int              `json:"expiry_days"`
+	LowStock     []*LowStockAlert `json:"low_stock"`
+	Expiring     []*ExpiryAlert   `json:"expiring"`
+}
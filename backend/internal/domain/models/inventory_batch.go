@@ -16,6 +16,7 @@ type InventoryBatch struct {
 	BatchNumber string     `gorm:"size:100;not null" json:"batch_number"`
 	Quantity   int         `gorm:"not null" json:"quantity"`
 	ExpiryDate *time.Time  `gorm:"index" json:"expiry_date,omitempty"` // nil = no expiry / unknown
+	BranchID   *uuid.UUID  `gorm:"type:uuid;index" json:"branch_id,omitempty"` // nil = pharmacy-wide (no branches yet)
 	CreatedAt  time.Time   `json:"created_at"`
 	UpdatedAt  time.Time   `json:"updated_at"`
 
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StockAdjustmentReason is why units were written off a batch outside of a sale.
+type StockAdjustmentReason string
+
+const (
+	StockAdjustmentDamaged    StockAdjustmentReason = "damaged"     // crushed or wet cartons, broken units
+	StockAdjustmentBrokenSeal StockAdjustmentReason = "broken_seal" // tampered or opened packs that cannot be sold
+	StockAdjustmentExpired    StockAdjustmentReason = "expired"
+	StockAdjustmentCount      StockAdjustmentReason = "count_correction" // stock count found fewer units
+	StockAdjustmentOther      StockAdjustmentReason = "other"
+)
+
+// ValidStockAdjustmentReason reports whether r is a known reason.
+func ValidStockAdjustmentReason(r StockAdjustmentReason) bool {
+	switch r {
+	case StockAdjustmentDamaged, StockAdjustmentBrokenSeal, StockAdjustmentExpired, StockAdjustmentCount, StockAdjustmentOther:
+		return true
+	}
+	return false
+}
+
+// StockAdjustment is one entry in a batch's stock ledger: units written off with a reason. Quantity is the
+// number of units removed and QuantityAfter the batch quantity left; Photos are the evidence taken for it.
+type StockAdjustment struct {
+	ID            uuid.UUID             `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID    uuid.UUID             `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	BatchID       uuid.UUID             `gorm:"type:uuid;not null;index" json:"batch_id"`
+	ProductID     uuid.UUID             `gorm:"type:uuid;not null;index" json:"product_id"`
+	Quantity      int                   `gorm:"not null" json:"quantity"`
+	QuantityAfter int                   `gorm:"not null" json:"quantity_after"`
+	Reason        StockAdjustmentReason `gorm:"size:30;not null" json:"reason"`
+	Note          string                `gorm:"size:500" json:"note"`
+	CreatedBy     uuid.UUID             `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt     time.Time             `json:"created_at"`
+
+	Photos []*InventoryPhoto `gorm:"foreignKey:AdjustmentID" json:"photos,omitempty"`
+}
+
+func (StockAdjustment) TableName() string { return "stock_adjustments" }
+
+func (a *StockAdjustment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// InventoryPhoto is a photo documenting a batch (damaged carton, broken seal, delivery condition). It can belong
+// to a write-off (AdjustmentID) and be attached as evidence to a supplier return (ClearanceActionID).
+type InventoryPhoto struct {
+	ID                uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	BatchID           uuid.UUID  `gorm:"type:uuid;not null;index" json:"batch_id"`
+	AdjustmentID      *uuid.UUID `gorm:"type:uuid;index" json:"adjustment_id,omitempty"`
+	ClearanceActionID *uuid.UUID `gorm:"type:uuid;index" json:"clearance_action_id,omitempty"`
+	Caption           string     `gorm:"size:255" json:"caption"`
+	URL               string     `gorm:"size:500;not null" json:"url"`
+	UploadedBy        uuid.UUID  `gorm:"type:uuid;not null" json:"uploaded_by"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+func (InventoryPhoto) TableName() string { return "inventory_photos" }
+
+func (p *InventoryPhoto) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package models
+
+// LocalizedText is one language variant of a user-facing title and body (a product's or category's name and
+// description).
+type LocalizedText struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// Translations maps a language code from SupportedLanguages to its variant.
+type Translations map[string]LocalizedText
+
+// ResolveLanguage returns the recipient's preferred language when supported, else the pharmacy default, else "en".
+func ResolveLanguage(preferred, pharmacyDefault string) string {
+	if _, ok := SupportedLanguages[preferred]; ok {
+		return preferred
+	}
+	if _, ok := SupportedLanguages[pharmacyDefault]; ok {
+		return pharmacyDefault
+	}
+	return "en"
+}
+
+// Pick returns the variant of the first language in langs that has one.
+func (t Translations) Pick(langs ...string) (text LocalizedText, picked string, ok bool) {
+	for _, l := range langs {
+		if v, found := t[l]; found && v.Title != "" {
+			return v, l, true
+		}
+	}
+	return LocalizedText{}, "", false
+}
+
+// ValidateTranslations checks that every key is a supported language and every variant has a title. It returns
+// a message for the first problem, or "".
+func ValidateTranslations(t Translations) string {
+	for lang, v := range t {
+		if _, ok := SupportedLanguages[lang]; !ok {
+			return "unsupported language " + lang
+		}
+		if v.Title == "" {
+			return "translation " + lang + " needs a title"
+		}
+	}
+	return ""
+}
+
+// Localize replaces Title/Body with the variant for lang when there is one. Title/Body otherwise stay in the
+// pharmacy's default language, which is the fallback.
+func (a *Announcement) Localize(lang string) {
+	if v, picked, ok := a.Translations.Pick(lang); ok {
+		a.Title, a.Body, a.Language = v.Title, v.Body, picked
+	}
+}
+
+// Localize replaces Name/Description with the variant for lang when there is one; an empty Body keeps the
+// default description.
+func (p *Product) Localize(lang string) {
+	if v, _, ok := p.Translations.Pick(lang); ok {
+		p.Name = v.Title
+		if v.Body != "" {
+			p.Description = v.Body
+		}
+	}
+	if p.CategoryDetail != nil {
+		p.CategoryDetail.Localize(lang)
+	}
+}
+
+// Localize replaces Name/Description with the variant for lang when there is one, parent included.
+func (c *Category) Localize(lang string) {
+	if v, _, ok := c.Translations.Pick(lang); ok {
+		c.Name = v.Title
+		if v.Body != "" {
+			c.Description = v.Body
+		}
+	}
+	if c.Parent != nil {
+		c.Parent.Localize(lang)
+	}
+}
@@ -8,18 +8,20 @@ import (
 )
 
 // Membership is a loyalty tier offered by a pharmacy. Name and details are
-// defined by the pharmacy (via API/UI); optional discount_percent applies at checkout.
+// defined by the pharmacy (via API/UI); optional discount_percent applies at checkout,
+// and benefits carries the tier's other perks.
 type Membership struct {
-	ID              uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
-	PharmacyID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
-	Name            string         `gorm:"size:100;not null" json:"name" binding:"required"`
-	Description     string         `gorm:"type:text" json:"description"`
-	DiscountPercent float64        `gorm:"default:0" json:"discount_percent" binding:"gte=0,lte=100"` // 0–100, e.g. 5 = 5% off
-	IsActive        bool           `gorm:"default:true" json:"is_active"`
-	SortOrder       int            `gorm:"default:0" json:"sort_order"`
-	CreatedAt       time.Time      `json:"created_at"`
-	UpdatedAt       time.Time      `json:"updated_at"`
-	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+	ID              uuid.UUID          `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID      uuid.UUID          `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	Name            string             `gorm:"size:100;not null" json:"name" binding:"required"`
+	Description     string             `gorm:"type:text" json:"description"`
+	DiscountPercent float64            `gorm:"default:0" json:"discount_percent" binding:"gte=0,lte=100"` // 0–100, e.g. 5 = 5% off
+	Benefits        MembershipBenefits `gorm:"type:jsonb;serializer:json" json:"benefits"`
+	IsActive        bool               `gorm:"default:true" json:"is_active"`
+	SortOrder       int                `gorm:"default:0" json:"sort_order"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt     `gorm:"index" json:"-"`
 
 	Pharmacy *Pharmacy `gorm:"foreignKey:PharmacyID" json:"pharmacy,omitempty"`
 }
@@ -32,3 +34,17 @@ func (m *Membership) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// MembershipBenefits are the perks of a tier beyond its discount, applied by the BenefitsEngine at checkout.
+type MembershipBenefits struct {
+	FreeDelivery                bool    `json:"free_delivery"`                   // waives the pharmacy delivery fee
+	FlashSaleEarlyAccessMinutes int     `json:"flash_sale_early_access_minutes"` // members may buy flash-sale items this long before the sale opens
+	PointsMultiplier            float64 `json:"points_multiplier"`               // purchase points are multiplied by this; 0 or 1 = standard
+	BirthdayBonusPoints         int     `json:"birthday_bonus_points"`           // credited with the first completed order in the birthday month
+	BirthdayDiscountPercent     float64 `json:"birthday_discount_percent"`       // off the first order in the birthday month
+}
+
+// HasBirthdayGift reports whether the tier gives anything in the customer's birthday month.
+func (b MembershipBenefits) HasBirthdayGift() bool {
+	return b.BirthdayBonusPoints > 0 || b.BirthdayDiscountPercent > 0
+}
@@ -13,7 +13,8 @@ type Notification struct {
 	UserID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
 	Title      string     `gorm:"size:255;not null" json:"title"`
 	Message    string     `gorm:"type:text" json:"message"`
-	Type       string     `gorm:"size:64;default:info" json:"type"` // e.g. order, payment, info
+	Type       string     `gorm:"size:64;default:info" json:"type"`  // e.g. order, payment, info
+	Language   string     `gorm:"size:16" json:"language,omitempty"` // variant delivered; empty for single-language notifications
 	ReadAt     *time.Time `json:"read_at,omitempty"`
 	CreatedAt  time.Time  `json:"created_at"`
 
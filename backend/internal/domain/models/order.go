@@ -26,21 +26,40 @@ type Order struct {
 	CustomerPhone   string         `gorm:"size:50" json:"customer_phone"`
 	CustomerEmail   string         `gorm:"size:255" json:"customer_email"`
 	CustomerID      *uuid.UUID     `gorm:"type:uuid;index" json:"customer_id,omitempty"`
+	BranchID        *uuid.UUID     `gorm:"type:uuid;index" json:"branch_id,omitempty"` // branch whose stock fulfils the order (creator's branch)
 	ReferralCodeUsed string        `gorm:"size:50" json:"referral_code_used,omitempty"`
 	PointsRedeemed  int            `gorm:"default:0" json:"points_redeemed"`
 	Status          OrderStatus    `gorm:"size:50;default:pending;index" json:"status"`
 	SubTotal        float64        `gorm:"type:decimal(12,2);not null" json:"sub_total"`
 	TaxAmount       float64        `gorm:"type:decimal(12,2);default:0" json:"tax_amount"`
+	TaxInclusive    bool           `gorm:"default:false" json:"tax_inclusive"` // snapshot of PharmacyConfig.PricesIncludeTax: tax_amount is already inside sub_total
 	DiscountAmount  float64        `gorm:"type:decimal(12,2);default:0" json:"discount_amount"`
+	DeliveryFee     float64        `gorm:"type:decimal(12,2);default:0" json:"delivery_fee"` // waived for tiers with free delivery
+	// PointsMultiplier and BirthdayBonusPoints are the membership benefits applied when the order completes.
+	PointsMultiplier    float64 `gorm:"default:1" json:"points_multiplier,omitempty"`
+	BirthdayBonusPoints int     `gorm:"default:0" json:"birthday_bonus_points,omitempty"`
 	PromoCodeID     *uuid.UUID     `gorm:"type:uuid;index" json:"promo_code_id,omitempty"`
-	TotalAmount     float64        `gorm:"type:decimal(12,2);not null" json:"total_amount"`
+	TotalAmount     float64        `gorm:"type:decimal(12,2);not null" json:"total_amount"` // amount due, after gift card and store credit
+	// GiftCardAmount and StoreCreditAmount are the parts of the total paid with stored value.
+	GiftCardID        *uuid.UUID `gorm:"type:uuid;index" json:"gift_card_id,omitempty"`
+	GiftCardAmount    float64    `gorm:"type:decimal(12,2);default:0" json:"gift_card_amount,omitempty"`
+	StoreCreditAmount float64    `gorm:"type:decimal(12,2);default:0" json:"store_credit_amount,omitempty"`
 	Currency        string         `gorm:"size:10;default:NPR" json:"currency"`
 	Notes             string         `gorm:"type:text" json:"notes"`
 	DeliveryAddress   string         `gorm:"type:text" json:"delivery_address,omitempty"` // snapshot of selected user address at order time
+	CustomFields      CustomFieldValues `gorm:"type:jsonb" json:"custom_fields,omitempty"` // values of the pharmacy's order fields (PharmacyConfig.OrderFields)
+	ConversationID    *uuid.UUID     `gorm:"type:uuid;index" json:"conversation_id,omitempty"` // chat conversation the order was created from
 	CreatedBy         uuid.UUID      `gorm:"type:uuid;index" json:"created_by"`
 	CreatedAt        time.Time      `json:"created_at"`
 	UpdatedAt        time.Time      `json:"updated_at"`
 	CompletedAt      *time.Time     `json:"completed_at,omitempty"` // set when status becomes completed (for 7-day review / 3-day return windows)
+	// ETA stamped after the order is placed and refreshed while it is open when order volume spikes.
+	EstimatedReadyAt    *time.Time `json:"estimated_ready_at,omitempty"`
+	EstimatedDeliveryAt *time.Time `json:"estimated_delivery_at,omitempty"` // delivery orders only
+	// Interaction and duplicate-therapy warnings found at creation (pharmacies); accepting needs an acknowledgement.
+	ClinicalWarnings       []ClinicalWarning `gorm:"type:jsonb;serializer:json" json:"clinical_warnings,omitempty"`
+	WarningsAcknowledgedBy *uuid.UUID        `gorm:"type:uuid" json:"warnings_acknowledged_by,omitempty"`
+	WarningsAcknowledgedAt *time.Time        `json:"warnings_acknowledged_at,omitempty"`
 	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
 
 	Pharmacy   *Pharmacy   `gorm:"foreignKey:PharmacyID" json:"pharmacy,omitempty"`
@@ -62,6 +81,13 @@ func (o *Order) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// OrderItemCountRow is the number of lines and units on one order.
+type OrderItemCountRow struct {
+	OrderID uuid.UUID `json:"order_id"`
+	Lines   int       `json:"lines"`
+	Units   int       `json:"units"`
+}
+
 type OrderItem struct {
 	ID         uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
 	OrderID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"order_id"`
@@ -69,10 +95,17 @@ type OrderItem struct {
 	Quantity   int            `gorm:"not null" json:"quantity"`
 	UnitPrice  float64        `gorm:"type:decimal(12,2);not null" json:"unit_price"`
 	TotalPrice float64        `gorm:"type:decimal(12,2);not null" json:"total_price"`
-	CreatedAt  time.Time      `json:"created_at"`
+	TaxClass   string         `gorm:"size:30" json:"tax_class,omitempty"`          // snapshot of product tax class at order time
+	TaxRate    float64        `gorm:"type:decimal(5,2);default:0" json:"tax_rate"` // percent applied
+	TaxAmount  float64        `gorm:"type:decimal(12,2);default:0" json:"tax_amount"`
+	// PromoDiscount is this line's share of the order's promo code discount; 0 when the code did not cover it.
+	PromoDiscount float64   `gorm:"type:decimal(12,2);default:0" json:"promo_discount,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt  time.Time      `json:"updated_at"`
 
-	Product *Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	Product *Product         `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	Batches []OrderItemBatch `gorm:"foreignKey:OrderItemID" json:"batches,omitempty"` // FEFO draw, empty for products without batches
+	Serials []ProductSerial  `gorm:"foreignKey:OrderItemID" json:"serials,omitempty"` // units sold on this line, serial-tracked products only
 }
 
 func (OrderItem) TableName() string { return "order_items" }
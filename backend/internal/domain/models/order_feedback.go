@@ -7,6 +7,26 @@ import (
 	"gorm.io/gorm"
 )
 
+// Feedback follow-up status. Ratings at or below LowFeedbackRating start as pending and alert managers.
+const (
+	FeedbackFollowUpNone      = "none"
+	FeedbackFollowUpPending   = "pending"
+	FeedbackFollowUpContacted = "contacted"
+	FeedbackFollowUpResolved  = "resolved"
+)
+
+// LowFeedbackRating is the highest rating that counts as a complaint.
+const LowFeedbackRating = 2
+
+// IsFeedbackFollowUpStatus reports whether s is a known follow-up status.
+func IsFeedbackFollowUpStatus(s string) bool {
+	switch s {
+	case FeedbackFollowUpNone, FeedbackFollowUpPending, FeedbackFollowUpContacted, FeedbackFollowUpResolved:
+		return true
+	}
+	return false
+}
+
 // OrderFeedback is a customer's feedback (rating + optional comment) for a completed order.
 // One feedback per user per order; only the order creator (created_by) may submit.
 type OrderFeedback struct {
@@ -19,6 +39,12 @@ type OrderFeedback struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
+	// Follow-up by staff after contacting the customer.
+	FollowUpStatus string     `gorm:"size:20;not null;default:none;index" json:"follow_up_status"`
+	FollowUpNote   string     `gorm:"type:text" json:"follow_up_note,omitempty"`
+	FollowUpBy     *uuid.UUID `gorm:"type:uuid" json:"follow_up_by,omitempty"`
+	FollowUpAt     *time.Time `json:"follow_up_at,omitempty"`
+
 	Order *Order `gorm:"foreignKey:OrderID" json:"order,omitempty"`
 	User  *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
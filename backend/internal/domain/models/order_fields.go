@@ -0,0 +1,20 @@
+package models
+
+// OrderFieldDefinition is one tenant-defined extra order field (doctor name, hospital, token number), kept in
+// PharmacyConfig.OrderFields. Fields that are not customer-visible are filled by staff only.
+type OrderFieldDefinition struct {
+	Key             string   `json:"key"` // stored key on the order, lower_snake_case
+	Label           string   `json:"label"`
+	Type            string   `json:"type"` // CustomField* type
+	Required        bool     `json:"required,omitempty"`
+	Options         []string `json:"options,omitempty"` // allowed values of a select field
+	CustomerVisible bool     `json:"customer_visible,omitempty"`
+	ShowOnInvoice   bool     `json:"show_on_invoice,omitempty"`
+}
+
+// OrderFieldValue is a labelled custom field value as printed on an invoice.
+type OrderFieldValue struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrderItemBatch records how many units of an order item were drawn from which inventory batch, so a batch
+// recall can find the orders that received it. Batch number and expiry are copied at sale time.
+type OrderItemBatch struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	OrderItemID uuid.UUID  `gorm:"type:uuid;not null;index" json:"order_item_id"`
+	BatchID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"batch_id"`
+	ProductID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"product_id"`
+	BatchNumber string     `gorm:"size:100;not null" json:"batch_number"`
+	ExpiryDate  *time.Time `json:"expiry_date,omitempty"`
+	Quantity    int        `gorm:"not null" json:"quantity"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	OrderItem *OrderItem `gorm:"foreignKey:OrderItemID" json:"order_item,omitempty"`
+}
+
+func (OrderItemBatch) TableName() string { return "order_item_batches" }
+
+func (b *OrderItemBatch) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
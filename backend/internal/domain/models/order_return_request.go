@@ -18,6 +18,33 @@ const (
 	ReturnRequestStatusRejected ReturnRequestStatus = "rejected"
 )
 
+// Defect reasons for return requests. Customers may pick one when submitting; staff can correct it on review.
+// An empty reason means the request has not been classified yet.
+const (
+	ReturnReasonDamagedPackaging = "damaged_packaging"
+	ReturnReasonBrokenSeal       = "broken_seal"
+	ReturnReasonExpired          = "expired"
+	ReturnReasonShortExpiry      = "short_expiry"
+	ReturnReasonWrongItem        = "wrong_item"
+	ReturnReasonMissingItem      = "missing_item"
+	ReturnReasonQualityDefect    = "quality_defect"
+	ReturnReasonAdverseReaction  = "adverse_reaction"
+	ReturnReasonOther            = "other"
+)
+
+// ReturnReasonRegistry lists every defect reason with its display label. Requests may only carry registered reasons.
+var ReturnReasonRegistry = map[string]string{
+	ReturnReasonDamagedPackaging: "Damaged packaging",
+	ReturnReasonBrokenSeal:       "Broken or missing seal",
+	ReturnReasonExpired:          "Expired on arrival",
+	ReturnReasonShortExpiry:      "Expiry too close",
+	ReturnReasonWrongItem:        "Wrong item or strength",
+	ReturnReasonMissingItem:      "Item missing from order",
+	ReturnReasonQualityDefect:    "Quality defect (discoloured, crumbled, leaking)",
+	ReturnReasonAdverseReaction:  "Adverse reaction",
+	ReturnReasonOther:            "Other",
+}
+
 // StringSlice is a slice of strings stored as JSON in the DB.
 type StringSlice []string
 
@@ -51,6 +78,13 @@ type OrderReturnRequest struct {
 	PhotoURLs   StringSlice         `gorm:"type:text" json:"photo_urls"` // JSON array of URLs
 	Notes       string              `gorm:"type:text" json:"notes"`
 	Description string              `gorm:"type:text" json:"description"`
+	ReasonCode  string              `gorm:"size:40;index" json:"reason_code"`          // key into ReturnReasonRegistry; empty = unclassified
+	ProductIDs  StringSlice         `gorm:"type:text" json:"product_ids,omitempty"`    // affected products; empty = the whole order
+	StaffNote   string              `gorm:"type:text" json:"staff_note,omitempty"`
+	ReviewedBy  *uuid.UUID          `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time          `json:"reviewed_at,omitempty"`
+	// RefundEntryID is the store credit (wallet) entry when the approval refunded straight to the wallet.
+	RefundEntryID *uuid.UUID `gorm:"type:uuid" json:"refund_entry_id,omitempty"`
 	CreatedAt   time.Time           `json:"created_at"`
 	UpdatedAt   time.Time           `json:"updated_at"`
 
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrderStatusHistory records one order status change. FromStatus is empty for the entry written when the order is
+// placed. ActorName is a snapshot so the trail reads the same after the user is renamed or removed.
+type OrderStatusHistory struct {
+	ID         uuid.UUID   `gorm:"type:uuid;primaryKey" json:"id"`
+	OrderID    uuid.UUID   `gorm:"type:uuid;not null;index" json:"order_id"`
+	FromStatus OrderStatus `gorm:"size:50" json:"from_status,omitempty"`
+	ToStatus   OrderStatus `gorm:"size:50;not null" json:"to_status"`
+	ActorID    *uuid.UUID  `gorm:"type:uuid" json:"actor_id,omitempty"`
+	ActorName  string      `gorm:"size:255" json:"actor_name,omitempty"`
+	Note       string      `gorm:"type:text" json:"note,omitempty"`
+	CreatedAt  time.Time   `gorm:"index" json:"created_at"`
+}
+
+func (OrderStatusHistory) TableName() string { return "order_status_histories" }
+
+func (h *OrderStatusHistory) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
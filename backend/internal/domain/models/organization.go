@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Organization groups the pharmacy tenants of one owner (a chain) for consolidated reporting. A pharmacy joins
+// with the organization's join code; linking also sets its GroupCode to the organization code, so stock
+// transfers follow the organization.
+type Organization struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	Name       string         `gorm:"size:255;not null" json:"name"`
+	Code       string         `gorm:"size:64;not null;uniqueIndex" json:"code"`
+	JoinCode   string         `gorm:"size:32;not null;uniqueIndex" json:"join_code,omitempty"` // shown to owners only
+	CreatedBy  uuid.UUID      `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+	Pharmacies []Pharmacy     `gorm:"foreignKey:OrganizationID" json:"pharmacies,omitempty"`
+}
+
+func (Organization) TableName() string { return "organizations" }
+
+func (o *Organization) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
+
+// OrganizationRole is a member's access to an organization: owners manage tenants and members, viewers see
+// the roll-up reports.
+type OrganizationRole string
+
+const (
+	OrganizationOwner  OrganizationRole = "owner"
+	OrganizationViewer OrganizationRole = "viewer"
+)
+
+// OrganizationMember gives a user group-level access: roll-up reports across every tenant of the organization,
+// whichever pharmacy the user signs in to. Members are users of one of the organization's tenants.
+type OrganizationMember struct {
+	ID             uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	OrganizationID uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_org_member" json:"organization_id"`
+	UserID         uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_org_member;index" json:"user_id"`
+	Role           OrganizationRole `gorm:"size:20;not null;default:viewer" json:"role"`
+	AddedBy        uuid.UUID        `gorm:"type:uuid;not null" json:"added_by"`
+	CreatedAt      time.Time        `json:"created_at"`
+	User           *User            `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (OrganizationMember) TableName() string { return "organization_members" }
+
+func (m *OrganizationMember) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+// OrgTenantSalesRow is one tenant's non-cancelled sales in a roll-up range.
+type OrgTenantSalesRow struct {
+	PharmacyID   uuid.UUID `json:"pharmacy_id"`
+	PharmacyName string    `json:"pharmacy_name"`
+	OrdersCount  int64     `json:"orders_count"`
+	Revenue      float64   `json:"revenue"`
+	Share        float64   `json:"share"` // percent of the organization's revenue
+}
+
+// OrgTopProductRow is a product ranked across tenants. Products of different tenants are matched by SKU, or
+// by name when the SKU is empty.
+type OrgTopProductRow struct {
+	SKU          string  `json:"sku"`
+	Name         string  `json:"name"`
+	QuantitySold int64   `json:"quantity_sold"`
+	Revenue      float64 `json:"revenue"`
+	OrdersCount  int64   `json:"orders_count"`
+	Tenants      int64   `json:"tenants"` // tenants that sold it
+}
+
+// OrgStockRow is a product's stock at one tenant branch; BranchID is nil for stock not assigned to a branch.
+type OrgStockRow struct {
+	SKU          string     `json:"sku"`
+	Name         string     `json:"name"`
+	PharmacyID   uuid.UUID  `json:"pharmacy_id"`
+	PharmacyName string     `json:"pharmacy_name"`
+	BranchID     *uuid.UUID `json:"branch_id,omitempty"`
+	BranchName   string     `json:"branch_name,omitempty"`
+	Quantity     int        `json:"quantity"`
+	Value        float64    `json:"value"` // quantity at the tenant's unit price
+}
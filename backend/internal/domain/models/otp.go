@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OTP purposes.
+const (
+	OTPPurposePhoneVerification = "phone_verification"
+)
+
+// OTPCode is a one-time code sent by SMS. Only a hash of the code is stored.
+type OTPCode struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID uuid.UUID  `gorm:"type:uuid;not null;index:idx_otp_lookup" json:"pharmacy_id"`
+	Phone      string     `gorm:"size:50;not null;index:idx_otp_lookup" json:"phone"`
+	Purpose    string     `gorm:"size:50;not null;index:idx_otp_lookup" json:"purpose"`
+	CodeHash   string     `gorm:"size:128;not null" json:"-"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	Attempts   int        `gorm:"default:0" json:"attempts"`
+	ConsumedAt *time.Time `json:"consumed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (OTPCode) TableName() string { return "otp_codes" }
+
+func (o *OTPCode) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
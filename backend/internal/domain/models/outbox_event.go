@@ -0,0 +1,115 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Domain event types written to the outbox.
+const (
+	EventOrderCreated     = "order.created"
+	EventOrderCompleted   = "order.completed"
+	EventStockLow         = "stock.low"
+	EventPaymentCompleted = "payment.completed"
+	EventReviewCreated    = "review.created"
+)
+
+// Outbox event statuses. Dispatched events are kept for a few days (see the event bus purge) and then deleted.
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusRunning = "running"
+	OutboxStatusDone    = "done"
+	OutboxStatusDead    = "dead" // a subscriber kept failing after the maximum attempts
+)
+
+// OutboxEvent is a domain event stored in the same transaction as the change that raised it, so an event is
+// recorded if and only if the change was committed. The event bus dispatches it to subscribers afterwards;
+// Handled lists the subscribers that already succeeded so a retry only re-runs the ones that failed.
+type OutboxEvent struct {
+	ID            uuid.UUID   `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID    uuid.UUID   `gorm:"type:uuid;index" json:"pharmacy_id"`
+	Type          string      `gorm:"size:50;not null;index" json:"type"`
+	AggregateID   uuid.UUID   `gorm:"type:uuid;index" json:"aggregate_id"` // order, payment, review or product the event is about
+	Payload       string      `gorm:"type:text;not null" json:"payload"`
+	Status        string      `gorm:"size:20;not null;default:pending;index:idx_outbox_due,priority:1" json:"status"`
+	Attempts      int         `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time   `gorm:"not null;index:idx_outbox_due,priority:2" json:"next_attempt_at"`
+	LockedUntil   *time.Time  `json:"-"`
+	Handled       StringSlice `gorm:"type:jsonb" json:"handled"`
+	LastError     string      `gorm:"size:1000" json:"last_error,omitempty"`
+	DispatchedAt  *time.Time  `gorm:"index" json:"dispatched_at,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
+
+func (OutboxEvent) TableName() string { return "outbox_events" }
+
+func (e *OutboxEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	if e.Status == "" {
+		e.Status = OutboxStatusPending
+	}
+	if e.NextAttemptAt.IsZero() {
+		e.NextAttemptAt = time.Now()
+	}
+	return nil
+}
+
+// NewOutboxEvent builds a pending event with data encoded as the JSON payload.
+func NewOutboxEvent(eventType string, pharmacyID, aggregateID uuid.UUID, data interface{}) *OutboxEvent {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		payload = []byte("{}")
+	}
+	return &OutboxEvent{
+		PharmacyID:  pharmacyID,
+		Type:        eventType,
+		AggregateID: aggregateID,
+		Payload:     string(payload),
+		Status:      OutboxStatusPending,
+	}
+}
+
+// Decode unmarshals the payload into v.
+func (e *OutboxEvent) Decode(v interface{}) error {
+	return json.Unmarshal([]byte(e.Payload), v)
+}
+
+// OrderEventData is the payload of order.created and order.completed.
+type OrderEventData struct {
+	OrderID     uuid.UUID   `json:"order_id"`
+	CustomerID  *uuid.UUID  `json:"customer_id,omitempty"`
+	CreatedBy   uuid.UUID   `json:"created_by"`
+	TotalAmount float64     `json:"total_amount"`
+	Status      OrderStatus `json:"status"`
+}
+
+// PaymentEventData is the payload of payment.completed.
+type PaymentEventData struct {
+	PaymentID uuid.UUID     `json:"payment_id"`
+	OrderID   uuid.UUID     `json:"order_id"`
+	Amount    float64       `json:"amount"`
+	Method    PaymentMethod `json:"method"`
+}
+
+// ReviewEventData is the payload of review.created.
+type ReviewEventData struct {
+	ReviewID  uuid.UUID `json:"review_id"`
+	ProductID uuid.UUID `json:"product_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Rating    int       `json:"rating"`
+}
+
+// StockLowEventData is the payload of stock.low, raised when a product first drops to its reorder level.
+type StockLowEventData struct {
+	ProductID     uuid.UUID `json:"product_id"`
+	Name          string    `json:"name"`
+	SKU           string    `json:"sku"`
+	StockQuantity int       `json:"stock_quantity"`
+	ReorderLevel  int       `json:"reorder_level"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// LicensePolicy holds a pharmacy's pharmacist license settings (PharmacyConfig.LicensePolicy).
+type LicensePolicy struct {
+	ReminderDays   []int `json:"reminder_days"`    // days before expiry the license holder and admins are reminded, e.g. [30, 7, 1]
+	BlockExpiredRx bool  `json:"block_expired_rx"` // members with an expired license cannot accept or complete orders with prescription items
+}
+
+// DefaultLicensePolicy applies when a pharmacy has not configured license_policy.
+func DefaultLicensePolicy() *LicensePolicy {
+	return &LicensePolicy{ReminderDays: []int{30, 7, 1}, BlockExpiredRx: true}
+}
+
+// License statuses reported by the compliance overview.
+const (
+	LicenseStatusValid    = "valid"
+	LicenseStatusExpiring = "expiring" // within the policy's longest reminder window
+	LicenseStatusExpired  = "expired"
+	LicenseStatusMissing  = "missing" // pharmacist without a license number or expiry date
+)
+
+// LicenseDaysLeft is the number of whole days until the license expiry date (0 on the expiry date itself,
+// negative once expired), or nil when no expiry is recorded. A license is valid through its expiry date.
+func (u *User) LicenseDaysLeft(now time.Time) *int {
+	if u.LicenseExpiresAt == nil {
+		return nil
+	}
+	y, m, d := u.LicenseExpiresAt.UTC().Date()
+	ny, nm, nd := now.UTC().Date()
+	days := int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Sub(time.Date(ny, nm, nd, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+	return &days
+}
+
+// LicenseExpired reports whether the user has a recorded license expiry that has passed.
+func (u *User) LicenseExpired(now time.Time) bool {
+	d := u.LicenseDaysLeft(now)
+	return d != nil && *d < 0
+}
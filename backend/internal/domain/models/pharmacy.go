@@ -15,6 +15,14 @@ const (
 	BusinessTypeOther    = "other"
 )
 
+// Pharmacy statuses. Pharmacies created by an admin start active; self-registered ones wait for a platform admin.
+const (
+	PharmacyStatusPending   = "pending" // signed up, awaiting approval
+	PharmacyStatusActive    = "active"
+	PharmacyStatusRejected  = "rejected"  // signup turned down; ReviewNote says why
+	PharmacyStatusSuspended = "suspended" // blocked by a platform admin: no logins, no public catalog
+)
+
 type Pharmacy struct {
 	ID            uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
 	Name          string         `gorm:"size:255;not null" json:"name"`
@@ -22,10 +30,20 @@ type Pharmacy struct {
 	TenantCode    string         `gorm:"size:64;uniqueIndex" json:"tenant_code"`    // Unique tenant identifier (e.g. "careplus")
 	HostnameSlug  string         `gorm:"size:128;uniqueIndex" json:"hostname_slug"` // Hostname or short name for URL
 	BusinessType  string         `gorm:"size:32;default:pharmacy" json:"business_type"` // pharmacy, retail, clinic, other
+	GroupCode     string         `gorm:"size:64;index" json:"group_code,omitempty"`     // pharmacies run by one owner share it; stock transfers stay within a group
+	OrganizationID *uuid.UUID    `gorm:"type:uuid;index" json:"organization_id,omitempty"` // chain the tenant belongs to, for roll-up reports
 	Address       string         `gorm:"type:text" json:"address"`
 	Phone         string         `gorm:"size:50" json:"phone"`
 	Email         string         `gorm:"size:255" json:"email"`
 	IsActive      bool           `gorm:"default:true" json:"is_active"`
+	Status        string         `gorm:"size:20;not null;default:active;index" json:"status"`
+	// LicenseDocumentURL is the private reference to the license sent with a signup; handed out only as a signed link.
+	LicenseDocumentURL string     `gorm:"size:512" json:"-"`
+	ReviewNote         string     `gorm:"type:text" json:"review_note,omitempty"`
+	ReviewedAt         *time.Time `json:"reviewed_at,omitempty"`
+	ReviewedBy         *uuid.UUID `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	SuspendedAt        *time.Time `json:"suspended_at,omitempty"`
+	SuspensionReason   string     `gorm:"type:text" json:"suspension_reason,omitempty"`
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
@@ -37,5 +55,13 @@ func (p *Pharmacy) BeforeCreate(tx *gorm.DB) error {
 	if p.ID == uuid.Nil {
 		p.ID = uuid.New()
 	}
+	if p.Status == "" {
+		p.Status = PharmacyStatusActive
+	}
 	return nil
 }
+
+// Open reports whether the pharmacy's staff may sign in and its storefront is public: approved and not suspended.
+func (p *Pharmacy) Open() bool {
+	return p.Status != PharmacyStatusPending && p.Status != PharmacyStatusRejected && p.Status != PharmacyStatusSuspended
+}
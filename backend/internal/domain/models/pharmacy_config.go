@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,6 +20,49 @@ func DefaultFeatureFlags() FeatureFlagsMap {
 	}
 }
 
+// SMS feature flags. They cost money per message, so they are off unless a tenant enables them
+// (they are not part of DefaultFeatureFlags).
+const (
+	FeatureSMSOrderUpdates = "sms_order_updates" // SMS on order confirmed, ready and completed
+	FeatureSMSOTP          = "sms_otp"           // one-time codes by SMS
+	FeatureSMSCampaigns    = "sms_campaigns"     // bulk SMS campaigns to customer segments
+)
+
+// FeatureFlagRegistry lists every feature flag key a config may set, with a short description.
+// Config updates with keys outside the registry are rejected.
+var FeatureFlagRegistry = map[string]string{
+	"products":             "Products / catalog",
+	"orders":               "Orders",
+	"chat":                 "Chat",
+	"promos":               "Promos and offers",
+	"referral":             "Referral and points",
+	"memberships":          "Memberships",
+	"billing":              "Billing (POS)",
+	"announcements":        "Announcements",
+	"inventory":            "Inventory",
+	"statements":           "Statements",
+	"categories":           "Categories",
+	"reviews":              "Product reviews",
+	FeatureSMSOrderUpdates: "SMS order updates",
+	FeatureSMSOTP:          "SMS one-time codes",
+	FeatureSMSCampaigns:    "SMS campaigns",
+}
+
+// SupportedLanguages are the UI languages a pharmacy may choose as default_language.
+var SupportedLanguages = map[string]string{
+	"en": "English",
+	"ne": "Nepali",
+}
+
+// BusinessHours is the opening time of one weekday ("mon".."sun"). Times are "HH:MM" in the pharmacy's
+// local time; Close may be "24:00". Closed days ignore Open/Close.
+type BusinessHours struct {
+	Day    string `json:"day"`
+	Open   string `json:"open,omitempty"`
+	Close  string `json:"close,omitempty"`
+	Closed bool   `json:"closed,omitempty"`
+}
+
 // PharmacyConfig holds site/display and company controls per tenant (name, logo, website on/off, features).
 // One row per pharmacy/tenant.
 type PharmacyConfig struct {
@@ -33,13 +77,32 @@ type PharmacyConfig struct {
 	ContactEmail         string         `gorm:"size:255" json:"contact_email"`
 	PrimaryColor         string         `gorm:"size:20" json:"primary_color"`
 	DefaultLanguage      string         `gorm:"size:16;default:en" json:"default_language"`
+	BaseCurrency         string         `gorm:"size:3;default:NPR" json:"base_currency"`             // prices, orders, invoices and reports; see Currencies
+	DisplayCurrency      string         `gorm:"size:3" json:"display_currency,omitempty"`  // storefront shows prices converted to it too, at the stored exchange rate; empty = base only
 	WebsiteEnabled       bool           `gorm:"default:true" json:"website_enabled"`       // Enable/disable public website for this company
+	SiteHostname         string         `gorm:"size:255" json:"site_hostname,omitempty"` // storefront host (e.g. shop.example.com) used in sitemap links; empty = APP_PUBLIC_URL
 	FeatureFlags         FeatureFlagsMap `gorm:"type:jsonb;serializer:json" json:"feature_flags,omitempty"` // Per-tenant feature toggles (products, orders, chat, etc.)
 	LicenseNo            string         `gorm:"size:100" json:"license_no"`
 	VerifiedAt           *time.Time     `gorm:"index" json:"verified_at,omitempty"`
 	EstablishedYear      int            `gorm:"default:0" json:"established_year"`
 	ReturnRefundPolicy   string         `gorm:"type:text" json:"return_refund_policy,omitempty"`
 	ChatEditWindowMinutes int           `gorm:"default:10" json:"chat_edit_window_minutes"`
+	TaxEnabled           bool           `gorm:"default:false" json:"tax_enabled"`                      // When false, orders carry no tax
+	TaxRates             TaxRatesMap    `gorm:"type:jsonb;serializer:json" json:"tax_rates,omitempty"` // VAT percent per tax class; "standard" applies to unclassified products
+	PricesIncludeTax     bool           `gorm:"default:false" json:"prices_include_tax"`               // true: unit prices are VAT-inclusive and tax is extracted; false: tax is added on top
+	TaxRegistrationNo    string         `gorm:"size:100" json:"tax_registration_no,omitempty"`         // VAT/PAN number printed on invoices
+	ExpiryDiscount       *ExpiryDiscountPolicy `gorm:"type:jsonb;serializer:json" json:"expiry_discount,omitempty"` // automatic markdowns for near-expiry stock
+	BusinessHours        []BusinessHours `gorm:"type:jsonb;serializer:json" json:"business_hours,omitempty"` // weekly opening hours
+	ReturnRateAlert      *ReturnRateAlertPolicy `gorm:"type:jsonb;serializer:json" json:"return_rate_alert,omitempty"` // flags products with high return rates; nil = defaults
+	InventoryAlerts      *InventoryAlertPolicy `gorm:"type:jsonb;serializer:json" json:"inventory_alerts,omitempty"` // low-stock and expiry alert defaults; nil = defaults
+	DeliveryFee          float64        `gorm:"type:decimal(12,2);default:0" json:"delivery_fee"` // flat fee on orders with a delivery address; 0 = free
+	DeliveryOTPRequired  bool           `gorm:"default:false" json:"delivery_otp_required"` // couriers must enter the customer's one-time code to complete a delivery
+	DeliveryAreas        []string       `gorm:"type:jsonb;serializer:json" json:"delivery_areas,omitempty"` // cities or areas delivered to; empty = anywhere
+	OrderFields          []OrderFieldDefinition `gorm:"type:jsonb;serializer:json" json:"order_fields,omitempty"` // extra typed fields captured on orders
+	ProductAttributes    []ProductAttributeDefinition `gorm:"type:jsonb;serializer:json" json:"product_attributes,omitempty"` // typed custom attributes on products
+	ActivityLogRetentionDays int         `gorm:"default:0" json:"activity_log_retention_days"` // activity log entries older than this are purged; 0 = 365 days
+	LicensePolicy        *LicensePolicy `gorm:"type:jsonb;serializer:json" json:"license_policy,omitempty"` // pharmacist license reminders and expired-license blocking; nil = defaults
+	SecurityPolicy       *SecurityPolicy `gorm:"type:jsonb;serializer:json" json:"security_policy,omitempty"` // password rules, session lifetime and two-factor roles; nil = defaults
 	CreatedAt            time.Time      `json:"created_at"`
 	UpdatedAt            time.Time      `json:"updated_at"`
 	DeletedAt            gorm.DeletedAt `gorm:"index" json:"-"`
@@ -55,3 +118,18 @@ func (c *PharmacyConfig) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// DeliversTo reports whether the address is in one of the delivery areas (case-insensitive match anywhere in
+// the address). Any address qualifies when no areas are configured.
+func (c *PharmacyConfig) DeliversTo(address string) bool {
+	if c == nil || len(c.DeliveryAreas) == 0 {
+		return true
+	}
+	address = strings.ToLower(address)
+	for _, area := range c.DeliveryAreas {
+		if a := strings.ToLower(strings.TrimSpace(area)); a != "" && strings.Contains(address, a) {
+			return true
+		}
+	}
+	return false
+}
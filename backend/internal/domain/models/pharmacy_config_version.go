@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Config history actions.
+const (
+	ConfigVersionBaseline = "baseline" // state before the first recorded change
+	ConfigVersionUpdate   = "update"
+	ConfigVersionRollback = "rollback"
+)
+
+// PharmacyConfigVersion is a snapshot of a pharmacy's config after a change. Versions count up per pharmacy
+// and are never edited, so any of them can be restored.
+type PharmacyConfigVersion struct {
+	ID             uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID     uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_config_version" json:"pharmacy_id"`
+	Version        int            `gorm:"not null;uniqueIndex:idx_config_version" json:"version"`
+	Action         string         `gorm:"size:20;not null" json:"action"`
+	RolledBackFrom *int           `json:"rolled_back_from,omitempty"` // version restored by a rollback
+	Snapshot       PharmacyConfig `gorm:"type:jsonb;serializer:json" json:"snapshot"`
+	ChangedBy      *uuid.UUID     `gorm:"type:uuid" json:"changed_by,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+func (PharmacyConfigVersion) TableName() string { return "pharmacy_config_versions" }
+
+func (v *PharmacyConfigVersion) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package models
+
+import "github.com/google/uuid"
+
+// SignupCategories are the top-level categories an approved signup starts with; the pharmacy edits them freely.
+var SignupCategories = []string{
+	"Medicines",
+	"Vitamins & Supplements",
+	"Personal Care",
+	"Baby & Mother Care",
+	"Health Devices",
+	"First Aid",
+}
+
+// SignupPaymentGateways returns the payment gateways an approved signup starts with: cash on delivery works right
+// away; the wallets and QR stay inactive until the pharmacy enters its credentials or QR image.
+func SignupPaymentGateways(pharmacyID uuid.UUID) []*PaymentGateway {
+	return []*PaymentGateway{
+		{PharmacyID: pharmacyID, Code: GatewayCodeCOD, Name: "Cash on Delivery", IsActive: true, SortOrder: 0},
+		{PharmacyID: pharmacyID, Code: GatewayCodeEsewa, Name: "eSewa", IsActive: false, SortOrder: 1},
+		{PharmacyID: pharmacyID, Code: GatewayCodeKhalti, Name: "Khalti", IsActive: false, SortOrder: 2},
+		{PharmacyID: pharmacyID, Code: GatewayCodeQR, Name: "QR Payment", IsActive: false, SortOrder: 3},
+	}
+}
@@ -14,6 +14,7 @@ const (
 	PointsTransactionTypeEarnPurchase  PointsTransactionType = "earn_purchase"
 	PointsTransactionTypeEarnReferral PointsTransactionType = "earn_referral"
 	PointsTransactionTypeRedeem       PointsTransactionType = "redeem"
+	PointsTransactionTypeEarnBirthday PointsTransactionType = "earn_birthday"
 )
 
 // PointsTransaction records every credit/debit for audit.
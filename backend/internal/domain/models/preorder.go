@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PreorderStatus string
+
+const (
+	PreorderStatusPending   PreorderStatus = "pending"   // waiting for stock
+	PreorderStatusConverted PreorderStatus = "converted" // turned into a normal order (OrderID set)
+	PreorderStatusCancelled PreorderStatus = "cancelled"
+)
+
+// Preorder reserves a product that is out of stock or not yet released. The customer pays a deposit (or in full)
+// up front; when a linked purchase order is received the preorder is converted into a normal Order and the
+// amount already paid is recorded as a payment on it.
+type Preorder struct {
+	ID               uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID       uuid.UUID      `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	PreorderNumber   string         `gorm:"size:50;uniqueIndex;not null" json:"preorder_number"`
+	ProductID        uuid.UUID      `gorm:"type:uuid;not null;index" json:"product_id"`
+	Quantity         int            `gorm:"not null" json:"quantity"`
+	UnitPrice        float64        `gorm:"type:decimal(12,2);not null" json:"unit_price"` // price locked at preorder time
+	TotalAmount      float64        `gorm:"type:decimal(12,2);not null" json:"total_amount"`
+	DepositAmount    float64        `gorm:"type:decimal(12,2);default:0" json:"deposit_amount"` // amount due up front
+	AmountPaid       float64        `gorm:"type:decimal(12,2);default:0" json:"amount_paid"`
+	PaymentGatewayID *uuid.UUID     `gorm:"type:uuid" json:"payment_gateway_id,omitempty"`
+	PaymentMethod    PaymentMethod  `gorm:"size:50" json:"payment_method,omitempty"`
+	CustomerName     string         `gorm:"size:255" json:"customer_name"`
+	CustomerPhone    string         `gorm:"size:50" json:"customer_phone"`
+	CustomerEmail    string         `gorm:"size:255" json:"customer_email"`
+	Notes            string         `gorm:"type:text" json:"notes"`
+	Status           PreorderStatus `gorm:"size:20;default:pending;index" json:"status"`
+	PurchaseOrderID  *uuid.UUID     `gorm:"type:uuid;index" json:"purchase_order_id,omitempty"` // restock this preorder waits for
+	ExpectedAt       *time.Time     `json:"expected_at,omitempty"`                              // current ETA; customers are notified when it changes
+	OrderID          *uuid.UUID     `gorm:"type:uuid;index" json:"order_id,omitempty"`
+	ConvertedAt      *time.Time     `json:"converted_at,omitempty"`
+	CancelledAt      *time.Time     `json:"cancelled_at,omitempty"`
+	CreatedBy        uuid.UUID      `gorm:"type:uuid;index" json:"created_by"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Product *Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+func (Preorder) TableName() string { return "preorders" }
+
+func (p *Preorder) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	if p.PreorderNumber == "" {
+		p.PreorderNumber = "PRE-" + uuid.New().String()[:8]
+	}
+	return nil
+}
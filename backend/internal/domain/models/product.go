@@ -15,10 +15,14 @@ type Product struct {
 	SKU                string         `gorm:"size:100;uniqueIndex;not null" json:"sku"`
 	Category           string         `gorm:"size:100;index" json:"category"`       // denormalized name for filter/display; synced from Category when CategoryID set
 	CategoryID         *uuid.UUID     `gorm:"type:uuid;index" json:"category_id,omitempty"` // optional FK: product type = category (parent) + subcategory (child)
+	SupplierID         *uuid.UUID     `gorm:"type:uuid;index" json:"supplier_id,omitempty"` // mapped supplier for reorder requests
 	UnitPrice          float64        `gorm:"type:decimal(12,2);not null" json:"unit_price"`
 	DiscountPercent    float64        `gorm:"type:decimal(5,2);default:0" json:"discount_percent"` // 0–100; when > 0, unit_price is sale price
 	Currency           string         `gorm:"size:10;default:NPR" json:"currency"`
 	StockQuantity      int            `gorm:"default:0" json:"stock_quantity"`
+	ReorderLevel       *int           `json:"reorder_level,omitempty"` // low-stock alert level; nil = pharmacy default (inventory_alerts)
+	ReorderQuantity    *int           `json:"reorder_quantity,omitempty"` // supplier lot size; reorder suggestions are rounded up to a multiple
+	MaxStock           *int           `json:"max_stock,omitempty"`        // cap for reorder suggestions; nil = no cap
 	Unit               string         `gorm:"size:50;default:units" json:"unit"`
 	RequiresRx         bool           `gorm:"default:false" json:"requires_rx"`
 	IsActive           bool           `gorm:"default:true" json:"is_active"`
@@ -30,8 +34,20 @@ type Product struct {
 	DosageForm         string         `gorm:"size:80" json:"dosage_form"`  // tablet, capsule, syrup, etc.
 	PackSize           string            `gorm:"size:80" json:"pack_size"`   // e.g. "10 tablets", "100ml"
 	GenericName        string            `gorm:"size:255" json:"generic_name"`
+	TaxClass           string            `gorm:"size:30" json:"tax_class,omitempty"` // key into PharmacyConfig.TaxRates; empty = standard, "exempt" = no VAT
+	PreorderEnabled        bool       `gorm:"default:false" json:"preorder_enabled"`                       // customers may pre-order while out of stock or before release
+	PreorderDepositPercent float64    `gorm:"type:decimal(5,2);default:0" json:"preorder_deposit_percent"` // share of the price due up front; 0 = none, 100 = full payment
+	PreorderExpectedAt     *time.Time `json:"preorder_expected_at,omitempty"`                              // ETA shown to customers when no purchase order is linked
+	TracksSerials          bool       `gorm:"default:false" json:"tracks_serials"`                         // units carry serial numbers recorded at receipt and picked at sale
+	WarrantyMonths         int        `gorm:"default:0" json:"warranty_months"`                            // warranty registered per serial sold; 0 = none
 	Hashtags           []string          `gorm:"type:jsonb;serializer:json" json:"hashtags,omitempty"`   // e.g. ["vitamin", "organic"]
 	Labels             map[string]string `gorm:"type:jsonb;serializer:json" json:"labels,omitempty"`    // key-value e.g. {"certified": "organic", "origin": "local"}
+	Attributes         CustomFieldValues `gorm:"type:jsonb;index:idx_products_attributes,type:gin" json:"attributes,omitempty"` // typed values of the pharmacy's product attributes
+	// Translations holds other language variants of the name (Title) and description (Body); Name/Description are
+	// the pharmacy-default text.
+	Translations Translations `gorm:"type:jsonb;serializer:json" json:"translations,omitempty"`
+	// Display is set on storefront responses with a display currency: the price converted at the stored rate.
+	Display *DisplayPrice `gorm:"-" json:"display,omitempty"`
 	CreatedAt          time.Time         `json:"created_at"`
 	UpdatedAt          time.Time      `json:"updated_at"`
 	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
@@ -39,6 +55,7 @@ type Product struct {
 	Pharmacy       *Pharmacy       `gorm:"foreignKey:PharmacyID" json:"pharmacy,omitempty"`
 	CategoryDetail *Category      `gorm:"foreignKey:CategoryID" json:"category_detail,omitempty"` // when set, Parent gives parent (product type = parent + subcategory)
 	Images         []*ProductImage `gorm:"foreignKey:ProductID" json:"images,omitempty"`
+	Supplier       *Supplier       `gorm:"foreignKey:SupplierID" json:"supplier,omitempty"`
 }
 
 func (Product) TableName() string { return "products" }
@@ -49,3 +66,15 @@ func (p *Product) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// ProductDelta is the slim view of a product for catalog sync: only what changes often. Deleted products
+// are sent once with Deleted set so clients can drop them.
+type ProductDelta struct {
+	ID              uuid.UUID `json:"id"`
+	UnitPrice       float64   `json:"price"`
+	DiscountPercent float64   `json:"discount_percent"`
+	StockQuantity   int       `json:"stock"`
+	IsActive        bool      `json:"active"`
+	Deleted         bool      `json:"deleted,omitempty"`
+	ChangedAt       time.Time `json:"changed_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// ProductAttributeDefinition is one tenant-defined typed product attribute ("Requires cold chain", "Ayurvedic"),
+// kept in PharmacyConfig.ProductAttributes. Filterable attributes can narrow the public catalog.
+type ProductAttributeDefinition struct {
+	Key        string   `json:"key"` // stored key in products.attributes, lower_snake_case
+	Label      string   `json:"label"`
+	Type       string   `json:"type"` // CustomField* type
+	Required   bool     `json:"required,omitempty"`
+	Options    []string `json:"options,omitempty"` // allowed values of a select attribute
+	Filterable bool     `json:"filterable,omitempty"`
+}
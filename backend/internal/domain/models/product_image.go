@@ -8,13 +8,19 @@ import (
 )
 
 type ProductImage struct {
-	ID        uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
-	ProductID uuid.UUID      `gorm:"type:uuid;not null;index" json:"product_id"`
-	URL       string         `gorm:"size:512;not null" json:"url"`
-	IsPrimary bool           `gorm:"default:false" json:"is_primary"`
-	SortOrder int            `gorm:"default:0" json:"sort_order"`
-	CreatedAt time.Time      `json:"created_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	ProductID uuid.UUID `gorm:"type:uuid;not null;index" json:"product_id"`
+	URL       string    `gorm:"size:512;not null" json:"url"`
+	LowURL    string    `gorm:"size:512" json:"low_url,omitempty"` // small JPEG for ?quality=low; empty when the format could not be decoded
+	// Resized variants (WebP, or JPEG without cwebp), empty when the format could not be decoded.
+	ThumbnailURL string         `gorm:"size:512" json:"thumbnail_url,omitempty"`
+	MediumURL    string         `gorm:"size:512" json:"medium_url,omitempty"`
+	LargeURL     string         `gorm:"size:512" json:"large_url,omitempty"`
+	OriginalURL  string         `gorm:"-" json:"original_url,omitempty"` // set in responses where url shows a variant
+	IsPrimary    bool           `gorm:"default:false" json:"is_primary"`
+	SortOrder    int            `gorm:"default:0" json:"sort_order"`
+	CreatedAt    time.Time      `json:"created_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
 
 	Product *Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
 }
@@ -27,3 +33,8 @@ func (p *ProductImage) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// Files returns the stored files behind the image: the original and its renditions.
+func (p *ProductImage) Files() []string {
+	return []string{p.URL, p.LowURL, p.ThumbnailURL, p.MediumURL, p.LargeURL}
+}
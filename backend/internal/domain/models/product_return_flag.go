@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReturnRateAlertPolicy decides when a product is flagged for purchasing review: its return rate over the
+// last WindowDays reaches ThresholdPercent after at least MinUnitsSold units were sold.
+type ReturnRateAlertPolicy struct {
+	Enabled          bool    `json:"enabled"`
+	ThresholdPercent float64 `json:"threshold_percent"`
+	MinUnitsSold     int     `json:"min_units_sold"`
+	WindowDays       int     `json:"window_days"`
+}
+
+// DefaultReturnRateAlertPolicy applies when a pharmacy has not configured return_rate_alert.
+func DefaultReturnRateAlertPolicy() *ReturnRateAlertPolicy {
+	return &ReturnRateAlertPolicy{Enabled: true, ThresholdPercent: 5, MinUnitsSold: 20, WindowDays: 90}
+}
+
+// Product return flag statuses.
+const (
+	ReturnFlagOpen     = "open"
+	ReturnFlagReviewed = "reviewed"
+)
+
+// ProductReturnFlag marks a product whose return rate crossed the pharmacy's threshold. One row per product;
+// a reviewed flag is reopened when a later return keeps the rate above the threshold.
+type ProductReturnFlag struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	ProductID     uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"product_id"`
+	Status        string     `gorm:"size:20;not null;default:open;index" json:"status"`
+	UnitsSold     int64      `json:"units_sold"`
+	UnitsReturned int64      `json:"units_returned"`
+	ReturnRate    float64    `gorm:"type:decimal(6,2)" json:"return_rate"` // percent
+	FlaggedAt     time.Time  `json:"flagged_at"`
+	ReviewedBy    *uuid.UUID `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+	ReviewNote    string     `gorm:"type:text" json:"review_note,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	Product *Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+func (ProductReturnFlag) TableName() string { return "product_return_flags" }
+
+func (f *ProductReturnFlag) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return nil
+}
@@ -25,6 +25,31 @@ type ProductReview struct {
 
 func (ProductReview) TableName() string { return "product_reviews" }
 
+// ReviewResponse is the pharmacy's official reply to a review, shown pinned above the review's comments under the
+// pharmacy's name. A review has at most one.
+type ReviewResponse struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	ReviewID   uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"review_id"`
+	PharmacyID uuid.UUID  `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	Body       string     `gorm:"type:text;not null" json:"body"`
+	CreatedBy  uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	UpdatedBy  *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// PharmacyName is filled in by the review service for display.
+	PharmacyName string `gorm:"-" json:"pharmacy_name"`
+}
+
+func (ReviewResponse) TableName() string { return "review_responses" }
+
+func (r *ReviewResponse) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
 func (r *ProductReview) BeforeCreate(tx *gorm.DB) error {
 	if r.ID == uuid.Nil {
 		r.ID = uuid.New()
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +15,18 @@ const (
 	PromoTypeEvent        = "event"
 )
 
+// Promo placements on the public store. A promo with no placements is shown everywhere.
+const (
+	PromoPlacementHomepageHero    = "homepage_hero"
+	PromoPlacementCategorySidebar = "category_sidebar"
+	PromoPlacementCheckout        = "checkout"
+)
+
+// IsPromoPlacement reports whether p is a known placement.
+func IsPromoPlacement(p string) bool {
+	return p == PromoPlacementHomepageHero || p == PromoPlacementCategorySidebar || p == PromoPlacementCheckout
+}
+
 type Promo struct {
 	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
 	PharmacyID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
@@ -21,13 +34,22 @@ type Promo struct {
 	Title       string     `gorm:"size:255;not null" json:"title"`
 	Description string     `gorm:"type:text" json:"description"`
 	ImageURL    string     `gorm:"size:512" json:"image_url"`
+	Images      *ImageSet  `gorm:"type:jsonb;serializer:json" json:"images,omitempty"`
 	LinkURL     string     `gorm:"size:512" json:"link_url"` // optional CTA link
 	StartAt     *time.Time `gorm:"index" json:"start_at"`
 	EndAt       *time.Time `gorm:"index" json:"end_at"`
 	SortOrder   int        `gorm:"default:0" json:"sort_order"`
-	IsActive    bool       `gorm:"default:true" json:"is_active"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	// Targeting: placements and categories (for category_sidebar) the promo appears on; empty means all.
+	Placements  []string    `gorm:"type:jsonb;serializer:json" json:"placements"`
+	CategoryIDs []uuid.UUID `gorm:"type:jsonb;serializer:json" json:"category_ids"`
+	// Recurring schedule inside StartAt..EndAt: weekdays ("mon".."sun", empty = every day) and a daily
+	// "HH:MM" window (empty = all day; DailyEnd before DailyStart runs past midnight). Server local time.
+	ScheduleDays []string  `gorm:"type:jsonb;serializer:json" json:"schedule_days"`
+	DailyStart   string    `gorm:"size:5" json:"daily_start"`
+	DailyEnd     string    `gorm:"size:5" json:"daily_end"`
+	IsActive     bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 
 	Pharmacy *Pharmacy `gorm:"foreignKey:PharmacyID" json:"pharmacy,omitempty"`
 }
@@ -40,3 +62,57 @@ func (p *Promo) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// LiveAt reports whether the promo is active and inside its date range and recurring schedule at t.
+func (p *Promo) LiveAt(t time.Time) bool {
+	if !p.IsActive || (p.StartAt != nil && t.Before(*p.StartAt)) || (p.EndAt != nil && t.After(*p.EndAt)) {
+		return false
+	}
+	if len(p.ScheduleDays) > 0 {
+		day := strings.ToLower(t.Weekday().String()[:3])
+		found := false
+		for _, d := range p.ScheduleDays {
+			if d == day {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if p.DailyStart == "" || p.DailyEnd == "" {
+		return true
+	}
+	now := t.Format("15:04")
+	if p.DailyStart <= p.DailyEnd {
+		return now >= p.DailyStart && now < p.DailyEnd
+	}
+	return now >= p.DailyStart || now < p.DailyEnd // overnight window
+}
+
+// Targets reports whether the promo should appear on placement (and, for a category page, categoryID).
+// Empty arguments match any promo.
+func (p *Promo) Targets(placement string, categoryID *uuid.UUID) bool {
+	if placement != "" && len(p.Placements) > 0 {
+		found := false
+		for _, pl := range p.Placements {
+			if pl == placement {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if categoryID != nil && len(p.CategoryIDs) > 0 {
+		for _, id := range p.CategoryIDs {
+			if id == *categoryID {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -15,27 +15,45 @@ const (
 	DiscountTypeFixed   DiscountType = "fixed"
 )
 
+// PromoStacking says whether a promo code combines with the customer's membership discount and points.
+type PromoStacking string
+
+const (
+	PromoStackingCombinable PromoStacking = "combinable" // default: membership and points apply as well
+	PromoStackingExclusive  PromoStacking = "exclusive"  // no membership discount, and points cannot be redeemed
+)
+
 // PromoCode is a pharmacy-scoped discount code for billing (e.g. for visited customers).
 type PromoCode struct {
-	ID             uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
-	PharmacyID     uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_pharmacy_promo_code" json:"pharmacy_id"`
-	Code           string         `gorm:"size:50;not null;uniqueIndex:idx_pharmacy_promo_code" json:"code"`
-	DiscountType   DiscountType   `gorm:"size:20;not null" json:"discount_type"`
-	DiscountValue  float64        `gorm:"type:decimal(12,2);not null" json:"discount_value"`
-	MinOrderAmount float64        `gorm:"type:decimal(12,2);default:0" json:"min_order_amount"`
-	ValidFrom      time.Time      `gorm:"not null" json:"valid_from"`
-	ValidUntil     time.Time      `gorm:"not null;index" json:"valid_until"`
-	MaxUses        int            `gorm:"default:0" json:"max_uses"` // 0 = unlimited
-	UsedCount      int            `gorm:"default:0" json:"used_count"`
-	IsActive       bool           `gorm:"default:true;index" json:"is_active"`
-	FirstOrderOnly bool           `gorm:"default:false" json:"first_order_only"` // When true, code valid only for user's first order at this pharmacy
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+	ID             uuid.UUID    `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID     uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex:idx_pharmacy_promo_code" json:"pharmacy_id"`
+	Code           string       `gorm:"size:50;not null;uniqueIndex:idx_pharmacy_promo_code" json:"code"`
+	DiscountType   DiscountType `gorm:"size:20;not null" json:"discount_type"`
+	DiscountValue  float64      `gorm:"type:decimal(12,2);not null" json:"discount_value"`
+	MinOrderAmount float64      `gorm:"type:decimal(12,2);default:0" json:"min_order_amount"`
+	ValidFrom      time.Time    `gorm:"not null" json:"valid_from"`
+	ValidUntil     time.Time    `gorm:"not null;index" json:"valid_until"`
+	MaxUses        int          `gorm:"default:0" json:"max_uses"` // 0 = unlimited
+	UsedCount      int          `gorm:"default:0" json:"used_count"`
+	IsActive       bool         `gorm:"default:true;index" json:"is_active"`
+	FirstOrderOnly bool         `gorm:"default:false" json:"first_order_only"` // When true, code valid only for user's first order at this pharmacy
+	MaxUsesPerUser int          `gorm:"default:0" json:"max_uses_per_user"`    // 0 = unlimited; counts the user's non-cancelled orders with this code
+	// ProductIDs and CategoryIDs limit the discount to matching items (a category also matches its subcategories);
+	// both empty = whole order.
+	ProductIDs      []uuid.UUID    `gorm:"type:jsonb;serializer:json" json:"product_ids,omitempty"`
+	CategoryIDs     []uuid.UUID    `gorm:"type:jsonb;serializer:json" json:"category_ids,omitempty"`
+	MinItemQuantity int            `gorm:"default:0" json:"min_item_quantity"` // units of matching items required; 0 = none
+	Stacking        PromoStacking  `gorm:"size:20;default:combinable" json:"stacking"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
 
 	Pharmacy *Pharmacy `gorm:"foreignKey:PharmacyID" json:"pharmacy,omitempty"`
 }
 
+// Scoped reports whether the code applies to specific products or categories only.
+func (p *PromoCode) Scoped() bool { return len(p.ProductIDs) > 0 || len(p.CategoryIDs) > 0 }
+
 func (PromoCode) TableName() string { return "promo_codes" }
 
 func (p *PromoCode) BeforeCreate(tx *gorm.DB) error {
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PromoDailyStat counts impressions and clicks for one promo, day and placement.
+// Placement is empty when the storefront did not say where the promo was shown.
+type PromoDailyStat struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID  uuid.UUID `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	PromoID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_promo_stat_day_placement" json:"promo_id"`
+	Day         time.Time `gorm:"type:date;not null;uniqueIndex:idx_promo_stat_day_placement" json:"day"`
+	Placement   string    `gorm:"size:32;not null;default:'';uniqueIndex:idx_promo_stat_day_placement" json:"placement"`
+	Impressions int64     `gorm:"not null;default:0" json:"impressions"`
+	Clicks      int64     `gorm:"not null;default:0" json:"clicks"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (PromoDailyStat) TableName() string { return "promo_daily_stats" }
+
+func (s *PromoDailyStat) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PurchaseOrderStatus string
+
+// draft: created (and emailed) and awaiting supplier confirmation; confirmed/declined: supplier replied via signed link;
+// received: stock arrived; cancelled: withdrawn by staff.
+const (
+	PurchaseOrderStatusDraft     PurchaseOrderStatus = "draft"
+	PurchaseOrderStatusConfirmed PurchaseOrderStatus = "confirmed"
+	PurchaseOrderStatusDeclined  PurchaseOrderStatus = "declined"
+	PurchaseOrderStatusReceived  PurchaseOrderStatus = "received"
+	PurchaseOrderStatusCancelled PurchaseOrderStatus = "cancelled"
+)
+
+type PurchaseOrder struct {
+	ID                    uuid.UUID           `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID            uuid.UUID           `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	SupplierID            uuid.UUID           `gorm:"type:uuid;not null;index" json:"supplier_id"`
+	PONumber              string              `gorm:"size:50;uniqueIndex;not null" json:"po_number"`
+	Status                PurchaseOrderStatus `gorm:"size:20;not null;default:draft;index" json:"status"`
+	ExpectedDeliveryDate  *time.Time          `gorm:"type:date" json:"expected_delivery_date,omitempty"`  // requested by pharmacy
+	ConfirmedDeliveryDate *time.Time          `gorm:"type:date" json:"confirmed_delivery_date,omitempty"` // proposed by supplier on confirm
+	Notes                 string              `gorm:"type:text" json:"notes"`
+	SupplierNote          string              `gorm:"type:text" json:"supplier_note"`
+	SentAt                *time.Time          `json:"sent_at,omitempty"` // last time the request email was sent
+	RespondedAt           *time.Time          `json:"responded_at,omitempty"`
+	ReceivedAt            *time.Time          `json:"received_at,omitempty"`
+	CreatedBy             uuid.UUID           `gorm:"type:uuid;index" json:"created_by"`
+	CreatedAt             time.Time           `json:"created_at"`
+	UpdatedAt             time.Time           `json:"updated_at"`
+
+	Supplier *Supplier           `gorm:"foreignKey:SupplierID" json:"supplier,omitempty"`
+	Items    []PurchaseOrderItem `gorm:"foreignKey:PurchaseOrderID" json:"items,omitempty"`
+}
+
+func (PurchaseOrder) TableName() string { return "purchase_orders" }
+
+func (po *PurchaseOrder) BeforeCreate(tx *gorm.DB) error {
+	if po.ID == uuid.Nil {
+		po.ID = uuid.New()
+	}
+	if po.PONumber == "" {
+		po.PONumber = "PO-" + uuid.New().String()[:8]
+	}
+	return nil
+}
+
+type PurchaseOrderItem struct {
+	ID              uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	PurchaseOrderID uuid.UUID `gorm:"type:uuid;not null;index" json:"purchase_order_id"`
+	ProductID       uuid.UUID `gorm:"type:uuid;not null;index" json:"product_id"`
+	Quantity        int       `gorm:"not null" json:"quantity"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+
+	Product *Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+func (PurchaseOrderItem) TableName() string { return "purchase_order_items" }
+
+func (i *PurchaseOrderItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RecentView is the last time a storefront viewer opened a product. Viewer is "user:<id>" for a signed-in user
+// of the pharmacy, otherwise "session:<id>" from the client's X-Session-ID; one row per viewer and product.
+type RecentView struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID uuid.UUID  `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	Viewer     string     `gorm:"size:80;not null;uniqueIndex:idx_recent_view_viewer_product" json:"-"`
+	UserID     *uuid.UUID `gorm:"type:uuid" json:"user_id,omitempty"`
+	ProductID  uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_recent_view_viewer_product" json:"product_id"`
+	ViewedAt   time.Time  `gorm:"not null;index" json:"viewed_at"`
+
+	Product *Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+func (RecentView) TableName() string { return "recent_views" }
+
+func (v *RecentView) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
+
+// ProductScore is a product with a ranking score, e.g. the number of orders it shared with other products.
+type ProductScore struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Score     int64     `json:"score"`
+}
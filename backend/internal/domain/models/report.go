@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Report rows are read models filled by aggregate queries; they have no table.
+
+// SalesPeriodRow is one bucket (day, week or month) of the sales summary. Cancelled orders are excluded.
+type SalesPeriodRow struct {
+	Period         time.Time `json:"period"`
+	OrdersCount    int64     `json:"orders_count"`
+	SubTotal       float64   `json:"sub_total"`
+	DiscountAmount float64   `json:"discount_amount"`
+	TaxAmount      float64   `json:"tax_amount"`
+	Revenue        float64   `json:"revenue"` // sum of order total_amount
+}
+
+// TopProductRow is a product ranked by quantity sold in the range.
+type TopProductRow struct {
+	ProductID    uuid.UUID `json:"product_id"`
+	Name         string    `json:"name"`
+	SKU          string    `json:"sku"`
+	QuantitySold int64     `json:"quantity_sold"`
+	Revenue      float64   `json:"revenue"`
+	OrdersCount  int64     `json:"orders_count"`
+}
+
+// PaymentMethodRow is completed payment volume for one method.
+type PaymentMethodRow struct {
+	Method        string  `json:"method"`
+	PaymentsCount int64   `json:"payments_count"`
+	Amount        float64 `json:"amount"`
+}
+
+// LowStockRow is an active product at or below the stock threshold.
+type LowStockRow struct {
+	ProductID     uuid.UUID `json:"product_id"`
+	Name          string    `json:"name"`
+	SKU           string    `json:"sku"`
+	Category      string    `json:"category"`
+	StockQuantity int       `json:"stock_quantity"`
+	UnitPrice     float64   `json:"unit_price"`
+}
+
+// ExpiringStockRow is a batch expiring in the range, valued at the product's unit price.
+type ExpiringStockRow struct {
+	BatchID     uuid.UUID `json:"batch_id"`
+	ProductID   uuid.UUID `json:"product_id"`
+	Name        string    `json:"name"`
+	SKU         string    `json:"sku"`
+	BatchNumber string    `json:"batch_number"`
+	Quantity    int       `json:"quantity"`
+	ExpiryDate  time.Time `json:"expiry_date"`
+	UnitPrice   float64   `json:"unit_price"`
+	Value       float64   `json:"value"` // quantity * unit_price
+}
+
+// FeedbackTrendRow is the order-feedback volume and average rating for one period.
+type FeedbackTrendRow struct {
+	Period        time.Time `json:"period"`
+	Count         int64     `json:"count"`
+	AverageRating float64   `json:"average_rating"`
+}
+
+// FeedbackGroupRow is the order-feedback volume and average rating for one group (staff member, fulfilment type).
+type FeedbackGroupRow struct {
+	Key           string  `json:"key"`
+	Label         string  `json:"label"`
+	Count         int64   `json:"count"`
+	AverageRating float64 `json:"average_rating"`
+	LowCount      int64   `json:"low_count"` // ratings at or below LowFeedbackRating
+}
+
+// ReturnRateRow is units sold and returned for one group (product, brand or supplier). ReturnRate is a percent.
+type ReturnRateRow struct {
+	Key            string  `json:"key"`
+	Label          string  `json:"label"`
+	UnitsSold      int64   `json:"units_sold"`
+	UnitsReturned  int64   `json:"units_returned"`
+	ReturnRequests int64   `json:"return_requests"`
+	ReturnRate     float64 `json:"return_rate" gorm:"-"`
+}
+
+// ReturnReasonRow is the number of return requests with one defect reason.
+type ReturnReasonRow struct {
+	ReasonCode string `json:"reason_code"`
+	Label      string `json:"label" gorm:"-"`
+	Count      int64  `json:"count"`
+}
+
+// DeadStockRow is an active product with stock on hand and no sales since the cutoff, valued at its unit price.
+type DeadStockRow struct {
+	ProductID         uuid.UUID  `json:"product_id"`
+	Name              string     `json:"name"`
+	SKU               string     `json:"sku"`
+	Category          string     `json:"category"`
+	SupplierID        *uuid.UUID `json:"supplier_id,omitempty"`
+	SupplierName      string     `json:"supplier_name"`
+	StockQuantity     int        `json:"stock_quantity"`
+	UnitPrice         float64    `json:"unit_price"`
+	StockValue        float64    `json:"stock_value"`                   // stock_quantity * unit_price
+	LastSoldAt        *time.Time `json:"last_sold_at,omitempty"`        // nil = never sold
+	ClearanceActionID *uuid.UUID `json:"clearance_action_id,omitempty"` // open clearance action holding the product
+}
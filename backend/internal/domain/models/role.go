@@ -0,0 +1,194 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Built-in roles. Admin always has every permission and buyer accounts ("staff") have none; manager and
+// pharmacist start from DefaultRolePermissions and can be customized per pharmacy.
+const (
+	RoleAdmin      = "admin"
+	RoleManager    = "manager"
+	RolePharmacist = "pharmacist"
+	RoleStaff      = "staff" // end-user / buyer
+)
+
+// Permission strings checked by the API. Each route needs exactly one.
+const (
+	PermPharmaciesWrite       = "pharmacies.write"
+	PermConfigWrite           = "config.write"
+	PermNotificationsSend     = "notifications.send"
+	PermActivityRead          = "activity.read"
+	PermPromosManage          = "promos.manage"
+	PermReferralManage        = "referral.manage"
+	PermPaymentGatewaysRead   = "payment_gateways.read"
+	PermPaymentGatewaysManage = "payment_gateways.manage"
+	PermRolesManage           = "roles.manage"
+	PermUsersManage           = "users.manage"
+	PermRosterManage          = "roster.manage"
+	PermDailyLogsManage       = "daily_logs.manage"
+	PermReportsRead           = "reports.read"
+	PermSuppliersManage       = "suppliers.manage"
+	PermPurchaseOrdersManage  = "purchase_orders.manage"
+	PermFlashSalesManage      = "flash_sales.manage"
+	PermTrainingManage        = "training.manage"
+	PermTrainingTake          = "training.take"
+	PermProductsRead          = "products.read"
+	PermProductsWrite         = "products.write"
+	PermCategoriesManage      = "categories.manage"
+	PermProductUnitsManage    = "product_units.manage"
+	PermMembershipsManage     = "memberships.manage"
+	PermInventoryRead         = "inventory.read"
+	PermInventoryWrite        = "inventory.write"
+	PermCustomersRead         = "customers.read"
+	PermOrdersAccept          = "orders.accept"
+	PermOrdersUpdateStatus    = "orders.update_status"
+	PermDeliveriesManage      = "deliveries.manage"
+	PermFeedbackManage        = "feedback.manage"
+	PermReturnsManage         = "returns.manage"
+	PermInvoicesManage        = "invoices.manage"
+	PermPaymentsManage        = "payments.manage"
+	PermPromoCodesManage      = "promo_codes.manage"
+	PermAnnouncementsManage   = "announcements.manage"
+	PermAIUse                 = "ai.use"
+	PermBlogWrite             = "blog.write"
+	PermBlogApprove           = "blog.approve"
+	PermDeliveryQueueManage   = "delivery_queue.manage"
+	PermIntegrityManage       = "integrity.manage"
+	PermBranchesManage        = "branches.manage"
+	PermStockTransfersManage  = "stock_transfers.manage"
+	PermGiftCardsManage       = "gift_cards.manage"
+	PermCampaignsManage       = "campaigns.manage"
+	PermClearanceManage       = "clearance.manage"
+	PermWarrantiesManage      = "warranties.manage"
+	PermOrganizationManage    = "organization.manage"
+	PermConsentManage         = "consent.manage"
+	PermExportsApprove        = "exports.approve"
+	PermReviewsRespond        = "reviews.respond"
+	PermReviewsManage         = "reviews.manage"
+	PermModerationManage      = "moderation.manage"
+)
+
+// PermissionRegistry lists every permission with a short description. Roles may only hold registered permissions.
+var PermissionRegistry = map[string]string{
+	PermPharmaciesWrite:       "Create and edit pharmacies",
+	PermConfigWrite:           "Edit pharmacy configuration",
+	PermNotificationsSend:     "Send notifications",
+	PermActivityRead:          "View the activity log",
+	PermPromosManage:          "Manage offers, announcements and events on the store",
+	PermReferralManage:        "Edit referral and points rules",
+	PermPaymentGatewaysRead:   "View payment gateways",
+	PermPaymentGatewaysManage: "Manage payment gateways",
+	PermRolesManage:           "Manage roles and permissions",
+	PermUsersManage:           "Manage team members",
+	PermRosterManage:          "Manage the duty roster",
+	PermDailyLogsManage:       "Manage daily logs",
+	PermReportsRead:           "View reports",
+	PermSuppliersManage:       "Manage suppliers",
+	PermPurchaseOrdersManage:  "Manage purchase orders and reorders",
+	PermFlashSalesManage:      "Manage flash sales",
+	PermTrainingManage:        "Manage SOPs, quizzes and training compliance",
+	PermTrainingTake:          "Read SOPs and take training quizzes",
+	PermProductsRead:          "View products in the dashboard",
+	PermProductsWrite:         "Create and edit products, stock and images",
+	PermCategoriesManage:      "Manage categories",
+	PermProductUnitsManage:    "Manage product units",
+	PermMembershipsManage:     "Manage memberships",
+	PermInventoryRead:         "View inventory batches and expiring stock",
+	PermInventoryWrite:        "Add, edit and delete inventory batches",
+	PermCustomersRead:         "View customers and points",
+	PermOrdersAccept:          "Accept orders",
+	PermOrdersUpdateStatus:    "Change order status",
+	PermDeliveriesManage:      "Create deliveries, assign delivery staff and update any delivery",
+	PermFeedbackManage:        "View order feedback and ratings, and record customer follow-ups",
+	PermReturnsManage:         "Review return requests, view return rates and clear return-rate flags",
+	PermInvoicesManage:        "Create and issue invoices",
+	PermPaymentsManage:        "Record and complete payments",
+	PermPromoCodesManage:      "Manage promo codes",
+	PermAnnouncementsManage:   "Manage dashboard announcements",
+	PermAIUse:                 "Generate and review AI drafts",
+	PermBlogWrite:             "Write blog posts and manage blog categories",
+	PermBlogApprove:           "Approve blog posts",
+	PermDeliveryQueueManage:   "View failed email, SMS and webhook deliveries and retry them",
+	PermIntegrityManage:       "Run data integrity checks and apply their automatic fixes",
+	PermBranchesManage:        "Manage branches and assign team members to them",
+	PermStockTransfersManage:  "Request, approve, dispatch and receive stock transfers with group pharmacies",
+	PermGiftCardsManage:       "Issue, list and deactivate gift cards",
+	PermCampaignsManage:       "Define customer segments and send bulk messages to them",
+	PermClearanceManage:       "Clear dead stock through discount promos and supplier returns",
+	PermWarrantiesManage:      "Look up device warranties and handle warranty claims",
+	PermOrganizationManage:    "Create an organization for this pharmacy or join one with its join code",
+	PermConsentManage:         "Record customers' marketing consent and export consent records",
+	PermExportsApprove:        "Download exports with personal data directly and approve other members' export requests",
+	PermReviewsRespond:        "Post the pharmacy's official response to a product review",
+	PermReviewsManage:         "Edit and remove the pharmacy's official review responses",
+	PermModerationManage:      "Work through reported reviews, comments and chat messages: dismiss, hide or warn the author",
+}
+
+var pharmacistPermissions = []string{
+	PermProductsRead, PermProductsWrite, PermCategoriesManage, PermProductUnitsManage, PermMembershipsManage,
+	PermInventoryRead, PermCustomersRead, PermOrdersAccept, PermOrdersUpdateStatus, PermDeliveriesManage, PermFeedbackManage,
+	PermReturnsManage, PermInvoicesManage, PermPaymentsManage, PermPaymentGatewaysRead, PermPromoCodesManage, PermAnnouncementsManage, PermAIUse,
+	PermTrainingTake, PermBlogWrite, PermWarrantiesManage, PermConsentManage, PermReviewsRespond, PermModerationManage,
+}
+
+var managerPermissions = append([]string{
+	PermInventoryWrite, PermUsersManage, PermRosterManage, PermDailyLogsManage, PermReportsRead,
+	PermSuppliersManage, PermPurchaseOrdersManage, PermFlashSalesManage, PermTrainingManage, PermBlogApprove,
+	PermBranchesManage, PermStockTransfersManage, PermGiftCardsManage, PermClearanceManage, PermExportsApprove,
+	PermReviewsManage,
+}, pharmacistPermissions...)
+
+// IsBuiltInRole reports whether name is one of the built-in roles.
+func IsBuiltInRole(name string) bool {
+	return name == RoleAdmin || name == RoleManager || name == RolePharmacist || name == RoleStaff
+}
+
+// DefaultRolePermissions returns the permissions a built-in role has until a pharmacy customizes it
+// (nil for custom roles and buyers).
+func DefaultRolePermissions(role string) []string {
+	switch role {
+	case RoleAdmin:
+		return AllPermissions()
+	case RoleManager:
+		return append([]string(nil), managerPermissions...)
+	case RolePharmacist:
+		return append([]string(nil), pharmacistPermissions...)
+	}
+	return nil
+}
+
+// AllPermissions returns every registered permission, sorted.
+func AllPermissions() []string {
+	all := make([]string, 0, len(PermissionRegistry))
+	for p := range PermissionRegistry {
+		all = append(all, p)
+	}
+	sort.Strings(all)
+	return all
+}
+
+// Role is a pharmacy-defined role, or a pharmacy's override of the manager or pharmacist permission set.
+// Users reference roles by Name (User.Role).
+type Role struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_role_pharmacy_name" json:"pharmacy_id"`
+	Name        string    `gorm:"size:32;not null;uniqueIndex:idx_role_pharmacy_name" json:"name"`
+	Description string    `gorm:"size:255" json:"description"`
+	Permissions []string  `gorm:"type:jsonb;serializer:json" json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (Role) TableName() string { return "roles" }
+
+func (r *Role) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SecurityPolicy holds a pharmacy's password and sign-in rules (PharmacyConfig.SecurityPolicy).
+type SecurityPolicy struct {
+	MinLength        int      `json:"min_length"`        // minimum password length
+	RequireUppercase bool     `json:"require_uppercase"` // at least one upper-case letter
+	RequireLowercase bool     `json:"require_lowercase"` // at least one lower-case letter
+	RequireDigit     bool     `json:"require_digit"`     // at least one digit
+	RequireSymbol    bool     `json:"require_symbol"`    // at least one character that is not a letter or digit
+	ExpiryDays       int      `json:"expiry_days"`       // passwords must be changed after this many days; 0 = never
+	ReuseCount       int      `json:"reuse_count"`       // a new password may not match any of the last N, the current one included; 0 = off
+	SessionHours     int      `json:"session_hours"`     // sign in again this long after login, however active; 0 = refresh token lifetime
+	TwoFactorRoles   []string `json:"two_factor_roles"`  // roles that confirm each login with a code sent by email
+}
+
+// DefaultSecurityPolicy applies when a pharmacy has not configured security_policy.
+func DefaultSecurityPolicy() *SecurityPolicy {
+	return &SecurityPolicy{MinLength: 6}
+}
+
+// PasswordRequirement is one rule of the policy and whether a password meets it.
+type PasswordRequirement struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Met     bool   `json:"met"`
+}
+
+// PasswordRequirements lists the policy's password rules in display order, checked against password.
+func (p *SecurityPolicy) PasswordRequirements(password string) []PasswordRequirement {
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsLetter(r) && !unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	out := []PasswordRequirement{{Code: "min_length", Message: fmt.Sprintf("at least %d characters", p.MinLength), Met: len([]rune(password)) >= p.MinLength}}
+	if p.RequireUppercase {
+		out = append(out, PasswordRequirement{Code: "uppercase", Message: "an upper-case letter", Met: upper})
+	}
+	if p.RequireLowercase {
+		out = append(out, PasswordRequirement{Code: "lowercase", Message: "a lower-case letter", Met: lower})
+	}
+	if p.RequireDigit {
+		out = append(out, PasswordRequirement{Code: "digit", Message: "a digit", Met: digit})
+	}
+	if p.RequireSymbol {
+		out = append(out, PasswordRequirement{Code: "symbol", Message: "a symbol", Met: symbol})
+	}
+	return out
+}
+
+// PasswordProblem describes the requirements password misses, or "" when it meets them all.
+func (p *SecurityPolicy) PasswordProblem(password string) string {
+	var missing []string
+	for _, r := range p.PasswordRequirements(password) {
+		if !r.Met {
+			missing = append(missing, r.Message)
+		}
+	}
+	if len(missing) == 0 {
+		return ""
+	}
+	return "password must contain " + strings.Join(missing, ", ")
+}
+
+// RequiresTwoFactor reports whether users with the role confirm logins with an emailed code.
+func (p *SecurityPolicy) RequiresTwoFactor(role string) bool {
+	for _, r := range p.TwoFactorRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// PasswordExpired reports whether the user's password is older than ExpiryDays. Accounts that never changed
+// their password count from when they were created.
+func (p *SecurityPolicy) PasswordExpired(u *User, now time.Time) bool {
+	if p.ExpiryDays <= 0 {
+		return false
+	}
+	changed := u.CreatedAt
+	if u.PasswordChangedAt != nil {
+		changed = *u.PasswordChangedAt
+	}
+	return now.Sub(changed) > time.Duration(p.ExpiryDays)*24*time.Hour
+}
+
+// PasswordReused reports whether plain matches the current password or one of the last ReuseCount-1 before it.
+func (p *SecurityPolicy) PasswordReused(u *User, plain string) bool {
+	if p.ReuseCount <= 0 || u.PasswordHash == "" {
+		return false
+	}
+	if u.CheckPassword(plain) {
+		return true
+	}
+	for i, hash := range u.PasswordHistory {
+		if i >= p.ReuseCount-1 {
+			break
+		}
+		if (&User{PasswordHash: hash}).CheckPassword(plain) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReplacePassword sets a new password, keeping the previous hashes the reuse rule needs.
+func (p *SecurityPolicy) ReplacePassword(u *User, plain string, now time.Time) error {
+	previous := u.PasswordHash
+	if err := u.SetPassword(plain); err != nil {
+		return err
+	}
+	var history []string
+	if previous != "" && p.ReuseCount > 1 {
+		history = append([]string{previous}, u.PasswordHistory...)
+		if len(history) > p.ReuseCount-1 {
+			history = history[:p.ReuseCount-1]
+		}
+	}
+	u.PasswordHistory = history
+	u.PasswordChangedAt = &now
+	return nil
+}
+
+// LoginChallenge is the second step of a login for roles the security policy requires two-factor for: the
+// password was accepted and a one-time code was emailed. Only hashes of the challenge token and code are stored.
+type LoginChallenge struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	CodeHash  string     `gorm:"size:64;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	Attempts  int        `gorm:"default:0" json:"attempts"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (LoginChallenge) TableName() string { return "login_challenges" }
+
+func (c *LoginChallenge) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
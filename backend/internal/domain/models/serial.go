@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SerialStatus: a serial is in stock from goods receipt until it is sold on an order item. Cancelling the order
+// puts it back in stock.
+type SerialStatus string
+
+const (
+	SerialInStock SerialStatus = "in_stock"
+	SerialSold    SerialStatus = "sold"
+)
+
+// ProductSerial is one unit of a serial-tracked product (glucometer, BP monitor). The serial is unique per product
+// within a pharmacy; OrderID and OrderItemID point at the sale so a device brought back can be traced to its
+// invoice for warranty.
+type ProductSerial struct {
+	ID              uuid.UUID    `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID      uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex:idx_product_serial" json:"pharmacy_id"`
+	ProductID       uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex:idx_product_serial" json:"product_id"`
+	SerialNumber    string       `gorm:"size:100;not null;uniqueIndex:idx_product_serial" json:"serial_number"`
+	Status          SerialStatus `gorm:"size:20;not null;default:in_stock;index" json:"status"`
+	PurchaseOrderID *uuid.UUID   `gorm:"type:uuid;index" json:"purchase_order_id,omitempty"` // goods receipt the unit arrived on
+	ReceivedBy      uuid.UUID    `gorm:"type:uuid;not null" json:"received_by"`
+	ReceivedAt      time.Time    `gorm:"not null" json:"received_at"`
+	OrderID         *uuid.UUID   `gorm:"type:uuid;index" json:"order_id,omitempty"`
+	OrderItemID     *uuid.UUID   `gorm:"type:uuid;index" json:"order_item_id,omitempty"`
+	SoldAt          *time.Time   `json:"sold_at,omitempty"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+
+	Product *Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	Order   *Order   `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+}
+
+func (ProductSerial) TableName() string { return "product_serials" }
+
+func (s *ProductSerial) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ShiftSwapStatus: an offer stays open until a manager approves one of its requests, rejects it, or the offerer
+// cancels it.
+type ShiftSwapStatus string
+
+const (
+	ShiftSwapOpen      ShiftSwapStatus = "open"
+	ShiftSwapApproved  ShiftSwapStatus = "approved"
+	ShiftSwapRejected  ShiftSwapStatus = "rejected"
+	ShiftSwapCancelled ShiftSwapStatus = "cancelled"
+)
+
+// ShiftSwapRequestStatus of a colleague's request to take an offered shift.
+type ShiftSwapRequestStatus string
+
+const (
+	ShiftSwapRequestPending   ShiftSwapRequestStatus = "pending"
+	ShiftSwapRequestApproved  ShiftSwapRequestStatus = "approved"
+	ShiftSwapRequestDeclined  ShiftSwapRequestStatus = "declined"
+	ShiftSwapRequestWithdrawn ShiftSwapRequestStatus = "withdrawn"
+)
+
+// ShiftSwap is a published roster shift its assignee offers to colleagues. On approval the roster entry moves to
+// the approved requester.
+type ShiftSwap struct {
+	ID                uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID        uuid.UUID       `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	RosterID          uuid.UUID       `gorm:"type:uuid;not null;index" json:"roster_id"`
+	OfferedBy         uuid.UUID       `gorm:"type:uuid;not null;index" json:"offered_by"`
+	Note              string          `gorm:"size:500" json:"note"`
+	Status            ShiftSwapStatus `gorm:"size:20;not null;default:open;index" json:"status"`
+	ApprovedRequestID *uuid.UUID      `gorm:"type:uuid" json:"approved_request_id,omitempty"`
+	DecidedBy         *uuid.UUID      `gorm:"type:uuid" json:"decided_by,omitempty"`
+	DecidedAt         *time.Time      `json:"decided_at,omitempty"`
+	DecisionNote      string          `gorm:"size:500" json:"decision_note,omitempty"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+
+	Roster   *DutyRoster         `gorm:"foreignKey:RosterID" json:"roster,omitempty"`
+	Offerer  *User               `gorm:"foreignKey:OfferedBy" json:"offerer,omitempty"`
+	Requests []*ShiftSwapRequest `gorm:"foreignKey:SwapID" json:"requests,omitempty"`
+	Events   []*ShiftSwapEvent   `gorm:"foreignKey:SwapID" json:"events,omitempty"`
+}
+
+func (ShiftSwap) TableName() string { return "shift_swaps" }
+
+func (s *ShiftSwap) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// ShiftSwapRequest is a colleague asking to take an offered shift.
+type ShiftSwapRequest struct {
+	ID        uuid.UUID              `gorm:"type:uuid;primaryKey" json:"id"`
+	SwapID    uuid.UUID              `gorm:"type:uuid;not null;index" json:"swap_id"`
+	UserID    uuid.UUID              `gorm:"type:uuid;not null;index" json:"user_id"`
+	Note      string                 `gorm:"size:500" json:"note"`
+	Status    ShiftSwapRequestStatus `gorm:"size:20;not null;default:pending" json:"status"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (ShiftSwapRequest) TableName() string { return "shift_swap_requests" }
+
+func (r *ShiftSwapRequest) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// ShiftSwapEvent is one entry in a swap's audit trail.
+type ShiftSwapEvent struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	SwapID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"swap_id"`
+	Action    string     `gorm:"size:20;not null" json:"action"`
+	ActorID   uuid.UUID  `gorm:"type:uuid;not null" json:"actor_id"`
+	RequestID *uuid.UUID `gorm:"type:uuid" json:"request_id,omitempty"`
+	Note      string     `gorm:"size:500" json:"note"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (ShiftSwapEvent) TableName() string { return "shift_swap_events" }
+
+func (e *ShiftSwapEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// Shift swap event actions.
+const (
+	ShiftSwapEventOffered   = "offered"
+	ShiftSwapEventRequested = "requested"
+	ShiftSwapEventWithdrawn = "withdrawn"
+	ShiftSwapEventApproved  = "approved"
+	ShiftSwapEventRejected  = "rejected"
+	ShiftSwapEventCancelled = "cancelled"
+)
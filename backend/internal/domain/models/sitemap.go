@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxSitemapURLs is the sitemap protocol's limit on URLs in one file.
+const MaxSitemapURLs = 50000
+
+// Kinds of storefront pages listed in a sitemap.
+const (
+	SitemapKindProduct  = "product"
+	SitemapKindCategory = "category"
+	SitemapKindBlogPost = "blog_post"
+)
+
+// StorefrontSitemap is a pharmacy's generated sitemap.xml. Fingerprint summarizes the content it was built from,
+// so the refresh job only rebuilds it when products, categories, blog posts or the config changed.
+type StorefrontSitemap struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"pharmacy_id"`
+	XML         string    `gorm:"column:xml;type:text;not null" json:"-"`
+	URLCount    int       `gorm:"not null;default:0" json:"url_count"`
+	BaseURL     string    `gorm:"size:300" json:"base_url"`
+	Fingerprint string    `gorm:"size:200" json:"-"`
+	GeneratedAt time.Time `json:"generated_at"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (StorefrontSitemap) TableName() string { return "storefront_sitemaps" }
+
+func (s *StorefrontSitemap) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// SitemapSource is one storefront page backed by content: Key is the product or category id, or the post slug.
+type SitemapSource struct {
+	Kind      string
+	Key       string
+	UpdatedAt time.Time
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StaffPointsTransaction records points credited to a team member for a completed sale. An order credits
+// points once, to the user who created it.
+type StaffPointsTransaction struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID  uuid.UUID `gorm:"type:uuid;not null;index:idx_staff_points_pharmacy_created" json:"pharmacy_id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	OrderID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"order_id"`
+	OrderNumber string    `gorm:"size:50" json:"order_number"`
+	SaleAmount  float64   `gorm:"type:decimal(12,2);not null" json:"sale_amount"`
+	Points      int       `gorm:"not null" json:"points"`
+	CreatedAt   time.Time `gorm:"index:idx_staff_points_pharmacy_created" json:"created_at"`
+}
+
+func (StaffPointsTransaction) TableName() string { return "staff_points_transactions" }
+
+func (t *StaffPointsTransaction) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// StaffPointsMonthRow is one team member's points for one calendar month, for incentive payouts.
+type StaffPointsMonthRow struct {
+	Month      string    `json:"month"` // YYYY-MM
+	UserID     uuid.UUID `json:"user_id"`
+	Name       string    `json:"name"`
+	Email      string    `json:"email"`
+	Role       string    `json:"role"`
+	SalesCount int64     `json:"sales_count"`
+	SaleAmount float64   `json:"sale_amount"`
+	Points     int64     `json:"points"`
+	Rank       int       `json:"rank" gorm:"-"` // within the month, by points
+}
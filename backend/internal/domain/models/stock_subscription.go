@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Stock subscription statuses.
+const (
+	StockSubscriptionPending  = "pending"  // waiting for the product to be back in stock
+	StockSubscriptionNotified = "notified" // the back-in-stock notice was sent; the subscription is done
+)
+
+// StockSubscription asks to be told once when an out-of-stock product is back in stock. The subscriber is a
+// signed-in user (UserID) or a guest reached by Phone and/or Email.
+type StockSubscription struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID uuid.UUID  `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	ProductID  uuid.UUID  `gorm:"type:uuid;not null;index:idx_stock_subscriptions_product_status" json:"product_id"`
+	UserID     *uuid.UUID `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	Phone      string     `gorm:"size:50" json:"phone,omitempty"`
+	Email      string     `gorm:"size:255" json:"email,omitempty"`
+	Status     string     `gorm:"size:20;not null;default:pending;index:idx_stock_subscriptions_product_status" json:"status"`
+	NotifiedAt *time.Time `json:"notified_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	Product *Product `gorm:"foreignKey:ProductID" json:"-"`
+}
+
+func (StockSubscription) TableName() string { return "stock_subscriptions" }
+
+func (s *StockSubscription) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	if s.Status == "" {
+		s.Status = StockSubscriptionPending
+	}
+	return nil
+}
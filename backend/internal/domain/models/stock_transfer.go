@@ -0,0 +1,130 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StockTransferStatus: the destination pharmacy requests stock, the source approves (or rejects) and dispatches
+// it, and the destination receives it. Either side can stop a transfer before dispatch.
+type StockTransferStatus string
+
+const (
+	StockTransferRequested StockTransferStatus = "requested"
+	StockTransferApproved  StockTransferStatus = "approved"
+	StockTransferInTransit StockTransferStatus = "in_transit"
+	StockTransferReceived  StockTransferStatus = "received"
+	StockTransferRejected  StockTransferStatus = "rejected"
+	StockTransferCancelled StockTransferStatus = "cancelled"
+)
+
+// StockTransfer moves stock between two pharmacies of the same group (see Pharmacy.GroupCode). Inventory only
+// changes on receipt: source batches are debited FEFO and matching batches are created at the destination.
+type StockTransfer struct {
+	ID               uuid.UUID           `gorm:"type:uuid;primaryKey" json:"id"`
+	SourcePharmacyID uuid.UUID           `gorm:"type:uuid;not null;index" json:"source_pharmacy_id"`
+	DestPharmacyID   uuid.UUID           `gorm:"type:uuid;not null;index" json:"dest_pharmacy_id"`
+	Status           StockTransferStatus `gorm:"size:20;not null;default:requested;index" json:"status"`
+	Note             string              `gorm:"size:500" json:"note"`
+	RequestedBy      uuid.UUID           `gorm:"type:uuid;not null" json:"requested_by"`
+	ApprovedBy       *uuid.UUID          `gorm:"type:uuid" json:"approved_by,omitempty"`
+	ApprovedAt       *time.Time          `json:"approved_at,omitempty"`
+	DispatchedBy     *uuid.UUID          `gorm:"type:uuid" json:"dispatched_by,omitempty"`
+	DispatchedAt     *time.Time          `json:"dispatched_at,omitempty"`
+	ReceivedBy       *uuid.UUID          `gorm:"type:uuid" json:"received_by,omitempty"`
+	ReceivedAt       *time.Time          `json:"received_at,omitempty"`
+	ClosedBy         *uuid.UUID          `gorm:"type:uuid" json:"closed_by,omitempty"` // who rejected or cancelled
+	ClosedAt         *time.Time          `json:"closed_at,omitempty"`
+	CreatedAt        time.Time           `json:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at"`
+
+	SourcePharmacy *Pharmacy             `gorm:"foreignKey:SourcePharmacyID" json:"source_pharmacy,omitempty"`
+	DestPharmacy   *Pharmacy             `gorm:"foreignKey:DestPharmacyID" json:"dest_pharmacy,omitempty"`
+	Items          []*StockTransferItem  `gorm:"foreignKey:TransferID" json:"items,omitempty"`
+	Events         []*StockTransferEvent `gorm:"foreignKey:TransferID" json:"events,omitempty"`
+}
+
+func (StockTransfer) TableName() string { return "stock_transfers" }
+
+func (t *StockTransfer) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// StockTransferItem is one requested product. Products are per pharmacy, so the source product is the one with
+// the destination product's SKU.
+type StockTransferItem struct {
+	ID              uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	TransferID      uuid.UUID `gorm:"type:uuid;not null;index" json:"transfer_id"`
+	SourceProductID uuid.UUID `gorm:"type:uuid;not null" json:"source_product_id"`
+	DestProductID   uuid.UUID `gorm:"type:uuid;not null" json:"dest_product_id"`
+	SKU             string    `gorm:"size:100" json:"sku"`
+	Name            string    `gorm:"size:255" json:"name"`
+	Quantity        int       `gorm:"not null" json:"quantity"`
+
+	Batches []StockTransferItemBatch `gorm:"foreignKey:ItemID" json:"batches,omitempty"`
+}
+
+func (StockTransferItem) TableName() string { return "stock_transfer_items" }
+
+func (i *StockTransferItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// StockTransferItemBatch records, on receipt, which source batch was debited and which destination batch credited.
+// Both are nil for products the source does not track in batches.
+type StockTransferItemBatch struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	ItemID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"item_id"`
+	FromBatchID *uuid.UUID `gorm:"type:uuid" json:"from_batch_id,omitempty"`
+	ToBatchID   *uuid.UUID `gorm:"type:uuid" json:"to_batch_id,omitempty"`
+	BatchNumber string     `gorm:"size:100" json:"batch_number"`
+	ExpiryDate  *time.Time `json:"expiry_date,omitempty"`
+	Quantity    int        `gorm:"not null" json:"quantity"`
+}
+
+func (StockTransferItemBatch) TableName() string { return "stock_transfer_item_batches" }
+
+func (b *StockTransferItemBatch) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
+
+// StockTransferEvent is one entry in a transfer's audit trail; PharmacyID is the actor's side.
+type StockTransferEvent struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	TransferID uuid.UUID `gorm:"type:uuid;not null;index" json:"transfer_id"`
+	Action     string    `gorm:"size:20;not null" json:"action"`
+	ActorID    uuid.UUID `gorm:"type:uuid;not null" json:"actor_id"`
+	PharmacyID uuid.UUID `gorm:"type:uuid;not null" json:"pharmacy_id"`
+	Note       string    `gorm:"size:500" json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (StockTransferEvent) TableName() string { return "stock_transfer_events" }
+
+func (e *StockTransferEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// Stock transfer event actions.
+const (
+	StockTransferEventRequested  = "requested"
+	StockTransferEventApproved   = "approved"
+	StockTransferEventRejected   = "rejected"
+	StockTransferEventDispatched = "dispatched"
+	StockTransferEventReceived   = "received"
+	StockTransferEventCancelled  = "cancelled"
+)
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Subscription plan codes.
+const (
+	PlanTrial    = "trial"
+	PlanBasic    = "basic"
+	PlanStandard = "standard"
+	PlanPro      = "pro"
+)
+
+// Plan limits a subscription enforces.
+const (
+	PlanLimitProducts = "products"
+	PlanLimitUsers    = "users"   // staff accounts; end users who sign up on the storefront are not counted
+	PlanLimitStorage  = "storage" // upload bytes
+)
+
+// Subscription states: writes keep working through the grace period after expiry, then answer 402 until renewal.
+const (
+	SubscriptionActive  = "active"
+	SubscriptionGrace   = "grace"
+	SubscriptionExpired = "expired"
+)
+
+// SubscriptionGracePeriod is how long a pharmacy keeps working after its subscription expires.
+const SubscriptionGracePeriod = 7 * 24 * time.Hour
+
+// TrialPeriod is how long the trial a newly approved pharmacy starts on lasts.
+const TrialPeriod = 14 * 24 * time.Hour
+
+// Plan is a subscription tier. Zero limits are unlimited; nil Features allows every feature flag.
+type Plan struct {
+	Code        string   `json:"code"`
+	Name        string   `json:"name"`
+	MaxProducts int      `json:"max_products"`
+	MaxUsers    int      `json:"max_users"`
+	StorageMB   int64    `json:"storage_mb"`
+	Features    []string `json:"features,omitempty"`
+}
+
+var standardFeatures = []string{
+	"products", "orders", "categories", "inventory", "billing", "announcements", "statements", "reviews",
+	"chat", "promos", "referral", "memberships",
+}
+
+// Plans is the plan catalog. The trial has the standard features with small limits; only pro adds paid SMS.
+var Plans = map[string]Plan{
+	PlanTrial: {Code: PlanTrial, Name: "Trial", MaxProducts: 100, MaxUsers: 3, StorageMB: 1024, Features: standardFeatures},
+	PlanBasic: {Code: PlanBasic, Name: "Basic", MaxProducts: 500, MaxUsers: 5, StorageMB: 2048,
+		Features: []string{"products", "orders", "categories", "inventory", "billing", "announcements", "statements", "reviews"}},
+	PlanStandard: {Code: PlanStandard, Name: "Standard", MaxProducts: 5000, MaxUsers: 20, StorageMB: 10240, Features: standardFeatures},
+	PlanPro:      {Code: PlanPro, Name: "Pro"},
+}
+
+// Allows reports whether the plan includes the feature flag.
+func (p Plan) Allows(feature string) bool {
+	if p.Features == nil {
+		return true
+	}
+	for _, f := range p.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription is a pharmacy's plan with its limits, copied from the plan when it starts or is renewed, and its
+// expiry. Pharmacies without one (those created before subscriptions) are not limited.
+type Subscription struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID  uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"pharmacy_id"`
+	Plan        string     `gorm:"size:20;not null" json:"plan"`
+	MaxProducts int        `gorm:"not null;default:0" json:"max_products"`
+	MaxUsers    int        `gorm:"not null;default:0" json:"max_users"`
+	StorageMB   int64      `gorm:"not null;default:0" json:"storage_mb"`
+	ExpiresAt   time.Time  `gorm:"not null;index" json:"expires_at"`
+	RenewedAt   *time.Time `json:"renewed_at,omitempty"`
+	RenewedBy   *uuid.UUID `gorm:"type:uuid" json:"renewed_by,omitempty"`
+	Reference   string     `gorm:"size:100" json:"reference,omitempty"` // billing reference of the last renewal
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (Subscription) TableName() string { return "subscriptions" }
+
+func (s *Subscription) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// SetPlan copies the plan's limits onto the subscription.
+func (s *Subscription) SetPlan(p Plan) {
+	s.Plan, s.MaxProducts, s.MaxUsers, s.StorageMB = p.Code, p.MaxProducts, p.MaxUsers, p.StorageMB
+}
+
+func (s *Subscription) GraceEndsAt() time.Time { return s.ExpiresAt.Add(SubscriptionGracePeriod) }
+
+// State is SubscriptionActive, SubscriptionGrace or SubscriptionExpired at now.
+func (s *Subscription) State(now time.Time) string {
+	switch {
+	case !now.After(s.ExpiresAt):
+		return SubscriptionActive
+	case !now.After(s.GraceEndsAt()):
+		return SubscriptionGrace
+	}
+	return SubscriptionExpired
+}
+
+// Limit returns the subscription's limit (storage in bytes); 0 is unlimited.
+func (s *Subscription) Limit(limit string) int64 {
+	switch limit {
+	case PlanLimitProducts:
+		return int64(s.MaxProducts)
+	case PlanLimitUsers:
+		return int64(s.MaxUsers)
+	case PlanLimitStorage:
+		return s.StorageMB << 20
+	}
+	return 0
+}
+
+// SubscriptionUsage is what a pharmacy uses of its plan's limits.
+type SubscriptionUsage struct {
+	Products     int64 `json:"products"`
+	Users        int64 `json:"users"`
+	StorageBytes int64 `json:"storage_bytes"`
+}
+
+// Of returns the usage counted against the limit.
+func (u *SubscriptionUsage) Of(limit string) int64 {
+	switch limit {
+	case PlanLimitProducts:
+		return u.Products
+	case PlanLimitUsers:
+		return u.Users
+	case PlanLimitStorage:
+		return u.StorageBytes
+	}
+	return 0
+}
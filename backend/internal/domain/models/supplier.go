@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Supplier is a vendor the pharmacy buys stock from. Products map to a supplier via Product.SupplierID.
+type Supplier struct {
+	ID           uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID   uuid.UUID      `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	Name         string         `gorm:"size:255;not null" json:"name"`
+	ContactName  string         `gorm:"size:255" json:"contact_name"`
+	Email        string         `gorm:"size:255" json:"email"` // purchase requests are sent here
+	Phone        string         `gorm:"size:50" json:"phone"`
+	Address      string         `gorm:"type:text" json:"address"`
+	LeadTimeDays int            `gorm:"default:3" json:"lead_time_days"` // default expected delivery = today + lead time
+	IsActive     bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Supplier) TableName() string { return "suppliers" }
+
+func (s *Supplier) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package models
+
+import "strings"
+
+// Tax classes for products. Empty TaxClass on a product means TaxClassStandard.
+const (
+	TaxClassStandard = "standard"
+	TaxClassExempt   = "exempt"
+)
+
+// TaxRatesMap is tax class -> rate percent (e.g. {"standard": 13, "reduced": 5}). Stored as JSONB on PharmacyConfig.
+type TaxRatesMap map[string]float64
+
+// RateFor returns the percent for a tax class. Exempt is always 0; unknown classes fall back to standard.
+func (m TaxRatesMap) RateFor(class string) float64 {
+	class = NormalizeTaxClass(class)
+	if class == TaxClassExempt {
+		return 0
+	}
+	if r, ok := m[class]; ok {
+		return r
+	}
+	return m[TaxClassStandard]
+}
+
+// NormalizeTaxClass lowercases and trims a class name; empty becomes standard.
+func NormalizeTaxClass(class string) string {
+	class = strings.ToLower(strings.TrimSpace(class))
+	if class == "" {
+		return TaxClassStandard
+	}
+	return class
+}
+
+// TaxLine is one row of a tax breakdown (invoice or report), grouped by class and rate.
+type TaxLine struct {
+	TaxClass      string  `json:"tax_class"`
+	Rate          float64 `json:"rate"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	TaxAmount     float64 `json:"tax_amount"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// TenantUsage is a pharmacy with how much of the platform it uses, for platform admins.
+type TenantUsage struct {
+	Pharmacy     *Pharmacy  `json:"pharmacy"`
+	Orders       int64      `json:"orders"`
+	LastOrderAt  *time.Time `json:"last_order_at,omitempty"`
+	Users        int64      `json:"users"`         // active and inactive accounts, customers included
+	StorageBytes int64      `json:"storage_bytes"` // uploads counted against the storage quota
+}
+
+// ImpersonationTTL is how long a platform admin's impersonation token lasts; it cannot be refreshed.
+const ImpersonationTTL = 30 * time.Minute
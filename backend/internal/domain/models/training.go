@@ -0,0 +1,123 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Training item statuses reported per team member.
+const (
+	TrainingStatusPassed       = "passed"
+	TrainingStatusFailed       = "failed"
+	TrainingStatusAcknowledged = "acknowledged"
+	TrainingStatusNotStarted   = "not_started"
+)
+
+// SOPDocument is a standard operating procedure that team members read and acknowledge.
+type SOPDocument struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID uuid.UUID      `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	Title      string         `gorm:"size:255;not null" json:"title"`
+	Body       string         `gorm:"type:text" json:"body"`
+	FileURL    string         `gorm:"size:512" json:"file_url,omitempty"` // optional uploaded PDF/document
+	Version    string         `gorm:"size:50" json:"version,omitempty"`
+	IsActive   bool           `gorm:"default:true;index" json:"is_active"`
+	CreatedBy  uuid.UUID      `gorm:"type:uuid" json:"created_by"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (SOPDocument) TableName() string { return "sop_documents" }
+
+func (d *SOPDocument) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// SOPAck records that a user read (and, when a quiz is attached, passed the quiz for) an SOP document.
+type SOPAck struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	SOPDocumentID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_sop_ack_user" json:"sop_document_id"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_sop_ack_user" json:"user_id"`
+	AcknowledgedAt time.Time `gorm:"not null" json:"acknowledged_at"`
+}
+
+func (SOPAck) TableName() string { return "sop_acks" }
+
+func (a *SOPAck) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// QuizQuestion is a single-choice question. CorrectIndex is never shown to the person taking the quiz.
+type QuizQuestion struct {
+	Prompt       string   `json:"prompt"`
+	Options      []string `json:"options"`
+	CorrectIndex int      `json:"correct_index"`
+}
+
+// TrainingQuiz is a short quiz attached to exactly one announcement or SOP document. Team members must
+// pass it before the announcement/SOP counts as acknowledged.
+type TrainingQuiz struct {
+	ID             uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	AnnouncementID *uuid.UUID     `gorm:"type:uuid;index" json:"announcement_id,omitempty"`
+	SOPDocumentID  *uuid.UUID     `gorm:"type:uuid;index" json:"sop_document_id,omitempty"`
+	Title          string         `gorm:"size:255;not null" json:"title"`
+	Questions      []QuizQuestion `gorm:"type:jsonb;serializer:json" json:"questions"`
+	PassPercent    int            `gorm:"default:80" json:"pass_percent"`
+	IsActive       bool           `gorm:"default:true;index" json:"is_active"`
+	CreatedBy      uuid.UUID      `gorm:"type:uuid" json:"created_by"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (TrainingQuiz) TableName() string { return "training_quizzes" }
+
+func (q *TrainingQuiz) BeforeCreate(tx *gorm.DB) error {
+	if q.ID == uuid.Nil {
+		q.ID = uuid.New()
+	}
+	return nil
+}
+
+// TrainingAttempt is one submission of a quiz. Answers holds the chosen option index per question.
+type TrainingAttempt struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	QuizID       uuid.UUID `gorm:"type:uuid;not null;index:idx_training_attempt_user" json:"quiz_id"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index:idx_training_attempt_user" json:"user_id"`
+	PharmacyID   uuid.UUID `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	Answers      []int     `gorm:"type:jsonb;serializer:json" json:"answers"`
+	Correct      int       `json:"correct"`
+	Total        int       `json:"total"`
+	ScorePercent int       `json:"score_percent"`
+	Passed       bool      `gorm:"index" json:"passed"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (TrainingAttempt) TableName() string { return "training_attempts" }
+
+func (a *TrainingAttempt) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// TrainingAttemptStat aggregates a user's attempts on one quiz (compliance report row).
+type TrainingAttemptStat struct {
+	QuizID        uuid.UUID `json:"quiz_id"`
+	UserID        uuid.UUID `json:"user_id"`
+	Attempts      int       `json:"attempts"`
+	BestScore     int       `json:"best_score"`
+	Passed        bool      `json:"passed"`
+	LastAttemptAt time.Time `json:"last_attempt_at"`
+}
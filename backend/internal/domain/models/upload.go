@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Upload purposes decide which file types are accepted and how large a file may be.
+const (
+	UploadPurposeFile         = "file"         // photos and documents (the default)
+	UploadPurposeCV           = "cv"           // pharmacist CVs
+	UploadPurposeChatVideo    = "chat_video"   // videos sent in chat
+	UploadPurposeReturnVideo  = "return_video" // order return request evidence
+	UploadPurposePrescription = "prescription" // prescriptions sent by customers
+	UploadPurposeLicense      = "license"      // pharmacy license sent with a signup
+)
+
+// Upload statuses.
+const (
+	UploadStatusPending   = "pending" // chunks still arriving
+	UploadStatusCompleted = "completed"
+	UploadStatusRejected  = "rejected" // the content failed type sniffing or the virus scan
+	UploadStatusExpired   = "expired"  // abandoned before the last chunk
+)
+
+const (
+	// UploadChunkMaxSize bounds one chunk of a resumable upload (one request body).
+	UploadChunkMaxSize = 8 << 20
+	// UploadSessionTTL is how long a resumable upload may take before it expires and its chunks are dropped.
+	UploadSessionTTL = 24 * time.Hour
+)
+
+// UploadRule is what an upload purpose accepts: content types as sniffed from the file, not as declared.
+// Private files are kept out of public storage and only reachable through time-limited signed links.
+type UploadRule struct {
+	MaxSize int64
+	Types   []string
+	Private bool
+}
+
+var (
+	uploadImageTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp", "image/svg+xml"}
+	uploadDocTypes   = []string{"application/pdf", "application/msword", "application/vnd.openxmlformats-officedocument.wordprocessingml.document"}
+	uploadVideoTypes = []string{"video/mp4", "video/webm", "video/quicktime"}
+)
+
+// UploadRules maps each purpose to its rule.
+var UploadRules = map[string]UploadRule{
+	UploadPurposeFile:         {MaxSize: 10 << 20, Types: append(append([]string{}, uploadImageTypes...), uploadDocTypes...)},
+	UploadPurposeCV:           {MaxSize: 20 << 20, Types: uploadDocTypes, Private: true},
+	UploadPurposeChatVideo:    {MaxSize: 100 << 20, Types: append(append([]string{}, uploadVideoTypes...), uploadImageTypes...)},
+	UploadPurposeReturnVideo:  {MaxSize: 200 << 20, Types: append(append([]string{}, uploadVideoTypes...), uploadImageTypes...), Private: true},
+	UploadPurposePrescription: {MaxSize: 20 << 20, Types: []string{"image/jpeg", "image/png", "image/webp", "application/pdf"}, Private: true},
+	UploadPurposeLicense:      {MaxSize: 10 << 20, Types: []string{"image/jpeg", "image/png", "image/webp", "application/pdf"}, Private: true},
+}
+
+// Upload is a file sent by a pharmacy's staff or chat customers, in one request or in chunks. Pending and
+// completed uploads count against the pharmacy's storage quota.
+type Upload struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	UploadedBy  uuid.UUID  `gorm:"type:uuid;not null;index" json:"uploaded_by"` // user or chat customer
+	Purpose     string     `gorm:"size:30;not null" json:"purpose"`
+	Filename    string     `gorm:"size:255" json:"filename"`
+	ContentType string     `gorm:"size:120" json:"content_type"` // declared; replaced by the sniffed type on completion
+	Size        int64      `gorm:"not null" json:"size"`         // total bytes declared up front
+	Received    int64      `gorm:"not null;default:0" json:"received"`
+	Status      string     `gorm:"size:20;not null;index" json:"status"`
+	Path        string     `gorm:"size:512" json:"path,omitempty"`
+	URL         string     `gorm:"size:512" json:"url,omitempty"`         // public URL, or "private:<path>" for private files
+	PreviewURL  string     `gorm:"-" json:"preview_url,omitempty"`        // signed link to a private file, for display
+	ScanResult  string     `gorm:"size:255" json:"scan_result,omitempty"` // "clean", "not_scanned" or the detected signature
+	ExpiresAt   time.Time  `json:"expires_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (Upload) TableName() string { return "uploads" }
+
+func (u *Upload) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == uuid.Nil {
+		u.ID = uuid.New()
+	}
+	return nil
+}
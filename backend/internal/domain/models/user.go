@@ -14,8 +14,8 @@ type User struct {
 	Email         string         `gorm:"size:255;uniqueIndex;not null" json:"email"`
 	PasswordHash  string         `gorm:"size:255;not null" json:"-"`
 	Name          string         `gorm:"size:255" json:"name"`
-	Role          string         `gorm:"size:50;default:staff" json:"role"` // admin, manager, pharmacist, staff
-	PointsBalance int            `gorm:"default:0" json:"points_balance"`   // earned from completed sales (pharmacist/staff)
+	Role          string         `gorm:"size:50;default:staff" json:"role"`         // admin, manager, pharmacist, staff
+	PointsBalance int            `gorm:"default:0;<-:create" json:"points_balance"` // earned from completed sales; after create only StaffPointsTransactionRepository.Credit changes it
 	IsActive      bool           `gorm:"default:true" json:"is_active"`
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
@@ -30,6 +30,24 @@ type User struct {
 	Gender        string     `gorm:"size:50" json:"gender,omitempty"`
 	Phone         string     `gorm:"size:50" json:"phone,omitempty"`
 
+	// LicenseExpiresAt is the last day the license is valid (see LicenseDaysLeft); nil = not recorded.
+	LicenseExpiresAt *time.Time `json:"license_expires_at,omitempty"`
+	// LicenseReminderDays is the smallest reminder threshold already sent for the current expiry (0 = the expired
+	// notice); nil = none sent. Reset when the expiry changes.
+	LicenseReminderDays *int `json:"-"`
+
+	// PreferredLanguage picks the variant of announcements, notifications, emails and SMS; empty = pharmacy default.
+	PreferredLanguage string `gorm:"size:16" json:"preferred_language,omitempty"`
+	// BranchID is the branch a team member works at; their orders draw stock there. nil = all branches.
+	BranchID *uuid.UUID `gorm:"type:uuid;index" json:"branch_id,omitempty"`
+	// PasswordChangedAt is when the password was last set under the security policy; nil = since the account was created.
+	PasswordChangedAt *time.Time `json:"password_changed_at,omitempty"`
+	// PasswordHistory holds previous password hashes, newest first, as many as SecurityPolicy.ReuseCount needs.
+	PasswordHistory []string `gorm:"type:jsonb;serializer:json" json:"-"`
+	// PlatformAdmin users run the platform itself (approving pharmacy signups), whatever their pharmacy role. It is
+	// set in the database or by the seed, never through the API.
+	PlatformAdmin bool `gorm:"default:false" json:"platform_admin,omitempty"`
+
 	Pharmacy *Pharmacy `gorm:"foreignKey:PharmacyID" json:"pharmacy,omitempty"`
 }
 
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WalletTopUpStatus is where a wallet top-up is in its payment.
+type WalletTopUpStatus string
+
+const (
+	WalletTopUpPending   WalletTopUpStatus = "pending"   // waiting for the gateway payment to be confirmed
+	WalletTopUpCompleted WalletTopUpStatus = "completed" // paid; the wallet was credited
+	WalletTopUpFailed    WalletTopUpStatus = "failed"    // payment failed or was abandoned; nothing credited
+)
+
+// WalletTopUp is a customer adding money to their wallet through a payment gateway. The wallet is the customer's
+// store credit (Customer.StoreCreditBalance); it is only credited, with a top_up entry (EntryID), when the payment
+// is confirmed.
+type WalletTopUp struct {
+	ID               uuid.UUID         `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID       uuid.UUID         `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	CustomerID       uuid.UUID         `gorm:"type:uuid;not null;index" json:"customer_id"`
+	UserID           uuid.UUID         `gorm:"type:uuid;not null;index" json:"user_id"`
+	PaymentGatewayID uuid.UUID         `gorm:"type:uuid;not null" json:"payment_gateway_id"`
+	Amount           float64           `gorm:"type:decimal(12,2);not null" json:"amount"`
+	Currency         string            `gorm:"size:10;default:NPR" json:"currency"`
+	Status           WalletTopUpStatus `gorm:"size:20;not null;default:pending;index" json:"status"`
+	Reference        string            `gorm:"size:255" json:"reference,omitempty"` // gateway transaction id
+	FailureReason    string            `gorm:"type:text" json:"failure_reason,omitempty"`
+	EntryID          *uuid.UUID        `gorm:"type:uuid" json:"entry_id,omitempty"`
+	SettledBy        *uuid.UUID        `gorm:"type:uuid" json:"settled_by,omitempty"`
+	SettledAt        *time.Time        `json:"settled_at,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+
+	PaymentGateway *PaymentGateway `gorm:"foreignKey:PaymentGatewayID" json:"payment_gateway,omitempty"`
+}
+
+func (WalletTopUp) TableName() string { return "wallet_top_ups" }
+
+func (t *WalletTopUp) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
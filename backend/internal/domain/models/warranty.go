@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Warranty covers one serial sold on an order for the product's WarrantyMonths from the sale. Cancelling the
+// order voids it; the serial goes back in stock and a later sale registers a new warranty.
+type Warranty struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	ProductID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"product_id"`
+	SerialID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"serial_id"`
+	SerialNumber  string     `gorm:"size:100;not null;index" json:"serial_number"`
+	OrderID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"order_id"`
+	OrderItemID   uuid.UUID  `gorm:"type:uuid;not null" json:"order_item_id"`
+	CustomerID    *uuid.UUID `gorm:"type:uuid;index" json:"customer_id,omitempty"`
+	CustomerName  string     `gorm:"size:255" json:"customer_name"`
+	CustomerPhone string     `gorm:"size:50" json:"customer_phone"`
+	CustomerEmail string     `gorm:"size:255" json:"customer_email"`
+	StartsAt      time.Time  `gorm:"not null" json:"starts_at"`
+	ExpiresAt     time.Time  `gorm:"not null;index" json:"expires_at"`
+	VoidedAt      *time.Time `json:"voided_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	Product *Product         `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	Claims  []*WarrantyClaim `gorm:"foreignKey:WarrantyID" json:"claims,omitempty"`
+}
+
+func (Warranty) TableName() string { return "warranties" }
+
+func (w *Warranty) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+// StatusAt is derived: void when the sale was cancelled, expired after ExpiresAt, else active.
+func (w *Warranty) StatusAt(at time.Time) string {
+	switch {
+	case w.VoidedAt != nil:
+		return "void"
+	case !at.Before(w.ExpiresAt):
+		return "expired"
+	default:
+		return "active"
+	}
+}
+
+// WarrantyClaimStatus: a claim is taken in at the counter, optionally sent to the vendor, then resolved.
+type WarrantyClaimStatus string
+
+const (
+	WarrantyClaimIntake       WarrantyClaimStatus = "intake"
+	WarrantyClaimSentToVendor WarrantyClaimStatus = "sent_to_vendor"
+	WarrantyClaimResolved     WarrantyClaimStatus = "resolved"
+)
+
+// WarrantyResolution is how a resolved claim ended.
+type WarrantyResolution string
+
+const (
+	WarrantyRepaired WarrantyResolution = "repaired"
+	WarrantyReplaced WarrantyResolution = "replaced"
+	WarrantyRefunded WarrantyResolution = "refunded"
+	WarrantyRejected WarrantyResolution = "rejected"
+)
+
+// WarrantyClaim is a customer bringing a device back under warranty. A warranty has at most one unresolved claim.
+type WarrantyClaim struct {
+	ID              uuid.UUID           `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID      uuid.UUID           `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	WarrantyID      uuid.UUID           `gorm:"type:uuid;not null;index" json:"warranty_id"`
+	Status          WarrantyClaimStatus `gorm:"size:20;not null;default:intake;index" json:"status"`
+	Issue           string              `gorm:"type:text;not null" json:"issue"`
+	VendorReference string              `gorm:"size:100" json:"vendor_reference,omitempty"` // vendor's RMA or ticket number
+	Resolution      WarrantyResolution  `gorm:"size:20" json:"resolution,omitempty"`
+	Note            string              `gorm:"type:text" json:"note,omitempty"` // latest staff note, shown to the customer
+	CreatedBy       uuid.UUID           `gorm:"type:uuid;not null" json:"created_by"`
+	SentToVendorAt  *time.Time          `json:"sent_to_vendor_at,omitempty"`
+	ResolvedBy      *uuid.UUID          `gorm:"type:uuid" json:"resolved_by,omitempty"`
+	ResolvedAt      *time.Time          `json:"resolved_at,omitempty"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+
+	Warranty *Warranty `gorm:"foreignKey:WarrantyID" json:"warranty,omitempty"`
+}
+
+func (WarrantyClaim) TableName() string { return "warranty_claims" }
+
+func (c *WarrantyClaim) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WishlistItem is a product a user marked as a favorite. SeenInStock and SeenPrice are what the user was last
+// told (at add time or by the last alert); the wishlist scan notifies when the product comes back in stock or its
+// price drops below SeenPrice.
+type WishlistItem struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PharmacyID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"pharmacy_id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_wishlist_user_product" json:"user_id"`
+	ProductID   uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_wishlist_user_product;index" json:"product_id"`
+	AddedPrice  float64    `gorm:"type:decimal(12,2)" json:"added_price"`
+	SeenPrice   float64    `gorm:"type:decimal(12,2)" json:"-"`
+	SeenInStock bool       `json:"-"`
+	NotifiedAt  *time.Time `json:"notified_at,omitempty"` // last restock or price drop alert
+	CreatedAt   time.Time  `json:"created_at"`
+
+	Product *Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+func (WishlistItem) TableName() string { return "wishlist_items" }
+
+func (w *WishlistItem) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
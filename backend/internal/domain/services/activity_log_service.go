@@ -2,21 +2,34 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+// defaultActivityLogRetentionDays applies when a pharmacy has not set activity_log_retention_days.
+const defaultActivityLogRetentionDays = 365
+
+// activityLogExportMax caps one CSV export; larger audits are split by date range.
+const activityLogExportMax = 50000
+
 type activityLogService struct {
-	repo   outbound.ActivityLogRepository
-	logger *zap.Logger
+	repo         outbound.ActivityLogRepository
+	configRepo   outbound.PharmacyConfigRepository
+	pharmacyRepo outbound.PharmacyRepository
+	logger       *zap.Logger
+	now          func() time.Time
 }
 
-func NewActivityLogService(repo outbound.ActivityLogRepository, logger *zap.Logger) inbound.ActivityLogService {
-	return &activityLogService{repo: repo, logger: logger}
+func NewActivityLogService(repo outbound.ActivityLogRepository, configRepo outbound.PharmacyConfigRepository, pharmacyRepo outbound.PharmacyRepository, logger *zap.Logger) inbound.ActivityLogService {
+	return &activityLogService{repo: repo, configRepo: configRepo, pharmacyRepo: pharmacyRepo, logger: logger, now: time.Now}
 }
 
 func (s *activityLogService) Create(ctx context.Context, pharmacyID, userID uuid.UUID, action, description, entityType, entityID, details, ipAddress string) error {
@@ -37,6 +50,77 @@ func (s *activityLogService) Create(ctx context.Context, pharmacyID, userID uuid
 	return nil
 }
 
-func (s *activityLogService) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.ActivityLog, error) {
-	return s.repo.ListByPharmacy(ctx, pharmacyID, limit, offset)
+func activityLogFilter(q inbound.ActivityLogQuery) (outbound.ActivityLogFilter, error) {
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		return outbound.ActivityLogFilter{}, errors.ErrValidation("from must be before to")
+	}
+	return outbound.ActivityLogFilter{
+		UserID:     q.UserID,
+		Action:     strings.TrimSpace(q.Action),
+		EntityType: strings.TrimSpace(q.EntityType),
+		From:       q.From,
+		To:         q.To,
+	}, nil
+}
+
+func (s *activityLogService) List(ctx context.Context, pharmacyID uuid.UUID, q inbound.ActivityLogQuery) ([]*models.ActivityLog, error) {
+	filter, err := activityLogFilter(q)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.repo.ListByPharmacy(ctx, pharmacyID, filter, q.Limit, q.Offset)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list activity", err)
+	}
+	return list, nil
+}
+
+func (s *activityLogService) Export(ctx context.Context, pharmacyID uuid.UUID, q inbound.ActivityLogQuery) ([]*models.ActivityLog, error) {
+	filter, err := activityLogFilter(q)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.repo.ListForExport(ctx, pharmacyID, filter, activityLogExportMax+1)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to export activity", err)
+	}
+	if len(list) > activityLogExportMax {
+		return nil, errors.ErrValidation(fmt.Sprintf("more than %d entries match; narrow the date range", activityLogExportMax))
+	}
+	return list, nil
+}
+
+func (s *activityLogService) retentionDays(ctx context.Context, pharmacyID uuid.UUID) int {
+	if cfg, err := s.configRepo.GetByPharmacyID(ctx, pharmacyID); err == nil && cfg != nil && cfg.ActivityLogRetentionDays > 0 {
+		return cfg.ActivityLogRetentionDays
+	}
+	return defaultActivityLogRetentionDays
+}
+
+func (s *activityLogService) PurgeExpired(ctx context.Context) error {
+	pharmacies, err := s.pharmacyRepo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("list pharmacies: %w", err)
+	}
+	now := s.now()
+	failed := 0
+	for _, p := range pharmacies {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		cutoff := now.AddDate(0, 0, -s.retentionDays(ctx, p.ID))
+		n, err := s.repo.DeleteBefore(ctx, p.ID, cutoff)
+		if err != nil {
+			failed++
+			s.logger.Warn("activity log purge failed", zap.String("pharmacy_id", p.ID.String()), zap.Error(err))
+			continue
+		}
+		if n > 0 {
+			s.logger.Info("purged activity log", zap.String("pharmacy_id", p.ID.String()), zap.Int64("entries", n), zap.Time("before", cutoff))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("activity log purge failed for %d of %d pharmacies", failed, len(pharmacies))
+	}
+	return nil
 }
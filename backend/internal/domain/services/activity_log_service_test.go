@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestActivityLogService_List_PassesFilters(t *testing.T) {
+	userID := uuid.New()
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var got outbound.ActivityLogFilter
+	repo := &mocks.MockActivityLogRepository{
+		ListByPharmacyFunc: func(ctx context.Context, pharmacyID uuid.UUID, filter outbound.ActivityLogFilter, limit, offset int) ([]*models.ActivityLog, error) {
+			got = filter
+			return nil, nil
+		},
+	}
+	svc := &activityLogService{repo: repo, logger: zap.NewNop()}
+
+	if _, err := svc.List(context.Background(), uuid.New(), inbound.ActivityLogQuery{UserID: &userID, Action: " POST ", EntityType: "order", From: &from}); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got.UserID == nil || *got.UserID != userID || got.Action != "POST" || got.EntityType != "order" || got.From != &from {
+		t.Errorf("filter = %+v", got)
+	}
+	to := from
+	_, err := svc.List(context.Background(), uuid.New(), inbound.ActivityLogQuery{From: &from, To: &to})
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("err = %v, want validation error for an empty range", err)
+	}
+}
+
+func TestActivityLogService_Export_RejectsOversizedRange(t *testing.T) {
+	repo := &mocks.MockActivityLogRepository{
+		ListForExportFunc: func(ctx context.Context, pharmacyID uuid.UUID, filter outbound.ActivityLogFilter, limit int) ([]*models.ActivityLog, error) {
+			return make([]*models.ActivityLog, limit), nil
+		},
+	}
+	svc := &activityLogService{repo: repo, logger: zap.NewNop()}
+
+	_, err := svc.Export(context.Background(), uuid.New(), inbound.ActivityLogQuery{})
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("err = %v, want validation error when more than the export cap match", err)
+	}
+}
+
+func TestActivityLogService_PurgeExpired_UsesPharmacyRetention(t *testing.T) {
+	now := time.Date(2026, 6, 1, 3, 0, 0, 0, time.UTC)
+	custom, standard := uuid.New(), uuid.New()
+	cutoffs := map[uuid.UUID]time.Time{}
+	repo := &mocks.MockActivityLogRepository{
+		DeleteBeforeFunc: func(ctx context.Context, pharmacyID uuid.UUID, cutoff time.Time) (int64, error) {
+			cutoffs[pharmacyID] = cutoff
+			return 3, nil
+		},
+	}
+	configs := &mocks.MockPharmacyConfigRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, pharmacyID uuid.UUID) (*models.PharmacyConfig, error) {
+			if pharmacyID == custom {
+				return &models.PharmacyConfig{ActivityLogRetentionDays: 90}, nil
+			}
+			return &models.PharmacyConfig{}, nil
+		},
+	}
+	pharmacies := &mocks.MockPharmacyRepository{
+		ListFunc: func(ctx context.Context) ([]*models.Pharmacy, error) {
+			return []*models.Pharmacy{{ID: custom}, {ID: standard}}, nil
+		},
+	}
+	svc := &activityLogService{repo: repo, configRepo: configs, pharmacyRepo: pharmacies, logger: zap.NewNop(), now: func() time.Time { return now }}
+
+	if err := svc.PurgeExpired(context.Background()); err != nil {
+		t.Fatalf("PurgeExpired: %v", err)
+	}
+	if want := now.AddDate(0, 0, -90); !cutoffs[custom].Equal(want) {
+		t.Errorf("custom cutoff = %v, want %v", cutoffs[custom], want)
+	}
+	if want := now.AddDate(0, 0, -defaultActivityLogRetentionDays); !cutoffs[standard].Equal(want) {
+		t.Errorf("default cutoff = %v, want %v", cutoffs[standard], want)
+	}
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	productDescriptionSystemPrompt = "You write concise, accurate product descriptions for a pharmacy catalog. " +
+		"Use plain language, 2-3 short paragraphs, no dosage advice beyond what is given, no medical claims, " +
+		"and end with a reminder to consult a pharmacist or doctor when relevant."
+	blogOutlineSystemPrompt = "You draft blog outlines for a community pharmacy. " +
+		"Return a title line, a one-sentence summary, then 4-7 section headings each with 2-3 bullet points. " +
+		"Explain medical terms in simple language and avoid diagnosis or prescription advice."
+
+	// Free-text prompt input is capped so one request cannot run up the provider bill.
+	aiMaxFieldRunes = 200
+	aiMaxHighlights = 10
+)
+
+type aiContentService struct {
+	genRepo      outbound.AIGenerationRepository
+	productRepo  outbound.ProductRepository
+	blogService  inbound.BlogService
+	llm          outbound.LLMProvider // nil when LLM_PROVIDER=none
+	providerName string
+	monthlyQuota int
+	logger       *zap.Logger
+}
+
+func NewAIContentService(
+	genRepo outbound.AIGenerationRepository,
+	productRepo outbound.ProductRepository,
+	blogService inbound.BlogService,
+	llm outbound.LLMProvider,
+	providerName string,
+	monthlyQuota int,
+	logger *zap.Logger,
+) inbound.AIContentService {
+	return &aiContentService{
+		genRepo:      genRepo,
+		productRepo:  productRepo,
+		blogService:  blogService,
+		llm:          llm,
+		providerName: providerName,
+		monthlyQuota: monthlyQuota,
+		logger:       logger,
+	}
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// checkEnabled returns an error when the provider is disabled.
+func (s *aiContentService) checkEnabled() error {
+	if s.llm == nil {
+		return errors.ErrForbidden("AI content generation is not enabled")
+	}
+	return nil
+}
+
+// checkPromptField rejects free text longer than aiMaxFieldRunes.
+func checkPromptField(label, v string) error {
+	if len([]rune(v)) > aiMaxFieldRunes {
+		return errors.ErrValidation(fmt.Sprintf("%s must be at most %d characters", label, aiMaxFieldRunes))
+	}
+	return nil
+}
+
+// generate saves g as pending, which takes one generation of the pharmacy's monthly allowance, then calls the
+// provider. A failed call leaves the row failed, which gives the allowance back.
+func (s *aiContentService) generate(ctx context.Context, g *models.AIGeneration, systemPrompt, userPrompt string) (*models.AIGeneration, error) {
+	g.Status = models.AIGenerationStatusPending
+	g.Provider = s.providerName
+	ok, err := s.genRepo.Create(ctx, g, monthStart(time.Now()), s.monthlyQuota)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to save AI generation", err)
+	}
+	if !ok {
+		return nil, errors.ErrTooManyRequests(fmt.Sprintf("monthly AI generation quota reached (%d)", s.monthlyQuota))
+	}
+	out, err := s.llm.Complete(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		s.logger.Warn("llm completion failed", zap.String("kind", g.Kind), zap.String("pharmacy_id", g.PharmacyID.String()), zap.String("generation_id", g.ID.String()), zap.Error(err))
+		g.Status = models.AIGenerationStatusFailed
+		if uerr := s.genRepo.Update(ctx, g); uerr != nil {
+			s.logger.Error("failed to record failed AI generation", zap.String("generation_id", g.ID.String()), zap.Error(uerr))
+		}
+		return nil, errors.ErrInternal("AI generation failed", err)
+	}
+	g.Output = out.Text
+	g.Status = models.AIGenerationStatusDraft
+	g.Model = out.Model
+	g.PromptTokens = out.PromptTokens
+	g.CompletionTokens = out.CompletionTokens
+	if err := s.genRepo.Update(ctx, g); err != nil {
+		return nil, errors.ErrInternal("failed to save AI generation", err)
+	}
+	s.logger.Info("ai generation created",
+		zap.String("kind", g.Kind),
+		zap.String("pharmacy_id", g.PharmacyID.String()),
+		zap.String("requested_by", g.RequestedBy.String()),
+		zap.Int("prompt_tokens", g.PromptTokens),
+		zap.Int("completion_tokens", g.CompletionTokens),
+	)
+	return g, nil
+}
+
+// productDescriptionPrompt renders the structured fields as labelled lines; empty fields are skipped.
+func productDescriptionPrompt(in inbound.ProductDescriptionInput) string {
+	var b strings.Builder
+	add := func(label, v string) {
+		if v = strings.TrimSpace(v); v != "" {
+			b.WriteString(label + ": " + v + "\n")
+		}
+	}
+	add("Product name", in.Name)
+	add("Generic name", in.GenericName)
+	add("Brand", in.Brand)
+	add("Category", in.Category)
+	add("Dosage form", in.DosageForm)
+	add("Pack size", in.PackSize)
+	if len(in.Highlights) > 0 {
+		add("Highlights", strings.Join(in.Highlights, "; "))
+	}
+	lang := in.Language
+	if lang == "" {
+		lang = "en"
+	}
+	add("Write in language", lang)
+	return b.String()
+}
+
+func (s *aiContentService) GenerateProductDescription(ctx context.Context, pharmacyID, userID uuid.UUID, in inbound.ProductDescriptionInput) (*models.AIGeneration, error) {
+	if in.ProductID != nil {
+		p, err := s.productRepo.GetByID(ctx, *in.ProductID)
+		if err != nil || p == nil || p.PharmacyID != pharmacyID {
+			return nil, errors.ErrNotFound("product")
+		}
+		if in.Name == "" {
+			in.Name = p.Name
+		}
+		if in.GenericName == "" {
+			in.GenericName = p.GenericName
+		}
+		if in.Brand == "" {
+			in.Brand = p.Brand
+		}
+		if in.Category == "" {
+			in.Category = p.Category
+		}
+		if in.DosageForm == "" {
+			in.DosageForm = p.DosageForm
+		}
+		if in.PackSize == "" {
+			in.PackSize = p.PackSize
+		}
+	}
+	if strings.TrimSpace(in.Name) == "" {
+		return nil, errors.ErrValidation("name or product_id is required")
+	}
+	if len(in.Highlights) > aiMaxHighlights {
+		return nil, errors.ErrValidation(fmt.Sprintf("at most %d highlights", aiMaxHighlights))
+	}
+	for _, h := range in.Highlights {
+		if err := checkPromptField("each highlight", h); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkEnabled(); err != nil {
+		return nil, err
+	}
+	g := &models.AIGeneration{
+		PharmacyID:  pharmacyID,
+		RequestedBy: userID,
+		Kind:        models.AIGenerationKindProductDescription,
+		ProductID:   in.ProductID,
+		Input: map[string]interface{}{
+			"name":         in.Name,
+			"generic_name": in.GenericName,
+			"brand":        in.Brand,
+			"category":     in.Category,
+			"dosage_form":  in.DosageForm,
+			"pack_size":    in.PackSize,
+			"highlights":   in.Highlights,
+			"language":     in.Language,
+		},
+	}
+	return s.generate(ctx, g, productDescriptionSystemPrompt, productDescriptionPrompt(in))
+}
+
+func (s *aiContentService) GenerateBlogOutline(ctx context.Context, pharmacyID, userID uuid.UUID, topic, audience string) (*models.AIGeneration, error) {
+	topic = strings.TrimSpace(topic)
+	if topic == "" {
+		return nil, errors.ErrValidation("topic is required")
+	}
+	audience = strings.TrimSpace(audience)
+	if err := checkPromptField("topic", topic); err != nil {
+		return nil, err
+	}
+	if err := checkPromptField("audience", audience); err != nil {
+		return nil, err
+	}
+	if err := s.checkEnabled(); err != nil {
+		return nil, err
+	}
+	prompt := "Topic: " + topic + "\n"
+	if audience != "" {
+		prompt += "Audience: " + audience + "\n"
+	}
+	g := &models.AIGeneration{
+		PharmacyID:  pharmacyID,
+		RequestedBy: userID,
+		Kind:        models.AIGenerationKindBlogOutline,
+		Input:       map[string]interface{}{"topic": topic, "audience": audience},
+	}
+	return s.generate(ctx, g, blogOutlineSystemPrompt, prompt)
+}
+
+func (s *aiContentService) ListGenerations(ctx context.Context, pharmacyID uuid.UUID, kind, status *string, limit, offset int) ([]*models.AIGeneration, int64, error) {
+	return s.genRepo.ListByPharmacy(ctx, pharmacyID, kind, status, limit, offset)
+}
+
+func (s *aiContentService) GetGeneration(ctx context.Context, pharmacyID, id uuid.UUID) (*models.AIGeneration, error) {
+	g, err := s.genRepo.GetByID(ctx, id)
+	if err != nil || g == nil || g.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("AI generation")
+	}
+	return g, nil
+}
+
+func (s *aiContentService) Review(ctx context.Context, pharmacyID, reviewerID, id uuid.UUID, approve bool, editedOutput *string) (*models.AIGeneration, error) {
+	g, err := s.GetGeneration(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if g.Status != models.AIGenerationStatusDraft {
+		return nil, errors.ErrConflict("generation is " + g.Status + ", not a draft awaiting review")
+	}
+	if editedOutput != nil && strings.TrimSpace(*editedOutput) != "" {
+		g.Output = strings.TrimSpace(*editedOutput)
+	}
+	now := time.Now()
+	g.ReviewedBy = &reviewerID
+	g.ReviewedAt = &now
+	if !approve {
+		g.Status = models.AIGenerationStatusRejected
+		if err := s.genRepo.Update(ctx, g); err != nil {
+			return nil, errors.ErrInternal("failed to update AI generation", err)
+		}
+		return g, nil
+	}
+	switch g.Kind {
+	case models.AIGenerationKindProductDescription:
+		if g.ProductID != nil {
+			p, err := s.productRepo.GetByID(ctx, *g.ProductID)
+			if err != nil || p == nil || p.PharmacyID != pharmacyID {
+				return nil, errors.ErrNotFound("product")
+			}
+			p.Description = g.Output
+			if err := s.productRepo.Update(ctx, p); err != nil {
+				return nil, errors.ErrInternal("failed to update product description", err)
+			}
+		}
+	case models.AIGenerationKindBlogOutline:
+		title, _ := g.Input["topic"].(string)
+		post, err := s.blogService.CreatePost(ctx, pharmacyID, reviewerID, title, "", g.Output, nil, models.BlogPostStatusDraft, nil, nil)
+		if err != nil {
+			return nil, errors.ErrInternal("failed to create blog draft", err)
+		}
+		g.BlogPostID = &post.ID
+	}
+	g.Status = models.AIGenerationStatusApproved
+	if err := s.genRepo.Update(ctx, g); err != nil {
+		return nil, errors.ErrInternal("failed to update AI generation", err)
+	}
+	return g, nil
+}
+
+func (s *aiContentService) GetUsage(ctx context.Context, pharmacyID uuid.UUID) (*inbound.AIUsage, error) {
+	start := monthStart(time.Now())
+	used, err := s.genRepo.CountByPharmacySince(ctx, pharmacyID, start)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load AI usage", err)
+	}
+	return &inbound.AIUsage{Used: used, Quota: s.monthlyQuota, PeriodStart: start, Enabled: s.llm != nil}, nil
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakeLLM answers every Complete with a fixed outline, or with err when set.
+type fakeLLM struct {
+	calls int
+	err   error
+}
+
+func (f *fakeLLM) Complete(ctx context.Context, systemPrompt, userPrompt string) (*outbound.LLMCompletion, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &outbound.LLMCompletion{Text: "Staying hydrated\n- Why water matters", Model: "test-model", PromptTokens: 10, CompletionTokens: 20}, nil
+}
+
+// fakeBlogService records the status of the last post created.
+type fakeBlogService struct {
+	inbound.BlogService
+	status string
+}
+
+func (f *fakeBlogService) CreatePost(ctx context.Context, pharmacyID, authorID uuid.UUID, title, excerpt, body string, categoryID *uuid.UUID, status string, media []inbound.BlogPostMediaInput, tags []string) (*models.BlogPost, error) {
+	f.status = status
+	return &models.BlogPost{ID: uuid.New(), PharmacyID: pharmacyID, Title: title, Status: status}, nil
+}
+
+func TestAIContentService_GenerateBlogOutline_EnforcesMonthlyQuota(t *testing.T) {
+	pharmacyID := uuid.New()
+	repo := &mocks.MockAIGenerationRepository{}
+	llm := &fakeLLM{}
+	svc := NewAIContentService(repo, &mocks.MockProductRepository{}, nil, llm, "test", 2, zap.NewNop())
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		g, err := svc.GenerateBlogOutline(ctx, pharmacyID, uuid.New(), "Hydration in summer", "")
+		if err != nil || g.Status != models.AIGenerationStatusDraft {
+			t.Fatalf("generation %d = %+v, %v; want a draft", i+1, g, err)
+		}
+	}
+	_, err := svc.GenerateBlogOutline(ctx, pharmacyID, uuid.New(), "Hydration in summer", "")
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeTooManyRequests {
+		t.Fatalf("third generation err = %v, want quota reached", err)
+	}
+	if llm.calls != 2 {
+		t.Errorf("provider called %d times, want 2 (not for the request over quota)", llm.calls)
+	}
+	if _, err := svc.GenerateBlogOutline(ctx, uuid.New(), uuid.New(), "Hydration in summer", ""); err != nil {
+		t.Errorf("another pharmacy has its own quota: %v", err)
+	}
+	usage, _ := svc.GetUsage(ctx, pharmacyID)
+	if usage.Used != 2 || usage.Quota != 2 {
+		t.Errorf("usage = %+v, want 2 of 2", usage)
+	}
+}
+
+func TestAIContentService_GenerateBlogOutline_RecordsFailedCall(t *testing.T) {
+	pharmacyID := uuid.New()
+	repo := &mocks.MockAIGenerationRepository{}
+	llm := &fakeLLM{err: errors.New("provider timeout")}
+	svc := NewAIContentService(repo, &mocks.MockProductRepository{}, nil, llm, "test", 1, zap.NewNop())
+
+	if _, err := svc.GenerateBlogOutline(context.Background(), pharmacyID, uuid.New(), "Hydration in summer", ""); err == nil {
+		t.Fatal("want an error when the provider fails")
+	}
+	if len(repo.Generations) != 1 || repo.Generations[0].Status != models.AIGenerationStatusFailed {
+		t.Fatalf("generations = %+v, want one failed row", repo.Generations)
+	}
+	// The failed call gave its slot back.
+	llm.err = nil
+	if _, err := svc.GenerateBlogOutline(context.Background(), pharmacyID, uuid.New(), "Hydration in summer", ""); err != nil {
+		t.Errorf("retry after a failed call: %v", err)
+	}
+}
+
+func TestAIContentService_RejectsOversizedInput(t *testing.T) {
+	repo := &mocks.MockAIGenerationRepository{}
+	llm := &fakeLLM{}
+	svc := NewAIContentService(repo, &mocks.MockProductRepository{}, nil, llm, "test", 0, zap.NewNop())
+	ctx := context.Background()
+	long := strings.Repeat("x", aiMaxFieldRunes+1)
+
+	cases := []struct {
+		name string
+		run  func() error
+	}{
+		{"topic", func() error { _, err := svc.GenerateBlogOutline(ctx, uuid.New(), uuid.New(), long, ""); return err }},
+		{"audience", func() error { _, err := svc.GenerateBlogOutline(ctx, uuid.New(), uuid.New(), "Hydration", long); return err }},
+		{"highlight", func() error {
+			_, err := svc.GenerateProductDescription(ctx, uuid.New(), uuid.New(), inbound.ProductDescriptionInput{Name: "ORS", Highlights: []string{long}})
+			return err
+		}},
+		{"highlight count", func() error {
+			_, err := svc.GenerateProductDescription(ctx, uuid.New(), uuid.New(), inbound.ProductDescriptionInput{Name: "ORS", Highlights: make([]string, aiMaxHighlights+1)})
+			return err
+		}},
+	}
+	for _, tc := range cases {
+		if appErr := pkgerrors.GetAppError(tc.run()); appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation {
+			t.Errorf("%s: want validation error", tc.name)
+		}
+	}
+	if llm.calls != 0 || len(repo.Generations) != 0 {
+		t.Errorf("provider called %d times, %d rows saved; want none for rejected input", llm.calls, len(repo.Generations))
+	}
+}
+
+func TestAIContentService_Review_CreatesUnpublishedBlogDraft(t *testing.T) {
+	pharmacyID := uuid.New()
+	repo := &mocks.MockAIGenerationRepository{}
+	blog := &fakeBlogService{}
+	svc := NewAIContentService(repo, &mocks.MockProductRepository{}, blog, &fakeLLM{}, "test", 0, zap.NewNop())
+	ctx := context.Background()
+
+	g, err := svc.GenerateBlogOutline(ctx, pharmacyID, uuid.New(), "Hydration in summer", "parents")
+	if err != nil {
+		t.Fatalf("GenerateBlogOutline: %v", err)
+	}
+	reviewed, err := svc.Review(ctx, pharmacyID, uuid.New(), g.ID, true, nil)
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	if blog.status != models.BlogPostStatusDraft || reviewed.BlogPostID == nil || reviewed.Status != models.AIGenerationStatusApproved {
+		t.Errorf("post status = %q, generation = %+v; want an approved generation with a draft post", blog.status, reviewed)
+	}
+	if _, err := svc.Review(ctx, pharmacyID, uuid.New(), g.ID, true, nil); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeConflict {
+		t.Errorf("second review err = %v, want conflict", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
 	"github.com/google/uuid"
@@ -18,17 +19,26 @@ const skipAllDuration = 24 * time.Hour
 type announcementService struct {
 	announcementRepo outbound.AnnouncementRepository
 	ackRepo          outbound.AnnouncementAckRepository
+	trainingRepo     outbound.TrainingRepository
+	pushNotifier     inbound.PushNotificationService
+	userRepo         outbound.UserRepository
 	logger           *zap.Logger
 }
 
 func NewAnnouncementService(
 	announcementRepo outbound.AnnouncementRepository,
 	ackRepo outbound.AnnouncementAckRepository,
+	trainingRepo outbound.TrainingRepository,
+	pushNotifier inbound.PushNotificationService,
+	userRepo outbound.UserRepository,
 	logger *zap.Logger,
 ) *announcementService {
 	return &announcementService{
 		announcementRepo: announcementRepo,
 		ackRepo:          ackRepo,
+		trainingRepo:     trainingRepo,
+		pushNotifier:     pushNotifier,
+		userRepo:         userRepo,
 		logger:           logger,
 	}
 }
@@ -47,9 +57,15 @@ func (s *announcementService) Create(ctx context.Context, pharmacyID uuid.UUID,
 	if a.Template == "" {
 		a.Template = models.AnnouncementTemplateCelebration
 	}
+	if msg := models.ValidateTranslations(a.Translations); msg != "" {
+		return nil, pkgerrors.ErrValidation(msg)
+	}
 	if err := s.announcementRepo.Create(ctx, a); err != nil {
 		return nil, err
 	}
+	if s.pushNotifier != nil {
+		_ = s.pushNotifier.AnnouncementPublished(ctx, a)
+	}
 	return a, nil
 }
 
@@ -76,6 +92,9 @@ func (s *announcementService) Update(ctx context.Context, pharmacyID uuid.UUID,
 	if existing.PharmacyID != pharmacyID {
 		return nil, pkgerrors.ErrNotFound("announcement")
 	}
+	if msg := models.ValidateTranslations(a.Translations); msg != "" {
+		return nil, pkgerrors.ErrValidation(msg)
+	}
 	if a.DisplaySeconds >= models.AnnouncementDisplaySecMin && a.DisplaySeconds <= models.AnnouncementDisplaySecMax {
 		existing.DisplaySeconds = a.DisplaySeconds
 	}
@@ -86,6 +105,10 @@ func (s *announcementService) Update(ctx context.Context, pharmacyID uuid.UUID,
 	existing.Template = a.Template
 	existing.Title = a.Title
 	existing.Body = a.Body
+	existing.Translations = a.Translations
+	if a.ImageURL != existing.ImageURL {
+		existing.Images = nil // a hand-set URL replaces the uploaded renditions
+	}
 	existing.ImageURL = a.ImageURL
 	existing.LinkURL = a.LinkURL
 	existing.ShowTerms = a.ShowTerms
@@ -127,19 +150,27 @@ func effectiveEnd(a *models.Announcement) time.Time {
 	return validUntil
 }
 
-func (s *announcementService) ListActiveForUser(ctx context.Context, pharmacyID, userID uuid.UUID) ([]*models.Announcement, error) {
+func (s *announcementService) ListActiveForUser(ctx context.Context, pharmacyID, userID uuid.UUID, role string) ([]*models.Announcement, error) {
 	skipAllSince := time.Now().Add(-skipAllDuration)
 	skipped, err := s.ackRepo.HasSkippedAllSince(ctx, userID, skipAllSince)
 	if err != nil {
 		return nil, err
 	}
-	if skipped {
+	team := isTeamRole(role)
+	if skipped && !team {
 		return nil, nil
 	}
 	list, err := s.announcementRepo.ListByPharmacy(ctx, pharmacyID, true)
 	if err != nil {
 		return nil, err
 	}
+	// Users without a preference (or without a variant in it) see the pharmacy-default Title/Body.
+	var lang string
+	if s.userRepo != nil {
+		if u, err := s.userRepo.GetByID(ctx, userID); err == nil && u != nil {
+			lang = u.PreferredLanguage
+		}
+	}
 	now := time.Now()
 	var out []*models.Announcement
 	for _, a := range list {
@@ -153,12 +184,35 @@ func (s *announcementService) ListActiveForUser(ctx context.Context, pharmacyID,
 		if acked {
 			continue
 		}
+		if team {
+			quiz, err := s.pendingQuiz(ctx, a.ID, userID)
+			if err != nil {
+				continue
+			}
+			if quiz != nil {
+				a.QuizID = &quiz.ID
+			}
+		}
+		// Skip all hides announcements except required training.
+		if skipped && a.QuizID == nil {
+			continue
+		}
+		a.Localize(lang)
 		out = append(out, a)
 	}
 	return out, nil
 }
 
-func (s *announcementService) Acknowledge(ctx context.Context, userID, announcementID uuid.UUID, skipAll bool) error {
+func (s *announcementService) Acknowledge(ctx context.Context, userID, announcementID uuid.UUID, role string, skipAll bool) error {
+	if !skipAll && isTeamRole(role) {
+		quiz, err := s.pendingQuiz(ctx, announcementID, userID)
+		if err != nil {
+			return err
+		}
+		if quiz != nil {
+			return pkgerrors.ErrValidation("pass the training quiz to acknowledge this announcement")
+		}
+	}
 	ack := &models.AnnouncementAck{
 		UserID:         userID,
 		AcknowledgedAt: time.Now(),
@@ -171,3 +225,19 @@ func (s *announcementService) Acknowledge(ctx context.Context, userID, announcem
 	}
 	return s.ackRepo.Create(ctx, ack)
 }
+
+// pendingQuiz returns the announcement's active training quiz when the user has not passed it yet.
+func (s *announcementService) pendingQuiz(ctx context.Context, announcementID, userID uuid.UUID) (*models.TrainingQuiz, error) {
+	if s.trainingRepo == nil {
+		return nil, nil
+	}
+	quiz, err := s.trainingRepo.GetActiveQuizFor(ctx, &announcementID, nil)
+	if err != nil || quiz == nil {
+		return nil, err
+	}
+	passed, err := s.trainingRepo.HasPassed(ctx, quiz.ID, userID)
+	if err != nil || passed {
+		return nil, err
+	}
+	return quiz, nil
+}
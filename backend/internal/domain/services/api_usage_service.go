@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	apiUsageMaxDays      = 366
+	apiUsageTopEndpoints = 10
+)
+
+type apiUsageKey struct {
+	pharmacyID    uuid.UUID
+	day           string
+	method, route string
+}
+
+type apiUsageService struct {
+	repo    outbound.APIUsageRepository
+	mu      sync.Mutex
+	pending map[apiUsageKey]*models.APIUsageDaily
+	now     func() time.Time
+	logger  *zap.Logger
+}
+
+func NewAPIUsageService(repo outbound.APIUsageRepository, logger *zap.Logger) inbound.APIUsageService {
+	return &apiUsageService{repo: repo, pending: make(map[apiUsageKey]*models.APIUsageDaily), now: time.Now, logger: logger}
+}
+
+func (s *apiUsageService) Record(pharmacyID uuid.UUID, method, route string, status int, latency time.Duration) {
+	day := statDay(s.now())
+	ms := latency.Milliseconds()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := apiUsageKey{pharmacyID: pharmacyID, day: day.Format("2006-01-02"), method: method, route: route}
+	row := s.pending[k]
+	if row == nil {
+		row = &models.APIUsageDaily{PharmacyID: pharmacyID, Day: day, Method: method, Route: route}
+		s.pending[k] = row
+	}
+	row.Requests++
+	switch {
+	case status >= 500:
+		row.ServerErrors++
+	case status >= 400:
+		row.ClientErrors++
+	}
+	row.LatencyMsTotal += ms
+	if ms > row.LatencyMsMax {
+		row.LatencyMsMax = ms
+	}
+}
+
+// Flush writes the pending counters. When the write fails they are merged back, so the next run retries them.
+func (s *apiUsageService) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = make(map[apiUsageKey]*models.APIUsageDaily)
+	s.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+	rows := make([]*models.APIUsageDaily, 0, len(batch))
+	for _, row := range batch {
+		rows = append(rows, row)
+	}
+	if err := s.repo.Add(ctx, rows); err != nil {
+		s.mu.Lock()
+		for k, row := range batch {
+			if cur := s.pending[k]; cur != nil {
+				row.Requests += cur.Requests
+				row.ClientErrors += cur.ClientErrors
+				row.ServerErrors += cur.ServerErrors
+				row.LatencyMsTotal += cur.LatencyMsTotal
+				row.LatencyMsMax = max(row.LatencyMsMax, cur.LatencyMsMax)
+			}
+			row.ID = uuid.Nil
+			s.pending[k] = row
+		}
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (s *apiUsageService) Report(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) (*inbound.APIUsageReport, error) {
+	from, to = statDay(from), statDay(to)
+	if to.Before(from) {
+		return nil, errors.ErrValidation("to must not be before from")
+	}
+	if to.Sub(from) > apiUsageMaxDays*24*time.Hour {
+		return nil, errors.ErrValidation("range must be at most a year")
+	}
+	rows, err := s.repo.List(ctx, pharmacyID, from, to)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load API usage", err)
+	}
+	report := &inbound.APIUsageReport{
+		From:         from.Format("2006-01-02"),
+		To:           to.Format("2006-01-02"),
+		ByDay:        []*inbound.APIUsageRow{},
+		TopEndpoints: []*inbound.APIUsageRow{},
+	}
+	days := map[string]*inbound.APIUsageRow{}
+	endpoints := map[string]*inbound.APIUsageRow{}
+	for _, r := range rows {
+		addUsage(&report.Totals, r)
+		day := r.Day.Format("2006-01-02")
+		d := days[day]
+		if d == nil {
+			d = &inbound.APIUsageRow{Day: day}
+			days[day] = d
+			report.ByDay = append(report.ByDay, d)
+		}
+		addUsage(d, r)
+		ep := endpoints[r.Method+" "+r.Route]
+		if ep == nil {
+			ep = &inbound.APIUsageRow{Method: r.Method, Route: r.Route}
+			endpoints[r.Method+" "+r.Route] = ep
+			report.TopEndpoints = append(report.TopEndpoints, ep)
+		}
+		addUsage(ep, r)
+	}
+	sort.Slice(report.ByDay, func(i, j int) bool { return report.ByDay[i].Day < report.ByDay[j].Day })
+	sort.Slice(report.TopEndpoints, func(i, j int) bool {
+		a, b := report.TopEndpoints[i], report.TopEndpoints[j]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return a.Route+a.Method < b.Route+b.Method
+	})
+	if len(report.TopEndpoints) > apiUsageTopEndpoints {
+		report.TopEndpoints = report.TopEndpoints[:apiUsageTopEndpoints]
+	}
+	finishUsage(&report.Totals)
+	for _, d := range report.ByDay {
+		finishUsage(d)
+	}
+	for _, ep := range report.TopEndpoints {
+		finishUsage(ep)
+	}
+	return report, nil
+}
+
+// addUsage adds a stored row to a report line; finishUsage then turns the latency total into an average.
+func addUsage(line *inbound.APIUsageRow, r *models.APIUsageDaily) {
+	line.Requests += r.Requests
+	line.ClientErrors += r.ClientErrors
+	line.ServerErrors += r.ServerErrors
+	line.AvgLatencyMs += float64(r.LatencyMsTotal)
+	line.MaxLatencyMs = max(line.MaxLatencyMs, r.LatencyMsMax)
+}
+
+func finishUsage(line *inbound.APIUsageRow) {
+	if line.Requests == 0 {
+		line.AvgLatencyMs = 0
+		return
+	}
+	n := float64(line.Requests)
+	line.AvgLatencyMs = math.Round(line.AvgLatencyMs/n*10) / 10
+	line.ErrorRate = float64(line.ClientErrors+line.ServerErrors) / n
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestAPIUsageService_FlushAggregatesAndRetries(t *testing.T) {
+	var written []*models.APIUsageDaily
+	fail := true
+	repo := &mocks.MockAPIUsageRepository{
+		AddFunc: func(ctx context.Context, rows []*models.APIUsageDaily) error {
+			if fail {
+				return errors.New("db down")
+			}
+			written = append(written, rows...)
+			return nil
+		},
+	}
+	svc := NewAPIUsageService(repo, zap.NewNop())
+	pharmacyID := uuid.New()
+	svc.Record(pharmacyID, "GET", "/api/v1/orders", 200, 40*time.Millisecond)
+	svc.Record(pharmacyID, "GET", "/api/v1/orders", 404, 10*time.Millisecond)
+	if err := svc.Flush(context.Background()); err == nil {
+		t.Fatal("expected the failed write to be reported")
+	}
+	svc.Record(pharmacyID, "GET", "/api/v1/orders", 500, 90*time.Millisecond)
+	svc.Record(pharmacyID, "POST", "/api/v1/orders", 201, 120*time.Millisecond)
+
+	fail = false
+	if err := svc.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if len(written) != 2 {
+		t.Fatalf("expected 2 rows (GET and POST), got %d", len(written))
+	}
+	for _, r := range written {
+		if r.Method != "GET" {
+			continue
+		}
+		if r.Requests != 3 || r.ClientErrors != 1 || r.ServerErrors != 1 || r.LatencyMsTotal != 140 || r.LatencyMsMax != 90 {
+			t.Errorf("unexpected GET row after retry %+v", r)
+		}
+	}
+	written = nil
+	if err := svc.Flush(context.Background()); err != nil || len(written) != 0 {
+		t.Errorf("expected nothing left to flush, got %d rows (%v)", len(written), err)
+	}
+}
+
+func TestAPIUsageService_Report(t *testing.T) {
+	day1 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	repo := &mocks.MockAPIUsageRepository{
+		ListFunc: func(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.APIUsageDaily, error) {
+			return []*models.APIUsageDaily{
+				{Day: day1, Method: "GET", Route: "/api/v1/products", Requests: 8, ClientErrors: 1, LatencyMsTotal: 400, LatencyMsMax: 120},
+				{Day: day1, Method: "POST", Route: "/api/v1/orders", Requests: 2, ServerErrors: 1, LatencyMsTotal: 600, LatencyMsMax: 500},
+				{Day: day2, Method: "GET", Route: "/api/v1/products", Requests: 10, LatencyMsTotal: 300, LatencyMsMax: 60},
+			}, nil
+		},
+	}
+	svc := NewAPIUsageService(repo, zap.NewNop())
+	r, err := svc.Report(context.Background(), uuid.New(), day1, day2)
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if r.Totals.Requests != 20 || r.Totals.ErrorRate != 0.1 || r.Totals.AvgLatencyMs != 65 || r.Totals.MaxLatencyMs != 500 {
+		t.Errorf("unexpected totals %+v", r.Totals)
+	}
+	if len(r.ByDay) != 2 || r.ByDay[0].Day != "2026-03-01" || r.ByDay[0].Requests != 10 || r.ByDay[0].ErrorRate != 0.2 {
+		t.Errorf("unexpected days %+v", r.ByDay)
+	}
+	if len(r.TopEndpoints) != 2 || r.TopEndpoints[0].Route != "/api/v1/products" || r.TopEndpoints[0].Requests != 18 || r.TopEndpoints[1].AvgLatencyMs != 300 {
+		t.Errorf("unexpected endpoints %+v %+v", r.TopEndpoints[0], r.TopEndpoints[1])
+	}
+
+	if _, err := svc.Report(context.Background(), uuid.New(), day2, day1); err == nil {
+		t.Error("expected to before from to be rejected")
+	}
+	if _, err := svc.Report(context.Background(), uuid.New(), day1, day1.AddDate(2, 0, 0)); err == nil {
+		t.Error("expected a range over a year to be rejected")
+	}
+}
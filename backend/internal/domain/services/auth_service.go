@@ -2,6 +2,13 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
@@ -11,19 +18,50 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	passwordResetTTL     = time.Hour
+	passwordResetPerHour = 3
+	loginCodeTTL         = 10 * time.Minute
+	loginCodesPerHour    = 5
+	loginCodeMaxAttempts = 5
+)
+
 type authService struct {
-	userRepo     outbound.UserRepository
-	pharmacyRepo outbound.PharmacyRepository
-	authProvider outbound.AuthProvider
-	logger       *zap.Logger
+	userRepo       outbound.UserRepository
+	pharmacyRepo   outbound.PharmacyRepository
+	authProvider   outbound.AuthProvider
+	resetTokenRepo outbound.PasswordResetTokenRepository
+	configRepo     outbound.PharmacyConfigRepository
+	challengeRepo  outbound.LoginChallengeRepository
+	mailer         inbound.MailerService
+	smsNotifier    inbound.SMSNotificationService
+	publicURL      string
+	uow            outbound.UnitOfWork
+	logger         *zap.Logger
 }
 
-func NewAuthService(userRepo outbound.UserRepository, pharmacyRepo outbound.PharmacyRepository, authProvider outbound.AuthProvider, logger *zap.Logger) inbound.AuthService {
-	return &authService{userRepo: userRepo, pharmacyRepo: pharmacyRepo, authProvider: authProvider, logger: logger}
+// NewAuthService builds the auth service. publicURL is the web app base used for password reset links; configRepo
+// supplies each pharmacy's security policy and challengeRepo holds pending two-factor logins. uow runs a password
+// reset's token claim and password update together.
+func NewAuthService(userRepo outbound.UserRepository, pharmacyRepo outbound.PharmacyRepository, authProvider outbound.AuthProvider, resetTokenRepo outbound.PasswordResetTokenRepository, configRepo outbound.PharmacyConfigRepository, challengeRepo outbound.LoginChallengeRepository, mailer inbound.MailerService, smsNotifier inbound.SMSNotificationService, publicURL string, uow outbound.UnitOfWork, logger *zap.Logger) inbound.AuthService {
+	return &authService{
+		userRepo:       userRepo,
+		pharmacyRepo:   pharmacyRepo,
+		authProvider:   authProvider,
+		resetTokenRepo: resetTokenRepo,
+		configRepo:     configRepo,
+		challengeRepo:  challengeRepo,
+		mailer:         mailer,
+		smsNotifier:    smsNotifier,
+		publicURL:      strings.TrimSuffix(publicURL, "/"),
+		uow:            uow,
+		logger:         logger,
+	}
 }
 
 func (s *authService) Register(ctx context.Context, pharmacyID uuid.UUID, email, password, name, role string) (*models.User, error) {
-	_, err := s.userRepo.GetByEmail(ctx, email)
+	// Emails are unique across pharmacies, so the check ignores the tenant scope.
+	_, err := s.userRepo.GetByEmail(outbound.WithoutTenant(ctx), email)
 	if err == nil {
 		return nil, errors.ErrConflict("email already registered")
 	}
@@ -38,12 +76,15 @@ func (s *authService) Register(ctx context.Context, pharmacyID uuid.UUID, email,
 		Role:       role,
 		IsActive:   true,
 	}
-	if err := u.SetPassword(password); err != nil {
-		return nil, errors.ErrInternal("failed to hash password", err)
+	if err := setPassword(securityPolicy(ctx, s.configRepo, pharmacyID), u, password, time.Now()); err != nil {
+		return nil, err
 	}
 	if err := s.userRepo.Create(ctx, u); err != nil {
 		return nil, errors.ErrInternal("failed to create user", err)
 	}
+	if s.mailer != nil {
+		_ = s.mailer.StaffAccountCreated(ctx, u)
+	}
 	return u, nil
 }
 
@@ -55,9 +96,26 @@ func (s *authService) Login(ctx context.Context, email, password string) (access
 	if !u.CheckPassword(password) {
 		return "", "", nil, errors.ErrInvalidCredentials()
 	}
+	if u.Pharmacy != nil && u.Pharmacy.Status == models.PharmacyStatusPending {
+		return "", "", nil, errors.ErrForbidden("pharmacy signup is awaiting approval")
+	}
+	if u.Pharmacy != nil && u.Pharmacy.Status == models.PharmacyStatusSuspended && !u.PlatformAdmin {
+		return "", "", nil, errors.ErrForbidden("pharmacy is suspended")
+	}
 	if !u.IsActive {
 		return "", "", nil, errors.ErrForbidden("account is inactive")
 	}
+	policy := securityPolicy(ctx, s.configRepo, u.PharmacyID)
+	if policy.PasswordExpired(u, time.Now()) {
+		return "", "", nil, errors.New(errors.ErrCodePasswordExpired, "password has expired; choose a new one")
+	}
+	if policy.RequiresTwoFactor(u.Role) {
+		return "", "", nil, s.startLoginChallenge(ctx, u)
+	}
+	return s.issueTokens(u)
+}
+
+func (s *authService) issueTokens(u *models.User) (accessToken, refreshToken string, user *models.User, err error) {
 	accessToken, err = s.authProvider.GenerateAccessToken(u.ID, u.PharmacyID, u.Role)
 	if err != nil {
 		return "", "", nil, errors.ErrInternal("failed to generate token", err)
@@ -69,8 +127,111 @@ func (s *authService) Login(ctx context.Context, email, password string) (access
 	return accessToken, refreshToken, u, nil
 }
 
+// startLoginChallenge emails a sign-in code and returns the ErrCodeTwoFactorRequired error carrying the
+// challenge token the code must be sent back with.
+func (s *authService) startLoginChallenge(ctx context.Context, u *models.User) error {
+	if s.challengeRepo == nil || s.mailer == nil {
+		return errors.ErrInternal("two-factor sign-in is not available", nil)
+	}
+	now := time.Now()
+	recent, err := s.challengeRepo.CountSince(ctx, u.ID, now.Add(-time.Hour))
+	if err != nil {
+		return errors.ErrInternal("failed to check sign-in codes", err)
+	}
+	if recent >= loginCodesPerHour {
+		return errors.ErrTooManyRequests("too many sign-in codes requested, try again later")
+	}
+	token, err := newResetToken()
+	if err != nil {
+		return errors.ErrInternal("failed to generate challenge", err)
+	}
+	code, err := randomOTP()
+	if err != nil {
+		return errors.ErrInternal("failed to generate sign-in code", err)
+	}
+	c := &models.LoginChallenge{ID: uuid.New(), UserID: u.ID, TokenHash: hashResetToken(token), ExpiresAt: now.Add(loginCodeTTL)}
+	c.CodeHash = hashOTP(c.ID, code)
+	if err := s.challengeRepo.Create(ctx, c); err != nil {
+		return errors.ErrInternal("failed to save challenge", err)
+	}
+	if err := s.mailer.LoginCode(ctx, u, code, c.ExpiresAt); err != nil {
+		return errors.ErrInternal("failed to send sign-in code", err)
+	}
+	return &errors.AppError{
+		Code:    errors.ErrCodeTwoFactorRequired,
+		Message: "a sign-in code was sent to your email",
+		Details: map[string]interface{}{"challenge_token": token, "expires_at": c.ExpiresAt},
+	}
+}
+
+func (s *authService) VerifyLoginCode(ctx context.Context, challengeToken, code string) (accessToken, refreshToken string, user *models.User, err error) {
+	c, err := s.challengeRepo.GetByTokenHash(ctx, hashResetToken(strings.TrimSpace(challengeToken)))
+	if err != nil {
+		return "", "", nil, errors.ErrInternal("failed to load challenge", err)
+	}
+	if c == nil || c.UsedAt != nil || time.Now().After(c.ExpiresAt) {
+		return "", "", nil, errors.ErrValidation("code is invalid or has expired")
+	}
+	if c.Attempts >= loginCodeMaxAttempts {
+		return "", "", nil, errors.ErrTooManyRequests("too many attempts, sign in again for a new code")
+	}
+	given := hashOTP(c.ID, strings.TrimSpace(code))
+	if subtle.ConstantTimeCompare([]byte(given), []byte(c.CodeHash)) != 1 {
+		c.Attempts++
+		if err := s.challengeRepo.Update(ctx, c); err != nil {
+			return "", "", nil, errors.ErrInternal("failed to update challenge", err)
+		}
+		return "", "", nil, errors.ErrValidation("code is invalid or has expired")
+	}
+	now := time.Now()
+	c.UsedAt = &now
+	if err := s.challengeRepo.Update(ctx, c); err != nil {
+		return "", "", nil, errors.ErrInternal("failed to update challenge", err)
+	}
+	u, err := s.userRepo.GetByID(ctx, c.UserID)
+	if err != nil || u == nil {
+		return "", "", nil, errors.ErrValidation("code is invalid or has expired")
+	}
+	if !u.IsActive {
+		return "", "", nil, errors.ErrForbidden("account is inactive")
+	}
+	return s.issueTokens(u)
+}
+
+func (s *authService) ChangeExpiredPassword(ctx context.Context, email, currentPassword, newPassword string) (accessToken, refreshToken string, user *models.User, err error) {
+	u, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil || u == nil || !u.CheckPassword(currentPassword) {
+		return "", "", nil, errors.ErrInvalidCredentials()
+	}
+	if !u.IsActive {
+		return "", "", nil, errors.ErrForbidden("account is inactive")
+	}
+	if err := setPassword(securityPolicy(ctx, s.configRepo, u.PharmacyID), u, newPassword, time.Now()); err != nil {
+		return "", "", nil, err
+	}
+	if err := s.userRepo.Update(ctx, u); err != nil {
+		return "", "", nil, errors.ErrInternal("failed to update password", err)
+	}
+	return s.Login(ctx, email, newPassword)
+}
+
+func (s *authService) PasswordPolicy(ctx context.Context, pharmacyID uuid.UUID, password string) (*inbound.PasswordPolicyCheck, error) {
+	pharmacy, err := s.pharmacyRepo.GetByID(ctx, pharmacyID)
+	if err != nil || pharmacy == nil {
+		return nil, errors.ErrNotFound("pharmacy")
+	}
+	policy := securityPolicy(ctx, s.configRepo, pharmacyID)
+	out := &inbound.PasswordPolicyCheck{Requirements: policy.PasswordRequirements(password), Valid: true, ExpiryDays: policy.ExpiryDays, ReuseCount: policy.ReuseCount}
+	for _, r := range out.Requirements {
+		out.Valid = out.Valid && r.Met
+	}
+	return out, nil
+}
+
+// RefreshToken issues a new access token while the login the refresh token came from is within the security
+// policy's session lifetime.
 func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (string, error) {
-	userID, err := s.authProvider.ValidateRefreshToken(refreshToken)
+	userID, issuedAt, err := s.authProvider.ValidateRefreshToken(refreshToken)
 	if err != nil {
 		return "", errors.ErrUnauthorized("invalid refresh token")
 	}
@@ -78,6 +239,12 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (st
 	if err != nil || u == nil || !u.IsActive {
 		return "", errors.ErrUnauthorized("user not found or inactive")
 	}
+	if u.Pharmacy != nil && u.Pharmacy.Status == models.PharmacyStatusSuspended && !u.PlatformAdmin {
+		return "", errors.ErrForbidden("pharmacy is suspended")
+	}
+	if hours := securityPolicy(ctx, s.configRepo, u.PharmacyID).SessionHours; hours > 0 && time.Since(issuedAt) > time.Duration(hours)*time.Hour {
+		return "", errors.ErrUnauthorized("session has expired, sign in again")
+	}
 	return s.authProvider.GenerateAccessToken(u.ID, u.PharmacyID, u.Role)
 }
 
@@ -85,7 +252,7 @@ func (s *authService) GetCurrentUser(ctx context.Context, userID uuid.UUID) (*mo
 	return s.userRepo.GetByID(ctx, userID)
 }
 
-func (s *authService) UpdateProfile(ctx context.Context, userID uuid.UUID, name string, phone *string, photoURL *string) (*models.User, error) {
+func (s *authService) UpdateProfile(ctx context.Context, userID uuid.UUID, name string, phone *string, photoURL *string, preferredLanguage *string) (*models.User, error) {
 	u, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil || u == nil {
 		return nil, errors.ErrNotFound("user")
@@ -100,6 +267,12 @@ func (s *authService) UpdateProfile(ctx context.Context, userID uuid.UUID, name
 	if photoURL != nil {
 		u.PhotoURL = *photoURL
 	}
+	if preferredLanguage != nil {
+		if _, ok := models.SupportedLanguages[*preferredLanguage]; !ok && *preferredLanguage != "" {
+			return nil, errors.ErrValidation("unsupported language; use one of " + strings.Join(sortedLanguageCodes(), ", "))
+		}
+		u.PreferredLanguage = *preferredLanguage
+	}
 	if err := s.userRepo.Update(ctx, u); err != nil {
 		return nil, errors.ErrInternal("failed to update profile", err)
 	}
@@ -117,11 +290,96 @@ func (s *authService) ChangePassword(ctx context.Context, userID uuid.UUID, curr
 	if !u.CheckPassword(currentPassword) {
 		return errors.ErrInvalidCredentials()
 	}
-	if err := u.SetPassword(newPassword); err != nil {
-		return errors.ErrInternal("failed to hash password", err)
+	if err := setPassword(securityPolicy(ctx, s.configRepo, u.PharmacyID), u, newPassword, time.Now()); err != nil {
+		return err
 	}
 	if err := s.userRepo.Update(ctx, u); err != nil {
 		return errors.ErrInternal("failed to update password", err)
 	}
 	return nil
 }
+
+func (s *authService) ForgotPassword(ctx context.Context, email string) error {
+	u, err := s.userRepo.GetByEmail(ctx, strings.TrimSpace(email))
+	if err != nil || u == nil || !u.IsActive {
+		return nil
+	}
+	now := time.Now()
+	recent, err := s.resetTokenRepo.CountSince(ctx, u.ID, now.Add(-time.Hour))
+	if err != nil {
+		return errors.ErrInternal("failed to check reset requests", err)
+	}
+	if recent >= passwordResetPerHour {
+		s.logger.Info("password reset throttled", zap.String("user_id", u.ID.String()))
+		return nil
+	}
+	token, err := newResetToken()
+	if err != nil {
+		return errors.ErrInternal("failed to generate reset token", err)
+	}
+	t := &models.PasswordResetToken{UserID: u.ID, TokenHash: hashResetToken(token), ExpiresAt: now.Add(passwordResetTTL)}
+	if err := s.resetTokenRepo.Create(ctx, t); err != nil {
+		return errors.ErrInternal("failed to save reset token", err)
+	}
+	resetURL := s.publicURL + "/reset-password?token=" + url.QueryEscape(token)
+	if s.mailer != nil {
+		_ = s.mailer.PasswordReset(ctx, u, resetURL, t.ExpiresAt)
+	}
+	if s.smsNotifier != nil {
+		_ = s.smsNotifier.PasswordReset(ctx, u, resetURL)
+	}
+	return nil
+}
+
+func (s *authService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	t, err := s.resetTokenRepo.GetByTokenHash(ctx, hashResetToken(strings.TrimSpace(token)))
+	if err != nil {
+		return errors.ErrInternal("failed to load reset token", err)
+	}
+	if t == nil || t.UsedAt != nil || time.Now().After(t.ExpiresAt) {
+		return errors.ErrValidation("reset link is invalid or has expired")
+	}
+	u, err := s.userRepo.GetByID(ctx, t.UserID)
+	if err != nil || u == nil {
+		return errors.ErrValidation("reset link is invalid or has expired")
+	}
+	if !u.IsActive {
+		return errors.ErrForbidden("account is inactive")
+	}
+	now := time.Now()
+	if err := setPassword(securityPolicy(ctx, s.configRepo, u.PharmacyID), u, newPassword, now); err != nil {
+		return err
+	}
+	// Claiming the token first lets only one of two concurrent resets with the same link through; the new password
+	// is saved in the same transaction, so a failed save leaves the link usable.
+	return inTx(ctx, s.uow, func(ctx context.Context) error {
+		claimed, err := s.resetTokenRepo.Claim(ctx, t.ID, now)
+		if err != nil {
+			return errors.ErrInternal("failed to claim reset token", err)
+		}
+		if !claimed {
+			return errors.ErrValidation("reset link is invalid or has expired")
+		}
+		if err := s.userRepo.Update(ctx, u); err != nil {
+			return errors.ErrInternal("failed to update password", err)
+		}
+		// Burn any other outstanding links for the user.
+		if err := s.resetTokenRepo.MarkAllUsed(ctx, u.ID, now); err != nil {
+			return errors.ErrInternal("failed to invalidate reset tokens", err)
+		}
+		return nil
+	})
+}
+
+func newResetToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
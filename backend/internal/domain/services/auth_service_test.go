@@ -3,7 +3,9 @@ package services
 import (
 	"context"
 	"errors"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/mocks/outbound"
@@ -38,7 +40,7 @@ func TestAuthService_Register_Success(t *testing.T) {
 		return nil
 	}
 
-	svc := NewAuthService(userRepo, pharmacyRepo, authProvider, logger)
+	svc := NewAuthService(userRepo, pharmacyRepo, authProvider, nil, nil, nil, nil, nil, "", nil, logger)
 	user, err := svc.Register(ctx, pharmacyID, "user@example.com", "password123", "Test User", "staff")
 	if err != nil {
 		t.Fatalf("Register failed: %v", err)
@@ -62,7 +64,7 @@ func TestAuthService_Register_EmailAlreadyExists(t *testing.T) {
 		return &models.User{Email: email}, nil // user already exists
 	}
 
-	svc := NewAuthService(userRepo, pharmacyRepo, authProvider, logger)
+	svc := NewAuthService(userRepo, pharmacyRepo, authProvider, nil, nil, nil, nil, nil, "", nil, logger)
 	user, err := svc.Register(ctx, uuid.New(), "existing@example.com", "pass", "Name", "staff")
 	if err == nil {
 		t.Fatal("expected conflict error, got nil")
@@ -90,7 +92,7 @@ func TestAuthService_Register_PharmacyNotFound(t *testing.T) {
 		return nil, errors.New("not found")
 	}
 
-	svc := NewAuthService(userRepo, pharmacyRepo, authProvider, logger)
+	svc := NewAuthService(userRepo, pharmacyRepo, authProvider, nil, nil, nil, nil, nil, "", nil, logger)
 	user, err := svc.Register(ctx, uuid.New(), "new@example.com", "pass", "Name", "staff")
 	if err == nil {
 		t.Fatal("expected pharmacy not found error, got nil")
@@ -134,7 +136,7 @@ func TestAuthService_Login_Success(t *testing.T) {
 		return "refresh-token", nil
 	}
 
-	svc := NewAuthService(userRepo, pharmacyRepo, authProvider, logger)
+	svc := NewAuthService(userRepo, pharmacyRepo, authProvider, nil, nil, nil, nil, nil, "", nil, logger)
 	access, refresh, user, err := svc.Login(ctx, "login@example.com", "secret")
 	if err != nil {
 		t.Fatalf("Login failed: %v", err)
@@ -158,7 +160,7 @@ func TestAuthService_Login_InvalidCredentials(t *testing.T) {
 		return nil, errors.New("not found")
 	}
 
-	svc := NewAuthService(userRepo, pharmacyRepo, authProvider, logger)
+	svc := NewAuthService(userRepo, pharmacyRepo, authProvider, nil, nil, nil, nil, nil, "", nil, logger)
 	_, _, user, err := svc.Login(ctx, "nonexistent@example.com", "any")
 	if err == nil {
 		t.Fatal("expected invalid credentials error, got nil")
@@ -188,7 +190,7 @@ func TestAuthService_GetCurrentUser_Success(t *testing.T) {
 		return nil, errors.New("not found")
 	}
 
-	svc := NewAuthService(userRepo, pharmacyRepo, authProvider, logger)
+	svc := NewAuthService(userRepo, pharmacyRepo, authProvider, nil, nil, nil, nil, nil, "", nil, logger)
 	user, err := svc.GetCurrentUser(ctx, userID)
 	if err != nil {
 		t.Fatalf("GetCurrentUser failed: %v", err)
@@ -197,3 +199,201 @@ func TestAuthService_GetCurrentUser_Success(t *testing.T) {
 		t.Errorf("expected user %+v, got %+v", expected, user)
 	}
 }
+
+func TestAuthService_ForgotPassword_UnknownEmailIsSilent(t *testing.T) {
+	userRepo := &mocks.MockUserRepository{}
+	userRepo.GetByEmailFunc = func(ctx context.Context, email string) (*models.User, error) {
+		return nil, errors.New("not found")
+	}
+	resetRepo := &mocks.MockPasswordResetTokenRepository{}
+	resetRepo.CreateFunc = func(ctx context.Context, tok *models.PasswordResetToken) error {
+		t.Fatal("no token should be created for an unknown email")
+		return nil
+	}
+
+	svc := NewAuthService(userRepo, &mocks.MockPharmacyRepository{}, &mocks.MockAuthProvider{}, resetRepo, nil, nil, nil, nil, "", nil, zap.NewNop())
+	if err := svc.ForgotPassword(context.Background(), "nobody@example.com"); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+}
+
+func TestAuthService_ResetPassword_TokenIsSingleUse(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{ID: uuid.New(), Email: "me@example.com", IsActive: true}
+	_ = user.SetPassword("old-password")
+	userRepo := &mocks.MockUserRepository{}
+	userRepo.GetByEmailFunc = func(ctx context.Context, email string) (*models.User, error) { return user, nil }
+	userRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*models.User, error) { return user, nil }
+
+	var stored *models.PasswordResetToken
+	resetRepo := &mocks.MockPasswordResetTokenRepository{}
+	resetRepo.CreateFunc = func(ctx context.Context, tok *models.PasswordResetToken) error {
+		stored = tok
+		return nil
+	}
+	resetRepo.GetByTokenHashFunc = func(ctx context.Context, hash string) (*models.PasswordResetToken, error) {
+		if stored != nil && stored.TokenHash == hash {
+			return stored, nil
+		}
+		return nil, nil
+	}
+	resetRepo.MarkAllUsedFunc = func(ctx context.Context, userID uuid.UUID, usedAt time.Time) error {
+		stored.UsedAt = &usedAt
+		return nil
+	}
+
+	sender := &captureSender{}
+	mailer := NewMailerService(sender, nil, nil, nil, "https://shop.example", zap.NewNop())
+	svc := NewAuthService(userRepo, &mocks.MockPharmacyRepository{}, &mocks.MockAuthProvider{}, resetRepo, nil, nil, mailer, nil, "https://shop.example", nil, zap.NewNop())
+
+	if err := svc.ForgotPassword(ctx, user.Email); err != nil {
+		t.Fatalf("ForgotPassword failed: %v", err)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected 1 reset email, got %d", len(sender.sent))
+	}
+	token := regexp.MustCompile(`token=([0-9a-f]+)`).FindStringSubmatch(sender.sent[0].TextBody)
+	if token == nil {
+		t.Fatalf("no reset link in email: %q", sender.sent[0].TextBody)
+	}
+
+	if err := svc.ResetPassword(ctx, token[1], "new-password"); err != nil {
+		t.Fatalf("ResetPassword failed: %v", err)
+	}
+	if !user.CheckPassword("new-password") {
+		t.Error("expected password to be updated")
+	}
+	err := svc.ResetPassword(ctx, token[1], "another-password")
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("expected reused token to be rejected, got %v", err)
+	}
+}
+
+func TestAuthService_Login_TwoFactorRoleNeedsEmailedCode(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{ID: uuid.New(), PharmacyID: uuid.New(), Email: "admin@example.com", Role: "admin", IsActive: true, CreatedAt: time.Now()}
+	_ = user.SetPassword("secret-pass")
+	userRepo := &mocks.MockUserRepository{}
+	userRepo.GetByEmailFunc = func(ctx context.Context, email string) (*models.User, error) { return user, nil }
+	userRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*models.User, error) { return user, nil }
+	configRepo := &mocks.MockPharmacyConfigRepository{GetByPharmacyIDFunc: func(ctx context.Context, pharmacyID uuid.UUID) (*models.PharmacyConfig, error) {
+		return &models.PharmacyConfig{SecurityPolicy: &models.SecurityPolicy{MinLength: 8, TwoFactorRoles: []string{"admin"}}}, nil
+	}}
+	var stored *models.LoginChallenge
+	challenges := &mocks.MockLoginChallengeRepository{
+		CreateFunc: func(ctx context.Context, c *models.LoginChallenge) error { stored = c; return nil },
+		GetByTokenHashFunc: func(ctx context.Context, hash string) (*models.LoginChallenge, error) {
+			if stored != nil && stored.TokenHash == hash {
+				return stored, nil
+			}
+			return nil, nil
+		},
+	}
+	sender := &captureSender{}
+	mailer := NewMailerService(sender, nil, nil, nil, "https://shop.example", zap.NewNop())
+	svc := NewAuthService(userRepo, &mocks.MockPharmacyRepository{}, &mocks.MockAuthProvider{}, nil, configRepo, challenges, mailer, nil, "", nil, zap.NewNop())
+
+	_, _, _, err := svc.Login(ctx, user.Email, "secret-pass")
+	appErr := pkgerrors.GetAppError(err)
+	if appErr == nil || appErr.Code != pkgerrors.ErrCodeTwoFactorRequired {
+		t.Fatalf("expected two-factor challenge, got %v", err)
+	}
+	token, _ := appErr.Details["challenge_token"].(string)
+	code := regexp.MustCompile(`code is (\d{6})`).FindStringSubmatch(sender.sent[0].TextBody)
+	if token == "" || code == nil {
+		t.Fatalf("missing challenge token %q or emailed code in %q", token, sender.sent[0].TextBody)
+	}
+
+	if _, _, _, err := svc.VerifyLoginCode(ctx, token, "not-the-code"); pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("expected wrong code to be rejected, got %v", err)
+	}
+	access, _, u, err := svc.VerifyLoginCode(ctx, token, code[1])
+	if err != nil || access == "" || u != user {
+		t.Fatalf("VerifyLoginCode = %q, %v", access, err)
+	}
+	if _, _, _, err := svc.VerifyLoginCode(ctx, token, code[1]); err == nil {
+		t.Fatal("expected a used challenge to be rejected")
+	}
+}
+
+func TestAuthService_ChangePassword_EnforcesSecurityPolicy(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{ID: uuid.New(), PharmacyID: uuid.New(), Email: "me@example.com", Role: "staff", IsActive: true, CreatedAt: time.Now().AddDate(0, 0, -100)}
+	_ = user.SetPassword("Original1")
+	userRepo := &mocks.MockUserRepository{}
+	userRepo.GetByEmailFunc = func(ctx context.Context, email string) (*models.User, error) { return user, nil }
+	userRepo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*models.User, error) { return user, nil }
+	policy := &models.SecurityPolicy{MinLength: 8, RequireUppercase: true, RequireDigit: true, ExpiryDays: 90, ReuseCount: 2}
+	configRepo := &mocks.MockPharmacyConfigRepository{GetByPharmacyIDFunc: func(ctx context.Context, pharmacyID uuid.UUID) (*models.PharmacyConfig, error) {
+		return &models.PharmacyConfig{SecurityPolicy: policy}, nil
+	}}
+	svc := NewAuthService(userRepo, &mocks.MockPharmacyRepository{}, &mocks.MockAuthProvider{}, nil, configRepo, nil, nil, nil, "", nil, zap.NewNop())
+
+	if _, _, _, err := svc.Login(ctx, user.Email, "Original1"); pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodePasswordExpired {
+		t.Fatalf("expected expired password, got %v", err)
+	}
+	for pw, want := range map[string]string{"short1A": "at least 8 characters", "nouppercase1": "an upper-case letter", "Original1": "used recently"} {
+		_, _, _, err := svc.ChangeExpiredPassword(ctx, user.Email, "Original1", pw)
+		if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation || !regexp.MustCompile(want).MatchString(appErr.Message) {
+			t.Errorf("ChangeExpiredPassword(%q) = %v, want a validation error about %q", pw, err, want)
+		}
+	}
+	if _, _, _, err := svc.ChangeExpiredPassword(ctx, user.Email, "Original1", "Replaced22"); err != nil {
+		t.Fatalf("ChangeExpiredPassword failed: %v", err)
+	}
+	if err := svc.ChangePassword(ctx, user.ID, "Replaced22", "Original1"); err == nil {
+		t.Error("expected the previous password to be refused while within reuse_count")
+	}
+	if err := svc.ChangePassword(ctx, user.ID, "Replaced22", "Another333"); err != nil {
+		t.Fatalf("ChangePassword failed: %v", err)
+	}
+	if err := svc.ChangePassword(ctx, user.ID, "Another333", "Original1"); err != nil {
+		t.Errorf("a password older than reuse_count should be allowed again, got %v", err)
+	}
+}
+
+func TestAuthService_ResetPassword_ClaimsTokenWithPasswordUpdate(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{ID: uuid.New(), Email: "me@example.com", IsActive: true}
+	_ = user.SetPassword("old-password")
+	tok := &models.PasswordResetToken{ID: uuid.New(), UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)}
+	resetRepo := &mocks.MockPasswordResetTokenRepository{
+		GetByTokenHashFunc: func(ctx context.Context, hash string) (*models.PasswordResetToken, error) { return tok, nil },
+	}
+	saved := 0
+	userRepo := &mocks.MockUserRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.User, error) { return user, nil },
+		UpdateFunc: func(ctx context.Context, u *models.User) error {
+			saved++
+			return nil
+		},
+	}
+	uow := &mocks.MockUnitOfWork{}
+	svc := NewAuthService(userRepo, &mocks.MockPharmacyRepository{}, &mocks.MockAuthProvider{}, resetRepo, nil, nil, nil, nil, "", uow, zap.NewNop())
+
+	// A concurrent reset with the same link claimed it between our read and our claim.
+	resetRepo.ClaimFunc = func(ctx context.Context, id uuid.UUID, usedAt time.Time) (bool, error) { return false, nil }
+	err := svc.ResetPassword(ctx, "token", "new-password")
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("lost claim: err = %v, want validation error", err)
+	}
+	if saved != 0 {
+		t.Error("password saved although the token was claimed by another reset")
+	}
+
+	// Burning the user's other links fails: the whole reset rolls back instead of only logging it.
+	resetRepo.ClaimFunc = func(ctx context.Context, id uuid.UUID, usedAt time.Time) (bool, error) {
+		if id != tok.ID {
+			t.Errorf("claimed %s, want the token %s", id, tok.ID)
+		}
+		return true, nil
+	}
+	resetRepo.MarkAllUsedFunc = func(ctx context.Context, userID uuid.UUID, usedAt time.Time) error { return errors.New("db down") }
+	err = svc.ResetPassword(ctx, "token", "new-password")
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeInternal {
+		t.Fatalf("failed invalidation: err = %v, want internal error", err)
+	}
+	if uow.Calls != 2 || uow.RolledBack != 2 {
+		t.Errorf("transactions = %d, rolled back = %d; want both resets rolled back", uow.Calls, uow.RolledBack)
+	}
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// backInStockBatch bounds one Dispatch pass; the rest is picked up by the next run.
+const backInStockBatch = 500
+
+type backInStockService struct {
+	repo                outbound.StockSubscriptionRepository
+	productRepo         outbound.ProductRepository
+	notificationService inbound.NotificationService
+	emailSender         outbound.EmailSender
+	smsSender           outbound.SMSSender
+	now                 func() time.Time
+	logger              *zap.Logger
+}
+
+func NewBackInStockService(repo outbound.StockSubscriptionRepository, productRepo outbound.ProductRepository, notificationService inbound.NotificationService, emailSender outbound.EmailSender, smsSender outbound.SMSSender, logger *zap.Logger) inbound.BackInStockService {
+	return &backInStockService{repo: repo, productRepo: productRepo, notificationService: notificationService, emailSender: emailSender, smsSender: smsSender, now: time.Now, logger: logger}
+}
+
+func (s *backInStockService) Subscribe(ctx context.Context, productID uuid.UUID, in inbound.BackInStockInput) (*models.StockSubscription, error) {
+	p, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil || p == nil || !p.IsActive {
+		return nil, errors.ErrNotFound("product")
+	}
+	if p.StockQuantity > 0 {
+		return nil, errors.ErrValidation("product is in stock")
+	}
+	phone := strings.TrimSpace(in.Phone)
+	email := strings.ToLower(strings.TrimSpace(in.Email))
+	if email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return nil, errors.ErrValidation("invalid email")
+		}
+	}
+	if phone != "" && (len(phone) < 7 || len(phone) > 20) {
+		return nil, errors.ErrValidation("invalid phone")
+	}
+	// A token from another pharmacy's store is ignored: the subscriber then needs a phone or email.
+	var userID *uuid.UUID
+	if in.UserID != nil && in.PharmacyID != nil && *in.PharmacyID == p.PharmacyID {
+		userID = in.UserID
+	}
+	if userID == nil && phone == "" && email == "" {
+		return nil, errors.ErrValidation("phone or email is required")
+	}
+	existing, err := s.repo.FindPending(ctx, productID, userID, phone, email)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to check subscriptions", err)
+	}
+	if existing != nil {
+		return existing, nil
+	}
+	sub := &models.StockSubscription{
+		PharmacyID: p.PharmacyID, ProductID: productID, UserID: userID, Phone: phone, Email: email,
+		Status: models.StockSubscriptionPending, CreatedAt: s.now(),
+	}
+	if err := s.repo.Create(ctx, sub); err != nil {
+		return nil, errors.ErrInternal("failed to subscribe", err)
+	}
+	return sub, nil
+}
+
+func (s *backInStockService) Subscribers(ctx context.Context, pharmacyID, productID uuid.UUID, status string) (*inbound.StockSubscriberList, error) {
+	if status != "" && status != models.StockSubscriptionPending && status != models.StockSubscriptionNotified {
+		return nil, errors.ErrValidation("status must be pending or notified")
+	}
+	p, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil || p == nil || p.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("product")
+	}
+	list, err := s.repo.ListByProduct(ctx, pharmacyID, productID, status)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list subscribers", err)
+	}
+	out := &inbound.StockSubscriberList{ProductID: productID, Subscribers: list}
+	for _, sub := range list {
+		if sub.Status == models.StockSubscriptionPending {
+			out.Pending++
+		}
+	}
+	return out, nil
+}
+
+func (s *backInStockService) Dispatch(ctx context.Context) error {
+	due, err := s.repo.ListDue(ctx, backInStockBatch)
+	if err != nil {
+		return fmt.Errorf("list due stock subscriptions: %w", err)
+	}
+	sent := 0
+	for _, sub := range due {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if sub.Product == nil {
+			continue
+		}
+		// Claimed first so a failing channel or a concurrent run never sends the notice twice.
+		ok, err := s.repo.MarkNotified(ctx, sub.ID, s.now())
+		if err != nil {
+			return fmt.Errorf("mark stock subscription notified: %w", err)
+		}
+		if !ok {
+			continue
+		}
+		s.notify(ctx, sub)
+		sent++
+	}
+	if sent > 0 {
+		s.logger.Info("back-in-stock notices sent", zap.Int("subscriptions", sent))
+	}
+	return nil
+}
+
+// notify sends the notice on every channel the subscriber gave. Failures are logged, never returned.
+func (s *backInStockService) notify(ctx context.Context, sub *models.StockSubscription) {
+	p := sub.Product
+	title := p.Name + " is back in stock"
+	message := fmt.Sprintf("%s is back in stock at %s %.2f. Order soon, stock is limited.", p.Name, p.Currency, p.UnitPrice)
+	if s.notificationService != nil && sub.UserID != nil {
+		if _, err := s.notificationService.Create(ctx, sub.PharmacyID, *sub.UserID, title, message, "stock"); err != nil {
+			s.logger.Warn("back-in-stock notification failed", zap.String("subscription_id", sub.ID.String()), zap.Error(err))
+		}
+	}
+	if s.emailSender != nil && sub.Email != "" {
+		msg := &outbound.EmailMessage{PharmacyID: sub.PharmacyID, To: []string{sub.Email}, Subject: title, TextBody: message}
+		if err := s.emailSender.Send(ctx, msg); err != nil {
+			s.logger.Warn("back-in-stock email failed", zap.String("subscription_id", sub.ID.String()), zap.Error(err))
+		}
+	}
+	if s.smsSender != nil && sub.Phone != "" {
+		if err := s.smsSender.Send(ctx, &outbound.SMSMessage{PharmacyID: sub.PharmacyID, To: sub.Phone, Body: message}); err != nil {
+			s.logger.Warn("back-in-stock SMS failed", zap.String("subscription_id", sub.ID.String()), zap.Error(err))
+		}
+	}
+}
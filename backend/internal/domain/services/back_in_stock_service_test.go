@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestBackInStockService_Subscribe_ValidatesAndDedupes(t *testing.T) {
+	pharmacyID := uuid.New()
+	p := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Cetamol", StockQuantity: 0, IsActive: true}
+	var stored []*models.StockSubscription
+	repo := &mocks.MockStockSubscriptionRepository{
+		CreateFunc: func(ctx context.Context, s *models.StockSubscription) error {
+			stored = append(stored, s)
+			return nil
+		},
+		FindPendingFunc: func(ctx context.Context, productID uuid.UUID, userID *uuid.UUID, phone, email string) (*models.StockSubscription, error) {
+			for _, s := range stored {
+				if (userID != nil && s.UserID != nil && *s.UserID == *userID) || (phone != "" && s.Phone == phone) || (email != "" && s.Email == email) {
+					return s, nil
+				}
+			}
+			return nil, nil
+		},
+	}
+	svc := &backInStockService{
+		repo:        repo,
+		productRepo: &mocks.MockProductRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) { return p, nil }},
+		now:         time.Now,
+		logger:      zap.NewNop(),
+	}
+	ctx := context.Background()
+
+	if _, err := svc.Subscribe(ctx, p.ID, inbound.BackInStockInput{}); err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("no contact: err = %v, want validation error", err)
+	}
+	if _, err := svc.Subscribe(ctx, p.ID, inbound.BackInStockInput{Email: "not-an-email"}); err == nil {
+		t.Error("invalid email accepted")
+	}
+	// A token from another pharmacy does not count as a subscriber.
+	userID, otherPharmacy := uuid.New(), uuid.New()
+	if _, err := svc.Subscribe(ctx, p.ID, inbound.BackInStockInput{UserID: &userID, PharmacyID: &otherPharmacy}); err == nil {
+		t.Error("user of another pharmacy subscribed without contact details")
+	}
+	first, err := svc.Subscribe(ctx, p.ID, inbound.BackInStockInput{Email: " Sita@Example.com "})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	again, err := svc.Subscribe(ctx, p.ID, inbound.BackInStockInput{Email: "sita@example.com"})
+	if err != nil || again.ID != first.ID || len(stored) != 1 {
+		t.Errorf("repeat subscription: %v, stored %d", err, len(stored))
+	}
+	if _, err := svc.Subscribe(ctx, p.ID, inbound.BackInStockInput{UserID: &userID, PharmacyID: &pharmacyID}); err != nil || stored[len(stored)-1].UserID == nil {
+		t.Errorf("signed-in subscriber: %v", err)
+	}
+	p.StockQuantity = 4
+	if _, err := svc.Subscribe(ctx, p.ID, inbound.BackInStockInput{Phone: "9800000000"}); err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("in-stock product: err = %v, want validation error", err)
+	}
+}
+
+func TestBackInStockService_Dispatch_NotifiesEachChannelOnce(t *testing.T) {
+	pharmacyID, userID := uuid.New(), uuid.New()
+	p := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Cetamol", Currency: "NPR", UnitPrice: 50, StockQuantity: 6, IsActive: true}
+	subs := []*models.StockSubscription{
+		{ID: uuid.New(), PharmacyID: pharmacyID, ProductID: p.ID, UserID: &userID, Status: models.StockSubscriptionPending, Product: p},
+		{ID: uuid.New(), PharmacyID: pharmacyID, ProductID: p.ID, Phone: "9800000000", Email: "sita@example.com", Status: models.StockSubscriptionPending, Product: p},
+	}
+	repo := &mocks.MockStockSubscriptionRepository{
+		ListDueFunc: func(ctx context.Context, limit int) ([]*models.StockSubscription, error) {
+			var due []*models.StockSubscription
+			for _, s := range subs {
+				if s.Status == models.StockSubscriptionPending {
+					due = append(due, s)
+				}
+			}
+			return due, nil
+		},
+		MarkNotifiedFunc: func(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+			for _, s := range subs {
+				if s.ID == id && s.Status == models.StockSubscriptionPending {
+					s.Status, s.NotifiedAt = models.StockSubscriptionNotified, &at
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+	}
+	notifier, mail, sms := &recordingNotifier{}, &captureSender{}, &captureSMS{}
+	svc := &backInStockService{repo: repo, notificationService: notifier, emailSender: mail, smsSender: sms, now: time.Now, logger: zap.NewNop()}
+
+	if err := svc.Dispatch(context.Background()); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].UserID != userID || notifier.sent[0].Type != "stock" {
+		t.Errorf("in-app notices = %+v", notifier.sent)
+	}
+	if len(mail.sent) != 1 || mail.sent[0].To[0] != "sita@example.com" || len(sms.sent) != 1 || sms.sent[0].To != "9800000000" {
+		t.Errorf("email %d, sms %d; want one each", len(mail.sent), len(sms.sent))
+	}
+	_ = svc.Dispatch(context.Background())
+	if len(notifier.sent) != 1 || len(mail.sent) != 1 || len(sms.sent) != 1 {
+		t.Error("second run notified again")
+	}
+}
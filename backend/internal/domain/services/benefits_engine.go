@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/careplus/pharmacy-backend/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type benefitsEngine struct {
+	customerRepo           outbound.CustomerRepository
+	customerMembershipRepo outbound.CustomerMembershipRepository
+	configRepo             outbound.PharmacyConfigRepository
+	logger                 *zap.Logger
+}
+
+// NewBenefitsEngine returns the BenefitsEngine. configRepo supplies the delivery fee that free delivery waives.
+func NewBenefitsEngine(customerRepo outbound.CustomerRepository, customerMembershipRepo outbound.CustomerMembershipRepository, configRepo outbound.PharmacyConfigRepository, logger *zap.Logger) inbound.BenefitsEngine {
+	return &benefitsEngine{customerRepo: customerRepo, customerMembershipRepo: customerMembershipRepo, configRepo: configRepo, logger: logger}
+}
+
+func (e *benefitsEngine) ForCustomer(ctx context.Context, customerID uuid.UUID, now time.Time) (*inbound.CustomerBenefits, error) {
+	cust, err := e.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load customer", err)
+	}
+	if cust == nil {
+		return nil, errors.ErrNotFound("customer")
+	}
+	return e.benefitsOf(ctx, cust, now), nil
+}
+
+func (e *benefitsEngine) benefitsOf(ctx context.Context, cust *models.Customer, now time.Time) *inbound.CustomerBenefits {
+	out := &inbound.CustomerBenefits{Perks: []string{}}
+	cm, _ := e.customerMembershipRepo.GetByCustomerID(ctx, cust.ID)
+	if cm == nil || cm.Membership == nil || !cm.Membership.IsActive {
+		return out
+	}
+	m := cm.Membership
+	out.Membership = &inbound.MembershipInfo{ID: m.ID, Name: m.Name}
+	out.Benefits = m.Benefits
+	out.BirthdayGiftAvailable = m.Benefits.HasBirthdayGift() && birthdayMonth(cust, now) && cust.BirthdayGiftYear != now.Year()
+	out.Perks = benefitPerks(m)
+	return out
+}
+
+func (e *benefitsEngine) Evaluate(ctx context.Context, pharmacyID uuid.UUID, customerID *uuid.UUID, in inbound.BenefitsCheckout) (_ *inbound.BenefitsEvaluation, err error) {
+	ctx, span := tracing.Start(ctx, "BenefitsEngine.Evaluate")
+	defer tracing.End(span, &err)
+	if in.Now.IsZero() {
+		in.Now = time.Now()
+	}
+	ev := &inbound.BenefitsEvaluation{CustomerBenefits: inbound.CustomerBenefits{Perks: []string{}}, PointsMultiplier: 1}
+	if customerID != nil {
+		cust, err := e.customerRepo.GetByID(ctx, *customerID)
+		if err != nil {
+			return nil, errors.ErrInternal("failed to load customer", err)
+		}
+		if cust != nil && cust.PharmacyID == pharmacyID {
+			ev.CustomerBenefits = *e.benefitsOf(ctx, cust, in.Now)
+		}
+	}
+	b := ev.Benefits
+	if b.PointsMultiplier > 1 {
+		ev.PointsMultiplier = b.PointsMultiplier
+	}
+
+	if in.Delivery && e.configRepo != nil {
+		if cfg, _ := e.configRepo.GetByPharmacyID(ctx, pharmacyID); cfg != nil && cfg.DeliveryFee > 0 {
+			if b.FreeDelivery {
+				ev.DeliveryFeeWaived = cfg.DeliveryFee
+			} else {
+				ev.DeliveryFee = cfg.DeliveryFee
+			}
+		}
+	}
+
+	// The birthday gift is claimed here so two checkouts in the same month cannot both take it. It waits for
+	// another order when an exclusive promo code rules out membership discounts.
+	if ev.BirthdayGiftAvailable && customerID != nil && !in.ExclusivePromo {
+		year := in.Now.Year()
+		claimed, err := e.customerRepo.ClaimBirthdayGift(ctx, *customerID, year)
+		if err != nil {
+			return nil, errors.ErrInternal("failed to claim birthday gift", err)
+		}
+		if claimed {
+			ev.BirthdayYear = year
+			ev.BirthdayBonusPoints = b.BirthdayBonusPoints
+			ev.BirthdayDiscount = roundMoney(in.SubTotal * b.BirthdayDiscountPercent / 100)
+		}
+		ev.BirthdayGiftAvailable = false
+	}
+	return ev, nil
+}
+
+func (e *benefitsEngine) ReleaseBirthdayGift(ctx context.Context, customerID uuid.UUID, year int) {
+	if err := e.customerRepo.ReleaseBirthdayGift(ctx, customerID, year); err != nil {
+		e.logger.Warn("failed to release birthday gift", zap.Error(err), zap.String("customer_id", customerID.String()))
+	}
+}
+
+// flashSaleEarlyAccess is how long before a flash sale opens the customer's tier may buy from it; 0 when
+// there is no engine or the tier has no such perk.
+func flashSaleEarlyAccess(ctx context.Context, engine inbound.BenefitsEngine, customerID uuid.UUID) time.Duration {
+	if engine == nil {
+		return 0
+	}
+	b, err := engine.ForCustomer(ctx, customerID, time.Now())
+	if err != nil {
+		return 0
+	}
+	return time.Duration(b.Benefits.FlashSaleEarlyAccessMinutes) * time.Minute
+}
+
+// birthdayMonth reports whether now falls in the customer's birthday month.
+func birthdayMonth(c *models.Customer, now time.Time) bool {
+	return c.DateOfBirth != nil && c.DateOfBirth.Month() == now.Month()
+}
+
+// benefitPerks labels a tier's perks for the storefront, discount first.
+func benefitPerks(m *models.Membership) []string {
+	perks := []string{}
+	b := m.Benefits
+	if m.DiscountPercent > 0 {
+		perks = append(perks, fmt.Sprintf("%g%% off every order", m.DiscountPercent))
+	}
+	if b.FreeDelivery {
+		perks = append(perks, "Free delivery")
+	}
+	if b.FlashSaleEarlyAccessMinutes > 0 {
+		perks = append(perks, fmt.Sprintf("Flash sales %s early", earlyAccessLabel(b.FlashSaleEarlyAccessMinutes)))
+	}
+	if b.PointsMultiplier > 1 {
+		perks = append(perks, fmt.Sprintf("%gx points on purchases", b.PointsMultiplier))
+	}
+	if b.BirthdayDiscountPercent > 0 {
+		perks = append(perks, fmt.Sprintf("%g%% off in your birthday month", b.BirthdayDiscountPercent))
+	}
+	if b.BirthdayBonusPoints > 0 {
+		perks = append(perks, fmt.Sprintf("%d bonus points in your birthday month", b.BirthdayBonusPoints))
+	}
+	return perks
+}
+
+func earlyAccessLabel(minutes int) string {
+	switch {
+	case minutes%(24*60) == 0:
+		return plural(minutes/(24*60), "day")
+	case minutes%60 == 0:
+		return plural(minutes/60, "hour")
+	default:
+		return plural(minutes, "minute")
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
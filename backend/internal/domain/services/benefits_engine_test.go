@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func benefitsFixture(pharmacyID uuid.UUID, dob time.Time, b models.MembershipBenefits) (*benefitsEngine, *models.Customer, *int) {
+	cust := &models.Customer{ID: uuid.New(), PharmacyID: pharmacyID, DateOfBirth: &dob}
+	claimedYear := new(int)
+	customers := &mocks.MockCustomerRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Customer, error) { return cust, nil },
+		ClaimBirthdayGiftFunc: func(ctx context.Context, customerID uuid.UUID, year int) (bool, error) {
+			if *claimedYear == year {
+				return false, nil
+			}
+			*claimedYear = year
+			return true, nil
+		},
+	}
+	memberships := &mocks.MockCustomerMembershipRepository{
+		GetByCustomerIDFunc: func(ctx context.Context, customerID uuid.UUID) (*models.CustomerMembership, error) {
+			return &models.CustomerMembership{Membership: &models.Membership{Name: "Gold", IsActive: true, DiscountPercent: 5, Benefits: b}}, nil
+		},
+	}
+	configs := &mocks.MockPharmacyConfigRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, pharmacyID uuid.UUID) (*models.PharmacyConfig, error) {
+			return &models.PharmacyConfig{DeliveryFee: 80}, nil
+		},
+	}
+	return &benefitsEngine{customerRepo: customers, customerMembershipRepo: memberships, configRepo: configs, logger: zap.NewNop()}, cust, claimedYear
+}
+
+func TestBenefitsEngine_Evaluate_FreeDeliveryAndMultiplier(t *testing.T) {
+	pharmacyID := uuid.New()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	e, cust, _ := benefitsFixture(pharmacyID, time.Date(1990, 7, 1, 0, 0, 0, 0, time.UTC),
+		models.MembershipBenefits{FreeDelivery: true, PointsMultiplier: 2, FlashSaleEarlyAccessMinutes: 120})
+
+	ev, err := e.Evaluate(context.Background(), pharmacyID, &cust.ID, inbound.BenefitsCheckout{SubTotal: 1000, Delivery: true, Now: now})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ev.DeliveryFee != 0 || ev.DeliveryFeeWaived != 80 {
+		t.Errorf("delivery fee = %v waived %v, want 0 waived 80", ev.DeliveryFee, ev.DeliveryFeeWaived)
+	}
+	if ev.PointsMultiplier != 2 || ev.BirthdayYear != 0 {
+		t.Errorf("multiplier = %v birthday year = %d, want 2 and no gift outside the birthday month", ev.PointsMultiplier, ev.BirthdayYear)
+	}
+	want := []string{"5% off every order", "Free delivery", "Flash sales 2 hours early", "2x points on purchases"}
+	if len(ev.Perks) != len(want) {
+		t.Fatalf("perks = %v, want %v", ev.Perks, want)
+	}
+	for i := range want {
+		if ev.Perks[i] != want[i] {
+			t.Errorf("perk %d = %q, want %q", i, ev.Perks[i], want[i])
+		}
+	}
+
+	walkIn, err := e.Evaluate(context.Background(), pharmacyID, nil, inbound.BenefitsCheckout{SubTotal: 1000, Delivery: true, Now: now})
+	if err != nil || walkIn.DeliveryFee != 80 || walkIn.PointsMultiplier != 1 {
+		t.Errorf("walk-in = %+v, %v; want delivery fee 80 and multiplier 1", walkIn, err)
+	}
+}
+
+func TestBenefitsEngine_Evaluate_BirthdayGiftOncePerYear(t *testing.T) {
+	pharmacyID := uuid.New()
+	now := time.Date(2026, 7, 15, 12, 0, 0, 0, time.UTC)
+	e, cust, claimedYear := benefitsFixture(pharmacyID, time.Date(1990, 7, 1, 0, 0, 0, 0, time.UTC),
+		models.MembershipBenefits{BirthdayDiscountPercent: 10, BirthdayBonusPoints: 50})
+
+	skipped, err := e.Evaluate(context.Background(), pharmacyID, &cust.ID, inbound.BenefitsCheckout{SubTotal: 500, ExclusivePromo: true, Now: now})
+	if err != nil || skipped.BirthdayYear != 0 || *claimedYear != 0 {
+		t.Fatalf("exclusive promo: birthday year = %d, claimed %d, err %v; want the gift left unclaimed", skipped.BirthdayYear, *claimedYear, err)
+	}
+	first, err := e.Evaluate(context.Background(), pharmacyID, &cust.ID, inbound.BenefitsCheckout{SubTotal: 500, Now: now})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.BirthdayYear != 2026 || first.BirthdayDiscount != 50 || first.BirthdayBonusPoints != 50 {
+		t.Errorf("first order = year %d discount %v points %d, want 2026, 50, 50", first.BirthdayYear, first.BirthdayDiscount, first.BirthdayBonusPoints)
+	}
+	second, err := e.Evaluate(context.Background(), pharmacyID, &cust.ID, inbound.BenefitsCheckout{SubTotal: 500, Now: now})
+	if err != nil || second.BirthdayYear != 0 || second.BirthdayDiscount != 0 {
+		t.Errorf("second order = year %d discount %v, err %v; want no gift", second.BirthdayYear, second.BirthdayDiscount, err)
+	}
+}
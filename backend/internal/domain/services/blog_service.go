@@ -2,7 +2,9 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -34,9 +36,14 @@ type blogService struct {
 	likeRepo    outbound.BlogPostLikeRepository
 	commentRepo outbound.BlogPostCommentRepository
 	viewRepo    outbound.BlogPostViewRepository
+	revisionRepo outbound.BlogPostRevisionRepository
+	tagRepo     outbound.BlogTagRepository
+	pharmacyRepo outbound.PharmacyRepository
+	publicURL   string
 	logger      *zap.Logger
 }
 
+// NewBlogService builds the blog service. publicURL is the web app base used for post links in the feed.
 func NewBlogService(
 	postRepo outbound.BlogPostRepository,
 	categoryRepo outbound.BlogCategoryRepository,
@@ -44,6 +51,10 @@ func NewBlogService(
 	likeRepo outbound.BlogPostLikeRepository,
 	commentRepo outbound.BlogPostCommentRepository,
 	viewRepo outbound.BlogPostViewRepository,
+	revisionRepo outbound.BlogPostRevisionRepository,
+	tagRepo outbound.BlogTagRepository,
+	pharmacyRepo outbound.PharmacyRepository,
+	publicURL string,
 	logger *zap.Logger,
 ) inbound.BlogService {
 	return &blogService{
@@ -53,6 +64,10 @@ func NewBlogService(
 		likeRepo:     likeRepo,
 		commentRepo:  commentRepo,
 		viewRepo:     viewRepo,
+		revisionRepo: revisionRepo,
+		tagRepo:      tagRepo,
+		pharmacyRepo: pharmacyRepo,
+		publicURL:    strings.TrimSuffix(publicURL, "/"),
 		logger:       logger,
 	}
 }
@@ -123,6 +138,181 @@ func (s *blogService) DeleteCategory(ctx context.Context, pharmacyID, id uuid.UU
 	return s.categoryRepo.Delete(ctx, id)
 }
 
+const blogFeedSize = 20
+
+func (s *blogService) Feed(ctx context.Context, pharmacyID uuid.UUID) (*inbound.BlogFeed, error) {
+	pharmacy, err := s.pharmacyRepo.GetByID(ctx, pharmacyID)
+	if err != nil || pharmacy == nil || !pharmacy.IsActive {
+		return nil, errors.ErrNotFound("pharmacy")
+	}
+	posts, _, err := s.postRepo.ListByPharmacy(ctx, pharmacyID, ptr(models.BlogPostStatusPublished), nil, nil, blogFeedSize, 0)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list posts", err)
+	}
+	feed := &inbound.BlogFeed{
+		PharmacyID: pharmacyID,
+		Title:      pharmacy.Name,
+		Link:       s.publicURL + "/blog",
+		SelfLink:   fmt.Sprintf("%s/api/v1/public/pharmacies/%s/blog/feed.xml", s.publicURL, pharmacyID),
+		Updated:    pharmacy.UpdatedAt,
+		Items:      make([]*inbound.BlogFeedItem, 0, len(posts)),
+	}
+	for _, p := range posts {
+		p.Tags, _ = s.tagRepo.ListByPostID(ctx, p.ID)
+		published := p.CreatedAt
+		if p.PublishedAt != nil {
+			published = *p.PublishedAt
+		}
+		feed.Items = append(feed.Items, &inbound.BlogFeedItem{Post: p, Link: s.publicURL + "/blog/" + p.Slug, Published: published})
+		if p.UpdatedAt.After(feed.Updated) {
+			feed.Updated = p.UpdatedAt
+		}
+	}
+	// Posts are listed by creation; a feed reads newest publication first.
+	sort.SliceStable(feed.Items, func(i, j int) bool { return feed.Items[i].Published.After(feed.Items[j].Published) })
+	return feed, nil
+}
+
+// blogTagSlug is the slug a tag name is stored and looked up under; "" when the name has no letters or digits.
+func blogTagSlug(name string) string {
+	return strings.Trim(slugRe.ReplaceAllString(strings.ToLower(strings.TrimSpace(name)), "-"), "-")
+}
+
+func (s *blogService) ListTags(ctx context.Context, pharmacyID uuid.UUID) ([]*models.BlogTag, error) {
+	list, err := s.tagRepo.ListByPharmacy(ctx, pharmacyID, false)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list tags", err)
+	}
+	return list, nil
+}
+
+func (s *blogService) TagCloud(ctx context.Context, pharmacyID uuid.UUID, limit int) ([]*models.BlogTag, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 30
+	}
+	list, err := s.tagRepo.ListByPharmacy(ctx, pharmacyID, true)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list tags", err)
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].PostCount > list[j].PostCount })
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+func (s *blogService) CreateTag(ctx context.Context, pharmacyID uuid.UUID, name string) (*models.BlogTag, error) {
+	name = strings.TrimSpace(name)
+	slug := blogTagSlug(name)
+	if slug == "" {
+		return nil, errors.ErrValidation("tag name needs a letter or digit")
+	}
+	existing, err := s.tagRepo.GetBySlug(ctx, pharmacyID, slug)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load tag", err)
+	}
+	if existing != nil {
+		return nil, errors.ErrConflict("tag already exists")
+	}
+	tag := &models.BlogTag{PharmacyID: pharmacyID, Name: name, Slug: slug}
+	if err := s.tagRepo.Create(ctx, tag); err != nil {
+		return nil, errors.ErrInternal("failed to create tag", err)
+	}
+	return tag, nil
+}
+
+func (s *blogService) UpdateTag(ctx context.Context, pharmacyID, id uuid.UUID, name string) (*models.BlogTag, error) {
+	tag, err := s.pharmacyTag(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	slug := blogTagSlug(name)
+	if slug == "" {
+		return nil, errors.ErrValidation("tag name needs a letter or digit")
+	}
+	if slug != tag.Slug {
+		existing, err := s.tagRepo.GetBySlug(ctx, pharmacyID, slug)
+		if err != nil {
+			return nil, errors.ErrInternal("failed to load tag", err)
+		}
+		if existing != nil {
+			return nil, errors.ErrConflict("another tag already uses this name")
+		}
+	}
+	tag.Name, tag.Slug = name, slug
+	if err := s.tagRepo.Update(ctx, tag); err != nil {
+		return nil, errors.ErrInternal("failed to update tag", err)
+	}
+	return tag, nil
+}
+
+func (s *blogService) DeleteTag(ctx context.Context, pharmacyID, id uuid.UUID) error {
+	if _, err := s.pharmacyTag(ctx, pharmacyID, id); err != nil {
+		return err
+	}
+	if err := s.tagRepo.Delete(ctx, id); err != nil {
+		return errors.ErrInternal("failed to delete tag", err)
+	}
+	return nil
+}
+
+func (s *blogService) pharmacyTag(ctx context.Context, pharmacyID, id uuid.UUID) (*models.BlogTag, error) {
+	tag, err := s.tagRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load tag", err)
+	}
+	if tag == nil || tag.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("tag")
+	}
+	return tag, nil
+}
+
+// resolveTags maps tag names to the pharmacy's tags, creating the ones that do not exist yet. Names with the same
+// slug count once.
+func (s *blogService) resolveTags(ctx context.Context, pharmacyID uuid.UUID, names []string) ([]*models.BlogTag, error) {
+	seen := make(map[string]bool, len(names))
+	out := make([]*models.BlogTag, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		slug := blogTagSlug(name)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		if len(seen) > models.MaxBlogPostTags {
+			return nil, errors.ErrValidation(fmt.Sprintf("a post can have at most %d tags", models.MaxBlogPostTags))
+		}
+		tag, err := s.tagRepo.GetBySlug(ctx, pharmacyID, slug)
+		if err != nil {
+			return nil, errors.ErrInternal("failed to load tag", err)
+		}
+		if tag == nil {
+			tag = &models.BlogTag{PharmacyID: pharmacyID, Name: name, Slug: slug}
+			if err := s.tagRepo.Create(ctx, tag); err != nil {
+				// Another request may have created it in the meantime.
+				if tag, _ = s.tagRepo.GetBySlug(ctx, pharmacyID, slug); tag == nil {
+					return nil, errors.ErrInternal("failed to create tag", err)
+				}
+			}
+		}
+		out = append(out, tag)
+	}
+	return out, nil
+}
+
+func (s *blogService) setPostTags(ctx context.Context, post *models.BlogPost, tags []*models.BlogTag) error {
+	ids := make([]uuid.UUID, 0, len(tags))
+	for _, t := range tags {
+		ids = append(ids, t.ID)
+	}
+	if err := s.tagRepo.SetPostTags(ctx, post.ID, ids); err != nil {
+		return errors.ErrInternal("failed to save post tags", err)
+	}
+	post.Tags = tags
+	return nil
+}
+
 func (s *blogService) ensureUniqueSlug(ctx context.Context, pharmacyID uuid.UUID, baseSlug string, excludeID *uuid.UUID) string {
 	slug := baseSlug
 	for i := 0; i < 100; i++ {
@@ -142,10 +332,14 @@ func (s *blogService) ensureUniqueSlug(ctx context.Context, pharmacyID uuid.UUID
 	return baseSlug + "-" + uuid.New().String()
 }
 
-func (s *blogService) CreatePost(ctx context.Context, pharmacyID, authorID uuid.UUID, title, excerpt, body string, categoryID *uuid.UUID, status string, media []inbound.BlogPostMediaInput) (*models.BlogPost, error) {
+func (s *blogService) CreatePost(ctx context.Context, pharmacyID, authorID uuid.UUID, title, excerpt, body string, categoryID *uuid.UUID, status string, media []inbound.BlogPostMediaInput, tags []string) (*models.BlogPost, error) {
 	if status != models.BlogPostStatusDraft && status != models.BlogPostStatusPendingApproval {
 		status = models.BlogPostStatusDraft
 	}
+	tagList, err := s.resolveTags(ctx, pharmacyID, tags)
+	if err != nil {
+		return nil, err
+	}
 	slug := s.ensureUniqueSlug(ctx, pharmacyID, slugFromTitle(title), nil)
 	var publishedAt *time.Time
 	if status == models.BlogPostStatusPublished {
@@ -166,6 +360,12 @@ func (s *blogService) CreatePost(ctx context.Context, pharmacyID, authorID uuid.
 	if err := s.postRepo.Create(ctx, post); err != nil {
 		return nil, err
 	}
+	s.recordRevision(ctx, post, models.BlogRevisionCreate, &authorID, nil)
+	if len(tagList) > 0 {
+		if err := s.setPostTags(ctx, post, tagList); err != nil {
+			return nil, err
+		}
+	}
 	for _, m := range media {
 		if m.URL == "" {
 			continue
@@ -194,6 +394,7 @@ func (s *blogService) getPostMeta(ctx context.Context, post *models.BlogPost, us
 	commentCount, _ := s.commentRepo.CountByPostID(ctx, post.ID)
 	viewCount, _ := s.viewRepo.CountByPostID(ctx, post.ID)
 	mediaList, _ := s.mediaRepo.ListByPostID(ctx, post.ID)
+	post.Tags, _ = s.tagRepo.ListByPostID(ctx, post.ID)
 	return &inbound.BlogPostWithMeta{
 		BlogPost:     post,
 		LikeCount:    likeCount,
@@ -233,8 +434,19 @@ func (s *blogService) GetPostBySlug(ctx context.Context, pharmacyID uuid.UUID, s
 	return s.getPostMeta(ctx, post, userID)
 }
 
-func (s *blogService) ListPosts(ctx context.Context, pharmacyID uuid.UUID, status *string, categoryID *uuid.UUID, limit, offset int) ([]*inbound.BlogPostWithMeta, int64, error) {
-	list, total, err := s.postRepo.ListByPharmacy(ctx, pharmacyID, status, categoryID, limit, offset)
+func (s *blogService) ListPosts(ctx context.Context, pharmacyID uuid.UUID, status *string, categoryID *uuid.UUID, tag string, limit, offset int) ([]*inbound.BlogPostWithMeta, int64, error) {
+	var tagID *uuid.UUID
+	if tag != "" {
+		t, err := s.tagRepo.GetBySlug(ctx, pharmacyID, blogTagSlug(tag))
+		if err != nil {
+			return nil, 0, errors.ErrInternal("failed to load tag", err)
+		}
+		if t == nil {
+			return []*inbound.BlogPostWithMeta{}, 0, nil
+		}
+		tagID = &t.ID
+	}
+	list, total, err := s.postRepo.ListByPharmacy(ctx, pharmacyID, status, categoryID, tagID, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -247,12 +459,12 @@ func (s *blogService) ListPosts(ctx context.Context, pharmacyID uuid.UUID, statu
 }
 
 func (s *blogService) ListPendingPosts(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*inbound.BlogPostWithMeta, int64, error) {
-	return s.ListPosts(ctx, pharmacyID, ptr(models.BlogPostStatusPendingApproval), nil, limit, offset)
+	return s.ListPosts(ctx, pharmacyID, ptr(models.BlogPostStatusPendingApproval), nil, "", limit, offset)
 }
 
 func ptr(s string) *string { return &s }
 
-func (s *blogService) UpdatePost(ctx context.Context, pharmacyID, userID, postID uuid.UUID, title, excerpt, body *string, categoryID *uuid.UUID, status *string, media []inbound.BlogPostMediaInput) (*models.BlogPost, error) {
+func (s *blogService) UpdatePost(ctx context.Context, pharmacyID, userID, postID uuid.UUID, title, excerpt, body *string, categoryID *uuid.UUID, status *string, media []inbound.BlogPostMediaInput, tags []string) (*models.BlogPost, error) {
 	post, err := s.postRepo.GetByID(ctx, postID)
 	if err != nil {
 		return nil, err
@@ -263,8 +475,18 @@ func (s *blogService) UpdatePost(ctx context.Context, pharmacyID, userID, postID
 	if post.AuthorID != userID {
 		return nil, errors.ErrForbidden("only the author can edit this post")
 	}
-	if post.Status == models.BlogPostStatusPublished {
-		return nil, errors.ErrForbidden("cannot edit published post")
+	if post.Status == models.BlogPostStatusPublished || post.Status == models.BlogPostStatusScheduled {
+		return nil, errors.ErrForbidden("cannot edit " + post.Status + " post")
+	}
+	var tagList []*models.BlogTag
+	if tags != nil {
+		if tagList, err = s.resolveTags(ctx, pharmacyID, tags); err != nil {
+			return nil, err
+		}
+	}
+	contentChanged := title != nil || excerpt != nil || body != nil
+	if contentChanged {
+		s.recordBaseline(ctx, post)
 	}
 	if title != nil {
 		post.Title = *title
@@ -285,6 +507,14 @@ func (s *blogService) UpdatePost(ctx context.Context, pharmacyID, userID, postID
 	if err := s.postRepo.Update(ctx, post); err != nil {
 		return nil, err
 	}
+	if contentChanged {
+		s.recordRevision(ctx, post, models.BlogRevisionUpdate, &userID, nil)
+	}
+	if tags != nil {
+		if err := s.setPostTags(ctx, post, tagList); err != nil {
+			return nil, err
+		}
+	}
 	if media != nil {
 		_ = s.mediaRepo.DeleteByPostID(ctx, postID)
 		for _, m := range media {
@@ -318,13 +548,13 @@ func (s *blogService) DeletePost(ctx context.Context, pharmacyID, userID, postID
 	if post.AuthorID != userID {
 		return errors.ErrForbidden("only the author can delete this post")
 	}
-	if post.Status == models.BlogPostStatusPublished {
-		return errors.ErrForbidden("cannot delete published post; contact manager")
+	if post.Status == models.BlogPostStatusPublished || post.Status == models.BlogPostStatusScheduled {
+		return errors.ErrForbidden("cannot delete " + post.Status + " post; contact manager")
 	}
 	return s.postRepo.Delete(ctx, postID)
 }
 
-func (s *blogService) ApprovePost(ctx context.Context, pharmacyID, postID uuid.UUID) (*models.BlogPost, error) {
+func (s *blogService) ApprovePost(ctx context.Context, pharmacyID, postID uuid.UUID, publishAt *time.Time) (*models.BlogPost, error) {
 	post, err := s.postRepo.GetByID(ctx, postID)
 	if err != nil {
 		return nil, err
@@ -332,18 +562,124 @@ func (s *blogService) ApprovePost(ctx context.Context, pharmacyID, postID uuid.U
 	if post.PharmacyID != pharmacyID {
 		return nil, errors.ErrNotFound("post")
 	}
-	if post.Status != models.BlogPostStatusPendingApproval {
+	if post.Status != models.BlogPostStatusPendingApproval && post.Status != models.BlogPostStatusScheduled {
 		return nil, errors.ErrValidation("post is not pending approval")
 	}
 	now := time.Now()
-	post.Status = models.BlogPostStatusPublished
-	post.PublishedAt = &now
+	if publishAt != nil && publishAt.After(now) {
+		if publishAt.After(now.AddDate(1, 0, 0)) {
+			return nil, errors.ErrValidation("publish_at must be within a year")
+		}
+		at := publishAt.UTC()
+		post.Status = models.BlogPostStatusScheduled
+		post.ScheduledAt = &at
+	} else {
+		post.Status = models.BlogPostStatusPublished
+		post.PublishedAt = &now
+		post.ScheduledAt = nil
+	}
 	if err := s.postRepo.Update(ctx, post); err != nil {
 		return nil, err
 	}
 	return post, nil
 }
 
+func (s *blogService) PublishScheduled(ctx context.Context) error {
+	n, err := s.postRepo.PublishScheduled(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("publish scheduled blog posts: %w", err)
+	}
+	if n > 0 {
+		s.logger.Info("scheduled blog posts published", zap.Int64("posts", n))
+	}
+	return nil
+}
+
+// editablePost loads a post of the pharmacy that the user may see the revisions of and restore: their own, or
+// any post when canManage.
+func (s *blogService) editablePost(ctx context.Context, pharmacyID, userID, postID uuid.UUID, canManage bool) (*models.BlogPost, error) {
+	post, err := s.postRepo.GetByID(ctx, postID)
+	if err != nil || post == nil || post.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("post")
+	}
+	if !canManage && post.AuthorID != userID {
+		return nil, errors.ErrForbidden("only the author or a manager can see this post's revisions")
+	}
+	return post, nil
+}
+
+func (s *blogService) ListRevisions(ctx context.Context, pharmacyID, userID, postID uuid.UUID, canManage bool, limit, offset int) ([]*models.BlogPostRevision, int64, error) {
+	if _, err := s.editablePost(ctx, pharmacyID, userID, postID, canManage); err != nil {
+		return nil, 0, err
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	list, total, err := s.revisionRepo.ListByPostID(ctx, postID, limit, offset)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to list revisions", err)
+	}
+	return list, total, nil
+}
+
+func (s *blogService) RestoreRevision(ctx context.Context, pharmacyID, userID, postID, revisionID uuid.UUID, canManage bool) (*models.BlogPost, error) {
+	post, err := s.editablePost(ctx, pharmacyID, userID, postID, canManage)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage && (post.Status == models.BlogPostStatusPublished || post.Status == models.BlogPostStatusScheduled) {
+		return nil, errors.ErrForbidden("cannot edit " + post.Status + " post")
+	}
+	rev, err := s.revisionRepo.GetByID(ctx, revisionID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load revision", err)
+	}
+	if rev == nil || rev.PostID != post.ID {
+		return nil, errors.ErrNotFound("revision")
+	}
+	s.recordBaseline(ctx, post)
+	// A published post keeps its slug so existing links still work.
+	if rev.Title != post.Title && post.Status != models.BlogPostStatusPublished {
+		post.Slug = s.ensureUniqueSlug(ctx, pharmacyID, slugFromTitle(rev.Title), &post.ID)
+	}
+	post.Title, post.Excerpt, post.Body = rev.Title, rev.Excerpt, rev.Body
+	if err := s.postRepo.Update(ctx, post); err != nil {
+		return nil, errors.ErrInternal("failed to restore revision", err)
+	}
+	s.recordRevision(ctx, post, models.BlogRevisionRestore, &userID, &rev.Revision)
+	return post, nil
+}
+
+// recordBaseline snapshots a post written before revisions existed, so its first edit can be undone. The
+// repository skips it if another edit recorded a revision in the meantime.
+func (s *blogService) recordBaseline(ctx context.Context, post *models.BlogPost) {
+	latest, err := s.revisionRepo.LatestRevision(ctx, post.ID)
+	if err != nil || latest > 0 {
+		return
+	}
+	s.recordRevision(ctx, post, models.BlogRevisionBaseline, nil, nil)
+}
+
+// recordRevision appends a content snapshot; the repository numbers it. Revisions are best effort: the post
+// itself is already saved.
+func (s *blogService) recordRevision(ctx context.Context, post *models.BlogPost, action string, editorID *uuid.UUID, restoredFrom *int) {
+	rev := &models.BlogPostRevision{
+		PostID:       post.ID,
+		Action:       action,
+		RestoredFrom: restoredFrom,
+		Title:        post.Title,
+		Excerpt:      post.Excerpt,
+		Body:         post.Body,
+		EditorID:     editorID,
+	}
+	if err := s.revisionRepo.Create(ctx, rev); err != nil {
+		s.logger.Warn("blog revisions: record revision failed", zap.String("post_id", post.ID.String()), zap.Error(err))
+	}
+}
+
 func (s *blogService) SubmitForApproval(ctx context.Context, pharmacyID, userID, postID uuid.UUID) (*models.BlogPost, error) {
 	post, err := s.postRepo.GetByID(ctx, postID)
 	if err != nil {
@@ -449,7 +785,7 @@ func (s *blogService) GetPostAnalytics(ctx context.Context, pharmacyID, postID u
 }
 
 func (s *blogService) GetAnalytics(ctx context.Context, pharmacyID uuid.UUID, limit int) ([]*inbound.BlogAnalytics, error) {
-	posts, _, err := s.postRepo.ListByPharmacy(ctx, pharmacyID, ptr(models.BlogPostStatusPublished), nil, limit, 0)
+	posts, _, err := s.postRepo.ListByPharmacy(ctx, pharmacyID, ptr(models.BlogPostStatusPublished), nil, nil, limit, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,406 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// fakeBlogPosts holds one post.
+type fakeBlogPosts struct {
+	outbound.BlogPostRepository
+	post       *models.BlogPost
+	deleted    bool
+	publishErr error
+	publishAt  time.Time
+	list       []*models.BlogPost
+	listErr    error
+	listStatus *string
+}
+
+func (f *fakeBlogPosts) GetByID(ctx context.Context, id uuid.UUID) (*models.BlogPost, error) {
+	if f.post == nil || f.post.ID != id {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := *f.post
+	return &cp, nil
+}
+
+func (f *fakeBlogPosts) GetByPharmacyAndSlug(ctx context.Context, pharmacyID uuid.UUID, slug string) (*models.BlogPost, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeBlogPosts) Update(ctx context.Context, p *models.BlogPost) error {
+	cp := *p
+	f.post = &cp
+	return nil
+}
+
+func (f *fakeBlogPosts) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, status *string, categoryID, tagID *uuid.UUID, limit, offset int) ([]*models.BlogPost, int64, error) {
+	f.listStatus = status
+	return f.list, int64(len(f.list)), f.listErr
+}
+
+func (f *fakeBlogPosts) Delete(ctx context.Context, id uuid.UUID) error {
+	f.deleted = true
+	return nil
+}
+
+func (f *fakeBlogPosts) PublishScheduled(ctx context.Context, now time.Time) (int64, error) {
+	f.publishAt = now
+	return 1, f.publishErr
+}
+
+// fakeBlogRevisions numbers revisions as the real repository does.
+type fakeBlogRevisions struct {
+	list []*models.BlogPostRevision
+}
+
+func (f *fakeBlogRevisions) Create(ctx context.Context, r *models.BlogPostRevision) error {
+	latest, _ := f.LatestRevision(ctx, r.PostID)
+	if r.Action == models.BlogRevisionBaseline && latest > 0 {
+		return nil
+	}
+	r.ID, r.Revision = uuid.New(), latest+1
+	f.list = append(f.list, r)
+	return nil
+}
+
+func (f *fakeBlogRevisions) GetByID(ctx context.Context, id uuid.UUID) (*models.BlogPostRevision, error) {
+	for _, r := range f.list {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeBlogRevisions) ListByPostID(ctx context.Context, postID uuid.UUID, limit, offset int) ([]*models.BlogPostRevision, int64, error) {
+	return f.list, int64(len(f.list)), nil
+}
+
+func (f *fakeBlogRevisions) LatestRevision(ctx context.Context, postID uuid.UUID) (int, error) {
+	latest := 0
+	for _, r := range f.list {
+		if r.PostID == postID && r.Revision > latest {
+			latest = r.Revision
+		}
+	}
+	return latest, nil
+}
+
+func TestBlogService_RestoreRevision_RecordsNextRevision(t *testing.T) {
+	pharmacyID, authorID := uuid.New(), uuid.New()
+	post := &models.BlogPost{ID: uuid.New(), PharmacyID: pharmacyID, AuthorID: authorID, Title: "Flu tips", Slug: "flu-tips", Body: "v8", Status: models.BlogPostStatusDraft}
+	posts := &fakeBlogPosts{post: post}
+	old := &models.BlogPostRevision{ID: uuid.New(), PostID: uuid.New(), Revision: 7, Title: "Winter flu tips", Body: "v7"}
+	revisions := &fakeBlogRevisions{list: []*models.BlogPostRevision{old}}
+	svc := &blogService{postRepo: posts, revisionRepo: revisions, logger: zap.NewNop()}
+	ctx := context.Background()
+
+	if _, err := svc.RestoreRevision(ctx, pharmacyID, authorID, post.ID, old.ID, false); err == nil {
+		t.Fatal("restored a revision of another post")
+	}
+
+	old.PostID = post.ID
+	restored, err := svc.RestoreRevision(ctx, pharmacyID, authorID, post.ID, old.ID, false)
+	if err != nil {
+		t.Fatalf("RestoreRevision: %v", err)
+	}
+	if restored.Title != "Winter flu tips" || posts.post.Body != "v7" {
+		t.Errorf("post = %+v, want revision 7's content", posts.post)
+	}
+	last := revisions.list[len(revisions.list)-1]
+	if len(revisions.list) != 2 || last.Revision != 8 || last.Action != models.BlogRevisionRestore || last.RestoredFrom == nil || *last.RestoredFrom != 7 {
+		t.Errorf("revisions = %d, last = %+v; want restore 8 from 7", len(revisions.list), last)
+	}
+}
+
+func TestBlogService_RecordBaseline_OnlyForPostsWithoutRevisions(t *testing.T) {
+	post := &models.BlogPost{ID: uuid.New(), Title: "Flu tips", Body: "v1"}
+	revisions := &fakeBlogRevisions{}
+	svc := &blogService{revisionRepo: revisions, logger: zap.NewNop()}
+	ctx := context.Background()
+
+	svc.recordBaseline(ctx, post)
+	svc.recordBaseline(ctx, post)
+	svc.recordRevision(ctx, post, models.BlogRevisionUpdate, nil, nil)
+	if len(revisions.list) != 2 || revisions.list[0].Action != models.BlogRevisionBaseline || revisions.list[1].Revision != 2 {
+		t.Errorf("revisions = %+v, want one baseline then update 2", revisions.list)
+	}
+}
+
+func TestBlogService_ApprovePost_SchedulesOrPublishes(t *testing.T) {
+	pharmacyID := uuid.New()
+	ctx := context.Background()
+	pending := func() *fakeBlogPosts {
+		return &fakeBlogPosts{post: &models.BlogPost{ID: uuid.New(), PharmacyID: pharmacyID, Status: models.BlogPostStatusPendingApproval}}
+	}
+
+	posts := pending()
+	svc := &blogService{postRepo: posts, logger: zap.NewNop()}
+	at := time.Now().Add(48 * time.Hour)
+	post, err := svc.ApprovePost(ctx, pharmacyID, posts.post.ID, &at)
+	if err != nil || post.Status != models.BlogPostStatusScheduled || post.ScheduledAt == nil || !post.ScheduledAt.Equal(at) || post.PublishedAt != nil {
+		t.Fatalf("future publish_at: post = %+v, err = %v; want scheduled", post, err)
+	}
+	// A scheduled post can be approved again to publish it now.
+	post, err = svc.ApprovePost(ctx, pharmacyID, posts.post.ID, nil)
+	if err != nil || post.Status != models.BlogPostStatusPublished || post.PublishedAt == nil || post.ScheduledAt != nil {
+		t.Fatalf("publish now: post = %+v, err = %v", post, err)
+	}
+	if _, err := svc.ApprovePost(ctx, pharmacyID, posts.post.ID, nil); appCode(err) != pkgerrors.ErrCodeValidation {
+		t.Errorf("approving a published post: err = %v, want validation error", err)
+	}
+
+	posts = pending()
+	svc.postRepo = posts
+	past := time.Now().Add(-time.Hour)
+	if post, err := svc.ApprovePost(ctx, pharmacyID, posts.post.ID, &past); err != nil || post.Status != models.BlogPostStatusPublished {
+		t.Errorf("past publish_at: post = %+v, err = %v; want published now", post, err)
+	}
+
+	posts = pending()
+	svc.postRepo = posts
+	tooFar := time.Now().AddDate(1, 0, 1)
+	if _, err := svc.ApprovePost(ctx, pharmacyID, posts.post.ID, &tooFar); appCode(err) != pkgerrors.ErrCodeValidation {
+		t.Errorf("publish_at over a year out: err = %v, want validation error", err)
+	}
+	if _, err := svc.ApprovePost(ctx, uuid.New(), posts.post.ID, nil); appCode(err) != pkgerrors.ErrCodeNotFound {
+		t.Errorf("another pharmacy's post: err = %v, want not found", err)
+	}
+}
+
+func TestBlogService_ScheduledPostIsLockedForAuthor(t *testing.T) {
+	pharmacyID, authorID := uuid.New(), uuid.New()
+	at := time.Now().Add(time.Hour)
+	posts := &fakeBlogPosts{post: &models.BlogPost{ID: uuid.New(), PharmacyID: pharmacyID, AuthorID: authorID, Status: models.BlogPostStatusScheduled, ScheduledAt: &at}}
+	svc := &blogService{postRepo: posts, revisionRepo: &fakeBlogRevisions{}, logger: zap.NewNop()}
+	ctx := context.Background()
+	title := "New title"
+
+	if _, err := svc.UpdatePost(ctx, pharmacyID, authorID, posts.post.ID, &title, nil, nil, nil, nil, nil, nil); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeForbidden {
+		t.Errorf("edit scheduled post: err = %v, want forbidden", err)
+	}
+	if err := svc.DeletePost(ctx, pharmacyID, authorID, posts.post.ID); pkgerrors.GetAppError(err) == nil || posts.deleted {
+		t.Errorf("delete scheduled post: err = %v, deleted = %v; want forbidden", err, posts.deleted)
+	}
+}
+
+func TestBlogService_PublishScheduled(t *testing.T) {
+	posts := &fakeBlogPosts{}
+	svc := &blogService{postRepo: posts, logger: zap.NewNop()}
+	if err := svc.PublishScheduled(context.Background()); err != nil || time.Since(posts.publishAt) > time.Minute {
+		t.Errorf("err = %v, published up to %v; want now", err, posts.publishAt)
+	}
+	posts.publishErr = errors.New("connection reset")
+	if err := svc.PublishScheduled(context.Background()); err == nil || !errors.Is(err, posts.publishErr) {
+		t.Errorf("err = %v, want the repository error wrapped", err)
+	}
+}
+
+// fakeBlogTags keeps tags and post links in memory.
+type fakeBlogTags struct {
+	tags     []*models.BlogTag
+	postTags map[uuid.UUID][]uuid.UUID
+}
+
+func (f *fakeBlogTags) Create(ctx context.Context, t *models.BlogTag) error {
+	t.ID = uuid.New()
+	f.tags = append(f.tags, t)
+	return nil
+}
+
+func (f *fakeBlogTags) GetByID(ctx context.Context, id uuid.UUID) (*models.BlogTag, error) {
+	for _, t := range f.tags {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeBlogTags) GetBySlug(ctx context.Context, pharmacyID uuid.UUID, slug string) (*models.BlogTag, error) {
+	for _, t := range f.tags {
+		if t.PharmacyID == pharmacyID && t.Slug == slug {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeBlogTags) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, publishedOnly bool) ([]*models.BlogTag, error) {
+	var out []*models.BlogTag
+	for _, t := range f.tags {
+		if t.PharmacyID == pharmacyID && (!publishedOnly || t.PostCount > 0) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeBlogTags) Update(ctx context.Context, t *models.BlogTag) error { return nil }
+
+func (f *fakeBlogTags) Delete(ctx context.Context, id uuid.UUID) error {
+	for i, t := range f.tags {
+		if t.ID == id {
+			f.tags = append(f.tags[:i], f.tags[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (f *fakeBlogTags) ListByPostID(ctx context.Context, postID uuid.UUID) ([]*models.BlogTag, error) {
+	var out []*models.BlogTag
+	for _, id := range f.postTags[postID] {
+		t, _ := f.GetByID(ctx, id)
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func (f *fakeBlogTags) SetPostTags(ctx context.Context, postID uuid.UUID, tagIDs []uuid.UUID) error {
+	if f.postTags == nil {
+		f.postTags = map[uuid.UUID][]uuid.UUID{}
+	}
+	f.postTags[postID] = tagIDs
+	return nil
+}
+
+func TestBlogService_Tags_CreateRenameDelete(t *testing.T) {
+	pharmacyID := uuid.New()
+	tags := &fakeBlogTags{}
+	svc := &blogService{tagRepo: tags, logger: zap.NewNop()}
+	ctx := context.Background()
+
+	flu, err := svc.CreateTag(ctx, pharmacyID, "  Flu Season ")
+	if err != nil || flu.Name != "Flu Season" || flu.Slug != "flu-season" {
+		t.Fatalf("CreateTag = %+v, %v", flu, err)
+	}
+	if _, err := svc.CreateTag(ctx, pharmacyID, "flu season!"); appCode(err) != pkgerrors.ErrCodeConflict {
+		t.Errorf("same slug: err = %v, want conflict", err)
+	}
+	if _, err := svc.CreateTag(ctx, pharmacyID, "?!"); appCode(err) != pkgerrors.ErrCodeValidation {
+		t.Errorf("no letters: err = %v, want validation error", err)
+	}
+	if _, err := svc.CreateTag(ctx, uuid.New(), "Flu season"); err != nil {
+		t.Errorf("same name in another pharmacy: %v", err)
+	}
+
+	kids, _ := svc.CreateTag(ctx, pharmacyID, "Kids")
+	if _, err := svc.UpdateTag(ctx, pharmacyID, kids.ID, "FLU season"); appCode(err) != pkgerrors.ErrCodeConflict {
+		t.Errorf("rename onto another tag: err = %v, want conflict", err)
+	}
+	if tag, err := svc.UpdateTag(ctx, pharmacyID, flu.ID, "Flu Season 2026"); err != nil || tag.Slug != "flu-season-2026" {
+		t.Errorf("rename = %+v, %v", tag, err)
+	}
+	if _, err := svc.UpdateTag(ctx, uuid.New(), flu.ID, "Mine"); appCode(err) != pkgerrors.ErrCodeNotFound {
+		t.Errorf("rename another pharmacy's tag: err = %v, want not found", err)
+	}
+	if err := svc.DeleteTag(ctx, uuid.New(), flu.ID); appCode(err) != pkgerrors.ErrCodeNotFound {
+		t.Errorf("delete another pharmacy's tag: err = %v, want not found", err)
+	}
+	if err := svc.DeleteTag(ctx, pharmacyID, flu.ID); err != nil {
+		t.Errorf("DeleteTag: %v", err)
+	}
+	if tag, _ := tags.GetByID(ctx, flu.ID); tag != nil {
+		t.Error("tag still stored after delete")
+	}
+}
+
+func TestBlogService_ResolveTags_DedupesCreatesAndCaps(t *testing.T) {
+	pharmacyID := uuid.New()
+	tags := &fakeBlogTags{}
+	svc := &blogService{tagRepo: tags, logger: zap.NewNop()}
+	ctx := context.Background()
+	existing, _ := svc.CreateTag(ctx, pharmacyID, "Vitamins")
+
+	list, err := svc.resolveTags(ctx, pharmacyID, []string{"vitamins", "Cold & Flu", "cold-flu", " ", "VITAMINS"})
+	if err != nil {
+		t.Fatalf("resolveTags: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != existing.ID || list[1].Slug != "cold-flu" || len(tags.tags) != 2 {
+		t.Errorf("tags = %+v (stored %d), want the existing Vitamins and a new cold-flu", list, len(tags.tags))
+	}
+
+	names := make([]string, models.MaxBlogPostTags+1)
+	for i := range names {
+		names[i] = "tag " + string(rune('a'+i))
+	}
+	if _, err := svc.resolveTags(ctx, pharmacyID, names); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("%d tags: err = %v, want validation error", len(names), err)
+	}
+}
+
+func TestBlogService_TagCloud_BusiestFirst(t *testing.T) {
+	pharmacyID := uuid.New()
+	tags := &fakeBlogTags{tags: []*models.BlogTag{
+		{ID: uuid.New(), PharmacyID: pharmacyID, Slug: "a", PostCount: 1},
+		{ID: uuid.New(), PharmacyID: pharmacyID, Slug: "b", PostCount: 5},
+		{ID: uuid.New(), PharmacyID: pharmacyID, Slug: "unused"},
+		{ID: uuid.New(), PharmacyID: pharmacyID, Slug: "c", PostCount: 3},
+	}}
+	svc := &blogService{tagRepo: tags, logger: zap.NewNop()}
+
+	cloud, err := svc.TagCloud(context.Background(), pharmacyID, 2)
+	if err != nil || len(cloud) != 2 || cloud[0].Slug != "b" || cloud[1].Slug != "c" {
+		t.Errorf("cloud = %+v, %v; want b then c", cloud, err)
+	}
+}
+
+func TestBlogService_Feed_NewestPublicationFirst(t *testing.T) {
+	pharmacy := &models.Pharmacy{ID: uuid.New(), Name: "Care Pharmacy", IsActive: true, UpdatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 9, 0, 0, 0, time.UTC) }
+	published := func(d int) *time.Time { t := day(d); return &t }
+	// Listed by creation: the older post was published (from a schedule) after the newer one.
+	scheduled := &models.BlogPost{ID: uuid.New(), Slug: "flu-tips", CreatedAt: day(1), UpdatedAt: day(10), PublishedAt: published(10)}
+	direct := &models.BlogPost{ID: uuid.New(), Slug: "hydration", CreatedAt: day(2), UpdatedAt: day(3), PublishedAt: published(3)}
+	legacy := &models.BlogPost{ID: uuid.New(), Slug: "welcome", CreatedAt: day(5), UpdatedAt: day(5)}
+	posts := &fakeBlogPosts{list: []*models.BlogPost{legacy, direct, scheduled}}
+	tags := &fakeBlogTags{tags: []*models.BlogTag{{ID: uuid.New(), Slug: "flu"}}}
+	tags.SetPostTags(context.Background(), scheduled.ID, []uuid.UUID{tags.tags[0].ID})
+	pharmacies := &mocks.MockPharmacyRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Pharmacy, error) {
+		if id == pharmacy.ID {
+			return pharmacy, nil
+		}
+		return &models.Pharmacy{ID: id}, nil
+	}}
+	svc := &blogService{postRepo: posts, tagRepo: tags, pharmacyRepo: pharmacies, publicURL: "https://shop.example.com", logger: zap.NewNop()}
+	ctx := context.Background()
+
+	feed, err := svc.Feed(ctx, pharmacy.ID)
+	if err != nil {
+		t.Fatalf("Feed: %v", err)
+	}
+	if posts.listStatus == nil || *posts.listStatus != models.BlogPostStatusPublished {
+		t.Errorf("listed status %v, want published only", posts.listStatus)
+	}
+	var order []string
+	for _, it := range feed.Items {
+		order = append(order, it.Post.Slug)
+	}
+	if len(order) != 3 || order[0] != "flu-tips" || order[1] != "welcome" || order[2] != "hydration" {
+		t.Errorf("items = %v, want flu-tips, welcome (creation date), hydration", order)
+	}
+	if feed.Title != "Care Pharmacy" || !feed.Updated.Equal(day(10)) || feed.Items[0].Link != "https://shop.example.com/blog/flu-tips" || len(feed.Items[0].Post.Tags) != 1 {
+		t.Errorf("feed = %+v, first item = %+v", feed, feed.Items[0])
+	}
+
+	if _, err := svc.Feed(ctx, uuid.New()); appCode(err) != pkgerrors.ErrCodeNotFound {
+		t.Errorf("inactive pharmacy: err = %v, want not found", err)
+	}
+	posts.listErr = errors.New("connection reset")
+	if _, err := svc.Feed(ctx, pharmacy.ID); appCode(err) != pkgerrors.ErrCodeInternal {
+		t.Errorf("list failure: err = %v, want internal error", err)
+	}
+}
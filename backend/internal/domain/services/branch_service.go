@@ -0,0 +1,373 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type branchService struct {
+	branchRepo  outbound.BranchRepository
+	batchRepo   outbound.InventoryBatchRepository
+	productRepo outbound.ProductRepository
+	userRepo    outbound.UserRepository
+	logger      *zap.Logger
+	now         func() time.Time
+}
+
+func NewBranchService(
+	branchRepo outbound.BranchRepository,
+	batchRepo outbound.InventoryBatchRepository,
+	productRepo outbound.ProductRepository,
+	userRepo outbound.UserRepository,
+	logger *zap.Logger,
+) inbound.BranchService {
+	return &branchService{
+		branchRepo:  branchRepo,
+		batchRepo:   batchRepo,
+		productRepo: productRepo,
+		userRepo:    userRepo,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+func (s *branchService) Create(ctx context.Context, pharmacyID uuid.UUID, input inbound.BranchInput) (*models.Branch, error) {
+	existing, err := s.branchRepo.ListByPharmacy(ctx, pharmacyID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load branches", err)
+	}
+	b := &models.Branch{PharmacyID: pharmacyID, IsActive: true}
+	if err := applyBranchInput(b, input, existing); err != nil {
+		return nil, err
+	}
+	first := len(existing) == 0
+	if first {
+		b.IsDefault, b.IsActive = true, true
+	}
+	if err := s.branchRepo.Create(ctx, b); err != nil {
+		return nil, errors.ErrInternal("failed to create branch", err)
+	}
+	if first {
+		n, err := s.branchRepo.AdoptUnassignedBatches(ctx, pharmacyID, b.ID)
+		if err != nil {
+			return nil, errors.ErrInternal("failed to assign existing stock to the branch", err)
+		}
+		s.logger.Info("first branch adopted pharmacy stock", zap.String("branch_id", b.ID.String()), zap.Int64("batches", n))
+	} else if b.IsDefault {
+		if err := s.branchRepo.SetDefault(ctx, pharmacyID, b.ID); err != nil {
+			return nil, errors.ErrInternal("failed to set default branch", err)
+		}
+	}
+	return b, nil
+}
+
+// applyBranchInput copies the input onto b, checking the code is unique among the pharmacy's other branches.
+func applyBranchInput(b *models.Branch, input inbound.BranchInput, others []*models.Branch) error {
+	code := strings.ToUpper(strings.TrimSpace(input.Code))
+	name := strings.TrimSpace(input.Name)
+	if code == "" || name == "" {
+		return errors.ErrValidation("code and name are required")
+	}
+	for _, o := range others {
+		if o.ID != b.ID && strings.EqualFold(o.Code, code) {
+			return errors.ErrConflict("a branch with this code already exists")
+		}
+	}
+	b.Code, b.Name = code, name
+	b.Address, b.Phone = strings.TrimSpace(input.Address), strings.TrimSpace(input.Phone)
+	if input.IsDefault {
+		b.IsDefault = true
+	}
+	if input.IsActive != nil {
+		b.IsActive = *input.IsActive
+	}
+	if b.IsDefault && !b.IsActive {
+		return errors.ErrValidation("the default branch must be active")
+	}
+	return nil
+}
+
+func (s *branchService) Get(ctx context.Context, pharmacyID, id uuid.UUID) (*models.Branch, error) {
+	b, err := s.branchRepo.GetByID(ctx, id)
+	if err != nil || b == nil || b.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("branch")
+	}
+	return b, nil
+}
+
+func (s *branchService) List(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Branch, error) {
+	list, err := s.branchRepo.ListByPharmacy(ctx, pharmacyID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list branches", err)
+	}
+	if list == nil {
+		list = []*models.Branch{}
+	}
+	return list, nil
+}
+
+func (s *branchService) Update(ctx context.Context, pharmacyID, id uuid.UUID, input inbound.BranchInput) (*models.Branch, error) {
+	b, err := s.Get(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	others, err := s.branchRepo.ListByPharmacy(ctx, pharmacyID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load branches", err)
+	}
+	wasDefault := b.IsDefault
+	if err := applyBranchInput(b, input, others); err != nil {
+		return nil, err
+	}
+	if err := s.branchRepo.Update(ctx, b); err != nil {
+		return nil, errors.ErrInternal("failed to update branch", err)
+	}
+	if b.IsDefault && !wasDefault {
+		if err := s.branchRepo.SetDefault(ctx, pharmacyID, b.ID); err != nil {
+			return nil, errors.ErrInternal("failed to set default branch", err)
+		}
+	}
+	return b, nil
+}
+
+func (s *branchService) Delete(ctx context.Context, pharmacyID, id uuid.UUID) error {
+	b, err := s.Get(ctx, pharmacyID, id)
+	if err != nil {
+		return err
+	}
+	if b.IsDefault {
+		all, err := s.branchRepo.ListByPharmacy(ctx, pharmacyID)
+		if err != nil {
+			return errors.ErrInternal("failed to load branches", err)
+		}
+		if len(all) > 1 {
+			return errors.ErrConflict("make another branch the default before deleting this one")
+		}
+	}
+	stock, err := s.branchRepo.Stock(ctx, id, startOfDay(s.now()))
+	if err != nil {
+		return errors.ErrInternal("failed to load branch stock", err)
+	}
+	for _, row := range stock {
+		if row.Quantity+row.Expired > 0 {
+			return errors.ErrConflict("the branch still holds stock; transfer or write it off first")
+		}
+	}
+	if err := s.branchRepo.Delete(ctx, id); err != nil {
+		return errors.ErrInternal("failed to delete branch", err)
+	}
+	return nil
+}
+
+func (s *branchService) AssignUser(ctx context.Context, pharmacyID, userID uuid.UUID, branchID *uuid.UUID) (*models.User, error) {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || u == nil || u.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("user")
+	}
+	if u.Role == models.RoleStaff {
+		return nil, errors.ErrValidation("only team members can be assigned to a branch")
+	}
+	if branchID != nil {
+		b, err := s.Get(ctx, pharmacyID, *branchID)
+		if err != nil {
+			return nil, err
+		}
+		if !b.IsActive {
+			return nil, errors.ErrValidation("branch is not active")
+		}
+	}
+	u.BranchID = branchID
+	if err := s.userRepo.Update(ctx, u); err != nil {
+		return nil, errors.ErrInternal("failed to assign branch", err)
+	}
+	return u, nil
+}
+
+func (s *branchService) Stock(ctx context.Context, pharmacyID, branchID uuid.UUID) ([]*models.BranchStockRow, error) {
+	if _, err := s.Get(ctx, pharmacyID, branchID); err != nil {
+		return nil, err
+	}
+	rows, err := s.branchRepo.Stock(ctx, branchID, startOfDay(s.now()))
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load branch stock", err)
+	}
+	if rows == nil {
+		rows = []*models.BranchStockRow{}
+	}
+	return rows, nil
+}
+
+// batchKey identifies "the same batch" at another branch: batch number and expiry day.
+func batchKey(b *models.InventoryBatch) string {
+	key := b.BatchNumber + "|"
+	if b.ExpiryDate != nil {
+		key += b.ExpiryDate.Format("2006-01-02")
+	}
+	return key
+}
+
+func (s *branchService) Transfer(ctx context.Context, pharmacyID, createdBy uuid.UUID, input inbound.BranchTransferInput) (*models.BranchTransfer, error) {
+	if input.FromBranchID == input.ToBranchID {
+		return nil, errors.ErrValidation("source and destination branch must differ")
+	}
+	from, err := s.Get(ctx, pharmacyID, input.FromBranchID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.Get(ctx, pharmacyID, input.ToBranchID)
+	if err != nil {
+		return nil, err
+	}
+	if !to.IsActive {
+		return nil, errors.ErrValidation("destination branch is not active")
+	}
+	if len(input.Items) == 0 {
+		return nil, errors.ErrValidation("at least one item is required")
+	}
+	// Merge repeated products so each is checked against its full quantity.
+	quantities := make(map[uuid.UUID]int)
+	var order []uuid.UUID
+	for _, it := range input.Items {
+		if it.Quantity <= 0 {
+			return nil, errors.ErrValidation("item quantity must be positive")
+		}
+		if _, ok := quantities[it.ProductID]; !ok {
+			order = append(order, it.ProductID)
+		}
+		quantities[it.ProductID] += it.Quantity
+	}
+
+	t := &models.BranchTransfer{
+		PharmacyID:   pharmacyID,
+		FromBranchID: from.ID,
+		ToBranchID:   to.ID,
+		Note:         strings.TrimSpace(input.Note),
+		CreatedBy:    createdBy,
+	}
+	var updated, created []*models.InventoryBatch
+	today := startOfDay(s.now())
+	for _, productID := range order {
+		prod, err := s.productRepo.GetByID(ctx, productID)
+		if err != nil || prod == nil || prod.PharmacyID != pharmacyID {
+			return nil, errors.ErrNotFound("product")
+		}
+		batches, err := s.batchRepo.ListByProductID(ctx, productID)
+		if err != nil {
+			return nil, errors.ErrInternal("failed to list batches", err)
+		}
+		var sources []*models.InventoryBatch
+		dest := make(map[string]*models.InventoryBatch)
+		for _, b := range batches {
+			switch {
+			case b.BranchID == nil:
+			case *b.BranchID == from.ID && !batchExpired(b, today):
+				sources = append(sources, b)
+			case *b.BranchID == to.ID:
+				dest[batchKey(b)] = b
+			}
+		}
+		sort.SliceStable(sources, func(i, j int) bool {
+			a, b := sources[i].ExpiryDate, sources[j].ExpiryDate
+			return a != nil && (b == nil || a.Before(*b))
+		})
+		available := 0
+		for _, b := range sources {
+			available += b.Quantity
+		}
+		want := quantities[productID]
+		if available < want {
+			return nil, errors.ErrValidation(fmt.Sprintf("only %d unexpired units of %s at %s", available, prod.Name, from.Name))
+		}
+		for _, src := range sources {
+			if want == 0 {
+				break
+			}
+			take := want
+			if take > src.Quantity {
+				take = src.Quantity
+			}
+			want -= take
+			src.Quantity -= take
+			updated = append(updated, src)
+			d, ok := dest[batchKey(src)]
+			if !ok {
+				d = &models.InventoryBatch{
+					ID:          uuid.New(),
+					ProductID:   src.ProductID,
+					PharmacyID:  pharmacyID,
+					BranchID:    &to.ID,
+					BatchNumber: src.BatchNumber,
+					ExpiryDate:  src.ExpiryDate,
+				}
+				dest[batchKey(src)] = d
+				created = append(created, d)
+			} else if !containsBatch(created, d) {
+				updated = appendBatchOnce(updated, d)
+			}
+			d.Quantity += take
+			t.Items = append(t.Items, models.BranchTransferItem{
+				ProductID:   src.ProductID,
+				FromBatchID: src.ID,
+				ToBatchID:   d.ID,
+				BatchNumber: src.BatchNumber,
+				ExpiryDate:  src.ExpiryDate,
+				Quantity:    take,
+			})
+		}
+	}
+	if err := s.branchRepo.CreateTransfer(ctx, t, updated, created); err != nil {
+		return nil, errors.ErrInternal("failed to record transfer", err)
+	}
+	return s.GetTransfer(ctx, pharmacyID, t.ID)
+}
+
+func containsBatch(list []*models.InventoryBatch, b *models.InventoryBatch) bool {
+	for _, x := range list {
+		if x == b {
+			return true
+		}
+	}
+	return false
+}
+
+func appendBatchOnce(list []*models.InventoryBatch, b *models.InventoryBatch) []*models.InventoryBatch {
+	if containsBatch(list, b) {
+		return list
+	}
+	return append(list, b)
+}
+
+func (s *branchService) GetTransfer(ctx context.Context, pharmacyID, id uuid.UUID) (*models.BranchTransfer, error) {
+	t, err := s.branchRepo.GetTransfer(ctx, id)
+	if err != nil || t == nil || t.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("transfer")
+	}
+	return t, nil
+}
+
+func (s *branchService) ListTransfers(ctx context.Context, pharmacyID uuid.UUID, branchID *uuid.UUID, limit, offset int) ([]*models.BranchTransfer, int64, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	list, total, err := s.branchRepo.ListTransfers(ctx, pharmacyID, branchID, limit, offset)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to list transfers", err)
+	}
+	if list == nil {
+		list = []*models.BranchTransfer{}
+	}
+	return list, total, nil
+}
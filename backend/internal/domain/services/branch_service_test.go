@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type branchFixture struct {
+	pharmacyID uuid.UUID
+	from, to   *models.Branch
+	product    *models.Product
+	batches    []*models.InventoryBatch
+	transfer   *models.BranchTransfer
+	updated    []*models.InventoryBatch
+	created    []*models.InventoryBatch
+	svc        *branchService
+}
+
+func newBranchFixture() *branchFixture {
+	f := &branchFixture{pharmacyID: uuid.New()}
+	f.from = &models.Branch{ID: uuid.New(), PharmacyID: f.pharmacyID, Code: "MAIN", Name: "Main", IsActive: true, IsDefault: true}
+	f.to = &models.Branch{ID: uuid.New(), PharmacyID: f.pharmacyID, Code: "EAST", Name: "East", IsActive: true}
+	f.product = &models.Product{ID: uuid.New(), PharmacyID: f.pharmacyID, Name: "Cetirizine 10mg"}
+	branches := &mocks.MockBranchRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Branch, error) {
+			for _, b := range []*models.Branch{f.from, f.to} {
+				if b.ID == id {
+					return b, nil
+				}
+			}
+			return nil, nil
+		},
+		CreateTransferFunc: func(ctx context.Context, t *models.BranchTransfer, updated, created []*models.InventoryBatch) error {
+			t.ID = uuid.New()
+			f.transfer, f.updated, f.created = t, updated, created
+			return nil
+		},
+		GetTransferFunc: func(ctx context.Context, id uuid.UUID) (*models.BranchTransfer, error) {
+			return f.transfer, nil
+		},
+	}
+	batches := &mocks.MockInventoryBatchRepository{
+		ListByProductIDFunc: func(ctx context.Context, productID uuid.UUID) ([]*models.InventoryBatch, error) {
+			return f.batches, nil
+		},
+	}
+	products := &mocks.MockProductRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+			return f.product, nil
+		},
+	}
+	f.svc = NewBranchService(branches, batches, products, &mocks.MockUserRepository{}, zap.NewNop()).(*branchService)
+	return f
+}
+
+func (f *branchFixture) transferInput(qty int) inbound.BranchTransferInput {
+	return inbound.BranchTransferInput{
+		FromBranchID: f.from.ID,
+		ToBranchID:   f.to.ID,
+		Items:        []inbound.BranchTransferItemInput{{ProductID: f.product.ID, Quantity: qty}},
+	}
+}
+
+// stock adds a batch of qty units at branch b expiring days from today.
+func (f *branchFixture) stock(b *models.Branch, number string, days, qty int) *models.InventoryBatch {
+	exp := startOfDay(time.Now()).AddDate(0, 0, days)
+	batch := &models.InventoryBatch{ID: uuid.New(), ProductID: f.product.ID, PharmacyID: f.pharmacyID, BranchID: &b.ID, BatchNumber: number, ExpiryDate: &exp, Quantity: qty}
+	f.batches = append(f.batches, batch)
+	return batch
+}
+
+func TestBranchService_Create_FirstBranchIsDefaultAndAdoptsStock(t *testing.T) {
+	var adopted uuid.UUID
+	repo := &mocks.MockBranchRepository{
+		AdoptUnassignedBatchesFunc: func(ctx context.Context, pharmacyID, branchID uuid.UUID) (int64, error) {
+			adopted = branchID
+			return 3, nil
+		},
+	}
+	svc := NewBranchService(repo, &mocks.MockInventoryBatchRepository{}, &mocks.MockProductRepository{}, &mocks.MockUserRepository{}, zap.NewNop())
+	b, err := svc.Create(context.Background(), uuid.New(), inbound.BranchInput{Code: "ktm-01", Name: "Kathmandu"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !b.IsDefault || !b.IsActive || b.Code != "KTM-01" {
+		t.Errorf("expected an active default branch with upper-cased code, got %+v", b)
+	}
+	if adopted != b.ID {
+		t.Errorf("expected unassigned batches to move to the first branch")
+	}
+}
+
+func TestBranchService_Transfer_MovesFirstExpiryAndSkipsExpired(t *testing.T) {
+	f := newBranchFixture()
+	expired := f.stock(f.from, "OLD", -2, 10)
+	soon := f.stock(f.from, "A1", 15, 4)
+	later := f.stock(f.from, "A2", 200, 10)
+	existing := f.stock(f.to, "A1", 15, 1)
+
+	tr, err := f.svc.Transfer(context.Background(), f.pharmacyID, uuid.New(), f.transferInput(6))
+	if err != nil {
+		t.Fatalf("Transfer: %v", err)
+	}
+	if expired.Quantity != 10 || soon.Quantity != 0 || later.Quantity != 8 {
+		t.Errorf("unexpected source quantities: expired=%d soon=%d later=%d", expired.Quantity, soon.Quantity, later.Quantity)
+	}
+	if existing.Quantity != 5 {
+		t.Errorf("expected the matching destination batch to be credited to 5, got %d", existing.Quantity)
+	}
+	if len(f.created) != 1 || f.created[0].BatchNumber != "A2" || f.created[0].Quantity != 2 || *f.created[0].BranchID != f.to.ID {
+		t.Fatalf("expected a new A2 batch of 2 at the destination, got %+v", f.created)
+	}
+	if len(f.updated) != 3 {
+		t.Errorf("expected both sources and the existing destination batch to be saved, got %d", len(f.updated))
+	}
+	if len(tr.Items) != 2 || tr.Items[0].ToBatchID != existing.ID || tr.Items[1].ToBatchID != f.created[0].ID {
+		t.Errorf("unexpected transfer items: %+v", tr.Items)
+	}
+}
+
+func TestBranchService_Transfer_ShortfallLeavesStockUntouched(t *testing.T) {
+	f := newBranchFixture()
+	f.stock(f.from, "OLD", -1, 50)
+	b := f.stock(f.from, "A1", 30, 3)
+
+	_, err := f.svc.Transfer(context.Background(), f.pharmacyID, uuid.New(), f.transferInput(4))
+	if pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation || !strings.Contains(err.Error(), "only 3") {
+		t.Fatalf("expected a validation error naming the 3 available units, got %v", err)
+	}
+	if b.Quantity != 3 || f.transfer != nil {
+		t.Errorf("a rejected transfer must not touch stock")
+	}
+}
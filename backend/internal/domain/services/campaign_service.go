@@ -0,0 +1,377 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	segmentPreviewSize = 20
+	campaignBatchSize  = 200
+)
+
+type campaignService struct {
+	segmentRepo     outbound.CustomerSegmentRepository
+	campaignRepo    outbound.CampaignRepository
+	notificationSvc inbound.NotificationService
+	smsSender       outbound.SMSSender
+	mailer          inbound.MailerService
+	configRepo      outbound.PharmacyConfigRepository
+	pharmacyRepo    outbound.PharmacyRepository
+	consentSvc      inbound.ConsentService
+	now             func() time.Time
+	logger          *zap.Logger
+}
+
+// NewCampaignService returns the service. SMS campaigns also need the pharmacy's sms_campaigns flag. Members
+// who have not opted in to the campaign's channel (see ConsentService) are skipped; consentSvc is nil only in
+// tests.
+func NewCampaignService(segmentRepo outbound.CustomerSegmentRepository, campaignRepo outbound.CampaignRepository, notificationSvc inbound.NotificationService, smsSender outbound.SMSSender, mailer inbound.MailerService, configRepo outbound.PharmacyConfigRepository, pharmacyRepo outbound.PharmacyRepository, consentSvc inbound.ConsentService, logger *zap.Logger) inbound.CampaignService {
+	return &campaignService{segmentRepo: segmentRepo, campaignRepo: campaignRepo, notificationSvc: notificationSvc, smsSender: smsSender, mailer: mailer, configRepo: configRepo, pharmacyRepo: pharmacyRepo, consentSvc: consentSvc, now: time.Now, logger: logger}
+}
+
+// campaignConsentChannels maps campaign channels to the consent they need; notification campaigns reach the
+// customer's devices, so they need push consent.
+var campaignConsentChannels = map[models.CampaignChannel]models.ConsentChannel{
+	models.CampaignChannelNotification: models.ConsentChannelPush,
+	models.CampaignChannelSMS:          models.ConsentChannelSMS,
+	models.CampaignChannelEmail:        models.ConsentChannelEmail,
+}
+
+func validateSegmentCriteria(c models.SegmentCriteria) error {
+	if (c.MinPoints != nil && *c.MinPoints < 0) || (c.MaxPoints != nil && *c.MaxPoints < 0) {
+		return errors.ErrValidation("points bounds must not be negative")
+	}
+	if c.MinPoints != nil && c.MaxPoints != nil && *c.MinPoints > *c.MaxPoints {
+		return errors.ErrValidation("min_points must not exceed max_points")
+	}
+	if (c.MinTotalSpend != nil && *c.MinTotalSpend < 0) || (c.MaxTotalSpend != nil && *c.MaxTotalSpend < 0) {
+		return errors.ErrValidation("spend bounds must not be negative")
+	}
+	if c.MinTotalSpend != nil && c.MaxTotalSpend != nil && *c.MinTotalSpend > *c.MaxTotalSpend {
+		return errors.ErrValidation("min_total_spend must not exceed max_total_spend")
+	}
+	if c.LastPurchaseAfter != nil && c.LastPurchaseBefore != nil && !c.LastPurchaseAfter.Before(*c.LastPurchaseBefore) {
+		return errors.ErrValidation("last_purchase_after must be before last_purchase_before")
+	}
+	return nil
+}
+
+func (s *campaignService) CreateSegment(ctx context.Context, pharmacyID, actorID uuid.UUID, in inbound.SegmentInput) (*models.CustomerSegment, error) {
+	if err := validateSegmentCriteria(in.Criteria); err != nil {
+		return nil, err
+	}
+	seg := &models.CustomerSegment{PharmacyID: pharmacyID, Name: strings.TrimSpace(in.Name), Description: strings.TrimSpace(in.Description), Criteria: in.Criteria, CreatedBy: actorID}
+	if err := s.segmentRepo.Create(ctx, seg); err != nil {
+		return nil, errors.ErrInternal("failed to create segment", err)
+	}
+	return seg, nil
+}
+
+func (s *campaignService) ListSegments(ctx context.Context, pharmacyID uuid.UUID) ([]*models.CustomerSegment, error) {
+	list, err := s.segmentRepo.ListByPharmacy(ctx, pharmacyID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list segments", err)
+	}
+	return list, nil
+}
+
+func (s *campaignService) segment(ctx context.Context, pharmacyID, id uuid.UUID) (*models.CustomerSegment, error) {
+	seg, err := s.segmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load segment", err)
+	}
+	if seg == nil || seg.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("segment")
+	}
+	return seg, nil
+}
+
+func (s *campaignService) UpdateSegment(ctx context.Context, pharmacyID, id uuid.UUID, in inbound.SegmentInput) (*models.CustomerSegment, error) {
+	seg, err := s.segment(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSegmentCriteria(in.Criteria); err != nil {
+		return nil, err
+	}
+	seg.Name, seg.Description, seg.Criteria = strings.TrimSpace(in.Name), strings.TrimSpace(in.Description), in.Criteria
+	if err := s.segmentRepo.Update(ctx, seg); err != nil {
+		return nil, errors.ErrInternal("failed to update segment", err)
+	}
+	return seg, nil
+}
+
+func (s *campaignService) DeleteSegment(ctx context.Context, pharmacyID, id uuid.UUID) error {
+	if _, err := s.segment(ctx, pharmacyID, id); err != nil {
+		return err
+	}
+	if err := s.segmentRepo.Delete(ctx, id); err != nil {
+		return errors.ErrInternal("failed to delete segment", err)
+	}
+	return nil
+}
+
+func (s *campaignService) PreviewSegment(ctx context.Context, pharmacyID, id uuid.UUID) (*inbound.SegmentPreview, error) {
+	seg, err := s.segment(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	sample, count, err := s.segmentRepo.Members(ctx, pharmacyID, seg.Criteria, segmentPreviewSize)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to match segment", err)
+	}
+	if sample == nil {
+		sample = []*models.SegmentMember{}
+	}
+	return &inbound.SegmentPreview{Count: count, Sample: sample}, nil
+}
+
+func (s *campaignService) applyInput(ctx context.Context, pharmacyID uuid.UUID, c *models.Campaign, in inbound.CampaignInput) error {
+	if _, err := s.segment(ctx, pharmacyID, in.SegmentID); err != nil {
+		return err
+	}
+	in.Subject, in.Message = strings.TrimSpace(in.Subject), strings.TrimSpace(in.Message)
+	if in.Channel == models.CampaignChannelEmail && in.Subject == "" {
+		return errors.ErrValidation("email campaigns need a subject")
+	}
+	if in.Message == "" {
+		return errors.ErrValidation("message is required")
+	}
+	c.Name, c.SegmentID, c.Channel, c.Subject, c.Message = strings.TrimSpace(in.Name), in.SegmentID, in.Channel, in.Subject, in.Message
+	return nil
+}
+
+func (s *campaignService) Create(ctx context.Context, pharmacyID, actorID uuid.UUID, in inbound.CampaignInput) (*models.Campaign, error) {
+	c := &models.Campaign{PharmacyID: pharmacyID, Status: models.CampaignStatusDraft, CreatedBy: actorID}
+	if err := s.applyInput(ctx, pharmacyID, c, in); err != nil {
+		return nil, err
+	}
+	if err := s.campaignRepo.Create(ctx, c); err != nil {
+		return nil, errors.ErrInternal("failed to create campaign", err)
+	}
+	return c, nil
+}
+
+func (s *campaignService) List(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.Campaign, int64, error) {
+	list, total, err := s.campaignRepo.ListByPharmacy(ctx, pharmacyID, limit, offset)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to list campaigns", err)
+	}
+	return list, total, nil
+}
+
+func (s *campaignService) Get(ctx context.Context, pharmacyID, id uuid.UUID) (*models.Campaign, error) {
+	c, err := s.campaignRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load campaign", err)
+	}
+	if c == nil || c.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("campaign")
+	}
+	return c, nil
+}
+
+func (s *campaignService) draft(ctx context.Context, pharmacyID, id uuid.UUID) (*models.Campaign, error) {
+	c, err := s.Get(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status != models.CampaignStatusDraft {
+		return nil, errors.ErrConflict("the campaign has already been sent")
+	}
+	return c, nil
+}
+
+func (s *campaignService) Update(ctx context.Context, pharmacyID, id uuid.UUID, in inbound.CampaignInput) (*models.Campaign, error) {
+	c, err := s.draft(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyInput(ctx, pharmacyID, c, in); err != nil {
+		return nil, err
+	}
+	c.Segment = nil
+	if err := s.campaignRepo.Update(ctx, c); err != nil {
+		return nil, errors.ErrInternal("failed to update campaign", err)
+	}
+	return c, nil
+}
+
+func (s *campaignService) Delete(ctx context.Context, pharmacyID, id uuid.UUID) error {
+	if _, err := s.draft(ctx, pharmacyID, id); err != nil {
+		return err
+	}
+	if err := s.campaignRepo.Delete(ctx, id); err != nil {
+		return errors.ErrInternal("failed to delete campaign", err)
+	}
+	return nil
+}
+
+func (s *campaignService) Send(ctx context.Context, pharmacyID, id uuid.UUID) (*models.Campaign, error) {
+	c, err := s.draft(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	seg, err := s.segment(ctx, pharmacyID, c.SegmentID)
+	if err != nil {
+		return nil, err
+	}
+	senderName, err := s.checkChannel(ctx, pharmacyID, c.Channel)
+	if err != nil {
+		return nil, err
+	}
+	members, _, err := s.segmentRepo.Members(ctx, pharmacyID, seg.Criteria, 0)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to match segment", err)
+	}
+	if len(members) == 0 {
+		return nil, errors.ErrValidation("the segment matches no customers")
+	}
+	now := s.now()
+	c.Criteria, c.RecipientCount, c.SentAt = seg.Criteria, len(members), &now
+	started, err := s.campaignRepo.StartSending(ctx, c)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to start campaign", err)
+	}
+	if !started {
+		return nil, errors.ErrConflict("the campaign has already been sent")
+	}
+	c.Status = models.CampaignStatusSending
+	go s.deliver(context.WithoutCancel(ctx), c, senderName, members)
+	return c, nil
+}
+
+// checkChannel reports whether the campaign's channel can be used, and returns the sender name for SMS.
+func (s *campaignService) checkChannel(ctx context.Context, pharmacyID uuid.UUID, ch models.CampaignChannel) (string, error) {
+	switch ch {
+	case models.CampaignChannelNotification:
+		if s.notificationSvc == nil {
+			return "", errors.ErrValidation("notifications are not available")
+		}
+	case models.CampaignChannelEmail:
+		if s.mailer == nil {
+			return "", errors.ErrValidation("email is not configured")
+		}
+	case models.CampaignChannelSMS:
+		name, enabled := smsSettings(ctx, s.configRepo, s.pharmacyRepo, pharmacyID, models.FeatureSMSCampaigns)
+		if s.smsSender == nil || !enabled {
+			return "", errors.ErrValidation("SMS campaigns are not enabled for this pharmacy")
+		}
+		return name, nil
+	default:
+		return "", errors.ErrValidation("unsupported channel")
+	}
+	return "", nil
+}
+
+// deliver sends the campaign to each member who opted in to its channel and records the outcomes in batches,
+// then marks it sent. Consent is read per batch, just before sending, so a late opt-out is respected.
+func (s *campaignService) deliver(ctx context.Context, c *models.Campaign, senderName string, members []*models.SegmentMember) {
+	batch := make([]*models.CampaignRecipient, 0, campaignBatchSize)
+	flush := func() {
+		if err := s.campaignRepo.RecordRecipients(ctx, c.ID, batch); err != nil {
+			s.logger.Error("failed to record campaign recipients", zap.Error(err), zap.String("campaign_id", c.ID.String()))
+		}
+		batch = batch[:0]
+	}
+	for start := 0; start < len(members); start += campaignBatchSize {
+		chunk := members[start:min(start+campaignBatchSize, len(members))]
+		consented, err := s.optedIn(ctx, c, chunk)
+		for _, m := range chunk {
+			switch {
+			case err != nil:
+				batch = append(batch, skipped(&models.CampaignRecipient{CustomerID: m.CustomerID}, "consent check failed"))
+			case consented != nil && !consented[m.CustomerID]:
+				batch = append(batch, skipped(&models.CampaignRecipient{CustomerID: m.CustomerID}, "no marketing consent"))
+			default:
+				batch = append(batch, s.sendTo(ctx, c, senderName, m))
+			}
+			if len(batch) == campaignBatchSize {
+				flush()
+			}
+		}
+	}
+	if len(batch) > 0 {
+		flush()
+	}
+	if err := s.campaignRepo.FinishSending(ctx, c.ID, s.now()); err != nil {
+		s.logger.Error("failed to finish campaign", zap.Error(err), zap.String("campaign_id", c.ID.String()))
+	}
+}
+
+// optedIn returns which members opted in to the campaign's channel; nil when consent is not checked.
+func (s *campaignService) optedIn(ctx context.Context, c *models.Campaign, members []*models.SegmentMember) (map[uuid.UUID]bool, error) {
+	if s.consentSvc == nil {
+		return nil, nil
+	}
+	ids := make([]uuid.UUID, len(members))
+	for i, m := range members {
+		ids[i] = m.CustomerID
+	}
+	consented, err := s.consentSvc.OptedIn(ctx, c.PharmacyID, campaignConsentChannels[c.Channel], ids)
+	if err != nil {
+		s.logger.Error("campaign consent check failed", zap.Error(err), zap.String("campaign_id", c.ID.String()))
+	}
+	return consented, err
+}
+
+func (s *campaignService) sendTo(ctx context.Context, c *models.Campaign, senderName string, m *models.SegmentMember) *models.CampaignRecipient {
+	r := &models.CampaignRecipient{CustomerID: m.CustomerID, Status: models.CampaignRecipientSent}
+	message := strings.ReplaceAll(c.Message, "{name}", customerName(m.Name))
+	var err error
+	switch c.Channel {
+	case models.CampaignChannelNotification:
+		if m.UserID == nil {
+			return skipped(r, "no app account")
+		}
+		title := c.Subject
+		if title == "" {
+			title = c.Name
+		}
+		_, err = s.notificationSvc.Create(ctx, c.PharmacyID, *m.UserID, title, message, "campaign")
+	case models.CampaignChannelSMS:
+		if strings.TrimSpace(m.Phone) == "" {
+			return skipped(r, "no phone number")
+		}
+		err = s.smsSender.Send(ctx, &outbound.SMSMessage{PharmacyID: c.PharmacyID, To: m.Phone, Body: senderName + ": " + message})
+	case models.CampaignChannelEmail:
+		if strings.TrimSpace(m.Email) == "" {
+			return skipped(r, "no email address")
+		}
+		err = s.mailer.CampaignMessage(ctx, c.PharmacyID, m.Email, m.Name, m.PreferredLanguage, c.Subject, message)
+	}
+	if err != nil {
+		r.Status, r.Error = models.CampaignRecipientFailed, truncateRunes(err.Error(), 500)
+	}
+	return r
+}
+
+func skipped(r *models.CampaignRecipient, reason string) *models.CampaignRecipient {
+	r.Status, r.Error = models.CampaignRecipientSkipped, reason
+	return r
+}
+
+func (s *campaignService) ListRecipients(ctx context.Context, pharmacyID, id uuid.UUID, status string, limit, offset int) ([]*models.CampaignRecipient, int64, error) {
+	if _, err := s.Get(ctx, pharmacyID, id); err != nil {
+		return nil, 0, err
+	}
+	switch models.CampaignRecipientStatus(status) {
+	case "", models.CampaignRecipientSent, models.CampaignRecipientFailed, models.CampaignRecipientSkipped:
+	default:
+		return nil, 0, errors.ErrValidation("status must be sent, failed or skipped")
+	}
+	list, total, err := s.campaignRepo.ListRecipients(ctx, id, status, limit, offset)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to list recipients", err)
+	}
+	return list, total, nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestCampaignService_Deliver_SMSRecordsOutcomes(t *testing.T) {
+	pharmacyID := uuid.New()
+	c := &models.Campaign{ID: uuid.New(), PharmacyID: pharmacyID, Channel: models.CampaignChannelSMS, Message: "Hi {name}, 10% off this week"}
+	var recorded []*models.CampaignRecipient
+	finished := false
+	campaigns := &mocks.MockCampaignRepository{
+		RecordRecipientsFunc: func(ctx context.Context, id uuid.UUID, rs []*models.CampaignRecipient) error {
+			recorded = append(recorded, rs...)
+			return nil
+		},
+		FinishSendingFunc: func(ctx context.Context, id uuid.UUID, at time.Time) error {
+			finished = true
+			return nil
+		},
+	}
+	sms := &captureSMS{}
+	svc := &campaignService{campaignRepo: campaigns, smsSender: sms, now: time.Now, logger: zap.NewNop()}
+
+	svc.deliver(context.Background(), c, "CarePlus", []*models.SegmentMember{
+		{CustomerID: uuid.New(), Name: "Asha", Phone: "9800000001"},
+		{CustomerID: uuid.New(), Name: "Bikash"},
+	})
+
+	if len(sms.sent) != 1 || sms.sent[0].Body != "CarePlus: Hi Asha, 10% off this week" || sms.sent[0].PharmacyID != pharmacyID {
+		t.Fatalf("sent %+v", sms.sent)
+	}
+	if len(recorded) != 2 || recorded[0].Status != models.CampaignRecipientSent || recorded[1].Status != models.CampaignRecipientSkipped {
+		t.Fatalf("recorded %+v, want one sent and one skipped", recorded)
+	}
+	if !finished {
+		t.Error("campaign was not marked sent")
+	}
+}
+
+func TestCampaignService_Send_SMSNeedsFlag(t *testing.T) {
+	pharmacyID, segmentID := uuid.New(), uuid.New()
+	campaigns := &mocks.MockCampaignRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Campaign, error) {
+			return &models.Campaign{ID: id, PharmacyID: pharmacyID, SegmentID: segmentID, Channel: models.CampaignChannelSMS, Status: models.CampaignStatusDraft}, nil
+		},
+		StartSendingFunc: func(ctx context.Context, c *models.Campaign) (bool, error) {
+			t.Fatal("campaign should not start")
+			return false, nil
+		},
+	}
+	segments := &mocks.MockCustomerSegmentRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.CustomerSegment, error) {
+			return &models.CustomerSegment{ID: id, PharmacyID: pharmacyID}, nil
+		},
+	}
+	configs := &mocks.MockPharmacyConfigRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, id uuid.UUID) (*models.PharmacyConfig, error) {
+			return &models.PharmacyConfig{FeatureFlags: models.FeatureFlagsMap{models.FeatureSMSOrderUpdates: true}}, nil
+		},
+	}
+	svc := &campaignService{segmentRepo: segments, campaignRepo: campaigns, smsSender: &captureSMS{}, configRepo: configs, now: time.Now, logger: zap.NewNop()}
+
+	_, err := svc.Send(context.Background(), pharmacyID, uuid.New())
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("err = %v, want validation error without sms_campaigns", err)
+	}
+}
+
+func TestCampaignService_CreateSegment_RejectsInvertedBounds(t *testing.T) {
+	svc := &campaignService{segmentRepo: &mocks.MockCustomerSegmentRepository{}, logger: zap.NewNop()}
+	lo, hi := 500, 100
+	_, err := svc.CreateSegment(context.Background(), uuid.New(), uuid.New(), inbound.SegmentInput{Name: "Loyal", Criteria: models.SegmentCriteria{MinPoints: &lo, MaxPoints: &hi}})
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("err = %v, want validation error", err)
+	}
+}
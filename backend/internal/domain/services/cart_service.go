@@ -0,0 +1,467 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Upper bound for a single cart line, independent of stock.
+const maxCartLineQuantity = 999
+
+// Price sources reported on cart lines.
+const (
+	cartPriceRegular     = "regular"
+	cartPriceFlashSale   = "flash_sale"
+	cartPriceShortExpiry = "short_expiry"
+)
+
+type cartService struct {
+	cartRepo               outbound.CartRepository
+	productRepo            outbound.ProductRepository
+	userRepo               outbound.UserRepository
+	customerRepo           outbound.CustomerRepository
+	customerMembershipRepo outbound.CustomerMembershipRepository
+	configRepo             outbound.PharmacyConfigRepository
+	paymentGatewayRepo     outbound.PaymentGatewayRepository
+	promoCodeSvc           inbound.PromoCodeService
+	referralPointsSvc      inbound.ReferralPointsService
+	flashSaleSvc           inbound.FlashSaleService
+	expiryDiscountSvc      inbound.ExpiryDiscountService
+	benefitsEngine         inbound.BenefitsEngine
+	orderSvc               inbound.OrderService
+	logger                 *zap.Logger
+}
+
+// NewCartService returns a CartService. flashSaleSvc, expiryDiscountSvc, benefitsEngine and referralPointsSvc may be nil.
+func NewCartService(
+	cartRepo outbound.CartRepository,
+	productRepo outbound.ProductRepository,
+	userRepo outbound.UserRepository,
+	customerRepo outbound.CustomerRepository,
+	customerMembershipRepo outbound.CustomerMembershipRepository,
+	configRepo outbound.PharmacyConfigRepository,
+	paymentGatewayRepo outbound.PaymentGatewayRepository,
+	promoCodeSvc inbound.PromoCodeService,
+	referralPointsSvc inbound.ReferralPointsService,
+	flashSaleSvc inbound.FlashSaleService,
+	expiryDiscountSvc inbound.ExpiryDiscountService,
+	benefitsEngine inbound.BenefitsEngine,
+	orderSvc inbound.OrderService,
+	logger *zap.Logger,
+) inbound.CartService {
+	return &cartService{
+		cartRepo:               cartRepo,
+		productRepo:            productRepo,
+		userRepo:               userRepo,
+		customerRepo:           customerRepo,
+		customerMembershipRepo: customerMembershipRepo,
+		configRepo:             configRepo,
+		paymentGatewayRepo:     paymentGatewayRepo,
+		promoCodeSvc:           promoCodeSvc,
+		referralPointsSvc:      referralPointsSvc,
+		flashSaleSvc:           flashSaleSvc,
+		expiryDiscountSvc:      expiryDiscountSvc,
+		benefitsEngine:         benefitsEngine,
+		orderSvc:               orderSvc,
+		logger:                 logger,
+	}
+}
+
+func (s *cartService) Get(ctx context.Context, pharmacyID, userID uuid.UUID) (*inbound.CartView, error) {
+	cart, err := s.cartRepo.GetOpen(ctx, pharmacyID, userID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load cart", err)
+	}
+	return s.view(ctx, pharmacyID, userID, cart)
+}
+
+func (s *cartService) AddItem(ctx context.Context, pharmacyID, userID, productID uuid.UUID, quantity int) (*inbound.CartView, error) {
+	if quantity <= 0 {
+		return nil, errors.ErrValidation("quantity must be positive")
+	}
+	cart, err := s.openCart(ctx, pharmacyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, it := range cart.Items {
+		if it.ProductID == productID {
+			quantity += it.Quantity
+			break
+		}
+	}
+	return s.setQuantity(ctx, pharmacyID, userID, cart, productID, quantity)
+}
+
+func (s *cartService) UpdateItem(ctx context.Context, pharmacyID, userID, productID uuid.UUID, quantity int) (*inbound.CartView, error) {
+	if quantity < 0 {
+		return nil, errors.ErrValidation("quantity must not be negative")
+	}
+	if quantity == 0 {
+		return s.RemoveItem(ctx, pharmacyID, userID, productID)
+	}
+	cart, err := s.openCart(ctx, pharmacyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.setQuantity(ctx, pharmacyID, userID, cart, productID, quantity)
+}
+
+func (s *cartService) RemoveItem(ctx context.Context, pharmacyID, userID, productID uuid.UUID) (*inbound.CartView, error) {
+	cart, err := s.cartRepo.GetOpen(ctx, pharmacyID, userID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load cart", err)
+	}
+	if cart != nil {
+		if err := s.cartRepo.DeleteItem(ctx, cart.ID, productID); err != nil {
+			return nil, errors.ErrInternal("failed to remove cart item", err)
+		}
+	}
+	return s.Get(ctx, pharmacyID, userID)
+}
+
+func (s *cartService) Clear(ctx context.Context, pharmacyID, userID uuid.UUID) (*inbound.CartView, error) {
+	cart, err := s.cartRepo.GetOpen(ctx, pharmacyID, userID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load cart", err)
+	}
+	if cart != nil {
+		if err := s.cartRepo.DeleteItems(ctx, cart.ID); err != nil {
+			return nil, errors.ErrInternal("failed to clear cart", err)
+		}
+		cart.PromoCode, cart.ReferralCode, cart.PointsToRedeem = "", "", 0
+		if err := s.cartRepo.UpdateCodes(ctx, cart); err != nil {
+			return nil, errors.ErrInternal("failed to clear cart", err)
+		}
+	}
+	return s.Get(ctx, pharmacyID, userID)
+}
+
+func (s *cartService) ApplyCodes(ctx context.Context, pharmacyID, userID uuid.UUID, input inbound.CartCodesInput) (*inbound.CartView, error) {
+	cart, err := s.openCart(ctx, pharmacyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if input.PromoCode != nil {
+		cart.PromoCode = strings.TrimSpace(*input.PromoCode)
+	}
+	if input.ReferralCode != nil {
+		code := strings.TrimSpace(*input.ReferralCode)
+		if code != "" && s.referralPointsSvc != nil {
+			res, err := s.referralPointsSvc.ValidateReferralCode(ctx, pharmacyID, code)
+			if err != nil {
+				return nil, err
+			}
+			if !res.Valid {
+				msg := res.Message
+				if msg == "" {
+					msg = "invalid referral code"
+				}
+				return nil, errors.ErrValidation(msg)
+			}
+		}
+		cart.ReferralCode = code
+	}
+	if input.PointsToRedeem != nil {
+		if *input.PointsToRedeem < 0 {
+			return nil, errors.ErrValidation("points_to_redeem must not be negative")
+		}
+		cart.PointsToRedeem = *input.PointsToRedeem
+	}
+	// A promo code is checked against the current cart so the caller learns right away whether it applies.
+	if input.PromoCode != nil && cart.PromoCode != "" {
+		view, err := s.view(ctx, pharmacyID, userID, cart)
+		if err != nil {
+			return nil, err
+		}
+		if view.PromoError != "" {
+			return nil, errors.ErrValidation(view.PromoError)
+		}
+	}
+	if err := s.cartRepo.UpdateCodes(ctx, cart); err != nil {
+		return nil, errors.ErrInternal("failed to update cart", err)
+	}
+	return s.Get(ctx, pharmacyID, userID)
+}
+
+func (s *cartService) Checkout(ctx context.Context, pharmacyID, userID uuid.UUID, input inbound.CartCheckoutInput) (*models.Order, error) {
+	cart, err := s.cartRepo.GetOpen(ctx, pharmacyID, userID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load cart", err)
+	}
+	if cart == nil || len(cart.Items) == 0 {
+		return nil, errors.ErrValidation("cart is empty")
+	}
+	view, err := s.view(ctx, pharmacyID, userID, cart)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range view.Items {
+		if line.Problem != "" {
+			return nil, errors.ErrValidation(line.Name + ": " + line.Problem)
+		}
+	}
+	if view.PromoError != "" {
+		return nil, errors.ErrValidation(view.PromoError)
+	}
+
+	claimed, err := s.cartRepo.TransitionStatus(ctx, cart.ID, models.CartStatusOpen, models.CartStatusCheckingOut, nil)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to lock cart", err)
+	}
+	if !claimed {
+		return nil, errors.ErrConflict("cart is already being checked out")
+	}
+
+	// Order creation re-prices lines (flash sales, short-expiry) and re-validates codes; send the catalog price.
+	items := make([]inbound.OrderItemInput, 0, len(cart.Items))
+	for _, it := range cart.Items {
+		items = append(items, inbound.OrderItemInput{ProductID: it.ProductID, Quantity: it.Quantity, UnitPrice: it.Product.UnitPrice})
+	}
+	name, phone, email := strings.TrimSpace(input.CustomerName), strings.TrimSpace(input.CustomerPhone), strings.TrimSpace(input.CustomerEmail)
+	if name == "" || phone == "" || email == "" {
+		if u, err := s.userRepo.GetByID(ctx, userID); err == nil && u != nil {
+			if name == "" {
+				name = u.Name
+			}
+			if phone == "" {
+				phone = u.Phone
+			}
+			if email == "" {
+				email = u.Email
+			}
+		}
+	}
+	var promoCode, referralCode *string
+	var points *int
+	if cart.PromoCode != "" {
+		promoCode = &cart.PromoCode
+	}
+	if cart.ReferralCode != "" {
+		referralCode = &cart.ReferralCode
+	}
+	if cart.PointsToRedeem > 0 {
+		points = &cart.PointsToRedeem
+	}
+	customFields := input.CustomFields
+	if customFields == nil {
+		customFields = map[string]any{} // still enforce required customer fields
+	}
+	order, err := s.orderSvc.Create(ctx, pharmacyID, userID, name, phone, email, items, input.Notes, input.DeliveryAddress, nil, promoCode, referralCode, points, input.PaymentGatewayID, &inbound.OrderTender{GiftCardCode: input.GiftCardCode, StoreCredit: input.StoreCredit}, customFields)
+	if err != nil {
+		if _, rerr := s.cartRepo.TransitionStatus(ctx, cart.ID, models.CartStatusCheckingOut, models.CartStatusOpen, nil); rerr != nil {
+			s.logger.Warn("failed to reopen cart after checkout error", zap.String("cart_id", cart.ID.String()), zap.Error(rerr))
+		}
+		return nil, err
+	}
+	if _, err := s.cartRepo.TransitionStatus(ctx, cart.ID, models.CartStatusCheckingOut, models.CartStatusConverted, &order.ID); err != nil {
+		s.logger.Warn("failed to mark cart converted", zap.String("cart_id", cart.ID.String()), zap.Error(err))
+	}
+	return order, nil
+}
+
+// openCart returns the user's open cart, creating an empty one when there is none.
+func (s *cartService) openCart(ctx context.Context, pharmacyID, userID uuid.UUID) (*models.Cart, error) {
+	cart, err := s.cartRepo.GetOpen(ctx, pharmacyID, userID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load cart", err)
+	}
+	if cart != nil {
+		return cart, nil
+	}
+	cart = &models.Cart{PharmacyID: pharmacyID, UserID: userID, Status: models.CartStatusOpen}
+	if err := s.cartRepo.Create(ctx, cart); err != nil {
+		return nil, errors.ErrInternal("failed to create cart", err)
+	}
+	return cart, nil
+}
+
+func (s *cartService) setQuantity(ctx context.Context, pharmacyID, userID uuid.UUID, cart *models.Cart, productID uuid.UUID, quantity int) (*inbound.CartView, error) {
+	if quantity > maxCartLineQuantity {
+		return nil, errors.ErrValidation("quantity is too large")
+	}
+	prod, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil || prod == nil || prod.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("product")
+	}
+	if !prod.IsActive {
+		return nil, errors.ErrValidation(prod.Name + " is not available")
+	}
+	if prod.StockQuantity < quantity {
+		return nil, errors.ErrValidation("insufficient stock for " + prod.Name)
+	}
+	if err := s.cartRepo.SetItemQuantity(ctx, cart.ID, productID, quantity); err != nil {
+		return nil, errors.ErrInternal("failed to update cart item", err)
+	}
+	return s.Get(ctx, pharmacyID, userID)
+}
+
+// view prices the cart and previews the discounts order creation will apply, in the same order:
+// membership and promo code on the subtotal (an exclusive code drops membership), then points, capped at the
+// subtotal, then VAT.
+func (s *cartService) view(ctx context.Context, pharmacyID, userID uuid.UUID, cart *models.Cart) (*inbound.CartView, error) {
+	var cfg *models.PharmacyConfig
+	if s.configRepo != nil {
+		cfg, _ = s.configRepo.GetByPharmacyID(ctx, pharmacyID)
+	}
+	v := &inbound.CartView{Items: []inbound.CartLine{}, Currency: cfg.Currency()}
+	if cart == nil {
+		return v, nil
+	}
+	v.ID = &cart.ID
+	v.PromoCode = cart.PromoCode
+	v.ReferralCode = cart.ReferralCode
+	v.PointsToRedeem = cart.PointsToRedeem
+
+	customer := s.customerForUser(ctx, pharmacyID, userID)
+	var earlyAccess time.Duration
+	if customer != nil {
+		earlyAccess = flashSaleEarlyAccess(ctx, s.benefitsEngine, customer.ID)
+	}
+	prices := s.effectivePrices(ctx, pharmacyID, cart.Items, earlyAccess)
+	taxLines := make([]taxableLine, 0, len(cart.Items))
+	promoLines := make([]inbound.PromoLine, 0, len(cart.Items))
+	for _, it := range cart.Items {
+		line := inbound.CartLine{ProductID: it.ProductID, Quantity: it.Quantity}
+		p := it.Product
+		if p == nil || p.PharmacyID != pharmacyID || !p.IsActive {
+			line.Name = "Unavailable product"
+			line.Problem = "unavailable"
+			v.Items = append(v.Items, line)
+			continue
+		}
+		line.Name, line.SKU, line.Available = p.Name, p.SKU, p.StockQuantity
+		if p.Currency != "" && p.Currency != v.Currency {
+			// Left out of the totals, which are in the base currency; order creation rejects it too.
+			line.Problem = "priced in " + p.Currency
+			v.Items = append(v.Items, line)
+			continue
+		}
+		line.RegularPrice, line.UnitPrice, line.PriceSource = p.UnitPrice, p.UnitPrice, cartPriceRegular
+		if ep, ok := prices[p.ID]; ok {
+			line.UnitPrice, line.PriceSource = ep.price, ep.source
+		}
+		switch {
+		case p.StockQuantity <= 0:
+			line.Problem = "out of stock"
+		case p.StockQuantity < it.Quantity:
+			line.Problem = "insufficient stock"
+		}
+		line.LineTotal = line.UnitPrice * float64(it.Quantity)
+		v.SubTotal += line.LineTotal
+		v.ItemCount += it.Quantity
+		taxLines = append(taxLines, taxableLine{TaxClass: p.TaxClass, Amount: line.LineTotal})
+		promoLines = append(promoLines, promoLine(p, it.Quantity, line.LineTotal))
+		v.Items = append(v.Items, line)
+	}
+	if v.SubTotal <= 0 {
+		return v, nil
+	}
+
+	if customer != nil {
+		v.PointsBalance = customer.PointsBalance
+		cm, _ := s.customerMembershipRepo.GetByCustomerID(ctx, customer.ID)
+		if cm != nil && cm.Membership != nil && cm.Membership.IsActive && cm.Membership.DiscountPercent > 0 {
+			v.MembershipName = cm.Membership.Name
+			v.MembershipDiscount = v.SubTotal * (cm.Membership.DiscountPercent / 100)
+		}
+		if cart.PointsToRedeem > 0 && s.referralPointsSvc != nil {
+			res, err := s.referralPointsSvc.ComputeRedeemDiscount(ctx, pharmacyID, customer.ID, cart.PointsToRedeem, v.SubTotal)
+			if err == nil && res != nil {
+				v.PointsDiscount = res.DiscountAmount
+				v.MaxRedeemable = res.MaxRedeemable
+			}
+		}
+	}
+	if cart.PromoCode != "" {
+		res, err := s.promoCodeSvc.Validate(ctx, pharmacyID, cart.PromoCode, v.SubTotal, promoLines, &userID)
+		switch {
+		case err != nil:
+			v.PromoError = "promo code does not apply"
+			if ae := errors.GetAppError(err); ae != nil {
+				v.PromoError = ae.Message
+			}
+		case res.Stacking == models.PromoStackingExclusive && cart.PointsToRedeem > 0:
+			// Same rule as order creation, which rejects the combination.
+			v.PromoError = "promo code cannot be combined with points redemption"
+		default:
+			v.PromoDiscount = res.DiscountAmount
+			v.PromoItems = res.Lines
+			if res.Stacking == models.PromoStackingExclusive {
+				v.MembershipDiscount = 0
+			}
+		}
+	}
+	v.DiscountAmount = v.MembershipDiscount + v.PromoDiscount + v.PointsDiscount
+	if v.DiscountAmount > v.SubTotal {
+		v.DiscountAmount = v.SubTotal
+	}
+	// Rounded like order creation rounds, so the preview matches the order to the cent.
+	v.SubTotal = models.RoundAmount(v.SubTotal, v.Currency)
+	v.DiscountAmount = models.RoundAmount(v.DiscountAmount, v.Currency)
+	v.TotalAmount = v.SubTotal - v.DiscountAmount
+
+	_, v.TaxAmount = computeOrderTax(cfg, taxLines, v.DiscountAmount)
+	v.TaxInclusive = cfg != nil && cfg.TaxEnabled && cfg.PricesIncludeTax
+	if !v.TaxInclusive {
+		v.TotalAmount += v.TaxAmount
+	}
+	v.TotalAmount = models.RoundAmount(v.TotalAmount, v.Currency)
+	return v, nil
+}
+
+type cartPrice struct {
+	price  float64
+	source string
+}
+
+// effectivePrices returns live flash-sale prices (earlyAccess from the customer's tier), falling back to
+// short-expiry markdowns, keyed by product.
+func (s *cartService) effectivePrices(ctx context.Context, pharmacyID uuid.UUID, items []*models.CartItem, earlyAccess time.Duration) map[uuid.UUID]cartPrice {
+	out := map[uuid.UUID]cartPrice{}
+	if len(items) == 0 {
+		return out
+	}
+	ids := make([]uuid.UUID, 0, len(items))
+	for _, it := range items {
+		ids = append(ids, it.ProductID)
+	}
+	if s.flashSaleSvc != nil {
+		offers, err := s.flashSaleSvc.ListLiveOffers(ctx, pharmacyID, ids, earlyAccess)
+		if err != nil {
+			s.logger.Warn("failed to load flash sale offers for cart", zap.Error(err))
+		}
+		for _, o := range offers {
+			out[o.ProductID] = cartPrice{price: o.SalePrice, source: cartPriceFlashSale}
+		}
+	}
+	if s.expiryDiscountSvc != nil {
+		offers, err := s.expiryDiscountSvc.ListOffers(ctx, pharmacyID, ids)
+		if err != nil {
+			s.logger.Warn("failed to load expiry discounts for cart", zap.Error(err))
+		}
+		for _, o := range offers {
+			if _, ok := out[o.ProductID]; !ok {
+				out[o.ProductID] = cartPrice{price: o.SalePrice, source: cartPriceShortExpiry}
+			}
+		}
+	}
+	return out
+}
+
+// customerForUser finds the loyalty customer matching the user's phone at this pharmacy, if any.
+func (s *cartService) customerForUser(ctx context.Context, pharmacyID, userID uuid.UUID) *models.Customer {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || u == nil || strings.TrimSpace(u.Phone) == "" {
+		return nil
+	}
+	c, _ := s.customerRepo.GetByPharmacyAndPhone(ctx, pharmacyID, strings.TrimSpace(u.Phone))
+	return c
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakeOrderService records the items of the last Create call.
+type fakeOrderService struct {
+	inbound.OrderService
+	items []inbound.OrderItemInput
+	err   error
+}
+
+func (f *fakeOrderService) Create(ctx context.Context, pharmacyID, createdBy uuid.UUID, customerName, customerPhone, customerEmail string, items []inbound.OrderItemInput, notes string, deliveryAddress string, discountAmount *float64, promoCode *string, referralCode *string, pointsToRedeem *int, paymentGatewayID *uuid.UUID, tender *inbound.OrderTender, customFields map[string]any) (*models.Order, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.items = items
+	return &models.Order{ID: uuid.New(), PharmacyID: pharmacyID, CreatedBy: createdBy}, nil
+}
+
+func newTestCart(pharmacyID, userID uuid.UUID, product *models.Product, qty int) *models.Cart {
+	cart := &models.Cart{ID: uuid.New(), PharmacyID: pharmacyID, UserID: userID, Status: models.CartStatusOpen}
+	if product != nil {
+		cart.Items = []*models.CartItem{{CartID: cart.ID, ProductID: product.ID, Quantity: qty, Product: product}}
+	}
+	return cart
+}
+
+func newTestCartService(cartRepo *mocks.MockCartRepository, product *models.Product, orders inbound.OrderService) inbound.CartService {
+	productRepo := &mocks.MockProductRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+			if product != nil && id == product.ID {
+				return product, nil
+			}
+			return nil, nil
+		},
+	}
+	return NewCartService(cartRepo, productRepo, &mocks.MockUserRepository{}, nil, nil, &mocks.MockPharmacyConfigRepository{}, &mocks.MockPaymentGatewayRepository{}, nil, nil, nil, nil, nil, orders, zap.NewNop())
+}
+
+func TestCartService_AddItem_AccumulatesAndChecksStock(t *testing.T) {
+	pharmacyID, userID := uuid.New(), uuid.New()
+	product := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Paracetamol", UnitPrice: 50, StockQuantity: 5, IsActive: true}
+	cart := newTestCart(pharmacyID, userID, product, 2)
+	var setQty int
+	repo := &mocks.MockCartRepository{
+		GetOpenFunc: func(ctx context.Context, phID, uID uuid.UUID) (*models.Cart, error) { return cart, nil },
+		SetItemQuantityFunc: func(ctx context.Context, cartID, productID uuid.UUID, quantity int) error {
+			setQty = quantity
+			cart.Items[0].Quantity = quantity
+			return nil
+		},
+	}
+	svc := newTestCartService(repo, product, nil)
+
+	view, err := svc.AddItem(context.Background(), pharmacyID, userID, product.ID, 3)
+	if err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	if setQty != 5 || view.SubTotal != 250 || view.TotalAmount != 250 || view.ItemCount != 5 {
+		t.Errorf("got qty=%d sub_total=%v total=%v count=%d", setQty, view.SubTotal, view.TotalAmount, view.ItemCount)
+	}
+
+	_, err = svc.AddItem(context.Background(), pharmacyID, userID, product.ID, 1)
+	if ae := pkgerrors.GetAppError(err); ae == nil || ae.Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("expected insufficient stock validation error, got %v", err)
+	}
+}
+
+func TestCartService_Checkout_ConvertsCart(t *testing.T) {
+	pharmacyID, userID := uuid.New(), uuid.New()
+	product := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Vitamin C", UnitPrice: 120, StockQuantity: 10, IsActive: true}
+	cart := newTestCart(pharmacyID, userID, product, 2)
+	var transitions []string
+	var convertedOrder *uuid.UUID
+	repo := &mocks.MockCartRepository{
+		GetOpenFunc: func(ctx context.Context, phID, uID uuid.UUID) (*models.Cart, error) { return cart, nil },
+		TransitionStatusFunc: func(ctx context.Context, cartID uuid.UUID, from, to string, orderID *uuid.UUID) (bool, error) {
+			transitions = append(transitions, from+">"+to)
+			if to == models.CartStatusConverted {
+				convertedOrder = orderID
+			}
+			return true, nil
+		},
+	}
+	orders := &fakeOrderService{}
+	svc := newTestCartService(repo, product, orders)
+
+	order, err := svc.Checkout(context.Background(), pharmacyID, userID, inbound.CartCheckoutInput{CustomerName: "Asha", CustomerPhone: "9800000000"})
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if len(orders.items) != 1 || orders.items[0].Quantity != 2 || orders.items[0].UnitPrice != 120 {
+		t.Errorf("unexpected order items %+v", orders.items)
+	}
+	if len(transitions) != 2 || transitions[0] != "open>checking_out" || transitions[1] != "checking_out>converted" {
+		t.Errorf("unexpected transitions %v", transitions)
+	}
+	if convertedOrder == nil || *convertedOrder != order.ID {
+		t.Error("expected cart linked to the created order")
+	}
+}
+
+func TestCartService_Checkout_AlreadyClaimed(t *testing.T) {
+	pharmacyID, userID := uuid.New(), uuid.New()
+	product := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Vitamin C", UnitPrice: 120, StockQuantity: 10, IsActive: true}
+	cart := newTestCart(pharmacyID, userID, product, 1)
+	repo := &mocks.MockCartRepository{
+		GetOpenFunc: func(ctx context.Context, phID, uID uuid.UUID) (*models.Cart, error) { return cart, nil },
+		TransitionStatusFunc: func(ctx context.Context, cartID uuid.UUID, from, to string, orderID *uuid.UUID) (bool, error) {
+			return false, nil
+		},
+	}
+	orders := &fakeOrderService{}
+	svc := newTestCartService(repo, product, orders)
+
+	_, err := svc.Checkout(context.Background(), pharmacyID, userID, inbound.CartCheckoutInput{})
+	if ae := pkgerrors.GetAppError(err); ae == nil || ae.Code != pkgerrors.ErrCodeConflict {
+		t.Errorf("expected conflict, got %v", err)
+	}
+	if orders.items != nil {
+		t.Error("order must not be created when the cart could not be claimed")
+	}
+}
+
+func TestCartService_Checkout_ReopensCartOnOrderFailure(t *testing.T) {
+	pharmacyID, userID := uuid.New(), uuid.New()
+	product := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Vitamin C", UnitPrice: 120, StockQuantity: 10, IsActive: true}
+	cart := newTestCart(pharmacyID, userID, product, 1)
+	var last string
+	repo := &mocks.MockCartRepository{
+		GetOpenFunc: func(ctx context.Context, phID, uID uuid.UUID) (*models.Cart, error) { return cart, nil },
+		TransitionStatusFunc: func(ctx context.Context, cartID uuid.UUID, from, to string, orderID *uuid.UUID) (bool, error) {
+			last = to
+			return true, nil
+		},
+	}
+	svc := newTestCartService(repo, product, &fakeOrderService{err: pkgerrors.ErrValidation("insufficient stock for Vitamin C")})
+
+	if _, err := svc.Checkout(context.Background(), pharmacyID, userID, inbound.CartCheckoutInput{}); err == nil {
+		t.Fatal("expected checkout error")
+	}
+	if last != models.CartStatusOpen {
+		t.Errorf("expected cart reopened, last transition to %q", last)
+	}
+}
+
+func TestCartService_Checkout_EmptyCart(t *testing.T) {
+	svc := newTestCartService(&mocks.MockCartRepository{}, nil, &fakeOrderService{})
+	_, err := svc.Checkout(context.Background(), uuid.New(), uuid.New(), inbound.CartCheckoutInput{})
+	if ae := pkgerrors.GetAppError(err); ae == nil || ae.Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("expected validation error, got %v", err)
+	}
+}
+
+func TestCartService_ValidateCheckout_CollectsAllIssues(t *testing.T) {
+	pharmacyID, userID := uuid.New(), uuid.New()
+	rx := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Amoxicillin", UnitPrice: 100, StockQuantity: 1, IsActive: true, RequiresRx: true}
+	products := map[uuid.UUID]*models.Product{rx.ID: rx}
+	cod := &models.PaymentGateway{ID: uuid.New(), PharmacyID: pharmacyID, Code: "cod", Name: "Cash on delivery", IsActive: true}
+	svc := &cartService{
+		cartRepo: &mocks.MockCartRepository{GetOpenFunc: func(ctx context.Context, phID, uID uuid.UUID) (*models.Cart, error) { return nil, nil }},
+		productRepo: &mocks.MockProductRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+			return products[id], nil
+		}},
+		userRepo: &mocks.MockUserRepository{},
+		configRepo: &mocks.MockPharmacyConfigRepository{GetByPharmacyIDFunc: func(ctx context.Context, id uuid.UUID) (*models.PharmacyConfig, error) {
+			return &models.PharmacyConfig{DeliveryFee: 80, DeliveryAreas: []string{"Lalitpur"}}, nil
+		}},
+		paymentGatewayRepo: &mocks.MockPaymentGatewayRepository{ListByPharmacyFunc: func(ctx context.Context, id uuid.UUID, activeOnly bool) ([]*models.PaymentGateway, error) {
+			return []*models.PaymentGateway{cod}, nil
+		}},
+		logger: zap.NewNop(),
+	}
+	ctx := context.Background()
+	missing, otherGateway := uuid.New(), uuid.New()
+
+	res, err := svc.ValidateCheckout(ctx, pharmacyID, userID, inbound.CheckoutValidationInput{
+		Items:            []inbound.CheckoutItemInput{{ProductID: rx.ID, Quantity: 2}, {ProductID: missing, Quantity: 1}},
+		DeliveryAddress:  "Baneshwor, Kathmandu",
+		PaymentGatewayID: &otherGateway,
+	})
+	if err != nil {
+		t.Fatalf("ValidateCheckout: %v", err)
+	}
+	codes := map[string]string{}
+	for _, is := range res.Issues {
+		codes[is.Code] = is.Severity
+	}
+	want := map[string]string{"insufficient_stock": "error", "unavailable": "error", "prescription_required": "warning", "delivery_area": "error", "payment_method": "error"}
+	for code, sev := range want {
+		if codes[code] != sev {
+			t.Errorf("issue %s = %q, want %q (issues %+v)", code, codes[code], sev, res.Issues)
+		}
+	}
+	if res.Valid || !res.RequiresPrescription || res.Cart.ID != nil || len(res.Payment.Methods) != 1 {
+		t.Errorf("result = %+v", res)
+	}
+
+	res, err = svc.ValidateCheckout(ctx, pharmacyID, userID, inbound.CheckoutValidationInput{
+		Items:            []inbound.CheckoutItemInput{{ProductID: rx.ID, Quantity: 1}},
+		DeliveryAddress:  "Jhamsikhel, lalitpur",
+		PaymentGatewayID: &cod.ID,
+	})
+	if err != nil {
+		t.Fatalf("ValidateCheckout: %v", err)
+	}
+	if !res.Valid || !res.Delivery.Available || res.Delivery.Fee != 80 || res.TotalAmount != 180 || !res.Payment.Available {
+		t.Errorf("valid checkout: %+v, issues %+v", res, res.Issues)
+	}
+}
+
+func TestCartService_Get_FlagsLinePricedInAnotherCurrency(t *testing.T) {
+	pharmacyID, userID := uuid.New(), uuid.New()
+	usd := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Imported syrup", UnitPrice: 12, Currency: "USD", StockQuantity: 5, IsActive: true}
+	npr := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Paracetamol", UnitPrice: 50, Currency: "NPR", StockQuantity: 5, IsActive: true}
+	cart := newTestCart(pharmacyID, userID, npr, 2)
+	cart.Items = append(cart.Items, &models.CartItem{CartID: cart.ID, ProductID: usd.ID, Quantity: 1, Product: usd})
+	repo := &mocks.MockCartRepository{GetOpenFunc: func(ctx context.Context, phID, uID uuid.UUID) (*models.Cart, error) { return cart, nil }}
+	svc := newTestCartService(repo, npr, nil)
+
+	v, err := svc.Get(context.Background(), pharmacyID, userID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if v.Currency != "NPR" || v.SubTotal != 100 || v.ItemCount != 2 {
+		t.Errorf("cart = %s %v for %d items, want NPR 100 for 2 (the USD line left out)", v.Currency, v.SubTotal, v.ItemCount)
+	}
+	if len(v.Items) != 2 || v.Items[1].Problem != "priced in USD" || v.Items[0].Problem != "" {
+		t.Errorf("lines = %+v, want the USD line flagged", v.Items)
+	}
+}
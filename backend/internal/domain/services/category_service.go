@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
@@ -11,19 +12,26 @@ import (
 	"go.uber.org/zap"
 )
 
+// categoryResyncTimeout bounds the background product re-sync after a rename.
+const categoryResyncTimeout = 5 * time.Minute
+
 type categoryService struct {
-	repo   outbound.CategoryRepository
-	logger *zap.Logger
+	repo        outbound.CategoryRepository
+	productRepo outbound.ProductRepository
+	logger      *zap.Logger
 }
 
-func NewCategoryService(repo outbound.CategoryRepository, logger *zap.Logger) inbound.CategoryService {
-	return &categoryService{repo: repo, logger: logger}
+func NewCategoryService(repo outbound.CategoryRepository, productRepo outbound.ProductRepository, logger *zap.Logger) inbound.CategoryService {
+	return &categoryService{repo: repo, productRepo: productRepo, logger: logger}
 }
 
 func (s *categoryService) Create(ctx context.Context, c *models.Category) error {
 	if c.Name == "" {
 		return errors.ErrValidation("category name is required")
 	}
+	if msg := models.ValidateTranslations(c.Translations); msg != "" {
+		return errors.ErrValidation(msg)
+	}
 	return s.repo.Create(ctx, c)
 }
 
@@ -46,7 +54,37 @@ func (s *categoryService) Update(ctx context.Context, c *models.Category) error
 	if c.Name == "" {
 		return errors.ErrValidation("category name is required")
 	}
-	return s.repo.Update(ctx, c)
+	if msg := models.ValidateTranslations(c.Translations); msg != "" {
+		return errors.ErrValidation(msg)
+	}
+	existing, err := s.repo.GetByID(ctx, c.ID)
+	if err != nil || existing == nil || existing.PharmacyID != c.PharmacyID {
+		return errors.ErrNotFound("category")
+	}
+	if c.Translations == nil {
+		// Omitted on update = keep; {} clears them.
+		c.Translations = existing.Translations
+	}
+	if err := s.repo.Update(ctx, c); err != nil {
+		return err
+	}
+	if existing.Name != c.Name && s.productRepo != nil {
+		go s.resyncProducts(context.WithoutCancel(ctx), c.ID, c.Name)
+	}
+	return nil
+}
+
+// resyncProducts copies a renamed category's name onto its products. Anything it misses shows up as
+// product_category_drift in the data doctor.
+func (s *categoryService) resyncProducts(ctx context.Context, categoryID uuid.UUID, name string) {
+	ctx, cancel := context.WithTimeout(ctx, categoryResyncTimeout)
+	defer cancel()
+	n, err := s.productRepo.SyncCategoryName(ctx, categoryID, name)
+	if err != nil {
+		s.logger.Warn("category product re-sync failed", zap.String("category_id", categoryID.String()), zap.Error(err))
+		return
+	}
+	s.logger.Info("category products re-synced", zap.String("category_id", categoryID.String()), zap.Int64("products", n))
 }
 
 func (s *categoryService) Delete(ctx context.Context, id uuid.UUID) error {
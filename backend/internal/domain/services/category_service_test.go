@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestCategoryService_Update_RenameResyncsProducts(t *testing.T) {
+	pharmacyID := uuid.New()
+	existing := &models.Category{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Pain relief"}
+	cats := &mocks.MockCategoryRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Category, error) { return existing, nil },
+	}
+	synced := make(chan string, 1)
+	products := &mocks.MockProductRepository{
+		SyncCategoryNameFunc: func(ctx context.Context, categoryID uuid.UUID, name string) (int64, error) {
+			if categoryID == existing.ID {
+				synced <- name
+			}
+			return 3, nil
+		},
+	}
+	svc := NewCategoryService(cats, products, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := svc.Update(ctx, &models.Category{ID: existing.ID, PharmacyID: pharmacyID, Name: "Analgesics"}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	cancel() // the re-sync must outlive the request
+	select {
+	case name := <-synced:
+		if name != "Analgesics" {
+			t.Errorf("expected products re-synced to the new name, got %q", name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a background product re-sync")
+	}
+}
+
+func TestCategoryService_Update_OtherPharmacyNotFound(t *testing.T) {
+	cats := &mocks.MockCategoryRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Category, error) {
+			return &models.Category{ID: id, PharmacyID: uuid.New(), Name: "Vitamins"}, nil
+		},
+		UpdateFunc: func(ctx context.Context, c *models.Category) error {
+			t.Fatal("category of another pharmacy must not be saved")
+			return nil
+		},
+	}
+	err := NewCategoryService(cats, &mocks.MockProductRepository{}, zap.NewNop()).
+		Update(context.Background(), &models.Category{ID: uuid.New(), PharmacyID: uuid.New(), Name: "Vitamins"})
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestCategoryService_Translations(t *testing.T) {
+	pharmacyID := uuid.New()
+	existing := &models.Category{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Vitamins",
+		Translations: models.Translations{"ne": {Title: "भिटामिन"}}}
+	var saved *models.Category
+	cats := &mocks.MockCategoryRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Category, error) { return existing, nil },
+		UpdateFunc:  func(ctx context.Context, c *models.Category) error { saved = c; return nil },
+	}
+	svc := NewCategoryService(cats, nil, zap.NewNop())
+	ctx := context.Background()
+
+	err := svc.Create(ctx, &models.Category{PharmacyID: pharmacyID, Name: "Vitamins", Translations: models.Translations{"fr": {Title: "Vitamines"}}})
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("create with an unsupported language: err = %v, want validation", err)
+	}
+	if err := svc.Update(ctx, &models.Category{ID: existing.ID, PharmacyID: pharmacyID, Name: "Vitamins"}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if saved.Translations["ne"].Title != "भिटामिन" {
+		t.Errorf("update without translations: saved %v, want the existing ones kept", saved.Translations)
+	}
+	if err := svc.Update(ctx, &models.Category{ID: existing.ID, PharmacyID: pharmacyID, Name: "Vitamins", Translations: models.Translations{}}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if len(saved.Translations) != 0 {
+		t.Errorf("update with {}: saved %v, want translations cleared", saved.Translations)
+	}
+
+	cat := &models.Category{Name: "Vitamins", Description: "Daily supplements", Translations: existing.Translations,
+		Parent: &models.Category{Name: "Wellness", Translations: models.Translations{"ne": {Title: "स्वास्थ्य"}}}}
+	cat.Localize("ne")
+	if cat.Name != "भिटामिन" || cat.Description != "Daily supplements" || cat.Parent.Name != "स्वास्थ्य" {
+		t.Errorf("localized category = %q (%q), parent %q", cat.Name, cat.Description, cat.Parent.Name)
+	}
+	cat.Localize("en")
+	if cat.Name != "भिटामिन" {
+		t.Errorf("language without a variant changed the name to %q", cat.Name)
+	}
+}
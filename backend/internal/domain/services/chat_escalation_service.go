@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	apperr "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	maxEscalationReasonLen   = 500
+	escalationTranscriptSize = 20
+	maxTicketCommentLen      = 1000
+)
+
+type chatEscalationService struct {
+	convRepo      outbound.ConversationRepository
+	msgRepo       outbound.ChatMessageRepository
+	userRepo      outbound.UserRepository
+	provider      outbound.TicketingProvider
+	webhookSecret string
+	publicURL     string
+	logger        *zap.Logger
+}
+
+// NewChatEscalationService escalates conversations. provider may be nil (TICKETING_PROVIDER=none): conversations
+// are still marked escalated in the app, but no ticket is created and webhooks are rejected.
+func NewChatEscalationService(
+	convRepo outbound.ConversationRepository,
+	msgRepo outbound.ChatMessageRepository,
+	userRepo outbound.UserRepository,
+	provider outbound.TicketingProvider,
+	webhookSecret string,
+	publicURL string,
+	logger *zap.Logger,
+) inbound.ChatEscalationService {
+	return &chatEscalationService{
+		convRepo:      convRepo,
+		msgRepo:       msgRepo,
+		userRepo:      userRepo,
+		provider:      provider,
+		webhookSecret: webhookSecret,
+		publicURL:     strings.TrimRight(publicURL, "/"),
+		logger:        logger,
+	}
+}
+
+func (s *chatEscalationService) Escalate(ctx context.Context, pharmacyID, conversationID, actorID uuid.UUID, reason string) (*models.Conversation, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, apperr.ErrValidation("reason is required")
+	}
+	if len([]rune(reason)) > maxEscalationReasonLen {
+		return nil, apperr.ErrValidation(fmt.Sprintf("reason must be at most %d characters", maxEscalationReasonLen))
+	}
+	conv, err := s.convRepo.GetByID(ctx, conversationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperr.ErrNotFound("conversation")
+		}
+		return nil, apperr.ErrInternal("failed to load conversation", err)
+	}
+	if conv.PharmacyID != pharmacyID {
+		return nil, apperr.ErrNotFound("conversation")
+	}
+	if conv.TicketID != "" && conv.Status != models.ConversationStatusResolved {
+		return nil, apperr.ErrConflict("conversation is already escalated as ticket " + conv.TicketID)
+	}
+	actorName := "staff"
+	if actor, err := s.userRepo.GetByID(ctx, actorID); err == nil && actor != nil && actor.Name != "" {
+		actorName = actor.Name
+	}
+
+	// A conversation that is escalated without a ticket is a retry after a failed ticket creation.
+	if conv.Status != models.ConversationStatusEscalated {
+		now := time.Now()
+		conv.Status = models.ConversationStatusEscalated
+		conv.EscalatedAt = &now
+		conv.EscalatedBy = &actorID
+		conv.EscalationReason = reason
+		// Escalating a resolved conversation again opens a new ticket.
+		conv.TicketProvider, conv.TicketID, conv.TicketURL, conv.TicketStatus, conv.TicketError = "", "", "", "", ""
+		s.postSystemMessage(ctx, conv, "Escalated to the support team by "+actorName+".")
+	}
+	if s.provider != nil {
+		ref, err := s.provider.CreateTicket(ctx, s.ticketRequest(ctx, conv, actorName))
+		if err != nil {
+			s.logger.Warn("ticket creation failed", zap.String("provider", s.provider.Name()), zap.String("conversation_id", conv.ID.String()), zap.Error(err))
+			conv.TicketError = truncateRunes(err.Error(), 500)
+		} else {
+			conv.TicketProvider = s.provider.Name()
+			conv.TicketID = ref.ID
+			conv.TicketURL = ref.URL
+			conv.TicketStatus = ref.Status
+			conv.TicketError = ""
+			s.postSystemMessage(ctx, conv, "Support ticket "+ref.ID+" opened.")
+		}
+	}
+	if err := s.convRepo.Update(ctx, conv); err != nil {
+		return nil, apperr.ErrInternal("failed to update conversation", err)
+	}
+	return conv, nil
+}
+
+// ticketRequest describes the conversation with the requester's contact details and the latest messages.
+func (s *chatEscalationService) ticketRequest(ctx context.Context, conv *models.Conversation, actorName string) *outbound.TicketRequest {
+	req := &outbound.TicketRequest{
+		ConversationID:  conv.ID,
+		PharmacyID:      conv.PharmacyID,
+		Reason:          conv.EscalationReason,
+		EscalatedBy:     actorName,
+		ConversationURL: s.publicURL + "/chat?conversation=" + conv.ID.String(),
+	}
+	if conv.Pharmacy != nil {
+		req.PharmacyName = conv.Pharmacy.Name
+	}
+	if conv.Customer != nil {
+		req.RequesterName, req.RequesterPhone, req.RequesterEmail = conv.Customer.Name, conv.Customer.Phone, conv.Customer.Email
+	} else if conv.UserID != nil {
+		if u, err := s.userRepo.GetByID(ctx, *conv.UserID); err == nil && u != nil {
+			req.RequesterName, req.RequesterPhone, req.RequesterEmail = u.Name, u.Phone, u.Email
+		}
+	}
+	who := req.RequesterName
+	if who == "" {
+		who = req.RequesterPhone
+	}
+	if who == "" {
+		who = "customer"
+	}
+	req.Subject = truncateRunes("Chat escalation: "+who+" - "+conv.EscalationReason, 200)
+
+	var b strings.Builder
+	b.WriteString("Reason: " + conv.EscalationReason + "\n")
+	b.WriteString("Escalated by: " + actorName + "\n")
+	b.WriteString("Conversation: " + req.ConversationURL + "\n\nRecent messages:\n")
+	// Messages come newest first; the transcript reads oldest first.
+	msgs, _, err := s.msgRepo.ListByConversationID(ctx, conv.ID, escalationTranscriptSize, 0)
+	if err != nil {
+		s.logger.Warn("load transcript failed", zap.Error(err))
+	}
+	for i := len(msgs) - 1; i >= 0; i-- {
+		m := msgs[i]
+		text := m.Body
+		if m.AttachmentURL != "" {
+			text = strings.TrimSpace(text + " [attachment: " + m.AttachmentURL + "]")
+		}
+		fmt.Fprintf(&b, "[%s] %s: %s\n", m.CreatedAt.UTC().Format("2006-01-02 15:04"), m.SenderType, text)
+	}
+	req.Description = b.String()
+	return req
+}
+
+func (s *chatEscalationService) HandleTicketWebhook(ctx context.Context, secret string, body []byte) error {
+	if s.provider == nil {
+		return apperr.ErrNotFound("ticketing integration")
+	}
+	if secret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(s.webhookSecret)) != 1 {
+		return apperr.ErrUnauthorized("invalid webhook secret")
+	}
+	update, err := s.provider.ParseWebhook(body)
+	if err != nil {
+		return apperr.ErrValidation(err.Error())
+	}
+	if update == nil {
+		return nil
+	}
+	conv, err := s.convRepo.GetByTicket(ctx, s.provider.Name(), update.TicketID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperr.ErrNotFound("conversation for ticket " + update.TicketID)
+		}
+		return apperr.ErrInternal("failed to load conversation", err)
+	}
+	if update.Status != "" && !strings.EqualFold(update.Status, conv.TicketStatus) {
+		conv.TicketStatus = update.Status
+		switch {
+		case update.Resolved:
+			conv.Status = models.ConversationStatusResolved
+			s.postSystemMessage(ctx, conv, "Support ticket "+conv.TicketID+" resolved.")
+		default:
+			// A resolved ticket that is reopened puts the conversation back into escalation.
+			conv.Status = models.ConversationStatusEscalated
+			s.postSystemMessage(ctx, conv, "Support ticket "+conv.TicketID+" status: "+update.Status+".")
+		}
+	}
+	if comment := strings.TrimSpace(update.Comment); comment != "" {
+		s.postSystemMessage(ctx, conv, "Support: "+truncateRunes(comment, maxTicketCommentLen))
+	}
+	if err := s.convRepo.Update(ctx, conv); err != nil {
+		return apperr.ErrInternal("failed to update conversation", err)
+	}
+	return nil
+}
+
+// postSystemMessage adds an app-authored message to the conversation; failures are logged, not returned.
+func (s *chatEscalationService) postSystemMessage(ctx context.Context, conv *models.Conversation, body string) {
+	msg := &models.ChatMessage{ConversationID: conv.ID, SenderType: models.SenderTypeSystem, SenderID: uuid.Nil, Body: body}
+	if err := s.msgRepo.Create(ctx, msg); err != nil {
+		s.logger.Warn("create system message failed", zap.String("conversation_id", conv.ID.String()), zap.Error(err))
+		return
+	}
+	now := time.Now()
+	conv.LastMessageAt = &now
+}
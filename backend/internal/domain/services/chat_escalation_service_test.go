@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakeTicketing records created tickets and returns canned webhook updates.
+type fakeTicketing struct {
+	created   []*outbound.TicketRequest
+	createErr error
+	update    *outbound.TicketUpdate
+}
+
+func (f *fakeTicketing) Name() string { return "jira" }
+
+func (f *fakeTicketing) CreateTicket(ctx context.Context, req *outbound.TicketRequest) (*outbound.TicketRef, error) {
+	f.created = append(f.created, req)
+	if f.createErr != nil {
+		return nil, f.createErr
+	}
+	return &outbound.TicketRef{ID: "SUP-12", URL: "https://acme.atlassian.net/browse/SUP-12", Status: "open"}, nil
+}
+
+func (f *fakeTicketing) ParseWebhook(body []byte) (*outbound.TicketUpdate, error) {
+	return f.update, nil
+}
+
+type escalationFixture struct {
+	pharmacyID uuid.UUID
+	conv       *models.Conversation
+	messages   []*models.ChatMessage
+	provider   *fakeTicketing
+	svc        inbound.ChatEscalationService
+}
+
+func newEscalationFixture(withProvider bool) *escalationFixture {
+	f := &escalationFixture{pharmacyID: uuid.New(), provider: &fakeTicketing{}}
+	customerID := uuid.New()
+	f.conv = &models.Conversation{
+		ID:         uuid.New(),
+		PharmacyID: f.pharmacyID,
+		CustomerID: &customerID,
+		Status:     models.ConversationStatusOpen,
+		Pharmacy:   &models.Pharmacy{Name: "CarePlus Thamel"},
+		Customer:   &models.Customer{Name: "Sita", Phone: "+9779800000001"},
+	}
+	convRepo := &mocks.MockConversationRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Conversation, error) { return f.conv, nil },
+		GetByTicketFunc: func(ctx context.Context, provider, ticketID string) (*models.Conversation, error) {
+			if provider == f.conv.TicketProvider && ticketID == f.conv.TicketID {
+				return f.conv, nil
+			}
+			return nil, errors.New("unexpected ticket lookup")
+		},
+	}
+	msgRepo := &mocks.MockChatMessageRepository{
+		CreateFunc: func(ctx context.Context, m *models.ChatMessage) error {
+			f.messages = append(f.messages, m)
+			return nil
+		},
+		ListByConversationIDFunc: func(ctx context.Context, id uuid.UUID, limit, offset int) ([]*models.ChatMessage, int64, error) {
+			now := time.Now()
+			return []*models.ChatMessage{
+				{SenderType: models.SenderTypeUser, Body: "We will check", CreatedAt: now},
+				{SenderType: models.SenderTypeCustomer, Body: "My order arrived damaged", CreatedAt: now.Add(-time.Minute)},
+			}, 2, nil
+		},
+	}
+	users := &mocks.MockUserRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.User, error) {
+			return &models.User{ID: id, Name: "Ram"}, nil
+		},
+	}
+	var provider outbound.TicketingProvider
+	if withProvider {
+		provider = f.provider
+	}
+	f.svc = NewChatEscalationService(convRepo, msgRepo, users, provider, "0123456789abcdef", "https://shop.example.com", zap.NewNop())
+	return f
+}
+
+func TestChatEscalationService_Escalate_CreatesTicket(t *testing.T) {
+	f := newEscalationFixture(true)
+	conv, err := f.svc.Escalate(context.Background(), f.pharmacyID, f.conv.ID, uuid.New(), "  Damaged delivery  ")
+	if err != nil {
+		t.Fatalf("Escalate: %v", err)
+	}
+	if conv.Status != models.ConversationStatusEscalated || conv.EscalationReason != "Damaged delivery" {
+		t.Errorf("unexpected conversation state: %+v", conv)
+	}
+	if conv.TicketProvider != "jira" || conv.TicketID != "SUP-12" || conv.TicketURL == "" || conv.TicketError != "" {
+		t.Errorf("expected ticket reference stored, got %+v", conv)
+	}
+	if len(f.provider.created) != 1 {
+		t.Fatalf("expected one ticket, got %d", len(f.provider.created))
+	}
+	req := f.provider.created[0]
+	if req.RequesterPhone != "+9779800000001" || req.PharmacyName != "CarePlus Thamel" || req.EscalatedBy != "Ram" {
+		t.Errorf("unexpected ticket request: %+v", req)
+	}
+	// Transcript is oldest first.
+	if i, j := strings.Index(req.Description, "arrived damaged"), strings.Index(req.Description, "We will check"); i < 0 || j < i {
+		t.Errorf("expected chronological transcript, got %q", req.Description)
+	}
+	if len(f.messages) != 2 || f.messages[0].SenderType != models.SenderTypeSystem || !strings.Contains(f.messages[1].Body, "SUP-12") {
+		t.Errorf("expected escalation and ticket system messages, got %+v", f.messages)
+	}
+
+	if _, err := f.svc.Escalate(context.Background(), f.pharmacyID, f.conv.ID, uuid.New(), "again"); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeConflict {
+		t.Errorf("expected conflict while the ticket is open, got %v", err)
+	}
+}
+
+func TestChatEscalationService_Escalate_RetriesAfterFailure(t *testing.T) {
+	f := newEscalationFixture(true)
+	f.provider.createErr = errors.New("jira: status 503")
+	conv, err := f.svc.Escalate(context.Background(), f.pharmacyID, f.conv.ID, uuid.New(), "Damaged delivery")
+	if err != nil {
+		t.Fatalf("Escalate: %v", err)
+	}
+	if conv.Status != models.ConversationStatusEscalated || conv.TicketID != "" || conv.TicketError == "" {
+		t.Fatalf("expected escalated without ticket and an error recorded, got %+v", conv)
+	}
+
+	f.provider.createErr = nil
+	conv, err = f.svc.Escalate(context.Background(), f.pharmacyID, f.conv.ID, uuid.New(), "Damaged delivery")
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if conv.TicketID != "SUP-12" || conv.TicketError != "" {
+		t.Errorf("expected ticket after retry, got %+v", conv)
+	}
+	// Only one "escalated" system message for both attempts, plus the ticket message.
+	if len(f.messages) != 2 {
+		t.Errorf("expected 2 system messages, got %d", len(f.messages))
+	}
+}
+
+func TestChatEscalationService_Escalate_ValidatesAndScopes(t *testing.T) {
+	f := newEscalationFixture(false)
+	if _, err := f.svc.Escalate(context.Background(), f.pharmacyID, f.conv.ID, uuid.New(), " "); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("expected validation error for empty reason, got %v", err)
+	}
+	if _, err := f.svc.Escalate(context.Background(), uuid.New(), f.conv.ID, uuid.New(), "x"); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeNotFound {
+		t.Errorf("expected not found for another pharmacy, got %v", err)
+	}
+	conv, err := f.svc.Escalate(context.Background(), f.pharmacyID, f.conv.ID, uuid.New(), "x")
+	if err != nil || conv.Status != models.ConversationStatusEscalated || conv.TicketID != "" {
+		t.Errorf("expected in-app escalation without provider, got %+v %v", conv, err)
+	}
+}
+
+func TestChatEscalationService_HandleTicketWebhook(t *testing.T) {
+	f := newEscalationFixture(true)
+	f.conv.Status = models.ConversationStatusEscalated
+	f.conv.TicketProvider, f.conv.TicketID, f.conv.TicketStatus = "jira", "SUP-12", "open"
+
+	f.provider.update = &outbound.TicketUpdate{TicketID: "SUP-12", Status: "Done", Comment: "Replacement sent", Resolved: true}
+	if err := f.svc.HandleTicketWebhook(context.Background(), "wrong-secret", nil); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeUnauthorized {
+		t.Fatalf("expected unauthorized for a bad secret, got %v", err)
+	}
+	if err := f.svc.HandleTicketWebhook(context.Background(), "0123456789abcdef", nil); err != nil {
+		t.Fatalf("HandleTicketWebhook: %v", err)
+	}
+	if f.conv.Status != models.ConversationStatusResolved || f.conv.TicketStatus != "Done" {
+		t.Errorf("expected resolved conversation, got %+v", f.conv)
+	}
+	if len(f.messages) != 2 || !strings.Contains(f.messages[0].Body, "resolved") || f.messages[1].Body != "Support: Replacement sent" {
+		t.Errorf("unexpected system messages: %+v", f.messages)
+	}
+
+	// A repeated status does not post again.
+	f.provider.update = &outbound.TicketUpdate{TicketID: "SUP-12", Status: "done", Resolved: true}
+	_ = f.svc.HandleTicketWebhook(context.Background(), "0123456789abcdef", nil)
+	if len(f.messages) != 2 {
+		t.Errorf("expected no message for an unchanged status, got %d", len(f.messages))
+	}
+}
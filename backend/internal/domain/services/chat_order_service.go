@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	apperr "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// chatOrderScanSize is how many of the latest messages are searched for shared products.
+const chatOrderScanSize = 100
+
+// chatProductLinkRe matches storefront product links (/products/<id>) in product cards and message bodies.
+var chatProductLinkRe = regexp.MustCompile(`/products/([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})`)
+
+type chatOrderService struct {
+	convRepo    outbound.ConversationRepository
+	msgRepo     outbound.ChatMessageRepository
+	userRepo    outbound.UserRepository
+	productRepo outbound.ProductRepository
+	orderRepo   outbound.OrderRepository
+	orderSvc    inbound.OrderService
+	logger      *zap.Logger
+}
+
+func NewChatOrderService(
+	convRepo outbound.ConversationRepository,
+	msgRepo outbound.ChatMessageRepository,
+	userRepo outbound.UserRepository,
+	productRepo outbound.ProductRepository,
+	orderRepo outbound.OrderRepository,
+	orderSvc inbound.OrderService,
+	logger *zap.Logger,
+) inbound.ChatOrderService {
+	return &chatOrderService{
+		convRepo:    convRepo,
+		msgRepo:     msgRepo,
+		userRepo:    userRepo,
+		productRepo: productRepo,
+		orderRepo:   orderRepo,
+		orderSvc:    orderSvc,
+		logger:      logger,
+	}
+}
+
+func (s *chatOrderService) conversation(ctx context.Context, pharmacyID, conversationID uuid.UUID) (*models.Conversation, error) {
+	conv, err := s.convRepo.GetByID(ctx, conversationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperr.ErrNotFound("conversation")
+		}
+		return nil, apperr.ErrInternal("failed to load conversation", err)
+	}
+	if conv == nil || conv.PharmacyID != pharmacyID {
+		return nil, apperr.ErrNotFound("conversation")
+	}
+	return conv, nil
+}
+
+func (s *chatOrderService) Draft(ctx context.Context, pharmacyID, conversationID uuid.UUID) (*inbound.ChatOrderDraft, error) {
+	conv, err := s.conversation(ctx, pharmacyID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	return s.draft(ctx, conv)
+}
+
+func (s *chatOrderService) draft(ctx context.Context, conv *models.Conversation) (*inbound.ChatOrderDraft, error) {
+	d := &inbound.ChatOrderDraft{ConversationID: conv.ID, Items: []inbound.ChatOrderDraftItem{}}
+	if c := conv.Customer; c != nil {
+		d.CustomerID = &c.ID
+		d.CustomerName, d.CustomerPhone, d.CustomerEmail = c.Name, c.Phone, c.Email
+	} else if conv.UserID != nil {
+		if u, err := s.userRepo.GetByID(ctx, *conv.UserID); err == nil && u != nil {
+			d.CustomerName, d.CustomerPhone, d.CustomerEmail = u.Name, u.Phone, u.Email
+		}
+	}
+	msgs, _, err := s.msgRepo.ListByConversationID(ctx, conv.ID, chatOrderScanSize, 0)
+	if err != nil {
+		return nil, apperr.ErrInternal("failed to load messages", err)
+	}
+	// Messages come newest first; suggestions are listed in the order the products were first shared.
+	seen := map[uuid.UUID]bool{}
+	for i := len(msgs) - 1; i >= 0; i-- {
+		m := msgs[i]
+		for _, id := range sharedProductIDs(m) {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			p, err := s.productRepo.GetByID(ctx, id)
+			if err != nil || p == nil || p.PharmacyID != conv.PharmacyID {
+				continue
+			}
+			d.Items = append(d.Items, inbound.ChatOrderDraftItem{
+				ProductID: p.ID, Name: p.Name, SKU: p.SKU, Quantity: 1, UnitPrice: p.UnitPrice,
+				StockQuantity: p.StockQuantity, RequiresRx: p.RequiresRx,
+				Available:       p.IsActive && !p.DeletedAt.Valid && p.StockQuantity > 0,
+				SourceMessageID: m.ID,
+			})
+		}
+	}
+	return d, nil
+}
+
+// sharedProductIDs returns the products a message shares: its product card, then product links in the body.
+func sharedProductIDs(m *models.ChatMessage) []uuid.UUID {
+	var refs []string
+	if m.AttachmentType == models.ChatProductCardType {
+		refs = append(refs, m.AttachmentURL)
+	}
+	refs = append(refs, m.Body)
+	var ids []uuid.UUID
+	for _, ref := range refs {
+		for _, match := range chatProductLinkRe.FindAllStringSubmatch(ref, -1) {
+			if id, err := uuid.Parse(match[1]); err == nil {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+func (s *chatOrderService) CreateOrder(ctx context.Context, pharmacyID, conversationID, actorID uuid.UUID, in inbound.ChatOrderInput) (*models.Order, error) {
+	conv, err := s.conversation(ctx, pharmacyID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	d, err := s.draft(ctx, conv)
+	if err != nil {
+		return nil, err
+	}
+	pick := func(v, fallback string) string {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+		return fallback
+	}
+	items := in.Items
+	if len(items) == 0 {
+		for _, it := range d.Items {
+			if it.Available {
+				items = append(items, inbound.OrderItemInput{ProductID: it.ProductID, Quantity: it.Quantity, UnitPrice: it.UnitPrice})
+			}
+		}
+	}
+	if len(items) == 0 {
+		return nil, apperr.ErrValidation("no items: the conversation has no available shared products")
+	}
+	order, err := s.orderSvc.Create(ctx, pharmacyID, actorID,
+		pick(in.CustomerName, d.CustomerName), pick(in.CustomerPhone, d.CustomerPhone), pick(in.CustomerEmail, d.CustomerEmail),
+		items, strings.TrimSpace(in.Notes), strings.TrimSpace(in.DeliveryAddress), nil, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	// The order exists at this point, so a failed link or message is logged rather than failing the request.
+	if err := s.orderRepo.SetConversation(ctx, order.ID, conv.ID); err != nil {
+		s.logger.Warn("failed to link order to conversation", zap.String("order_id", order.ID.String()), zap.String("conversation_id", conv.ID.String()), zap.Error(err))
+	} else {
+		order.ConversationID = &conv.ID
+	}
+	msg := &models.ChatMessage{ConversationID: conv.ID, SenderType: models.SenderTypeSystem, SenderID: uuid.Nil, Body: "Order " + order.OrderNumber + " was created from this conversation."}
+	if err := s.msgRepo.Create(ctx, msg); err != nil {
+		s.logger.Warn("create system message failed", zap.String("conversation_id", conv.ID.String()), zap.Error(err))
+	} else {
+		now := time.Now()
+		conv.LastMessageAt = &now
+		if err := s.convRepo.Update(ctx, conv); err != nil {
+			s.logger.Warn("failed to update conversation", zap.String("conversation_id", conv.ID.String()), zap.Error(err))
+		}
+	}
+	return order, nil
+}
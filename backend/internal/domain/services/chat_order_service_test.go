@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestChatOrderService_CreateOrder_FromSharedProducts(t *testing.T) {
+	pharmacyID, actorID := uuid.New(), uuid.New()
+	customer := &models.Customer{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Sita", Phone: "9800000000"}
+	conv := &models.Conversation{ID: uuid.New(), PharmacyID: pharmacyID, CustomerID: &customer.ID, Customer: customer}
+	inStock := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Cetamol", UnitPrice: 25, StockQuantity: 10, IsActive: true}
+	soldOut := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Vitamin C", UnitPrice: 120, IsActive: true}
+	otherShop := &models.Product{ID: uuid.New(), PharmacyID: uuid.New(), Name: "Elsewhere", StockQuantity: 5, IsActive: true}
+	products := map[uuid.UUID]*models.Product{inStock.ID: inStock, soldOut.ID: soldOut, otherShop.ID: otherShop}
+
+	// Newest first, as the repository returns them.
+	msgs := []*models.ChatMessage{
+		{ID: uuid.New(), Body: "also https://shop.example/products/" + soldOut.ID.String() + " and /products/" + otherShop.ID.String()},
+		{ID: uuid.New(), Body: "Sharing a product", AttachmentType: models.ChatProductCardType, AttachmentURL: "/products/" + inStock.ID.String(), AttachmentName: inStock.Name},
+		{ID: uuid.New(), Body: "do you have /products/" + inStock.ID.String() + "?"},
+	}
+	var posted []*models.ChatMessage
+	var linked uuid.UUID
+	orders := &fakeOrderService{}
+	svc := &chatOrderService{
+		convRepo: &mocks.MockConversationRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Conversation, error) { return conv, nil }},
+		msgRepo: &mocks.MockChatMessageRepository{
+			ListByConversationIDFunc: func(ctx context.Context, id uuid.UUID, limit, offset int) ([]*models.ChatMessage, int64, error) {
+				return msgs, int64(len(msgs)), nil
+			},
+			CreateFunc: func(ctx context.Context, msg *models.ChatMessage) error { posted = append(posted, msg); return nil },
+		},
+		productRepo: &mocks.MockProductRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) { return products[id], nil }},
+		orderRepo: &mocks.MockOrderRepository{SetConversationFunc: func(ctx context.Context, orderID, conversationID uuid.UUID) error {
+			linked = conversationID
+			return nil
+		}},
+		orderSvc: orders,
+		logger:   zap.NewNop(),
+	}
+	ctx := context.Background()
+
+	d, err := svc.Draft(ctx, pharmacyID, conv.ID)
+	if err != nil {
+		t.Fatalf("Draft: %v", err)
+	}
+	if d.CustomerName != "Sita" || d.CustomerPhone != "9800000000" || d.CustomerID == nil || *d.CustomerID != customer.ID {
+		t.Errorf("draft customer = %q %q, want the conversation's customer", d.CustomerName, d.CustomerPhone)
+	}
+	if len(d.Items) != 2 || d.Items[0].ProductID != inStock.ID || d.Items[0].SourceMessageID != msgs[2].ID || d.Items[1].Available {
+		t.Fatalf("draft items = %+v, want the in-stock product first and the sold-out one unavailable", d.Items)
+	}
+
+	order, err := svc.CreateOrder(ctx, pharmacyID, conv.ID, actorID, inbound.ChatOrderInput{})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if len(orders.items) != 1 || orders.items[0].ProductID != inStock.ID || orders.items[0].Quantity != 1 {
+		t.Errorf("ordered items = %+v, want only the available shared product", orders.items)
+	}
+	if linked != conv.ID || order.ConversationID == nil || *order.ConversationID != conv.ID {
+		t.Errorf("order not linked to the conversation")
+	}
+	if len(posted) != 1 || posted[0].SenderType != models.SenderTypeSystem || !strings.Contains(posted[0].Body, "created from this conversation") {
+		t.Errorf("system message = %+v", posted)
+	}
+
+	if _, err := svc.Draft(ctx, uuid.New(), conv.ID); err == nil {
+		t.Error("Draft for another pharmacy's conversation should fail")
+	}
+}
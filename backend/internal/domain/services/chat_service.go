@@ -21,6 +21,8 @@ type chatService struct {
 	msgRepo     outbound.ChatMessageRepository
 	configRepo  outbound.PharmacyConfigRepository
 	customerRepo outbound.CustomerRepository
+	pushNotifier inbound.PushNotificationService
+	files       inbound.FileCleanupService
 	logger      *zap.Logger
 }
 
@@ -29,6 +31,8 @@ func NewChatService(
 	msgRepo outbound.ChatMessageRepository,
 	configRepo outbound.PharmacyConfigRepository,
 	customerRepo outbound.CustomerRepository,
+	pushNotifier inbound.PushNotificationService,
+	files inbound.FileCleanupService,
 	logger *zap.Logger,
 ) inbound.ChatService {
 	return &chatService{
@@ -36,6 +40,8 @@ func NewChatService(
 		msgRepo:      msgRepo,
 		configRepo:   configRepo,
 		customerRepo: customerRepo,
+		pushNotifier: pushNotifier,
+		files:        files,
 		logger:       logger,
 	}
 }
@@ -174,6 +180,9 @@ func (s *chatService) SendMessage(ctx context.Context, conversationID uuid.UUID,
 	now := time.Now()
 	conv.LastMessageAt = &now
 	_ = s.convRepo.Update(ctx, conv)
+	if s.pushNotifier != nil {
+		_ = s.pushNotifier.ChatMessage(ctx, conv, msg)
+	}
 	return msg, nil
 }
 
@@ -258,7 +267,11 @@ func (s *chatService) DeleteMessage(ctx context.Context, conversationID, message
 			return apperr.ErrForbidden("can only delete your own messages")
 		}
 	}
-	return s.msgRepo.Delete(ctx, messageID)
+	if err := s.msgRepo.Delete(ctx, messageID); err != nil {
+		return err
+	}
+	releaseFiles(ctx, s.files, s.logger, msg.AttachmentURL)
+	return nil
 }
 
 func (s *chatService) DeleteConversation(ctx context.Context, conversationID, pharmacyID uuid.UUID, customerID *uuid.UUID, userID *uuid.UUID, role string) error {
@@ -277,11 +290,39 @@ func (s *chatService) DeleteConversation(ctx context.Context, conversationID, ph
 	} else {
 		return apperr.ErrForbidden("cannot delete this conversation")
 	}
+	attachments, err := s.attachmentURLs(ctx, conversationID)
+	if err != nil {
+		return err
+	}
 	if err := s.msgRepo.DeleteByConversationID(ctx, conversationID); err != nil {
 		s.logger.Warn("delete conversation messages failed", zap.Error(err))
 		return err
 	}
-	return s.convRepo.Delete(ctx, conversationID)
+	if err := s.convRepo.Delete(ctx, conversationID); err != nil {
+		return err
+	}
+	releaseFiles(ctx, s.files, s.logger, attachments...)
+	return nil
+}
+
+// attachmentURLs collects the files attached to a conversation's messages, page by page.
+func (s *chatService) attachmentURLs(ctx context.Context, conversationID uuid.UUID) ([]string, error) {
+	const page = 200
+	var urls []string
+	for offset := 0; ; offset += page {
+		list, total, err := s.msgRepo.ListByConversationID(ctx, conversationID, page, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range list {
+			if m.AttachmentURL != "" {
+				urls = append(urls, m.AttachmentURL)
+			}
+		}
+		if len(list) < page || int64(offset+page) >= total {
+			return urls, nil
+		}
+	}
 }
 
 func (s *chatService) GetChatEditWindowMinutes(ctx context.Context, pharmacyID uuid.UUID) int {
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// ValidateCheckout runs the checks order creation would, on the same cart view, and collects every problem
+// instead of stopping at the first. Nothing is reserved: flash-sale caps, points and birthday gifts are only
+// claimed by POST /orders.
+func (s *cartService) ValidateCheckout(ctx context.Context, pharmacyID, userID uuid.UUID, input inbound.CheckoutValidationInput) (*inbound.CheckoutValidation, error) {
+	cart, err := s.checkoutCart(ctx, pharmacyID, userID, input)
+	if err != nil {
+		return nil, err
+	}
+	view, err := s.view(ctx, pharmacyID, userID, cart)
+	if err != nil {
+		return nil, err
+	}
+	if cart.ID == uuid.Nil {
+		view.ID = nil
+	}
+	out := &inbound.CheckoutValidation{Cart: view, Issues: []inbound.CheckoutIssue{}}
+	add := func(code, severity string, productID *uuid.UUID, message string) {
+		out.Issues = append(out.Issues, inbound.CheckoutIssue{Code: code, Severity: severity, ProductID: productID, Message: message})
+	}
+
+	if len(view.Items) == 0 {
+		add("empty_cart", inbound.CheckoutIssueError, nil, "cart is empty")
+	}
+	for i := range view.Items {
+		line := &view.Items[i]
+		switch line.Problem {
+		case "unavailable":
+			add("unavailable", inbound.CheckoutIssueError, &line.ProductID, "a product in the cart is no longer available")
+		case "out of stock":
+			add("out_of_stock", inbound.CheckoutIssueError, &line.ProductID, line.Name+" is out of stock")
+		case "insufficient stock":
+			add("insufficient_stock", inbound.CheckoutIssueError, &line.ProductID, fmt.Sprintf("only %d of %s in stock", line.Available, line.Name))
+		default:
+			if strings.HasPrefix(line.Problem, "priced in ") {
+				add("currency_mismatch", inbound.CheckoutIssueError, &line.ProductID, line.Name+" is "+line.Problem+", not "+view.Currency)
+			}
+		}
+	}
+	for _, it := range cart.Items {
+		if p := it.Product; p != nil && p.IsActive && p.PharmacyID == pharmacyID && p.RequiresRx {
+			out.RequiresPrescription = true
+			add("prescription_required", inbound.CheckoutIssueWarning, &p.ID, p.Name+" requires a prescription; a pharmacist checks it before the order is confirmed")
+		}
+	}
+	if view.PromoError != "" {
+		add("promo_code", inbound.CheckoutIssueError, nil, view.PromoError)
+	}
+	if cart.PointsToRedeem > 0 && view.SubTotal > 0 && view.PromoError == "" {
+		switch {
+		case view.PointsDiscount == 0:
+			add("points", inbound.CheckoutIssueWarning, nil, "no points can be redeemed on this order")
+		case view.MaxRedeemable < cart.PointsToRedeem:
+			add("points", inbound.CheckoutIssueWarning, nil, fmt.Sprintf("only %d of %d points can be redeemed", view.MaxRedeemable, cart.PointsToRedeem))
+		}
+	}
+
+	var cfg *models.PharmacyConfig
+	if s.configRepo != nil {
+		cfg, _ = s.configRepo.GetByPharmacyID(ctx, pharmacyID)
+	}
+	addr := strings.TrimSpace(input.DeliveryAddress)
+	out.Delivery.Requested = addr != ""
+	out.Delivery.Available = !out.Delivery.Requested || cfg.DeliversTo(addr)
+	if !out.Delivery.Available {
+		add("delivery_area", inbound.CheckoutIssueError, nil, "delivery is not available to this address")
+	}
+	if out.Delivery.Requested && cfg != nil && cfg.DeliveryFee > 0 {
+		out.Delivery.Fee = cfg.DeliveryFee
+		if s.freeDelivery(ctx, pharmacyID, userID) {
+			out.Delivery.Fee, out.Delivery.FeeWaived = 0, cfg.DeliveryFee
+		}
+	}
+
+	out.Payment.Available = true
+	out.Payment.Methods = []inbound.CheckoutPaymentMethod{}
+	if s.paymentGatewayRepo != nil {
+		gateways, err := s.paymentGatewayRepo.ListByPharmacy(ctx, pharmacyID, true)
+		if err != nil {
+			return nil, errors.ErrInternal("failed to load payment methods", err)
+		}
+		for _, g := range gateways {
+			out.Payment.Methods = append(out.Payment.Methods, inbound.CheckoutPaymentMethod{ID: g.ID, Code: g.Code, Name: g.Name})
+		}
+	}
+	if id := input.PaymentGatewayID; id != nil && *id != uuid.Nil {
+		out.Payment.GatewayID = id
+		out.Payment.Available = false
+		for _, m := range out.Payment.Methods {
+			if m.ID == *id {
+				out.Payment.Available = true
+			}
+		}
+		if !out.Payment.Available {
+			add("payment_method", inbound.CheckoutIssueError, nil, "the selected payment method is not available")
+		}
+	}
+
+	out.TotalAmount = roundMoney(view.TotalAmount + out.Delivery.Fee)
+	out.Valid = true
+	for _, is := range out.Issues {
+		if is.Severity == inbound.CheckoutIssueError {
+			out.Valid = false
+		}
+	}
+	return out, nil
+}
+
+// checkoutCart returns the cart to validate: the user's open cart, or input.Items priced as a cart that is not
+// stored. Codes given in the input replace the cart's for this check only.
+func (s *cartService) checkoutCart(ctx context.Context, pharmacyID, userID uuid.UUID, input inbound.CheckoutValidationInput) (*models.Cart, error) {
+	stored, err := s.cartRepo.GetOpen(ctx, pharmacyID, userID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load cart", err)
+	}
+	cart := &models.Cart{PharmacyID: pharmacyID, UserID: userID}
+	if stored != nil {
+		cart.PromoCode, cart.ReferralCode, cart.PointsToRedeem = stored.PromoCode, stored.ReferralCode, stored.PointsToRedeem
+		if len(input.Items) == 0 {
+			cart.ID, cart.Items = stored.ID, stored.Items
+		}
+	}
+	if len(input.Items) > 0 {
+		lines := map[uuid.UUID]*models.CartItem{}
+		for _, in := range input.Items {
+			if line, ok := lines[in.ProductID]; ok {
+				line.Quantity += in.Quantity
+				continue
+			}
+			// A missing product stays a line without a product, reported as unavailable.
+			p, _ := s.productRepo.GetByID(ctx, in.ProductID)
+			line := &models.CartItem{ProductID: in.ProductID, Quantity: in.Quantity, Product: p}
+			lines[in.ProductID] = line
+			cart.Items = append(cart.Items, line)
+		}
+		for _, line := range cart.Items {
+			if line.Quantity > maxCartLineQuantity {
+				return nil, errors.ErrValidation("quantity is too large")
+			}
+		}
+	}
+	if input.PromoCode != nil {
+		cart.PromoCode = strings.TrimSpace(*input.PromoCode)
+	}
+	if input.PointsToRedeem != nil {
+		cart.PointsToRedeem = *input.PointsToRedeem
+	}
+	return cart, nil
+}
+
+// freeDelivery reports whether the user's loyalty customer has a tier that waives the delivery fee.
+func (s *cartService) freeDelivery(ctx context.Context, pharmacyID, userID uuid.UUID) bool {
+	if s.benefitsEngine == nil {
+		return false
+	}
+	customer := s.customerForUser(ctx, pharmacyID, userID)
+	if customer == nil {
+		return false
+	}
+	b, err := s.benefitsEngine.ForCustomer(ctx, customer.ID, time.Now())
+	return err == nil && b != nil && b.Benefits.FreeDelivery
+}
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	clearancePromoDays    = 30
+	clearanceCodePrefix   = "CLR"
+	clearanceCodeRandLen  = 6
+	clearanceMaxDiscount  = 90.0
+	clearanceCandidateMax = 500
+)
+
+type clearanceService struct {
+	repo             outbound.ClearanceRepository
+	reportRepo       outbound.ReportRepository
+	productRepo      outbound.ProductRepository
+	promoCodeRepo    outbound.PromoCodeRepository
+	promoCodeService inbound.PromoCodeService
+	inventoryService inbound.InventoryService
+	uow              outbound.UnitOfWork
+	logger           *zap.Logger
+	now              func() time.Time
+}
+
+// NewClearanceService returns the service. Promo codes are created through promoCodeService so they get the same
+// validation as hand-made ones; supplier returns take stock out through inventoryService (FEFO across batches).
+func NewClearanceService(repo outbound.ClearanceRepository, reportRepo outbound.ReportRepository, productRepo outbound.ProductRepository, promoCodeRepo outbound.PromoCodeRepository, promoCodeService inbound.PromoCodeService, inventoryService inbound.InventoryService, uow outbound.UnitOfWork, logger *zap.Logger) inbound.ClearanceService {
+	return &clearanceService{repo: repo, reportRepo: reportRepo, productRepo: productRepo, promoCodeRepo: promoCodeRepo, promoCodeService: promoCodeService, inventoryService: inventoryService, uow: uow, logger: logger, now: time.Now}
+}
+
+func (s *clearanceService) SupplierCandidates(ctx context.Context, pharmacyID uuid.UUID, days int, minValue float64) ([]*inbound.SupplierReturnCandidate, error) {
+	if days <= 0 {
+		days = defaultDeadStockDays
+	}
+	if days > maxReportRangeDays {
+		return nil, errors.ErrValidation("days is too long (max 2 years)")
+	}
+	rows, err := s.reportRepo.DeadStock(ctx, pharmacyID, s.now().AddDate(0, 0, -days), minValue, clearanceCandidateMax)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load dead stock", err)
+	}
+	bySupplier := make(map[uuid.UUID]*inbound.SupplierReturnCandidate)
+	var out []*inbound.SupplierReturnCandidate
+	for _, r := range rows {
+		if r.SupplierID == nil || r.ClearanceActionID != nil {
+			continue
+		}
+		c := bySupplier[*r.SupplierID]
+		if c == nil {
+			c = &inbound.SupplierReturnCandidate{SupplierID: *r.SupplierID, SupplierName: r.SupplierName}
+			bySupplier[*r.SupplierID] = c
+			out = append(out, c)
+		}
+		c.Products = append(c.Products, r)
+		c.StockQuantity += r.StockQuantity
+		c.StockValue = roundMoney(c.StockValue + r.StockValue)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].StockValue > out[j].StockValue })
+	return out, nil
+}
+
+func (s *clearanceService) Create(ctx context.Context, pharmacyID, actorID uuid.UUID, in inbound.ClearanceInput) (*models.ClearanceAction, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return nil, errors.ErrValidation("name is required")
+	}
+	switch in.Kind {
+	case models.ClearanceKindPromo:
+		if in.DiscountPercent <= 0 || in.DiscountPercent > clearanceMaxDiscount {
+			return nil, errors.ErrValidation("discount_percent must be between 0 and 90")
+		}
+	case models.ClearanceKindSupplierReturn:
+	default:
+		return nil, errors.ErrValidation("kind must be promo or supplier_return")
+	}
+	var productIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, id := range in.ProductIDs {
+		if !seen[id] {
+			seen[id] = true
+			productIDs = append(productIDs, id)
+		}
+	}
+	if len(productIDs) == 0 {
+		return nil, errors.ErrValidation("at least one product is required")
+	}
+	busy, err := s.repo.OpenProductIDs(ctx, pharmacyID, productIDs)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to check open clearance actions", err)
+	}
+	if len(busy) > 0 {
+		return nil, errors.ErrConflict("a product is already in an open clearance action")
+	}
+
+	a := &models.ClearanceAction{
+		PharmacyID: pharmacyID,
+		Kind:       in.Kind,
+		Status:     models.ClearanceOpen,
+		Name:       truncateRunes(name, 255),
+		Note:       truncateRunes(strings.TrimSpace(in.Note), 500),
+		CreatedBy:  actorID,
+	}
+	for _, id := range productIDs {
+		p, err := s.productRepo.GetByID(ctx, id)
+		if err != nil || p == nil || p.PharmacyID != pharmacyID {
+			return nil, errors.ErrNotFound("product")
+		}
+		if p.StockQuantity <= 0 {
+			return nil, errors.ErrValidation(p.Name + " has no stock to clear")
+		}
+		if in.Kind == models.ClearanceKindSupplierReturn {
+			if p.SupplierID == nil {
+				return nil, errors.ErrValidation(p.Name + " has no supplier")
+			}
+			if a.SupplierID == nil {
+				a.SupplierID = p.SupplierID
+			} else if *a.SupplierID != *p.SupplierID {
+				return nil, errors.ErrValidation("products come from different suppliers; open one supplier return per supplier")
+			}
+		}
+		value := roundMoney(float64(p.StockQuantity) * p.UnitPrice)
+		a.Items = append(a.Items, &models.ClearanceItem{
+			ProductID:  p.ID,
+			Name:       p.Name,
+			SKU:        p.SKU,
+			Quantity:   p.StockQuantity,
+			UnitPrice:  p.UnitPrice,
+			StockValue: value,
+		})
+		a.StockValue = roundMoney(a.StockValue + value)
+	}
+
+	err = inTx(ctx, s.uow, func(ctx context.Context) error {
+		if in.Kind == models.ClearanceKindPromo {
+			promo, err := s.createPromo(ctx, pharmacyID, in, productIDs)
+			if err != nil {
+				return err
+			}
+			a.PromoCodeID = &promo.ID
+			a.DiscountPercent = promo.DiscountValue
+		}
+		if err := s.repo.Create(ctx, a); err != nil {
+			return errors.ErrInternal("failed to create clearance action", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, pharmacyID, a.ID)
+}
+
+// createPromo creates the action's percent code, limited to its products, from now for ValidDays.
+func (s *clearanceService) createPromo(ctx context.Context, pharmacyID uuid.UUID, in inbound.ClearanceInput, productIDs []uuid.UUID) (*models.PromoCode, error) {
+	days := in.ValidDays
+	if days <= 0 {
+		days = clearancePromoDays
+	}
+	code := strings.TrimSpace(in.Code)
+	if code == "" {
+		generated, err := s.generateCode(ctx, pharmacyID)
+		if err != nil {
+			return nil, err
+		}
+		code = generated
+	}
+	now := s.now()
+	return s.promoCodeService.Create(ctx, pharmacyID, &models.PromoCode{
+		Code:          code,
+		DiscountType:  models.DiscountTypePercent,
+		DiscountValue: roundMoney(in.DiscountPercent),
+		ValidFrom:     now,
+		ValidUntil:    now.AddDate(0, 0, days),
+		IsActive:      true,
+		ProductIDs:    productIDs,
+	})
+}
+
+func (s *clearanceService) generateCode(ctx context.Context, pharmacyID uuid.UUID) (string, error) {
+	for i := 0; i < 20; i++ {
+		var b strings.Builder
+		b.WriteString(clearanceCodePrefix)
+		for j := 0; j < clearanceCodeRandLen; j++ {
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(referralCodeChars))))
+			if err != nil {
+				return "", errors.ErrInternal("failed to generate promo code", err)
+			}
+			b.WriteByte(referralCodeChars[n.Int64()])
+		}
+		if existing, err := s.promoCodeRepo.GetByPharmacyAndCode(ctx, pharmacyID, b.String()); err != nil || existing == nil {
+			return b.String(), nil
+		}
+	}
+	return "", errors.ErrInternal("failed to generate unique promo code", nil)
+}
+
+func (s *clearanceService) List(ctx context.Context, pharmacyID uuid.UUID, kind models.ClearanceKind, status models.ClearanceStatus, limit, offset int) ([]*models.ClearanceAction, int64, error) {
+	limit, offset = clampPage(limit, offset)
+	list, total, err := s.repo.List(ctx, pharmacyID, outbound.ClearanceFilter{Kind: kind, Status: status}, limit, offset)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to list clearance actions", err)
+	}
+	if err := s.fillRecovery(ctx, list); err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+func (s *clearanceService) Get(ctx context.Context, pharmacyID, id uuid.UUID) (*models.ClearanceAction, error) {
+	a, err := s.load(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillRecovery(ctx, []*models.ClearanceAction{a}); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *clearanceService) load(ctx context.Context, pharmacyID, id uuid.UUID) (*models.ClearanceAction, error) {
+	a, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load clearance action", err)
+	}
+	if a == nil || a.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("clearance action")
+	}
+	return a, nil
+}
+
+// fillRecovery sets RecoveredValue and UnitsCleared: promo sales for promo actions, recorded returns and
+// credits for supplier returns.
+func (s *clearanceService) fillRecovery(ctx context.Context, list []*models.ClearanceAction) error {
+	var promoIDs []uuid.UUID
+	for _, a := range list {
+		a.RecoveredValue, a.UnitsCleared = 0, 0
+		if a.Kind == models.ClearanceKindPromo {
+			promoIDs = append(promoIDs, a.ID)
+			continue
+		}
+		for _, it := range a.Items {
+			a.UnitsCleared += it.ReturnedQuantity
+			a.RecoveredValue += it.CreditAmount
+		}
+		a.RecoveredValue = roundMoney(a.RecoveredValue)
+	}
+	if len(promoIDs) == 0 {
+		return nil
+	}
+	rows, err := s.repo.PromoRecovery(ctx, promoIDs)
+	if err != nil {
+		return errors.ErrInternal("failed to load clearance sales", err)
+	}
+	byAction := make(map[uuid.UUID]*models.ClearanceRecoveryRow, len(rows))
+	for _, r := range rows {
+		byAction[r.ActionID] = r
+	}
+	for _, a := range list {
+		if r := byAction[a.ID]; r != nil {
+			a.UnitsCleared = r.UnitsSold
+			a.RecoveredValue = roundMoney(r.Revenue)
+		}
+	}
+	return nil
+}
+
+func (s *clearanceService) RecordReturn(ctx context.Context, pharmacyID, id uuid.UUID, in inbound.ClearanceReturnInput) (*models.ClearanceAction, error) {
+	a, err := s.load(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if a.Kind != models.ClearanceKindSupplierReturn {
+		return nil, errors.ErrValidation("returns can only be recorded on a supplier return")
+	}
+	if a.Status != models.ClearanceOpen {
+		return nil, errors.ErrValidation("clearance action is " + string(a.Status))
+	}
+	if len(in.Lines) == 0 {
+		return nil, errors.ErrValidation("at least one line is required")
+	}
+	items := make(map[uuid.UUID]*models.ClearanceItem, len(a.Items))
+	for _, it := range a.Items {
+		items[it.ProductID] = it
+	}
+	for _, l := range in.Lines {
+		it := items[l.ProductID]
+		if it == nil {
+			return nil, errors.ErrValidation("product is not in this supplier return")
+		}
+		if l.Quantity <= 0 || l.CreditAmount < 0 {
+			return nil, errors.ErrValidation("quantity must be positive and credit_amount cannot be negative")
+		}
+		if it.ReturnedQuantity+l.Quantity > it.Quantity {
+			return nil, errors.ErrValidation("cannot return more of " + it.Name + " than the action holds")
+		}
+	}
+	err = inTx(ctx, s.uow, func(ctx context.Context) error {
+		for _, l := range in.Lines {
+			it := items[l.ProductID]
+			ok, err := s.repo.RecordReturn(ctx, it.ID, l.Quantity, roundMoney(l.CreditAmount))
+			if err != nil {
+				return errors.ErrInternal("failed to record supplier return", err)
+			}
+			if !ok {
+				return errors.ErrConflict("cannot return more of " + it.Name + " than the action holds")
+			}
+			if err := s.inventoryService.Consume(ctx, &models.OrderItem{ProductID: it.ProductID, Quantity: l.Quantity}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, pharmacyID, id)
+}
+
+func (s *clearanceService) Complete(ctx context.Context, pharmacyID, id, actorID uuid.UUID) (*models.ClearanceAction, error) {
+	return s.close(ctx, pharmacyID, id, actorID, models.ClearanceCompleted)
+}
+
+func (s *clearanceService) Cancel(ctx context.Context, pharmacyID, id, actorID uuid.UUID) (*models.ClearanceAction, error) {
+	return s.close(ctx, pharmacyID, id, actorID, models.ClearanceCancelled)
+}
+
+// close moves an open action to status and stops its promo code from applying to new orders.
+func (s *clearanceService) close(ctx context.Context, pharmacyID, id, actorID uuid.UUID, status models.ClearanceStatus) (*models.ClearanceAction, error) {
+	a, err := s.load(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if a.Status != models.ClearanceOpen {
+		return nil, errors.ErrValidation("clearance action is already " + string(a.Status))
+	}
+	now := s.now()
+	a.Status, a.ClosedBy, a.ClosedAt = status, &actorID, &now
+	err = inTx(ctx, s.uow, func(ctx context.Context) error {
+		ok, err := s.repo.Close(ctx, a)
+		if err != nil {
+			return errors.ErrInternal("failed to close clearance action", err)
+		}
+		if !ok {
+			return errors.ErrConflict("clearance action was closed by another request")
+		}
+		if a.PromoCodeID == nil {
+			return nil
+		}
+		promo, err := s.promoCodeRepo.GetByID(ctx, *a.PromoCodeID)
+		if err != nil || promo == nil || !promo.IsActive {
+			return nil
+		}
+		promo.IsActive = false
+		if err := s.promoCodeRepo.Update(ctx, promo); err != nil {
+			return errors.ErrInternal("failed to deactivate clearance promo code", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, pharmacyID, id)
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// clearanceFixture keeps one stored action in memory and records stock adjustments.
+type clearanceFixture struct {
+	pharmacyID uuid.UUID
+	products   map[uuid.UUID]*models.Product
+	action     *models.ClearanceAction
+	promos     map[uuid.UUID]*models.PromoCode
+	stock      map[uuid.UUID]int
+	recovery   []*models.ClearanceRecoveryRow
+	svc        inbound.ClearanceService
+}
+
+func newClearanceFixture(products ...*models.Product) *clearanceFixture {
+	f := &clearanceFixture{pharmacyID: uuid.New(), products: map[uuid.UUID]*models.Product{}, promos: map[uuid.UUID]*models.PromoCode{}, stock: map[uuid.UUID]int{}}
+	for _, p := range products {
+		p.PharmacyID = f.pharmacyID
+		f.products[p.ID] = p
+	}
+	productRepo := &mocks.MockProductRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+			return f.products[id], nil
+		},
+		AdjustStockFunc: func(ctx context.Context, id uuid.UUID, delta int) (bool, error) {
+			f.stock[id] += delta
+			return true, nil
+		},
+	}
+	repo := &mocks.MockClearanceRepository{
+		CreateFunc: func(ctx context.Context, a *models.ClearanceAction) error {
+			a.ID = uuid.New()
+			for _, it := range a.Items {
+				it.ID, it.ActionID = uuid.New(), a.ID
+			}
+			f.action = a
+			return nil
+		},
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.ClearanceAction, error) {
+			if f.action == nil || f.action.ID != id {
+				return nil, nil
+			}
+			return f.action, nil
+		},
+		RecordReturnFunc: func(ctx context.Context, itemID uuid.UUID, quantity int, credit float64) (bool, error) {
+			for _, it := range f.action.Items {
+				if it.ID == itemID {
+					it.ReturnedQuantity += quantity
+					it.CreditAmount += credit
+				}
+			}
+			return true, nil
+		},
+		PromoRecoveryFunc: func(ctx context.Context, actionIDs []uuid.UUID) ([]*models.ClearanceRecoveryRow, error) {
+			return f.recovery, nil
+		},
+	}
+	promoRepo := &mocks.MockPromoCodeRepository{
+		CreateFunc: func(ctx context.Context, p *models.PromoCode) error {
+			p.ID = uuid.New()
+			f.promos[p.ID] = p
+			return nil
+		},
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.PromoCode, error) {
+			return f.promos[id], nil
+		},
+	}
+	inventory := NewInventoryService(&mocks.MockInventoryBatchRepository{}, productRepo, nil, nil)
+	f.svc = NewClearanceService(repo, &mocks.MockReportRepository{}, productRepo, promoRepo, NewPromoCodeService(promoRepo, nil, zap.NewNop()), inventory, &mocks.MockUnitOfWork{}, zap.NewNop())
+	return f
+}
+
+func TestClearanceService_Create_PromoScopesCodeAndSupplierReturnNeedsOneSupplier(t *testing.T) {
+	supplierA, supplierB := uuid.New(), uuid.New()
+	cream := &models.Product{ID: uuid.New(), Name: "Cream", StockQuantity: 10, UnitPrice: 150, SupplierID: &supplierA}
+	syrup := &models.Product{ID: uuid.New(), Name: "Syrup", StockQuantity: 4, UnitPrice: 80.5, SupplierID: &supplierB}
+	f := newClearanceFixture(cream, syrup)
+	ctx := context.Background()
+
+	_, err := f.svc.Create(ctx, f.pharmacyID, uuid.New(), inbound.ClearanceInput{
+		Kind: models.ClearanceKindSupplierReturn, Name: "Return", ProductIDs: []uuid.UUID{cream.ID, syrup.ID},
+	})
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("err = %v, want validation error for mixed suppliers", err)
+	}
+
+	a, err := f.svc.Create(ctx, f.pharmacyID, uuid.New(), inbound.ClearanceInput{
+		Kind: models.ClearanceKindPromo, Name: "Winter clearance", DiscountPercent: 30, ProductIDs: []uuid.UUID{cream.ID, syrup.ID, cream.ID},
+	})
+	if err != nil {
+		t.Fatalf("Create promo: %v", err)
+	}
+	if len(a.Items) != 2 || a.StockValue != 1822 || a.Status != models.ClearanceOpen {
+		t.Errorf("action = %+v, want 2 items worth 1822 and open", a)
+	}
+	promo := f.promos[*a.PromoCodeID]
+	if promo == nil || promo.DiscountType != models.DiscountTypePercent || promo.DiscountValue != 30 || len(promo.ProductIDs) != 2 || len(promo.Code) != len(clearanceCodePrefix)+clearanceCodeRandLen {
+		t.Errorf("promo = %+v, want a generated 30%% code scoped to both products", promo)
+	}
+}
+
+func TestClearanceService_RecordReturn_TakesStockAndTracksCredit(t *testing.T) {
+	supplier := uuid.New()
+	cream := &models.Product{ID: uuid.New(), Name: "Cream", StockQuantity: 10, UnitPrice: 150, SupplierID: &supplier}
+	f := newClearanceFixture(cream)
+	ctx := context.Background()
+	a, err := f.svc.Create(ctx, f.pharmacyID, uuid.New(), inbound.ClearanceInput{
+		Kind: models.ClearanceKindSupplierReturn, Name: "Return to supplier", ProductIDs: []uuid.UUID{cream.ID},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if a.SupplierID == nil || *a.SupplierID != supplier {
+		t.Fatalf("supplier = %v, want %v", a.SupplierID, supplier)
+	}
+
+	_, err = f.svc.RecordReturn(ctx, f.pharmacyID, a.ID, inbound.ClearanceReturnInput{Lines: []inbound.ClearanceReturnLine{{ProductID: cream.ID, Quantity: 11}}})
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("err = %v, want validation error for returning more than the action holds", err)
+	}
+	a, err = f.svc.RecordReturn(ctx, f.pharmacyID, a.ID, inbound.ClearanceReturnInput{Lines: []inbound.ClearanceReturnLine{{ProductID: cream.ID, Quantity: 6, CreditAmount: 720}}})
+	if err != nil {
+		t.Fatalf("RecordReturn: %v", err)
+	}
+	if f.stock[cream.ID] != -6 {
+		t.Errorf("stock adjusted by %d, want -6", f.stock[cream.ID])
+	}
+	if a.UnitsCleared != 6 || a.RecoveredValue != 720 {
+		t.Errorf("cleared %d recovered %v, want 6 and 720", a.UnitsCleared, a.RecoveredValue)
+	}
+}
+
+func TestClearanceService_Complete_DeactivatesPromoAndReportsSales(t *testing.T) {
+	cream := &models.Product{ID: uuid.New(), Name: "Cream", StockQuantity: 10, UnitPrice: 150}
+	f := newClearanceFixture(cream)
+	ctx := context.Background()
+	actorID := uuid.New()
+	a, err := f.svc.Create(ctx, f.pharmacyID, actorID, inbound.ClearanceInput{
+		Kind: models.ClearanceKindPromo, Name: "Clearance", DiscountPercent: 25, ProductIDs: []uuid.UUID{cream.ID}, Code: "clear25",
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	f.recovery = []*models.ClearanceRecoveryRow{{ActionID: a.ID, UnitsSold: 4, Revenue: 450.004}}
+
+	a, err = f.svc.Complete(ctx, f.pharmacyID, a.ID, actorID)
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if a.Status != models.ClearanceCompleted || a.ClosedBy == nil || *a.ClosedBy != actorID {
+		t.Errorf("action = %+v, want completed by the actor", a)
+	}
+	if promo := f.promos[*a.PromoCodeID]; promo.IsActive || promo.Code != "CLEAR25" {
+		t.Errorf("promo = %+v, want CLEAR25 deactivated", promo)
+	}
+	if a.UnitsCleared != 4 || a.RecoveredValue != 450 {
+		t.Errorf("cleared %d recovered %v, want 4 and 450", a.UnitsCleared, a.RecoveredValue)
+	}
+	if _, err := f.svc.Cancel(ctx, f.pharmacyID, a.ID, actorID); err == nil {
+		t.Error("Cancel after Complete succeeded, want an error")
+	}
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	consentHistoryLimit  = 50
+	consentExportMaxDays = 366
+)
+
+type consentService struct {
+	repo         outbound.ConsentRepository
+	customerRepo outbound.CustomerRepository
+	userRepo     outbound.UserRepository
+	referralSvc  inbound.ReferralPointsService
+	logger       *zap.Logger
+}
+
+// NewConsentService returns the service. referralSvc creates the customer record when a user without one
+// records consent from the app; without it such users get a validation error.
+func NewConsentService(repo outbound.ConsentRepository, customerRepo outbound.CustomerRepository, userRepo outbound.UserRepository, referralSvc inbound.ReferralPointsService, logger *zap.Logger) inbound.ConsentService {
+	return &consentService{repo: repo, customerRepo: customerRepo, userRepo: userRepo, referralSvc: referralSvc, logger: logger}
+}
+
+func (s *consentService) Record(ctx context.Context, pharmacyID, customerID, actorID uuid.UUID, in inbound.ConsentInput) (*models.ConsentRecord, error) {
+	c, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil || c == nil || c.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("customer")
+	}
+	source := in.Source
+	if source == "" {
+		source = models.ConsentSourceInStore
+	}
+	return s.record(ctx, c, &actorID, source, in)
+}
+
+func (s *consentService) RecordForUser(ctx context.Context, pharmacyID, userID uuid.UUID, in inbound.ConsentInput) (*models.ConsentRecord, error) {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || u == nil {
+		return nil, errors.ErrNotFound("user")
+	}
+	phone := strings.TrimSpace(u.Phone)
+	if phone == "" {
+		return nil, errors.ErrValidation("add a phone number to your profile to manage marketing preferences")
+	}
+	c, err := s.customerRepo.GetByPharmacyAndPhone(ctx, pharmacyID, phone)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load customer", err)
+	}
+	if c == nil {
+		if s.referralSvc == nil {
+			return nil, errors.ErrValidation("no customer record for this account yet")
+		}
+		if c, err = s.referralSvc.GetOrCreateCustomer(ctx, pharmacyID, u.Name, phone, u.Email); err != nil {
+			return nil, err
+		}
+	}
+	return s.record(ctx, c, &userID, models.ConsentSourceApp, in)
+}
+
+func (s *consentService) record(ctx context.Context, c *models.Customer, actorID *uuid.UUID, source string, in inbound.ConsentInput) (*models.ConsentRecord, error) {
+	if !validConsentChannel(in.Channel) {
+		return nil, errors.ErrValidation("channel must be sms, email, whatsapp or push")
+	}
+	if in.Granted == nil {
+		return nil, errors.ErrValidation("granted is required")
+	}
+	rec := &models.ConsentRecord{
+		PharmacyID: c.PharmacyID,
+		CustomerID: c.ID,
+		Channel:    in.Channel,
+		Granted:    *in.Granted,
+		Source:     source,
+		Note:       truncateRunes(strings.TrimSpace(in.Note), 500),
+		RecordedBy: actorID,
+		IPAddress:  truncateRunes(in.IPAddress, 64),
+	}
+	if err := s.repo.Create(ctx, rec); err != nil {
+		return nil, errors.ErrInternal("failed to record consent", err)
+	}
+	return rec, nil
+}
+
+func (s *consentService) Get(ctx context.Context, pharmacyID, customerID uuid.UUID) (*inbound.CustomerConsent, error) {
+	c, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil || c == nil || c.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("customer")
+	}
+	return s.consent(ctx, c.ID)
+}
+
+func (s *consentService) GetForUser(ctx context.Context, pharmacyID, userID uuid.UUID) (*inbound.CustomerConsent, error) {
+	if c := s.customerForUser(ctx, pharmacyID, userID); c != nil {
+		return s.consent(ctx, c.ID)
+	}
+	return &inbound.CustomerConsent{Channels: channelConsents(nil), History: []*models.ConsentRecord{}}, nil
+}
+
+func (s *consentService) consent(ctx context.Context, customerID uuid.UUID) (*inbound.CustomerConsent, error) {
+	current, err := s.repo.Current(ctx, customerID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load consent", err)
+	}
+	history, err := s.repo.History(ctx, customerID, consentHistoryLimit)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load consent history", err)
+	}
+	return &inbound.CustomerConsent{CustomerID: &customerID, Channels: channelConsents(current), History: history}, nil
+}
+
+// channelConsents lists every channel with its latest record; channels without one are opted out.
+func channelConsents(current []*models.ConsentRecord) []*inbound.ChannelConsent {
+	byChannel := make(map[models.ConsentChannel]*models.ConsentRecord, len(current))
+	for _, r := range current {
+		byChannel[r.Channel] = r
+	}
+	out := make([]*inbound.ChannelConsent, 0, len(models.ConsentChannels))
+	for _, ch := range models.ConsentChannels {
+		cc := &inbound.ChannelConsent{Channel: ch}
+		if r := byChannel[ch]; r != nil {
+			at := r.CreatedAt
+			cc.Granted, cc.Source, cc.UpdatedAt = r.Granted, r.Source, &at
+		}
+		out = append(out, cc)
+	}
+	return out
+}
+
+func (s *consentService) OptedIn(ctx context.Context, pharmacyID uuid.UUID, channel models.ConsentChannel, customerIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	ids, err := s.repo.OptedIn(ctx, pharmacyID, channel, customerIDs)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load consent", err)
+	}
+	out := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		out[id] = true
+	}
+	return out, nil
+}
+
+func (s *consentService) UserOptedIn(ctx context.Context, pharmacyID, userID uuid.UUID, channel models.ConsentChannel) bool {
+	c := s.customerForUser(ctx, pharmacyID, userID)
+	if c == nil {
+		return false
+	}
+	ok, err := s.OptedIn(ctx, pharmacyID, channel, []uuid.UUID{c.ID})
+	if err != nil {
+		s.logger.Warn("consent check failed", zap.String("user_id", userID.String()), zap.Error(err))
+		return false
+	}
+	return ok[c.ID]
+}
+
+func (s *consentService) Export(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.ConsentRecord, error) {
+	from, to, err := normalizeRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if to.Sub(from) > consentExportMaxDays*24*time.Hour {
+		return nil, errors.ErrValidation("export at most one year at a time")
+	}
+	list, err := s.repo.List(ctx, pharmacyID, from, to)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to export consent records", err)
+	}
+	return list, nil
+}
+
+// customerForUser finds the customer matching the user's phone at this pharmacy, if any.
+func (s *consentService) customerForUser(ctx context.Context, pharmacyID, userID uuid.UUID) *models.Customer {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || u == nil || strings.TrimSpace(u.Phone) == "" {
+		return nil
+	}
+	c, _ := s.customerRepo.GetByPharmacyAndPhone(ctx, pharmacyID, strings.TrimSpace(u.Phone))
+	return c
+}
+
+func validConsentChannel(ch models.ConsentChannel) bool {
+	for _, c := range models.ConsentChannels {
+		if c == ch {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestConsentService_Record_RejectsOtherPharmacysCustomer(t *testing.T) {
+	customers := &mocks.MockCustomerRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Customer, error) {
+			return &models.Customer{ID: id, PharmacyID: uuid.New()}, nil
+		},
+	}
+	repo := &mocks.MockConsentRepository{
+		CreateFunc: func(ctx context.Context, r *models.ConsentRecord) error {
+			t.Fatal("consent should not be recorded")
+			return nil
+		},
+	}
+	svc := NewConsentService(repo, customers, &mocks.MockUserRepository{}, nil, zap.NewNop())
+	granted := true
+
+	_, err := svc.Record(context.Background(), uuid.New(), uuid.New(), uuid.New(), inbound.ConsentInput{Channel: models.ConsentChannelSMS, Granted: &granted})
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeNotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
+
+func TestConsentService_Get_ListsEveryChannel(t *testing.T) {
+	pharmacyID, customerID := uuid.New(), uuid.New()
+	customers := &mocks.MockCustomerRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Customer, error) {
+			return &models.Customer{ID: id, PharmacyID: pharmacyID}, nil
+		},
+	}
+	repo := &mocks.MockConsentRepository{
+		CurrentFunc: func(ctx context.Context, id uuid.UUID) ([]*models.ConsentRecord, error) {
+			return []*models.ConsentRecord{{CustomerID: id, Channel: models.ConsentChannelEmail, Granted: true, Source: models.ConsentSourcePaperForm, CreatedAt: time.Now()}}, nil
+		},
+	}
+	svc := NewConsentService(repo, customers, &mocks.MockUserRepository{}, nil, zap.NewNop())
+
+	got, err := svc.Get(context.Background(), pharmacyID, customerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Channels) != len(models.ConsentChannels) {
+		t.Fatalf("channels = %d, want %d", len(got.Channels), len(models.ConsentChannels))
+	}
+	for _, ch := range got.Channels {
+		if want := ch.Channel == models.ConsentChannelEmail; ch.Granted != want {
+			t.Errorf("%s granted = %v, want %v", ch.Channel, ch.Granted, want)
+		}
+	}
+}
+
+func TestCampaignService_Deliver_SkipsWithoutConsent(t *testing.T) {
+	pharmacyID := uuid.New()
+	optedIn, optedOut := uuid.New(), uuid.New()
+	c := &models.Campaign{ID: uuid.New(), PharmacyID: pharmacyID, Channel: models.CampaignChannelSMS, Message: "Hi {name}"}
+	var recorded []*models.CampaignRecipient
+	campaigns := &mocks.MockCampaignRepository{
+		RecordRecipientsFunc: func(ctx context.Context, id uuid.UUID, rs []*models.CampaignRecipient) error {
+			recorded = append(recorded, rs...)
+			return nil
+		},
+	}
+	consents := &mocks.MockConsentRepository{
+		OptedInFunc: func(ctx context.Context, id uuid.UUID, channel models.ConsentChannel, ids []uuid.UUID) ([]uuid.UUID, error) {
+			if channel != models.ConsentChannelSMS {
+				t.Errorf("channel = %s, want sms", channel)
+			}
+			return []uuid.UUID{optedIn}, nil
+		},
+	}
+	sms := &captureSMS{}
+	svc := &campaignService{campaignRepo: campaigns, smsSender: sms, now: time.Now, logger: zap.NewNop(),
+		consentSvc: NewConsentService(consents, &mocks.MockCustomerRepository{}, &mocks.MockUserRepository{}, nil, zap.NewNop())}
+
+	svc.deliver(context.Background(), c, "CarePlus", []*models.SegmentMember{
+		{CustomerID: optedIn, Name: "Asha", Phone: "9800000001"},
+		{CustomerID: optedOut, Name: "Bikash", Phone: "9800000002"},
+	})
+
+	if len(sms.sent) != 1 || sms.sent[0].To != "9800000001" {
+		t.Fatalf("sent %+v, want only the opted-in customer", sms.sent)
+	}
+	if len(recorded) != 2 || recorded[1].Status != models.CampaignRecipientSkipped {
+		t.Fatalf("recorded %+v, want the opted-out customer skipped", recorded)
+	}
+}
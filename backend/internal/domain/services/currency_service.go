@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxExchangeRate bounds a rate so a typo (a missing decimal point) cannot show prices a million times too high.
+const maxExchangeRate = 100000
+
+type currencyService struct {
+	rateRepo   outbound.ExchangeRateRepository
+	configRepo outbound.PharmacyConfigRepository
+	logger     *zap.Logger
+}
+
+func NewCurrencyService(rateRepo outbound.ExchangeRateRepository, configRepo outbound.PharmacyConfigRepository, logger *zap.Logger) inbound.CurrencyService {
+	return &currencyService{rateRepo: rateRepo, configRepo: configRepo, logger: logger}
+}
+
+// baseCurrency is the pharmacy's base currency; models.DefaultCurrency without a config.
+func (s *currencyService) baseCurrency(ctx context.Context, pharmacyID uuid.UUID) (string, *models.PharmacyConfig) {
+	cfg, _ := s.configRepo.GetByPharmacyID(ctx, pharmacyID)
+	return cfg.Currency(), cfg
+}
+
+func (s *currencyService) ListRates(ctx context.Context, pharmacyID uuid.UUID) ([]*models.ExchangeRate, error) {
+	base, _ := s.baseCurrency(ctx, pharmacyID)
+	return s.currentRates(ctx, pharmacyID, base)
+}
+
+// currentRates lists the pharmacy's rates from base, leaving out those set for a former base currency.
+func (s *currencyService) currentRates(ctx context.Context, pharmacyID uuid.UUID, base string) ([]*models.ExchangeRate, error) {
+	list, err := s.rateRepo.ListByPharmacy(ctx, pharmacyID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list exchange rates", err)
+	}
+	rates := make([]*models.ExchangeRate, 0, len(list))
+	for _, r := range list {
+		if r.Base == base {
+			rates = append(rates, r)
+		}
+	}
+	return rates, nil
+}
+
+func (s *currencyService) SetRate(ctx context.Context, pharmacyID, userID uuid.UUID, currency string, rate float64) (*models.ExchangeRate, error) {
+	currency = models.NormalizeCurrency(currency)
+	if _, ok := models.Currencies[currency]; !ok {
+		return nil, errors.ErrValidation("unsupported currency " + currency)
+	}
+	if rate <= 0 || rate > maxExchangeRate {
+		return nil, errors.ErrValidation("rate must be greater than 0 and at most 100000")
+	}
+	base, _ := s.baseCurrency(ctx, pharmacyID)
+	if currency == base {
+		return nil, errors.ErrValidation("the base currency needs no exchange rate")
+	}
+	r, err := s.rateRepo.Get(ctx, pharmacyID, currency)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load exchange rate", err)
+	}
+	if r == nil {
+		r = &models.ExchangeRate{PharmacyID: pharmacyID, Currency: currency}
+	}
+	r.Base = base
+	r.Rate = rate
+	r.UpdatedBy = &userID
+	if err := s.rateRepo.Save(ctx, r); err != nil {
+		return nil, errors.ErrInternal("failed to save exchange rate", err)
+	}
+	return r, nil
+}
+
+func (s *currencyService) DeleteRate(ctx context.Context, pharmacyID uuid.UUID, currency string) error {
+	currency = models.NormalizeCurrency(currency)
+	r, err := s.rateRepo.Get(ctx, pharmacyID, currency)
+	if err != nil {
+		return errors.ErrInternal("failed to load exchange rate", err)
+	}
+	if r == nil {
+		return errors.ErrNotFound("exchange rate")
+	}
+	if err := s.rateRepo.Delete(ctx, pharmacyID, currency); err != nil {
+		return errors.ErrInternal("failed to delete exchange rate", err)
+	}
+	return nil
+}
+
+func (s *currencyService) Storefront(ctx context.Context, pharmacyID uuid.UUID) (*inbound.StorefrontCurrencies, error) {
+	base, cfg := s.baseCurrency(ctx, pharmacyID)
+	rates, err := s.currentRates(ctx, pharmacyID, base)
+	if err != nil {
+		return nil, err
+	}
+	out := &inbound.StorefrontCurrencies{Base: models.Currencies[base], Rates: rates}
+	if out.Base.Code == "" {
+		out.Base = models.Currency{Code: base, Name: base, Symbol: base, MinorUnits: 2}
+	}
+	// The display currency is only offered while it has a rate.
+	for _, r := range rates {
+		if cfg != nil && r.Currency == cfg.DisplayCurrency {
+			out.Display = r.Currency
+		}
+	}
+	return out, nil
+}
+
+func (s *currencyService) DisplayRate(ctx context.Context, pharmacyID uuid.UUID, requested string) (*models.ExchangeRate, error) {
+	base, cfg := s.baseCurrency(ctx, pharmacyID)
+	currency := models.NormalizeCurrency(requested)
+	if currency == "" && cfg != nil {
+		currency = cfg.DisplayCurrency
+	}
+	if currency == "" || currency == base {
+		return nil, nil
+	}
+	if _, ok := models.Currencies[currency]; !ok {
+		return nil, errors.ErrValidation("unsupported currency " + currency)
+	}
+	r, err := s.rateRepo.Get(ctx, pharmacyID, currency)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load exchange rate", err)
+	}
+	if r == nil || r.Base != base {
+		if requested == "" {
+			// A configured display currency without a current rate falls back to the base currency.
+			s.logger.Warn("display currency has no exchange rate", zap.String("pharmacy_id", pharmacyID.String()), zap.String("currency", currency))
+			return nil, nil
+		}
+		return nil, errors.ErrValidation("no exchange rate for " + currency)
+	}
+	return r, nil
+}
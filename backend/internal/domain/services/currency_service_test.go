@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// newCurrencyFixture keeps rates in memory for a pharmacy with the given config (nil = no config).
+func newCurrencyFixture(cfg *models.PharmacyConfig) (*currencyService, map[string]*models.ExchangeRate) {
+	rates := map[string]*models.ExchangeRate{}
+	rateRepo := &mocks.MockExchangeRateRepository{
+		ListByPharmacyFunc: func(ctx context.Context, pharmacyID uuid.UUID) ([]*models.ExchangeRate, error) {
+			list := []*models.ExchangeRate{}
+			for _, r := range rates {
+				list = append(list, r)
+			}
+			return list, nil
+		},
+		GetFunc: func(ctx context.Context, pharmacyID uuid.UUID, currency string) (*models.ExchangeRate, error) {
+			return rates[currency], nil
+		},
+		SaveFunc: func(ctx context.Context, r *models.ExchangeRate) error {
+			rates[r.Currency] = r
+			return nil
+		},
+		DeleteFunc: func(ctx context.Context, pharmacyID uuid.UUID, currency string) error {
+			delete(rates, currency)
+			return nil
+		},
+	}
+	configRepo := &mocks.MockPharmacyConfigRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, pharmacyID uuid.UUID) (*models.PharmacyConfig, error) {
+			return cfg, nil
+		},
+	}
+	return NewCurrencyService(rateRepo, configRepo, zap.NewNop()).(*currencyService), rates
+}
+
+func TestCurrencyService_SetRate(t *testing.T) {
+	ctx := context.Background()
+	pharmacyID, userID := uuid.New(), uuid.New()
+	svc, rates := newCurrencyFixture(nil)
+
+	r, err := svc.SetRate(ctx, pharmacyID, userID, " usd ", 0.0075)
+	if err != nil {
+		t.Fatalf("SetRate: %v", err)
+	}
+	if r.Base != models.DefaultCurrency || r.Currency != "USD" || r.Rate != 0.0075 || r.UpdatedBy == nil || *r.UpdatedBy != userID {
+		t.Errorf("rate = %+v", r)
+	}
+	if _, err := svc.SetRate(ctx, pharmacyID, userID, "USD", 0.008); err != nil || len(rates) != 1 || rates["USD"].Rate != 0.008 {
+		t.Errorf("updating USD: err = %v, rates = %v", err, rates)
+	}
+	for _, tc := range []struct {
+		currency string
+		rate     float64
+	}{{"XYZ", 1}, {"NPR", 1}, {"EUR", 0}, {"EUR", -1}, {"EUR", 1e6}} {
+		if _, err := svc.SetRate(ctx, pharmacyID, userID, tc.currency, tc.rate); appCode(err) != errors.ErrCodeValidation {
+			t.Errorf("SetRate(%s, %v): err = %v, want validation", tc.currency, tc.rate, err)
+		}
+	}
+
+	if err := svc.DeleteRate(ctx, pharmacyID, "usd"); err != nil || len(rates) != 0 {
+		t.Errorf("DeleteRate: err = %v, rates = %v", err, rates)
+	}
+	if err := svc.DeleteRate(ctx, pharmacyID, "USD"); appCode(err) != errors.ErrCodeNotFound {
+		t.Errorf("DeleteRate twice: err = %v, want not found", err)
+	}
+}
+
+func TestCurrencyService_DisplayRate(t *testing.T) {
+	ctx := context.Background()
+	pharmacyID := uuid.New()
+	cfg := &models.PharmacyConfig{BaseCurrency: "NPR", DisplayCurrency: "USD"}
+	svc, rates := newCurrencyFixture(cfg)
+
+	// A configured display currency without a rate shows base prices only.
+	if r, err := svc.DisplayRate(ctx, pharmacyID, ""); r != nil || err != nil {
+		t.Errorf("no USD rate: rate = %v, err = %v", r, err)
+	}
+	rates["USD"] = &models.ExchangeRate{PharmacyID: pharmacyID, Base: "NPR", Currency: "USD", Rate: 0.0075}
+	rates["INR"] = &models.ExchangeRate{PharmacyID: pharmacyID, Base: "NPR", Currency: "INR", Rate: 0.625}
+	r, err := svc.DisplayRate(ctx, pharmacyID, "")
+	if err != nil || r == nil || r.Currency != "USD" {
+		t.Fatalf("display USD: rate = %v, err = %v", r, err)
+	}
+	if got := r.Convert(1999); got != 14.99 {
+		t.Errorf("1999 NPR = %v USD, want 14.99", got)
+	}
+	if r, err := svc.DisplayRate(ctx, pharmacyID, "inr"); err != nil || r == nil || r.Currency != "INR" {
+		t.Errorf("?currency=inr: rate = %v, err = %v", r, err)
+	}
+	if r, err := svc.DisplayRate(ctx, pharmacyID, "NPR"); r != nil || err != nil {
+		t.Errorf("?currency=NPR (base): rate = %v, err = %v", r, err)
+	}
+	if _, err := svc.DisplayRate(ctx, pharmacyID, "EUR"); appCode(err) != errors.ErrCodeValidation {
+		t.Errorf("?currency=EUR without a rate: err = %v, want validation", err)
+	}
+
+	// Once the base currency changes, rates from the former base no longer apply.
+	cfg.BaseCurrency = "INR"
+	if _, err := svc.DisplayRate(ctx, pharmacyID, "USD"); appCode(err) != errors.ErrCodeValidation {
+		t.Errorf("stale USD rate: err = %v, want validation", err)
+	}
+	list, err := svc.ListRates(ctx, pharmacyID)
+	if err != nil || len(list) != 0 {
+		t.Errorf("ListRates after base change = %v, %v; want none", list, err)
+	}
+	front, err := svc.Storefront(ctx, pharmacyID)
+	if err != nil || front.Base.Code != "INR" || front.Display != "" || len(front.Rates) != 0 {
+		t.Errorf("Storefront after base change = %+v, %v", front, err)
+	}
+}
+
+func TestPharmacyConfigService_Validate_Currencies(t *testing.T) {
+	current := &models.PharmacyConfig{BaseCurrency: "NPR"}
+	cases := []struct {
+		name            string
+		base, display   string
+		field           string
+		wantBaseWarning bool
+	}{
+		{"supported", "npr", "usd", "", false},
+		{"unsupported base", "XYZ", "", "base_currency", false},
+		{"unsupported display", "", "ABC", "display_currency", false},
+		{"display is base", "", "NPR", "display_currency", false},
+		{"display is new base", "INR", "inr", "display_currency", true},
+		{"base change", "INR", "", "", true},
+	}
+	for _, tc := range cases {
+		result := validatePharmacyConfig(&models.PharmacyConfig{BaseCurrency: tc.base, DisplayCurrency: tc.display, WebsiteEnabled: true}, current)
+		for _, field := range []string{"base_currency", "display_currency"} {
+			if _, got := result.Errors[field]; got != (field == tc.field) {
+				t.Errorf("%s: errors = %v", tc.name, result.Errors)
+			}
+		}
+		warned := false
+		for _, w := range result.Warnings {
+			if strings.HasPrefix(w, "the base currency") {
+				warned = true
+			}
+		}
+		if warned != tc.wantBaseWarning {
+			t.Errorf("%s: warnings = %v", tc.name, result.Warnings)
+		}
+	}
+}
+
+func TestProductService_Update_KeepsStoredCurrency(t *testing.T) {
+	ctx := context.Background()
+	pharmacyID := uuid.New()
+	var existing, saved *models.Product
+	repo := &mocks.MockProductRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) { return existing, nil },
+		UpdateFunc: func(ctx context.Context, p *models.Product) error {
+			saved = p
+			return nil
+		},
+	}
+	configRepo := &mocks.MockPharmacyConfigRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, id uuid.UUID) (*models.PharmacyConfig, error) {
+			return &models.PharmacyConfig{PharmacyID: pharmacyID, BaseCurrency: "USD"}, nil
+		},
+	}
+	svc := NewProductService(repo, &mocks.MockProductImageRepository{}, configRepo, nil, zap.NewNop())
+
+	// Priced before the base currency changed from NPR: an edit must not relabel the unconverted price.
+	existing = &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, UnitPrice: 500, Currency: "NPR"}
+	if err := svc.Update(ctx, &models.Product{ID: existing.ID, PharmacyID: pharmacyID, Name: "Cetamol", UnitPrice: 500}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if saved.Currency != "NPR" {
+		t.Errorf("currency = %q, want the stored NPR", saved.Currency)
+	}
+
+	existing = &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, UnitPrice: 5}
+	if err := svc.Update(ctx, &models.Product{ID: existing.ID, PharmacyID: pharmacyID, Name: "Cetamol", UnitPrice: 5}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if saved.Currency != "USD" {
+		t.Errorf("currency without a stored one = %q, want the base USD", saved.Currency)
+	}
+}
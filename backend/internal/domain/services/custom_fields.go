@@ -0,0 +1,112 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+)
+
+const (
+	maxCustomFieldOptions   = 50
+	maxCustomFieldTextRunes = 500
+)
+
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// validateCustomFieldSpec checks one tenant field definition; kind names it in messages ("order field").
+func validateCustomFieldSpec(kind, key, label, typ string, options []string) error {
+	if !customFieldKeyPattern.MatchString(key) {
+		return errors.ErrValidation(kind + " key " + strconv.Quote(key) + " must be lower_snake_case, up to 40 characters")
+	}
+	if l := strings.TrimSpace(label); l == "" || len([]rune(l)) > 100 {
+		return errors.ErrValidation(kind + " " + key + " needs a label of up to 100 characters")
+	}
+	switch typ {
+	case models.CustomFieldText, models.CustomFieldNumber, models.CustomFieldDate, models.CustomFieldBoolean:
+		if len(options) > 0 {
+			return errors.ErrValidation("only select " + kind + "s take options")
+		}
+	case models.CustomFieldSelect:
+		if len(options) == 0 || len(options) > maxCustomFieldOptions {
+			return errors.ErrValidation(fmt.Sprintf("select %s %s needs 1 to %d options", kind, key, maxCustomFieldOptions))
+		}
+		seen := map[string]bool{}
+		for _, o := range options {
+			if strings.TrimSpace(o) == "" || seen[o] {
+				return errors.ErrValidation("select " + kind + " " + key + " has an empty or repeated option")
+			}
+			seen[o] = true
+		}
+	default:
+		return errors.ErrValidation(kind + " " + key + " has unknown type " + strconv.Quote(typ))
+	}
+	return nil
+}
+
+// customFieldValue converts one submitted value to its stored type; nil means the field was left empty.
+func customFieldValue(label, typ string, options []string, raw any) (any, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	invalid := errors.ErrValidation(label + " must be a valid " + typ)
+	switch typ {
+	case models.CustomFieldNumber:
+		switch v := raw.(type) {
+		case float64:
+			return v, nil
+		case json.Number:
+			f, err := v.Float64()
+			if err != nil {
+				return nil, invalid
+			}
+			return f, nil
+		case string:
+			if strings.TrimSpace(v) == "" {
+				return nil, nil
+			}
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, invalid
+			}
+			return f, nil
+		}
+		return nil, invalid
+	case models.CustomFieldBoolean:
+		if v, ok := raw.(bool); ok {
+			return v, nil
+		}
+		return nil, invalid
+	}
+	s, ok := raw.(string)
+	if !ok {
+		return nil, invalid
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	switch typ {
+	case models.CustomFieldDate:
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return nil, errors.ErrValidation(label + " must be a date (YYYY-MM-DD)")
+		}
+	case models.CustomFieldSelect:
+		for _, o := range options {
+			if o == s {
+				return s, nil
+			}
+		}
+		return nil, errors.ErrValidation(label + " must be one of: " + strings.Join(options, ", "))
+	default:
+		if len([]rune(s)) > maxCustomFieldTextRunes {
+			return nil, errors.ErrValidation(fmt.Sprintf("%s must be at most %d characters", label, maxCustomFieldTextRunes))
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	apperr "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxDeliveryTimeout bounds one delivery attempt; it is also kept below the lease so a slow attempt is not
+// handed to a second worker while still running.
+const maxDeliveryTimeout = 30 * time.Second
+
+// DeliveryQueueOptions tunes the delivery queue (see config.QueueConfig).
+type DeliveryQueueOptions struct {
+	Workers      int
+	PollInterval time.Duration
+	MaxAttempts  int
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+	Lease        time.Duration
+}
+
+type deliveryQueueService struct {
+	repo    outbound.DeliveryJobRepository
+	email   outbound.EmailSender
+	sms     outbound.SMSSender
+	webhook outbound.WebhookSender
+	opts    DeliveryQueueOptions
+	now     func() time.Time
+	wake    chan struct{}
+	stop    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+	logger  *zap.Logger
+}
+
+// NewDeliveryQueueService stores deliveries in repo and sends them through the given transports. A nil
+// transport (e.g. SMS_PROVIDER=none) turns jobs of that kind into dead letters on their first attempt.
+func NewDeliveryQueueService(
+	repo outbound.DeliveryJobRepository,
+	email outbound.EmailSender,
+	sms outbound.SMSSender,
+	webhook outbound.WebhookSender,
+	opts DeliveryQueueOptions,
+	logger *zap.Logger,
+) inbound.DeliveryQueueService {
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 1
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = 2 * time.Minute
+	}
+	return &deliveryQueueService{
+		repo:    repo,
+		email:   email,
+		sms:     sms,
+		webhook: webhook,
+		opts:    opts,
+		now:     time.Now,
+		wake:    make(chan struct{}, opts.Workers),
+		stop:    make(chan struct{}),
+		logger:  logger,
+	}
+}
+
+func (s *deliveryQueueService) EnqueueEmail(ctx context.Context, msg *outbound.EmailMessage) error {
+	return s.enqueue(ctx, models.DeliveryJobKindEmail, msg.PharmacyID, strings.Join(msg.To, ","), msg.Subject, msg)
+}
+
+func (s *deliveryQueueService) EnqueueSMS(ctx context.Context, msg *outbound.SMSMessage) error {
+	return s.enqueue(ctx, models.DeliveryJobKindSMS, msg.PharmacyID, msg.To, "", msg)
+}
+
+func (s *deliveryQueueService) EnqueueWebhook(ctx context.Context, msg *outbound.WebhookMessage) error {
+	if msg.ID == uuid.Nil {
+		msg.ID = uuid.New()
+	}
+	return s.enqueue(ctx, models.DeliveryJobKindWebhook, msg.PharmacyID, msg.URL, msg.Event, msg)
+}
+
+func (s *deliveryQueueService) enqueue(ctx context.Context, kind string, pharmacyID uuid.UUID, target, summary string, msg interface{}) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encode %s job: %w", kind, err)
+	}
+	job := &models.DeliveryJob{
+		PharmacyID:    pharmacyID,
+		Kind:          kind,
+		Target:        truncateRunes(target, 500),
+		Summary:       truncateRunes(summary, 255),
+		Payload:       string(payload),
+		Status:        models.DeliveryJobStatusPending,
+		MaxAttempts:   s.opts.MaxAttempts,
+		NextAttemptAt: s.now(),
+	}
+	if err := s.repo.Create(ctx, job); err != nil {
+		s.logger.Error("enqueue delivery failed", zap.String("kind", kind), zap.Error(err))
+		return err
+	}
+	s.signal()
+	return nil
+}
+
+// signal wakes an idle worker without blocking; busy workers pick the job up on their next claim.
+func (s *deliveryQueueService) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *deliveryQueueService) ProcessDue(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		n, err := s.processBatch(ctx, s.opts.Workers)
+		total += n
+		if err != nil || n == 0 {
+			return total, err
+		}
+	}
+}
+
+func (s *deliveryQueueService) processBatch(ctx context.Context, limit int) (int, error) {
+	jobs, err := s.repo.ClaimDue(ctx, s.now(), s.opts.Lease, limit)
+	if err != nil {
+		return 0, err
+	}
+	for _, job := range jobs {
+		s.attempt(ctx, job)
+	}
+	return len(jobs), nil
+}
+
+// attempt delivers one claimed job: delivered jobs are deleted, failed ones rescheduled or dead-lettered.
+func (s *deliveryQueueService) attempt(ctx context.Context, job *models.DeliveryJob) {
+	timeout := maxDeliveryTimeout
+	if half := s.opts.Lease / 2; half < timeout {
+		timeout = half
+	}
+	// Detached from the caller's context so shutdown does not cut an attempt short.
+	sendCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	err := s.deliver(sendCtx, job)
+	cancel()
+	if err == nil {
+		if err := s.repo.Delete(ctx, job.ID); err != nil {
+			s.logger.Warn("delete delivered job failed", zap.String("job_id", job.ID.String()), zap.Error(err))
+		}
+		return
+	}
+	job.Attempts++
+	job.LastError = truncateRunes(err.Error(), 1000)
+	job.LockedUntil = nil
+	var permanent *permanentDeliveryError
+	if errors.As(err, &permanent) || job.Attempts >= job.MaxAttempts {
+		job.Status = models.DeliveryJobStatusDead
+		s.logger.Warn("delivery gave up", zap.String("job_id", job.ID.String()), zap.String("kind", job.Kind),
+			zap.String("target", job.Target), zap.Int("attempts", job.Attempts), zap.Error(err))
+	} else {
+		job.Status = models.DeliveryJobStatusPending
+		job.NextAttemptAt = s.now().Add(s.backoff(job.Attempts))
+		s.logger.Info("delivery failed, will retry", zap.String("job_id", job.ID.String()), zap.String("kind", job.Kind),
+			zap.Int("attempts", job.Attempts), zap.Time("next_attempt_at", job.NextAttemptAt), zap.Error(err))
+	}
+	if err := s.repo.Update(ctx, job); err != nil {
+		s.logger.Error("update delivery job failed", zap.String("job_id", job.ID.String()), zap.Error(err))
+	}
+}
+
+// backoff returns BackoffBase doubled for every attempt after the first, capped at BackoffMax.
+func (s *deliveryQueueService) backoff(attempts int) time.Duration {
+	return retryBackoff(s.opts.BackoffBase, s.opts.BackoffMax, attempts)
+}
+
+// retryBackoff returns base doubled for every attempt after the first, capped at maxDelay when it is set.
+func retryBackoff(base, maxDelay time.Duration, attempts int) time.Duration {
+	d := base
+	for i := 1; i < attempts && d < maxDelay; i++ {
+		d *= 2
+	}
+	if maxDelay > 0 && d > maxDelay {
+		d = maxDelay
+	}
+	return d
+}
+
+// permanentDeliveryError marks failures that retrying cannot fix (undecodable payload, no transport).
+type permanentDeliveryError struct{ msg string }
+
+func (e *permanentDeliveryError) Error() string { return e.msg }
+
+func (s *deliveryQueueService) deliver(ctx context.Context, job *models.DeliveryJob) error {
+	switch job.Kind {
+	case models.DeliveryJobKindEmail:
+		var msg outbound.EmailMessage
+		if err := json.Unmarshal([]byte(job.Payload), &msg); err != nil {
+			return &permanentDeliveryError{"invalid email payload: " + err.Error()}
+		}
+		if s.email == nil {
+			return &permanentDeliveryError{"no email transport configured"}
+		}
+		return s.email.Send(ctx, &msg)
+	case models.DeliveryJobKindSMS:
+		var msg outbound.SMSMessage
+		if err := json.Unmarshal([]byte(job.Payload), &msg); err != nil {
+			return &permanentDeliveryError{"invalid sms payload: " + err.Error()}
+		}
+		if s.sms == nil {
+			return &permanentDeliveryError{"no sms transport configured"}
+		}
+		return s.sms.Send(ctx, &msg)
+	case models.DeliveryJobKindWebhook:
+		var msg outbound.WebhookMessage
+		if err := json.Unmarshal([]byte(job.Payload), &msg); err != nil {
+			return &permanentDeliveryError{"invalid webhook payload: " + err.Error()}
+		}
+		if s.webhook == nil {
+			return &permanentDeliveryError{"no webhook transport configured"}
+		}
+		return s.webhook.Send(ctx, &msg)
+	}
+	return &permanentDeliveryError{"unknown job kind " + job.Kind}
+}
+
+func (s *deliveryQueueService) ListDeadLetters(ctx context.Context, pharmacyID uuid.UUID, kind string, limit, offset int) ([]*models.DeliveryJob, int64, error) {
+	switch kind {
+	case "", models.DeliveryJobKindEmail, models.DeliveryJobKindSMS, models.DeliveryJobKindWebhook:
+	default:
+		return nil, 0, apperr.ErrValidation("kind must be email, sms or webhook")
+	}
+	list, total, err := s.repo.ListDead(ctx, pharmacyID, kind, limit, offset)
+	if err != nil {
+		return nil, 0, apperr.ErrInternal("failed to list dead letters", err)
+	}
+	if list == nil {
+		list = []*models.DeliveryJob{}
+	}
+	return list, total, nil
+}
+
+func (s *deliveryQueueService) Retry(ctx context.Context, pharmacyID, jobID uuid.UUID) (*models.DeliveryJob, error) {
+	job, err := s.repo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, apperr.ErrInternal("failed to load delivery job", err)
+	}
+	if job == nil || job.PharmacyID != pharmacyID {
+		return nil, apperr.ErrNotFound("delivery job")
+	}
+	if job.Status != models.DeliveryJobStatusDead {
+		return nil, apperr.ErrConflict("only dead deliveries can be retried")
+	}
+	job.Status = models.DeliveryJobStatusPending
+	job.Attempts = 0
+	job.MaxAttempts = s.opts.MaxAttempts
+	job.NextAttemptAt = s.now()
+	job.LockedUntil = nil
+	if err := s.repo.Update(ctx, job); err != nil {
+		return nil, apperr.ErrInternal("failed to update delivery job", err)
+	}
+	s.signal()
+	return job, nil
+}
+
+func (s *deliveryQueueService) Start() {
+	for i := 0; i < s.opts.Workers; i++ {
+		s.wg.Add(1)
+		go s.work()
+	}
+}
+
+// work claims one job at a time; when the queue is empty it sleeps until a new job, the poll interval or stop.
+func (s *deliveryQueueService) work() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		n, err := s.processBatch(context.Background(), 1)
+		if err != nil {
+			s.logger.Warn("claim delivery jobs failed", zap.Error(err))
+		}
+		if n > 0 && err == nil {
+			select {
+			case <-s.stop:
+				return
+			default:
+				continue
+			}
+		}
+		select {
+		case <-s.stop:
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *deliveryQueueService) Close(ctx context.Context) error {
+	s.once.Do(func() { close(s.stop) })
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// memoryDeliveryJobRepo mirrors the DB queue: ClaimDue takes pending jobs that are due and expired leases.
+// Create, GetByID and ListDead fail with err when it is set.
+type memoryDeliveryJobRepo struct {
+	jobs map[uuid.UUID]*models.DeliveryJob
+	err  error
+}
+
+func newMemoryDeliveryJobRepo() *memoryDeliveryJobRepo {
+	return &memoryDeliveryJobRepo{jobs: make(map[uuid.UUID]*models.DeliveryJob)}
+}
+
+func (r *memoryDeliveryJobRepo) Create(ctx context.Context, j *models.DeliveryJob) error {
+	if r.err != nil {
+		return r.err
+	}
+	j.ID = uuid.New()
+	r.jobs[j.ID] = j
+	return nil
+}
+
+func (r *memoryDeliveryJobRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.DeliveryJob, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.jobs[id], nil
+}
+
+func (r *memoryDeliveryJobRepo) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.DeliveryJob, error) {
+	var out []*models.DeliveryJob
+	for _, j := range r.jobs {
+		due := j.Status == models.DeliveryJobStatusPending && !j.NextAttemptAt.After(now)
+		stale := j.Status == models.DeliveryJobStatusRunning && j.LockedUntil != nil && j.LockedUntil.Before(now)
+		if (due || stale) && len(out) < limit {
+			until := now.Add(lease)
+			j.Status, j.LockedUntil = models.DeliveryJobStatusRunning, &until
+			out = append(out, j)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryDeliveryJobRepo) Update(ctx context.Context, j *models.DeliveryJob) error {
+	r.jobs[j.ID] = j
+	return nil
+}
+
+func (r *memoryDeliveryJobRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(r.jobs, id)
+	return nil
+}
+
+func (r *memoryDeliveryJobRepo) ListDead(ctx context.Context, pharmacyID uuid.UUID, kind string, limit, offset int) ([]*models.DeliveryJob, int64, error) {
+	if r.err != nil {
+		return nil, 0, r.err
+	}
+	var out []*models.DeliveryJob
+	for _, j := range r.jobs {
+		if j.PharmacyID == pharmacyID && j.Status == models.DeliveryJobStatusDead && (kind == "" || j.Kind == kind) {
+			out = append(out, j)
+		}
+	}
+	return out, int64(len(out)), nil
+}
+
+// flakyEmailSender fails the first fails sends.
+type flakyEmailSender struct {
+	fails int
+	sent  []*outbound.EmailMessage
+}
+
+func (f *flakyEmailSender) Send(ctx context.Context, msg *outbound.EmailMessage) error {
+	if f.fails > 0 {
+		f.fails--
+		return errors.New("smtp: 421 try again later")
+	}
+	f.sent = append(f.sent, msg)
+	return nil
+}
+
+func newTestDeliveryQueue(repo *memoryDeliveryJobRepo, email outbound.EmailSender, now *time.Time) *deliveryQueueService {
+	svc := NewDeliveryQueueService(repo, email, nil, nil, DeliveryQueueOptions{
+		Workers: 2, MaxAttempts: 3, BackoffBase: time.Minute, BackoffMax: 90 * time.Second, Lease: time.Minute,
+	}, zap.NewNop()).(*deliveryQueueService)
+	svc.now = func() time.Time { return *now }
+	return svc
+}
+
+func TestDeliveryQueueService_ProcessDue_DeliversAndDeletes(t *testing.T) {
+	repo := newMemoryDeliveryJobRepo()
+	sender := &flakyEmailSender{}
+	now := time.Now()
+	svc := newTestDeliveryQueue(repo, sender, &now)
+	pharmacyID := uuid.New()
+
+	if err := svc.EnqueueEmail(context.Background(), &outbound.EmailMessage{PharmacyID: pharmacyID, To: []string{"a@example.com"}, Subject: "Order ready", TextBody: "hi"}); err != nil {
+		t.Fatalf("EnqueueEmail: %v", err)
+	}
+	if len(repo.jobs) != 1 || len(sender.sent) != 0 {
+		t.Fatalf("expected a stored job and nothing sent yet, got %d jobs, %d sent", len(repo.jobs), len(sender.sent))
+	}
+	for _, j := range repo.jobs {
+		if j.Target != "a@example.com" || j.Summary != "Order ready" || j.PharmacyID != pharmacyID {
+			t.Errorf("unexpected job description: %+v", j)
+		}
+	}
+	n, err := svc.ProcessDue(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("ProcessDue = %d, %v", n, err)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].Subject != "Order ready" || sender.sent[0].To[0] != "a@example.com" {
+		t.Errorf("expected the email to be delivered, got %+v", sender.sent)
+	}
+	if len(repo.jobs) != 0 {
+		t.Errorf("expected delivered job to be deleted, %d left", len(repo.jobs))
+	}
+}
+
+func TestDeliveryQueueService_ProcessDue_BacksOffThenDeadLetters(t *testing.T) {
+	repo := newMemoryDeliveryJobRepo()
+	sender := &flakyEmailSender{fails: 10}
+	now := time.Now()
+	svc := newTestDeliveryQueue(repo, sender, &now)
+	pharmacyID := uuid.New()
+	_ = svc.EnqueueEmail(context.Background(), &outbound.EmailMessage{PharmacyID: pharmacyID, To: []string{"a@example.com"}, Subject: "x"})
+	var job *models.DeliveryJob
+	for _, j := range repo.jobs {
+		job = j
+	}
+
+	_, _ = svc.ProcessDue(context.Background())
+	if job.Status != models.DeliveryJobStatusPending || job.Attempts != 1 || !job.NextAttemptAt.Equal(now.Add(time.Minute)) || job.LastError == "" {
+		t.Fatalf("expected retry after 1m, got %+v", job)
+	}
+	// Not due yet.
+	if n, _ := svc.ProcessDue(context.Background()); n != 0 {
+		t.Errorf("expected no attempt before the backoff, got %d", n)
+	}
+	now = now.Add(time.Minute)
+	_, _ = svc.ProcessDue(context.Background())
+	// 2m doubled from 1m, capped at 90s.
+	if job.Attempts != 2 || !job.NextAttemptAt.Equal(now.Add(90*time.Second)) {
+		t.Fatalf("expected capped backoff, got attempts=%d next=%v", job.Attempts, job.NextAttemptAt.Sub(now))
+	}
+	now = now.Add(90 * time.Second)
+	_, _ = svc.ProcessDue(context.Background())
+	if job.Status != models.DeliveryJobStatusDead || job.Attempts != 3 {
+		t.Fatalf("expected dead letter after max attempts, got %+v", job)
+	}
+
+	dead, total, err := svc.ListDeadLetters(context.Background(), pharmacyID, "", 20, 0)
+	if err != nil || total != 1 || dead[0].ID != job.ID {
+		t.Fatalf("expected the job in dead letters, got %v %d %v", dead, total, err)
+	}
+	if _, total, _ := svc.ListDeadLetters(context.Background(), uuid.New(), "", 20, 0); total != 0 {
+		t.Errorf("dead letters leaked to another pharmacy")
+	}
+
+	sender.fails = 0
+	if _, err := svc.Retry(context.Background(), uuid.New(), job.ID); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeNotFound {
+		t.Errorf("expected not found for another pharmacy, got %v", err)
+	}
+	retried, err := svc.Retry(context.Background(), pharmacyID, job.ID)
+	if err != nil || retried.Status != models.DeliveryJobStatusPending || retried.Attempts != 0 {
+		t.Fatalf("Retry: %+v %v", retried, err)
+	}
+	if _, err := svc.Retry(context.Background(), pharmacyID, job.ID); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeConflict {
+		t.Errorf("expected conflict retrying a pending job, got %v", err)
+	}
+	_, _ = svc.ProcessDue(context.Background())
+	if len(sender.sent) != 1 || len(repo.jobs) != 0 {
+		t.Errorf("expected delivery after manual retry, sent=%d jobs=%d", len(sender.sent), len(repo.jobs))
+	}
+}
+
+func TestDeliveryQueueService_ProcessDue_MissingTransportIsPermanent(t *testing.T) {
+	repo := newMemoryDeliveryJobRepo()
+	now := time.Now()
+	svc := newTestDeliveryQueue(repo, &flakyEmailSender{}, &now)
+	_ = svc.EnqueueSMS(context.Background(), &outbound.SMSMessage{To: "+9779800000001", Body: "code 123456"})
+	_, _ = svc.ProcessDue(context.Background())
+	for _, j := range repo.jobs {
+		if j.Status != models.DeliveryJobStatusDead || j.Attempts != 1 {
+			t.Errorf("expected dead letter on first attempt without an SMS transport, got %+v", j)
+		}
+	}
+}
+
+func TestDeliveryQueueService_ProcessDue_ReclaimsExpiredLease(t *testing.T) {
+	repo := newMemoryDeliveryJobRepo()
+	sender := &flakyEmailSender{}
+	now := time.Now()
+	svc := newTestDeliveryQueue(repo, sender, &now)
+	_ = svc.EnqueueEmail(context.Background(), &outbound.EmailMessage{To: []string{"a@example.com"}})
+	// A worker on another instance claimed the job and died.
+	_, _ = repo.ClaimDue(context.Background(), now, time.Minute, 10)
+	if n, _ := svc.ProcessDue(context.Background()); n != 0 {
+		t.Fatalf("expected the leased job to be skipped, got %d", n)
+	}
+	now = now.Add(2 * time.Minute)
+	if n, _ := svc.ProcessDue(context.Background()); n != 1 || len(sender.sent) != 1 {
+		t.Errorf("expected the job to be reclaimed after the lease, got n=%d sent=%d", n, len(sender.sent))
+	}
+}
+
+func TestDeliveryQueueService_ProcessDue_UndeliverableJobsArePermanent(t *testing.T) {
+	repo := newMemoryDeliveryJobRepo()
+	now := time.Now()
+	svc := newTestDeliveryQueue(repo, &flakyEmailSender{}, &now)
+	for _, j := range []*models.DeliveryJob{
+		{Kind: models.DeliveryJobKindEmail, Payload: "{not json"},
+		{Kind: models.DeliveryJobKindWebhook, Payload: `{"url":"https://example.com/hook"}`}, // no webhook transport
+		{Kind: "fax", Payload: "{}"},
+	} {
+		j.Status, j.MaxAttempts, j.NextAttemptAt = models.DeliveryJobStatusPending, 3, now
+		_ = repo.Create(context.Background(), j)
+	}
+	if n, err := svc.ProcessDue(context.Background()); n != 3 || err != nil {
+		t.Fatalf("ProcessDue = %d, %v", n, err)
+	}
+	for _, j := range repo.jobs {
+		if j.Status != models.DeliveryJobStatusDead || j.Attempts != 1 || j.LastError == "" {
+			t.Errorf("%s job = %+v, want a dead letter with the reason after one attempt", j.Kind, j)
+		}
+	}
+}
+
+func TestDeliveryQueueService_Enqueue_ReturnsStoreError(t *testing.T) {
+	repo := newMemoryDeliveryJobRepo()
+	repo.err = errors.New("connection reset")
+	now := time.Now()
+	svc := newTestDeliveryQueue(repo, &flakyEmailSender{}, &now)
+	if err := svc.EnqueueWebhook(context.Background(), &outbound.WebhookMessage{URL: "https://example.com/hook"}); !errors.Is(err, repo.err) {
+		t.Errorf("err = %v, want the store error so the caller knows nothing was queued", err)
+	}
+}
+
+func TestDeliveryQueueService_ListDeadLetters(t *testing.T) {
+	repo := newMemoryDeliveryJobRepo()
+	now := time.Now()
+	svc := newTestDeliveryQueue(repo, &flakyEmailSender{}, &now)
+	ctx := context.Background()
+
+	if _, _, err := svc.ListDeadLetters(ctx, uuid.New(), "fax", 20, 0); appCode(err) != pkgerrors.ErrCodeValidation {
+		t.Errorf("unknown kind: err = %v, want validation error", err)
+	}
+	list, total, err := svc.ListDeadLetters(ctx, uuid.New(), "", 20, 0)
+	if err != nil || list == nil || total != 0 {
+		t.Errorf("no dead letters = %v, %d, %v; want an empty list", list, total, err)
+	}
+	repo.err = errors.New("connection reset")
+	if _, _, err := svc.ListDeadLetters(ctx, uuid.New(), models.DeliveryJobKindSMS, 20, 0); appCode(err) != pkgerrors.ErrCodeInternal {
+		t.Errorf("store failure: err = %v, want internal error", err)
+	}
+}
+
+func TestDeliveryQueueService_Retry(t *testing.T) {
+	repo := newMemoryDeliveryJobRepo()
+	now := time.Now()
+	svc := newTestDeliveryQueue(repo, &flakyEmailSender{}, &now)
+	ctx, pharmacyID := context.Background(), uuid.New()
+	dead := &models.DeliveryJob{PharmacyID: pharmacyID, Kind: models.DeliveryJobKindSMS, Status: models.DeliveryJobStatusDead, Attempts: 3, MaxAttempts: 1}
+	pending := &models.DeliveryJob{PharmacyID: pharmacyID, Kind: models.DeliveryJobKindSMS, Status: models.DeliveryJobStatusPending}
+	_ = repo.Create(ctx, dead)
+	_ = repo.Create(ctx, pending)
+
+	if _, err := svc.Retry(ctx, uuid.New(), dead.ID); appCode(err) != pkgerrors.ErrCodeNotFound {
+		t.Errorf("another pharmacy's job: err = %v, want not found", err)
+	}
+	if _, err := svc.Retry(ctx, pharmacyID, uuid.New()); appCode(err) != pkgerrors.ErrCodeNotFound {
+		t.Errorf("missing job: err = %v, want not found", err)
+	}
+	if _, err := svc.Retry(ctx, pharmacyID, pending.ID); appCode(err) != pkgerrors.ErrCodeConflict {
+		t.Errorf("pending job: err = %v, want conflict", err)
+	}
+	job, err := svc.Retry(ctx, pharmacyID, dead.ID)
+	if err != nil || job.Status != models.DeliveryJobStatusPending || job.Attempts != 0 || job.MaxAttempts != 3 || !job.NextAttemptAt.Equal(now) {
+		t.Errorf("Retry = %+v, %v; want pending, due now, with a fresh attempt budget", job, err)
+	}
+	repo.err = errors.New("connection reset")
+	if _, err := svc.Retry(ctx, pharmacyID, dead.ID); appCode(err) != pkgerrors.ErrCodeInternal {
+		t.Errorf("store failure: err = %v, want internal error", err)
+	}
+}
@@ -0,0 +1,531 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/careplus/pharmacy-backend/pkg/imaging"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// deliveryNotices is the customer notification title and message per stage (%s = order number).
+var deliveryNotices = map[models.DeliveryStatus][2]string{
+	models.DeliveryStatusPacked:         {"Order packed", "Your order %s has been packed and will be dispatched soon."},
+	models.DeliveryStatusDispatched:     {"Order dispatched", "Your order %s has left the pharmacy."},
+	models.DeliveryStatusOutForDelivery: {"Out for delivery", "Your order %s is out for delivery and will arrive soon."},
+	models.DeliveryStatusDelivered:      {"Order delivered", "Your order %s has been delivered. Thank you for shopping with us."},
+}
+
+// Proof images are shrunk to fit these bounds and stored as JPEG (signatures are flattened onto white).
+var deliveryProofSizes = map[string][2]int{
+	"photo":     {1600, 1600},
+	"signature": {800, 400},
+}
+
+const deliveryProofQuality = 80
+
+type deliveryService struct {
+	deliveryRepo        outbound.DeliveryRepository
+	orderRepo           outbound.OrderRepository
+	userRepo            outbound.UserRepository
+	configRepo          outbound.PharmacyConfigRepository
+	pharmacyRepo        outbound.PharmacyRepository
+	notificationService inbound.NotificationService
+	smsSender           outbound.SMSSender
+	storage             outbound.FileStorage
+	logger              *zap.Logger
+}
+
+// NewDeliveryService tracks deliveries. smsSender (optional) also texts delivery codes to the customer's phone
+// when the pharmacy enables SMS order updates; storage holds signature and doorstep photos.
+func NewDeliveryService(deliveryRepo outbound.DeliveryRepository, orderRepo outbound.OrderRepository, userRepo outbound.UserRepository, configRepo outbound.PharmacyConfigRepository, pharmacyRepo outbound.PharmacyRepository, notificationService inbound.NotificationService, smsSender outbound.SMSSender, storage outbound.FileStorage, logger *zap.Logger) inbound.DeliveryService {
+	return &deliveryService{
+		deliveryRepo:        deliveryRepo,
+		orderRepo:           orderRepo,
+		userRepo:            userRepo,
+		configRepo:          configRepo,
+		pharmacyRepo:        pharmacyRepo,
+		notificationService: notificationService,
+		smsSender:           smsSender,
+		storage:             storage,
+		logger:              logger,
+	}
+}
+
+func (s *deliveryService) Create(ctx context.Context, pharmacyID, orderID, actorID uuid.UUID, assignedTo *uuid.UUID, note string) (*models.Delivery, error) {
+	order, err := s.order(ctx, pharmacyID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	switch order.Status {
+	case models.OrderStatusPending:
+		return nil, errors.ErrValidation("accept the order before starting delivery")
+	case models.OrderStatusCancelled:
+		return nil, errors.ErrValidation("order is cancelled")
+	}
+	existing, err := s.deliveryRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load delivery", err)
+	}
+	if existing != nil {
+		return nil, errors.ErrConflict("order already has a delivery")
+	}
+	var assignee *models.User
+	if assignedTo != nil {
+		if assignee, err = s.checkAssignee(ctx, pharmacyID, *assignedTo); err != nil {
+			return nil, err
+		}
+	}
+	now := time.Now()
+	d := &models.Delivery{
+		PharmacyID: pharmacyID,
+		OrderID:    orderID,
+		Status:     models.DeliveryStatusPacked,
+		AssignedTo: assignedTo,
+		PackedAt:   &now,
+		CreatedBy:  actorID,
+	}
+	if err := s.deliveryRepo.Create(ctx, d); err != nil {
+		return nil, errors.ErrInternal("failed to create delivery", err)
+	}
+	s.addEvent(ctx, d, models.DeliveryEventStatus, strings.TrimSpace(note), nil, nil, actorID)
+	if assignee != nil {
+		s.addEvent(ctx, d, models.DeliveryEventAssigned, "assigned to "+assignee.Name, nil, nil, actorID)
+	}
+	s.notify(ctx, order, d.Status)
+	return s.GetByOrder(ctx, pharmacyID, orderID)
+}
+
+func (s *deliveryService) GetByOrder(ctx context.Context, pharmacyID, orderID uuid.UUID) (*models.Delivery, error) {
+	d, err := s.deliveryRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load delivery", err)
+	}
+	if d == nil || d.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("delivery")
+	}
+	return d, nil
+}
+
+func (s *deliveryService) List(ctx context.Context, pharmacyID uuid.UUID, status *models.DeliveryStatus, assignedTo *uuid.UUID) ([]*models.Delivery, error) {
+	if status != nil && models.DeliveryStatusRank(*status) == 0 {
+		return nil, errors.ErrValidation("unknown delivery status")
+	}
+	return s.deliveryRepo.List(ctx, pharmacyID, status, assignedTo)
+}
+
+func (s *deliveryService) Assign(ctx context.Context, pharmacyID, orderID, actorID, assigneeID uuid.UUID) (*models.Delivery, error) {
+	d, err := s.GetByOrder(ctx, pharmacyID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == models.DeliveryStatusDelivered {
+		return nil, errors.ErrValidation("delivery is already completed")
+	}
+	assignee, err := s.checkAssignee(ctx, pharmacyID, assigneeID)
+	if err != nil {
+		return nil, err
+	}
+	d.AssignedTo = &assigneeID
+	d.Assignee = nil
+	if err := s.deliveryRepo.Update(ctx, d); err != nil {
+		return nil, errors.ErrInternal("failed to assign delivery", err)
+	}
+	s.addEvent(ctx, d, models.DeliveryEventAssigned, "assigned to "+assignee.Name, nil, nil, actorID)
+	return s.GetByOrder(ctx, pharmacyID, orderID)
+}
+
+func (s *deliveryService) UpdateStatus(ctx context.Context, pharmacyID, orderID, actorID uuid.UUID, canManage bool, input inbound.DeliveryUpdateInput) (*models.Delivery, error) {
+	newRank := models.DeliveryStatusRank(input.Status)
+	if newRank == 0 {
+		return nil, errors.ErrValidation("status must be packed, dispatched, out_for_delivery or delivered")
+	}
+	if err := validateCoordinates(input.Latitude, input.Longitude); err != nil {
+		return nil, err
+	}
+	d, err := s.editable(ctx, pharmacyID, orderID, actorID, canManage)
+	if err != nil {
+		return nil, err
+	}
+	if newRank <= models.DeliveryStatusRank(d.Status) {
+		return nil, errors.ErrValidation("delivery is already " + string(d.Status))
+	}
+	if input.Status == models.DeliveryStatusDelivered {
+		if err := s.applyProof(ctx, d, input); err != nil {
+			return nil, err
+		}
+	}
+	now := time.Now()
+	d.Status = input.Status
+	switch input.Status {
+	case models.DeliveryStatusDispatched:
+		d.DispatchedAt = &now
+	case models.DeliveryStatusOutForDelivery:
+		d.OutForDeliveryAt = &now
+	case models.DeliveryStatusDelivered:
+		d.DeliveredAt = &now
+	}
+	note := strings.TrimSpace(input.Note)
+	applyLocation(d, "", input.Latitude, input.Longitude, now)
+	d.Assignee, d.Events = nil, nil
+	if err := s.deliveryRepo.Update(ctx, d); err != nil {
+		return nil, errors.ErrInternal("failed to update delivery", err)
+	}
+	if note == "" && d.ReceivedBy != "" {
+		note = "received by " + d.ReceivedBy
+		if d.ReceiverRelation != "" {
+			note += " (" + d.ReceiverRelation + ")"
+		}
+	}
+	s.addEvent(ctx, d, models.DeliveryEventStatus, note, input.Latitude, input.Longitude, actorID)
+	if order, err := s.orderRepo.GetByID(ctx, orderID); err == nil && order != nil {
+		s.notify(ctx, order, d.Status)
+		if d.Status == models.DeliveryStatusOutForDelivery && s.otpRequired(ctx, pharmacyID) {
+			if err := s.sendOTP(ctx, d, order, actorID); err != nil {
+				s.logger.Warn("failed to send delivery code", zap.String("order_id", orderID.String()), zap.Error(err))
+			}
+		}
+	}
+	return s.GetByOrder(ctx, pharmacyID, orderID)
+}
+
+func (s *deliveryService) UpdateLocation(ctx context.Context, pharmacyID, orderID, actorID uuid.UUID, canManage bool, input inbound.DeliveryUpdateInput) (*models.Delivery, error) {
+	note := strings.TrimSpace(input.Note)
+	if note == "" && (input.Latitude == nil || input.Longitude == nil) {
+		return nil, errors.ErrValidation("send latitude and longitude, a note, or both")
+	}
+	if err := validateCoordinates(input.Latitude, input.Longitude); err != nil {
+		return nil, err
+	}
+	d, err := s.editable(ctx, pharmacyID, orderID, actorID, canManage)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == models.DeliveryStatusDelivered {
+		return nil, errors.ErrValidation("delivery is already completed")
+	}
+	applyLocation(d, note, input.Latitude, input.Longitude, time.Now())
+	d.Assignee, d.Events = nil, nil
+	if err := s.deliveryRepo.Update(ctx, d); err != nil {
+		return nil, errors.ErrInternal("failed to update delivery", err)
+	}
+	s.addEvent(ctx, d, models.DeliveryEventLocation, note, input.Latitude, input.Longitude, actorID)
+	return s.GetByOrder(ctx, pharmacyID, orderID)
+}
+
+func (s *deliveryService) SetDestination(ctx context.Context, pharmacyID, orderID uuid.UUID, input inbound.DeliveryDestinationInput) (*models.Delivery, error) {
+	if input.Latitude == nil || input.Longitude == nil {
+		return nil, errors.ErrValidation("latitude and longitude are required")
+	}
+	if err := validateCoordinates(input.Latitude, input.Longitude); err != nil {
+		return nil, err
+	}
+	if input.WindowStart != nil && input.WindowEnd != nil && !input.WindowEnd.After(*input.WindowStart) {
+		return nil, errors.ErrValidation("window_end must be after window_start")
+	}
+	d, err := s.GetByOrder(ctx, pharmacyID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == models.DeliveryStatusDelivered {
+		return nil, errors.ErrValidation("delivery is already completed")
+	}
+	d.DestLatitude, d.DestLongitude = input.Latitude, input.Longitude
+	d.WindowStart, d.WindowEnd = input.WindowStart, input.WindowEnd
+	d.Assignee, d.Events = nil, nil
+	if err := s.deliveryRepo.Update(ctx, d); err != nil {
+		return nil, errors.ErrInternal("failed to update delivery", err)
+	}
+	return s.GetByOrder(ctx, pharmacyID, orderID)
+}
+
+func (s *deliveryService) AttachProof(ctx context.Context, pharmacyID, orderID, actorID uuid.UUID, canManage bool, kind string, body io.Reader) (*models.Delivery, error) {
+	size, ok := deliveryProofSizes[kind]
+	if !ok {
+		return nil, errors.ErrValidation("proof must be a signature or photo")
+	}
+	if s.storage == nil {
+		return nil, errors.ErrInternal("file storage is not configured", nil)
+	}
+	d, err := s.editable(ctx, pharmacyID, orderID, actorID, canManage)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == models.DeliveryStatusDelivered {
+		return nil, errors.ErrValidation("delivery is already completed")
+	}
+	img, _, err := imaging.Decode(body)
+	if err != nil {
+		return nil, errors.ErrValidation(err.Error())
+	}
+	data, err := imaging.EncodeJPEG(imaging.Fit(img, size[0], size[1]), deliveryProofQuality)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to encode image", err)
+	}
+	path := "photos/deliveries/" + time.Now().Format("2006/01") + "/" + uuid.New().String() + "-" + kind + ".jpg"
+	url, err := s.storage.Save(ctx, path, bytes.NewReader(data), "image/jpeg")
+	if err != nil {
+		return nil, errors.ErrInternal("failed to store image", err)
+	}
+	note := "doorstep photo added"
+	if kind == "signature" {
+		d.SignatureURL = url
+		note = "signature captured"
+	} else {
+		d.PhotoURL = url
+	}
+	d.Assignee, d.Events = nil, nil
+	if err := s.deliveryRepo.Update(ctx, d); err != nil {
+		return nil, errors.ErrInternal("failed to update delivery", err)
+	}
+	s.addEvent(ctx, d, models.DeliveryEventProof, note, nil, nil, actorID)
+	return s.GetByOrder(ctx, pharmacyID, orderID)
+}
+
+func (s *deliveryService) SendOTP(ctx context.Context, pharmacyID, orderID, actorID uuid.UUID, canManage bool) (*models.Delivery, error) {
+	d, err := s.editable(ctx, pharmacyID, orderID, actorID, canManage)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == models.DeliveryStatusDelivered {
+		return nil, errors.ErrValidation("delivery is already completed")
+	}
+	if d.OTPSentAt != nil && time.Since(*d.OTPSentAt) < otpResendInterval {
+		return nil, errors.ErrTooManyRequests("please wait a minute before sending another code")
+	}
+	order, err := s.order(ctx, pharmacyID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sendOTP(ctx, d, order, actorID); err != nil {
+		return nil, err
+	}
+	return s.GetByOrder(ctx, pharmacyID, orderID)
+}
+
+// sendOTP stores a new delivery code and sends it to the customer in the app and, when SMS order updates are
+// enabled, by SMS. The code is never sent to a team member's account (e.g. the cashier of a counter order).
+func (s *deliveryService) sendOTP(ctx context.Context, d *models.Delivery, order *models.Order, actorID uuid.UUID) error {
+	code, err := randomOTP()
+	if err != nil {
+		return errors.ErrInternal("failed to generate delivery code", err)
+	}
+	now := time.Now()
+	d.OTPHash = hashOTP(d.ID, code)
+	d.OTPAttempts = 0
+	d.OTPSentAt = &now
+	d.Assignee, d.Events = nil, nil
+	if err := s.deliveryRepo.Update(ctx, d); err != nil {
+		return errors.ErrInternal("failed to update delivery", err)
+	}
+	message := fmt.Sprintf("Your delivery code for order %s is %s. Share it with the courier only when you receive your order.", order.OrderNumber, code)
+	reached := false
+	if s.notificationService != nil && order.CreatedBy != uuid.Nil {
+		if u, err := s.userRepo.GetByID(ctx, order.CreatedBy); err == nil && u != nil && u.Role == models.RoleStaff {
+			if _, err := s.notificationService.Create(ctx, order.PharmacyID, u.ID, "Delivery code", message, "delivery"); err != nil {
+				s.logger.Warn("failed to send delivery code notification", zap.String("order_id", order.ID.String()), zap.Error(err))
+			} else {
+				reached = true
+			}
+		}
+	}
+	if s.smsSender != nil && strings.TrimSpace(order.CustomerPhone) != "" {
+		if name, enabled := smsSettings(ctx, s.configRepo, s.pharmacyRepo, order.PharmacyID, models.FeatureSMSOrderUpdates); enabled {
+			if err := s.smsSender.Send(ctx, &outbound.SMSMessage{PharmacyID: order.PharmacyID, To: strings.TrimSpace(order.CustomerPhone), Body: name + ": " + message}); err != nil {
+				s.logger.Warn("failed to text delivery code", zap.String("order_id", order.ID.String()), zap.Error(err))
+			} else {
+				reached = true
+			}
+		}
+	}
+	if !reached {
+		return errors.ErrValidation("the customer has no app account or SMS-enabled phone to receive the code")
+	}
+	s.addEvent(ctx, d, models.DeliveryEventOTP, "delivery code sent to the customer", nil, nil, actorID)
+	return nil
+}
+
+// applyProof records who received the order and checks the delivery code, which is required when the pharmacy
+// requires codes and optional otherwise. A wrong code counts towards otpMaxAttempts.
+func (s *deliveryService) applyProof(ctx context.Context, d *models.Delivery, input inbound.DeliveryUpdateInput) error {
+	relation := strings.ToLower(strings.TrimSpace(input.ReceiverRelation))
+	if relation != "" && !models.DeliveryReceiverRelations[relation] {
+		return errors.ErrValidation("receiver_relation must be self, family, friend, neighbour, colleague, security or other")
+	}
+	code := strings.TrimSpace(input.OTP)
+	if code != "" || s.otpRequired(ctx, d.PharmacyID) {
+		if d.OTPHash == "" {
+			return errors.ErrValidation("send the delivery code to the customer first")
+		}
+		if code == "" {
+			return errors.ErrValidation("the customer's delivery code is required")
+		}
+		if d.OTPAttempts >= otpMaxAttempts {
+			return errors.ErrTooManyRequests("too many wrong codes, send a new code to the customer")
+		}
+		if subtle.ConstantTimeCompare([]byte(hashOTP(d.ID, code)), []byte(d.OTPHash)) != 1 {
+			d.OTPAttempts++
+			d.Assignee, d.Events = nil, nil
+			if err := s.deliveryRepo.Update(ctx, d); err != nil {
+				return errors.ErrInternal("failed to update delivery", err)
+			}
+			return errors.ErrValidation("delivery code is incorrect")
+		}
+		now := time.Now()
+		d.OTPVerifiedAt = &now
+	}
+	d.ReceivedBy = truncateRunes(strings.TrimSpace(input.ReceivedBy), 255)
+	d.ReceiverRelation = relation
+	return nil
+}
+
+func (s *deliveryService) otpRequired(ctx context.Context, pharmacyID uuid.UUID) bool {
+	if s.configRepo == nil {
+		return false
+	}
+	cfg, err := s.configRepo.GetByPharmacyID(ctx, pharmacyID)
+	return err == nil && cfg != nil && cfg.DeliveryOTPRequired
+}
+
+func (s *deliveryService) Track(ctx context.Context, pharmacyID, orderID uuid.UUID, customerUserID *uuid.UUID) (*inbound.DeliveryTracking, error) {
+	order, err := s.order(ctx, pharmacyID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if customerUserID != nil && order.CreatedBy != *customerUserID {
+		return nil, errors.ErrNotFound("order")
+	}
+	d, err := s.GetByOrder(ctx, pharmacyID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	t := &inbound.DeliveryTracking{
+		OrderID:          order.ID,
+		OrderNumber:      order.OrderNumber,
+		Status:           d.Status,
+		LocationNote:     d.LocationNote,
+		Latitude:         d.Latitude,
+		Longitude:        d.Longitude,
+		LocationAt:       d.LocationAt,
+		PackedAt:         d.PackedAt,
+		DispatchedAt:     d.DispatchedAt,
+		OutForDeliveryAt: d.OutForDeliveryAt,
+		DeliveredAt:      d.DeliveredAt,
+		Proof:            d.Proof(),
+		Timeline:         []inbound.DeliveryTrackingEvent{},
+	}
+	if d.Assignee != nil {
+		t.CourierName = firstName(d.Assignee.Name)
+	}
+	for _, e := range d.Events {
+		if e.Kind == models.DeliveryEventStatus {
+			t.Timeline = append(t.Timeline, inbound.DeliveryTrackingEvent{Status: e.Status, Note: e.Note, CreatedAt: e.CreatedAt})
+		}
+	}
+	return t, nil
+}
+
+func (s *deliveryService) order(ctx context.Context, pharmacyID, orderID uuid.UUID) (*models.Order, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil || order == nil || order.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("order")
+	}
+	return order, nil
+}
+
+// editable loads the delivery and checks the actor may change it: managers of deliveries, or the assignee.
+func (s *deliveryService) editable(ctx context.Context, pharmacyID, orderID, actorID uuid.UUID, canManage bool) (*models.Delivery, error) {
+	d, err := s.GetByOrder(ctx, pharmacyID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage && (d.AssignedTo == nil || *d.AssignedTo != actorID) {
+		return nil, errors.ErrForbidden("only the assigned delivery person can update this delivery")
+	}
+	return d, nil
+}
+
+// checkAssignee requires an active team member of the pharmacy (not a buyer account).
+func (s *deliveryService) checkAssignee(ctx context.Context, pharmacyID, userID uuid.UUID) (*models.User, error) {
+	return deliveryAssignee(ctx, s.userRepo, pharmacyID, userID)
+}
+
+// deliveryAssignee loads a user who may carry deliveries: an active team member of the pharmacy.
+func deliveryAssignee(ctx context.Context, userRepo outbound.UserRepository, pharmacyID, userID uuid.UUID) (*models.User, error) {
+	u, err := userRepo.GetByID(ctx, userID)
+	if err != nil || u == nil || u.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("user")
+	}
+	if !u.IsActive || u.Role == models.RoleStaff {
+		return nil, errors.ErrValidation("deliveries can only be assigned to active team members")
+	}
+	return u, nil
+}
+
+// addEvent appends to the timeline; failures are logged since the delivery itself is already saved.
+func (s *deliveryService) addEvent(ctx context.Context, d *models.Delivery, kind, note string, lat, lng *float64, actorID uuid.UUID) {
+	e := &models.DeliveryEvent{
+		DeliveryID: d.ID,
+		Kind:       kind,
+		Status:     d.Status,
+		Note:       note,
+		Latitude:   lat,
+		Longitude:  lng,
+		CreatedBy:  actorID,
+	}
+	if err := s.deliveryRepo.CreateEvent(ctx, e); err != nil {
+		s.logger.Warn("failed to record delivery event", zap.String("delivery_id", d.ID.String()), zap.Error(err))
+	}
+}
+
+// notify tells the customer (the order creator) about a stage change. Failures are logged, never returned.
+func (s *deliveryService) notify(ctx context.Context, order *models.Order, status models.DeliveryStatus) {
+	if s.notificationService == nil || order.CreatedBy == uuid.Nil {
+		return
+	}
+	notice, ok := deliveryNotices[status]
+	if !ok {
+		return
+	}
+	if _, err := s.notificationService.Create(ctx, order.PharmacyID, order.CreatedBy, notice[0], fmt.Sprintf(notice[1], order.OrderNumber), "delivery"); err != nil {
+		s.logger.Warn("failed to send delivery notification", zap.String("order_id", order.ID.String()), zap.Error(err))
+	}
+}
+
+func applyLocation(d *models.Delivery, note string, lat, lng *float64, at time.Time) {
+	if lat != nil && lng != nil {
+		d.Latitude, d.Longitude = lat, lng
+		d.LocationAt = &at
+	}
+	if note != "" {
+		d.LocationNote = note
+		d.LocationAt = &at
+	}
+}
+
+func validateCoordinates(lat, lng *float64) error {
+	if (lat == nil) != (lng == nil) {
+		return errors.ErrValidation("latitude and longitude must be sent together")
+	}
+	if lat != nil && (*lat < -90 || *lat > 90 || *lng < -180 || *lng > 180) {
+		return errors.ErrValidation("coordinates out of range")
+	}
+	return nil
+}
+
+func firstName(name string) string {
+	if fields := strings.Fields(name); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
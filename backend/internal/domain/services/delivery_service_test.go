@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// recordingNotifier captures notifications sent through NotificationService.Create.
+type recordingNotifier struct {
+	inbound.NotificationService
+	sent []*models.Notification
+}
+
+func (r *recordingNotifier) Create(ctx context.Context, pharmacyID, userID uuid.UUID, title, message, notifType string) (*models.Notification, error) {
+	n := &models.Notification{PharmacyID: pharmacyID, UserID: userID, Title: title, Message: message, Type: notifType}
+	r.sent = append(r.sent, n)
+	return n, nil
+}
+
+type deliveryFixture struct {
+	pharmacyID, courierID uuid.UUID
+	order                 *models.Order
+	delivery              *models.Delivery
+	events                []*models.DeliveryEvent
+	notifier              *recordingNotifier
+	config                *models.PharmacyConfig
+	svc                   inbound.DeliveryService
+}
+
+func newDeliveryFixture(status models.OrderStatus) *deliveryFixture {
+	f := &deliveryFixture{pharmacyID: uuid.New(), courierID: uuid.New(), notifier: &recordingNotifier{}, config: &models.PharmacyConfig{}}
+	f.order = &models.Order{ID: uuid.New(), PharmacyID: f.pharmacyID, OrderNumber: "ORD-1001", Status: status, CreatedBy: uuid.New()}
+	deliveries := &mocks.MockDeliveryRepository{
+		CreateFunc: func(ctx context.Context, d *models.Delivery) error {
+			d.ID = uuid.New()
+			f.delivery = d
+			return nil
+		},
+		GetByOrderIDFunc: func(ctx context.Context, orderID uuid.UUID) (*models.Delivery, error) {
+			if f.delivery == nil || f.delivery.OrderID != orderID {
+				return nil, nil
+			}
+			f.delivery.Events = f.events
+			return f.delivery, nil
+		},
+		CreateEventFunc: func(ctx context.Context, e *models.DeliveryEvent) error {
+			f.events = append(f.events, e)
+			return nil
+		},
+	}
+	orders := &mocks.MockOrderRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Order, error) {
+			if id == f.order.ID {
+				return f.order, nil
+			}
+			return nil, nil
+		},
+	}
+	users := &mocks.MockUserRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.User, error) {
+			if id == f.courierID {
+				return &models.User{ID: id, PharmacyID: f.pharmacyID, Name: "Ram Thapa", Role: models.RolePharmacist, IsActive: true}, nil
+			}
+			if id == f.order.CreatedBy {
+				return &models.User{ID: id, PharmacyID: f.pharmacyID, Name: "Sita Rai", Role: models.RoleStaff, IsActive: true}, nil
+			}
+			return nil, nil
+		},
+	}
+	configs := &mocks.MockPharmacyConfigRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, pharmacyID uuid.UUID) (*models.PharmacyConfig, error) { return f.config, nil },
+	}
+	f.svc = NewDeliveryService(deliveries, orders, users, configs, nil, f.notifier, nil, nil, zap.NewNop())
+	return f
+}
+
+func TestDeliveryService_Create_NotifiesCustomer(t *testing.T) {
+	f := newDeliveryFixture(models.OrderStatusReady)
+	d, err := f.svc.Create(context.Background(), f.pharmacyID, f.order.ID, uuid.New(), &f.courierID, "")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if d.Status != models.DeliveryStatusPacked || d.PackedAt == nil {
+		t.Errorf("expected packed delivery, got %s", d.Status)
+	}
+	if len(f.notifier.sent) != 1 || f.notifier.sent[0].UserID != f.order.CreatedBy || f.notifier.sent[0].Type != "delivery" {
+		t.Errorf("expected one delivery notification to the customer, got %+v", f.notifier.sent)
+	}
+	if _, err := f.svc.Create(context.Background(), f.pharmacyID, f.order.ID, uuid.New(), nil, ""); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeConflict {
+		t.Errorf("expected conflict for second delivery, got %v", err)
+	}
+}
+
+func TestDeliveryService_Create_RejectsPendingOrder(t *testing.T) {
+	f := newDeliveryFixture(models.OrderStatusPending)
+	_, err := f.svc.Create(context.Background(), f.pharmacyID, f.order.ID, uuid.New(), nil, "")
+	if ae := pkgerrors.GetAppError(err); ae == nil || ae.Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("expected validation error, got %v", err)
+	}
+}
+
+func TestDeliveryService_UpdateStatus_ForwardOnlyAndAssigneeOnly(t *testing.T) {
+	f := newDeliveryFixture(models.OrderStatusReady)
+	if _, err := f.svc.Create(context.Background(), f.pharmacyID, f.order.ID, uuid.New(), &f.courierID, ""); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	ctx := context.Background()
+
+	_, err := f.svc.UpdateStatus(ctx, f.pharmacyID, f.order.ID, uuid.New(), false, inbound.DeliveryUpdateInput{Status: models.DeliveryStatusDispatched})
+	if ae := pkgerrors.GetAppError(err); ae == nil || ae.Code != pkgerrors.ErrCodeForbidden {
+		t.Errorf("expected forbidden for a non-assignee, got %v", err)
+	}
+
+	lat, lng := 27.7172, 85.3240
+	d, err := f.svc.UpdateStatus(ctx, f.pharmacyID, f.order.ID, f.courierID, false, inbound.DeliveryUpdateInput{Status: models.DeliveryStatusOutForDelivery, Latitude: &lat, Longitude: &lng})
+	if err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	if d.Status != models.DeliveryStatusOutForDelivery || d.OutForDeliveryAt == nil || d.Latitude == nil {
+		t.Errorf("unexpected delivery after update: %+v", d)
+	}
+
+	_, err = f.svc.UpdateStatus(ctx, f.pharmacyID, f.order.ID, f.courierID, false, inbound.DeliveryUpdateInput{Status: models.DeliveryStatusDispatched})
+	if ae := pkgerrors.GetAppError(err); ae == nil || ae.Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("expected validation error moving backwards, got %v", err)
+	}
+	if len(f.notifier.sent) != 2 {
+		t.Errorf("expected notifications for packed and out_for_delivery, got %d", len(f.notifier.sent))
+	}
+}
+
+func TestDeliveryService_Track_OwnOrderOnly(t *testing.T) {
+	f := newDeliveryFixture(models.OrderStatusReady)
+	if _, err := f.svc.Create(context.Background(), f.pharmacyID, f.order.ID, uuid.New(), &f.courierID, ""); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	f.delivery.Assignee = &models.User{Name: "Ram Thapa"}
+
+	tr, err := f.svc.Track(context.Background(), f.pharmacyID, f.order.ID, &f.order.CreatedBy)
+	if err != nil {
+		t.Fatalf("Track: %v", err)
+	}
+	if tr.CourierName != "Ram" || tr.OrderNumber != "ORD-1001" || len(tr.Timeline) != 1 {
+		t.Errorf("unexpected tracking view %+v", tr)
+	}
+
+	other := uuid.New()
+	_, err = f.svc.Track(context.Background(), f.pharmacyID, f.order.ID, &other)
+	if ae := pkgerrors.GetAppError(err); ae == nil || ae.Code != pkgerrors.ErrCodeNotFound {
+		t.Errorf("expected not found for another customer, got %v", err)
+	}
+}
+
+func TestDeliveryService_UpdateStatus_RequiresDeliveryCode(t *testing.T) {
+	f := newDeliveryFixture(models.OrderStatusReady)
+	f.config.DeliveryOTPRequired = true
+	ctx := context.Background()
+	if _, err := f.svc.Create(ctx, f.pharmacyID, f.order.ID, uuid.New(), &f.courierID, ""); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	delivered := inbound.DeliveryUpdateInput{Status: models.DeliveryStatusDelivered, ReceivedBy: "Hari Rai", ReceiverRelation: "Family"}
+	if _, err := f.svc.UpdateStatus(ctx, f.pharmacyID, f.order.ID, f.courierID, false, delivered); pkgerrors.GetAppError(err) == nil {
+		t.Fatal("expected an error completing before a code was sent")
+	}
+
+	if _, err := f.svc.UpdateStatus(ctx, f.pharmacyID, f.order.ID, f.courierID, false, inbound.DeliveryUpdateInput{Status: models.DeliveryStatusOutForDelivery}); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	last := f.notifier.sent[len(f.notifier.sent)-1]
+	if last.Title != "Delivery code" || last.UserID != f.order.CreatedBy || f.delivery.OTPHash == "" {
+		t.Fatalf("expected the code to be sent to the customer, got %+v", last)
+	}
+	code := strings.Fields(last.Message)[7][:6]
+
+	delivered.OTP = "000000"
+	if code == delivered.OTP {
+		delivered.OTP = "999999"
+	}
+	_, err := f.svc.UpdateStatus(ctx, f.pharmacyID, f.order.ID, f.courierID, false, delivered)
+	if ae := pkgerrors.GetAppError(err); ae == nil || ae.Code != pkgerrors.ErrCodeValidation || f.delivery.OTPAttempts != 1 {
+		t.Fatalf("expected a counted validation error for a wrong code, got %v (attempts %d)", err, f.delivery.OTPAttempts)
+	}
+
+	delivered.OTP = code
+	d, err := f.svc.UpdateStatus(ctx, f.pharmacyID, f.order.ID, f.courierID, false, delivered)
+	if err != nil {
+		t.Fatalf("UpdateStatus with code: %v", err)
+	}
+	p := d.Proof()
+	if d.Status != models.DeliveryStatusDelivered || p == nil || !p.OTPVerified || p.ReceivedBy != "Hari Rai" || p.ReceiverRelation != "family" {
+		t.Errorf("unexpected proof %+v", p)
+	}
+	if e := f.events[len(f.events)-1]; e.Note != "received by Hari Rai (family)" {
+		t.Errorf("delivered event note = %q", e.Note)
+	}
+}
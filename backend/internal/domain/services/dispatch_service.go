@@ -0,0 +1,407 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	dispatchDefaultMaxStops = 20
+	dispatchMaxStops        = 50
+	dispatchDefaultSpeedKmh = 20.0
+	dispatchDefaultService  = 5 * time.Minute
+	// dispatchLatePenalty makes a stop that would be reached after its window closes a rider's last resort.
+	dispatchLatePenalty = 2 * time.Hour
+	// dispatchPushStopsMax bounds the per-stop ETAs sent in the push payload; the app loads the full manifest.
+	dispatchPushStopsMax = 2048
+)
+
+type dispatchService struct {
+	deliveryRepo        outbound.DeliveryRepository
+	batchRepo           outbound.DispatchBatchRepository
+	orderRepo           outbound.OrderRepository
+	userRepo            outbound.UserRepository
+	notificationService inbound.NotificationService
+	pushNotifier        inbound.PushNotificationService
+	uow                 outbound.UnitOfWork
+	logger              *zap.Logger
+	now                 func() time.Time
+}
+
+// NewDispatchService plans riders' delivery runs. notificationService and pushNotifier (both optional) tell
+// riders about their new batch.
+func NewDispatchService(deliveryRepo outbound.DeliveryRepository, batchRepo outbound.DispatchBatchRepository, orderRepo outbound.OrderRepository, userRepo outbound.UserRepository, notificationService inbound.NotificationService, pushNotifier inbound.PushNotificationService, uow outbound.UnitOfWork, logger *zap.Logger) inbound.DispatchService {
+	return &dispatchService{
+		deliveryRepo:        deliveryRepo,
+		batchRepo:           batchRepo,
+		orderRepo:           orderRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+		pushNotifier:        pushNotifier,
+		uow:                 uow,
+		logger:              logger,
+		now:                 time.Now,
+	}
+}
+
+func (s *dispatchService) PlanBatches(ctx context.Context, pharmacyID, actorID uuid.UUID, input inbound.DispatchPlanInput) (*inbound.DispatchPlan, error) {
+	if len(input.RiderIDs) == 0 {
+		return nil, errors.ErrValidation("at least one rider is required")
+	}
+	if input.StartLatitude == nil || input.StartLongitude == nil {
+		return nil, errors.ErrValidation("start_latitude and start_longitude are required")
+	}
+	if err := validateCoordinates(input.StartLatitude, input.StartLongitude); err != nil {
+		return nil, err
+	}
+	maxStops := input.MaxStops
+	if maxStops == 0 {
+		maxStops = dispatchDefaultMaxStops
+	}
+	if maxStops < 0 || maxStops > dispatchMaxStops {
+		return nil, errors.ErrValidation(fmt.Sprintf("max_stops must be between 1 and %d", dispatchMaxStops))
+	}
+	speed := input.SpeedKmh
+	if speed == 0 {
+		speed = dispatchDefaultSpeedKmh
+	}
+	if speed < 0 || speed > 120 {
+		return nil, errors.ErrValidation("speed_kmh must be between 1 and 120")
+	}
+	service := dispatchDefaultService
+	if input.ServiceMinutes != 0 {
+		if input.ServiceMinutes < 0 || input.ServiceMinutes > 120 {
+			return nil, errors.ErrValidation("service_minutes must be between 1 and 120")
+		}
+		service = time.Duration(input.ServiceMinutes) * time.Minute
+	}
+	depart := s.now()
+	if input.DepartAt != nil {
+		depart = *input.DepartAt
+	}
+
+	var riders []*models.User
+	seen := map[uuid.UUID]bool{}
+	for _, id := range input.RiderIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		u, err := deliveryAssignee(ctx, s.userRepo, pharmacyID, id)
+		if err != nil {
+			return nil, err
+		}
+		riders = append(riders, u)
+	}
+
+	plan := &inbound.DispatchPlan{Batches: []*inbound.DispatchManifest{}, Unrouted: []inbound.DispatchSkip{}}
+	stops, err := s.candidates(ctx, pharmacyID, input.OrderIDs, plan)
+	if err != nil {
+		return nil, err
+	}
+	routes, leftover := planRoutes(*input.StartLatitude, *input.StartLongitude, depart, len(riders), maxStops, speed, service, stops)
+	for _, st := range leftover {
+		plan.Unrouted = append(plan.Unrouted, inbound.DispatchSkip{OrderID: st.order.ID, OrderNumber: st.order.OrderNumber, Reason: "more stops than the riders can take (max_stops)"})
+	}
+	var batchLegs [][]routeLeg // per manifest in plan.Batches
+	for i, r := range routes {
+		if len(r.legs) == 0 {
+			continue
+		}
+		m := &inbound.DispatchManifest{
+			Batch: &models.DispatchBatch{
+				PharmacyID:     pharmacyID,
+				RiderID:        riders[i].ID,
+				StartLatitude:  *input.StartLatitude,
+				StartLongitude: *input.StartLongitude,
+				DepartAt:       depart,
+				FinishAt:       r.clock,
+				DistanceKm:     math.Round(r.km*10) / 10,
+				Stops:          len(r.legs),
+				CreatedBy:      actorID,
+				Rider:          riders[i],
+			},
+		}
+		for seq, leg := range r.legs {
+			eta := leg.eta
+			m.Stops = append(m.Stops, dispatchStop(seq+1, leg.stop.delivery, leg.stop.order, leg.legKm, &eta))
+		}
+		plan.Batches = append(plan.Batches, m)
+		batchLegs = append(batchLegs, r.legs)
+	}
+	if input.DryRun || len(plan.Batches) == 0 {
+		return plan, nil
+	}
+
+	err = inTx(ctx, s.uow, func(ctx context.Context) error {
+		for i, m := range plan.Batches {
+			if err := s.batchRepo.Create(ctx, m.Batch); err != nil {
+				return errors.ErrInternal("failed to save dispatch batch", err)
+			}
+			rider := m.Batch.Rider
+			for seq, leg := range batchLegs[i] {
+				d := leg.stop.delivery
+				eta := leg.eta
+				d.AssignedTo, d.BatchID, d.StopSequence, d.ETA = &rider.ID, &m.Batch.ID, seq+1, &eta
+				d.Assignee, d.Events = nil, nil
+				if err := s.deliveryRepo.Update(ctx, d); err != nil {
+					return errors.ErrInternal("failed to update delivery", err)
+				}
+				note := fmt.Sprintf("stop %d of %d for %s, ETA %s", d.StopSequence, len(m.Stops), rider.Name, eta.Format("15:04"))
+				if err := s.deliveryRepo.CreateEvent(ctx, &models.DeliveryEvent{DeliveryID: d.ID, Kind: models.DeliveryEventRouted, Status: d.Status, Note: note, CreatedBy: actorID}); err != nil {
+					return errors.ErrInternal("failed to record delivery event", err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range plan.Batches {
+		s.notifyRider(ctx, m)
+	}
+	return plan, nil
+}
+
+// candidates returns the packed deliveries of ready orders to plan: those listed in orderIDs, or else every one
+// not yet in a batch. Deliveries that cannot be routed are added to plan.Unrouted. The result is sorted by
+// window end (open-ended last), then by age, so the tightest stops are considered first on ties.
+func (s *dispatchService) candidates(ctx context.Context, pharmacyID uuid.UUID, orderIDs []uuid.UUID, plan *inbound.DispatchPlan) ([]*routeStop, error) {
+	packed := models.DeliveryStatusPacked
+	list, err := s.deliveryRepo.List(ctx, pharmacyID, &packed, nil)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list deliveries", err)
+	}
+	wanted := map[uuid.UUID]bool{}
+	for _, id := range orderIDs {
+		wanted[id] = true
+	}
+	var stops []*routeStop
+	for _, d := range list {
+		if len(wanted) > 0 {
+			if !wanted[d.OrderID] {
+				continue
+			}
+			delete(wanted, d.OrderID)
+		} else if d.BatchID != nil {
+			continue
+		}
+		order, err := s.orderRepo.GetByID(ctx, d.OrderID)
+		if err != nil || order == nil {
+			plan.Unrouted = append(plan.Unrouted, inbound.DispatchSkip{OrderID: d.OrderID, Reason: "order not found"})
+			continue
+		}
+		switch {
+		case order.Status != models.OrderStatusReady:
+			plan.Unrouted = append(plan.Unrouted, inbound.DispatchSkip{OrderID: order.ID, OrderNumber: order.OrderNumber, Reason: "order is " + string(order.Status) + ", not ready"})
+		case d.DestLatitude == nil || d.DestLongitude == nil:
+			plan.Unrouted = append(plan.Unrouted, inbound.DispatchSkip{OrderID: order.ID, OrderNumber: order.OrderNumber, Reason: "no drop-off coordinates"})
+		default:
+			stops = append(stops, &routeStop{delivery: d, order: order, lat: *d.DestLatitude, lng: *d.DestLongitude})
+		}
+	}
+	for _, id := range orderIDs {
+		if wanted[id] {
+			delete(wanted, id)
+			plan.Unrouted = append(plan.Unrouted, inbound.DispatchSkip{OrderID: id, Reason: "no packed delivery for this order"})
+		}
+	}
+	sort.SliceStable(stops, func(i, j int) bool {
+		a, b := stops[i].delivery.WindowEnd, stops[j].delivery.WindowEnd
+		if (a == nil) != (b == nil) {
+			return a != nil
+		}
+		if a != nil && !a.Equal(*b) {
+			return a.Before(*b)
+		}
+		return stops[i].delivery.CreatedAt.Before(stops[j].delivery.CreatedAt)
+	})
+	return stops, nil
+}
+
+func (s *dispatchService) GetBatch(ctx context.Context, pharmacyID, batchID uuid.UUID, riderID *uuid.UUID) (*inbound.DispatchManifest, error) {
+	b, err := s.batchRepo.GetByID(ctx, batchID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load dispatch batch", err)
+	}
+	if b == nil || b.PharmacyID != pharmacyID || (riderID != nil && b.RiderID != *riderID) {
+		return nil, errors.ErrNotFound("dispatch batch")
+	}
+	// Deliveries re-planned into a later batch are no longer loaded here, so the manifest shows what is left.
+	m := &inbound.DispatchManifest{Batch: b, Stops: []inbound.DispatchStop{}}
+	lat, lng := b.StartLatitude, b.StartLongitude
+	for _, d := range b.Deliveries {
+		order, err := s.orderRepo.GetByID(ctx, d.OrderID)
+		if err != nil || order == nil {
+			continue
+		}
+		leg := 0.0
+		if d.DestLatitude != nil && d.DestLongitude != nil {
+			leg = haversineKm(lat, lng, *d.DestLatitude, *d.DestLongitude)
+			lat, lng = *d.DestLatitude, *d.DestLongitude
+		}
+		m.Stops = append(m.Stops, dispatchStop(d.StopSequence, d, order, leg, d.ETA))
+	}
+	b.Stops = len(m.Stops)
+	return m, nil
+}
+
+func (s *dispatchService) ListBatches(ctx context.Context, pharmacyID uuid.UUID, riderID *uuid.UUID, limit, offset int) ([]*models.DispatchBatch, int64, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	list, total, err := s.batchRepo.List(ctx, pharmacyID, riderID, limit, offset)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to list dispatch batches", err)
+	}
+	if list == nil {
+		list = []*models.DispatchBatch{}
+	}
+	return list, total, nil
+}
+
+// notifyRider sends the new batch to the rider in-app and as a push carrying the per-stop ETAs (when they fit
+// in the payload). Failures are logged, never returned: the batch is already saved.
+func (s *dispatchService) notifyRider(ctx context.Context, m *inbound.DispatchManifest) {
+	b := m.Batch
+	title := "New delivery route"
+	message := fmt.Sprintf("%d stops, first ETA %s, done by about %s.", len(m.Stops), m.Stops[0].ETA.Format("15:04"), b.FinishAt.Format("15:04"))
+	if s.notificationService != nil {
+		if _, err := s.notificationService.Create(ctx, b.PharmacyID, b.RiderID, title, message, "delivery"); err != nil {
+			s.logger.Warn("failed to notify rider of dispatch batch", zap.String("batch_id", b.ID.String()), zap.Error(err))
+		}
+	}
+	if s.pushNotifier == nil {
+		return
+	}
+	type pushStop struct {
+		Sequence    int       `json:"seq"`
+		OrderNumber string    `json:"order"`
+		ETA         time.Time `json:"eta"`
+	}
+	etas := make([]pushStop, 0, len(m.Stops))
+	for _, st := range m.Stops {
+		etas = append(etas, pushStop{Sequence: st.Sequence, OrderNumber: st.OrderNumber, ETA: *st.ETA})
+	}
+	data := map[string]string{"type": "dispatch_batch", "batch_id": b.ID.String()}
+	if raw, err := json.Marshal(etas); err == nil && len(raw) <= dispatchPushStopsMax {
+		data["stops"] = string(raw)
+	}
+	if err := s.pushNotifier.NotifyUser(ctx, b.RiderID, title, message, "/deliveries/batches/"+b.ID.String(), data); err != nil {
+		s.logger.Warn("failed to push dispatch batch", zap.String("batch_id", b.ID.String()), zap.Error(err))
+	}
+}
+
+func dispatchStop(seq int, d *models.Delivery, order *models.Order, legKm float64, eta *time.Time) inbound.DispatchStop {
+	st := inbound.DispatchStop{
+		Sequence:      seq,
+		OrderID:       order.ID,
+		OrderNumber:   order.OrderNumber,
+		CustomerName:  order.CustomerName,
+		CustomerPhone: order.CustomerPhone,
+		Address:       order.DeliveryAddress,
+		LegKm:         math.Round(legKm*10) / 10,
+		ETA:           eta,
+		WindowStart:   d.WindowStart,
+		WindowEnd:     d.WindowEnd,
+		Status:        d.Status,
+	}
+	if d.DestLatitude != nil && d.DestLongitude != nil {
+		st.Latitude, st.Longitude = *d.DestLatitude, *d.DestLongitude
+	}
+	st.Late = eta != nil && d.WindowEnd != nil && eta.After(*d.WindowEnd)
+	return st
+}
+
+// routeStop is a delivery waiting to be routed.
+type routeStop struct {
+	delivery *models.Delivery
+	order    *models.Order
+	lat, lng float64
+}
+
+// routeLeg is a stop placed on a rider's route; eta is when the rider starts serving it.
+type routeLeg struct {
+	stop  *routeStop
+	legKm float64
+	eta   time.Time
+}
+
+// riderRoute is one rider's route being built: where the rider is and when they are free again.
+type riderRoute struct {
+	legs     []routeLeg
+	lat, lng float64
+	clock    time.Time
+	km       float64
+}
+
+// planRoutes spreads stops over riders who all leave the start point at depart. Each round the rider who is
+// free earliest takes the stop they can start soonest, counting travel at speedKmh plus any wait for the
+// window to open; a stop they would reach after its window closes is taken only when nothing else is left.
+// Every stop costs service time on top of travel. Stops beyond maxStops per rider are returned as leftover.
+func planRoutes(startLat, startLng float64, depart time.Time, riders, maxStops int, speedKmh float64, service time.Duration, stops []*routeStop) ([]*riderRoute, []*routeStop) {
+	routes := make([]*riderRoute, riders)
+	for i := range routes {
+		routes[i] = &riderRoute{lat: startLat, lng: startLng, clock: depart}
+	}
+	remaining := append([]*routeStop(nil), stops...)
+	for len(remaining) > 0 {
+		var r *riderRoute
+		for _, c := range routes {
+			if len(c.legs) < maxStops && (r == nil || c.clock.Before(r.clock)) {
+				r = c
+			}
+		}
+		if r == nil {
+			break
+		}
+		best := -1
+		var bestCost time.Duration
+		var bestLeg routeLeg
+		for i, st := range remaining {
+			km := haversineKm(r.lat, r.lng, st.lat, st.lng)
+			start := r.clock.Add(time.Duration(km / speedKmh * float64(time.Hour))).Truncate(time.Second)
+			if ws := st.delivery.WindowStart; ws != nil && ws.After(start) {
+				start = *ws
+			}
+			cost := start.Sub(r.clock)
+			if we := st.delivery.WindowEnd; we != nil && start.After(*we) {
+				cost += dispatchLatePenalty
+			}
+			if best < 0 || cost < bestCost {
+				best, bestCost, bestLeg = i, cost, routeLeg{stop: st, legKm: km, eta: start}
+			}
+		}
+		r.legs = append(r.legs, bestLeg)
+		r.lat, r.lng = bestLeg.stop.lat, bestLeg.stop.lng
+		r.clock = bestLeg.eta.Add(service)
+		r.km += bestLeg.legKm
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+	return routes, remaining
+}
+
+// haversineKm is the great-circle distance between two points in kilometres.
+func haversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadiusKm = 6371.0
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLng := (lng2 - lng1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Points east of the start along the equator; 0.01 degrees of longitude is about 1.1 km.
+func eastStop(name string, lng float64) *routeStop {
+	return &routeStop{delivery: &models.Delivery{ID: uuid.New()}, order: &models.Order{OrderNumber: name}, lat: 0, lng: lng}
+}
+
+func TestPlanRoutes_NearestFirstButWaitsForWindows(t *testing.T) {
+	depart := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	near, mid, far := eastStop("near", 0.01), eastStop("mid", 0.03), eastStop("far", 0.05)
+	opens := depart.Add(2 * time.Hour)
+	near.delivery.WindowStart = &opens
+
+	routes, leftover := planRoutes(0, 0, depart, 1, 10, 20, 5*time.Minute, []*routeStop{near, mid, far})
+	if len(leftover) != 0 || len(routes[0].legs) != 3 {
+		t.Fatalf("legs = %d, leftover = %d; want all 3 stops on the route", len(routes[0].legs), len(leftover))
+	}
+	var got []string
+	for _, l := range routes[0].legs {
+		got = append(got, l.stop.order.OrderNumber)
+	}
+	if got[0] != "mid" || got[1] != "far" || got[2] != "near" {
+		t.Fatalf("route = %v, want mid, far, then near once its window opens", got)
+	}
+	if eta := routes[0].legs[2].eta; eta.Before(opens) {
+		t.Errorf("near ETA = %v, want no earlier than the window start %v", eta, opens)
+	}
+	// 3.3 km at 20 km/h is about 10 minutes.
+	if eta := routes[0].legs[0].eta; eta.Sub(depart) < 9*time.Minute || eta.Sub(depart) > 11*time.Minute {
+		t.Errorf("first ETA = %v after departure, want about 10 minutes", eta.Sub(depart))
+	}
+}
+
+func TestPlanRoutes_SpreadsOverRidersUpToMaxStops(t *testing.T) {
+	depart := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	stops := []*routeStop{eastStop("a", 0.01), eastStop("b", 0.02), eastStop("c", 0.03)}
+
+	routes, leftover := planRoutes(0, 0, depart, 2, 1, 20, 5*time.Minute, stops)
+	if len(routes[0].legs) != 1 || len(routes[1].legs) != 1 {
+		t.Fatalf("stops per rider = %d, %d; want one each", len(routes[0].legs), len(routes[1].legs))
+	}
+	if len(leftover) != 1 || leftover[0].order.OrderNumber != "c" {
+		t.Fatalf("leftover = %v, want the farthest stop", leftover)
+	}
+}
+
+func TestDispatchService_PlanBatches_AssignsRouteAndSkipsUnroutable(t *testing.T) {
+	pharmacyID, riderID := uuid.New(), uuid.New()
+	lat, lng := 27.70, 85.32
+	ready := &models.Order{ID: uuid.New(), PharmacyID: pharmacyID, OrderNumber: "ORD-1", Status: models.OrderStatusReady}
+	noCoords := &models.Order{ID: uuid.New(), PharmacyID: pharmacyID, OrderNumber: "ORD-2", Status: models.OrderStatusReady}
+	notReady := &models.Order{ID: uuid.New(), PharmacyID: pharmacyID, OrderNumber: "ORD-3", Status: models.OrderStatusProcessing}
+	orders := map[uuid.UUID]*models.Order{ready.ID: ready, noCoords.ID: noCoords, notReady.ID: notReady}
+	routed := &models.Delivery{ID: uuid.New(), OrderID: ready.ID, Status: models.DeliveryStatusPacked, DestLatitude: &lat, DestLongitude: &lng}
+	list := []*models.Delivery{
+		routed,
+		{ID: uuid.New(), OrderID: noCoords.ID, Status: models.DeliveryStatusPacked},
+		{ID: uuid.New(), OrderID: notReady.ID, Status: models.DeliveryStatusPacked, DestLatitude: &lat, DestLongitude: &lng},
+	}
+	var events []*models.DeliveryEvent
+	deliveries := &mocks.MockDeliveryRepository{
+		ListFunc: func(ctx context.Context, pid uuid.UUID, status *models.DeliveryStatus, assignedTo *uuid.UUID) ([]*models.Delivery, error) {
+			return list, nil
+		},
+		CreateEventFunc: func(ctx context.Context, e *models.DeliveryEvent) error {
+			events = append(events, e)
+			return nil
+		},
+	}
+	batches := &mocks.MockDispatchBatchRepository{
+		CreateFunc: func(ctx context.Context, b *models.DispatchBatch) error {
+			b.ID = uuid.New()
+			return nil
+		},
+	}
+	orderRepo := &mocks.MockOrderRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Order, error) { return orders[id], nil },
+	}
+	users := &mocks.MockUserRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.User, error) {
+			return &models.User{ID: id, PharmacyID: pharmacyID, Name: "Hari", Role: models.RolePharmacist, IsActive: true}, nil
+		},
+	}
+	notifier := &recordingNotifier{}
+	uow := &mocks.MockUnitOfWork{}
+	svc := NewDispatchService(deliveries, batches, orderRepo, users, notifier, nil, uow, zap.NewNop())
+
+	startLat, startLng := 27.71, 85.32
+	plan, err := svc.PlanBatches(context.Background(), pharmacyID, uuid.New(), inbound.DispatchPlanInput{
+		RiderIDs: []uuid.UUID{riderID}, StartLatitude: &startLat, StartLongitude: &startLng,
+	})
+	if err != nil {
+		t.Fatalf("PlanBatches: %v", err)
+	}
+	if len(plan.Batches) != 1 || len(plan.Batches[0].Stops) != 1 || len(plan.Unrouted) != 2 {
+		t.Fatalf("plan = %d batches, %d unrouted; want 1 batch with 1 stop and 2 unrouted", len(plan.Batches), len(plan.Unrouted))
+	}
+	b := plan.Batches[0].Batch
+	if routed.AssignedTo == nil || *routed.AssignedTo != riderID || routed.BatchID == nil || *routed.BatchID != b.ID || routed.StopSequence != 1 || routed.ETA == nil {
+		t.Errorf("delivery = %+v, want assigned to the rider as stop 1 of the batch with an ETA", routed)
+	}
+	if len(events) != 1 || events[0].Kind != models.DeliveryEventRouted {
+		t.Errorf("events = %v, want one routed event", events)
+	}
+	if uow.Calls != 1 || len(notifier.sent) != 1 || notifier.sent[0].UserID != riderID {
+		t.Errorf("transactions = %d, notifications = %d; want one of each, to the rider", uow.Calls, len(notifier.sent))
+	}
+}
@@ -0,0 +1,378 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/careplus/pharmacy-backend/pkg/pdf"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxRosterExportDays bounds one roster export (two months) so a typo in the range cannot produce a huge file.
+const maxRosterExportDays = 62
+
+// defaultBrandColor is used when the pharmacy has no valid primary color.
+var defaultBrandColor = pdf.Color{R: 0.05, G: 0.46, B: 0.43}
+
+type documentService struct {
+	pharmacyRepo outbound.PharmacyRepository
+	configRepo   outbound.PharmacyConfigRepository
+	rosterRepo   outbound.DutyRosterRepository
+	logRepo      outbound.DailyLogRepository
+	invoiceSvc   inbound.InvoiceService
+	now          func() time.Time
+	logger       *zap.Logger
+}
+
+func NewDocumentService(pharmacyRepo outbound.PharmacyRepository, configRepo outbound.PharmacyConfigRepository, rosterRepo outbound.DutyRosterRepository, logRepo outbound.DailyLogRepository, invoiceSvc inbound.InvoiceService, logger *zap.Logger) inbound.DocumentService {
+	return &documentService{pharmacyRepo: pharmacyRepo, configRepo: configRepo, rosterRepo: rosterRepo, logRepo: logRepo, invoiceSvc: invoiceSvc, now: time.Now, logger: logger}
+}
+
+// branding is what the page header shows: the store name (display name over the legal name), a line of
+// contact details, and the primary color.
+type branding struct {
+	name    string
+	details string
+	color   pdf.Color
+}
+
+func (s *documentService) branding(ctx context.Context, pharmacyID uuid.UUID) (*branding, error) {
+	p, err := s.pharmacyRepo.GetByID(ctx, pharmacyID)
+	if err != nil || p == nil {
+		return nil, errors.ErrNotFound("pharmacy")
+	}
+	b := &branding{name: p.Name, color: defaultBrandColor}
+	address, phone := p.Address, p.Phone
+	var tagline string
+	if cfg, _ := s.configRepo.GetByPharmacyID(ctx, pharmacyID); cfg != nil {
+		if cfg.DisplayName != "" {
+			b.name = cfg.DisplayName
+		}
+		if cfg.Location != "" {
+			address = cfg.Location
+		}
+		if cfg.ContactPhone != "" {
+			phone = cfg.ContactPhone
+		}
+		if c, ok := pdf.ParseHexColor(cfg.PrimaryColor); ok {
+			b.color = c
+		}
+		tagline = cfg.Tagline
+	}
+	var parts []string
+	for _, v := range []string{tagline, address, phone} {
+		if v = strings.TrimSpace(v); v != "" {
+			parts = append(parts, v)
+		}
+	}
+	b.details = strings.Join(parts, "  •  ")
+	return b, nil
+}
+
+// document starts a PDF whose every page carries the pharmacy band, the title and the subtitle.
+func (b *branding) document(title, subtitle string) *pdf.Document {
+	return pdf.New(b.name+" – "+title, func(d *pdf.Document) {
+		d.Rect(0, 0, pdf.PageWidth, 56, b.color)
+		d.Text(pdf.Margin, 34, pdf.Bold, 18, pdf.White, b.name)
+		d.SetY(56 + 8)
+		if b.details != "" {
+			d.Paragraph(b.details, pdf.Regular, 9, pdf.Gray)
+		}
+		d.Space(6)
+		d.Heading(title, 16, pdf.Black)
+		if subtitle != "" {
+			d.Paragraph(subtitle, pdf.Regular, 10, pdf.Gray)
+		}
+		d.Space(8)
+	})
+}
+
+func (s *documentService) finish(d *pdf.Document) []byte {
+	d.Footer("Printed " + s.now().Format("2 Jan 2006 15:04"))
+	return d.Bytes()
+}
+
+func (s *documentService) DutyRosterPDF(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]byte, error) {
+	if to.Before(from) {
+		return nil, errors.ErrValidation("to must not be before from")
+	}
+	if to.Sub(from) > maxRosterExportDays*24*time.Hour {
+		return nil, errors.ErrValidation(fmt.Sprintf("export at most %d days at a time", maxRosterExportDays))
+	}
+	b, err := s.branding(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.rosterRepo.ListByPharmacyAndDateRange(ctx, pharmacyID, from, to)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list duty roster", err)
+	}
+	// The noticeboard copy shows what staff can already see: drafts stay off it.
+	var rows [][]string
+	lastDate := ""
+	for _, r := range list {
+		if r.Status != models.RosterPublished {
+			continue
+		}
+		date := r.Date.Format("Mon 2 Jan")
+		if date == lastDate {
+			date = ""
+		} else {
+			lastDate = date
+		}
+		name := ""
+		if r.User != nil {
+			name = r.User.Name
+		}
+		rows = append(rows, []string{date, shiftLabel(r.ShiftType), name, r.Notes})
+	}
+	d := b.document("Duty roster", from.Format("2 Jan 2006")+" to "+to.Format("2 Jan 2006"))
+	if len(rows) == 0 {
+		d.Paragraph("No published shifts in this period.", pdf.Regular, 11, pdf.Black)
+		return s.finish(d), nil
+	}
+	d.Table([]pdf.Column{{Title: "Date", Width: 1.2}, {Title: "Shift", Width: 1}, {Title: "Staff", Width: 2}, {Title: "Notes", Width: 3}}, rows, b.color)
+	return s.finish(d), nil
+}
+
+func shiftLabel(t models.ShiftType) string {
+	switch t {
+	case models.ShiftMorning:
+		return "Morning"
+	case models.ShiftEvening:
+		return "Evening"
+	case models.ShiftFull:
+		return "Full day"
+	}
+	return string(t)
+}
+
+func (s *documentService) DailyLogPDF(ctx context.Context, pharmacyID uuid.UUID, date time.Time) ([]byte, error) {
+	b, err := s.branding(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.logRepo.ListByPharmacyAndDate(ctx, pharmacyID, date)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list daily logs", err)
+	}
+	done := 0
+	rows := make([][]string, 0, len(list))
+	for i, l := range list {
+		status := "Open"
+		if l.Status == models.DailyLogDone {
+			status = "Done"
+			done++
+		}
+		by := ""
+		if l.Creator != nil {
+			by = l.Creator.Name
+		}
+		rows = append(rows, []string{fmt.Sprint(i + 1), l.Title, l.Description, status, by})
+	}
+	d := b.document("Day sheet", date.Format("Monday 2 January 2006"))
+	if len(rows) == 0 {
+		d.Paragraph("Nothing logged for this day.", pdf.Regular, 11, pdf.Black)
+	} else {
+		d.Paragraph(fmt.Sprintf("%d of %d done", done, len(rows)), pdf.Bold, 10, pdf.Black)
+		d.Space(4)
+		d.Table([]pdf.Column{{Title: "#", Width: 0.4}, {Title: "Task", Width: 2.2}, {Title: "Details", Width: 3.4}, {Title: "Status", Width: 0.9}, {Title: "Logged by", Width: 1.6}}, rows, b.color)
+	}
+	// Sign-off lines for the noticeboard copy.
+	d.Space(28)
+	d.Ensure(40)
+	y := d.Y()
+	d.Line(pdf.Margin, y, pdf.Margin+200, y, 0.7, pdf.Black)
+	d.Line(pdf.PageWidth-pdf.Margin-200, y, pdf.PageWidth-pdf.Margin, y, 0.7, pdf.Black)
+	d.Text(pdf.Margin, y+12, pdf.Regular, 9, pdf.Gray, "Checked by")
+	d.Text(pdf.PageWidth-pdf.Margin-200, y+12, pdf.Regular, 9, pdf.Gray, "Date and time")
+	return s.finish(d), nil
+}
+
+func (s *documentService) InvoicePDF(ctx context.Context, pharmacyID, invoiceID uuid.UUID) ([]byte, error) {
+	view, err := s.invoiceSvc.GetByID(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if view.Invoice.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("invoice")
+	}
+	b, err := s.branding(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	inv, o := view.Invoice, view.Order
+	subtitle := "Invoice " + inv.InvoiceNumber + "  •  Draft"
+	if inv.IssuedAt != nil {
+		subtitle = "Invoice " + inv.InvoiceNumber + "  •  Issued " + inv.IssuedAt.Format("2 Jan 2006")
+	}
+	d := b.document("Tax invoice", subtitle)
+	partyBlock(d, o, view.TaxRegistrationNo, view.CustomFields)
+
+	rows := make([][]string, 0, len(o.Items))
+	for i, it := range o.Items {
+		name := "Item"
+		if it.Product != nil {
+			name = it.Product.Name
+		}
+		if len(it.Serials) > 0 {
+			serials := make([]string, len(it.Serials))
+			for j, sn := range it.Serials {
+				serials[j] = sn.SerialNumber
+			}
+			name += " (S/N: " + strings.Join(serials, ", ") + ")"
+		}
+		rows = append(rows, []string{fmt.Sprint(i + 1), name, fmt.Sprint(it.Quantity), moneyText(it.UnitPrice), fmt.Sprintf("%g%%", it.TaxRate), moneyText(it.TotalPrice)})
+	}
+	d.Table([]pdf.Column{{Title: "#", Width: 0.4}, {Title: "Item", Width: 3.4}, {Title: "Qty", Width: 0.6}, {Title: "Unit price", Width: 1.1}, {Title: "VAT", Width: 0.7}, {Title: "Amount", Width: 1.1}}, rows, b.color)
+	d.Space(10)
+	totalLine(d, pdf.Regular, "Subtotal", o.Currency, o.SubTotal)
+	if o.DiscountAmount > 0 {
+		totalLine(d, pdf.Regular, "Discount", o.Currency, -o.DiscountAmount)
+	}
+	if o.TaxAmount > 0 {
+		label := "VAT"
+		if o.TaxInclusive {
+			label = "VAT (included above)"
+		}
+		totalLine(d, pdf.Regular, label, o.Currency, o.TaxAmount)
+	}
+	if o.DeliveryFee > 0 {
+		totalLine(d, pdf.Regular, "Delivery", o.Currency, o.DeliveryFee)
+	}
+	totalLine(d, pdf.Bold, "Invoice total", o.Currency, view.InvoiceTotal)
+	if o.GiftCardAmount > 0 {
+		totalLine(d, pdf.Regular, "Paid by gift card", o.Currency, -o.GiftCardAmount)
+	}
+	if o.StoreCreditAmount > 0 {
+		totalLine(d, pdf.Regular, "Paid by store credit", o.Currency, -o.StoreCreditAmount)
+	}
+
+	if p := view.DeliveryProof; p != nil {
+		d.Space(10)
+		d.Heading("Proof of delivery", 11, pdf.Black)
+		d.Paragraph(deliveryProofText(p), pdf.Regular, 10, pdf.Black)
+	}
+
+	if len(view.TaxLines) > 0 {
+		d.Space(10)
+		d.Heading("VAT breakdown", 11, pdf.Black)
+		taxRows := make([][]string, 0, len(view.TaxLines))
+		for _, l := range view.TaxLines {
+			taxRows = append(taxRows, []string{l.TaxClass, fmt.Sprintf("%g%%", l.Rate), moneyText(l.TaxableAmount), moneyText(l.TaxAmount)})
+		}
+		d.Table([]pdf.Column{{Title: "Class", Width: 2}, {Title: "Rate", Width: 1}, {Title: "Taxable", Width: 1.5}, {Title: "VAT", Width: 1.5}}, taxRows, b.color)
+	}
+
+	if len(view.CreditNotes) > 0 {
+		d.Space(10)
+		d.Heading("Credit notes", 11, pdf.Black)
+		cnRows := make([][]string, 0, len(view.CreditNotes))
+		for _, n := range view.CreditNotes {
+			cnRows = append(cnRows, []string{n.CreditNoteNumber, n.IssuedAt.Format("2 Jan 2006"), n.Reason, moneyText(n.Amount)})
+		}
+		d.Table([]pdf.Column{{Title: "Number", Width: 1.2}, {Title: "Date", Width: 1.1}, {Title: "Reason", Width: 3.2}, {Title: "Amount", Width: 1.1}}, cnRows, b.color)
+		d.Space(6)
+		totalLine(d, pdf.Bold, "Net after credit notes", o.Currency, view.NetAmount)
+	}
+	return s.finish(d), nil
+}
+
+func (s *documentService) CreditNotePDF(ctx context.Context, pharmacyID, creditNoteID uuid.UUID) ([]byte, error) {
+	view, err := s.invoiceSvc.GetCreditNote(ctx, pharmacyID, creditNoteID)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.branding(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	n, o := view.CreditNote, view.Order
+	d := b.document("Credit note", n.CreditNoteNumber+"  •  Issued "+n.IssuedAt.Format("2 Jan 2006"))
+	if view.Invoice != nil {
+		against := "Against invoice " + view.Invoice.InvoiceNumber
+		if view.Invoice.IssuedAt != nil {
+			against += " of " + view.Invoice.IssuedAt.Format("2 Jan 2006")
+		}
+		d.Paragraph(against, pdf.Bold, 10, pdf.Black)
+		d.Space(4)
+	}
+	partyBlock(d, o, view.TaxRegistrationNo, nil)
+	if n.Reason != "" {
+		d.Paragraph("Reason: "+n.Reason, pdf.Regular, 10, pdf.Black)
+		d.Space(8)
+	}
+	totalLine(d, pdf.Regular, "Taxable amount", o.Currency, n.TaxableAmount)
+	totalLine(d, pdf.Regular, "VAT", o.Currency, n.TaxAmount)
+	totalLine(d, pdf.Bold, "Total credited", o.Currency, n.Amount)
+	return s.finish(d), nil
+}
+
+// partyBlock prints who the document is for and the order it concerns.
+func partyBlock(d *pdf.Document, o *models.Order, taxRegistrationNo string, fields []models.OrderFieldValue) {
+	lines := []string{"Order " + o.OrderNumber + " of " + o.CreatedAt.Format("2 Jan 2006")}
+	if name := strings.TrimSpace(o.CustomerName); name != "" {
+		lines = append(lines, "Customer: "+name)
+	}
+	if o.CustomerPhone != "" {
+		lines = append(lines, "Phone: "+o.CustomerPhone)
+	}
+	if o.DeliveryAddress != "" {
+		lines = append(lines, "Deliver to: "+o.DeliveryAddress)
+	}
+	if taxRegistrationNo != "" {
+		lines = append(lines, "VAT/PAN: "+taxRegistrationNo)
+	}
+	for _, f := range fields {
+		lines = append(lines, f.Label+": "+f.Value)
+	}
+	for _, l := range lines {
+		d.Paragraph(l, pdf.Regular, 10, pdf.Black)
+	}
+	d.Space(8)
+}
+
+// deliveryProofText summarises the proof of delivery in one line; the images themselves stay online.
+func deliveryProofText(p *models.DeliveryProof) string {
+	var parts []string
+	if p.DeliveredAt != nil {
+		parts = append(parts, "Delivered "+p.DeliveredAt.Format("2 Jan 2006 15:04"))
+	}
+	if p.ReceivedBy != "" {
+		who := "received by " + p.ReceivedBy
+		if p.ReceiverRelation != "" {
+			who += " (" + p.ReceiverRelation + ")"
+		}
+		parts = append(parts, who)
+	}
+	if p.SignatureURL != "" {
+		parts = append(parts, "signature on file")
+	}
+	if p.PhotoURL != "" {
+		parts = append(parts, "doorstep photo on file")
+	}
+	if p.OTPVerified {
+		parts = append(parts, "confirmed with the customer's delivery code")
+	}
+	return strings.Join(parts, "; ")
+}
+
+// totalLine writes a right-aligned "label  amount" row at the cursor.
+func totalLine(d *pdf.Document, f pdf.Font, label, currency string, v float64) {
+	const size = 10.0
+	d.Ensure(size * 1.6)
+	right := pdf.PageWidth - pdf.Margin
+	value := currency + " " + moneyText(v)
+	d.Text(right-160, d.Y()+size, f, size, pdf.Black, label)
+	d.Text(right-pdf.TextWidth(f, size, value), d.Y()+size, f, size, pdf.Black, value)
+	d.Space(size * 1.6)
+}
+
+func moneyText(v float64) string { return fmt.Sprintf("%.2f", v) }
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// pdfText inflates every content stream of a PDF written by pkg/pdf.
+func pdfText(t *testing.T, out []byte) string {
+	t.Helper()
+	var all strings.Builder
+	for _, part := range bytes.Split(out, []byte("stream\n"))[1:] {
+		r, err := zlib.NewReader(bytes.NewReader(part))
+		if err != nil {
+			continue
+		}
+		b, _ := io.ReadAll(r)
+		all.Write(b)
+	}
+	return all.String()
+}
+
+func TestDocumentService_DutyRosterPDF_PublishedOnlyWithBranding(t *testing.T) {
+	pharmacyID := uuid.New()
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	svc := &documentService{
+		pharmacyRepo: &mocks.MockPharmacyRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Pharmacy, error) {
+			return &models.Pharmacy{ID: id, Name: "Care Pharmacy Pvt Ltd", Phone: "01-5550100"}, nil
+		}},
+		configRepo: &mocks.MockPharmacyConfigRepository{GetByPharmacyIDFunc: func(ctx context.Context, id uuid.UUID) (*models.PharmacyConfig, error) {
+			return &models.PharmacyConfig{DisplayName: "CarePlus Baneshwor", PrimaryColor: "#ff0000"}, nil
+		}},
+		rosterRepo: &mocks.MockDutyRosterRepository{ListByPharmacyAndDateRangeFunc: func(ctx context.Context, id uuid.UUID, from, to time.Time) ([]*models.DutyRoster, error) {
+			return []*models.DutyRoster{
+				{Date: day, ShiftType: models.ShiftMorning, Status: models.RosterPublished, User: &models.User{Name: "Sita"}},
+				{Date: day, ShiftType: models.ShiftEvening, Status: models.RosterDraft, User: &models.User{Name: "Hari"}},
+			}, nil
+		}},
+		now:    time.Now,
+		logger: zap.NewNop(),
+	}
+
+	out, err := svc.DutyRosterPDF(context.Background(), pharmacyID, day, day.AddDate(0, 0, 6))
+	if err != nil {
+		t.Fatalf("DutyRosterPDF: %v", err)
+	}
+	text := pdfText(t, out)
+	for _, want := range []string{"(CarePlus Baneshwor)", "(Sita)", "(Morning)", "1.00 0.00 0.00 rg"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("PDF content missing %q", want)
+		}
+	}
+	if strings.Contains(text, "(Hari)") {
+		t.Error("draft shift printed on the noticeboard copy")
+	}
+
+	_, err = svc.DutyRosterPDF(context.Background(), pharmacyID, day, day.AddDate(0, 3, 0))
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("err = %v, want validation error for a three-month range", err)
+	}
+}
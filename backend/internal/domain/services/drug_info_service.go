@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxProductIngredients bounds the active ingredients stored for one product.
+const maxProductIngredients = 20
+
+type drugInfoService struct {
+	repo         outbound.DrugInfoRepository
+	productRepo  outbound.ProductRepository
+	pharmacyRepo outbound.PharmacyRepository
+	logger       *zap.Logger
+}
+
+func NewDrugInfoService(repo outbound.DrugInfoRepository, productRepo outbound.ProductRepository, pharmacyRepo outbound.PharmacyRepository, logger *zap.Logger) inbound.DrugInfoService {
+	return &drugInfoService{repo: repo, productRepo: productRepo, pharmacyRepo: pharmacyRepo, logger: logger}
+}
+
+// normalizeIngredient lowercases the name and collapses whitespace so "Ibuprofen " and "ibuprofen" match.
+func normalizeIngredient(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// genericIngredients splits a generic name such as "Paracetamol + Caffeine" into its ingredients.
+func genericIngredients(generic string) []string {
+	var out []string
+	for _, part := range strings.FieldsFunc(generic, func(r rune) bool { return r == '+' || r == ',' || r == '/' }) {
+		if name := normalizeIngredient(part); name != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+func (s *drugInfoService) product(ctx context.Context, pharmacyID, productID uuid.UUID) (*models.Product, error) {
+	p, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil || p == nil || p.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("product")
+	}
+	return p, nil
+}
+
+func (s *drugInfoService) SetIngredients(ctx context.Context, pharmacyID, productID uuid.UUID, ingredients []inbound.IngredientInput) ([]*models.ProductIngredient, error) {
+	if len(ingredients) > maxProductIngredients {
+		return nil, errors.ErrValidation(fmt.Sprintf("at most %d ingredients per product", maxProductIngredients))
+	}
+	if _, err := s.product(ctx, pharmacyID, productID); err != nil {
+		return nil, err
+	}
+	rows := make([]*models.ProductIngredient, 0, len(ingredients))
+	seen := make(map[string]bool, len(ingredients))
+	for _, in := range ingredients {
+		name := normalizeIngredient(in.Name)
+		if name == "" {
+			return nil, errors.ErrValidation("ingredient name is required")
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		rows = append(rows, &models.ProductIngredient{PharmacyID: pharmacyID, ProductID: productID, Ingredient: name, Strength: strings.TrimSpace(in.Strength)})
+	}
+	if err := s.repo.ReplaceIngredients(ctx, productID, rows); err != nil {
+		return nil, errors.ErrInternal("failed to save ingredients", err)
+	}
+	return rows, nil
+}
+
+func (s *drugInfoService) Ingredients(ctx context.Context, pharmacyID, productID uuid.UUID) ([]*models.ProductIngredient, error) {
+	p, err := s.product(ctx, pharmacyID, productID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.ListIngredients(ctx, []uuid.UUID{productID})
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load ingredients", err)
+	}
+	if len(rows) > 0 {
+		return rows, nil
+	}
+	for _, name := range genericIngredients(p.GenericName) {
+		rows = append(rows, &models.ProductIngredient{PharmacyID: pharmacyID, ProductID: productID, Ingredient: name})
+	}
+	return rows, nil
+}
+
+func (s *drugInfoService) AddInteraction(ctx context.Context, pharmacyID, actorID uuid.UUID, in inbound.DrugInteractionInput) (*models.DrugInteraction, error) {
+	a, b := normalizeIngredient(in.IngredientA), normalizeIngredient(in.IngredientB)
+	if a == "" || b == "" {
+		return nil, errors.ErrValidation("both ingredients are required")
+	}
+	if a == b {
+		return nil, errors.ErrValidation("an interaction needs two different ingredients")
+	}
+	if !models.ValidInteractionSeverity(in.Severity) {
+		return nil, errors.ErrValidation("severity must be minor, moderate, major or contraindicated")
+	}
+	if b < a {
+		a, b = b, a
+	}
+	rule := &models.DrugInteraction{
+		PharmacyID:  pharmacyID,
+		IngredientA: a,
+		IngredientB: b,
+		Severity:    in.Severity,
+		Description: strings.TrimSpace(in.Description),
+		CreatedBy:   actorID,
+	}
+	if err := s.repo.CreateInteraction(ctx, rule); err != nil {
+		return nil, errors.ErrInternal("failed to save interaction", err)
+	}
+	return rule, nil
+}
+
+func (s *drugInfoService) ListInteractions(ctx context.Context, pharmacyID uuid.UUID) ([]*models.DrugInteraction, error) {
+	list, err := s.repo.ListInteractions(ctx, pharmacyID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list interactions", err)
+	}
+	return list, nil
+}
+
+func (s *drugInfoService) DeleteInteraction(ctx context.Context, pharmacyID, id uuid.UUID) error {
+	ok, err := s.repo.DeleteInteraction(ctx, pharmacyID, id)
+	if err != nil {
+		return errors.ErrInternal("failed to delete interaction", err)
+	}
+	if !ok {
+		return errors.ErrNotFound("interaction")
+	}
+	return nil
+}
+
+func (s *drugInfoService) CheckProducts(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID) ([]models.ClinicalWarning, error) {
+	products := make([]*models.Product, 0, len(productIDs))
+	for _, id := range productIDs {
+		p, err := s.product(ctx, pharmacyID, id)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	return s.Check(ctx, pharmacyID, products)
+}
+
+var interactionSeverityRank = map[models.InteractionSeverity]int{
+	models.InteractionSeverityMinor:           1,
+	models.InteractionSeverityModerate:        2,
+	models.InteractionSeverityMajor:           3,
+	models.InteractionSeverityContraindicated: 4,
+}
+
+// Check finds ingredients shared by two or more products (duplicate therapy) and interaction rules between
+// ingredients of different products. A combination product is not checked against itself.
+func (s *drugInfoService) Check(ctx context.Context, pharmacyID uuid.UUID, products []*models.Product) ([]models.ClinicalWarning, error) {
+	ph, err := s.pharmacyRepo.GetByID(ctx, pharmacyID)
+	if err != nil || ph == nil {
+		return nil, errors.ErrNotFound("pharmacy")
+	}
+	if ph.BusinessType != models.BusinessTypePharmacy {
+		return nil, nil
+	}
+	// The same product on two lines is one product.
+	var uniq []*models.Product
+	seen := map[uuid.UUID]bool{}
+	for _, p := range products {
+		if p != nil && !seen[p.ID] {
+			seen[p.ID] = true
+			uniq = append(uniq, p)
+		}
+	}
+	if len(uniq) < 2 {
+		return nil, nil
+	}
+	ids := make([]uuid.UUID, len(uniq))
+	for i, p := range uniq {
+		ids[i] = p.ID
+	}
+	rows, err := s.repo.ListIngredients(ctx, ids)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load ingredients", err)
+	}
+	stored := map[uuid.UUID][]string{}
+	for _, r := range rows {
+		stored[r.ProductID] = append(stored[r.ProductID], r.Ingredient)
+	}
+	// holders lists, per ingredient, the indexes into uniq of the products containing it.
+	holders := map[string][]int{}
+	var names []string
+	for i, p := range uniq {
+		ingredients, ok := stored[p.ID]
+		if !ok {
+			ingredients = genericIngredients(p.GenericName)
+		}
+		for _, name := range ingredients {
+			if n := holders[name]; len(n) > 0 && n[len(n)-1] == i {
+				continue
+			}
+			if len(holders[name]) == 0 {
+				names = append(names, name)
+			}
+			holders[name] = append(holders[name], i)
+		}
+	}
+	sort.Strings(names)
+
+	var warnings []models.ClinicalWarning
+	for _, name := range names {
+		if idx := holders[name]; len(idx) > 1 {
+			w := clinicalWarning(models.ClinicalWarningDuplicateTherapy, models.InteractionSeverityModerate, []string{name}, uniq, idx)
+			w.Message = fmt.Sprintf("%s is in more than one product: %s", name, strings.Join(w.ProductNames, ", "))
+			warnings = append(warnings, w)
+		}
+	}
+	rules, err := s.repo.FindInteractions(ctx, pharmacyID, names)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load interactions", err)
+	}
+	for _, rule := range rules {
+		a, b := holders[rule.IngredientA], holders[rule.IngredientB]
+		if !acrossProducts(a, b) {
+			continue
+		}
+		w := clinicalWarning(models.ClinicalWarningInteraction, rule.Severity, []string{rule.IngredientA, rule.IngredientB}, uniq, mergeIndexes(a, b))
+		w.Message = fmt.Sprintf("%s interaction between %s and %s", rule.Severity, rule.IngredientA, rule.IngredientB)
+		if rule.Description != "" {
+			w.Message += ": " + rule.Description
+		}
+		warnings = append(warnings, w)
+	}
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return interactionSeverityRank[warnings[i].Severity] > interactionSeverityRank[warnings[j].Severity]
+	})
+	return warnings, nil
+}
+
+// acrossProducts reports whether some product in a differs from some product in b.
+func acrossProducts(a, b []int) bool {
+	for _, i := range a {
+		for _, j := range b {
+			if i != j {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func mergeIndexes(a, b []int) []int {
+	out := append([]int(nil), a...)
+	for _, j := range b {
+		found := false
+		for _, i := range out {
+			if i == j {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, j)
+		}
+	}
+	sort.Ints(out)
+	return out
+}
+
+func clinicalWarning(typ models.ClinicalWarningType, severity models.InteractionSeverity, ingredients []string, products []*models.Product, idx []int) models.ClinicalWarning {
+	w := models.ClinicalWarning{Type: typ, Severity: severity, Ingredients: ingredients}
+	for _, i := range idx {
+		w.ProductIDs = append(w.ProductIDs, products[i].ID)
+		w.ProductNames = append(w.ProductNames, products[i].Name)
+	}
+	return w
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func newDrugInfoFixture(businessType string, rules []*models.DrugInteraction, stored []*models.ProductIngredient) *drugInfoService {
+	return &drugInfoService{
+		repo: &mocks.MockDrugInfoRepository{
+			ListIngredientsFunc: func(ctx context.Context, productIDs []uuid.UUID) ([]*models.ProductIngredient, error) {
+				return stored, nil
+			},
+			FindInteractionsFunc: func(ctx context.Context, pharmacyID uuid.UUID, ingredients []string) ([]*models.DrugInteraction, error) {
+				in := map[string]bool{}
+				for _, i := range ingredients {
+					in[i] = true
+				}
+				var out []*models.DrugInteraction
+				for _, r := range rules {
+					if in[r.IngredientA] && in[r.IngredientB] {
+						out = append(out, r)
+					}
+				}
+				return out, nil
+			},
+		},
+		pharmacyRepo: &mocks.MockPharmacyRepository{
+			GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Pharmacy, error) {
+				return &models.Pharmacy{ID: id, BusinessType: businessType}, nil
+			},
+		},
+		logger: zap.NewNop(),
+	}
+}
+
+func TestDrugInfoService_Check_DuplicateAndInteraction(t *testing.T) {
+	pharmacyID := uuid.New()
+	panadol := &models.Product{ID: uuid.New(), Name: "Panadol", GenericName: "Paracetamol"}
+	cold := &models.Product{ID: uuid.New(), Name: "Cold Relief", GenericName: "Paracetamol + Phenylephrine"}
+	warfarin := &models.Product{ID: uuid.New(), Name: "Warf 5", GenericName: "Warfarin"}
+	aspirin := &models.Product{ID: uuid.New(), Name: "Ecosprin"}
+	stored := []*models.ProductIngredient{{ProductID: aspirin.ID, Ingredient: "aspirin"}}
+	rules := []*models.DrugInteraction{{IngredientA: "aspirin", IngredientB: "warfarin", Severity: models.InteractionSeverityMajor, Description: "bleeding risk"}}
+	svc := newDrugInfoFixture(models.BusinessTypePharmacy, rules, stored)
+
+	warnings, err := svc.Check(context.Background(), pharmacyID, []*models.Product{panadol, cold, warfarin, aspirin, panadol})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %+v", warnings)
+	}
+	if w := warnings[0]; w.Type != models.ClinicalWarningInteraction || w.Severity != models.InteractionSeverityMajor || len(w.ProductIDs) != 2 {
+		t.Errorf("expected the major interaction first, got %+v", w)
+	}
+	if w := warnings[1]; w.Type != models.ClinicalWarningDuplicateTherapy || w.Ingredients[0] != "paracetamol" || len(w.ProductIDs) != 2 {
+		t.Errorf("expected paracetamol duplicate therapy across two products, got %+v", w)
+	}
+}
+
+func TestDrugInfoService_Check_IgnoresCombinationProductAndNonPharmacy(t *testing.T) {
+	pharmacyID := uuid.New()
+	combo := &models.Product{ID: uuid.New(), Name: "Combo", GenericName: "Aspirin + Warfarin"}
+	other := &models.Product{ID: uuid.New(), Name: "Vitamin C", GenericName: "Ascorbic acid"}
+	rules := []*models.DrugInteraction{{IngredientA: "aspirin", IngredientB: "warfarin", Severity: models.InteractionSeverityMajor}}
+
+	warnings, err := newDrugInfoFixture(models.BusinessTypePharmacy, rules, nil).Check(context.Background(), pharmacyID, []*models.Product{combo, other})
+	if err != nil || len(warnings) != 0 {
+		t.Errorf("expected no warnings within one product, got %+v %v", warnings, err)
+	}
+	dup := &models.Product{ID: uuid.New(), Name: "Aspirin", GenericName: "Aspirin"}
+	warnings, err = newDrugInfoFixture(models.BusinessTypeRetail, rules, nil).Check(context.Background(), pharmacyID, []*models.Product{combo, dup})
+	if err != nil || warnings != nil {
+		t.Errorf("expected retail shops to skip checks, got %+v %v", warnings, err)
+	}
+}
+
+func TestDrugInfoService_AddInteraction_NormalizesPair(t *testing.T) {
+	var saved *models.DrugInteraction
+	svc := &drugInfoService{repo: &mocks.MockDrugInfoRepository{
+		CreateInteractionFunc: func(ctx context.Context, rule *models.DrugInteraction) error {
+			saved = rule
+			return nil
+		},
+	}, logger: zap.NewNop()}
+
+	_, err := svc.AddInteraction(context.Background(), uuid.New(), uuid.New(), inbound.DrugInteractionInput{IngredientA: " Warfarin", IngredientB: "ASPIRIN ", Severity: models.InteractionSeverityMajor})
+	if err != nil {
+		t.Fatalf("AddInteraction: %v", err)
+	}
+	if saved.IngredientA != "aspirin" || saved.IngredientB != "warfarin" {
+		t.Errorf("expected sorted lowercase pair, got %q %q", saved.IngredientA, saved.IngredientB)
+	}
+	_, err = svc.AddInteraction(context.Background(), uuid.New(), uuid.New(), inbound.DrugInteractionInput{IngredientA: "aspirin", IngredientB: "Aspirin", Severity: models.InteractionSeverityMajor})
+	if appErr, ok := err.(*pkgerrors.AppError); !ok || appErr.Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("expected validation error for the same ingredient twice, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
@@ -13,13 +14,17 @@ import (
 )
 
 type dutyRosterService struct {
-	rosterRepo outbound.DutyRosterRepository
-	userRepo   outbound.UserRepository
-	logger     *zap.Logger
+	rosterRepo          outbound.DutyRosterRepository
+	userRepo            outbound.UserRepository
+	notificationService inbound.NotificationService
+	now                 func() time.Time
+	logger              *zap.Logger
 }
 
-func NewDutyRosterService(rosterRepo outbound.DutyRosterRepository, userRepo outbound.UserRepository, logger *zap.Logger) inbound.DutyRosterService {
-	return &dutyRosterService{rosterRepo: rosterRepo, userRepo: userRepo, logger: logger}
+// NewDutyRosterService creates roster entries as drafts; staff are notified when they are published or a
+// published entry changes. notificationService may be nil.
+func NewDutyRosterService(rosterRepo outbound.DutyRosterRepository, userRepo outbound.UserRepository, notificationService inbound.NotificationService, logger *zap.Logger) inbound.DutyRosterService {
+	return &dutyRosterService{rosterRepo: rosterRepo, userRepo: userRepo, notificationService: notificationService, now: time.Now, logger: logger}
 }
 
 func (s *dutyRosterService) Create(ctx context.Context, pharmacyID uuid.UUID, userID uuid.UUID, date time.Time, shiftType models.ShiftType, notes string) (*models.DutyRoster, error) {
@@ -39,6 +44,7 @@ func (s *dutyRosterService) Create(ctx context.Context, pharmacyID uuid.UUID, us
 		Date:       time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location()),
 		ShiftType:  shiftType,
 		Notes:      notes,
+		Status:     models.RosterDraft,
 	}
 	if err := s.rosterRepo.Create(ctx, d); err != nil {
 		return nil, errors.ErrInternal("failed to create duty roster", err)
@@ -69,6 +75,7 @@ func (s *dutyRosterService) Update(ctx context.Context, pharmacyID uuid.UUID, id
 	if d.PharmacyID != pharmacyID {
 		return nil, errors.ErrNotFound("duty roster")
 	}
+	before := *d
 	if userID != nil {
 		user, err := s.userRepo.GetByID(ctx, *userID)
 		if err != nil || user == nil || user.PharmacyID != pharmacyID || user.Role != RolePharmacist {
@@ -85,9 +92,25 @@ func (s *dutyRosterService) Update(ctx context.Context, pharmacyID uuid.UUID, id
 	if notes != nil {
 		d.Notes = *notes
 	}
+	changed := d.UserID != before.UserID || !d.Date.Equal(before.Date) || d.ShiftType != before.ShiftType || d.Notes != before.Notes
+	if d.Status == models.RosterPublished && changed {
+		now := s.now()
+		d.Revision++
+		d.PublishedAt = &now
+		d.AcknowledgedAt = nil
+		d.ChangeNote = rosterChangeNote(&before, d)
+	}
 	if err := s.rosterRepo.Update(ctx, d); err != nil {
 		return nil, errors.ErrInternal("failed to update duty roster", err)
 	}
+	if d.Status == models.RosterPublished && changed {
+		if d.UserID != before.UserID {
+			s.notify(ctx, &before, "Shift removed", fmt.Sprintf("Your %s is no longer assigned to you.", describeShift(&before)))
+			s.notify(ctx, d, "New shift assigned", fmt.Sprintf("You have been assigned the %s. Please acknowledge it in your roster.", describeShift(d)))
+		} else {
+			s.notify(ctx, d, "Shift changed", d.ChangeNote+" Please acknowledge the change in your roster.")
+		}
+	}
 	return s.rosterRepo.GetByID(ctx, d.ID)
 }
 
@@ -99,5 +122,131 @@ func (s *dutyRosterService) Delete(ctx context.Context, pharmacyID uuid.UUID, id
 	if d.PharmacyID != pharmacyID {
 		return errors.ErrNotFound("duty roster")
 	}
-	return s.rosterRepo.Delete(ctx, id)
+	if err := s.rosterRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	if d.Status == models.RosterPublished {
+		s.notify(ctx, d, "Shift cancelled", fmt.Sprintf("Your %s has been cancelled.", describeShift(d)))
+	}
+	return nil
+}
+
+func (s *dutyRosterService) Publish(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.DutyRoster, error) {
+	if to.Before(from) {
+		return nil, errors.ErrValidation("to must not be before from")
+	}
+	drafts, err := s.rosterRepo.ListByStatusAndDateRange(ctx, pharmacyID, models.RosterDraft, from, to)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list draft roster", err)
+	}
+	now := s.now()
+	shifts := make(map[uuid.UUID][]*models.DutyRoster)
+	var order []uuid.UUID
+	for _, d := range drafts {
+		d.Status = models.RosterPublished
+		d.PublishedAt = &now
+		d.Revision = 1
+		d.ChangeNote = ""
+		d.AcknowledgedAt = nil
+		if err := s.rosterRepo.Update(ctx, d); err != nil {
+			return nil, errors.ErrInternal("failed to publish duty roster", err)
+		}
+		if _, ok := shifts[d.UserID]; !ok {
+			order = append(order, d.UserID)
+		}
+		shifts[d.UserID] = append(shifts[d.UserID], d)
+	}
+	// One notification per person, however many shifts they got.
+	for _, userID := range order {
+		list := shifts[userID]
+		msg := fmt.Sprintf("You have been assigned the %s.", describeShift(list[0]))
+		if len(list) > 1 {
+			first, last := list[0].Date, list[0].Date
+			for _, d := range list[1:] {
+				if d.Date.Before(first) {
+					first = d.Date
+				}
+				if d.Date.After(last) {
+					last = d.Date
+				}
+			}
+			msg = fmt.Sprintf("You have %d shifts between %s and %s.", len(list), first.Format(rosterDateLayout), last.Format(rosterDateLayout))
+		}
+		s.notify(ctx, list[0], "Duty roster published", msg+" Please acknowledge them in your roster.")
+	}
+	if drafts == nil {
+		drafts = []*models.DutyRoster{}
+	}
+	s.logger.Info("duty roster published", zap.String("pharmacy_id", pharmacyID.String()), zap.Int("entries", len(drafts)), zap.Int("staff", len(order)))
+	return drafts, nil
+}
+
+func (s *dutyRosterService) ListMine(ctx context.Context, pharmacyID, userID uuid.UUID, from, to time.Time) ([]*models.DutyRoster, error) {
+	list, err := s.rosterRepo.ListByUserAndDateRange(ctx, pharmacyID, userID, models.RosterPublished, from, to)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list duty roster", err)
+	}
+	if list == nil {
+		list = []*models.DutyRoster{}
+	}
+	return list, nil
+}
+
+func (s *dutyRosterService) Acknowledge(ctx context.Context, pharmacyID, userID, id uuid.UUID) (*models.DutyRoster, error) {
+	d, err := s.rosterRepo.GetByID(ctx, id)
+	// Drafts and other people's shifts look the same as missing ones.
+	if err != nil || d == nil || d.PharmacyID != pharmacyID || d.UserID != userID || d.Status != models.RosterPublished {
+		return nil, errors.ErrNotFound("duty roster")
+	}
+	if d.AcknowledgedAt != nil {
+		return d, nil
+	}
+	now := s.now()
+	d.AcknowledgedAt = &now
+	if err := s.rosterRepo.Update(ctx, d); err != nil {
+		return nil, errors.ErrInternal("failed to acknowledge duty roster", err)
+	}
+	return d, nil
+}
+
+func (s *dutyRosterService) ListUnacknowledged(ctx context.Context, pharmacyID uuid.UUID) ([]*models.DutyRoster, error) {
+	now := s.now()
+	// Shifts that already took place no longer need an acknowledgment.
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	list, err := s.rosterRepo.ListUnacknowledged(ctx, pharmacyID, today)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list unacknowledged roster", err)
+	}
+	if list == nil {
+		list = []*models.DutyRoster{}
+	}
+	return list, nil
+}
+
+const rosterDateLayout = "Mon 2 Jan"
+
+// describeShift reads like "morning shift on Mon 20 Oct".
+func describeShift(d *models.DutyRoster) string {
+	return fmt.Sprintf("%s shift on %s", d.ShiftType, d.Date.Format(rosterDateLayout))
+}
+
+// rosterChangeNote tells the assignee what changed on a published entry.
+func rosterChangeNote(before, after *models.DutyRoster) string {
+	if !before.Date.Equal(after.Date) || before.ShiftType != after.ShiftType {
+		return fmt.Sprintf("Your %s moved to the %s.", describeShift(before), describeShift(after))
+	}
+	if before.UserID != after.UserID {
+		return fmt.Sprintf("The %s was reassigned.", describeShift(after))
+	}
+	return fmt.Sprintf("The notes for your %s changed: %s", describeShift(after), after.Notes)
+}
+
+// notify sends an in-app (and push) roster notification to the entry's assignee. Failures are logged, never returned.
+func (s *dutyRosterService) notify(ctx context.Context, d *models.DutyRoster, title, message string) {
+	if s.notificationService == nil {
+		return
+	}
+	if _, err := s.notificationService.Create(ctx, d.PharmacyID, d.UserID, title, message, "roster"); err != nil {
+		s.logger.Warn("failed to send roster notification", zap.String("roster_id", d.ID.String()), zap.Error(err))
+	}
 }
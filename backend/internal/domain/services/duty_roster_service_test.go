@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type rosterFixture struct {
+	pharmacyID, ram, sita uuid.UUID
+	entries               map[uuid.UUID]*models.DutyRoster
+	notifier              *recordingNotifier
+	now                   time.Time
+	svc                   *dutyRosterService
+}
+
+func newRosterFixture() *rosterFixture {
+	f := &rosterFixture{
+		pharmacyID: uuid.New(), ram: uuid.New(), sita: uuid.New(),
+		entries:  make(map[uuid.UUID]*models.DutyRoster),
+		notifier: &recordingNotifier{},
+		now:      time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC),
+	}
+	inRange := func(d *models.DutyRoster, from, to time.Time) bool {
+		return !d.Date.Before(from) && !d.Date.After(to)
+	}
+	repo := &mocks.MockDutyRosterRepository{
+		CreateFunc: func(ctx context.Context, d *models.DutyRoster) error {
+			d.ID = uuid.New()
+			f.entries[d.ID] = d
+			return nil
+		},
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.DutyRoster, error) {
+			return f.entries[id], nil
+		},
+		ListByStatusAndDateRangeFunc: func(ctx context.Context, pharmacyID uuid.UUID, status models.RosterStatus, from, to time.Time) ([]*models.DutyRoster, error) {
+			var out []*models.DutyRoster
+			for _, d := range f.entries {
+				if d.PharmacyID == pharmacyID && d.Status == status && inRange(d, from, to) {
+					out = append(out, d)
+				}
+			}
+			return out, nil
+		},
+		ListByUserAndDateRangeFunc: func(ctx context.Context, pharmacyID, userID uuid.UUID, status models.RosterStatus, from, to time.Time) ([]*models.DutyRoster, error) {
+			var out []*models.DutyRoster
+			for _, d := range f.entries {
+				if d.PharmacyID == pharmacyID && d.UserID == userID && d.Status == status && inRange(d, from, to) {
+					out = append(out, d)
+				}
+			}
+			return out, nil
+		},
+		ListUnacknowledgedFunc: func(ctx context.Context, pharmacyID uuid.UUID, from time.Time) ([]*models.DutyRoster, error) {
+			var out []*models.DutyRoster
+			for _, d := range f.entries {
+				if d.PharmacyID == pharmacyID && d.Status == models.RosterPublished && d.AcknowledgedAt == nil && !d.Date.Before(from) {
+					out = append(out, d)
+				}
+			}
+			return out, nil
+		},
+		DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+			delete(f.entries, id)
+			return nil
+		},
+	}
+	users := &mocks.MockUserRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.User, error) {
+			if id == f.ram || id == f.sita {
+				return &models.User{ID: id, PharmacyID: f.pharmacyID, Role: models.RolePharmacist, IsActive: true}, nil
+			}
+			return nil, nil
+		},
+	}
+	f.svc = NewDutyRosterService(repo, users, f.notifier, zap.NewNop()).(*dutyRosterService)
+	f.svc.now = func() time.Time { return f.now }
+	return f
+}
+
+func (f *rosterFixture) add(t *testing.T, userID uuid.UUID, day int, shift models.ShiftType) *models.DutyRoster {
+	t.Helper()
+	d, err := f.svc.Create(context.Background(), f.pharmacyID, userID, time.Date(2026, 10, day, 0, 0, 0, 0, time.UTC), shift, "")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return d
+}
+
+func TestDutyRosterService_Publish_NotifiesEachPharmacistOnce(t *testing.T) {
+	f := newRosterFixture()
+	ctx := context.Background()
+	a := f.add(t, f.ram, 20, models.ShiftMorning)
+	f.add(t, f.ram, 21, models.ShiftEvening)
+	f.add(t, f.sita, 20, models.ShiftFull)
+	later := f.add(t, f.sita, 30, models.ShiftFull)
+
+	if a.Status != models.RosterDraft || len(f.notifier.sent) != 0 {
+		t.Fatalf("expected silent draft, got status=%s notifications=%d", a.Status, len(f.notifier.sent))
+	}
+	if mine, _ := f.svc.ListMine(ctx, f.pharmacyID, f.ram, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)); len(mine) != 0 {
+		t.Errorf("drafts must not be visible to staff, got %d", len(mine))
+	}
+
+	published, err := f.svc.Publish(ctx, f.pharmacyID, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC))
+	if err != nil || len(published) != 3 {
+		t.Fatalf("Publish = %d, %v", len(published), err)
+	}
+	if later.Status != models.RosterDraft {
+		t.Errorf("entry outside the range must stay draft")
+	}
+	if len(f.notifier.sent) != 2 {
+		t.Fatalf("expected one notification per pharmacist, got %d", len(f.notifier.sent))
+	}
+	for _, n := range f.notifier.sent {
+		if n.UserID == f.ram && !strings.Contains(n.Message, "2 shifts between Tue 20 Oct and Wed 21 Oct") {
+			t.Errorf("unexpected message for ram: %q", n.Message)
+		}
+		if n.Type != "roster" {
+			t.Errorf("expected roster notification type, got %q", n.Type)
+		}
+	}
+	if a.Revision != 1 || a.PublishedAt == nil {
+		t.Errorf("expected revision 1 with publish time, got %+v", a)
+	}
+	unacked, _ := f.svc.ListUnacknowledged(ctx, f.pharmacyID)
+	if len(unacked) != 3 {
+		t.Errorf("expected 3 unacknowledged entries, got %d", len(unacked))
+	}
+}
+
+func TestDutyRosterService_Update_PublishedChangeResetsAcknowledgment(t *testing.T) {
+	f := newRosterFixture()
+	ctx := context.Background()
+	d := f.add(t, f.ram, 20, models.ShiftMorning)
+	_, _ = f.svc.Publish(ctx, f.pharmacyID, d.Date, d.Date)
+
+	if _, err := f.svc.Acknowledge(ctx, f.pharmacyID, f.sita, d.ID); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeNotFound {
+		t.Errorf("expected not found acknowledging someone else's shift, got %v", err)
+	}
+	if _, err := f.svc.Acknowledge(ctx, f.pharmacyID, f.ram, d.ID); err != nil || d.AcknowledgedAt == nil {
+		t.Fatalf("Acknowledge: %v", err)
+	}
+	if unacked, _ := f.svc.ListUnacknowledged(ctx, f.pharmacyID); len(unacked) != 0 {
+		t.Errorf("expected nothing pending after acknowledgment, got %d", len(unacked))
+	}
+
+	f.notifier.sent = nil
+	evening := models.ShiftEvening
+	if _, err := f.svc.Update(ctx, f.pharmacyID, d.ID, nil, nil, &evening, nil); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if d.AcknowledgedAt != nil || d.Revision != 2 || d.ChangeNote != "Your morning shift on Tue 20 Oct moved to the evening shift on Tue 20 Oct." {
+		t.Errorf("expected a new unacknowledged revision, got %+v", d)
+	}
+	if len(f.notifier.sent) != 1 || f.notifier.sent[0].UserID != f.ram || f.notifier.sent[0].Title != "Shift changed" {
+		t.Errorf("expected ram to be told about the change, got %+v", f.notifier.sent)
+	}
+
+	// Reassigning tells both the old and the new pharmacist.
+	f.notifier.sent = nil
+	_, _ = f.svc.Update(ctx, f.pharmacyID, d.ID, &f.sita, nil, nil, nil)
+	if len(f.notifier.sent) != 2 || f.notifier.sent[0].UserID != f.ram || f.notifier.sent[1].UserID != f.sita {
+		t.Errorf("expected removal and assignment notices, got %+v", f.notifier.sent)
+	}
+}
+
+func TestDutyRosterService_Update_DraftIsSilent(t *testing.T) {
+	f := newRosterFixture()
+	ctx := context.Background()
+	d := f.add(t, f.ram, 20, models.ShiftMorning)
+	full := models.ShiftFull
+	_, _ = f.svc.Update(ctx, f.pharmacyID, d.ID, nil, nil, &full, nil)
+	if err := f.svc.Delete(ctx, f.pharmacyID, d.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if len(f.notifier.sent) != 0 || d.Revision != 0 {
+		t.Errorf("draft edits must not notify, got %d notifications", len(f.notifier.sent))
+	}
+}
+
+func TestDutyRosterService_ListUnacknowledged_SkipsPastShifts(t *testing.T) {
+	f := newRosterFixture()
+	ctx := context.Background()
+	past := f.add(t, f.ram, 10, models.ShiftMorning)
+	f.add(t, f.ram, 16, models.ShiftMorning)
+	_, _ = f.svc.Publish(ctx, f.pharmacyID, past.Date, time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC))
+	unacked, err := f.svc.ListUnacknowledged(ctx, f.pharmacyID)
+	if err != nil || len(unacked) != 1 || unacked[0].ID == past.ID {
+		t.Errorf("expected only the upcoming shift, got %d, %v", len(unacked), err)
+	}
+}
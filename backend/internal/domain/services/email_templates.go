@@ -0,0 +1,340 @@
+package services
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+// emailTemplate is one transactional email: subject and plain-text body use text/template, the HTML body
+// uses html/template so customer-supplied values are escaped.
+type emailTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+func newEmailTemplate(name, subject, text, html string) *emailTemplate {
+	return newLayoutEmailTemplate(name, emailHTMLLayout, subject, text, html)
+}
+
+func newLayoutEmailTemplate(name, layout, subject, text, html string) *emailTemplate {
+	return &emailTemplate{
+		subject: texttemplate.Must(texttemplate.New(name + "_subject").Parse(subject)),
+		text:    texttemplate.Must(texttemplate.New(name + "_text").Funcs(emailFuncs).Parse(text)),
+		html:    htmltemplate.Must(htmltemplate.New(name + "_html").Funcs(emailFuncs).Parse(layout + html)),
+	}
+}
+
+// localizedEmail is one email in each language it is written in, keyed by language code. "en" is the fallback.
+type localizedEmail map[string]*emailTemplate
+
+func (l localizedEmail) pick(lang string) *emailTemplate {
+	if t, ok := l[lang]; ok {
+		return t
+	}
+	return l["en"]
+}
+
+func (t *emailTemplate) render(data any) (subject, text, html string, err error) {
+	var s, tx, h bytes.Buffer
+	if err = t.subject.Execute(&s, data); err != nil {
+		return
+	}
+	if err = t.text.Execute(&tx, data); err != nil {
+		return
+	}
+	if err = t.html.ExecuteTemplate(&h, "layout", data); err != nil {
+		return
+	}
+	return s.String(), tx.String(), h.String(), nil
+}
+
+var emailFuncs = map[string]any{
+	"money": formatMoney,
+}
+
+// emailHTMLLayout wraps every HTML body; each template defines "content".
+const emailHTMLLayout = `{{define "layout"}}<!DOCTYPE html>
+<html><body style="font-family:Arial,sans-serif;color:#222;max-width:600px;margin:0 auto;padding:16px">
+<h2 style="color:#0f766e">{{.PharmacyName}}</h2>
+{{template "content" .}}
+<p style="color:#888;font-size:12px;margin-top:32px">This is an automated message from {{.PharmacyName}}.</p>
+</body></html>{{end}}`
+
+var orderConfirmationEmail = newEmailTemplate("order_confirmation",
+	`Order {{.Order.OrderNumber}} received – {{.PharmacyName}}`,
+	`Hi {{.Name}},
+
+Thank you for your order {{.Order.OrderNumber}}. We will let you know as soon as it is ready.
+
+{{range .Order.Items}}- {{if .Product}}{{.Product.Name}}{{else}}Item{{end}} x{{.Quantity}}: {{money $.Order.Currency .TotalPrice}}
+{{end}}
+Total: {{money .Order.Currency .Order.TotalAmount}}
+{{if .OrderURL}}
+Track your order: {{.OrderURL}}
+{{end}}
+{{.PharmacyName}}
+`,
+	`{{define "content"}}<p>Hi {{.Name}},</p>
+<p>Thank you for your order <strong>{{.Order.OrderNumber}}</strong>. We will let you know as soon as it is ready.</p>
+<table cellpadding="6" style="border-collapse:collapse;width:100%">
+{{range .Order.Items}}<tr><td>{{if .Product}}{{.Product.Name}}{{else}}Item{{end}}</td><td>x{{.Quantity}}</td><td align="right">{{money $.Order.Currency .TotalPrice}}</td></tr>
+{{end}}<tr><td colspan="2"><strong>Total</strong></td><td align="right"><strong>{{money .Order.Currency .Order.TotalAmount}}</strong></td></tr>
+</table>
+{{if .OrderURL}}<p><a href="{{.OrderURL}}">Track your order</a></p>{{end}}{{end}}`)
+
+var orderStatusEmail = newEmailTemplate("order_status",
+	`Order {{.Order.OrderNumber}} is {{.Status}} – {{.PharmacyName}}`,
+	`Hi {{.Name}},
+
+Your order {{.Order.OrderNumber}} is now {{.Status}}.
+{{if .StatusNote}}
+{{.StatusNote}}
+{{end}}{{if .OrderURL}}
+Order details: {{.OrderURL}}
+{{end}}
+{{.PharmacyName}}
+`,
+	`{{define "content"}}<p>Hi {{.Name}},</p>
+<p>Your order <strong>{{.Order.OrderNumber}}</strong> is now <strong>{{.Status}}</strong>.</p>
+{{if .StatusNote}}<p>{{.StatusNote}}</p>{{end}}
+{{if .OrderURL}}<p><a href="{{.OrderURL}}">Order details</a></p>{{end}}{{end}}`)
+
+var invoiceIssuedEmail = newEmailTemplate("invoice_issued",
+	`Invoice {{.Invoice.InvoiceNumber}} – {{.PharmacyName}}`,
+	`Hi {{.Name}},
+
+Invoice {{.Invoice.InvoiceNumber}} for order {{.Order.OrderNumber}} has been issued.
+
+Amount: {{money .Order.Currency .Order.TotalAmount}}
+{{if .Order.TaxAmount}}Includes tax: {{money .Order.Currency .Order.TaxAmount}}
+{{end}}
+{{.PharmacyName}}
+`,
+	`{{define "content"}}<p>Hi {{.Name}},</p>
+<p>Invoice <strong>{{.Invoice.InvoiceNumber}}</strong> for order {{.Order.OrderNumber}} has been issued.</p>
+<p>Amount: <strong>{{money .Order.Currency .Order.TotalAmount}}</strong>{{if .Order.TaxAmount}}<br>Includes tax: {{money .Order.Currency .Order.TaxAmount}}{{end}}</p>{{end}}`)
+
+var passwordResetEmail = newEmailTemplate("password_reset",
+	`Reset your {{.PharmacyName}} password`,
+	`Hi {{.Name}},
+
+We received a request to reset your password. Open the link below to choose a new one:
+
+{{.ResetURL}}
+
+The link expires at {{.ExpiresAt}}. If you did not ask for this, you can ignore this email.
+
+{{.PharmacyName}}
+`,
+	`{{define "content"}}<p>Hi {{.Name}},</p>
+<p>We received a request to reset your password.</p>
+<p><a href="{{.ResetURL}}" style="background:#0f766e;color:#fff;padding:10px 16px;text-decoration:none;border-radius:4px">Choose a new password</a></p>
+<p>The link expires at {{.ExpiresAt}}. If you did not ask for this, you can ignore this email.</p>{{end}}`)
+
+var loginCodeEmail = newEmailTemplate("login_code",
+	`Your {{.PharmacyName}} sign-in code`,
+	`Hi {{.Name}},
+
+Your sign-in code is {{.Code}}
+
+It expires at {{.ExpiresAt}}. If you did not just sign in, change your password.
+
+{{.PharmacyName}}
+`,
+	`{{define "content"}}<p>Hi {{.Name}},</p>
+<p>Your sign-in code is</p>
+<p style="font-size:24px;letter-spacing:4px"><strong>{{.Code}}</strong></p>
+<p>It expires at {{.ExpiresAt}}. If you did not just sign in, change your password.</p>{{end}}`)
+
+var staffAccountEmail = newEmailTemplate("staff_account",
+	`Your {{.PharmacyName}} account is ready`,
+	`Hi {{.Name}},
+
+An account has been created for you at {{.PharmacyName}} with the role {{.Role}}.
+
+Sign in with this email address ({{.Email}}) at:
+{{.LoginURL}}
+
+{{.PharmacyName}}
+`,
+	`{{define "content"}}<p>Hi {{.Name}},</p>
+<p>An account has been created for you at {{.PharmacyName}} with the role <strong>{{.Role}}</strong>.</p>
+<p>Sign in with this email address ({{.Email}}):</p>
+<p><a href="{{.LoginURL}}">{{.LoginURL}}</a></p>{{end}}`)
+
+// Nepali variants.
+
+const emailHTMLLayoutNe = `{{define "layout"}}<!DOCTYPE html>
+<html lang="ne"><body style="font-family:Arial,sans-serif;color:#222;max-width:600px;margin:0 auto;padding:16px">
+<h2 style="color:#0f766e">{{.PharmacyName}}</h2>
+{{template "content" .}}
+<p style="color:#888;font-size:12px;margin-top:32px">यो {{.PharmacyName}} बाट पठाइएको स्वचालित सन्देश हो।</p>
+</body></html>{{end}}`
+
+var orderConfirmationEmailNe = newLayoutEmailTemplate("order_confirmation_ne", emailHTMLLayoutNe,
+	`अर्डर {{.Order.OrderNumber}} प्राप्त भयो – {{.PharmacyName}}`,
+	`नमस्ते {{.Name}},
+
+तपाईंको अर्डर {{.Order.OrderNumber}} का लागि धन्यवाद। अर्डर तयार हुनेबित्तिकै हामी जानकारी दिनेछौं।
+
+{{range .Order.Items}}- {{if .Product}}{{.Product.Name}}{{else}}सामान{{end}} x{{.Quantity}}: {{money $.Order.Currency .TotalPrice}}
+{{end}}
+जम्मा: {{money .Order.Currency .Order.TotalAmount}}
+{{if .OrderURL}}
+अर्डर ट्र्याक गर्नुहोस्: {{.OrderURL}}
+{{end}}
+{{.PharmacyName}}
+`,
+	`{{define "content"}}<p>नमस्ते {{.Name}},</p>
+<p>तपाईंको अर्डर <strong>{{.Order.OrderNumber}}</strong> का लागि धन्यवाद। अर्डर तयार हुनेबित्तिकै हामी जानकारी दिनेछौं।</p>
+<table cellpadding="6" style="border-collapse:collapse;width:100%">
+{{range .Order.Items}}<tr><td>{{if .Product}}{{.Product.Name}}{{else}}सामान{{end}}</td><td>x{{.Quantity}}</td><td align="right">{{money $.Order.Currency .TotalPrice}}</td></tr>
+{{end}}<tr><td colspan="2"><strong>जम्मा</strong></td><td align="right"><strong>{{money .Order.Currency .Order.TotalAmount}}</strong></td></tr>
+</table>
+{{if .OrderURL}}<p><a href="{{.OrderURL}}">अर्डर ट्र्याक गर्नुहोस्</a></p>{{end}}{{end}}`)
+
+var orderStatusEmailNe = newLayoutEmailTemplate("order_status_ne", emailHTMLLayoutNe,
+	`अर्डर {{.Order.OrderNumber}}: {{.Status}} – {{.PharmacyName}}`,
+	`नमस्ते {{.Name}},
+
+तपाईंको अर्डर {{.Order.OrderNumber}} को स्थिति अब "{{.Status}}" छ।
+{{if .StatusNote}}
+{{.StatusNote}}
+{{end}}{{if .OrderURL}}
+अर्डर विवरण: {{.OrderURL}}
+{{end}}
+{{.PharmacyName}}
+`,
+	`{{define "content"}}<p>नमस्ते {{.Name}},</p>
+<p>तपाईंको अर्डर <strong>{{.Order.OrderNumber}}</strong> को स्थिति अब <strong>{{.Status}}</strong> छ।</p>
+{{if .StatusNote}}<p>{{.StatusNote}}</p>{{end}}
+{{if .OrderURL}}<p><a href="{{.OrderURL}}">अर्डर विवरण</a></p>{{end}}{{end}}`)
+
+var invoiceIssuedEmailNe = newLayoutEmailTemplate("invoice_issued_ne", emailHTMLLayoutNe,
+	`बिल {{.Invoice.InvoiceNumber}} – {{.PharmacyName}}`,
+	`नमस्ते {{.Name}},
+
+अर्डर {{.Order.OrderNumber}} को बिल {{.Invoice.InvoiceNumber}} जारी गरिएको छ।
+
+रकम: {{money .Order.Currency .Order.TotalAmount}}
+{{if .Order.TaxAmount}}कर सहित: {{money .Order.Currency .Order.TaxAmount}}
+{{end}}
+{{.PharmacyName}}
+`,
+	`{{define "content"}}<p>नमस्ते {{.Name}},</p>
+<p>अर्डर {{.Order.OrderNumber}} को बिल <strong>{{.Invoice.InvoiceNumber}}</strong> जारी गरिएको छ।</p>
+<p>रकम: <strong>{{money .Order.Currency .Order.TotalAmount}}</strong>{{if .Order.TaxAmount}}<br>कर सहित: {{money .Order.Currency .Order.TaxAmount}}{{end}}</p>{{end}}`)
+
+var passwordResetEmailNe = newLayoutEmailTemplate("password_reset_ne", emailHTMLLayoutNe,
+	`{{.PharmacyName}} पासवर्ड रिसेट गर्नुहोस्`,
+	`नमस्ते {{.Name}},
+
+तपाईंको पासवर्ड रिसेट गर्ने अनुरोध प्राप्त भयो। नयाँ पासवर्ड राख्न तलको लिङ्क खोल्नुहोस्:
+
+{{.ResetURL}}
+
+यो लिङ्क {{.ExpiresAt}} मा समाप्त हुन्छ। तपाईंले अनुरोध गर्नुभएको होइन भने यो इमेललाई बेवास्ता गर्नुहोस्।
+
+{{.PharmacyName}}
+`,
+	`{{define "content"}}<p>नमस्ते {{.Name}},</p>
+<p>तपाईंको पासवर्ड रिसेट गर्ने अनुरोध प्राप्त भयो।</p>
+<p><a href="{{.ResetURL}}" style="background:#0f766e;color:#fff;padding:10px 16px;text-decoration:none;border-radius:4px">नयाँ पासवर्ड राख्नुहोस्</a></p>
+<p>यो लिङ्क {{.ExpiresAt}} मा समाप्त हुन्छ। तपाईंले अनुरोध गर्नुभएको होइन भने यो इमेललाई बेवास्ता गर्नुहोस्।</p>{{end}}`)
+
+var loginCodeEmailNe = newLayoutEmailTemplate("login_code_ne", emailHTMLLayoutNe,
+	`तपाईंको {{.PharmacyName}} साइन इन कोड`,
+	`नमस्ते {{.Name}},
+
+तपाईंको साइन इन कोड {{.Code}} हो।
+
+यो कोड {{.ExpiresAt}} मा समाप्त हुन्छ। तपाईंले भर्खरै साइन इन गर्नुभएको होइन भने पासवर्ड परिवर्तन गर्नुहोस्।
+
+{{.PharmacyName}}
+`,
+	`{{define "content"}}<p>नमस्ते {{.Name}},</p>
+<p>तपाईंको साइन इन कोड</p>
+<p style="font-size:24px;letter-spacing:4px"><strong>{{.Code}}</strong></p>
+<p>यो कोड {{.ExpiresAt}} मा समाप्त हुन्छ। तपाईंले भर्खरै साइन इन गर्नुभएको होइन भने पासवर्ड परिवर्तन गर्नुहोस्।</p>{{end}}`)
+
+var staffAccountEmailNe = newLayoutEmailTemplate("staff_account_ne", emailHTMLLayoutNe,
+	`तपाईंको {{.PharmacyName}} खाता तयार छ`,
+	`नमस्ते {{.Name}},
+
+{{.PharmacyName}} मा तपाईंका लागि {{.Role}} भूमिकासहित खाता बनाइएको छ।
+
+यो इमेल ({{.Email}}) प्रयोग गरी यहाँ साइन इन गर्नुहोस्:
+{{.LoginURL}}
+
+{{.PharmacyName}}
+`,
+	`{{define "content"}}<p>नमस्ते {{.Name}},</p>
+<p>{{.PharmacyName}} मा तपाईंका लागि <strong>{{.Role}}</strong> भूमिकासहित खाता बनाइएको छ।</p>
+<p>यो इमेल ({{.Email}}) प्रयोग गरी साइन इन गर्नुहोस्:</p>
+<p><a href="{{.LoginURL}}">{{.LoginURL}}</a></p>{{end}}`)
+
+// campaignEmail wraps a campaign message written by the pharmacy; only the greeting is translated.
+var campaignEmail = newEmailTemplate("campaign",
+	`{{.Subject}}`,
+	`Hi {{.Name}},
+
+{{.Message}}
+
+{{.PharmacyName}}
+`,
+	`{{define "content"}}<p>Hi {{.Name}},</p>
+{{range .Paragraphs}}<p>{{.}}</p>
+{{end}}{{end}}`)
+
+var campaignEmailNe = newLayoutEmailTemplate("campaign_ne", emailHTMLLayoutNe,
+	`{{.Subject}}`,
+	`नमस्ते {{.Name}},
+
+{{.Message}}
+
+{{.PharmacyName}}
+`,
+	`{{define "content"}}<p>नमस्ते {{.Name}},</p>
+{{range .Paragraphs}}<p>{{.}}</p>
+{{end}}{{end}}`)
+
+var warrantyClaimEmail = newEmailTemplate("warranty_claim",
+	`Warranty claim update – {{.PharmacyName}}`,
+	`Hi {{.Name}},
+
+{{.Message}}
+{{if .Note}}
+Note from the pharmacy: {{.Note}}
+{{end}}
+{{.PharmacyName}}
+`,
+	`{{define "content"}}<p>Hi {{.Name}},</p>
+<p>{{.Message}}</p>
+{{if .Note}}<p>Note from the pharmacy: {{.Note}}</p>{{end}}{{end}}`)
+
+var warrantyClaimEmailNe = newLayoutEmailTemplate("warranty_claim_ne", emailHTMLLayoutNe,
+	`वारेन्टी दाबीको जानकारी – {{.PharmacyName}}`,
+	`नमस्ते {{.Name}},
+
+{{.Message}}
+{{if .Note}}
+फार्मेसीको टिप्पणी: {{.Note}}
+{{end}}
+{{.PharmacyName}}
+`,
+	`{{define "content"}}<p>नमस्ते {{.Name}},</p>
+<p>{{.Message}}</p>
+{{if .Note}}<p>फार्मेसीको टिप्पणी: {{.Note}}</p>{{end}}{{end}}`)
+
+var (
+	campaignEmails          = localizedEmail{"en": campaignEmail, "ne": campaignEmailNe}
+	orderConfirmationEmails = localizedEmail{"en": orderConfirmationEmail, "ne": orderConfirmationEmailNe}
+	orderStatusEmails       = localizedEmail{"en": orderStatusEmail, "ne": orderStatusEmailNe}
+	invoiceIssuedEmails     = localizedEmail{"en": invoiceIssuedEmail, "ne": invoiceIssuedEmailNe}
+	passwordResetEmails     = localizedEmail{"en": passwordResetEmail, "ne": passwordResetEmailNe}
+	loginCodeEmails         = localizedEmail{"en": loginCodeEmail, "ne": loginCodeEmailNe}
+	staffAccountEmails      = localizedEmail{"en": staffAccountEmail, "ne": staffAccountEmailNe}
+	warrantyClaimEmails     = localizedEmail{"en": warrantyClaimEmail, "ne": warrantyClaimEmailNe}
+)
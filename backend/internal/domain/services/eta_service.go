@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	etaDefaultPrepMinutes     = 15 // until a pharmacy has etaMinSamples orders to average
+	etaDefaultDeliveryMinutes = 30
+	etaMinSamples             = 5
+	etaHistoryDays            = 30
+	etaStatsTTL               = 10 * time.Minute
+	etaOpenOrdersLimit        = 500
+	etaShiftChangeHour        = 14 // morning shifts end and evening shifts start
+
+	// A spike is at least etaSpikeMinOrders orders in the last etaSpikeWindow and etaSpikeFactor times the
+	// average of the same window on the previous etaSpikeDays days.
+	etaSpikeWindow    = 15 * time.Minute
+	etaSpikeDays      = 7
+	etaSpikeFactor    = 2.0
+	etaSpikeMinOrders = 5
+	etaSpikeCooldown  = 5 * time.Minute // between recalculations of every open order
+)
+
+type cachedETAStats struct {
+	prep, delivery time.Duration
+	expires        time.Time
+}
+
+type etaService struct {
+	repo         outbound.ETARepository
+	orderRepo    outbound.OrderRepository
+	deliveryRepo outbound.DeliveryRepository
+	rosterRepo   outbound.DutyRosterRepository
+	now          func() time.Time
+	logger       *zap.Logger
+
+	mu         sync.Mutex
+	stats      map[uuid.UUID]cachedETAStats
+	recomputed map[uuid.UUID]time.Time // last spike recalculation per pharmacy
+}
+
+func NewETAService(repo outbound.ETARepository, orderRepo outbound.OrderRepository, deliveryRepo outbound.DeliveryRepository, rosterRepo outbound.DutyRosterRepository, logger *zap.Logger) inbound.ETAService {
+	return &etaService{
+		repo: repo, orderRepo: orderRepo, deliveryRepo: deliveryRepo, rosterRepo: rosterRepo, now: time.Now, logger: logger,
+		stats: map[uuid.UUID]cachedETAStats{}, recomputed: map[uuid.UUID]time.Time{},
+	}
+}
+
+// etaSnapshot is a pharmacy's load at one moment. Every staff member on duty prepares one order at a time, so
+// the order at queue position p (0 = next) is ready after p/staff+1 preparation times.
+type etaSnapshot struct {
+	now            time.Time
+	prep, delivery time.Duration
+	staff          int
+	open           []*models.Order
+	busy           bool
+}
+
+func (sn *etaSnapshot) readyAt(position int) time.Time {
+	return sn.now.Add(time.Duration(position/sn.staff+1) * sn.prep)
+}
+
+func (sn *etaSnapshot) estimate(position int, delivery bool) inbound.ETAEstimate {
+	ready := sn.readyAt(position)
+	e := inbound.ETAEstimate{
+		ReadyAt: &ready, ReadyInMinutes: minutesUntil(sn.now, ready), QueueDepth: position,
+		StaffOnDuty: sn.staff, PrepMinutes: math.Round(sn.prep.Minutes()*10) / 10, Busy: sn.busy,
+	}
+	if delivery {
+		sn.setDelivery(&e, ready.Add(sn.delivery))
+	}
+	return e
+}
+
+func (sn *etaSnapshot) setDelivery(e *inbound.ETAEstimate, at time.Time) {
+	m := minutesUntil(sn.now, at)
+	e.DeliveryAt, e.DeliveryInMinutes = &at, &m
+}
+
+func (s *etaService) Estimate(ctx context.Context, pharmacyID uuid.UUID, delivery bool) (*inbound.ETAEstimate, error) {
+	sn, err := s.snapshot(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	e := sn.estimate(len(sn.open), delivery)
+	return &e, nil
+}
+
+func (s *etaService) ForOrder(ctx context.Context, pharmacyID, orderID uuid.UUID, customerUserID *uuid.UUID) (*inbound.OrderETA, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil || order == nil || order.PharmacyID != pharmacyID || (customerUserID != nil && order.CreatedBy != *customerUserID) {
+		return nil, errors.ErrNotFound("order")
+	}
+	out := &inbound.OrderETA{OrderID: order.ID, OrderNumber: order.OrderNumber, Status: order.Status}
+	if order.Status == models.OrderStatusCompleted || order.Status == models.OrderStatusCancelled {
+		out.Done = true
+		return out, nil
+	}
+	sn, err := s.snapshot(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	isDelivery := strings.TrimSpace(order.DeliveryAddress) != ""
+	if order.Status != models.OrderStatusReady {
+		out.ETAEstimate = sn.estimate(queuePosition(sn.open, order.ID), isDelivery)
+		return out, nil
+	}
+	out.ETAEstimate = inbound.ETAEstimate{StaffOnDuty: sn.staff, PrepMinutes: math.Round(sn.prep.Minutes()*10) / 10, Busy: sn.busy}
+	if !isDelivery {
+		return out, nil
+	}
+	d, err := s.deliveryRepo.GetByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load delivery", err)
+	}
+	switch {
+	case d != nil && d.DeliveredAt != nil:
+		out.Done = true
+	case d != nil && d.ETA != nil && d.ETA.After(sn.now):
+		sn.setDelivery(&out.ETAEstimate, *d.ETA) // planned into a rider's route
+	case d != nil && (d.OutForDeliveryAt != nil || d.DispatchedAt != nil):
+		left := d.DispatchedAt
+		if d.OutForDeliveryAt != nil {
+			left = d.OutForDeliveryAt
+		}
+		at := left.Add(sn.delivery)
+		if at.Before(sn.now) {
+			at = sn.now
+		}
+		sn.setDelivery(&out.ETAEstimate, at)
+	default:
+		sn.setDelivery(&out.ETAEstimate, sn.now.Add(sn.delivery))
+	}
+	return out, nil
+}
+
+func (s *etaService) OnOrderCreated(ctx context.Context, order *models.Order) error {
+	sn, err := s.snapshot(ctx, order.PharmacyID)
+	if err != nil {
+		return err
+	}
+	position := queuePosition(sn.open, order.ID)
+	if position == len(sn.open) {
+		return nil // no longer open (e.g. a counter sale completed at once)
+	}
+	if err := s.stamp(ctx, sn, order, position); err != nil {
+		return err
+	}
+	if !sn.busy || !s.claimRecalculation(order.PharmacyID, sn.now) {
+		return nil
+	}
+	for i, o := range sn.open {
+		if o.ID == order.ID {
+			continue
+		}
+		if err := s.stamp(ctx, sn, o, i); err != nil {
+			s.logger.Warn("failed to update order ETA", zap.String("order_id", o.ID.String()), zap.Error(err))
+		}
+	}
+	s.logger.Info("order volume spike: recalculated ETAs", zap.String("pharmacy_id", order.PharmacyID.String()), zap.Int("orders", len(sn.open)))
+	return nil
+}
+
+func (s *etaService) stamp(ctx context.Context, sn *etaSnapshot, o *models.Order, position int) error {
+	e := sn.estimate(position, strings.TrimSpace(o.DeliveryAddress) != "")
+	return s.repo.SetEstimates(ctx, o.ID, e.ReadyAt, e.DeliveryAt)
+}
+
+// claimRecalculation reports whether the pharmacy's open orders may be recalculated now, at most once per
+// etaSpikeCooldown.
+func (s *etaService) claimRecalculation(pharmacyID uuid.UUID, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if last, ok := s.recomputed[pharmacyID]; ok && now.Sub(last) < etaSpikeCooldown {
+		return false
+	}
+	s.recomputed[pharmacyID] = now
+	return true
+}
+
+func (s *etaService) snapshot(ctx context.Context, pharmacyID uuid.UUID) (*etaSnapshot, error) {
+	now := s.now()
+	open, err := s.repo.OpenOrders(ctx, pharmacyID, etaOpenOrdersLimit)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load open orders", err)
+	}
+	sn := &etaSnapshot{now: now, open: open, staff: s.staffOnDuty(ctx, pharmacyID, now)}
+	sn.prep, sn.delivery = s.handlingTimes(ctx, pharmacyID, now)
+	recent, previous, err := s.repo.OrderVolume(ctx, pharmacyID, now, etaSpikeWindow, etaSpikeDays)
+	if err != nil {
+		s.logger.Warn("failed to load order volume", zap.String("pharmacy_id", pharmacyID.String()), zap.Error(err))
+	} else {
+		sn.busy = recent >= etaSpikeMinOrders && float64(recent) >= etaSpikeFactor*float64(previous)/etaSpikeDays
+	}
+	return sn, nil
+}
+
+// handlingTimes returns the average preparation and delivery times, cached per pharmacy for etaStatsTTL.
+// Too little history or a failed query falls back to the defaults.
+func (s *etaService) handlingTimes(ctx context.Context, pharmacyID uuid.UUID, now time.Time) (prep, delivery time.Duration) {
+	s.mu.Lock()
+	c, ok := s.stats[pharmacyID]
+	s.mu.Unlock()
+	if ok && now.Before(c.expires) {
+		return c.prep, c.delivery
+	}
+	prep, delivery = etaDefaultPrepMinutes*time.Minute, etaDefaultDeliveryMinutes*time.Minute
+	st, err := s.repo.Stats(ctx, pharmacyID, now.AddDate(0, 0, -etaHistoryDays))
+	if err != nil {
+		s.logger.Warn("failed to load ETA history", zap.String("pharmacy_id", pharmacyID.String()), zap.Error(err))
+		return prep, delivery
+	}
+	if st.PrepSamples >= etaMinSamples && st.PrepMinutes > 0 {
+		prep = time.Duration(st.PrepMinutes * float64(time.Minute))
+	}
+	if st.DeliverySamples >= etaMinSamples && st.DeliveryMinutes > 0 {
+		delivery = time.Duration(st.DeliveryMinutes * float64(time.Minute))
+	}
+	s.mu.Lock()
+	s.stats[pharmacyID] = cachedETAStats{prep: prep, delivery: delivery, expires: now.Add(etaStatsTTL)}
+	s.mu.Unlock()
+	return prep, delivery
+}
+
+// staffOnDuty counts staff on today's published roster whose shift covers now: morning shifts before
+// etaShiftChangeHour, evening shifts from it, full days all day. At least one, so a pharmacy without a roster
+// still gets estimates.
+func (s *etaService) staffOnDuty(ctx context.Context, pharmacyID uuid.UUID, now time.Time) int {
+	if s.rosterRepo == nil {
+		return 1
+	}
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	entries, err := s.rosterRepo.ListByStatusAndDateRange(ctx, pharmacyID, models.RosterPublished, day, day)
+	if err != nil {
+		s.logger.Warn("failed to load duty roster", zap.String("pharmacy_id", pharmacyID.String()), zap.Error(err))
+		return 1
+	}
+	morning := now.Hour() < etaShiftChangeHour
+	users := map[uuid.UUID]bool{}
+	for _, e := range entries {
+		if e.ShiftType == models.ShiftFull || (e.ShiftType == models.ShiftMorning && morning) || (e.ShiftType == models.ShiftEvening && !morning) {
+			users[e.UserID] = true
+		}
+	}
+	if len(users) == 0 {
+		return 1
+	}
+	return len(users)
+}
+
+// queuePosition is the number of open orders ahead of orderID; len(open) when it is not among them.
+func queuePosition(open []*models.Order, orderID uuid.UUID) int {
+	for i, o := range open {
+		if o.ID == orderID {
+			return i
+		}
+	}
+	return len(open)
+}
+
+func minutesUntil(now, t time.Time) int {
+	if !t.After(now) {
+		return 0
+	}
+	return int(math.Ceil(t.Sub(now).Minutes()))
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func newTestETAService(repo *mocks.MockETARepository, roster []*models.DutyRoster, now time.Time) *etaService {
+	rosters := &mocks.MockDutyRosterRepository{
+		ListByStatusAndDateRangeFunc: func(ctx context.Context, pharmacyID uuid.UUID, status models.RosterStatus, from, to time.Time) ([]*models.DutyRoster, error) {
+			return roster, nil
+		},
+	}
+	svc := NewETAService(repo, &mocks.MockOrderRepository{}, &mocks.MockDeliveryRepository{}, rosters, zap.NewNop()).(*etaService)
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+func TestETAService_Estimate_QueueSharedByStaffOnDuty(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	open := make([]*models.Order, 4)
+	for i := range open {
+		open[i] = &models.Order{ID: uuid.New(), Status: models.OrderStatusPending}
+	}
+	repo := &mocks.MockETARepository{
+		StatsFunc: func(ctx context.Context, pharmacyID uuid.UUID, since time.Time) (*models.ETAStats, error) {
+			return &models.ETAStats{PrepSamples: 20, PrepMinutes: 10, DeliverySamples: 2, DeliveryMinutes: 90}, nil
+		},
+		OpenOrdersFunc: func(ctx context.Context, pharmacyID uuid.UUID, limit int) ([]*models.Order, error) {
+			return open, nil
+		},
+	}
+	// Two morning pharmacists are on duty at 10:00; the evening one is not.
+	roster := []*models.DutyRoster{
+		{UserID: uuid.New(), ShiftType: models.ShiftMorning},
+		{UserID: uuid.New(), ShiftType: models.ShiftFull},
+		{UserID: uuid.New(), ShiftType: models.ShiftEvening},
+	}
+	svc := newTestETAService(repo, roster, now)
+
+	e, err := svc.Estimate(context.Background(), uuid.New(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.StaffOnDuty != 2 || e.QueueDepth != 4 {
+		t.Fatalf("staff %d, queue %d; want 2 and 4", e.StaffOnDuty, e.QueueDepth)
+	}
+	// Four orders ahead over two staff: two rounds of 10 minutes before this order's own 10 minutes.
+	if e.ReadyInMinutes != 30 || !e.ReadyAt.Equal(now.Add(30*time.Minute)) {
+		t.Errorf("ready in %d minutes, want 30", e.ReadyInMinutes)
+	}
+	// Too few delivery samples: the default delivery time applies.
+	if e.DeliveryInMinutes == nil || *e.DeliveryInMinutes != 30+etaDefaultDeliveryMinutes {
+		t.Errorf("delivery in %v minutes, want %d", e.DeliveryInMinutes, 30+etaDefaultDeliveryMinutes)
+	}
+	if e.Busy {
+		t.Error("busy without a spike")
+	}
+}
+
+func TestETAService_OnOrderCreated_SpikeRecalculatesOpenOrders(t *testing.T) {
+	now := time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC)
+	open := []*models.Order{{ID: uuid.New()}, {ID: uuid.New(), DeliveryAddress: "Baneshwor"}, {ID: uuid.New()}}
+	stamped := map[uuid.UUID]*time.Time{}
+	delivered := map[uuid.UUID]*time.Time{}
+	recent := int64(3)
+	repo := &mocks.MockETARepository{
+		OpenOrdersFunc: func(ctx context.Context, pharmacyID uuid.UUID, limit int) ([]*models.Order, error) {
+			return open, nil
+		},
+		OrderVolumeFunc: func(ctx context.Context, pharmacyID uuid.UUID, at time.Time, window time.Duration, days int) (int64, int64, error) {
+			return recent, 14, nil // two orders in this window on an average day
+		},
+		SetEstimatesFunc: func(ctx context.Context, orderID uuid.UUID, readyAt, deliveryAt *time.Time) error {
+			stamped[orderID], delivered[orderID] = readyAt, deliveryAt
+			return nil
+		},
+	}
+	svc := newTestETAService(repo, nil, now)
+
+	if err := svc.OnOrderCreated(context.Background(), open[2]); err != nil {
+		t.Fatal(err)
+	}
+	if len(stamped) != 1 || stamped[open[2].ID] == nil || !stamped[open[2].ID].Equal(now.Add(3*etaDefaultPrepMinutes*time.Minute)) {
+		t.Fatalf("stamped %v, want only the new order, ready after two orders ahead", stamped)
+	}
+
+	recent = 6 // three times the usual volume
+	if err := svc.OnOrderCreated(context.Background(), open[2]); err != nil {
+		t.Fatal(err)
+	}
+	if len(stamped) != 3 {
+		t.Fatalf("stamped %d orders on a spike, want all 3 open orders", len(stamped))
+	}
+	if delivered[open[1].ID] == nil || delivered[open[0].ID] != nil {
+		t.Error("only the delivery order should get a delivery ETA")
+	}
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"go.uber.org/zap"
+)
+
+const (
+	// outboxBatch is how many events one claim takes; DispatchDue keeps claiming until the outbox is drained.
+	outboxBatch = 50
+	// outboxRetention is how long dispatched events are kept for troubleshooting before they are purged.
+	outboxRetention = 7 * 24 * time.Hour
+	// maxEventHandlerTimeout bounds one subscriber call, like maxDeliveryTimeout for deliveries.
+	maxEventHandlerTimeout = 30 * time.Second
+)
+
+// EventBusOptions tunes retries of failing subscribers (see config.QueueConfig).
+type EventBusOptions struct {
+	MaxAttempts int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	Lease       time.Duration
+}
+
+type eventSubscription struct {
+	name    string
+	handler inbound.EventHandler
+}
+
+type eventBus struct {
+	repo   outbound.OutboxRepository
+	opts   EventBusOptions
+	mu     sync.RWMutex
+	subs   map[string][]eventSubscription
+	now    func() time.Time
+	logger *zap.Logger
+}
+
+// NewEventBus dispatches the events stored in repo. Subscribers are registered at startup, before the
+// scheduler starts calling DispatchDue.
+func NewEventBus(repo outbound.OutboxRepository, opts EventBusOptions, logger *zap.Logger) inbound.EventBus {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 1
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = 2 * time.Minute
+	}
+	return &eventBus{repo: repo, opts: opts, subs: make(map[string][]eventSubscription), now: time.Now, logger: logger}
+}
+
+func (s *eventBus) Subscribe(eventType, subscriber string, h inbound.EventHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sub := range s.subs[eventType] {
+		if sub.name == subscriber {
+			panic(fmt.Sprintf("event bus: duplicate subscriber %q for %s", subscriber, eventType))
+		}
+	}
+	s.subs[eventType] = append(s.subs[eventType], eventSubscription{name: subscriber, handler: h})
+}
+
+func (s *eventBus) DispatchDue(ctx context.Context) error {
+	for ctx.Err() == nil {
+		events, err := s.repo.ClaimDue(ctx, s.now(), s.opts.Lease, outboxBatch)
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			s.dispatch(ctx, e)
+		}
+		if len(events) < outboxBatch {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// dispatch runs the subscribers of a claimed event that have not handled it yet. The event is done when all
+// succeeded; otherwise it is rescheduled with backoff, or marked dead after the maximum attempts.
+func (s *eventBus) dispatch(ctx context.Context, e *models.OutboxEvent) {
+	s.mu.RLock()
+	subs := s.subs[e.Type]
+	s.mu.RUnlock()
+	var failure error
+	for _, sub := range subs {
+		if slices.Contains(e.Handled, sub.name) {
+			continue
+		}
+		if err := s.call(ctx, sub, e); err != nil {
+			s.logger.Warn("event subscriber failed", zap.String("event_id", e.ID.String()), zap.String("type", e.Type),
+				zap.String("subscriber", sub.name), zap.Error(err))
+			if failure == nil {
+				failure = fmt.Errorf("%s: %w", sub.name, err)
+			}
+			continue
+		}
+		e.Handled = append(e.Handled, sub.name)
+	}
+	e.LockedUntil = nil
+	if failure == nil {
+		now := s.now()
+		e.Status = models.OutboxStatusDone
+		e.DispatchedAt = &now
+		e.LastError = ""
+	} else {
+		e.Attempts++
+		e.LastError = truncateRunes(failure.Error(), 1000)
+		if e.Attempts >= s.opts.MaxAttempts {
+			e.Status = models.OutboxStatusDead
+			s.logger.Error("event dispatch gave up", zap.String("event_id", e.ID.String()), zap.String("type", e.Type),
+				zap.Int("attempts", e.Attempts), zap.Error(failure))
+		} else {
+			e.Status = models.OutboxStatusPending
+			e.NextAttemptAt = s.now().Add(retryBackoff(s.opts.BackoffBase, s.opts.BackoffMax, e.Attempts))
+		}
+	}
+	if err := s.repo.Update(ctx, e); err != nil {
+		s.logger.Error("update outbox event failed", zap.String("event_id", e.ID.String()), zap.Error(err))
+	}
+}
+
+// call runs one subscriber with a timeout below the lease, turning a panic into an error.
+func (s *eventBus) call(ctx context.Context, sub eventSubscription, e *models.OutboxEvent) (err error) {
+	timeout := maxEventHandlerTimeout
+	if half := s.opts.Lease / 2; half < timeout {
+		timeout = half
+	}
+	callCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return sub.handler(callCtx, e)
+}
+
+func (s *eventBus) PurgeDispatched(ctx context.Context) error {
+	n, err := s.repo.DeleteDispatchedBefore(ctx, s.now().Add(-outboxRetention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		s.logger.Info("purged dispatched outbox events", zap.Int64("deleted", n))
+	}
+	return nil
+}
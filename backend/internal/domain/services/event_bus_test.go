@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// outboxWith returns a mock outbox that hands out e while it is due.
+func outboxWith(e *models.OutboxEvent) *mocks.MockOutboxRepository {
+	return &mocks.MockOutboxRepository{
+		ClaimDueFunc: func(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.OutboxEvent, error) {
+			if e.Status != models.OutboxStatusPending || e.NextAttemptAt.After(now) {
+				return nil, nil
+			}
+			e.Status = models.OutboxStatusRunning
+			return []*models.OutboxEvent{e}, nil
+		},
+	}
+}
+
+func TestEventBus_DispatchDue_RetriesOnlyFailedSubscribers(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	e := models.NewOutboxEvent(models.EventOrderCompleted, uuid.New(), uuid.New(), nil)
+	e.NextAttemptAt = now
+	bus := NewEventBus(outboxWith(e), EventBusOptions{MaxAttempts: 3, BackoffBase: time.Minute, BackoffMax: time.Hour}, zap.NewNop()).(*eventBus)
+	bus.now = func() time.Time { return now }
+
+	okCalls, flakyCalls := 0, 0
+	bus.Subscribe(models.EventOrderCompleted, "ok", func(ctx context.Context, e *models.OutboxEvent) error {
+		okCalls++
+		return nil
+	})
+	bus.Subscribe(models.EventOrderCompleted, "flaky", func(ctx context.Context, e *models.OutboxEvent) error {
+		flakyCalls++
+		if flakyCalls == 1 {
+			return errors.New("points service down")
+		}
+		return nil
+	})
+
+	if err := bus.DispatchDue(context.Background()); err != nil {
+		t.Fatalf("DispatchDue: %v", err)
+	}
+	if e.Status != models.OutboxStatusPending || e.Attempts != 1 || !e.NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("after failure: status=%s attempts=%d next=%v, want pending, 1, +1m", e.Status, e.Attempts, e.NextAttemptAt)
+	}
+	if len(e.Handled) != 1 || e.Handled[0] != "ok" {
+		t.Fatalf("handled = %v, want [ok]", e.Handled)
+	}
+
+	now = now.Add(time.Minute)
+	if err := bus.DispatchDue(context.Background()); err != nil {
+		t.Fatalf("DispatchDue: %v", err)
+	}
+	if e.Status != models.OutboxStatusDone || e.DispatchedAt == nil || e.LastError != "" {
+		t.Errorf("after retry: status=%s dispatched=%v err=%q, want done", e.Status, e.DispatchedAt, e.LastError)
+	}
+	if okCalls != 1 || flakyCalls != 2 {
+		t.Errorf("calls ok=%d flaky=%d, want 1 and 2", okCalls, flakyCalls)
+	}
+}
+
+func TestEventBus_DispatchDue_PanicMarksDeadAfterMaxAttempts(t *testing.T) {
+	e := models.NewOutboxEvent(models.EventReviewCreated, uuid.New(), uuid.New(), nil)
+	bus := NewEventBus(outboxWith(e), EventBusOptions{MaxAttempts: 1}, zap.NewNop())
+	bus.Subscribe(models.EventReviewCreated, "broken", func(ctx context.Context, e *models.OutboxEvent) error {
+		panic("nil map")
+	})
+
+	if err := bus.DispatchDue(context.Background()); err != nil {
+		t.Fatalf("DispatchDue: %v", err)
+	}
+	if e.Status != models.OutboxStatusDead || e.LastError != "broken: panic: nil map" {
+		t.Errorf("status=%s err=%q, want dead with the panic recorded", e.Status, e.LastError)
+	}
+}
+
+func TestOrderService_UpdateStatus_CompletionRaisesEvent(t *testing.T) {
+	o := &models.Order{ID: uuid.New(), PharmacyID: uuid.New(), Status: models.OrderStatusReady, TotalAmount: 1250}
+	repo := &mocks.MockOrderRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Order, error) { return o, nil },
+	}
+	svc := &orderService{orderRepo: repo, userRepo: &mocks.MockUserRepository{}, logger: zap.NewNop()}
+
+	if _, err := svc.UpdateStatus(context.Background(), o.ID, models.OrderStatusCompleted, uuid.New(), ""); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	if len(repo.Events) != 1 || repo.Events[0].Type != models.EventOrderCompleted || repo.Events[0].AggregateID != o.ID {
+		t.Fatalf("events = %+v, want one order.completed for the order", repo.Events)
+	}
+	var data models.OrderEventData
+	if err := repo.Events[0].Decode(&data); err != nil || data.TotalAmount != 1250 {
+		t.Errorf("payload = %+v (%v), want total 1250", data, err)
+	}
+}
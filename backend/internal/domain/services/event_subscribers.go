@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Subscriber names recorded in OutboxEvent.Handled. Renaming one makes pending events run it again.
+const (
+	subscriberCustomerPoints      = "points.customer"
+	subscriberStaffPoints         = "points.staff"
+	subscriberReviewNotifications = "notifications.review"
+	subscriberAnalytics           = "analytics"
+	subscriberOrderETA            = "eta.order"
+)
+
+// eventSubscribers holds the side effects that used to run inline in the services raising the events.
+type eventSubscribers struct {
+	orderRepo           outbound.OrderRepository
+	productRepo         outbound.ProductRepository
+	userRepo            outbound.UserRepository
+	referralPointsSvc   inbound.ReferralPointsService
+	staffPointsSvc      inbound.StaffPointsService
+	notificationService inbound.NotificationService
+	etaService          inbound.ETAService
+	logger              *zap.Logger
+}
+
+// RegisterEventSubscribers subscribes the built-in handlers: customer and staff points on order completion,
+// manager notifications for new reviews, the ETA of new orders, and an analytics log line for every event
+// type. Nil services skip their subscriber.
+func RegisterEventSubscribers(
+	bus inbound.EventBus,
+	orderRepo outbound.OrderRepository,
+	productRepo outbound.ProductRepository,
+	userRepo outbound.UserRepository,
+	referralPointsSvc inbound.ReferralPointsService,
+	staffPointsSvc inbound.StaffPointsService,
+	notificationService inbound.NotificationService,
+	etaService inbound.ETAService,
+	logger *zap.Logger,
+) {
+	s := &eventSubscribers{
+		orderRepo:           orderRepo,
+		productRepo:         productRepo,
+		userRepo:            userRepo,
+		referralPointsSvc:   referralPointsSvc,
+		staffPointsSvc:      staffPointsSvc,
+		notificationService: notificationService,
+		etaService:          etaService,
+		logger:              logger,
+	}
+	if referralPointsSvc != nil {
+		bus.Subscribe(models.EventOrderCompleted, subscriberCustomerPoints, s.creditCustomerPoints)
+	}
+	if staffPointsSvc != nil {
+		bus.Subscribe(models.EventOrderCompleted, subscriberStaffPoints, s.creditStaffPoints)
+	}
+	if notificationService != nil {
+		bus.Subscribe(models.EventReviewCreated, subscriberReviewNotifications, s.notifyReview)
+	}
+	if etaService != nil {
+		bus.Subscribe(models.EventOrderCreated, subscriberOrderETA, s.stampETA)
+	}
+	for _, t := range []string{models.EventOrderCreated, models.EventOrderCompleted, models.EventStockLow, models.EventPaymentCompleted, models.EventReviewCreated} {
+		bus.Subscribe(t, subscriberAnalytics, s.track)
+	}
+}
+
+func (s *eventSubscribers) order(ctx context.Context, e *models.OutboxEvent) (*models.Order, error) {
+	o, err := s.orderRepo.GetByID(ctx, e.AggregateID)
+	if err != nil || o == nil {
+		return nil, fmt.Errorf("load order %s: %v", e.AggregateID, err)
+	}
+	return o, nil
+}
+
+// creditCustomerPoints awards purchase, birthday and referral points for a completed order.
+func (s *eventSubscribers) creditCustomerPoints(ctx context.Context, e *models.OutboxEvent) error {
+	o, err := s.order(ctx, e)
+	if err != nil {
+		return err
+	}
+	return s.referralPointsSvc.OnOrderCompleted(ctx, o)
+}
+
+// creditStaffPoints credits the user who created a completed order; crediting is idempotent per order.
+func (s *eventSubscribers) creditStaffPoints(ctx context.Context, e *models.OutboxEvent) error {
+	o, err := s.order(ctx, e)
+	if err != nil {
+		return err
+	}
+	return s.staffPointsSvc.CreditSale(ctx, o)
+}
+
+// stampETA stores the estimated ready and delivery times of a new order.
+func (s *eventSubscribers) stampETA(ctx context.Context, e *models.OutboxEvent) error {
+	o, err := s.order(ctx, e)
+	if err != nil {
+		return err
+	}
+	return s.etaService.OnOrderCreated(ctx, o)
+}
+
+// notifyReview tells the pharmacy's active admins and managers about a new product review. Failures for single
+// recipients are logged, not retried, so a retry never notifies the others twice.
+func (s *eventSubscribers) notifyReview(ctx context.Context, e *models.OutboxEvent) error {
+	var data models.ReviewEventData
+	if err := e.Decode(&data); err != nil {
+		return err
+	}
+	users, err := s.userRepo.GetByPharmacyID(ctx, e.PharmacyID)
+	if err != nil {
+		return err
+	}
+	name := "a product"
+	if p, err := s.productRepo.GetByID(ctx, data.ProductID); err == nil && p != nil {
+		name = p.Name
+	}
+	message := fmt.Sprintf("%d-star review on %s", data.Rating, name)
+	for _, u := range users {
+		if !u.IsActive || (u.Role != models.RoleAdmin && u.Role != models.RoleManager) {
+			continue
+		}
+		if _, err := s.notificationService.Create(ctx, e.PharmacyID, u.ID, "New product review", message, "review"); err != nil {
+			s.logger.Warn("failed to send review notification", zap.String("user_id", u.ID.String()), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// track writes one structured log line per event for the analytics pipeline that ships the logs.
+func (s *eventSubscribers) track(ctx context.Context, e *models.OutboxEvent) error {
+	fields := []zap.Field{
+		zap.String("event_id", e.ID.String()),
+		zap.String("type", e.Type),
+		zap.String("aggregate_id", e.AggregateID.String()),
+		zap.Time("occurred_at", e.CreatedAt),
+		zap.String("data", e.Payload),
+	}
+	if e.PharmacyID != uuid.Nil {
+		fields = append(fields, zap.String("pharmacy_id", e.PharmacyID.String()))
+	}
+	s.logger.Info("domain event", fields...)
+	return nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type expiryDiscountService struct {
+	batchRepo    outbound.InventoryBatchRepository
+	categoryRepo outbound.CategoryRepository
+	configRepo   outbound.PharmacyConfigRepository
+	logger       *zap.Logger
+}
+
+func NewExpiryDiscountService(batchRepo outbound.InventoryBatchRepository, categoryRepo outbound.CategoryRepository, configRepo outbound.PharmacyConfigRepository, logger *zap.Logger) inbound.ExpiryDiscountService {
+	return &expiryDiscountService{batchRepo: batchRepo, categoryRepo: categoryRepo, configRepo: configRepo, logger: logger}
+}
+
+func (s *expiryDiscountService) ListOffers(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID) ([]*inbound.ExpiryDiscountOffer, error) {
+	cfg, err := s.configRepo.GetByPharmacyID(ctx, pharmacyID)
+	if err != nil || cfg == nil || cfg.ExpiryDiscount == nil || !cfg.ExpiryDiscount.Enabled {
+		return []*inbound.ExpiryDiscountOffer{}, nil
+	}
+	policy := cfg.ExpiryDiscount
+	today := startOfDay(time.Now())
+	batches, err := s.batchRepo.ListExpiringByPharmacy(ctx, pharmacyID, today.AddDate(0, 0, policy.MaxDays()+1))
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load expiring batches", err)
+	}
+	wanted := make(map[uuid.UUID]bool, len(productIDs))
+	for _, id := range productIDs {
+		wanted[id] = true
+	}
+	parents := map[uuid.UUID]*uuid.UUID{}
+	seen := map[uuid.UUID]bool{}
+	offers := []*inbound.ExpiryDiscountOffer{}
+	// Batches come soonest expiry first, so the first unexpired batch per product is its earliest.
+	for _, b := range batches {
+		p := b.Product
+		if p == nil || seen[b.ProductID] || b.ExpiryDate == nil || b.ExpiryDate.Before(today) {
+			continue
+		}
+		if len(wanted) > 0 && !wanted[b.ProductID] {
+			continue
+		}
+		seen[b.ProductID] = true
+		if !p.IsActive || p.StockQuantity <= 0 || policy.IsExcluded(p.CategoryID, s.parentOf(ctx, p.CategoryID, parents)) {
+			continue
+		}
+		days := int(startOfDay(*b.ExpiryDate).Sub(today).Hours() / 24)
+		percent := policy.PercentFor(days)
+		if percent <= 0 {
+			continue
+		}
+		offers = append(offers, &inbound.ExpiryDiscountOffer{
+			ProductID:       p.ID,
+			Product:         p,
+			BatchNumber:     b.BatchNumber,
+			ExpiryDate:      *b.ExpiryDate,
+			DaysToExpiry:    days,
+			DiscountPercent: percent,
+			RegularPrice:    p.UnitPrice,
+			SalePrice:       math.Round(p.UnitPrice*(100-percent)) / 100,
+			Label:           "Short expiry: best before " + b.ExpiryDate.Format("2006-01-02"),
+		})
+	}
+	return offers, nil
+}
+
+// parentOf returns the parent of a category (cached per call) so excluding a category also excludes its subcategories.
+func (s *expiryDiscountService) parentOf(ctx context.Context, categoryID *uuid.UUID, cache map[uuid.UUID]*uuid.UUID) *uuid.UUID {
+	if categoryID == nil || s.categoryRepo == nil {
+		return nil
+	}
+	if parent, ok := cache[*categoryID]; ok {
+		return parent
+	}
+	var parent *uuid.UUID
+	if cat, err := s.categoryRepo.GetByID(ctx, *categoryID); err == nil && cat != nil {
+		parent = cat.ParentID
+	}
+	cache[*categoryID] = parent
+	return parent
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestExpiryDiscountService_ListOffers_AppliesTiersAndExclusions(t *testing.T) {
+	pharmacyID := uuid.New()
+	excluded := uuid.New()
+	policy := &models.ExpiryDiscountPolicy{
+		Enabled:             true,
+		Tiers:               []models.ExpiryDiscountTier{{DaysBeforeExpiry: 60, DiscountPercent: 20}, {DaysBeforeExpiry: 30, DiscountPercent: 40}},
+		ExcludedCategoryIDs: []uuid.UUID{excluded},
+	}
+	configRepo := &mocks.MockPharmacyConfigRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, id uuid.UUID) (*models.PharmacyConfig, error) {
+			return &models.PharmacyConfig{PharmacyID: id, ExpiryDiscount: policy}, nil
+		},
+	}
+	day := func(n int) *time.Time {
+		d := time.Now().AddDate(0, 0, n)
+		return &d
+	}
+	soon := &models.Product{ID: uuid.New(), UnitPrice: 100, StockQuantity: 5, IsActive: true}
+	later := &models.Product{ID: uuid.New(), UnitPrice: 50, StockQuantity: 5, IsActive: true}
+	skipped := &models.Product{ID: uuid.New(), UnitPrice: 80, StockQuantity: 5, IsActive: true, CategoryID: &excluded}
+	batchRepo := &mocks.MockInventoryBatchRepository{
+		ListExpiringByPharmacyFunc: func(ctx context.Context, id uuid.UUID, beforeOrOn time.Time) ([]*models.InventoryBatch, error) {
+			return []*models.InventoryBatch{
+				{ProductID: soon.ID, Product: soon, BatchNumber: "OLD", ExpiryDate: day(-2)}, // already expired: ignored
+				{ProductID: soon.ID, Product: soon, BatchNumber: "B1", ExpiryDate: day(10)},
+				{ProductID: skipped.ID, Product: skipped, BatchNumber: "B2", ExpiryDate: day(15)},
+				{ProductID: later.ID, Product: later, BatchNumber: "B3", ExpiryDate: day(45)},
+				{ProductID: soon.ID, Product: soon, BatchNumber: "B4", ExpiryDate: day(50)},
+			}, nil
+		},
+	}
+
+	svc := NewExpiryDiscountService(batchRepo, nil, configRepo, zap.NewNop())
+	offers, err := svc.ListOffers(context.Background(), pharmacyID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(offers) != 2 {
+		t.Fatalf("expected 2 offers, got %d", len(offers))
+	}
+	if offers[0].ProductID != soon.ID || offers[0].BatchNumber != "B1" || offers[0].DiscountPercent != 40 || offers[0].SalePrice != 60 {
+		t.Errorf("unexpected first offer: %+v", offers[0])
+	}
+	if offers[1].ProductID != later.ID || offers[1].DiscountPercent != 20 || offers[1].SalePrice != 40 {
+		t.Errorf("unexpected second offer: %+v", offers[1])
+	}
+}
+
+func TestExpiryDiscountService_ListOffers_DisabledPolicy(t *testing.T) {
+	configRepo := &mocks.MockPharmacyConfigRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, id uuid.UUID) (*models.PharmacyConfig, error) {
+			return &models.PharmacyConfig{PharmacyID: id}, nil
+		},
+	}
+	batchRepo := &mocks.MockInventoryBatchRepository{
+		ListExpiringByPharmacyFunc: func(ctx context.Context, id uuid.UUID, beforeOrOn time.Time) ([]*models.InventoryBatch, error) {
+			t.Fatal("batches should not be loaded when the policy is off")
+			return nil, nil
+		},
+	}
+	svc := NewExpiryDiscountService(batchRepo, nil, configRepo, zap.NewNop())
+	offers, err := svc.ListOffers(context.Background(), uuid.New(), nil)
+	if err != nil || len(offers) != 0 {
+		t.Fatalf("expected no offers, got %v, %v", offers, err)
+	}
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// exportApprovalTTL is how long an approved export request can be downloaded.
+	exportApprovalTTL = 24 * time.Hour
+	// maxExportRequests bounds List.
+	maxExportRequests = 200
+)
+
+type exportApprovalService struct {
+	repo        outbound.ExportRequestRepository
+	userRepo    outbound.UserRepository
+	activitySvc inbound.ActivityLogService
+	logger      *zap.Logger
+	now         func() time.Time
+}
+
+func NewExportApprovalService(repo outbound.ExportRequestRepository, userRepo outbound.UserRepository, activitySvc inbound.ActivityLogService, logger *zap.Logger) inbound.ExportApprovalService {
+	return &exportApprovalService{repo: repo, userRepo: userRepo, activitySvc: activitySvc, logger: logger, now: time.Now}
+}
+
+func piiExportKindList() string {
+	kinds := make([]string, 0, len(models.PIIExportKinds))
+	for k := range models.PIIExportKinds {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return strings.Join(kinds, ", ")
+}
+
+func (s *exportApprovalService) Request(ctx context.Context, pharmacyID, userID uuid.UUID, kind, reason string) (*models.ExportRequest, error) {
+	if _, ok := models.PIIExportKinds[kind]; !ok {
+		return nil, errors.ErrValidation("kind must be one of " + piiExportKindList())
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.ErrValidation("reason is required")
+	}
+	r := &models.ExportRequest{PharmacyID: pharmacyID, Kind: kind, Reason: reason, Status: models.ExportRequestPending, RequestedBy: userID}
+	if err := s.repo.Create(ctx, r); err != nil {
+		return nil, errors.ErrInternal("failed to create export request", err)
+	}
+	return r, nil
+}
+
+func (s *exportApprovalService) List(ctx context.Context, pharmacyID uuid.UUID, requestedBy *uuid.UUID, status string) ([]*models.ExportRequest, error) {
+	list, err := s.repo.List(ctx, pharmacyID, requestedBy, status, maxExportRequests)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list export requests", err)
+	}
+	return list, nil
+}
+
+func (s *exportApprovalService) Review(ctx context.Context, pharmacyID, id, reviewerID uuid.UUID, approve bool, note string) (*models.ExportRequest, error) {
+	r, err := s.repo.GetByID(ctx, id)
+	if err != nil || r == nil || r.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("export request")
+	}
+	if r.RequestedBy == reviewerID {
+		return nil, errors.ErrForbidden("you cannot review your own export request")
+	}
+	if r.Status != models.ExportRequestPending {
+		return nil, errors.ErrConflict("export request is already " + string(r.Status))
+	}
+	now := s.now()
+	r.ReviewedBy = &reviewerID
+	r.ReviewedAt = &now
+	r.ReviewNote = strings.TrimSpace(note)
+	if approve {
+		expires := now.Add(exportApprovalTTL)
+		r.Status = models.ExportRequestApproved
+		r.ExpiresAt = &expires
+	} else {
+		r.Status = models.ExportRequestRejected
+	}
+	if err := s.repo.Update(ctx, r); err != nil {
+		return nil, errors.ErrInternal("failed to review export request", err)
+	}
+	return r, nil
+}
+
+func (s *exportApprovalService) Authorize(ctx context.Context, pharmacyID, userID uuid.UUID, kind string, canApprove bool, requestID *uuid.UUID) (*inbound.ExportGrant, error) {
+	now := s.now()
+	g := &inbound.ExportGrant{ID: uuid.New(), PharmacyID: pharmacyID, Kind: kind, UserID: userID, IssuedAt: now}
+	if !canApprove {
+		if requestID == nil {
+			return nil, errors.ErrForbidden("this export contains personal data and needs a manager's approval: request it with POST /exports/requests {kind: \"" + kind + "\", reason}, then download with ?export_request_id=<id>")
+		}
+		r, err := s.repo.GetByID(ctx, *requestID)
+		if err != nil || r == nil || r.PharmacyID != pharmacyID || r.RequestedBy != userID {
+			return nil, errors.ErrNotFound("export request")
+		}
+		if r.Kind != kind {
+			return nil, errors.ErrForbidden("export request " + r.ID.String() + " was approved for " + r.Kind + ", not " + kind)
+		}
+		if r.Status != models.ExportRequestApproved {
+			return nil, errors.ErrForbidden("export request is " + string(r.Status))
+		}
+		ok, err := s.repo.Consume(ctx, r.ID, now)
+		if err != nil {
+			return nil, errors.ErrInternal("failed to use export request", err)
+		}
+		if !ok {
+			return nil, errors.ErrForbidden("export approval has expired or was already used")
+		}
+		g.RequestID = &r.ID
+	}
+	if s.userRepo != nil {
+		if u, err := s.userRepo.GetByID(ctx, userID); err == nil && u != nil {
+			g.UserName, g.UserEmail = u.Name, u.Email
+		}
+	}
+	return g, nil
+}
+
+func (s *exportApprovalService) RecordDownload(ctx context.Context, g *inbound.ExportGrant, filename, ipAddress string) error {
+	details, _ := json.Marshal(map[string]interface{}{
+		"kind":              g.Kind,
+		"rows":              g.Rows,
+		"filename":          filename,
+		"export_request_id": g.RequestID,
+		"issued_at":         g.IssuedAt.UTC().Format(time.RFC3339),
+	})
+	desc := fmt.Sprintf("Exported %d %s row(s)", g.Rows, g.Kind)
+	return s.activitySvc.Create(ctx, g.PharmacyID, g.UserID, "EXPORT "+g.Kind, desc, "export", g.ID.String(), string(details), ipAddress)
+}
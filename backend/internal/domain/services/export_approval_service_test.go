@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// exportRequestStore keeps requests in memory and consumes them the way the repository's conditional update does.
+func exportRequestStore() (*mocks.MockExportRequestRepository, map[uuid.UUID]*models.ExportRequest) {
+	rows := map[uuid.UUID]*models.ExportRequest{}
+	repo := &mocks.MockExportRequestRepository{
+		CreateFunc: func(ctx context.Context, r *models.ExportRequest) error {
+			r.ID = uuid.New()
+			rows[r.ID] = r
+			return nil
+		},
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.ExportRequest, error) {
+			if r, ok := rows[id]; ok {
+				cp := *r
+				return &cp, nil
+			}
+			return nil, nil
+		},
+		UpdateFunc: func(ctx context.Context, r *models.ExportRequest) error {
+			rows[r.ID] = r
+			return nil
+		},
+		ConsumeFunc: func(ctx context.Context, id uuid.UUID, now time.Time) (bool, error) {
+			r, ok := rows[id]
+			if !ok || r.Status != models.ExportRequestApproved || r.ExpiresAt == nil || !r.ExpiresAt.After(now) {
+				return false, nil
+			}
+			r.Status, r.UsedAt = models.ExportRequestUsed, &now
+			return true, nil
+		},
+	}
+	return repo, rows
+}
+
+func TestExportApprovalService_ApprovedRequestCoversOneDownload(t *testing.T) {
+	pharmacyID, clerk, manager := uuid.New(), uuid.New(), uuid.New()
+	repo, _ := exportRequestStore()
+	users := &mocks.MockUserRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.User, error) {
+			return &models.User{ID: id, Name: "Sita", Email: "sita@valley.test"}, nil
+		},
+	}
+	svc := &exportApprovalService{repo: repo, userRepo: users, logger: zap.NewNop(), now: time.Now}
+	ctx := context.Background()
+
+	if _, err := svc.Authorize(ctx, pharmacyID, clerk, models.ExportKindConsents, false, nil); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeForbidden {
+		t.Fatalf("without a request: err = %v, want forbidden", err)
+	}
+	r, err := svc.Request(ctx, pharmacyID, clerk, models.ExportKindConsents, "regulator audit")
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if _, err := svc.Authorize(ctx, pharmacyID, clerk, models.ExportKindConsents, false, &r.ID); err == nil {
+		t.Fatal("pending request authorized a download")
+	}
+	if _, err := svc.Review(ctx, pharmacyID, r.ID, manager, true, "ok"); err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	if _, err := svc.Authorize(ctx, pharmacyID, clerk, models.ExportKindActivity, false, &r.ID); err == nil {
+		t.Fatal("request for consents authorized an activity export")
+	}
+	g, err := svc.Authorize(ctx, pharmacyID, clerk, models.ExportKindConsents, false, &r.ID)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if g.RequestID == nil || *g.RequestID != r.ID || !strings.Contains(g.Watermark(), "sita@valley.test") || !strings.Contains(g.Watermark(), g.ID.String()) {
+		t.Errorf("grant = %+v, watermark %q", g, g.Watermark())
+	}
+	if _, err := svc.Authorize(ctx, pharmacyID, clerk, models.ExportKindConsents, false, &r.ID); err == nil {
+		t.Error("used request authorized a second download")
+	}
+}
+
+func TestExportApprovalService_Review_RejectsSelfReviewAndExpires(t *testing.T) {
+	pharmacyID, clerk, manager := uuid.New(), uuid.New(), uuid.New()
+	repo, _ := exportRequestStore()
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	svc := &exportApprovalService{repo: repo, logger: zap.NewNop(), now: func() time.Time { return now }}
+	ctx := context.Background()
+
+	r, err := svc.Request(ctx, pharmacyID, clerk, models.ExportKindStaffPoints, "payroll")
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if _, err := svc.Review(ctx, pharmacyID, r.ID, clerk, true, ""); err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeForbidden {
+		t.Errorf("self review: err = %v, want forbidden", err)
+	}
+	if _, err := svc.Review(ctx, pharmacyID, r.ID, manager, true, ""); err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	now = now.Add(exportApprovalTTL + time.Minute)
+	if _, err := svc.Authorize(ctx, pharmacyID, clerk, models.ExportKindStaffPoints, false, &r.ID); err == nil {
+		t.Error("expired approval authorized a download")
+	}
+	if _, err := svc.Request(ctx, pharmacyID, clerk, "orders", "x"); err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("unknown kind: err = %v, want validation error", err)
+	}
+}
+
+func TestExportApprovalService_RecordDownload_LogsRowCount(t *testing.T) {
+	var logged *models.ActivityLog
+	activity := &activityLogService{repo: &mocks.MockActivityLogRepository{
+		CreateFunc: func(ctx context.Context, a *models.ActivityLog) error {
+			logged = a
+			return nil
+		},
+	}, logger: zap.NewNop()}
+	svc := &exportApprovalService{activitySvc: activity, logger: zap.NewNop(), now: time.Now}
+	g, err := svc.Authorize(context.Background(), uuid.New(), uuid.New(), models.ExportKindActivity, true, nil)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	g.Rows = 42
+
+	if err := svc.RecordDownload(context.Background(), g, "activity-log.csv", "10.0.0.1"); err != nil {
+		t.Fatalf("RecordDownload: %v", err)
+	}
+	if logged == nil || logged.EntityType != "export" || logged.EntityID != g.ID.String() || !strings.Contains(logged.Details, `"rows":42`) || logged.IPAddress != "10.0.0.1" {
+		t.Errorf("logged = %+v", logged)
+	}
+}
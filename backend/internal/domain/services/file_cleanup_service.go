@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fileSweepBatch bounds the ledger entries one sweep checks; each check scans every text column once.
+const fileSweepBatch = 500
+
+type fileCleanupService struct {
+	refs   outbound.FileReferenceRepository
+	files  outbound.FileDeleter
+	grace  time.Duration
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewFileCleanupService builds the orphan sweep. Files nobody released are only swept once they are older than
+// grace, which leaves time to attach an upload to the record it was made for.
+func NewFileCleanupService(refs outbound.FileReferenceRepository, files outbound.FileDeleter, grace time.Duration, logger *zap.Logger) inbound.FileCleanupService {
+	return &fileCleanupService{refs: refs, files: files, grace: grace, logger: logger, now: time.Now}
+}
+
+func (s *fileCleanupService) Release(ctx context.Context, urls ...string) error {
+	list := make([]string, 0, len(urls))
+	for _, u := range urls {
+		if u != "" {
+			list = append(list, u)
+		}
+	}
+	if err := s.refs.Release(ctx, list, s.now()); err != nil {
+		return fmt.Errorf("release files: %w", err)
+	}
+	return nil
+}
+
+func (s *fileCleanupService) Sweep(ctx context.Context, dryRun bool) (*models.FileSweepReport, error) {
+	now := s.now()
+	report := &models.FileSweepReport{GeneratedAt: now, DryRun: dryRun, Files: []*models.FileReference{}}
+	candidates, err := s.refs.ListSweepCandidates(ctx, now.Add(-s.grace), fileSweepBatch)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list stored files", err)
+	}
+	report.Checked = len(candidates)
+	urls := make([]string, len(candidates))
+	for i, f := range candidates {
+		urls[i] = f.URL
+	}
+	referenced, err := s.refs.Referenced(ctx, urls)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to check file references", err)
+	}
+	var inUse []uuid.UUID
+	for _, f := range candidates {
+		if referenced[f.URL] {
+			inUse = append(inUse, f.ID)
+			continue
+		}
+		report.Orphans++
+		report.Bytes += f.Size
+		if len(report.Files) < models.FileSweepSampleSize {
+			report.Files = append(report.Files, f)
+		}
+		if dryRun {
+			continue
+		}
+		if err := s.files.Delete(ctx, f.URL); err != nil {
+			s.logger.Warn("failed to delete orphaned file", zap.String("url", f.URL), zap.Error(err))
+			continue
+		}
+		if err := s.refs.Delete(ctx, f.ID); err != nil {
+			s.logger.Warn("failed to drop file reference", zap.String("url", f.URL), zap.Error(err))
+			continue
+		}
+		report.Deleted++
+	}
+	if !dryRun {
+		// Files still in use go to the back of the queue, and a release that turned out early is forgotten.
+		if err := s.refs.MarkChecked(ctx, inUse, now); err != nil {
+			return nil, errors.ErrInternal("failed to record checked files", err)
+		}
+	}
+	return report, nil
+}
+
+func (s *fileCleanupService) SweepOrphans(ctx context.Context) error {
+	report, err := s.Sweep(ctx, false)
+	if err != nil {
+		return err
+	}
+	if report.Orphans > 0 {
+		s.logger.Info("orphaned files swept", zap.Int("checked", report.Checked), zap.Int("orphans", report.Orphans),
+			zap.Int("deleted", report.Deleted), zap.Int64("bytes", report.Bytes))
+	}
+	if report.Deleted < report.Orphans {
+		return fmt.Errorf("failed to delete %d of %d orphaned files", report.Orphans-report.Deleted, report.Orphans)
+	}
+	return nil
+}
+
+// releaseFiles hands the files of a deleted record to the sweep; files may be nil. A failure is only logged: the
+// files are still swept once past the grace period.
+func releaseFiles(ctx context.Context, files inbound.FileCleanupService, logger *zap.Logger, urls ...string) {
+	if files == nil || len(urls) == 0 {
+		return
+	}
+	if err := files.Release(ctx, urls...); err != nil {
+		logger.Warn("failed to release files", zap.Error(err))
+	}
+}
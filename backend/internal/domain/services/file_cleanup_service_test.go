@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// memoryDeleter deletes from a set of stored URLs and fails for the ones in broken.
+type memoryDeleter struct {
+	stored map[string]bool
+	broken map[string]bool
+}
+
+func (m *memoryDeleter) Delete(ctx context.Context, url string) error {
+	if m.broken[url] {
+		return fmt.Errorf("storage unavailable")
+	}
+	delete(m.stored, url)
+	return nil
+}
+
+func TestFileCleanupService_Sweep(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	ledger := map[string]*models.FileReference{}
+	for _, u := range []string{"/uploads/in-use.png", "/uploads/orphan.png", "/uploads/released.png", "/uploads/stuck.png", "/uploads/fresh.png"} {
+		ledger[u] = &models.FileReference{ID: uuid.New(), URL: u, Size: 10, CreatedAt: now.Add(-30 * 24 * time.Hour)}
+	}
+	ledger["/uploads/fresh.png"].CreatedAt = now.Add(-time.Hour)
+	var checked []uuid.UUID
+	refs := &mocks.MockFileReferenceRepository{
+		ReleaseFunc: func(ctx context.Context, urls []string, at time.Time) error {
+			for _, u := range urls {
+				if f := ledger[u]; f != nil {
+					f.ReleasedAt = &at
+				}
+			}
+			return nil
+		},
+		ListSweepCandidatesFunc: func(ctx context.Context, createdBefore time.Time, limit int) ([]*models.FileReference, error) {
+			var list []*models.FileReference
+			for _, f := range ledger {
+				if f.ReleasedAt != nil || f.CreatedAt.Before(createdBefore) {
+					list = append(list, f)
+				}
+			}
+			return list, nil
+		},
+		ReferencedFunc: func(ctx context.Context, urls []string) (map[string]bool, error) {
+			return map[string]bool{"/uploads/in-use.png": true}, nil
+		},
+		MarkCheckedFunc: func(ctx context.Context, ids []uuid.UUID, at time.Time) error {
+			checked = append(checked, ids...)
+			return nil
+		},
+		DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+			for u, f := range ledger {
+				if f.ID == id {
+					delete(ledger, u)
+				}
+			}
+			return nil
+		},
+	}
+	deleter := &memoryDeleter{stored: map[string]bool{}, broken: map[string]bool{"/uploads/stuck.png": true}}
+	for u := range ledger {
+		deleter.stored[u] = true
+	}
+	svc := NewFileCleanupService(refs, deleter, 7*24*time.Hour, zap.NewNop())
+
+	// A released file is swept right away, past the grace period or not.
+	ledger["/uploads/released.png"].CreatedAt = now.Add(-time.Minute)
+	if err := svc.Release(ctx, "/uploads/released.png", ""); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	report, err := svc.Sweep(ctx, true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if report.Checked != 4 || report.Orphans != 3 || report.Bytes != 30 || report.Deleted != 0 || len(deleter.stored) != 5 || len(checked) != 0 {
+		t.Errorf("dry run = %+v, stored %d, checked %d; want 3 orphans reported and nothing changed", report, len(deleter.stored), len(checked))
+	}
+
+	if err := svc.SweepOrphans(ctx); err == nil {
+		t.Error("SweepOrphans hid a failed delete")
+	}
+	for _, u := range []string{"/uploads/orphan.png", "/uploads/released.png"} {
+		if deleter.stored[u] || ledger[u] != nil {
+			t.Errorf("%s was not swept", u)
+		}
+	}
+	for _, u := range []string{"/uploads/in-use.png", "/uploads/stuck.png", "/uploads/fresh.png"} {
+		if !deleter.stored[u] || ledger[u] == nil {
+			t.Errorf("%s was swept", u)
+		}
+	}
+	if len(checked) != 1 || checked[0] != ledger["/uploads/in-use.png"].ID {
+		t.Errorf("checked = %v, want only the file in use", checked)
+	}
+}
@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/careplus/pharmacy-backend/pkg/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type flashSaleService struct {
+	repo        outbound.FlashSaleRepository
+	productRepo outbound.ProductRepository
+	logger      *zap.Logger
+}
+
+func NewFlashSaleService(repo outbound.FlashSaleRepository, productRepo outbound.ProductRepository, logger *zap.Logger) inbound.FlashSaleService {
+	return &flashSaleService{repo: repo, productRepo: productRepo, logger: logger}
+}
+
+func (s *flashSaleService) Create(ctx context.Context, pharmacyID, createdBy uuid.UUID, input inbound.FlashSaleInput) (*models.FlashSale, error) {
+	if len(input.Items) == 0 {
+		return nil, errors.ErrValidation("at least one item is required")
+	}
+	f := &models.FlashSale{PharmacyID: pharmacyID, CreatedBy: createdBy, IsActive: true}
+	if err := s.applyInput(f, input); err != nil {
+		return nil, err
+	}
+	items, err := s.buildItems(ctx, f, input.Items)
+	if err != nil {
+		return nil, err
+	}
+	f.Items = items
+	if err := s.repo.Create(ctx, f); err != nil {
+		return nil, errors.ErrInternal("failed to create flash sale", err)
+	}
+	return s.repo.GetByID(ctx, f.ID)
+}
+
+func (s *flashSaleService) GetByID(ctx context.Context, pharmacyID, id uuid.UUID) (*models.FlashSale, error) {
+	f, err := s.repo.GetByID(ctx, id)
+	if err != nil || f == nil || f.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("flash sale")
+	}
+	return f, nil
+}
+
+func (s *flashSaleService) List(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.FlashSale, int64, error) {
+	return s.repo.ListByPharmacy(ctx, pharmacyID, limit, offset)
+}
+
+func (s *flashSaleService) Update(ctx context.Context, pharmacyID, id uuid.UUID, input inbound.FlashSaleInput) (*models.FlashSale, error) {
+	f, err := s.GetByID(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	started := !time.Now().Before(f.StartsAt)
+	if started && len(input.Items) > 0 {
+		return nil, errors.ErrValidation("items cannot be changed after the sale has started")
+	}
+	if err := s.applyInput(f, input); err != nil {
+		return nil, err
+	}
+	var items []models.FlashSaleItem
+	if !started && len(input.Items) > 0 {
+		if items, err = s.buildItems(ctx, f, input.Items); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.Update(ctx, f); err != nil {
+		return nil, errors.ErrInternal("failed to update flash sale", err)
+	}
+	if items != nil {
+		if err := s.repo.ReplaceItems(ctx, f.ID, items); err != nil {
+			return nil, errors.ErrInternal("failed to update flash sale items", err)
+		}
+	}
+	return s.repo.GetByID(ctx, f.ID)
+}
+
+func (s *flashSaleService) Delete(ctx context.Context, pharmacyID, id uuid.UUID) error {
+	if _, err := s.GetByID(ctx, pharmacyID, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return errors.ErrInternal("failed to delete flash sale", err)
+	}
+	return nil
+}
+
+func (s *flashSaleService) applyInput(f *models.FlashSale, input inbound.FlashSaleInput) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return errors.ErrValidation("name is required")
+	}
+	if !input.EndsAt.After(input.StartsAt) {
+		return errors.ErrValidation("ends_at must be after starts_at")
+	}
+	f.Name = strings.TrimSpace(input.Name)
+	f.Description = input.Description
+	f.StartsAt = input.StartsAt
+	f.EndsAt = input.EndsAt
+	if input.IsActive != nil {
+		f.IsActive = *input.IsActive
+	}
+	return nil
+}
+
+// buildItems validates products (same pharmacy, price below regular, not in another overlapping sale).
+func (s *flashSaleService) buildItems(ctx context.Context, f *models.FlashSale, inputs []inbound.FlashSaleItemInput) ([]models.FlashSaleItem, error) {
+	seen := make(map[uuid.UUID]bool, len(inputs))
+	items := make([]models.FlashSaleItem, 0, len(inputs))
+	for _, in := range inputs {
+		if seen[in.ProductID] {
+			return nil, errors.ErrValidation("each product can appear only once in a flash sale")
+		}
+		seen[in.ProductID] = true
+		prod, err := s.productRepo.GetByID(ctx, in.ProductID)
+		if err != nil || prod == nil || prod.PharmacyID != f.PharmacyID {
+			return nil, errors.ErrNotFound("product")
+		}
+		if in.SalePrice <= 0 || in.SalePrice >= prod.UnitPrice {
+			return nil, errors.ErrValidation("sale price for " + prod.Name + " must be above 0 and below the regular price")
+		}
+		if in.MaxQuantity < 0 || in.PerCustomerLimit < 0 {
+			return nil, errors.ErrValidation("quantities cannot be negative")
+		}
+		n, err := s.repo.CountOverlappingItems(ctx, f.PharmacyID, in.ProductID, f.StartsAt, f.EndsAt, f.ID)
+		if err != nil {
+			return nil, errors.ErrInternal("failed to check overlapping sales", err)
+		}
+		if n > 0 {
+			return nil, errors.ErrConflict(prod.Name + " is already in another flash sale during this time")
+		}
+		items = append(items, models.FlashSaleItem{
+			ProductID:        in.ProductID,
+			SalePrice:        in.SalePrice,
+			MaxQuantity:      in.MaxQuantity,
+			PerCustomerLimit: in.PerCustomerLimit,
+		})
+	}
+	return items, nil
+}
+
+func (s *flashSaleService) ListLiveOffers(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID, earlyAccess time.Duration) ([]*inbound.FlashSaleOffer, error) {
+	now := time.Now()
+	items, err := s.repo.ListLiveItems(ctx, pharmacyID, productIDs, now, now.Add(earlyAccess))
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load flash sales", err)
+	}
+	offers := make([]*inbound.FlashSaleOffer, 0, len(items))
+	for _, it := range items {
+		if it.FlashSale == nil {
+			continue
+		}
+		o := &inbound.FlashSaleOffer{
+			FlashSaleID:      it.FlashSaleID,
+			FlashSaleItemID:  it.ID,
+			Name:             it.FlashSale.Name,
+			ProductID:        it.ProductID,
+			Product:          it.Product,
+			SalePrice:        it.SalePrice,
+			StartsAt:         it.FlashSale.StartsAt,
+			EndsAt:           it.FlashSale.EndsAt,
+			EndsInSeconds:    int64(it.FlashSale.EndsAt.Sub(now).Seconds()),
+			PerCustomerLimit: it.PerCustomerLimit,
+			EarlyAccess:      it.FlashSale.StartsAt.After(now),
+		}
+		if it.Product != nil {
+			o.RegularPrice = it.Product.UnitPrice
+		}
+		if it.MaxQuantity > 0 {
+			remaining := it.MaxQuantity - it.SoldQuantity
+			if remaining < 0 {
+				remaining = 0
+			}
+			o.Remaining = &remaining
+		}
+		offers = append(offers, o)
+	}
+	return offers, nil
+}
+
+func (s *flashSaleService) ReserveForOrder(ctx context.Context, pharmacyID uuid.UUID, customerKey string, earlyAccess time.Duration, items []inbound.OrderItemInput) (_ []*inbound.FlashSaleReservation, err error) {
+	ctx, span := tracing.Start(ctx, "FlashSaleService.ReserveForOrder")
+	defer tracing.End(span, &err)
+	out := make([]*inbound.FlashSaleReservation, len(items))
+	productIDs := make([]uuid.UUID, 0, len(items))
+	for _, it := range items {
+		productIDs = append(productIDs, it.ProductID)
+	}
+	now := time.Now()
+	live, err := s.repo.ListLiveItems(ctx, pharmacyID, productIDs, now, now.Add(earlyAccess))
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load flash sales", err)
+	}
+	if len(live) == 0 {
+		return out, nil
+	}
+	byProduct := make(map[uuid.UUID]*models.FlashSaleItem, len(live))
+	for _, it := range live {
+		if _, ok := byProduct[it.ProductID]; !ok {
+			byProduct[it.ProductID] = it
+		}
+	}
+	for i, it := range items {
+		fi, ok := byProduct[it.ProductID]
+		if !ok {
+			continue
+		}
+		name := "product"
+		if fi.Product != nil {
+			name = fi.Product.Name
+		}
+		r := &inbound.FlashSaleReservation{FlashSaleItemID: fi.ID, ProductID: it.ProductID, Quantity: it.Quantity, SalePrice: fi.SalePrice}
+		// The customer's count is raised with a conditional upsert, so two orders cannot both pass the limit.
+		if fi.PerCustomerLimit > 0 && customerKey != "" {
+			ok, err := s.repo.ReserveCustomerQuantity(ctx, fi.ID, customerKey, it.Quantity, fi.PerCustomerLimit)
+			if err != nil {
+				s.ReleaseReservations(ctx, out)
+				return nil, errors.ErrInternal("failed to check flash sale limit", err)
+			}
+			if !ok {
+				s.ReleaseReservations(ctx, out)
+				return nil, errors.ErrValidation("flash sale limit of " + strconv.Itoa(fi.PerCustomerLimit) + " per customer reached for " + name)
+			}
+			r.CustomerKey = customerKey
+		}
+		ok, err := s.repo.ReserveItem(ctx, fi.ID, it.Quantity)
+		if err != nil || !ok {
+			s.releaseCustomerQuantity(ctx, r.FlashSaleItemID, r.CustomerKey, r.Quantity)
+			s.ReleaseReservations(ctx, out)
+			if err != nil {
+				return nil, errors.ErrInternal("failed to reserve flash sale quantity", err)
+			}
+			return nil, errors.ErrConflict("not enough flash sale quantity left for " + name)
+		}
+		out[i] = r
+	}
+	return out, nil
+}
+
+// releaseCustomerQuantity gives units back to the customer's per-customer count; a no-op without a key.
+func (s *flashSaleService) releaseCustomerQuantity(ctx context.Context, itemID uuid.UUID, customerKey string, qty int) {
+	if customerKey == "" {
+		return
+	}
+	if err := s.repo.ReleaseCustomerQuantity(ctx, itemID, customerKey, qty); err != nil {
+		s.logger.Warn("failed to release flash sale customer quantity", zap.Error(err), zap.String("flash_sale_item_id", itemID.String()))
+	}
+}
+
+func (s *flashSaleService) ReleaseReservations(ctx context.Context, reservations []*inbound.FlashSaleReservation) {
+	for _, r := range reservations {
+		if r == nil {
+			continue
+		}
+		if err := s.repo.ReleaseItem(ctx, r.FlashSaleItemID, r.Quantity); err != nil {
+			s.logger.Warn("failed to release flash sale quantity", zap.Error(err), zap.String("flash_sale_item_id", r.FlashSaleItemID.String()))
+		}
+		s.releaseCustomerQuantity(ctx, r.FlashSaleItemID, r.CustomerKey, r.Quantity)
+	}
+}
+
+func (s *flashSaleService) CommitReservations(ctx context.Context, orderID uuid.UUID, reservations []*inbound.FlashSaleReservation) error {
+	for _, r := range reservations {
+		if r == nil {
+			continue
+		}
+		rd := &models.FlashSaleRedemption{FlashSaleItemID: r.FlashSaleItemID, CustomerKey: r.CustomerKey, OrderID: orderID, Quantity: r.Quantity}
+		if err := s.repo.CreateRedemption(ctx, rd); err != nil {
+			return errors.ErrInternal("failed to record flash sale redemption", err)
+		}
+	}
+	return nil
+}
+
+func (s *flashSaleService) ReleaseForOrder(ctx context.Context, orderID uuid.UUID) error {
+	list, err := s.repo.ListRedemptionsByOrder(ctx, orderID)
+	if err != nil {
+		return errors.ErrInternal("failed to load flash sale redemptions", err)
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	for _, rd := range list {
+		if err := s.repo.ReleaseItem(ctx, rd.FlashSaleItemID, rd.Quantity); err != nil {
+			return errors.ErrInternal("failed to release flash sale quantity", err)
+		}
+		if rd.CustomerKey != "" {
+			if err := s.repo.ReleaseCustomerQuantity(ctx, rd.FlashSaleItemID, rd.CustomerKey, rd.Quantity); err != nil {
+				return errors.ErrInternal("failed to release flash sale customer quantity", err)
+			}
+		}
+	}
+	if err := s.repo.DeleteRedemptionsByOrder(ctx, orderID); err != nil {
+		return errors.ErrInternal("failed to delete flash sale redemptions", err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func liveFlashItem(productID uuid.UUID, price float64, maxQty, perCustomer int) *models.FlashSaleItem {
+	now := time.Now()
+	return &models.FlashSaleItem{
+		ID: uuid.New(), ProductID: productID, SalePrice: price, MaxQuantity: maxQty, PerCustomerLimit: perCustomer,
+		FlashSale: &models.FlashSale{Name: "Weekend", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), IsActive: true},
+		Product:   &models.Product{Name: "Vitamin C", UnitPrice: 100},
+	}
+}
+
+func TestFlashSaleService_ReserveForOrder_AppliesSalePrice(t *testing.T) {
+	ctx := context.Background()
+	onSale, regular := uuid.New(), uuid.New()
+	item := liveFlashItem(onSale, 60, 10, 0)
+	repo := &mocks.MockFlashSaleRepository{}
+	repo.ListLiveItemsFunc = func(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID, now, startsBy time.Time) ([]*models.FlashSaleItem, error) {
+		return []*models.FlashSaleItem{item}, nil
+	}
+	svc := NewFlashSaleService(repo, &mocks.MockProductRepository{}, zap.NewNop())
+
+	res, err := svc.ReserveForOrder(ctx, uuid.New(), "9800000000", 0, []inbound.OrderItemInput{
+		{ProductID: regular, Quantity: 1, UnitPrice: 50},
+		{ProductID: onSale, Quantity: 2, UnitPrice: 100},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res[0] != nil {
+		t.Errorf("expected no reservation for regular product")
+	}
+	if res[1] == nil || res[1].SalePrice != 60 || res[1].Quantity != 2 || res[1].FlashSaleItemID != item.ID {
+		t.Errorf("unexpected reservation: %+v", res[1])
+	}
+}
+
+func TestFlashSaleService_ReserveForOrder_SoldOutReleasesEarlierLines(t *testing.T) {
+	ctx := context.Background()
+	a, b := uuid.New(), uuid.New()
+	itemA, itemB := liveFlashItem(a, 60, 10, 0), liveFlashItem(b, 40, 1, 0)
+	repo := &mocks.MockFlashSaleRepository{}
+	repo.ListLiveItemsFunc = func(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID, now, startsBy time.Time) ([]*models.FlashSaleItem, error) {
+		return []*models.FlashSaleItem{itemA, itemB}, nil
+	}
+	repo.ReserveItemFunc = func(ctx context.Context, itemID uuid.UUID, qty int) (bool, error) {
+		return itemID == itemA.ID, nil
+	}
+	released := map[uuid.UUID]int{}
+	repo.ReleaseItemFunc = func(ctx context.Context, itemID uuid.UUID, qty int) error {
+		released[itemID] += qty
+		return nil
+	}
+	svc := NewFlashSaleService(repo, &mocks.MockProductRepository{}, zap.NewNop())
+
+	_, err := svc.ReserveForOrder(ctx, uuid.New(), "9800000000", 0, []inbound.OrderItemInput{
+		{ProductID: a, Quantity: 3, UnitPrice: 100},
+		{ProductID: b, Quantity: 2, UnitPrice: 100},
+	})
+	if err == nil {
+		t.Fatal("expected error when cap is exceeded")
+	}
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeConflict {
+		t.Errorf("expected conflict, got %v", err)
+	}
+	if released[itemA.ID] != 3 || released[itemB.ID] != 0 {
+		t.Errorf("expected only the first line released, got %v", released)
+	}
+}
+
+func TestFlashSaleService_ReserveForOrder_PerCustomerLimit(t *testing.T) {
+	ctx := context.Background()
+	p := uuid.New()
+	item := liveFlashItem(p, 60, 0, 2)
+	repo := &mocks.MockFlashSaleRepository{}
+	repo.ListLiveItemsFunc = func(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID, now, startsBy time.Time) ([]*models.FlashSaleItem, error) {
+		return []*models.FlashSaleItem{item}, nil
+	}
+	repo.ReserveCustomerQuantityFunc = func(ctx context.Context, itemID uuid.UUID, customerKey string, qty, limit int) (bool, error) {
+		if customerKey != "9800000000" || qty != 2 || limit != 2 {
+			t.Errorf("customer count taken as %q %d/%d, want 9800000000 2/2", customerKey, qty, limit)
+		}
+		return false, nil
+	}
+	reserved := false
+	repo.ReserveItemFunc = func(ctx context.Context, itemID uuid.UUID, qty int) (bool, error) {
+		reserved = true
+		return true, nil
+	}
+	svc := NewFlashSaleService(repo, &mocks.MockProductRepository{}, zap.NewNop())
+
+	_, err := svc.ReserveForOrder(ctx, uuid.New(), "9800000000", 0, []inbound.OrderItemInput{{ProductID: p, Quantity: 2, UnitPrice: 100}})
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if reserved {
+		t.Error("cap should not be touched when the customer limit is reached")
+	}
+}
+
+func TestFlashSaleService_ReserveForOrder_EarlyAccessWidensStartWindow(t *testing.T) {
+	ctx := context.Background()
+	p := uuid.New()
+	var gotEarly time.Duration
+	repo := &mocks.MockFlashSaleRepository{}
+	repo.ListLiveItemsFunc = func(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID, now, startsBy time.Time) ([]*models.FlashSaleItem, error) {
+		gotEarly = startsBy.Sub(now)
+		return nil, nil
+	}
+	svc := NewFlashSaleService(repo, &mocks.MockProductRepository{}, zap.NewNop())
+
+	if _, err := svc.ReserveForOrder(ctx, uuid.New(), "9800000000", 30*time.Minute, []inbound.OrderItemInput{{ProductID: p, Quantity: 1, UnitPrice: 100}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotEarly != 30*time.Minute {
+		t.Errorf("sales starting within %v were included, want 30m", gotEarly)
+	}
+}
+
+func TestFlashSaleService_ReserveForOrder_SoldOutReturnsCustomerCount(t *testing.T) {
+	ctx := context.Background()
+	p := uuid.New()
+	item := liveFlashItem(p, 60, 1, 2)
+	repo := &mocks.MockFlashSaleRepository{}
+	repo.ListLiveItemsFunc = func(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID, now, startsBy time.Time) ([]*models.FlashSaleItem, error) {
+		return []*models.FlashSaleItem{item}, nil
+	}
+	repo.ReserveItemFunc = func(ctx context.Context, itemID uuid.UUID, qty int) (bool, error) { return false, nil }
+	released := 0
+	repo.ReleaseCustomerQuantityFunc = func(ctx context.Context, itemID uuid.UUID, customerKey string, qty int) error {
+		released += qty
+		return nil
+	}
+	svc := NewFlashSaleService(repo, &mocks.MockProductRepository{}, zap.NewNop())
+
+	_, err := svc.ReserveForOrder(ctx, uuid.New(), "9800000000", 0, []inbound.OrderItemInput{{ProductID: p, Quantity: 2, UnitPrice: 100}})
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeConflict {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+	if released != 2 {
+		t.Errorf("released %d units of the customer's count, want 2", released)
+	}
+}
+
+func TestFlashSaleService_CommitAndReleaseForOrder_TrackCustomerCount(t *testing.T) {
+	ctx := context.Background()
+	orderID, limited, open := uuid.New(), uuid.New(), uuid.New()
+	repo := &mocks.MockFlashSaleRepository{}
+	svc := NewFlashSaleService(repo, &mocks.MockProductRepository{}, zap.NewNop())
+
+	err := svc.CommitReservations(ctx, orderID, []*inbound.FlashSaleReservation{
+		{FlashSaleItemID: limited, Quantity: 2, CustomerKey: "9800000000"},
+		nil,
+		{FlashSaleItemID: open, Quantity: 1},
+	})
+	if err != nil {
+		t.Fatalf("CommitReservations: %v", err)
+	}
+	if len(repo.Redemptions) != 2 || repo.Redemptions[0].CustomerKey != "9800000000" || repo.Redemptions[1].CustomerKey != "" {
+		t.Fatalf("redemptions = %+v, want the key only on the counted line", repo.Redemptions)
+	}
+
+	repo.ListRedemptionsByOrderFunc = func(ctx context.Context, id uuid.UUID) ([]*models.FlashSaleRedemption, error) {
+		return repo.Redemptions, nil
+	}
+	customerReleased := map[uuid.UUID]int{}
+	repo.ReleaseCustomerQuantityFunc = func(ctx context.Context, itemID uuid.UUID, customerKey string, qty int) error {
+		customerReleased[itemID] += qty
+		return nil
+	}
+	if err := svc.ReleaseForOrder(ctx, orderID); err != nil {
+		t.Fatalf("ReleaseForOrder: %v", err)
+	}
+	if customerReleased[limited] != 2 || len(customerReleased) != 1 {
+		t.Errorf("customer counts released = %v, want 2 units of the limited item only", customerReleased)
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const giftCardCodeLen = 12 // generated codes use referralCodeChars
+
+type giftCardService struct {
+	repo         outbound.GiftCardRepository
+	customerRepo outbound.CustomerRepository
+	configRepo   outbound.PharmacyConfigRepository
+	logger       *zap.Logger
+}
+
+// NewGiftCardService returns the service. configRepo supplies the pharmacy's base currency, which cards are issued in.
+func NewGiftCardService(repo outbound.GiftCardRepository, customerRepo outbound.CustomerRepository, configRepo outbound.PharmacyConfigRepository, logger *zap.Logger) inbound.GiftCardService {
+	return &giftCardService{repo: repo, customerRepo: customerRepo, configRepo: configRepo, logger: logger}
+}
+
+func (s *giftCardService) config(ctx context.Context, pharmacyID uuid.UUID) *models.PharmacyConfig {
+	if s.configRepo == nil {
+		return nil
+	}
+	cfg, _ := s.configRepo.GetByPharmacyID(ctx, pharmacyID)
+	return cfg
+}
+
+func normalizeGiftCardCode(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+func (s *giftCardService) generateCode(ctx context.Context, pharmacyID uuid.UUID) (string, error) {
+	for i := 0; i < 20; i++ {
+		var b strings.Builder
+		for j := 0; j < giftCardCodeLen; j++ {
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(referralCodeChars))))
+			if err != nil {
+				return "", errors.ErrInternal("failed to generate gift card code", err)
+			}
+			b.WriteByte(referralCodeChars[n.Int64()])
+		}
+		if existing, err := s.repo.GetByCode(ctx, pharmacyID, b.String()); err != nil || existing == nil {
+			return b.String(), nil
+		}
+	}
+	return "", errors.ErrInternal("failed to generate unique gift card code", nil)
+}
+
+func (s *giftCardService) Issue(ctx context.Context, pharmacyID, issuedBy uuid.UUID, in inbound.GiftCardInput) (*models.GiftCard, error) {
+	amount := roundMoney(in.Amount)
+	if amount <= 0 {
+		return nil, errors.ErrValidation("amount must be positive")
+	}
+	if in.ExpiresAt != nil && !in.ExpiresAt.After(time.Now()) {
+		return nil, errors.ErrValidation("expires_at must be in the future")
+	}
+	if in.CustomerID != nil {
+		c, err := s.customerRepo.GetByID(ctx, *in.CustomerID)
+		if err != nil || c == nil || c.PharmacyID != pharmacyID {
+			return nil, errors.ErrNotFound("customer")
+		}
+	}
+	code := normalizeGiftCardCode(in.Code)
+	if code == "" {
+		generated, err := s.generateCode(ctx, pharmacyID)
+		if err != nil {
+			return nil, err
+		}
+		code = generated
+	} else if existing, err := s.repo.GetByCode(ctx, pharmacyID, code); err == nil && existing != nil {
+		return nil, errors.ErrConflict("gift card code already exists")
+	}
+	card := &models.GiftCard{
+		PharmacyID:    pharmacyID,
+		Code:          code,
+		InitialAmount: amount,
+		Balance:       amount,
+		Currency:      s.config(ctx, pharmacyID).Currency(),
+		ExpiresAt:     in.ExpiresAt,
+		IsActive:      true,
+		CustomerID:    in.CustomerID,
+		Note:          strings.TrimSpace(in.Note),
+		IssuedBy:      issuedBy,
+	}
+	issue := &models.GiftCardTransaction{Type: models.StoredValueIssue, Amount: amount, BalanceAfter: amount, CreatedBy: issuedBy}
+	if err := s.repo.Create(ctx, card, issue); err != nil {
+		return nil, errors.ErrInternal("failed to issue gift card", err)
+	}
+	return card, nil
+}
+
+func (s *giftCardService) List(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.GiftCard, int64, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	list, total, err := s.repo.List(ctx, pharmacyID, limit, offset)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to list gift cards", err)
+	}
+	return list, total, nil
+}
+
+func (s *giftCardService) get(ctx context.Context, pharmacyID, id uuid.UUID) (*models.GiftCard, error) {
+	card, err := s.repo.GetByID(ctx, id)
+	if err != nil || card == nil || card.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("gift card")
+	}
+	return card, nil
+}
+
+func (s *giftCardService) Get(ctx context.Context, pharmacyID, id uuid.UUID) (*inbound.GiftCardView, error) {
+	card, err := s.get(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	txns, err := s.repo.ListTransactions(ctx, card.ID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load gift card transactions", err)
+	}
+	return &inbound.GiftCardView{Card: card, Transactions: txns}, nil
+}
+
+func (s *giftCardService) Check(ctx context.Context, pharmacyID uuid.UUID, code string) (*inbound.GiftCardBalance, error) {
+	code = normalizeGiftCardCode(code)
+	if code == "" {
+		return nil, errors.ErrValidation("gift card code is required")
+	}
+	card, err := s.repo.GetByCode(ctx, pharmacyID, code)
+	if err != nil || card == nil {
+		return nil, errors.ErrNotFound("gift card")
+	}
+	usable, reason := card.Usable(time.Now())
+	return &inbound.GiftCardBalance{
+		Code:      card.Code,
+		Balance:   card.Balance,
+		Currency:  card.Currency,
+		ExpiresAt: card.ExpiresAt,
+		Usable:    usable,
+		Reason:    reason,
+	}, nil
+}
+
+func (s *giftCardService) Redeem(ctx context.Context, pharmacyID uuid.UUID, code string, amount float64, orderID *uuid.UUID, actorID uuid.UUID) (*models.GiftCardTransaction, error) {
+	amount = roundMoney(amount)
+	if amount <= 0 {
+		return nil, errors.ErrValidation("amount must be positive")
+	}
+	card, err := s.repo.GetByCode(ctx, pharmacyID, normalizeGiftCardCode(code))
+	if err != nil || card == nil {
+		return nil, errors.ErrNotFound("gift card")
+	}
+	if usable, reason := card.Usable(time.Now()); !usable {
+		return nil, errors.ErrValidation(reason)
+	}
+	t := &models.GiftCardTransaction{GiftCardID: card.ID, Type: models.StoredValueRedeem, Amount: -amount, OrderID: orderID, CreatedBy: actorID}
+	ok, err := s.repo.Adjust(ctx, t)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to redeem gift card", err)
+	}
+	if !ok {
+		return nil, errors.ErrValidation("gift card balance is too low")
+	}
+	return t, nil
+}
+
+func (s *giftCardService) Deactivate(ctx context.Context, pharmacyID, id uuid.UUID) (*models.GiftCard, error) {
+	card, err := s.get(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetActive(ctx, card.ID, false); err != nil {
+		return nil, errors.ErrInternal("failed to deactivate gift card", err)
+	}
+	card.IsActive = false
+	return card, nil
+}
+
+func (s *giftCardService) ReverseForOrder(ctx context.Context, orderID, actorID uuid.UUID) error {
+	txns, err := s.repo.ListTransactionsByOrder(ctx, orderID)
+	if err != nil {
+		return errors.ErrInternal("failed to load gift card transactions", err)
+	}
+	held := map[uuid.UUID]float64{}
+	var cards []uuid.UUID
+	for _, t := range txns {
+		if t.Type != models.StoredValueRedeem && t.Type != models.StoredValueReversal {
+			continue
+		}
+		if _, seen := held[t.GiftCardID]; !seen {
+			cards = append(cards, t.GiftCardID)
+		}
+		held[t.GiftCardID] -= t.Amount
+	}
+	for _, cardID := range cards {
+		amount := roundMoney(held[cardID])
+		if amount <= 0 {
+			continue
+		}
+		t := &models.GiftCardTransaction{GiftCardID: cardID, Type: models.StoredValueReversal, Amount: amount, OrderID: &orderID, CreatedBy: actorID}
+		if _, err := s.repo.Adjust(ctx, t); err != nil {
+			return errors.ErrInternal("failed to reverse gift card redemption", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestGiftCardService_Issue_GeneratesCodeAndRecordsIssue(t *testing.T) {
+	pharmacyID := uuid.New()
+	var issued *models.GiftCardTransaction
+	repo := &mocks.MockGiftCardRepository{
+		CreateFunc: func(ctx context.Context, card *models.GiftCard, issue *models.GiftCardTransaction) error {
+			issued = issue
+			return nil
+		},
+	}
+	configs := &mocks.MockPharmacyConfigRepository{GetByPharmacyIDFunc: func(ctx context.Context, pid uuid.UUID) (*models.PharmacyConfig, error) {
+		return &models.PharmacyConfig{PharmacyID: pid, BaseCurrency: "USD"}, nil
+	}}
+	svc := NewGiftCardService(repo, &mocks.MockCustomerRepository{}, configs, zap.NewNop())
+
+	card, err := svc.Issue(context.Background(), pharmacyID, uuid.New(), inbound.GiftCardInput{Amount: 500})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if len(card.Code) != giftCardCodeLen || card.Balance != 500 || card.Currency != "USD" || !card.IsActive {
+		t.Errorf("card = %+v, want a %d-char code with balance 500 USD (the base currency)", card, giftCardCodeLen)
+	}
+	if issued == nil || issued.Type != models.StoredValueIssue || issued.Amount != 500 {
+		t.Errorf("issue transaction = %+v", issued)
+	}
+}
+
+func TestGiftCardService_Redeem_BalanceTooLow(t *testing.T) {
+	card := &models.GiftCard{ID: uuid.New(), Code: "GIFT1234", Balance: 100, IsActive: true}
+	repo := &mocks.MockGiftCardRepository{
+		GetByCodeFunc: func(ctx context.Context, pharmacyID uuid.UUID, code string) (*models.GiftCard, error) {
+			if code != "GIFT1234" {
+				return nil, nil
+			}
+			return card, nil
+		},
+		AdjustFunc: func(ctx context.Context, t *models.GiftCardTransaction) (bool, error) {
+			return card.Balance+t.Amount >= 0, nil
+		},
+	}
+	svc := &giftCardService{repo: repo, logger: zap.NewNop()}
+
+	_, err := svc.Redeem(context.Background(), uuid.New(), "gift-1234", 150, nil, uuid.New())
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("err = %v, want validation error", err)
+	}
+	txn, err := svc.Redeem(context.Background(), uuid.New(), "gift-1234", 60, nil, uuid.New())
+	if err != nil {
+		t.Fatalf("Redeem: %v", err)
+	}
+	if txn.Amount != -60 || txn.Type != models.StoredValueRedeem {
+		t.Errorf("txn = %+v, want redeem of -60", txn)
+	}
+}
+
+func TestGiftCardService_ReverseForOrder_ReturnsNetRedeemed(t *testing.T) {
+	orderID, cardID := uuid.New(), uuid.New()
+	var reversed []*models.GiftCardTransaction
+	repo := &mocks.MockGiftCardRepository{
+		ListTransactionsByOrderFunc: func(ctx context.Context, id uuid.UUID) ([]*models.GiftCardTransaction, error) {
+			return []*models.GiftCardTransaction{
+				{GiftCardID: cardID, Type: models.StoredValueRedeem, Amount: -80},
+				{GiftCardID: cardID, Type: models.StoredValueReversal, Amount: 30},
+			}, nil
+		},
+		AdjustFunc: func(ctx context.Context, t *models.GiftCardTransaction) (bool, error) {
+			reversed = append(reversed, t)
+			return true, nil
+		},
+	}
+	svc := &giftCardService{repo: repo, logger: zap.NewNop()}
+
+	if err := svc.ReverseForOrder(context.Background(), orderID, uuid.New()); err != nil {
+		t.Fatalf("ReverseForOrder: %v", err)
+	}
+	if len(reversed) != 1 || reversed[0].Amount != 50 || reversed[0].Type != models.StoredValueReversal {
+		t.Errorf("reversals = %+v, want one of 50", reversed)
+	}
+}
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	trendingDefaultDays  = 7
+	trendingMaxDays      = 90
+	trendingDefaultLimit = 10
+)
+
+type hashtagService struct {
+	repo        outbound.HashtagRepository
+	viewRepo    outbound.ProductViewRepository
+	productRepo outbound.ProductRepository
+	logger      *zap.Logger
+	now         func() time.Time
+}
+
+func NewHashtagService(repo outbound.HashtagRepository, viewRepo outbound.ProductViewRepository, productRepo outbound.ProductRepository, logger *zap.Logger) inbound.HashtagService {
+	return &hashtagService{repo: repo, viewRepo: viewRepo, productRepo: productRepo, logger: logger, now: time.Now}
+}
+
+// registry indexes the pharmacy's hashtags by tag and by alias.
+type registry struct {
+	list    []*models.Hashtag
+	byTag   map[string]*models.Hashtag
+	byAlias map[string]*models.Hashtag
+}
+
+func (s *hashtagService) registry(ctx context.Context, pharmacyID uuid.UUID) (*registry, error) {
+	list, err := s.repo.ListByPharmacy(ctx, pharmacyID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load hashtags", err)
+	}
+	reg := &registry{list: list, byTag: make(map[string]*models.Hashtag), byAlias: make(map[string]*models.Hashtag)}
+	for _, h := range list {
+		reg.byTag[h.Tag] = h
+		for _, a := range h.Aliases {
+			reg.byAlias[a] = h
+		}
+	}
+	return reg, nil
+}
+
+// resolve maps a raw tag to its registry entry, if any, and the canonical tag it should be stored as.
+func (r *registry) resolve(raw string) (*models.Hashtag, string) {
+	tag := models.CanonicalHashtag(raw)
+	if h := r.byTag[tag]; h != nil {
+		return h, h.Tag
+	}
+	if h := r.byAlias[tag]; h != nil {
+		return h, h.Tag
+	}
+	return nil, tag
+}
+
+func (s *hashtagService) List(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Hashtag, error) {
+	reg, err := s.registry(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.repo.Usage(ctx, pharmacyID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to count hashtag usage", err)
+	}
+	var unregistered []*models.Hashtag
+	for _, u := range usage {
+		if h := reg.byTag[u.Tag]; h != nil {
+			h.ProductCount += u.ProductCount
+			continue
+		}
+		unregistered = append(unregistered, &models.Hashtag{PharmacyID: pharmacyID, Tag: u.Tag, Aliases: []string{}, ProductCount: u.ProductCount})
+	}
+	out := append([]*models.Hashtag{}, reg.list...)
+	return append(out, unregistered...), nil
+}
+
+// applyInput canonicalizes and checks input against the registry; h is the entry being saved (ID may be new).
+func (s *hashtagService) applyInput(reg *registry, h *models.Hashtag, input inbound.HashtagInput) error {
+	tag := models.CanonicalHashtag(input.Tag)
+	if tag == "" {
+		return errors.ErrValidation("tag must contain letters or digits")
+	}
+	if other := reg.byTag[tag]; other != nil && other.ID != h.ID {
+		return errors.ErrConflict("hashtag #" + tag + " already exists")
+	}
+	if other := reg.byAlias[tag]; other != nil && other.ID != h.ID {
+		return errors.ErrConflict("#" + tag + " is an alias of #" + other.Tag + "; merge instead")
+	}
+	aliases := []string{}
+	seen := map[string]bool{tag: true}
+	for _, raw := range input.Aliases {
+		a := models.CanonicalHashtag(raw)
+		if a == "" || seen[a] {
+			continue
+		}
+		if other := reg.byTag[a]; other != nil && other.ID != h.ID {
+			return errors.ErrConflict("#" + a + " is a hashtag of its own; merge it instead")
+		}
+		if other := reg.byAlias[a]; other != nil && other.ID != h.ID {
+			return errors.ErrConflict("#" + a + " is already an alias of #" + other.Tag)
+		}
+		seen[a] = true
+		aliases = append(aliases, a)
+	}
+	h.Tag, h.Aliases, h.IsBlocked = tag, aliases, input.IsBlocked
+	h.Label = strings.TrimSpace(input.Label)
+	return nil
+}
+
+// rewrite brings products in line with an entry: its old tag and aliases become the tag, or go when it is blocked.
+func (s *hashtagService) rewrite(ctx context.Context, h *models.Hashtag, oldTag string) {
+	from := append([]string{h.Tag}, h.Aliases...)
+	if oldTag != "" && oldTag != h.Tag {
+		from = append(from, oldTag)
+	}
+	to := h.Tag
+	if h.IsBlocked {
+		to = ""
+	} else {
+		from = from[1:]
+	}
+	if _, err := s.repo.ReplaceOnProducts(ctx, h.PharmacyID, from, to); err != nil {
+		s.logger.Warn("failed to rewrite product hashtags", zap.Error(err), zap.String("hashtag", h.Tag))
+	}
+}
+
+func (s *hashtagService) Create(ctx context.Context, pharmacyID uuid.UUID, input inbound.HashtagInput) (*models.Hashtag, error) {
+	reg, err := s.registry(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	h := &models.Hashtag{ID: uuid.New(), PharmacyID: pharmacyID}
+	if err := s.applyInput(reg, h, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, h); err != nil {
+		return nil, errors.ErrInternal("failed to create hashtag", err)
+	}
+	s.rewrite(ctx, h, "")
+	return h, nil
+}
+
+func (s *hashtagService) get(ctx context.Context, pharmacyID, id uuid.UUID) (*models.Hashtag, error) {
+	h, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load hashtag", err)
+	}
+	if h == nil || h.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("hashtag")
+	}
+	return h, nil
+}
+
+func (s *hashtagService) Update(ctx context.Context, pharmacyID, id uuid.UUID, input inbound.HashtagInput) (*models.Hashtag, error) {
+	h, err := s.get(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	reg, err := s.registry(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	oldTag := h.Tag
+	if err := s.applyInput(reg, h, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, h); err != nil {
+		return nil, errors.ErrInternal("failed to update hashtag", err)
+	}
+	s.rewrite(ctx, h, oldTag)
+	return h, nil
+}
+
+func (s *hashtagService) Delete(ctx context.Context, pharmacyID, id uuid.UUID) error {
+	if _, err := s.get(ctx, pharmacyID, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return errors.ErrInternal("failed to delete hashtag", err)
+	}
+	return nil
+}
+
+func (s *hashtagService) Merge(ctx context.Context, pharmacyID, sourceID, targetID uuid.UUID) (*models.Hashtag, int64, error) {
+	if sourceID == targetID {
+		return nil, 0, errors.ErrValidation("cannot merge a hashtag into itself")
+	}
+	source, err := s.get(ctx, pharmacyID, sourceID)
+	if err != nil {
+		return nil, 0, err
+	}
+	target, err := s.get(ctx, pharmacyID, targetID)
+	if err != nil {
+		return nil, 0, err
+	}
+	seen := map[string]bool{target.Tag: true}
+	for _, a := range target.Aliases {
+		seen[a] = true
+	}
+	for _, a := range append([]string{source.Tag}, source.Aliases...) {
+		if !seen[a] {
+			seen[a] = true
+			target.Aliases = append(target.Aliases, a)
+		}
+	}
+	n, err := s.repo.Merge(ctx, source, target)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to merge hashtags", err)
+	}
+	if target.IsBlocked {
+		s.rewrite(ctx, target, "")
+	}
+	return target, n, nil
+}
+
+func (s *hashtagService) Normalize(ctx context.Context, pharmacyID uuid.UUID, tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return tags, nil
+	}
+	reg, err := s.registry(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, raw := range tags {
+		h, tag := reg.resolve(raw)
+		if tag == "" || seen[tag] || (h != nil && h.IsBlocked) {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+		if h == nil {
+			nh := &models.Hashtag{PharmacyID: pharmacyID, Tag: tag, Aliases: []string{}}
+			if err := s.repo.Create(ctx, nh); err != nil {
+				s.logger.Warn("failed to register hashtag", zap.Error(err), zap.String("hashtag", tag))
+				continue
+			}
+			reg.byTag[tag] = nh
+		}
+	}
+	return out, nil
+}
+
+func (s *hashtagService) Sync(ctx context.Context, pharmacyID uuid.UUID) (int64, error) {
+	usage, err := s.repo.Usage(ctx, pharmacyID)
+	if err != nil {
+		return 0, errors.ErrInternal("failed to count hashtag usage", err)
+	}
+	raw := make([]string, len(usage))
+	for i, u := range usage {
+		raw[i] = u.Tag
+	}
+	if _, err := s.Normalize(ctx, pharmacyID, raw); err != nil {
+		return 0, err
+	}
+	reg, err := s.registry(ctx, pharmacyID)
+	if err != nil {
+		return 0, err
+	}
+	// Group stored values by what they should become; "" collects blocked and empty ones.
+	rewrites := make(map[string][]string)
+	for _, value := range raw {
+		h, tag := reg.resolve(value)
+		if h != nil && h.IsBlocked {
+			tag = ""
+		}
+		if tag != value {
+			rewrites[tag] = append(rewrites[tag], value)
+		}
+	}
+	var changed int64
+	for to, from := range rewrites {
+		n, err := s.repo.ReplaceOnProducts(ctx, pharmacyID, from, to)
+		if err != nil {
+			return changed, errors.ErrInternal("failed to rewrite product hashtags", err)
+		}
+		changed += n
+	}
+	return changed, nil
+}
+
+func (s *hashtagService) Trending(ctx context.Context, pharmacyID uuid.UUID, days int, by string, limit int) ([]*models.HashtagTrend, error) {
+	if days <= 0 {
+		days = trendingDefaultDays
+	}
+	if days > trendingMaxDays {
+		days = trendingMaxDays
+	}
+	if limit <= 0 || limit > 50 {
+		limit = trendingDefaultLimit
+	}
+	switch by {
+	case "":
+		by = "views"
+	case "views", "sales":
+	default:
+		return nil, errors.ErrValidation("by must be views or sales")
+	}
+	reg, err := s.registry(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	since := startOfDay(s.now()).AddDate(0, 0, -(days - 1))
+	rows, err := s.repo.Trending(ctx, pharmacyID, since)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load trending hashtags", err)
+	}
+	out := make([]*models.HashtagTrend, 0, len(rows))
+	for _, r := range rows {
+		h := reg.byTag[r.Tag]
+		if h != nil && h.IsBlocked {
+			continue
+		}
+		r.Label = r.Tag
+		if h != nil && h.Label != "" {
+			r.Label = h.Label
+		}
+		out = append(out, r)
+	}
+	metric := func(t *models.HashtagTrend) (int64, int64) {
+		if by == "sales" {
+			return t.UnitsSold, t.Views
+		}
+		return t.Views, t.UnitsSold
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a1, a2 := metric(out[i])
+		b1, b2 := metric(out[j])
+		if a1 != b1 {
+			return a1 > b1
+		}
+		if a2 != b2 {
+			return a2 > b2
+		}
+		return out[i].Tag < out[j].Tag
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (s *hashtagService) RecordProductView(ctx context.Context, pharmacyID, productID uuid.UUID) error {
+	p, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil || p == nil || p.PharmacyID != pharmacyID || !p.IsActive {
+		return errors.ErrNotFound("product")
+	}
+	if err := s.viewRepo.Increment(ctx, pharmacyID, productID, startOfDay(s.now())); err != nil {
+		return errors.ErrInternal("failed to record view", err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestCanonicalHashtag(t *testing.T) {
+	cases := map[string]string{
+		"#Vitamin C":      "vitamin-c",
+		"  ##skin_care ":  "skin-care",
+		"Pain -- Relief!": "pain-relief",
+		"औषधि":            "औषधि",
+		"#!!":             "",
+		"--cold & flu--":  "cold-flu",
+	}
+	for in, want := range cases {
+		if got := models.CanonicalHashtag(in); got != want {
+			t.Errorf("CanonicalHashtag(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func newHashtagService(repo *mocks.MockHashtagRepository) *hashtagService {
+	svc := NewHashtagService(repo, nil, &mocks.MockProductRepository{}, zap.NewNop()).(*hashtagService)
+	svc.now = func() time.Time { return time.Date(2026, 4, 10, 15, 0, 0, 0, time.UTC) }
+	return svc
+}
+
+func TestHashtagService_Normalize_AppliesAliasesAndBlocklist(t *testing.T) {
+	pharmacyID := uuid.New()
+	var registered []string
+	repo := &mocks.MockHashtagRepository{
+		ListByPharmacyFunc: func(ctx context.Context, pid uuid.UUID) ([]*models.Hashtag, error) {
+			return []*models.Hashtag{
+				{ID: uuid.New(), PharmacyID: pid, Tag: "vitamins", Aliases: []string{"vitamin", "vits"}},
+				{ID: uuid.New(), PharmacyID: pid, Tag: "cheap", IsBlocked: true},
+			}, nil
+		},
+		CreateFunc: func(ctx context.Context, h *models.Hashtag) error {
+			registered = append(registered, h.Tag)
+			return nil
+		},
+	}
+	got, err := newHashtagService(repo).Normalize(context.Background(), pharmacyID, []string{"#Vitamin", "Cheap", "Immune Boost", "vits", "#", "immune_boost"})
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	if want := []string{"vitamins", "immune-boost"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("tags = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(registered, []string{"immune-boost"}) {
+		t.Errorf("registered = %v, want only the new tag", registered)
+	}
+}
+
+func TestHashtagService_Create_AliasOfAnotherTagConflicts(t *testing.T) {
+	repo := &mocks.MockHashtagRepository{
+		ListByPharmacyFunc: func(ctx context.Context, pid uuid.UUID) ([]*models.Hashtag, error) {
+			return []*models.Hashtag{{ID: uuid.New(), PharmacyID: pid, Tag: "vitamins", Aliases: []string{"vitamin"}}}, nil
+		},
+	}
+	_, err := newHashtagService(repo).Create(context.Background(), uuid.New(), inbound.HashtagInput{Tag: "supplements", Aliases: []string{"Vitamin"}})
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeConflict {
+		t.Fatalf("err = %v, want conflict", err)
+	}
+}
+
+func TestHashtagService_Merge_FoldsSourceIntoTargetAliases(t *testing.T) {
+	pharmacyID := uuid.New()
+	source := &models.Hashtag{ID: uuid.New(), PharmacyID: pharmacyID, Tag: "vitamin", Aliases: []string{"vit"}}
+	target := &models.Hashtag{ID: uuid.New(), PharmacyID: pharmacyID, Tag: "vitamins", Aliases: []string{"vit"}}
+	var merged *models.Hashtag
+	repo := &mocks.MockHashtagRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Hashtag, error) {
+			if id == source.ID {
+				return source, nil
+			}
+			return target, nil
+		},
+		MergeFunc: func(ctx context.Context, s, t *models.Hashtag) (int64, error) {
+			merged = t
+			return 4, nil
+		},
+	}
+	got, n, err := newHashtagService(repo).Merge(context.Background(), pharmacyID, source.ID, target.ID)
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if n != 4 || merged != got || !reflect.DeepEqual(got.Aliases, []string{"vit", "vitamin"}) {
+		t.Errorf("merged aliases %v (%d products), want [vit vitamin] (4)", got.Aliases, n)
+	}
+}
+
+func TestHashtagService_Sync_RewritesStoredVariants(t *testing.T) {
+	pharmacyID := uuid.New()
+	reg := []*models.Hashtag{{ID: uuid.New(), PharmacyID: pharmacyID, Tag: "spam", IsBlocked: true}}
+	rewrites := map[string][]string{}
+	repo := &mocks.MockHashtagRepository{
+		ListByPharmacyFunc: func(ctx context.Context, pid uuid.UUID) ([]*models.Hashtag, error) { return reg, nil },
+		CreateFunc: func(ctx context.Context, h *models.Hashtag) error {
+			reg = append(reg, h)
+			return nil
+		},
+		UsageFunc: func(ctx context.Context, pid uuid.UUID) ([]*models.HashtagUsageRow, error) {
+			return []*models.HashtagUsageRow{{Tag: "organic"}, {Tag: "Organic"}, {Tag: "#SPAM"}, {Tag: "Skin Care"}}, nil
+		},
+		ReplaceOnProductsFunc: func(ctx context.Context, pid uuid.UUID, from []string, to string) (int64, error) {
+			sort.Strings(from)
+			rewrites[to] = from
+			return int64(len(from)), nil
+		},
+	}
+	n, err := newHashtagService(repo).Sync(context.Background(), pharmacyID)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	want := map[string][]string{"organic": {"Organic"}, "": {"#SPAM"}, "skin-care": {"Skin Care"}}
+	if n != 3 || !reflect.DeepEqual(rewrites, want) {
+		t.Errorf("rewrites = %v (%d), want %v", rewrites, n, want)
+	}
+}
+
+func TestHashtagService_Trending_RanksBySalesAndHidesBlocked(t *testing.T) {
+	var since time.Time
+	repo := &mocks.MockHashtagRepository{
+		ListByPharmacyFunc: func(ctx context.Context, pid uuid.UUID) ([]*models.Hashtag, error) {
+			return []*models.Hashtag{
+				{Tag: "cold-flu", Label: "Cold & Flu"},
+				{Tag: "hidden", IsBlocked: true},
+			}, nil
+		},
+		TrendingFunc: func(ctx context.Context, pid uuid.UUID, s time.Time) ([]*models.HashtagTrend, error) {
+			since = s
+			return []*models.HashtagTrend{
+				{Tag: "vitamins", Views: 90, UnitsSold: 3},
+				{Tag: "hidden", Views: 500, UnitsSold: 50},
+				{Tag: "cold-flu", Views: 10, UnitsSold: 12},
+			}, nil
+		},
+	}
+	got, err := newHashtagService(repo).Trending(context.Background(), uuid.New(), 3, "sales", 0)
+	if err != nil {
+		t.Fatalf("Trending: %v", err)
+	}
+	if want := time.Date(2026, 4, 8, 0, 0, 0, 0, time.UTC); !since.Equal(want) {
+		t.Errorf("since = %v, want %v", since, want)
+	}
+	if len(got) != 2 || got[0].Tag != "cold-flu" || got[0].Label != "Cold & Flu" || got[1].Label != "vitamins" {
+		t.Errorf("trending = %+v, want cold-flu (labelled) then vitamins", got)
+	}
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// idempotencyKeyTTL is how long a key replays its response; a retry after that runs the request again.
+	idempotencyKeyTTL = 24 * time.Hour
+	// idempotencyLockTimeout is when an in-progress key is taken to be abandoned (the instance died mid-request).
+	idempotencyLockTimeout = 2 * time.Minute
+	idempotencyKeyMaxLen   = 255
+)
+
+type idempotencyService struct {
+	repo   outbound.IdempotencyKeyRepository
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+func NewIdempotencyService(repo outbound.IdempotencyKeyRepository, logger *zap.Logger) inbound.IdempotencyService {
+	return &idempotencyService{repo: repo, logger: logger, now: time.Now}
+}
+
+func (s *idempotencyService) Begin(ctx context.Context, pharmacyID, userID uuid.UUID, scope, key, requestHash string) (*models.IdempotencyKey, bool, error) {
+	key = strings.TrimSpace(key)
+	if key == "" || len(key) > idempotencyKeyMaxLen {
+		return nil, false, errors.ErrValidation("Idempotency-Key must be 1 to 255 characters")
+	}
+	now := s.now()
+	rec := &models.IdempotencyKey{
+		PharmacyID:  pharmacyID,
+		UserID:      userID,
+		Scope:       scope,
+		Key:         key,
+		RequestHash: requestHash,
+		Status:      models.IdempotencyStatusInProgress,
+		ExpiresAt:   now.Add(idempotencyKeyTTL),
+	}
+	// Two rounds: an expired or abandoned key is deleted and claimed afresh.
+	for round := 0; round < 2; round++ {
+		created, err := s.repo.Create(ctx, rec)
+		if err != nil {
+			return nil, false, errors.ErrInternal("failed to store idempotency key", err)
+		}
+		if created {
+			return rec, false, nil
+		}
+		existing, err := s.repo.Get(ctx, userID, scope, key)
+		if err != nil {
+			return nil, false, errors.ErrInternal("failed to load idempotency key", err)
+		}
+		if existing == nil {
+			continue
+		}
+		stale := existing.ExpiresAt.Before(now) ||
+			(existing.Status == models.IdempotencyStatusInProgress && now.Sub(existing.UpdatedAt) > idempotencyLockTimeout)
+		if stale {
+			if err := s.repo.Delete(ctx, existing.ID); err != nil {
+				return nil, false, errors.ErrInternal("failed to release idempotency key", err)
+			}
+			continue
+		}
+		if existing.RequestHash != requestHash {
+			return nil, false, errors.ErrValidation("Idempotency-Key was already used for a different request")
+		}
+		if existing.Status == models.IdempotencyStatusCompleted {
+			return existing, true, nil
+		}
+		return nil, false, errors.ErrConflict("a request with this Idempotency-Key is still being processed; retry later")
+	}
+	return nil, false, errors.ErrConflict("a request with this Idempotency-Key is still being processed; retry later")
+}
+
+func (s *idempotencyService) Complete(ctx context.Context, rec *models.IdempotencyKey, status int, contentType string, body []byte) error {
+	if status >= 500 {
+		return s.Release(ctx, rec)
+	}
+	rec.Status = models.IdempotencyStatusCompleted
+	rec.ResponseStatus = status
+	rec.ContentType = contentType
+	rec.ResponseBody = body
+	return s.repo.Update(ctx, rec)
+}
+
+func (s *idempotencyService) Release(ctx context.Context, rec *models.IdempotencyKey) error {
+	return s.repo.Delete(ctx, rec.ID)
+}
+
+func (s *idempotencyService) PurgeExpired(ctx context.Context) error {
+	n, err := s.repo.DeleteExpired(ctx, s.now())
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		s.logger.Info("purged expired idempotency keys", zap.Int64("deleted", n))
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// idempotencyStore keeps keys in memory, enforcing the (user, scope, key) uniqueness of the real table.
+func idempotencyStore(now *time.Time) *mocks.MockIdempotencyKeyRepository {
+	rows := map[string]*models.IdempotencyKey{}
+	id := func(userID uuid.UUID, scope, key string) string { return userID.String() + "|" + scope + "|" + key }
+	return &mocks.MockIdempotencyKeyRepository{
+		CreateFunc: func(ctx context.Context, k *models.IdempotencyKey) (bool, error) {
+			if _, taken := rows[id(k.UserID, k.Scope, k.Key)]; taken {
+				return false, nil
+			}
+			k.ID, k.UpdatedAt = uuid.New(), *now
+			stored := *k
+			rows[id(k.UserID, k.Scope, k.Key)] = &stored
+			return true, nil
+		},
+		GetFunc: func(ctx context.Context, userID uuid.UUID, scope, key string) (*models.IdempotencyKey, error) {
+			return rows[id(userID, scope, key)], nil
+		},
+		UpdateFunc: func(ctx context.Context, k *models.IdempotencyKey) error {
+			stored := *k
+			rows[id(k.UserID, k.Scope, k.Key)] = &stored
+			return nil
+		},
+		DeleteFunc: func(ctx context.Context, keyID uuid.UUID) error {
+			for k, v := range rows {
+				if v.ID == keyID {
+					delete(rows, k)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func TestIdempotencyService_Begin_ReplaysCompletedRequest(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	svc := NewIdempotencyService(idempotencyStore(&now), zap.NewNop()).(*idempotencyService)
+	svc.now = func() time.Time { return now }
+	ctx, pharmacyID, userID := context.Background(), uuid.New(), uuid.New()
+
+	rec, replay, err := svc.Begin(ctx, pharmacyID, userID, "POST /api/v1/orders", "k-1", "hash-a")
+	if err != nil || replay {
+		t.Fatalf("first Begin = %v, %v, want a fresh claim", replay, err)
+	}
+	_, _, err = svc.Begin(ctx, pharmacyID, userID, "POST /api/v1/orders", "k-1", "hash-a")
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeConflict {
+		t.Fatalf("retry while running: err = %v, want conflict", err)
+	}
+	if err := svc.Complete(ctx, rec, 201, "application/json", []byte(`{"id":"o-1"}`)); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	got, replay, err := svc.Begin(ctx, pharmacyID, userID, "POST /api/v1/orders", "k-1", "hash-a")
+	if err != nil || !replay || got.ResponseStatus != 201 || string(got.ResponseBody) != `{"id":"o-1"}` {
+		t.Fatalf("retry after completion = %+v, %v, %v; want the stored 201 response", got, replay, err)
+	}
+	_, _, err = svc.Begin(ctx, pharmacyID, userID, "POST /api/v1/orders", "k-1", "hash-b")
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("same key, different body: err = %v, want validation error", err)
+	}
+	if _, replay, err := svc.Begin(ctx, pharmacyID, uuid.New(), "POST /api/v1/orders", "k-1", "hash-b"); err != nil || replay {
+		t.Fatalf("another user's key = %v, %v, want a fresh claim", replay, err)
+	}
+}
+
+func TestIdempotencyService_Complete_ServerErrorReleasesKey(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	svc := NewIdempotencyService(idempotencyStore(&now), zap.NewNop()).(*idempotencyService)
+	svc.now = func() time.Time { return now }
+	ctx, userID := context.Background(), uuid.New()
+
+	rec, _, err := svc.Begin(ctx, uuid.New(), userID, "POST /api/v1/payments", "k-2", "h")
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	if err := svc.Complete(ctx, rec, 500, "application/json", []byte(`{}`)); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if _, replay, err := svc.Begin(ctx, uuid.New(), userID, "POST /api/v1/payments", "k-2", "h"); err != nil || replay {
+		t.Fatalf("retry after 500 = %v, %v, want the request to run again", replay, err)
+	}
+}
+
+func TestIdempotencyService_Begin_TakesOverAbandonedKey(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	svc := NewIdempotencyService(idempotencyStore(&now), zap.NewNop()).(*idempotencyService)
+	svc.now = func() time.Time { return now }
+	ctx, userID := context.Background(), uuid.New()
+
+	if _, _, err := svc.Begin(ctx, uuid.New(), userID, "POST /api/v1/orders", "k-3", "h"); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	now = now.Add(idempotencyLockTimeout + time.Second)
+	if _, replay, err := svc.Begin(ctx, uuid.New(), userID, "POST /api/v1/orders", "k-3", "h"); err != nil || replay {
+		t.Fatalf("retry after the lock timeout = %v, %v, want a fresh claim", replay, err)
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// integritySampleSize caps the issues listed per check; the count always covers all of them.
+const integritySampleSize = 50
+
+type integrityService struct {
+	repo   outbound.IntegrityRepository
+	files  outbound.FileChecker
+	logger *zap.Logger
+}
+
+// NewIntegrityService builds the data doctor. files may be nil, which skips the missing-file check.
+func NewIntegrityService(repo outbound.IntegrityRepository, files outbound.FileChecker, logger *zap.Logger) inbound.IntegrityService {
+	return &integrityService{repo: repo, files: files, logger: logger}
+}
+
+// integrityCheck finds issues and, when fix is set, repairs them by record id.
+type integrityCheck struct {
+	code        string
+	description string
+	fixHint     string
+	find        func(ctx context.Context, pharmacyID *uuid.UUID) ([]models.IntegrityIssue, error)
+	fix         func(ctx context.Context, ids []uuid.UUID) (int64, error)
+}
+
+func (s *integrityService) checks() []integrityCheck {
+	checks := []integrityCheck{
+		{
+			code:        models.IntegrityCheckCrossTenantOrderItem,
+			description: "Order items that reference a product of another pharmacy",
+			fixHint:     "Not fixed automatically: replace the line with the pharmacy's own product or cancel the order.",
+			find:        s.repo.CrossTenantOrderItems,
+		},
+		{
+			code:        models.IntegrityCheckPaymentWithoutOrder,
+			description: "Payments whose order is missing or deleted",
+			fixHint:     "Not fixed automatically: money may have been received; refund or re-attach the payment by hand.",
+			find:        s.repo.PaymentsWithoutOrder,
+		},
+		{
+			code:        models.IntegrityCheckPointsLedgerMismatch,
+			description: "Customers whose points balance differs from their points ledger",
+			fixHint:     "Sets the balance to the sum of the customer's points transactions.",
+			find:        s.repo.PointsLedgerMismatches,
+			fix:         s.repo.ResetPointsFromLedger,
+		},
+		{
+			code:        models.IntegrityCheckCategoryDrift,
+			description: "Products whose category name differs from the name of their category",
+			fixHint:     "Copies the category's current name onto the product.",
+			find:        s.repo.ProductCategoryDrift,
+			fix:         s.repo.ResyncProductCategories,
+		},
+	}
+	if s.files != nil {
+		checks = append(checks, integrityCheck{
+			code:        models.IntegrityCheckImageMissingFile,
+			description: "Product images whose file no longer exists in storage",
+			fixHint:     "Soft-deletes the image rows so the storefront stops showing broken images.",
+			find:        s.missingImageFiles,
+			fix:         s.repo.SoftDeleteProductImages,
+		})
+	}
+	return checks
+}
+
+func (s *integrityService) missingImageFiles(ctx context.Context, pharmacyID *uuid.UUID) ([]models.IntegrityIssue, error) {
+	images, err := s.repo.ProductImages(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	var missing []models.IntegrityIssue
+	for _, img := range images {
+		ok, err := s.files.Exists(ctx, img.Detail)
+		if err != nil {
+			// Storage hiccups must not turn into deletions.
+			s.logger.Warn("integrity: file check failed", zap.String("url", img.Detail), zap.Error(err))
+			continue
+		}
+		if !ok {
+			img.Detail = "file missing: " + img.Detail
+			missing = append(missing, img)
+		}
+	}
+	return missing, nil
+}
+
+func (s *integrityService) Run(ctx context.Context, pharmacyID *uuid.UUID, applyFixes bool) (*models.IntegrityReport, error) {
+	report := &models.IntegrityReport{PharmacyID: pharmacyID, GeneratedAt: time.Now(), Applied: applyFixes, Checks: []*models.IntegrityCheckResult{}}
+	for _, c := range s.checks() {
+		found, err := c.find(ctx, pharmacyID)
+		if err != nil {
+			return nil, errors.ErrInternal("integrity check "+c.code+" failed", err)
+		}
+		res := &models.IntegrityCheckResult{
+			Code:        c.code,
+			Description: c.description,
+			Fixable:     c.fix != nil,
+			Fix:         c.fixHint,
+			Count:       len(found),
+			Samples:     found,
+		}
+		if len(res.Samples) > integritySampleSize {
+			res.Samples = res.Samples[:integritySampleSize]
+		}
+		if res.Samples == nil {
+			res.Samples = []models.IntegrityIssue{}
+		}
+		if applyFixes && c.fix != nil && len(found) > 0 {
+			ids := make([]uuid.UUID, len(found))
+			for i, f := range found {
+				ids[i] = f.RecordID
+			}
+			n, err := c.fix(ctx, ids)
+			if err != nil {
+				return nil, errors.ErrInternal("integrity fix "+c.code+" failed", err)
+			}
+			res.Fixed = int(n)
+			s.logger.Info("integrity fix applied", zap.String("check", c.code), zap.Int64("records", n))
+		}
+		report.Issues += res.Count - res.Fixed
+		report.Checks = append(report.Checks, res)
+	}
+	return report, nil
+}
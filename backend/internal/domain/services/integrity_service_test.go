@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakeIntegrityRepo returns canned issues and records the ids passed to fixes.
+type fakeIntegrityRepo struct {
+	crossTenant, payments, points, images, drift []models.IntegrityIssue
+	resetIDs, deletedIDs, resyncedIDs            []uuid.UUID
+	scope                                        *uuid.UUID
+}
+
+func (f *fakeIntegrityRepo) CrossTenantOrderItems(ctx context.Context, pharmacyID *uuid.UUID) ([]models.IntegrityIssue, error) {
+	f.scope = pharmacyID
+	return f.crossTenant, nil
+}
+
+func (f *fakeIntegrityRepo) PaymentsWithoutOrder(ctx context.Context, pharmacyID *uuid.UUID) ([]models.IntegrityIssue, error) {
+	return f.payments, nil
+}
+
+func (f *fakeIntegrityRepo) PointsLedgerMismatches(ctx context.Context, pharmacyID *uuid.UUID) ([]models.IntegrityIssue, error) {
+	return f.points, nil
+}
+
+func (f *fakeIntegrityRepo) ProductImages(ctx context.Context, pharmacyID *uuid.UUID) ([]models.IntegrityIssue, error) {
+	return f.images, nil
+}
+
+func (f *fakeIntegrityRepo) ProductCategoryDrift(ctx context.Context, pharmacyID *uuid.UUID) ([]models.IntegrityIssue, error) {
+	return f.drift, nil
+}
+
+func (f *fakeIntegrityRepo) ResyncProductCategories(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	f.resyncedIDs = append(f.resyncedIDs, ids...)
+	return int64(len(ids)), nil
+}
+
+func (f *fakeIntegrityRepo) ResetPointsFromLedger(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	f.resetIDs = append(f.resetIDs, ids...)
+	return int64(len(ids)), nil
+}
+
+func (f *fakeIntegrityRepo) SoftDeleteProductImages(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	f.deletedIDs = append(f.deletedIDs, ids...)
+	return int64(len(ids)), nil
+}
+
+// fakeFileChecker treats URLs in missing as gone and URLs in broken as storage errors.
+type fakeFileChecker struct {
+	missing, broken map[string]bool
+}
+
+func (f *fakeFileChecker) Exists(ctx context.Context, url string) (bool, error) {
+	if f.broken[url] {
+		return false, errors.New("storage timeout")
+	}
+	return !f.missing[url], nil
+}
+
+func issue(detail string) models.IntegrityIssue {
+	return models.IntegrityIssue{RecordID: uuid.New(), PharmacyID: uuid.New(), Detail: detail}
+}
+
+func findCheck(r *models.IntegrityReport, code string) *models.IntegrityCheckResult {
+	for _, c := range r.Checks {
+		if c.Code == code {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestIntegrityService_Run_ReportsWithoutFixing(t *testing.T) {
+	repo := &fakeIntegrityRepo{
+		crossTenant: []models.IntegrityIssue{issue("order ORD-1 has product of pharmacy B")},
+		points:      []models.IntegrityIssue{issue("balance 120, ledger 100")},
+		images:      []models.IntegrityIssue{issue("/uploads/a.jpg"), issue("/uploads/b.jpg"), issue("/uploads/c.jpg")},
+	}
+	files := &fakeFileChecker{missing: map[string]bool{"/uploads/b.jpg": true}, broken: map[string]bool{"/uploads/c.jpg": true}}
+	svc := NewIntegrityService(repo, files, zap.NewNop())
+	pharmacyID := uuid.New()
+
+	report, err := svc.Run(context.Background(), &pharmacyID, false)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if repo.scope == nil || *repo.scope != pharmacyID {
+		t.Errorf("expected checks scoped to the pharmacy")
+	}
+	if len(report.Checks) != 5 || report.Issues != 3 || report.Applied {
+		t.Fatalf("unexpected report: issues=%d checks=%d applied=%v", report.Issues, len(report.Checks), report.Applied)
+	}
+	img := findCheck(report, models.IntegrityCheckImageMissingFile)
+	// A storage error is not proof the file is gone.
+	if img == nil || img.Count != 1 || img.Samples[0].Detail != "file missing: /uploads/b.jpg" {
+		t.Errorf("expected only the missing file reported, got %+v", img)
+	}
+	if p := findCheck(report, models.IntegrityCheckPaymentWithoutOrder); p == nil || p.Count != 0 || p.Samples == nil {
+		t.Errorf("expected an empty payments check with non-nil samples, got %+v", p)
+	}
+	if len(repo.resetIDs) != 0 || len(repo.deletedIDs) != 0 {
+		t.Errorf("report-only run must not fix anything")
+	}
+}
+
+func TestIntegrityService_Run_AppliesOnlySafeFixes(t *testing.T) {
+	repo := &fakeIntegrityRepo{
+		crossTenant: []models.IntegrityIssue{issue("cross tenant")},
+		payments:    []models.IntegrityIssue{issue("order missing")},
+		points:      []models.IntegrityIssue{issue("balance 5, ledger 0"), issue("balance 0, ledger 10")},
+		images:      []models.IntegrityIssue{issue("/uploads/gone.jpg")},
+	}
+	files := &fakeFileChecker{missing: map[string]bool{"/uploads/gone.jpg": true}}
+	report, err := NewIntegrityService(repo, files, zap.NewNop()).Run(context.Background(), nil, true)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(repo.resetIDs) != 2 || len(repo.deletedIDs) != 1 || repo.deletedIDs[0] != repo.images[0].RecordID {
+		t.Errorf("expected points reset and image soft-deleted, got reset=%v deleted=%v", repo.resetIDs, repo.deletedIDs)
+	}
+	if c := findCheck(report, models.IntegrityCheckPointsLedgerMismatch); c.Fixed != 2 || !c.Fixable {
+		t.Errorf("unexpected points result: %+v", c)
+	}
+	if c := findCheck(report, models.IntegrityCheckCrossTenantOrderItem); c.Fixed != 0 || c.Fixable {
+		t.Errorf("cross-tenant items must not be fixed automatically: %+v", c)
+	}
+	// Cross-tenant item and orphaned payment remain.
+	if report.Issues != 2 || !report.Applied {
+		t.Errorf("expected 2 remaining issues, got %d", report.Issues)
+	}
+}
+
+func TestIntegrityService_Run_SamplesAreCapped(t *testing.T) {
+	repo := &fakeIntegrityRepo{}
+	for i := 0; i < integritySampleSize+10; i++ {
+		repo.payments = append(repo.payments, issue("order missing"))
+	}
+	// No file checker: the image check is skipped.
+	report, err := NewIntegrityService(repo, nil, zap.NewNop()).Run(context.Background(), nil, false)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(report.Checks) != 4 {
+		t.Errorf("expected the file check to be skipped, got %d checks", len(report.Checks))
+	}
+	c := findCheck(report, models.IntegrityCheckPaymentWithoutOrder)
+	if c.Count != integritySampleSize+10 || len(c.Samples) != integritySampleSize {
+		t.Errorf("expected full count and capped samples, got count=%d samples=%d", c.Count, len(c.Samples))
+	}
+}
+
+func TestIntegrityService_Run_ResyncsCategoryDrift(t *testing.T) {
+	drifted := issue(`product SKU-1 has category "Pain relief", category is named "Analgesics"`)
+	repo := &fakeIntegrityRepo{drift: []models.IntegrityIssue{drifted}}
+	report, err := NewIntegrityService(repo, nil, zap.NewNop()).Run(context.Background(), nil, true)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	res := findCheck(report, models.IntegrityCheckCategoryDrift)
+	if res == nil || res.Count != 1 || !res.Fixable || res.Fixed != 1 {
+		t.Fatalf("unexpected drift result: %+v", res)
+	}
+	if len(repo.resyncedIDs) != 1 || repo.resyncedIDs[0] != drifted.RecordID {
+		t.Errorf("expected the drifted product to be resynced, got %v", repo.resyncedIDs)
+	}
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type inventoryAlertService struct {
+	productRepo         outbound.ProductRepository
+	batchRepo           outbound.InventoryBatchRepository
+	alertRepo           outbound.InventoryAlertRepository
+	configRepo          outbound.PharmacyConfigRepository
+	pharmacyRepo        outbound.PharmacyRepository
+	userRepo            outbound.UserRepository
+	notificationService inbound.NotificationService
+	now                 func() time.Time
+	logger              *zap.Logger
+}
+
+func NewInventoryAlertService(
+	productRepo outbound.ProductRepository,
+	batchRepo outbound.InventoryBatchRepository,
+	alertRepo outbound.InventoryAlertRepository,
+	configRepo outbound.PharmacyConfigRepository,
+	pharmacyRepo outbound.PharmacyRepository,
+	userRepo outbound.UserRepository,
+	notificationService inbound.NotificationService,
+	logger *zap.Logger,
+) inbound.InventoryAlertService {
+	return &inventoryAlertService{
+		productRepo:         productRepo,
+		batchRepo:           batchRepo,
+		alertRepo:           alertRepo,
+		configRepo:          configRepo,
+		pharmacyRepo:        pharmacyRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+		now:                 time.Now,
+		logger:              logger,
+	}
+}
+
+func (s *inventoryAlertService) policy(ctx context.Context, pharmacyID uuid.UUID) *models.InventoryAlertPolicy {
+	if cfg, err := s.configRepo.GetByPharmacyID(ctx, pharmacyID); err == nil && cfg != nil && cfg.InventoryAlerts != nil {
+		return cfg.InventoryAlerts
+	}
+	return models.DefaultInventoryAlertPolicy()
+}
+
+// collect computes the live alerts of a pharmacy.
+func (s *inventoryAlertService) collect(ctx context.Context, pharmacyID uuid.UUID, policy *models.InventoryAlertPolicy) (*models.InventoryAlertDigest, error) {
+	now := s.now()
+	today := startOfDay(now)
+	digest := &models.InventoryAlertDigest{
+		GeneratedAt:  now,
+		ReorderLevel: policy.ReorderLevel,
+		ExpiryDays:   policy.ExpiryDays,
+		LowStock:     []*models.LowStockAlert{},
+		Expiring:     []*models.ExpiryAlert{},
+	}
+	products, err := s.productRepo.ListBelowReorderLevel(ctx, pharmacyID, policy.ReorderLevel)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load low stock products", err)
+	}
+	for _, p := range products {
+		level := policy.ReorderLevel
+		if p.ReorderLevel != nil {
+			level = *p.ReorderLevel
+		}
+		digest.LowStock = append(digest.LowStock, &models.LowStockAlert{
+			ProductID: p.ID, Name: p.Name, SKU: p.SKU, StockQuantity: p.StockQuantity, ReorderLevel: level,
+		})
+	}
+	// Includes batches that already expired but still hold stock.
+	batches, err := s.batchRepo.ListExpiringByPharmacy(ctx, pharmacyID, today.AddDate(0, 0, policy.ExpiryDays))
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load expiring batches", err)
+	}
+	for _, b := range batches {
+		if b.ExpiryDate == nil {
+			continue
+		}
+		days := int(startOfDay(*b.ExpiryDate).Sub(today).Hours() / 24)
+		a := &models.ExpiryAlert{
+			BatchID: b.ID, ProductID: b.ProductID, BatchNumber: b.BatchNumber, Quantity: b.Quantity,
+			ExpiryDate: *b.ExpiryDate, DaysToExpiry: days, Expired: days < 0,
+		}
+		if b.Product != nil {
+			a.ProductName = b.Product.Name
+		}
+		digest.Expiring = append(digest.Expiring, a)
+	}
+	return digest, nil
+}
+
+func (s *inventoryAlertService) Digest(ctx context.Context, pharmacyID uuid.UUID) (*models.InventoryAlertDigest, error) {
+	digest, err := s.collect(ctx, pharmacyID, s.policy(ctx, pharmacyID))
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.alertRepo.ListByPharmacy(ctx, pharmacyID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load inventory alerts", err)
+	}
+	since := make(map[string]time.Time, len(stored))
+	for _, a := range stored {
+		since[a.Kind+":"+a.RefID.String()] = a.FirstSeenAt
+	}
+	for _, l := range digest.LowStock {
+		if t, ok := since[models.InventoryAlertLowStock+":"+l.ProductID.String()]; ok {
+			l.Since = &t
+		}
+	}
+	for _, e := range digest.Expiring {
+		if t, ok := since[expiryAlertKind(e)+":"+e.BatchID.String()]; ok {
+			e.Since = &t
+		}
+	}
+	return digest, nil
+}
+
+func expiryAlertKind(e *models.ExpiryAlert) string {
+	if e.Expired {
+		return models.InventoryAlertExpired
+	}
+	return models.InventoryAlertExpiring
+}
+
+func (s *inventoryAlertService) Scan(ctx context.Context, pharmacyID uuid.UUID) (int, error) {
+	policy := s.policy(ctx, pharmacyID)
+	digest, err := s.collect(ctx, pharmacyID, policy)
+	if err != nil {
+		return 0, err
+	}
+	stored, err := s.alertRepo.ListByPharmacy(ctx, pharmacyID)
+	if err != nil {
+		return 0, errors.ErrInternal("failed to load inventory alerts", err)
+	}
+	open := make(map[string]*models.InventoryAlert, len(stored))
+	for _, a := range stored {
+		open[a.Kind+":"+a.RefID.String()] = a
+	}
+	now := s.now()
+	var seen []uuid.UUID
+	var newLow []*models.LowStockAlert
+	var newExpiring, newExpired []*models.ExpiryAlert
+	// track returns true when the alert was not open before the scan; events are saved with a new alert.
+	track := func(kind string, refID uuid.UUID, events ...*models.OutboxEvent) (bool, error) {
+		key := kind + ":" + refID.String()
+		if a, ok := open[key]; ok {
+			seen = append(seen, a.ID)
+			delete(open, key)
+			return false, nil
+		}
+		a := &models.InventoryAlert{PharmacyID: pharmacyID, Kind: kind, RefID: refID, FirstSeenAt: now, LastSeenAt: now}
+		if err := s.alertRepo.Create(ctx, a, events...); err != nil {
+			return false, errors.ErrInternal("failed to save inventory alert", err)
+		}
+		return true, nil
+	}
+	for _, l := range digest.LowStock {
+		isNew, err := track(models.InventoryAlertLowStock, l.ProductID, models.NewOutboxEvent(models.EventStockLow, pharmacyID, l.ProductID, models.StockLowEventData{
+			ProductID: l.ProductID, Name: l.Name, SKU: l.SKU, StockQuantity: l.StockQuantity, ReorderLevel: l.ReorderLevel,
+		}))
+		if err != nil {
+			return 0, err
+		}
+		if isNew {
+			newLow = append(newLow, l)
+		}
+	}
+	for _, e := range digest.Expiring {
+		isNew, err := track(expiryAlertKind(e), e.BatchID)
+		if err != nil {
+			return 0, err
+		}
+		if isNew && e.Expired {
+			newExpired = append(newExpired, e)
+		} else if isNew {
+			newExpiring = append(newExpiring, e)
+		}
+	}
+	if err := s.alertRepo.MarkSeen(ctx, seen, now); err != nil {
+		return 0, errors.ErrInternal("failed to update inventory alerts", err)
+	}
+	// Whatever is left was resolved (restocked, sold out, expiry moved); a recurrence alerts again.
+	resolved := make([]uuid.UUID, 0, len(open))
+	for _, a := range open {
+		resolved = append(resolved, a.ID)
+	}
+	if err := s.alertRepo.Delete(ctx, resolved); err != nil {
+		return 0, errors.ErrInternal("failed to clear resolved inventory alerts", err)
+	}
+	total := len(newLow) + len(newExpiring) + len(newExpired)
+	if total > 0 {
+		s.notifyManagers(ctx, pharmacyID, inventoryAlertMessage(newLow, newExpiring, newExpired, policy.ExpiryDays), total)
+	}
+	return total, nil
+}
+
+func (s *inventoryAlertService) ScanAll(ctx context.Context) error {
+	pharmacies, err := s.pharmacyRepo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("list pharmacies: %w", err)
+	}
+	failed := 0
+	for _, p := range pharmacies {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !p.IsActive || !s.policy(ctx, p.ID).Enabled {
+			continue
+		}
+		n, err := s.Scan(ctx, p.ID)
+		if err != nil {
+			failed++
+			s.logger.Warn("inventory alert scan failed", zap.String("pharmacy_id", p.ID.String()), zap.Error(err))
+			continue
+		}
+		if n > 0 {
+			s.logger.Info("new inventory alerts", zap.String("pharmacy_id", p.ID.String()), zap.Int("alerts", n))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("inventory alert scan failed for %d of %d pharmacies", failed, len(pharmacies))
+	}
+	return nil
+}
+
+// inventoryAlertMessage summarizes new alerts, naming up to three products per kind.
+func inventoryAlertMessage(low []*models.LowStockAlert, expiring, expired []*models.ExpiryAlert, expiryDays int) string {
+	var parts []string
+	if len(low) > 0 {
+		names := make([]string, len(low))
+		for i, l := range low {
+			names[i] = l.Name
+		}
+		parts = append(parts, fmt.Sprintf("%d at or below reorder level (%s)", len(low), listNames(names)))
+	}
+	batchNames := func(list []*models.ExpiryAlert) string {
+		names := make([]string, len(list))
+		for i, e := range list {
+			names[i] = e.ProductName + " " + e.BatchNumber
+		}
+		return listNames(names)
+	}
+	if len(expiring) > 0 {
+		parts = append(parts, fmt.Sprintf("%d batches expiring within %d days (%s)", len(expiring), expiryDays, batchNames(expiring)))
+	}
+	if len(expired) > 0 {
+		parts = append(parts, fmt.Sprintf("%d expired batches still in stock (%s)", len(expired), batchNames(expired)))
+	}
+	return strings.Join(parts, "; ") + ". See Inventory > Alerts."
+}
+
+func listNames(names []string) string {
+	if len(names) > 3 {
+		return strings.Join(names[:3], ", ") + fmt.Sprintf(" and %d more", len(names)-3)
+	}
+	return strings.Join(names, ", ")
+}
+
+// notifyManagers sends the alert summary to the pharmacy's active admins and managers. Failures are logged, never returned.
+func (s *inventoryAlertService) notifyManagers(ctx context.Context, pharmacyID uuid.UUID, message string, count int) {
+	if s.notificationService == nil {
+		return
+	}
+	users, err := s.userRepo.GetByPharmacyID(ctx, pharmacyID)
+	if err != nil {
+		s.logger.Warn("failed to load managers for inventory alert", zap.String("pharmacy_id", pharmacyID.String()), zap.Error(err))
+		return
+	}
+	title := fmt.Sprintf("%d new inventory alerts", count)
+	if count == 1 {
+		title = "New inventory alert"
+	}
+	for _, u := range users {
+		if !u.IsActive || (u.Role != models.RoleAdmin && u.Role != models.RoleManager) {
+			continue
+		}
+		if _, err := s.notificationService.Create(ctx, pharmacyID, u.ID, title, message, "inventory"); err != nil {
+			s.logger.Warn("failed to send inventory alert", zap.String("user_id", u.ID.String()), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type alertFixture struct {
+	pharmacyID uuid.UUID
+	products   []*models.Product
+	batches    []*models.InventoryBatch
+	alerts     map[uuid.UUID]*models.InventoryAlert
+	notifier   *recordingNotifier
+	now        time.Time
+	svc        *inventoryAlertService
+}
+
+func newAlertFixture() *alertFixture {
+	f := &alertFixture{
+		pharmacyID: uuid.New(),
+		alerts:     make(map[uuid.UUID]*models.InventoryAlert),
+		notifier:   &recordingNotifier{},
+		now:        time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC),
+	}
+	products := &mocks.MockProductRepository{
+		ListBelowReorderLevelFunc: func(ctx context.Context, pharmacyID uuid.UUID, defaultLevel int) ([]*models.Product, error) {
+			var out []*models.Product
+			for _, p := range f.products {
+				level := defaultLevel
+				if p.ReorderLevel != nil {
+					level = *p.ReorderLevel
+				}
+				if p.StockQuantity <= level {
+					out = append(out, p)
+				}
+			}
+			return out, nil
+		},
+	}
+	batches := &mocks.MockInventoryBatchRepository{
+		ListExpiringByPharmacyFunc: func(ctx context.Context, pharmacyID uuid.UUID, beforeOrOn time.Time) ([]*models.InventoryBatch, error) {
+			var out []*models.InventoryBatch
+			for _, b := range f.batches {
+				if b.Quantity > 0 && !b.ExpiryDate.After(beforeOrOn) {
+					out = append(out, b)
+				}
+			}
+			return out, nil
+		},
+	}
+	alerts := &mocks.MockInventoryAlertRepository{
+		ListByPharmacyFunc: func(ctx context.Context, pharmacyID uuid.UUID) ([]*models.InventoryAlert, error) {
+			var out []*models.InventoryAlert
+			for _, a := range f.alerts {
+				out = append(out, a)
+			}
+			return out, nil
+		},
+		CreateFunc: func(ctx context.Context, a *models.InventoryAlert) error {
+			a.ID = uuid.New()
+			f.alerts[a.ID] = a
+			return nil
+		},
+		MarkSeenFunc: func(ctx context.Context, ids []uuid.UUID, at time.Time) error {
+			for _, id := range ids {
+				f.alerts[id].LastSeenAt = at
+			}
+			return nil
+		},
+		DeleteFunc: func(ctx context.Context, ids []uuid.UUID) error {
+			for _, id := range ids {
+				delete(f.alerts, id)
+			}
+			return nil
+		},
+	}
+	users := &mocks.MockUserRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, pharmacyID uuid.UUID) ([]*models.User, error) {
+			return []*models.User{
+				{ID: uuid.New(), Role: models.RoleManager, IsActive: true},
+				{ID: uuid.New(), Role: models.RoleAdmin, IsActive: true},
+				{ID: uuid.New(), Role: models.RoleManager, IsActive: false},
+				{ID: uuid.New(), Role: models.RolePharmacist, IsActive: true},
+			}, nil
+		},
+	}
+	f.svc = NewInventoryAlertService(products, batches, alerts, &mocks.MockPharmacyConfigRepository{}, &mocks.MockPharmacyRepository{}, users, f.notifier, zap.NewNop()).(*inventoryAlertService)
+	f.svc.now = func() time.Time { return f.now }
+	return f
+}
+
+func (f *alertFixture) addProduct(name string, stock int, reorderLevel *int) *models.Product {
+	p := &models.Product{ID: uuid.New(), PharmacyID: f.pharmacyID, Name: name, StockQuantity: stock, ReorderLevel: reorderLevel}
+	f.products = append(f.products, p)
+	return p
+}
+
+func (f *alertFixture) addBatch(p *models.Product, number string, qty, days int) *models.InventoryBatch {
+	exp := startOfDay(f.now).AddDate(0, 0, days)
+	b := &models.InventoryBatch{ID: uuid.New(), ProductID: p.ID, BatchNumber: number, Quantity: qty, ExpiryDate: &exp, Product: p}
+	f.batches = append(f.batches, b)
+	return b
+}
+
+func TestInventoryAlertService_Scan_NotifiesManagersOncePerAlert(t *testing.T) {
+	f := newAlertFixture()
+	ctx := context.Background()
+	para := f.addProduct("Paracetamol", 4, nil)
+	f.addProduct("Cetirizine", 50, nil)
+	f.addBatch(para, "P-01", 4, 12)
+
+	n, err := f.svc.Scan(ctx, f.pharmacyID)
+	if err != nil || n != 2 {
+		t.Fatalf("Scan = %d, %v; want 2 new alerts", n, err)
+	}
+	if len(f.notifier.sent) != 2 {
+		t.Fatalf("expected one notification per active admin/manager, got %d", len(f.notifier.sent))
+	}
+	msg := f.notifier.sent[0].Message
+	if f.notifier.sent[0].Type != "inventory" || !strings.Contains(msg, "1 at or below reorder level (Paracetamol)") || !strings.Contains(msg, "1 batches expiring within 30 days (Paracetamol P-01)") {
+		t.Errorf("unexpected notification: %+v", f.notifier.sent[0])
+	}
+
+	f.notifier.sent = nil
+	f.now = f.now.Add(time.Hour)
+	if n, _ := f.svc.Scan(ctx, f.pharmacyID); n != 0 || len(f.notifier.sent) != 0 {
+		t.Errorf("a repeated scan must not notify again, got %d new, %d notifications", n, len(f.notifier.sent))
+	}
+	for _, a := range f.alerts {
+		if !a.LastSeenAt.Equal(f.now) {
+			t.Errorf("expected alert %s to be marked seen", a.Kind)
+		}
+	}
+}
+
+func TestInventoryAlertService_Scan_ResolvedAlertsAreClearedAndExpiryRealerts(t *testing.T) {
+	f := newAlertFixture()
+	ctx := context.Background()
+	ibu := f.addProduct("Ibuprofen", 3, nil)
+	batch := f.addBatch(ibu, "I-07", 20, 1)
+	_, _ = f.svc.Scan(ctx, f.pharmacyID)
+
+	// Restocked, and the batch expired overnight.
+	ibu.StockQuantity = 40
+	f.now = f.now.AddDate(0, 0, 2)
+	f.notifier.sent = nil
+	n, err := f.svc.Scan(ctx, f.pharmacyID)
+	if err != nil || n != 1 {
+		t.Fatalf("Scan = %d, %v; want only the expired batch as new", n, err)
+	}
+	if len(f.alerts) != 1 {
+		t.Fatalf("expected the low-stock and expiring alerts to be cleared, got %d open", len(f.alerts))
+	}
+	for _, a := range f.alerts {
+		if a.Kind != models.InventoryAlertExpired || a.RefID != batch.ID {
+			t.Errorf("unexpected open alert %+v", a)
+		}
+	}
+	if len(f.notifier.sent) == 0 || !strings.Contains(f.notifier.sent[0].Message, "1 expired batches still in stock") {
+		t.Errorf("expected an expired-batch notification, got %+v", f.notifier.sent)
+	}
+
+	digest, err := f.svc.Digest(ctx, f.pharmacyID)
+	if err != nil || len(digest.LowStock) != 0 || len(digest.Expiring) != 1 {
+		t.Fatalf("Digest = %+v, %v", digest, err)
+	}
+	if e := digest.Expiring[0]; !e.Expired || e.DaysToExpiry != -1 || e.Since == nil || !e.Since.Equal(f.now) {
+		t.Errorf("unexpected expiry alert %+v", e)
+	}
+}
+
+func TestInventoryAlertService_Digest_ProductReorderLevelOverridesDefault(t *testing.T) {
+	f := newAlertFixture()
+	high, none := 20, 0
+	f.addProduct("Insulin", 15, &high)
+	f.addProduct("Antacid", 15, nil)
+	f.addProduct("Vitamin C", 2, &none)
+
+	digest, err := f.svc.Digest(context.Background(), f.pharmacyID)
+	if err != nil {
+		t.Fatalf("Digest: %v", err)
+	}
+	if len(digest.LowStock) != 1 || digest.LowStock[0].Name != "Insulin" || digest.LowStock[0].ReorderLevel != 20 {
+		t.Errorf("expected only Insulin (level 20) to be low, got %+v", digest.LowStock)
+	}
+	if digest.ReorderLevel != 10 || digest.LowStock[0].Since != nil {
+		t.Errorf("expected the default level and no scan history, got %+v", digest)
+	}
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/careplus/pharmacy-backend/pkg/imaging"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// Evidence photos are shrunk to fit inventoryPhotoMaxSide and stored as JPEG.
+	inventoryPhotoMaxSide = 1600
+	inventoryPhotoQuality = 85
+	// maxEvidencePhotos bounds how many photos one request attaches to a supplier return.
+	maxEvidencePhotos = 20
+)
+
+type inventoryEvidenceService struct {
+	repo          outbound.InventoryEvidenceRepository
+	batchRepo     outbound.InventoryBatchRepository
+	productRepo   outbound.ProductRepository
+	clearanceRepo outbound.ClearanceRepository
+	storage       outbound.FileStorage
+	uow           outbound.UnitOfWork
+	logger        *zap.Logger
+}
+
+// NewInventoryEvidenceService returns the service; storage holds the photos.
+func NewInventoryEvidenceService(repo outbound.InventoryEvidenceRepository, batchRepo outbound.InventoryBatchRepository, productRepo outbound.ProductRepository, clearanceRepo outbound.ClearanceRepository, storage outbound.FileStorage, uow outbound.UnitOfWork, logger *zap.Logger) inbound.InventoryEvidenceService {
+	return &inventoryEvidenceService{repo: repo, batchRepo: batchRepo, productRepo: productRepo, clearanceRepo: clearanceRepo, storage: storage, uow: uow, logger: logger}
+}
+
+func (s *inventoryEvidenceService) batch(ctx context.Context, pharmacyID, batchID uuid.UUID) (*models.InventoryBatch, error) {
+	b, err := s.batchRepo.GetByID(ctx, batchID)
+	if err != nil || b == nil || b.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("inventory batch")
+	}
+	return b, nil
+}
+
+func (s *inventoryEvidenceService) WriteOff(ctx context.Context, pharmacyID, batchID, actorID uuid.UUID, in inbound.StockWriteOffInput) (*models.StockAdjustment, error) {
+	if in.Quantity <= 0 {
+		return nil, errors.ErrValidation("quantity must be positive")
+	}
+	if !models.ValidStockAdjustmentReason(in.Reason) {
+		return nil, errors.ErrValidation("reason must be damaged, broken_seal, expired, count_correction or other")
+	}
+	b, err := s.batch(ctx, pharmacyID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	a := &models.StockAdjustment{
+		PharmacyID: pharmacyID,
+		BatchID:    b.ID,
+		ProductID:  b.ProductID,
+		Quantity:   in.Quantity,
+		Reason:     in.Reason,
+		Note:       in.Note,
+		CreatedBy:  actorID,
+	}
+	err = inTx(ctx, s.uow, func(ctx context.Context) error {
+		ok, err := s.batchRepo.Draw(ctx, b.ID, in.Quantity)
+		if err != nil {
+			return errors.ErrInternal("failed to update batch", err)
+		}
+		if !ok {
+			return errors.ErrConflict(fmt.Sprintf("batch %s holds fewer than %d units", b.BatchNumber, in.Quantity))
+		}
+		if _, err := s.productRepo.AdjustStock(ctx, b.ProductID, -in.Quantity); err != nil {
+			return errors.ErrInternal("failed to update product stock", err)
+		}
+		after, err := s.batchRepo.GetByID(ctx, b.ID)
+		if err != nil || after == nil {
+			return errors.ErrInternal("failed to load batch", err)
+		}
+		a.QuantityAfter = after.Quantity
+		if err := s.repo.CreateAdjustment(ctx, a); err != nil {
+			return errors.ErrInternal("failed to record write-off", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *inventoryEvidenceService) AddPhoto(ctx context.Context, pharmacyID, batchID, actorID uuid.UUID, adjustmentID *uuid.UUID, caption string, body io.Reader) (*models.InventoryPhoto, error) {
+	if s.storage == nil {
+		return nil, errors.ErrInternal("file storage is not configured", nil)
+	}
+	if len(caption) > 255 {
+		return nil, errors.ErrValidation("caption must be at most 255 characters")
+	}
+	b, err := s.batch(ctx, pharmacyID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if adjustmentID != nil {
+		a, err := s.repo.GetAdjustment(ctx, *adjustmentID)
+		if err != nil {
+			return nil, errors.ErrInternal("failed to load write-off", err)
+		}
+		if a == nil || a.BatchID != b.ID {
+			return nil, errors.ErrNotFound("write-off")
+		}
+	}
+	img, _, err := imaging.Decode(body)
+	if err != nil {
+		return nil, errors.ErrValidation(err.Error())
+	}
+	data, err := imaging.EncodeJPEG(imaging.Fit(img, inventoryPhotoMaxSide, inventoryPhotoMaxSide), inventoryPhotoQuality)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to encode image", err)
+	}
+	path := "photos/inventory/" + time.Now().Format("2006/01") + "/" + uuid.New().String() + ".jpg"
+	url, err := s.storage.Save(ctx, path, bytes.NewReader(data), "image/jpeg")
+	if err != nil {
+		return nil, errors.ErrInternal("failed to store image", err)
+	}
+	p := &models.InventoryPhoto{
+		PharmacyID:   pharmacyID,
+		BatchID:      b.ID,
+		AdjustmentID: adjustmentID,
+		Caption:      caption,
+		URL:          url,
+		UploadedBy:   actorID,
+	}
+	if err := s.repo.CreatePhoto(ctx, p); err != nil {
+		return nil, errors.ErrInternal("failed to save photo", err)
+	}
+	return p, nil
+}
+
+func (s *inventoryEvidenceService) Ledger(ctx context.Context, pharmacyID, batchID uuid.UUID) (*inbound.BatchLedger, error) {
+	b, err := s.batch(ctx, pharmacyID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	adjustments, err := s.repo.ListAdjustments(ctx, b.ID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load stock ledger", err)
+	}
+	photos, err := s.repo.ListPhotos(ctx, b.ID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load batch photos", err)
+	}
+	if adjustments == nil {
+		adjustments = []*models.StockAdjustment{}
+	}
+	if photos == nil {
+		photos = []*models.InventoryPhoto{}
+	}
+	return &inbound.BatchLedger{Batch: b, Adjustments: adjustments, Photos: photos}, nil
+}
+
+func (s *inventoryEvidenceService) AttachToReturn(ctx context.Context, pharmacyID, actionID uuid.UUID, photoIDs []uuid.UUID) ([]*models.InventoryPhoto, error) {
+	if len(photoIDs) == 0 || len(photoIDs) > maxEvidencePhotos {
+		return nil, errors.ErrValidation(fmt.Sprintf("photo_ids must list 1 to %d photos", maxEvidencePhotos))
+	}
+	a, err := s.clearanceRepo.GetByID(ctx, actionID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load clearance action", err)
+	}
+	if a == nil || a.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("clearance action")
+	}
+	if a.Kind != models.ClearanceKindSupplierReturn {
+		return nil, errors.ErrValidation("photos can only be attached to a supplier return")
+	}
+	if a.Status == models.ClearanceCancelled {
+		return nil, errors.ErrValidation("clearance action is cancelled")
+	}
+	seen := make(map[uuid.UUID]bool, len(photoIDs))
+	var want []uuid.UUID
+	for _, id := range photoIDs {
+		if !seen[id] {
+			seen[id] = true
+			want = append(want, id)
+		}
+	}
+	photos, err := s.repo.GetPhotos(ctx, pharmacyID, want)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load photos", err)
+	}
+	if len(photos) != len(want) {
+		return nil, errors.ErrNotFound("photo")
+	}
+	inReturn := make(map[uuid.UUID]bool, len(a.Items))
+	for _, it := range a.Items {
+		inReturn[it.ProductID] = true
+	}
+	ids := make([]uuid.UUID, 0, len(photos))
+	for _, p := range photos {
+		b, err := s.batchRepo.GetByID(ctx, p.BatchID)
+		if err != nil || b == nil || !inReturn[b.ProductID] {
+			return nil, errors.ErrValidation("photo " + p.ID.String() + " is not of a product in this supplier return")
+		}
+		ids = append(ids, p.ID)
+	}
+	if err := s.repo.LinkPhotos(ctx, ids, a.ID); err != nil {
+		return nil, errors.ErrInternal("failed to attach photos", err)
+	}
+	for _, p := range photos {
+		p.ClearanceActionID = &a.ID
+	}
+	return photos, nil
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// evidenceFixture holds one batch in memory and records write-offs, photos and product stock changes.
+type evidenceFixture struct {
+	batch       *models.InventoryBatch
+	stock       int
+	adjustments []*models.StockAdjustment
+	photos      map[uuid.UUID]*models.InventoryPhoto
+	action      *models.ClearanceAction
+	store       *memoryStorage
+	svc         inbound.InventoryEvidenceService
+}
+
+func newEvidenceFixture(quantity int) *evidenceFixture {
+	f := &evidenceFixture{
+		batch:  &models.InventoryBatch{ID: uuid.New(), PharmacyID: uuid.New(), ProductID: uuid.New(), BatchNumber: "B-1", Quantity: quantity},
+		stock:  quantity,
+		photos: map[uuid.UUID]*models.InventoryPhoto{},
+		store:  &memoryStorage{files: map[string]int{}},
+	}
+	batchRepo := &mocks.MockInventoryBatchRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.InventoryBatch, error) {
+			if id != f.batch.ID {
+				return nil, nil
+			}
+			b := *f.batch
+			return &b, nil
+		},
+		DrawFunc: func(ctx context.Context, id uuid.UUID, quantity int) (bool, error) {
+			if f.batch.Quantity < quantity {
+				return false, nil
+			}
+			f.batch.Quantity -= quantity
+			return true, nil
+		},
+	}
+	productRepo := &mocks.MockProductRepository{
+		AdjustStockFunc: func(ctx context.Context, id uuid.UUID, delta int) (bool, error) {
+			f.stock += delta
+			return true, nil
+		},
+	}
+	repo := &mocks.MockInventoryEvidenceRepository{
+		CreateAdjustmentFunc: func(ctx context.Context, a *models.StockAdjustment) error {
+			a.ID = uuid.New()
+			f.adjustments = append(f.adjustments, a)
+			return nil
+		},
+		GetAdjustmentFunc: func(ctx context.Context, id uuid.UUID) (*models.StockAdjustment, error) {
+			for _, a := range f.adjustments {
+				if a.ID == id {
+					return a, nil
+				}
+			}
+			return nil, nil
+		},
+		CreatePhotoFunc: func(ctx context.Context, p *models.InventoryPhoto) error {
+			p.ID = uuid.New()
+			f.photos[p.ID] = p
+			return nil
+		},
+		GetPhotosFunc: func(ctx context.Context, pharmacyID uuid.UUID, ids []uuid.UUID) ([]*models.InventoryPhoto, error) {
+			var out []*models.InventoryPhoto
+			for _, id := range ids {
+				if p := f.photos[id]; p != nil && p.PharmacyID == pharmacyID {
+					out = append(out, p)
+				}
+			}
+			return out, nil
+		},
+		LinkPhotosFunc: func(ctx context.Context, ids []uuid.UUID, actionID uuid.UUID) error {
+			for _, id := range ids {
+				f.photos[id].ClearanceActionID = &actionID
+			}
+			return nil
+		},
+	}
+	clearanceRepo := &mocks.MockClearanceRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.ClearanceAction, error) {
+			if f.action == nil || f.action.ID != id {
+				return nil, nil
+			}
+			return f.action, nil
+		},
+	}
+	f.svc = NewInventoryEvidenceService(repo, batchRepo, productRepo, clearanceRepo, f.store, &mocks.MockUnitOfWork{}, zap.NewNop())
+	return f
+}
+
+func TestInventoryEvidenceService_WriteOff_RecordsLedgerEntry(t *testing.T) {
+	f := newEvidenceFixture(10)
+	actor := uuid.New()
+	a, err := f.svc.WriteOff(context.Background(), f.batch.PharmacyID, f.batch.ID, actor, inbound.StockWriteOffInput{Quantity: 3, Reason: models.StockAdjustmentDamaged, Note: "crushed carton"})
+	if err != nil {
+		t.Fatalf("WriteOff: %v", err)
+	}
+	if a.Quantity != 3 || a.QuantityAfter != 7 || a.ProductID != f.batch.ProductID || a.CreatedBy != actor {
+		t.Errorf("unexpected adjustment %+v", a)
+	}
+	if f.batch.Quantity != 7 || f.stock != 7 {
+		t.Errorf("batch %d and product stock %d, want 7 and 7", f.batch.Quantity, f.stock)
+	}
+
+	_, err = f.svc.WriteOff(context.Background(), f.batch.PharmacyID, f.batch.ID, actor, inbound.StockWriteOffInput{Quantity: 8, Reason: models.StockAdjustmentBrokenSeal})
+	if ae, ok := err.(*pkgerrors.AppError); !ok || ae.Code != pkgerrors.ErrCodeConflict {
+		t.Fatalf("expected conflict writing off more than the batch holds, got %v", err)
+	}
+	if len(f.adjustments) != 1 || f.stock != 7 {
+		t.Errorf("failed write-off changed the ledger or stock: %d entries, stock %d", len(f.adjustments), f.stock)
+	}
+
+	_, err = f.svc.WriteOff(context.Background(), f.batch.PharmacyID, f.batch.ID, actor, inbound.StockWriteOffInput{Quantity: 1, Reason: "lost"})
+	if ae, ok := err.(*pkgerrors.AppError); !ok || ae.Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("expected validation error for unknown reason, got %v", err)
+	}
+	if _, err := f.svc.WriteOff(context.Background(), uuid.New(), f.batch.ID, actor, inbound.StockWriteOffInput{Quantity: 1, Reason: models.StockAdjustmentDamaged}); err == nil {
+		t.Fatal("expected another pharmacy's batch to be not found")
+	}
+}
+
+func TestInventoryEvidenceService_AddPhoto(t *testing.T) {
+	f := newEvidenceFixture(10)
+	ctx := context.Background()
+	a, err := f.svc.WriteOff(ctx, f.batch.PharmacyID, f.batch.ID, uuid.New(), inbound.StockWriteOffInput{Quantity: 2, Reason: models.StockAdjustmentDamaged})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := f.svc.AddPhoto(ctx, f.batch.PharmacyID, f.batch.ID, uuid.New(), &a.ID, "wet carton", pngOfSize(t, 2400, 1200))
+	if err != nil {
+		t.Fatalf("AddPhoto: %v", err)
+	}
+	if p.AdjustmentID == nil || *p.AdjustmentID != a.ID || p.Caption != "wet carton" || !strings.HasPrefix(p.URL, "/uploads/photos/inventory/") || !strings.HasSuffix(p.URL, ".jpg") {
+		t.Errorf("unexpected photo %+v", p)
+	}
+	if len(f.store.files) != 1 {
+		t.Errorf("expected one stored file, got %v", f.store.files)
+	}
+
+	other := uuid.New()
+	if _, err := f.svc.AddPhoto(ctx, f.batch.PharmacyID, f.batch.ID, uuid.New(), &other, "", pngOfSize(t, 10, 10)); err == nil {
+		t.Fatal("expected unknown write-off to be rejected")
+	}
+	if _, err := f.svc.AddPhoto(ctx, f.batch.PharmacyID, f.batch.ID, uuid.New(), nil, "", strings.NewReader("not an image")); err == nil {
+		t.Fatal("expected non-image upload to be rejected")
+	}
+}
+
+func TestInventoryEvidenceService_AttachToReturn(t *testing.T) {
+	f := newEvidenceFixture(10)
+	ctx := context.Background()
+	p, err := f.svc.AddPhoto(ctx, f.batch.PharmacyID, f.batch.ID, uuid.New(), nil, "broken seal", pngOfSize(t, 20, 20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.action = &models.ClearanceAction{ID: uuid.New(), PharmacyID: f.batch.PharmacyID, Kind: models.ClearanceKindSupplierReturn, Status: models.ClearanceOpen,
+		Items: []*models.ClearanceItem{{ProductID: uuid.New()}}}
+
+	if _, err := f.svc.AttachToReturn(ctx, f.batch.PharmacyID, f.action.ID, []uuid.UUID{p.ID}); err == nil {
+		t.Fatal("expected photo of a product outside the return to be rejected")
+	}
+	f.action.Items = append(f.action.Items, &models.ClearanceItem{ProductID: f.batch.ProductID})
+	photos, err := f.svc.AttachToReturn(ctx, f.batch.PharmacyID, f.action.ID, []uuid.UUID{p.ID, p.ID})
+	if err != nil {
+		t.Fatalf("AttachToReturn: %v", err)
+	}
+	if len(photos) != 1 || photos[0].ClearanceActionID == nil || *photos[0].ClearanceActionID != f.action.ID {
+		t.Errorf("unexpected photos %+v", photos)
+	}
+	if _, err := f.svc.AttachToReturn(ctx, f.batch.PharmacyID, f.action.ID, []uuid.UUID{uuid.New()}); err == nil {
+		t.Fatal("expected unknown photo to be rejected")
+	}
+
+	f.action.Kind = models.ClearanceKindPromo
+	if _, err := f.svc.AttachToReturn(ctx, f.batch.PharmacyID, f.action.ID, []uuid.UUID{p.ID}); err == nil {
+		t.Fatal("expected promo action to be rejected")
+	}
+}
@@ -2,25 +2,47 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/careplus/pharmacy-backend/pkg/tracing"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type inventoryService struct {
 	batchRepo   outbound.InventoryBatchRepository
 	productRepo outbound.ProductRepository
+	branchRepo  outbound.BranchRepository
+	uow         outbound.UnitOfWork
 }
 
-func NewInventoryService(batchRepo outbound.InventoryBatchRepository, productRepo outbound.ProductRepository) inbound.InventoryService {
-	return &inventoryService{batchRepo: batchRepo, productRepo: productRepo}
+// NewInventoryService builds the service. uow may be nil (tests); then a batch change and its product stock
+// adjustment are not committed together.
+func NewInventoryService(batchRepo outbound.InventoryBatchRepository, productRepo outbound.ProductRepository, branchRepo outbound.BranchRepository, uow outbound.UnitOfWork) inbound.InventoryService {
+	return &inventoryService{batchRepo: batchRepo, productRepo: productRepo, branchRepo: branchRepo, uow: uow}
 }
 
-func (s *inventoryService) AddBatch(ctx context.Context, pharmacyID, productID uuid.UUID, batchNumber string, quantity int, expiryDate *time.Time) (*models.InventoryBatch, error) {
+// adjustStock changes the product's stock by delta and fails when the change would take it below zero, so the
+// caller's transaction rolls back instead of leaving batches and product stock apart.
+func (s *inventoryService) adjustStock(ctx context.Context, productID uuid.UUID, delta int) error {
+	ok, err := s.productRepo.AdjustStock(ctx, productID, delta)
+	if err != nil {
+		return errors.ErrInternal("failed to update product stock", err)
+	}
+	if !ok {
+		return errors.ErrConflict("product stock changed meanwhile and would go negative; reload and try again")
+	}
+	return nil
+}
+
+// AddBatch stocks a new batch at branchID, or at the pharmacy's default branch when nil (pharmacy-wide if it has no branches).
+func (s *inventoryService) AddBatch(ctx context.Context, pharmacyID, productID uuid.UUID, batchNumber string, quantity int, expiryDate *time.Time, branchID *uuid.UUID) (*models.InventoryBatch, error) {
 	if quantity <= 0 {
 		return nil, errors.ErrValidation("quantity must be positive")
 	}
@@ -31,29 +53,68 @@ func (s *inventoryService) AddBatch(ctx context.Context, pharmacyID, productID u
 	if prod.PharmacyID != pharmacyID {
 		return nil, errors.ErrForbidden("product does not belong to this pharmacy")
 	}
+	branchID, err = s.resolveBranch(ctx, pharmacyID, branchID)
+	if err != nil {
+		return nil, err
+	}
 	b := &models.InventoryBatch{
 		ProductID:   productID,
 		PharmacyID:  pharmacyID,
+		BranchID:    branchID,
 		BatchNumber: batchNumber,
 		Quantity:    quantity,
 		ExpiryDate:  expiryDate,
 	}
-	if err := s.batchRepo.Create(ctx, b); err != nil {
-		return nil, errors.ErrInternal("failed to create batch", err)
-	}
-	prod.StockQuantity += quantity
-	if err := s.productRepo.Update(ctx, prod); err != nil {
-		return nil, errors.ErrInternal("failed to update product stock", err)
+	err = inTx(ctx, s.uow, func(ctx context.Context) error {
+		if err := s.batchRepo.Create(ctx, b); err != nil {
+			return errors.ErrInternal("failed to create batch", err)
+		}
+		return s.adjustStock(ctx, prod.ID, quantity)
+	})
+	if err != nil {
+		return nil, err
 	}
 	return b, nil
 }
 
+// resolveBranch checks that branchID belongs to the pharmacy, defaulting to its default branch when nil.
+func (s *inventoryService) resolveBranch(ctx context.Context, pharmacyID uuid.UUID, branchID *uuid.UUID) (*uuid.UUID, error) {
+	if s.branchRepo == nil {
+		return branchID, nil
+	}
+	if branchID == nil {
+		def, err := s.branchRepo.GetDefault(ctx, pharmacyID)
+		if err != nil {
+			return nil, errors.ErrInternal("failed to load default branch", err)
+		}
+		if def == nil {
+			return nil, nil
+		}
+		return &def.ID, nil
+	}
+	br, err := s.branchRepo.GetByID(ctx, *branchID)
+	if err != nil || br == nil || br.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("branch")
+	}
+	return branchID, nil
+}
+
 func (s *inventoryService) ListBatchesByProduct(ctx context.Context, productID uuid.UUID) ([]*models.InventoryBatch, error) {
 	return s.batchRepo.ListByProductID(ctx, productID)
 }
 
-func (s *inventoryService) ListBatchesByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.InventoryBatch, error) {
-	return s.batchRepo.ListByPharmacyID(ctx, pharmacyID)
+func (s *inventoryService) ListBatchesByPharmacy(ctx context.Context, pharmacyID uuid.UUID, branchID *uuid.UUID) ([]*models.InventoryBatch, error) {
+	list, err := s.batchRepo.ListByPharmacyID(ctx, pharmacyID)
+	if err != nil || branchID == nil {
+		return list, err
+	}
+	out := make([]*models.InventoryBatch, 0, len(list))
+	for _, b := range list {
+		if b.BranchID != nil && *b.BranchID == *branchID {
+			out = append(out, b)
+		}
+	}
+	return out, nil
 }
 
 func (s *inventoryService) ListExpiringSoon(ctx context.Context, pharmacyID uuid.UUID, withinDays int) ([]*models.InventoryBatch, error) {
@@ -76,13 +137,14 @@ func (s *inventoryService) UpdateBatch(ctx context.Context, id uuid.UUID, quanti
 		}
 		delta := *quantity - b.Quantity
 		b.Quantity = *quantity
-		if err := s.batchRepo.Update(ctx, b); err != nil {
-			return nil, errors.ErrInternal("failed to update batch", err)
-		}
-		prod, _ := s.productRepo.GetByID(ctx, b.ProductID)
-		if prod != nil {
-			prod.StockQuantity += delta
-			_ = s.productRepo.Update(ctx, prod)
+		err := inTx(ctx, s.uow, func(ctx context.Context) error {
+			if err := s.batchRepo.Update(ctx, b); err != nil {
+				return errors.ErrInternal("failed to update batch", err)
+			}
+			return s.adjustStock(ctx, b.ProductID, delta)
+		})
+		if err != nil {
+			return nil, err
 		}
 	} else if expiryDate != nil {
 		b.ExpiryDate = expiryDate
@@ -98,62 +160,143 @@ func (s *inventoryService) DeleteBatch(ctx context.Context, id uuid.UUID) error
 	if err != nil || b == nil {
 		return errors.ErrNotFound("inventory batch")
 	}
-	if err := s.batchRepo.Delete(ctx, id); err != nil {
-		return errors.ErrInternal("failed to delete batch", err)
-	}
-	prod, _ := s.productRepo.GetByID(ctx, b.ProductID)
-	if prod != nil {
-		prod.StockQuantity -= b.Quantity
-		if prod.StockQuantity < 0 {
-			prod.StockQuantity = 0
+	return inTx(ctx, s.uow, func(ctx context.Context) error {
+		if err := s.batchRepo.Delete(ctx, id); err != nil {
+			return errors.ErrInternal("failed to delete batch", err)
 		}
-		_ = s.productRepo.Update(ctx, prod)
-	}
-	return nil
+		prod, _ := s.productRepo.GetByID(ctx, b.ProductID)
+		if prod != nil && prod.StockQuantity > 0 {
+			return s.adjustStock(ctx, prod.ID, -min(b.Quantity, prod.StockQuantity))
+		}
+		return nil
+	})
+}
+
+// Consume deducts an order item's quantity from product stock. When the product has inventory batches they
+// are drawn FEFO (first expiry, first out), batches past their expiry date are never sold, and the draw is
+// recorded as OrderItemBatch rows (also set on item.Batches). Emptied batches are kept at zero so those rows
+// still point at them. Returns ErrValidation if there is not enough sellable stock, and ErrConflict when a
+// concurrent sale took it between the check and the draw.
+func (s *inventoryService) Consume(ctx context.Context, item *models.OrderItem) error {
+	return s.ConsumeAt(ctx, item, nil)
 }
 
-// Consume deducts quantity from product stock using FEFO (first expiry, first out).
-// If the product has inventory batches, deducts from batches first; then always
-// decrements product.StockQuantity. Returns ErrValidation if insufficient stock.
-func (s *inventoryService) Consume(ctx context.Context, productID uuid.UUID, quantity int) error {
+// ConsumeAt is Consume limited to one branch's batches; a nil branch draws from the whole pharmacy.
+// A batch-tracked product with no stock at the branch cannot be sold there.
+func (s *inventoryService) ConsumeAt(ctx context.Context, item *models.OrderItem, branchID *uuid.UUID) (err error) {
+	ctx, span := tracing.Start(ctx, "InventoryService.ConsumeAt", attribute.String("product.id", item.ProductID.String()), attribute.Int("quantity", item.Quantity))
+	defer tracing.End(span, &err)
+	quantity := item.Quantity
 	if quantity <= 0 {
 		return errors.ErrValidation("quantity must be positive")
 	}
-	prod, err := s.productRepo.GetByID(ctx, productID)
+	prod, err := s.productRepo.GetByID(ctx, item.ProductID)
 	if err != nil || prod == nil {
 		return errors.ErrNotFound("product")
 	}
 	if prod.StockQuantity < quantity {
 		return errors.ErrValidation("insufficient stock for " + prod.Name)
 	}
-	batches, err := s.batchRepo.ListByProductID(ctx, productID)
+	batches, err := s.batchRepo.ListByProductID(ctx, item.ProductID)
 	if err != nil {
 		return errors.ErrInternal("failed to list batches", err)
 	}
-	if len(batches) > 0 {
+	tracked := len(batches) > 0
+	if tracked && branchID != nil {
+		atBranch := batches[:0:0]
+		for _, b := range batches {
+			if b.BranchID != nil && *b.BranchID == *branchID {
+				atBranch = append(atBranch, b)
+			}
+		}
+		batches = atBranch
+	}
+	if tracked {
+		// The repository already returns FEFO order; sorting here keeps the rule in the domain.
+		sort.SliceStable(batches, func(i, j int) bool {
+			a, b := batches[i].ExpiryDate, batches[j].ExpiryDate
+			return a != nil && (b == nil || a.Before(*b))
+		})
+		today := startOfDay(time.Now())
+		usable, expired := 0, 0
+		for _, b := range batches {
+			if batchExpired(b, today) {
+				expired += b.Quantity
+			} else {
+				usable += b.Quantity
+			}
+		}
+		// Checked up front so a short order leaves every batch untouched.
+		if usable < quantity {
+			if expired > 0 {
+				return errors.ErrValidation(fmt.Sprintf("only %d unexpired units of %s in stock (%d expired)", usable, prod.Name, expired))
+			}
+			return errors.ErrValidation("insufficient batch stock for " + prod.Name)
+		}
 		remaining := quantity
 		for _, b := range batches {
 			if remaining <= 0 {
 				break
 			}
+			if batchExpired(b, today) || b.Quantity <= 0 {
+				continue
+			}
 			take := remaining
 			if take > b.Quantity {
 				take = b.Quantity
 			}
+			ok, err := s.batchRepo.Draw(ctx, b.ID, take)
+			if err != nil {
+				return errors.ErrInternal("failed to update batch", err)
+			}
+			if !ok {
+				return errors.ErrConflict("stock of " + prod.Name + " changed while ordering; please try again")
+			}
 			b.Quantity -= take
 			remaining -= take
-			if b.Quantity <= 0 {
-				_ = s.batchRepo.Delete(ctx, b.ID)
-			} else {
-				_ = s.batchRepo.Update(ctx, b)
+			alloc := &models.OrderItemBatch{
+				OrderItemID: item.ID,
+				BatchID:     b.ID,
+				ProductID:   b.ProductID,
+				BatchNumber: b.BatchNumber,
+				ExpiryDate:  b.ExpiryDate,
+				Quantity:    take,
 			}
+			if err := s.batchRepo.CreateAllocation(ctx, alloc); err != nil {
+				return errors.ErrInternal("failed to record batch allocation", err)
+			}
+			item.Batches = append(item.Batches, *alloc)
 		}
-		if remaining > 0 {
-			return errors.ErrValidation("insufficient batch stock for " + prod.Name)
-		}
+	}
+	ok, err := s.productRepo.AdjustStock(ctx, prod.ID, -quantity)
+	if err != nil {
+		return errors.ErrInternal("failed to update product stock", err)
+	}
+	if !ok {
+		return errors.ErrConflict("stock of " + prod.Name + " changed while ordering; please try again")
 	}
 	prod.StockQuantity -= quantity
-	return s.productRepo.Update(ctx, prod)
+	return nil
+}
+
+// batchExpired reports whether a batch's expiry date is before today; a batch is still sellable on its expiry day.
+func batchExpired(b *models.InventoryBatch, today time.Time) bool {
+	return b.ExpiryDate != nil && startOfDay(*b.ExpiryDate).Before(today)
+}
+
+func (s *inventoryService) ListBatchAllocations(ctx context.Context, pharmacyID, batchID uuid.UUID) ([]*models.OrderItemBatch, error) {
+	b, err := s.batchRepo.GetByID(ctx, batchID)
+	if err != nil || b == nil || b.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("inventory batch")
+	}
+	list, err := s.batchRepo.ListAllocationsByBatch(ctx, batchID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list batch allocations", err)
+	}
+	if list == nil {
+		list = []*models.OrderItemBatch{}
+	}
+	return list, nil
 }
 
 func (s *inventoryService) HasBatches(ctx context.Context, productID uuid.UUID) (bool, error) {
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// stockBatch describes a test batch: qty units expiring days from today (nil = no expiry).
+type stockBatch struct {
+	days *int
+	qty  int
+}
+
+type inventoryFixture struct {
+	product *models.Product
+	batches []*models.InventoryBatch
+	allocs  []*models.OrderItemBatch
+	svc     *inventoryService
+}
+
+// newInventoryFixture stocks a product with the given batches.
+func newInventoryFixture(batches ...stockBatch) *inventoryFixture {
+	f := &inventoryFixture{product: &models.Product{ID: uuid.New(), Name: "Paracetamol 500mg"}}
+	today := startOfDay(time.Now())
+	for i, b := range batches {
+		batch := &models.InventoryBatch{ID: uuid.New(), ProductID: f.product.ID, BatchNumber: "B" + string(rune('1'+i)), Quantity: b.qty}
+		if b.days != nil {
+			exp := today.AddDate(0, 0, *b.days)
+			batch.ExpiryDate = &exp
+		}
+		f.batches = append(f.batches, batch)
+		f.product.StockQuantity += b.qty
+	}
+	batchRepo := &mocks.MockInventoryBatchRepository{
+		// Insertion order on purpose: Consume must sort by expiry itself.
+		ListByProductIDFunc: func(ctx context.Context, productID uuid.UUID) ([]*models.InventoryBatch, error) {
+			var out []*models.InventoryBatch
+			for _, b := range f.batches {
+				if b.Quantity > 0 {
+					out = append(out, b)
+				}
+			}
+			return out, nil
+		},
+		CreateAllocationFunc: func(ctx context.Context, a *models.OrderItemBatch) error {
+			f.allocs = append(f.allocs, a)
+			return nil
+		},
+	}
+	products := &mocks.MockProductRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+			return f.product, nil
+		},
+	}
+	f.svc = NewInventoryService(batchRepo, products, nil, nil).(*inventoryService)
+	return f
+}
+
+func inDays(n int) *int { return &n }
+
+func TestInventoryService_Consume_DrawsFirstExpiryFirstAndRecordsBatches(t *testing.T) {
+	f := newInventoryFixture(
+		stockBatch{nil, 10},
+		stockBatch{inDays(30), 5},
+		stockBatch{inDays(0), 3},
+	)
+	item := &models.OrderItem{ID: uuid.New(), ProductID: f.product.ID, Quantity: 10}
+	if err := f.svc.Consume(context.Background(), item); err != nil {
+		t.Fatalf("Consume: %v", err)
+	}
+	// Expiring today (B3) first, then the 30-day batch (B2), then the undated one (B1).
+	want := []struct {
+		batch string
+		qty   int
+	}{{"B3", 3}, {"B2", 5}, {"B1", 2}}
+	if len(item.Batches) != len(want) || len(f.allocs) != len(want) {
+		t.Fatalf("expected %d allocations, got %+v", len(want), item.Batches)
+	}
+	for i, w := range want {
+		if item.Batches[i].BatchNumber != w.batch || item.Batches[i].Quantity != w.qty || item.Batches[i].OrderItemID != item.ID {
+			t.Errorf("allocation %d = %s x%d, want %s x%d", i, item.Batches[i].BatchNumber, item.Batches[i].Quantity, w.batch, w.qty)
+		}
+	}
+	if f.batches[0].Quantity != 8 || f.batches[1].Quantity != 0 || f.batches[2].Quantity != 0 {
+		t.Errorf("unexpected batch quantities after consume: %d, %d, %d", f.batches[0].Quantity, f.batches[1].Quantity, f.batches[2].Quantity)
+	}
+	if f.product.StockQuantity != 8 {
+		t.Errorf("expected product stock 8, got %d", f.product.StockQuantity)
+	}
+}
+
+func TestInventoryService_Consume_BlocksExpiredBatches(t *testing.T) {
+	f := newInventoryFixture(
+		stockBatch{inDays(-1), 6},
+		stockBatch{inDays(60), 4},
+	)
+	item := &models.OrderItem{ID: uuid.New(), ProductID: f.product.ID, Quantity: 5}
+	err := f.svc.Consume(context.Background(), item)
+	if pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation || !strings.Contains(err.Error(), "6 expired") {
+		t.Fatalf("expected validation error mentioning expired stock, got %v", err)
+	}
+	if f.batches[0].Quantity != 6 || f.batches[1].Quantity != 4 || f.product.StockQuantity != 10 || len(f.allocs) != 0 {
+		t.Errorf("a rejected consume must not touch stock")
+	}
+
+	item.Quantity = 4
+	if err := f.svc.Consume(context.Background(), item); err != nil {
+		t.Fatalf("Consume: %v", err)
+	}
+	if len(item.Batches) != 1 || item.Batches[0].BatchID != f.batches[1].ID || f.batches[0].Quantity != 6 {
+		t.Errorf("expected only the unexpired batch to be used, got %+v", item.Batches)
+	}
+}
+
+func TestInventoryService_Consume_WithoutBatchesUsesProductStock(t *testing.T) {
+	f := newInventoryFixture()
+	f.product.StockQuantity = 3
+	item := &models.OrderItem{ID: uuid.New(), ProductID: f.product.ID, Quantity: 2}
+	if err := f.svc.Consume(context.Background(), item); err != nil {
+		t.Fatalf("Consume: %v", err)
+	}
+	if f.product.StockQuantity != 1 || len(item.Batches) != 0 {
+		t.Errorf("expected plain stock decrement, got stock=%d batches=%d", f.product.StockQuantity, len(item.Batches))
+	}
+}
+
+func TestInventoryService_Consume_LostRaceIsConflict(t *testing.T) {
+	f := newInventoryFixture(stockBatch{inDays(30), 5})
+	// A concurrent order emptied the batch after it was listed.
+	f.svc.batchRepo.(*mocks.MockInventoryBatchRepository).DrawFunc = func(ctx context.Context, id uuid.UUID, quantity int) (bool, error) {
+		return false, nil
+	}
+	item := &models.OrderItem{ID: uuid.New(), ProductID: f.product.ID, Quantity: 3}
+	err := f.svc.Consume(context.Background(), item)
+	if pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeConflict {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+	if f.batches[0].Quantity != 5 || f.product.StockQuantity != 5 || len(f.allocs) != 0 {
+		t.Errorf("a lost draw must not record stock or allocations")
+	}
+}
+
+func TestInventoryService_ConsumeAt_DrawsOnlyFromTheBranch(t *testing.T) {
+	f := newInventoryFixture(
+		stockBatch{inDays(10), 5},
+		stockBatch{inDays(90), 4},
+	)
+	branch, other := uuid.New(), uuid.New()
+	f.batches[0].BranchID = &other
+	f.batches[1].BranchID = &branch
+
+	item := &models.OrderItem{ID: uuid.New(), ProductID: f.product.ID, Quantity: 5}
+	if err := f.svc.ConsumeAt(context.Background(), item, &branch); err == nil {
+		t.Fatal("expected the branch's 4 units to be too few for 5")
+	}
+	item.Quantity = 3
+	if err := f.svc.ConsumeAt(context.Background(), item, &branch); err != nil {
+		t.Fatalf("ConsumeAt: %v", err)
+	}
+	if len(item.Batches) != 1 || item.Batches[0].BatchID != f.batches[1].ID || f.batches[0].Quantity != 5 {
+		t.Errorf("expected only the branch's batch to be used, got %+v", item.Batches)
+	}
+}
+
+func TestInventoryService_BatchChanges_FailWhenStockAdjustmentFails(t *testing.T) {
+	ctx := context.Background()
+	batch := &models.InventoryBatch{ID: uuid.New(), ProductID: uuid.New(), Quantity: 10}
+	product := &models.Product{ID: batch.ProductID, StockQuantity: 10}
+	adjusted := 0
+	batchRepo := &mocks.MockInventoryBatchRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.InventoryBatch, error) {
+			cp := *batch
+			return &cp, nil
+		},
+	}
+	products := &mocks.MockProductRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) { return product, nil },
+		AdjustStockFunc: func(ctx context.Context, id uuid.UUID, delta int) (bool, error) {
+			return false, nil
+		},
+	}
+	uow := &mocks.MockUnitOfWork{}
+	svc := NewInventoryService(batchRepo, products, nil, uow)
+
+	// Stock sold meanwhile: lowering the batch would take the product below zero.
+	_, err := svc.UpdateBatch(ctx, batch.ID, inDays(4), nil)
+	if pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeConflict {
+		t.Errorf("UpdateBatch with the guard not met: err = %v, want conflict", err)
+	}
+	if err := svc.DeleteBatch(ctx, batch.ID); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeConflict {
+		t.Errorf("DeleteBatch with the guard not met: err = %v, want conflict", err)
+	}
+	if uow.RolledBack != 2 {
+		t.Errorf("rolled back %d transactions, want 2", uow.RolledBack)
+	}
+
+	products.AdjustStockFunc = func(ctx context.Context, id uuid.UUID, delta int) (bool, error) {
+		return false, context.DeadlineExceeded
+	}
+	if _, err := svc.AddBatch(ctx, uuid.Nil, product.ID, "B9", 5, nil, nil); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeInternal {
+		t.Errorf("AddBatch with a failed adjustment: err = %v, want internal", err)
+	}
+
+	products.AdjustStockFunc = func(ctx context.Context, id uuid.UUID, delta int) (bool, error) {
+		adjusted += delta
+		return true, nil
+	}
+	if _, err := svc.UpdateBatch(ctx, batch.ID, inDays(4), nil); err != nil || adjusted != -6 {
+		t.Errorf("UpdateBatch: err = %v, adjusted = %d, want -6", err, adjusted)
+	}
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
@@ -14,21 +15,36 @@ import (
 
 type invoiceService struct {
 	invRepo    outbound.InvoiceRepository
+	creditNoteRepo outbound.CreditNoteRepository
 	orderRepo  outbound.OrderRepository
 	paymentRepo outbound.PaymentRepository
+	deliveryRepo outbound.DeliveryRepository
+	configRepo outbound.PharmacyConfigRepository
+	mailer     inbound.MailerService
+	uow        outbound.UnitOfWork
 	logger     *zap.Logger
 }
 
 func NewInvoiceService(
 	invRepo outbound.InvoiceRepository,
+	creditNoteRepo outbound.CreditNoteRepository,
 	orderRepo outbound.OrderRepository,
 	paymentRepo outbound.PaymentRepository,
+	deliveryRepo outbound.DeliveryRepository,
+	configRepo outbound.PharmacyConfigRepository,
+	mailer inbound.MailerService,
+	uow outbound.UnitOfWork,
 	logger *zap.Logger,
 ) inbound.InvoiceService {
 	return &invoiceService{
 		invRepo:     invRepo,
+		creditNoteRepo: creditNoteRepo,
 		orderRepo:   orderRepo,
 		paymentRepo: paymentRepo,
+		deliveryRepo: deliveryRepo,
+		configRepo:  configRepo,
+		mailer:      mailer,
+		uow:         uow,
 		logger:     logger,
 	}
 }
@@ -41,18 +57,24 @@ func (s *invoiceService) CreateFromOrder(ctx context.Context, pharmacyID, orderI
 	if order.PharmacyID != pharmacyID {
 		return nil, errors.ErrForbidden("order does not belong to this pharmacy")
 	}
-	existing, _ := s.invRepo.GetByOrderID(ctx, orderID)
-	if existing != nil {
-		return nil, errors.ErrConflict("invoice already exists for this order")
-	}
 	inv := &models.Invoice{
 		PharmacyID: pharmacyID,
 		OrderID:    orderID,
 		Status:     models.InvoiceStatusDraft,
 		CreatedBy:  createdBy,
 	}
-	if err := s.invRepo.Create(ctx, inv); err != nil {
-		return nil, errors.ErrInternal("failed to create invoice", err)
+	err = inTx(ctx, s.uow, func(ctx context.Context) error {
+		existing, _ := s.invRepo.GetByOrderID(ctx, orderID)
+		if existing != nil {
+			return errors.ErrConflict("invoice already exists for this order")
+		}
+		if err := s.invRepo.Create(ctx, inv); err != nil {
+			return errors.ErrInternal("failed to create invoice", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return inv, nil
 }
@@ -70,11 +92,40 @@ func (s *invoiceService) GetByID(ctx context.Context, id uuid.UUID) (*inbound.In
 	if err != nil {
 		payments = nil
 	}
-	return &inbound.InvoiceView{
+	view := &inbound.InvoiceView{
 		Invoice:  inv,
 		Order:    order,
 		Payments: payments,
-	}, nil
+		TaxLines: taxBreakdown(order),
+		InvoiceTotal: invoiceTotal(order),
+		CreditNotes:  []*models.CreditNote{},
+	}
+	if s.creditNoteRepo != nil {
+		notes, err := s.creditNoteRepo.ListByOrder(ctx, order.ID)
+		if err != nil {
+			return nil, errors.ErrInternal("failed to load credit notes", err)
+		}
+		for _, n := range notes {
+			view.CreditedAmount += n.Amount
+		}
+		if notes != nil {
+			view.CreditNotes = notes
+		}
+	}
+	if s.deliveryRepo != nil {
+		if d, err := s.deliveryRepo.GetByOrderID(ctx, order.ID); err == nil && d != nil && d.Status == models.DeliveryStatusDelivered {
+			view.DeliveryProof = d.Proof()
+		}
+	}
+	view.CreditedAmount = models.RoundAmount(view.CreditedAmount, order.Currency)
+	view.NetAmount = models.RoundAmount(view.InvoiceTotal-view.CreditedAmount, order.Currency)
+	if s.configRepo != nil {
+		if cfg, _ := s.configRepo.GetByPharmacyID(ctx, inv.PharmacyID); cfg != nil {
+			view.TaxRegistrationNo = cfg.TaxRegistrationNo
+			view.CustomFields = invoiceFieldValues(cfg.OrderFields, order.CustomFields)
+		}
+	}
+	return view, nil
 }
 
 func (s *invoiceService) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Invoice, error) {
@@ -95,5 +146,136 @@ func (s *invoiceService) Issue(ctx context.Context, invoiceID uuid.UUID) (*model
 	if err := s.invRepo.Update(ctx, inv); err != nil {
 		return nil, errors.ErrInternal("failed to issue invoice", err)
 	}
+	if s.mailer != nil {
+		if order, err := s.orderRepo.GetByID(ctx, inv.OrderID); err == nil {
+			_ = s.mailer.InvoiceIssued(ctx, inv, order)
+		}
+	}
 	return inv, nil
 }
+
+// invoiceTotal is what the invoice covers: the amount due plus what gift card and store credit paid, rounded in
+// the order's currency like every invoice amount.
+func invoiceTotal(o *models.Order) float64 {
+	return models.RoundAmount(o.TotalAmount+o.GiftCardAmount+o.StoreCreditAmount, o.Currency)
+}
+
+func (s *invoiceService) IssueCreditNote(ctx context.Context, pharmacyID, orderID, actorID uuid.UUID, in inbound.CreditNoteInput) (*models.CreditNote, error) {
+	if in.Amount < 0 {
+		return nil, errors.ErrValidation("credit amount must not be negative")
+	}
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil || order == nil || order.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("order")
+	}
+	total := invoiceTotal(order)
+	credited, err := s.creditNoteRepo.SumByOrder(ctx, orderID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load credit notes", err)
+	}
+	amount := models.RoundAmount(total-credited, order.Currency)
+	if in.Amount > 0 && models.RoundAmount(in.Amount, order.Currency) < amount {
+		amount = models.RoundAmount(in.Amount, order.Currency)
+	}
+	if amount <= 0 {
+		return nil, nil
+	}
+	// VAT is credited in proportion to the share of the invoice being credited.
+	tax := 0.0
+	if total > 0 {
+		tax = models.RoundAmount(order.TaxAmount*amount/total, order.Currency)
+	}
+	n := &models.CreditNote{
+		PharmacyID:    pharmacyID,
+		OrderID:       orderID,
+		Source:        in.Source,
+		SourceID:      in.SourceID,
+		Reason:        strings.TrimSpace(in.Reason),
+		TaxableAmount: models.RoundAmount(amount-tax, order.Currency),
+		TaxAmount:     tax,
+		Amount:        amount,
+		IssuedAt:      time.Now(),
+		CreatedBy:     actorID,
+	}
+	// The invoice is only created or issued if the credit note referring to it is stored too.
+	err = inTx(ctx, s.uow, func(ctx context.Context) error {
+		inv, err := s.issuedInvoice(ctx, order, actorID)
+		if err != nil {
+			return err
+		}
+		n.InvoiceID = inv.ID
+		ok, err := s.creditNoteRepo.Create(ctx, n, total)
+		if err != nil {
+			return errors.ErrInternal("failed to create credit note", err)
+		}
+		if !ok {
+			return errors.ErrConflict("the invoice was credited at the same time; try again")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// issuedInvoice returns the order's invoice, creating or issuing it so the credit note has an issued document
+// to refer to. No invoice email is sent for these.
+func (s *invoiceService) issuedInvoice(ctx context.Context, order *models.Order, actorID uuid.UUID) (*models.Invoice, error) {
+	now := time.Now()
+	inv, _ := s.invRepo.GetByOrderID(ctx, order.ID)
+	if inv == nil {
+		inv = &models.Invoice{PharmacyID: order.PharmacyID, OrderID: order.ID, Status: models.InvoiceStatusIssued, IssuedAt: &now, CreatedBy: actorID}
+		if err := s.invRepo.Create(ctx, inv); err != nil {
+			return nil, errors.ErrInternal("failed to create invoice", err)
+		}
+		return inv, nil
+	}
+	if inv.Status != models.InvoiceStatusIssued {
+		inv.Status = models.InvoiceStatusIssued
+		inv.IssuedAt = &now
+		if err := s.invRepo.Update(ctx, inv); err != nil {
+			return nil, errors.ErrInternal("failed to issue invoice", err)
+		}
+	}
+	return inv, nil
+}
+
+func (s *invoiceService) ListCreditNotes(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.CreditNote, int64, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	list, total, err := s.creditNoteRepo.ListByPharmacy(ctx, pharmacyID, limit, offset)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to list credit notes", err)
+	}
+	if list == nil {
+		list = []*models.CreditNote{}
+	}
+	return list, total, nil
+}
+
+func (s *invoiceService) GetCreditNote(ctx context.Context, pharmacyID, id uuid.UUID) (*inbound.CreditNoteView, error) {
+	n, err := s.creditNoteRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load credit note", err)
+	}
+	if n == nil || n.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("credit note")
+	}
+	order, err := s.orderRepo.GetByID(ctx, n.OrderID)
+	if err != nil || order == nil {
+		return nil, errors.ErrNotFound("order")
+	}
+	view := &inbound.CreditNoteView{CreditNote: n, Invoice: n.Invoice, Order: order}
+	n.Invoice = nil
+	if s.configRepo != nil {
+		if cfg, _ := s.configRepo.GetByPharmacyID(ctx, pharmacyID); cfg != nil {
+			view.TaxRegistrationNo = cfg.TaxRegistrationNo
+		}
+	}
+	return view, nil
+}
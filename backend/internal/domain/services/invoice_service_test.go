@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestInvoiceService_IssueCreditNote_IssuesDraftAndCapsAtRemainder(t *testing.T) {
+	pharmacyID := uuid.New()
+	order := &models.Order{ID: uuid.New(), PharmacyID: pharmacyID, TotalAmount: 1030, StoreCreditAmount: 100, TaxAmount: 130}
+	draft := &models.Invoice{ID: uuid.New(), PharmacyID: pharmacyID, OrderID: order.ID, Status: models.InvoiceStatusDraft}
+	invoices := &mocks.MockInvoiceRepository{
+		GetByOrderIDFunc: func(ctx context.Context, orderID uuid.UUID) (*models.Invoice, error) { return draft, nil },
+	}
+	var maxTotal float64
+	notes := &mocks.MockCreditNoteRepository{
+		SumByOrderFunc: func(ctx context.Context, orderID uuid.UUID) (float64, error) { return 565, nil },
+		CreateFunc: func(ctx context.Context, n *models.CreditNote, max float64) (bool, error) {
+			maxTotal = max
+			return true, nil
+		},
+	}
+	orders := &mocks.MockOrderRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Order, error) { return order, nil },
+	}
+	svc := &invoiceService{invRepo: invoices, creditNoteRepo: notes, orderRepo: orders, logger: zap.NewNop()}
+
+	n, err := svc.IssueCreditNote(context.Background(), pharmacyID, order.ID, uuid.New(), inbound.CreditNoteInput{Source: models.CreditNoteSourceReturn, Amount: 1000, Reason: " damaged "})
+	if err != nil {
+		t.Fatalf("IssueCreditNote: %v", err)
+	}
+	if n.Amount != 565 || maxTotal != 1130 {
+		t.Errorf("amount = %v, cap = %v, want the 565 left of 1130", n.Amount, maxTotal)
+	}
+	if n.TaxAmount != 65 || n.TaxableAmount != 500 {
+		t.Errorf("tax = %v, taxable = %v, want 65 and 500", n.TaxAmount, n.TaxableAmount)
+	}
+	if n.InvoiceID != draft.ID || n.Reason != "damaged" {
+		t.Errorf("invoice = %v, reason = %q", n.InvoiceID, n.Reason)
+	}
+	if draft.Status != models.InvoiceStatusIssued || draft.IssuedAt == nil {
+		t.Errorf("draft invoice was not issued: %+v", draft)
+	}
+}
+
+func TestInvoiceService_IssueCreditNote_FullyCredited(t *testing.T) {
+	pharmacyID := uuid.New()
+	order := &models.Order{ID: uuid.New(), PharmacyID: pharmacyID, TotalAmount: 200}
+	notes := &mocks.MockCreditNoteRepository{
+		SumByOrderFunc: func(ctx context.Context, orderID uuid.UUID) (float64, error) { return 200, nil },
+		CreateFunc: func(ctx context.Context, n *models.CreditNote, max float64) (bool, error) {
+			t.Fatal("no credit note should be created")
+			return false, nil
+		},
+	}
+	orders := &mocks.MockOrderRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Order, error) { return order, nil },
+	}
+	svc := &invoiceService{invRepo: &mocks.MockInvoiceRepository{}, creditNoteRepo: notes, orderRepo: orders, logger: zap.NewNop()}
+
+	n, err := svc.IssueCreditNote(context.Background(), pharmacyID, order.ID, uuid.New(), inbound.CreditNoteInput{Source: models.CreditNoteSourceRefund})
+	if err != nil || n != nil {
+		t.Fatalf("got %v, %v; want nothing to credit", n, err)
+	}
+	_, err = svc.IssueCreditNote(context.Background(), uuid.New(), order.ID, uuid.New(), inbound.CreditNoteInput{})
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeNotFound {
+		t.Errorf("err = %v, want not found for another pharmacy's order", err)
+	}
+}
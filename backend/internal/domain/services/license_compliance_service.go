@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type licenseComplianceService struct {
+	userRepo            outbound.UserRepository
+	configRepo          outbound.PharmacyConfigRepository
+	pharmacyRepo        outbound.PharmacyRepository
+	notificationService inbound.NotificationService
+	now                 func() time.Time
+	logger              *zap.Logger
+}
+
+func NewLicenseComplianceService(userRepo outbound.UserRepository, configRepo outbound.PharmacyConfigRepository, pharmacyRepo outbound.PharmacyRepository, notificationService inbound.NotificationService, logger *zap.Logger) inbound.LicenseComplianceService {
+	return &licenseComplianceService{userRepo: userRepo, configRepo: configRepo, pharmacyRepo: pharmacyRepo, notificationService: notificationService, now: time.Now, logger: logger}
+}
+
+func (s *licenseComplianceService) policy(ctx context.Context, pharmacyID uuid.UUID) *models.LicensePolicy {
+	if cfg, err := s.configRepo.GetByPharmacyID(ctx, pharmacyID); err == nil && cfg != nil && cfg.LicensePolicy != nil {
+		return cfg.LicensePolicy
+	}
+	return models.DefaultLicensePolicy()
+}
+
+// tracksLicense reports whether the member belongs in the overview and the reminders.
+func tracksLicense(u *models.User) bool {
+	return u.IsActive && (u.Role == models.RolePharmacist || u.LicenseNumber != "" || u.LicenseExpiresAt != nil)
+}
+
+func maxReminderDays(p *models.LicensePolicy) int {
+	m := 0
+	for _, d := range p.ReminderDays {
+		if d > m {
+			m = d
+		}
+	}
+	return m
+}
+
+func licenseStatus(u *models.User, now time.Time, warnDays int) string {
+	days := u.LicenseDaysLeft(now)
+	switch {
+	case days != nil && *days < 0:
+		return models.LicenseStatusExpired
+	case days == nil || strings.TrimSpace(u.LicenseNumber) == "":
+		return models.LicenseStatusMissing
+	case *days <= warnDays:
+		return models.LicenseStatusExpiring
+	default:
+		return models.LicenseStatusValid
+	}
+}
+
+var licenseStatusOrder = map[string]int{
+	models.LicenseStatusExpired: 0, models.LicenseStatusExpiring: 1, models.LicenseStatusMissing: 2, models.LicenseStatusValid: 3,
+}
+
+func (s *licenseComplianceService) Overview(ctx context.Context, pharmacyID uuid.UUID) (*inbound.LicenseComplianceOverview, error) {
+	users, err := s.userRepo.GetByPharmacyID(ctx, pharmacyID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load team members", err)
+	}
+	policy := s.policy(ctx, pharmacyID)
+	now := s.now()
+	warn := maxReminderDays(policy)
+	out := &inbound.LicenseComplianceOverview{
+		GeneratedAt:    now,
+		ReminderDays:   policy.ReminderDays,
+		BlockExpiredRx: policy.BlockExpiredRx,
+		Counts:         map[string]int{models.LicenseStatusValid: 0, models.LicenseStatusExpiring: 0, models.LicenseStatusExpired: 0, models.LicenseStatusMissing: 0},
+		Members:        []*inbound.LicenseComplianceRow{},
+	}
+	for _, u := range users {
+		if !tracksLicense(u) {
+			continue
+		}
+		row := &inbound.LicenseComplianceRow{
+			UserID: u.ID, Name: u.Name, Email: u.Email, Role: u.Role, LicenseNumber: u.LicenseNumber,
+			ExpiresAt: u.LicenseExpiresAt, DaysLeft: u.LicenseDaysLeft(now), Status: licenseStatus(u, now, warn),
+		}
+		out.Counts[row.Status]++
+		out.Members = append(out.Members, row)
+	}
+	sort.SliceStable(out.Members, func(i, j int) bool {
+		a, b := out.Members[i], out.Members[j]
+		if licenseStatusOrder[a.Status] != licenseStatusOrder[b.Status] {
+			return licenseStatusOrder[a.Status] < licenseStatusOrder[b.Status]
+		}
+		if a.DaysLeft != nil && b.DaysLeft != nil && *a.DaysLeft != *b.DaysLeft {
+			return *a.DaysLeft < *b.DaysLeft
+		}
+		return a.Name < b.Name
+	})
+	return out, nil
+}
+
+// reminderDue returns the threshold to notify for days left (0 = expired notice), or -1 when no reminder is due
+// or it was already sent.
+func reminderDue(policy *models.LicensePolicy, daysLeft int, sent *int) int {
+	due := -1
+	if daysLeft < 0 {
+		due = 0
+	} else {
+		for _, d := range policy.ReminderDays {
+			if daysLeft <= d && (due == -1 || d < due) {
+				due = d
+			}
+		}
+	}
+	if due == -1 || (sent != nil && *sent <= due) {
+		return -1
+	}
+	return due
+}
+
+func (s *licenseComplianceService) SendReminders(ctx context.Context) error {
+	pharmacies, err := s.pharmacyRepo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("list pharmacies: %w", err)
+	}
+	failed := 0
+	for _, p := range pharmacies {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !p.IsActive {
+			continue
+		}
+		n, err := s.remind(ctx, p.ID)
+		if err != nil {
+			failed++
+			s.logger.Warn("license reminders failed", zap.String("pharmacy_id", p.ID.String()), zap.Error(err))
+			continue
+		}
+		if n > 0 {
+			s.logger.Info("license reminders sent", zap.String("pharmacy_id", p.ID.String()), zap.Int("members", n))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("license reminders failed for %d of %d pharmacies", failed, len(pharmacies))
+	}
+	return nil
+}
+
+func (s *licenseComplianceService) remind(ctx context.Context, pharmacyID uuid.UUID) (int, error) {
+	users, err := s.userRepo.GetByPharmacyID(ctx, pharmacyID)
+	if err != nil {
+		return 0, err
+	}
+	policy := s.policy(ctx, pharmacyID)
+	now := s.now()
+	sent := 0
+	for _, u := range users {
+		days := u.LicenseDaysLeft(now)
+		if !u.IsActive || days == nil {
+			continue
+		}
+		due := reminderDue(policy, *days, u.LicenseReminderDays)
+		if due == -1 {
+			continue
+		}
+		// Marked first so a failing notification never repeats the reminder on every run.
+		if err := s.userRepo.SetLicenseReminderDays(ctx, u.ID, due); err != nil {
+			return sent, err
+		}
+		s.notify(ctx, pharmacyID, u, *days, users)
+		sent++
+	}
+	return sent, nil
+}
+
+// notify tells the license holder and the pharmacy's active admins. Failures are logged, never returned.
+func (s *licenseComplianceService) notify(ctx context.Context, pharmacyID uuid.UUID, holder *models.User, daysLeft int, members []*models.User) {
+	if s.notificationService == nil {
+		return
+	}
+	date := holder.LicenseExpiresAt.UTC().Format("2006-01-02")
+	when := fmt.Sprintf("expires in %d days, on %s", daysLeft, date)
+	switch {
+	case daysLeft < 0:
+		when = "expired on " + date
+	case daysLeft == 0:
+		when = "expires today (" + date + ")"
+	case daysLeft == 1:
+		when = "expires tomorrow (" + date + ")"
+	}
+	send := func(to *models.User, title, message string) {
+		if _, err := s.notificationService.Create(ctx, pharmacyID, to.ID, title, message, "compliance"); err != nil {
+			s.logger.Warn("failed to send license reminder", zap.String("user_id", to.ID.String()), zap.Error(err))
+		}
+	}
+	title := "License expiry reminder"
+	if daysLeft < 0 {
+		title = "License expired"
+	}
+	send(holder, title, fmt.Sprintf("Your pharmacist license %s %s. Please renew it and update your profile.", holder.LicenseNumber, when))
+	for _, a := range members {
+		if a.ID == holder.ID || !a.IsActive || a.Role != models.RoleAdmin {
+			continue
+		}
+		send(a, title, fmt.Sprintf("%s's pharmacist license %s %s. See Team > License compliance.", holder.Name, holder.LicenseNumber, when))
+	}
+}
+
+func (s *licenseComplianceService) CheckDispense(ctx context.Context, pharmacyID, userID uuid.UUID) error {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || u == nil || !u.LicenseExpired(s.now()) {
+		return nil
+	}
+	if !s.policy(ctx, pharmacyID).BlockExpiredRx {
+		return nil
+	}
+	return errors.ErrForbidden("your pharmacist license expired on " + u.LicenseExpiresAt.UTC().Format("2006-01-02") + "; prescription items cannot be dispensed until it is renewed")
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestLicenseComplianceService_SendReminders_OncePerThreshold(t *testing.T) {
+	pharmacyID := uuid.New()
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	expires := time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)
+	pharmacist := &models.User{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Ram", Role: models.RolePharmacist, IsActive: true, LicenseNumber: "NPC-1", LicenseExpiresAt: &expires}
+	admin := &models.User{ID: uuid.New(), PharmacyID: pharmacyID, Role: models.RoleAdmin, IsActive: true}
+	staff := &models.User{ID: uuid.New(), PharmacyID: pharmacyID, Role: models.RoleStaff, IsActive: true}
+	users := &mocks.MockUserRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, id uuid.UUID) ([]*models.User, error) {
+			return []*models.User{pharmacist, admin, staff}, nil
+		},
+		SetLicenseReminderDaysFunc: func(ctx context.Context, id uuid.UUID, days int) error {
+			pharmacist.LicenseReminderDays = &days
+			return nil
+		},
+	}
+	pharmacies := &mocks.MockPharmacyRepository{
+		ListFunc: func(ctx context.Context) ([]*models.Pharmacy, error) {
+			return []*models.Pharmacy{{ID: pharmacyID, IsActive: true}}, nil
+		},
+	}
+	notifier := &recordingNotifier{}
+	svc := &licenseComplianceService{userRepo: users, configRepo: &mocks.MockPharmacyConfigRepository{}, pharmacyRepo: pharmacies, notificationService: notifier, now: func() time.Time { return now }, logger: zap.NewNop()}
+	ctx := context.Background()
+
+	// 24 days left: inside the 30-day window, sent to the pharmacist and the admin only.
+	if err := svc.SendReminders(ctx); err != nil {
+		t.Fatalf("SendReminders: %v", err)
+	}
+	if len(notifier.sent) != 2 || pharmacist.LicenseReminderDays == nil || *pharmacist.LicenseReminderDays != 30 {
+		t.Fatalf("sent %d notifications, marker %v; want 2 for the 30-day reminder", len(notifier.sent), pharmacist.LicenseReminderDays)
+	}
+	if err := svc.SendReminders(ctx); err != nil || len(notifier.sent) != 2 {
+		t.Fatalf("second run sent %d notifications (err %v), want no repeat", len(notifier.sent), err)
+	}
+	now = time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)
+	_ = svc.SendReminders(ctx)
+	if len(notifier.sent) != 4 || *pharmacist.LicenseReminderDays != 7 {
+		t.Fatalf("after 7-day threshold: sent %d, marker %d", len(notifier.sent), *pharmacist.LicenseReminderDays)
+	}
+	now = time.Date(2026, 10, 26, 9, 0, 0, 0, time.UTC)
+	_ = svc.SendReminders(ctx)
+	if len(notifier.sent) != 6 || *pharmacist.LicenseReminderDays != 0 || notifier.sent[4].Title != "License expired" {
+		t.Fatalf("after expiry: sent %d, marker %d", len(notifier.sent), *pharmacist.LicenseReminderDays)
+	}
+}
+
+func TestLicenseComplianceService_CheckDispense_FollowsPolicy(t *testing.T) {
+	pharmacyID := uuid.New()
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	lastDay := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	u := &models.User{ID: uuid.New(), PharmacyID: pharmacyID, Role: models.RolePharmacist, LicenseExpiresAt: &lastDay}
+	cfg := &models.PharmacyConfig{}
+	svc := &licenseComplianceService{
+		userRepo:   &mocks.MockUserRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.User, error) { return u, nil }},
+		configRepo: &mocks.MockPharmacyConfigRepository{GetByPharmacyIDFunc: func(ctx context.Context, id uuid.UUID) (*models.PharmacyConfig, error) { return cfg, nil }},
+		now:        func() time.Time { return now },
+		logger:     zap.NewNop(),
+	}
+	ctx := context.Background()
+
+	if err := svc.CheckDispense(ctx, pharmacyID, u.ID); err != nil {
+		t.Errorf("license is valid through its expiry date, got %v", err)
+	}
+	now = now.AddDate(0, 0, 1)
+	err := svc.CheckDispense(ctx, pharmacyID, u.ID)
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeForbidden {
+		t.Errorf("expired license: err = %v, want forbidden", err)
+	}
+	cfg.LicensePolicy = &models.LicensePolicy{ReminderDays: []int{30}, BlockExpiredRx: false}
+	if err := svc.CheckDispense(ctx, pharmacyID, u.ID); err != nil {
+		t.Errorf("blocking disabled: err = %v", err)
+	}
+}
+
+func TestOrderService_Accept_BlocksRxForExpiredLicense(t *testing.T) {
+	pharmacyID, actorID := uuid.New(), uuid.New()
+	expired := time.Now().AddDate(0, 0, -3)
+	o := &models.Order{ID: uuid.New(), PharmacyID: pharmacyID, Status: models.OrderStatusPending, Items: []models.OrderItem{{Product: &models.Product{RequiresRx: true}}}}
+	repo := &mocks.MockOrderRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Order, error) { return o, nil },
+		UpdateStatusFunc: func(ctx context.Context, order *models.Order, h *models.OrderStatusHistory) error {
+			return nil
+		},
+	}
+	users := &mocks.MockUserRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.User, error) {
+		return &models.User{ID: id, Role: models.RolePharmacist, LicenseExpiresAt: &expired}, nil
+	}}
+	licenses := &licenseComplianceService{userRepo: users, configRepo: &mocks.MockPharmacyConfigRepository{}, now: time.Now, logger: zap.NewNop()}
+	svc := &orderService{orderRepo: repo, userRepo: users, licenseSvc: licenses, logger: zap.NewNop()}
+
+	if _, err := svc.Accept(context.Background(), o.ID, actorID, ""); err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeForbidden {
+		t.Fatalf("Accept: err = %v, want forbidden for an expired license", err)
+	}
+	o.Items[0].Product.RequiresRx = false
+	if _, err := svc.Accept(context.Background(), o.ID, actorID, ""); err != nil {
+		t.Fatalf("Accept without prescription items: %v", err)
+	}
+}
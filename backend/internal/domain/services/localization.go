@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+)
+
+// pharmacyLanguage returns the pharmacy's default_language, or "en" when it is unset or cannot be loaded.
+func pharmacyLanguage(ctx context.Context, configRepo outbound.PharmacyConfigRepository, pharmacyID uuid.UUID) string {
+	if configRepo != nil {
+		if cfg, err := configRepo.GetByPharmacyID(ctx, pharmacyID); err == nil && cfg != nil {
+			return models.ResolveLanguage(cfg.DefaultLanguage, "en")
+		}
+	}
+	return "en"
+}
+
+// customerLanguage returns the preferred language of the order's customer, or "" when there is none.
+func customerLanguage(ctx context.Context, customerRepo outbound.CustomerRepository, order *models.Order) string {
+	if order.Customer != nil {
+		return order.Customer.PreferredLanguage
+	}
+	return customerIDLanguage(ctx, customerRepo, order.CustomerID)
+}
+
+// customerIDLanguage returns the preferred language of the customer with the id, or "" when there is none.
+func customerIDLanguage(ctx context.Context, customerRepo outbound.CustomerRepository, customerID *uuid.UUID) string {
+	if customerID == nil || customerRepo == nil {
+		return ""
+	}
+	if c, err := customerRepo.GetByID(ctx, *customerID); err == nil && c != nil {
+		return c.PreferredLanguage
+	}
+	return ""
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type mailerService struct {
+	emailSender  outbound.EmailSender
+	configRepo   outbound.PharmacyConfigRepository
+	pharmacyRepo outbound.PharmacyRepository
+	customerRepo outbound.CustomerRepository
+	publicURL    string
+	logger       *zap.Logger
+}
+
+// NewMailerService builds the transactional mailer. publicURL is the web app base used for links in emails.
+// Emails go out in the recipient's preferred language (customerRepo resolves it for orders), else the pharmacy default.
+func NewMailerService(emailSender outbound.EmailSender, configRepo outbound.PharmacyConfigRepository, pharmacyRepo outbound.PharmacyRepository, customerRepo outbound.CustomerRepository, publicURL string, logger *zap.Logger) inbound.MailerService {
+	return &mailerService{
+		emailSender:  emailSender,
+		configRepo:   configRepo,
+		pharmacyRepo: pharmacyRepo,
+		customerRepo: customerRepo,
+		publicURL:    strings.TrimSuffix(publicURL, "/"),
+		logger:       logger,
+	}
+}
+
+// orderStatusNotes adds a line of guidance to status emails where the customer has something to do, per language.
+var orderStatusNotes = map[string]map[models.OrderStatus]string{
+	"en": {
+		models.OrderStatusReady:     "Your order is ready for pickup or dispatch.",
+		models.OrderStatusCancelled: "If you did not expect this, please contact the pharmacy.",
+	},
+	"ne": {
+		models.OrderStatusReady:     "तपाईंको अर्डर लैजान वा पठाउन तयार छ।",
+		models.OrderStatusCancelled: "यो अपेक्षित थिएन भने कृपया फार्मेसीमा सम्पर्क गर्नुहोस्।",
+	},
+}
+
+// orderStatusLabels translates status names in emails; English uses the status value itself.
+var orderStatusLabels = map[string]map[models.OrderStatus]string{
+	"ne": {
+		models.OrderStatusPending:    "प्रक्रियामा",
+		models.OrderStatusConfirmed:  "पुष्टि भयो",
+		models.OrderStatusProcessing: "तयारी हुँदैछ",
+		models.OrderStatusReady:      "तयार",
+		models.OrderStatusCompleted:  "पूरा भयो",
+		models.OrderStatusCancelled:  "रद्द भयो",
+	},
+}
+
+func (s *mailerService) OrderConfirmation(ctx context.Context, order *models.Order) error {
+	if order == nil || order.CustomerEmail == "" {
+		return nil
+	}
+	lang := s.language(ctx, order.PharmacyID, customerLanguage(ctx, s.customerRepo, order))
+	return s.send(ctx, order.PharmacyID, order.CustomerEmail, orderConfirmationEmails.pick(lang), map[string]any{
+		"Name":     customerName(order.CustomerName),
+		"Order":    order,
+		"OrderURL": s.link("/orders/" + order.ID.String()),
+	})
+}
+
+func (s *mailerService) OrderStatusChanged(ctx context.Context, order *models.Order) error {
+	if order == nil || order.CustomerEmail == "" {
+		return nil
+	}
+	lang := s.language(ctx, order.PharmacyID, customerLanguage(ctx, s.customerRepo, order))
+	status := string(order.Status)
+	if label, ok := orderStatusLabels[lang][order.Status]; ok {
+		status = label
+	}
+	return s.send(ctx, order.PharmacyID, order.CustomerEmail, orderStatusEmails.pick(lang), map[string]any{
+		"Name":       customerName(order.CustomerName),
+		"Order":      order,
+		"Status":     status,
+		"StatusNote": orderStatusNotes[lang][order.Status],
+		"OrderURL":   s.link("/orders/" + order.ID.String()),
+	})
+}
+
+func (s *mailerService) InvoiceIssued(ctx context.Context, invoice *models.Invoice, order *models.Order) error {
+	if invoice == nil || order == nil || order.CustomerEmail == "" {
+		return nil
+	}
+	lang := s.language(ctx, invoice.PharmacyID, customerLanguage(ctx, s.customerRepo, order))
+	return s.send(ctx, invoice.PharmacyID, order.CustomerEmail, invoiceIssuedEmails.pick(lang), map[string]any{
+		"Name":    customerName(order.CustomerName),
+		"Invoice": invoice,
+		"Order":   order,
+	})
+}
+
+func (s *mailerService) PasswordReset(ctx context.Context, user *models.User, resetURL string, expiresAt time.Time) error {
+	if user == nil || user.Email == "" {
+		return nil
+	}
+	return s.send(ctx, user.PharmacyID, user.Email, passwordResetEmails.pick(s.language(ctx, user.PharmacyID, user.PreferredLanguage)), map[string]any{
+		"Name":      customerName(user.Name),
+		"ResetURL":  resetURL,
+		"ExpiresAt": expiresAt.UTC().Format("2006-01-02 15:04 UTC"),
+	})
+}
+
+func (s *mailerService) LoginCode(ctx context.Context, user *models.User, code string, expiresAt time.Time) error {
+	if user == nil || user.Email == "" {
+		return nil
+	}
+	return s.send(ctx, user.PharmacyID, user.Email, loginCodeEmails.pick(s.language(ctx, user.PharmacyID, user.PreferredLanguage)), map[string]any{
+		"Name":      customerName(user.Name),
+		"Code":      code,
+		"ExpiresAt": expiresAt.UTC().Format("2006-01-02 15:04 UTC"),
+	})
+}
+
+func (s *mailerService) StaffAccountCreated(ctx context.Context, user *models.User) error {
+	if user == nil || user.Email == "" {
+		return nil
+	}
+	return s.send(ctx, user.PharmacyID, user.Email, staffAccountEmails.pick(s.language(ctx, user.PharmacyID, user.PreferredLanguage)), map[string]any{
+		"Name":     customerName(user.Name),
+		"Email":    user.Email,
+		"Role":     user.Role,
+		"LoginURL": s.link("/login"),
+	})
+}
+
+func (s *mailerService) CampaignMessage(ctx context.Context, pharmacyID uuid.UUID, to, name, language, subject, message string) error {
+	if to == "" {
+		return nil
+	}
+	var paragraphs []string
+	for _, p := range strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			paragraphs = append(paragraphs, p)
+		}
+	}
+	return s.send(ctx, pharmacyID, to, campaignEmails.pick(s.language(ctx, pharmacyID, language)), map[string]any{
+		"Name":       customerName(name),
+		"Subject":    subject,
+		"Message":    message,
+		"Paragraphs": paragraphs,
+	})
+}
+
+func (s *mailerService) WarrantyClaimUpdated(ctx context.Context, claim *models.WarrantyClaim) error {
+	if claim == nil || claim.Warranty == nil || claim.Warranty.CustomerEmail == "" {
+		return nil
+	}
+	w := claim.Warranty
+	lang := s.language(ctx, w.PharmacyID, customerIDLanguage(ctx, s.customerRepo, w.CustomerID))
+	return s.send(ctx, w.PharmacyID, w.CustomerEmail, warrantyClaimEmails.pick(lang), map[string]any{
+		"Name":    customerName(w.CustomerName),
+		"Message": warrantyClaimMessage(lang, claim),
+		"Note":    claim.Note,
+	})
+}
+
+func (s *mailerService) send(ctx context.Context, pharmacyID uuid.UUID, to string, tpl *emailTemplate, data map[string]any) error {
+	if s.emailSender == nil {
+		return nil
+	}
+	data["PharmacyName"] = s.pharmacyName(ctx, pharmacyID)
+	subject, text, html, err := tpl.render(data)
+	if err != nil {
+		s.logger.Error("failed to render email", zap.Error(err), zap.String("template", tpl.subject.Name()))
+		return err
+	}
+	msg := &outbound.EmailMessage{PharmacyID: pharmacyID, To: []string{to}, Subject: subject, TextBody: text, HTMLBody: html}
+	if err := s.emailSender.Send(ctx, msg); err != nil {
+		s.logger.Warn("failed to send email", zap.Error(err), zap.String("subject", subject))
+		return err
+	}
+	return nil
+}
+
+// language resolves the email language: the recipient's preference when supported, else the pharmacy default.
+func (s *mailerService) language(ctx context.Context, pharmacyID uuid.UUID, preferred string) string {
+	return models.ResolveLanguage(preferred, pharmacyLanguage(ctx, s.configRepo, pharmacyID))
+}
+
+// pharmacyName prefers the branded display name from pharmacy config, then the pharmacy record.
+func (s *mailerService) pharmacyName(ctx context.Context, pharmacyID uuid.UUID) string {
+	if s.configRepo != nil {
+		if cfg, err := s.configRepo.GetByPharmacyID(ctx, pharmacyID); err == nil && cfg != nil && cfg.DisplayName != "" {
+			return cfg.DisplayName
+		}
+	}
+	if s.pharmacyRepo != nil {
+		if p, err := s.pharmacyRepo.GetByID(ctx, pharmacyID); err == nil && p != nil && p.Name != "" {
+			return p.Name
+		}
+	}
+	return "CarePlus Pharmacy"
+}
+
+func (s *mailerService) link(path string) string {
+	if s.publicURL == "" {
+		return ""
+	}
+	return s.publicURL + path
+}
+
+func customerName(name string) string {
+	if strings.TrimSpace(name) == "" {
+		return "there"
+	}
+	return name
+}
+
+// formatMoney shows an amount with its currency's minor units (JPY 1500, NPR 1500.00).
+func formatMoney(currency string, amount float64) string {
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+	minor := 2
+	if c, ok := models.Currencies[currency]; ok {
+		minor = c.MinorUnits
+	}
+	return fmt.Sprintf("%s %.*f", currency, minor, models.RoundAmount(amount, currency))
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type captureSender struct {
+	sent []*outbound.EmailMessage
+}
+
+func (c *captureSender) Send(ctx context.Context, msg *outbound.EmailMessage) error {
+	c.sent = append(c.sent, msg)
+	return nil
+}
+
+func TestMailerService_OrderConfirmation_RendersItemsAndEscapesHTML(t *testing.T) {
+	sender := &captureSender{}
+	svc := NewMailerService(sender, nil, nil, nil, "https://shop.example/", zap.NewNop())
+	order := &models.Order{
+		ID:            uuid.New(),
+		OrderNumber:   "ORD-1234",
+		CustomerName:  "<b>Sita</b>",
+		CustomerEmail: "sita@example.com",
+		Currency:      "NPR",
+		TotalAmount:   226,
+		Items:         []models.OrderItem{{Quantity: 2, TotalPrice: 200, Product: &models.Product{Name: "Paracetamol"}}},
+	}
+	if err := svc.OrderConfirmation(context.Background(), order); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected 1 email, got %d", len(sender.sent))
+	}
+	msg := sender.sent[0]
+	if msg.To[0] != "sita@example.com" || !strings.Contains(msg.Subject, "ORD-1234") {
+		t.Errorf("unexpected envelope: %v %q", msg.To, msg.Subject)
+	}
+	if !strings.Contains(msg.TextBody, "Paracetamol x2: NPR 200.00") || !strings.Contains(msg.TextBody, "Total: NPR 226.00") {
+		t.Errorf("text body missing items or total:\n%s", msg.TextBody)
+	}
+	if !strings.Contains(msg.TextBody, "https://shop.example/orders/"+order.ID.String()) {
+		t.Errorf("text body missing order link:\n%s", msg.TextBody)
+	}
+	if strings.Contains(msg.HTMLBody, "<b>Sita</b>") {
+		t.Errorf("customer name was not escaped in HTML body")
+	}
+}
+
+func TestMailerService_SkipsWithoutRecipient(t *testing.T) {
+	sender := &captureSender{}
+	svc := NewMailerService(sender, nil, nil, nil, "", zap.NewNop())
+	if err := svc.OrderStatusChanged(context.Background(), &models.Order{Status: models.OrderStatusReady}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sender.sent) != 0 {
+		t.Errorf("expected no email without customer email, got %d", len(sender.sent))
+	}
+}
+
+func TestMailerService_OrderStatusChanged_UsesCustomerLanguage(t *testing.T) {
+	sender := &captureSender{}
+	svc := NewMailerService(sender, nil, nil, nil, "", zap.NewNop())
+	order := &models.Order{
+		OrderNumber:   "ORD-77",
+		CustomerEmail: "ram@example.com",
+		Status:        models.OrderStatusReady,
+		Customer:      &models.Customer{PreferredLanguage: "ne"},
+	}
+	if err := svc.OrderStatusChanged(context.Background(), order); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected 1 email, got %d", len(sender.sent))
+	}
+	if body := sender.sent[0].TextBody; !strings.Contains(body, "तयार") || strings.Contains(body, "ready") {
+		t.Errorf("expected the Nepali template and status label:\n%s", body)
+	}
+}
+
+func TestMailerService_CampaignMessage_SplitsParagraphsAndEscapes(t *testing.T) {
+	sender := &captureSender{}
+	svc := NewMailerService(sender, nil, nil, nil, "", zap.NewNop())
+	err := svc.CampaignMessage(context.Background(), uuid.New(), "asha@example.com", "Asha", "", "Monsoon offers",
+		"Vitamins are 10% off.\n\nShow this <email> at the counter.")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected 1 email, got %d", len(sender.sent))
+	}
+	msg := sender.sent[0]
+	if msg.Subject != "Monsoon offers" || !strings.Contains(msg.TextBody, "Hi Asha,") {
+		t.Errorf("unexpected email: %q\n%s", msg.Subject, msg.TextBody)
+	}
+	if strings.Count(msg.HTMLBody, "<p>") < 3 || strings.Contains(msg.HTMLBody, "<email>") {
+		t.Errorf("html body should have one paragraph each and escape the message:\n%s", msg.HTMLBody)
+	}
+}
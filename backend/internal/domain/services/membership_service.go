@@ -27,6 +27,9 @@ func (s *membershipService) Create(ctx context.Context, m *models.Membership) er
 	if m.DiscountPercent < 0 || m.DiscountPercent > 100 {
 		return errors.ErrValidation("discount percent must be between 0 and 100")
 	}
+	if err := validateMembershipBenefits(m.Benefits); err != nil {
+		return err
+	}
 	return s.repo.Create(ctx, m)
 }
 
@@ -48,9 +51,28 @@ func (s *membershipService) Update(ctx context.Context, m *models.Membership) er
 	if m.DiscountPercent < 0 || m.DiscountPercent > 100 {
 		return errors.ErrValidation("discount percent must be between 0 and 100")
 	}
+	if err := validateMembershipBenefits(m.Benefits); err != nil {
+		return err
+	}
 	return s.repo.Update(ctx, m)
 }
 
 func (s *membershipService) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)
 }
+
+func validateMembershipBenefits(b models.MembershipBenefits) error {
+	if b.FlashSaleEarlyAccessMinutes < 0 || b.FlashSaleEarlyAccessMinutes > 7*24*60 {
+		return errors.ErrValidation("flash sale early access must be between 0 and 10080 minutes")
+	}
+	if b.PointsMultiplier != 0 && (b.PointsMultiplier < 1 || b.PointsMultiplier > 10) {
+		return errors.ErrValidation("points multiplier must be between 1 and 10")
+	}
+	if b.BirthdayBonusPoints < 0 {
+		return errors.ErrValidation("birthday bonus points must not be negative")
+	}
+	if b.BirthdayDiscountPercent < 0 || b.BirthdayDiscountPercent > 100 {
+		return errors.ErrValidation("birthday discount percent must be between 0 and 100")
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	reportNoteMaxChars    = 1000
+	reportExcerptMaxChars = 1000
+	// moderatedChatMessage replaces the body of a hidden chat message, which has no soft delete.
+	moderatedChatMessage     = "This message was removed by a moderator."
+	defaultModerationWarning = "Content you posted was reported and reviewed by the pharmacy. Please follow the community " +
+		"guidelines; repeated violations can get your account deactivated."
+)
+
+// moderationVerbs describes each action in the activity log.
+var moderationVerbs = map[string]string{
+	models.ModerationDismiss:        "Dismissed reports on",
+	models.ModerationHideContent:    "Hid",
+	models.ModerationWarnUser:       "Warned the author of",
+	models.ModerationDeactivateUser: "Deactivated the author of",
+}
+
+type moderationService struct {
+	repo                outbound.ContentReportRepository
+	reviewRepo          outbound.ProductReviewRepository
+	reviewCommentRepo   outbound.ReviewCommentRepository
+	blogPostRepo        outbound.BlogPostRepository
+	blogCommentRepo     outbound.BlogPostCommentRepository
+	productRepo         outbound.ProductRepository
+	convRepo            outbound.ConversationRepository
+	msgRepo             outbound.ChatMessageRepository
+	userRepo            outbound.UserRepository
+	notificationService inbound.NotificationService
+	activitySvc         inbound.ActivityLogService
+	logger              *zap.Logger
+	now                 func() time.Time
+}
+
+func NewModerationService(
+	repo outbound.ContentReportRepository,
+	reviewRepo outbound.ProductReviewRepository,
+	reviewCommentRepo outbound.ReviewCommentRepository,
+	blogPostRepo outbound.BlogPostRepository,
+	blogCommentRepo outbound.BlogPostCommentRepository,
+	productRepo outbound.ProductRepository,
+	convRepo outbound.ConversationRepository,
+	msgRepo outbound.ChatMessageRepository,
+	userRepo outbound.UserRepository,
+	notificationService inbound.NotificationService,
+	activitySvc inbound.ActivityLogService,
+	logger *zap.Logger,
+) inbound.ModerationService {
+	return &moderationService{
+		repo: repo, reviewRepo: reviewRepo, reviewCommentRepo: reviewCommentRepo, blogPostRepo: blogPostRepo,
+		blogCommentRepo: blogCommentRepo, productRepo: productRepo, convRepo: convRepo, msgRepo: msgRepo,
+		userRepo: userRepo, notificationService: notificationService, activitySvc: activitySvc, logger: logger, now: time.Now,
+	}
+}
+
+// reportedContent is what a report points at.
+type reportedContent struct {
+	pharmacyID uuid.UUID
+	authorID   *uuid.UUID
+	excerpt    string
+	// participant is the user a chat conversation belongs to; nil for other content.
+	participant *uuid.UUID
+	hide        func(ctx context.Context) error
+}
+
+// content loads the reported content; NotFound when it does not exist or was already removed.
+func (s *moderationService) content(ctx context.Context, targetType string, id uuid.UUID) (*reportedContent, error) {
+	switch targetType {
+	case models.ReportTargetReview:
+		r, err := s.reviewRepo.GetByID(ctx, id)
+		if err != nil || r == nil {
+			return nil, errors.ErrNotFound("review")
+		}
+		return s.reviewContent(ctx, r, strings.TrimSpace(r.Title+"\n"+r.Body), func(ctx context.Context) error {
+			return s.reviewRepo.Delete(ctx, r.ID)
+		})
+	case models.ReportTargetReviewComment:
+		c, err := s.reviewCommentRepo.GetByID(ctx, id)
+		if err != nil || c == nil {
+			return nil, errors.ErrNotFound("comment")
+		}
+		r, err := s.reviewRepo.GetByID(ctx, c.ReviewID)
+		if err != nil || r == nil {
+			return nil, errors.ErrNotFound("comment")
+		}
+		out, err := s.reviewContent(ctx, r, c.Body, func(ctx context.Context) error {
+			return s.reviewCommentRepo.Delete(ctx, c.ID)
+		})
+		if err != nil {
+			return nil, err
+		}
+		out.authorID = &c.UserID
+		return out, nil
+	case models.ReportTargetBlogComment:
+		c, err := s.blogCommentRepo.GetByID(ctx, id)
+		if err != nil || c == nil {
+			return nil, errors.ErrNotFound("comment")
+		}
+		p, err := s.blogPostRepo.GetByID(ctx, c.PostID)
+		if err != nil || p == nil {
+			return nil, errors.ErrNotFound("comment")
+		}
+		return &reportedContent{pharmacyID: p.PharmacyID, authorID: &c.UserID, excerpt: c.Body, hide: func(ctx context.Context) error {
+			return s.blogCommentRepo.Delete(ctx, c.ID)
+		}}, nil
+	case models.ReportTargetChatMessage:
+		m, err := s.msgRepo.GetByID(ctx, id)
+		if err != nil || m == nil {
+			return nil, errors.ErrNotFound("message")
+		}
+		conv, err := s.convRepo.GetByID(ctx, m.ConversationID)
+		if err != nil || conv == nil {
+			return nil, errors.ErrNotFound("message")
+		}
+		out := &reportedContent{pharmacyID: conv.PharmacyID, excerpt: m.Body, participant: conv.UserID, hide: func(ctx context.Context) error {
+			m.Body, m.AttachmentURL, m.AttachmentName, m.AttachmentType = moderatedChatMessage, "", "", ""
+			return s.msgRepo.Update(ctx, m)
+		}}
+		if out.excerpt == "" {
+			out.excerpt = m.AttachmentName
+		}
+		if m.SenderType == models.SenderTypeUser {
+			out.authorID = &m.SenderID
+		}
+		return out, nil
+	}
+	return nil, errors.ErrValidation("target_type must be one of review, review_comment, blog_comment, chat_message")
+}
+
+// reviewContent is a review or one of its comments, which belong to the pharmacy selling the product.
+func (s *moderationService) reviewContent(ctx context.Context, r *models.ProductReview, excerpt string, hide func(ctx context.Context) error) (*reportedContent, error) {
+	p, err := s.productRepo.GetByID(ctx, r.ProductID)
+	if err != nil || p == nil {
+		return nil, errors.ErrNotFound("review")
+	}
+	return &reportedContent{pharmacyID: p.PharmacyID, authorID: &r.UserID, excerpt: excerpt, hide: hide}, nil
+}
+
+func (s *moderationService) Report(ctx context.Context, pharmacyID, reporterID uuid.UUID, role string, in inbound.ContentReportInput) (*models.ContentReport, error) {
+	if _, ok := models.ReportReasons[in.Reason]; !ok {
+		return nil, errors.ErrValidation("reason must be one of spam, harassment, misinformation, offensive, privacy, other")
+	}
+	note := strings.TrimSpace(in.Note)
+	if len([]rune(note)) > reportNoteMaxChars {
+		return nil, errors.ErrValidation(fmt.Sprintf("note must be at most %d characters", reportNoteMaxChars))
+	}
+	if in.Reason == "other" && note == "" {
+		return nil, errors.ErrValidation("note is required when the reason is other")
+	}
+	c, err := s.content(ctx, in.TargetType, in.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if c.pharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound(strings.ReplaceAll(in.TargetType, "_", " "))
+	}
+	// Buyers only see chat conversations of their own.
+	if in.TargetType == models.ReportTargetChatMessage && role == RoleStaff && (c.participant == nil || *c.participant != reporterID) {
+		return nil, errors.ErrNotFound("message")
+	}
+	if c.authorID != nil && *c.authorID == reporterID {
+		return nil, errors.ErrValidation("you cannot report your own content")
+	}
+	exists, err := s.repo.ExistsByReporter(ctx, reporterID, in.TargetType, in.TargetID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to check reports", err)
+	}
+	if exists {
+		return nil, errors.ErrConflict("you already reported this")
+	}
+	excerpt := []rune(c.excerpt)
+	if len(excerpt) > reportExcerptMaxChars {
+		excerpt = excerpt[:reportExcerptMaxChars]
+	}
+	r := &models.ContentReport{
+		PharmacyID: pharmacyID,
+		TargetType: in.TargetType,
+		TargetID:   in.TargetID,
+		ReporterID: reporterID,
+		AuthorID:   c.authorID,
+		Reason:     in.Reason,
+		Note:       note,
+		Excerpt:    string(excerpt),
+		Status:     models.ContentReportOpen,
+	}
+	if err := s.repo.Create(ctx, r); err != nil {
+		return nil, errors.ErrInternal("failed to create report", err)
+	}
+	return r, nil
+}
+
+func (s *moderationService) Queue(ctx context.Context, pharmacyID uuid.UUID, q inbound.ModerationQuery) ([]*models.ContentReport, int64, error) {
+	filter := outbound.ContentReportFilter{Status: q.Status, TargetType: q.TargetType, AuthorID: q.AuthorID}
+	switch filter.Status {
+	case "":
+		filter.Status = string(models.ContentReportOpen)
+	case "all":
+		filter.Status = ""
+	case string(models.ContentReportOpen), string(models.ContentReportResolved):
+	default:
+		return nil, 0, errors.ErrValidation("status must be open, resolved or all")
+	}
+	switch filter.TargetType {
+	case "", models.ReportTargetReview, models.ReportTargetReviewComment, models.ReportTargetBlogComment, models.ReportTargetChatMessage:
+	default:
+		return nil, 0, errors.ErrValidation("target_type must be one of review, review_comment, blog_comment, chat_message")
+	}
+	limit := q.Limit
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	list, total, err := s.repo.ListByPharmacy(ctx, pharmacyID, filter, limit, q.Offset)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to list reports", err)
+	}
+	return list, total, nil
+}
+
+func (s *moderationService) Act(ctx context.Context, pharmacyID, reportID, moderatorID uuid.UUID, in inbound.ModerationActionInput, canDeactivate bool, ipAddress string) (*models.ContentReport, error) {
+	verb, ok := moderationVerbs[in.Action]
+	if !ok {
+		return nil, errors.ErrValidation("action must be one of dismiss, hide_content, warn_user, deactivate_user")
+	}
+	r, err := s.repo.GetByID(ctx, reportID)
+	if err != nil || r == nil || r.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("report")
+	}
+	if r.Status != models.ContentReportOpen {
+		return nil, errors.ErrConflict("report is already resolved")
+	}
+	hide := in.Action == models.ModerationHideContent ||
+		(in.HideContent && (in.Action == models.ModerationWarnUser || in.Action == models.ModerationDeactivateUser))
+	note := strings.TrimSpace(in.Note)
+
+	switch in.Action {
+	case models.ModerationWarnUser:
+		if r.AuthorID == nil {
+			return nil, errors.ErrValidation("the content was not posted from a user account")
+		}
+		message := strings.TrimSpace(in.Message)
+		if message == "" {
+			message = defaultModerationWarning
+		}
+		if _, err := s.notificationService.Create(ctx, pharmacyID, *r.AuthorID, "Community guidelines warning", message, "moderation"); err != nil {
+			return nil, errors.ErrInternal("failed to warn user", err)
+		}
+	case models.ModerationDeactivateUser:
+		if !canDeactivate {
+			return nil, errors.ErrForbidden("missing permission " + models.PermUsersManage)
+		}
+		if r.AuthorID == nil {
+			return nil, errors.ErrValidation("the content was not posted from a user account")
+		}
+		u, err := s.userRepo.GetByID(ctx, *r.AuthorID)
+		if err != nil || u == nil || u.PharmacyID != pharmacyID {
+			return nil, errors.ErrNotFound("user")
+		}
+		if u.Role != RoleStaff {
+			return nil, errors.ErrForbidden("team members are deactivated from team management, not the moderation queue")
+		}
+		u.IsActive = false
+		if err := s.userRepo.Update(ctx, u); err != nil {
+			return nil, errors.ErrInternal("failed to deactivate user", err)
+		}
+	}
+	if hide {
+		// Content that no longer exists is already hidden.
+		if c, err := s.content(ctx, r.TargetType, r.TargetID); err == nil {
+			if err := c.hide(ctx); err != nil {
+				return nil, errors.ErrInternal("failed to hide content", err)
+			}
+		}
+	}
+
+	now := s.now()
+	n, err := s.repo.ResolveTarget(ctx, pharmacyID, r.TargetType, r.TargetID, in.Action, note, moderatorID, now)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to resolve reports", err)
+	}
+	details, _ := json.Marshal(map[string]interface{}{
+		"target_type":    r.TargetType,
+		"target_id":      r.TargetID,
+		"author_id":      r.AuthorID,
+		"action":         in.Action,
+		"content_hidden": hide,
+		"reports":        n,
+		"note":           note,
+	})
+	desc := fmt.Sprintf("%s reported %s (%d report(s))", verb, strings.ReplaceAll(r.TargetType, "_", " "), n)
+	if err := s.activitySvc.Create(ctx, pharmacyID, moderatorID, "MODERATE "+in.Action, desc, "content_report", r.ID.String(), string(details), ipAddress); err != nil {
+		s.logger.Warn("moderation action not logged", zap.String("report_id", r.ID.String()), zap.Error(err))
+	}
+
+	if updated, err := s.repo.GetByID(ctx, r.ID); err == nil && updated != nil {
+		return updated, nil
+	}
+	r.Status, r.Action, r.ResolutionNote, r.ResolvedBy, r.ResolvedAt = models.ContentReportResolved, in.Action, note, &moderatorID, &now
+	return r, nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestModerationService_ReportAndDeactivateChatAuthor(t *testing.T) {
+	pharmacyID, moderatorID, otherBuyer := uuid.New(), uuid.New(), uuid.New()
+	author := &models.User{ID: uuid.New(), PharmacyID: pharmacyID, Role: models.RoleStaff, IsActive: true}
+	conv := &models.Conversation{ID: uuid.New(), PharmacyID: pharmacyID, UserID: &author.ID}
+	msg := &models.ChatMessage{ID: uuid.New(), ConversationID: conv.ID, SenderType: models.SenderTypeUser, SenderID: author.ID, Body: "buy cheap pills at example.com", AttachmentURL: "https://example.com/x.png"}
+
+	var saved []*models.ContentReport
+	var resolved string
+	var logged []*models.ActivityLog
+	svc := &moderationService{
+		repo: &mocks.MockContentReportRepository{
+			CreateFunc: func(ctx context.Context, r *models.ContentReport) error {
+				r.ID = uuid.New()
+				saved = append(saved, r)
+				return nil
+			},
+			ExistsByReporterFunc: func(ctx context.Context, reporterID uuid.UUID, targetType string, targetID uuid.UUID) (bool, error) {
+				for _, r := range saved {
+					if r.ReporterID == reporterID && r.TargetType == targetType && r.TargetID == targetID {
+						return true, nil
+					}
+				}
+				return false, nil
+			},
+			GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.ContentReport, error) {
+				for _, r := range saved {
+					if r.ID == id {
+						return r, nil
+					}
+				}
+				return nil, nil
+			},
+			ResolveTargetFunc: func(ctx context.Context, pID uuid.UUID, targetType string, targetID uuid.UUID, action, note string, resolvedBy uuid.UUID, at time.Time) (int64, error) {
+				resolved = action
+				for _, r := range saved {
+					r.Status, r.Action = models.ContentReportResolved, action
+				}
+				return int64(len(saved)), nil
+			},
+		},
+		convRepo: &mocks.MockConversationRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Conversation, error) { return conv, nil }},
+		msgRepo: &mocks.MockChatMessageRepository{
+			GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.ChatMessage, error) { return msg, nil },
+		},
+		userRepo: &mocks.MockUserRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.User, error) { return author, nil }},
+		activitySvc: NewActivityLogService(&mocks.MockActivityLogRepository{CreateFunc: func(ctx context.Context, a *models.ActivityLog) error {
+			logged = append(logged, a)
+			return nil
+		}}, nil, nil, zap.NewNop()),
+		logger: zap.NewNop(),
+		now:    time.Now,
+	}
+	ctx := context.Background()
+	in := inbound.ContentReportInput{TargetType: models.ReportTargetChatMessage, TargetID: msg.ID, Reason: "spam"}
+
+	if _, err := svc.Report(ctx, pharmacyID, otherBuyer, models.RoleStaff, in); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeNotFound {
+		t.Errorf("buyer outside the conversation: err = %v, want not found", err)
+	}
+	if _, err := svc.Report(ctx, pharmacyID, author.ID, models.RoleStaff, in); err == nil {
+		t.Error("reporting your own message should fail")
+	}
+	r, err := svc.Report(ctx, pharmacyID, moderatorID, models.RolePharmacist, in)
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if r.AuthorID == nil || *r.AuthorID != author.ID || r.Excerpt != msg.Body || r.Status != models.ContentReportOpen {
+		t.Errorf("report = %+v, want the message author and text", r)
+	}
+	if _, err := svc.Report(ctx, pharmacyID, moderatorID, models.RolePharmacist, in); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeConflict {
+		t.Errorf("second report: err = %v, want conflict", err)
+	}
+
+	action := inbound.ModerationActionInput{Action: models.ModerationDeactivateUser, HideContent: true}
+	if _, err := svc.Act(ctx, pharmacyID, r.ID, moderatorID, action, false, ""); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeForbidden {
+		t.Errorf("deactivate without users.manage: err = %v, want forbidden", err)
+	}
+	got, err := svc.Act(ctx, pharmacyID, r.ID, moderatorID, action, true, "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Act: %v", err)
+	}
+	if author.IsActive {
+		t.Error("author still active")
+	}
+	if msg.Body != moderatedChatMessage || msg.AttachmentURL != "" {
+		t.Errorf("message not hidden: %+v", msg)
+	}
+	if resolved != models.ModerationDeactivateUser || got.Status != models.ContentReportResolved {
+		t.Errorf("report status = %s, action = %s", got.Status, resolved)
+	}
+	if len(logged) != 1 || logged[0].Action != "MODERATE deactivate_user" || logged[0].UserID != moderatorID || logged[0].EntityID != r.ID.String() {
+		t.Errorf("activity log = %+v", logged)
+	}
+	if _, err := svc.Act(ctx, pharmacyID, r.ID, moderatorID, inbound.ModerationActionInput{Action: models.ModerationDismiss}, true, ""); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeConflict {
+		t.Errorf("acting on a resolved report: err = %v, want conflict", err)
+	}
+}
@@ -10,33 +10,83 @@ import (
 	"go.uber.org/zap"
 )
 
+// Realtime events sent to the recipient's open WebSocket connections.
+const (
+	EventNewNotification = "new_notification"
+	EventUnreadCount     = "unread_count"
+)
+
 type notificationService struct {
-	repo   outbound.NotificationRepository
-	logger *zap.Logger
+	repo       outbound.NotificationRepository
+	pusher     inbound.PushNotificationService
+	realtime   outbound.RealtimeNotifier
+	userRepo   outbound.UserRepository
+	configRepo outbound.PharmacyConfigRepository
+	consent    inbound.ConsentService
+	logger     *zap.Logger
 }
 
-func NewNotificationService(repo outbound.NotificationRepository, logger *zap.Logger) inbound.NotificationService {
-	return &notificationService{repo: repo, logger: logger}
+// NewNotificationService stores in-app notifications. When pusher is set, each one is also pushed to the
+// recipient's registered devices; when realtime is set, it is delivered to their open WebSocket connections
+// together with the new unread count, and read changes resend the count so other tabs stay in sync.
+// userRepo and configRepo pick the language of CreateLocalized notifications; either may be nil. Marketing
+// notifications (models.MarketingNotificationTypes) are only pushed when consent shows the recipient opted in to
+// push; they still appear in the in-app inbox. A nil consent pushes them without checking.
+func NewNotificationService(repo outbound.NotificationRepository, pusher inbound.PushNotificationService, realtime outbound.RealtimeNotifier, userRepo outbound.UserRepository, configRepo outbound.PharmacyConfigRepository, consent inbound.ConsentService, logger *zap.Logger) inbound.NotificationService {
+	return &notificationService{repo: repo, pusher: pusher, realtime: realtime, userRepo: userRepo, configRepo: configRepo, consent: consent, logger: logger}
 }
 
 func (s *notificationService) Create(ctx context.Context, pharmacyID, userID uuid.UUID, title, message, notifType string) (*models.Notification, error) {
-	if notifType == "" {
-		notifType = "info"
+	return s.create(ctx, &models.Notification{PharmacyID: pharmacyID, UserID: userID, Title: title, Message: message, Type: notifType})
+}
+
+func (s *notificationService) CreateLocalized(ctx context.Context, pharmacyID, userID uuid.UUID, title, message string, translations models.Translations, notifType string) (*models.Notification, error) {
+	n := &models.Notification{PharmacyID: pharmacyID, UserID: userID, Title: title, Message: message, Type: notifType}
+	if len(translations) > 0 {
+		var preferred string
+		if s.userRepo != nil {
+			if u, err := s.userRepo.GetByID(ctx, userID); err == nil && u != nil {
+				preferred = u.PreferredLanguage
+			}
+		}
+		lang := models.ResolveLanguage(preferred, pharmacyLanguage(ctx, s.configRepo, pharmacyID))
+		if v, picked, ok := translations.Pick(lang); ok {
+			n.Title, n.Message, n.Language = v.Title, v.Body, picked
+		}
 	}
-	n := &models.Notification{
-		PharmacyID: pharmacyID,
-		UserID:     userID,
-		Title:      title,
-		Message:    message,
-		Type:       notifType,
+	return s.create(ctx, n)
+}
+
+func (s *notificationService) create(ctx context.Context, n *models.Notification) (*models.Notification, error) {
+	if n.Type == "" {
+		n.Type = "info"
 	}
 	if err := s.repo.Create(ctx, n); err != nil {
 		s.logger.Warn("notification create failed", zap.Error(err))
 		return nil, err
 	}
+	if s.pusher != nil && s.mayPush(ctx, n) {
+		data := map[string]string{"type": n.Type, "notification_id": n.ID.String()}
+		_ = s.pusher.NotifyUser(ctx, n.UserID, n.Title, n.Message, "/notifications", data)
+	}
+	if s.realtime != nil {
+		event := map[string]interface{}{"notification": n}
+		if count, err := s.repo.CountUnreadByUser(ctx, n.UserID); err == nil {
+			event["unread_count"] = count
+		}
+		s.realtime.SendToUser(n.UserID, EventNewNotification, event)
+	}
 	return n, nil
 }
 
+// mayPush reports whether n may go to the recipient's devices: marketing needs their push consent.
+func (s *notificationService) mayPush(ctx context.Context, n *models.Notification) bool {
+	if !models.MarketingNotificationTypes[n.Type] || s.consent == nil {
+		return true
+	}
+	return s.consent.UserOptedIn(ctx, n.PharmacyID, n.UserID, models.ConsentChannelPush)
+}
+
 func (s *notificationService) ListByUser(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]*models.Notification, error) {
 	return s.repo.ListByUser(ctx, userID, unreadOnly, limit, offset)
 }
@@ -46,9 +96,34 @@ func (s *notificationService) CountUnreadByUser(ctx context.Context, userID uuid
 }
 
 func (s *notificationService) MarkRead(ctx context.Context, id, userID uuid.UUID) error {
-	return s.repo.MarkRead(ctx, id, userID)
+	if err := s.repo.MarkRead(ctx, id, userID); err != nil {
+		return err
+	}
+	s.sendUnreadCount(ctx, userID)
+	return nil
 }
 
 func (s *notificationService) MarkAllRead(ctx context.Context, userID uuid.UUID) error {
-	return s.repo.MarkAllRead(ctx, userID)
+	if err := s.repo.MarkAllRead(ctx, userID); err != nil {
+		return err
+	}
+	s.sendUnreadCount(ctx, userID)
+	return nil
+}
+
+// SendUnreadCount sends the user's unread count to their open connections (e.g. when a socket connects).
+func (s *notificationService) SendUnreadCount(ctx context.Context, userID uuid.UUID) {
+	s.sendUnreadCount(ctx, userID)
+}
+
+func (s *notificationService) sendUnreadCount(ctx context.Context, userID uuid.UUID) {
+	if s.realtime == nil {
+		return
+	}
+	count, err := s.repo.CountUnreadByUser(ctx, userID)
+	if err != nil {
+		s.logger.Warn("notification unread count failed", zap.Error(err))
+		return
+	}
+	s.realtime.SendToUser(userID, EventUnreadCount, map[string]int64{"count": count})
 }
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type realtimeEvent struct {
+	userID uuid.UUID
+	event  string
+	data   interface{}
+}
+
+// recordingRealtime captures events sent to users' WebSocket connections.
+type recordingRealtime struct {
+	events []realtimeEvent
+}
+
+func (r *recordingRealtime) SendToUser(userID uuid.UUID, event string, data interface{}) {
+	r.events = append(r.events, realtimeEvent{userID: userID, event: event, data: data})
+}
+
+func TestNotificationService_Create_SendsRealtimeEvent(t *testing.T) {
+	userID := uuid.New()
+	repo := &mocks.MockNotificationRepository{
+		CountUnreadByUserFunc: func(ctx context.Context, id uuid.UUID) (int64, error) { return 3, nil },
+	}
+	rt := &recordingRealtime{}
+	svc := NewNotificationService(repo, nil, rt, nil, nil, nil, zap.NewNop())
+
+	n, err := svc.Create(context.Background(), uuid.New(), userID, "Order ready", "ORD-1 is ready", "")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(rt.events) != 1 || rt.events[0].userID != userID || rt.events[0].event != EventNewNotification {
+		t.Fatalf("expected one new_notification event to the recipient, got %+v", rt.events)
+	}
+	data := rt.events[0].data.(map[string]interface{})
+	if data["notification"].(*models.Notification) != n || data["unread_count"] != int64(3) {
+		t.Errorf("unexpected event data: %+v", data)
+	}
+}
+
+func TestNotificationService_MarkAllRead_SendsUnreadCount(t *testing.T) {
+	userID := uuid.New()
+	rt := &recordingRealtime{}
+	svc := NewNotificationService(&mocks.MockNotificationRepository{}, nil, rt, nil, nil, nil, zap.NewNop())
+
+	if err := svc.MarkAllRead(context.Background(), userID); err != nil {
+		t.Fatalf("MarkAllRead: %v", err)
+	}
+	if len(rt.events) != 1 || rt.events[0].event != EventUnreadCount {
+		t.Fatalf("expected an unread_count event, got %+v", rt.events)
+	}
+	if got := rt.events[0].data.(map[string]int64)["count"]; got != 0 {
+		t.Errorf("expected count 0, got %d", got)
+	}
+}
+
+func TestNotificationService_CreateLocalized_PicksRecipientLanguage(t *testing.T) {
+	pharmacyID := uuid.New()
+	prefs := map[uuid.UUID]string{}
+	users := &mocks.MockUserRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.User, error) {
+			return &models.User{ID: id, PreferredLanguage: prefs[id]}, nil
+		},
+	}
+	configs := &mocks.MockPharmacyConfigRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, id uuid.UUID) (*models.PharmacyConfig, error) {
+			return &models.PharmacyConfig{PharmacyID: id, DefaultLanguage: "ne"}, nil
+		},
+	}
+	svc := NewNotificationService(&mocks.MockNotificationRepository{}, nil, nil, users, configs, nil, zap.NewNop())
+	translations := models.Translations{"ne": {Title: "अर्डर तयार", Body: "ORD-1 तयार छ"}}
+
+	english := uuid.New()
+	prefs[english] = "en"
+	n, err := svc.CreateLocalized(context.Background(), pharmacyID, english, "Order ready", "ORD-1 is ready", translations, "order")
+	if err != nil {
+		t.Fatalf("CreateLocalized: %v", err)
+	}
+	if n.Title != "Order ready" || n.Language != "" {
+		t.Errorf("expected base text for an English reader, got %q (%s)", n.Title, n.Language)
+	}
+
+	// No preference: falls back to the pharmacy default.
+	n, err = svc.CreateLocalized(context.Background(), pharmacyID, uuid.New(), "Order ready", "ORD-1 is ready", translations, "order")
+	if err != nil {
+		t.Fatalf("CreateLocalized: %v", err)
+	}
+	if n.Title != "अर्डर तयार" || n.Message != "ORD-1 तयार छ" || n.Language != "ne" {
+		t.Errorf("expected the Nepali variant, got %q / %q (%s)", n.Title, n.Message, n.Language)
+	}
+}
@@ -2,21 +2,29 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 	"github.com/careplus/pharmacy-backend/pkg/errors"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 type orderFeedbackService struct {
-	orderRepo   outbound.OrderRepository
-	feedbackRepo outbound.OrderFeedbackRepository
+	orderRepo           outbound.OrderRepository
+	feedbackRepo        outbound.OrderFeedbackRepository
+	userRepo            outbound.UserRepository
+	notificationService inbound.NotificationService
+	logger              *zap.Logger
 }
 
-func NewOrderFeedbackService(orderRepo outbound.OrderRepository, feedbackRepo outbound.OrderFeedbackRepository) inbound.OrderFeedbackService {
-	return &orderFeedbackService{orderRepo: orderRepo, feedbackRepo: feedbackRepo}
+func NewOrderFeedbackService(orderRepo outbound.OrderRepository, feedbackRepo outbound.OrderFeedbackRepository, userRepo outbound.UserRepository, notificationService inbound.NotificationService, logger *zap.Logger) inbound.OrderFeedbackService {
+	return &orderFeedbackService{orderRepo: orderRepo, feedbackRepo: feedbackRepo, userRepo: userRepo, notificationService: notificationService, logger: logger}
 }
 
 func (s *orderFeedbackService) Create(ctx context.Context, orderID, userID uuid.UUID, rating int, comment string) (*models.OrderFeedback, error) {
@@ -38,17 +46,153 @@ func (s *orderFeedbackService) Create(ctx context.Context, orderID, userID uuid.
 		return nil, errors.ErrConflict("you have already submitted feedback for this order")
 	}
 	f := &models.OrderFeedback{
-		OrderID: orderID,
-		UserID:  userID,
-		Rating:  rating,
-		Comment: comment,
+		OrderID:        orderID,
+		UserID:         userID,
+		Rating:         rating,
+		Comment:        comment,
+		FollowUpStatus: models.FeedbackFollowUpNone,
+	}
+	if rating <= models.LowFeedbackRating {
+		f.FollowUpStatus = models.FeedbackFollowUpPending
 	}
 	if err := s.feedbackRepo.Create(ctx, f); err != nil {
 		return nil, err
 	}
+	if f.FollowUpStatus == models.FeedbackFollowUpPending {
+		s.alertManagers(ctx, order, f)
+	}
 	return f, nil
 }
 
 func (s *orderFeedbackService) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.OrderFeedback, error) {
 	return s.feedbackRepo.GetByOrderID(ctx, orderID)
 }
+
+func (s *orderFeedbackService) List(ctx context.Context, pharmacyID uuid.UUID, q inbound.FeedbackListQuery) ([]*models.OrderFeedback, int64, error) {
+	if q.FollowUpStatus != "" && !models.IsFeedbackFollowUpStatus(q.FollowUpStatus) {
+		return nil, 0, errors.ErrValidation("unknown follow_up_status")
+	}
+	if q.Limit <= 0 || q.Limit > 100 {
+		q.Limit = 20
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	return s.feedbackRepo.ListByPharmacy(ctx, pharmacyID, outbound.FeedbackFilter{FollowUpStatus: q.FollowUpStatus, MaxRating: q.MaxRating}, q.Limit, q.Offset)
+}
+
+func (s *orderFeedbackService) Summary(ctx context.Context, pharmacyID uuid.UUID, granularity string, from, to time.Time) (*inbound.FeedbackSummary, error) {
+	from, to, err := normalizeRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	switch granularity {
+	case "day", "week", "month":
+	default:
+		granularity = "day"
+	}
+	trend, err := s.feedbackRepo.RatingTrend(ctx, pharmacyID, granularity, from, to)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load feedback trend", err)
+	}
+	byStaff, err := s.feedbackRepo.RatingByStaff(ctx, pharmacyID, from, to)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load feedback by staff", err)
+	}
+	byFulfilment, err := s.feedbackRepo.RatingByFulfilment(ctx, pharmacyID, from, to)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load feedback by fulfilment", err)
+	}
+	_, pending, err := s.feedbackRepo.ListByPharmacy(ctx, pharmacyID, outbound.FeedbackFilter{FollowUpStatus: models.FeedbackFollowUpPending}, 1, 0)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to count pending follow-ups", err)
+	}
+	if trend == nil {
+		trend = []*models.FeedbackTrendRow{}
+	}
+	if byStaff == nil {
+		byStaff = []*models.FeedbackGroupRow{}
+	}
+	if byFulfilment == nil {
+		byFulfilment = []*models.FeedbackGroupRow{}
+	}
+	summary := &inbound.FeedbackSummary{
+		From:             from,
+		To:               to,
+		Granularity:      granularity,
+		PendingFollowUps: pending,
+		Trend:            trend,
+		ByStaff:          byStaff,
+		ByFulfilment:     byFulfilment,
+	}
+	var ratingSum float64
+	for _, row := range trend {
+		summary.Count += row.Count
+		ratingSum += row.AverageRating * float64(row.Count)
+		row.AverageRating = roundRating(row.AverageRating)
+	}
+	if summary.Count > 0 {
+		summary.AverageRating = roundRating(ratingSum / float64(summary.Count))
+	}
+	for _, row := range byFulfilment {
+		summary.LowCount += row.LowCount
+		row.AverageRating = roundRating(row.AverageRating)
+	}
+	for _, row := range byStaff {
+		row.AverageRating = roundRating(row.AverageRating)
+	}
+	return summary, nil
+}
+
+func (s *orderFeedbackService) UpdateFollowUp(ctx context.Context, pharmacyID, feedbackID, actorID uuid.UUID, status, note string) (*models.OrderFeedback, error) {
+	if !models.IsFeedbackFollowUpStatus(status) || status == models.FeedbackFollowUpNone {
+		return nil, errors.ErrValidation("status must be pending, contacted or resolved")
+	}
+	f, err := s.feedbackRepo.GetByID(ctx, feedbackID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load feedback", err)
+	}
+	if f == nil || f.Order == nil || f.Order.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("feedback")
+	}
+	now := time.Now()
+	f.FollowUpStatus = status
+	if note = strings.TrimSpace(note); note != "" {
+		f.FollowUpNote = note
+	}
+	f.FollowUpBy = &actorID
+	f.FollowUpAt = &now
+	if err := s.feedbackRepo.Update(ctx, f); err != nil {
+		return nil, errors.ErrInternal("failed to update feedback", err)
+	}
+	return f, nil
+}
+
+// alertManagers notifies active admins and managers of the order's pharmacy about a low rating.
+func (s *orderFeedbackService) alertManagers(ctx context.Context, order *models.Order, f *models.OrderFeedback) {
+	if s.notificationService == nil || s.userRepo == nil {
+		return
+	}
+	users, err := s.userRepo.GetByPharmacyID(ctx, order.PharmacyID)
+	if err != nil {
+		s.logger.Warn("failed to load managers for feedback alert", zap.String("order_id", order.ID.String()), zap.Error(err))
+		return
+	}
+	title := fmt.Sprintf("%d-star feedback on order %s", f.Rating, order.OrderNumber)
+	message := "A customer left a low rating. Contact them and record the follow-up."
+	if c := strings.TrimSpace(f.Comment); c != "" {
+		message = fmt.Sprintf("%q. Contact the customer and record the follow-up.", c)
+	}
+	for _, u := range users {
+		if !u.IsActive || (u.Role != models.RoleAdmin && u.Role != models.RoleManager) {
+			continue
+		}
+		if _, err := s.notificationService.Create(ctx, order.PharmacyID, u.ID, title, message, "feedback"); err != nil {
+			s.logger.Warn("failed to send feedback alert", zap.String("user_id", u.ID.String()), zap.Error(err))
+		}
+	}
+}
+
+func roundRating(v float64) float64 {
+	return math.Round(v*100) / 100
+}
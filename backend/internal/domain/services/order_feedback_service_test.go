@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type feedbackFixture struct {
+	order     *models.Order
+	managerID uuid.UUID
+	saved     *models.OrderFeedback
+	notifier  *recordingNotifier
+	repo      *mocks.MockOrderFeedbackRepository
+	svc       inbound.OrderFeedbackService
+}
+
+func newFeedbackFixture() *feedbackFixture {
+	f := &feedbackFixture{managerID: uuid.New(), notifier: &recordingNotifier{}}
+	f.order = &models.Order{ID: uuid.New(), PharmacyID: uuid.New(), OrderNumber: "ORD-2001", Status: models.OrderStatusCompleted, CreatedBy: uuid.New()}
+	orders := &mocks.MockOrderRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Order, error) { return f.order, nil },
+	}
+	users := &mocks.MockUserRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, pharmacyID uuid.UUID) ([]*models.User, error) {
+			return []*models.User{
+				{ID: f.managerID, Role: models.RoleManager, IsActive: true},
+				{ID: uuid.New(), Role: models.RoleManager, IsActive: false},
+				{ID: uuid.New(), Role: models.RolePharmacist, IsActive: true},
+				{ID: f.order.CreatedBy, Role: models.RoleStaff, IsActive: true},
+			}, nil
+		},
+	}
+	f.repo = &mocks.MockOrderFeedbackRepository{
+		CreateFunc: func(ctx context.Context, fb *models.OrderFeedback) error {
+			fb.ID = uuid.New()
+			f.saved = fb
+			return nil
+		},
+	}
+	f.svc = NewOrderFeedbackService(orders, f.repo, users, f.notifier, zap.NewNop())
+	return f
+}
+
+func TestOrderFeedbackService_Create_LowRatingAlertsManagers(t *testing.T) {
+	f := newFeedbackFixture()
+	fb, err := f.svc.Create(context.Background(), f.order.ID, f.order.CreatedBy, 2, "Late and rude")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if fb.FollowUpStatus != models.FeedbackFollowUpPending {
+		t.Errorf("expected pending follow-up, got %q", fb.FollowUpStatus)
+	}
+	if len(f.notifier.sent) != 1 || f.notifier.sent[0].UserID != f.managerID || f.notifier.sent[0].Type != "feedback" {
+		t.Errorf("expected one feedback alert to the active manager, got %+v", f.notifier.sent)
+	}
+}
+
+func TestOrderFeedbackService_Create_GoodRatingNoAlert(t *testing.T) {
+	f := newFeedbackFixture()
+	fb, err := f.svc.Create(context.Background(), f.order.ID, f.order.CreatedBy, 4, "")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if fb.FollowUpStatus != models.FeedbackFollowUpNone {
+		t.Errorf("expected no follow-up, got %q", fb.FollowUpStatus)
+	}
+	if len(f.notifier.sent) != 0 {
+		t.Errorf("expected no alerts, got %d", len(f.notifier.sent))
+	}
+}
+
+func TestOrderFeedbackService_UpdateFollowUp(t *testing.T) {
+	f := newFeedbackFixture()
+	stored := &models.OrderFeedback{ID: uuid.New(), OrderID: f.order.ID, Order: f.order, Rating: 1, FollowUpStatus: models.FeedbackFollowUpPending}
+	f.repo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*models.OrderFeedback, error) { return stored, nil }
+	ctx := context.Background()
+	actor := uuid.New()
+
+	if _, err := f.svc.UpdateFollowUp(ctx, uuid.New(), stored.ID, actor, models.FeedbackFollowUpContacted, ""); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeNotFound {
+		t.Errorf("expected not found for another pharmacy, got %v", err)
+	}
+	if _, err := f.svc.UpdateFollowUp(ctx, f.order.PharmacyID, stored.ID, actor, "called", ""); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("expected validation error for unknown status, got %v", err)
+	}
+	fb, err := f.svc.UpdateFollowUp(ctx, f.order.PharmacyID, stored.ID, actor, models.FeedbackFollowUpContacted, " Called, offered refund ")
+	if err != nil {
+		t.Fatalf("UpdateFollowUp: %v", err)
+	}
+	if fb.FollowUpStatus != models.FeedbackFollowUpContacted || fb.FollowUpNote != "Called, offered refund" || fb.FollowUpBy == nil || *fb.FollowUpBy != actor || fb.FollowUpAt == nil {
+		t.Errorf("unexpected follow-up fields: %+v", fb)
+	}
+}
+
+func TestOrderFeedbackService_Summary_Aggregates(t *testing.T) {
+	f := newFeedbackFixture()
+	f.repo.RatingTrendFunc = func(ctx context.Context, pharmacyID uuid.UUID, granularity string, from, to time.Time) ([]*models.FeedbackTrendRow, error) {
+		return []*models.FeedbackTrendRow{{Count: 3, AverageRating: 5}, {Count: 1, AverageRating: 1}}, nil
+	}
+	f.repo.RatingByFulfilmentFunc = func(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.FeedbackGroupRow, error) {
+		return []*models.FeedbackGroupRow{{Key: "delivery", Count: 2, AverageRating: 3, LowCount: 1}, {Key: "pickup", Count: 2, AverageRating: 5}}, nil
+	}
+	f.repo.ListByPharmacyFunc = func(ctx context.Context, pharmacyID uuid.UUID, filter outbound.FeedbackFilter, limit, offset int) ([]*models.OrderFeedback, int64, error) {
+		if filter.FollowUpStatus != models.FeedbackFollowUpPending {
+			t.Errorf("expected pending filter, got %q", filter.FollowUpStatus)
+		}
+		return nil, 1, nil
+	}
+	s, err := f.svc.Summary(context.Background(), f.order.PharmacyID, "", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	if s.Count != 4 || s.AverageRating != 4 || s.LowCount != 1 || s.PendingFollowUps != 1 || s.Granularity != "day" {
+		t.Errorf("unexpected summary: %+v", s)
+	}
+}
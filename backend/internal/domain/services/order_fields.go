@@ -0,0 +1,81 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+)
+
+const maxOrderFields = 20
+
+// validateOrderFieldDefinitions checks a tenant's order field schema.
+func validateOrderFieldDefinitions(defs []models.OrderFieldDefinition) error {
+	if len(defs) > maxOrderFields {
+		return errors.ErrValidation(fmt.Sprintf("at most %d order fields are allowed", maxOrderFields))
+	}
+	seen := map[string]bool{}
+	for _, d := range defs {
+		if err := validateCustomFieldSpec("order field", d.Key, d.Label, d.Type, d.Options); err != nil {
+			return err
+		}
+		if seen[d.Key] {
+			return errors.ErrValidation("order field key " + d.Key + " is used twice")
+		}
+		seen[d.Key] = true
+	}
+	return nil
+}
+
+// resolveOrderCustomFields validates submitted order field values against the schema and returns them in their
+// stored types. Customers may only fill customer-visible fields, and only those are required of them. A nil input
+// skips the schema (orders the system creates itself, such as pre-order conversions).
+func resolveOrderCustomFields(defs []models.OrderFieldDefinition, input map[string]any, customer bool) (models.CustomFieldValues, error) {
+	if input == nil {
+		return nil, nil
+	}
+	byKey := make(map[string]models.OrderFieldDefinition, len(defs))
+	for _, d := range defs {
+		if !customer || d.CustomerVisible {
+			byKey[d.Key] = d
+		}
+	}
+	out := models.CustomFieldValues{}
+	for key, raw := range input {
+		d, ok := byKey[key]
+		if !ok {
+			return nil, errors.ErrValidation("unknown order field " + strconv.Quote(key))
+		}
+		v, err := customFieldValue(d.Label, d.Type, d.Options, raw)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			out[key] = v
+		}
+	}
+	for _, d := range byKey {
+		if _, ok := out[d.Key]; d.Required && !ok {
+			return nil, errors.ErrValidation(d.Label + " is required")
+		}
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// invoiceFieldValues lists the order's values of fields marked for invoices, in schema order.
+func invoiceFieldValues(defs []models.OrderFieldDefinition, values models.CustomFieldValues) []models.OrderFieldValue {
+	var out []models.OrderFieldValue
+	for _, d := range defs {
+		if !d.ShowOnInvoice {
+			continue
+		}
+		if text := values.Text(d.Key); text != "" {
+			out = append(out, models.OrderFieldValue{Key: d.Key, Label: d.Label, Value: text})
+		}
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+)
+
+var testOrderFields = []models.OrderFieldDefinition{
+	{Key: "doctor_name", Label: "Doctor", Type: models.CustomFieldText, Required: true, CustomerVisible: true, ShowOnInvoice: true},
+	{Key: "token_number", Label: "Token", Type: models.CustomFieldNumber, Required: true},
+	{Key: "hospital", Label: "Hospital", Type: models.CustomFieldSelect, Options: []string{"Bir", "Teaching"}, ShowOnInvoice: true},
+	{Key: "follow_up", Label: "Follow-up", Type: models.CustomFieldDate},
+}
+
+func TestResolveOrderCustomFields_StaffTypesAndRequired(t *testing.T) {
+	got, err := resolveOrderCustomFields(testOrderFields, map[string]any{"doctor_name": " Dr. Rai ", "token_number": "42", "hospital": "Bir", "follow_up": ""}, false)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if got["doctor_name"] != "Dr. Rai" || got["token_number"] != 42.0 || got["hospital"] != "Bir" {
+		t.Errorf("values = %v", got)
+	}
+	if _, ok := got["follow_up"]; ok {
+		t.Error("empty follow_up should be dropped")
+	}
+	if vals := invoiceFieldValues(testOrderFields, got); len(vals) != 2 || vals[0].Value != "Dr. Rai" || vals[1].Label != "Hospital" {
+		t.Errorf("invoice values = %+v", vals)
+	}
+
+	for name, input := range map[string]map[string]any{
+		"missing required": {"doctor_name": "Dr. Rai"},
+		"bad option":       {"doctor_name": "x", "token_number": 1.0, "hospital": "Other"},
+		"bad date":         {"doctor_name": "x", "token_number": 1.0, "follow_up": "tomorrow"},
+		"unknown key":      {"doctor_name": "x", "token_number": 1.0, "ward": "3"},
+	} {
+		if _, err := resolveOrderCustomFields(testOrderFields, input, false); err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+			t.Errorf("%s: err = %v, want validation error", name, err)
+		}
+	}
+}
+
+func TestResolveOrderCustomFields_CustomerSeesOnlyVisibleFields(t *testing.T) {
+	if _, err := resolveOrderCustomFields(testOrderFields, map[string]any{"doctor_name": "Dr. Rai"}, true); err != nil {
+		t.Errorf("staff-only required field should not apply to customers: %v", err)
+	}
+	if _, err := resolveOrderCustomFields(testOrderFields, map[string]any{"doctor_name": "Dr. Rai", "token_number": 5.0}, true); err == nil {
+		t.Error("customers must not set staff-only fields")
+	}
+	if _, err := resolveOrderCustomFields(testOrderFields, map[string]any{}, true); err == nil {
+		t.Error("required customer field should be enforced")
+	}
+	if got, err := resolveOrderCustomFields(testOrderFields, nil, false); err != nil || got != nil {
+		t.Errorf("nil input should skip the schema, got %v, %v", got, err)
+	}
+}
+
+func TestValidateOrderFieldDefinitions(t *testing.T) {
+	if err := validateOrderFieldDefinitions(testOrderFields); err != nil {
+		t.Fatalf("valid schema rejected: %v", err)
+	}
+	for name, defs := range map[string][]models.OrderFieldDefinition{
+		"bad key":        {{Key: "Doctor Name", Label: "Doctor", Type: models.CustomFieldText}},
+		"duplicate key":  {{Key: "a", Label: "A", Type: models.CustomFieldText}, {Key: "a", Label: "B", Type: models.CustomFieldText}},
+		"select no opts": {{Key: "a", Label: "A", Type: models.CustomFieldSelect}},
+		"unknown type":   {{Key: "a", Label: "A", Type: "file"}},
+		"missing label":  {{Key: "a", Type: models.CustomFieldText}},
+	} {
+		if err := validateOrderFieldDefinitions(defs); err == nil {
+			t.Errorf("%s: want error", name)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
@@ -9,20 +11,28 @@ import (
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 	"github.com/careplus/pharmacy-backend/pkg/errors"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 const returnRequestWindowDays = 3
 
 type orderReturnRequestService struct {
-	orderRepo  outbound.OrderRepository
-	returnRepo outbound.OrderReturnRequestRepository
+	orderRepo      outbound.OrderRepository
+	returnRepo     outbound.OrderReturnRequestRepository
+	flagRepo       outbound.ProductReturnFlagRepository
+	configRepo     outbound.PharmacyConfigRepository
+	invoiceSvc     inbound.InvoiceService
+	storeCreditSvc inbound.StoreCreditService
+	logger         *zap.Logger
 }
 
-func NewOrderReturnRequestService(orderRepo outbound.OrderRepository, returnRepo outbound.OrderReturnRequestRepository) inbound.OrderReturnRequestService {
-	return &orderReturnRequestService{orderRepo: orderRepo, returnRepo: returnRepo}
+// NewOrderReturnRequestService returns the service. invoiceSvc, when set, issues a credit note on approval;
+// storeCreditSvc, when set, lets an approval refund straight to the customer's wallet.
+func NewOrderReturnRequestService(orderRepo outbound.OrderRepository, returnRepo outbound.OrderReturnRequestRepository, flagRepo outbound.ProductReturnFlagRepository, configRepo outbound.PharmacyConfigRepository, invoiceSvc inbound.InvoiceService, storeCreditSvc inbound.StoreCreditService, logger *zap.Logger) inbound.OrderReturnRequestService {
+	return &orderReturnRequestService{orderRepo: orderRepo, returnRepo: returnRepo, flagRepo: flagRepo, configRepo: configRepo, invoiceSvc: invoiceSvc, storeCreditSvc: storeCreditSvc, logger: logger}
 }
 
-func (s *orderReturnRequestService) Create(ctx context.Context, orderID, userID uuid.UUID, videoURL string, photoURLs []string, notes, description string) (*models.OrderReturnRequest, error) {
+func (s *orderReturnRequestService) Create(ctx context.Context, orderID, userID uuid.UUID, in inbound.ReturnRequestInput) (*models.OrderReturnRequest, error) {
 	o, err := s.orderRepo.GetByID(ctx, orderID)
 	if err != nil || o == nil {
 		return nil, errors.ErrNotFound("order")
@@ -40,12 +50,25 @@ func (s *orderReturnRequestService) Create(ctx context.Context, orderID, userID
 	if time.Since(*completedAt) > returnRequestWindowDays*24*time.Hour {
 		return nil, errors.ErrValidation("return requests must be submitted within 3 days of order completion")
 	}
-	if videoURL == "" && len(photoURLs) == 0 {
+	if in.VideoURL == "" && len(in.PhotoURLs) == 0 {
 		return nil, errors.ErrValidation("please provide at least one video or photo as evidence")
 	}
-	if notes == "" && description == "" {
+	if in.Notes == "" && in.Description == "" {
 		return nil, errors.ErrValidation("please provide notes and description for the return request")
 	}
+	if in.ReasonCode != "" {
+		if _, ok := models.ReturnReasonRegistry[in.ReasonCode]; !ok {
+			return nil, errors.ErrValidation("unknown reason_code")
+		}
+	}
+	items, err := s.orderRepo.GetItemsByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load order items", err)
+	}
+	productIDs, err := returnedProducts(items, in.ProductIDs)
+	if err != nil {
+		return nil, err
+	}
 	existing, err := s.returnRepo.GetByOrderID(ctx, orderID)
 	if err != nil {
 		return nil, err
@@ -57,17 +80,327 @@ func (s *orderReturnRequestService) Create(ctx context.Context, orderID, userID
 		OrderID:     orderID,
 		UserID:      userID,
 		Status:      models.ReturnRequestStatusPending,
-		VideoURL:    videoURL,
-		PhotoURLs:   models.StringSlice(photoURLs),
-		Notes:       notes,
-		Description: description,
+		VideoURL:    in.VideoURL,
+		PhotoURLs:   models.StringSlice(in.PhotoURLs),
+		Notes:       in.Notes,
+		Description: in.Description,
+		ReasonCode:  in.ReasonCode,
+	}
+	for _, id := range in.ProductIDs {
+		req.ProductIDs = append(req.ProductIDs, id.String())
 	}
 	if err := s.returnRepo.Create(ctx, req); err != nil {
 		return nil, err
 	}
+	s.evaluateFlags(ctx, o.PharmacyID, productIDs)
 	return req, nil
 }
 
 func (s *orderReturnRequestService) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.OrderReturnRequest, error) {
 	return s.returnRepo.GetByOrderID(ctx, orderID)
 }
+
+// returnReasonOrder is the display order of models.ReturnReasonRegistry, with "other" last.
+var returnReasonOrder = []string{
+	models.ReturnReasonDamagedPackaging, models.ReturnReasonBrokenSeal, models.ReturnReasonExpired,
+	models.ReturnReasonShortExpiry, models.ReturnReasonWrongItem, models.ReturnReasonMissingItem,
+	models.ReturnReasonQualityDefect, models.ReturnReasonAdverseReaction, models.ReturnReasonOther,
+}
+
+func (s *orderReturnRequestService) Reasons() []inbound.ReturnReasonInfo {
+	list := make([]inbound.ReturnReasonInfo, 0, len(returnReasonOrder))
+	for _, code := range returnReasonOrder {
+		list = append(list, inbound.ReturnReasonInfo{Code: code, Label: models.ReturnReasonRegistry[code]})
+	}
+	return list
+}
+
+func (s *orderReturnRequestService) List(ctx context.Context, pharmacyID uuid.UUID, q inbound.ReturnRequestListQuery) ([]*models.OrderReturnRequest, int64, error) {
+	if q.ReasonCode != "" {
+		if _, ok := models.ReturnReasonRegistry[q.ReasonCode]; !ok {
+			return nil, 0, errors.ErrValidation("unknown reason_code")
+		}
+	}
+	if q.Limit <= 0 || q.Limit > 100 {
+		q.Limit = 20
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	return s.returnRepo.ListByPharmacy(ctx, pharmacyID, outbound.ReturnRequestFilter{Status: q.Status, ReasonCode: q.ReasonCode}, q.Limit, q.Offset)
+}
+
+func (s *orderReturnRequestService) Review(ctx context.Context, pharmacyID, id, actorID uuid.UUID, in inbound.ReturnReviewInput) (*models.OrderReturnRequest, error) {
+	req, err := s.returnRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load return request", err)
+	}
+	if req == nil || req.Order == nil || req.Order.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("return request")
+	}
+	wasApproved := req.Status == models.ReturnRequestStatusApproved
+	if in.Status != nil {
+		switch *in.Status {
+		case models.ReturnRequestStatusPending, models.ReturnRequestStatusApproved, models.ReturnRequestStatusRejected:
+			req.Status = *in.Status
+		default:
+			return nil, errors.ErrValidation("status must be pending, approved or rejected")
+		}
+	}
+	if in.ReasonCode != nil {
+		if _, ok := models.ReturnReasonRegistry[*in.ReasonCode]; !ok {
+			return nil, errors.ErrValidation("unknown reason_code")
+		}
+		req.ReasonCode = *in.ReasonCode
+	}
+	if in.StaffNote != nil {
+		req.StaffNote = strings.TrimSpace(*in.StaffNote)
+	}
+	if in.RefundToWallet {
+		switch {
+		case s.storeCreditSvc == nil:
+			return nil, errors.ErrValidation("wallet refunds are not available")
+		case req.Status != models.ReturnRequestStatusApproved:
+			return nil, errors.ErrValidation("only an approved return can be refunded to the wallet")
+		case req.RefundEntryID != nil:
+			return nil, errors.ErrConflict("return was already refunded to the wallet")
+		}
+	}
+	now := time.Now()
+	req.ReviewedBy = &actorID
+	req.ReviewedAt = &now
+	if err := s.returnRepo.Update(ctx, req); err != nil {
+		return nil, errors.ErrInternal("failed to update return request", err)
+	}
+	if req.Status == models.ReturnRequestStatusApproved && !wasApproved {
+		s.issueCreditNote(ctx, pharmacyID, actorID, req)
+	}
+	// The wallet refund comes after the credit note, so the invoice is credited for the return itself and
+	// the refund adds no second note. A failed refund leaves the approval in place to be retried.
+	if in.RefundToWallet {
+		o, err := s.orderRepo.GetByID(ctx, req.OrderID)
+		if err != nil || o == nil {
+			return nil, errors.ErrNotFound("order")
+		}
+		amount, ok := returnAmount(o, req)
+		if !ok {
+			return nil, errors.ErrValidation("the returned products are not on the order")
+		}
+		e, err := s.storeCreditSvc.RefundOrder(ctx, pharmacyID, o.ID, actorID, amount, returnReason(req))
+		if err != nil {
+			return nil, err
+		}
+		req.RefundEntryID = &e.ID
+		if err := s.returnRepo.Update(ctx, req); err != nil {
+			return nil, errors.ErrInternal("failed to record wallet refund", err)
+		}
+	}
+	return req, nil
+}
+
+// returnAmount is the returned products' share of the invoice, or 0 (everything not yet credited) when the
+// request names no products. ok is false when none of the named products are on the order.
+func returnAmount(o *models.Order, req *models.OrderReturnRequest) (float64, bool) {
+	if len(req.ProductIDs) == 0 || o.SubTotal <= 0 {
+		return 0, true
+	}
+	returned := map[string]bool{}
+	for _, id := range req.ProductIDs {
+		returned[id] = true
+	}
+	lines := 0.0
+	for _, it := range o.Items {
+		if returned[it.ProductID.String()] {
+			lines += it.TotalPrice
+		}
+	}
+	if lines <= 0 {
+		return 0, false
+	}
+	return roundMoney(invoiceTotal(o) * math.Min(lines/o.SubTotal, 1)), true
+}
+
+func returnReason(req *models.OrderReturnRequest) string {
+	if reason := models.ReturnReasonRegistry[req.ReasonCode]; reason != "" {
+		return reason
+	}
+	return "Return approved"
+}
+
+// issueCreditNote credits the returned products' share of the invoice (the whole invoice when the request
+// names no products). Failures are logged: the approval stands and finance can credit manually.
+func (s *orderReturnRequestService) issueCreditNote(ctx context.Context, pharmacyID, actorID uuid.UUID, req *models.OrderReturnRequest) {
+	if s.invoiceSvc == nil {
+		return
+	}
+	o, err := s.orderRepo.GetByID(ctx, req.OrderID)
+	if err != nil || o == nil {
+		s.logger.Warn("credit note: order not found", zap.String("order_id", req.OrderID.String()), zap.Error(err))
+		return
+	}
+	amount, ok := returnAmount(o, req)
+	if !ok {
+		return
+	}
+	reason := returnReason(req)
+	if _, err := s.invoiceSvc.IssueCreditNote(ctx, pharmacyID, o.ID, actorID, inbound.CreditNoteInput{
+		Source:   models.CreditNoteSourceReturn,
+		SourceID: &req.ID,
+		Amount:   amount,
+		Reason:   reason,
+	}); err != nil {
+		s.logger.Warn("failed to issue credit note for return", zap.String("return_request_id", req.ID.String()), zap.Error(err))
+	}
+}
+
+func (s *orderReturnRequestService) Analytics(ctx context.Context, pharmacyID uuid.UUID, groupBy string, from, to time.Time) (*inbound.ReturnAnalytics, error) {
+	from, to, err := normalizeRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	switch groupBy {
+	case "product", "brand", "supplier":
+	case "":
+		groupBy = "product"
+	default:
+		return nil, errors.ErrValidation("group_by must be product, brand or supplier")
+	}
+	rows, err := s.returnRepo.ReturnRates(ctx, pharmacyID, groupBy, from, to, nil)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load return rates", err)
+	}
+	reasons, err := s.returnRepo.ReasonBreakdown(ctx, pharmacyID, from, to)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load return reasons", err)
+	}
+	out := &inbound.ReturnAnalytics{From: from, To: to, GroupBy: groupBy, Rows: rows, Reasons: reasons}
+	if out.Rows == nil {
+		out.Rows = []*models.ReturnRateRow{}
+	}
+	if out.Reasons == nil {
+		out.Reasons = []*models.ReturnReasonRow{}
+	}
+	for _, row := range out.Rows {
+		row.ReturnRate = returnRate(row.UnitsReturned, row.UnitsSold)
+		out.UnitsSold += row.UnitsSold
+		out.UnitsReturned += row.UnitsReturned
+	}
+	out.ReturnRate = returnRate(out.UnitsReturned, out.UnitsSold)
+	for _, r := range out.Reasons {
+		r.Label = models.ReturnReasonRegistry[r.ReasonCode]
+		if r.ReasonCode == "" {
+			r.Label = "Unclassified"
+		}
+	}
+	return out, nil
+}
+
+func (s *orderReturnRequestService) ListFlags(ctx context.Context, pharmacyID uuid.UUID, status string) ([]*models.ProductReturnFlag, error) {
+	if status != "" && status != models.ReturnFlagOpen && status != models.ReturnFlagReviewed {
+		return nil, errors.ErrValidation("status must be open or reviewed")
+	}
+	return s.flagRepo.ListByPharmacy(ctx, pharmacyID, status)
+}
+
+func (s *orderReturnRequestService) ReviewFlag(ctx context.Context, pharmacyID, productID, actorID uuid.UUID, note string) (*models.ProductReturnFlag, error) {
+	f, err := s.flagRepo.GetByProductID(ctx, productID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load return flag", err)
+	}
+	if f == nil || f.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("return flag")
+	}
+	now := time.Now()
+	f.Status = models.ReturnFlagReviewed
+	f.ReviewedBy = &actorID
+	f.ReviewedAt = &now
+	f.ReviewNote = strings.TrimSpace(note)
+	if err := s.flagRepo.Update(ctx, f); err != nil {
+		return nil, errors.ErrInternal("failed to update return flag", err)
+	}
+	return f, nil
+}
+
+// evaluateFlags recomputes the return rate of the given products over the policy window and opens (or
+// refreshes) a flag for each product at or above the threshold. Failures are logged, not returned.
+func (s *orderReturnRequestService) evaluateFlags(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID) {
+	if s.flagRepo == nil || len(productIDs) == 0 {
+		return
+	}
+	policy := models.DefaultReturnRateAlertPolicy()
+	if s.configRepo != nil {
+		if cfg, err := s.configRepo.GetByPharmacyID(ctx, pharmacyID); err == nil && cfg != nil && cfg.ReturnRateAlert != nil {
+			policy = cfg.ReturnRateAlert
+		}
+	}
+	if !policy.Enabled {
+		return
+	}
+	now := time.Now()
+	rows, err := s.returnRepo.ReturnRates(ctx, pharmacyID, "product", now.AddDate(0, 0, -policy.WindowDays), now, productIDs)
+	if err != nil {
+		s.logger.Warn("failed to compute return rates", zap.String("pharmacy_id", pharmacyID.String()), zap.Error(err))
+		return
+	}
+	for _, row := range rows {
+		rate := returnRate(row.UnitsReturned, row.UnitsSold)
+		if row.UnitsSold < int64(policy.MinUnitsSold) || rate < policy.ThresholdPercent {
+			continue
+		}
+		productID, err := uuid.Parse(row.Key)
+		if err != nil {
+			continue
+		}
+		f, err := s.flagRepo.GetByProductID(ctx, productID)
+		if err != nil {
+			s.logger.Warn("failed to load return flag", zap.String("product_id", row.Key), zap.Error(err))
+			continue
+		}
+		if f == nil {
+			f = &models.ProductReturnFlag{PharmacyID: pharmacyID, ProductID: productID, Status: models.ReturnFlagOpen, FlaggedAt: now}
+		} else if f.Status != models.ReturnFlagOpen {
+			f.Status = models.ReturnFlagOpen
+			f.FlaggedAt = now
+			f.ReviewedBy, f.ReviewedAt, f.ReviewNote = nil, nil, ""
+		}
+		f.UnitsSold, f.UnitsReturned, f.ReturnRate = row.UnitsSold, row.UnitsReturned, rate
+		if f.ID == uuid.Nil {
+			err = s.flagRepo.Create(ctx, f)
+		} else {
+			err = s.flagRepo.Update(ctx, f)
+		}
+		if err != nil {
+			s.logger.Warn("failed to save return flag", zap.String("product_id", row.Key), zap.Error(err))
+		}
+	}
+}
+
+// returnedProducts checks that the requested products are on the order and returns the products the
+// request covers (all order lines when none were named).
+func returnedProducts(items []*models.OrderItem, requested []uuid.UUID) ([]uuid.UUID, error) {
+	onOrder := make(map[uuid.UUID]bool, len(items))
+	all := make([]uuid.UUID, 0, len(items))
+	for _, it := range items {
+		if !onOrder[it.ProductID] {
+			onOrder[it.ProductID] = true
+			all = append(all, it.ProductID)
+		}
+	}
+	if len(requested) == 0 {
+		return all, nil
+	}
+	for _, id := range requested {
+		if !onOrder[id] {
+			return nil, errors.ErrValidation("product_ids must be products on this order")
+		}
+	}
+	return requested, nil
+}
+
+// returnRate is returned as a percent of sold, rounded to 2 decimals.
+func returnRate(returned, sold int64) float64 {
+	if sold <= 0 {
+		return 0
+	}
+	return math.Round(float64(returned)/float64(sold)*10000) / 100
+}
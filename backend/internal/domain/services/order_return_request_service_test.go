@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type returnFixture struct {
+	order    *models.Order
+	productA uuid.UUID
+	productB uuid.UUID
+	rates    []*models.ReturnRateRow
+	ratesFor []uuid.UUID
+	flag     *models.ProductReturnFlag
+	returns  *mocks.MockOrderReturnRequestRepository
+	svc      inbound.OrderReturnRequestService
+}
+
+func newReturnFixture() *returnFixture {
+	f := &returnFixture{productA: uuid.New(), productB: uuid.New()}
+	completed := time.Now().Add(-time.Hour)
+	f.order = &models.Order{ID: uuid.New(), PharmacyID: uuid.New(), Status: models.OrderStatusCompleted, CreatedBy: uuid.New(), CompletedAt: &completed}
+	orders := &mocks.MockOrderRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Order, error) { return f.order, nil },
+		GetItemsByOrderIDFunc: func(ctx context.Context, orderID uuid.UUID) ([]*models.OrderItem, error) {
+			return []*models.OrderItem{{ProductID: f.productA, Quantity: 1}, {ProductID: f.productB, Quantity: 2}}, nil
+		},
+	}
+	f.returns = &mocks.MockOrderReturnRequestRepository{
+		ReturnRatesFunc: func(ctx context.Context, pharmacyID uuid.UUID, groupBy string, from, to time.Time, productIDs []uuid.UUID) ([]*models.ReturnRateRow, error) {
+			f.ratesFor = productIDs
+			return f.rates, nil
+		},
+	}
+	flags := &mocks.MockProductReturnFlagRepository{
+		GetByProductIDFunc: func(ctx context.Context, productID uuid.UUID) (*models.ProductReturnFlag, error) { return f.flag, nil },
+		CreateFunc: func(ctx context.Context, fl *models.ProductReturnFlag) error {
+			fl.ID = uuid.New()
+			f.flag = fl
+			return nil
+		},
+	}
+	f.svc = NewOrderReturnRequestService(orders, f.returns, flags, &mocks.MockPharmacyConfigRepository{}, nil, nil, zap.NewNop())
+	return f
+}
+
+func (f *returnFixture) input() inbound.ReturnRequestInput {
+	return inbound.ReturnRequestInput{PhotoURLs: []string{"https://example.com/a.jpg"}, Description: "Seal was broken"}
+}
+
+func TestOrderReturnRequestService_Create_ValidatesReasonAndProducts(t *testing.T) {
+	f := newReturnFixture()
+	in := f.input()
+	in.ReasonCode = "changed_mind"
+	if _, err := f.svc.Create(context.Background(), f.order.ID, f.order.CreatedBy, in); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("expected validation error for unknown reason, got %v", err)
+	}
+	in.ReasonCode = models.ReturnReasonBrokenSeal
+	in.ProductIDs = []uuid.UUID{uuid.New()}
+	if _, err := f.svc.Create(context.Background(), f.order.ID, f.order.CreatedBy, in); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("expected validation error for product not on order, got %v", err)
+	}
+	in.ProductIDs = []uuid.UUID{f.productB}
+	req, err := f.svc.Create(context.Background(), f.order.ID, f.order.CreatedBy, in)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if req.ReasonCode != models.ReturnReasonBrokenSeal || len(req.ProductIDs) != 1 || req.ProductIDs[0] != f.productB.String() {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if len(f.ratesFor) != 1 || f.ratesFor[0] != f.productB {
+		t.Errorf("expected flag evaluation for the returned product only, got %v", f.ratesFor)
+	}
+}
+
+func TestOrderReturnRequestService_Create_FlagsHighReturnRate(t *testing.T) {
+	f := newReturnFixture()
+	f.rates = []*models.ReturnRateRow{
+		{Key: f.productA.String(), UnitsSold: 40, UnitsReturned: 4}, // 10%: flagged
+		{Key: f.productB.String(), UnitsSold: 10, UnitsReturned: 5}, // too few sold
+	}
+	if _, err := f.svc.Create(context.Background(), f.order.ID, f.order.CreatedBy, f.input()); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(f.ratesFor) != 2 {
+		t.Errorf("expected evaluation of every order line, got %v", f.ratesFor)
+	}
+	if f.flag == nil || f.flag.ProductID != f.productA || f.flag.Status != models.ReturnFlagOpen || f.flag.ReturnRate != 10 {
+		t.Fatalf("expected open flag for product A at 10%%, got %+v", f.flag)
+	}
+
+	// A reviewed flag is reopened when a later return keeps the rate above the threshold.
+	reviewer := uuid.New()
+	f.flag.Status, f.flag.ReviewedBy = models.ReturnFlagReviewed, &reviewer
+	if _, err := f.svc.Create(context.Background(), f.order.ID, f.order.CreatedBy, f.input()); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if f.flag.Status != models.ReturnFlagOpen || f.flag.ReviewedBy != nil {
+		t.Errorf("expected flag reopened, got %+v", f.flag)
+	}
+}
+
+func TestOrderReturnRequestService_Review_RejectsOtherPharmacy(t *testing.T) {
+	f := newReturnFixture()
+	stored := &models.OrderReturnRequest{ID: uuid.New(), OrderID: f.order.ID, Order: f.order, Status: models.ReturnRequestStatusPending}
+	f.returns.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*models.OrderReturnRequest, error) { return stored, nil }
+	approved := models.ReturnRequestStatusApproved
+	reason := models.ReturnReasonQualityDefect
+	if _, err := f.svc.Review(context.Background(), uuid.New(), stored.ID, uuid.New(), inbound.ReturnReviewInput{Status: &approved}); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeNotFound {
+		t.Errorf("expected not found for another pharmacy, got %v", err)
+	}
+	req, err := f.svc.Review(context.Background(), f.order.PharmacyID, stored.ID, uuid.New(), inbound.ReturnReviewInput{Status: &approved, ReasonCode: &reason})
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	if req.Status != approved || req.ReasonCode != reason || req.ReviewedAt == nil {
+		t.Errorf("unexpected review result: %+v", req)
+	}
+}
+
+func TestOrderReturnRequestService_Analytics_ComputesRates(t *testing.T) {
+	f := newReturnFixture()
+	f.rates = []*models.ReturnRateRow{{Key: "Acme", UnitsSold: 200, UnitsReturned: 3}, {Key: "Other", UnitsSold: 100, UnitsReturned: 0}}
+	f.returns.ReasonBreakdownFunc = func(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.ReturnReasonRow, error) {
+		return []*models.ReturnReasonRow{{ReasonCode: models.ReturnReasonExpired, Count: 2}, {ReasonCode: "", Count: 1}}, nil
+	}
+	if _, err := f.svc.Analytics(context.Background(), f.order.PharmacyID, "category", time.Time{}, time.Time{}); pkgerrors.GetAppError(err) == nil {
+		t.Errorf("expected validation error for unknown group_by")
+	}
+	out, err := f.svc.Analytics(context.Background(), f.order.PharmacyID, "brand", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Analytics: %v", err)
+	}
+	if out.Rows[0].ReturnRate != 1.5 || out.ReturnRate != 1 || out.UnitsSold != 300 {
+		t.Errorf("unexpected rates: %+v", out)
+	}
+	if out.Reasons[0].Label != models.ReturnReasonRegistry[models.ReturnReasonExpired] || out.Reasons[1].Label != "Unclassified" {
+		t.Errorf("unexpected reason labels: %+v", out.Reasons)
+	}
+}
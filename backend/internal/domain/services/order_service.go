@@ -10,7 +10,9 @@ import (
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/careplus/pharmacy-backend/pkg/tracing"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -26,12 +28,32 @@ type orderService struct {
 	paymentGatewayRepo      outbound.PaymentGatewayRepository
 	paymentSvc              inbound.PaymentService
 	userRepo                outbound.UserRepository
-	staffPointsConfigRepo   outbound.StaffPointsConfigRepository
+	configRepo              outbound.PharmacyConfigRepository
+	flashSaleSvc            inbound.FlashSaleService
+	mailer                  inbound.MailerService
+	smsNotifier             inbound.SMSNotificationService
+	pushNotifier            inbound.PushNotificationService
+	expiryDiscountSvc       inbound.ExpiryDiscountService
+	giftCardSvc             inbound.GiftCardService
+	storeCreditSvc          inbound.StoreCreditService
+	benefitsEngine          inbound.BenefitsEngine
+	serialSvc               inbound.SerialService
+	drugInfoSvc             inbound.DrugInfoService
+	licenseSvc              inbound.LicenseComplianceService
+	uow                     outbound.UnitOfWork
 	logger                  *zap.Logger
 }
 
-func NewOrderService(orderRepo outbound.OrderRepository, productRepo outbound.ProductRepository, inventoryService inbound.InventoryService, promoCodeRepo outbound.PromoCodeRepository, promoCodeSvc inbound.PromoCodeService, customerRepo outbound.CustomerRepository, customerMembershipRepo outbound.CustomerMembershipRepository, referralPointsSvc inbound.ReferralPointsService, paymentGatewayRepo outbound.PaymentGatewayRepository, paymentSvc inbound.PaymentService, userRepo outbound.UserRepository, staffPointsConfigRepo outbound.StaffPointsConfigRepository, logger *zap.Logger) inbound.OrderService {
-	return &orderService{orderRepo: orderRepo, productRepo: productRepo, inventoryService: inventoryService, promoCodeRepo: promoCodeRepo, promoCodeSvc: promoCodeSvc, customerRepo: customerRepo, customerMembershipRepo: customerMembershipRepo, referralPointsSvc: referralPointsSvc, paymentGatewayRepo: paymentGatewayRepo, paymentSvc: paymentSvc, userRepo: userRepo, staffPointsConfigRepo: staffPointsConfigRepo, logger: logger}
+func NewOrderService(orderRepo outbound.OrderRepository, productRepo outbound.ProductRepository, inventoryService inbound.InventoryService, promoCodeRepo outbound.PromoCodeRepository, promoCodeSvc inbound.PromoCodeService, customerRepo outbound.CustomerRepository, customerMembershipRepo outbound.CustomerMembershipRepository, referralPointsSvc inbound.ReferralPointsService, paymentGatewayRepo outbound.PaymentGatewayRepository, paymentSvc inbound.PaymentService, userRepo outbound.UserRepository, configRepo outbound.PharmacyConfigRepository, flashSaleSvc inbound.FlashSaleService, mailer inbound.MailerService, smsNotifier inbound.SMSNotificationService, pushNotifier inbound.PushNotificationService, expiryDiscountSvc inbound.ExpiryDiscountService, giftCardSvc inbound.GiftCardService, storeCreditSvc inbound.StoreCreditService, benefitsEngine inbound.BenefitsEngine, serialSvc inbound.SerialService, drugInfoSvc inbound.DrugInfoService, licenseSvc inbound.LicenseComplianceService, uow outbound.UnitOfWork, logger *zap.Logger) inbound.OrderService {
+	return &orderService{orderRepo: orderRepo, productRepo: productRepo, inventoryService: inventoryService, promoCodeRepo: promoCodeRepo, promoCodeSvc: promoCodeSvc, customerRepo: customerRepo, customerMembershipRepo: customerMembershipRepo, referralPointsSvc: referralPointsSvc, paymentGatewayRepo: paymentGatewayRepo, paymentSvc: paymentSvc, userRepo: userRepo, configRepo: configRepo, flashSaleSvc: flashSaleSvc, mailer: mailer, smsNotifier: smsNotifier, pushNotifier: pushNotifier, expiryDiscountSvc: expiryDiscountSvc, giftCardSvc: giftCardSvc, storeCreditSvc: storeCreditSvc, benefitsEngine: benefitsEngine, serialSvc: serialSvc, drugInfoSvc: drugInfoSvc, licenseSvc: licenseSvc, uow: uow, logger: logger}
+}
+
+// inTx runs fn in uow's transaction, or directly when there is none (unit tests without a database).
+func inTx(ctx context.Context, uow outbound.UnitOfWork, fn func(ctx context.Context) error) error {
+	if uow == nil {
+		return fn(ctx)
+	}
+	return uow.Do(ctx, fn)
 }
 
 // gatewayCodeToPaymentMethod maps payment gateway code to Payment method for recording.
@@ -50,11 +72,69 @@ func gatewayCodeToPaymentMethod(code string) models.PaymentMethod {
 	}
 }
 
-func (s *orderService) Create(ctx context.Context, pharmacyID, createdBy uuid.UUID, customerName, customerPhone, customerEmail string, items []inbound.OrderItemInput, notes string, deliveryAddress string, discountAmount *float64, promoCode *string, referralCode *string, pointsToRedeem *int, paymentGatewayID *uuid.UUID) (*models.Order, error) {
+func (s *orderService) Create(ctx context.Context, pharmacyID, createdBy uuid.UUID, customerName, customerPhone, customerEmail string, items []inbound.OrderItemInput, notes string, deliveryAddress string, discountAmount *float64, promoCode *string, referralCode *string, pointsToRedeem *int, paymentGatewayID *uuid.UUID, tender *inbound.OrderTender, customFields map[string]any) (_ *models.Order, err error) {
+	ctx, span := tracing.Start(ctx, "OrderService.Create", attribute.String("pharmacy.id", pharmacyID.String()), attribute.Int("order.lines", len(items)))
+	defer tracing.End(span, &err)
 	if len(items) == 0 {
 		return nil, errors.ErrValidation("at least one item is required")
 	}
+	var cfg *models.PharmacyConfig
+	if s.configRepo != nil {
+		cfg, _ = s.configRepo.GetByPharmacyID(ctx, pharmacyID)
+	}
+	var creator *models.User
+	if s.userRepo != nil {
+		creator, _ = s.userRepo.GetByID(ctx, createdBy)
+	}
+	// Tenant order fields are checked before stock, promos or stored value are reserved.
+	var fieldDefs []models.OrderFieldDefinition
+	if cfg != nil {
+		fieldDefs = cfg.OrderFields
+	}
+	custom, err := resolveOrderCustomFields(fieldDefs, customFields, creator != nil && creator.Role == models.RoleStaff)
+	if err != nil {
+		return nil, err
+	}
+	if addr := strings.TrimSpace(deliveryAddress); addr != "" && !cfg.DeliversTo(addr) {
+		return nil, errors.ErrValidation("delivery is not available to this address")
+	}
+	// Live flash sales override the line price; caps are taken atomically and released if the order is not created.
+	items = append([]inbound.OrderItemInput(nil), items...)
+	// Per-customer caps need a known customer; walk-in sales without a phone only count against the total cap.
+	flashCustomerKey := strings.TrimSpace(customerPhone)
+	var flashReservations []*inbound.FlashSaleReservation
+	flashCommitted := false
+	if s.flashSaleSvc != nil {
+		var earlyAccess time.Duration
+		if phone := strings.TrimSpace(customerPhone); phone != "" {
+			if cust, _ := s.customerRepo.GetByPharmacyAndPhone(ctx, pharmacyID, phone); cust != nil {
+				earlyAccess = flashSaleEarlyAccess(ctx, s.benefitsEngine, cust.ID)
+			}
+		}
+		res, err := s.flashSaleSvc.ReserveForOrder(ctx, pharmacyID, flashCustomerKey, earlyAccess, items)
+		if err != nil {
+			return nil, err
+		}
+		flashReservations = res
+		defer func() {
+			if !flashCommitted {
+				s.flashSaleSvc.ReleaseReservations(ctx, flashReservations)
+			}
+		}()
+		for i, r := range res {
+			if r != nil {
+				items[i].UnitPrice = r.SalePrice
+			}
+		}
+	}
+	// Near-expiry markdowns apply to lines without a flash-sale price.
+	if s.expiryDiscountSvc != nil {
+		s.applyExpiryDiscounts(ctx, pharmacyID, items, flashReservations)
+	}
 	var subTotal float64
+	taxLines := make([]taxableLine, 0, len(items))
+	promoLines := make([]inbound.PromoLine, 0, len(items))
+	products := make([]*models.Product, 0, len(items))
 	for _, it := range items {
 		if it.Quantity <= 0 {
 			return nil, errors.ErrValidation("quantity must be positive")
@@ -66,10 +146,27 @@ func (s *orderService) Create(ctx context.Context, pharmacyID, createdBy uuid.UU
 		if prod.PharmacyID != pharmacyID {
 			return nil, errors.ErrForbidden("product does not belong to this pharmacy")
 		}
+		// Totals are summed and labelled in the base currency; a line priced in another one cannot be added to them.
+		if prod.Currency != "" && prod.Currency != cfg.Currency() {
+			return nil, errors.ErrValidation(prod.Name + " is priced in " + prod.Currency + ", not " + cfg.Currency())
+		}
 		if prod.StockQuantity < it.Quantity {
 			return nil, errors.ErrValidation("insufficient stock for " + prod.Name)
 		}
+		if len(it.SerialNumbers) > 0 && (!prod.TracksSerials || s.serialSvc == nil) {
+			return nil, errors.ErrValidation(prod.Name + " does not track serial numbers")
+		}
 		subTotal += it.UnitPrice * float64(it.Quantity)
+		taxLines = append(taxLines, taxableLine{TaxClass: prod.TaxClass, Amount: it.UnitPrice * float64(it.Quantity)})
+		promoLines = append(promoLines, promoLine(prod, it.Quantity, it.UnitPrice*float64(it.Quantity)))
+		products = append(products, prod)
+	}
+	// Interaction and duplicate-therapy warnings are stored on the order for the pharmacist to acknowledge.
+	var warnings []models.ClinicalWarning
+	if s.drugInfoSvc != nil {
+		if warnings, err = s.drugInfoSvc.Check(ctx, pharmacyID, products); err != nil {
+			return nil, err
+		}
 	}
 
 	discount := 0.0
@@ -104,111 +201,492 @@ func (s *orderService) Create(ctx context.Context, pharmacyID, createdBy uuid.UU
 	}
 
 	// Membership discount (if customer exists)
+	membershipDiscount := 0.0
 	if customerID != nil {
 		cm, _ := s.customerMembershipRepo.GetByCustomerID(ctx, *customerID)
 		if cm != nil && cm.Membership != nil && cm.Membership.IsActive && cm.Membership.DiscountPercent > 0 {
-			discount += subTotal * (cm.Membership.DiscountPercent / 100)
+			membershipDiscount = subTotal * (cm.Membership.DiscountPercent / 100)
 		}
 	}
 
+	// Promo discount per product, recorded on the order items it applied to.
+	promoShares := map[uuid.UUID]float64{}
+	exclusivePromo := false
 	if promoCode != nil && *promoCode != "" {
-		result, err := s.promoCodeSvc.Validate(ctx, pharmacyID, *promoCode, subTotal, &createdBy)
+		result, err := s.promoCodeSvc.Validate(ctx, pharmacyID, *promoCode, subTotal, promoLines, &createdBy)
 		if err != nil {
 			return nil, err
 		}
+		if result.Stacking == models.PromoStackingExclusive {
+			if pointsRedeemed > 0 {
+				return nil, errors.ErrValidation("promo code " + result.Code + " cannot be combined with points redemption")
+			}
+			membershipDiscount = 0
+			exclusivePromo = true
+		}
 		discount += result.DiscountAmount
 		promoCodeID = &result.PromoCodeID
+		for _, l := range result.Lines {
+			promoShares[l.ProductID] = l.Amount
+		}
 	} else if discountAmount != nil && *discountAmount > 0 {
 		discount += *discountAmount
 	}
+	discount += membershipDiscount
 	discount += discountFromPoints
+
+	// Tier benefits: delivery fee (waived with free delivery), birthday gift and points multiplier.
+	benefits := &inbound.BenefitsEvaluation{PointsMultiplier: 1}
+	birthdayCommitted := false
+	if s.benefitsEngine != nil {
+		ev, err := s.benefitsEngine.Evaluate(ctx, pharmacyID, customerID, inbound.BenefitsCheckout{
+			SubTotal:       subTotal,
+			Delivery:       strings.TrimSpace(deliveryAddress) != "",
+			ExclusivePromo: exclusivePromo,
+			Now:            time.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		benefits = ev
+		if ev.BirthdayYear != 0 {
+			defer func() {
+				if !birthdayCommitted {
+					s.benefitsEngine.ReleaseBirthdayGift(ctx, *customerID, ev.BirthdayYear)
+				}
+			}()
+		}
+	}
+	discount += benefits.BirthdayDiscount
 	if discount > subTotal {
 		discount = subTotal
 	}
 
+	// Amounts are rounded in the pharmacy's base currency, so the order, its invoice and reports agree.
+	currency := cfg.Currency()
+	subTotal = models.RoundAmount(subTotal, currency)
+	discount = models.RoundAmount(discount, currency)
 	totalAmount := subTotal - discount
 	if totalAmount < 0 {
 		totalAmount = 0
 	}
 
-	o := &models.Order{
-		PharmacyID:       pharmacyID,
-		CustomerName:     customerName,
-		CustomerPhone:    customerPhone,
-		CustomerEmail:    customerEmail,
-		CustomerID:       customerID,
-		Status:           models.OrderStatusPending,
-		SubTotal:         subTotal,
-		TaxAmount:        0,
-		DiscountAmount:   discount,
-		DeliveryAddress:  strings.TrimSpace(deliveryAddress),
-		PromoCodeID:      promoCodeID,
-		TotalAmount:      totalAmount,
-		Currency:         "NPR",
-		Notes:            notes,
-		CreatedBy:        createdBy,
-		ReferralCodeUsed: referralCodeUsed,
-		PointsRedeemed:   pointsRedeemed,
-	}
-	if err := s.orderRepo.Create(ctx, o); err != nil {
-		return nil, errors.ErrInternal("failed to create order", err)
-	}
-	if promoCodeID != nil {
-		_ = s.promoCodeRepo.IncrementUsedCount(ctx, *promoCodeID)
-	}
-	if pointsRedeemed > 0 && customerID != nil && s.referralPointsSvc != nil {
-		if err := s.referralPointsSvc.ApplyPointsRedeem(ctx, o.ID, *customerID, pointsRedeemed); err != nil {
-			return nil, err
-		}
+	// VAT per pharmacy config: added on top, or extracted when prices already include it
+	lineTaxes, taxAmount := computeOrderTax(cfg, taxLines, discount)
+	taxInclusive := cfg != nil && cfg.TaxEnabled && cfg.PricesIncludeTax
+	if !taxInclusive {
+		totalAmount += taxAmount
 	}
-	for _, it := range items {
-		item := &models.OrderItem{
-			OrderID:    o.ID,
-			ProductID:  it.ProductID,
-			Quantity:   it.Quantity,
-			UnitPrice:  it.UnitPrice,
-			TotalPrice: it.UnitPrice * float64(it.Quantity),
+	totalAmount += benefits.DeliveryFee
+	totalAmount = models.RoundAmount(totalAmount, currency)
+
+	// Orders taken by a team member assigned to a branch are fulfilled from that branch's stock.
+	var branchID *uuid.UUID
+	if creator != nil && creator.Role != models.RoleStaff {
+		branchID = creator.BranchID
+	}
+
+	// From tender to payment record every write commits together, so a failure part-way (e.g. a batch that
+	// cannot be consumed) leaves no order, items, stock movement, redeemed points or spent gift card behind.
+	orderID := uuid.New()
+	var o *models.Order
+	err = inTx(ctx, s.uow, func(ctx context.Context) error {
+		// Gift card and store credit pay part of the total.
+		var giftCardID *uuid.UUID
+		giftCardAmount, storeCreditAmount := 0.0, 0.0
+		if tender != nil && (strings.TrimSpace(tender.GiftCardCode) != "" || tender.StoreCredit > 0) {
+			var err error
+			giftCardID, giftCardAmount, storeCreditAmount, err = s.applyTender(ctx, pharmacyID, orderID, createdBy, customerID, tender, totalAmount, currency)
+			if err != nil {
+				return err
+			}
+			totalAmount = models.RoundAmount(totalAmount-giftCardAmount-storeCreditAmount, currency)
 		}
-		if err := s.orderRepo.CreateItem(ctx, item); err != nil {
-			return nil, errors.ErrInternal("failed to create order item", err)
+
+		o = &models.Order{
+			ID:                  orderID,
+			PharmacyID:          pharmacyID,
+			BranchID:            branchID,
+			CustomerName:        customerName,
+			CustomerPhone:       customerPhone,
+			CustomerEmail:       customerEmail,
+			CustomerID:          customerID,
+			Status:              models.OrderStatusPending,
+			SubTotal:            subTotal,
+			TaxAmount:           taxAmount,
+			TaxInclusive:        taxInclusive,
+			DiscountAmount:      discount,
+			DeliveryFee:         benefits.DeliveryFee,
+			DeliveryAddress:     strings.TrimSpace(deliveryAddress),
+			CustomFields:        custom,
+			PromoCodeID:         promoCodeID,
+			TotalAmount:         totalAmount,
+			Currency:            currency,
+			Notes:               notes,
+			CreatedBy:           createdBy,
+			ReferralCodeUsed:    referralCodeUsed,
+			PointsRedeemed:      pointsRedeemed,
+			GiftCardID:          giftCardID,
+			GiftCardAmount:      giftCardAmount,
+			StoreCreditAmount:   storeCreditAmount,
+			PointsMultiplier:    benefits.PointsMultiplier,
+			BirthdayBonusPoints: benefits.BirthdayBonusPoints,
+			ClinicalWarnings:    warnings,
 		}
-		if err := s.inventoryService.Consume(ctx, it.ProductID, it.Quantity); err != nil {
-			return nil, err
+		if err := s.orderRepo.Create(ctx, o, models.NewOutboxEvent(models.EventOrderCreated, o.PharmacyID, o.ID, orderEventData(o))); err != nil {
+			return errors.ErrInternal("failed to create order", err)
+		}
+		// A failed statement aborts the transaction, so secondary writes fail the order instead of being logged.
+		if err := s.orderRepo.CreateStatusHistory(ctx, s.statusChange(ctx, o, "", createdBy, "")); err != nil {
+			return errors.ErrInternal("failed to record order status history", err)
+		}
+		if s.flashSaleSvc != nil {
+			if err := s.flashSaleSvc.CommitReservations(ctx, o.ID, flashReservations); err != nil {
+				return errors.ErrInternal("failed to record flash sale redemptions", err)
+			}
+		}
+		if promoCodeID != nil {
+			if err := s.promoCodeRepo.IncrementUsedCount(ctx, *promoCodeID); err != nil {
+				return errors.ErrInternal("failed to update promo code usage", err)
+			}
 		}
+		if pointsRedeemed > 0 && customerID != nil && s.referralPointsSvc != nil {
+			if err := s.referralPointsSvc.ApplyPointsRedeem(ctx, o.ID, *customerID, pointsRedeemed); err != nil {
+				return err
+			}
+		}
+		for i, it := range items {
+			item := &models.OrderItem{
+				OrderID:    o.ID,
+				ProductID:  it.ProductID,
+				Quantity:   it.Quantity,
+				UnitPrice:  it.UnitPrice,
+				TotalPrice: it.UnitPrice * float64(it.Quantity),
+				TaxClass:   lineTaxes[i].TaxClass,
+				TaxRate:    lineTaxes[i].Rate,
+				TaxAmount:  lineTaxes[i].Tax,
+			}
+			// A product listed twice carries its whole promo share on the first line.
+			item.PromoDiscount = promoShares[it.ProductID]
+			delete(promoShares, it.ProductID)
+			if err := s.orderRepo.CreateItem(ctx, item); err != nil {
+				return errors.ErrInternal("failed to create order item", err)
+			}
+			if err := s.inventoryService.ConsumeAt(ctx, item, o.BranchID); err != nil {
+				return err
+			}
+			if len(it.SerialNumbers) > 0 {
+				if err := s.serialSvc.AssignToItem(ctx, o, item, it.SerialNumbers); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Mock payment: if payment gateway was selected, create and complete a payment record.
+		if paymentGatewayID != nil && *paymentGatewayID != uuid.Nil && o.TotalAmount > 0 {
+			gateway, err := s.paymentGatewayRepo.GetByID(ctx, *paymentGatewayID)
+			if err == nil && gateway != nil && gateway.PharmacyID == pharmacyID && gateway.IsActive {
+				payment := &models.Payment{
+					OrderID:          o.ID,
+					PharmacyID:       pharmacyID,
+					PaymentGatewayID: paymentGatewayID,
+					Amount:           o.TotalAmount,
+					Currency:         o.Currency,
+					Method:           gatewayCodeToPaymentMethod(gateway.Code),
+					Reference:        "mock-" + o.ID.String(),
+					CreatedBy:        createdBy,
+				}
+				if err := s.paymentSvc.Create(ctx, payment); err != nil {
+					return err
+				}
+				if err := s.paymentSvc.Complete(ctx, payment.ID); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	flashCommitted = true
+	birthdayCommitted = true
+
+	created, err := s.orderRepo.GetByID(ctx, o.ID)
+	if err == nil && s.mailer != nil {
+		_ = s.mailer.OrderConfirmation(ctx, created)
 	}
+	return created, err
+}
 
-	// Mock payment: if payment gateway was selected, create and complete a payment record.
-	if paymentGatewayID != nil && *paymentGatewayID != uuid.Nil {
-		gateway, err := s.paymentGatewayRepo.GetByID(ctx, *paymentGatewayID)
-		if err == nil && gateway != nil && gateway.PharmacyID == pharmacyID && gateway.IsActive {
-			payment := &models.Payment{
-				OrderID:          o.ID,
-				PharmacyID:       pharmacyID,
-				PaymentGatewayID: paymentGatewayID,
-				Amount:           o.TotalAmount,
-				Currency:         o.Currency,
-				Method:           gatewayCodeToPaymentMethod(gateway.Code),
-				Reference:        "mock-" + o.ID.String(),
-				CreatedBy:        createdBy,
+// applyTender redeems the gift card and store credit towards due, returning the card used and the amounts taken.
+// On error, whatever was already taken is left for the caller to reverse. A gift card must hold the order's
+// currency; store credit is kept in the base currency.
+func (s *orderService) applyTender(ctx context.Context, pharmacyID, orderID, actorID uuid.UUID, customerID *uuid.UUID, t *inbound.OrderTender, due float64, currency string) (*uuid.UUID, float64, float64, error) {
+	var cardID *uuid.UUID
+	card, credit := 0.0, 0.0
+	if code := strings.TrimSpace(t.GiftCardCode); code != "" {
+		if s.giftCardSvc == nil {
+			return nil, 0, 0, errors.ErrValidation("gift cards are not available")
+		}
+		bal, err := s.giftCardSvc.Check(ctx, pharmacyID, code)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if !bal.Usable {
+			return nil, 0, 0, errors.ErrValidation(bal.Reason)
+		}
+		if bal.Currency != currency {
+			return nil, 0, 0, errors.ErrValidation("gift card is in " + bal.Currency + ", but the order is in " + currency)
+		}
+		if card = roundMoney(math.Min(bal.Balance, due)); card > 0 {
+			txn, err := s.giftCardSvc.Redeem(ctx, pharmacyID, code, card, &orderID, actorID)
+			if err != nil {
+				return nil, 0, 0, err
 			}
-			if createErr := s.paymentSvc.Create(ctx, payment); createErr == nil {
-				_ = s.paymentSvc.Complete(ctx, payment.ID)
+			cardID = &txn.GiftCardID
+		}
+	}
+	if t.StoreCredit > 0 {
+		if s.storeCreditSvc == nil {
+			return cardID, card, 0, errors.ErrValidation("store credit is not available")
+		}
+		if customerID == nil {
+			return cardID, card, 0, errors.ErrValidation("store credit needs a known customer; provide the customer phone")
+		}
+		if credit = roundMoney(math.Min(t.StoreCredit, due-card)); credit > 0 {
+			if _, err := s.storeCreditSvc.Use(ctx, pharmacyID, *customerID, orderID, actorID, credit); err != nil {
+				return cardID, card, 0, err
 			}
 		}
 	}
+	return cardID, card, credit, nil
+}
 
-	return s.orderRepo.GetByID(ctx, o.ID)
+// reverseTender returns gift card and store credit held by a cancelled order.
+func (s *orderService) reverseTender(ctx context.Context, orderID, actorID uuid.UUID) error {
+	if s.giftCardSvc != nil {
+		if err := s.giftCardSvc.ReverseForOrder(ctx, orderID, actorID); err != nil {
+			return err
+		}
+	}
+	if s.storeCreditSvc != nil {
+		if err := s.storeCreditSvc.ReverseForOrder(ctx, orderID, actorID); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *orderService) GetByID(ctx context.Context, id uuid.UUID) (*models.Order, error) {
 	return s.orderRepo.GetByID(ctx, id)
 }
 
-func (s *orderService) List(ctx context.Context, pharmacyID uuid.UUID, createdBy *uuid.UUID, status *string) ([]*models.Order, error) {
+func (s *orderService) List(ctx context.Context, pharmacyID uuid.UUID, createdBy *uuid.UUID, status *string, branchID *uuid.UUID) ([]*models.Order, error) {
+	var list []*models.Order
+	var err error
 	if createdBy != nil {
-		return s.orderRepo.ListByPharmacyAndCreatedBy(ctx, pharmacyID, *createdBy, status)
+		list, err = s.orderRepo.ListByPharmacyAndCreatedBy(ctx, pharmacyID, *createdBy, status)
+	} else {
+		list, err = s.orderRepo.ListByPharmacy(ctx, pharmacyID, status)
+	}
+	if err != nil || branchID == nil {
+		return list, err
+	}
+	out := make([]*models.Order, 0, len(list))
+	for _, o := range list {
+		if o.BranchID != nil && *o.BranchID == *branchID {
+			out = append(out, o)
+		}
+	}
+	return out, nil
+}
+
+func (s *orderService) ListPage(ctx context.Context, pharmacyID uuid.UUID, createdBy *uuid.UUID, status *string, branchID *uuid.UUID, limit, offset int) ([]*models.Order, int64, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	list, total, err := s.orderRepo.ListPage(ctx, pharmacyID, createdBy, status, branchID, limit, offset)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to list orders", err)
+	}
+	if list == nil {
+		list = []*models.Order{}
+	}
+	return list, total, nil
+}
+
+func (s *orderService) ListPaginated(ctx context.Context, pharmacyID uuid.UUID, q inbound.OrderListQuery) ([]*models.Order, int64, error) {
+	if q.Limit <= 0 || q.Limit > 100 {
+		q.Limit = 20
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return nil, 0, errors.ErrValidation("from must be before to")
+	}
+	filter := outbound.OrderFilter{
+		CreatedBy:   q.CreatedBy,
+		BranchID:    q.BranchID,
+		Status:      q.Status,
+		From:        q.From,
+		To:          q.To,
+		OrderNumber: strings.TrimSpace(q.OrderNumber),
+	}
+	list, total, err := s.orderRepo.ListPaginated(ctx, pharmacyID, filter, q.Limit, q.Offset)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to list orders", err)
 	}
-	return s.orderRepo.ListByPharmacy(ctx, pharmacyID, status)
+	if list == nil {
+		list = []*models.Order{}
+	}
+	return list, total, nil
+}
+
+func (s *orderService) ListMine(ctx context.Context, pharmacyID, userID uuid.UUID, q inbound.OrderListQuery) ([]*inbound.OrderSummary, int64, error) {
+	q.CreatedBy, q.BranchID = &userID, nil
+	list, total, err := s.ListPaginated(ctx, pharmacyID, q)
+	if err != nil {
+		return nil, 0, err
+	}
+	ids := make([]uuid.UUID, len(list))
+	for i, o := range list {
+		ids[i] = o.ID
+	}
+	rows, err := s.orderRepo.ItemCounts(ctx, ids)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to count order items", err)
+	}
+	counts := make(map[uuid.UUID]*models.OrderItemCountRow, len(rows))
+	for _, r := range rows {
+		counts[r.OrderID] = r
+	}
+	changes, err := s.orderRepo.ListStatusHistory(ctx, ids)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to load order status history", err)
+	}
+	history := make(map[uuid.UUID][]*models.OrderStatusHistory, len(list))
+	for _, h := range changes {
+		history[h.OrderID] = append(history[h.OrderID], h)
+	}
+	out := make([]*inbound.OrderSummary, 0, len(list))
+	for _, o := range list {
+		sum := &inbound.OrderSummary{
+			ID:          o.ID,
+			OrderNumber: o.OrderNumber,
+			Status:      o.Status,
+			TotalAmount: o.TotalAmount,
+			Currency:    o.Currency,
+			CreatedAt:   o.CreatedAt,
+			CompletedAt: o.CompletedAt,
+			Timeline:    orderTimeline(o, history[o.ID]),
+		}
+		if c := counts[o.ID]; c != nil {
+			sum.ItemCount, sum.UnitCount = c.Lines, c.Units
+		}
+		out = append(out, sum)
+	}
+	return out, total, nil
+}
+
+// orderFlow is the happy path an order moves through; cancellation can end it at any stage.
+var orderFlow = []models.OrderStatus{
+	models.OrderStatusPending,
+	models.OrderStatusConfirmed,
+	models.OrderStatusProcessing,
+	models.OrderStatusReady,
+	models.OrderStatusCompleted,
+}
+
+// orderTimeline lays the order's status out on orderFlow, dating each step from the status history (oldest
+// first). Orders placed before history was recorded fall back to created_at, completed_at and updated_at for
+// the placement, completion and latest steps. A cancelled order shows the steps it reached and the cancellation.
+func orderTimeline(o *models.Order, history []*models.OrderStatusHistory) []inbound.OrderTimelineStep {
+	reachedAt := make(map[models.OrderStatus]*time.Time, len(history))
+	for _, h := range history {
+		at := h.CreatedAt
+		reachedAt[h.ToStatus] = &at
+	}
+	placed, updated := o.CreatedAt, o.UpdatedAt
+	if reachedAt[models.OrderStatusPending] == nil {
+		reachedAt[models.OrderStatusPending] = &placed
+	}
+	if o.Status == models.OrderStatusCancelled {
+		steps := []inbound.OrderTimelineStep{}
+		for _, st := range orderFlow {
+			if at := reachedAt[st]; at != nil {
+				steps = append(steps, inbound.OrderTimelineStep{Status: st, Reached: true, At: at})
+			}
+		}
+		at := reachedAt[models.OrderStatusCancelled]
+		if at == nil {
+			at = &updated
+		}
+		return append(steps, inbound.OrderTimelineStep{Status: models.OrderStatusCancelled, Reached: true, At: at})
+	}
+	current := -1
+	for i, st := range orderFlow {
+		if st == o.Status {
+			current = i
+		}
+	}
+	steps := make([]inbound.OrderTimelineStep, len(orderFlow))
+	for i, st := range orderFlow {
+		steps[i] = inbound.OrderTimelineStep{Status: st, Reached: i <= current}
+		if i <= current {
+			steps[i].At = reachedAt[st]
+		}
+	}
+	if current > 0 && steps[current].At == nil {
+		if o.Status == models.OrderStatusCompleted && o.CompletedAt != nil {
+			steps[current].At = o.CompletedAt
+		} else {
+			steps[current].At = &updated
+		}
+	}
+	return steps
+}
+
+// orderEventData is the payload of the order events raised for o.
+func orderEventData(o *models.Order) models.OrderEventData {
+	return models.OrderEventData{OrderID: o.ID, CustomerID: o.CustomerID, CreatedBy: o.CreatedBy, TotalAmount: o.TotalAmount, Status: o.Status}
+}
+
+// statusChange builds the history entry for moving o from `from` to its current status, snapshotting the
+// actor's name.
+func (s *orderService) statusChange(ctx context.Context, o *models.Order, from models.OrderStatus, actorID uuid.UUID, note string) *models.OrderStatusHistory {
+	h := &models.OrderStatusHistory{OrderID: o.ID, FromStatus: from, ToStatus: o.Status, Note: strings.TrimSpace(note)}
+	if actorID != uuid.Nil {
+		h.ActorID = &actorID
+		if s.userRepo != nil {
+			if u, err := s.userRepo.GetByID(ctx, actorID); err == nil && u != nil {
+				h.ActorName = u.Name
+			}
+		}
+	}
+	return h
+}
+
+func (s *orderService) History(ctx context.Context, pharmacyID, orderID uuid.UUID, viewerID *uuid.UUID) ([]*models.OrderStatusHistory, error) {
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil || o == nil || o.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("order")
+	}
+	if viewerID != nil && o.CreatedBy != *viewerID {
+		return nil, errors.ErrForbidden("you can only view your own orders")
+	}
+	list, err := s.orderRepo.ListStatusHistory(ctx, []uuid.UUID{orderID})
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load order status history", err)
+	}
+	if list == nil {
+		list = []*models.OrderStatusHistory{}
+	}
+	return list, nil
 }
 
 // validTransitions defines allowed next statuses from each current status.
@@ -234,7 +712,9 @@ func (s *orderService) canTransition(from, to models.OrderStatus) bool {
 	return false
 }
 
-func (s *orderService) UpdateStatus(ctx context.Context, orderID uuid.UUID, status models.OrderStatus) (*models.Order, error) {
+func (s *orderService) UpdateStatus(ctx context.Context, orderID uuid.UUID, status models.OrderStatus, actorID uuid.UUID, note string) (_ *models.Order, err error) {
+	ctx, span := tracing.Start(ctx, "OrderService.UpdateStatus", attribute.String("order.id", orderID.String()), attribute.String("order.status", string(status)))
+	defer tracing.End(span, &err)
 	o, err := s.orderRepo.GetByID(ctx, orderID)
 	if err != nil || o == nil {
 		return nil, errors.ErrNotFound("order")
@@ -242,41 +722,65 @@ func (s *orderService) UpdateStatus(ctx context.Context, orderID uuid.UUID, stat
 	if !s.canTransition(o.Status, status) {
 		return nil, errors.ErrValidation("invalid status transition from " + string(o.Status) + " to " + string(status))
 	}
+	if o.Status == models.OrderStatusPending && status == models.OrderStatusConfirmed && warningsPending(o) {
+		return nil, errors.ErrValidation("acknowledge the order's clinical warnings before accepting it")
+	}
+	if status != o.Status && (status == models.OrderStatusConfirmed || status == models.OrderStatusCompleted) {
+		if err := s.checkRxDispense(ctx, o, actorID); err != nil {
+			return nil, err
+		}
+	}
+	changed := o.Status != status
+	from := o.Status
 	wasCompleted := o.Status == models.OrderStatusCompleted
+	wasCancelled := o.Status == models.OrderStatusCancelled
 	o.Status = status
+	// Customer and staff points are credited by the order.completed subscribers (see event_subscribers.go).
+	var events []*models.OutboxEvent
 	if !wasCompleted && status == models.OrderStatusCompleted {
 		now := time.Now()
 		o.CompletedAt = &now
+		events = append(events, models.NewOutboxEvent(models.EventOrderCompleted, o.PharmacyID, o.ID, orderEventData(o)))
 	}
-	if err := s.orderRepo.Update(ctx, o); err != nil {
-		return nil, errors.ErrInternal("failed to update order status", err)
-	}
-	if !wasCompleted && status == models.OrderStatusCompleted {
-		if s.referralPointsSvc != nil {
-			_ = s.referralPointsSvc.OnOrderCompleted(ctx, o)
-		}
-		// Credit pharmacist/staff points for completed sale (created_by user)
-		if s.staffPointsConfigRepo != nil && s.userRepo != nil {
-			cfg, _ := s.staffPointsConfigRepo.GetOrCreateByPharmacyID(ctx, o.PharmacyID)
-			if cfg != nil && cfg.CurrencyUnitForPoints > 0 && cfg.PointsPerCurrencyUnit > 0 && o.TotalAmount > 0 {
-				units := math.Floor(o.TotalAmount / cfg.CurrencyUnitForPoints)
-				points := int(units * cfg.PointsPerCurrencyUnit)
-				if points > 0 {
-					u, err := s.userRepo.GetByID(ctx, o.CreatedBy)
-					if err == nil && u != nil {
-						u.PointsBalance += points
-						if err := s.userRepo.Update(ctx, u); err != nil {
-							s.logger.Warn("failed to credit staff points", zap.Error(err), zap.String("order_id", orderID.String()), zap.String("user_id", o.CreatedBy.String()))
-						}
-					}
-				}
+	// Cancelling releases flash sale quantity and serial numbers and returns stored value in the same transaction
+	// as the status.
+	cancelling := !wasCancelled && status == models.OrderStatusCancelled
+	err = inTx(ctx, s.uow, func(ctx context.Context) error {
+		if !changed {
+			if err := s.orderRepo.Update(ctx, o); err != nil {
+				return errors.ErrInternal("failed to update order status", err)
+			}
+		} else if err := s.orderRepo.UpdateStatus(ctx, o, s.statusChange(ctx, o, from, actorID, note), events...); err != nil {
+			return errors.ErrInternal("failed to update order status", err)
+		}
+		if cancelling && s.flashSaleSvc != nil {
+			if err := s.flashSaleSvc.ReleaseForOrder(ctx, o.ID); err != nil {
+				return err
 			}
 		}
+		if cancelling && s.serialSvc != nil {
+			if err := s.serialSvc.ReleaseOrder(ctx, o.ID); err != nil {
+				return err
+			}
+		}
+		if cancelling && (o.GiftCardAmount > 0 || o.StoreCreditAmount > 0) {
+			if err := s.reverseTender(ctx, o.ID, actorID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	updated, err := s.orderRepo.GetByID(ctx, orderID)
+	if err == nil && changed {
+		s.notifyStatusChanged(ctx, updated)
 	}
-	return s.orderRepo.GetByID(ctx, orderID)
+	return updated, err
 }
 
-func (s *orderService) Accept(ctx context.Context, orderID uuid.UUID) (*models.Order, error) {
+func (s *orderService) Accept(ctx context.Context, orderID, actorID uuid.UUID, note string) (*models.Order, error) {
 	o, err := s.orderRepo.GetByID(ctx, orderID)
 	if err != nil || o == nil {
 		return nil, errors.ErrNotFound("order")
@@ -284,9 +788,102 @@ func (s *orderService) Accept(ctx context.Context, orderID uuid.UUID) (*models.O
 	if o.Status != models.OrderStatusPending {
 		return nil, errors.ErrValidation("only pending orders can be accepted")
 	}
+	if warningsPending(o) {
+		return nil, errors.ErrValidation("acknowledge the order's clinical warnings before accepting it")
+	}
+	if err := s.checkRxDispense(ctx, o, actorID); err != nil {
+		return nil, err
+	}
 	o.Status = models.OrderStatusConfirmed
-	if err := s.orderRepo.Update(ctx, o); err != nil {
+	if err := s.orderRepo.UpdateStatus(ctx, o, s.statusChange(ctx, o, models.OrderStatusPending, actorID, note)); err != nil {
 		return nil, errors.ErrInternal("failed to accept order", err)
 	}
-	return s.orderRepo.GetByID(ctx, orderID)
+	updated, err := s.orderRepo.GetByID(ctx, orderID)
+	if err == nil {
+		s.notifyStatusChanged(ctx, updated)
+	}
+	return updated, err
+}
+
+// checkRxDispense refuses accepting or completing an order with prescription items when the actor's pharmacist
+// license has expired and the pharmacy blocks that (see LicenseComplianceService.CheckDispense).
+func (s *orderService) checkRxDispense(ctx context.Context, o *models.Order, actorID uuid.UUID) error {
+	if s.licenseSvc == nil || actorID == uuid.Nil {
+		return nil
+	}
+	for _, it := range o.Items {
+		if it.Product != nil && it.Product.RequiresRx {
+			return s.licenseSvc.CheckDispense(ctx, o.PharmacyID, actorID)
+		}
+	}
+	return nil
+}
+
+// warningsPending reports whether the order has clinical warnings nobody has acknowledged.
+func warningsPending(o *models.Order) bool {
+	return len(o.ClinicalWarnings) > 0 && o.WarningsAcknowledgedAt == nil
+}
+
+func (s *orderService) AcknowledgeWarnings(ctx context.Context, orderID, actorID uuid.UUID) (*models.Order, error) {
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil || o == nil {
+		return nil, errors.ErrNotFound("order")
+	}
+	if len(o.ClinicalWarnings) == 0 {
+		return nil, errors.ErrValidation("order has no clinical warnings")
+	}
+	if o.WarningsAcknowledgedAt != nil {
+		return o, nil
+	}
+	now := time.Now()
+	o.WarningsAcknowledgedBy = &actorID
+	o.WarningsAcknowledgedAt = &now
+	if err := s.orderRepo.Update(ctx, o); err != nil {
+		return nil, errors.ErrInternal("failed to acknowledge warnings", err)
+	}
+	return o, nil
+}
+
+// applyExpiryDiscounts prices lines at the short-expiry sale price. A failed lookup is logged and leaves prices unchanged.
+func (s *orderService) applyExpiryDiscounts(ctx context.Context, pharmacyID uuid.UUID, items []inbound.OrderItemInput, flash []*inbound.FlashSaleReservation) {
+	ids := make([]uuid.UUID, 0, len(items))
+	for i, it := range items {
+		if i < len(flash) && flash[i] != nil {
+			continue
+		}
+		ids = append(ids, it.ProductID)
+	}
+	if len(ids) == 0 {
+		return
+	}
+	offers, err := s.expiryDiscountSvc.ListOffers(ctx, pharmacyID, ids)
+	if err != nil {
+		s.logger.Warn("failed to load expiry discounts", zap.Error(err))
+		return
+	}
+	prices := make(map[uuid.UUID]float64, len(offers))
+	for _, o := range offers {
+		prices[o.ProductID] = o.SalePrice
+	}
+	for i := range items {
+		if i < len(flash) && flash[i] != nil {
+			continue
+		}
+		if price, ok := prices[items[i].ProductID]; ok {
+			items[i].UnitPrice = price
+		}
+	}
+}
+
+// notifyStatusChanged emails and texts the customer; delivery failures are logged by the notifiers and never fail the update.
+func (s *orderService) notifyStatusChanged(ctx context.Context, order *models.Order) {
+	if s.mailer != nil {
+		_ = s.mailer.OrderStatusChanged(ctx, order)
+	}
+	if s.smsNotifier != nil {
+		_ = s.smsNotifier.OrderStatusChanged(ctx, order)
+	}
+	if s.pushNotifier != nil {
+		_ = s.pushNotifier.OrderStatusChanged(ctx, order)
+	}
 }
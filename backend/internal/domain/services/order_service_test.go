@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestOrderService_ListPage_ClampsAndScopes(t *testing.T) {
+	pharmacyID, userID := uuid.New(), uuid.New()
+	var gotLimit, gotOffset int
+	var gotCreatedBy *uuid.UUID
+	repo := &mocks.MockOrderRepository{
+		ListPageFunc: func(ctx context.Context, pid uuid.UUID, createdBy *uuid.UUID, status *string, branchID *uuid.UUID, limit, offset int) ([]*models.Order, int64, error) {
+			gotCreatedBy, gotLimit, gotOffset = createdBy, limit, offset
+			return nil, 0, nil
+		},
+	}
+	svc := &orderService{orderRepo: repo, logger: zap.NewNop()}
+
+	list, total, err := svc.ListPage(context.Background(), pharmacyID, &userID, nil, nil, 500, -3)
+	if err != nil {
+		t.Fatalf("ListPage: %v", err)
+	}
+	if gotLimit != 20 || gotOffset != 0 {
+		t.Errorf("expected limit 20 offset 0, got %d %d", gotLimit, gotOffset)
+	}
+	if gotCreatedBy == nil || *gotCreatedBy != userID {
+		t.Errorf("expected list scoped to the user")
+	}
+	if list == nil || len(list) != 0 || total != 0 {
+		t.Errorf("expected an empty non-nil page, got %v %d", list, total)
+	}
+}
+
+func TestOrderService_ListMine_SummarisesOwnOrders(t *testing.T) {
+	pharmacyID, userID := uuid.New(), uuid.New()
+	placed := time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)
+	done := placed.Add(26 * time.Hour)
+	ready := &models.Order{ID: uuid.New(), OrderNumber: "ORD-1", Status: models.OrderStatusReady, TotalAmount: 450, CreatedAt: placed, UpdatedAt: placed.Add(time.Hour)}
+	completed := &models.Order{ID: uuid.New(), OrderNumber: "ORD-2", Status: models.OrderStatusCompleted, TotalAmount: 90, CreatedAt: placed, UpdatedAt: done, CompletedAt: &done}
+	var got outbound.OrderFilter
+	repo := &mocks.MockOrderRepository{
+		ListPaginatedFunc: func(ctx context.Context, pid uuid.UUID, filter outbound.OrderFilter, limit, offset int) ([]*models.Order, int64, error) {
+			got = filter
+			return []*models.Order{ready, completed}, 7, nil
+		},
+		ItemCountsFunc: func(ctx context.Context, orderIDs []uuid.UUID) ([]*models.OrderItemCountRow, error) {
+			return []*models.OrderItemCountRow{{OrderID: ready.ID, Lines: 2, Units: 5}}, nil
+		},
+	}
+	svc := &orderService{orderRepo: repo, logger: zap.NewNop()}
+	branchID := uuid.New()
+
+	list, total, err := svc.ListMine(context.Background(), pharmacyID, userID, inbound.OrderListQuery{BranchID: &branchID, OrderNumber: " ord ", Limit: 10})
+	if err != nil {
+		t.Fatalf("ListMine: %v", err)
+	}
+	if got.CreatedBy == nil || *got.CreatedBy != userID || got.BranchID != nil || got.OrderNumber != "ord" {
+		t.Errorf("filter = %+v, want own orders, no branch, trimmed search", got)
+	}
+	if total != 7 || len(list) != 2 {
+		t.Fatalf("got %d summaries of %d, want 2 of 7", len(list), total)
+	}
+	if list[0].ItemCount != 2 || list[0].UnitCount != 5 || list[1].ItemCount != 0 {
+		t.Errorf("counts = %d/%d and %d, want 2/5 and 0", list[0].ItemCount, list[0].UnitCount, list[1].ItemCount)
+	}
+	tl := list[0].Timeline
+	if len(tl) != 5 || !tl[3].Reached || tl[4].Reached || tl[3].At == nil || !tl[3].At.Equal(ready.UpdatedAt) {
+		t.Errorf("ready timeline = %+v, want reached through ready, dated at the last update", tl)
+	}
+	if at := list[1].Timeline[4].At; at == nil || !at.Equal(done) {
+		t.Errorf("completed step at %v, want %v", at, done)
+	}
+}
+
+func TestOrderService_ListPaginated_RejectsInvertedRange(t *testing.T) {
+	svc := &orderService{orderRepo: &mocks.MockOrderRepository{}, logger: zap.NewNop()}
+	now := time.Now()
+	_, _, err := svc.ListPaginated(context.Background(), uuid.New(), inbound.OrderListQuery{From: now, To: now.Add(-time.Hour)})
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("err = %v, want validation error", err)
+	}
+}
+
+func TestOrderService_UpdateStatus_RecordsHistory(t *testing.T) {
+	actorID := uuid.New()
+	o := &models.Order{ID: uuid.New(), PharmacyID: uuid.New(), Status: models.OrderStatusConfirmed}
+	var recorded *models.OrderStatusHistory
+	repo := &mocks.MockOrderRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Order, error) { return o, nil },
+		UpdateStatusFunc: func(ctx context.Context, order *models.Order, h *models.OrderStatusHistory) error {
+			recorded = h
+			return nil
+		},
+	}
+	users := &mocks.MockUserRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.User, error) {
+			return &models.User{ID: id, Name: "Asha"}, nil
+		},
+	}
+	svc := &orderService{orderRepo: repo, userRepo: users, logger: zap.NewNop()}
+
+	if _, err := svc.UpdateStatus(context.Background(), o.ID, models.OrderStatusProcessing, actorID, " packing now "); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	if recorded == nil {
+		t.Fatal("expected a status history entry")
+	}
+	if recorded.FromStatus != models.OrderStatusConfirmed || recorded.ToStatus != models.OrderStatusProcessing {
+		t.Errorf("change = %s -> %s, want confirmed -> processing", recorded.FromStatus, recorded.ToStatus)
+	}
+	if recorded.ActorID == nil || *recorded.ActorID != actorID || recorded.ActorName != "Asha" || recorded.Note != "packing now" {
+		t.Errorf("entry = %+v, want actor Asha with trimmed note", recorded)
+	}
+}
+
+func TestOrderService_History_ForbidsOtherUsersOrder(t *testing.T) {
+	pharmacyID, ownerID, otherID := uuid.New(), uuid.New(), uuid.New()
+	o := &models.Order{ID: uuid.New(), PharmacyID: pharmacyID, CreatedBy: ownerID}
+	repo := &mocks.MockOrderRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Order, error) { return o, nil },
+	}
+	svc := &orderService{orderRepo: repo, logger: zap.NewNop()}
+
+	_, err := svc.History(context.Background(), pharmacyID, o.ID, &otherID)
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeForbidden {
+		t.Fatalf("err = %v, want forbidden", err)
+	}
+	list, err := svc.History(context.Background(), pharmacyID, o.ID, &ownerID)
+	if err != nil || list == nil {
+		t.Fatalf("owner History = %v, %v, want empty list", list, err)
+	}
+}
+
+func TestOrderService_Create_RollsBackWhenStockCannotBeConsumed(t *testing.T) {
+	pharmacyID := uuid.New()
+	// Five units on hand, all expired: the order passes the stock check but consuming the batch fails.
+	f := newInventoryFixture(stockBatch{inDays(-1), 5})
+	f.product.PharmacyID = pharmacyID
+	repo := &mocks.MockOrderRepository{}
+	uow := &mocks.MockUnitOfWork{}
+	svc := &orderService{orderRepo: repo, productRepo: f.svc.productRepo, inventoryService: f.svc, uow: uow, logger: zap.NewNop()}
+
+	items := []inbound.OrderItemInput{{ProductID: f.product.ID, Quantity: 3, UnitPrice: 10}}
+	_, err := svc.Create(context.Background(), pharmacyID, uuid.New(), "Walk-in", "", "", items, "", "", nil, nil, nil, nil, nil, nil, nil)
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("err = %v, want validation error from stock consumption", err)
+	}
+	if uow.Calls != 1 || uow.RolledBack != 1 {
+		t.Errorf("transactions = %d, rolled back = %d; want the order written in one rolled-back transaction", uow.Calls, uow.RolledBack)
+	}
+	if len(repo.Events) != 1 {
+		t.Errorf("expected the order.created event to be written inside the transaction, got %d events", len(repo.Events))
+	}
+}
+
+func TestOrderService_UpdateStatus_CancelsInOneTransaction(t *testing.T) {
+	o := &models.Order{ID: uuid.New(), PharmacyID: uuid.New(), Status: models.OrderStatusPending}
+	repo := &mocks.MockOrderRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Order, error) { return o, nil },
+	}
+	uow := &mocks.MockUnitOfWork{}
+	svc := &orderService{orderRepo: repo, userRepo: &mocks.MockUserRepository{}, uow: uow, logger: zap.NewNop()}
+
+	if _, err := svc.UpdateStatus(context.Background(), o.ID, models.OrderStatusCancelled, uuid.New(), ""); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	if uow.Calls != 1 || uow.RolledBack != 0 {
+		t.Errorf("transactions = %d, rolled back = %d; want one committed transaction", uow.Calls, uow.RolledBack)
+	}
+}
+
+func TestOrderService_Accept_RequiresAcknowledgedWarnings(t *testing.T) {
+	actorID := uuid.New()
+	o := &models.Order{ID: uuid.New(), Status: models.OrderStatusPending, ClinicalWarnings: []models.ClinicalWarning{{Type: models.ClinicalWarningDuplicateTherapy, Severity: models.InteractionSeverityModerate}}}
+	repo := &mocks.MockOrderRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Order, error) { return o, nil },
+		UpdateFunc:  func(ctx context.Context, order *models.Order) error { return nil },
+		UpdateStatusFunc: func(ctx context.Context, order *models.Order, h *models.OrderStatusHistory) error {
+			return nil
+		},
+	}
+	svc := &orderService{orderRepo: repo, userRepo: &mocks.MockUserRepository{}, logger: zap.NewNop()}
+
+	if _, err := svc.Accept(context.Background(), o.ID, actorID, ""); err == nil {
+		t.Fatal("expected accept to fail while warnings are unacknowledged")
+	}
+	if _, err := svc.UpdateStatus(context.Background(), o.ID, models.OrderStatusConfirmed, actorID, ""); err == nil {
+		t.Fatal("expected confirming via status update to fail while warnings are unacknowledged")
+	}
+	if _, err := svc.AcknowledgeWarnings(context.Background(), o.ID, actorID); err != nil {
+		t.Fatalf("AcknowledgeWarnings: %v", err)
+	}
+	if o.WarningsAcknowledgedBy == nil || *o.WarningsAcknowledgedBy != actorID {
+		t.Errorf("expected acknowledgement by the actor")
+	}
+	if _, err := svc.Accept(context.Background(), o.ID, actorID, ""); err != nil {
+		t.Fatalf("Accept after acknowledgement: %v", err)
+	}
+	if o.Status != models.OrderStatusConfirmed {
+		t.Errorf("expected confirmed, got %s", o.Status)
+	}
+}
+
+func TestOrderService_Create_WalkInSkipsFlashSalePerCustomerLimit(t *testing.T) {
+	pharmacyID := uuid.New()
+	f := newInventoryFixture(stockBatch{inDays(90), 10})
+	f.product.PharmacyID = pharmacyID
+	item := liveFlashItem(f.product.ID, 6, 0, 1)
+	flashRepo := &mocks.MockFlashSaleRepository{
+		ListLiveItemsFunc: func(ctx context.Context, pid uuid.UUID, productIDs []uuid.UUID, now, startsBy time.Time) ([]*models.FlashSaleItem, error) {
+			return []*models.FlashSaleItem{item}, nil
+		},
+		ReserveCustomerQuantityFunc: func(ctx context.Context, itemID uuid.UUID, customerKey string, qty, limit int) (bool, error) {
+			t.Errorf("per-customer limit checked for key %q on a walk-in order", customerKey)
+			return true, nil
+		},
+	}
+	reserved := 0
+	flashRepo.ReserveItemFunc = func(ctx context.Context, itemID uuid.UUID, qty int) (bool, error) {
+		reserved += qty
+		return true, nil
+	}
+	svc := &orderService{orderRepo: &mocks.MockOrderRepository{}, productRepo: f.svc.productRepo, inventoryService: f.svc, flashSaleSvc: NewFlashSaleService(flashRepo, f.svc.productRepo, zap.NewNop()), logger: zap.NewNop()}
+
+	items := []inbound.OrderItemInput{{ProductID: f.product.ID, Quantity: 3, UnitPrice: 10}}
+	if _, err := svc.Create(context.Background(), pharmacyID, uuid.New(), "Walk-in", "", "", items, "", "", nil, nil, nil, nil, nil, nil, nil); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if reserved != 3 {
+		t.Errorf("reserved %d sale units, want 3 (total cap still applies)", reserved)
+	}
+}
+
+func TestOrderService_ApplyTender_RejectsGiftCardInAnotherCurrency(t *testing.T) {
+	card := &models.GiftCard{ID: uuid.New(), Code: "GIFT1234", Balance: 100, Currency: "NPR", IsActive: true}
+	redeemed := 0
+	repo := &mocks.MockGiftCardRepository{
+		GetByCodeFunc: func(ctx context.Context, pharmacyID uuid.UUID, code string) (*models.GiftCard, error) {
+			return card, nil
+		},
+		AdjustFunc: func(ctx context.Context, t *models.GiftCardTransaction) (bool, error) {
+			redeemed++
+			return true, nil
+		},
+	}
+	svc := &orderService{giftCardSvc: &giftCardService{repo: repo, logger: zap.NewNop()}, logger: zap.NewNop()}
+	tender := &inbound.OrderTender{GiftCardCode: "GIFT1234"}
+
+	_, _, _, err := svc.applyTender(context.Background(), uuid.New(), uuid.New(), uuid.New(), nil, tender, 80, "USD")
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("err = %v, want validation error for an NPR card on a USD order", err)
+	}
+	if redeemed != 0 {
+		t.Error("card redeemed although its currency differs from the order's")
+	}
+	if _, amount, _, err := svc.applyTender(context.Background(), uuid.New(), uuid.New(), uuid.New(), nil, tender, 80, "NPR"); err != nil || amount != 80 {
+		t.Errorf("same currency: amount = %v, err = %v; want 80 taken from the card", amount, err)
+	}
+}
+
+func TestOrderService_Create_RejectsLinePricedInAnotherCurrency(t *testing.T) {
+	pharmacyID := uuid.New()
+	f := newInventoryFixture(stockBatch{inDays(90), 10})
+	f.product.PharmacyID, f.product.Currency = pharmacyID, "USD"
+	repo := &mocks.MockOrderRepository{}
+	svc := &orderService{orderRepo: repo, productRepo: f.svc.productRepo, inventoryService: f.svc, logger: zap.NewNop()}
+	items := []inbound.OrderItemInput{{ProductID: f.product.ID, Quantity: 1, UnitPrice: 10}}
+
+	_, err := svc.Create(context.Background(), pharmacyID, uuid.New(), "Walk-in", "", "", items, "", "", nil, nil, nil, nil, nil, nil, nil)
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("err = %v, want validation error for a USD product in an NPR pharmacy", err)
+	}
+	if len(repo.Events) != 0 {
+		t.Error("order written although a line is in another currency")
+	}
+
+	f.product.Currency = "NPR"
+	if _, err := svc.Create(context.Background(), pharmacyID, uuid.New(), "Walk-in", "", "", items, "", "", nil, nil, nil, nil, nil, nil, nil); err != nil {
+		t.Errorf("base-currency product: %v", err)
+	}
+}
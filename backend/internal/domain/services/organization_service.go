@@ -0,0 +1,396 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	organizationJoinCodeLen   = 10
+	organizationDashboardTop  = 5
+	organizationStockMaxLimit = 200
+	organizationStockDefLimit = 50
+)
+
+type organizationService struct {
+	repo         outbound.OrganizationRepository
+	pharmacyRepo outbound.PharmacyRepository
+	userRepo     outbound.UserRepository
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+func NewOrganizationService(repo outbound.OrganizationRepository, pharmacyRepo outbound.PharmacyRepository, userRepo outbound.UserRepository, logger *zap.Logger) inbound.OrganizationService {
+	return &organizationService{repo: repo, pharmacyRepo: pharmacyRepo, userRepo: userRepo, logger: logger, now: time.Now}
+}
+
+func (s *organizationService) Create(ctx context.Context, pharmacyID, userID uuid.UUID, input inbound.OrganizationInput) (*models.Organization, error) {
+	name := strings.TrimSpace(input.Name)
+	code := strings.ToUpper(strings.TrimSpace(input.Code))
+	if name == "" || code == "" {
+		return nil, errors.ErrValidation("name and code are required")
+	}
+	p, err := s.pharmacyRepo.GetByID(ctx, pharmacyID)
+	if err != nil || p == nil {
+		return nil, errors.ErrNotFound("pharmacy")
+	}
+	if p.OrganizationID != nil {
+		return nil, errors.ErrConflict("this pharmacy already belongs to an organization")
+	}
+	if existing, err := s.repo.GetByCode(ctx, code); err != nil {
+		return nil, errors.ErrInternal("failed to check organization code", err)
+	} else if existing != nil {
+		return nil, errors.ErrConflict("organization code is already taken")
+	}
+	joinCode, err := s.joinCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	o := &models.Organization{Name: name, Code: code, JoinCode: joinCode, CreatedBy: userID}
+	owner := &models.OrganizationMember{UserID: userID, Role: models.OrganizationOwner, AddedBy: userID}
+	if err := s.repo.Create(ctx, o, owner, p.ID); err != nil {
+		return nil, errors.ErrInternal("failed to create organization", err)
+	}
+	return s.repo.GetByID(ctx, o.ID)
+}
+
+func (s *organizationService) Join(ctx context.Context, pharmacyID, userID uuid.UUID, joinCode string) (*models.Organization, error) {
+	joinCode = strings.ToUpper(strings.TrimSpace(joinCode))
+	if joinCode == "" {
+		return nil, errors.ErrValidation("join code is required")
+	}
+	p, err := s.pharmacyRepo.GetByID(ctx, pharmacyID)
+	if err != nil || p == nil {
+		return nil, errors.ErrNotFound("pharmacy")
+	}
+	if p.OrganizationID != nil {
+		return nil, errors.ErrConflict("this pharmacy already belongs to an organization")
+	}
+	o, err := s.repo.GetByJoinCode(ctx, joinCode)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load organization", err)
+	}
+	if o == nil {
+		return nil, errors.ErrNotFound("organization")
+	}
+	if err := s.repo.LinkPharmacy(ctx, p.ID, o.ID, o.Code); err != nil {
+		return nil, errors.ErrInternal("failed to join organization", err)
+	}
+	if m, err := s.repo.GetMember(ctx, o.ID, userID); err == nil && m == nil {
+		if err := s.repo.AddMember(ctx, &models.OrganizationMember{OrganizationID: o.ID, UserID: userID, Role: models.OrganizationViewer, AddedBy: userID}); err != nil {
+			s.logger.Warn("failed to add joining user to organization", zap.String("organization_id", o.ID.String()), zap.Error(err))
+		}
+	}
+	s.logger.Info("pharmacy joined organization", zap.String("organization_id", o.ID.String()), zap.String("pharmacy_id", p.ID.String()))
+	o, err = s.repo.GetByID(ctx, o.ID)
+	if err != nil || o == nil {
+		return nil, errors.ErrInternal("failed to load organization", err)
+	}
+	o.JoinCode = ""
+	return o, nil
+}
+
+func (s *organizationService) ListMine(ctx context.Context, userID uuid.UUID) ([]*models.Organization, error) {
+	list, err := s.repo.ListByMember(ctx, userID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list organizations", err)
+	}
+	for _, o := range list {
+		if m, err := s.repo.GetMember(ctx, o.ID, userID); err != nil || m == nil || m.Role != models.OrganizationOwner {
+			o.JoinCode = ""
+		}
+	}
+	return list, nil
+}
+
+func (s *organizationService) Get(ctx context.Context, organizationID, userID uuid.UUID) (*models.Organization, error) {
+	o, err := s.access(ctx, organizationID, userID, false)
+	return o, err
+}
+
+func (s *organizationService) RemovePharmacy(ctx context.Context, organizationID, userID, pharmacyID uuid.UUID) error {
+	o, err := s.access(ctx, organizationID, userID, true)
+	if err != nil {
+		return err
+	}
+	if _, err := tenantScope(o, &pharmacyID); err != nil {
+		return err
+	}
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err == nil && u != nil && u.PharmacyID == pharmacyID {
+		return errors.ErrValidation("owners cannot remove their own pharmacy")
+	}
+	if err := s.repo.UnlinkPharmacy(ctx, pharmacyID, o.ID); err != nil {
+		return errors.ErrInternal("failed to remove pharmacy", err)
+	}
+	return nil
+}
+
+func (s *organizationService) ListMembers(ctx context.Context, organizationID, userID uuid.UUID) ([]*models.OrganizationMember, error) {
+	o, err := s.access(ctx, organizationID, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.repo.ListMembers(ctx, o.ID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list members", err)
+	}
+	return list, nil
+}
+
+func (s *organizationService) AddMember(ctx context.Context, organizationID, userID uuid.UUID, input inbound.OrganizationMemberInput) (*models.OrganizationMember, error) {
+	o, err := s.access(ctx, organizationID, userID, true)
+	if err != nil {
+		return nil, err
+	}
+	role := input.Role
+	if role == "" {
+		role = models.OrganizationViewer
+	}
+	u, err := s.userRepo.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(input.Email)))
+	if err != nil || u == nil || !u.IsActive {
+		return nil, errors.ErrNotFound("user")
+	}
+	if _, err := tenantScope(o, &u.PharmacyID); err != nil {
+		return nil, errors.ErrValidation("members must be users of one of the organization's pharmacies")
+	}
+	if existing, err := s.repo.GetMember(ctx, o.ID, u.ID); err != nil {
+		return nil, errors.ErrInternal("failed to check membership", err)
+	} else if existing != nil {
+		return nil, errors.ErrConflict("user is already a member")
+	}
+	m := &models.OrganizationMember{OrganizationID: o.ID, UserID: u.ID, Role: role, AddedBy: userID}
+	if err := s.repo.AddMember(ctx, m); err != nil {
+		return nil, errors.ErrInternal("failed to add member", err)
+	}
+	m.User = u
+	return m, nil
+}
+
+func (s *organizationService) RemoveMember(ctx context.Context, organizationID, userID, memberUserID uuid.UUID) error {
+	o, err := s.access(ctx, organizationID, userID, true)
+	if err != nil {
+		return err
+	}
+	members, err := s.repo.ListMembers(ctx, o.ID)
+	if err != nil {
+		return errors.ErrInternal("failed to list members", err)
+	}
+	var target *models.OrganizationMember
+	owners := 0
+	for _, m := range members {
+		if m.UserID == memberUserID {
+			target = m
+		}
+		if m.Role == models.OrganizationOwner {
+			owners++
+		}
+	}
+	if target == nil {
+		return errors.ErrNotFound("member")
+	}
+	if target.Role == models.OrganizationOwner && owners == 1 {
+		return errors.ErrValidation("the last owner cannot be removed")
+	}
+	if err := s.repo.RemoveMember(ctx, o.ID, memberUserID); err != nil {
+		return errors.ErrInternal("failed to remove member", err)
+	}
+	return nil
+}
+
+func (s *organizationService) Dashboard(ctx context.Context, organizationID, userID uuid.UUID, from, to time.Time, pharmacyID *uuid.UUID) (*inbound.OrganizationDashboard, error) {
+	o, err := s.access(ctx, organizationID, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := tenantScope(o, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	if from, to, err = normalizeRange(from, to); err != nil {
+		return nil, err
+	}
+	tenants, err := s.repo.SalesByTenant(ctx, ids, from, to)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load sales by pharmacy", err)
+	}
+	top, err := s.repo.TopProducts(ctx, ids, from, to, organizationDashboardTop)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load top products", err)
+	}
+	d := &inbound.OrganizationDashboard{From: from, To: to, Tenants: tenants, TopProducts: top}
+	for _, t := range tenants {
+		d.OrdersCount += t.OrdersCount
+		d.Revenue += t.Revenue
+	}
+	for _, t := range tenants {
+		if d.Revenue > 0 {
+			t.Share = math.Round(t.Revenue/d.Revenue*10000) / 100
+		}
+	}
+	return d, nil
+}
+
+func (s *organizationService) Sales(ctx context.Context, organizationID, userID uuid.UUID, granularity string, from, to time.Time, pharmacyID *uuid.UUID) (*inbound.SalesSummaryReport, error) {
+	switch granularity {
+	case "":
+		granularity = "day"
+	case "day", "week", "month":
+	default:
+		return nil, errors.ErrValidation("granularity must be day, week or month")
+	}
+	o, err := s.access(ctx, organizationID, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := tenantScope(o, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	if from, to, err = normalizeRange(from, to); err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.SalesByPeriod(ctx, ids, granularity, from, to)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load sales summary", err)
+	}
+	report := &inbound.SalesSummaryReport{Granularity: granularity, From: from, To: to, Rows: rows}
+	for _, r := range rows {
+		report.TotalOrders += r.OrdersCount
+		report.TotalRevenue += r.Revenue
+	}
+	return report, nil
+}
+
+func (s *organizationService) TopProducts(ctx context.Context, organizationID, userID uuid.UUID, from, to time.Time, pharmacyID *uuid.UUID, limit int) ([]*models.OrgTopProductRow, error) {
+	o, err := s.access(ctx, organizationID, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := tenantScope(o, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	if from, to, err = normalizeRange(from, to); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	rows, err := s.repo.TopProducts(ctx, ids, from, to, limit)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load top products", err)
+	}
+	return rows, nil
+}
+
+func (s *organizationService) Stock(ctx context.Context, organizationID, userID uuid.UUID, query string, pharmacyID *uuid.UUID, limit int) ([]*inbound.OrganizationStockItem, error) {
+	o, err := s.access(ctx, organizationID, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := tenantScope(o, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > organizationStockMaxLimit {
+		limit = organizationStockDefLimit
+	}
+	rows, err := s.repo.Stock(ctx, ids, strings.TrimSpace(query), s.now(), limit)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load stock", err)
+	}
+	// Rows come grouped by product; products of different tenants share an item when their SKU (or, without
+	// one, their name) matches.
+	items := []*inbound.OrganizationStockItem{}
+	byKey := map[string]*inbound.OrganizationStockItem{}
+	for _, r := range rows {
+		key := r.SKU
+		if key == "" {
+			key = strings.ToLower(r.Name)
+		}
+		it, ok := byKey[key]
+		if !ok {
+			it = &inbound.OrganizationStockItem{SKU: r.SKU, Name: r.Name}
+			byKey[key] = it
+			items = append(items, it)
+		}
+		it.Quantity += r.Quantity
+		it.Value += r.Value
+		it.Locations = append(it.Locations, r)
+	}
+	return items, nil
+}
+
+// access loads the organization for a member. Non-members get not found so organization ids are not
+// confirmed to outsiders; ownerOnly turns away viewers.
+func (s *organizationService) access(ctx context.Context, organizationID, userID uuid.UUID, ownerOnly bool) (*models.Organization, error) {
+	m, err := s.repo.GetMember(ctx, organizationID, userID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to check membership", err)
+	}
+	if m == nil {
+		return nil, errors.ErrNotFound("organization")
+	}
+	if ownerOnly && m.Role != models.OrganizationOwner {
+		return nil, errors.ErrForbidden("only organization owners can do this")
+	}
+	o, err := s.repo.GetByID(ctx, organizationID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load organization", err)
+	}
+	if o == nil {
+		return nil, errors.ErrNotFound("organization")
+	}
+	if m.Role != models.OrganizationOwner {
+		o.JoinCode = ""
+	}
+	return o, nil
+}
+
+// tenantScope returns the pharmacies a report covers: all of the organization's tenants, or the one asked for
+// when drilling down, which must be a tenant.
+func tenantScope(o *models.Organization, pharmacyID *uuid.UUID) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(o.Pharmacies))
+	for _, p := range o.Pharmacies {
+		if pharmacyID != nil && p.ID == *pharmacyID {
+			return []uuid.UUID{p.ID}, nil
+		}
+		ids = append(ids, p.ID)
+	}
+	if pharmacyID != nil {
+		return nil, errors.ErrNotFound("pharmacy")
+	}
+	if len(ids) == 0 {
+		return nil, errors.ErrValidation("organization has no pharmacies")
+	}
+	return ids, nil
+}
+
+func (s *organizationService) joinCode(ctx context.Context) (string, error) {
+	for i := 0; i < 5; i++ {
+		var b strings.Builder
+		for j := 0; j < organizationJoinCodeLen; j++ {
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(referralCodeChars))))
+			if err != nil {
+				return "", errors.ErrInternal("failed to generate join code", err)
+			}
+			b.WriteByte(referralCodeChars[n.Int64()])
+		}
+		if existing, err := s.repo.GetByJoinCode(ctx, b.String()); err == nil && existing == nil {
+			return b.String(), nil
+		}
+	}
+	return "", errors.ErrInternal("failed to generate a unique join code", nil)
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// organizationFixture is an organization of two tenants with one owner and one viewer.
+type organizationFixture struct {
+	org           *models.Organization
+	tenantA       uuid.UUID
+	tenantB       uuid.UUID
+	owner, viewer uuid.UUID
+	members       map[uuid.UUID]*models.OrganizationMember
+	users         map[string]*models.User
+	scopes        [][]uuid.UUID
+	repo          *mocks.MockOrganizationRepository
+	svc           inbound.OrganizationService
+}
+
+func newOrganizationFixture() *organizationFixture {
+	f := &organizationFixture{tenantA: uuid.New(), tenantB: uuid.New(), owner: uuid.New(), viewer: uuid.New(), users: map[string]*models.User{}}
+	f.org = &models.Organization{ID: uuid.New(), Name: "Care Chain", Code: "CARE", JoinCode: "JOINME2345",
+		Pharmacies: []models.Pharmacy{{ID: f.tenantA, Name: "Care Baneshwor"}, {ID: f.tenantB, Name: "Care Lalitpur"}}}
+	f.members = map[uuid.UUID]*models.OrganizationMember{
+		f.owner:  {OrganizationID: f.org.ID, UserID: f.owner, Role: models.OrganizationOwner},
+		f.viewer: {OrganizationID: f.org.ID, UserID: f.viewer, Role: models.OrganizationViewer},
+	}
+	f.repo = &mocks.MockOrganizationRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Organization, error) {
+			if id != f.org.ID {
+				return nil, nil
+			}
+			o := *f.org
+			return &o, nil
+		},
+		GetMemberFunc: func(ctx context.Context, organizationID, userID uuid.UUID) (*models.OrganizationMember, error) {
+			if organizationID != f.org.ID {
+				return nil, nil
+			}
+			return f.members[userID], nil
+		},
+		ListMembersFunc: func(ctx context.Context, organizationID uuid.UUID) ([]*models.OrganizationMember, error) {
+			var list []*models.OrganizationMember
+			for _, m := range f.members {
+				list = append(list, m)
+			}
+			return list, nil
+		},
+		AddMemberFunc: func(ctx context.Context, m *models.OrganizationMember) error {
+			f.members[m.UserID] = m
+			return nil
+		},
+		SalesByTenantFunc: func(ctx context.Context, pharmacyIDs []uuid.UUID, from, to time.Time) ([]*models.OrgTenantSalesRow, error) {
+			f.scopes = append(f.scopes, pharmacyIDs)
+			rows := []*models.OrgTenantSalesRow{}
+			for _, id := range pharmacyIDs {
+				revenue := 3000.0
+				if id == f.tenantB {
+					revenue = 1000
+				}
+				rows = append(rows, &models.OrgTenantSalesRow{PharmacyID: id, OrdersCount: 10, Revenue: revenue})
+			}
+			return rows, nil
+		},
+		StockFunc: func(ctx context.Context, pharmacyIDs []uuid.UUID, query string, today time.Time, limit int) ([]*models.OrgStockRow, error) {
+			return []*models.OrgStockRow{
+				{SKU: "PCM-500", Name: "Paracetamol 500mg", PharmacyID: f.tenantA, Quantity: 40, Value: 200},
+				{SKU: "PCM-500", Name: "Paracetamol 500 mg", PharmacyID: f.tenantB, Quantity: 10, Value: 55},
+				{SKU: "", Name: "Cotton Roll", PharmacyID: f.tenantA, Quantity: 5, Value: 50},
+				{SKU: "", Name: "cotton roll", PharmacyID: f.tenantB, Quantity: 2, Value: 20},
+			}, nil
+		},
+	}
+	userRepo := &mocks.MockUserRepository{
+		GetByEmailFunc: func(ctx context.Context, email string) (*models.User, error) {
+			return f.users[email], nil
+		},
+	}
+	f.svc = NewOrganizationService(f.repo, &mocks.MockPharmacyRepository{}, userRepo, zap.NewNop())
+	return f
+}
+
+func TestOrganizationService_Dashboard_MembersOnlyWithTenantDrillDown(t *testing.T) {
+	f := newOrganizationFixture()
+	ctx := context.Background()
+
+	_, err := f.svc.Dashboard(ctx, f.org.ID, uuid.New(), time.Time{}, time.Time{}, nil)
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeNotFound {
+		t.Fatalf("err = %v, want not found for a non-member", err)
+	}
+
+	d, err := f.svc.Dashboard(ctx, f.org.ID, f.viewer, time.Time{}, time.Time{}, nil)
+	if err != nil {
+		t.Fatalf("Dashboard: %v", err)
+	}
+	if d.OrdersCount != 20 || d.Revenue != 4000 || len(d.Tenants) != 2 || d.Tenants[0].Share != 75 || d.Tenants[1].Share != 25 {
+		t.Errorf("dashboard = %+v, want 20 orders, 4000 revenue split 75/25", d)
+	}
+
+	if _, err := f.svc.Dashboard(ctx, f.org.ID, f.viewer, time.Time{}, time.Time{}, &f.tenantB); err != nil {
+		t.Fatalf("Dashboard drill-down: %v", err)
+	}
+	if last := f.scopes[len(f.scopes)-1]; len(last) != 1 || last[0] != f.tenantB {
+		t.Errorf("drill-down scope = %v, want only %v", last, f.tenantB)
+	}
+	outsider := uuid.New()
+	_, err = f.svc.Dashboard(ctx, f.org.ID, f.viewer, time.Time{}, time.Time{}, &outsider)
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeNotFound {
+		t.Errorf("err = %v, want not found when drilling into a pharmacy outside the organization", err)
+	}
+
+	o, err := f.svc.Get(ctx, f.org.ID, f.viewer)
+	if err != nil || o.JoinCode != "" {
+		t.Errorf("viewer sees join code %q (err %v), want it hidden", o.JoinCode, err)
+	}
+}
+
+func TestOrganizationService_StockAndMembers(t *testing.T) {
+	f := newOrganizationFixture()
+	ctx := context.Background()
+
+	items, err := f.svc.Stock(ctx, f.org.ID, f.viewer, "", nil, 0)
+	if err != nil {
+		t.Fatalf("Stock: %v", err)
+	}
+	if len(items) != 2 || items[0].Quantity != 50 || items[0].Value != 255 || len(items[0].Locations) != 2 || items[1].Quantity != 7 {
+		t.Errorf("items = %+v, want paracetamol (50 units over 2 tenants) and cotton roll (7) matched by SKU and name", items)
+	}
+
+	f.users["rita@example.com"] = &models.User{ID: uuid.New(), PharmacyID: f.tenantB, IsActive: true}
+	f.users["stranger@example.com"] = &models.User{ID: uuid.New(), PharmacyID: uuid.New(), IsActive: true}
+	if _, err := f.svc.AddMember(ctx, f.org.ID, f.viewer, inbound.OrganizationMemberInput{Email: "rita@example.com"}); err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeForbidden {
+		t.Errorf("err = %v, want forbidden for a viewer adding members", err)
+	}
+	if _, err := f.svc.AddMember(ctx, f.org.ID, f.owner, inbound.OrganizationMemberInput{Email: "stranger@example.com"}); err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("err = %v, want validation error for a user outside the tenants", err)
+	}
+	m, err := f.svc.AddMember(ctx, f.org.ID, f.owner, inbound.OrganizationMemberInput{Email: "rita@example.com"})
+	if err != nil || m.Role != models.OrganizationViewer {
+		t.Fatalf("AddMember = %+v, %v; want a viewer", m, err)
+	}
+
+	err = f.svc.RemoveMember(ctx, f.org.ID, f.owner, f.owner)
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("err = %v, want validation error for removing the last owner", err)
+	}
+}
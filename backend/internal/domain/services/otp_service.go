@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	otpTTL            = 10 * time.Minute
+	otpResendInterval = time.Minute
+	otpMaxPerHour     = 5
+	otpMaxAttempts    = 5
+)
+
+var otpPurposes = map[string]bool{
+	models.OTPPurposePhoneVerification: true,
+}
+
+type otpService struct {
+	otpRepo      outbound.OTPRepository
+	smsSender    outbound.SMSSender
+	configRepo   outbound.PharmacyConfigRepository
+	pharmacyRepo outbound.PharmacyRepository
+	logger       *zap.Logger
+}
+
+func NewOTPService(otpRepo outbound.OTPRepository, smsSender outbound.SMSSender, configRepo outbound.PharmacyConfigRepository, pharmacyRepo outbound.PharmacyRepository, logger *zap.Logger) inbound.OTPService {
+	return &otpService{otpRepo: otpRepo, smsSender: smsSender, configRepo: configRepo, pharmacyRepo: pharmacyRepo, logger: logger}
+}
+
+func (s *otpService) Send(ctx context.Context, pharmacyID uuid.UUID, phone, purpose string) (*inbound.OTPSendResult, error) {
+	phone, purpose, err := normalizeOTPRequest(phone, purpose)
+	if err != nil {
+		return nil, err
+	}
+	name, enabled := smsSettings(ctx, s.configRepo, s.pharmacyRepo, pharmacyID, models.FeatureSMSOTP)
+	if !enabled || s.smsSender == nil {
+		return nil, errors.ErrForbidden("SMS verification is not enabled for this pharmacy")
+	}
+	now := time.Now()
+	last, err := s.otpRepo.GetLatest(ctx, pharmacyID, phone, purpose)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load otp", err)
+	}
+	if last != nil && now.Sub(last.CreatedAt) < otpResendInterval {
+		return nil, errors.ErrTooManyRequests("please wait a minute before requesting another code")
+	}
+	sent, err := s.otpRepo.CountSince(ctx, pharmacyID, phone, now.Add(-time.Hour))
+	if err != nil {
+		return nil, errors.ErrInternal("failed to count otps", err)
+	}
+	if sent >= otpMaxPerHour {
+		return nil, errors.ErrTooManyRequests("too many codes requested, try again later")
+	}
+	code, err := randomOTP()
+	if err != nil {
+		return nil, errors.ErrInternal("failed to generate otp", err)
+	}
+	otp := &models.OTPCode{
+		ID:         uuid.New(),
+		PharmacyID: pharmacyID,
+		Phone:      phone,
+		Purpose:    purpose,
+		ExpiresAt:  now.Add(otpTTL),
+	}
+	otp.CodeHash = hashOTP(otp.ID, code)
+	if err := s.otpRepo.Create(ctx, otp); err != nil {
+		return nil, errors.ErrInternal("failed to save otp", err)
+	}
+	body := fmt.Sprintf("%s: your verification code is %s. It expires in %d minutes.", name, code, int(otpTTL.Minutes()))
+	if err := s.smsSender.Send(ctx, &outbound.SMSMessage{PharmacyID: pharmacyID, To: phone, Body: body}); err != nil {
+		s.logger.Warn("failed to send otp sms", zap.Error(err))
+		return nil, errors.ErrInternal("failed to send verification code", err)
+	}
+	return &inbound.OTPSendResult{ExpiresAt: otp.ExpiresAt}, nil
+}
+
+func (s *otpService) Verify(ctx context.Context, pharmacyID uuid.UUID, phone, purpose, code string) error {
+	phone, purpose, err := normalizeOTPRequest(phone, purpose)
+	if err != nil {
+		return err
+	}
+	otp, err := s.otpRepo.GetLatest(ctx, pharmacyID, phone, purpose)
+	if err != nil {
+		return errors.ErrInternal("failed to load otp", err)
+	}
+	if otp == nil || otp.ConsumedAt != nil || time.Now().After(otp.ExpiresAt) {
+		return errors.ErrValidation("code is invalid or has expired")
+	}
+	if otp.Attempts >= otpMaxAttempts {
+		return errors.ErrTooManyRequests("too many attempts, request a new code")
+	}
+	given := hashOTP(otp.ID, strings.TrimSpace(code))
+	if subtle.ConstantTimeCompare([]byte(given), []byte(otp.CodeHash)) != 1 {
+		otp.Attempts++
+		if err := s.otpRepo.Update(ctx, otp); err != nil {
+			return errors.ErrInternal("failed to update otp", err)
+		}
+		return errors.ErrValidation("code is invalid or has expired")
+	}
+	now := time.Now()
+	otp.ConsumedAt = &now
+	if err := s.otpRepo.Update(ctx, otp); err != nil {
+		return errors.ErrInternal("failed to update otp", err)
+	}
+	return nil
+}
+
+func normalizeOTPRequest(phone, purpose string) (string, string, error) {
+	phone = strings.TrimSpace(phone)
+	if phone == "" {
+		return "", "", errors.ErrValidation("phone is required")
+	}
+	if purpose == "" {
+		purpose = models.OTPPurposePhoneVerification
+	}
+	if !otpPurposes[purpose] {
+		return "", "", errors.ErrValidation("unknown otp purpose")
+	}
+	return phone, purpose, nil
+}
+
+// randomOTP returns a zero-padded 6-digit code.
+func randomOTP() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// hashOTP salts the code with the row id so equal codes never share a hash.
+func hashOTP(id uuid.UUID, code string) string {
+	sum := sha256.Sum256([]byte(id.String() + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/careplus/pharmacy-backend/pkg/tracing"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -21,12 +22,14 @@ func NewPaymentService(repo outbound.PaymentRepository, logger *zap.Logger) inbo
 	return &paymentService{repo: repo, logger: logger}
 }
 
-func (s *paymentService) Create(ctx context.Context, p *models.Payment) error {
+func (s *paymentService) Create(ctx context.Context, p *models.Payment) (err error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.Create")
+	defer tracing.End(span, &err)
 	if p.Amount <= 0 {
 		return errors.ErrValidation("amount must be positive")
 	}
 	if p.Currency == "" {
-		p.Currency = "NPR"
+		p.Currency = models.DefaultCurrency
 	}
 	p.Status = models.PaymentStatusPending
 	return s.repo.Create(ctx, p)
@@ -55,5 +58,7 @@ func (s *paymentService) Complete(ctx context.Context, paymentID uuid.UUID) erro
 	now := time.Now()
 	p.Status = models.PaymentStatusCompleted
 	p.PaidAt = &now
-	return s.repo.Update(ctx, p)
+	return s.repo.Update(ctx, p, models.NewOutboxEvent(models.EventPaymentCompleted, p.PharmacyID, p.ID, models.PaymentEventData{
+		PaymentID: p.ID, OrderID: p.OrderID, Amount: p.Amount, Method: p.Method,
+	}))
 }
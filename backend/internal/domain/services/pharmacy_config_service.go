@@ -15,12 +15,16 @@ import (
 
 type pharmacyConfigService struct {
 	configRepo   outbound.PharmacyConfigRepository
+	versionRepo  outbound.PharmacyConfigVersionRepository
 	pharmacyRepo outbound.PharmacyRepository
+	subsRepo     outbound.SubscriptionRepository
 	logger       *zap.Logger
 }
 
-func NewPharmacyConfigService(configRepo outbound.PharmacyConfigRepository, pharmacyRepo outbound.PharmacyRepository, logger *zap.Logger) inbound.PharmacyConfigService {
-	return &pharmacyConfigService{configRepo: configRepo, pharmacyRepo: pharmacyRepo, logger: logger}
+// NewPharmacyConfigService builds the config service; subsRepo (may be nil) narrows the app config's feature flags
+// to the pharmacy's plan.
+func NewPharmacyConfigService(configRepo outbound.PharmacyConfigRepository, versionRepo outbound.PharmacyConfigVersionRepository, pharmacyRepo outbound.PharmacyRepository, subsRepo outbound.SubscriptionRepository, logger *zap.Logger) inbound.PharmacyConfigService {
+	return &pharmacyConfigService{configRepo: configRepo, versionRepo: versionRepo, pharmacyRepo: pharmacyRepo, subsRepo: subsRepo, logger: logger}
 }
 
 func (s *pharmacyConfigService) GetByPharmacyID(ctx context.Context, pharmacyID uuid.UUID) (*models.PharmacyConfig, error) {
@@ -74,19 +78,21 @@ func (s *pharmacyConfigService) GetAppConfigByHostname(ctx context.Context, host
 		return nil, err
 	}
 	resp := &inbound.AppConfigResponse{
-		CompanyName:    cfg.DisplayName,
-		DefaultTheme:   cfg.PrimaryColor,
-		Language:       cfg.DefaultLanguage,
-		Address:        cfg.Location,
-		TenantCode:     pharmacy.TenantCode,
-		PharmacyID:     pharmacy.ID.String(),
-		BusinessType:   pharmacy.BusinessType,
-		WebsiteEnabled: cfg.WebsiteEnabled,
-		Features:       cfg.FeatureFlags,
-		LogoURL:        cfg.LogoURL,
-		Tagline:        cfg.Tagline,
-		ContactPhone:   cfg.ContactPhone,
-		ContactEmail:   cfg.ContactEmail,
+		CompanyName:     cfg.DisplayName,
+		DefaultTheme:    cfg.PrimaryColor,
+		Language:        cfg.DefaultLanguage,
+		Address:         cfg.Location,
+		TenantCode:      pharmacy.TenantCode,
+		PharmacyID:      pharmacy.ID.String(),
+		BusinessType:    pharmacy.BusinessType,
+		WebsiteEnabled:  cfg.WebsiteEnabled,
+		Features:        cfg.FeatureFlags,
+		LogoURL:         cfg.LogoURL,
+		Tagline:         cfg.Tagline,
+		ContactPhone:    cfg.ContactPhone,
+		ContactEmail:    cfg.ContactEmail,
+		Currency:        cfg.Currency(),
+		DisplayCurrency: cfg.DisplayCurrency,
 	}
 	if resp.BusinessType == "" {
 		resp.BusinessType = models.BusinessTypePharmacy
@@ -97,6 +103,13 @@ func (s *pharmacyConfigService) GetAppConfigByHostname(ctx context.Context, host
 	if len(resp.Features) == 0 {
 		resp.Features = models.DefaultFeatureFlags()
 	}
+	if s.subsRepo != nil {
+		sub, err := s.subsRepo.GetByPharmacyID(ctx, pharmacy.ID)
+		if err != nil {
+			return nil, errors.ErrInternal("failed to load subscription", err)
+		}
+		resp.Features = planFeatures(resp.Features, sub)
+	}
 	if cfg.VerifiedAt != nil {
 		s := cfg.VerifiedAt.Format("2006-01-02T15:04:05Z07:00")
 		resp.VerifiedAt = &s
@@ -104,26 +117,138 @@ func (s *pharmacyConfigService) GetAppConfigByHostname(ctx context.Context, host
 	return resp, nil
 }
 
-func (s *pharmacyConfigService) Upsert(ctx context.Context, pharmacyID uuid.UUID, input *models.PharmacyConfig) (*models.PharmacyConfig, error) {
-	c, err := s.configRepo.GetByPharmacyID(ctx, pharmacyID)
-	if err != nil && err != gorm.ErrRecordNotFound {
+func (s *pharmacyConfigService) Validate(ctx context.Context, pharmacyID uuid.UUID, input *models.PharmacyConfig) (*inbound.ConfigValidationResult, error) {
+	current, err := s.currentConfig(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	return validatePharmacyConfig(input, current), nil
+}
+
+func (s *pharmacyConfigService) Upsert(ctx context.Context, pharmacyID, changedBy uuid.UUID, input *models.PharmacyConfig) (*models.PharmacyConfig, error) {
+	c, err := s.currentConfig(ctx, pharmacyID)
+	if err != nil {
 		return nil, err
 	}
-	if err == gorm.ErrRecordNotFound || c == nil {
+	if result := validatePharmacyConfig(input, c); !result.Valid {
+		return nil, firstValidationError(result)
+	}
+	if len(input.TaxRates) > 0 {
+		rates := make(models.TaxRatesMap, len(input.TaxRates))
+		for class, rate := range input.TaxRates {
+			rates[models.NormalizeTaxClass(class)] = rate
+		}
+		input.TaxRates = rates
+	}
+	if c == nil {
 		c = &models.PharmacyConfig{PharmacyID: pharmacyID}
 		applyInput(c, input)
 		if err := s.configRepo.Create(ctx, c); err != nil {
 			return nil, errors.ErrInternal("failed to create config", err)
 		}
+		s.recordVersion(ctx, c, models.ConfigVersionUpdate, changedBy, nil)
 		return c, nil
 	}
+	s.recordBaseline(ctx, c)
 	applyInput(c, input)
 	if err := s.configRepo.Update(ctx, c); err != nil {
 		return nil, errors.ErrInternal("failed to update config", err)
 	}
+	s.recordVersion(ctx, c, models.ConfigVersionUpdate, changedBy, nil)
+	return c, nil
+}
+
+func (s *pharmacyConfigService) ListHistory(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.PharmacyConfigVersion, int64, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.versionRepo.ListByPharmacy(ctx, pharmacyID, limit, offset)
+}
+
+func (s *pharmacyConfigService) GetVersion(ctx context.Context, pharmacyID uuid.UUID, version int) (*models.PharmacyConfigVersion, error) {
+	v, err := s.versionRepo.GetByVersion(ctx, pharmacyID, version)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, errors.ErrNotFound("config version")
+	}
+	return v, nil
+}
+
+func (s *pharmacyConfigService) Rollback(ctx context.Context, pharmacyID, changedBy uuid.UUID, version int) (*models.PharmacyConfig, error) {
+	v, err := s.GetVersion(ctx, pharmacyID, version)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.currentConfig(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, errors.ErrNotFound("config")
+	}
+	// Snapshots were valid when saved, but the rules (e.g. the flag registry) may have tightened since.
+	if result := validatePharmacyConfig(&v.Snapshot, c); !result.Valid {
+		return nil, firstValidationError(result)
+	}
+	s.recordBaseline(ctx, c)
+	applyInput(c, &v.Snapshot)
+	if err := s.configRepo.Update(ctx, c); err != nil {
+		return nil, errors.ErrInternal("failed to update config", err)
+	}
+	s.recordVersion(ctx, c, models.ConfigVersionRollback, changedBy, &version)
+	return c, nil
+}
+
+// currentConfig returns the saved config, or nil when the pharmacy has none yet.
+func (s *pharmacyConfigService) currentConfig(ctx context.Context, pharmacyID uuid.UUID) (*models.PharmacyConfig, error) {
+	c, err := s.configRepo.GetByPharmacyID(ctx, pharmacyID)
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
 	return c, nil
 }
 
+// recordBaseline snapshots a config saved before history existed, so the first change can be rolled back.
+func (s *pharmacyConfigService) recordBaseline(ctx context.Context, c *models.PharmacyConfig) {
+	latest, err := s.versionRepo.LatestVersion(ctx, c.PharmacyID)
+	if err != nil || latest > 0 {
+		return
+	}
+	s.recordVersion(ctx, c, models.ConfigVersionBaseline, uuid.Nil, nil)
+}
+
+// recordVersion appends a history snapshot. History is best effort: the config change itself is already saved.
+func (s *pharmacyConfigService) recordVersion(ctx context.Context, c *models.PharmacyConfig, action string, changedBy uuid.UUID, rolledBackFrom *int) {
+	latest, err := s.versionRepo.LatestVersion(ctx, c.PharmacyID)
+	if err != nil {
+		s.logger.Warn("config history: latest version lookup failed", zap.String("pharmacy_id", c.PharmacyID.String()), zap.Error(err))
+		return
+	}
+	snapshot := *c
+	snapshot.Pharmacy = nil
+	v := &models.PharmacyConfigVersion{
+		PharmacyID:     c.PharmacyID,
+		Version:        latest + 1,
+		Action:         action,
+		RolledBackFrom: rolledBackFrom,
+		Snapshot:       snapshot,
+	}
+	if changedBy != uuid.Nil {
+		v.ChangedBy = &changedBy
+	}
+	if err := s.versionRepo.Create(ctx, v); err != nil {
+		s.logger.Warn("config history: record version failed", zap.String("pharmacy_id", c.PharmacyID.String()), zap.Error(err))
+	}
+}
+
 func applyInput(dst *models.PharmacyConfig, src *models.PharmacyConfig) {
 	dst.DisplayName = src.DisplayName
 	dst.Location = src.Location
@@ -134,7 +259,12 @@ func applyInput(dst *models.PharmacyConfig, src *models.PharmacyConfig) {
 	dst.ContactEmail = src.ContactEmail
 	dst.PrimaryColor = src.PrimaryColor
 	dst.DefaultLanguage = src.DefaultLanguage
+	if base := models.NormalizeCurrency(src.BaseCurrency); base != "" {
+		dst.BaseCurrency = base
+	}
+	dst.DisplayCurrency = models.NormalizeCurrency(src.DisplayCurrency)
 	dst.WebsiteEnabled = src.WebsiteEnabled
+	dst.SiteHostname = strings.ToLower(strings.TrimSpace(src.SiteHostname))
 	if len(src.FeatureFlags) > 0 {
 		dst.FeatureFlags = src.FeatureFlags
 	}
@@ -143,4 +273,106 @@ func applyInput(dst *models.PharmacyConfig, src *models.PharmacyConfig) {
 	dst.EstablishedYear = src.EstablishedYear
 	dst.ReturnRefundPolicy = src.ReturnRefundPolicy
 	dst.ChatEditWindowMinutes = src.ChatEditWindowMinutes
+	dst.TaxEnabled = src.TaxEnabled
+	dst.TaxRates = src.TaxRates
+	dst.PricesIncludeTax = src.PricesIncludeTax
+	dst.TaxRegistrationNo = src.TaxRegistrationNo
+	dst.ExpiryDiscount = src.ExpiryDiscount
+	dst.BusinessHours = src.BusinessHours
+	dst.ReturnRateAlert = src.ReturnRateAlert
+	dst.InventoryAlerts = src.InventoryAlerts
+	dst.DeliveryFee = src.DeliveryFee
+	dst.DeliveryOTPRequired = src.DeliveryOTPRequired
+	dst.DeliveryAreas = src.DeliveryAreas
+	dst.ActivityLogRetentionDays = src.ActivityLogRetentionDays
+	dst.LicensePolicy = src.LicensePolicy
+	dst.SecurityPolicy = src.SecurityPolicy
+	dst.OrderFields = src.OrderFields
+	dst.ProductAttributes = src.ProductAttributes
+}
+
+func validateInventoryAlertPolicy(p *models.InventoryAlertPolicy) error {
+	if p == nil || !p.Enabled {
+		return nil
+	}
+	if p.ReorderLevel < 0 || p.ReorderLevel > 100000 {
+		return errors.ErrValidation("inventory alert reorder_level must be between 0 and 100000")
+	}
+	if p.ExpiryDays < 1 || p.ExpiryDays > 365 {
+		return errors.ErrValidation("inventory alert expiry_days must be between 1 and 365")
+	}
+	return nil
+}
+
+func validateLicensePolicy(p *models.LicensePolicy) error {
+	if p == nil {
+		return nil
+	}
+	if len(p.ReminderDays) > 5 {
+		return errors.ErrValidation("license reminder_days takes at most 5 values")
+	}
+	for _, d := range p.ReminderDays {
+		if d < 1 || d > 365 {
+			return errors.ErrValidation("license reminder_days must be between 1 and 365")
+		}
+	}
+	return nil
+}
+
+func validateSecurityPolicy(p *models.SecurityPolicy) error {
+	if p == nil {
+		return nil
+	}
+	if p.MinLength < 6 || p.MinLength > 128 {
+		return errors.ErrValidation("security min_length must be between 6 and 128")
+	}
+	if p.ExpiryDays < 0 || p.ExpiryDays > 730 {
+		return errors.ErrValidation("security expiry_days must be between 0 and 730")
+	}
+	if p.ReuseCount < 0 || p.ReuseCount > 10 {
+		return errors.ErrValidation("security reuse_count must be between 0 and 10")
+	}
+	if p.SessionHours < 0 || p.SessionHours > 24*90 {
+		return errors.ErrValidation("security session_hours must be between 0 and 2160")
+	}
+	for _, role := range p.TwoFactorRoles {
+		if role == "" || len(role) > 50 {
+			return errors.ErrValidation("security two_factor_roles must be role names")
+		}
+	}
+	return nil
+}
+
+func validateReturnRateAlertPolicy(p *models.ReturnRateAlertPolicy) error {
+	if p == nil || !p.Enabled {
+		return nil
+	}
+	if p.ThresholdPercent <= 0 || p.ThresholdPercent > 100 {
+		return errors.ErrValidation("return rate threshold_percent must be greater than 0 and at most 100")
+	}
+	if p.MinUnitsSold < 1 {
+		return errors.ErrValidation("return rate min_units_sold must be at least 1")
+	}
+	if p.WindowDays < 7 || p.WindowDays > 365 {
+		return errors.ErrValidation("return rate window_days must be between 7 and 365")
+	}
+	return nil
+}
+
+func validateExpiryDiscountPolicy(p *models.ExpiryDiscountPolicy) error {
+	if p == nil {
+		return nil
+	}
+	if p.Enabled && len(p.Tiers) == 0 {
+		return errors.ErrValidation("expiry discount needs at least one tier")
+	}
+	for _, t := range p.Tiers {
+		if t.DaysBeforeExpiry <= 0 || t.DaysBeforeExpiry > 730 {
+			return errors.ErrValidation("expiry discount days_before_expiry must be between 1 and 730")
+		}
+		if t.DiscountPercent <= 0 || t.DiscountPercent >= 100 {
+			return errors.ErrValidation("expiry discount percent must be greater than 0 and less than 100")
+		}
+	}
+	return nil
 }
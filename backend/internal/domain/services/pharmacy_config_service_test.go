@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// memoryConfigVersions stores config history in memory for service tests.
+func memoryConfigVersions() (*mocks.MockPharmacyConfigVersionRepository, *[]*models.PharmacyConfigVersion) {
+	versions := []*models.PharmacyConfigVersion{}
+	repo := &mocks.MockPharmacyConfigVersionRepository{
+		CreateFunc: func(ctx context.Context, v *models.PharmacyConfigVersion) error {
+			versions = append(versions, v)
+			return nil
+		},
+		LatestVersionFunc: func(ctx context.Context, pharmacyID uuid.UUID) (int, error) {
+			return len(versions), nil
+		},
+		GetByVersionFunc: func(ctx context.Context, pharmacyID uuid.UUID, version int) (*models.PharmacyConfigVersion, error) {
+			for _, v := range versions {
+				if v.Version == version {
+					return v, nil
+				}
+			}
+			return nil, nil
+		},
+	}
+	return repo, &versions
+}
+
+func TestPharmacyConfigService_Validate_ReportsFieldErrors(t *testing.T) {
+	versionRepo, _ := memoryConfigVersions()
+	svc := NewPharmacyConfigService(&mocks.MockPharmacyConfigRepository{}, versionRepo, &mocks.MockPharmacyRepository{}, nil, zap.NewNop())
+	input := &models.PharmacyConfig{
+		PrimaryColor:    "teal",
+		DefaultLanguage: "fr",
+		FeatureFlags:    models.FeatureFlagsMap{"products": true, "teleport": true},
+		BusinessHours: []models.BusinessHours{
+			{Day: "mon", Open: "09:00", Close: "18:00"},
+			{Day: "mon", Open: "09:00", Close: "18:00"},
+			{Day: "tue", Open: "18:00", Close: "09:00"},
+			{Day: "wed", Open: "9am", Close: "24:00"},
+		},
+	}
+	result, err := svc.Validate(context.Background(), uuid.New(), input)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if result.Valid {
+		t.Fatal("expected invalid config")
+	}
+	for _, field := range []string{"primary_color", "default_language", "feature_flags.teleport", "business_hours[1].day", "business_hours[2].close", "business_hours[3].open"} {
+		if _, ok := result.Errors[field]; !ok {
+			t.Errorf("expected error for %s, got %v", field, result.Errors)
+		}
+	}
+	if _, ok := result.Errors["business_hours[3].close"]; ok {
+		t.Error("24:00 should be accepted as a closing time")
+	}
+}
+
+func TestPharmacyConfigService_Validate_WarningsAndChanges(t *testing.T) {
+	pharmacyID := uuid.New()
+	configRepo := &mocks.MockPharmacyConfigRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, id uuid.UUID) (*models.PharmacyConfig, error) {
+			return &models.PharmacyConfig{PharmacyID: id, DisplayName: "Care", PrimaryColor: "#0d9488", WebsiteEnabled: true, ContactPhone: "9800000000"}, nil
+		},
+	}
+	versionRepo, _ := memoryConfigVersions()
+	svc := NewPharmacyConfigService(configRepo, versionRepo, &mocks.MockPharmacyRepository{}, nil, zap.NewNop())
+	input := &models.PharmacyConfig{DisplayName: "Care", PrimaryColor: "#fff", ContactPhone: "9800000000", TaxEnabled: true}
+	result, err := svc.Validate(context.Background(), pharmacyID, input)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if !result.Valid {
+		t.Fatalf("expected valid config, got %v", result.Errors)
+	}
+	if len(result.Warnings) != 2 {
+		t.Errorf("expected website and tax warnings, got %v", result.Warnings)
+	}
+	want := map[string]bool{"primary_color": true, "website_enabled": true, "tax_enabled": true}
+	if len(result.Changes) != len(want) {
+		t.Fatalf("expected changes %v, got %v", want, result.Changes)
+	}
+	for _, field := range result.Changes {
+		if !want[field] {
+			t.Errorf("unexpected change %s", field)
+		}
+	}
+}
+
+func TestPharmacyConfigService_Upsert_RejectsInvalidConfig(t *testing.T) {
+	versionRepo, versions := memoryConfigVersions()
+	svc := NewPharmacyConfigService(&mocks.MockPharmacyConfigRepository{}, versionRepo, &mocks.MockPharmacyRepository{}, nil, zap.NewNop())
+	_, err := svc.Upsert(context.Background(), uuid.New(), uuid.New(), &models.PharmacyConfig{DefaultLanguage: "xx"})
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if len(*versions) != 0 {
+		t.Errorf("invalid config must not be recorded, got %d versions", len(*versions))
+	}
+}
+
+func TestPharmacyConfigService_UpsertAndRollback_RecordsHistory(t *testing.T) {
+	pharmacyID, userID := uuid.New(), uuid.New()
+	saved := &models.PharmacyConfig{ID: uuid.New(), PharmacyID: pharmacyID, DisplayName: "Original", PrimaryColor: "#111111", WebsiteEnabled: true}
+	configRepo := &mocks.MockPharmacyConfigRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, id uuid.UUID) (*models.PharmacyConfig, error) {
+			c := *saved
+			return &c, nil
+		},
+	}
+	versionRepo, versions := memoryConfigVersions()
+	svc := NewPharmacyConfigService(configRepo, versionRepo, &mocks.MockPharmacyRepository{}, nil, zap.NewNop())
+
+	updated, err := svc.Upsert(context.Background(), pharmacyID, userID, &models.PharmacyConfig{DisplayName: "Renamed", PrimaryColor: "#222222", WebsiteEnabled: true})
+	if err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	saved = updated
+	if len(*versions) != 2 {
+		t.Fatalf("expected baseline and update versions, got %d", len(*versions))
+	}
+	baseline, update := (*versions)[0], (*versions)[1]
+	if baseline.Action != models.ConfigVersionBaseline || baseline.Version != 1 || baseline.Snapshot.DisplayName != "Original" {
+		t.Errorf("unexpected baseline %+v", baseline)
+	}
+	if update.Action != models.ConfigVersionUpdate || update.Version != 2 || update.ChangedBy == nil || *update.ChangedBy != userID {
+		t.Errorf("unexpected update version %+v", update)
+	}
+
+	restored, err := svc.Rollback(context.Background(), pharmacyID, userID, 1)
+	if err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if restored.DisplayName != "Original" || restored.PrimaryColor != "#111111" {
+		t.Errorf("rollback did not restore version 1: %+v", restored)
+	}
+	last := (*versions)[len(*versions)-1]
+	if last.Action != models.ConfigVersionRollback || last.Version != 3 || last.RolledBackFrom == nil || *last.RolledBackFrom != 1 {
+		t.Errorf("unexpected rollback version %+v", last)
+	}
+
+	_, err = svc.Rollback(context.Background(), pharmacyID, userID, 42)
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeNotFound {
+		t.Errorf("expected not found for missing version, got %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+)
+
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// siteHostnamePattern matches a bare host name with at least two labels, e.g. shop.example.com.
+var siteHostnamePattern = regexp.MustCompile(`^(?i)([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+var weekdays = map[string]bool{"mon": true, "tue": true, "wed": true, "thu": true, "fri": true, "sat": true, "sun": true}
+
+// validatePharmacyConfig checks a config update. current is the saved config (nil when there is none)
+// and is only used to report which fields change.
+func validatePharmacyConfig(input, current *models.PharmacyConfig) *inbound.ConfigValidationResult {
+	errs := map[string]string{}
+	warnings := []string{}
+
+	if input.PrimaryColor != "" && !hexColorPattern.MatchString(input.PrimaryColor) {
+		errs["primary_color"] = "must be a hex color like #0d9488 or #09a"
+	}
+	if input.DefaultLanguage != "" {
+		if _, ok := models.SupportedLanguages[input.DefaultLanguage]; !ok {
+			errs["default_language"] = "unsupported language; use one of " + strings.Join(sortedLanguageCodes(), ", ")
+		}
+	}
+	base, display := models.NormalizeCurrency(input.BaseCurrency), models.NormalizeCurrency(input.DisplayCurrency)
+	if _, ok := models.Currencies[base]; base != "" && !ok {
+		errs["base_currency"] = "unsupported currency; use one of " + strings.Join(sortedCurrencyCodes(), ", ")
+	}
+	if _, ok := models.Currencies[display]; display != "" && !ok {
+		errs["display_currency"] = "unsupported currency; use one of " + strings.Join(sortedCurrencyCodes(), ", ")
+	} else if display != "" && (display == base || base == "" && display == current.Currency()) {
+		errs["display_currency"] = "must differ from the base currency"
+	}
+	if _, ok := models.Currencies[base]; ok && current != nil && base != current.Currency() {
+		warnings = append(warnings, "the base currency changes from "+current.Currency()+" to "+base+"; existing prices, orders and invoices are not converted, products keep their current currency, and exchange rates must be set again")
+	}
+	if h := strings.TrimSpace(input.SiteHostname); h != "" && !siteHostnamePattern.MatchString(h) {
+		errs["site_hostname"] = "must be a host name like shop.example.com, without scheme, port or path"
+	}
+	if input.ContactEmail != "" {
+		if _, err := mail.ParseAddress(input.ContactEmail); err != nil {
+			errs["contact_email"] = "must be a valid email address"
+		}
+	}
+	for key := range input.FeatureFlags {
+		if _, ok := models.FeatureFlagRegistry[key]; !ok {
+			errs["feature_flags."+key] = "unknown feature flag"
+		}
+	}
+	if input.ChatEditWindowMinutes < 0 || input.ChatEditWindowMinutes > 1440 {
+		errs["chat_edit_window_minutes"] = "must be between 0 and 1440"
+	}
+	if y := input.EstablishedYear; y != 0 && (y < 1800 || y > time.Now().Year()) {
+		errs["established_year"] = fmt.Sprintf("must be between 1800 and %d", time.Now().Year())
+	}
+	for class, rate := range input.TaxRates {
+		if rate < 0 || rate > 100 {
+			errs["tax_rates."+class] = "must be between 0 and 100"
+		}
+	}
+	if input.DeliveryFee < 0 {
+		errs["delivery_fee"] = "must not be negative"
+	}
+	if len(input.DeliveryAreas) > 100 {
+		errs["delivery_areas"] = "at most 100 areas"
+	}
+	for _, area := range input.DeliveryAreas {
+		if a := strings.TrimSpace(area); a == "" || len(a) > 100 {
+			errs["delivery_areas"] = "areas must be 1 to 100 characters"
+		}
+	}
+	if d := input.ActivityLogRetentionDays; d != 0 && (d < 30 || d > 3650) {
+		errs["activity_log_retention_days"] = "must be 0 (default) or between 30 and 3650"
+	}
+	if err := validateExpiryDiscountPolicy(input.ExpiryDiscount); err != nil {
+		errs["expiry_discount"] = errors.GetAppError(err).Message
+	}
+	if err := validateReturnRateAlertPolicy(input.ReturnRateAlert); err != nil {
+		errs["return_rate_alert"] = errors.GetAppError(err).Message
+	}
+	if err := validateInventoryAlertPolicy(input.InventoryAlerts); err != nil {
+		errs["inventory_alerts"] = errors.GetAppError(err).Message
+	}
+	if err := validateLicensePolicy(input.LicensePolicy); err != nil {
+		errs["license_policy"] = errors.GetAppError(err).Message
+	}
+	if err := validateSecurityPolicy(input.SecurityPolicy); err != nil {
+		errs["security_policy"] = errors.GetAppError(err).Message
+	}
+	if err := validateOrderFieldDefinitions(input.OrderFields); err != nil {
+		errs["order_fields"] = errors.GetAppError(err).Message
+	}
+	if err := validateProductAttributeDefinitions(input.ProductAttributes); err != nil {
+		errs["product_attributes"] = errors.GetAppError(err).Message
+	}
+	validateBusinessHours(input.BusinessHours, errs, &warnings)
+
+	if !input.WebsiteEnabled {
+		warnings = append(warnings, "the public website is disabled; visitors will see a temporarily unavailable page")
+	}
+	if input.TaxEnabled && len(input.TaxRates) == 0 {
+		warnings = append(warnings, "tax is enabled but no tax rates are set, so orders will carry no VAT")
+	}
+	if flags := input.FeatureFlags; flags != nil && flags["orders"] && !flags["products"] {
+		warnings = append(warnings, "orders are enabled while products are disabled; customers cannot browse what they order")
+	}
+	if input.ContactPhone == "" && input.ContactEmail == "" {
+		warnings = append(warnings, "no contact phone or email is set; customers have no way to reach the pharmacy")
+	}
+	if strings.TrimSpace(input.DisplayName) == "" {
+		warnings = append(warnings, "display name is empty; the registered pharmacy name will be shown")
+	}
+
+	return &inbound.ConfigValidationResult{
+		Valid:    len(errs) == 0,
+		Errors:   errs,
+		Warnings: warnings,
+		Changes:  configChanges(input, current),
+	}
+}
+
+func validateBusinessHours(hours []models.BusinessHours, errs map[string]string, warnings *[]string) {
+	if len(hours) == 0 {
+		return
+	}
+	seen := map[string]bool{}
+	open := 0
+	for i, h := range hours {
+		field := fmt.Sprintf("business_hours[%d]", i)
+		day := strings.ToLower(strings.TrimSpace(h.Day))
+		if !weekdays[day] {
+			errs[field+".day"] = "must be one of mon, tue, wed, thu, fri, sat, sun"
+			continue
+		}
+		if seen[day] {
+			errs[field+".day"] = "duplicate day " + day
+			continue
+		}
+		seen[day] = true
+		if h.Closed {
+			continue
+		}
+		openAt, ok1 := parseClock(h.Open)
+		closeAt, ok2 := parseClock(h.Close)
+		if !ok1 {
+			errs[field+".open"] = "must be a time like 09:00"
+		}
+		if !ok2 {
+			errs[field+".close"] = "must be a time like 18:00 (24:00 for midnight)"
+		}
+		if ok1 && ok2 && closeAt <= openAt {
+			errs[field+".close"] = "must be after the opening time"
+		}
+		open++
+	}
+	if open == 0 {
+		*warnings = append(*warnings, "business hours mark every listed day as closed")
+	}
+}
+
+// firstValidationError turns a failed validation result into a single service error, picking the
+// alphabetically first field so the message is stable.
+func firstValidationError(result *inbound.ConfigValidationResult) error {
+	fields := make([]string, 0, len(result.Errors))
+	for field := range result.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return errors.ErrValidation(fields[0] + ": " + result.Errors[fields[0]])
+}
+
+// parseClock returns minutes since midnight for "HH:MM"; "24:00" is allowed as a closing time.
+func parseClock(v string) (int, bool) {
+	if v == "24:00" {
+		return 24 * 60, true
+	}
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+func sortedLanguageCodes() []string {
+	codes := make([]string, 0, len(models.SupportedLanguages))
+	for code := range models.SupportedLanguages {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+func sortedCurrencyCodes() []string {
+	codes := make([]string, 0, len(models.Currencies))
+	for code := range models.Currencies {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// configChanges lists the JSON fields whose value differs between the update and the saved config.
+func configChanges(input, current *models.PharmacyConfig) []string {
+	changes := []string{}
+	before := configFields(current)
+	after := configFields(input)
+	for key, v := range after {
+		if string(before[key]) != string(v) {
+			changes = append(changes, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			changes = append(changes, key)
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+func configFields(c *models.PharmacyConfig) map[string]json.RawMessage {
+	fields := map[string]json.RawMessage{}
+	if c == nil {
+		return fields
+	}
+	b, _ := json.Marshal(c)
+	_ = json.Unmarshal(b, &fields)
+	for _, key := range []string{"id", "pharmacy_id", "created_at", "updated_at", "pharmacy"} {
+		delete(fields, key)
+	}
+	return fields
+}
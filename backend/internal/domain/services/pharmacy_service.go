@@ -38,6 +38,12 @@ func (s *pharmacyService) Update(ctx context.Context, p *models.Pharmacy) error
 	if p.ID == uuid.Nil {
 		return errors.ErrValidation("pharmacy ID is required")
 	}
+	// The signup review and suspension are not editable here.
+	if cur, err := s.repo.GetByID(ctx, p.ID); err == nil && cur != nil {
+		p.Status, p.LicenseDocumentURL = cur.Status, cur.LicenseDocumentURL
+		p.ReviewNote, p.ReviewedAt, p.ReviewedBy = cur.ReviewNote, cur.ReviewedAt, cur.ReviewedBy
+		p.SuspendedAt, p.SuspensionReason = cur.SuspendedAt, cur.SuspensionReason
+	}
 	return s.repo.Update(ctx, p)
 }
 
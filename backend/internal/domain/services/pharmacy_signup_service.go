@@ -0,0 +1,331 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// signupSlugPattern is what a signup's hostname slug may look like; it ends up in storefront URLs.
+var signupSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+type pharmacySignupService struct {
+	pharmacyRepo outbound.PharmacyRepository
+	userRepo     outbound.UserRepository
+	configRepo   outbound.PharmacyConfigRepository
+	categoryRepo outbound.CategoryRepository
+	gatewayRepo  outbound.PaymentGatewayRepository
+	subsRepo     outbound.SubscriptionRepository
+	uploads      inbound.UploadService
+	private      outbound.PrivateFileStorage
+	mailer       inbound.MailerService
+	uow          outbound.UnitOfWork
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+// NewPharmacySignupService builds the onboarding flow. The license document goes through uploads (type sniffing,
+// virus scan, private storage); mailer may be nil.
+func NewPharmacySignupService(pharmacyRepo outbound.PharmacyRepository, userRepo outbound.UserRepository, configRepo outbound.PharmacyConfigRepository, categoryRepo outbound.CategoryRepository, gatewayRepo outbound.PaymentGatewayRepository, subsRepo outbound.SubscriptionRepository, uploads inbound.UploadService, private outbound.PrivateFileStorage, mailer inbound.MailerService, uow outbound.UnitOfWork, logger *zap.Logger) inbound.PharmacySignupService {
+	return &pharmacySignupService{
+		pharmacyRepo: pharmacyRepo,
+		userRepo:     userRepo,
+		configRepo:   configRepo,
+		categoryRepo: categoryRepo,
+		gatewayRepo:  gatewayRepo,
+		subsRepo:     subsRepo,
+		uploads:      uploads,
+		private:      private,
+		mailer:       mailer,
+		uow:          uow,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+func (s *pharmacySignupService) Signup(ctx context.Context, in inbound.PharmacySignupInput) (*inbound.PharmacySignup, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	in.LicenseNo = strings.TrimSpace(in.LicenseNo)
+	in.HostnameSlug = strings.ToLower(strings.TrimSpace(in.HostnameSlug))
+	in.OwnerEmail = strings.TrimSpace(in.OwnerEmail)
+	switch {
+	case in.Name == "":
+		return nil, errors.ErrValidation("pharmacy name is required")
+	case in.LicenseNo == "":
+		return nil, errors.ErrValidation("license number is required")
+	case !signupSlugPattern.MatchString(in.HostnameSlug):
+		return nil, errors.ErrValidation("hostname slug must be 2-63 lowercase letters, digits or dashes")
+	case in.OwnerEmail == "" || !strings.Contains(in.OwnerEmail, "@"):
+		return nil, errors.ErrValidation("owner email is required")
+	case len(in.License) == 0:
+		return nil, errors.ErrValidation("license document is required")
+	}
+	businessType := in.BusinessType
+	switch businessType {
+	case "":
+		businessType = models.BusinessTypePharmacy
+	case models.BusinessTypePharmacy, models.BusinessTypeRetail, models.BusinessTypeClinic, models.BusinessTypeOther:
+	default:
+		return nil, errors.ErrValidation("invalid business type")
+	}
+	if u, err := s.userRepo.GetByEmail(ctx, in.OwnerEmail); err == nil && u != nil {
+		return nil, errors.ErrConflict("email already registered")
+	}
+	taken, err := s.pharmacyRepo.Taken(ctx, in.LicenseNo, in.HostnameSlug)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to check pharmacy", err)
+	}
+	if taken {
+		return nil, errors.ErrConflict("a pharmacy with this license number or hostname slug already exists")
+	}
+
+	p := &models.Pharmacy{
+		ID:           uuid.New(),
+		Name:         in.Name,
+		LicenseNo:    in.LicenseNo,
+		TenantCode:   in.HostnameSlug,
+		HostnameSlug: in.HostnameSlug,
+		BusinessType: businessType,
+		Address:      strings.TrimSpace(in.Address),
+		Phone:        strings.TrimSpace(in.Phone),
+		Email:        strings.TrimSpace(in.Email),
+		Status:       models.PharmacyStatusPending,
+	}
+	owner := &models.User{
+		ID:         uuid.New(),
+		PharmacyID: p.ID,
+		Email:      in.OwnerEmail,
+		Name:       strings.TrimSpace(in.OwnerName),
+		Role:       RoleAdmin,
+	}
+	if err := setPassword(securityPolicy(ctx, nil, p.ID), owner, in.OwnerPassword, s.now()); err != nil {
+		return nil, err
+	}
+	// The license is checked and stored first so a bad document leaves nothing behind; an upload whose signup
+	// then fails is left to the orphan sweep.
+	doc, err := s.uploads.Upload(ctx, p.ID, owner.ID, models.UploadPurposeLicense, in.LicenseFilename, in.License)
+	if err != nil {
+		return nil, err
+	}
+	p.LicenseDocumentURL = doc.URL
+
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.pharmacyRepo.Create(ctx, p); err != nil {
+			return errors.ErrInternal("failed to create pharmacy", err)
+		}
+		if err := s.userRepo.Create(ctx, owner); err != nil {
+			return errors.ErrInternal("failed to create owner account", err)
+		}
+		// is_active defaults to true in the database, so false is only stored by an update.
+		p.IsActive, owner.IsActive = false, false
+		if err := s.pharmacyRepo.Update(ctx, p); err != nil {
+			return errors.ErrInternal("failed to create pharmacy", err)
+		}
+		if err := s.userRepo.Update(ctx, owner); err != nil {
+			return errors.ErrInternal("failed to create owner account", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("pharmacy signed up", zap.String("pharmacy_id", p.ID.String()), zap.String("slug", p.HostnameSlug))
+	return &inbound.PharmacySignup{Pharmacy: p, Owner: owner}, nil
+}
+
+func (s *pharmacySignupService) List(ctx context.Context, status string) ([]*inbound.PharmacySignup, error) {
+	if status == "" {
+		status = models.PharmacyStatusPending
+	}
+	pharmacies, err := s.pharmacyRepo.ListByStatus(ctx, status)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list signups", err)
+	}
+	list := make([]*inbound.PharmacySignup, 0, len(pharmacies))
+	for _, p := range pharmacies {
+		signup, err := s.view(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, signup)
+	}
+	return list, nil
+}
+
+func (s *pharmacySignupService) Get(ctx context.Context, pharmacyID uuid.UUID) (*inbound.PharmacySignup, error) {
+	p, err := s.pharmacyRepo.GetByID(ctx, pharmacyID)
+	if err != nil || p == nil {
+		return nil, errors.ErrNotFound("pharmacy")
+	}
+	return s.view(ctx, p)
+}
+
+// Approve activates the pharmacy and its owner and seeds what a new pharmacy needs to open its storefront. Config,
+// categories and gateways the pharmacy already has are kept, so a retried approval does not duplicate them.
+func (s *pharmacySignupService) Approve(ctx context.Context, pharmacyID, reviewerID uuid.UUID) (*inbound.PharmacySignup, error) {
+	p, owner, err := s.pending(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.seedDefaults(ctx, p); err != nil {
+			return err
+		}
+		p.Status, p.IsActive = models.PharmacyStatusActive, true
+		p.ReviewedAt, p.ReviewedBy, p.ReviewNote = &now, &reviewerID, ""
+		if err := s.pharmacyRepo.Update(ctx, p); err != nil {
+			return errors.ErrInternal("failed to approve pharmacy", err)
+		}
+		if owner != nil {
+			owner.IsActive = true
+			if err := s.userRepo.Update(ctx, owner); err != nil {
+				return errors.ErrInternal("failed to activate owner account", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if owner != nil && s.mailer != nil {
+		if err := s.mailer.StaffAccountCreated(ctx, owner); err != nil {
+			s.logger.Warn("failed to email approved pharmacy owner", zap.String("pharmacy_id", p.ID.String()), zap.Error(err))
+		}
+	}
+	s.logger.Info("pharmacy signup approved", zap.String("pharmacy_id", p.ID.String()), zap.String("reviewer_id", reviewerID.String()))
+	return s.view(ctx, p)
+}
+
+func (s *pharmacySignupService) Reject(ctx context.Context, pharmacyID, reviewerID uuid.UUID, reason string) (*inbound.PharmacySignup, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.ErrValidation("reason is required")
+	}
+	p, _, err := s.pending(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	p.Status = models.PharmacyStatusRejected
+	p.ReviewedAt, p.ReviewedBy, p.ReviewNote = &now, &reviewerID, reason
+	if err := s.pharmacyRepo.Update(ctx, p); err != nil {
+		return nil, errors.ErrInternal("failed to reject pharmacy", err)
+	}
+	s.logger.Info("pharmacy signup rejected", zap.String("pharmacy_id", p.ID.String()), zap.String("reviewer_id", reviewerID.String()))
+	return s.view(ctx, p)
+}
+
+// pending loads a signup awaiting review with its owner.
+func (s *pharmacySignupService) pending(ctx context.Context, pharmacyID uuid.UUID) (*models.Pharmacy, *models.User, error) {
+	p, err := s.pharmacyRepo.GetByID(ctx, pharmacyID)
+	if err != nil || p == nil {
+		return nil, nil, errors.ErrNotFound("pharmacy")
+	}
+	if p.Status != models.PharmacyStatusPending {
+		return nil, nil, errors.ErrConflict("pharmacy is not awaiting approval")
+	}
+	owner, err := s.owner(ctx, p.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, owner, nil
+}
+
+// owner returns the pharmacy's first admin, the account created with the signup; nil when there is none.
+func (s *pharmacySignupService) owner(ctx context.Context, pharmacyID uuid.UUID) (*models.User, error) {
+	users, err := s.userRepo.GetByPharmacyID(ctx, pharmacyID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load pharmacy owner", err)
+	}
+	var owner *models.User
+	for _, u := range users {
+		if u.Role == RoleAdmin && (owner == nil || u.CreatedAt.Before(owner.CreatedAt)) {
+			owner = u
+		}
+	}
+	return owner, nil
+}
+
+func (s *pharmacySignupService) seedDefaults(ctx context.Context, p *models.Pharmacy) error {
+	if cfg, err := s.configRepo.GetByPharmacyID(ctx, p.ID); err != nil || cfg == nil {
+		cfg = &models.PharmacyConfig{
+			PharmacyID:      p.ID,
+			DisplayName:     p.Name,
+			Location:        p.Address,
+			PrimaryColor:    "#0d9488",
+			DefaultLanguage: "en",
+			WebsiteEnabled:  true,
+			FeatureFlags:    models.DefaultFeatureFlags(),
+		}
+		if err := s.configRepo.Create(ctx, cfg); err != nil {
+			return errors.ErrInternal("failed to create pharmacy config", err)
+		}
+	}
+	categories, err := s.categoryRepo.ListByPharmacy(ctx, p.ID)
+	if err != nil {
+		return errors.ErrInternal("failed to list categories", err)
+	}
+	if len(categories) == 0 {
+		for i, name := range models.SignupCategories {
+			if err := s.categoryRepo.Create(ctx, &models.Category{PharmacyID: p.ID, Name: name, SortOrder: i}); err != nil {
+				return errors.ErrInternal("failed to create categories", err)
+			}
+		}
+	}
+	gateways, err := s.gatewayRepo.ListByPharmacy(ctx, p.ID, false)
+	if err != nil {
+		return errors.ErrInternal("failed to list payment gateways", err)
+	}
+	if len(gateways) == 0 {
+		for _, pg := range models.SignupPaymentGateways(p.ID) {
+			active := pg.IsActive
+			if err := s.gatewayRepo.Create(ctx, pg); err != nil {
+				return errors.ErrInternal("failed to create payment gateways", err)
+			}
+			if !active {
+				pg.IsActive = false
+				if err := s.gatewayRepo.Update(ctx, pg); err != nil {
+					return errors.ErrInternal("failed to create payment gateways", err)
+				}
+			}
+		}
+	}
+	// Approved pharmacies start on the trial plan.
+	if sub, err := s.subsRepo.GetByPharmacyID(ctx, p.ID); err != nil {
+		return errors.ErrInternal("failed to load subscription", err)
+	} else if sub == nil {
+		sub = &models.Subscription{PharmacyID: p.ID, ExpiresAt: s.now().Add(models.TrialPeriod)}
+		sub.SetPlan(models.Plans[models.PlanTrial])
+		if err := s.subsRepo.Save(ctx, sub); err != nil {
+			return errors.ErrInternal("failed to start trial subscription", err)
+		}
+	}
+	return nil
+}
+
+// view adds the owner and a signed link to the license document.
+func (s *pharmacySignupService) view(ctx context.Context, p *models.Pharmacy) (*inbound.PharmacySignup, error) {
+	owner, err := s.owner(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	signup := &inbound.PharmacySignup{Pharmacy: p, Owner: owner}
+	if p.LicenseDocumentURL != "" {
+		link, err := s.private.SignedURL(ctx, p.LicenseDocumentURL)
+		if err != nil {
+			s.logger.Warn("failed to sign license document", zap.String("pharmacy_id", p.ID.String()), zap.Error(err))
+		} else {
+			signup.LicenseDocumentURL = link
+		}
+	}
+	return signup, nil
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type signupFixture struct {
+	svc        inbound.PharmacySignupService
+	pharmacies map[uuid.UUID]*models.Pharmacy
+	users      map[uuid.UUID]*models.User
+	configs    int
+	categories int
+	gateways   []*models.PaymentGateway
+}
+
+func newSignupFixture() *signupFixture {
+	f := &signupFixture{pharmacies: map[uuid.UUID]*models.Pharmacy{}, users: map[uuid.UUID]*models.User{}}
+	pharmacyRepo := &mocks.MockPharmacyRepository{
+		CreateFunc: func(ctx context.Context, p *models.Pharmacy) error {
+			cp := *p
+			cp.IsActive = true // the database default
+			f.pharmacies[p.ID] = &cp
+			return nil
+		},
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Pharmacy, error) {
+			if p := f.pharmacies[id]; p != nil {
+				cp := *p
+				return &cp, nil
+			}
+			return nil, nil
+		},
+		UpdateFunc: func(ctx context.Context, p *models.Pharmacy) error {
+			cp := *p
+			f.pharmacies[p.ID] = &cp
+			return nil
+		},
+		ListByStatusFunc: func(ctx context.Context, status string) ([]*models.Pharmacy, error) {
+			var list []*models.Pharmacy
+			for _, p := range f.pharmacies {
+				if p.Status == status {
+					cp := *p
+					list = append(list, &cp)
+				}
+			}
+			return list, nil
+		},
+		TakenFunc: func(ctx context.Context, licenseNo, hostnameSlug string) (bool, error) {
+			for _, p := range f.pharmacies {
+				if p.LicenseNo == licenseNo || p.HostnameSlug == hostnameSlug {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+	}
+	userRepo := &mocks.MockUserRepository{
+		CreateFunc: func(ctx context.Context, u *models.User) error {
+			cp := *u
+			cp.IsActive = true
+			f.users[u.ID] = &cp
+			return nil
+		},
+		GetByEmailFunc: func(ctx context.Context, email string) (*models.User, error) {
+			for _, u := range f.users {
+				if u.Email == email {
+					return u, nil
+				}
+			}
+			return nil, errors.ErrNotFound("user")
+		},
+		GetByPharmacyIDFunc: func(ctx context.Context, pharmacyID uuid.UUID) ([]*models.User, error) {
+			var list []*models.User
+			for _, u := range f.users {
+				if u.PharmacyID == pharmacyID {
+					cp := *u
+					list = append(list, &cp)
+				}
+			}
+			return list, nil
+		},
+		UpdateFunc: func(ctx context.Context, u *models.User) error {
+			cp := *u
+			f.users[u.ID] = &cp
+			return nil
+		},
+	}
+	configRepo := &mocks.MockPharmacyConfigRepository{CreateFunc: func(ctx context.Context, c *models.PharmacyConfig) error {
+		f.configs++
+		return nil
+	}}
+	categoryRepo := &mocks.MockCategoryRepository{CreateFunc: func(ctx context.Context, c *models.Category) error {
+		f.categories++
+		return nil
+	}}
+	gatewayRepo := &mocks.MockPaymentGatewayRepository{CreateFunc: func(ctx context.Context, pg *models.PaymentGateway) error {
+		f.gateways = append(f.gateways, pg)
+		return nil
+	}}
+	uploads, _, _, storage := newUploadFixture(0)
+	f.svc = NewPharmacySignupService(pharmacyRepo, userRepo, configRepo, categoryRepo, gatewayRepo, &mocks.MockSubscriptionRepository{}, uploads, storage, nil, &mocks.MockUnitOfWork{}, zap.NewNop())
+	return f
+}
+
+func validSignup() inbound.PharmacySignupInput {
+	return inbound.PharmacySignupInput{
+		Name:            "Hamro Pharmacy",
+		LicenseNo:       "PH-2026-0042",
+		HostnameSlug:    "Hamro",
+		OwnerName:       "Sita Sharma",
+		OwnerEmail:      "sita@hamro.test",
+		OwnerPassword:   "Str0ng-password",
+		LicenseFilename: "license.pdf",
+		License:         []byte("%PDF-1.7 license"),
+	}
+}
+
+func TestPharmacySignupService_Signup(t *testing.T) {
+	f := newSignupFixture()
+	ctx := context.Background()
+
+	bad := validSignup()
+	bad.License = []byte("<html><script>alert(1)</script></html>")
+	if _, err := f.svc.Signup(ctx, bad); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeValidation {
+		t.Errorf("html license: err = %v, want validation", err)
+	}
+	bad = validSignup()
+	bad.HostnameSlug = "hamro pharmacy"
+	if _, err := f.svc.Signup(ctx, bad); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeValidation {
+		t.Errorf("slug with a space: err = %v, want validation", err)
+	}
+	if len(f.pharmacies) != 0 {
+		t.Fatal("a rejected signup created a pharmacy")
+	}
+
+	signup, err := f.svc.Signup(ctx, validSignup())
+	if err != nil {
+		t.Fatalf("Signup: %v", err)
+	}
+	p, owner := f.pharmacies[signup.Pharmacy.ID], f.users[signup.Owner.ID]
+	if p.Status != models.PharmacyStatusPending || p.IsActive || p.HostnameSlug != "hamro" || p.TenantCode != "hamro" {
+		t.Errorf("pharmacy = %+v, want an inactive pending pharmacy with slug hamro", p)
+	}
+	if !strings.HasPrefix(p.LicenseDocumentURL, "private:") {
+		t.Errorf("license document = %q, want a private reference", p.LicenseDocumentURL)
+	}
+	if owner.Role != RoleAdmin || owner.IsActive || owner.PharmacyID != p.ID || !owner.CheckPassword("Str0ng-password") {
+		t.Errorf("owner = %+v, want an inactive admin of the pharmacy", owner)
+	}
+
+	again := validSignup()
+	again.OwnerEmail = "other@hamro.test"
+	if _, err := f.svc.Signup(ctx, again); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeConflict {
+		t.Errorf("same slug again: err = %v, want conflict", err)
+	}
+}
+
+func TestPharmacySignupService_Review(t *testing.T) {
+	f := newSignupFixture()
+	ctx := context.Background()
+	reviewer := uuid.New()
+	signup, err := f.svc.Signup(ctx, validSignup())
+	if err != nil {
+		t.Fatalf("Signup: %v", err)
+	}
+	id := signup.Pharmacy.ID
+
+	if pending, err := f.svc.List(ctx, ""); err != nil || len(pending) != 1 || pending[0].Pharmacy.ID != id {
+		t.Fatalf("List = %v, %v; want the pending signup", pending, err)
+	}
+	got, err := f.svc.Get(ctx, id)
+	if err != nil || got.Owner == nil || !strings.HasPrefix(got.LicenseDocumentURL, "/signed/") {
+		t.Fatalf("Get = %+v, %v; want the owner and a signed license link", got, err)
+	}
+
+	approved, err := f.svc.Approve(ctx, id, reviewer)
+	if err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	p := f.pharmacies[id]
+	if p.Status != models.PharmacyStatusActive || !p.IsActive || p.ReviewedBy == nil || *p.ReviewedBy != reviewer {
+		t.Errorf("pharmacy = %+v, want active and reviewed", p)
+	}
+	if !f.users[approved.Owner.ID].IsActive {
+		t.Error("owner was not activated")
+	}
+	if f.configs != 1 || f.categories != len(models.SignupCategories) || len(f.gateways) != 4 {
+		t.Errorf("seeded %d configs, %d categories, %d gateways", f.configs, f.categories, len(f.gateways))
+	}
+	for _, pg := range f.gateways {
+		if pg.IsActive != (pg.Code == models.GatewayCodeCOD) {
+			t.Errorf("gateway %s active = %v, want only cash on delivery active", pg.Code, pg.IsActive)
+		}
+	}
+
+	if _, err := f.svc.Approve(ctx, id, reviewer); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeConflict {
+		t.Errorf("second approval: err = %v, want conflict", err)
+	}
+	if _, err := f.svc.Reject(ctx, id, reviewer, "duplicate"); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeConflict {
+		t.Errorf("rejecting an approved pharmacy: err = %v, want conflict", err)
+	}
+
+	other := validSignup()
+	other.LicenseNo, other.HostnameSlug, other.OwnerEmail = "PH-2026-0043", "other", "ram@other.test"
+	signup, err = f.svc.Signup(ctx, other)
+	if err != nil {
+		t.Fatalf("Signup: %v", err)
+	}
+	if _, err := f.svc.Reject(ctx, signup.Pharmacy.ID, reviewer, " "); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeValidation {
+		t.Errorf("reject without a reason: err = %v, want validation", err)
+	}
+	if _, err := f.svc.Reject(ctx, signup.Pharmacy.ID, reviewer, "License number does not match the document"); err != nil {
+		t.Fatalf("Reject: %v", err)
+	}
+	if p := f.pharmacies[signup.Pharmacy.ID]; p.Status != models.PharmacyStatusRejected || p.IsActive || p.ReviewNote == "" {
+		t.Errorf("rejected pharmacy = %+v", p)
+	}
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type platformService struct {
+	pharmacyRepo outbound.PharmacyRepository
+	userRepo     outbound.UserRepository
+	authProvider outbound.AuthProvider
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+func NewPlatformService(pharmacyRepo outbound.PharmacyRepository, userRepo outbound.UserRepository, authProvider outbound.AuthProvider, logger *zap.Logger) inbound.PlatformService {
+	return &platformService{pharmacyRepo: pharmacyRepo, userRepo: userRepo, authProvider: authProvider, logger: logger, now: time.Now}
+}
+
+func (s *platformService) ListTenants(ctx context.Context) ([]*models.TenantUsage, error) {
+	list, err := s.pharmacyRepo.ListUsage(ctx)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list tenants", err)
+	}
+	return list, nil
+}
+
+func (s *platformService) Suspend(ctx context.Context, pharmacyID, actorID uuid.UUID, reason string) (*models.Pharmacy, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.ErrValidation("a reason is required to suspend a pharmacy")
+	}
+	p, err := s.pharmacy(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	switch p.Status {
+	case models.PharmacyStatusSuspended:
+		return nil, errors.ErrConflict("pharmacy is already suspended")
+	case models.PharmacyStatusPending, models.PharmacyStatusRejected:
+		return nil, errors.ErrConflict("pharmacy signup has not been approved")
+	}
+	now := s.now()
+	p.Status = models.PharmacyStatusSuspended
+	p.IsActive = false
+	p.SuspendedAt = &now
+	p.SuspensionReason = reason
+	if err := s.pharmacyRepo.Update(ctx, p); err != nil {
+		return nil, errors.ErrInternal("failed to suspend pharmacy", err)
+	}
+	s.logger.Info("pharmacy suspended", zap.String("pharmacy_id", p.ID.String()), zap.String("by", actorID.String()))
+	return p, nil
+}
+
+func (s *platformService) Reactivate(ctx context.Context, pharmacyID, actorID uuid.UUID) (*models.Pharmacy, error) {
+	p, err := s.pharmacy(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != models.PharmacyStatusSuspended {
+		return nil, errors.ErrConflict("pharmacy is not suspended")
+	}
+	p.Status = models.PharmacyStatusActive
+	p.IsActive = true
+	p.SuspendedAt = nil
+	p.SuspensionReason = ""
+	if err := s.pharmacyRepo.Update(ctx, p); err != nil {
+		return nil, errors.ErrInternal("failed to reactivate pharmacy", err)
+	}
+	s.logger.Info("pharmacy reactivated", zap.String("pharmacy_id", p.ID.String()), zap.String("by", actorID.String()))
+	return p, nil
+}
+
+func (s *platformService) Impersonate(ctx context.Context, pharmacyID, actorID uuid.UUID, userID *uuid.UUID) (*inbound.Impersonation, error) {
+	p, err := s.pharmacy(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.userRepo.GetByPharmacyID(ctx, p.ID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load pharmacy users", err)
+	}
+	var target *models.User
+	for _, u := range users {
+		switch {
+		case userID != nil:
+			if u.ID == *userID {
+				target = u
+			}
+		case u.Role == models.RoleAdmin && u.IsActive && (target == nil || u.CreatedAt.Before(target.CreatedAt)):
+			target = u
+		}
+	}
+	switch {
+	case target == nil && userID != nil:
+		return nil, errors.ErrNotFound("user")
+	case target == nil:
+		return nil, errors.ErrNotFound("pharmacy admin")
+	case target.ID == actorID:
+		return nil, errors.ErrValidation("cannot impersonate yourself")
+	case !target.IsActive:
+		return nil, errors.ErrValidation("user is inactive")
+	}
+	token, err := s.authProvider.GenerateImpersonationToken(target.ID, p.ID, target.Role, actorID, models.ImpersonationTTL)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to issue impersonation token", err)
+	}
+	s.logger.Info("pharmacy user impersonated", zap.String("pharmacy_id", p.ID.String()),
+		zap.String("user_id", target.ID.String()), zap.String("by", actorID.String()))
+	return &inbound.Impersonation{AccessToken: token, ExpiresAt: s.now().Add(models.ImpersonationTTL), User: target}, nil
+}
+
+func (s *platformService) pharmacy(ctx context.Context, pharmacyID uuid.UUID) (*models.Pharmacy, error) {
+	p, err := s.pharmacyRepo.GetByID(ctx, pharmacyID)
+	if err != nil || p == nil {
+		return nil, errors.ErrNotFound("pharmacy")
+	}
+	return p, nil
+}
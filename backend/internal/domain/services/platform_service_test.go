@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestPlatformService_SuspendAndReactivate(t *testing.T) {
+	ctx := context.Background()
+	p := &models.Pharmacy{ID: uuid.New(), Name: "Hamro", Status: models.PharmacyStatusActive, IsActive: true}
+	pharmacyRepo := &mocks.MockPharmacyRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Pharmacy, error) {
+			cp := *p
+			return &cp, nil
+		},
+		UpdateFunc: func(ctx context.Context, u *models.Pharmacy) error {
+			cp := *u
+			p = &cp
+			return nil
+		},
+	}
+	svc := NewPlatformService(pharmacyRepo, &mocks.MockUserRepository{}, &mocks.MockAuthProvider{}, zap.NewNop())
+	actor := uuid.New()
+
+	if _, err := svc.Suspend(ctx, p.ID, actor, "  "); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeValidation {
+		t.Errorf("suspend without a reason: err = %v, want validation", err)
+	}
+	if _, err := svc.Reactivate(ctx, p.ID, actor); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeConflict {
+		t.Errorf("reactivate an active pharmacy: err = %v, want conflict", err)
+	}
+	if _, err := svc.Suspend(ctx, p.ID, actor, "Unpaid invoices"); err != nil {
+		t.Fatalf("Suspend: %v", err)
+	}
+	if p.Status != models.PharmacyStatusSuspended || p.IsActive || p.SuspendedAt == nil || p.SuspensionReason != "Unpaid invoices" || p.Open() {
+		t.Errorf("pharmacy = %+v, want suspended", p)
+	}
+	if _, err := svc.Suspend(ctx, p.ID, actor, "again"); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeConflict {
+		t.Errorf("second suspension: err = %v, want conflict", err)
+	}
+	if _, err := svc.Reactivate(ctx, p.ID, actor); err != nil {
+		t.Fatalf("Reactivate: %v", err)
+	}
+	if p.Status != models.PharmacyStatusActive || !p.IsActive || p.SuspendedAt != nil || p.SuspensionReason != "" || !p.Open() {
+		t.Errorf("pharmacy = %+v, want active again", p)
+	}
+}
+
+func TestPlatformService_Impersonate(t *testing.T) {
+	ctx := context.Background()
+	pharmacyID, actor := uuid.New(), uuid.New()
+	now := time.Now()
+	owner := &models.User{ID: uuid.New(), PharmacyID: pharmacyID, Role: models.RoleAdmin, IsActive: true, CreatedAt: now.Add(-time.Hour)}
+	admin := &models.User{ID: uuid.New(), PharmacyID: pharmacyID, Role: models.RoleAdmin, IsActive: true, CreatedAt: now}
+	gone := &models.User{ID: uuid.New(), PharmacyID: pharmacyID, Role: models.RoleStaff, CreatedAt: now}
+	pharmacyRepo := &mocks.MockPharmacyRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Pharmacy, error) {
+		return &models.Pharmacy{ID: id, Status: models.PharmacyStatusSuspended}, nil
+	}}
+	userRepo := &mocks.MockUserRepository{GetByPharmacyIDFunc: func(ctx context.Context, id uuid.UUID) ([]*models.User, error) {
+		return []*models.User{admin, gone, owner}, nil
+	}}
+	var issued struct {
+		userID, impersonator uuid.UUID
+		ttl                  time.Duration
+	}
+	auth := &mocks.MockAuthProvider{GenerateImpersonationTokenFunc: func(userID, pharmacyID uuid.UUID, role string, impersonatorID uuid.UUID, ttl time.Duration) (string, error) {
+		issued.userID, issued.impersonator, issued.ttl = userID, impersonatorID, ttl
+		return "imp-token", nil
+	}}
+	svc := NewPlatformService(pharmacyRepo, userRepo, auth, zap.NewNop())
+
+	imp, err := svc.Impersonate(ctx, pharmacyID, actor, nil)
+	if err != nil {
+		t.Fatalf("Impersonate: %v", err)
+	}
+	if imp.AccessToken != "imp-token" || imp.User.ID != owner.ID || issued.userID != owner.ID || issued.impersonator != actor || issued.ttl != models.ImpersonationTTL {
+		t.Errorf("impersonation = %+v (issued %+v), want a token for the owner carrying the actor", imp, issued)
+	}
+	if imp, err := svc.Impersonate(ctx, pharmacyID, actor, &admin.ID); err != nil || imp.User.ID != admin.ID {
+		t.Errorf("Impersonate(admin) = %+v, %v", imp, err)
+	}
+	if _, err := svc.Impersonate(ctx, pharmacyID, actor, &gone.ID); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeValidation {
+		t.Errorf("inactive user: err = %v, want validation", err)
+	}
+	other := uuid.New()
+	if _, err := svc.Impersonate(ctx, pharmacyID, actor, &other); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeNotFound {
+		t.Errorf("user of another pharmacy: err = %v, want not found", err)
+	}
+}
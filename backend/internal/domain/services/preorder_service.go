@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type preorderService struct {
+	preorderRepo        outbound.PreorderRepository
+	productRepo         outbound.ProductRepository
+	poRepo              outbound.PurchaseOrderRepository
+	paymentGatewayRepo  outbound.PaymentGatewayRepository
+	orderService        inbound.OrderService
+	paymentService      inbound.PaymentService
+	notificationService inbound.NotificationService
+	emailSender         outbound.EmailSender
+	logger              *zap.Logger
+}
+
+func NewPreorderService(
+	preorderRepo outbound.PreorderRepository,
+	productRepo outbound.ProductRepository,
+	poRepo outbound.PurchaseOrderRepository,
+	paymentGatewayRepo outbound.PaymentGatewayRepository,
+	orderService inbound.OrderService,
+	paymentService inbound.PaymentService,
+	notificationService inbound.NotificationService,
+	emailSender outbound.EmailSender,
+	logger *zap.Logger,
+) inbound.PreorderService {
+	return &preorderService{
+		preorderRepo:        preorderRepo,
+		productRepo:         productRepo,
+		poRepo:              poRepo,
+		paymentGatewayRepo:  paymentGatewayRepo,
+		orderService:        orderService,
+		paymentService:      paymentService,
+		notificationService: notificationService,
+		emailSender:         emailSender,
+		logger:              logger,
+	}
+}
+
+func (s *preorderService) Create(ctx context.Context, pharmacyID, createdBy uuid.UUID, input inbound.PreorderInput) (*models.Preorder, error) {
+	if input.Quantity <= 0 {
+		return nil, errors.ErrValidation("quantity must be positive")
+	}
+	prod, err := s.productRepo.GetByID(ctx, input.ProductID)
+	if err != nil || prod == nil || prod.PharmacyID != pharmacyID || !prod.IsActive {
+		return nil, errors.ErrNotFound("product")
+	}
+	if !prod.PreorderEnabled {
+		return nil, errors.ErrValidation(prod.Name + " is not available for pre-order")
+	}
+	total := roundMoney(prod.UnitPrice * float64(input.Quantity))
+	deposit := roundMoney(total * prod.PreorderDepositPercent / 100)
+	if input.PayFull {
+		deposit = total
+	}
+	p := &models.Preorder{
+		PharmacyID:    pharmacyID,
+		ProductID:     prod.ID,
+		Quantity:      input.Quantity,
+		UnitPrice:     prod.UnitPrice,
+		TotalAmount:   total,
+		DepositAmount: deposit,
+		CustomerName:  strings.TrimSpace(input.CustomerName),
+		CustomerPhone: strings.TrimSpace(input.CustomerPhone),
+		CustomerEmail: strings.TrimSpace(input.CustomerEmail),
+		Notes:         input.Notes,
+		Status:        models.PreorderStatusPending,
+		ExpectedAt:    prod.PreorderExpectedAt,
+		CreatedBy:     createdBy,
+	}
+	// Mock payment, as for orders: a selected active gateway marks the amount due as paid.
+	if input.PaymentGatewayID != nil && *input.PaymentGatewayID != uuid.Nil && deposit > 0 {
+		gateway, err := s.paymentGatewayRepo.GetByID(ctx, *input.PaymentGatewayID)
+		if err != nil || gateway == nil || gateway.PharmacyID != pharmacyID || !gateway.IsActive {
+			return nil, errors.ErrValidation("invalid payment gateway")
+		}
+		p.PaymentGatewayID = input.PaymentGatewayID
+		p.PaymentMethod = gatewayCodeToPaymentMethod(gateway.Code)
+		p.AmountPaid = deposit
+	}
+	if err := s.preorderRepo.Create(ctx, p); err != nil {
+		return nil, errors.ErrInternal("failed to create preorder", err)
+	}
+	p.Product = prod
+	return p, nil
+}
+
+func (s *preorderService) GetByID(ctx context.Context, pharmacyID, id uuid.UUID, createdBy *uuid.UUID) (*models.Preorder, error) {
+	p, err := s.preorderRepo.GetByID(ctx, id)
+	if err != nil || p == nil || p.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("preorder")
+	}
+	if createdBy != nil && p.CreatedBy != *createdBy {
+		return nil, errors.ErrNotFound("preorder")
+	}
+	return p, nil
+}
+
+func (s *preorderService) List(ctx context.Context, pharmacyID uuid.UUID, status *models.PreorderStatus, productID, createdBy *uuid.UUID, limit, offset int) ([]*models.Preorder, int64, error) {
+	return s.preorderRepo.ListByPharmacy(ctx, pharmacyID, status, productID, createdBy, limit, offset)
+}
+
+func (s *preorderService) Cancel(ctx context.Context, pharmacyID, id uuid.UUID, createdBy *uuid.UUID) (*models.Preorder, error) {
+	p, err := s.GetByID(ctx, pharmacyID, id, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != models.PreorderStatusPending {
+		return nil, errors.ErrConflict("only pending preorders can be cancelled")
+	}
+	now := time.Now()
+	p.Status = models.PreorderStatusCancelled
+	p.CancelledAt = &now
+	if err := s.preorderRepo.Update(ctx, p); err != nil {
+		return nil, errors.ErrInternal("failed to cancel preorder", err)
+	}
+	return p, nil
+}
+
+func (s *preorderService) LinkPurchaseOrder(ctx context.Context, po *models.PurchaseOrder) error {
+	for _, it := range po.Items {
+		list, err := s.preorderRepo.ListPendingByProduct(ctx, it.ProductID)
+		if err != nil {
+			return errors.ErrInternal("failed to load preorders", err)
+		}
+		for _, p := range list {
+			if p.PharmacyID != po.PharmacyID || p.PurchaseOrderID != nil {
+				continue
+			}
+			p.PurchaseOrderID = &po.ID
+			if err := s.preorderRepo.Update(ctx, p); err != nil {
+				return errors.ErrInternal("failed to link preorder", err)
+			}
+		}
+		if err := s.RefreshETAs(ctx, po.PharmacyID, it.ProductID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *preorderService) RefreshETAs(ctx context.Context, pharmacyID, productID uuid.UUID) error {
+	prod, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil || prod == nil || prod.PharmacyID != pharmacyID {
+		return errors.ErrNotFound("product")
+	}
+	list, err := s.preorderRepo.ListPendingByProduct(ctx, productID)
+	if err != nil {
+		return errors.ErrInternal("failed to load preorders", err)
+	}
+	pos := map[uuid.UUID]*models.PurchaseOrder{}
+	for _, p := range list {
+		eta := prod.PreorderExpectedAt
+		if p.PurchaseOrderID != nil {
+			po, ok := pos[*p.PurchaseOrderID]
+			if !ok {
+				po, _ = s.poRepo.GetByID(ctx, *p.PurchaseOrderID)
+				pos[*p.PurchaseOrderID] = po
+			}
+			switch {
+			case po == nil || po.Status == models.PurchaseOrderStatusDeclined || po.Status == models.PurchaseOrderStatusCancelled:
+				// Restock fell through; wait for the next purchase order.
+				p.PurchaseOrderID = nil
+			case po.ConfirmedDeliveryDate != nil:
+				eta = po.ConfirmedDeliveryDate
+			case po.ExpectedDeliveryDate != nil:
+				eta = po.ExpectedDeliveryDate
+			}
+		}
+		changed := !sameDay(p.ExpectedAt, eta)
+		p.ExpectedAt = eta
+		if err := s.preorderRepo.Update(ctx, p); err != nil {
+			return errors.ErrInternal("failed to update preorder", err)
+		}
+		if changed {
+			when := "to be confirmed"
+			if eta != nil {
+				when = eta.Format("2006-01-02")
+			}
+			s.notify(ctx, p, "Pre-order "+p.PreorderNumber+" update",
+				fmt.Sprintf("The expected arrival of %s for your pre-order %s is now %s.", prod.Name, p.PreorderNumber, when))
+		}
+	}
+	return nil
+}
+
+func (s *preorderService) ConvertForPurchaseOrder(ctx context.Context, po *models.PurchaseOrder) error {
+	for _, it := range po.Items {
+		list, err := s.preorderRepo.ListPendingByProduct(ctx, it.ProductID)
+		if err != nil {
+			return errors.ErrInternal("failed to load preorders", err)
+		}
+		for _, p := range list {
+			if p.PharmacyID != po.PharmacyID {
+				continue
+			}
+			if err := s.convert(ctx, p); err != nil {
+				// Usually out of stock: later preorders keep waiting for the next delivery.
+				s.logger.Info("preorder not converted", zap.String("preorder_id", p.ID.String()), zap.Error(err))
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// convert creates the order at the locked preorder price and records the amount already paid against it.
+func (s *preorderService) convert(ctx context.Context, p *models.Preorder) error {
+	items := []inbound.OrderItemInput{{ProductID: p.ProductID, Quantity: p.Quantity, UnitPrice: p.UnitPrice}}
+	o, err := s.orderService.Create(ctx, p.PharmacyID, p.CreatedBy, p.CustomerName, p.CustomerPhone, p.CustomerEmail, items, "Pre-order "+p.PreorderNumber, "", nil, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	p.Status = models.PreorderStatusConverted
+	p.OrderID = &o.ID
+	p.ConvertedAt = &now
+	if err := s.preorderRepo.Update(ctx, p); err != nil {
+		return errors.ErrInternal("failed to update preorder", err)
+	}
+	if p.AmountPaid > 0 {
+		method := p.PaymentMethod
+		if method == "" {
+			method = models.PaymentMethodOther
+		}
+		payment := &models.Payment{
+			OrderID:          o.ID,
+			PharmacyID:       p.PharmacyID,
+			PaymentGatewayID: p.PaymentGatewayID,
+			Amount:           p.AmountPaid,
+			Currency:         o.Currency,
+			Method:           method,
+			Reference:        "preorder-" + p.PreorderNumber,
+			CreatedBy:        p.CreatedBy,
+		}
+		if err := s.paymentService.Create(ctx, payment); err == nil {
+			_ = s.paymentService.Complete(ctx, payment.ID)
+		} else {
+			s.logger.Warn("failed to record preorder payment", zap.String("preorder_id", p.ID.String()), zap.Error(err))
+		}
+	}
+	name := "your item"
+	if p.Product != nil {
+		name = p.Product.Name
+	}
+	s.notify(ctx, p, "Pre-order "+p.PreorderNumber+" is ready",
+		fmt.Sprintf("%s has arrived. Your pre-order %s is now order %s.", name, p.PreorderNumber, o.OrderNumber))
+	return nil
+}
+
+// notify sends an in-app notification to the user who placed the preorder and emails the customer when an address is known.
+func (s *preorderService) notify(ctx context.Context, p *models.Preorder, title, message string) {
+	if s.notificationService != nil && p.CreatedBy != uuid.Nil {
+		if _, err := s.notificationService.Create(ctx, p.PharmacyID, p.CreatedBy, title, message, "preorder"); err != nil {
+			s.logger.Debug("preorder notification failed", zap.Error(err))
+		}
+	}
+	if s.emailSender != nil && p.CustomerEmail != "" {
+		msg := &outbound.EmailMessage{PharmacyID: p.PharmacyID, To: []string{p.CustomerEmail}, Subject: title, TextBody: message}
+		if err := s.emailSender.Send(ctx, msg); err != nil {
+			s.logger.Debug("preorder email failed", zap.Error(err))
+		}
+	}
+}
+
+func sameDay(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakePaymentService records payments and which of them were completed.
+type fakePaymentService struct {
+	inbound.PaymentService
+	created   []*models.Payment
+	completed []uuid.UUID
+}
+
+func (f *fakePaymentService) Create(ctx context.Context, p *models.Payment) error {
+	p.ID = uuid.New()
+	f.created = append(f.created, p)
+	return nil
+}
+
+func (f *fakePaymentService) Complete(ctx context.Context, paymentID uuid.UUID) error {
+	f.completed = append(f.completed, paymentID)
+	return nil
+}
+
+func TestPreorderService_Create_DepositAndRounding(t *testing.T) {
+	pharmacyID := uuid.New()
+	gateway := &models.PaymentGateway{ID: uuid.New(), PharmacyID: pharmacyID, Code: models.GatewayCodeEsewa, IsActive: true}
+	cases := []struct {
+		name        string
+		unitPrice   float64
+		percent     float64
+		quantity    int
+		payFull     bool
+		gateway     bool
+		wantTotal   float64
+		wantDeposit float64
+		wantPaid    float64
+	}{
+		{"deposit share", 120, 25, 2, false, false, 240, 60, 0},
+		{"deposit rounds half up", 0.35, 50, 3, false, false, 1.05, 0.53, 0},
+		{"total rounds to cents", 33.333, 25, 3, false, false, 100, 25, 0},
+		{"pay in full", 120, 25, 2, true, false, 240, 240, 0},
+		{"no deposit", 120, 0, 1, false, false, 120, 0, 0},
+		{"gateway pays the deposit", 99.99, 10, 1, false, true, 99.99, 10, 10},
+		{"nothing due ignores the gateway", 99.99, 0, 1, false, true, 99.99, 0, 0},
+	}
+	for _, tc := range cases {
+		prod := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Insulin pen", IsActive: true,
+			UnitPrice: tc.unitPrice, PreorderEnabled: true, PreorderDepositPercent: tc.percent}
+		var created *models.Preorder
+		svc := NewPreorderService(
+			&mocks.MockPreorderRepository{CreateFunc: func(ctx context.Context, p *models.Preorder) error {
+				created = p
+				return nil
+			}},
+			&mocks.MockProductRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) { return prod, nil }},
+			nil,
+			&mocks.MockPaymentGatewayRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.PaymentGateway, error) { return gateway, nil }},
+			nil, nil, nil, nil, zap.NewNop(),
+		)
+		input := inbound.PreorderInput{ProductID: prod.ID, Quantity: tc.quantity, PayFull: tc.payFull}
+		if tc.gateway {
+			input.PaymentGatewayID = &gateway.ID
+		}
+		p, err := svc.Create(context.Background(), pharmacyID, uuid.New(), input)
+		if err != nil {
+			t.Fatalf("%s: Create: %v", tc.name, err)
+		}
+		if created != p || p.Status != models.PreorderStatusPending || p.UnitPrice != tc.unitPrice {
+			t.Errorf("%s: created %+v", tc.name, p)
+		}
+		if p.TotalAmount != tc.wantTotal || p.DepositAmount != tc.wantDeposit || p.AmountPaid != tc.wantPaid {
+			t.Errorf("%s: total %v deposit %v paid %v, want %v %v %v", tc.name, p.TotalAmount, p.DepositAmount, p.AmountPaid, tc.wantTotal, tc.wantDeposit, tc.wantPaid)
+		}
+		if tc.wantPaid > 0 && p.PaymentMethod != models.PaymentMethodWallet {
+			t.Errorf("%s: payment method = %q, want wallet", tc.name, p.PaymentMethod)
+		}
+	}
+}
+
+func TestPreorderService_Create_Rejects(t *testing.T) {
+	pharmacyID := uuid.New()
+	prod := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Insulin pen", IsActive: true, UnitPrice: 100}
+	svc := NewPreorderService(
+		&mocks.MockPreorderRepository{CreateFunc: func(ctx context.Context, p *models.Preorder) error {
+			t.Fatal("Create should not be called")
+			return nil
+		}},
+		&mocks.MockProductRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) { return prod, nil }},
+		nil, &mocks.MockPaymentGatewayRepository{}, nil, nil, nil, nil, zap.NewNop(),
+	)
+	ctx := context.Background()
+	cases := []struct {
+		name       string
+		pharmacyID uuid.UUID
+		input      inbound.PreorderInput
+		wantCode   string
+	}{
+		{"zero quantity", pharmacyID, inbound.PreorderInput{ProductID: prod.ID}, pkgerrors.ErrCodeValidation},
+		{"pre-order not enabled", pharmacyID, inbound.PreorderInput{ProductID: prod.ID, Quantity: 1}, pkgerrors.ErrCodeValidation},
+		{"another pharmacy's product", uuid.New(), inbound.PreorderInput{ProductID: prod.ID, Quantity: 1}, pkgerrors.ErrCodeNotFound},
+	}
+	for _, tc := range cases {
+		_, err := svc.Create(ctx, tc.pharmacyID, uuid.New(), tc.input)
+		if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != tc.wantCode {
+			t.Errorf("%s: err = %v, want %s", tc.name, err, tc.wantCode)
+		}
+	}
+
+	// An unknown gateway with an amount due fails instead of recording an unpaid deposit as paid.
+	prod.PreorderEnabled, prod.PreorderDepositPercent = true, 20
+	missing := uuid.New()
+	_, err := svc.Create(ctx, pharmacyID, uuid.New(), inbound.PreorderInput{ProductID: prod.ID, Quantity: 1, PaymentGatewayID: &missing})
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("unknown gateway: err = %v, want validation", err)
+	}
+}
+
+func TestPreorderService_RefreshETAs(t *testing.T) {
+	pharmacyID := uuid.New()
+	day := func(d int) *time.Time {
+		v := time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC)
+		return &v
+	}
+	prod := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Insulin pen", PreorderExpectedAt: day(20)}
+	pos := map[uuid.UUID]*models.PurchaseOrder{}
+	newPO := func(status models.PurchaseOrderStatus, expected, confirmed *time.Time) *uuid.UUID {
+		po := &models.PurchaseOrder{ID: uuid.New(), PharmacyID: pharmacyID, Status: status, ExpectedDeliveryDate: expected, ConfirmedDeliveryDate: confirmed}
+		pos[po.ID] = po
+		return &po.ID
+	}
+	gone := uuid.New()
+	cases := []struct {
+		name    string
+		p       *models.Preorder
+		wantETA *time.Time
+		wantPO  bool // still linked to its purchase order
+		notify  bool
+	}{
+		{"no purchase order uses the product date", &models.Preorder{ExpectedAt: day(15)}, day(20), false, true},
+		{"unchanged date is not notified", &models.Preorder{ExpectedAt: day(20)}, day(20), false, false},
+		{"expected delivery", &models.Preorder{PurchaseOrderID: newPO(models.PurchaseOrderStatusDraft, day(10), nil)}, day(10), true, true},
+		{"confirmed delivery wins", &models.Preorder{PurchaseOrderID: newPO(models.PurchaseOrderStatusConfirmed, day(10), day(12))}, day(12), true, true},
+		{"declined purchase order is unlinked", &models.Preorder{ExpectedAt: day(10), PurchaseOrderID: newPO(models.PurchaseOrderStatusDeclined, day(10), nil)}, day(20), false, true},
+		{"missing purchase order is unlinked", &models.Preorder{ExpectedAt: day(20), PurchaseOrderID: &gone}, day(20), false, false},
+	}
+	var list []*models.Preorder
+	for _, tc := range cases {
+		tc.p.ID, tc.p.PharmacyID, tc.p.PreorderNumber, tc.p.CustomerEmail = uuid.New(), pharmacyID, "PRE-"+tc.name, "asha@example.com"
+		list = append(list, tc.p)
+	}
+	updated := map[uuid.UUID]bool{}
+	email := &flakyEmailSender{}
+	svc := NewPreorderService(
+		&mocks.MockPreorderRepository{
+			ListPendingByProductFunc: func(ctx context.Context, productID uuid.UUID) ([]*models.Preorder, error) { return list, nil },
+			UpdateFunc: func(ctx context.Context, p *models.Preorder) error {
+				updated[p.ID] = true
+				return nil
+			},
+		},
+		&mocks.MockProductRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) { return prod, nil }},
+		&mocks.MockPurchaseOrderRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.PurchaseOrder, error) { return pos[id], nil }},
+		nil, nil, nil, nil, email, zap.NewNop(),
+	)
+	if err := svc.RefreshETAs(context.Background(), pharmacyID, prod.ID); err != nil {
+		t.Fatalf("RefreshETAs: %v", err)
+	}
+	for _, tc := range cases {
+		if !sameDay(tc.p.ExpectedAt, tc.wantETA) {
+			t.Errorf("%s: ETA = %v, want %v", tc.name, tc.p.ExpectedAt, tc.wantETA)
+		}
+		if (tc.p.PurchaseOrderID != nil) != tc.wantPO {
+			t.Errorf("%s: purchase order link = %v, want linked %v", tc.name, tc.p.PurchaseOrderID, tc.wantPO)
+		}
+		if !updated[tc.p.ID] {
+			t.Errorf("%s: preorder not saved", tc.name)
+		}
+		notified := false
+		for _, m := range email.sent {
+			if strings.Contains(m.Subject, tc.p.PreorderNumber) {
+				notified = true
+			}
+		}
+		if notified != tc.notify {
+			t.Errorf("%s: notified = %v, want %v", tc.name, notified, tc.notify)
+		}
+	}
+
+	if err := svc.RefreshETAs(context.Background(), uuid.New(), prod.ID); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeNotFound {
+		t.Errorf("another pharmacy's product: err = %v, want not found", err)
+	}
+}
+
+func TestPreorderService_ConvertForPurchaseOrder(t *testing.T) {
+	pharmacyID := uuid.New()
+	productID := uuid.New()
+	gatewayID := uuid.New()
+	paid := &models.Preorder{ID: uuid.New(), PharmacyID: pharmacyID, ProductID: productID, PreorderNumber: "PRE-1", Quantity: 2, UnitPrice: 80,
+		AmountPaid: 40, PaymentGatewayID: &gatewayID, PaymentMethod: models.PaymentMethodWallet, Status: models.PreorderStatusPending}
+	unpaid := &models.Preorder{ID: uuid.New(), PharmacyID: pharmacyID, ProductID: productID, PreorderNumber: "PRE-2", Quantity: 1, UnitPrice: 80,
+		Status: models.PreorderStatusPending}
+	otherPharmacy := &models.Preorder{ID: uuid.New(), PharmacyID: uuid.New(), ProductID: productID, PreorderNumber: "PRE-3", Quantity: 1,
+		Status: models.PreorderStatusPending}
+	orders := &fakeOrderService{}
+	payments := &fakePaymentService{}
+	svc := NewPreorderService(
+		&mocks.MockPreorderRepository{ListPendingByProductFunc: func(ctx context.Context, id uuid.UUID) ([]*models.Preorder, error) {
+			return []*models.Preorder{paid, otherPharmacy, unpaid}, nil
+		}},
+		&mocks.MockProductRepository{}, nil, nil, orders, payments, nil, nil, zap.NewNop(),
+	)
+	po := &models.PurchaseOrder{ID: uuid.New(), PharmacyID: pharmacyID, Items: []models.PurchaseOrderItem{{ProductID: productID, Quantity: 5}}}
+	if err := svc.ConvertForPurchaseOrder(context.Background(), po); err != nil {
+		t.Fatalf("ConvertForPurchaseOrder: %v", err)
+	}
+
+	for _, p := range []*models.Preorder{paid, unpaid} {
+		if p.Status != models.PreorderStatusConverted || p.OrderID == nil || p.ConvertedAt == nil {
+			t.Errorf("%s: status %s order %v, want converted", p.PreorderNumber, p.Status, p.OrderID)
+		}
+	}
+	if otherPharmacy.Status != models.PreorderStatusPending {
+		t.Error("another pharmacy's preorder was converted")
+	}
+	// The order is placed at the locked preorder price.
+	if len(orders.items) != 1 || orders.items[0].UnitPrice != 80 || orders.items[0].Quantity != 1 {
+		t.Errorf("last order items = %+v", orders.items)
+	}
+	// Only the paid preorder records its payment against the new order, and it is completed.
+	if len(payments.created) != 1 {
+		t.Fatalf("payments = %d, want 1", len(payments.created))
+	}
+	pay := payments.created[0]
+	if pay.OrderID != *paid.OrderID || pay.Amount != 40 || pay.Method != models.PaymentMethodWallet || pay.Reference != "preorder-PRE-1" {
+		t.Errorf("payment = %+v", pay)
+	}
+	if len(payments.completed) != 1 || payments.completed[0] != pay.ID {
+		t.Errorf("completed = %v, want the preorder payment", payments.completed)
+	}
+}
+
+func TestPreorderService_ConvertForPurchaseOrder_StopsWhenOrderFails(t *testing.T) {
+	pharmacyID := uuid.New()
+	productID := uuid.New()
+	first := &models.Preorder{ID: uuid.New(), PharmacyID: pharmacyID, ProductID: productID, Quantity: 10, Status: models.PreorderStatusPending}
+	second := &models.Preorder{ID: uuid.New(), PharmacyID: pharmacyID, ProductID: productID, Quantity: 1, Status: models.PreorderStatusPending}
+	updated := 0
+	svc := NewPreorderService(
+		&mocks.MockPreorderRepository{
+			ListPendingByProductFunc: func(ctx context.Context, id uuid.UUID) ([]*models.Preorder, error) {
+				return []*models.Preorder{first, second}, nil
+			},
+			UpdateFunc: func(ctx context.Context, p *models.Preorder) error {
+				updated++
+				return nil
+			},
+		},
+		&mocks.MockProductRepository{}, nil, nil, &fakeOrderService{err: pkgerrors.ErrValidation("insufficient stock")}, &fakePaymentService{}, nil, nil, zap.NewNop(),
+	)
+	po := &models.PurchaseOrder{ID: uuid.New(), PharmacyID: pharmacyID, Items: []models.PurchaseOrderItem{{ProductID: productID, Quantity: 5}}}
+	if err := svc.ConvertForPurchaseOrder(context.Background(), po); err != nil {
+		t.Fatalf("ConvertForPurchaseOrder: %v", err)
+	}
+	// Preorders convert oldest first; once one cannot, later ones keep waiting rather than jumping the queue.
+	if first.Status != models.PreorderStatusPending || second.Status != models.PreorderStatusPending || updated != 0 {
+		t.Errorf("statuses %s %s, %d updates; want both pending", first.Status, second.Status, updated)
+	}
+}
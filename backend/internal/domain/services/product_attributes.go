@@ -0,0 +1,97 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+)
+
+const maxProductAttributes = 30
+
+// validateProductAttributeDefinitions checks a tenant's product attribute schema.
+func validateProductAttributeDefinitions(defs []models.ProductAttributeDefinition) error {
+	if len(defs) > maxProductAttributes {
+		return errors.ErrValidation(fmt.Sprintf("at most %d product attributes are allowed", maxProductAttributes))
+	}
+	seen := map[string]bool{}
+	for _, d := range defs {
+		if err := validateCustomFieldSpec("product attribute", d.Key, d.Label, d.Type, d.Options); err != nil {
+			return err
+		}
+		if seen[d.Key] {
+			return errors.ErrValidation("product attribute key " + d.Key + " is used twice")
+		}
+		seen[d.Key] = true
+	}
+	return nil
+}
+
+// resolveProductAttributes validates a product's attribute values against the schema and returns them in their
+// stored types.
+func resolveProductAttributes(defs []models.ProductAttributeDefinition, input models.CustomFieldValues) (models.CustomFieldValues, error) {
+	byKey := make(map[string]models.ProductAttributeDefinition, len(defs))
+	for _, d := range defs {
+		byKey[d.Key] = d
+	}
+	out := models.CustomFieldValues{}
+	for key, raw := range input {
+		d, ok := byKey[key]
+		if !ok {
+			return nil, errors.ErrValidation("unknown product attribute " + strconv.Quote(key))
+		}
+		v, err := customFieldValue(d.Label, d.Type, d.Options, raw)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			out[key] = v
+		}
+	}
+	for _, d := range defs {
+		if _, ok := out[d.Key]; d.Required && !ok {
+			return nil, errors.ErrValidation(d.Label + " is required")
+		}
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// catalogAttributeFilter turns attr.<key>=value query filters into typed values for a jsonb containment match.
+// Only filterable attributes may be used.
+func catalogAttributeFilter(defs []models.ProductAttributeDefinition, raw map[string]string) (map[string]any, error) {
+	out := make(map[string]any, len(raw))
+	for key, s := range raw {
+		var def *models.ProductAttributeDefinition
+		for i := range defs {
+			if defs[i].Key == key && defs[i].Filterable {
+				def = &defs[i]
+				break
+			}
+		}
+		if def == nil {
+			return nil, errors.ErrValidation("attribute " + strconv.Quote(key) + " cannot be filtered on")
+		}
+		var value any = s
+		if def.Type == models.CustomFieldBoolean {
+			switch strings.ToLower(strings.TrimSpace(s)) {
+			case "true", "1", "yes":
+				value = true
+			case "false", "0", "no":
+				value = false
+			}
+		}
+		v, err := customFieldValue(def.Label, def.Type, def.Options, value)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			out[key] = v
+		}
+	}
+	return out, nil
+}
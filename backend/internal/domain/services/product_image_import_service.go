@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	imageImportMaxFiles    = 500
+	imageImportMaxFileSize = 10 << 20 // 10 MiB, as for single uploads
+)
+
+// imageImportTypes are the accepted extensions and the content type stored with them.
+var imageImportTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".webp": "image/webp",
+	".gif":  "image/gif",
+}
+
+type productImageImportService struct {
+	productRepo    outbound.ProductRepository
+	productService inbound.ProductService
+	images         inbound.ProductImageProcessor
+	logger         *zap.Logger
+}
+
+func NewProductImageImportService(productRepo outbound.ProductRepository, productService inbound.ProductService, images inbound.ProductImageProcessor, logger *zap.Logger) inbound.ProductImageImportService {
+	return &productImageImportService{productRepo: productRepo, productService: productService, images: images, logger: logger}
+}
+
+// skuFromFileName splits "SKU123_2.jpg" into ("SKU123", 2). A name without a numeric "_N" suffix is all SKU.
+// full is the whole stem, tried as the SKU when the split one does not match (SKUs may end in "_<digits>").
+func skuFromFileName(name string) (sku string, position int, full string) {
+	full = strings.TrimSpace(strings.TrimSuffix(name, path.Ext(name)))
+	if i := strings.LastIndex(full, "_"); i > 0 {
+		if n, err := strconv.Atoi(full[i+1:]); err == nil && n >= 0 {
+			return full[:i], n, full
+		}
+	}
+	return full, 0, full
+}
+
+type importPlan struct {
+	file  inbound.ImageUpload
+	match *inbound.ImageImportMatch
+}
+
+func (s *productImageImportService) Import(ctx context.Context, pharmacyID uuid.UUID, files []inbound.ImageUpload, dryRun bool) (*inbound.ImageImportResult, error) {
+	if len(files) == 0 {
+		return nil, errors.ErrValidation("no files uploaded")
+	}
+	if len(files) > imageImportMaxFiles {
+		return nil, errors.ErrValidation(fmt.Sprintf("at most %d files per upload", imageImportMaxFiles))
+	}
+	res := &inbound.ImageImportResult{DryRun: dryRun, Matched: []*inbound.ImageImportMatch{}, Unmatched: []*inbound.ImageImportReject{}}
+	reject := func(f inbound.ImageUpload, reason string) {
+		res.Unmatched = append(res.Unmatched, &inbound.ImageImportReject{File: f.Name, Reason: reason})
+	}
+	products := make(map[string]*models.Product) // by SKU tried, nil when not found
+	lookup := func(sku string) *models.Product {
+		if p, ok := products[sku]; ok {
+			return p
+		}
+		p, err := s.productRepo.GetBySKU(ctx, pharmacyID, sku)
+		if err != nil || p == nil || p.PharmacyID != pharmacyID {
+			p = nil
+		}
+		products[sku] = p
+		return p
+	}
+
+	var plans []importPlan
+	for _, f := range files {
+		base := path.Base(strings.ReplaceAll(f.Name, "\\", "/"))
+		if _, ok := imageImportTypes[strings.ToLower(path.Ext(base))]; !ok {
+			reject(f, "not a supported image (jpg, jpeg, png, webp, gif)")
+			continue
+		}
+		if f.Size > imageImportMaxFileSize {
+			reject(f, "file too large (max 10MB)")
+			continue
+		}
+		sku, position, full := skuFromFileName(base)
+		if sku == "" {
+			reject(f, "file name has no SKU")
+			continue
+		}
+		p := lookup(sku)
+		if p == nil && full != sku {
+			if p = lookup(full); p != nil {
+				sku, position = full, 0
+			}
+		}
+		if p == nil {
+			reject(f, "no product with SKU "+sku)
+			continue
+		}
+		plans = append(plans, importPlan{file: f, match: &inbound.ImageImportMatch{File: f.Name, SKU: sku, ProductID: p.ID, Position: position}})
+	}
+
+	// Per product, images go in position order, then by file name.
+	sort.SliceStable(plans, func(i, j int) bool {
+		a, b := plans[i].match, plans[j].match
+		if a.SKU != b.SKU {
+			return a.SKU < b.SKU
+		}
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		return a.File < b.File
+	})
+	seen := make(map[uuid.UUID]bool)
+	for _, pl := range plans {
+		m := pl.match
+		if !seen[m.ProductID] {
+			seen[m.ProductID] = true
+			res.Products++
+		}
+		if dryRun {
+			res.Matched = append(res.Matched, m)
+			continue
+		}
+		if err := s.attach(ctx, pl); err != nil {
+			s.logger.Warn("bulk image import failed", zap.Error(err), zap.String("file", m.File), zap.String("sku", m.SKU))
+			reject(pl.file, "upload failed")
+			continue
+		}
+		res.ImagesCreated++
+		res.Matched = append(res.Matched, m)
+	}
+	return res, nil
+}
+
+// attach stores one file and adds it as the product's next image; the first image of a product becomes primary.
+func (s *productImageImportService) attach(ctx context.Context, pl importPlan) error {
+	r, err := pl.file.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(io.LimitReader(r, imageImportMaxFileSize+1))
+	if err != nil {
+		return err
+	}
+	ext := strings.ToLower(path.Ext(pl.file.Name))
+	stored, err := s.images.Store(ctx, pl.match.ProductID, data, ext, imageImportTypes[ext])
+	if err != nil {
+		return err
+	}
+	img, err := s.productService.AddImage(ctx, pl.match.ProductID, stored, false)
+	if err != nil {
+		return err
+	}
+	pl.match.ImageID, pl.match.URL, pl.match.IsPrimary = &img.ID, img.URL, img.IsPrimary
+	return nil
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func upload(name string) inbound.ImageUpload {
+	return inbound.ImageUpload{Name: name, Size: 3, Open: func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader("img")), nil }}
+}
+
+func newImageImportFixture() (svc *productImageImportService, storage *memoryStorage, images map[uuid.UUID][]*models.ProductImage, pharmacyID, amxID uuid.UUID) {
+	pharmacyID = uuid.New()
+	catalog := map[string]*models.Product{
+		"AMX500":  {ID: uuid.New(), PharmacyID: pharmacyID, SKU: "AMX500"},
+		"PCM_650": {ID: uuid.New(), PharmacyID: pharmacyID, SKU: "PCM_650"},
+		"VITC":    {ID: uuid.New(), PharmacyID: pharmacyID, SKU: "VITC"},
+	}
+	images = make(map[uuid.UUID][]*models.ProductImage)
+	repo := &mocks.MockProductRepository{
+		GetBySKUFunc: func(ctx context.Context, pid uuid.UUID, sku string) (*models.Product, error) {
+			return catalog[sku], nil
+		},
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+			for _, p := range catalog {
+				if p.ID == id {
+					return p, nil
+				}
+			}
+			return nil, nil
+		},
+	}
+	imgRepo := &mocks.MockProductImageRepository{
+		ListByProductIDFunc: func(ctx context.Context, productID uuid.UUID) ([]*models.ProductImage, error) {
+			return images[productID], nil
+		},
+		CreateFunc: func(ctx context.Context, img *models.ProductImage) error {
+			img.ID = uuid.New()
+			images[img.ProductID] = append(images[img.ProductID], img)
+			return nil
+		},
+	}
+	storage = &memoryStorage{files: map[string]int{}}
+	svc = NewProductImageImportService(repo, NewProductService(repo, imgRepo, nil, nil, zap.NewNop()), NewProductImageProcessor(storage, nil, models.ProductImageSizes{Thumbnail: 200, Medium: 600, Large: 1200}, 80, zap.NewNop()), zap.NewNop()).(*productImageImportService)
+	return svc, storage, images, pharmacyID, catalog["AMX500"].ID
+}
+
+func TestProductImageImportService_Import_MatchesBySKUInOrder(t *testing.T) {
+	svc, storage, images, pharmacyID, amxID := newImageImportFixture()
+	files := []inbound.ImageUpload{
+		upload("photos/AMX500_2.jpg"),
+		upload("AMX500_10.PNG"),
+		upload("AMX500_1.jpg"),
+		upload("PCM_650.webp"),
+		upload("UNKNOWN_1.jpg"),
+		upload("notes.txt"),
+	}
+	res, err := svc.Import(context.Background(), pharmacyID, files, false)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if res.ImagesCreated != 4 || res.Products != 2 || len(storage.files) != 4 {
+		t.Fatalf("created %d images for %d products (%d saved), want 4 for 2", res.ImagesCreated, res.Products, len(storage.files))
+	}
+	got := []string{}
+	for _, m := range res.Matched {
+		got = append(got, m.File)
+	}
+	if want := "AMX500_1.jpg,photos/AMX500_2.jpg,AMX500_10.PNG,PCM_650.webp"; strings.Join(got, ",") != want {
+		t.Errorf("order = %v, want %s", got, want)
+	}
+	if imgs := images[amxID]; len(imgs) != 3 || !imgs[0].IsPrimary || imgs[1].IsPrimary || imgs[2].SortOrder != 2 {
+		t.Errorf("AMX500 images = %+v, want 3 in order with the first primary", imgs)
+	}
+	if res.Matched[3].SKU != "PCM_650" || res.Matched[3].Position != 0 {
+		t.Errorf("PCM_650.webp matched %q position %d, want whole stem as SKU", res.Matched[3].SKU, res.Matched[3].Position)
+	}
+	if len(res.Unmatched) != 2 || res.Unmatched[0].Reason != "no product with SKU UNKNOWN" {
+		t.Errorf("unmatched = %+v, want the unknown SKU and the text file", res.Unmatched)
+	}
+}
+
+func TestProductImageImportService_Import_DryRunStoresNothing(t *testing.T) {
+	svc, storage, images, pharmacyID, _ := newImageImportFixture()
+	res, err := svc.Import(context.Background(), pharmacyID, []inbound.ImageUpload{upload("VITC.jpg"), upload("VITC_3.jpg")}, true)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if !res.DryRun || len(res.Matched) != 2 || res.ImagesCreated != 0 || len(storage.files) != 0 || len(images) != 0 {
+		t.Errorf("dry run = %+v, saved %v; want 2 matches and nothing stored", res, storage.files)
+	}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"path"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/imaging"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// productImageUprightQuality is used when a photo has to be re-encoded to bake in its EXIF orientation.
+const productImageUprightQuality = 92
+
+type productImageProcessor struct {
+	storage outbound.FileStorage
+	webp    outbound.WebPEncoder
+	sizes   models.ProductImageSizes
+	quality int
+	logger  *zap.Logger
+}
+
+// NewProductImageProcessor builds the product image pipeline. webp may be nil, in which case the variants are
+// stored as JPEG at the same quality.
+func NewProductImageProcessor(storage outbound.FileStorage, webp outbound.WebPEncoder, sizes models.ProductImageSizes, quality int, logger *zap.Logger) inbound.ProductImageProcessor {
+	return &productImageProcessor{storage: storage, webp: webp, sizes: sizes, quality: quality, logger: logger}
+}
+
+func (p *productImageProcessor) Store(ctx context.Context, productID uuid.UUID, data []byte, ext, contentType string) (*models.ProductImage, error) {
+	base := path.Join("photos", "products", productID.String(), time.Now().Format("2006/01"), uuid.New().String())
+	out := &models.ProductImage{ProductID: productID}
+	img, _, err := imaging.Decode(bytes.NewReader(data))
+	if err != nil {
+		// WebP, SVG and anything else the standard library cannot decode is kept as uploaded.
+		if out.URL, err = p.storage.Save(ctx, base+ext, bytes.NewReader(data), contentType); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+
+	original := imaging.StripMetadata(data)
+	if o := imaging.Orientation(data); o != 1 {
+		// Without its EXIF orientation the photo would show sideways, so store it upright instead.
+		img = imaging.Orient(img, o)
+		if original, err = imaging.EncodeJPEG(img, productImageUprightQuality); err != nil {
+			return nil, err
+		}
+		ext, contentType = ".jpg", "image/jpeg"
+	}
+	if out.URL, err = p.storage.Save(ctx, base+ext, bytes.NewReader(original), contentType); err != nil {
+		return nil, err
+	}
+
+	// Variants are best effort: a missing one falls back to the original when served.
+	variants := []struct {
+		name string
+		size int
+		url  *string
+	}{
+		{"thumb", p.sizes.Thumbnail, &out.ThumbnailURL},
+		{"medium", p.sizes.Medium, &out.MediumURL},
+		{"large", p.sizes.Large, &out.LargeURL},
+	}
+	for _, v := range variants {
+		body, vext, vtype, err := p.encode(ctx, imaging.Fit(img, v.size, v.size))
+		if err == nil {
+			*v.url, err = p.storage.Save(ctx, base+"-"+v.name+vext, bytes.NewReader(body), vtype)
+		}
+		if err != nil {
+			p.logger.Warn("product image variant failed", zap.String("variant", v.name), zap.Error(err))
+		}
+	}
+	low, err := imaging.EncodeJPEG(imaging.Fit(img, models.LowImageSize, models.LowImageSize), models.LowImageQuality)
+	if err == nil {
+		out.LowURL, err = p.storage.Save(ctx, base+"-low.jpg", bytes.NewReader(low), "image/jpeg")
+	}
+	if err != nil {
+		p.logger.Warn("product image rendition save failed", zap.Error(err))
+	}
+	return out, nil
+}
+
+// encode returns img as WebP, or as JPEG when no WebP encoder is configured or it fails.
+func (p *productImageProcessor) encode(ctx context.Context, img image.Image) (data []byte, ext, contentType string, err error) {
+	if p.webp != nil {
+		if data, err = p.webp.EncodeWebP(ctx, img, p.quality); err == nil {
+			return data, ".webp", "image/webp", nil
+		}
+		p.logger.Warn("webp encoding failed, storing jpeg", zap.Error(err))
+	}
+	data, err = imaging.EncodeJPEG(img, p.quality)
+	return data, ".jpg", "image/jpeg", err
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakeWebP records the size of each image it encodes; fail makes it return an error instead.
+type fakeWebP struct {
+	sizes []image.Point
+	fail  bool
+}
+
+func (f *fakeWebP) EncodeWebP(ctx context.Context, img image.Image, quality int) ([]byte, error) {
+	if f.fail {
+		return nil, errors.New("cwebp exited 1")
+	}
+	f.sizes = append(f.sizes, img.Bounds().Size())
+	return []byte("RIFF....WEBP"), nil
+}
+
+func TestProductImageProcessor_StoresVariants(t *testing.T) {
+	ctx := context.Background()
+	productID := uuid.New()
+	sizes := models.ProductImageSizes{Thumbnail: 100, Medium: 300, Large: 600}
+	storage := &memoryStorage{files: map[string]int{}}
+	webp := &fakeWebP{}
+	p := NewProductImageProcessor(storage, webp, sizes, 80, zap.NewNop())
+
+	img, err := p.Store(ctx, productID, pngOfSize(t, 1000, 500).Bytes(), ".png", "image/png")
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	if !strings.HasSuffix(img.URL, ".png") || !strings.HasSuffix(img.LowURL, "-low.jpg") {
+		t.Errorf("original = %q, low = %q", img.URL, img.LowURL)
+	}
+	for name, url := range map[string]string{"-thumb.webp": img.ThumbnailURL, "-medium.webp": img.MediumURL, "-large.webp": img.LargeURL} {
+		if !strings.HasSuffix(url, name) || !strings.Contains(url, "photos/products/"+productID.String()+"/") {
+			t.Errorf("variant %s stored as %q", name, url)
+		}
+	}
+	want := []image.Point{{100, 50}, {300, 150}, {600, 300}}
+	if len(webp.sizes) != 3 || webp.sizes[0] != want[0] || webp.sizes[1] != want[1] || webp.sizes[2] != want[2] {
+		t.Errorf("encoded sizes = %v, want %v", webp.sizes, want)
+	}
+	if len(storage.files) != 5 {
+		t.Errorf("saved %d files, want original, 3 variants and low", len(storage.files))
+	}
+
+	webp.fail = true
+	img, err = p.Store(ctx, productID, pngOfSize(t, 400, 400).Bytes(), ".png", "image/png")
+	if err != nil || !strings.HasSuffix(img.MediumURL, "-medium.jpg") {
+		t.Errorf("failed webp: medium = %q, err = %v; want a jpeg fallback", img.MediumURL, err)
+	}
+
+	img, err = p.Store(ctx, productID, []byte("<svg/>"), ".svg", "image/svg+xml")
+	if err != nil || !strings.HasSuffix(img.URL, ".svg") || img.ThumbnailURL != "" || img.LowURL != "" {
+		t.Errorf("undecodable upload = %+v, err = %v; want the original only", img, err)
+	}
+}
@@ -2,7 +2,9 @@ package services
 
 import (
 	"context"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
@@ -15,11 +17,32 @@ import (
 type productService struct {
 	repo     outbound.ProductRepository
 	imageRepo outbound.ProductImageRepository
+	configRepo outbound.PharmacyConfigRepository
+	files    inbound.FileCleanupService
 	logger   *zap.Logger
 }
 
-func NewProductService(repo outbound.ProductRepository, imageRepo outbound.ProductImageRepository, logger *zap.Logger) inbound.ProductService {
-	return &productService{repo: repo, imageRepo: imageRepo, logger: logger}
+// NewProductService builds the product service. files may be nil; otherwise deleted images' files are released
+// to the orphan sweep.
+func NewProductService(repo outbound.ProductRepository, imageRepo outbound.ProductImageRepository, configRepo outbound.PharmacyConfigRepository, files inbound.FileCleanupService, logger *zap.Logger) inbound.ProductService {
+	return &productService{repo: repo, imageRepo: imageRepo, configRepo: configRepo, files: files, logger: logger}
+}
+
+// config returns the pharmacy's config, nil when it has none.
+func (s *productService) config(ctx context.Context, pharmacyID uuid.UUID) *models.PharmacyConfig {
+	if s.configRepo == nil {
+		return nil
+	}
+	cfg, _ := s.configRepo.GetByPharmacyID(ctx, pharmacyID)
+	return cfg
+}
+
+// attributeDefs returns the pharmacy's product attribute schema (none when unset).
+func (s *productService) attributeDefs(cfg *models.PharmacyConfig) []models.ProductAttributeDefinition {
+	if cfg == nil {
+		return nil
+	}
+	return cfg.ProductAttributes
 }
 
 func (s *productService) Create(ctx context.Context, p *models.Product) error {
@@ -29,13 +52,24 @@ func (s *productService) Create(ctx context.Context, p *models.Product) error {
 	if p.SKU == "" {
 		return errors.ErrValidation("SKU is required")
 	}
+	if msg := models.ValidateTranslations(p.Translations); msg != "" {
+		return errors.ErrValidation(msg)
+	}
+	if err := validateStockLevels(p); err != nil {
+		return err
+	}
+	cfg := s.config(ctx, p.PharmacyID)
+	attrs, err := resolveProductAttributes(s.attributeDefs(cfg), p.Attributes)
+	if err != nil {
+		return err
+	}
+	p.Attributes = attrs
 	existing, _ := s.repo.GetBySKU(ctx, p.PharmacyID, p.SKU)
 	if existing != nil {
 		return errors.ErrConflict("product with this SKU already exists")
 	}
-	if p.Currency == "" {
-		p.Currency = "NPR"
-	}
+	// Prices are in the pharmacy's base currency; the field records which one.
+	p.Currency = cfg.Currency()
 	if p.Unit == "" {
 		p.Unit = "units"
 	}
@@ -46,6 +80,35 @@ func (s *productService) GetByID(ctx context.Context, id uuid.UUID) (*models.Pro
 	return s.repo.GetByID(ctx, id)
 }
 
+func (s *productService) Alternatives(ctx context.Context, productID uuid.UUID, limit int) (*models.Product, []*models.Product, error) {
+	p, err := s.repo.GetByID(ctx, productID)
+	if err != nil || p == nil || !p.IsActive {
+		return nil, nil, errors.ErrNotFound("product")
+	}
+	if limit <= 0 {
+		limit = 10
+	} else if limit > 50 {
+		limit = 50
+	}
+	list, err := s.repo.ListAlternatives(ctx, p, limit)
+	if err != nil {
+		return nil, nil, errors.ErrInternal("failed to list alternatives", err)
+	}
+	return p, list, nil
+}
+
+func (s *productService) AlternativeFlags(ctx context.Context, productIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	ids, err := s.repo.WithAlternatives(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	flags := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		flags[id] = true
+	}
+	return flags, nil
+}
+
 func (s *productService) GetByBarcode(ctx context.Context, pharmacyID uuid.UUID, barcode string) (*models.Product, error) {
 	return s.repo.GetByBarcode(ctx, pharmacyID, barcode)
 }
@@ -63,42 +126,205 @@ func (s *productService) ListCatalog(ctx context.Context, pharmacyID uuid.UUID,
 	if sortOut != outbound.CatalogSortPriceAsc && sortOut != outbound.CatalogSortPriceDesc && sortOut != outbound.CatalogSortNewest {
 		sortOut = outbound.CatalogSortName
 	}
-	var outFilters *outbound.CatalogFilters
-	if filters != nil {
-		outFilters = &outbound.CatalogFilters{
-			Hashtag:    filters.Hashtag,
-			Brand:      filters.Brand,
-			LabelKey:   filters.LabelKey,
-			LabelValue: filters.LabelValue,
+	out, err := s.outboundFilters(ctx, pharmacyID, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListByPharmacyCatalog(ctx, pharmacyID, category, inStockOnly, strings.TrimSpace(searchQ), sortOut, limit, offset, out)
+}
+
+// outboundFilters converts catalog filters, typing attribute filters with the pharmacy's attribute schema.
+func (s *productService) outboundFilters(ctx context.Context, pharmacyID uuid.UUID, filters *inbound.CatalogFilters) (*outbound.CatalogFilters, error) {
+	out := toOutboundFilters(filters)
+	if filters != nil && len(filters.Attributes) > 0 {
+		attrs, err := catalogAttributeFilter(s.attributeDefs(s.config(ctx, pharmacyID)), filters.Attributes)
+		if err != nil {
+			return nil, err
 		}
+		out.Attributes = attrs
+	}
+	return out, nil
+}
+
+func toOutboundFilters(filters *inbound.CatalogFilters) *outbound.CatalogFilters {
+	if filters == nil {
+		return nil
+	}
+	return &outbound.CatalogFilters{
+		Hashtag:    filters.Hashtag,
+		Brand:      filters.Brand,
+		LabelKey:   filters.LabelKey,
+		LabelValue: filters.LabelValue,
+		DosageForm: filters.DosageForm,
+		MinPrice:   filters.MinPrice,
+		MaxPrice:   filters.MaxPrice,
+	}
+}
+
+// catalogPriceEdges are the storefront price bucket boundaries (in the pharmacy currency, NPR by default).
+var catalogPriceEdges = []float64{100, 250, 500, 1000, 2500}
+
+// productDeltaSettle keeps the delta window this far behind now so rows from transactions still in flight
+// (committed with an earlier updated_at) are not skipped by a cursor that has already moved past them.
+const productDeltaSettle = 5 * time.Second
+
+func (s *productService) Delta(ctx context.Context, pharmacyID uuid.UUID, since time.Time, afterID uuid.UUID, limit int) (*inbound.ProductDeltaPage, error) {
+	now := time.Now().UTC()
+	until := now.Add(-productDeltaSettle)
+	if since.After(until) {
+		return &inbound.ProductDeltaPage{Items: []*models.ProductDelta{}, NextSince: since, ServerTime: now}, nil
+	}
+	rows, err := s.repo.ListChangedSince(ctx, pharmacyID, since, afterID, until, limit)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load product changes", err)
 	}
-	return s.repo.ListByPharmacyCatalog(ctx, pharmacyID, category, inStockOnly, strings.TrimSpace(searchQ), sortOut, limit, offset, outFilters)
+	page := &inbound.ProductDeltaPage{Items: rows, NextSince: until, ServerTime: now}
+	if page.Items == nil {
+		page.Items = []*models.ProductDelta{}
+	}
+	if limit > 0 && len(rows) == limit {
+		last := rows[len(rows)-1]
+		page.NextSince = last.ChangedAt
+		page.NextAfter = &last.ID
+		page.HasMore = true
+	}
+	return page, nil
+}
+
+// catalogFacetLimit caps the values returned per text facet (brands and hashtags can be long-tailed).
+const catalogFacetLimit = 50
+
+func (s *productService) CatalogFacets(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, searchQ string, filters *inbound.CatalogFilters) (*models.CatalogFacets, error) {
+	if filters != nil && filters.MinPrice != nil && filters.MaxPrice != nil && *filters.MinPrice >= *filters.MaxPrice {
+		return nil, errors.ErrValidation("min_price must be below max_price")
+	}
+	out, err := s.outboundFilters(ctx, pharmacyID, filters)
+	if err != nil {
+		return nil, err
+	}
+	facets, err := s.repo.CatalogFacets(ctx, pharmacyID, category, inStockOnly, strings.TrimSpace(searchQ), out, catalogPriceEdges, catalogFacetLimit)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load catalog facets", err)
+	}
+	for i := range facets.PriceBuckets {
+		b := &facets.PriceBuckets[i]
+		switch {
+		case i == 0 && b.Max != nil:
+			b.Label = "Under " + formatPrice(*b.Max)
+		case b.Max == nil:
+			b.Label = formatPrice(b.Min) + "+"
+		default:
+			b.Label = formatPrice(b.Min) + "-" + formatPrice(*b.Max)
+		}
+	}
+	return facets, nil
+}
+
+func formatPrice(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
 }
 
 func (s *productService) Update(ctx context.Context, p *models.Product) error {
 	if p.ID == uuid.Nil {
 		return errors.ErrValidation("product ID is required")
 	}
+	if msg := models.ValidateTranslations(p.Translations); msg != "" {
+		return errors.ErrValidation(msg)
+	}
+	if err := validateStockLevels(p); err != nil {
+		return err
+	}
+	existing, _ := s.repo.GetByID(ctx, p.ID)
+	if p.Translations == nil && existing != nil {
+		// Omitted on update = keep; {} clears them.
+		p.Translations = existing.Translations
+	}
+	cfg := s.config(ctx, p.PharmacyID)
+	attrs, err := resolveProductAttributes(s.attributeDefs(cfg), p.Attributes)
+	if err != nil {
+		return err
+	}
+	p.Attributes = attrs
+	// The price keeps the currency it was entered in. A base currency change does not convert prices, so
+	// relabelling here would turn NPR 500 into USD 500 on the next edit.
+	p.Currency = cfg.Currency()
+	if existing != nil && existing.Currency != "" {
+		p.Currency = existing.Currency
+	}
 	return s.repo.Update(ctx, p)
 }
 
+// validateStockLevels checks that max_stock leaves room above reorder_level and holds at least one reorder lot.
+func validateStockLevels(p *models.Product) error {
+	if p.MaxStock == nil {
+		return nil
+	}
+	if p.ReorderLevel != nil && *p.MaxStock <= *p.ReorderLevel {
+		return errors.ErrValidation("max_stock must be greater than reorder_level")
+	}
+	if p.ReorderQuantity != nil && *p.MaxStock < *p.ReorderQuantity {
+		return errors.ErrValidation("max_stock must be at least reorder_quantity")
+	}
+	return nil
+}
+
+func (s *productService) RenameBrand(ctx context.Context, pharmacyID uuid.UUID, from, to string) (int64, error) {
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if from == "" || to == "" {
+		return 0, errors.ErrValidation("from and to brands are required")
+	}
+	if len(to) > 150 {
+		return 0, errors.ErrValidation("brand must be at most 150 characters")
+	}
+	n, err := s.repo.RenameBrand(ctx, pharmacyID, from, to)
+	if err != nil {
+		return 0, errors.ErrInternal("failed to rename brand", err)
+	}
+	s.logger.Info("brand renamed", zap.String("pharmacy_id", pharmacyID.String()), zap.String("from", from), zap.String("to", to), zap.Int64("products", n))
+	return n, nil
+}
+
 func (s *productService) UpdateStock(ctx context.Context, productID uuid.UUID, quantity int) error {
 	p, err := s.repo.GetByID(ctx, productID)
 	if err != nil || p == nil {
 		return errors.ErrNotFound("product")
 	}
-	p.StockQuantity += quantity
-	if p.StockQuantity < 0 {
+	if p.StockQuantity+quantity < 0 {
 		return errors.ErrValidation("stock cannot be negative")
 	}
-	return s.repo.Update(ctx, p)
+	ok, err := s.repo.AdjustStock(ctx, p.ID, quantity)
+	if err != nil {
+		return errors.ErrInternal("failed to update stock", err)
+	}
+	if !ok {
+		return errors.ErrConflict("stock changed meanwhile and would go negative; reload and try again")
+	}
+	p.StockQuantity += quantity
+	return nil
 }
 
+// Delete removes the product along with its images, whose files go to the orphan sweep.
 func (s *productService) Delete(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+	images, err := s.imageRepo.ListByProductID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	var files []string
+	for _, img := range images {
+		if err := s.imageRepo.Delete(ctx, img.ID); err != nil {
+			s.logger.Warn("failed to delete image of deleted product", zap.String("image_id", img.ID.String()), zap.Error(err))
+			continue
+		}
+		files = append(files, img.Files()...)
+	}
+	releaseFiles(ctx, s.files, s.logger, files...)
+	return nil
 }
 
-func (s *productService) AddImage(ctx context.Context, productID uuid.UUID, url string, isPrimary bool) (*models.ProductImage, error) {
+func (s *productService) AddImage(ctx context.Context, productID uuid.UUID, stored *models.ProductImage, isPrimary bool) (*models.ProductImage, error) {
 	p, err := s.repo.GetByID(ctx, productID)
 	if err != nil || p == nil {
 		return nil, errors.ErrNotFound("product")
@@ -113,7 +339,8 @@ func (s *productService) AddImage(ctx context.Context, productID uuid.UUID, url
 		isPrimary = true
 	}
 	sortOrder := len(existing)
-	img := &models.ProductImage{ProductID: productID, URL: url, IsPrimary: isPrimary, SortOrder: sortOrder}
+	img := &models.ProductImage{ProductID: productID, URL: stored.URL, LowURL: stored.LowURL, ThumbnailURL: stored.ThumbnailURL,
+		MediumURL: stored.MediumURL, LargeURL: stored.LargeURL, IsPrimary: isPrimary, SortOrder: sortOrder}
 	if err := s.imageRepo.Create(ctx, img); err != nil {
 		return nil, err
 	}
@@ -162,6 +389,7 @@ func (s *productService) DeleteImage(ctx context.Context, productID, imageID uui
 	if err := s.imageRepo.Delete(ctx, imageID); err != nil {
 		return err
 	}
+	releaseFiles(ctx, s.files, s.logger, img.Files()...)
 	if img.IsPrimary {
 		remaining, _ := s.imageRepo.ListByProductID(ctx, productID)
 		if len(remaining) > 0 {
@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -27,7 +30,7 @@ func TestProductService_Create_Success(t *testing.T) {
 	}
 
 	imgRepo := &mocks.MockProductImageRepository{}
-	svc := NewProductService(repo, imgRepo, logger)
+	svc := NewProductService(repo, imgRepo, nil, nil, logger)
 	pharmacyID := uuid.New()
 	p := &models.Product{PharmacyID: pharmacyID, Name: "Product A", SKU: "SKU-001", UnitPrice: 10.5}
 	err := svc.Create(ctx, p)
@@ -52,7 +55,7 @@ func TestProductService_Create_Validation_NameRequired(t *testing.T) {
 	}
 
 	imgRepo := &mocks.MockProductImageRepository{}
-	svc := NewProductService(repo, imgRepo, logger)
+	svc := NewProductService(repo, imgRepo, nil, nil, logger)
 	err := svc.Create(ctx, &models.Product{PharmacyID: uuid.New(), SKU: "SKU-1", UnitPrice: 1})
 	if err == nil {
 		t.Fatal("expected validation error for empty name")
@@ -81,7 +84,7 @@ func TestProductService_Create_SKUConflict(t *testing.T) {
 	}
 
 	imgRepo := &mocks.MockProductImageRepository{}
-	svc := NewProductService(repo, imgRepo, logger)
+	svc := NewProductService(repo, imgRepo, nil, nil, logger)
 	err := svc.Create(ctx, &models.Product{PharmacyID: pharmacyID, Name: "X", SKU: "SKU-EXISTS", UnitPrice: 1})
 	if err == nil {
 		t.Fatal("expected conflict error for duplicate SKU")
@@ -107,7 +110,7 @@ func TestProductService_GetByID_Success(t *testing.T) {
 	}
 
 	imgRepo := &mocks.MockProductImageRepository{}
-	svc := NewProductService(repo, imgRepo, logger)
+	svc := NewProductService(repo, imgRepo, nil, nil, logger)
 	got, err := svc.GetByID(ctx, id)
 	if err != nil {
 		t.Fatalf("GetByID failed: %v", err)
@@ -135,7 +138,7 @@ func TestProductService_List_Success(t *testing.T) {
 	}
 
 	imgRepo := &mocks.MockProductImageRepository{}
-	svc := NewProductService(repo, imgRepo, logger)
+	svc := NewProductService(repo, imgRepo, nil, nil, logger)
 	got, err := svc.List(ctx, pharmacyID, nil, nil)
 	if err != nil {
 		t.Fatalf("List failed: %v", err)
@@ -158,20 +161,34 @@ func TestProductService_UpdateStock_Success(t *testing.T) {
 		}
 		return nil, errors.New("not found")
 	}
-	var updated *models.Product
-	repo.UpdateFunc = func(ctx context.Context, prod *models.Product) error {
-		updated = prod
-		return nil
+	stock := p.StockQuantity
+	repo.AdjustStockFunc = func(ctx context.Context, id uuid.UUID, delta int) (bool, error) {
+		stock += delta
+		return true, nil
 	}
 
 	imgRepo := &mocks.MockProductImageRepository{}
-	svc := NewProductService(repo, imgRepo, logger)
+	svc := NewProductService(repo, imgRepo, nil, nil, logger)
 	err := svc.UpdateStock(ctx, productID, 5)
 	if err != nil {
 		t.Fatalf("UpdateStock failed: %v", err)
 	}
-	if updated.StockQuantity != 15 {
-		t.Errorf("expected stock 15, got %d", updated.StockQuantity)
+	if stock != 15 {
+		t.Errorf("expected stock 15, got %d", stock)
+	}
+}
+
+func TestProductService_UpdateStock_ConcurrentChangeIsConflict(t *testing.T) {
+	repo := &mocks.MockProductRepository{}
+	p := &models.Product{ID: uuid.New(), StockQuantity: 4}
+	repo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*models.Product, error) { return p, nil }
+	// Another sale took the stock after it was read, so the guarded update matches no row.
+	repo.AdjustStockFunc = func(ctx context.Context, id uuid.UUID, delta int) (bool, error) { return false, nil }
+
+	svc := NewProductService(repo, &mocks.MockProductImageRepository{}, nil, nil, zap.NewNop())
+	err := svc.UpdateStock(context.Background(), p.ID, -4)
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeConflict {
+		t.Fatalf("err = %v, want conflict", err)
 	}
 }
 
@@ -188,7 +205,7 @@ func TestProductService_UpdateStock_ProductNotFound(t *testing.T) {
 	}
 
 	imgRepo := &mocks.MockProductImageRepository{}
-	svc := NewProductService(repo, imgRepo, logger)
+	svc := NewProductService(repo, imgRepo, nil, nil, logger)
 	err := svc.UpdateStock(ctx, uuid.New(), 5)
 	if err == nil {
 		t.Fatal("expected not found error")
@@ -215,7 +232,7 @@ func TestProductService_Delete_Success(t *testing.T) {
 	}
 
 	imgRepo := &mocks.MockProductImageRepository{}
-	svc := NewProductService(repo, imgRepo, logger)
+	svc := NewProductService(repo, imgRepo, nil, nil, logger)
 	err := svc.Delete(ctx, id)
 	if err != nil {
 		t.Fatalf("Delete failed: %v", err)
@@ -224,3 +241,243 @@ func TestProductService_Delete_Success(t *testing.T) {
 		t.Error("expected Delete to be called")
 	}
 }
+
+func TestProductService_Update_MaxStockMustExceedReorderLevel(t *testing.T) {
+	svc := NewProductService(&mocks.MockProductRepository{}, &mocks.MockProductImageRepository{}, nil, nil, zap.NewNop())
+	level, maxStock := 20, 20
+	err := svc.Update(context.Background(), &models.Product{ID: uuid.New(), Name: "A", SKU: "A", ReorderLevel: &level, MaxStock: &maxStock})
+	appErr := pkgerrors.GetAppError(err)
+	if appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestProductService_RenameBrand_TrimsAndValidates(t *testing.T) {
+	var from, to string
+	repo := &mocks.MockProductRepository{
+		RenameBrandFunc: func(ctx context.Context, pharmacyID uuid.UUID, f, t string) (int64, error) {
+			from, to = f, t
+			return 4, nil
+		},
+	}
+	svc := NewProductService(repo, &mocks.MockProductImageRepository{}, nil, nil, zap.NewNop())
+	n, err := svc.RenameBrand(context.Background(), uuid.New(), "  cipla ", " Cipla Ltd ")
+	if err != nil || n != 4 {
+		t.Fatalf("RenameBrand: %d, %v", n, err)
+	}
+	if from != "cipla" || to != "Cipla Ltd" {
+		t.Errorf("expected trimmed brands, got %q -> %q", from, to)
+	}
+	if _, err := svc.RenameBrand(context.Background(), uuid.New(), "Cipla", "  "); pkgerrors.GetAppError(err) == nil {
+		t.Errorf("expected validation error for an empty target brand, got %v", err)
+	}
+}
+
+func TestProductService_CatalogFacets_LabelsPriceBuckets(t *testing.T) {
+	var gotEdges []float64
+	var gotFilters *outbound.CatalogFilters
+	repo := &mocks.MockProductRepository{
+		CatalogFacetsFunc: func(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, searchQ string, filters *outbound.CatalogFilters, priceEdges []float64, facetLimit int) (*models.CatalogFacets, error) {
+			gotEdges, gotFilters = priceEdges, filters
+			upper := 100.0
+			return &models.CatalogFacets{PriceBuckets: []models.PriceBucketCount{
+				{Min: 0, Max: &upper, Count: 4},
+				{Min: 100, Count: 2},
+			}}, nil
+		},
+	}
+	svc := NewProductService(repo, &mocks.MockProductImageRepository{}, nil, nil, zap.NewNop())
+	form := "syrup"
+	facets, err := svc.CatalogFacets(context.Background(), uuid.New(), nil, nil, " cough ", &inbound.CatalogFilters{DosageForm: &form})
+	if err != nil {
+		t.Fatalf("CatalogFacets: %v", err)
+	}
+	if len(gotEdges) == 0 || gotFilters == nil || gotFilters.DosageForm == nil || *gotFilters.DosageForm != "syrup" {
+		t.Errorf("expected price edges and the dosage form filter to reach the repository")
+	}
+	if facets.PriceBuckets[0].Label != "Under 100" || facets.PriceBuckets[1].Label != "100+" {
+		t.Errorf("unexpected bucket labels: %q, %q", facets.PriceBuckets[0].Label, facets.PriceBuckets[1].Label)
+	}
+
+	lo, hi := 500.0, 100.0
+	_, err = svc.CatalogFacets(context.Background(), uuid.New(), nil, nil, "", &inbound.CatalogFilters{MinPrice: &lo, MaxPrice: &hi})
+	if pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("expected validation error for an empty price range, got %v", err)
+	}
+}
+
+func TestProductService_Delta_CursorAdvances(t *testing.T) {
+	ctx := context.Background()
+	changed := time.Now().Add(-time.Minute)
+	rows := []*models.ProductDelta{{ID: uuid.New(), ChangedAt: changed.Add(-time.Second)}, {ID: uuid.New(), ChangedAt: changed}}
+	var gotUntil time.Time
+	repo := &mocks.MockProductRepository{
+		ListChangedSinceFunc: func(ctx context.Context, pharmacyID uuid.UUID, since time.Time, afterID uuid.UUID, until time.Time, limit int) ([]*models.ProductDelta, error) {
+			gotUntil = until
+			if limit < len(rows) {
+				return rows[:limit], nil
+			}
+			return rows, nil
+		},
+	}
+	svc := NewProductService(repo, nil, nil, nil, zap.NewNop())
+
+	full, err := svc.Delta(ctx, uuid.New(), time.Time{}, uuid.Nil, 2)
+	if err != nil {
+		t.Fatalf("Delta: %v", err)
+	}
+	if !full.HasMore || !full.NextSince.Equal(changed) || full.NextAfter == nil || *full.NextAfter != rows[1].ID {
+		t.Errorf("full page cursor = %v/%v more=%v, want last row", full.NextSince, full.NextAfter, full.HasMore)
+	}
+	if !gotUntil.Before(full.ServerTime) {
+		t.Errorf("until %v should trail server time %v", gotUntil, full.ServerTime)
+	}
+
+	partial, err := svc.Delta(ctx, uuid.New(), changed, rows[1].ID, 5)
+	if err != nil {
+		t.Fatalf("Delta: %v", err)
+	}
+	if partial.HasMore || partial.NextAfter != nil || !partial.NextSince.Equal(gotUntil) {
+		t.Errorf("partial page cursor = %v/%v more=%v, want window end", partial.NextSince, partial.NextAfter, partial.HasMore)
+	}
+
+	future, _ := svc.Delta(ctx, uuid.New(), time.Now().Add(time.Hour), uuid.Nil, 5)
+	if len(future.Items) != 0 || future.HasMore {
+		t.Errorf("cursor ahead of the window should return nothing, got %+v", future)
+	}
+}
+
+func TestProductService_Attributes_ValidatedAndFilterable(t *testing.T) {
+	ctx := context.Background()
+	pharmacyID := uuid.New()
+	configs := &mocks.MockPharmacyConfigRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, id uuid.UUID) (*models.PharmacyConfig, error) {
+			return &models.PharmacyConfig{ProductAttributes: []models.ProductAttributeDefinition{
+				{Key: "cold_chain", Label: "Requires cold chain", Type: models.CustomFieldBoolean, Required: true, Filterable: true},
+				{Key: "system", Label: "System", Type: models.CustomFieldSelect, Options: []string{"Ayurvedic", "Allopathic"}},
+			}}, nil
+		},
+	}
+	var created *models.Product
+	var gotFilters *outbound.CatalogFilters
+	repo := &mocks.MockProductRepository{
+		CreateFunc: func(ctx context.Context, p *models.Product) error { created = p; return nil },
+		ListByPharmacyCatalogFunc: func(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, searchQ string, sort outbound.CatalogSort, limit, offset int, filters *outbound.CatalogFilters) ([]*models.Product, int64, error) {
+			gotFilters = filters
+			return nil, 0, nil
+		},
+	}
+	svc := NewProductService(repo, &mocks.MockProductImageRepository{}, configs, nil, zap.NewNop())
+
+	p := &models.Product{PharmacyID: pharmacyID, Name: "Insulin", SKU: "INS-1", Attributes: models.CustomFieldValues{"cold_chain": true, "system": "Allopathic"}}
+	if err := svc.Create(ctx, p); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created.Attributes["cold_chain"] != true || created.Attributes["system"] != "Allopathic" {
+		t.Errorf("attributes = %v", created.Attributes)
+	}
+	for name, attrs := range map[string]models.CustomFieldValues{
+		"missing required": {"system": "Ayurvedic"},
+		"unknown key":      {"cold_chain": false, "origin": "local"},
+		"bad option":       {"cold_chain": false, "system": "Homeopathic"},
+	} {
+		err := svc.Create(ctx, &models.Product{PharmacyID: pharmacyID, Name: "X", SKU: "X-" + name, Attributes: attrs})
+		if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+			t.Errorf("%s: err = %v, want validation error", name, err)
+		}
+	}
+
+	if _, _, err := svc.ListCatalog(ctx, pharmacyID, nil, nil, "", "", 20, 0, &inbound.CatalogFilters{Attributes: map[string]string{"cold_chain": "yes"}}); err != nil {
+		t.Fatalf("ListCatalog: %v", err)
+	}
+	if gotFilters == nil || gotFilters.Attributes["cold_chain"] != true {
+		t.Errorf("attribute filter = %+v, want typed true", gotFilters)
+	}
+	_, _, err := svc.ListCatalog(ctx, pharmacyID, nil, nil, "", "", 20, 0, &inbound.CatalogFilters{Attributes: map[string]string{"system": "Ayurvedic"}})
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("err = %v, want validation error for a non-filterable attribute", err)
+	}
+}
+
+func TestProductService_Alternatives_ClampsLimitAndHidesInactive(t *testing.T) {
+	ctx := context.Background()
+	active := &models.Product{ID: uuid.New(), IsActive: true, GenericName: "Paracetamol", UnitPrice: 50}
+	cheaper := &models.Product{ID: uuid.New(), IsActive: true, GenericName: "paracetamol", UnitPrice: 30}
+	var gotLimit int
+	repo := &mocks.MockProductRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+			if id == active.ID {
+				return active, nil
+			}
+			return &models.Product{ID: id, IsActive: false}, nil
+		},
+		ListAlternativesFunc: func(ctx context.Context, p *models.Product, limit int) ([]*models.Product, error) {
+			gotLimit = limit
+			return []*models.Product{cheaper}, nil
+		},
+	}
+	svc := NewProductService(repo, &mocks.MockProductImageRepository{}, nil, nil, zap.NewNop())
+
+	p, list, err := svc.Alternatives(ctx, active.ID, 500)
+	if err != nil {
+		t.Fatalf("Alternatives: %v", err)
+	}
+	if p != active || len(list) != 1 || list[0] != cheaper || gotLimit != 50 {
+		t.Errorf("expected the product, its cheaper substitute and limit 50, got %v %v %d", p, list, gotLimit)
+	}
+	if _, _, err := svc.Alternatives(ctx, uuid.New(), 0); err == nil {
+		t.Error("expected inactive products to be not found")
+	}
+}
+
+func TestProductService_AlternativeFlags(t *testing.T) {
+	withAlt, without := uuid.New(), uuid.New()
+	repo := &mocks.MockProductRepository{
+		WithAlternativesFunc: func(ctx context.Context, productIDs []uuid.UUID) ([]uuid.UUID, error) {
+			return []uuid.UUID{withAlt}, nil
+		},
+	}
+	svc := NewProductService(repo, &mocks.MockProductImageRepository{}, nil, nil, zap.NewNop())
+
+	flags, err := svc.AlternativeFlags(context.Background(), []uuid.UUID{withAlt, without})
+	if err != nil {
+		t.Fatalf("AlternativeFlags: %v", err)
+	}
+	if !flags[withAlt] || flags[without] {
+		t.Errorf("unexpected flags %v", flags)
+	}
+}
+
+func TestProductService_Update_KeepsTranslations(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+	ne := models.Translations{"ne": {Title: "सिटामोल", Body: "ज्वरो र दुखाइका लागि"}}
+	var saved *models.Product
+	repo := &mocks.MockProductRepository{
+		GetByIDFunc: func(ctx context.Context, pid uuid.UUID) (*models.Product, error) {
+			return &models.Product{ID: id, Name: "Cetamol", Translations: ne}, nil
+		},
+		UpdateFunc: func(ctx context.Context, p *models.Product) error {
+			saved = p
+			return nil
+		},
+	}
+	svc := NewProductService(repo, &mocks.MockProductImageRepository{}, nil, nil, zap.NewNop())
+
+	if err := svc.Update(ctx, &models.Product{ID: id, Name: "Cetamol 500"}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if saved.Translations["ne"].Title != "सिटामोल" {
+		t.Errorf("update without translations: saved %v, want the existing ones kept", saved.Translations)
+	}
+	err := svc.Update(ctx, &models.Product{ID: id, Name: "Cetamol", Translations: models.Translations{"ne": {Body: "no title"}}})
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("translation without a name: err = %v, want validation", err)
+	}
+
+	p := &models.Product{Name: "Cetamol", Description: "For fever and pain", Translations: ne}
+	p.Localize("ne")
+	if p.Name != "सिटामोल" || p.Description != "ज्वरो र दुखाइका लागि" {
+		t.Errorf("localized product = %q (%q)", p.Name, p.Description)
+	}
+}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -9,6 +11,7 @@ import (
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/careplus/pharmacy-backend/pkg/tracing"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -23,7 +26,9 @@ func NewPromoCodeService(repo outbound.PromoCodeRepository, orderRepo outbound.O
 	return &promoCodeService{repo: repo, orderRepo: orderRepo, logger: logger}
 }
 
-func (s *promoCodeService) Validate(ctx context.Context, pharmacyID uuid.UUID, code string, subTotal float64, userID *uuid.UUID) (*inbound.PromoCodeValidateResult, error) {
+func (s *promoCodeService) Validate(ctx context.Context, pharmacyID uuid.UUID, code string, subTotal float64, lines []inbound.PromoLine, userID *uuid.UUID) (_ *inbound.PromoCodeValidateResult, err error) {
+	ctx, span := tracing.Start(ctx, "PromoCodeService.Validate")
+	defer tracing.End(span, &err)
 	code = strings.TrimSpace(strings.ToUpper(code))
 	if code == "" {
 		return nil, errors.ErrValidation("promo code is required")
@@ -54,23 +59,142 @@ func (s *promoCodeService) Validate(ctx context.Context, pharmacyID uuid.UUID, c
 			return nil, errors.ErrValidation("this code is for first order only")
 		}
 	}
+	if p.MaxUsesPerUser > 0 {
+		if userID == nil {
+			return nil, errors.ErrValidation("this code is limited per customer; please log in")
+		}
+		used, err := s.orderRepo.CountByPromoCodeAndUser(ctx, p.ID, *userID)
+		if err != nil {
+			return nil, errors.ErrInternal("failed to check promo code usage", err)
+		}
+		if used >= int64(p.MaxUsesPerUser) {
+			return nil, errors.ErrValidation("you have already used this promo code the maximum number of times")
+		}
+	}
 	if p.MinOrderAmount > 0 && subTotal < p.MinOrderAmount {
 		return nil, errors.ErrValidation("order subtotal is below minimum for this promo")
 	}
-	discount := s.computeDiscount(p, subTotal)
+	eligible, matched, err := promoEligibleLines(p, subTotal, lines)
+	if err != nil {
+		return nil, err
+	}
+	discount := s.computeDiscount(p, eligible)
 	if discount <= 0 {
 		return nil, errors.ErrValidation("promo does not apply to this order")
 	}
-	if discount > subTotal {
-		discount = subTotal
+	if discount > eligible {
+		discount = eligible
+	}
+	stacking := p.Stacking
+	if stacking == "" {
+		stacking = models.PromoStackingCombinable
 	}
 	return &inbound.PromoCodeValidateResult{
-		Code:           p.Code,
-		DiscountAmount: discount,
-		PromoCodeID:    p.ID,
+		Code:             p.Code,
+		DiscountAmount:   discount,
+		PromoCodeID:      p.ID,
+		Stacking:         stacking,
+		EligibleSubTotal: eligible,
+		Lines:            splitPromoDiscount(discount, matched),
 	}, nil
 }
 
+// promoEligibleLines returns the subtotal the code applies to and the lines it covers. Without lines only
+// unscoped codes can be checked, against the whole subtotal.
+func promoEligibleLines(p *models.PromoCode, subTotal float64, lines []inbound.PromoLine) (float64, []inbound.PromoLine, error) {
+	if lines == nil {
+		if p.Scoped() || p.MinItemQuantity > 0 {
+			return 0, nil, errors.ErrValidation("this code depends on the items in the order; apply it to your cart")
+		}
+		return subTotal, nil, nil
+	}
+	products := make(map[uuid.UUID]bool, len(p.ProductIDs))
+	for _, id := range p.ProductIDs {
+		products[id] = true
+	}
+	categories := make(map[uuid.UUID]bool, len(p.CategoryIDs))
+	for _, id := range p.CategoryIDs {
+		categories[id] = true
+	}
+	var eligible float64
+	var units int
+	var matched []inbound.PromoLine
+	for _, l := range lines {
+		ok := !p.Scoped() || products[l.ProductID]
+		for _, c := range l.CategoryIDs {
+			ok = ok || categories[c]
+		}
+		if !ok {
+			continue
+		}
+		eligible += l.Amount
+		units += l.Quantity
+		matched = append(matched, l)
+	}
+	if len(matched) == 0 {
+		return 0, nil, errors.ErrValidation("promo code does not apply to any item in this order")
+	}
+	if p.MinItemQuantity > 0 && units < p.MinItemQuantity {
+		return 0, nil, errors.ErrValidation(fmt.Sprintf("add at least %d qualifying items to use this promo code", p.MinItemQuantity))
+	}
+	return eligible, matched, nil
+}
+
+// splitPromoDiscount shares discount across lines in proportion to their amounts, per product and rounded to the
+// cent; the last product takes the rounding remainder.
+func splitPromoDiscount(discount float64, lines []inbound.PromoLine) []inbound.PromoLineDiscount {
+	var total float64
+	var order []uuid.UUID
+	amounts := make(map[uuid.UUID]float64)
+	for _, l := range lines {
+		if _, seen := amounts[l.ProductID]; !seen {
+			order = append(order, l.ProductID)
+		}
+		amounts[l.ProductID] += l.Amount
+		total += l.Amount
+	}
+	if total <= 0 {
+		return nil
+	}
+	out := make([]inbound.PromoLineDiscount, 0, len(order))
+	left := discount
+	for i, id := range order {
+		share := math.Round(discount*amounts[id]/total*100) / 100
+		if i == len(order)-1 {
+			share = math.Round(left*100) / 100
+		}
+		left -= share
+		out = append(out, inbound.PromoLineDiscount{ProductID: id, Amount: share})
+	}
+	return out
+}
+
+// promoLine describes a priced line for promo scoping; p.CategoryDetail, when loaded, adds the parent category.
+func promoLine(p *models.Product, quantity int, amount float64) inbound.PromoLine {
+	l := inbound.PromoLine{ProductID: p.ID, Quantity: quantity, Amount: amount}
+	if p.CategoryID != nil {
+		l.CategoryIDs = append(l.CategoryIDs, *p.CategoryID)
+	}
+	if p.CategoryDetail != nil && p.CategoryDetail.ParentID != nil {
+		l.CategoryIDs = append(l.CategoryIDs, *p.CategoryDetail.ParentID)
+	}
+	return l
+}
+
+// checkPromoRules validates and defaults the usage rules shared by Create and Update.
+func checkPromoRules(p *models.PromoCode) error {
+	if p.Stacking == "" {
+		p.Stacking = models.PromoStackingCombinable
+	}
+	if p.Stacking != models.PromoStackingCombinable && p.Stacking != models.PromoStackingExclusive {
+		return errors.ErrValidation("stacking must be combinable or exclusive")
+	}
+	if p.MaxUses < 0 || p.MaxUsesPerUser < 0 || p.MinItemQuantity < 0 {
+		return errors.ErrValidation("max_uses, max_uses_per_user and min_item_quantity cannot be negative")
+	}
+	return nil
+}
+
 func (s *promoCodeService) computeDiscount(p *models.PromoCode, subTotal float64) float64 {
 	switch p.DiscountType {
 	case models.DiscountTypePercent:
@@ -100,6 +224,9 @@ func (s *promoCodeService) Create(ctx context.Context, pharmacyID uuid.UUID, p *
 	if p.ValidUntil.Before(p.ValidFrom) {
 		return nil, errors.ErrValidation("valid_until must be after valid_from")
 	}
+	if err := checkPromoRules(p); err != nil {
+		return nil, err
+	}
 	existing, _ := s.repo.GetByPharmacyAndCode(ctx, pharmacyID, p.Code)
 	if existing != nil {
 		return nil, errors.ErrConflict("promo code already exists for this pharmacy")
@@ -129,6 +256,9 @@ func (s *promoCodeService) Update(ctx context.Context, pharmacyID uuid.UUID, p *
 	p.PharmacyID = pharmacyID
 	p.Code = strings.TrimSpace(strings.ToUpper(p.Code))
 	p.UsedCount = existing.UsedCount
+	if err := checkPromoRules(p); err != nil {
+		return nil, err
+	}
 	if err := s.repo.Update(ctx, p); err != nil {
 		return nil, errors.ErrInternal("failed to update promo code", err)
 	}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func promoCodeFixture(p *models.PromoCode) *mocks.MockPromoCodeRepository {
+	p.ID, p.Code, p.IsActive = uuid.New(), "SAVE", true
+	p.ValidFrom, p.ValidUntil = time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	return &mocks.MockPromoCodeRepository{
+		GetByPharmacyAndCodeFunc: func(ctx context.Context, pharmacyID uuid.UUID, code string) (*models.PromoCode, error) { return p, nil },
+	}
+}
+
+func TestPromoCodeService_Validate_ScopesToCategoryAndSplits(t *testing.T) {
+	vitamins, child := uuid.New(), uuid.New()
+	p := &models.PromoCode{DiscountType: models.DiscountTypePercent, DiscountValue: 10, CategoryIDs: []uuid.UUID{vitamins}, MinItemQuantity: 3}
+	svc := &promoCodeService{repo: promoCodeFixture(p), orderRepo: &mocks.MockOrderRepository{}, logger: zap.NewNop()}
+	a, b, other := uuid.New(), uuid.New(), uuid.New()
+	lines := []inbound.PromoLine{
+		{ProductID: a, CategoryIDs: []uuid.UUID{vitamins}, Quantity: 1, Amount: 300},
+		{ProductID: b, CategoryIDs: []uuid.UUID{child, vitamins}, Quantity: 2, Amount: 100},
+		{ProductID: other, CategoryIDs: []uuid.UUID{uuid.New()}, Quantity: 5, Amount: 600},
+	}
+
+	res, err := svc.Validate(context.Background(), uuid.New(), "save", 1000, lines, nil)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if res.EligibleSubTotal != 400 || res.DiscountAmount != 40 {
+		t.Errorf("eligible %.2f discount %.2f, want 400 and 40", res.EligibleSubTotal, res.DiscountAmount)
+	}
+	if len(res.Lines) != 2 || res.Lines[0].ProductID != a || res.Lines[0].Amount != 30 || res.Lines[1].Amount != 10 {
+		t.Errorf("lines = %+v, want 30 on the first product and 10 on the second", res.Lines)
+	}
+	if res.Stacking != models.PromoStackingCombinable {
+		t.Errorf("stacking = %q, want combinable by default", res.Stacking)
+	}
+
+	_, err = svc.Validate(context.Background(), uuid.New(), "save", 1000, lines[:1], nil)
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("one qualifying unit: err = %v, want validation error for min_item_quantity", err)
+	}
+	_, err = svc.Validate(context.Background(), uuid.New(), "save", 1000, nil, nil)
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("no lines: err = %v, want validation error for a scoped code", err)
+	}
+}
+
+func TestPromoCodeService_Validate_PerUserLimit(t *testing.T) {
+	p := &models.PromoCode{DiscountType: models.DiscountTypeFixed, DiscountValue: 50, MaxUsesPerUser: 2}
+	used := int64(2)
+	orders := &mocks.MockOrderRepository{
+		CountByPromoCodeAndUserFunc: func(ctx context.Context, promoCodeID, createdBy uuid.UUID) (int64, error) { return used, nil },
+	}
+	svc := &promoCodeService{repo: promoCodeFixture(p), orderRepo: orders, logger: zap.NewNop()}
+	userID := uuid.New()
+
+	if _, err := svc.Validate(context.Background(), uuid.New(), "SAVE", 500, nil, &userID); err == nil {
+		t.Fatal("expected the third use to be rejected")
+	}
+	if _, err := svc.Validate(context.Background(), uuid.New(), "SAVE", 500, nil, nil); err == nil {
+		t.Fatal("expected a per-user code to need a user")
+	}
+	used = 1
+	res, err := svc.Validate(context.Background(), uuid.New(), "SAVE", 500, nil, &userID)
+	if err != nil || res.DiscountAmount != 50 {
+		t.Fatalf("second use = %v, %v, want 50 off", res, err)
+	}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/careplus/pharmacy-backend/pkg/imaging"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Uploaded promo images must be landscape, between 4:3 and 4:1, and wide enough for a sharp banner.
+const (
+	promoImageMinAspect = 4.0 / 3.0
+	promoImageMaxAspect = 4.0
+	promoImageMinWidth  = 800
+	promoImageQuality   = 85
+)
+
+type promoImageService struct {
+	promoRepo        outbound.PromoRepository
+	announcementRepo outbound.AnnouncementRepository
+	storage          outbound.FileStorage
+	logger           *zap.Logger
+}
+
+func NewPromoImageService(promoRepo outbound.PromoRepository, announcementRepo outbound.AnnouncementRepository, storage outbound.FileStorage, logger *zap.Logger) inbound.PromoImageService {
+	return &promoImageService{promoRepo: promoRepo, announcementRepo: announcementRepo, storage: storage, logger: logger}
+}
+
+func (s *promoImageService) SetPromoImage(ctx context.Context, pharmacyID, promoID uuid.UUID, body io.Reader) (*models.Promo, error) {
+	p, err := s.promoRepo.GetByID(ctx, promoID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgerrors.ErrNotFound("promo")
+		}
+		return nil, err
+	}
+	if p == nil || p.PharmacyID != pharmacyID {
+		return nil, pkgerrors.ErrNotFound("promo")
+	}
+	set, err := s.store(ctx, "promos/"+promoID.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	p.Images = set
+	p.ImageURL = set.Banner
+	if err := s.promoRepo.Update(ctx, p); err != nil {
+		return nil, pkgerrors.ErrInternal("failed to update promo", err)
+	}
+	return p, nil
+}
+
+func (s *promoImageService) SetAnnouncementImage(ctx context.Context, pharmacyID, announcementID uuid.UUID, body io.Reader) (*models.Announcement, error) {
+	a, err := s.announcementRepo.GetByID(ctx, announcementID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgerrors.ErrNotFound("announcement")
+		}
+		return nil, err
+	}
+	if a == nil || a.PharmacyID != pharmacyID {
+		return nil, pkgerrors.ErrNotFound("announcement")
+	}
+	set, err := s.store(ctx, "announcements/"+announcementID.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	a.Images = set
+	a.ImageURL = set.Banner
+	if err := s.announcementRepo.Update(ctx, a); err != nil {
+		return nil, pkgerrors.ErrInternal("failed to update announcement", err)
+	}
+	return a, nil
+}
+
+// store validates the upload and saves the original plus banner and thumbnail renditions under
+// photos/<prefix>/<yyyy/mm>/<id>-<rendition>.
+func (s *promoImageService) store(ctx context.Context, prefix string, body io.Reader) (*models.ImageSet, error) {
+	var raw bytes.Buffer
+	img, format, err := imaging.Decode(io.TeeReader(body, &raw))
+	if err != nil {
+		return nil, pkgerrors.ErrValidation(err.Error())
+	}
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w < promoImageMinWidth {
+		return nil, pkgerrors.ErrValidation(fmt.Sprintf("image must be at least %d pixels wide", promoImageMinWidth))
+	}
+	if aspect := float64(w) / float64(h); aspect < promoImageMinAspect || aspect > promoImageMaxAspect {
+		return nil, pkgerrors.ErrValidation(fmt.Sprintf("image aspect ratio %.2f:1 is outside the allowed range 4:3 to 4:1", aspect))
+	}
+
+	base := "photos/" + prefix + "/" + time.Now().Format("2006/01") + "/" + uuid.New().String()
+	set := &models.ImageSet{Width: w, Height: h}
+	ext := map[string]string{"jpeg": ".jpg", "png": ".png", "gif": ".gif"}[format]
+	if set.Original, err = s.storage.Save(ctx, base+"-original"+ext, &raw, "image/"+format); err != nil {
+		return nil, pkgerrors.ErrInternal("failed to store image", err)
+	}
+	renditions := []struct {
+		name          string
+		width, height int
+		url           *string
+	}{
+		{"banner", models.BannerImageWidth, models.BannerImageHeight, &set.Banner},
+		{"thumb", models.ThumbnailImageWidth, models.ThumbnailImageHeight, &set.Thumbnail},
+	}
+	for _, r := range renditions {
+		data, err := imaging.EncodeJPEG(imaging.Cover(img, r.width, r.height), promoImageQuality)
+		if err != nil {
+			return nil, pkgerrors.ErrInternal("failed to encode image", err)
+		}
+		if *r.url, err = s.storage.Save(ctx, base+"-"+r.name+".jpg", bytes.NewReader(data), "image/jpeg"); err != nil {
+			return nil, pkgerrors.ErrInternal("failed to store image", err)
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// memoryStorage records saved files by path.
+type memoryStorage struct{ files map[string]int }
+
+func (m *memoryStorage) Save(ctx context.Context, path string, body io.Reader, contentType string) (string, error) {
+	n, _ := io.Copy(io.Discard, body)
+	m.files[path] = int(n)
+	return "/uploads/" + path, nil
+}
+
+func pngOfSize(t *testing.T, w, h int) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestPromoImageService_SetPromoImage_StoresRenditions(t *testing.T) {
+	pharmacyID := uuid.New()
+	promo := &models.Promo{ID: uuid.New(), PharmacyID: pharmacyID, Title: "Winter sale"}
+	repo := &mocks.MockPromoRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Promo, error) { return promo, nil },
+	}
+	store := &memoryStorage{files: map[string]int{}}
+	svc := NewPromoImageService(repo, nil, store, zap.NewNop())
+
+	p, err := svc.SetPromoImage(context.Background(), pharmacyID, promo.ID, pngOfSize(t, 1600, 600))
+	if err != nil {
+		t.Fatalf("SetPromoImage: %v", err)
+	}
+	if p.Images == nil || p.Images.Width != 1600 || p.Images.Height != 600 {
+		t.Fatalf("unexpected image set %+v", p.Images)
+	}
+	if p.ImageURL != p.Images.Banner || !strings.HasSuffix(p.Images.Banner, "-banner.jpg") || !strings.HasSuffix(p.Images.Thumbnail, "-thumb.jpg") {
+		t.Errorf("unexpected urls %+v (image_url %s)", p.Images, p.ImageURL)
+	}
+	if len(store.files) != 3 {
+		t.Errorf("expected original, banner and thumbnail to be stored, got %v", store.files)
+	}
+}
+
+func TestPromoImageService_SetPromoImage_RejectsBadAspectOrSize(t *testing.T) {
+	pharmacyID := uuid.New()
+	promo := &models.Promo{ID: uuid.New(), PharmacyID: pharmacyID}
+	repo := &mocks.MockPromoRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Promo, error) { return promo, nil },
+	}
+	store := &memoryStorage{files: map[string]int{}}
+	svc := NewPromoImageService(repo, nil, store, zap.NewNop())
+
+	for name, size := range map[string][2]int{"portrait": {900, 1200}, "too wide": {2500, 500}, "too small": {600, 300}} {
+		_, err := svc.SetPromoImage(context.Background(), pharmacyID, promo.ID, pngOfSize(t, size[0], size[1]))
+		if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation {
+			t.Errorf("%s: expected validation error, got %v", name, err)
+		}
+	}
+	if _, err := svc.SetPromoImage(context.Background(), uuid.New(), promo.ID, pngOfSize(t, 1200, 400)); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeNotFound {
+		t.Errorf("expected not found for another pharmacy's promo, got %v", err)
+	}
+	if len(store.files) != 0 {
+		t.Errorf("rejected uploads must not be stored, got %v", store.files)
+	}
+}
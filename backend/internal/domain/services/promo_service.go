@@ -3,6 +3,10 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
@@ -13,19 +17,26 @@ import (
 	"gorm.io/gorm"
 )
 
+// Maximum number of promo ids accepted in one impression batch.
+const maxImpressionBatch = 50
+
 type promoService struct {
-	repo   outbound.PromoRepository
-	logger *zap.Logger
+	repo     outbound.PromoRepository
+	statRepo outbound.PromoStatRepository
+	logger   *zap.Logger
 }
 
-func NewPromoService(repo outbound.PromoRepository, logger *zap.Logger) inbound.PromoService {
-	return &promoService{repo: repo, logger: logger}
+func NewPromoService(repo outbound.PromoRepository, statRepo outbound.PromoStatRepository, logger *zap.Logger) inbound.PromoService {
+	return &promoService{repo: repo, statRepo: statRepo, logger: logger}
 }
 
 func (s *promoService) Create(ctx context.Context, pharmacyID uuid.UUID, p *models.Promo) (*models.Promo, error) {
 	if p.Type == "" {
 		p.Type = models.PromoTypeAnnouncement
 	}
+	if err := normalizePromoTargeting(p); err != nil {
+		return nil, err
+	}
 	p.PharmacyID = pharmacyID
 	if err := s.repo.Create(ctx, p); err != nil {
 		s.logger.Warn("promo create failed", zap.Error(err))
@@ -60,6 +71,9 @@ func (s *promoService) Update(ctx context.Context, pharmacyID uuid.UUID, p *mode
 	if existing.PharmacyID != pharmacyID {
 		return nil, pkgerrors.ErrNotFound("promo")
 	}
+	if err := normalizePromoTargeting(p); err != nil {
+		return nil, err
+	}
 	p.PharmacyID = pharmacyID
 	if err := s.repo.Update(ctx, p); err != nil {
 		s.logger.Warn("promo update failed", zap.Error(err))
@@ -81,3 +95,209 @@ func (s *promoService) Delete(ctx context.Context, pharmacyID, id uuid.UUID) err
 	}
 	return s.repo.Delete(ctx, id)
 }
+
+func (s *promoService) ListPublic(ctx context.Context, pharmacyID uuid.UUID, q inbound.PublicPromoQuery) ([]*models.Promo, int64, error) {
+	list, err := s.repo.ListByPharmacy(ctx, pharmacyID, q.Types, true)
+	if err != nil {
+		return nil, 0, err
+	}
+	now := time.Now()
+	live := make([]*models.Promo, 0, len(list))
+	for _, p := range list {
+		if p.LiveAt(now) && p.Targets(q.Placement, q.CategoryID) {
+			live = append(live, p)
+		}
+	}
+	total := int64(len(live))
+	if q.Offset > 0 {
+		if q.Offset >= len(live) {
+			return []*models.Promo{}, total, nil
+		}
+		live = live[q.Offset:]
+	}
+	if q.Limit > 0 && q.Limit < len(live) {
+		live = live[:q.Limit]
+	}
+	return live, total, nil
+}
+
+func (s *promoService) RecordImpressions(ctx context.Context, pharmacyID uuid.UUID, promoIDs []uuid.UUID, placement string) error {
+	if placement != "" && !models.IsPromoPlacement(placement) {
+		return pkgerrors.ErrValidation("unknown placement")
+	}
+	if len(promoIDs) > maxImpressionBatch {
+		return pkgerrors.ErrValidation(fmt.Sprintf("at most %d promo ids per request", maxImpressionBatch))
+	}
+	list, err := s.repo.ListByPharmacy(ctx, pharmacyID, nil, false)
+	if err != nil {
+		return err
+	}
+	owned := make(map[uuid.UUID]bool, len(list))
+	for _, p := range list {
+		owned[p.ID] = true
+	}
+	day := statDay(time.Now())
+	seen := make(map[uuid.UUID]bool, len(promoIDs))
+	for _, id := range promoIDs {
+		if !owned[id] || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if err := s.statRepo.Increment(ctx, pharmacyID, id, day, placement, 1, 0); err != nil {
+			s.logger.Warn("promo impression record failed", zap.String("promo_id", id.String()), zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *promoService) RecordClick(ctx context.Context, pharmacyID, promoID uuid.UUID, placement string) error {
+	if placement != "" && !models.IsPromoPlacement(placement) {
+		return pkgerrors.ErrValidation("unknown placement")
+	}
+	if _, err := s.ownedPromo(ctx, pharmacyID, promoID); err != nil {
+		return err
+	}
+	if err := s.statRepo.Increment(ctx, pharmacyID, promoID, statDay(time.Now()), placement, 0, 1); err != nil {
+		s.logger.Warn("promo click record failed", zap.String("promo_id", promoID.String()), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s *promoService) Performance(ctx context.Context, pharmacyID, promoID uuid.UUID, from, to time.Time) (*inbound.PromoPerformance, error) {
+	if _, err := s.ownedPromo(ctx, pharmacyID, promoID); err != nil {
+		return nil, err
+	}
+	from, to = statDay(from), statDay(to)
+	if to.Before(from) {
+		return nil, pkgerrors.ErrValidation("to must not be before from")
+	}
+	stats, err := s.statRepo.ListByPromo(ctx, promoID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	report := &inbound.PromoPerformance{
+		PromoID:     promoID,
+		From:        from.Format("2006-01-02"),
+		To:          to.Format("2006-01-02"),
+		ByDay:       []inbound.PromoStatRow{},
+		ByPlacement: []inbound.PromoStatRow{},
+	}
+	dayIdx := map[string]int{}
+	placementIdx := map[string]int{}
+	for _, st := range stats {
+		report.Impressions += st.Impressions
+		report.Clicks += st.Clicks
+		day := st.Day.Format("2006-01-02")
+		if i, ok := dayIdx[day]; ok {
+			report.ByDay[i].Impressions += st.Impressions
+			report.ByDay[i].Clicks += st.Clicks
+		} else {
+			dayIdx[day] = len(report.ByDay)
+			report.ByDay = append(report.ByDay, inbound.PromoStatRow{Day: day, Impressions: st.Impressions, Clicks: st.Clicks})
+		}
+		placement := st.Placement
+		if placement == "" {
+			placement = "unspecified"
+		}
+		if i, ok := placementIdx[placement]; ok {
+			report.ByPlacement[i].Impressions += st.Impressions
+			report.ByPlacement[i].Clicks += st.Clicks
+		} else {
+			placementIdx[placement] = len(report.ByPlacement)
+			report.ByPlacement = append(report.ByPlacement, inbound.PromoStatRow{Placement: placement, Impressions: st.Impressions, Clicks: st.Clicks})
+		}
+	}
+	sort.Slice(report.ByDay, func(i, j int) bool { return report.ByDay[i].Day < report.ByDay[j].Day })
+	sort.Slice(report.ByPlacement, func(i, j int) bool { return report.ByPlacement[i].Placement < report.ByPlacement[j].Placement })
+	report.CTR = clickThroughRate(report.Clicks, report.Impressions)
+	for i := range report.ByDay {
+		report.ByDay[i].CTR = clickThroughRate(report.ByDay[i].Clicks, report.ByDay[i].Impressions)
+	}
+	for i := range report.ByPlacement {
+		report.ByPlacement[i].CTR = clickThroughRate(report.ByPlacement[i].Clicks, report.ByPlacement[i].Impressions)
+	}
+	return report, nil
+}
+
+func (s *promoService) ownedPromo(ctx context.Context, pharmacyID, promoID uuid.UUID) (*models.Promo, error) {
+	p, err := s.GetByID(ctx, promoID)
+	if err != nil {
+		return nil, err
+	}
+	if p.PharmacyID != pharmacyID {
+		return nil, pkgerrors.ErrNotFound("promo")
+	}
+	return p, nil
+}
+
+// normalizePromoTargeting validates and cleans placements, schedule days and the daily window.
+func normalizePromoTargeting(p *models.Promo) error {
+	placements := make([]string, 0, len(p.Placements))
+	for _, pl := range p.Placements {
+		pl = strings.TrimSpace(pl)
+		if !models.IsPromoPlacement(pl) {
+			return pkgerrors.ErrValidation("unknown placement: " + pl)
+		}
+		if !containsString(placements, pl) {
+			placements = append(placements, pl)
+		}
+	}
+	p.Placements = placements
+	days := make([]string, 0, len(p.ScheduleDays))
+	for _, d := range p.ScheduleDays {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if !weekdays[d] {
+			return pkgerrors.ErrValidation("unknown schedule day: " + d)
+		}
+		if !containsString(days, d) {
+			days = append(days, d)
+		}
+	}
+	p.ScheduleDays = days
+	if (p.DailyStart == "") != (p.DailyEnd == "") {
+		return pkgerrors.ErrValidation("daily_start and daily_end must be set together")
+	}
+	if p.DailyStart != "" {
+		start, ok := parseClock(p.DailyStart)
+		if !ok || start >= 24*60 {
+			return pkgerrors.ErrValidation("daily_start must be HH:MM")
+		}
+		end, ok := parseClock(p.DailyEnd)
+		if !ok {
+			return pkgerrors.ErrValidation("daily_end must be HH:MM")
+		}
+		if start == end {
+			return pkgerrors.ErrValidation("daily_start and daily_end must differ")
+		}
+		p.DailyStart = fmt.Sprintf("%02d:%02d", start/60, start%60)
+		p.DailyEnd = fmt.Sprintf("%02d:%02d", end/60, end%60)
+	}
+	if p.StartAt != nil && p.EndAt != nil && p.EndAt.Before(*p.StartAt) {
+		return pkgerrors.ErrValidation("end_at must be after start_at")
+	}
+	return nil
+}
+
+// statDay truncates t to its calendar day in local time.
+func statDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func clickThroughRate(clicks, impressions int64) float64 {
+	if impressions == 0 {
+		return 0
+	}
+	return float64(clicks) / float64(impressions)
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestPromo_LiveAt_ScheduleAndOvernightWindow(t *testing.T) {
+	// 2026-03-06 is a Friday.
+	fri2330 := time.Date(2026, 3, 6, 23, 30, 0, 0, time.Local)
+	fri1200 := time.Date(2026, 3, 6, 12, 0, 0, 0, time.Local)
+	p := &models.Promo{IsActive: true, ScheduleDays: []string{"fri"}, DailyStart: "22:00", DailyEnd: "02:00"}
+	if !p.LiveAt(fri2330) {
+		t.Error("expected promo live inside overnight window")
+	}
+	if p.LiveAt(fri1200) {
+		t.Error("expected promo hidden outside daily window")
+	}
+	p.ScheduleDays = []string{"sat"}
+	if p.LiveAt(fri2330) {
+		t.Error("expected promo hidden on unscheduled weekday")
+	}
+}
+
+func TestPromoService_ListPublic_FiltersAndPaginates(t *testing.T) {
+	pharmacyID := uuid.New()
+	catID := uuid.New()
+	promos := []*models.Promo{
+		{ID: uuid.New(), PharmacyID: pharmacyID, IsActive: true, Title: "everywhere"},
+		{ID: uuid.New(), PharmacyID: pharmacyID, IsActive: true, Title: "hero", Placements: []string{models.PromoPlacementHomepageHero}},
+		{ID: uuid.New(), PharmacyID: pharmacyID, IsActive: true, Title: "sidebar", Placements: []string{models.PromoPlacementCategorySidebar}, CategoryIDs: []uuid.UUID{catID}},
+		{ID: uuid.New(), PharmacyID: pharmacyID, IsActive: false, Title: "inactive"},
+	}
+	repo := &mocks.MockPromoRepository{
+		ListByPharmacyFunc: func(ctx context.Context, id uuid.UUID, types []string, activeOnly bool) ([]*models.Promo, error) {
+			return promos, nil
+		},
+	}
+	svc := NewPromoService(repo, &mocks.MockPromoStatRepository{}, zap.NewNop())
+
+	list, total, err := svc.ListPublic(context.Background(), pharmacyID, inbound.PublicPromoQuery{Placement: models.PromoPlacementHomepageHero})
+	if err != nil {
+		t.Fatalf("ListPublic: %v", err)
+	}
+	if total != 2 || len(list) != 2 || list[0].Title != "everywhere" || list[1].Title != "hero" {
+		t.Fatalf("hero placement: got total=%d %v", total, list)
+	}
+
+	other := uuid.New()
+	list, total, _ = svc.ListPublic(context.Background(), pharmacyID, inbound.PublicPromoQuery{Placement: models.PromoPlacementCategorySidebar, CategoryID: &other})
+	if total != 1 || list[0].Title != "everywhere" {
+		t.Fatalf("sidebar for other category: got total=%d", total)
+	}
+
+	list, total, _ = svc.ListPublic(context.Background(), pharmacyID, inbound.PublicPromoQuery{Limit: 1, Offset: 1})
+	if total != 3 || len(list) != 1 || list[0].Title != "hero" {
+		t.Fatalf("pagination: got total=%d len=%d", total, len(list))
+	}
+}
+
+func TestPromoService_RecordImpressions_IgnoresForeignAndDuplicateIDs(t *testing.T) {
+	pharmacyID := uuid.New()
+	own := &models.Promo{ID: uuid.New(), PharmacyID: pharmacyID}
+	repo := &mocks.MockPromoRepository{
+		ListByPharmacyFunc: func(ctx context.Context, id uuid.UUID, types []string, activeOnly bool) ([]*models.Promo, error) {
+			return []*models.Promo{own}, nil
+		},
+	}
+	counted := map[uuid.UUID]int64{}
+	stats := &mocks.MockPromoStatRepository{
+		IncrementFunc: func(ctx context.Context, phID, promoID uuid.UUID, day time.Time, placement string, impressions, clicks int64) error {
+			counted[promoID] += impressions
+			return nil
+		},
+	}
+	svc := NewPromoService(repo, stats, zap.NewNop())
+	err := svc.RecordImpressions(context.Background(), pharmacyID, []uuid.UUID{own.ID, uuid.New(), own.ID}, models.PromoPlacementCheckout)
+	if err != nil {
+		t.Fatalf("RecordImpressions: %v", err)
+	}
+	if len(counted) != 1 || counted[own.ID] != 1 {
+		t.Errorf("expected one impression for own promo, got %v", counted)
+	}
+	err = svc.RecordImpressions(context.Background(), pharmacyID, []uuid.UUID{own.ID}, "footer")
+	if ae := pkgerrors.GetAppError(err); ae == nil || ae.Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("expected validation error for unknown placement, got %v", err)
+	}
+}
+
+func TestPromoService_Performance_AggregatesCTR(t *testing.T) {
+	pharmacyID := uuid.New()
+	promo := &models.Promo{ID: uuid.New(), PharmacyID: pharmacyID}
+	repo := &mocks.MockPromoRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Promo, error) { return promo, nil },
+	}
+	day1 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	stats := &mocks.MockPromoStatRepository{
+		ListByPromoFunc: func(ctx context.Context, promoID uuid.UUID, from, to time.Time) ([]*models.PromoDailyStat, error) {
+			return []*models.PromoDailyStat{
+				{Day: day1, Placement: models.PromoPlacementHomepageHero, Impressions: 100, Clicks: 5},
+				{Day: day1, Placement: models.PromoPlacementCheckout, Impressions: 50, Clicks: 5},
+				{Day: day2, Placement: models.PromoPlacementHomepageHero, Impressions: 50, Clicks: 0},
+			}, nil
+		},
+	}
+	svc := NewPromoService(repo, stats, zap.NewNop())
+	report, err := svc.Performance(context.Background(), pharmacyID, promo.ID, day1, day2)
+	if err != nil {
+		t.Fatalf("Performance: %v", err)
+	}
+	if report.Impressions != 200 || report.Clicks != 10 || report.CTR != 0.05 {
+		t.Errorf("totals: got %d/%d ctr=%v", report.Impressions, report.Clicks, report.CTR)
+	}
+	if len(report.ByDay) != 2 || report.ByDay[0].Impressions != 150 {
+		t.Errorf("by day: got %+v", report.ByDay)
+	}
+	if len(report.ByPlacement) != 2 || report.ByPlacement[1].Placement != models.PromoPlacementHomepageHero || report.ByPlacement[1].Impressions != 150 {
+		t.Errorf("by placement: got %+v", report.ByPlacement)
+	}
+
+	_, err = svc.Performance(context.Background(), uuid.New(), promo.ID, day1, day2)
+	if ae := pkgerrors.GetAppError(err); ae == nil || ae.Code != pkgerrors.ErrCodeNotFound {
+		t.Errorf("expected not found for another pharmacy, got %v", err)
+	}
+}
+
+func TestPromoService_Create_RejectsBadDailyWindow(t *testing.T) {
+	svc := NewPromoService(&mocks.MockPromoRepository{}, &mocks.MockPromoStatRepository{}, zap.NewNop())
+	_, err := svc.Create(context.Background(), uuid.New(), &models.Promo{Title: "x", DailyStart: "09:00"})
+	if ae := pkgerrors.GetAppError(err); ae == nil || ae.Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("expected validation error, got %v", err)
+	}
+	p, err := svc.Create(context.Background(), uuid.New(), &models.Promo{Title: "x", DailyStart: "9:00", DailyEnd: "17:30", ScheduleDays: []string{"Mon", "mon"}})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if p.DailyStart != "09:00" || len(p.ScheduleDays) != 1 || p.ScheduleDays[0] != "mon" {
+		t.Errorf("expected normalized schedule, got %q %v", p.DailyStart, p.ScheduleDays)
+	}
+}
@@ -0,0 +1,455 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/careplus/pharmacy-backend/pkg/signing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// supplierReplyLinkTTL is how long the signed reply link in a purchase request stays valid.
+const supplierReplyLinkTTL = 14 * 24 * time.Hour
+
+type purchaseOrderService struct {
+	poRepo              outbound.PurchaseOrderRepository
+	supplierRepo        outbound.SupplierRepository
+	productRepo         outbound.ProductRepository
+	emailSender         outbound.EmailSender
+	notificationService inbound.NotificationService
+	inventoryService    inbound.InventoryService
+	preorderService     inbound.PreorderService
+	publicURL           string
+	signingSecret       []byte
+	logger              *zap.Logger
+}
+
+func NewPurchaseOrderService(
+	poRepo outbound.PurchaseOrderRepository,
+	supplierRepo outbound.SupplierRepository,
+	productRepo outbound.ProductRepository,
+	emailSender outbound.EmailSender,
+	notificationService inbound.NotificationService,
+	inventoryService inbound.InventoryService,
+	preorderService inbound.PreorderService,
+	publicURL string,
+	signingSecret string,
+	logger *zap.Logger,
+) inbound.PurchaseOrderService {
+	return &purchaseOrderService{
+		poRepo:              poRepo,
+		supplierRepo:        supplierRepo,
+		productRepo:         productRepo,
+		emailSender:         emailSender,
+		notificationService: notificationService,
+		inventoryService:    inventoryService,
+		preorderService:     preorderService,
+		publicURL:           strings.TrimSuffix(publicURL, "/"),
+		signingSecret:       []byte(signingSecret),
+		logger:              logger,
+	}
+}
+
+const (
+	// defaultSalesWindowDays is how far back ReorderSuggestions looks for sales velocity.
+	defaultSalesWindowDays = 30
+	// reorderCoverDays is how many days of sales a reorder should cover once it arrives.
+	reorderCoverDays = 30
+	// unmappedLeadTimeDays stands in for the lead time of products without a supplier.
+	unmappedLeadTimeDays = 3
+)
+
+// suggestedReorderQuantity sizes a reorder for a product at or below its reorder level.
+// With recent sales it targets the reorder level plus demand over the lead time and reorderCoverDays;
+// without sales it tops stock back up to twice the level. The target is capped at max_stock, the
+// quantity is rounded up to whole reorder_quantity lots (dropping lots that would overshoot max_stock,
+// but never the last one) and is at least 1 unit.
+func suggestedReorderQuantity(p *models.Product, level int, dailySales float64, leadTimeDays int) int {
+	target := level * 2
+	if dailySales > 0 {
+		target = level + int(math.Ceil(dailySales*float64(leadTimeDays+reorderCoverDays)))
+	}
+	if p.MaxStock != nil && target > *p.MaxStock {
+		target = *p.MaxStock
+	}
+	q := target - p.StockQuantity
+	if q < 1 {
+		q = 1
+	}
+	if lot := p.ReorderQuantity; lot != nil && *lot > 0 {
+		q = (q + *lot - 1) / *lot * *lot
+		for p.MaxStock != nil && q > *lot && p.StockQuantity+q > *p.MaxStock {
+			q -= *lot
+		}
+	}
+	return q
+}
+
+func (s *purchaseOrderService) ReorderSuggestions(ctx context.Context, pharmacyID uuid.UUID, threshold, salesDays int) ([]*inbound.ReorderSuggestion, error) {
+	if threshold <= 0 {
+		threshold = defaultLowStockThreshold
+	}
+	if salesDays <= 0 {
+		salesDays = defaultSalesWindowDays
+	}
+	products, err := s.productRepo.ListBelowReorderLevel(ctx, pharmacyID, threshold)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load low stock products", err)
+	}
+	sold, err := s.productRepo.UnitsSold(ctx, pharmacyID, time.Now().AddDate(0, 0, -salesDays))
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load sales velocity", err)
+	}
+	bySupplier := make(map[uuid.UUID]*inbound.ReorderSuggestion)
+	var unmapped *inbound.ReorderSuggestion
+	for _, p := range products {
+		level := threshold
+		if p.ReorderLevel != nil {
+			level = *p.ReorderLevel
+		}
+		leadTime := unmappedLeadTimeDays
+		if p.Supplier != nil {
+			leadTime = p.Supplier.LeadTimeDays
+		}
+		daily := float64(sold[p.ID]) / float64(salesDays)
+		item := inbound.ReorderSuggestionItem{
+			ProductID:         p.ID,
+			Name:              p.Name,
+			SKU:               p.SKU,
+			StockQuantity:     p.StockQuantity,
+			ReorderLevel:      level,
+			ReorderQuantity:   p.ReorderQuantity,
+			MaxStock:          p.MaxStock,
+			UnitsSold:         sold[p.ID],
+			DailySales:        math.Round(daily*100) / 100,
+			SuggestedQuantity: suggestedReorderQuantity(p, level, daily, leadTime),
+		}
+		if daily > 0 {
+			days := math.Round(float64(p.StockQuantity)/daily*10) / 10
+			item.DaysOfStock = &days
+		}
+		if p.SupplierID == nil || p.Supplier == nil {
+			if unmapped == nil {
+				unmapped = &inbound.ReorderSuggestion{}
+			}
+			unmapped.Items = append(unmapped.Items, item)
+			continue
+		}
+		g, ok := bySupplier[*p.SupplierID]
+		if !ok {
+			g = &inbound.ReorderSuggestion{Supplier: p.Supplier}
+			bySupplier[*p.SupplierID] = g
+		}
+		g.Items = append(g.Items, item)
+	}
+	out := make([]*inbound.ReorderSuggestion, 0, len(bySupplier)+1)
+	for _, g := range bySupplier {
+		out = append(out, g)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Supplier.Name < out[j].Supplier.Name })
+	if unmapped != nil {
+		out = append(out, unmapped)
+	}
+	return out, nil
+}
+
+func (s *purchaseOrderService) CreateReorderRequest(ctx context.Context, pharmacyID, createdBy, supplierID uuid.UUID, items []inbound.PurchaseOrderItemInput, expectedDelivery *time.Time, notes string) (*models.PurchaseOrder, error) {
+	supplier, err := s.supplierRepo.GetByID(ctx, supplierID)
+	if err != nil || supplier == nil || supplier.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("supplier")
+	}
+	if strings.TrimSpace(supplier.Email) == "" {
+		return nil, errors.ErrValidation("supplier has no email address")
+	}
+	if len(items) == 0 {
+		if items, err = s.suggestedItems(ctx, pharmacyID, supplierID, 0, 0); err != nil {
+			return nil, err
+		}
+	}
+	po, err := s.createDraft(ctx, pharmacyID, createdBy, supplier, items, expectedDelivery, notes)
+	if err != nil {
+		return nil, err
+	}
+	// Email failure keeps the draft; staff can resend.
+	if err := s.sendRequest(ctx, po); err != nil {
+		s.logger.Warn("purchase request email failed", zap.String("po_id", po.ID.String()), zap.Error(err))
+	}
+	return po, nil
+}
+
+func (s *purchaseOrderService) DraftFromSuggestions(ctx context.Context, pharmacyID, createdBy, supplierID uuid.UUID, threshold, salesDays int, notes string) (*models.PurchaseOrder, error) {
+	supplier, err := s.supplierRepo.GetByID(ctx, supplierID)
+	if err != nil || supplier == nil || supplier.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("supplier")
+	}
+	items, err := s.suggestedItems(ctx, pharmacyID, supplierID, threshold, salesDays)
+	if err != nil {
+		return nil, err
+	}
+	return s.createDraft(ctx, pharmacyID, createdBy, supplier, items, nil, notes)
+}
+
+// suggestedItems turns the supplier's current reorder suggestions into purchase order lines.
+func (s *purchaseOrderService) suggestedItems(ctx context.Context, pharmacyID, supplierID uuid.UUID, threshold, salesDays int) ([]inbound.PurchaseOrderItemInput, error) {
+	suggestions, err := s.ReorderSuggestions(ctx, pharmacyID, threshold, salesDays)
+	if err != nil {
+		return nil, err
+	}
+	var items []inbound.PurchaseOrderItemInput
+	for _, g := range suggestions {
+		if g.Supplier != nil && g.Supplier.ID == supplierID {
+			for _, it := range g.Items {
+				items = append(items, inbound.PurchaseOrderItemInput{ProductID: it.ProductID, Quantity: it.SuggestedQuantity})
+			}
+		}
+	}
+	if len(items) == 0 {
+		return nil, errors.ErrValidation("no reorder suggestions for this supplier")
+	}
+	return items, nil
+}
+
+// createDraft stores a draft purchase order for the supplier and links waiting preorders; it does not email it.
+func (s *purchaseOrderService) createDraft(ctx context.Context, pharmacyID, createdBy uuid.UUID, supplier *models.Supplier, items []inbound.PurchaseOrderItemInput, expectedDelivery *time.Time, notes string) (*models.PurchaseOrder, error) {
+	supplierID := supplier.ID
+	po := &models.PurchaseOrder{
+		PharmacyID: pharmacyID,
+		SupplierID: supplierID,
+		Status:     models.PurchaseOrderStatusDraft,
+		Notes:      notes,
+		CreatedBy:  createdBy,
+	}
+	if expectedDelivery != nil {
+		po.ExpectedDeliveryDate = expectedDelivery
+	} else {
+		d := time.Now().AddDate(0, 0, supplier.LeadTimeDays)
+		po.ExpectedDeliveryDate = &d
+	}
+	for _, in := range items {
+		if in.Quantity <= 0 {
+			return nil, errors.ErrValidation("item quantity must be positive")
+		}
+		p, err := s.productRepo.GetByID(ctx, in.ProductID)
+		if err != nil || p == nil || p.PharmacyID != pharmacyID {
+			return nil, errors.ErrNotFound("product")
+		}
+		po.Items = append(po.Items, models.PurchaseOrderItem{ProductID: in.ProductID, Quantity: in.Quantity})
+	}
+	if err := s.poRepo.Create(ctx, po); err != nil {
+		return nil, errors.ErrInternal("failed to create purchase order", err)
+	}
+	po, err := s.poRepo.GetByID(ctx, po.ID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load purchase order", err)
+	}
+	if s.preorderService != nil {
+		if err := s.preorderService.LinkPurchaseOrder(ctx, po); err != nil {
+			s.logger.Warn("failed to link preorders", zap.String("po_id", po.ID.String()), zap.Error(err))
+		}
+	}
+	return po, nil
+}
+
+func (s *purchaseOrderService) replyPayload(id uuid.UUID) string {
+	return "purchase_order:" + id.String()
+}
+
+func (s *purchaseOrderService) replyLink(po *models.PurchaseOrder) string {
+	token := signing.Sign(s.signingSecret, s.replyPayload(po.ID), time.Now().Add(supplierReplyLinkTTL))
+	return fmt.Sprintf("%s/api/v1/public/purchase-orders/%s?token=%s", s.publicURL, po.ID, token)
+}
+
+// composePurchaseRequest renders the plain-text purchase request email.
+func composePurchaseRequest(po *models.PurchaseOrder, replyLink string) *outbound.EmailMessage {
+	var b strings.Builder
+	name := po.Supplier.ContactName
+	if name == "" {
+		name = po.Supplier.Name
+	}
+	fmt.Fprintf(&b, "Dear %s,\n\nPlease supply the following items for purchase order %s:\n\n", name, po.PONumber)
+	for _, it := range po.Items {
+		label := it.ProductID.String()
+		if it.Product != nil {
+			label = it.Product.Name
+			if it.Product.SKU != "" {
+				label += " (" + it.Product.SKU + ")"
+			}
+		}
+		fmt.Fprintf(&b, "  - %s x %d\n", label, it.Quantity)
+	}
+	if po.ExpectedDeliveryDate != nil {
+		fmt.Fprintf(&b, "\nRequested delivery date: %s\n", po.ExpectedDeliveryDate.Format("2006-01-02"))
+	}
+	if po.Notes != "" {
+		fmt.Fprintf(&b, "\nNotes: %s\n", po.Notes)
+	}
+	fmt.Fprintf(&b, "\nPlease confirm or decline this order (and optionally propose a delivery date) here:\n%s\n\nThank you.\n", replyLink)
+	return &outbound.EmailMessage{
+		PharmacyID: po.PharmacyID,
+		To:         []string{po.Supplier.Email},
+		Subject:    "Purchase request " + po.PONumber,
+		TextBody:   b.String(),
+	}
+}
+
+func (s *purchaseOrderService) sendRequest(ctx context.Context, po *models.PurchaseOrder) error {
+	if po.Supplier == nil {
+		return fmt.Errorf("purchase order %s has no supplier loaded", po.ID)
+	}
+	if err := s.emailSender.Send(ctx, composePurchaseRequest(po, s.replyLink(po))); err != nil {
+		return err
+	}
+	now := time.Now()
+	po.SentAt = &now
+	return s.poRepo.Update(ctx, po)
+}
+
+func (s *purchaseOrderService) GetByID(ctx context.Context, pharmacyID, id uuid.UUID) (*models.PurchaseOrder, error) {
+	po, err := s.poRepo.GetByID(ctx, id)
+	if err != nil || po == nil || po.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("purchase order")
+	}
+	return po, nil
+}
+
+func (s *purchaseOrderService) List(ctx context.Context, pharmacyID uuid.UUID, status *models.PurchaseOrderStatus, supplierID *uuid.UUID, limit, offset int) ([]*models.PurchaseOrder, int64, error) {
+	return s.poRepo.ListByPharmacy(ctx, pharmacyID, status, supplierID, limit, offset)
+}
+
+func (s *purchaseOrderService) Resend(ctx context.Context, pharmacyID, id uuid.UUID) (*models.PurchaseOrder, error) {
+	po, err := s.GetByID(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if po.Status != models.PurchaseOrderStatusDraft {
+		return nil, errors.ErrConflict("only draft purchase orders can be resent")
+	}
+	if err := s.sendRequest(ctx, po); err != nil {
+		return nil, errors.ErrInternal("failed to send purchase request", err)
+	}
+	return po, nil
+}
+
+func (s *purchaseOrderService) Cancel(ctx context.Context, pharmacyID, id uuid.UUID) (*models.PurchaseOrder, error) {
+	po, err := s.GetByID(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if po.Status == models.PurchaseOrderStatusReceived || po.Status == models.PurchaseOrderStatusCancelled {
+		return nil, errors.ErrConflict("purchase order can no longer be cancelled")
+	}
+	po.Status = models.PurchaseOrderStatusCancelled
+	if err := s.poRepo.Update(ctx, po); err != nil {
+		return nil, errors.ErrInternal("failed to cancel purchase order", err)
+	}
+	s.refreshPreorderETAs(ctx, po)
+	return po, nil
+}
+
+func (s *purchaseOrderService) MarkReceived(ctx context.Context, pharmacyID, id uuid.UUID) (*models.PurchaseOrder, error) {
+	po, err := s.GetByID(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if po.Status != models.PurchaseOrderStatusConfirmed && po.Status != models.PurchaseOrderStatusDraft {
+		return nil, errors.ErrConflict("only draft or confirmed purchase orders can be received")
+	}
+	now := time.Now()
+	po.Status = models.PurchaseOrderStatusReceived
+	po.ReceivedAt = &now
+	if err := s.poRepo.Update(ctx, po); err != nil {
+		return nil, errors.ErrInternal("failed to update purchase order", err)
+	}
+	// Received goods go into stock as one batch per line (batch number = PO number), then waiting preorders convert.
+	if s.inventoryService != nil {
+		for _, it := range po.Items {
+			if _, err := s.inventoryService.AddBatch(ctx, pharmacyID, it.ProductID, po.PONumber, it.Quantity, nil, nil); err != nil {
+				s.logger.Warn("failed to add received stock", zap.String("po_id", po.ID.String()), zap.String("product_id", it.ProductID.String()), zap.Error(err))
+			}
+		}
+	}
+	if s.preorderService != nil {
+		if err := s.preorderService.ConvertForPurchaseOrder(ctx, po); err != nil {
+			s.logger.Warn("failed to convert preorders", zap.String("po_id", po.ID.String()), zap.Error(err))
+		}
+	}
+	return po, nil
+}
+
+func (s *purchaseOrderService) verifyReplyToken(id uuid.UUID, token string) error {
+	if err := signing.Verify(s.signingSecret, s.replyPayload(id), token, time.Now()); err != nil {
+		if err == signing.ErrExpiredToken {
+			return errors.ErrForbidden("reply link has expired")
+		}
+		return errors.ErrForbidden("invalid reply link")
+	}
+	return nil
+}
+
+func (s *purchaseOrderService) GetForSupplier(ctx context.Context, id uuid.UUID, token string) (*models.PurchaseOrder, error) {
+	if err := s.verifyReplyToken(id, token); err != nil {
+		return nil, err
+	}
+	po, err := s.poRepo.GetByID(ctx, id)
+	if err != nil || po == nil {
+		return nil, errors.ErrNotFound("purchase order")
+	}
+	return po, nil
+}
+
+func (s *purchaseOrderService) RespondAsSupplier(ctx context.Context, id uuid.UUID, token string, accept bool, note string, deliveryDate *time.Time) (*models.PurchaseOrder, error) {
+	po, err := s.GetForSupplier(ctx, id, token)
+	if err != nil {
+		return nil, err
+	}
+	if po.Status != models.PurchaseOrderStatusDraft {
+		return nil, errors.ErrConflict("this purchase order has already been answered")
+	}
+	now := time.Now()
+	po.RespondedAt = &now
+	po.SupplierNote = note
+	if accept {
+		po.Status = models.PurchaseOrderStatusConfirmed
+		po.ConfirmedDeliveryDate = deliveryDate
+		if po.ConfirmedDeliveryDate == nil {
+			po.ConfirmedDeliveryDate = po.ExpectedDeliveryDate
+		}
+	} else {
+		po.Status = models.PurchaseOrderStatusDeclined
+	}
+	if err := s.poRepo.Update(ctx, po); err != nil {
+		return nil, errors.ErrInternal("failed to record supplier response", err)
+	}
+	s.refreshPreorderETAs(ctx, po)
+	if s.notificationService != nil && po.CreatedBy != uuid.Nil {
+		msg := fmt.Sprintf("%s %s purchase order %s.", po.Supplier.Name, po.Status, po.PONumber)
+		if note != "" {
+			msg += " Note: " + note
+		}
+		if _, err := s.notificationService.Create(ctx, po.PharmacyID, po.CreatedBy, "Supplier replied to "+po.PONumber, msg, "purchase_order"); err != nil {
+			s.logger.Debug("purchase order notification failed", zap.Error(err))
+		}
+	}
+	return po, nil
+}
+
+// refreshPreorderETAs re-evaluates preorders waiting on the PO's products after its delivery date or status changed.
+func (s *purchaseOrderService) refreshPreorderETAs(ctx context.Context, po *models.PurchaseOrder) {
+	if s.preorderService == nil {
+		return
+	}
+	for _, it := range po.Items {
+		if err := s.preorderService.RefreshETAs(ctx, po.PharmacyID, it.ProductID); err != nil {
+			s.logger.Warn("failed to refresh preorder ETAs", zap.String("po_id", po.ID.String()), zap.Error(err))
+		}
+	}
+}
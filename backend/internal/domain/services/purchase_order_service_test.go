@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestPurchaseOrderService_ReorderSuggestions_UsesSalesVelocityAndLevels(t *testing.T) {
+	pharmacyID := uuid.New()
+	supplier := &models.Supplier{ID: uuid.New(), PharmacyID: pharmacyID, Name: "MedSupply", LeadTimeDays: 5}
+	level, lot, maxStock := 10, 24, 90
+	fast := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Cetirizine", StockQuantity: 4,
+		ReorderLevel: &level, ReorderQuantity: &lot, MaxStock: &maxStock, SupplierID: &supplier.ID, Supplier: supplier}
+	idle := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Bandage", StockQuantity: 3}
+	var defaultLevel int
+	var since time.Time
+	repo := &mocks.MockProductRepository{
+		ListBelowReorderLevelFunc: func(ctx context.Context, id uuid.UUID, d int) ([]*models.Product, error) {
+			defaultLevel = d
+			return []*models.Product{fast, idle}, nil
+		},
+		UnitsSoldFunc: func(ctx context.Context, id uuid.UUID, from time.Time) (map[uuid.UUID]int, error) {
+			since = from
+			return map[uuid.UUID]int{fast.ID: 60}, nil
+		},
+	}
+	svc := NewPurchaseOrderService(nil, nil, repo, nil, nil, nil, nil, "", "secret", zap.NewNop())
+
+	groups, err := svc.ReorderSuggestions(context.Background(), pharmacyID, 0, 0)
+	if err != nil {
+		t.Fatalf("ReorderSuggestions: %v", err)
+	}
+	if defaultLevel != defaultLowStockThreshold {
+		t.Errorf("expected default level %d, got %d", defaultLowStockThreshold, defaultLevel)
+	}
+	if d := time.Since(since); d < 29*24*time.Hour || d > 31*24*time.Hour {
+		t.Errorf("expected a 30-day sales window, got since %v", since)
+	}
+	if len(groups) != 2 || groups[0].Supplier != supplier || groups[1].Supplier != nil {
+		t.Fatalf("expected the supplier group then the unmapped group, got %+v", groups)
+	}
+	// 2/day over 5 days lead + 30 days cover above level 10 = 80; 76 short rounds to 4 lots of 24,
+	// trimmed to 3 lots so stock stays within max_stock 90.
+	got := groups[0].Items[0]
+	if got.SuggestedQuantity != 72 || got.DailySales != 2 || got.DaysOfStock == nil || *got.DaysOfStock != 2 {
+		t.Errorf("unexpected fast-seller suggestion: %+v", got)
+	}
+	// No sales: top up to twice the default level.
+	if got := groups[1].Items[0]; got.SuggestedQuantity != 17 || got.ReorderLevel != 10 || got.DaysOfStock != nil {
+		t.Errorf("unexpected idle-product suggestion: %+v", got)
+	}
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	maxDeviceTokenLength = 512
+	maxPushBodyLength    = 180
+)
+
+// orderPushText holds the title and body per status; statuses without an entry are not pushed.
+var orderPushText = map[models.OrderStatus][2]string{
+	models.OrderStatusConfirmed: {"Order confirmed", "Your order %s is confirmed."},
+	models.OrderStatusReady:     {"Order ready", "Your order %s is ready."},
+	models.OrderStatusCompleted: {"Order completed", "Order %s is complete. Thank you!"},
+	models.OrderStatusCancelled: {"Order cancelled", "Your order %s was cancelled."},
+}
+
+type pushNotificationService struct {
+	deviceRepo outbound.DeviceTokenRepository
+	userRepo   outbound.UserRepository
+	pushSender outbound.PushSender
+	publicURL  string
+	logger     *zap.Logger
+}
+
+// NewPushNotificationService returns the push fan-out service. pushSender may be nil (PUSH_PROVIDER=none):
+// devices can still be registered but nothing is sent.
+func NewPushNotificationService(deviceRepo outbound.DeviceTokenRepository, userRepo outbound.UserRepository, pushSender outbound.PushSender, publicURL string, logger *zap.Logger) inbound.PushNotificationService {
+	return &pushNotificationService{deviceRepo: deviceRepo, userRepo: userRepo, pushSender: pushSender, publicURL: strings.TrimRight(publicURL, "/"), logger: logger}
+}
+
+func (s *pushNotificationService) RegisterDevice(ctx context.Context, pharmacyID, userID uuid.UUID, token, platform string) (*models.DeviceToken, error) {
+	token = strings.TrimSpace(token)
+	if token == "" || len(token) > maxDeviceTokenLength {
+		return nil, errors.ErrValidation("token is required (max 512 characters)")
+	}
+	if platform == "" {
+		platform = models.DevicePlatformWeb
+	}
+	switch platform {
+	case models.DevicePlatformWeb, models.DevicePlatformAndroid, models.DevicePlatformIOS:
+	default:
+		return nil, errors.ErrValidation("platform must be web, android or ios")
+	}
+	d := &models.DeviceToken{PharmacyID: pharmacyID, UserID: userID, Token: token, Platform: platform}
+	if err := s.deviceRepo.Upsert(ctx, d); err != nil {
+		return nil, errors.ErrInternal("failed to register device", err)
+	}
+	return d, nil
+}
+
+func (s *pushNotificationService) UnregisterDevice(ctx context.Context, userID uuid.UUID, token string) error {
+	if strings.TrimSpace(token) == "" {
+		return errors.ErrValidation("token is required")
+	}
+	return s.deviceRepo.Delete(ctx, userID, strings.TrimSpace(token))
+}
+
+func (s *pushNotificationService) ListDevices(ctx context.Context, userID uuid.UUID) ([]*models.DeviceToken, error) {
+	return s.deviceRepo.ListByUser(ctx, userID)
+}
+
+func (s *pushNotificationService) NotifyUser(ctx context.Context, userID uuid.UUID, title, body, path string, data map[string]string) error {
+	if s.pushSender == nil {
+		return nil
+	}
+	devices, err := s.deviceRepo.ListByUser(ctx, userID)
+	if err != nil {
+		s.logger.Warn("failed to load devices for push", zap.String("user_id", userID.String()), zap.Error(err))
+		return err
+	}
+	link := ""
+	if s.publicURL != "" && path != "" {
+		link = s.publicURL + path
+	}
+	body = truncateRunes(body, maxPushBodyLength)
+	var firstErr error
+	for _, d := range devices {
+		msg := &outbound.PushMessage{Token: d.Token, Title: title, Body: body, Data: data, Link: link}
+		if err := s.pushSender.Send(ctx, msg); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		s.logger.Warn("push fan-out incomplete", zap.String("user_id", userID.String()), zap.Error(firstErr))
+	}
+	return firstErr
+}
+
+func (s *pushNotificationService) OrderStatusChanged(ctx context.Context, order *models.Order) error {
+	if s.pushSender == nil || order == nil {
+		return nil
+	}
+	text, ok := orderPushText[order.Status]
+	if !ok {
+		return nil
+	}
+	// Orders rung up by the team are created by a staff member; only buyers get order pushes.
+	buyer, err := s.userRepo.GetByID(ctx, order.CreatedBy)
+	if err != nil || buyer == nil || buyer.Role != models.RoleStaff {
+		return nil
+	}
+	data := map[string]string{"type": "order", "order_id": order.ID.String(), "status": string(order.Status)}
+	return s.NotifyUser(ctx, buyer.ID, text[0], fmt.Sprintf(text[1], order.OrderNumber), "/orders", data)
+}
+
+func (s *pushNotificationService) ChatMessage(ctx context.Context, conv *models.Conversation, msg *models.ChatMessage) error {
+	if s.pushSender == nil || conv == nil || msg == nil {
+		return nil
+	}
+	body := msg.Body
+	if body == "" && msg.AttachmentURL != "" {
+		body = "Sent an attachment"
+	}
+	data := map[string]string{"type": "chat", "conversation_id": conv.ID.String(), "message_id": msg.ID.String()}
+	fromBuyer := msg.SenderType == models.SenderTypeCustomer || (conv.UserID != nil && *conv.UserID == msg.SenderID)
+	if !fromBuyer {
+		if conv.UserID == nil {
+			return nil // walk-in customers have no account to push to
+		}
+		return s.NotifyUser(ctx, *conv.UserID, "New message from the pharmacy", body, "/chat", data)
+	}
+	users, err := s.userRepo.GetByPharmacyID(ctx, conv.PharmacyID)
+	if err != nil {
+		s.logger.Warn("failed to load team for chat push", zap.String("conversation_id", conv.ID.String()), zap.Error(err))
+		return err
+	}
+	for _, u := range users {
+		if u.IsActive && u.Role != models.RoleStaff && u.ID != msg.SenderID {
+			_ = s.NotifyUser(ctx, u.ID, "New customer message", body, "/chat", data)
+		}
+	}
+	return nil
+}
+
+func (s *pushNotificationService) AnnouncementPublished(ctx context.Context, a *models.Announcement) error {
+	if s.pushSender == nil || a == nil || !a.IsActive {
+		return nil
+	}
+	now := time.Now()
+	if (a.StartAt != nil && a.StartAt.After(now)) || (a.EndAt != nil && !a.EndAt.After(now)) {
+		return nil
+	}
+	users, err := s.userRepo.GetByPharmacyID(ctx, a.PharmacyID)
+	if err != nil {
+		s.logger.Warn("failed to load users for announcement push", zap.String("announcement_id", a.ID.String()), zap.Error(err))
+		return err
+	}
+	data := map[string]string{"type": "announcement", "announcement_id": a.ID.String()}
+	for _, u := range users {
+		if !u.IsActive {
+			continue
+		}
+		title, body := a.Title, a.Body
+		if v, _, ok := a.Translations.Pick(u.PreferredLanguage); ok {
+			title, body = v.Title, v.Body
+		}
+		_ = s.NotifyUser(ctx, u.ID, title, body, "/dashboard", data)
+	}
+	return nil
+}
+
+// truncateRunes shortens s to at most n runes, ending with an ellipsis when cut.
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
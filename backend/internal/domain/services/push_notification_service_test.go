@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// recordingPushSender captures pushed messages.
+type recordingPushSender struct {
+	sent []*outbound.PushMessage
+}
+
+func (r *recordingPushSender) Send(ctx context.Context, msg *outbound.PushMessage) error {
+	r.sent = append(r.sent, msg)
+	return nil
+}
+
+type pushFixture struct {
+	pharmacyID uuid.UUID
+	buyer      *models.User
+	manager    *models.User
+	users      []*models.User
+	devices    map[uuid.UUID][]*models.DeviceToken
+	sender     *recordingPushSender
+	svc        inbound.PushNotificationService
+}
+
+func newPushFixture() *pushFixture {
+	f := &pushFixture{pharmacyID: uuid.New(), sender: &recordingPushSender{}, devices: map[uuid.UUID][]*models.DeviceToken{}}
+	f.buyer = &models.User{ID: uuid.New(), PharmacyID: f.pharmacyID, Role: models.RoleStaff, IsActive: true}
+	f.manager = &models.User{ID: uuid.New(), PharmacyID: f.pharmacyID, Role: models.RoleManager, IsActive: true}
+	inactive := &models.User{ID: uuid.New(), PharmacyID: f.pharmacyID, Role: models.RolePharmacist, IsActive: false}
+	f.users = []*models.User{f.buyer, f.manager, inactive}
+	for _, u := range f.users {
+		f.devices[u.ID] = []*models.DeviceToken{{UserID: u.ID, Token: "tok-" + u.ID.String()}}
+	}
+	users := &mocks.MockUserRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.User, error) {
+			for _, u := range f.users {
+				if u.ID == id {
+					return u, nil
+				}
+			}
+			return nil, nil
+		},
+		GetByPharmacyIDFunc: func(ctx context.Context, pharmacyID uuid.UUID) ([]*models.User, error) { return f.users, nil },
+	}
+	devices := &mocks.MockDeviceTokenRepository{
+		ListByUserFunc: func(ctx context.Context, userID uuid.UUID) ([]*models.DeviceToken, error) {
+			return f.devices[userID], nil
+		},
+	}
+	f.svc = NewPushNotificationService(devices, users, f.sender, "https://shop.example.com/", zap.NewNop())
+	return f
+}
+
+func (f *pushFixture) sentTo(u *models.User) int {
+	n := 0
+	for _, m := range f.sender.sent {
+		if m.Token == "tok-"+u.ID.String() {
+			n++
+		}
+	}
+	return n
+}
+
+func TestPushNotificationService_RegisterDevice_Validates(t *testing.T) {
+	f := newPushFixture()
+	if _, err := f.svc.RegisterDevice(context.Background(), f.pharmacyID, f.buyer.ID, "  ", ""); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("expected validation error for empty token, got %v", err)
+	}
+	if _, err := f.svc.RegisterDevice(context.Background(), f.pharmacyID, f.buyer.ID, "abc", "blackberry"); pkgerrors.GetAppError(err) == nil {
+		t.Errorf("expected validation error for unknown platform")
+	}
+	d, err := f.svc.RegisterDevice(context.Background(), f.pharmacyID, f.buyer.ID, " abc ", "")
+	if err != nil {
+		t.Fatalf("RegisterDevice: %v", err)
+	}
+	if d.Token != "abc" || d.Platform != models.DevicePlatformWeb || d.UserID != f.buyer.ID {
+		t.Errorf("unexpected device: %+v", d)
+	}
+}
+
+func TestPushNotificationService_OrderStatusChanged_BuyerOnly(t *testing.T) {
+	f := newPushFixture()
+	order := &models.Order{ID: uuid.New(), PharmacyID: f.pharmacyID, OrderNumber: "ORD-7", Status: models.OrderStatusReady, CreatedBy: f.buyer.ID}
+	if err := f.svc.OrderStatusChanged(context.Background(), order); err != nil {
+		t.Fatalf("OrderStatusChanged: %v", err)
+	}
+	if len(f.sender.sent) != 1 || f.sentTo(f.buyer) != 1 {
+		t.Fatalf("expected one push to the buyer, got %+v", f.sender.sent)
+	}
+	if m := f.sender.sent[0]; m.Data["order_id"] != order.ID.String() || m.Link != "https://shop.example.com/orders" {
+		t.Errorf("unexpected message: %+v", m)
+	}
+
+	// Orders rung up by the team and statuses without text are not pushed.
+	order.CreatedBy = f.manager.ID
+	_ = f.svc.OrderStatusChanged(context.Background(), order)
+	order.CreatedBy, order.Status = f.buyer.ID, models.OrderStatusProcessing
+	_ = f.svc.OrderStatusChanged(context.Background(), order)
+	if len(f.sender.sent) != 1 {
+		t.Errorf("expected no further pushes, got %d", len(f.sender.sent))
+	}
+}
+
+func TestPushNotificationService_ChatMessage_Recipients(t *testing.T) {
+	f := newPushFixture()
+	conv := &models.Conversation{ID: uuid.New(), PharmacyID: f.pharmacyID, UserID: &f.buyer.ID}
+
+	fromBuyer := &models.ChatMessage{ID: uuid.New(), SenderType: models.SenderTypeUser, SenderID: f.buyer.ID, Body: "Is this in stock?"}
+	if err := f.svc.ChatMessage(context.Background(), conv, fromBuyer); err != nil {
+		t.Fatalf("ChatMessage: %v", err)
+	}
+	if len(f.sender.sent) != 1 || f.sentTo(f.manager) != 1 {
+		t.Fatalf("expected one push to the active team member, got %+v", f.sender.sent)
+	}
+
+	reply := &models.ChatMessage{ID: uuid.New(), SenderType: models.SenderTypeUser, SenderID: f.manager.ID, Body: "Yes"}
+	if err := f.svc.ChatMessage(context.Background(), conv, reply); err != nil {
+		t.Fatalf("ChatMessage: %v", err)
+	}
+	if len(f.sender.sent) != 2 || f.sentTo(f.buyer) != 1 {
+		t.Errorf("expected the reply pushed to the buyer, got %+v", f.sender.sent)
+	}
+}
+
+func TestPushNotificationService_AnnouncementPublished_LiveOnly(t *testing.T) {
+	f := newPushFixture()
+	later := time.Now().Add(time.Hour)
+	a := &models.Announcement{ID: uuid.New(), PharmacyID: f.pharmacyID, Title: "Holiday hours", IsActive: true, StartAt: &later}
+	_ = f.svc.AnnouncementPublished(context.Background(), a)
+	if len(f.sender.sent) != 0 {
+		t.Fatalf("expected no push for a scheduled announcement, got %d", len(f.sender.sent))
+	}
+	a.StartAt = nil
+	if err := f.svc.AnnouncementPublished(context.Background(), a); err != nil {
+		t.Fatalf("AnnouncementPublished: %v", err)
+	}
+	if len(f.sender.sent) != 2 || f.sentTo(f.buyer) != 1 || f.sentTo(f.manager) != 1 {
+		t.Errorf("expected pushes to the two active users, got %+v", f.sender.sent)
+	}
+}
+
+func TestNotificationService_Create_PushesToDevices(t *testing.T) {
+	f := newPushFixture()
+	svc := NewNotificationService(&mocks.MockNotificationRepository{}, f.svc, nil, nil, nil, nil, zap.NewNop())
+	if _, err := svc.Create(context.Background(), f.pharmacyID, f.manager.ID, "Low rating", "2 stars", "feedback"); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(f.sender.sent) != 1 || f.sentTo(f.manager) != 1 || f.sender.sent[0].Data["type"] != "feedback" {
+		t.Errorf("expected the notification pushed to the recipient, got %+v", f.sender.sent)
+	}
+}
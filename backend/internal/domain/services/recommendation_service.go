@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	recentViewsKept      = 50 // per viewer; older views are pruned on the next view
+	recentViewsShown     = 12 // recently viewed products returned with recommendations
+	recentViewsRetention = 90 * 24 * time.Hour
+	recommendationSeeds  = 10                   // recent views used to seed suggestions
+	recommendationWindow = 180 * 24 * time.Hour // order history considered
+)
+
+type recommendationService struct {
+	repo           outbound.RecommendationRepository
+	productRepo    outbound.ProductRepository
+	hashtagService inbound.HashtagService
+	now            func() time.Time
+	logger         *zap.Logger
+}
+
+// NewRecommendationService builds the service; hashtagService keeps counting views for trending hashtags.
+func NewRecommendationService(repo outbound.RecommendationRepository, productRepo outbound.ProductRepository, hashtagService inbound.HashtagService, logger *zap.Logger) inbound.RecommendationService {
+	return &recommendationService{repo: repo, productRepo: productRepo, hashtagService: hashtagService, now: time.Now, logger: logger}
+}
+
+// viewerKey is the RecentView.Viewer of the viewer, or "" when anonymous.
+func viewerKey(v inbound.StorefrontViewer) string {
+	switch {
+	case v.UserID != nil:
+		return "user:" + v.UserID.String()
+	case v.SessionID != "":
+		return "session:" + v.SessionID
+	}
+	return ""
+}
+
+func (s *recommendationService) RecordView(ctx context.Context, pharmacyID, productID uuid.UUID, viewer inbound.StorefrontViewer) error {
+	// Validates the product and counts the view.
+	if err := s.hashtagService.RecordProductView(ctx, pharmacyID, productID); err != nil {
+		return err
+	}
+	key := viewerKey(viewer)
+	if key == "" {
+		return nil
+	}
+	v := &models.RecentView{PharmacyID: pharmacyID, Viewer: key, UserID: viewer.UserID, ProductID: productID, ViewedAt: s.now()}
+	if err := s.repo.TouchView(ctx, v); err != nil {
+		return errors.ErrInternal("failed to record view", err)
+	}
+	if err := s.repo.PruneViews(ctx, key, recentViewsKept); err != nil {
+		s.logger.Warn("failed to prune recent views", zap.Error(err))
+	}
+	return nil
+}
+
+func (s *recommendationService) Recommended(ctx context.Context, pharmacyID uuid.UUID, viewer inbound.StorefrontViewer, productID *uuid.UUID, limit int) (*inbound.ProductRecommendations, error) {
+	if limit <= 0 {
+		limit = 8
+	} else if limit > 24 {
+		limit = 24
+	}
+	out := &inbound.ProductRecommendations{RecentlyViewed: []*models.Product{}, Recommended: []*inbound.RecommendedProduct{}}
+	var seeds []*models.Product
+	if productID != nil {
+		p, err := s.productRepo.GetByID(ctx, *productID)
+		if err != nil || p == nil || p.PharmacyID != pharmacyID || !p.IsActive {
+			return nil, errors.ErrNotFound("product")
+		}
+		seeds = append(seeds, p)
+	}
+	if key := viewerKey(viewer); key != "" {
+		views, err := s.repo.ListViews(ctx, pharmacyID, key, recentViewsShown)
+		if err != nil {
+			return nil, errors.ErrInternal("failed to load recently viewed", err)
+		}
+		for i, v := range views {
+			if v.Product == nil {
+				continue
+			}
+			out.RecentlyViewed = append(out.RecentlyViewed, v.Product)
+			if i < recommendationSeeds && (productID == nil || v.ProductID != *productID) {
+				seeds = append(seeds, v.Product)
+			}
+		}
+	}
+	if len(seeds) == 0 {
+		return out, nil
+	}
+
+	seedIDs := make([]uuid.UUID, 0, len(seeds))
+	var categories []string
+	hasCategory := map[string]bool{}
+	for _, p := range seeds {
+		seedIDs = append(seedIDs, p.ID)
+		if p.Category != "" && !hasCategory[p.Category] {
+			hasCategory[p.Category] = true
+			categories = append(categories, p.Category)
+		}
+	}
+	since := s.now().Add(-recommendationWindow)
+	picked := append([]uuid.UUID{}, seedIDs...)
+
+	scores, err := s.repo.BoughtTogether(ctx, pharmacyID, seedIDs, nil, since, limit)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load recommendations", err)
+	}
+	for _, sc := range scores {
+		p, err := s.productRepo.GetByID(ctx, sc.ProductID)
+		if err != nil || p == nil {
+			continue
+		}
+		out.Recommended = append(out.Recommended, &inbound.RecommendedProduct{Product: p, Reason: inbound.RecommendationBoughtTogether, Score: sc.Score})
+		picked = append(picked, p.ID)
+	}
+	if rest := limit - len(out.Recommended); rest > 0 {
+		similar, err := s.repo.SameCategory(ctx, pharmacyID, categories, picked, since, rest)
+		if err != nil {
+			return nil, errors.ErrInternal("failed to load recommendations", err)
+		}
+		for _, p := range similar {
+			out.Recommended = append(out.Recommended, &inbound.RecommendedProduct{Product: p, Reason: inbound.RecommendationSameCategory})
+		}
+	}
+	return out, nil
+}
+
+func (s *recommendationService) PurgeViews(ctx context.Context) error {
+	n, err := s.repo.DeleteViewsBefore(ctx, s.now().Add(-recentViewsRetention))
+	if err != nil {
+		return fmt.Errorf("purge recent views: %w", err)
+	}
+	if n > 0 {
+		s.logger.Info("recent views purged", zap.Int64("rows", n))
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type countingHashtagService struct {
+	inbound.HashtagService
+	views int
+}
+
+func (f *countingHashtagService) RecordProductView(ctx context.Context, pharmacyID, productID uuid.UUID) error {
+	f.views++
+	return nil
+}
+
+func TestRecommendationService_RecordView_RemembersKnownViewers(t *testing.T) {
+	pharmacyID, productID := uuid.New(), uuid.New()
+	var touched []*models.RecentView
+	repo := &mocks.MockRecommendationRepository{TouchViewFunc: func(ctx context.Context, v *models.RecentView) error {
+		touched = append(touched, v)
+		return nil
+	}}
+	counter := &countingHashtagService{}
+	svc := &recommendationService{repo: repo, hashtagService: counter, now: time.Now, logger: zap.NewNop()}
+	ctx := context.Background()
+
+	if err := svc.RecordView(ctx, pharmacyID, productID, inbound.StorefrontViewer{}); err != nil {
+		t.Fatalf("anonymous view: %v", err)
+	}
+	userID := uuid.New()
+	_ = svc.RecordView(ctx, pharmacyID, productID, inbound.StorefrontViewer{SessionID: "abcdef123456"})
+	_ = svc.RecordView(ctx, pharmacyID, productID, inbound.StorefrontViewer{UserID: &userID, SessionID: "abcdef123456"})
+	if counter.views != 3 {
+		t.Errorf("counted %d views, want every view counted", counter.views)
+	}
+	if len(touched) != 2 || touched[0].Viewer != "session:abcdef123456" || touched[1].Viewer != "user:"+userID.String() {
+		t.Fatalf("remembered %+v, want the session and then the user, not the anonymous view", touched)
+	}
+}
+
+func TestRecommendationService_Recommended_BoughtTogetherThenSameCategory(t *testing.T) {
+	pharmacyID := uuid.New()
+	shown := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Cetamol", Category: "Pain relief", IsActive: true}
+	viewed := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Vitamin C", Category: "Supplements", IsActive: true}
+	paired := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "ORS", IsActive: true, StockQuantity: 4}
+	similar := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Ibuprofen", Category: "Pain relief", IsActive: true}
+	products := map[uuid.UUID]*models.Product{shown.ID: shown, viewed.ID: viewed, paired.ID: paired}
+	var seeds, excluded []uuid.UUID
+	var categories []string
+	repo := &mocks.MockRecommendationRepository{
+		ListViewsFunc: func(ctx context.Context, id uuid.UUID, viewer string, limit int) ([]*models.RecentView, error) {
+			return []*models.RecentView{{ProductID: shown.ID, Product: shown}, {ProductID: viewed.ID, Product: viewed}}, nil
+		},
+		BoughtTogetherFunc: func(ctx context.Context, id uuid.UUID, productIDs, exclude []uuid.UUID, since time.Time, limit int) ([]*models.ProductScore, error) {
+			seeds = productIDs
+			return []*models.ProductScore{{ProductID: paired.ID, Score: 7}}, nil
+		},
+		SameCategoryFunc: func(ctx context.Context, id uuid.UUID, cats []string, exclude []uuid.UUID, since time.Time, limit int) ([]*models.Product, error) {
+			categories, excluded = cats, exclude
+			return []*models.Product{similar}, nil
+		},
+	}
+	productRepo := &mocks.MockProductRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) { return products[id], nil }}
+	svc := &recommendationService{repo: repo, productRepo: productRepo, now: time.Now, logger: zap.NewNop()}
+
+	out, err := svc.Recommended(context.Background(), pharmacyID, inbound.StorefrontViewer{SessionID: "abcdef123456"}, &shown.ID, 5)
+	if err != nil {
+		t.Fatalf("Recommended: %v", err)
+	}
+	if len(seeds) != 2 || seeds[0] != shown.ID || seeds[1] != viewed.ID {
+		t.Errorf("seeds = %v, want the shown product once, then the other recent view", seeds)
+	}
+	if len(out.RecentlyViewed) != 2 {
+		t.Errorf("recently viewed = %d products, want 2", len(out.RecentlyViewed))
+	}
+	if len(out.Recommended) != 2 || out.Recommended[0].Product != paired || out.Recommended[0].Reason != inbound.RecommendationBoughtTogether || out.Recommended[0].Score != 7 ||
+		out.Recommended[1].Product != similar || out.Recommended[1].Reason != inbound.RecommendationSameCategory {
+		t.Fatalf("recommended = %+v", out.Recommended)
+	}
+	if len(categories) != 2 || categories[0] != "Pain relief" || len(excluded) != 3 {
+		t.Errorf("same-category fallback got categories %v and %d exclusions, want seeds and picks excluded", categories, len(excluded))
+	}
+}
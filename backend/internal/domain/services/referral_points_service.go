@@ -3,13 +3,16 @@ package services
 import (
 	"context"
 	"crypto/rand"
+	"math"
 	"math/big"
 	"strings"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/careplus/pharmacy-backend/pkg/tracing"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -24,6 +27,7 @@ type referralPointsService struct {
 	configRepo             outbound.ReferralPointsConfigRepository
 	orderRepo              outbound.OrderRepository
 	userRepo               outbound.UserRepository
+	benefitsEngine         inbound.BenefitsEngine
 	logger                 *zap.Logger
 }
 
@@ -34,6 +38,7 @@ func NewReferralPointsService(
 	configRepo outbound.ReferralPointsConfigRepository,
 	orderRepo outbound.OrderRepository,
 	userRepo outbound.UserRepository,
+	benefitsEngine inbound.BenefitsEngine,
 	logger *zap.Logger,
 ) inbound.ReferralPointsService {
 	return &referralPointsService{
@@ -43,6 +48,7 @@ func NewReferralPointsService(
 		configRepo:             configRepo,
 		orderRepo:              orderRepo,
 		userRepo:               userRepo,
+		benefitsEngine:         benefitsEngine,
 		logger:                 logger,
 	}
 }
@@ -222,6 +228,8 @@ func (s *referralPointsService) ComputeRedeemDiscount(ctx context.Context, pharm
 }
 
 func (s *referralPointsService) PrepareOrderReferralAndPoints(ctx context.Context, pharmacyID uuid.UUID, customerName, customerPhone, customerEmail string, referralCode *string, pointsToRedeem *int, subTotal float64) (customerID *uuid.UUID, referralCodeUsed string, pointsRedeemed int, discountFromPoints float64, err error) {
+	ctx, span := tracing.Start(ctx, "ReferralPointsService.PrepareOrderReferralAndPoints")
+	defer tracing.End(span, &err)
 	cfg, _ := s.GetConfig(ctx, pharmacyID)
 	if cfg == nil {
 		return nil, "", 0, 0, nil
@@ -271,10 +279,13 @@ func (s *referralPointsService) ApplyPointsRedeem(ctx context.Context, orderID,
 	if c.PointsBalance < pointsRedeemed {
 		return errors.ErrValidation("insufficient points balance")
 	}
-	c.PointsBalance -= pointsRedeemed
-	if err := s.customerRepo.Update(ctx, c); err != nil {
+	ok, err := s.customerRepo.AdjustPoints(ctx, c.ID, -pointsRedeemed)
+	if err != nil {
 		return errors.ErrInternal("failed to deduct points", err)
 	}
+	if !ok {
+		return errors.ErrConflict("points were spent on another order meanwhile; please try again")
+	}
 	tx := &models.PointsTransaction{
 		CustomerID: c.ID,
 		Amount:     -pointsRedeemed,
@@ -297,21 +308,32 @@ func (s *referralPointsService) OnOrderCompleted(ctx context.Context, order *mod
 		pointsEarned := 0
 		if cfg.CurrencyUnitForPoints > 0 && cfg.PointsPerCurrencyUnit > 0 {
 			units := int(order.TotalAmount / cfg.CurrencyUnitForPoints)
-			pointsEarned = int(float64(units) * cfg.PointsPerCurrencyUnit)
+			// Tiers with a points multiplier earn more; orders from before multipliers carry 0.
+			multiplier := math.Max(order.PointsMultiplier, 1)
+			pointsEarned = int(float64(units) * cfg.PointsPerCurrencyUnit * multiplier)
 		}
-		if pointsEarned > 0 {
-			c.PointsBalance += pointsEarned
-			if err := s.customerRepo.Update(ctx, c); err != nil {
+		if pointsEarned > 0 || order.BirthdayBonusPoints > 0 {
+			if _, err := s.customerRepo.AdjustPoints(ctx, c.ID, pointsEarned+order.BirthdayBonusPoints); err != nil {
 				s.logger.Warn("failed to update customer points", zap.Error(err))
 				return nil
 			}
-			tx := &models.PointsTransaction{
-				CustomerID: c.ID,
-				Amount:     pointsEarned,
-				Type:       models.PointsTransactionTypeEarnPurchase,
-				OrderID:    &order.ID,
+			if pointsEarned > 0 {
+				tx := &models.PointsTransaction{
+					CustomerID: c.ID,
+					Amount:     pointsEarned,
+					Type:       models.PointsTransactionTypeEarnPurchase,
+					OrderID:    &order.ID,
+				}
+				_ = s.pointsRepo.Create(ctx, tx)
+			}
+			if order.BirthdayBonusPoints > 0 {
+				_ = s.pointsRepo.Create(ctx, &models.PointsTransaction{
+					CustomerID: c.ID,
+					Amount:     order.BirthdayBonusPoints,
+					Type:       models.PointsTransactionTypeEarnBirthday,
+					OrderID:    &order.ID,
+				})
 			}
-			_ = s.pointsRepo.Create(ctx, tx)
 		}
 	}
 	if order.ReferralCodeUsed != "" && order.CustomerID != nil {
@@ -325,8 +347,7 @@ func (s *referralPointsService) OnOrderCompleted(ctx context.Context, order *mod
 		}
 		reward := cfg.ReferralRewardPoints
 		if reward > 0 {
-			referrer.PointsBalance += reward
-			if err := s.customerRepo.Update(ctx, referrer); err != nil {
+			if _, err := s.customerRepo.AdjustPoints(ctx, referrer.ID, reward); err != nil {
 				s.logger.Warn("failed to update referrer points", zap.Error(err))
 				return nil
 			}
@@ -351,6 +372,36 @@ func (s *referralPointsService) GetCustomerByPhone(ctx context.Context, pharmacy
 	return s.customerRepo.GetByPharmacyAndPhone(ctx, pharmacyID, strings.TrimSpace(phone))
 }
 
+func (s *referralPointsService) SetCustomerLanguage(ctx context.Context, pharmacyID, customerID uuid.UUID, language string) (*models.Customer, error) {
+	if _, ok := models.SupportedLanguages[language]; !ok && language != "" {
+		return nil, errors.ErrValidation("unsupported language; use one of " + strings.Join(sortedLanguageCodes(), ", "))
+	}
+	cust, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil || cust == nil || cust.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("customer")
+	}
+	cust.PreferredLanguage = language
+	if err := s.customerRepo.Update(ctx, cust); err != nil {
+		return nil, errors.ErrInternal("failed to update customer", err)
+	}
+	return cust, nil
+}
+
+func (s *referralPointsService) SetCustomerDateOfBirth(ctx context.Context, pharmacyID, customerID uuid.UUID, dob *time.Time) (*models.Customer, error) {
+	if dob != nil && (dob.After(time.Now()) || dob.Year() < 1900) {
+		return nil, errors.ErrValidation("date of birth must be between 1900 and today")
+	}
+	cust, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil || cust == nil || cust.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("customer")
+	}
+	cust.DateOfBirth = dob
+	if err := s.customerRepo.Update(ctx, cust); err != nil {
+		return nil, errors.ErrInternal("failed to update customer", err)
+	}
+	return cust, nil
+}
+
 func (s *referralPointsService) GetCustomerByPhoneWithMembership(ctx context.Context, pharmacyID uuid.UUID, phone string) (*inbound.CustomerWithMembership, error) {
 	cust, err := s.customerRepo.GetByPharmacyAndPhone(ctx, pharmacyID, strings.TrimSpace(phone))
 	if err != nil || cust == nil {
@@ -410,5 +461,10 @@ func (s *referralPointsService) GetMyCustomerProfile(ctx context.Context, userID
 		PointsEarnedFromPurchases: pointsEarnedFromPurchases,
 		PointsTransactions:       txs,
 	}
+	if s.benefitsEngine != nil {
+		if b, err := s.benefitsEngine.ForCustomer(ctx, cust.ID, time.Now()); err == nil {
+			out.Benefits = b
+		}
+	}
 	return out, nil
 }
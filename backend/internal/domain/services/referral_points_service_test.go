@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestReferralPointsService_ApplyPointsRedeem_DoubleSpendIsConflict(t *testing.T) {
+	customerID := uuid.New()
+	balance := 100
+	customers := &mocks.MockCustomerRepository{
+		// Both orders read the balance before either spends it.
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Customer, error) {
+			return &models.Customer{ID: id, PointsBalance: 100}, nil
+		},
+		AdjustPointsFunc: func(ctx context.Context, id uuid.UUID, delta int) (bool, error) {
+			if balance+delta < 0 {
+				return false, nil
+			}
+			balance += delta
+			return true, nil
+		},
+	}
+	var ledger []*models.PointsTransaction
+	points := &mocks.MockPointsTransactionRepository{
+		CreateFunc: func(ctx context.Context, p *models.PointsTransaction) error {
+			ledger = append(ledger, p)
+			return nil
+		},
+	}
+	svc := &referralPointsService{customerRepo: customers, pointsRepo: points, logger: zap.NewNop()}
+
+	if err := svc.ApplyPointsRedeem(context.Background(), uuid.New(), customerID, 80); err != nil {
+		t.Fatalf("first redemption: %v", err)
+	}
+	if balance != 20 || len(ledger) != 1 {
+		t.Fatalf("balance = %d, want 20 after the first redemption", balance)
+	}
+	err := svc.ApplyPointsRedeem(context.Background(), uuid.New(), customerID, 80)
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeConflict {
+		t.Fatalf("err = %v, want conflict for the second redemption", err)
+	}
+	if balance != 20 || len(ledger) != 1 {
+		t.Errorf("balance = %d, ledger = %d; want the second redemption to take nothing", balance, len(ledger))
+	}
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultReportRangeDays   = 30
+	maxReportRangeDays       = 366 * 2
+	defaultLowStockThreshold = 10
+	defaultDeadStockDays     = 90
+	maxDeadStockRows         = 500
+)
+
+type reportService struct {
+	reportRepo outbound.ReportRepository
+	configRepo outbound.PharmacyConfigRepository
+	logger     *zap.Logger
+}
+
+func NewReportService(reportRepo outbound.ReportRepository, configRepo outbound.PharmacyConfigRepository, logger *zap.Logger) inbound.ReportService {
+	return &reportService{reportRepo: reportRepo, configRepo: configRepo, logger: logger}
+}
+
+// config returns the pharmacy's config, nil when it has none. Report totals are in its base currency.
+func (s *reportService) config(ctx context.Context, pharmacyID uuid.UUID) *models.PharmacyConfig {
+	if s.configRepo == nil {
+		return nil
+	}
+	cfg, _ := s.configRepo.GetByPharmacyID(ctx, pharmacyID)
+	return cfg
+}
+
+// normalizeRange fills zero bounds (to = now, from = to - 30 days) and rejects empty or overly long ranges.
+func normalizeRange(from, to time.Time) (time.Time, time.Time, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -defaultReportRangeDays)
+	}
+	if !from.Before(to) {
+		return from, to, errors.ErrValidation("from must be before to")
+	}
+	if to.Sub(from) > maxReportRangeDays*24*time.Hour {
+		return from, to, errors.ErrValidation("date range is too long (max 2 years)")
+	}
+	return from, to, nil
+}
+
+func (s *reportService) SalesSummary(ctx context.Context, pharmacyID uuid.UUID, granularity string, from, to time.Time, branchID *uuid.UUID) (*inbound.SalesSummaryReport, error) {
+	switch granularity {
+	case "":
+		granularity = "day"
+	case "day", "week", "month":
+	default:
+		return nil, errors.ErrValidation("granularity must be day, week or month")
+	}
+	from, to, err := normalizeRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.reportRepo.SalesByPeriod(ctx, pharmacyID, granularity, from, to, branchID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load sales summary", err)
+	}
+	report := &inbound.SalesSummaryReport{Granularity: granularity, From: from, To: to, Rows: rows}
+	for _, r := range rows {
+		report.TotalOrders += r.OrdersCount
+		report.TotalRevenue += r.Revenue
+	}
+	return report, nil
+}
+
+func (s *reportService) TopSellingProducts(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time, branchID *uuid.UUID, limit int) ([]*models.TopProductRow, error) {
+	from, to, err := normalizeRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	rows, err := s.reportRepo.TopSellingProducts(ctx, pharmacyID, from, to, branchID, limit)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load top products", err)
+	}
+	return rows, nil
+}
+
+func (s *reportService) RevenueByPaymentMethod(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.PaymentMethodRow, error) {
+	from, to, err := normalizeRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.reportRepo.RevenueByPaymentMethod(ctx, pharmacyID, from, to)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load revenue by payment method", err)
+	}
+	return rows, nil
+}
+
+func (s *reportService) LowStock(ctx context.Context, pharmacyID uuid.UUID, threshold int) ([]*models.LowStockRow, error) {
+	if threshold <= 0 {
+		threshold = defaultLowStockThreshold
+	}
+	rows, err := s.reportRepo.LowStock(ctx, pharmacyID, threshold)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load low stock report", err)
+	}
+	return rows, nil
+}
+
+// ExpiringStock defaults to the next 30 days (unlike sales reports, which look back).
+func (s *reportService) ExpiringStock(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) (*inbound.ExpiringStockReport, error) {
+	if from.IsZero() {
+		from = time.Now()
+	}
+	if to.IsZero() {
+		to = from.AddDate(0, 0, defaultReportRangeDays)
+	}
+	from, to, err := normalizeRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.reportRepo.ExpiringStock(ctx, pharmacyID, from, to)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load expiring stock report", err)
+	}
+	report := &inbound.ExpiringStockReport{From: from, To: to, Rows: rows}
+	for _, r := range rows {
+		report.TotalQuantity += r.Quantity
+		report.TotalValue += r.Value
+	}
+	return report, nil
+}
+
+func (s *reportService) TaxSummary(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) (*inbound.TaxSummaryReport, error) {
+	from, to, err := normalizeRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.reportRepo.TaxByRate(ctx, pharmacyID, from, to)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load tax report", err)
+	}
+	report := &inbound.TaxSummaryReport{From: from, To: to, Rows: rows, Currency: s.config(ctx, pharmacyID).Currency()}
+	for _, r := range rows {
+		report.TotalTaxable += r.TaxableAmount
+		report.TotalTax += r.TaxAmount
+	}
+	report.TotalTaxable = models.RoundAmount(report.TotalTaxable, report.Currency)
+	report.TotalTax = models.RoundAmount(report.TotalTax, report.Currency)
+	return report, nil
+}
+
+func (s *reportService) SalesRegister(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) (*inbound.SalesRegisterReport, error) {
+	from, to, err := normalizeRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.reportRepo.SalesRegister(ctx, pharmacyID, from, to)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load sales register", err)
+	}
+	report := &inbound.SalesRegisterReport{From: from, To: to, Rows: rows}
+	if report.Rows == nil {
+		report.Rows = []*models.SalesRegisterRow{}
+	}
+	cfg := s.config(ctx, pharmacyID)
+	if cfg != nil {
+		report.OrderFields = cfg.OrderFields
+	}
+	report.Currency = cfg.Currency()
+	for _, r := range rows {
+		report.TotalTaxable += r.TaxableAmount
+		report.TotalTax += r.TaxAmount
+		report.TotalAmount += r.TotalAmount
+		if r.DocumentType == "credit_note" {
+			report.CreditedTotal -= r.TotalAmount
+		}
+	}
+	report.TotalTaxable = models.RoundAmount(report.TotalTaxable, report.Currency)
+	report.TotalTax = models.RoundAmount(report.TotalTax, report.Currency)
+	report.TotalAmount = models.RoundAmount(report.TotalAmount, report.Currency)
+	report.CreditedTotal = models.RoundAmount(report.CreditedTotal, report.Currency)
+	return report, nil
+}
+
+// DeadStock looks back days (default 90, at most two years) for sales; products added within that window are not
+// dead yet and are left out.
+func (s *reportService) DeadStock(ctx context.Context, pharmacyID uuid.UUID, days int, minValue float64, limit int) (*inbound.DeadStockReport, error) {
+	if days <= 0 {
+		days = defaultDeadStockDays
+	}
+	if days > maxReportRangeDays {
+		return nil, errors.ErrValidation("days is too long (max 2 years)")
+	}
+	if minValue < 0 {
+		return nil, errors.ErrValidation("min_value cannot be negative")
+	}
+	if limit <= 0 || limit > maxDeadStockRows {
+		limit = maxDeadStockRows
+	}
+	since := time.Now().AddDate(0, 0, -days)
+	rows, err := s.reportRepo.DeadStock(ctx, pharmacyID, since, minValue, limit)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load dead stock report", err)
+	}
+	report := &inbound.DeadStockReport{Days: days, Since: since, Rows: rows, Currency: s.config(ctx, pharmacyID).Currency()}
+	for _, r := range rows {
+		report.TotalQuantity += r.StockQuantity
+		report.TotalValue += r.StockValue
+	}
+	report.TotalValue = models.RoundAmount(report.TotalValue, report.Currency)
+	return report, nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestReportService_SalesSummary_DefaultsAndTotals(t *testing.T) {
+	ctx := context.Background()
+	repo := &mocks.MockReportRepository{}
+	var gotGranularity string
+	var gotFrom, gotTo time.Time
+	repo.SalesByPeriodFunc = func(ctx context.Context, pharmacyID uuid.UUID, granularity string, from, to time.Time, branchID *uuid.UUID) ([]*models.SalesPeriodRow, error) {
+		gotGranularity, gotFrom, gotTo = granularity, from, to
+		return []*models.SalesPeriodRow{
+			{OrdersCount: 2, Revenue: 150.5},
+			{OrdersCount: 3, Revenue: 49.5},
+		}, nil
+	}
+
+	svc := NewReportService(repo, nil, zap.NewNop())
+	report, err := svc.SalesSummary(ctx, uuid.New(), "", time.Time{}, time.Time{}, nil)
+	if err != nil {
+		t.Fatalf("SalesSummary failed: %v", err)
+	}
+	if gotGranularity != "day" {
+		t.Errorf("expected default granularity day, got %q", gotGranularity)
+	}
+	if d := gotTo.Sub(gotFrom); d != defaultReportRangeDays*24*time.Hour {
+		t.Errorf("expected default range of %d days, got %v", defaultReportRangeDays, d)
+	}
+	if report.TotalOrders != 5 || report.TotalRevenue != 200 {
+		t.Errorf("unexpected totals: orders=%d revenue=%v", report.TotalOrders, report.TotalRevenue)
+	}
+}
+
+func TestReportService_SalesSummary_InvalidGranularity(t *testing.T) {
+	svc := NewReportService(&mocks.MockReportRepository{}, nil, zap.NewNop())
+	_, err := svc.SalesSummary(context.Background(), uuid.New(), "hour", time.Time{}, time.Time{}, nil)
+	appErr := pkgerrors.GetAppError(err)
+	if appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("expected VALIDATION_ERROR, got %v", err)
+	}
+}
+
+func TestReportService_RangeValidation(t *testing.T) {
+	svc := NewReportService(&mocks.MockReportRepository{}, nil, zap.NewNop())
+	now := time.Now()
+	_, err := svc.RevenueByPaymentMethod(context.Background(), uuid.New(), now, now.Add(-time.Hour))
+	appErr := pkgerrors.GetAppError(err)
+	if appErr == nil || appErr.Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("expected VALIDATION_ERROR for from after to, got %v", err)
+	}
+}
+
+func TestReportService_ExpiringStock_DefaultsForward(t *testing.T) {
+	repo := &mocks.MockReportRepository{}
+	repo.ExpiringStockFunc = func(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.ExpiringStockRow, error) {
+		if !to.After(time.Now()) {
+			t.Errorf("expected default window in the future, got to=%v", to)
+		}
+		return []*models.ExpiringStockRow{{Quantity: 4, Value: 40}, {Quantity: 1, Value: 12.5}}, nil
+	}
+	svc := NewReportService(repo, nil, zap.NewNop())
+	report, err := svc.ExpiringStock(context.Background(), uuid.New(), time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("ExpiringStock failed: %v", err)
+	}
+	if report.TotalQuantity != 5 || report.TotalValue != 52.5 {
+		t.Errorf("unexpected totals: qty=%d value=%v", report.TotalQuantity, report.TotalValue)
+	}
+}
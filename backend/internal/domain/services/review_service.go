@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
@@ -12,16 +13,20 @@ import (
 	"go.uber.org/zap"
 )
 
-const reviewWindowDays = 7
+const (
+	reviewWindowDays       = 7
+	reviewResponseMaxChars = 2000
+)
 
 type reviewService struct {
-	reviewRepo  outbound.ProductReviewRepository
-	likeRepo    outbound.ReviewLikeRepository
-	commentRepo outbound.ReviewCommentRepository
-	productRepo outbound.ProductRepository
-	orderRepo   outbound.OrderRepository
-	userRepo    outbound.UserRepository
-	logger      *zap.Logger
+	reviewRepo   outbound.ProductReviewRepository
+	likeRepo     outbound.ReviewLikeRepository
+	commentRepo  outbound.ReviewCommentRepository
+	productRepo  outbound.ProductRepository
+	orderRepo    outbound.OrderRepository
+	userRepo     outbound.UserRepository
+	pharmacyRepo outbound.PharmacyRepository
+	logger       *zap.Logger
 }
 
 func NewReviewService(
@@ -31,16 +36,18 @@ func NewReviewService(
 	productRepo outbound.ProductRepository,
 	orderRepo outbound.OrderRepository,
 	userRepo outbound.UserRepository,
+	pharmacyRepo outbound.PharmacyRepository,
 	logger *zap.Logger,
 ) inbound.ReviewService {
 	return &reviewService{
-		reviewRepo:  reviewRepo,
-		likeRepo:    likeRepo,
-		commentRepo: commentRepo,
-		productRepo: productRepo,
-		orderRepo:   orderRepo,
-		userRepo:    userRepo,
-		logger:      logger,
+		reviewRepo:   reviewRepo,
+		likeRepo:     likeRepo,
+		commentRepo:  commentRepo,
+		productRepo:  productRepo,
+		orderRepo:    orderRepo,
+		userRepo:     userRepo,
+		pharmacyRepo: pharmacyRepo,
+		logger:       logger,
 	}
 }
 
@@ -72,13 +79,17 @@ func (s *reviewService) Create(ctx context.Context, userID uuid.UUID, productID
 		return nil, errors.ErrConflict("you have already reviewed this product")
 	}
 	rev := &models.ProductReview{
+		ID:        uuid.New(),
 		ProductID: productID,
 		UserID:    userID,
 		Rating:    rating,
 		Title:     title,
 		Body:      body,
 	}
-	if err := s.reviewRepo.Create(ctx, rev); err != nil {
+	event := models.NewOutboxEvent(models.EventReviewCreated, prod.PharmacyID, rev.ID, models.ReviewEventData{
+		ReviewID: rev.ID, ProductID: productID, UserID: userID, Rating: rating,
+	})
+	if err := s.reviewRepo.Create(ctx, rev, event); err != nil {
 		return nil, err
 	}
 	return rev, nil
@@ -93,9 +104,9 @@ func (s *reviewService) getMeta(ctx context.Context, rev *models.ProductReview,
 	commentCount, _ := s.commentRepo.CountByReviewID(ctx, rev.ID)
 	return &inbound.ProductReviewWithMeta{
 		ProductReview: rev,
-		LikeCount:      likeCount,
-		UserLiked:      userLiked,
-		CommentCount:   commentCount,
+		LikeCount:     likeCount,
+		UserLiked:     userLiked,
+		CommentCount:  commentCount,
 	}, nil
 }
 
@@ -104,7 +115,22 @@ func (s *reviewService) GetByID(ctx context.Context, id uuid.UUID, userID *uuid.
 	if err != nil || rev == nil {
 		return nil, errors.ErrNotFound("review")
 	}
-	return s.getMeta(ctx, rev, userID)
+	meta, err := s.getMeta(ctx, rev, userID)
+	if err != nil {
+		return nil, err
+	}
+	if resp, err := s.reviewRepo.GetResponse(ctx, rev.ID); err == nil && resp != nil {
+		resp.PharmacyName = s.pharmacyName(ctx, resp.PharmacyID)
+		meta.PharmacyResponse = resp
+	}
+	return meta, nil
+}
+
+func (s *reviewService) pharmacyName(ctx context.Context, pharmacyID uuid.UUID) string {
+	if p, err := s.pharmacyRepo.GetByID(ctx, pharmacyID); err == nil && p != nil {
+		return p.Name
+	}
+	return ""
 }
 
 func (s *reviewService) ListByProductID(ctx context.Context, productID uuid.UUID, userID *uuid.UUID, limit, offset int) ([]*inbound.ProductReviewWithMeta, error) {
@@ -116,9 +142,30 @@ func (s *reviewService) ListByProductID(ctx context.Context, productID uuid.UUID
 		return nil, err
 	}
 	out := make([]*inbound.ProductReviewWithMeta, 0, len(list))
+	ids := make([]uuid.UUID, 0, len(list))
 	for _, rev := range list {
 		meta, _ := s.getMeta(ctx, rev, userID)
 		out = append(out, meta)
+		ids = append(ids, rev.ID)
+	}
+	responses, err := s.reviewRepo.ListResponses(ctx, ids)
+	if err != nil {
+		s.logger.Warn("failed to load review responses", zap.Error(err))
+	}
+	byReview := make(map[uuid.UUID]*models.ReviewResponse, len(responses))
+	for _, resp := range responses {
+		byReview[resp.ReviewID] = resp
+	}
+	// Every review is of the same product, so one pharmacy name serves them all.
+	name := ""
+	for _, meta := range out {
+		if resp := byReview[meta.ID]; resp != nil {
+			if name == "" {
+				name = s.pharmacyName(ctx, resp.PharmacyID)
+			}
+			resp.PharmacyName = name
+			meta.PharmacyResponse = resp
+		}
 	}
 	return out, nil
 }
@@ -208,3 +255,90 @@ func (s *reviewService) DeleteComment(ctx context.Context, commentID, userID uui
 	}
 	return s.commentRepo.Delete(ctx, commentID)
 }
+
+// pharmacyReview returns the review when it is of one of the pharmacy's products.
+func (s *reviewService) pharmacyReview(ctx context.Context, pharmacyID, reviewID uuid.UUID) (*models.ProductReview, error) {
+	rev, err := s.reviewRepo.GetByID(ctx, reviewID)
+	if err != nil || rev == nil {
+		return nil, errors.ErrNotFound("review")
+	}
+	prod, err := s.productRepo.GetByID(ctx, rev.ProductID)
+	if err != nil || prod == nil || prod.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("review")
+	}
+	return rev, nil
+}
+
+func validateReviewResponse(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", errors.ErrValidation("response body is required")
+	}
+	if len([]rune(body)) > reviewResponseMaxChars {
+		return "", errors.ErrValidation("response must be at most 2000 characters")
+	}
+	return body, nil
+}
+
+func (s *reviewService) Respond(ctx context.Context, pharmacyID, reviewID, actorID uuid.UUID, body string) (*models.ReviewResponse, error) {
+	body, err := validateReviewResponse(body)
+	if err != nil {
+		return nil, err
+	}
+	rev, err := s.pharmacyReview(ctx, pharmacyID, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.reviewRepo.GetResponse(ctx, rev.ID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load response", err)
+	}
+	if existing != nil {
+		return nil, errors.ErrConflict("the review already has a pharmacy response; edit it instead")
+	}
+	resp := &models.ReviewResponse{ReviewID: rev.ID, PharmacyID: pharmacyID, Body: body, CreatedBy: actorID}
+	if err := s.reviewRepo.SaveResponse(ctx, resp); err != nil {
+		return nil, errors.ErrInternal("failed to save response", err)
+	}
+	resp.PharmacyName = s.pharmacyName(ctx, pharmacyID)
+	return resp, nil
+}
+
+func (s *reviewService) UpdateResponse(ctx context.Context, pharmacyID, reviewID, actorID uuid.UUID, body string) (*models.ReviewResponse, error) {
+	body, err := validateReviewResponse(body)
+	if err != nil {
+		return nil, err
+	}
+	rev, err := s.pharmacyReview(ctx, pharmacyID, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.reviewRepo.GetResponse(ctx, rev.ID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load response", err)
+	}
+	if resp == nil {
+		return nil, errors.ErrNotFound("response")
+	}
+	resp.Body, resp.UpdatedBy = body, &actorID
+	if err := s.reviewRepo.SaveResponse(ctx, resp); err != nil {
+		return nil, errors.ErrInternal("failed to save response", err)
+	}
+	resp.PharmacyName = s.pharmacyName(ctx, pharmacyID)
+	return resp, nil
+}
+
+func (s *reviewService) DeleteResponse(ctx context.Context, pharmacyID, reviewID uuid.UUID) error {
+	rev, err := s.pharmacyReview(ctx, pharmacyID, reviewID)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.reviewRepo.DeleteResponse(ctx, rev.ID)
+	if err != nil {
+		return errors.ErrInternal("failed to delete response", err)
+	}
+	if !deleted {
+		return errors.ErrNotFound("response")
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakeReviews holds one review and its pharmacy response.
+type fakeReviews struct {
+	outbound.ProductReviewRepository
+	review   *models.ProductReview
+	response *models.ReviewResponse
+	saves    int
+	getErr   error
+}
+
+func (f *fakeReviews) GetByID(ctx context.Context, id uuid.UUID) (*models.ProductReview, error) {
+	if f.review == nil || f.review.ID != id {
+		return nil, nil
+	}
+	return f.review, nil
+}
+
+func (f *fakeReviews) GetResponse(ctx context.Context, reviewID uuid.UUID) (*models.ReviewResponse, error) {
+	if f.getErr != nil {
+		return nil, f.getErr
+	}
+	if f.response == nil {
+		return nil, nil
+	}
+	cp := *f.response
+	return &cp, nil
+}
+
+func (f *fakeReviews) SaveResponse(ctx context.Context, r *models.ReviewResponse) error {
+	f.saves++
+	cp := *r
+	f.response = &cp
+	return nil
+}
+
+func (f *fakeReviews) DeleteResponse(ctx context.Context, reviewID uuid.UUID) (bool, error) {
+	had := f.response != nil
+	f.response = nil
+	return had, nil
+}
+
+func newReviewResponseFixture() (*reviewService, *fakeReviews, uuid.UUID) {
+	pharmacyID := uuid.New()
+	product := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID}
+	reviews := &fakeReviews{review: &models.ProductReview{ID: uuid.New(), ProductID: product.ID, Rating: 2}}
+	svc := &reviewService{
+		reviewRepo: reviews,
+		productRepo: &mocks.MockProductRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+			return product, nil
+		}},
+		pharmacyRepo: &mocks.MockPharmacyRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Pharmacy, error) {
+			return &models.Pharmacy{ID: id, Name: "Care Pharmacy"}, nil
+		}},
+		logger: zap.NewNop(),
+	}
+	return svc, reviews, pharmacyID
+}
+
+func TestReviewService_Respond(t *testing.T) {
+	svc, reviews, pharmacyID := newReviewResponseFixture()
+	ctx, staff := context.Background(), uuid.New()
+	reviewID := reviews.review.ID
+
+	for name, body := range map[string]string{"blank": "   ", "too long": strings.Repeat("x", reviewResponseMaxChars+1)} {
+		if _, err := svc.Respond(ctx, pharmacyID, reviewID, staff, body); appCode(err) != pkgerrors.ErrCodeValidation {
+			t.Errorf("%s body: err = %v, want validation error", name, err)
+		}
+	}
+	if _, err := svc.Respond(ctx, uuid.New(), reviewID, staff, "Thanks"); appCode(err) != pkgerrors.ErrCodeNotFound {
+		t.Errorf("another pharmacy's review: err = %v, want not found", err)
+	}
+	if _, err := svc.Respond(ctx, pharmacyID, uuid.New(), staff, "Thanks"); appCode(err) != pkgerrors.ErrCodeNotFound {
+		t.Errorf("missing review: err = %v, want not found", err)
+	}
+	if reviews.saves != 0 {
+		t.Fatalf("saved %d responses for rejected requests", reviews.saves)
+	}
+
+	resp, err := svc.Respond(ctx, pharmacyID, reviewID, staff, "  Sorry about the delay.  ")
+	if err != nil || resp.Body != "Sorry about the delay." || resp.CreatedBy != staff || resp.PharmacyName != "Care Pharmacy" {
+		t.Fatalf("Respond = %+v, %v", resp, err)
+	}
+	if _, err := svc.Respond(ctx, pharmacyID, reviewID, staff, "Again"); appCode(err) != pkgerrors.ErrCodeConflict {
+		t.Errorf("second response: err = %v, want conflict", err)
+	}
+
+	reviews.getErr = errors.New("connection reset")
+	if _, err := svc.Respond(ctx, pharmacyID, reviewID, staff, "Thanks"); appCode(err) != pkgerrors.ErrCodeInternal {
+		t.Errorf("lookup failure: err = %v, want internal error", err)
+	}
+}
+
+func TestReviewService_UpdateAndDeleteResponse(t *testing.T) {
+	svc, reviews, pharmacyID := newReviewResponseFixture()
+	ctx, author, editor := context.Background(), uuid.New(), uuid.New()
+	reviewID := reviews.review.ID
+
+	if _, err := svc.UpdateResponse(ctx, pharmacyID, reviewID, editor, "Edited"); appCode(err) != pkgerrors.ErrCodeNotFound {
+		t.Errorf("update without a response: err = %v, want not found", err)
+	}
+	if err := svc.DeleteResponse(ctx, pharmacyID, reviewID); appCode(err) != pkgerrors.ErrCodeNotFound {
+		t.Errorf("delete without a response: err = %v, want not found", err)
+	}
+
+	if _, err := svc.Respond(ctx, pharmacyID, reviewID, author, "Thanks"); err != nil {
+		t.Fatalf("Respond: %v", err)
+	}
+	if _, err := svc.UpdateResponse(ctx, pharmacyID, reviewID, editor, ""); appCode(err) != pkgerrors.ErrCodeValidation {
+		t.Errorf("blank edit: err = %v, want validation error", err)
+	}
+	resp, err := svc.UpdateResponse(ctx, pharmacyID, reviewID, editor, "Thanks, a refund is on its way.")
+	if err != nil || resp.Body != "Thanks, a refund is on its way." || resp.CreatedBy != author || resp.UpdatedBy == nil || *resp.UpdatedBy != editor {
+		t.Fatalf("UpdateResponse = %+v, %v; want the author kept and the editor recorded", resp, err)
+	}
+
+	if err := svc.DeleteResponse(ctx, uuid.New(), reviewID); appCode(err) != pkgerrors.ErrCodeNotFound || reviews.response == nil {
+		t.Errorf("delete from another pharmacy: err = %v, want not found and the response kept", err)
+	}
+	if err := svc.DeleteResponse(ctx, pharmacyID, reviewID); err != nil || reviews.response != nil {
+		t.Errorf("DeleteResponse: err = %v, response = %+v", err, reviews.response)
+	}
+}
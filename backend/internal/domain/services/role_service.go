@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// permissionCacheTTL bounds how long a role change on another API instance can take to apply here.
+const permissionCacheTTL = time.Minute
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,31}$`)
+
+type cachedPermissions struct {
+	set     map[string]bool
+	expires time.Time
+}
+
+type roleService struct {
+	roleRepo outbound.RoleRepository
+	userRepo outbound.UserRepository
+	logger   *zap.Logger
+
+	mu    sync.RWMutex
+	cache map[uuid.UUID]map[string]cachedPermissions // pharmacy -> role -> permissions
+}
+
+func NewRoleService(roleRepo outbound.RoleRepository, userRepo outbound.UserRepository, logger *zap.Logger) inbound.RoleService {
+	return &roleService{roleRepo: roleRepo, userRepo: userRepo, logger: logger, cache: map[uuid.UUID]map[string]cachedPermissions{}}
+}
+
+func (s *roleService) ListPermissions() []inbound.PermissionInfo {
+	list := make([]inbound.PermissionInfo, 0, len(models.PermissionRegistry))
+	for _, key := range models.AllPermissions() {
+		list = append(list, inbound.PermissionInfo{Key: key, Description: models.PermissionRegistry[key]})
+	}
+	return list
+}
+
+func (s *roleService) ListRoles(ctx context.Context, pharmacyID uuid.UUID) ([]*inbound.RoleView, error) {
+	stored, err := s.roleRepo.ListByPharmacy(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	overrides := map[string]*models.Role{}
+	for _, r := range stored {
+		overrides[r.Name] = r
+	}
+	views := []*inbound.RoleView{}
+	for _, name := range []string{models.RoleAdmin, models.RoleManager, models.RolePharmacist, models.RoleStaff} {
+		views = append(views, builtInRoleView(name, overrides[name]))
+	}
+	for _, r := range stored {
+		if !models.IsBuiltInRole(r.Name) {
+			views = append(views, customRoleView(r))
+		}
+	}
+	return views, nil
+}
+
+func (s *roleService) CreateRole(ctx context.Context, pharmacyID uuid.UUID, input inbound.RoleInput) (*inbound.RoleView, error) {
+	name := strings.ToLower(strings.TrimSpace(input.Name))
+	if !roleNamePattern.MatchString(name) {
+		return nil, errors.ErrValidation("role name must be 2-32 lowercase letters, digits, '_' or '-', starting with a letter")
+	}
+	if models.IsBuiltInRole(name) {
+		return nil, errors.ErrConflict("role " + name + " is built in; update it instead")
+	}
+	perms, err := normalizePermissions(input.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.roleRepo.GetByName(ctx, pharmacyID, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.ErrConflict("role " + name + " already exists")
+	}
+	r := &models.Role{PharmacyID: pharmacyID, Name: name, Description: strings.TrimSpace(input.Description), Permissions: perms}
+	if err := s.roleRepo.Create(ctx, r); err != nil {
+		return nil, errors.ErrInternal("failed to create role", err)
+	}
+	s.invalidate(pharmacyID)
+	return customRoleView(r), nil
+}
+
+func (s *roleService) UpdateRole(ctx context.Context, pharmacyID uuid.UUID, name string, input inbound.RoleInput) (*inbound.RoleView, error) {
+	if name == models.RoleAdmin || name == models.RoleStaff {
+		return nil, errors.ErrForbidden("the " + name + " role cannot be changed")
+	}
+	perms, err := normalizePermissions(input.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.roleRepo.GetByName(ctx, pharmacyID, name)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		if !models.IsBuiltInRole(name) {
+			return nil, errors.ErrNotFound("role")
+		}
+		// First customization of manager or pharmacist: store an override row.
+		r = &models.Role{PharmacyID: pharmacyID, Name: name}
+	}
+	r.Description = strings.TrimSpace(input.Description)
+	r.Permissions = perms
+	if r.ID == uuid.Nil {
+		err = s.roleRepo.Create(ctx, r)
+	} else {
+		err = s.roleRepo.Update(ctx, r)
+	}
+	if err != nil {
+		return nil, errors.ErrInternal("failed to save role", err)
+	}
+	s.invalidate(pharmacyID)
+	if models.IsBuiltInRole(name) {
+		return builtInRoleView(name, r), nil
+	}
+	return customRoleView(r), nil
+}
+
+func (s *roleService) DeleteRole(ctx context.Context, pharmacyID uuid.UUID, name string) error {
+	if name == models.RoleAdmin || name == models.RoleStaff {
+		return errors.ErrForbidden("the " + name + " role cannot be deleted")
+	}
+	r, err := s.roleRepo.GetByName(ctx, pharmacyID, name)
+	if err != nil {
+		return err
+	}
+	if r == nil {
+		if models.IsBuiltInRole(name) {
+			return nil // already at defaults
+		}
+		return errors.ErrNotFound("role")
+	}
+	if !models.IsBuiltInRole(name) {
+		users, err := s.userRepo.GetByPharmacyID(ctx, pharmacyID)
+		if err != nil {
+			return err
+		}
+		for _, u := range users {
+			if u.Role == name {
+				return errors.ErrConflict("role is still assigned to users; move them to another role first")
+			}
+		}
+	}
+	if err := s.roleRepo.Delete(ctx, r.ID); err != nil {
+		return errors.ErrInternal("failed to delete role", err)
+	}
+	s.invalidate(pharmacyID)
+	return nil
+}
+
+func (s *roleService) Permissions(ctx context.Context, pharmacyID uuid.UUID, role string) (map[string]bool, error) {
+	if role == models.RoleAdmin {
+		return toSet(models.AllPermissions()), nil // admins cannot lock themselves out
+	}
+	if role == models.RoleStaff || role == "" {
+		return map[string]bool{}, nil
+	}
+	now := time.Now()
+	s.mu.RLock()
+	entry, ok := s.cache[pharmacyID][role]
+	s.mu.RUnlock()
+	if ok && now.Before(entry.expires) {
+		return entry.set, nil
+	}
+	r, err := s.roleRepo.GetByName(ctx, pharmacyID, role)
+	if err != nil {
+		return nil, err
+	}
+	var perms []string
+	if r != nil {
+		perms = r.Permissions
+	} else {
+		perms = models.DefaultRolePermissions(role) // nil for a role that no longer exists
+	}
+	set := toSet(perms)
+	s.mu.Lock()
+	if s.cache[pharmacyID] == nil {
+		s.cache[pharmacyID] = map[string]cachedPermissions{}
+	}
+	s.cache[pharmacyID][role] = cachedPermissions{set: set, expires: now.Add(permissionCacheTTL)}
+	s.mu.Unlock()
+	return set, nil
+}
+
+func (s *roleService) IsAssignable(ctx context.Context, pharmacyID uuid.UUID, role string) (bool, error) {
+	if role == models.RoleManager || role == models.RolePharmacist || role == models.RoleStaff {
+		return true, nil
+	}
+	if role == models.RoleAdmin {
+		return false, nil
+	}
+	r, err := s.roleRepo.GetByName(ctx, pharmacyID, role)
+	if err != nil {
+		return false, err
+	}
+	return r != nil, nil
+}
+
+func (s *roleService) invalidate(pharmacyID uuid.UUID) {
+	s.mu.Lock()
+	delete(s.cache, pharmacyID)
+	s.mu.Unlock()
+}
+
+// normalizePermissions rejects unknown permissions and returns the rest sorted without duplicates.
+func normalizePermissions(perms []string) ([]string, error) {
+	set := map[string]bool{}
+	for _, p := range perms {
+		p = strings.TrimSpace(p)
+		if _, ok := models.PermissionRegistry[p]; !ok {
+			return nil, errors.ErrValidation("unknown permission " + p)
+		}
+		set[p] = true
+	}
+	out := make([]string, 0, len(set))
+	for p := range set {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func toSet(perms []string) map[string]bool {
+	set := make(map[string]bool, len(perms))
+	for _, p := range perms {
+		set[p] = true
+	}
+	return set
+}
+
+func builtInRoleView(name string, override *models.Role) *inbound.RoleView {
+	v := &inbound.RoleView{
+		Name:        name,
+		Description: map[string]string{models.RoleAdmin: "Full access", models.RoleManager: "Runs the pharmacy and its team", models.RolePharmacist: "Day-to-day dispensing and sales", models.RoleStaff: "Customer account"}[name],
+		Permissions: models.DefaultRolePermissions(name),
+		BuiltIn:     true,
+		Editable:    name == models.RoleManager || name == models.RolePharmacist,
+	}
+	if v.Permissions == nil {
+		v.Permissions = []string{}
+	}
+	if override != nil {
+		v.Permissions = override.Permissions
+		v.Customized = true
+		if override.Description != "" {
+			v.Description = override.Description
+		}
+	}
+	return v
+}
+
+func customRoleView(r *models.Role) *inbound.RoleView {
+	perms := r.Permissions
+	if perms == nil {
+		perms = []string{}
+	}
+	return &inbound.RoleView{Name: r.Name, Description: r.Description, Permissions: perms, Editable: true}
+}
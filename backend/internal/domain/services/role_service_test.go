@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// memoryRoles stores roles in memory and counts lookups so tests can observe caching.
+func memoryRoles() (*mocks.MockRoleRepository, map[string]*models.Role, *int) {
+	roles := map[string]*models.Role{}
+	lookups := 0
+	repo := &mocks.MockRoleRepository{
+		CreateFunc: func(ctx context.Context, r *models.Role) error {
+			r.ID = uuid.New()
+			roles[r.Name] = r
+			return nil
+		},
+		UpdateFunc: func(ctx context.Context, r *models.Role) error {
+			roles[r.Name] = r
+			return nil
+		},
+		GetByNameFunc: func(ctx context.Context, pharmacyID uuid.UUID, name string) (*models.Role, error) {
+			lookups++
+			return roles[name], nil
+		},
+		DeleteFunc: func(ctx context.Context, id uuid.UUID) error {
+			for name, r := range roles {
+				if r.ID == id {
+					delete(roles, name)
+				}
+			}
+			return nil
+		},
+	}
+	return repo, roles, &lookups
+}
+
+func TestRoleService_Permissions_DefaultsAndAdmin(t *testing.T) {
+	repo, _, _ := memoryRoles()
+	svc := NewRoleService(repo, &mocks.MockUserRepository{}, zap.NewNop())
+	pharmacyID := uuid.New()
+
+	pharmacist, err := svc.Permissions(context.Background(), pharmacyID, models.RolePharmacist)
+	if err != nil {
+		t.Fatalf("Permissions: %v", err)
+	}
+	if !pharmacist[models.PermOrdersAccept] || pharmacist[models.PermInventoryWrite] {
+		t.Errorf("unexpected pharmacist defaults %v", pharmacist)
+	}
+	manager, _ := svc.Permissions(context.Background(), pharmacyID, models.RoleManager)
+	if !manager[models.PermInventoryWrite] || manager[models.PermConfigWrite] {
+		t.Errorf("unexpected manager defaults %v", manager)
+	}
+	admin, _ := svc.Permissions(context.Background(), pharmacyID, models.RoleAdmin)
+	if len(admin) != len(models.PermissionRegistry) {
+		t.Errorf("admin should have every permission, got %d", len(admin))
+	}
+	buyer, _ := svc.Permissions(context.Background(), pharmacyID, models.RoleStaff)
+	if len(buyer) != 0 {
+		t.Errorf("buyers should have no permissions, got %v", buyer)
+	}
+}
+
+func TestRoleService_CustomRole_CachedAndInvalidated(t *testing.T) {
+	repo, _, lookups := memoryRoles()
+	svc := NewRoleService(repo, &mocks.MockUserRepository{}, zap.NewNop())
+	pharmacyID := uuid.New()
+	ctx := context.Background()
+
+	if _, err := svc.CreateRole(ctx, pharmacyID, inbound.RoleInput{Name: "Cashier", Permissions: []string{models.PermPaymentsManage, models.PermPaymentsManage}}); err != nil {
+		t.Fatalf("CreateRole: %v", err)
+	}
+	perms, _ := svc.Permissions(ctx, pharmacyID, "cashier")
+	if !perms[models.PermPaymentsManage] || len(perms) != 1 {
+		t.Fatalf("unexpected cashier permissions %v", perms)
+	}
+	before := *lookups
+	_, _ = svc.Permissions(ctx, pharmacyID, "cashier")
+	if *lookups != before {
+		t.Error("second lookup should be served from the cache")
+	}
+
+	if _, err := svc.UpdateRole(ctx, pharmacyID, "cashier", inbound.RoleInput{Permissions: []string{models.PermInvoicesManage}}); err != nil {
+		t.Fatalf("UpdateRole: %v", err)
+	}
+	perms, _ = svc.Permissions(ctx, pharmacyID, "cashier")
+	if perms[models.PermPaymentsManage] || !perms[models.PermInvoicesManage] {
+		t.Errorf("update should invalidate the cache, got %v", perms)
+	}
+}
+
+func TestRoleService_Validation(t *testing.T) {
+	repo, _, _ := memoryRoles()
+	svc := NewRoleService(repo, &mocks.MockUserRepository{}, zap.NewNop())
+	pharmacyID := uuid.New()
+	ctx := context.Background()
+
+	cases := []struct {
+		name string
+		err  error
+		code string
+	}{
+		{"unknown permission", func() error {
+			_, err := svc.CreateRole(ctx, pharmacyID, inbound.RoleInput{Name: "cashier", Permissions: []string{"everything.delete"}})
+			return err
+		}(), pkgerrors.ErrCodeValidation},
+		{"bad name", func() error {
+			_, err := svc.CreateRole(ctx, pharmacyID, inbound.RoleInput{Name: "a b", Permissions: []string{}})
+			return err
+		}(), pkgerrors.ErrCodeValidation},
+		{"built-in name", func() error {
+			_, err := svc.CreateRole(ctx, pharmacyID, inbound.RoleInput{Name: "manager", Permissions: []string{}})
+			return err
+		}(), pkgerrors.ErrCodeConflict},
+		{"edit admin", func() error {
+			_, err := svc.UpdateRole(ctx, pharmacyID, models.RoleAdmin, inbound.RoleInput{Permissions: []string{}})
+			return err
+		}(), pkgerrors.ErrCodeForbidden},
+	}
+	for _, tc := range cases {
+		if appErr := pkgerrors.GetAppError(tc.err); appErr == nil || appErr.Code != tc.code {
+			t.Errorf("%s: expected %s, got %v", tc.name, tc.code, tc.err)
+		}
+	}
+}
+
+func TestRoleService_BuiltInOverrideAndDelete(t *testing.T) {
+	repo, roles, _ := memoryRoles()
+	pharmacyID := uuid.New()
+	users := &mocks.MockUserRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, id uuid.UUID) ([]*models.User, error) {
+			return []*models.User{{PharmacyID: id, Role: "cashier"}}, nil
+		},
+	}
+	svc := NewRoleService(repo, users, zap.NewNop())
+	ctx := context.Background()
+
+	view, err := svc.UpdateRole(ctx, pharmacyID, models.RolePharmacist, inbound.RoleInput{Permissions: []string{models.PermProductsRead}})
+	if err != nil || !view.Customized {
+		t.Fatalf("UpdateRole pharmacist: %v %+v", err, view)
+	}
+	perms, _ := svc.Permissions(ctx, pharmacyID, models.RolePharmacist)
+	if perms[models.PermOrdersAccept] {
+		t.Error("override should replace pharmacist defaults")
+	}
+	if err := svc.DeleteRole(ctx, pharmacyID, models.RolePharmacist); err != nil {
+		t.Fatalf("reset pharmacist: %v", err)
+	}
+	perms, _ = svc.Permissions(ctx, pharmacyID, models.RolePharmacist)
+	if !perms[models.PermOrdersAccept] {
+		t.Error("deleting the override should restore pharmacist defaults")
+	}
+
+	roles["cashier"] = &models.Role{ID: uuid.New(), PharmacyID: pharmacyID, Name: "cashier"}
+	err = svc.DeleteRole(ctx, pharmacyID, "cashier")
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeConflict {
+		t.Errorf("expected conflict deleting a role still assigned, got %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// securityPolicy returns the pharmacy's security policy, or the defaults when none is configured.
+func securityPolicy(ctx context.Context, configRepo outbound.PharmacyConfigRepository, pharmacyID uuid.UUID) *models.SecurityPolicy {
+	if configRepo != nil {
+		if cfg, err := configRepo.GetByPharmacyID(ctx, pharmacyID); err == nil && cfg != nil && cfg.SecurityPolicy != nil {
+			return cfg.SecurityPolicy
+		}
+	}
+	return models.DefaultSecurityPolicy()
+}
+
+// setPassword checks plain against the policy's rules and recent passwords, then sets it on u.
+func setPassword(policy *models.SecurityPolicy, u *models.User, plain string, now time.Time) error {
+	if problem := policy.PasswordProblem(plain); problem != "" {
+		return errors.ErrValidation(problem)
+	}
+	if policy.PasswordReused(u, plain) {
+		return errors.ErrValidation("password was used recently; choose a different one")
+	}
+	if err := policy.ReplacePassword(u, plain, now); err != nil {
+		return errors.ErrInternal("failed to hash password", err)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/careplus/pharmacy-backend/pkg/tracing"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// minSerialSearchLen keeps a serial search from matching most of the stock.
+const minSerialSearchLen = 3
+
+type serialService struct {
+	repo        outbound.SerialRepository
+	productRepo outbound.ProductRepository
+	poRepo      outbound.PurchaseOrderRepository
+	orderRepo   outbound.OrderRepository
+	warrantySvc inbound.WarrantyService
+	uow         outbound.UnitOfWork
+	logger      *zap.Logger
+	now         func() time.Time
+}
+
+func NewSerialService(repo outbound.SerialRepository, productRepo outbound.ProductRepository, poRepo outbound.PurchaseOrderRepository, orderRepo outbound.OrderRepository, warrantySvc inbound.WarrantyService, uow outbound.UnitOfWork, logger *zap.Logger) inbound.SerialService {
+	return &serialService{repo: repo, productRepo: productRepo, poRepo: poRepo, orderRepo: orderRepo, warrantySvc: warrantySvc, uow: uow, logger: logger, now: time.Now}
+}
+
+// serialNumbers trims the numbers and rejects blanks and repeats.
+func serialNumbers(numbers []string) ([]string, error) {
+	out := make([]string, 0, len(numbers))
+	seen := make(map[string]bool, len(numbers))
+	for _, n := range numbers {
+		n = strings.TrimSpace(n)
+		if n == "" {
+			return nil, errors.ErrValidation("serial numbers cannot be blank")
+		}
+		if seen[n] {
+			return nil, errors.ErrValidation("serial number " + n + " is listed twice")
+		}
+		seen[n] = true
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// trackedProduct loads a product of the pharmacy that tracks serials.
+func (s *serialService) trackedProduct(ctx context.Context, pharmacyID, productID uuid.UUID) (*models.Product, error) {
+	p, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil || p == nil || p.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("product")
+	}
+	if !p.TracksSerials {
+		return nil, errors.ErrValidation(p.Name + " does not track serial numbers")
+	}
+	return p, nil
+}
+
+func (s *serialService) Receive(ctx context.Context, pharmacyID, actorID uuid.UUID, in inbound.SerialReceiveInput) ([]*models.ProductSerial, error) {
+	numbers, err := serialNumbers(in.SerialNumbers)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.trackedProduct(ctx, pharmacyID, in.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if in.PurchaseOrderID != nil {
+		po, err := s.poRepo.GetByID(ctx, *in.PurchaseOrderID)
+		if err != nil || po == nil || po.PharmacyID != pharmacyID {
+			return nil, errors.ErrNotFound("purchase order")
+		}
+		onOrder := false
+		for _, it := range po.Items {
+			if it.ProductID == p.ID {
+				onOrder = true
+				break
+			}
+		}
+		if !onOrder {
+			return nil, errors.ErrValidation(p.Name + " is not on purchase order " + po.PONumber)
+		}
+	}
+	existing, err := s.repo.FindByNumbers(ctx, pharmacyID, p.ID, numbers)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to check serial numbers", err)
+	}
+	if len(existing) > 0 {
+		return nil, errors.ErrConflict("serial number " + existing[0].SerialNumber + " is already recorded for " + p.Name)
+	}
+	inStock, err := s.repo.CountInStock(ctx, pharmacyID, p.ID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to count serial numbers", err)
+	}
+	if int(inStock)+len(numbers) > p.StockQuantity {
+		return nil, errors.ErrValidation("receive the stock first: " + p.Name + " has room for " + strconv.Itoa(p.StockQuantity-int(inStock)) + " more serial numbers")
+	}
+	now := s.now()
+	list := make([]*models.ProductSerial, 0, len(numbers))
+	for _, n := range numbers {
+		list = append(list, &models.ProductSerial{
+			PharmacyID: pharmacyID, ProductID: p.ID, SerialNumber: n, Status: models.SerialInStock,
+			PurchaseOrderID: in.PurchaseOrderID, ReceivedBy: actorID, ReceivedAt: now,
+		})
+	}
+	if err := s.repo.CreateBatch(ctx, list); err != nil {
+		return nil, errors.ErrInternal("failed to record serial numbers", err)
+	}
+	return list, nil
+}
+
+func (s *serialService) List(ctx context.Context, pharmacyID uuid.UUID, productID *uuid.UUID, status models.SerialStatus, limit, offset int) ([]*models.ProductSerial, int64, error) {
+	limit, offset = clampPage(limit, offset)
+	list, total, err := s.repo.List(ctx, pharmacyID, outbound.SerialFilter{ProductID: productID, Status: status}, limit, offset)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to list serial numbers", err)
+	}
+	return list, total, nil
+}
+
+func (s *serialService) Search(ctx context.Context, pharmacyID uuid.UUID, q string) ([]*models.ProductSerial, error) {
+	q = strings.TrimSpace(q)
+	if len([]rune(q)) < minSerialSearchLen {
+		return nil, errors.ErrValidation("search needs at least 3 characters of the serial number")
+	}
+	list, _, err := s.repo.List(ctx, pharmacyID, outbound.SerialFilter{Query: q}, 20, 0)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to search serial numbers", err)
+	}
+	return list, nil
+}
+
+func (s *serialService) AssignToItem(ctx context.Context, o *models.Order, item *models.OrderItem, numbers []string) (err error) {
+	ctx, span := tracing.Start(ctx, "SerialService.AssignToItem", attribute.Int("quantity", item.Quantity))
+	defer tracing.End(span, &err)
+	numbers, err = serialNumbers(numbers)
+	if err != nil {
+		return err
+	}
+	pharmacyID := o.PharmacyID
+	p, err := s.trackedProduct(ctx, pharmacyID, item.ProductID)
+	if err != nil {
+		return err
+	}
+	if len(numbers) != item.Quantity {
+		return errors.ErrValidation("give one serial number per unit: " + strconv.Itoa(item.Quantity) + " needed, " + strconv.Itoa(len(numbers)) + " given")
+	}
+	found, err := s.repo.FindByNumbers(ctx, pharmacyID, item.ProductID, numbers)
+	if err != nil {
+		return errors.ErrInternal("failed to check serial numbers", err)
+	}
+	available := make(map[string]bool, len(found))
+	for _, f := range found {
+		available[f.SerialNumber] = f.Status == models.SerialInStock
+	}
+	var missing []string
+	for _, n := range numbers {
+		if !available[n] {
+			missing = append(missing, n)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return errors.ErrValidation("serial numbers not in stock: " + strings.Join(missing, ", "))
+	}
+	n, err := s.repo.MarkSold(ctx, pharmacyID, item.ProductID, numbers, item.OrderID, item.ID, s.now())
+	if err != nil {
+		return errors.ErrInternal("failed to mark serial numbers sold", err)
+	}
+	if int(n) != len(numbers) {
+		return errors.ErrConflict("some of the serial numbers were just sold on another order")
+	}
+	if s.warrantySvc != nil {
+		return s.warrantySvc.RegisterSale(ctx, o, item, p, found)
+	}
+	return nil
+}
+
+func (s *serialService) AssignToOrder(ctx context.Context, pharmacyID, orderID, orderItemID uuid.UUID, numbers []string) (*models.OrderItem, error) {
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil || o == nil || o.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("order")
+	}
+	if o.Status == models.OrderStatusCancelled {
+		return nil, errors.ErrConflict("order is cancelled")
+	}
+	var item *models.OrderItem
+	for i := range o.Items {
+		if o.Items[i].ID == orderItemID {
+			item = &o.Items[i]
+		}
+	}
+	if item == nil {
+		return nil, errors.ErrNotFound("order item")
+	}
+	if len(item.Serials) > 0 {
+		return nil, errors.ErrConflict("serial numbers are already recorded for this line")
+	}
+	if err := inTx(ctx, s.uow, func(ctx context.Context) error {
+		return s.AssignToItem(ctx, o, item, numbers)
+	}); err != nil {
+		return nil, err
+	}
+	found, err := s.repo.FindByNumbers(ctx, pharmacyID, item.ProductID, numbers)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load serial numbers", err)
+	}
+	item.Serials = make([]models.ProductSerial, 0, len(found))
+	for _, f := range found {
+		item.Serials = append(item.Serials, *f)
+	}
+	return item, nil
+}
+
+func (s *serialService) ReleaseOrder(ctx context.Context, orderID uuid.UUID) error {
+	if err := s.repo.ReleaseOrder(ctx, orderID); err != nil {
+		return errors.ErrInternal("failed to release serial numbers", err)
+	}
+	if s.warrantySvc != nil {
+		return s.warrantySvc.VoidOrder(ctx, orderID)
+	}
+	return nil
+}
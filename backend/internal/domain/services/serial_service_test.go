@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// serialFixture keeps serials in memory, keyed by serial number (one product per fixture).
+type serialFixture struct {
+	pharmacyID uuid.UUID
+	product    *models.Product
+	serials    map[string]*models.ProductSerial
+	svc        inbound.SerialService
+}
+
+func newSerialFixture(stock int) *serialFixture {
+	f := &serialFixture{pharmacyID: uuid.New(), serials: map[string]*models.ProductSerial{}}
+	f.product = &models.Product{ID: uuid.New(), PharmacyID: f.pharmacyID, Name: "Glucometer", StockQuantity: stock, TracksSerials: true}
+	productRepo := &mocks.MockProductRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+			if id != f.product.ID {
+				return nil, nil
+			}
+			return f.product, nil
+		},
+	}
+	repo := &mocks.MockSerialRepository{
+		CreateBatchFunc: func(ctx context.Context, serials []*models.ProductSerial) error {
+			for _, s := range serials {
+				f.serials[s.SerialNumber] = s
+			}
+			return nil
+		},
+		FindByNumbersFunc: func(ctx context.Context, pharmacyID, productID uuid.UUID, numbers []string) ([]*models.ProductSerial, error) {
+			var out []*models.ProductSerial
+			for _, n := range numbers {
+				if s := f.serials[n]; s != nil {
+					out = append(out, s)
+				}
+			}
+			return out, nil
+		},
+		CountInStockFunc: func(ctx context.Context, pharmacyID, productID uuid.UUID) (int64, error) {
+			var n int64
+			for _, s := range f.serials {
+				if s.Status == models.SerialInStock {
+					n++
+				}
+			}
+			return n, nil
+		},
+		MarkSoldFunc: func(ctx context.Context, pharmacyID, productID uuid.UUID, numbers []string, orderID, orderItemID uuid.UUID, at time.Time) (int64, error) {
+			var n int64
+			for _, num := range numbers {
+				if s := f.serials[num]; s != nil && s.Status == models.SerialInStock {
+					s.Status, s.OrderID, s.OrderItemID, s.SoldAt = models.SerialSold, &orderID, &orderItemID, &at
+					n++
+				}
+			}
+			return n, nil
+		},
+	}
+	f.svc = NewSerialService(repo, productRepo, nil, &mocks.MockOrderRepository{}, nil, nil, zap.NewNop())
+	return f
+}
+
+func TestSerialService_Receive_RejectsDuplicatesAndMoreSerialsThanStock(t *testing.T) {
+	f := newSerialFixture(3)
+	ctx := context.Background()
+	in := inbound.SerialReceiveInput{ProductID: f.product.ID, SerialNumbers: []string{" GM-001 ", "GM-002"}}
+	list, err := f.svc.Receive(ctx, f.pharmacyID, uuid.New(), in)
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	if len(list) != 2 || list[0].SerialNumber != "GM-001" || list[0].Status != models.SerialInStock {
+		t.Errorf("serials = %+v, want GM-001 and GM-002 in stock", list)
+	}
+
+	_, err = f.svc.Receive(ctx, f.pharmacyID, uuid.New(), inbound.SerialReceiveInput{ProductID: f.product.ID, SerialNumbers: []string{"GM-002"}})
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeConflict {
+		t.Errorf("err = %v, want conflict for an already recorded serial", err)
+	}
+	_, err = f.svc.Receive(ctx, f.pharmacyID, uuid.New(), inbound.SerialReceiveInput{ProductID: f.product.ID, SerialNumbers: []string{"GM-003", "GM-004"}})
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("err = %v, want validation error for serials beyond stock", err)
+	}
+	_, err = f.svc.Receive(ctx, f.pharmacyID, uuid.New(), inbound.SerialReceiveInput{ProductID: f.product.ID, SerialNumbers: []string{"GM-005", "GM-005"}})
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("err = %v, want validation error for a repeated serial", err)
+	}
+
+	f.product.TracksSerials = false
+	_, err = f.svc.Receive(ctx, f.pharmacyID, uuid.New(), inbound.SerialReceiveInput{ProductID: f.product.ID, SerialNumbers: []string{"GM-006"}})
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("err = %v, want validation error for a product without serial tracking", err)
+	}
+}
+
+func TestSerialService_AssignToItem_NeedsOneInStockSerialPerUnit(t *testing.T) {
+	f := newSerialFixture(3)
+	ctx := context.Background()
+	if _, err := f.svc.Receive(ctx, f.pharmacyID, uuid.New(), inbound.SerialReceiveInput{ProductID: f.product.ID, SerialNumbers: []string{"A1", "A2", "A3"}}); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	order := &models.Order{ID: uuid.New(), PharmacyID: f.pharmacyID}
+	item := &models.OrderItem{ID: uuid.New(), OrderID: order.ID, ProductID: f.product.ID, Quantity: 2}
+
+	if err := f.svc.AssignToItem(ctx, order, item, []string{"A1"}); err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("err = %v, want validation error for too few serials", err)
+	}
+	if err := f.svc.AssignToItem(ctx, order, item, []string{"A1", "B9"}); err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("err = %v, want validation error for an unknown serial", err)
+	}
+	if f.serials["A1"].Status != models.SerialInStock {
+		t.Fatalf("A1 status = %s after a rejected assignment, want in_stock", f.serials["A1"].Status)
+	}
+	if err := f.svc.AssignToItem(ctx, order, item, []string{"A1", "A2"}); err != nil {
+		t.Fatalf("AssignToItem: %v", err)
+	}
+	if s := f.serials["A2"]; s.Status != models.SerialSold || s.OrderItemID == nil || *s.OrderItemID != item.ID {
+		t.Errorf("A2 = %+v, want sold on the item", s)
+	}
+
+	other := &models.OrderItem{ID: uuid.New(), OrderID: uuid.New(), ProductID: f.product.ID, Quantity: 1}
+	if err := f.svc.AssignToItem(ctx, order, other, []string{"A2"}); err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("err = %v, want validation error for a serial already sold", err)
+	}
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type shiftSwapService struct {
+	swapRepo            outbound.ShiftSwapRepository
+	rosterRepo          outbound.DutyRosterRepository
+	userRepo            outbound.UserRepository
+	notificationService inbound.NotificationService
+	now                 func() time.Time
+	logger              *zap.Logger
+}
+
+// NewShiftSwapService creates the shift swap service. notificationService may be nil.
+func NewShiftSwapService(swapRepo outbound.ShiftSwapRepository, rosterRepo outbound.DutyRosterRepository, userRepo outbound.UserRepository, notificationService inbound.NotificationService, logger *zap.Logger) inbound.ShiftSwapService {
+	return &shiftSwapService{swapRepo: swapRepo, rosterRepo: rosterRepo, userRepo: userRepo, notificationService: notificationService, now: time.Now, logger: logger}
+}
+
+func (s *shiftSwapService) today() time.Time {
+	now := s.now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+func (s *shiftSwapService) Offer(ctx context.Context, pharmacyID, userID, rosterID uuid.UUID, note string) (*models.ShiftSwap, error) {
+	d, err := s.rosterRepo.GetByID(ctx, rosterID)
+	// Only the assignee can offer their own published shift; anything else looks missing.
+	if err != nil || d == nil || d.PharmacyID != pharmacyID || d.UserID != userID || d.Status != models.RosterPublished {
+		return nil, errors.ErrNotFound("duty roster")
+	}
+	if d.Date.Before(s.today()) {
+		return nil, errors.ErrValidation("past shifts cannot be swapped")
+	}
+	open, err := s.swapRepo.GetOpenByRoster(ctx, rosterID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to check shift swaps", err)
+	}
+	if open != nil {
+		return nil, errors.ErrConflict("this shift is already offered for swap")
+	}
+	sw := &models.ShiftSwap{PharmacyID: pharmacyID, RosterID: rosterID, OfferedBy: userID, Note: note, Status: models.ShiftSwapOpen}
+	if err := s.swapRepo.Create(ctx, sw, &models.ShiftSwapEvent{Action: models.ShiftSwapEventOffered, ActorID: userID, Note: note}); err != nil {
+		return nil, errors.ErrInternal("failed to create shift swap", err)
+	}
+	offerer, _ := s.userRepo.GetByID(ctx, userID)
+	if offerer != nil {
+		colleagues, err := s.userRepo.GetByPharmacyID(ctx, pharmacyID)
+		if err != nil {
+			s.logger.Warn("failed to load colleagues for shift swap", zap.String("swap_id", sw.ID.String()), zap.Error(err))
+		}
+		msg := fmt.Sprintf("%s offered their %s. Request it from the shift swap board.", offerer.Name, describeShift(d))
+		for _, u := range colleagues {
+			if u.ID != userID && u.IsActive && u.Role == offerer.Role {
+				s.notify(ctx, pharmacyID, u.ID, "Shift up for swap", msg)
+			}
+		}
+	}
+	return s.swapRepo.GetByID(ctx, sw.ID)
+}
+
+func (s *shiftSwapService) Get(ctx context.Context, pharmacyID, id uuid.UUID) (*models.ShiftSwap, error) {
+	sw, err := s.swapRepo.GetByID(ctx, id)
+	if err != nil || sw == nil || sw.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("shift swap")
+	}
+	return sw, nil
+}
+
+func (s *shiftSwapService) List(ctx context.Context, pharmacyID uuid.UUID, status models.ShiftSwapStatus) ([]*models.ShiftSwap, error) {
+	list, err := s.swapRepo.List(ctx, pharmacyID, status)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list shift swaps", err)
+	}
+	if list == nil {
+		list = []*models.ShiftSwap{}
+	}
+	return list, nil
+}
+
+// openSwap loads an open swap of the pharmacy together with its current roster entry.
+func (s *shiftSwapService) openSwap(ctx context.Context, pharmacyID, id uuid.UUID) (*models.ShiftSwap, *models.DutyRoster, error) {
+	sw, err := s.Get(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sw.Status != models.ShiftSwapOpen {
+		return nil, nil, errors.ErrConflict(fmt.Sprintf("shift swap is %s", sw.Status))
+	}
+	d, err := s.rosterRepo.GetByID(ctx, sw.RosterID)
+	if err != nil || d == nil {
+		return nil, nil, errors.ErrConflict("the offered shift no longer exists")
+	}
+	return sw, d, nil
+}
+
+// checkTaker verifies that user can take the offered shift: an active colleague with the offerer's role and no
+// other published shift that day.
+func (s *shiftSwapService) checkTaker(ctx context.Context, sw *models.ShiftSwap, d *models.DutyRoster, userID uuid.UUID) (*models.User, error) {
+	if userID == sw.OfferedBy {
+		return nil, errors.ErrValidation("you cannot request your own shift")
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil || user.PharmacyID != sw.PharmacyID || !user.IsActive {
+		return nil, errors.ErrForbidden("user cannot take this shift")
+	}
+	offerer, err := s.userRepo.GetByID(ctx, sw.OfferedBy)
+	if err != nil || offerer == nil {
+		return nil, errors.ErrForbidden("user cannot take this shift")
+	}
+	if offerer.Role != user.Role {
+		return nil, errors.ErrForbidden(fmt.Sprintf("only %s staff can take this shift", offerer.Role))
+	}
+	busy, err := s.rosterRepo.ListByUserAndDateRange(ctx, sw.PharmacyID, userID, models.RosterPublished, d.Date, d.Date)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to check roster", err)
+	}
+	if len(busy) > 0 {
+		return nil, errors.ErrConflict(fmt.Sprintf("%s already has a shift on %s", user.Name, d.Date.Format(rosterDateLayout)))
+	}
+	return user, nil
+}
+
+func (s *shiftSwapService) Request(ctx context.Context, pharmacyID, userID, swapID uuid.UUID, note string) (*models.ShiftSwap, error) {
+	sw, d, err := s.openSwap(ctx, pharmacyID, swapID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range sw.Requests {
+		if r.UserID == userID && r.Status == models.ShiftSwapRequestPending {
+			return nil, errors.ErrConflict("you already requested this shift")
+		}
+	}
+	user, err := s.checkTaker(ctx, sw, d, userID)
+	if err != nil {
+		return nil, err
+	}
+	req := &models.ShiftSwapRequest{ID: uuid.New(), SwapID: sw.ID, UserID: userID, Note: note, Status: models.ShiftSwapRequestPending}
+	sw.Requests = append(sw.Requests, req)
+	if err := s.swapRepo.Save(ctx, sw, nil, &models.ShiftSwapEvent{Action: models.ShiftSwapEventRequested, ActorID: userID, RequestID: &req.ID, Note: note}); err != nil {
+		return nil, errors.ErrInternal("failed to request shift swap", err)
+	}
+	s.notify(ctx, pharmacyID, sw.OfferedBy, "Shift swap requested", fmt.Sprintf("%s wants to take your %s. A manager will decide.", user.Name, describeShift(d)))
+	s.notifyManagers(ctx, pharmacyID, "Shift swap awaiting approval", fmt.Sprintf("%s asked to take the %s.", user.Name, describeShift(d)))
+	return s.swapRepo.GetByID(ctx, sw.ID)
+}
+
+func (s *shiftSwapService) Withdraw(ctx context.Context, pharmacyID, userID, swapID uuid.UUID) (*models.ShiftSwap, error) {
+	sw, _, err := s.openSwap(ctx, pharmacyID, swapID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range sw.Requests {
+		if r.UserID == userID && r.Status == models.ShiftSwapRequestPending {
+			r.Status = models.ShiftSwapRequestWithdrawn
+			if err := s.swapRepo.Save(ctx, sw, nil, &models.ShiftSwapEvent{Action: models.ShiftSwapEventWithdrawn, ActorID: userID, RequestID: &r.ID}); err != nil {
+				return nil, errors.ErrInternal("failed to withdraw shift swap request", err)
+			}
+			return s.swapRepo.GetByID(ctx, sw.ID)
+		}
+	}
+	return nil, errors.ErrNotFound("shift swap request")
+}
+
+func (s *shiftSwapService) Cancel(ctx context.Context, pharmacyID, userID, swapID uuid.UUID) (*models.ShiftSwap, error) {
+	sw, d, err := s.openSwap(ctx, pharmacyID, swapID)
+	if err != nil {
+		return nil, err
+	}
+	if sw.OfferedBy != userID {
+		return nil, errors.ErrForbidden("only the offerer can cancel a shift swap")
+	}
+	return s.close(ctx, sw, d, models.ShiftSwapCancelled, userID, "")
+}
+
+func (s *shiftSwapService) Reject(ctx context.Context, pharmacyID, managerID, swapID uuid.UUID, note string) (*models.ShiftSwap, error) {
+	sw, d, err := s.openSwap(ctx, pharmacyID, swapID)
+	if err != nil {
+		return nil, err
+	}
+	out, err := s.close(ctx, sw, d, models.ShiftSwapRejected, managerID, note)
+	if err != nil {
+		return nil, err
+	}
+	msg := fmt.Sprintf("Your %s stays with you; the swap was rejected.", describeShift(d))
+	if note != "" {
+		msg += " " + note
+	}
+	s.notify(ctx, pharmacyID, sw.OfferedBy, "Shift swap rejected", msg)
+	return out, nil
+}
+
+// close ends an open swap without moving the shift and tells pending requesters.
+func (s *shiftSwapService) close(ctx context.Context, sw *models.ShiftSwap, d *models.DutyRoster, status models.ShiftSwapStatus, actorID uuid.UUID, note string) (*models.ShiftSwap, error) {
+	now := s.now()
+	var pending []uuid.UUID
+	for _, r := range sw.Requests {
+		if r.Status == models.ShiftSwapRequestPending {
+			r.Status = models.ShiftSwapRequestDeclined
+			pending = append(pending, r.UserID)
+		}
+	}
+	sw.Status = status
+	sw.DecidedBy = &actorID
+	sw.DecidedAt = &now
+	sw.DecisionNote = note
+	action := models.ShiftSwapEventRejected
+	if status == models.ShiftSwapCancelled {
+		action = models.ShiftSwapEventCancelled
+	}
+	if err := s.swapRepo.Save(ctx, sw, nil, &models.ShiftSwapEvent{Action: action, ActorID: actorID, Note: note}); err != nil {
+		return nil, errors.ErrInternal("failed to close shift swap", err)
+	}
+	for _, userID := range pending {
+		s.notify(ctx, sw.PharmacyID, userID, "Shift swap closed", fmt.Sprintf("The %s is no longer up for swap.", describeShift(d)))
+	}
+	return s.swapRepo.GetByID(ctx, sw.ID)
+}
+
+func (s *shiftSwapService) Approve(ctx context.Context, pharmacyID, managerID, swapID, requestID uuid.UUID, note string) (*models.ShiftSwap, error) {
+	sw, d, err := s.openSwap(ctx, pharmacyID, swapID)
+	if err != nil {
+		return nil, err
+	}
+	var chosen *models.ShiftSwapRequest
+	for _, r := range sw.Requests {
+		if r.ID == requestID && r.Status == models.ShiftSwapRequestPending {
+			chosen = r
+		}
+	}
+	if chosen == nil {
+		return nil, errors.ErrNotFound("shift swap request")
+	}
+	// The manager may have edited or reassigned the entry since it was offered.
+	if d.UserID != sw.OfferedBy || d.Status != models.RosterPublished {
+		return nil, errors.ErrConflict("the offered shift changed since it was offered; cancel the swap and offer it again")
+	}
+	if d.Date.Before(s.today()) {
+		return nil, errors.ErrValidation("past shifts cannot be swapped")
+	}
+	taker, err := s.checkTaker(ctx, sw, d, chosen.UserID)
+	if err != nil {
+		return nil, err
+	}
+	offerer, _ := s.userRepo.GetByID(ctx, sw.OfferedBy)
+	offererName := "a colleague"
+	if offerer != nil {
+		offererName = offerer.Name
+	}
+
+	now := s.now()
+	var declined []uuid.UUID
+	for _, r := range sw.Requests {
+		if r == chosen {
+			r.Status = models.ShiftSwapRequestApproved
+		} else if r.Status == models.ShiftSwapRequestPending {
+			r.Status = models.ShiftSwapRequestDeclined
+			declined = append(declined, r.UserID)
+		}
+	}
+	sw.Status = models.ShiftSwapApproved
+	sw.ApprovedRequestID = &chosen.ID
+	sw.DecidedBy = &managerID
+	sw.DecidedAt = &now
+	sw.DecisionNote = note
+	d.UserID = taker.ID
+	d.User = nil
+	d.Revision++
+	d.PublishedAt = &now
+	d.AcknowledgedAt = nil
+	d.ChangeNote = fmt.Sprintf("You took over the %s from %s in a shift swap.", describeShift(d), offererName)
+	if err := s.swapRepo.Save(ctx, sw, d, &models.ShiftSwapEvent{Action: models.ShiftSwapEventApproved, ActorID: managerID, RequestID: &chosen.ID, Note: note}); err != nil {
+		return nil, errors.ErrInternal("failed to approve shift swap", err)
+	}
+
+	s.notify(ctx, pharmacyID, taker.ID, "Shift swap approved", d.ChangeNote+" Please acknowledge it in your roster.")
+	s.notify(ctx, pharmacyID, sw.OfferedBy, "Shift swap approved", fmt.Sprintf("Your %s is now covered by %s.", describeShift(d), taker.Name))
+	for _, userID := range declined {
+		s.notify(ctx, pharmacyID, userID, "Shift swap closed", fmt.Sprintf("The %s went to another colleague.", describeShift(d)))
+	}
+	s.logger.Info("shift swap approved", zap.String("swap_id", sw.ID.String()), zap.String("roster_id", d.ID.String()), zap.String("to_user", taker.ID.String()))
+	return s.swapRepo.GetByID(ctx, sw.ID)
+}
+
+// notify sends an in-app (and push) roster notification. Failures are logged, never returned.
+func (s *shiftSwapService) notify(ctx context.Context, pharmacyID, userID uuid.UUID, title, message string) {
+	if s.notificationService == nil {
+		return
+	}
+	if _, err := s.notificationService.Create(ctx, pharmacyID, userID, title, message, "roster"); err != nil {
+		s.logger.Warn("failed to send shift swap notification", zap.String("user_id", userID.String()), zap.Error(err))
+	}
+}
+
+func (s *shiftSwapService) notifyManagers(ctx context.Context, pharmacyID uuid.UUID, title, message string) {
+	users, err := s.userRepo.GetByPharmacyID(ctx, pharmacyID)
+	if err != nil {
+		s.logger.Warn("failed to load managers for shift swap", zap.String("pharmacy_id", pharmacyID.String()), zap.Error(err))
+		return
+	}
+	for _, u := range users {
+		if u.IsActive && (u.Role == models.RoleAdmin || u.Role == models.RoleManager) {
+			s.notify(ctx, pharmacyID, u.ID, title, message)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type swapFixture struct {
+	pharmacyID uuid.UUID
+	users      map[uuid.UUID]*models.User
+	ram, sita  *models.User
+	hari       *models.User // pharmacist, already rostered on the offered day
+	cashier    *models.User
+	manager    *models.User
+	roster     map[uuid.UUID]*models.DutyRoster
+	swaps      map[uuid.UUID]*models.ShiftSwap
+	events     []*models.ShiftSwapEvent
+	notifier   *recordingNotifier
+	svc        *shiftSwapService
+}
+
+func newSwapFixture() *swapFixture {
+	f := &swapFixture{
+		pharmacyID: uuid.New(),
+		users:      make(map[uuid.UUID]*models.User),
+		roster:     make(map[uuid.UUID]*models.DutyRoster),
+		swaps:      make(map[uuid.UUID]*models.ShiftSwap),
+		notifier:   &recordingNotifier{},
+	}
+	add := func(name, role string) *models.User {
+		u := &models.User{ID: uuid.New(), PharmacyID: f.pharmacyID, Name: name, Role: role, IsActive: true}
+		f.users[u.ID] = u
+		return u
+	}
+	f.ram = add("Ram", models.RolePharmacist)
+	f.sita = add("Sita", models.RolePharmacist)
+	f.hari = add("Hari", models.RolePharmacist)
+	f.cashier = add("Gita", models.RoleStaff)
+	f.manager = add("Maya", models.RoleManager)
+
+	swapRepo := &mocks.MockShiftSwapRepository{
+		CreateFunc: func(ctx context.Context, s *models.ShiftSwap, e *models.ShiftSwapEvent) error {
+			s.ID = uuid.New()
+			f.swaps[s.ID] = s
+			f.events = append(f.events, e)
+			return nil
+		},
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.ShiftSwap, error) {
+			return f.swaps[id], nil
+		},
+		GetOpenByRosterFunc: func(ctx context.Context, rosterID uuid.UUID) (*models.ShiftSwap, error) {
+			for _, s := range f.swaps {
+				if s.RosterID == rosterID && s.Status == models.ShiftSwapOpen {
+					return s, nil
+				}
+			}
+			return nil, nil
+		},
+		SaveFunc: func(ctx context.Context, s *models.ShiftSwap, roster *models.DutyRoster, e *models.ShiftSwapEvent) error {
+			if roster != nil {
+				f.roster[roster.ID] = roster
+			}
+			f.events = append(f.events, e)
+			return nil
+		},
+	}
+	rosterRepo := &mocks.MockDutyRosterRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.DutyRoster, error) {
+			return f.roster[id], nil
+		},
+		ListByUserAndDateRangeFunc: func(ctx context.Context, pharmacyID, userID uuid.UUID, status models.RosterStatus, from, to time.Time) ([]*models.DutyRoster, error) {
+			var out []*models.DutyRoster
+			for _, d := range f.roster {
+				if d.UserID == userID && d.Status == status && !d.Date.Before(from) && !d.Date.After(to) {
+					out = append(out, d)
+				}
+			}
+			return out, nil
+		},
+	}
+	userRepo := &mocks.MockUserRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.User, error) {
+			return f.users[id], nil
+		},
+		GetByPharmacyIDFunc: func(ctx context.Context, pharmacyID uuid.UUID) ([]*models.User, error) {
+			var out []*models.User
+			for _, u := range f.users {
+				out = append(out, u)
+			}
+			return out, nil
+		},
+	}
+	f.svc = NewShiftSwapService(swapRepo, rosterRepo, userRepo, f.notifier, zap.NewNop()).(*shiftSwapService)
+	f.svc.now = func() time.Time { return time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC) }
+	return f
+}
+
+func (f *swapFixture) shift(u *models.User, day int) *models.DutyRoster {
+	d := &models.DutyRoster{
+		ID: uuid.New(), PharmacyID: f.pharmacyID, UserID: u.ID, Date: time.Date(2026, 10, day, 0, 0, 0, 0, time.UTC),
+		ShiftType: models.ShiftMorning, Status: models.RosterPublished, Revision: 1,
+	}
+	f.roster[d.ID] = d
+	return d
+}
+
+func (f *swapFixture) sentTo(u *models.User) []*models.Notification {
+	var out []*models.Notification
+	for _, n := range f.notifier.sent {
+		if n.UserID == u.ID {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+func TestShiftSwapService_Approve_MovesShiftAndNotifies(t *testing.T) {
+	f := newSwapFixture()
+	ctx := context.Background()
+	d := f.shift(f.ram, 20)
+	now := time.Now()
+	d.AcknowledgedAt = &now
+
+	sw, err := f.svc.Offer(ctx, f.pharmacyID, f.ram.ID, d.ID, "family event")
+	if err != nil {
+		t.Fatalf("Offer: %v", err)
+	}
+	if len(f.sentTo(f.sita)) != 1 || len(f.sentTo(f.cashier)) != 0 || len(f.sentTo(f.ram)) != 0 {
+		t.Errorf("expected only same-role colleagues to hear about the offer, got %+v", f.notifier.sent)
+	}
+	if _, err := f.svc.Offer(ctx, f.pharmacyID, f.ram.ID, d.ID, ""); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeConflict {
+		t.Errorf("expected conflict offering the same shift twice, got %v", err)
+	}
+
+	if _, err := f.svc.Request(ctx, f.pharmacyID, f.sita.ID, sw.ID, "happy to"); err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	f.shift(f.hari, 21)
+	if _, err := f.svc.Request(ctx, f.pharmacyID, f.hari.ID, sw.ID, ""); err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if len(f.sentTo(f.manager)) != 2 || len(f.sentTo(f.ram)) != 2 {
+		t.Errorf("expected the offerer and managers to hear about each request")
+	}
+
+	f.notifier.sent = nil
+	sitaReq := sw.Requests[0]
+	if _, err := f.svc.Approve(ctx, f.pharmacyID, f.manager.ID, sw.ID, sitaReq.ID, ""); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if d.UserID != f.sita.ID || d.Revision != 2 || d.AcknowledgedAt != nil || !strings.Contains(d.ChangeNote, "from Ram in a shift swap") {
+		t.Errorf("expected the shift to move to Sita as a new unacknowledged revision, got %+v", d)
+	}
+	if sw.Status != models.ShiftSwapApproved || *sw.ApprovedRequestID != sitaReq.ID || sw.Requests[1].Status != models.ShiftSwapRequestDeclined {
+		t.Errorf("unexpected swap state %+v", sw)
+	}
+	if len(f.sentTo(f.sita)) != 1 || len(f.sentTo(f.ram)) != 1 || len(f.sentTo(f.hari)) != 1 {
+		t.Errorf("expected taker, offerer and declined requester to be notified, got %+v", f.notifier.sent)
+	}
+	var actions []string
+	for _, e := range f.events {
+		actions = append(actions, e.Action)
+	}
+	if strings.Join(actions, ",") != "offered,requested,requested,approved" {
+		t.Errorf("unexpected audit trail %v", actions)
+	}
+}
+
+func TestShiftSwapService_Request_RequiresCompatibleFreeColleague(t *testing.T) {
+	f := newSwapFixture()
+	ctx := context.Background()
+	d := f.shift(f.ram, 20)
+	f.shift(f.hari, 20)
+	sw, _ := f.svc.Offer(ctx, f.pharmacyID, f.ram.ID, d.ID, "")
+
+	cases := []struct {
+		name string
+		user *models.User
+		code string
+	}{
+		{"own shift", f.ram, pkgerrors.ErrCodeValidation},
+		{"different role", f.cashier, pkgerrors.ErrCodeForbidden},
+		{"already working that day", f.hari, pkgerrors.ErrCodeConflict},
+	}
+	for _, tc := range cases {
+		_, err := f.svc.Request(ctx, f.pharmacyID, tc.user.ID, sw.ID, "")
+		if pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != tc.code {
+			t.Errorf("%s: expected %s, got %v", tc.name, tc.code, err)
+		}
+	}
+	if len(sw.Requests) != 0 {
+		t.Errorf("rejected requests must not be stored")
+	}
+}
+
+func TestShiftSwapService_Approve_FailsWhenShiftChanged(t *testing.T) {
+	f := newSwapFixture()
+	ctx := context.Background()
+	d := f.shift(f.ram, 20)
+	sw, _ := f.svc.Offer(ctx, f.pharmacyID, f.ram.ID, d.ID, "")
+	_, _ = f.svc.Request(ctx, f.pharmacyID, f.sita.ID, sw.ID, "")
+
+	// A manager reassigned the entry directly in the meantime.
+	d.UserID = f.hari.ID
+	_, err := f.svc.Approve(ctx, f.pharmacyID, f.manager.ID, sw.ID, sw.Requests[0].ID, "")
+	if pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeConflict {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+	if sw.Status != models.ShiftSwapOpen || d.UserID != f.hari.ID {
+		t.Errorf("a failed approval must not change anything")
+	}
+}
+
+func TestShiftSwapService_Cancel_OnlyOffererAndDeclinesRequests(t *testing.T) {
+	f := newSwapFixture()
+	ctx := context.Background()
+	d := f.shift(f.ram, 20)
+	sw, _ := f.svc.Offer(ctx, f.pharmacyID, f.ram.ID, d.ID, "")
+	_, _ = f.svc.Request(ctx, f.pharmacyID, f.sita.ID, sw.ID, "")
+
+	if _, err := f.svc.Cancel(ctx, f.pharmacyID, f.sita.ID, sw.ID); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeForbidden {
+		t.Errorf("expected forbidden for a non-offerer, got %v", err)
+	}
+	f.notifier.sent = nil
+	if _, err := f.svc.Cancel(ctx, f.pharmacyID, f.ram.ID, sw.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if sw.Status != models.ShiftSwapCancelled || sw.Requests[0].Status != models.ShiftSwapRequestDeclined || d.UserID != f.ram.ID {
+		t.Errorf("unexpected state after cancel: swap=%s request=%s", sw.Status, sw.Requests[0].Status)
+	}
+	if len(f.sentTo(f.sita)) != 1 {
+		t.Errorf("expected the pending requester to be told")
+	}
+	if _, err := f.svc.Request(ctx, f.pharmacyID, f.hari.ID, sw.ID, ""); pkgerrors.GetAppError(err) == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeConflict {
+		t.Errorf("expected conflict requesting a closed swap, got %v", err)
+	}
+}
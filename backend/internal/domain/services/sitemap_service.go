@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// sitemapMaxAge is how old a sitemap may get before the refresh job rebuilds it even if nothing changed.
+const sitemapMaxAge = 24 * time.Hour
+
+// sitemapStaticPages are the storefront pages listed in every sitemap.
+var sitemapStaticPages = []sitemapURL{
+	{Loc: "/", ChangeFreq: "daily", Priority: "1.0"},
+	{Loc: "/products", ChangeFreq: "daily", Priority: "0.9"},
+	{Loc: "/blog", ChangeFreq: "daily", Priority: "0.7"},
+	{Loc: "/terms", ChangeFreq: "yearly", Priority: "0.3"},
+	{Loc: "/privacy", ChangeFreq: "yearly", Priority: "0.3"},
+	{Loc: "/return-refund", ChangeFreq: "yearly", Priority: "0.3"},
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+type sitemapService struct {
+	repo         outbound.SitemapRepository
+	pharmacyRepo outbound.PharmacyRepository
+	configRepo   outbound.PharmacyConfigRepository
+	publicURL    string
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+// NewSitemapService builds the sitemap service. publicURL is the web app base used when a pharmacy has no
+// site_hostname configured.
+func NewSitemapService(repo outbound.SitemapRepository, pharmacyRepo outbound.PharmacyRepository, configRepo outbound.PharmacyConfigRepository, publicURL string, logger *zap.Logger) inbound.SitemapService {
+	return &sitemapService{
+		repo:         repo,
+		pharmacyRepo: pharmacyRepo,
+		configRepo:   configRepo,
+		publicURL:    strings.TrimSuffix(publicURL, "/"),
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+func (s *sitemapService) Get(ctx context.Context, pharmacyID uuid.UUID) (*models.StorefrontSitemap, error) {
+	baseURL, err := s.baseURL(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.repo.Get(ctx, pharmacyID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load sitemap", err)
+	}
+	if stored == nil {
+		return s.build(ctx, pharmacyID, baseURL)
+	}
+	if fresh, err := s.isFresh(ctx, stored, baseURL); err == nil && fresh {
+		return stored, nil
+	}
+	sm, err := s.build(ctx, pharmacyID, baseURL)
+	if err != nil {
+		// A stale sitemap is better for crawlers than none; the refresh job tries again.
+		s.logger.Warn("sitemap rebuild failed, serving the stored one", zap.String("pharmacy_id", pharmacyID.String()), zap.Error(err))
+		return stored, nil
+	}
+	return sm, nil
+}
+
+func (s *sitemapService) Regenerate(ctx context.Context, pharmacyID uuid.UUID) (*models.StorefrontSitemap, error) {
+	baseURL, err := s.baseURL(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	return s.build(ctx, pharmacyID, baseURL)
+}
+
+func (s *sitemapService) RefreshAll(ctx context.Context) error {
+	pharmacies, err := s.pharmacyRepo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("list pharmacies: %w", err)
+	}
+	failed, rebuilt := 0, 0
+	for _, p := range pharmacies {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !p.IsActive {
+			continue
+		}
+		baseURL, err := s.baseURL(ctx, p.ID)
+		if err != nil {
+			continue // website disabled
+		}
+		stored, err := s.repo.Get(ctx, p.ID)
+		if err == nil && stored != nil {
+			if fresh, err := s.isFresh(ctx, stored, baseURL); err == nil && fresh {
+				continue
+			}
+		}
+		if _, err := s.build(ctx, p.ID, baseURL); err != nil {
+			failed++
+			s.logger.Warn("sitemap refresh failed", zap.String("pharmacy_id", p.ID.String()), zap.Error(err))
+			continue
+		}
+		rebuilt++
+	}
+	if rebuilt > 0 {
+		s.logger.Info("sitemaps rebuilt", zap.Int("count", rebuilt))
+	}
+	if failed > 0 {
+		return fmt.Errorf("sitemap refresh failed for %d of %d pharmacies", failed, len(pharmacies))
+	}
+	return nil
+}
+
+// isFresh reports whether the stored sitemap was built for baseURL from the current content within sitemapMaxAge.
+func (s *sitemapService) isFresh(ctx context.Context, stored *models.StorefrontSitemap, baseURL string) (bool, error) {
+	if stored.BaseURL != baseURL || s.now().Sub(stored.GeneratedAt) >= sitemapMaxAge {
+		return false, nil
+	}
+	fp, err := s.repo.Fingerprint(ctx, stored.PharmacyID)
+	if err != nil {
+		return false, err
+	}
+	return fp == stored.Fingerprint, nil
+}
+
+// baseURL returns the storefront origin links are built on: https://<site_hostname>, or the app's public URL.
+// Pharmacies that are inactive or have the website disabled get not found.
+func (s *sitemapService) baseURL(ctx context.Context, pharmacyID uuid.UUID) (string, error) {
+	pharmacy, err := s.pharmacyRepo.GetByID(ctx, pharmacyID)
+	if err != nil || pharmacy == nil || !pharmacy.IsActive {
+		return "", errors.ErrNotFound("sitemap")
+	}
+	cfg, err := s.configRepo.GetByPharmacyID(ctx, pharmacyID)
+	if err != nil {
+		cfg = nil // no config yet: defaults apply
+	}
+	if cfg != nil && !cfg.WebsiteEnabled {
+		return "", errors.ErrNotFound("sitemap")
+	}
+	if cfg != nil && cfg.SiteHostname != "" {
+		return "https://" + cfg.SiteHostname, nil
+	}
+	return s.publicURL, nil
+}
+
+// build renders and stores the sitemap. The fingerprint is read before the content, so a change made while
+// building is picked up by the next refresh.
+func (s *sitemapService) build(ctx context.Context, pharmacyID uuid.UUID, baseURL string) (*models.StorefrontSitemap, error) {
+	fp, err := s.repo.Fingerprint(ctx, pharmacyID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to read sitemap content", err)
+	}
+	sources, err := s.repo.Sources(ctx, pharmacyID, models.MaxSitemapURLs-len(sitemapStaticPages))
+	if err != nil {
+		return nil, errors.ErrInternal("failed to read sitemap content", err)
+	}
+	set := sitemapURLSet{URLs: make([]sitemapURL, 0, len(sitemapStaticPages)+len(sources))}
+	for _, u := range sitemapStaticPages {
+		u.Loc = baseURL + u.Loc
+		set.URLs = append(set.URLs, u)
+	}
+	for _, src := range sources {
+		u := sitemapURL{LastMod: src.UpdatedAt.UTC().Format("2006-01-02")}
+		switch src.Kind {
+		case models.SitemapKindProduct:
+			u.Loc, u.ChangeFreq, u.Priority = baseURL+"/products/"+src.Key, "weekly", "0.8"
+		case models.SitemapKindCategory:
+			u.Loc, u.ChangeFreq, u.Priority = baseURL+"/products?category="+src.Key, "weekly", "0.6"
+		case models.SitemapKindBlogPost:
+			u.Loc, u.ChangeFreq, u.Priority = baseURL+"/blog/"+src.Key, "monthly", "0.6"
+		default:
+			continue
+		}
+		set.URLs = append(set.URLs, u)
+	}
+	out, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return nil, errors.ErrInternal("failed to render sitemap", err)
+	}
+	sm := &models.StorefrontSitemap{
+		PharmacyID:  pharmacyID,
+		XML:         xml.Header + string(out),
+		URLCount:    len(set.URLs),
+		BaseURL:     baseURL,
+		Fingerprint: fp,
+		GeneratedAt: s.now(),
+	}
+	if err := s.repo.Save(ctx, sm); err != nil {
+		return nil, errors.ErrInternal("failed to save sitemap", err)
+	}
+	return sm, nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestSitemapService_BuildsOnHostnameAndRefreshesOnChange(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	live := &models.Pharmacy{ID: uuid.New(), IsActive: true}
+	closed := &models.Pharmacy{ID: uuid.New(), IsActive: true}
+	productID := uuid.New()
+	configs := map[uuid.UUID]*models.PharmacyConfig{
+		live.ID:   {PharmacyID: live.ID, WebsiteEnabled: true, SiteHostname: "shop.example.com"},
+		closed.ID: {PharmacyID: closed.ID, WebsiteEnabled: false},
+	}
+	stored := map[uuid.UUID]*models.StorefrontSitemap{}
+	fingerprint, builds := "v1", 0
+	repo := &mocks.MockSitemapRepository{
+		GetFunc: func(ctx context.Context, pharmacyID uuid.UUID) (*models.StorefrontSitemap, error) {
+			return stored[pharmacyID], nil
+		},
+		SaveFunc: func(ctx context.Context, s *models.StorefrontSitemap) error {
+			builds++
+			stored[s.PharmacyID] = s
+			return nil
+		},
+		FingerprintFunc: func(ctx context.Context, pharmacyID uuid.UUID) (string, error) { return fingerprint, nil },
+		SourcesFunc: func(ctx context.Context, pharmacyID uuid.UUID, limit int) ([]*models.SitemapSource, error) {
+			return []*models.SitemapSource{
+				{Kind: models.SitemapKindProduct, Key: productID.String(), UpdatedAt: now},
+				{Kind: models.SitemapKindCategory, Key: "c1", UpdatedAt: now},
+				{Kind: models.SitemapKindBlogPost, Key: "winter-flu-tips", UpdatedAt: now},
+			}, nil
+		},
+	}
+	svc := &sitemapService{
+		repo: repo,
+		pharmacyRepo: &mocks.MockPharmacyRepository{
+			GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Pharmacy, error) {
+				if id == live.ID {
+					return live, nil
+				}
+				return closed, nil
+			},
+			ListFunc: func(ctx context.Context) ([]*models.Pharmacy, error) { return []*models.Pharmacy{live, closed}, nil },
+		},
+		configRepo: &mocks.MockPharmacyConfigRepository{
+			GetByPharmacyIDFunc: func(ctx context.Context, id uuid.UUID) (*models.PharmacyConfig, error) { return configs[id], nil },
+		},
+		publicURL: "http://localhost:8090",
+		logger:    zap.NewNop(),
+		now:       func() time.Time { return now },
+	}
+
+	sm, err := svc.Get(ctx, live.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	for _, want := range []string{
+		"<loc>https://shop.example.com/</loc>",
+		"<loc>https://shop.example.com/products/" + productID.String() + "</loc>",
+		"<loc>https://shop.example.com/products?category=c1</loc>",
+		"<loc>https://shop.example.com/blog/winter-flu-tips</loc>",
+		"<lastmod>2026-03-01</lastmod>",
+	} {
+		if !strings.Contains(sm.XML, want) {
+			t.Errorf("sitemap lacks %s:\n%s", want, sm.XML)
+		}
+	}
+	if sm.URLCount != len(sitemapStaticPages)+3 || sm.Fingerprint != "v1" {
+		t.Errorf("url_count = %d, fingerprint = %q", sm.URLCount, sm.Fingerprint)
+	}
+	if _, err := svc.Get(ctx, closed.ID); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeNotFound {
+		t.Errorf("website disabled: err = %v, want not found", err)
+	}
+
+	if err := svc.RefreshAll(ctx); err != nil || builds != 1 {
+		t.Fatalf("unchanged content: err = %v, builds = %d, want 1", err, builds)
+	}
+	fingerprint = "v2"
+	if err := svc.RefreshAll(ctx); err != nil || builds != 2 || stored[live.ID].Fingerprint != "v2" {
+		t.Fatalf("changed content: err = %v, builds = %d", err, builds)
+	}
+	now = now.Add(sitemapMaxAge)
+	if err := svc.RefreshAll(ctx); err != nil || builds != 3 {
+		t.Fatalf("stale sitemap: err = %v, builds = %d, want 3", err, builds)
+	}
+	if stored[closed.ID] != nil {
+		t.Error("built a sitemap for a pharmacy with the website disabled")
+	}
+}
+
+func TestSitemapService_GetRebuildsStaleOrMovedSitemap(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	pharmacy := &models.Pharmacy{ID: uuid.New(), IsActive: true}
+	cfg := &models.PharmacyConfig{PharmacyID: pharmacy.ID, WebsiteEnabled: true, SiteHostname: "shop.example.com"}
+	var stored *models.StorefrontSitemap
+	fingerprint, builds := "v1", 0
+	repo := &mocks.MockSitemapRepository{
+		GetFunc: func(ctx context.Context, pharmacyID uuid.UUID) (*models.StorefrontSitemap, error) { return stored, nil },
+		SaveFunc: func(ctx context.Context, s *models.StorefrontSitemap) error {
+			builds++
+			stored = s
+			return nil
+		},
+		FingerprintFunc: func(ctx context.Context, pharmacyID uuid.UUID) (string, error) { return fingerprint, nil },
+	}
+	svc := &sitemapService{
+		repo: repo,
+		pharmacyRepo: &mocks.MockPharmacyRepository{
+			GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Pharmacy, error) { return pharmacy, nil },
+		},
+		configRepo: &mocks.MockPharmacyConfigRepository{
+			GetByPharmacyIDFunc: func(ctx context.Context, id uuid.UUID) (*models.PharmacyConfig, error) { return cfg, nil },
+		},
+		publicURL: "http://localhost:8090",
+		logger:    zap.NewNop(),
+		now:       func() time.Time { return now },
+	}
+
+	steps := []struct {
+		name       string
+		change     func()
+		wantBuilds int
+		wantBase   string
+	}{
+		{"first request builds", func() {}, 1, "https://shop.example.com"},
+		{"fresh sitemap is served", func() {}, 1, "https://shop.example.com"},
+		{"hostname changed", func() { cfg.SiteHostname = "care.example.com" }, 2, "https://care.example.com"},
+		{"hostname cleared", func() { cfg.SiteHostname = "" }, 3, "http://localhost:8090"},
+		{"content changed", func() { fingerprint = "v2" }, 4, "http://localhost:8090"},
+		{"too old", func() { now = now.Add(sitemapMaxAge) }, 5, "http://localhost:8090"},
+	}
+	for _, st := range steps {
+		st.change()
+		sm, err := svc.Get(ctx, pharmacy.ID)
+		if err != nil {
+			t.Fatalf("%s: Get: %v", st.name, err)
+		}
+		if builds != st.wantBuilds || sm.BaseURL != st.wantBase || !strings.Contains(sm.XML, "<loc>"+st.wantBase+"/</loc>") {
+			t.Errorf("%s: builds = %d, base = %q; want %d, %q", st.name, builds, sm.BaseURL, st.wantBuilds, st.wantBase)
+		}
+	}
+
+	// A failed rebuild still serves the stored sitemap.
+	repo.SaveFunc = func(ctx context.Context, s *models.StorefrontSitemap) error { return context.DeadlineExceeded }
+	fingerprint = "v3"
+	if sm, err := svc.Get(ctx, pharmacy.ID); err != nil || sm != stored {
+		t.Errorf("failed rebuild: sitemap = %v, err = %v; want the stored one", sm, err)
+	}
+}
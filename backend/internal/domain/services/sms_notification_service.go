@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type smsNotificationService struct {
+	smsSender    outbound.SMSSender
+	configRepo   outbound.PharmacyConfigRepository
+	pharmacyRepo outbound.PharmacyRepository
+	customerRepo outbound.CustomerRepository
+	logger       *zap.Logger
+}
+
+// NewSMSNotificationService texts customers and users in their preferred language (customerRepo resolves it for
+// orders), else the pharmacy default. customerRepo may be nil.
+func NewSMSNotificationService(smsSender outbound.SMSSender, configRepo outbound.PharmacyConfigRepository, pharmacyRepo outbound.PharmacyRepository, customerRepo outbound.CustomerRepository, logger *zap.Logger) inbound.SMSNotificationService {
+	return &smsNotificationService{smsSender: smsSender, configRepo: configRepo, pharmacyRepo: pharmacyRepo, customerRepo: customerRepo, logger: logger}
+}
+
+// orderSMSText holds the message per language and status; statuses without an entry are not texted.
+var orderSMSText = map[string]map[models.OrderStatus]string{
+	"en": {
+		models.OrderStatusConfirmed: "%s: your order %s is confirmed. We will text you when it is ready.",
+		models.OrderStatusReady:     "%s: your order %s is ready for pickup.",
+		models.OrderStatusCompleted: "%s: order %s is complete. Thank you for shopping with us!",
+	},
+	"ne": {
+		models.OrderStatusConfirmed: "%s: तपाईंको अर्डर %s पुष्टि भयो। तयार भएपछि हामी SMS गर्नेछौं।",
+		models.OrderStatusReady:     "%s: तपाईंको अर्डर %s लैजान तयार छ।",
+		models.OrderStatusCompleted: "%s: अर्डर %s पूरा भयो। हामीसँग किनमेल गर्नुभएकोमा धन्यवाद!",
+	},
+}
+
+// passwordResetSMSText is the reset message per language: pharmacy name, then link.
+var passwordResetSMSText = map[string]string{
+	"en": "%s: reset your password here: %s (valid for 1 hour). Ignore this if you did not ask for it.",
+	"ne": "%s: पासवर्ड रिसेट गर्न यहाँ जानुहोस्: %s (१ घण्टासम्म मान्य)। तपाईंले अनुरोध गर्नुभएको होइन भने बेवास्ता गर्नुहोस्।",
+}
+
+func (s *smsNotificationService) OrderStatusChanged(ctx context.Context, order *models.Order) error {
+	if s.smsSender == nil || order == nil || order.CustomerPhone == "" {
+		return nil
+	}
+	lang := models.ResolveLanguage(customerLanguage(ctx, s.customerRepo, order), pharmacyLanguage(ctx, s.configRepo, order.PharmacyID))
+	text, ok := orderSMSText[lang][order.Status]
+	if !ok {
+		return nil
+	}
+	name, enabled := smsSettings(ctx, s.configRepo, s.pharmacyRepo, order.PharmacyID, models.FeatureSMSOrderUpdates)
+	if !enabled {
+		return nil
+	}
+	msg := &outbound.SMSMessage{PharmacyID: order.PharmacyID, To: order.CustomerPhone, Body: fmt.Sprintf(text, name, order.OrderNumber)}
+	if err := s.smsSender.Send(ctx, msg); err != nil {
+		s.logger.Warn("failed to send order sms", zap.Error(err), zap.String("order_id", order.ID.String()))
+		return err
+	}
+	return nil
+}
+
+func (s *smsNotificationService) PasswordReset(ctx context.Context, user *models.User, resetURL string) error {
+	if s.smsSender == nil || user == nil || user.Phone == "" {
+		return nil
+	}
+	name, enabled := smsSettings(ctx, s.configRepo, s.pharmacyRepo, user.PharmacyID, models.FeatureSMSOTP)
+	if !enabled {
+		return nil
+	}
+	lang := models.ResolveLanguage(user.PreferredLanguage, pharmacyLanguage(ctx, s.configRepo, user.PharmacyID))
+	msg := &outbound.SMSMessage{PharmacyID: user.PharmacyID, To: user.Phone, Body: fmt.Sprintf(passwordResetSMSText[lang], name, resetURL)}
+	if err := s.smsSender.Send(ctx, msg); err != nil {
+		s.logger.Warn("failed to send password reset sms", zap.Error(err), zap.String("user_id", user.ID.String()))
+		return err
+	}
+	return nil
+}
+
+func (s *smsNotificationService) WarrantyClaimUpdated(ctx context.Context, claim *models.WarrantyClaim) error {
+	if s.smsSender == nil || claim == nil || claim.Warranty == nil || claim.Warranty.CustomerPhone == "" {
+		return nil
+	}
+	w := claim.Warranty
+	name, enabled := smsSettings(ctx, s.configRepo, s.pharmacyRepo, w.PharmacyID, models.FeatureSMSOrderUpdates)
+	if !enabled {
+		return nil
+	}
+	lang := models.ResolveLanguage(customerIDLanguage(ctx, s.customerRepo, w.CustomerID), pharmacyLanguage(ctx, s.configRepo, w.PharmacyID))
+	msg := &outbound.SMSMessage{PharmacyID: w.PharmacyID, To: w.CustomerPhone, Body: name + ": " + warrantyClaimMessage(lang, claim)}
+	if err := s.smsSender.Send(ctx, msg); err != nil {
+		s.logger.Warn("failed to send warranty claim sms", zap.Error(err), zap.String("claim_id", claim.ID.String()))
+		return err
+	}
+	return nil
+}
+
+// smsSettings returns the pharmacy's display name and whether the given SMS feature flag is on.
+func smsSettings(ctx context.Context, configRepo outbound.PharmacyConfigRepository, pharmacyRepo outbound.PharmacyRepository, pharmacyID uuid.UUID, flag string) (string, bool) {
+	name := "CarePlus"
+	if pharmacyRepo != nil {
+		if p, err := pharmacyRepo.GetByID(ctx, pharmacyID); err == nil && p != nil && p.Name != "" {
+			name = p.Name
+		}
+	}
+	if configRepo == nil {
+		return name, false
+	}
+	cfg, err := configRepo.GetByPharmacyID(ctx, pharmacyID)
+	if err != nil || cfg == nil {
+		return name, false
+	}
+	if cfg.DisplayName != "" {
+		name = cfg.DisplayName
+	}
+	return name, cfg.FeatureFlags[flag]
+}
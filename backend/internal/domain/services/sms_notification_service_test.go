@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type captureSMS struct {
+	sent []*outbound.SMSMessage
+}
+
+func (c *captureSMS) Send(ctx context.Context, msg *outbound.SMSMessage) error {
+	c.sent = append(c.sent, msg)
+	return nil
+}
+
+// memoryOTPRepo keeps codes in a slice; GetLatest mirrors the DB query (newest unconsumed).
+type memoryOTPRepo struct {
+	codes []*models.OTPCode
+}
+
+func (r *memoryOTPRepo) Create(ctx context.Context, o *models.OTPCode) error {
+	o.CreatedAt = time.Now()
+	r.codes = append(r.codes, o)
+	return nil
+}
+
+func (r *memoryOTPRepo) GetLatest(ctx context.Context, pharmacyID uuid.UUID, phone, purpose string) (*models.OTPCode, error) {
+	for i := len(r.codes) - 1; i >= 0; i-- {
+		o := r.codes[i]
+		if o.PharmacyID == pharmacyID && o.Phone == phone && o.Purpose == purpose && o.ConsumedAt == nil {
+			return o, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryOTPRepo) CountSince(ctx context.Context, pharmacyID uuid.UUID, phone string, since time.Time) (int64, error) {
+	var n int64
+	for _, o := range r.codes {
+		if o.PharmacyID == pharmacyID && o.Phone == phone && !o.CreatedAt.Before(since) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *memoryOTPRepo) Update(ctx context.Context, o *models.OTPCode) error { return nil }
+
+func configWithFlags(flags models.FeatureFlagsMap) *mocks.MockPharmacyConfigRepository {
+	return &mocks.MockPharmacyConfigRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, pharmacyID uuid.UUID) (*models.PharmacyConfig, error) {
+			return &models.PharmacyConfig{PharmacyID: pharmacyID, DisplayName: "City Pharmacy", FeatureFlags: flags}, nil
+		},
+	}
+}
+
+func TestSMSNotificationService_OrderStatusChanged_RespectsFlagAndStatus(t *testing.T) {
+	order := &models.Order{ID: uuid.New(), PharmacyID: uuid.New(), OrderNumber: "ORD-1", CustomerPhone: "9800000000", Status: models.OrderStatusReady}
+
+	sender := &captureSMS{}
+	svc := NewSMSNotificationService(sender, configWithFlags(nil), nil, nil, zap.NewNop())
+	if err := svc.OrderStatusChanged(context.Background(), order); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("expected no sms with flag off, got %d", len(sender.sent))
+	}
+
+	svc = NewSMSNotificationService(sender, configWithFlags(models.FeatureFlagsMap{models.FeatureSMSOrderUpdates: true}), nil, nil, zap.NewNop())
+	_ = svc.OrderStatusChanged(context.Background(), order)
+	order.Status = models.OrderStatusProcessing
+	_ = svc.OrderStatusChanged(context.Background(), order)
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected 1 sms (ready only), got %d", len(sender.sent))
+	}
+	if sender.sent[0].To != "9800000000" {
+		t.Errorf("unexpected recipient %q", sender.sent[0].To)
+	}
+}
+
+func TestOTPService_SendAndVerify(t *testing.T) {
+	ctx := context.Background()
+	pharmacyID := uuid.New()
+	sender := &captureSMS{}
+	repo := &memoryOTPRepo{}
+	svc := NewOTPService(repo, sender, configWithFlags(models.FeatureFlagsMap{models.FeatureSMSOTP: true}), nil, zap.NewNop())
+
+	if _, err := svc.Send(ctx, pharmacyID, "9800000000", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Send(ctx, pharmacyID, "9800000000", ""); pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeTooManyRequests {
+		t.Fatalf("expected resend to be rate limited, got %v", err)
+	}
+	code := regexp.MustCompile(`\d{6}`).FindString(sender.sent[0].Body)
+	if code == "" {
+		t.Fatalf("no code in sms body %q", sender.sent[0].Body)
+	}
+	if err := svc.Verify(ctx, pharmacyID, "9800000000", "", "000000x"); pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("expected wrong code to fail validation, got %v", err)
+	}
+	if repo.codes[0].Attempts != 1 {
+		t.Errorf("expected 1 failed attempt, got %d", repo.codes[0].Attempts)
+	}
+	if err := svc.Verify(ctx, pharmacyID, "9800000000", "", code); err != nil {
+		t.Fatalf("expected code to verify, got %v", err)
+	}
+	if err := svc.Verify(ctx, pharmacyID, "9800000000", "", code); err == nil {
+		t.Fatal("expected a consumed code to be rejected")
+	}
+}
+
+func TestOTPService_Send_FlagOff(t *testing.T) {
+	svc := NewOTPService(&memoryOTPRepo{}, &captureSMS{}, configWithFlags(nil), nil, zap.NewNop())
+	_, err := svc.Send(context.Background(), uuid.New(), "9800000000", "")
+	if pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeForbidden {
+		t.Fatalf("expected forbidden, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type staffPointsService struct {
+	configRepo outbound.StaffPointsConfigRepository
+	txRepo     outbound.StaffPointsTransactionRepository
+	userRepo   outbound.UserRepository
+	logger     *zap.Logger
+}
+
+func NewStaffPointsService(configRepo outbound.StaffPointsConfigRepository, txRepo outbound.StaffPointsTransactionRepository, userRepo outbound.UserRepository, logger *zap.Logger) inbound.StaffPointsService {
+	return &staffPointsService{configRepo: configRepo, txRepo: txRepo, userRepo: userRepo, logger: logger}
+}
+
+func (s *staffPointsService) CreditSale(ctx context.Context, o *models.Order) error {
+	if o.CreatedBy == uuid.Nil || o.TotalAmount <= 0 {
+		return nil
+	}
+	cfg, err := s.configRepo.GetOrCreateByPharmacyID(ctx, o.PharmacyID)
+	if err != nil {
+		return errors.ErrInternal("failed to load staff points config", err)
+	}
+	if cfg == nil || cfg.CurrencyUnitForPoints <= 0 || cfg.PointsPerCurrencyUnit <= 0 {
+		return nil
+	}
+	units := math.Floor(o.TotalAmount / cfg.CurrencyUnitForPoints)
+	points := int(units * cfg.PointsPerCurrencyUnit)
+	if points <= 0 {
+		return nil
+	}
+	_, err = s.txRepo.Credit(ctx, &models.StaffPointsTransaction{
+		PharmacyID:  o.PharmacyID,
+		UserID:      o.CreatedBy,
+		OrderID:     o.ID,
+		OrderNumber: o.OrderNumber,
+		SaleAmount:  o.TotalAmount,
+		Points:      points,
+	})
+	if err != nil {
+		return errors.ErrInternal("failed to credit staff points", err)
+	}
+	return nil
+}
+
+func (s *staffPointsService) History(ctx context.Context, userID uuid.UUID, limit, offset int) (*inbound.StaffPointsHistory, error) {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || u == nil {
+		return nil, errors.ErrNotFound("user")
+	}
+	items, total, err := s.txRepo.ListByUser(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load points history", err)
+	}
+	if items == nil {
+		items = []*models.StaffPointsTransaction{}
+	}
+	return &inbound.StaffPointsHistory{PointsBalance: u.PointsBalance, Items: items, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+func (s *staffPointsService) MonthlyReport(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) (*inbound.StaffPointsReport, error) {
+	from, to, err := normalizeRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.txRepo.MonthlyTotals(ctx, pharmacyID, from, to)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load staff points", err)
+	}
+	if rows == nil {
+		rows = []*models.StaffPointsMonthRow{}
+	}
+	leaders := map[uuid.UUID]*inbound.StaffPointsLeader{}
+	var board []*inbound.StaffPointsLeader
+	month, rank := "", 0
+	for _, r := range rows {
+		// Rows come ordered by month, then points descending.
+		if r.Month != month {
+			month, rank = r.Month, 0
+		}
+		rank++
+		r.Rank = rank
+		l, ok := leaders[r.UserID]
+		if !ok {
+			l = &inbound.StaffPointsLeader{UserID: r.UserID, Name: r.Name, Role: r.Role}
+			leaders[r.UserID] = l
+			board = append(board, l)
+		}
+		l.SalesCount += r.SalesCount
+		l.SaleAmount = roundMoney(l.SaleAmount + r.SaleAmount)
+		l.Points += r.Points
+	}
+	sort.SliceStable(board, func(i, j int) bool {
+		if board[i].Points != board[j].Points {
+			return board[i].Points > board[j].Points
+		}
+		return board[i].Name < board[j].Name
+	})
+	for i, l := range board {
+		l.Rank = i + 1
+	}
+	if board == nil {
+		board = []*inbound.StaffPointsLeader{}
+	}
+	return &inbound.StaffPointsReport{From: from, To: to, Months: rows, Leaderboard: board}, nil
+}
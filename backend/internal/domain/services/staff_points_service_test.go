@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestStaffPointsService_CreditSale_RecordsTransaction(t *testing.T) {
+	cfg := &models.StaffPointsConfig{PointsPerCurrencyUnit: 2, CurrencyUnitForPoints: 100}
+	var got *models.StaffPointsTransaction
+	txs := &mocks.MockStaffPointsTransactionRepository{
+		CreditFunc: func(ctx context.Context, tx *models.StaffPointsTransaction) (bool, error) {
+			got = tx
+			return true, nil
+		},
+	}
+	svc := &staffPointsService{configRepo: &mocks.MockStaffPointsConfigRepository{Config: cfg}, txRepo: txs, logger: zap.NewNop()}
+
+	o := &models.Order{ID: uuid.New(), PharmacyID: uuid.New(), CreatedBy: uuid.New(), OrderNumber: "ORD-1", TotalAmount: 1250}
+	if err := svc.CreditSale(context.Background(), o); err != nil {
+		t.Fatalf("CreditSale: %v", err)
+	}
+	if got == nil || got.Points != 24 || got.UserID != o.CreatedBy || got.OrderID != o.ID || got.SaleAmount != 1250 {
+		t.Fatalf("credited %+v, want 24 points for the creator", got)
+	}
+
+	got = nil
+	o.TotalAmount = 80
+	if err := svc.CreditSale(context.Background(), o); err != nil || got != nil {
+		t.Fatalf("a sale under one currency unit should earn nothing, got %+v, %v", got, err)
+	}
+}
+
+func TestStaffPointsService_MonthlyReport_RanksMonthsAndLeaderboard(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+	txs := &mocks.MockStaffPointsTransactionRepository{
+		MonthlyTotalsFunc: func(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.StaffPointsMonthRow, error) {
+			return []*models.StaffPointsMonthRow{
+				{Month: "2026-08", UserID: alice, Name: "Alice", SalesCount: 3, SaleAmount: 900, Points: 9},
+				{Month: "2026-08", UserID: bob, Name: "Bob", SalesCount: 1, SaleAmount: 200, Points: 2},
+				{Month: "2026-09", UserID: bob, Name: "Bob", SalesCount: 5, SaleAmount: 1500, Points: 15},
+			}, nil
+		},
+	}
+	svc := &staffPointsService{txRepo: txs, logger: zap.NewNop()}
+
+	to := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	r, err := svc.MonthlyReport(context.Background(), uuid.New(), to.AddDate(0, -2, 0), to)
+	if err != nil {
+		t.Fatalf("MonthlyReport: %v", err)
+	}
+	if r.Months[0].Rank != 1 || r.Months[1].Rank != 2 || r.Months[2].Rank != 1 {
+		t.Errorf("month ranks = %d, %d, %d; want 1, 2, 1", r.Months[0].Rank, r.Months[1].Rank, r.Months[2].Rank)
+	}
+	if len(r.Leaderboard) != 2 || r.Leaderboard[0].UserID != bob || r.Leaderboard[0].Points != 17 || r.Leaderboard[0].SalesCount != 6 || r.Leaderboard[1].Rank != 2 {
+		t.Errorf("leaderboard = %+v, want Bob first with 17 points", r.Leaderboard)
+	}
+}
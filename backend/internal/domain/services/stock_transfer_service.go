@@ -0,0 +1,376 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type stockTransferService struct {
+	repo         outbound.StockTransferRepository
+	pharmacyRepo outbound.PharmacyRepository
+	productRepo  outbound.ProductRepository
+	batchRepo    outbound.InventoryBatchRepository
+	branchRepo   outbound.BranchRepository
+	activityLog  inbound.ActivityLogService
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+func NewStockTransferService(
+	repo outbound.StockTransferRepository,
+	pharmacyRepo outbound.PharmacyRepository,
+	productRepo outbound.ProductRepository,
+	batchRepo outbound.InventoryBatchRepository,
+	branchRepo outbound.BranchRepository,
+	activityLog inbound.ActivityLogService,
+	logger *zap.Logger,
+) inbound.StockTransferService {
+	return &stockTransferService{
+		repo:         repo,
+		pharmacyRepo: pharmacyRepo,
+		productRepo:  productRepo,
+		batchRepo:    batchRepo,
+		branchRepo:   branchRepo,
+		activityLog:  activityLog,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+func (s *stockTransferService) Request(ctx context.Context, pharmacyID, userID uuid.UUID, input inbound.StockTransferInput) (*models.StockTransfer, error) {
+	if input.SourcePharmacyID == pharmacyID {
+		return nil, errors.ErrValidation("source and destination pharmacy must differ")
+	}
+	dest, err := s.pharmacyRepo.GetByID(ctx, pharmacyID)
+	if err != nil || dest == nil {
+		return nil, errors.ErrNotFound("pharmacy")
+	}
+	source, err := s.pharmacyRepo.GetByID(ctx, input.SourcePharmacyID)
+	if err != nil || source == nil {
+		return nil, errors.ErrNotFound("source pharmacy")
+	}
+	if dest.GroupCode == "" || !strings.EqualFold(dest.GroupCode, source.GroupCode) {
+		return nil, errors.ErrForbidden("stock can only be transferred between pharmacies of the same group")
+	}
+	if !source.IsActive {
+		return nil, errors.ErrValidation("source pharmacy is not active")
+	}
+	if len(input.Items) == 0 {
+		return nil, errors.ErrValidation("at least one item is required")
+	}
+
+	t := &models.StockTransfer{
+		SourcePharmacyID: source.ID,
+		DestPharmacyID:   dest.ID,
+		Status:           models.StockTransferRequested,
+		Note:             strings.TrimSpace(input.Note),
+		RequestedBy:      userID,
+	}
+	byProduct := make(map[uuid.UUID]*models.StockTransferItem)
+	for _, in := range input.Items {
+		if in.Quantity <= 0 {
+			return nil, errors.ErrValidation("item quantity must be positive")
+		}
+		if it, ok := byProduct[in.ProductID]; ok {
+			it.Quantity += in.Quantity
+			continue
+		}
+		p, err := s.productRepo.GetByID(ctx, in.ProductID)
+		if err != nil || p == nil || p.PharmacyID != dest.ID {
+			return nil, errors.ErrNotFound("product")
+		}
+		if strings.TrimSpace(p.SKU) == "" {
+			return nil, errors.ErrValidation(p.Name + " has no SKU to match at the source pharmacy")
+		}
+		src, err := s.productRepo.GetBySKU(ctx, source.ID, p.SKU)
+		if err != nil || src == nil {
+			return nil, errors.ErrValidation(fmt.Sprintf("%s does not stock SKU %s (%s)", source.Name, p.SKU, p.Name))
+		}
+		it := &models.StockTransferItem{SourceProductID: src.ID, DestProductID: p.ID, SKU: p.SKU, Name: p.Name, Quantity: in.Quantity}
+		byProduct[in.ProductID] = it
+		t.Items = append(t.Items, it)
+	}
+	e := &models.StockTransferEvent{Action: models.StockTransferEventRequested, ActorID: userID, PharmacyID: dest.ID, Note: t.Note}
+	if err := s.repo.Create(ctx, t, e); err != nil {
+		return nil, errors.ErrInternal("failed to create stock transfer", err)
+	}
+	t.SourcePharmacy, t.DestPharmacy = source, dest
+	s.audit(ctx, t, dest.ID, userID, e)
+	return s.repo.GetByID(ctx, t.ID)
+}
+
+func (s *stockTransferService) Get(ctx context.Context, pharmacyID, id uuid.UUID) (*models.StockTransfer, error) {
+	t, err := s.repo.GetByID(ctx, id)
+	if err != nil || t == nil || (t.SourcePharmacyID != pharmacyID && t.DestPharmacyID != pharmacyID) {
+		return nil, errors.ErrNotFound("stock transfer")
+	}
+	return t, nil
+}
+
+func (s *stockTransferService) List(ctx context.Context, pharmacyID uuid.UUID, direction string, status models.StockTransferStatus, limit, offset int) ([]*models.StockTransfer, int64, error) {
+	switch direction {
+	case "", "incoming", "outgoing":
+	default:
+		return nil, 0, errors.ErrValidation("direction must be incoming or outgoing")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	list, total, err := s.repo.List(ctx, pharmacyID, direction, status, limit, offset)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to list stock transfers", err)
+	}
+	if list == nil {
+		list = []*models.StockTransfer{}
+	}
+	return list, total, nil
+}
+
+// side loads a transfer for one of its parties; asSource says which side may take the step.
+func (s *stockTransferService) side(ctx context.Context, pharmacyID, id uuid.UUID, asSource bool, step string) (*models.StockTransfer, error) {
+	t, err := s.Get(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if asSource && t.SourcePharmacyID != pharmacyID {
+		return nil, errors.ErrForbidden("only the source pharmacy can " + step + " a transfer")
+	}
+	if !asSource && t.DestPharmacyID != pharmacyID {
+		return nil, errors.ErrForbidden("only the requesting pharmacy can " + step + " a transfer")
+	}
+	return t, nil
+}
+
+// move applies a status change guarded by the current status and records it.
+func (s *stockTransferService) move(ctx context.Context, t *models.StockTransfer, pharmacyID, userID uuid.UUID, to models.StockTransferStatus, action, note string) (*models.StockTransfer, error) {
+	from := t.Status
+	t.Status = to
+	e := &models.StockTransferEvent{Action: action, ActorID: userID, PharmacyID: pharmacyID, Note: strings.TrimSpace(note)}
+	ok, err := s.repo.Transition(ctx, t, from, e)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to update stock transfer", err)
+	}
+	if !ok {
+		return nil, errors.ErrConflict("the transfer was changed by someone else; reload it")
+	}
+	s.audit(ctx, t, pharmacyID, userID, e)
+	return s.repo.GetByID(ctx, t.ID)
+}
+
+func (s *stockTransferService) Approve(ctx context.Context, pharmacyID, userID, id uuid.UUID, note string) (*models.StockTransfer, error) {
+	t, err := s.side(ctx, pharmacyID, id, true, "approve")
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != models.StockTransferRequested {
+		return nil, errors.ErrConflict("only requested transfers can be approved")
+	}
+	if _, _, _, err := s.plan(ctx, t); err != nil {
+		return nil, err
+	}
+	now := s.now()
+	t.ApprovedBy, t.ApprovedAt = &userID, &now
+	return s.move(ctx, t, pharmacyID, userID, models.StockTransferApproved, models.StockTransferEventApproved, note)
+}
+
+func (s *stockTransferService) Reject(ctx context.Context, pharmacyID, userID, id uuid.UUID, note string) (*models.StockTransfer, error) {
+	t, err := s.side(ctx, pharmacyID, id, true, "reject")
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != models.StockTransferRequested && t.Status != models.StockTransferApproved {
+		return nil, errors.ErrConflict("only transfers that are not yet dispatched can be rejected")
+	}
+	now := s.now()
+	t.ClosedBy, t.ClosedAt = &userID, &now
+	return s.move(ctx, t, pharmacyID, userID, models.StockTransferRejected, models.StockTransferEventRejected, note)
+}
+
+// Dispatch re-checks the source stock but does not move it; that happens on receipt.
+func (s *stockTransferService) Dispatch(ctx context.Context, pharmacyID, userID, id uuid.UUID, note string) (*models.StockTransfer, error) {
+	t, err := s.side(ctx, pharmacyID, id, true, "dispatch")
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != models.StockTransferApproved {
+		return nil, errors.ErrConflict("only approved transfers can be dispatched")
+	}
+	if _, _, _, err := s.plan(ctx, t); err != nil {
+		return nil, err
+	}
+	now := s.now()
+	t.DispatchedBy, t.DispatchedAt = &userID, &now
+	return s.move(ctx, t, pharmacyID, userID, models.StockTransferInTransit, models.StockTransferEventDispatched, note)
+}
+
+func (s *stockTransferService) Cancel(ctx context.Context, pharmacyID, userID, id uuid.UUID, note string) (*models.StockTransfer, error) {
+	t, err := s.side(ctx, pharmacyID, id, false, "cancel")
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != models.StockTransferRequested && t.Status != models.StockTransferApproved {
+		return nil, errors.ErrConflict("only transfers that are not yet dispatched can be cancelled")
+	}
+	now := s.now()
+	t.ClosedBy, t.ClosedAt = &userID, &now
+	return s.move(ctx, t, pharmacyID, userID, models.StockTransferCancelled, models.StockTransferEventCancelled, note)
+}
+
+func (s *stockTransferService) Receive(ctx context.Context, pharmacyID, userID, id uuid.UUID, note string) (*models.StockTransfer, error) {
+	t, err := s.side(ctx, pharmacyID, id, false, "receive")
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != models.StockTransferInTransit {
+		return nil, errors.ErrConflict("only dispatched transfers can be received")
+	}
+	debited, created, deltas, err := s.plan(ctx, t)
+	if err != nil {
+		if appErr := errors.GetAppError(err); appErr != nil && appErr.Code == errors.ErrCodeValidation {
+			return nil, errors.ErrConflict(appErr.Message + "; the transfer stays in transit")
+		}
+		return nil, err
+	}
+	now := s.now()
+	t.Status = models.StockTransferReceived
+	t.ReceivedBy, t.ReceivedAt = &userID, &now
+	e := &models.StockTransferEvent{Action: models.StockTransferEventReceived, ActorID: userID, PharmacyID: pharmacyID, Note: strings.TrimSpace(note)}
+	ok, err := s.repo.Receive(ctx, t, e, debited, created, deltas)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to receive stock transfer", err)
+	}
+	if !ok {
+		return nil, errors.ErrConflict("the transfer was changed by someone else; reload it")
+	}
+	s.audit(ctx, t, pharmacyID, userID, e)
+	return s.repo.GetByID(ctx, t.ID)
+}
+
+// plan works out the stock movement of a receipt against current stock and fills each item's Batches. Source
+// batches are drawn FEFO and expired ones are never sent. The destination gets one new batch per draw (at its
+// default branch, if it has branches) when it tracks the product in batches or has none of it in stock;
+// otherwise only its stock count grows, so existing unbatched stock stays sellable.
+func (s *stockTransferService) plan(ctx context.Context, t *models.StockTransfer) (debited, created []*models.InventoryBatch, deltas map[uuid.UUID]int, err error) {
+	sourceName := "the source pharmacy"
+	if t.SourcePharmacy != nil {
+		sourceName = t.SourcePharmacy.Name
+	}
+	var destBranchID *uuid.UUID
+	if s.branchRepo != nil {
+		def, err := s.branchRepo.GetDefault(ctx, t.DestPharmacyID)
+		if err != nil {
+			return nil, nil, nil, errors.ErrInternal("failed to load default branch", err)
+		}
+		if def != nil {
+			destBranchID = &def.ID
+		}
+	}
+	today := startOfDay(s.now())
+	deltas = make(map[uuid.UUID]int)
+	for _, it := range t.Items {
+		src, err := s.productRepo.GetByID(ctx, it.SourceProductID)
+		if err != nil || src == nil || src.PharmacyID != t.SourcePharmacyID {
+			return nil, nil, nil, errors.ErrValidation(fmt.Sprintf("%s no longer stocks %s", sourceName, it.Name))
+		}
+		dst, err := s.productRepo.GetByID(ctx, it.DestProductID)
+		if err != nil || dst == nil || dst.PharmacyID != t.DestPharmacyID {
+			return nil, nil, nil, errors.ErrValidation(it.Name + " no longer exists at the requesting pharmacy")
+		}
+		if src.StockQuantity < it.Quantity {
+			return nil, nil, nil, errors.ErrValidation(fmt.Sprintf("%s has only %d units of %s", sourceName, src.StockQuantity, it.Name))
+		}
+		batches, err := s.batchRepo.ListByProductID(ctx, src.ID)
+		if err != nil {
+			return nil, nil, nil, errors.ErrInternal("failed to list batches", err)
+		}
+		var draws []models.StockTransferItemBatch
+		if len(batches) == 0 {
+			draws = []models.StockTransferItemBatch{{Quantity: it.Quantity}}
+		} else {
+			var usable []*models.InventoryBatch
+			available := 0
+			for _, b := range batches {
+				if !batchExpired(b, today) && b.Quantity > 0 {
+					usable = append(usable, b)
+					available += b.Quantity
+				}
+			}
+			if available < it.Quantity {
+				return nil, nil, nil, errors.ErrValidation(fmt.Sprintf("%s has only %d unexpired units of %s", sourceName, available, it.Name))
+			}
+			sort.SliceStable(usable, func(i, j int) bool {
+				a, b := usable[i].ExpiryDate, usable[j].ExpiryDate
+				return a != nil && (b == nil || a.Before(*b))
+			})
+			remaining := it.Quantity
+			for _, b := range usable {
+				if remaining == 0 {
+					break
+				}
+				take := remaining
+				if take > b.Quantity {
+					take = b.Quantity
+				}
+				remaining -= take
+				b.Quantity -= take
+				debited = append(debited, b)
+				fromID := b.ID
+				draws = append(draws, models.StockTransferItemBatch{FromBatchID: &fromID, BatchNumber: b.BatchNumber, ExpiryDate: b.ExpiryDate, Quantity: take})
+			}
+		}
+		destBatches, err := s.batchRepo.ListByProductID(ctx, dst.ID)
+		if err != nil {
+			return nil, nil, nil, errors.ErrInternal("failed to list batches", err)
+		}
+		if len(destBatches) > 0 || dst.StockQuantity == 0 {
+			for i := range draws {
+				if draws[i].BatchNumber == "" {
+					draws[i].BatchNumber = "ST-" + t.ID.String()[:8]
+				}
+				nb := &models.InventoryBatch{
+					ID:          uuid.New(),
+					ProductID:   dst.ID,
+					PharmacyID:  t.DestPharmacyID,
+					BranchID:    destBranchID,
+					BatchNumber: draws[i].BatchNumber,
+					ExpiryDate:  draws[i].ExpiryDate,
+					Quantity:    draws[i].Quantity,
+				}
+				created = append(created, nb)
+				toID := nb.ID
+				draws[i].ToBatchID = &toID
+			}
+		}
+		it.Batches = draws
+		deltas[src.ID] -= it.Quantity
+		deltas[dst.ID] += it.Quantity
+	}
+	return debited, created, deltas, nil
+}
+
+// audit writes the step to both pharmacies' activity logs; the transfer's own events are the primary trail.
+func (s *stockTransferService) audit(ctx context.Context, t *models.StockTransfer, actorPharmacyID, userID uuid.UUID, e *models.StockTransferEvent) {
+	if s.activityLog == nil {
+		return
+	}
+	desc := fmt.Sprintf("Stock transfer %s %s (%d items)", t.ID.String()[:8], e.Action, len(t.Items))
+	for _, pharmacyID := range []uuid.UUID{t.SourcePharmacyID, t.DestPharmacyID} {
+		details := fmt.Sprintf(`{"status":%q,"actor_pharmacy_id":%q,"note":%q}`, t.Status, actorPharmacyID, e.Note)
+		if err := s.activityLog.Create(ctx, pharmacyID, userID, "stock_transfer."+e.Action, desc, "stock_transfer", t.ID.String(), details, ""); err != nil {
+			s.logger.Warn("stock transfer audit log failed", zap.Error(err), zap.String("transfer_id", t.ID.String()))
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type stockTransferFixture struct {
+	source, dest  *models.Pharmacy
+	srcProduct    *models.Product
+	dstProduct    *models.Product
+	srcBatches    []*models.InventoryBatch
+	transfer      *models.StockTransfer
+	debited       []*models.InventoryBatch
+	created       []*models.InventoryBatch
+	deltas        map[uuid.UUID]int
+	receiveCalled bool
+	svc           *stockTransferService
+}
+
+func newStockTransferFixture() *stockTransferFixture {
+	f := &stockTransferFixture{}
+	f.source = &models.Pharmacy{ID: uuid.New(), Name: "City Pharmacy", GroupCode: "ACME", IsActive: true}
+	f.dest = &models.Pharmacy{ID: uuid.New(), Name: "Lakeside Pharmacy", GroupCode: "acme", IsActive: true}
+	f.srcProduct = &models.Product{ID: uuid.New(), PharmacyID: f.source.ID, SKU: "AMOX-500", Name: "Amoxicillin 500mg", StockQuantity: 30}
+	f.dstProduct = &models.Product{ID: uuid.New(), PharmacyID: f.dest.ID, SKU: "AMOX-500", Name: "Amoxicillin 500mg"}
+	pharmacies := &mocks.MockPharmacyRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Pharmacy, error) {
+			for _, p := range []*models.Pharmacy{f.source, f.dest} {
+				if p.ID == id {
+					return p, nil
+				}
+			}
+			return nil, nil
+		},
+	}
+	products := &mocks.MockProductRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+			for _, p := range []*models.Product{f.srcProduct, f.dstProduct} {
+				if p.ID == id {
+					return p, nil
+				}
+			}
+			return nil, nil
+		},
+		GetBySKUFunc: func(ctx context.Context, pharmacyID uuid.UUID, sku string) (*models.Product, error) {
+			if pharmacyID == f.source.ID && sku == f.srcProduct.SKU {
+				return f.srcProduct, nil
+			}
+			return nil, nil
+		},
+	}
+	batches := &mocks.MockInventoryBatchRepository{
+		ListByProductIDFunc: func(ctx context.Context, productID uuid.UUID) ([]*models.InventoryBatch, error) {
+			if productID == f.srcProduct.ID {
+				return f.srcBatches, nil
+			}
+			return nil, nil
+		},
+	}
+	repo := &mocks.MockStockTransferRepository{
+		CreateFunc: func(ctx context.Context, t *models.StockTransfer, e *models.StockTransferEvent) error {
+			t.ID = uuid.New()
+			f.transfer = t
+			return nil
+		},
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.StockTransfer, error) {
+			return f.transfer, nil
+		},
+		ReceiveFunc: func(ctx context.Context, t *models.StockTransfer, e *models.StockTransferEvent, debited, created []*models.InventoryBatch, stockDeltas map[uuid.UUID]int) (bool, error) {
+			f.receiveCalled = true
+			f.debited, f.created, f.deltas = debited, created, stockDeltas
+			return true, nil
+		},
+	}
+	f.svc = NewStockTransferService(repo, pharmacies, products, batches, &mocks.MockBranchRepository{}, nil, zap.NewNop()).(*stockTransferService)
+	f.svc.now = func() time.Time { return time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC) }
+	return f
+}
+
+func (f *stockTransferFixture) inTransit(qty int) {
+	f.transfer = &models.StockTransfer{
+		ID:               uuid.New(),
+		SourcePharmacyID: f.source.ID,
+		DestPharmacyID:   f.dest.ID,
+		Status:           models.StockTransferInTransit,
+		SourcePharmacy:   f.source,
+		Items: []*models.StockTransferItem{{
+			ID: uuid.New(), SourceProductID: f.srcProduct.ID, DestProductID: f.dstProduct.ID, SKU: "AMOX-500", Name: "Amoxicillin 500mg", Quantity: qty,
+		}},
+	}
+}
+
+func TestStockTransferService_Request_MatchesSourceProductBySKU(t *testing.T) {
+	f := newStockTransferFixture()
+	got, err := f.svc.Request(context.Background(), f.dest.ID, uuid.New(), inbound.StockTransferInput{
+		SourcePharmacyID: f.source.ID,
+		Items:            []inbound.StockTransferItemInput{{ProductID: f.dstProduct.ID, Quantity: 4}, {ProductID: f.dstProduct.ID, Quantity: 6}},
+	})
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if got.Status != models.StockTransferRequested || len(got.Items) != 1 {
+		t.Fatalf("status %s, %d items; want requested with 1 merged item", got.Status, len(got.Items))
+	}
+	if it := got.Items[0]; it.SourceProductID != f.srcProduct.ID || it.Quantity != 10 {
+		t.Errorf("item = %+v, want source product matched by SKU with quantity 10", it)
+	}
+}
+
+func TestStockTransferService_Request_OtherGroupForbidden(t *testing.T) {
+	f := newStockTransferFixture()
+	f.source.GroupCode = "OTHER"
+	_, err := f.svc.Request(context.Background(), f.dest.ID, uuid.New(), inbound.StockTransferInput{
+		SourcePharmacyID: f.source.ID,
+		Items:            []inbound.StockTransferItemInput{{ProductID: f.dstProduct.ID, Quantity: 1}},
+	})
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeForbidden {
+		t.Fatalf("err = %v, want forbidden", err)
+	}
+}
+
+func TestStockTransferService_Receive_DebitsFEFOAndSkipsExpired(t *testing.T) {
+	f := newStockTransferFixture()
+	expired := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	soon := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	later := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	f.srcBatches = []*models.InventoryBatch{
+		{ID: uuid.New(), ProductID: f.srcProduct.ID, BatchNumber: "L-LATE", ExpiryDate: &later, Quantity: 10},
+		{ID: uuid.New(), ProductID: f.srcProduct.ID, BatchNumber: "L-OLD", ExpiryDate: &expired, Quantity: 10},
+		{ID: uuid.New(), ProductID: f.srcProduct.ID, BatchNumber: "L-SOON", ExpiryDate: &soon, Quantity: 10},
+	}
+	f.inTransit(14)
+
+	got, err := f.svc.Receive(context.Background(), f.dest.ID, uuid.New(), f.transfer.ID, "")
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	if got.Status != models.StockTransferReceived {
+		t.Errorf("status = %s, want received", got.Status)
+	}
+	if len(f.debited) != 2 || f.debited[0].BatchNumber != "L-SOON" || f.debited[0].Quantity != 0 || f.debited[1].BatchNumber != "L-LATE" || f.debited[1].Quantity != 6 {
+		t.Fatalf("debited = %+v, want L-SOON emptied then 4 from L-LATE", f.debited)
+	}
+	if len(f.created) != 2 || f.created[0].PharmacyID != f.dest.ID || f.created[0].Quantity != 10 || f.created[1].BatchNumber != "L-LATE" {
+		t.Errorf("created = %+v, want two destination batches mirroring the draws", f.created)
+	}
+	if f.deltas[f.srcProduct.ID] != -14 || f.deltas[f.dstProduct.ID] != 14 {
+		t.Errorf("deltas = %v, want -14/+14", f.deltas)
+	}
+	if b := got.Items[0].Batches; len(b) != 2 || b[0].FromBatchID == nil || b[0].ToBatchID == nil {
+		t.Errorf("item batches = %+v, want from/to links per draw", b)
+	}
+}
+
+func TestStockTransferService_Receive_ShortfallStaysInTransit(t *testing.T) {
+	f := newStockTransferFixture()
+	soon := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	f.srcBatches = []*models.InventoryBatch{{ID: uuid.New(), ProductID: f.srcProduct.ID, BatchNumber: "L-SOON", ExpiryDate: &soon, Quantity: 5}}
+	f.inTransit(8)
+
+	_, err := f.svc.Receive(context.Background(), f.dest.ID, uuid.New(), f.transfer.ID, "")
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeConflict {
+		t.Fatalf("err = %v, want conflict", err)
+	}
+	if f.receiveCalled || f.transfer.Status != models.StockTransferInTransit {
+		t.Errorf("receive called = %v, status = %s; want no stock movement", f.receiveCalled, f.transfer.Status)
+	}
+}
+
+func TestStockTransferService_Receive_SourceSideForbidden(t *testing.T) {
+	f := newStockTransferFixture()
+	f.inTransit(1)
+	_, err := f.svc.Receive(context.Background(), f.source.ID, uuid.New(), f.transfer.ID, "")
+	if appErr := pkgerrors.GetAppError(err); appErr == nil || appErr.Code != pkgerrors.ErrCodeForbidden {
+		t.Fatalf("err = %v, want forbidden", err)
+	}
+}
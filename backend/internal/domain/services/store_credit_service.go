@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type storeCreditService struct {
+	repo         outbound.StoreCreditRepository
+	customerRepo outbound.CustomerRepository
+	orderRepo    outbound.OrderRepository
+	invoiceSvc   inbound.InvoiceService
+	logger       *zap.Logger
+}
+
+// NewStoreCreditService returns the service. invoiceSvc, when set, issues a credit note for order refunds.
+func NewStoreCreditService(repo outbound.StoreCreditRepository, customerRepo outbound.CustomerRepository, orderRepo outbound.OrderRepository, invoiceSvc inbound.InvoiceService, logger *zap.Logger) inbound.StoreCreditService {
+	return &storeCreditService{repo: repo, customerRepo: customerRepo, orderRepo: orderRepo, invoiceSvc: invoiceSvc, logger: logger}
+}
+
+func (s *storeCreditService) customer(ctx context.Context, pharmacyID, customerID uuid.UUID) (*models.Customer, error) {
+	c, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil || c == nil || c.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("customer")
+	}
+	return c, nil
+}
+
+func (s *storeCreditService) adjust(ctx context.Context, e *models.StoreCreditEntry) (*models.StoreCreditEntry, error) {
+	e.Amount = roundMoney(e.Amount)
+	ok, err := s.repo.Adjust(ctx, e)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to update store credit", err)
+	}
+	if !ok {
+		return nil, errors.ErrValidation("store credit balance is too low")
+	}
+	return e, nil
+}
+
+func (s *storeCreditService) Ledger(ctx context.Context, pharmacyID, customerID uuid.UUID, limit, offset int) (*inbound.StoreCreditLedger, error) {
+	c, err := s.customer(ctx, pharmacyID, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	entries, total, err := s.repo.ListByCustomer(ctx, c.ID, limit, offset)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list store credit", err)
+	}
+	return &inbound.StoreCreditLedger{CustomerID: c.ID, Balance: c.StoreCreditBalance, Entries: entries, Total: total}, nil
+}
+
+func (s *storeCreditService) Adjust(ctx context.Context, pharmacyID, customerID, actorID uuid.UUID, amount float64, note string) (*models.StoreCreditEntry, error) {
+	if roundMoney(amount) == 0 {
+		return nil, errors.ErrValidation("amount must not be zero")
+	}
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, errors.ErrValidation("note is required for a manual adjustment")
+	}
+	if _, err := s.customer(ctx, pharmacyID, customerID); err != nil {
+		return nil, err
+	}
+	return s.adjust(ctx, &models.StoreCreditEntry{
+		PharmacyID: pharmacyID,
+		CustomerID: customerID,
+		Type:       models.StoredValueAdjustment,
+		Amount:     amount,
+		Note:       note,
+		CreatedBy:  actorID,
+	})
+}
+
+func (s *storeCreditService) RefundOrder(ctx context.Context, pharmacyID, orderID, actorID uuid.UUID, amount float64, note string) (*models.StoreCreditEntry, error) {
+	if amount < 0 {
+		return nil, errors.ErrValidation("amount must not be negative")
+	}
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil || o == nil || o.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("order")
+	}
+	if o.Status != models.OrderStatusCompleted {
+		return nil, errors.ErrValidation("only completed orders can be refunded to store credit")
+	}
+	if o.CustomerID == nil {
+		return nil, errors.ErrValidation("order has no customer to credit")
+	}
+	entries, err := s.repo.ListByOrder(ctx, o.ID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load store credit", err)
+	}
+	refundable := o.TotalAmount + o.GiftCardAmount + o.StoreCreditAmount
+	for _, e := range entries {
+		if e.Type == models.StoredValueRefund {
+			refundable -= e.Amount
+		}
+	}
+	refundable = roundMoney(refundable)
+	if refundable <= 0 {
+		return nil, errors.ErrValidation("order is already fully refunded")
+	}
+	if amount == 0 {
+		amount = refundable
+	}
+	if roundMoney(amount) > refundable {
+		return nil, errors.ErrValidation("refund exceeds the refundable amount")
+	}
+	e, err := s.adjust(ctx, &models.StoreCreditEntry{
+		PharmacyID: pharmacyID,
+		CustomerID: *o.CustomerID,
+		Type:       models.StoredValueRefund,
+		Amount:     amount,
+		OrderID:    &o.ID,
+		Note:       strings.TrimSpace(note),
+		CreatedBy:  actorID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The credit note is capped at what is not yet credited, so a refund for an approved return adds none.
+	if s.invoiceSvc != nil {
+		reason := strings.TrimSpace(note)
+		if reason == "" {
+			reason = "Refund to store credit"
+		}
+		if _, err := s.invoiceSvc.IssueCreditNote(ctx, pharmacyID, o.ID, actorID, inbound.CreditNoteInput{
+			Source: models.CreditNoteSourceRefund, SourceID: &e.ID, Amount: amount, Reason: reason,
+		}); err != nil {
+			s.logger.Warn("failed to issue credit note for refund", zap.String("order_id", o.ID.String()), zap.Error(err))
+		}
+	}
+	return e, nil
+}
+
+func (s *storeCreditService) Use(ctx context.Context, pharmacyID, customerID, orderID, actorID uuid.UUID, amount float64) (*models.StoreCreditEntry, error) {
+	if roundMoney(amount) <= 0 {
+		return nil, errors.ErrValidation("amount must be positive")
+	}
+	if _, err := s.customer(ctx, pharmacyID, customerID); err != nil {
+		return nil, err
+	}
+	return s.adjust(ctx, &models.StoreCreditEntry{
+		PharmacyID: pharmacyID,
+		CustomerID: customerID,
+		Type:       models.StoredValueRedeem,
+		Amount:     -amount,
+		OrderID:    &orderID,
+		CreatedBy:  actorID,
+	})
+}
+
+func (s *storeCreditService) ReverseForOrder(ctx context.Context, orderID, actorID uuid.UUID) error {
+	entries, err := s.repo.ListByOrder(ctx, orderID)
+	if err != nil {
+		return errors.ErrInternal("failed to load store credit", err)
+	}
+	held := 0.0
+	var last *models.StoreCreditEntry
+	for _, e := range entries {
+		if e.Type == models.StoredValueRedeem || e.Type == models.StoredValueReversal {
+			held -= e.Amount
+			last = e
+		}
+	}
+	if held = roundMoney(held); held <= 0 || last == nil {
+		return nil
+	}
+	_, err = s.adjust(ctx, &models.StoreCreditEntry{
+		PharmacyID: last.PharmacyID,
+		CustomerID: last.CustomerID,
+		Type:       models.StoredValueReversal,
+		Amount:     held,
+		OrderID:    &orderID,
+		CreatedBy:  actorID,
+	})
+	return err
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestStoreCreditService_RefundOrder_CapsAtPaidAmount(t *testing.T) {
+	pharmacyID, customerID := uuid.New(), uuid.New()
+	order := &models.Order{ID: uuid.New(), PharmacyID: pharmacyID, CustomerID: &customerID, Status: models.OrderStatusCompleted,
+		TotalAmount: 300, GiftCardAmount: 100, StoreCreditAmount: 50}
+	var credited []*models.StoreCreditEntry
+	repo := &mocks.MockStoreCreditRepository{
+		ListByOrderFunc: func(ctx context.Context, orderID uuid.UUID) ([]*models.StoreCreditEntry, error) {
+			return []*models.StoreCreditEntry{
+				{Type: models.StoredValueRedeem, Amount: -50},
+				{Type: models.StoredValueRefund, Amount: 200},
+			}, nil
+		},
+		AdjustFunc: func(ctx context.Context, e *models.StoreCreditEntry) (bool, error) {
+			credited = append(credited, e)
+			return true, nil
+		},
+	}
+	orders := &mocks.MockOrderRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Order, error) { return order, nil },
+	}
+	svc := &storeCreditService{repo: repo, orderRepo: orders, logger: zap.NewNop()}
+
+	_, err := svc.RefundOrder(context.Background(), pharmacyID, order.ID, uuid.New(), 300, "")
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("err = %v, want validation error for refund over 250", err)
+	}
+	e, err := svc.RefundOrder(context.Background(), pharmacyID, order.ID, uuid.New(), 0, "damaged")
+	if err != nil {
+		t.Fatalf("RefundOrder: %v", err)
+	}
+	if e.Amount != 250 || e.CustomerID != customerID || e.Type != models.StoredValueRefund || len(credited) != 1 {
+		t.Errorf("entry = %+v, want refund of the remaining 250 to the customer", e)
+	}
+}
+
+func TestStoreCreditService_Use_BalanceTooLow(t *testing.T) {
+	pharmacyID, customerID := uuid.New(), uuid.New()
+	customers := &mocks.MockCustomerRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Customer, error) {
+			return &models.Customer{ID: id, PharmacyID: pharmacyID}, nil
+		},
+	}
+	repo := &mocks.MockStoreCreditRepository{
+		AdjustFunc: func(ctx context.Context, e *models.StoreCreditEntry) (bool, error) { return false, nil },
+	}
+	svc := &storeCreditService{repo: repo, customerRepo: customers, logger: zap.NewNop()}
+
+	_, err := svc.Use(context.Background(), pharmacyID, customerID, uuid.New(), uuid.New(), 40)
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("err = %v, want validation error", err)
+	}
+}
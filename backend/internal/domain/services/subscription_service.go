@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxRenewalMonths bounds one renewal.
+const maxRenewalMonths = 36
+
+type subscriptionService struct {
+	repo   outbound.SubscriptionRepository
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+func NewSubscriptionService(repo outbound.SubscriptionRepository, logger *zap.Logger) inbound.SubscriptionService {
+	return &subscriptionService{repo: repo, logger: logger, now: time.Now}
+}
+
+func (s *subscriptionService) Get(ctx context.Context, pharmacyID uuid.UUID) (*inbound.SubscriptionStatus, error) {
+	sub, err := s.load(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.repo.Usage(ctx, pharmacyID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to count plan usage", err)
+	}
+	status := &inbound.SubscriptionStatus{Subscription: sub, Usage: usage}
+	if sub != nil {
+		plan := models.Plans[sub.Plan]
+		grace := sub.GraceEndsAt()
+		status.Plan, status.State, status.GraceEndsAt = &plan, sub.State(s.now()), &grace
+	}
+	return status, nil
+}
+
+func (s *subscriptionService) CheckWritable(ctx context.Context, pharmacyID uuid.UUID) error {
+	sub, err := s.load(ctx, pharmacyID)
+	if err != nil || sub == nil {
+		return err
+	}
+	if sub.State(s.now()) == models.SubscriptionExpired {
+		return errors.ErrPaymentRequired(fmt.Sprintf("the %s plan expired on %s and its grace period ended on %s; renew the subscription to make changes",
+			sub.Plan, sub.ExpiresAt.Format("2006-01-02"), sub.GraceEndsAt().Format("2006-01-02")))
+	}
+	return nil
+}
+
+func (s *subscriptionService) CheckLimit(ctx context.Context, pharmacyID uuid.UUID, limit string) error {
+	sub, err := s.load(ctx, pharmacyID)
+	if err != nil || sub == nil {
+		return err
+	}
+	allowed := sub.Limit(limit)
+	if allowed <= 0 {
+		return nil
+	}
+	usage, err := s.repo.Usage(ctx, pharmacyID)
+	if err != nil {
+		return errors.ErrInternal("failed to count plan usage", err)
+	}
+	if used := usage.Of(limit); used >= allowed {
+		if limit == models.PlanLimitStorage {
+			return errors.ErrPlanLimit(fmt.Sprintf("the %s plan includes %d MB of storage and %d MB are used; upgrade the plan or delete files",
+				sub.Plan, allowed>>20, used>>20))
+		}
+		return errors.ErrPlanLimit(fmt.Sprintf("the %s plan allows %d %s and the pharmacy has %d; upgrade the plan to add more",
+			sub.Plan, allowed, limit, used))
+	}
+	return nil
+}
+
+func (s *subscriptionService) CheckFeature(ctx context.Context, pharmacyID uuid.UUID, feature string) error {
+	sub, err := s.load(ctx, pharmacyID)
+	if err != nil || sub == nil {
+		return err
+	}
+	if !models.Plans[sub.Plan].Allows(feature) {
+		return errors.ErrPlanLimit(fmt.Sprintf("%s is not included in the %s plan; upgrade the plan to use it", feature, sub.Plan))
+	}
+	return nil
+}
+
+func (s *subscriptionService) Renew(ctx context.Context, pharmacyID, actorID uuid.UUID, plan string, months int, reference string) (*models.Subscription, error) {
+	p, ok := models.Plans[plan]
+	if !ok || plan == models.PlanTrial {
+		return nil, errors.ErrValidation("unknown plan " + plan)
+	}
+	if months < 1 || months > maxRenewalMonths {
+		return nil, errors.ErrValidation(fmt.Sprintf("months must be between 1 and %d", maxRenewalMonths))
+	}
+	sub, err := s.load(ctx, pharmacyID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if sub == nil {
+		sub = &models.Subscription{PharmacyID: pharmacyID, ExpiresAt: now}
+	}
+	from := sub.ExpiresAt
+	if from.Before(now) {
+		// Time lost past expiry is not paid for again.
+		from = now
+	}
+	sub.SetPlan(p)
+	sub.ExpiresAt = from.AddDate(0, months, 0)
+	sub.RenewedAt, sub.RenewedBy, sub.Reference = &now, &actorID, strings.TrimSpace(reference)
+	if err := s.repo.Save(ctx, sub); err != nil {
+		return nil, errors.ErrInternal("failed to renew subscription", err)
+	}
+	s.logger.Info("subscription renewed", zap.String("pharmacy_id", pharmacyID.String()), zap.String("plan", plan),
+		zap.Int("months", months), zap.Time("expires_at", sub.ExpiresAt))
+	return sub, nil
+}
+
+func (s *subscriptionService) load(ctx context.Context, pharmacyID uuid.UUID) (*models.Subscription, error) {
+	sub, err := s.repo.GetByPharmacyID(ctx, pharmacyID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load subscription", err)
+	}
+	return sub, nil
+}
+
+// planFeatures narrows the config's feature flags to those the pharmacy's plan includes; sub may be nil.
+func planFeatures(flags models.FeatureFlagsMap, sub *models.Subscription) models.FeatureFlagsMap {
+	if sub == nil {
+		return flags
+	}
+	plan := models.Plans[sub.Plan]
+	out := make(models.FeatureFlagsMap, len(flags))
+	for k, v := range flags {
+		out[k] = v && plan.Allows(k)
+	}
+	return out
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func newSubscriptionFixture(sub *models.Subscription, usage *models.SubscriptionUsage, now time.Time) (*subscriptionService, *models.Subscription) {
+	repo := &mocks.MockSubscriptionRepository{
+		GetByPharmacyIDFunc: func(ctx context.Context, pharmacyID uuid.UUID) (*models.Subscription, error) {
+			if sub == nil {
+				return nil, nil
+			}
+			cp := *sub
+			return &cp, nil
+		},
+		SaveFunc: func(ctx context.Context, s *models.Subscription) error {
+			cp := *s
+			sub = &cp
+			return nil
+		},
+		UsageFunc: func(ctx context.Context, pharmacyID uuid.UUID) (*models.SubscriptionUsage, error) {
+			return usage, nil
+		},
+	}
+	svc := NewSubscriptionService(repo, zap.NewNop()).(*subscriptionService)
+	svc.now = func() time.Time { return now }
+	return svc, sub
+}
+
+func planSubscription(plan string, expiresAt time.Time) *models.Subscription {
+	sub := &models.Subscription{ID: uuid.New(), PharmacyID: uuid.New(), ExpiresAt: expiresAt}
+	sub.SetPlan(models.Plans[plan])
+	return sub
+}
+
+func appCode(err error) string {
+	if appErr := errors.GetAppError(err); appErr != nil {
+		return appErr.Code
+	}
+	return ""
+}
+
+func TestSubscriptionService_GracePeriod(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name      string
+		expiresAt time.Time
+		state     string
+		writable  bool
+	}{
+		{"active", now.AddDate(0, 0, 10), models.SubscriptionActive, true},
+		{"in grace", now.AddDate(0, 0, -3), models.SubscriptionGrace, true},
+		{"past grace", now.AddDate(0, 0, -8), models.SubscriptionExpired, false},
+	}
+	for _, tc := range cases {
+		sub := planSubscription(models.PlanBasic, tc.expiresAt)
+		svc, _ := newSubscriptionFixture(sub, &models.SubscriptionUsage{}, now)
+		status, err := svc.Get(ctx, sub.PharmacyID)
+		if err != nil {
+			t.Fatalf("%s: Get: %v", tc.name, err)
+		}
+		if status.State != tc.state {
+			t.Errorf("%s: state = %q, want %q", tc.name, status.State, tc.state)
+		}
+		err = svc.CheckWritable(ctx, sub.PharmacyID)
+		if tc.writable && err != nil {
+			t.Errorf("%s: CheckWritable: %v", tc.name, err)
+		}
+		if !tc.writable && appCode(err) != errors.ErrCodePaymentRequired {
+			t.Errorf("%s: CheckWritable err = %v, want payment required", tc.name, err)
+		}
+	}
+
+	// Pharmacies from before subscriptions are not limited.
+	svc, _ := newSubscriptionFixture(nil, &models.SubscriptionUsage{Products: 1 << 20}, now)
+	if err := svc.CheckWritable(ctx, uuid.New()); err != nil {
+		t.Errorf("CheckWritable without a subscription: %v", err)
+	}
+	if err := svc.CheckLimit(ctx, uuid.New(), models.PlanLimitProducts); err != nil {
+		t.Errorf("CheckLimit without a subscription: %v", err)
+	}
+}
+
+func TestSubscriptionService_Limits(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	sub := planSubscription(models.PlanTrial, now.Add(models.TrialPeriod))
+
+	svc, _ := newSubscriptionFixture(sub, &models.SubscriptionUsage{Products: 99, Users: 3, StorageBytes: 1024 << 20}, now)
+	if err := svc.CheckLimit(ctx, sub.PharmacyID, models.PlanLimitProducts); err != nil {
+		t.Errorf("product 100 of 100: %v", err)
+	}
+	err := svc.CheckLimit(ctx, sub.PharmacyID, models.PlanLimitUsers)
+	if appCode(err) != errors.ErrCodePlanLimit || !strings.Contains(err.Error(), "allows 3 users") {
+		t.Errorf("user 4 of 3: err = %v, want a plan limit naming the limit", err)
+	}
+	err = svc.CheckLimit(ctx, sub.PharmacyID, models.PlanLimitStorage)
+	if appCode(err) != errors.ErrCodePlanLimit || !strings.Contains(err.Error(), "1024 MB") {
+		t.Errorf("storage full: err = %v, want a plan limit in MB", err)
+	}
+
+	if err := svc.CheckFeature(ctx, sub.PharmacyID, "chat"); err != nil {
+		t.Errorf("chat on trial: %v", err)
+	}
+	basic := planSubscription(models.PlanBasic, now.AddDate(0, 1, 0))
+	svc, _ = newSubscriptionFixture(basic, &models.SubscriptionUsage{}, now)
+	if err := svc.CheckFeature(ctx, basic.PharmacyID, "chat"); appCode(err) != errors.ErrCodePlanLimit {
+		t.Errorf("chat on basic: err = %v, want plan limit", err)
+	}
+	pro := planSubscription(models.PlanPro, now.AddDate(0, 1, 0))
+	svc, _ = newSubscriptionFixture(pro, &models.SubscriptionUsage{Products: 1 << 20}, now)
+	if err := svc.CheckLimit(ctx, pro.PharmacyID, models.PlanLimitProducts); err != nil {
+		t.Errorf("products on pro: %v", err)
+	}
+}
+
+func TestSubscriptionService_Renew(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	actor := uuid.New()
+
+	// Renewing early keeps the days left.
+	sub := planSubscription(models.PlanTrial, now.AddDate(0, 0, 5))
+	svc, _ := newSubscriptionFixture(sub, &models.SubscriptionUsage{}, now)
+	got, err := svc.Renew(ctx, sub.PharmacyID, actor, models.PlanStandard, 12, " INV-1 ")
+	if err != nil {
+		t.Fatalf("Renew: %v", err)
+	}
+	if want := now.AddDate(0, 0, 5).AddDate(0, 12, 0); !got.ExpiresAt.Equal(want) {
+		t.Errorf("expires_at = %v, want %v", got.ExpiresAt, want)
+	}
+	if got.Plan != models.PlanStandard || got.MaxProducts != 5000 || got.Reference != "INV-1" || got.RenewedBy == nil || *got.RenewedBy != actor {
+		t.Errorf("renewed subscription = %+v", got)
+	}
+
+	// Renewing after expiry starts from now.
+	sub = planSubscription(models.PlanBasic, now.AddDate(0, -2, 0))
+	svc, _ = newSubscriptionFixture(sub, &models.SubscriptionUsage{}, now)
+	got, err = svc.Renew(ctx, sub.PharmacyID, actor, models.PlanBasic, 1, "")
+	if err != nil {
+		t.Fatalf("Renew: %v", err)
+	}
+	if want := now.AddDate(0, 1, 0); !got.ExpiresAt.Equal(want) {
+		t.Errorf("expires_at = %v, want %v", got.ExpiresAt, want)
+	}
+
+	for _, tc := range []struct {
+		plan   string
+		months int
+	}{{models.PlanTrial, 1}, {"gold", 1}, {models.PlanPro, 0}, {models.PlanPro, 37}} {
+		if _, err := svc.Renew(ctx, sub.PharmacyID, actor, tc.plan, tc.months, ""); appCode(err) != errors.ErrCodeValidation {
+			t.Errorf("Renew(%s, %d): err = %v, want validation", tc.plan, tc.months, err)
+		}
+	}
+}
+
+func TestPlanFeatures(t *testing.T) {
+	flags := models.FeatureFlagsMap{"products": true, "chat": true, "promos": false}
+	if got := planFeatures(flags, nil); !got["chat"] {
+		t.Errorf("no subscription: chat = false, want the config's flag")
+	}
+	got := planFeatures(flags, planSubscription(models.PlanBasic, time.Now()))
+	if !got["products"] || got["chat"] || got["promos"] {
+		t.Errorf("basic plan flags = %v, want chat turned off", got)
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type supplierService struct {
+	repo   outbound.SupplierRepository
+	logger *zap.Logger
+}
+
+func NewSupplierService(repo outbound.SupplierRepository, logger *zap.Logger) inbound.SupplierService {
+	return &supplierService{repo: repo, logger: logger}
+}
+
+func (s *supplierService) Create(ctx context.Context, pharmacyID uuid.UUID, sup *models.Supplier) (*models.Supplier, error) {
+	if strings.TrimSpace(sup.Name) == "" {
+		return nil, errors.ErrValidation("supplier name is required")
+	}
+	sup.ID = uuid.Nil
+	sup.PharmacyID = pharmacyID
+	if err := s.repo.Create(ctx, sup); err != nil {
+		return nil, errors.ErrInternal("failed to create supplier", err)
+	}
+	return sup, nil
+}
+
+func (s *supplierService) GetByID(ctx context.Context, pharmacyID, id uuid.UUID) (*models.Supplier, error) {
+	sup, err := s.repo.GetByID(ctx, id)
+	if err != nil || sup == nil || sup.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("supplier")
+	}
+	return sup, nil
+}
+
+func (s *supplierService) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, activeOnly bool) ([]*models.Supplier, error) {
+	return s.repo.ListByPharmacy(ctx, pharmacyID, activeOnly)
+}
+
+func (s *supplierService) Update(ctx context.Context, pharmacyID uuid.UUID, sup *models.Supplier) (*models.Supplier, error) {
+	existing, err := s.GetByID(ctx, pharmacyID, sup.ID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(sup.Name) == "" {
+		return nil, errors.ErrValidation("supplier name is required")
+	}
+	sup.PharmacyID = pharmacyID
+	sup.CreatedAt = existing.CreatedAt
+	if err := s.repo.Update(ctx, sup); err != nil {
+		return nil, errors.ErrInternal("failed to update supplier", err)
+	}
+	return sup, nil
+}
+
+func (s *supplierService) Delete(ctx context.Context, pharmacyID, id uuid.UUID) error {
+	if _, err := s.GetByID(ctx, pharmacyID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
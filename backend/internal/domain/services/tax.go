@@ -0,0 +1,108 @@
+package services
+
+import (
+	"math"
+	"sort"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+)
+
+// taxableLine is one order line for tax computation: class from the product, amount = unit price * quantity.
+type taxableLine struct {
+	TaxClass string
+	Amount   float64
+}
+
+// lineTax is the computed VAT for one order line.
+type lineTax struct {
+	TaxClass string
+	Rate     float64
+	Tax      float64
+}
+
+// roundMoney rounds an amount in the default currency; amounts of an order or pharmacy round with
+// models.RoundAmount in their own currency.
+func roundMoney(v float64) float64 {
+	return models.RoundAmount(v, models.DefaultCurrency)
+}
+
+// computeOrderTax spreads the order discount pro rata over the lines and applies each line's VAT rate.
+// When prices include tax, VAT is extracted from the discounted amount (total unchanged);
+// otherwise it is added on top and the caller adds the returned total to the order total.
+// A nil config or disabled tax yields zero tax for every line.
+func computeOrderTax(cfg *models.PharmacyConfig, lines []taxableLine, discount float64) ([]lineTax, float64) {
+	out := make([]lineTax, len(lines))
+	var subTotal float64
+	for i, l := range lines {
+		out[i].TaxClass = models.NormalizeTaxClass(l.TaxClass)
+		subTotal += l.Amount
+	}
+	if cfg == nil || !cfg.TaxEnabled || subTotal <= 0 {
+		return out, 0
+	}
+	discountRatio := 0.0
+	if discount > 0 {
+		discountRatio = math.Min(discount/subTotal, 1)
+	}
+	var total float64
+	for i, l := range lines {
+		rate := cfg.TaxRates.RateFor(l.TaxClass)
+		if rate <= 0 {
+			continue
+		}
+		taxable := l.Amount * (1 - discountRatio)
+		var tax float64
+		if cfg.PricesIncludeTax {
+			tax = taxable - taxable/(1+rate/100)
+		} else {
+			tax = taxable * rate / 100
+		}
+		out[i].Rate = rate
+		out[i].Tax = models.RoundAmount(tax, cfg.Currency())
+		total += out[i].Tax
+	}
+	return out, models.RoundAmount(total, cfg.Currency())
+}
+
+// taxBreakdown groups order items by class and rate for invoices. Taxable amount excludes VAT.
+func taxBreakdown(order *models.Order) []models.TaxLine {
+	if order == nil || order.TaxAmount == 0 {
+		return nil
+	}
+	discountRatio := 0.0
+	if order.SubTotal > 0 && order.DiscountAmount > 0 {
+		discountRatio = math.Min(order.DiscountAmount/order.SubTotal, 1)
+	}
+	type key struct {
+		class string
+		rate  float64
+	}
+	groups := map[key]*models.TaxLine{}
+	for _, it := range order.Items {
+		k := key{models.NormalizeTaxClass(it.TaxClass), it.TaxRate}
+		g, ok := groups[k]
+		if !ok {
+			g = &models.TaxLine{TaxClass: k.class, Rate: k.rate}
+			groups[k] = g
+		}
+		taxable := it.TotalPrice * (1 - discountRatio)
+		if order.TaxInclusive {
+			taxable -= it.TaxAmount
+		}
+		g.TaxableAmount += taxable
+		g.TaxAmount += it.TaxAmount
+	}
+	lines := make([]models.TaxLine, 0, len(groups))
+	for _, g := range groups {
+		g.TaxableAmount = models.RoundAmount(g.TaxableAmount, order.Currency)
+		g.TaxAmount = models.RoundAmount(g.TaxAmount, order.Currency)
+		lines = append(lines, *g)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Rate != lines[j].Rate {
+			return lines[i].Rate > lines[j].Rate
+		}
+		return lines[i].TaxClass < lines[j].TaxClass
+	})
+	return lines
+}
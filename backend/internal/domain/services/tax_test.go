@@ -0,0 +1,91 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+)
+
+func TestComputeOrderTax_Disabled(t *testing.T) {
+	cfg := &models.PharmacyConfig{TaxEnabled: false, TaxRates: models.TaxRatesMap{"standard": 13}}
+	lines, total := computeOrderTax(cfg, []taxableLine{{Amount: 100}}, 0)
+	if total != 0 || lines[0].Tax != 0 {
+		t.Errorf("expected no tax when disabled, got total=%v line=%v", total, lines[0].Tax)
+	}
+	if _, total := computeOrderTax(nil, []taxableLine{{Amount: 100}}, 0); total != 0 {
+		t.Errorf("expected no tax without config, got %v", total)
+	}
+}
+
+func TestComputeOrderTax_ExclusiveWithClassesAndDiscount(t *testing.T) {
+	cfg := &models.PharmacyConfig{TaxEnabled: true, TaxRates: models.TaxRatesMap{"standard": 13, "reduced": 5}}
+	lines := []taxableLine{
+		{TaxClass: "", Amount: 100},
+		{TaxClass: "Reduced", Amount: 100},
+		{TaxClass: "exempt", Amount: 100},
+	}
+	// 30 discount on 300 -> each line taxed on 90
+	out, total := computeOrderTax(cfg, lines, 30)
+	if out[0].Rate != 13 || out[0].Tax != 11.7 || out[0].TaxClass != "standard" {
+		t.Errorf("standard line: %+v", out[0])
+	}
+	if out[1].Rate != 5 || out[1].Tax != 4.5 || out[1].TaxClass != "reduced" {
+		t.Errorf("reduced line: %+v", out[1])
+	}
+	if out[2].Rate != 0 || out[2].Tax != 0 {
+		t.Errorf("exempt line: %+v", out[2])
+	}
+	if total != 16.2 {
+		t.Errorf("total = %v, want 16.2", total)
+	}
+}
+
+func TestComputeOrderTax_Inclusive(t *testing.T) {
+	cfg := &models.PharmacyConfig{TaxEnabled: true, PricesIncludeTax: true, TaxRates: models.TaxRatesMap{"standard": 13}}
+	out, total := computeOrderTax(cfg, []taxableLine{{Amount: 113}}, 0)
+	if out[0].Tax != 13 || total != 13 {
+		t.Errorf("expected 13 extracted from 113, got line=%v total=%v", out[0].Tax, total)
+	}
+}
+
+func TestTaxBreakdown_GroupsByClassAndRate(t *testing.T) {
+	order := &models.Order{
+		SubTotal:  300,
+		TaxAmount: 30,
+		Items: []models.OrderItem{
+			{TotalPrice: 100, TaxClass: "standard", TaxRate: 13, TaxAmount: 13},
+			{TotalPrice: 100, TaxClass: "standard", TaxRate: 13, TaxAmount: 13},
+			{TotalPrice: 100, TaxClass: "reduced", TaxRate: 4, TaxAmount: 4},
+		},
+	}
+	lines := taxBreakdown(order)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	if lines[0].TaxClass != "standard" || lines[0].TaxableAmount != 200 || lines[0].TaxAmount != 26 {
+		t.Errorf("standard group: %+v", lines[0])
+	}
+	if lines[1].TaxClass != "reduced" || lines[1].TaxAmount != 4 {
+		t.Errorf("reduced group: %+v", lines[1])
+	}
+}
+
+func TestComputeOrderTax_RoundsInBaseCurrency(t *testing.T) {
+	cfg := &models.PharmacyConfig{TaxEnabled: true, BaseCurrency: "JPY", TaxRates: models.TaxRatesMap{"standard": 10}}
+	out, total := computeOrderTax(cfg, []taxableLine{{Amount: 1234}, {Amount: 1234}}, 0)
+	if out[0].Tax != 123 || total != 246 {
+		t.Errorf("JPY tax: line=%v total=%v, want 123 and 246", out[0].Tax, total)
+	}
+}
+
+func TestRoundMoney_HalfAwayFromZero(t *testing.T) {
+	cases := map[float64]float64{1.005: 1.01, 2.675: 2.68, 0.125: 0.13, -1.005: -1.01, 10.004: 10}
+	for in, want := range cases {
+		if got := roundMoney(in); got != want {
+			t.Errorf("roundMoney(%v) = %v, want %v", in, got, want)
+		}
+	}
+	if got := models.RoundAmount(1.2345, "KWD"); got != 1.235 {
+		t.Errorf("KWD rounding = %v, want 1.235", got)
+	}
+}
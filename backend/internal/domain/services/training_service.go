@@ -0,0 +1,430 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const defaultQuizPassPercent = 80
+
+// isTeamRole reports whether the role is a pharmacy team member who is subject to training requirements:
+// manager, pharmacist or a custom role (admins and buyer accounts are not).
+func isTeamRole(role string) bool {
+	return role != "" && role != RoleAdmin && role != RoleStaff
+}
+
+type trainingService struct {
+	repo             outbound.TrainingRepository
+	announcementRepo outbound.AnnouncementRepository
+	ackRepo          outbound.AnnouncementAckRepository
+	userRepo         outbound.UserRepository
+	logger           *zap.Logger
+}
+
+func NewTrainingService(
+	repo outbound.TrainingRepository,
+	announcementRepo outbound.AnnouncementRepository,
+	ackRepo outbound.AnnouncementAckRepository,
+	userRepo outbound.UserRepository,
+	logger *zap.Logger,
+) inbound.TrainingService {
+	return &trainingService{
+		repo:             repo,
+		announcementRepo: announcementRepo,
+		ackRepo:          ackRepo,
+		userRepo:         userRepo,
+		logger:           logger,
+	}
+}
+
+func (s *trainingService) CreateSOP(ctx context.Context, pharmacyID, createdBy uuid.UUID, input inbound.SOPInput) (*models.SOPDocument, error) {
+	d := &models.SOPDocument{PharmacyID: pharmacyID, CreatedBy: createdBy, IsActive: true}
+	if err := applySOPInput(d, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateSOP(ctx, d); err != nil {
+		return nil, errors.ErrInternal("failed to create SOP document", err)
+	}
+	return d, nil
+}
+
+func (s *trainingService) GetSOP(ctx context.Context, pharmacyID, id uuid.UUID) (*models.SOPDocument, error) {
+	d, err := s.repo.GetSOPByID(ctx, id)
+	if err != nil || d == nil || d.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("SOP document")
+	}
+	return d, nil
+}
+
+func (s *trainingService) ListSOPs(ctx context.Context, pharmacyID uuid.UUID, activeOnly bool) ([]*models.SOPDocument, error) {
+	return s.repo.ListSOPs(ctx, pharmacyID, activeOnly)
+}
+
+func (s *trainingService) UpdateSOP(ctx context.Context, pharmacyID, id uuid.UUID, input inbound.SOPInput) (*models.SOPDocument, error) {
+	d, err := s.GetSOP(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applySOPInput(d, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateSOP(ctx, d); err != nil {
+		return nil, errors.ErrInternal("failed to update SOP document", err)
+	}
+	return d, nil
+}
+
+func (s *trainingService) DeleteSOP(ctx context.Context, pharmacyID, id uuid.UUID) error {
+	if _, err := s.GetSOP(ctx, pharmacyID, id); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteSOP(ctx, id); err != nil {
+		return errors.ErrInternal("failed to delete SOP document", err)
+	}
+	return nil
+}
+
+func applySOPInput(d *models.SOPDocument, input inbound.SOPInput) error {
+	if strings.TrimSpace(input.Title) == "" {
+		return errors.ErrValidation("title is required")
+	}
+	d.Title = strings.TrimSpace(input.Title)
+	d.Body = input.Body
+	d.FileURL = input.FileURL
+	d.Version = input.Version
+	if input.IsActive != nil {
+		d.IsActive = *input.IsActive
+	}
+	return nil
+}
+
+func (s *trainingService) AcknowledgeSOP(ctx context.Context, pharmacyID, userID, sopID uuid.UUID) error {
+	if _, err := s.GetSOP(ctx, pharmacyID, sopID); err != nil {
+		return err
+	}
+	quiz, err := s.repo.GetActiveQuizFor(ctx, nil, &sopID)
+	if err != nil {
+		return errors.ErrInternal("failed to load training quiz", err)
+	}
+	if quiz != nil {
+		passed, err := s.repo.HasPassed(ctx, quiz.ID, userID)
+		if err != nil {
+			return errors.ErrInternal("failed to check training quiz", err)
+		}
+		if !passed {
+			return errors.ErrValidation("pass the training quiz to acknowledge this SOP")
+		}
+	}
+	if err := s.repo.CreateSOPAck(ctx, &models.SOPAck{SOPDocumentID: sopID, UserID: userID, AcknowledgedAt: time.Now()}); err != nil {
+		return errors.ErrInternal("failed to acknowledge SOP", err)
+	}
+	return nil
+}
+
+func (s *trainingService) CreateQuiz(ctx context.Context, pharmacyID, createdBy uuid.UUID, input inbound.TrainingQuizInput) (*models.TrainingQuiz, error) {
+	q := &models.TrainingQuiz{PharmacyID: pharmacyID, CreatedBy: createdBy, IsActive: true}
+	if err := s.applyQuizInput(ctx, q, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateQuiz(ctx, q); err != nil {
+		return nil, errors.ErrInternal("failed to create training quiz", err)
+	}
+	return q, nil
+}
+
+func (s *trainingService) GetQuiz(ctx context.Context, pharmacyID, id uuid.UUID) (*models.TrainingQuiz, error) {
+	q, err := s.repo.GetQuizByID(ctx, id)
+	if err != nil || q == nil || q.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("training quiz")
+	}
+	return q, nil
+}
+
+func (s *trainingService) ListQuizzes(ctx context.Context, pharmacyID uuid.UUID) ([]*models.TrainingQuiz, error) {
+	return s.repo.ListQuizzes(ctx, pharmacyID, false)
+}
+
+// UpdateQuiz edits the quiz. Existing attempts keep their recorded result; changed questions apply to new attempts.
+func (s *trainingService) UpdateQuiz(ctx context.Context, pharmacyID, id uuid.UUID, input inbound.TrainingQuizInput) (*models.TrainingQuiz, error) {
+	q, err := s.GetQuiz(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyQuizInput(ctx, q, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateQuiz(ctx, q); err != nil {
+		return nil, errors.ErrInternal("failed to update training quiz", err)
+	}
+	return q, nil
+}
+
+func (s *trainingService) DeleteQuiz(ctx context.Context, pharmacyID, id uuid.UUID) error {
+	if _, err := s.GetQuiz(ctx, pharmacyID, id); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteQuiz(ctx, id); err != nil {
+		return errors.ErrInternal("failed to delete training quiz", err)
+	}
+	return nil
+}
+
+// applyQuizInput validates the target (exactly one announcement or SOP of this pharmacy, without another
+// active quiz) and the questions.
+func (s *trainingService) applyQuizInput(ctx context.Context, q *models.TrainingQuiz, input inbound.TrainingQuizInput) error {
+	if strings.TrimSpace(input.Title) == "" {
+		return errors.ErrValidation("title is required")
+	}
+	if (input.AnnouncementID == nil) == (input.SOPDocumentID == nil) {
+		return errors.ErrValidation("attach the quiz to exactly one of announcement_id or sop_document_id")
+	}
+	if input.AnnouncementID != nil {
+		a, err := s.announcementRepo.GetByID(ctx, *input.AnnouncementID)
+		if err != nil || a == nil || a.PharmacyID != q.PharmacyID {
+			return errors.ErrNotFound("announcement")
+		}
+	} else {
+		d, err := s.repo.GetSOPByID(ctx, *input.SOPDocumentID)
+		if err != nil || d == nil || d.PharmacyID != q.PharmacyID {
+			return errors.ErrNotFound("SOP document")
+		}
+	}
+	if len(input.Questions) == 0 {
+		return errors.ErrValidation("at least one question is required")
+	}
+	for i, qq := range input.Questions {
+		n := strconv.Itoa(i + 1)
+		if strings.TrimSpace(qq.Prompt) == "" {
+			return errors.ErrValidation("question " + n + " needs a prompt")
+		}
+		if len(qq.Options) < 2 {
+			return errors.ErrValidation("question " + n + " needs at least two options")
+		}
+		if qq.CorrectIndex < 0 || qq.CorrectIndex >= len(qq.Options) {
+			return errors.ErrValidation("question " + n + " has an invalid correct_index")
+		}
+	}
+	isActive := q.IsActive
+	if input.IsActive != nil {
+		isActive = *input.IsActive
+	}
+	if isActive {
+		existing, err := s.repo.GetActiveQuizFor(ctx, input.AnnouncementID, input.SOPDocumentID)
+		if err != nil {
+			return errors.ErrInternal("failed to check existing quiz", err)
+		}
+		if existing != nil && existing.ID != q.ID {
+			return errors.ErrConflict("an active quiz is already attached to this item")
+		}
+	}
+	q.AnnouncementID = input.AnnouncementID
+	q.SOPDocumentID = input.SOPDocumentID
+	q.Title = strings.TrimSpace(input.Title)
+	q.Questions = input.Questions
+	q.PassPercent = input.PassPercent
+	if q.PassPercent <= 0 {
+		q.PassPercent = defaultQuizPassPercent
+	}
+	q.IsActive = isActive
+	return nil
+}
+
+func (s *trainingService) GetQuizForUser(ctx context.Context, pharmacyID, userID, quizID uuid.UUID) (*inbound.TrainingQuizView, error) {
+	q, err := s.GetQuiz(ctx, pharmacyID, quizID)
+	if err != nil || !q.IsActive {
+		return nil, errors.ErrNotFound("training quiz")
+	}
+	passed, err := s.repo.HasPassed(ctx, q.ID, userID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to check training quiz", err)
+	}
+	view := &inbound.TrainingQuizView{
+		ID:             q.ID,
+		Title:          q.Title,
+		AnnouncementID: q.AnnouncementID,
+		SOPDocumentID:  q.SOPDocumentID,
+		PassPercent:    q.PassPercent,
+		Questions:      make([]inbound.TrainingQuizQuestionView, 0, len(q.Questions)),
+		Passed:         passed,
+	}
+	for _, qq := range q.Questions {
+		view.Questions = append(view.Questions, inbound.TrainingQuizQuestionView{Prompt: qq.Prompt, Options: qq.Options})
+	}
+	return view, nil
+}
+
+func (s *trainingService) SubmitAttempt(ctx context.Context, pharmacyID, userID, quizID uuid.UUID, answers []int) (*models.TrainingAttempt, error) {
+	q, err := s.GetQuiz(ctx, pharmacyID, quizID)
+	if err != nil || !q.IsActive {
+		return nil, errors.ErrNotFound("training quiz")
+	}
+	if len(answers) != len(q.Questions) {
+		return nil, errors.ErrValidation("answer every question (expected " + strconv.Itoa(len(q.Questions)) + " answers)")
+	}
+	attempt := scoreQuiz(q, answers)
+	attempt.UserID = userID
+	attempt.PharmacyID = pharmacyID
+	if err := s.repo.CreateAttempt(ctx, attempt); err != nil {
+		return nil, errors.ErrInternal("failed to record quiz attempt", err)
+	}
+	if attempt.Passed {
+		s.acknowledgeTarget(ctx, q, userID)
+	}
+	return attempt, nil
+}
+
+// scoreQuiz grades the answers against the quiz; the caller fills user and pharmacy.
+func scoreQuiz(q *models.TrainingQuiz, answers []int) *models.TrainingAttempt {
+	correct := 0
+	for i, qq := range q.Questions {
+		if i < len(answers) && answers[i] == qq.CorrectIndex {
+			correct++
+		}
+	}
+	total := len(q.Questions)
+	score := 0
+	if total > 0 {
+		score = correct * 100 / total
+	}
+	return &models.TrainingAttempt{
+		QuizID:       q.ID,
+		Answers:      answers,
+		Correct:      correct,
+		Total:        total,
+		ScorePercent: score,
+		Passed:       score >= q.PassPercent,
+	}
+}
+
+// acknowledgeTarget records the announcement/SOP acknowledgement after a passing attempt. Failures are
+// logged only: the pass is stored, so acknowledging again later succeeds.
+func (s *trainingService) acknowledgeTarget(ctx context.Context, q *models.TrainingQuiz, userID uuid.UUID) {
+	var err error
+	switch {
+	case q.AnnouncementID != nil:
+		var acked bool
+		if acked, err = s.ackRepo.HasAcked(ctx, userID, *q.AnnouncementID); err == nil && !acked {
+			err = s.ackRepo.Create(ctx, &models.AnnouncementAck{UserID: userID, AnnouncementID: q.AnnouncementID, AcknowledgedAt: time.Now()})
+		}
+	case q.SOPDocumentID != nil:
+		err = s.repo.CreateSOPAck(ctx, &models.SOPAck{SOPDocumentID: *q.SOPDocumentID, UserID: userID, AcknowledgedAt: time.Now()})
+	}
+	if err != nil {
+		s.logger.Warn("failed to acknowledge after passing quiz", zap.Error(err), zap.String("quiz_id", q.ID.String()))
+	}
+}
+
+func (s *trainingService) MyTraining(ctx context.Context, pharmacyID, userID uuid.UUID) (*inbound.StaffTrainingCompliance, error) {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || u == nil || u.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("user")
+	}
+	report, err := s.buildReport(ctx, pharmacyID, []*models.User{u})
+	if err != nil {
+		return nil, err
+	}
+	return report.Staff[0], nil
+}
+
+func (s *trainingService) Compliance(ctx context.Context, pharmacyID uuid.UUID) (*inbound.TrainingComplianceReport, error) {
+	users, err := s.userRepo.GetByPharmacyID(ctx, pharmacyID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load team", err)
+	}
+	team := make([]*models.User, 0, len(users))
+	for _, u := range users {
+		if u.IsActive && isTeamRole(u.Role) {
+			team = append(team, u)
+		}
+	}
+	return s.buildReport(ctx, pharmacyID, team)
+}
+
+// buildReport lists active quizzes plus active SOPs without a quiz as required items, and each user's status on them.
+func (s *trainingService) buildReport(ctx context.Context, pharmacyID uuid.UUID, users []*models.User) (*inbound.TrainingComplianceReport, error) {
+	quizzes, err := s.repo.ListQuizzes(ctx, pharmacyID, true)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load training quizzes", err)
+	}
+	sops, err := s.repo.ListSOPs(ctx, pharmacyID, true)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load SOP documents", err)
+	}
+	report := &inbound.TrainingComplianceReport{Items: []*inbound.TrainingItem{}, Staff: []*inbound.StaffTrainingCompliance{}}
+	quizIDs := make([]uuid.UUID, 0, len(quizzes))
+	quizzedSOPs := map[uuid.UUID]bool{}
+	for _, q := range quizzes {
+		quizIDs = append(quizIDs, q.ID)
+		if q.SOPDocumentID != nil {
+			quizzedSOPs[*q.SOPDocumentID] = true
+		}
+		report.Items = append(report.Items, &inbound.TrainingItem{Kind: "quiz", ID: q.ID, Title: q.Title, AnnouncementID: q.AnnouncementID, SOPDocumentID: q.SOPDocumentID})
+	}
+	sopIDs := make([]uuid.UUID, 0, len(sops))
+	for _, d := range sops {
+		if quizzedSOPs[d.ID] {
+			continue
+		}
+		sopIDs = append(sopIDs, d.ID)
+		id := d.ID
+		report.Items = append(report.Items, &inbound.TrainingItem{Kind: "sop", ID: d.ID, Title: d.Title, SOPDocumentID: &id})
+	}
+	stats, err := s.repo.AttemptStats(ctx, quizIDs)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load quiz attempts", err)
+	}
+	acks, err := s.repo.ListSOPAcks(ctx, sopIDs)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load SOP acknowledgements", err)
+	}
+	type key struct{ item, user uuid.UUID }
+	statBy := make(map[key]*models.TrainingAttemptStat, len(stats))
+	for _, st := range stats {
+		statBy[key{st.QuizID, st.UserID}] = st
+	}
+	ackBy := make(map[key]*models.SOPAck, len(acks))
+	for _, a := range acks {
+		ackBy[key{a.SOPDocumentID, a.UserID}] = a
+	}
+	for _, u := range users {
+		row := &inbound.StaffTrainingCompliance{UserID: u.ID, Name: u.Name, Email: u.Email, Role: u.Role, Required: len(report.Items)}
+		for _, it := range report.Items {
+			st := &inbound.TrainingItemStatus{ItemID: it.ID, Status: models.TrainingStatusNotStarted}
+			if it.Kind == "quiz" {
+				if a, ok := statBy[key{it.ID, u.ID}]; ok {
+					st.Attempts = a.Attempts
+					st.BestScore = a.BestScore
+					last := a.LastAttemptAt
+					st.LastActivity = &last
+					st.Status = models.TrainingStatusFailed
+					if a.Passed {
+						st.Status = models.TrainingStatusPassed
+					}
+				}
+			} else if a, ok := ackBy[key{it.ID, u.ID}]; ok {
+				at := a.AcknowledgedAt
+				st.LastActivity = &at
+				st.Status = models.TrainingStatusAcknowledged
+			}
+			if st.Status == models.TrainingStatusPassed || st.Status == models.TrainingStatusAcknowledged {
+				row.Completed++
+			}
+			row.Items = append(row.Items, st)
+		}
+		row.Compliant = row.Completed == row.Required
+		if row.Compliant {
+			report.CompliantCount++
+		}
+		report.Staff = append(report.Staff, row)
+	}
+	return report, nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestScoreQuiz_PassThreshold(t *testing.T) {
+	q := &models.TrainingQuiz{
+		ID:          uuid.New(),
+		PassPercent: 75,
+		Questions: []models.QuizQuestion{
+			{Prompt: "a", Options: []string{"x", "y"}, CorrectIndex: 0},
+			{Prompt: "b", Options: []string{"x", "y"}, CorrectIndex: 1},
+			{Prompt: "c", Options: []string{"x", "y"}, CorrectIndex: 1},
+			{Prompt: "d", Options: []string{"x", "y"}, CorrectIndex: 0},
+		},
+	}
+	if a := scoreQuiz(q, []int{0, 1, 1, 1}); a.Correct != 3 || a.ScorePercent != 75 || !a.Passed {
+		t.Errorf("3/4 at 75%% should pass: %+v", a)
+	}
+	if a := scoreQuiz(q, []int{0, 1, 0, 1}); a.ScorePercent != 50 || a.Passed {
+		t.Errorf("2/4 at 75%% should fail: %+v", a)
+	}
+}
+
+func TestTrainingService_AcknowledgeSOP_RequiresPassedQuiz(t *testing.T) {
+	ctx := context.Background()
+	pharmacyID, userID, sopID := uuid.New(), uuid.New(), uuid.New()
+	passed := false
+	acked := false
+	repo := &mocks.MockTrainingRepository{
+		GetSOPByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.SOPDocument, error) {
+			return &models.SOPDocument{ID: id, PharmacyID: pharmacyID, IsActive: true}, nil
+		},
+		GetActiveQuizForFunc: func(ctx context.Context, announcementID, sopID *uuid.UUID) (*models.TrainingQuiz, error) {
+			return &models.TrainingQuiz{ID: uuid.New(), SOPDocumentID: sopID, IsActive: true}, nil
+		},
+		HasPassedFunc: func(ctx context.Context, quizID, uid uuid.UUID) (bool, error) { return passed, nil },
+		CreateSOPAckFunc: func(ctx context.Context, a *models.SOPAck) error {
+			acked = true
+			return nil
+		},
+	}
+	svc := NewTrainingService(repo, nil, nil, nil, zap.NewNop())
+
+	err := svc.AcknowledgeSOP(ctx, pharmacyID, userID, sopID)
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("expected validation error before passing the quiz, got %v", err)
+	}
+	if acked {
+		t.Fatal("SOP must not be acknowledged before the quiz is passed")
+	}
+	passed = true
+	if err := svc.AcknowledgeSOP(ctx, pharmacyID, userID, sopID); err != nil {
+		t.Fatalf("unexpected error after passing: %v", err)
+	}
+	if !acked {
+		t.Error("expected SOP acknowledgement after passing the quiz")
+	}
+}
+
+func TestTrainingService_CreateQuiz_RequiresSingleTarget(t *testing.T) {
+	svc := NewTrainingService(&mocks.MockTrainingRepository{}, nil, nil, nil, zap.NewNop())
+	id1, id2 := uuid.New(), uuid.New()
+	_, err := svc.CreateQuiz(context.Background(), uuid.New(), uuid.New(), inbound.TrainingQuizInput{
+		AnnouncementID: &id1,
+		SOPDocumentID:  &id2,
+		Title:          "Cold chain",
+		Questions:      []models.QuizQuestion{{Prompt: "Fridge range?", Options: []string{"2-8C", "10-15C"}}},
+	})
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("expected validation error for two targets, got %v", err)
+	}
+}
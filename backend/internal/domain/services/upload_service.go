@@ -0,0 +1,432 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// uploadSniffLen is how much of a file content type sniffing looks at (as http.DetectContentType).
+	uploadSniffLen = 512
+	// uploadExpireBatch bounds the uploads one ExpireStale run drops.
+	uploadExpireBatch = 500
+
+	docxContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+)
+
+// oleSignature starts legacy Office files (.doc, .xls, .ppt).
+var oleSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// uploadExtensions is the extension stored files get for each accepted content type, whatever the client named them.
+var uploadExtensions = map[string]string{
+	"image/jpeg": ".jpg", "image/png": ".png", "image/gif": ".gif", "image/webp": ".webp", "image/svg+xml": ".svg",
+	"application/pdf": ".pdf", "application/msword": ".doc", docxContentType: ".docx",
+	"video/mp4": ".mp4", "video/webm": ".webm", "video/quicktime": ".mov",
+}
+
+type uploadService struct {
+	repo    outbound.UploadRepository
+	staging outbound.UploadStaging
+	storage outbound.FileStorage
+	private outbound.PrivateFileStorage
+	scanner outbound.VirusScanner
+	quota   int64
+	logger  *zap.Logger
+	now     func() time.Time
+}
+
+// NewUploadService builds the upload service. Purposes with private rules (CVs, prescriptions, return
+// evidence) are stored in private. scanner may be nil (files are stored unscanned); quota is the bytes each
+// pharmacy may store, 0 for no limit.
+func NewUploadService(repo outbound.UploadRepository, staging outbound.UploadStaging, storage outbound.FileStorage, private outbound.PrivateFileStorage, scanner outbound.VirusScanner, quota int64, logger *zap.Logger) inbound.UploadService {
+	return &uploadService{repo: repo, staging: staging, storage: storage, private: private, scanner: scanner, quota: quota, logger: logger, now: time.Now}
+}
+
+func (s *uploadService) Upload(ctx context.Context, pharmacyID, uploadedBy uuid.UUID, purpose, filename string, data []byte) (*models.Upload, error) {
+	purpose, rule, err := uploadRule(purpose)
+	if err != nil {
+		return nil, err
+	}
+	size := int64(len(data))
+	if err := checkUploadSize(rule, size); err != nil {
+		return nil, err
+	}
+	if err := s.reserve(ctx, pharmacyID, size); err != nil {
+		return nil, err
+	}
+	u := &models.Upload{PharmacyID: pharmacyID, UploadedBy: uploadedBy, Purpose: purpose, Filename: uploadFilename(filename),
+		Size: size, Received: size, Status: models.UploadStatusPending, ExpiresAt: s.now()}
+	contentType, err := sniffAllowed(rule, u.Filename, data)
+	if err != nil {
+		return nil, err
+	}
+	if u.ScanResult, err = s.scan(ctx, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if u.ScanResult != "clean" && u.ScanResult != "not_scanned" {
+		s.logger.Warn("upload rejected by virus scan", zap.String("pharmacy_id", pharmacyID.String()), zap.String("signature", u.ScanResult))
+		return nil, errors.ErrValidation("file rejected: malware detected")
+	}
+	if err := s.store(ctx, u, rule, contentType, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, u); err != nil {
+		return nil, errors.ErrInternal("failed to record upload", err)
+	}
+	return s.withPreview(ctx, u), nil
+}
+
+func (s *uploadService) StartUpload(ctx context.Context, pharmacyID, uploadedBy uuid.UUID, in inbound.StartUploadInput) (*models.Upload, error) {
+	purpose, rule, err := uploadRule(in.Purpose)
+	if err != nil {
+		return nil, err
+	}
+	name := uploadFilename(in.Filename)
+	if name == "" {
+		return nil, errors.ErrValidation("filename is required")
+	}
+	if err := checkUploadSize(rule, in.Size); err != nil {
+		return nil, err
+	}
+	// Fail early on a declared type the purpose never accepts; the bytes are checked again on completion.
+	declared, _, _ := mime.ParseMediaType(in.ContentType)
+	if declared != "" && declared != "application/octet-stream" && !slices.Contains(rule.Types, declared) {
+		return nil, errors.ErrValidation(fmt.Sprintf("file type %s is not allowed for %s uploads", declared, purpose))
+	}
+	if err := s.reserve(ctx, pharmacyID, in.Size); err != nil {
+		return nil, err
+	}
+	u := &models.Upload{PharmacyID: pharmacyID, UploadedBy: uploadedBy, Purpose: purpose, Filename: name, ContentType: declared,
+		Size: in.Size, Status: models.UploadStatusPending, ExpiresAt: s.now().Add(models.UploadSessionTTL)}
+	if err := s.repo.Create(ctx, u); err != nil {
+		return nil, errors.ErrInternal("failed to start upload", err)
+	}
+	return u, nil
+}
+
+func (s *uploadService) UploadChunk(ctx context.Context, pharmacyID, uploadedBy, id uuid.UUID, offset int64, body io.Reader) (*models.Upload, error) {
+	u, err := s.owned(ctx, pharmacyID, uploadedBy, id)
+	if err != nil {
+		return nil, err
+	}
+	if u.Status == models.UploadStatusCompleted && offset == u.Size {
+		return s.withPreview(ctx, u), nil // the response to the last chunk was lost; the client retries it
+	}
+	if u.Status != models.UploadStatusPending {
+		return nil, errors.ErrConflict("upload is " + u.Status)
+	}
+	if !s.now().Before(u.ExpiresAt) {
+		return nil, errors.ErrConflict("upload expired")
+	}
+	if offset != u.Received {
+		return nil, errors.ErrConflict(fmt.Sprintf("offset %d does not match the %d bytes received", offset, u.Received))
+	}
+	remaining := u.Size - offset
+	n, err := s.staging.WriteAt(ctx, id.String(), offset, io.LimitReader(body, remaining+1))
+	if err != nil {
+		return nil, errors.ErrInternal("failed to store chunk", err)
+	}
+	if n > remaining {
+		return nil, errors.ErrValidation(fmt.Sprintf("chunk runs past the declared size of %d bytes", u.Size))
+	}
+	if n > 0 {
+		ok, err := s.repo.Advance(ctx, id, offset, offset+n)
+		if err != nil {
+			return nil, errors.ErrInternal("failed to record chunk", err)
+		}
+		if !ok {
+			return nil, errors.ErrConflict("upload was changed by another request")
+		}
+		u.Received = offset + n
+	}
+	if u.Received < u.Size {
+		return u, nil
+	}
+	return s.complete(ctx, u)
+}
+
+func (s *uploadService) GetUpload(ctx context.Context, pharmacyID, uploadedBy, id uuid.UUID) (*models.Upload, error) {
+	u, err := s.owned(ctx, pharmacyID, uploadedBy, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.withPreview(ctx, u), nil
+}
+
+func (s *uploadService) CancelUpload(ctx context.Context, pharmacyID, uploadedBy, id uuid.UUID) error {
+	u, err := s.owned(ctx, pharmacyID, uploadedBy, id)
+	if err != nil {
+		return err
+	}
+	if u.Status == models.UploadStatusCompleted {
+		return errors.ErrConflict("upload is already complete")
+	}
+	if err := s.staging.Remove(ctx, id.String()); err != nil {
+		return errors.ErrInternal("failed to drop upload chunks", err)
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return errors.ErrInternal("failed to cancel upload", err)
+	}
+	return nil
+}
+
+func (s *uploadService) ExpireStale(ctx context.Context) error {
+	list, err := s.repo.ListExpired(ctx, s.now(), uploadExpireBatch)
+	if err != nil {
+		return fmt.Errorf("list expired uploads: %w", err)
+	}
+	failed := 0
+	for _, u := range list {
+		if err := s.staging.Remove(ctx, u.ID.String()); err != nil {
+			failed++
+			s.logger.Warn("failed to drop expired upload", zap.String("upload_id", u.ID.String()), zap.Error(err))
+			continue
+		}
+		u.Status = models.UploadStatusExpired
+		if err := s.repo.Update(ctx, u); err != nil {
+			failed++
+			s.logger.Warn("failed to expire upload", zap.String("upload_id", u.ID.String()), zap.Error(err))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to expire %d of %d uploads", failed, len(list))
+	}
+	return nil
+}
+
+// complete checks the fully received file and moves it from staging to file storage. Rejected files are
+// dropped; a scanner or storage failure leaves the upload pending so the last chunk can be retried.
+func (s *uploadService) complete(ctx context.Context, u *models.Upload) (*models.Upload, error) {
+	_, rule, err := uploadRule(u.Purpose)
+	if err != nil {
+		return nil, err
+	}
+	var head []byte
+	if err := s.withStaged(ctx, u.ID, func(r io.Reader) (err error) {
+		head, err = io.ReadAll(io.LimitReader(r, uploadSniffLen))
+		return err
+	}); err != nil {
+		return nil, errors.ErrInternal("failed to read upload", err)
+	}
+	contentType, err := sniffAllowed(rule, u.Filename, head)
+	if err != nil {
+		s.reject(ctx, u, "")
+		return nil, err
+	}
+	if err := s.withStaged(ctx, u.ID, func(r io.Reader) (err error) {
+		u.ScanResult, err = s.scan(ctx, r)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if u.ScanResult != "clean" && u.ScanResult != "not_scanned" {
+		s.logger.Warn("upload rejected by virus scan", zap.String("upload_id", u.ID.String()), zap.String("signature", u.ScanResult))
+		s.reject(ctx, u, u.ScanResult)
+		return nil, errors.ErrValidation("file rejected: malware detected")
+	}
+	if err := s.withStaged(ctx, u.ID, func(r io.Reader) error {
+		return s.store(ctx, u, rule, contentType, r)
+	}); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, u); err != nil {
+		return nil, errors.ErrInternal("failed to record upload", err)
+	}
+	if err := s.staging.Remove(ctx, u.ID.String()); err != nil {
+		s.logger.Warn("failed to drop upload chunks", zap.String("upload_id", u.ID.String()), zap.Error(err))
+	}
+	return s.withPreview(ctx, u), nil
+}
+
+// withStaged opens the upload's staged file for fn.
+func (s *uploadService) withStaged(ctx context.Context, id uuid.UUID, fn func(io.Reader) error) error {
+	f, err := s.staging.Open(ctx, id.String())
+	if err != nil {
+		return errors.ErrInternal("failed to read upload", err)
+	}
+	defer f.Close()
+	return fn(f)
+}
+
+// store saves the file under photos/, videos/ or files/<yyyy/mm>/<id><ext>, in private storage when the rule
+// says so, and marks the upload completed.
+func (s *uploadService) store(ctx context.Context, u *models.Upload, rule models.UploadRule, contentType string, body io.Reader) error {
+	dir := "files"
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		dir = "photos"
+	case strings.HasPrefix(contentType, "video/"):
+		dir = "videos"
+	}
+	if u.ID == uuid.Nil {
+		u.ID = uuid.New()
+	}
+	now := s.now()
+	p := dir + "/" + now.Format("2006/01") + "/" + u.ID.String() + uploadExtensions[contentType]
+	save := s.storage.Save
+	if rule.Private {
+		save = s.private.SavePrivate
+	}
+	url, err := save(ctx, p, body, contentType)
+	if err != nil {
+		return errors.ErrInternal("failed to store file", err)
+	}
+	u.Path, u.URL, u.ContentType = p, url, contentType
+	u.Status, u.CompletedAt = models.UploadStatusCompleted, &now
+	return nil
+}
+
+// withPreview sets the signed link to a completed private upload.
+func (s *uploadService) withPreview(ctx context.Context, u *models.Upload) *models.Upload {
+	if u.Status != models.UploadStatusCompleted || !strings.HasPrefix(u.URL, outbound.PrivateFileRefPrefix) {
+		return u
+	}
+	preview, err := s.private.SignedURL(ctx, u.URL)
+	if err != nil {
+		s.logger.Warn("failed to sign upload preview", zap.String("upload_id", u.ID.String()), zap.Error(err))
+		return u
+	}
+	u.PreviewURL = preview
+	return u
+}
+
+// reject marks the upload rejected, which releases its quota, and drops its chunks.
+func (s *uploadService) reject(ctx context.Context, u *models.Upload, signature string) {
+	u.Status, u.ScanResult = models.UploadStatusRejected, signature
+	if err := s.repo.Update(ctx, u); err != nil {
+		s.logger.Warn("failed to reject upload", zap.String("upload_id", u.ID.String()), zap.Error(err))
+	}
+	if err := s.staging.Remove(ctx, u.ID.String()); err != nil {
+		s.logger.Warn("failed to drop upload chunks", zap.String("upload_id", u.ID.String()), zap.Error(err))
+	}
+}
+
+// scan returns "clean", "not_scanned" when no scanner is configured, or the detected signature. A scanner
+// failure fails the upload rather than storing an unchecked file.
+func (s *uploadService) scan(ctx context.Context, r io.Reader) (string, error) {
+	if s.scanner == nil {
+		return "not_scanned", nil
+	}
+	signature, err := s.scanner.Scan(ctx, r)
+	if err != nil {
+		s.logger.Error("virus scan failed", zap.Error(err))
+		return "", errors.ErrInternal("virus scan unavailable, try again later", err)
+	}
+	if signature == "" {
+		return "clean", nil
+	}
+	return signature, nil
+}
+
+// reserve fails when size more bytes would take the pharmacy over its quota.
+func (s *uploadService) reserve(ctx context.Context, pharmacyID uuid.UUID, size int64) error {
+	if s.quota <= 0 {
+		return nil
+	}
+	used, err := s.repo.UsageBytes(ctx, pharmacyID)
+	if err != nil {
+		return errors.ErrInternal("failed to read storage usage", err)
+	}
+	if used+size > s.quota {
+		return errors.ErrTooManyRequests(fmt.Sprintf("storage quota reached (%d of %d MB used)", used>>20, s.quota>>20))
+	}
+	return nil
+}
+
+func (s *uploadService) owned(ctx context.Context, pharmacyID, uploadedBy, id uuid.UUID) (*models.Upload, error) {
+	u, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load upload", err)
+	}
+	if u == nil || u.PharmacyID != pharmacyID || u.UploadedBy != uploadedBy {
+		return nil, errors.ErrNotFound("upload")
+	}
+	return u, nil
+}
+
+func uploadRule(purpose string) (string, models.UploadRule, error) {
+	if purpose == "" {
+		purpose = models.UploadPurposeFile
+	}
+	rule, ok := models.UploadRules[purpose]
+	if !ok {
+		return "", rule, errors.ErrValidation("unknown upload purpose " + purpose)
+	}
+	return purpose, rule, nil
+}
+
+func checkUploadSize(rule models.UploadRule, size int64) error {
+	if size <= 0 {
+		return errors.ErrValidation("file is empty")
+	}
+	if size > rule.MaxSize {
+		return errors.ErrValidation(fmt.Sprintf("file too large (max %dMB)", rule.MaxSize>>20))
+	}
+	return nil
+}
+
+// uploadFilename keeps the base name the client sent, for display only; stored paths never use it.
+func uploadFilename(name string) string {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "." || name == "/" {
+		return ""
+	}
+	if r := []rune(name); len(r) > 255 {
+		name = string(r[:255])
+	}
+	return name
+}
+
+// sniffAllowed detects the content type from the file's first bytes and checks it against the rule.
+func sniffAllowed(rule models.UploadRule, filename string, data []byte) (string, error) {
+	ct := sniffContentType(filename, data[:min(len(data), uploadSniffLen)])
+	if !slices.Contains(rule.Types, ct) {
+		return "", errors.ErrValidation(fmt.Sprintf("file content (%s) is not an allowed type", ct))
+	}
+	return ct, nil
+}
+
+// sniffContentType identifies a file from its first bytes, adding the video containers and Word formats
+// http.DetectContentType does not tell apart. Office formats need the file name: a .docx is a zip archive and
+// a .doc shares its signature with other legacy Office files.
+func sniffContentType(filename string, head []byte) string {
+	ext := strings.ToLower(path.Ext(filename))
+	switch {
+	case len(head) >= 12 && string(head[4:8]) == "ftyp":
+		switch string(head[8:12]) {
+		case "qt  ":
+			return "video/quicktime"
+		case "heic", "heix", "mif1", "msf1", "avif":
+			return "image/heif"
+		}
+		return "video/mp4"
+	case bytes.HasPrefix(head, oleSignature):
+		if ext == ".doc" {
+			return "application/msword"
+		}
+		return "application/x-ole-storage"
+	}
+	ct, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	switch {
+	case ct == "application/zip" && ext == ".docx":
+		return docxContentType
+	case (ct == "text/xml" || ct == "text/plain") && bytes.Contains(head, []byte("<svg")):
+		return "image/svg+xml"
+	}
+	return ct
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// memoryStaging keeps staged chunks in memory.
+type memoryStaging struct{ files map[string][]byte }
+
+func (m *memoryStaging) WriteAt(ctx context.Context, id string, offset int64, body io.Reader) (int64, error) {
+	data, err := io.ReadAll(body)
+	m.files[id] = append(m.files[id][:offset], data...)
+	return int64(len(data)), err
+}
+
+func (m *memoryStaging) Open(ctx context.Context, id string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(m.files[id])), nil
+}
+
+func (m *memoryStaging) Remove(ctx context.Context, id string) error {
+	delete(m.files, id)
+	return nil
+}
+
+// SavePrivate records private files under private/ so they are told apart from public ones.
+func (m *memoryStorage) SavePrivate(ctx context.Context, path string, body io.Reader, contentType string) (string, error) {
+	n, _ := io.Copy(io.Discard, body)
+	m.files["private/"+path] = int(n)
+	return outbound.PrivateFileRefPrefix + path, nil
+}
+
+func (m *memoryStorage) SignedURL(ctx context.Context, ref string) (string, error) {
+	if path, ok := strings.CutPrefix(ref, outbound.PrivateFileRefPrefix); ok {
+		return "/signed/" + path + "?token=t", nil
+	}
+	return ref, nil
+}
+
+func (m *memoryStorage) PrivateRef(url string) (string, bool) { return "", false }
+
+// signatureScanner flags content containing its marker.
+type signatureScanner struct{ marker string }
+
+func (s signatureScanner) Scan(ctx context.Context, r io.Reader) (string, error) {
+	data, _ := io.ReadAll(r)
+	if bytes.Contains(data, []byte(s.marker)) {
+		return "Eicar-Test-Signature", nil
+	}
+	return "", nil
+}
+
+func newUploadFixture(quota int64) (*uploadService, map[uuid.UUID]*models.Upload, *memoryStaging, *memoryStorage) {
+	uploads := map[uuid.UUID]*models.Upload{}
+	repo := &mocks.MockUploadRepository{
+		CreateFunc: func(ctx context.Context, u *models.Upload) error {
+			if u.ID == uuid.Nil {
+				u.ID = uuid.New()
+			}
+			uploads[u.ID] = u
+			return nil
+		},
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Upload, error) {
+			if u := uploads[id]; u != nil {
+				cp := *u
+				return &cp, nil
+			}
+			return nil, nil
+		},
+		UpdateFunc: func(ctx context.Context, u *models.Upload) error {
+			uploads[u.ID] = u
+			return nil
+		},
+		AdvanceFunc: func(ctx context.Context, id uuid.UUID, from, to int64) (bool, error) {
+			if u := uploads[id]; u != nil && u.Received == from {
+				u.Received = to
+				return true, nil
+			}
+			return false, nil
+		},
+		UsageBytesFunc: func(ctx context.Context, pharmacyID uuid.UUID) (int64, error) {
+			var total int64
+			for _, u := range uploads {
+				if u.PharmacyID == pharmacyID && (u.Status == models.UploadStatusPending || u.Status == models.UploadStatusCompleted) {
+					total += u.Size
+				}
+			}
+			return total, nil
+		},
+	}
+	staging := &memoryStaging{files: map[string][]byte{}}
+	storage := &memoryStorage{files: map[string]int{}}
+	svc := NewUploadService(repo, staging, storage, storage, signatureScanner{marker: "EICAR"}, quota, zap.NewNop()).(*uploadService)
+	return svc, uploads, staging, storage
+}
+
+func TestUploadService_SniffsSingleUploads(t *testing.T) {
+	svc, _, _, storage := newUploadFixture(0)
+	ctx := context.Background()
+	pharmacyID, userID := uuid.New(), uuid.New()
+
+	u, err := svc.Upload(ctx, pharmacyID, userID, "", "photo.exe", pngOfSize(t, 4, 4).Bytes())
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if u.ContentType != "image/png" || !strings.HasPrefix(u.Path, "photos/") || !strings.HasSuffix(u.Path, ".png") || u.ScanResult != "clean" {
+		t.Errorf("upload = %+v, want a clean png under photos/", u)
+	}
+	if _, err := svc.Upload(ctx, pharmacyID, userID, "", "invoice.pdf", []byte("<html><script>alert(1)</script></html>")); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeValidation {
+		t.Errorf("html named .pdf: err = %v, want validation", err)
+	}
+	if _, err := svc.Upload(ctx, pharmacyID, userID, models.UploadPurposeCV, "cv.pdf", []byte("%PDF-1.7 EICAR")); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeValidation {
+		t.Errorf("infected pdf: err = %v, want validation", err)
+	}
+	if len(storage.files) != 1 {
+		t.Errorf("stored %d files, want only the png", len(storage.files))
+	}
+}
+
+func TestUploadService_ResumableUploadAndQuota(t *testing.T) {
+	svc, uploads, staging, storage := newUploadFixture(64)
+	ctx := context.Background()
+	pharmacyID, userID := uuid.New(), uuid.New()
+	video := append([]byte("\x00\x00\x00\x18ftypmp42"), bytes.Repeat([]byte{0x01}, 28)...) // 40 bytes
+
+	u, err := svc.StartUpload(ctx, pharmacyID, userID, inbound.StartUploadInput{Filename: "unboxing.mp4", ContentType: "video/mp4", Size: 40, Purpose: models.UploadPurposeReturnVideo})
+	if err != nil {
+		t.Fatalf("StartUpload: %v", err)
+	}
+	if _, err := svc.StartUpload(ctx, pharmacyID, userID, inbound.StartUploadInput{Filename: "b.mp4", Size: 40, Purpose: models.UploadPurposeReturnVideo}); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeTooManyRequests {
+		t.Errorf("over quota: err = %v, want too many requests", err)
+	}
+	if _, err := svc.UploadChunk(ctx, pharmacyID, uuid.New(), u.ID, 0, bytes.NewReader(video[:16])); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeNotFound {
+		t.Errorf("someone else's upload: err = %v, want not found", err)
+	}
+
+	got, err := svc.UploadChunk(ctx, pharmacyID, userID, u.ID, 0, bytes.NewReader(video[:16]))
+	if err != nil || got.Received != 16 || got.Status != models.UploadStatusPending {
+		t.Fatalf("first chunk: %+v, %v", got, err)
+	}
+	if _, err := svc.UploadChunk(ctx, pharmacyID, userID, u.ID, 8, bytes.NewReader(video[8:])); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeConflict {
+		t.Errorf("wrong offset: err = %v, want conflict", err)
+	}
+	got, err = svc.UploadChunk(ctx, pharmacyID, userID, u.ID, 16, bytes.NewReader(video[16:]))
+	if err != nil {
+		t.Fatalf("last chunk: %v", err)
+	}
+	if got.Status != models.UploadStatusCompleted || got.ContentType != "video/mp4" || !strings.HasPrefix(got.Path, "videos/") || storage.files["private/"+got.Path] != 40 {
+		t.Errorf("completed upload = %+v, stored %v", got, storage.files)
+	}
+	// Return evidence is private: the upload keeps a reference and hands out a signed link.
+	if got.URL != outbound.PrivateFileRefPrefix+got.Path || got.PreviewURL != "/signed/"+got.Path+"?token=t" {
+		t.Errorf("url = %q, preview = %q; want a private reference and a signed link", got.URL, got.PreviewURL)
+	}
+	if len(staging.files) != 0 {
+		t.Error("staged chunks were not dropped")
+	}
+
+	// The quota freed by a rejected upload can be used again.
+	svc.quota = 100
+	bad, err := svc.StartUpload(ctx, pharmacyID, userID, inbound.StartUploadInput{Filename: "clip.mp4", Size: 12, Purpose: models.UploadPurposeChatVideo})
+	if err != nil {
+		t.Fatalf("StartUpload: %v", err)
+	}
+	if _, err := svc.UploadChunk(ctx, pharmacyID, userID, bad.ID, 0, strings.NewReader("MZ\x90\x00EICAR...")); errors.GetAppError(err) == nil {
+		t.Error("an executable posing as a video was accepted")
+	}
+	if uploads[bad.ID].Status != models.UploadStatusRejected {
+		t.Errorf("status = %s, want rejected", uploads[bad.ID].Status)
+	}
+
+	svc.now = func() time.Time { return time.Now().Add(models.UploadSessionTTL + time.Minute) }
+	late, _ := svc.StartUpload(ctx, pharmacyID, userID, inbound.StartUploadInput{Filename: "late.mp4", Size: 10, Purpose: models.UploadPurposeChatVideo})
+	svc.now = func() time.Time { return time.Now().Add(2*models.UploadSessionTTL + time.Hour) }
+	if _, err := svc.UploadChunk(ctx, pharmacyID, userID, late.ID, 0, strings.NewReader("0123456789")); errors.GetAppError(err) == nil || errors.GetAppError(err).Code != errors.ErrCodeConflict {
+		t.Errorf("expired upload: err = %v, want conflict", err)
+	}
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
@@ -18,6 +19,17 @@ func applyPharmacistProfile(u *models.User, p *inbound.PharmacistProfileInput) {
 	if p.LicenseNumber != nil {
 		u.LicenseNumber = *p.LicenseNumber
 	}
+	if p.LicenseExpiresAt != nil {
+		var expires *time.Time
+		if !p.LicenseExpiresAt.IsZero() {
+			t := *p.LicenseExpiresAt
+			expires = &t
+		}
+		if !sameDay(u.LicenseExpiresAt, expires) {
+			u.LicenseReminderDays = nil
+		}
+		u.LicenseExpiresAt = expires
+	}
 	if p.Qualification != nil {
 		u.Qualification = *p.Qualification
 	}
@@ -39,20 +51,26 @@ func applyPharmacistProfile(u *models.User, p *inbound.PharmacistProfileInput) {
 }
 
 const (
-	RoleAdmin      = "admin"
-	RoleManager    = "manager"
-	RolePharmacist = "pharmacist"
-	RoleStaff      = "staff"
+	RoleAdmin      = models.RoleAdmin
+	RoleManager    = models.RoleManager
+	RolePharmacist = models.RolePharmacist
+	RoleStaff      = models.RoleStaff
 )
 
+// Only admins manage every account. Other actors allowed to manage users (manager, or a custom role with
+// users.manage) are limited to pharmacists.
 type userService struct {
 	userRepo     outbound.UserRepository
 	pharmacyRepo outbound.PharmacyRepository
+	configRepo   outbound.PharmacyConfigRepository
+	roles        inbound.RoleService
+	mailer       inbound.MailerService
 	logger       *zap.Logger
 }
 
-func NewUserService(userRepo outbound.UserRepository, pharmacyRepo outbound.PharmacyRepository, logger *zap.Logger) inbound.UserService {
-	return &userService{userRepo: userRepo, pharmacyRepo: pharmacyRepo, logger: logger}
+// NewUserService builds the user service; configRepo supplies the security policy new passwords must meet.
+func NewUserService(userRepo outbound.UserRepository, pharmacyRepo outbound.PharmacyRepository, configRepo outbound.PharmacyConfigRepository, roles inbound.RoleService, mailer inbound.MailerService, logger *zap.Logger) inbound.UserService {
+	return &userService{userRepo: userRepo, pharmacyRepo: pharmacyRepo, configRepo: configRepo, roles: roles, mailer: mailer, logger: logger}
 }
 
 func (s *userService) List(ctx context.Context, pharmacyID uuid.UUID, actorRole string) ([]*models.User, error) {
@@ -60,7 +78,7 @@ func (s *userService) List(ctx context.Context, pharmacyID uuid.UUID, actorRole
 	if err != nil {
 		return nil, err
 	}
-	if actorRole == RoleManager {
+	if actorRole != RoleAdmin {
 		filtered := make([]*models.User, 0, len(list))
 		for _, u := range list {
 			if u.Role == RolePharmacist {
@@ -76,22 +94,18 @@ func (s *userService) Create(ctx context.Context, pharmacyID uuid.UUID, actorRol
 	if role == "" {
 		role = RoleStaff
 	}
-	if actorRole == RoleManager {
-		if role != RolePharmacist {
-			return nil, errors.ErrForbidden("manager can only create pharmacists")
-		}
-	} else if actorRole != RoleAdmin {
-		return nil, errors.ErrForbidden("only admin or manager can create users")
+	if actorRole != RoleAdmin && role != RolePharmacist {
+		return nil, errors.ErrForbidden("manager can only create pharmacists")
 	}
 	if role == RoleAdmin {
 		return nil, errors.ErrForbidden("cannot create admin users via this endpoint")
 	}
-	allowedRoles := map[string]bool{RoleManager: true, RolePharmacist: true, RoleStaff: true}
-	if !allowedRoles[role] {
-		return nil, errors.ErrForbidden("invalid role")
+	if err := s.checkAssignable(ctx, pharmacyID, role); err != nil {
+		return nil, err
 	}
 
-	_, err := s.userRepo.GetByEmail(ctx, email)
+	// Emails are unique across pharmacies, so the check ignores the tenant scope.
+	_, err := s.userRepo.GetByEmail(outbound.WithoutTenant(ctx), email)
 	if err == nil {
 		return nil, errors.ErrConflict("email already registered")
 	}
@@ -109,12 +123,15 @@ func (s *userService) Create(ctx context.Context, pharmacyID uuid.UUID, actorRol
 	if role == RolePharmacist && pharmacist != nil {
 		applyPharmacistProfile(u, pharmacist)
 	}
-	if err := u.SetPassword(password); err != nil {
-		return nil, errors.ErrInternal("failed to hash password", err)
+	if err := setPassword(securityPolicy(ctx, s.configRepo, pharmacyID), u, password, time.Now()); err != nil {
+		return nil, err
 	}
 	if err := s.userRepo.Create(ctx, u); err != nil {
 		return nil, errors.ErrInternal("failed to create user", err)
 	}
+	if s.mailer != nil {
+		_ = s.mailer.StaffAccountCreated(ctx, u)
+	}
 	return u, nil
 }
 
@@ -126,7 +143,7 @@ func (s *userService) GetByID(ctx context.Context, pharmacyID uuid.UUID, actorRo
 	if u.PharmacyID != pharmacyID {
 		return nil, errors.ErrNotFound("user")
 	}
-	if actorRole == RoleManager && u.Role != RolePharmacist {
+	if actorRole != RoleAdmin && u.Role != RolePharmacist {
 		return nil, errors.ErrForbidden("manager can only view pharmacists")
 	}
 	return u, nil
@@ -140,20 +157,21 @@ func (s *userService) Update(ctx context.Context, pharmacyID uuid.UUID, actorRol
 	if u.PharmacyID != pharmacyID {
 		return nil, errors.ErrNotFound("user")
 	}
-	if actorRole == RoleManager {
+	if actorRole != RoleAdmin {
 		if u.Role != RolePharmacist {
 			return nil, errors.ErrForbidden("manager can only update pharmacists")
 		}
 		if role != nil && *role != RolePharmacist {
 			return nil, errors.ErrForbidden("manager cannot change role to non-pharmacist")
 		}
-	} else if actorRole != RoleAdmin {
-		return nil, errors.ErrForbidden("only admin or manager can update users")
 	}
 	if role != nil {
 		if *role == RoleAdmin {
 			return nil, errors.ErrForbidden("cannot set role to admin")
 		}
+		if err := s.checkAssignable(ctx, pharmacyID, *role); err != nil {
+			return nil, err
+		}
 		u.Role = *role
 	}
 	if name != "" {
@@ -179,15 +197,30 @@ func (s *userService) Deactivate(ctx context.Context, pharmacyID uuid.UUID, acto
 	if u.PharmacyID != pharmacyID {
 		return nil, errors.ErrNotFound("user")
 	}
-	if actorRole == RoleManager && u.Role != RolePharmacist {
+	if actorRole != RoleAdmin && u.Role != RolePharmacist {
 		return nil, errors.ErrForbidden("manager can only deactivate pharmacists")
 	}
-	if actorRole != RoleAdmin && actorRole != RoleManager {
-		return nil, errors.ErrForbidden("only admin or manager can deactivate users")
-	}
 	u.IsActive = false
 	if err := s.userRepo.Update(ctx, u); err != nil {
 		return nil, errors.ErrInternal("failed to deactivate user", err)
 	}
 	return u, nil
 }
+
+// checkAssignable rejects roles that are neither a built-in team role nor a custom role of the pharmacy.
+func (s *userService) checkAssignable(ctx context.Context, pharmacyID uuid.UUID, role string) error {
+	if s.roles == nil {
+		if role == RoleManager || role == RolePharmacist || role == RoleStaff {
+			return nil
+		}
+		return errors.ErrForbidden("invalid role")
+	}
+	ok, err := s.roles.IsAssignable(ctx, pharmacyID, role)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.ErrForbidden("invalid role")
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// walletTopUpMax caps a single top-up; larger amounts go through the counter as a store credit adjustment.
+const walletTopUpMax = 100000.0
+
+type walletService struct {
+	topUpRepo           outbound.WalletTopUpRepository
+	storeCreditRepo     outbound.StoreCreditRepository
+	customerRepo        outbound.CustomerRepository
+	userRepo            outbound.UserRepository
+	gatewayRepo         outbound.PaymentGatewayRepository
+	referralPointsSvc   inbound.ReferralPointsService
+	notificationService inbound.NotificationService
+	configRepo          outbound.PharmacyConfigRepository
+	uow                 outbound.UnitOfWork
+	logger              *zap.Logger
+	now                 func() time.Time
+}
+
+// NewWalletService returns the service. referralPointsSvc creates the customer on a first top-up;
+// notificationService, when set, tells the customer when a top-up is settled. configRepo supplies the pharmacy's
+// base currency, which top-ups are taken in.
+func NewWalletService(topUpRepo outbound.WalletTopUpRepository, storeCreditRepo outbound.StoreCreditRepository, customerRepo outbound.CustomerRepository, userRepo outbound.UserRepository, gatewayRepo outbound.PaymentGatewayRepository, referralPointsSvc inbound.ReferralPointsService, notificationService inbound.NotificationService, configRepo outbound.PharmacyConfigRepository, uow outbound.UnitOfWork, logger *zap.Logger) inbound.WalletService {
+	return &walletService{topUpRepo: topUpRepo, storeCreditRepo: storeCreditRepo, customerRepo: customerRepo, userRepo: userRepo, gatewayRepo: gatewayRepo, referralPointsSvc: referralPointsSvc, notificationService: notificationService, configRepo: configRepo, uow: uow, logger: logger, now: time.Now}
+}
+
+func (s *walletService) config(ctx context.Context, pharmacyID uuid.UUID) *models.PharmacyConfig {
+	if s.configRepo == nil {
+		return nil
+	}
+	cfg, _ := s.configRepo.GetByPharmacyID(ctx, pharmacyID)
+	return cfg
+}
+
+// customer finds the customer matching the user's phone; nil when there is none yet.
+func (s *walletService) customer(ctx context.Context, pharmacyID, userID uuid.UUID) (*models.User, *models.Customer, error) {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || u == nil {
+		return nil, nil, errors.ErrNotFound("user")
+	}
+	phone := strings.TrimSpace(u.Phone)
+	if phone == "" {
+		return u, nil, nil
+	}
+	c, err := s.customerRepo.GetByPharmacyAndPhone(ctx, pharmacyID, phone)
+	if err != nil {
+		return nil, nil, errors.ErrInternal("failed to load customer", err)
+	}
+	return u, c, nil
+}
+
+func clampPage(limit, offset int) (int, int) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+func (s *walletService) Get(ctx context.Context, pharmacyID, userID uuid.UUID, limit, offset int) (*inbound.StoreCreditLedger, error) {
+	_, c, err := s.customer(ctx, pharmacyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return &inbound.StoreCreditLedger{Entries: []*models.StoreCreditEntry{}}, nil
+	}
+	limit, offset = clampPage(limit, offset)
+	entries, total, err := s.storeCreditRepo.ListByCustomer(ctx, c.ID, limit, offset)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to list wallet entries", err)
+	}
+	return &inbound.StoreCreditLedger{CustomerID: c.ID, Balance: c.StoreCreditBalance, Entries: entries, Total: total}, nil
+}
+
+func (s *walletService) StartTopUp(ctx context.Context, pharmacyID, userID uuid.UUID, in inbound.WalletTopUpInput) (*models.WalletTopUp, error) {
+	amount := roundMoney(in.Amount)
+	if amount <= 0 {
+		return nil, errors.ErrValidation("amount must be positive")
+	}
+	if amount > walletTopUpMax {
+		return nil, errors.ErrValidation(fmt.Sprintf("a single top-up can be at most %.0f", walletTopUpMax))
+	}
+	gw, err := s.gatewayRepo.GetByID(ctx, in.PaymentGatewayID)
+	if err != nil || gw == nil || gw.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("payment gateway")
+	}
+	if !gw.IsActive {
+		return nil, errors.ErrValidation("payment gateway is not active")
+	}
+	if gw.Code == models.GatewayCodeCOD {
+		return nil, errors.ErrValidation("cash on delivery cannot be used to top up a wallet")
+	}
+	u, c, err := s.customer(ctx, pharmacyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		if strings.TrimSpace(u.Phone) == "" {
+			return nil, errors.ErrValidation("add a phone number to your profile to use the wallet")
+		}
+		if s.referralPointsSvc == nil {
+			return nil, errors.ErrValidation("wallet is not available")
+		}
+		if c, err = s.referralPointsSvc.GetOrCreateCustomer(ctx, pharmacyID, u.Name, strings.TrimSpace(u.Phone), u.Email); err != nil {
+			return nil, err
+		}
+	}
+	t := &models.WalletTopUp{
+		PharmacyID:       pharmacyID,
+		CustomerID:       c.ID,
+		UserID:           userID,
+		PaymentGatewayID: gw.ID,
+		Amount:           amount,
+		Currency:         s.config(ctx, pharmacyID).Currency(),
+		Status:           models.WalletTopUpPending,
+	}
+	if err := s.topUpRepo.Create(ctx, t); err != nil {
+		return nil, errors.ErrInternal("failed to create top-up", err)
+	}
+	t.PaymentGateway = gw
+	return t, nil
+}
+
+func (s *walletService) ListMyTopUps(ctx context.Context, pharmacyID, userID uuid.UUID, limit, offset int) ([]*models.WalletTopUp, int64, error) {
+	limit, offset = clampPage(limit, offset)
+	list, total, err := s.topUpRepo.List(ctx, pharmacyID, outbound.WalletTopUpFilter{UserID: &userID}, limit, offset)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to list top-ups", err)
+	}
+	return list, total, nil
+}
+
+func (s *walletService) ListTopUps(ctx context.Context, pharmacyID uuid.UUID, q inbound.WalletTopUpQuery) ([]*models.WalletTopUp, int64, error) {
+	switch q.Status {
+	case "", models.WalletTopUpPending, models.WalletTopUpCompleted, models.WalletTopUpFailed:
+	default:
+		return nil, 0, errors.ErrValidation("status must be pending, completed or failed")
+	}
+	limit, offset := clampPage(q.Limit, q.Offset)
+	list, total, err := s.topUpRepo.List(ctx, pharmacyID, outbound.WalletTopUpFilter{CustomerID: q.CustomerID, Status: q.Status}, limit, offset)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to list top-ups", err)
+	}
+	return list, total, nil
+}
+
+// pending loads a top-up of this pharmacy that can still be settled.
+func (s *walletService) pending(ctx context.Context, pharmacyID, id uuid.UUID) (*models.WalletTopUp, error) {
+	t, err := s.topUpRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load top-up", err)
+	}
+	if t == nil || t.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("top-up")
+	}
+	if t.Status != models.WalletTopUpPending {
+		return nil, errors.ErrConflict("top-up is already " + string(t.Status))
+	}
+	return t, nil
+}
+
+func (s *walletService) CompleteTopUp(ctx context.Context, pharmacyID, id, actorID uuid.UUID, reference string) (*models.WalletTopUp, error) {
+	t, err := s.pending(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	entry := &models.StoreCreditEntry{
+		ID:         uuid.New(),
+		PharmacyID: t.PharmacyID,
+		CustomerID: t.CustomerID,
+		Type:       models.StoredValueTopUp,
+		Amount:     t.Amount,
+		Note:       truncateRunes(strings.TrimSpace("Top-up "+reference), 500),
+		CreatedBy:  actorID,
+	}
+	t.Status = models.WalletTopUpCompleted
+	t.Reference = truncateRunes(strings.TrimSpace(reference), 255)
+	t.EntryID = &entry.ID
+	t.SettledBy = &actorID
+	t.SettledAt = &now
+	// Claiming the top-up and crediting the wallet commit together, so a retry can neither credit twice
+	// nor leave a completed top-up without its entry.
+	err = inTx(ctx, s.uow, func(ctx context.Context) error {
+		ok, err := s.topUpRepo.Settle(ctx, t)
+		if err != nil {
+			return errors.ErrInternal("failed to complete top-up", err)
+		}
+		if !ok {
+			return errors.ErrConflict("top-up was already settled")
+		}
+		if _, err := s.storeCreditRepo.Adjust(ctx, entry); err != nil {
+			return errors.ErrInternal("failed to credit wallet", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.notify(ctx, t, "Wallet topped up", fmt.Sprintf("%.2f %s was added to your wallet. New balance: %.2f.", t.Amount, t.Currency, entry.BalanceAfter))
+	return t, nil
+}
+
+func (s *walletService) FailTopUp(ctx context.Context, pharmacyID, id, actorID uuid.UUID, reason string) (*models.WalletTopUp, error) {
+	t, err := s.pending(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	t.Status = models.WalletTopUpFailed
+	t.FailureReason = strings.TrimSpace(reason)
+	t.SettledBy = &actorID
+	t.SettledAt = &now
+	ok, err := s.topUpRepo.Settle(ctx, t)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to update top-up", err)
+	}
+	if !ok {
+		return nil, errors.ErrConflict("top-up was already settled")
+	}
+	message := fmt.Sprintf("Your top-up of %.2f %s did not go through; your wallet was not charged.", t.Amount, t.Currency)
+	if t.FailureReason != "" {
+		message += " " + t.FailureReason
+	}
+	s.notify(ctx, t, "Wallet top-up failed", message)
+	return t, nil
+}
+
+func (s *walletService) notify(ctx context.Context, t *models.WalletTopUp, title, message string) {
+	if s.notificationService == nil {
+		return
+	}
+	if _, err := s.notificationService.Create(ctx, t.PharmacyID, t.UserID, title, message, "wallet"); err != nil {
+		s.logger.Warn("failed to notify wallet top-up", zap.String("top_up_id", t.ID.String()), zap.Error(err))
+	}
+}
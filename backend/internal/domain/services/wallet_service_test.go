@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestWalletService_StartTopUp_UsesCustomerByPhoneAndRejectsCOD(t *testing.T) {
+	pharmacyID, userID, customerID := uuid.New(), uuid.New(), uuid.New()
+	gateways := map[string]*models.PaymentGateway{
+		"khalti": {ID: uuid.New(), PharmacyID: pharmacyID, Code: models.GatewayCodeKhalti, IsActive: true},
+		"cod":    {ID: uuid.New(), PharmacyID: pharmacyID, Code: models.GatewayCodeCOD, IsActive: true},
+	}
+	gatewayRepo := &mocks.MockPaymentGatewayRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.PaymentGateway, error) {
+			for _, g := range gateways {
+				if g.ID == id {
+					return g, nil
+				}
+			}
+			return nil, nil
+		},
+	}
+	users := &mocks.MockUserRepository{
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.User, error) {
+			return &models.User{ID: id, Phone: " 9800000001 "}, nil
+		},
+	}
+	customers := &mocks.MockCustomerRepository{
+		GetByPharmacyAndPhoneFunc: func(ctx context.Context, pid uuid.UUID, phone string) (*models.Customer, error) {
+			if phone != "9800000001" {
+				t.Fatalf("looked up phone %q, want it trimmed", phone)
+			}
+			return &models.Customer{ID: customerID, PharmacyID: pid}, nil
+		},
+	}
+	var created []*models.WalletTopUp
+	topUps := &mocks.MockWalletTopUpRepository{
+		CreateFunc: func(ctx context.Context, tu *models.WalletTopUp) error {
+			created = append(created, tu)
+			return nil
+		},
+	}
+	configs := &mocks.MockPharmacyConfigRepository{GetByPharmacyIDFunc: func(ctx context.Context, pid uuid.UUID) (*models.PharmacyConfig, error) {
+		return &models.PharmacyConfig{PharmacyID: pid, BaseCurrency: "INR"}, nil
+	}}
+	svc := NewWalletService(topUps, &mocks.MockStoreCreditRepository{}, customers, users, gatewayRepo, nil, nil, configs, nil, zap.NewNop())
+
+	_, err := svc.StartTopUp(context.Background(), pharmacyID, userID, inbound.WalletTopUpInput{Amount: 500, PaymentGatewayID: gateways["cod"].ID})
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Fatalf("err = %v, want validation error for cash on delivery", err)
+	}
+	tu, err := svc.StartTopUp(context.Background(), pharmacyID, userID, inbound.WalletTopUpInput{Amount: 500.004, PaymentGatewayID: gateways["khalti"].ID})
+	if err != nil {
+		t.Fatalf("StartTopUp: %v", err)
+	}
+	if len(created) != 1 || tu.CustomerID != customerID || tu.UserID != userID || tu.Amount != 500 || tu.Currency != "INR" || tu.Status != models.WalletTopUpPending {
+		t.Errorf("top-up = %+v, want one pending top-up of 500 INR (the base currency) for the customer", tu)
+	}
+}
+
+func TestWalletService_CompleteTopUp_CreditsWalletOnce(t *testing.T) {
+	pharmacyID, customerID, userID := uuid.New(), uuid.New(), uuid.New()
+	id := uuid.New()
+	topUps := &mocks.MockWalletTopUpRepository{
+		GetByIDFunc: func(ctx context.Context, got uuid.UUID) (*models.WalletTopUp, error) {
+			return &models.WalletTopUp{ID: got, PharmacyID: pharmacyID, CustomerID: customerID, UserID: userID, Amount: 750, Currency: "NPR", Status: models.WalletTopUpPending}, nil
+		},
+	}
+	settled := 0
+	topUps.SettleFunc = func(ctx context.Context, tu *models.WalletTopUp) (bool, error) {
+		settled++
+		return settled == 1, nil // a second request loses the race
+	}
+	var entries []*models.StoreCreditEntry
+	credit := &mocks.MockStoreCreditRepository{
+		AdjustFunc: func(ctx context.Context, e *models.StoreCreditEntry) (bool, error) {
+			e.BalanceAfter = 750
+			entries = append(entries, e)
+			return true, nil
+		},
+	}
+	notifier := &recordingNotifier{}
+	uow := &mocks.MockUnitOfWork{}
+	svc := NewWalletService(topUps, credit, &mocks.MockCustomerRepository{}, &mocks.MockUserRepository{}, &mocks.MockPaymentGatewayRepository{}, nil, notifier, nil, uow, zap.NewNop())
+
+	tu, err := svc.CompleteTopUp(context.Background(), pharmacyID, id, uuid.New(), "KH-123")
+	if err != nil {
+		t.Fatalf("CompleteTopUp: %v", err)
+	}
+	if tu.Status != models.WalletTopUpCompleted || tu.Reference != "KH-123" || tu.EntryID == nil {
+		t.Errorf("top-up = %+v, want completed with reference and entry", tu)
+	}
+	if len(entries) != 1 || entries[0].Type != models.StoredValueTopUp || entries[0].Amount != 750 || entries[0].CustomerID != customerID || *tu.EntryID != entries[0].ID {
+		t.Errorf("entries = %+v, want one top_up credit of 750 linked to the top-up", entries)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].UserID != userID {
+		t.Errorf("notifications = %d, want one to the user who topped up", len(notifier.sent))
+	}
+
+	_, err = svc.CompleteTopUp(context.Background(), pharmacyID, id, uuid.New(), "KH-123")
+	if err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeConflict {
+		t.Fatalf("err = %v, want conflict for a top-up settled by another request", err)
+	}
+	if len(entries) != 1 || uow.RolledBack != 1 {
+		t.Errorf("entries = %d, rollbacks = %d; want the second completion to credit nothing", len(entries), uow.RolledBack)
+	}
+}
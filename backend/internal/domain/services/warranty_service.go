@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// warrantyClaimText is the customer message per language and claim status: product name, serial number and,
+// for resolved claims, the resolution.
+var warrantyClaimText = map[string]map[models.WarrantyClaimStatus]string{
+	"en": {
+		models.WarrantyClaimIntake:       "we have received your %s (S/N %s) for a warranty claim.",
+		models.WarrantyClaimSentToVendor: "your %s (S/N %s) has been sent to the manufacturer for warranty service.",
+		models.WarrantyClaimResolved:     "the warranty claim for your %s (S/N %s) is resolved: %s.",
+	},
+	"ne": {
+		models.WarrantyClaimIntake:       "वारेन्टी दाबीका लागि तपाईंको %s (S/N %s) हामीले प्राप्त गर्यौं।",
+		models.WarrantyClaimSentToVendor: "तपाईंको %s (S/N %s) वारेन्टी सेवाका लागि निर्मातालाई पठाइएको छ।",
+		models.WarrantyClaimResolved:     "तपाईंको %s (S/N %s) को वारेन्टी दाबी टुंगियो: %s।",
+	},
+}
+
+// warrantyResolutionLabels names resolutions in customer messages per language.
+var warrantyResolutionLabels = map[string]map[models.WarrantyResolution]string{
+	"en": {
+		models.WarrantyRepaired: "repaired",
+		models.WarrantyReplaced: "replaced",
+		models.WarrantyRefunded: "refunded",
+		models.WarrantyRejected: "not covered by the warranty",
+	},
+	"ne": {
+		models.WarrantyRepaired: "मर्मत गरियो",
+		models.WarrantyReplaced: "बदलियो",
+		models.WarrantyRefunded: "रकम फिर्ता गरियो",
+		models.WarrantyRejected: "वारेन्टीमा पर्दैन",
+	},
+}
+
+// warrantyClaimMessage is the SMS and email text for the claim's status; the claim must carry its warranty.
+func warrantyClaimMessage(lang string, c *models.WarrantyClaim) string {
+	texts, ok := warrantyClaimText[lang]
+	if !ok {
+		lang, texts = "en", warrantyClaimText["en"]
+	}
+	product := "device"
+	if c.Warranty.Product != nil {
+		product = c.Warranty.Product.Name
+	}
+	if c.Status == models.WarrantyClaimResolved {
+		return fmt.Sprintf(texts[c.Status], product, c.Warranty.SerialNumber, warrantyResolutionLabels[lang][c.Resolution])
+	}
+	return fmt.Sprintf(texts[c.Status], product, c.Warranty.SerialNumber)
+}
+
+type warrantyService struct {
+	repo        outbound.WarrantyRepository
+	smsNotifier inbound.SMSNotificationService
+	mailer      inbound.MailerService
+	logger      *zap.Logger
+	now         func() time.Time
+}
+
+// NewWarrantyService returns the service. smsNotifier and mailer may be nil.
+func NewWarrantyService(repo outbound.WarrantyRepository, smsNotifier inbound.SMSNotificationService, mailer inbound.MailerService, logger *zap.Logger) inbound.WarrantyService {
+	return &warrantyService{repo: repo, smsNotifier: smsNotifier, mailer: mailer, logger: logger, now: time.Now}
+}
+
+func (s *warrantyService) RegisterSale(ctx context.Context, o *models.Order, item *models.OrderItem, product *models.Product, serials []*models.ProductSerial) error {
+	if product.WarrantyMonths <= 0 || len(serials) == 0 {
+		return nil
+	}
+	start := s.now()
+	list := make([]*models.Warranty, 0, len(serials))
+	for _, sn := range serials {
+		list = append(list, &models.Warranty{
+			PharmacyID: o.PharmacyID, ProductID: product.ID, SerialID: sn.ID, SerialNumber: sn.SerialNumber,
+			OrderID: o.ID, OrderItemID: item.ID, CustomerID: o.CustomerID,
+			CustomerName: o.CustomerName, CustomerPhone: o.CustomerPhone, CustomerEmail: o.CustomerEmail,
+			StartsAt: start, ExpiresAt: start.AddDate(0, product.WarrantyMonths, 0),
+		})
+	}
+	if err := s.repo.CreateBatch(ctx, list); err != nil {
+		return errors.ErrInternal("failed to register warranties", err)
+	}
+	return nil
+}
+
+func (s *warrantyService) VoidOrder(ctx context.Context, orderID uuid.UUID) error {
+	if err := s.repo.VoidOrder(ctx, orderID, s.now()); err != nil {
+		return errors.ErrInternal("failed to void warranties", err)
+	}
+	return nil
+}
+
+func (s *warrantyService) Check(ctx context.Context, pharmacyID uuid.UUID, serialNumber string) ([]*inbound.WarrantyCheck, error) {
+	list, err := s.FindBySerial(ctx, pharmacyID, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	out := make([]*inbound.WarrantyCheck, 0, len(list))
+	for _, w := range list {
+		c := &inbound.WarrantyCheck{SerialNumber: w.SerialNumber, PurchasedAt: w.StartsAt, ExpiresAt: w.ExpiresAt, Status: w.StatusAt(now)}
+		if w.Product != nil {
+			c.ProductName = w.Product.Name
+		}
+		if len(w.Claims) > 0 {
+			c.ClaimStatus = w.Claims[0].Status
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func (s *warrantyService) FindBySerial(ctx context.Context, pharmacyID uuid.UUID, serialNumber string) ([]*models.Warranty, error) {
+	serialNumber = strings.TrimSpace(serialNumber)
+	if serialNumber == "" {
+		return nil, errors.ErrValidation("serial number is required")
+	}
+	list, err := s.repo.FindBySerial(ctx, pharmacyID, serialNumber)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to look up warranty", err)
+	}
+	if len(list) == 0 {
+		return nil, errors.ErrNotFound("warranty")
+	}
+	return list, nil
+}
+
+func (s *warrantyService) Get(ctx context.Context, pharmacyID, id uuid.UUID) (*models.Warranty, error) {
+	w, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load warranty", err)
+	}
+	if w == nil || w.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("warranty")
+	}
+	return w, nil
+}
+
+func (s *warrantyService) OpenClaim(ctx context.Context, pharmacyID, actorID uuid.UUID, in inbound.WarrantyClaimInput) (*models.WarrantyClaim, error) {
+	w, err := s.Get(ctx, pharmacyID, in.WarrantyID)
+	if err != nil {
+		return nil, err
+	}
+	if status := w.StatusAt(s.now()); status != "active" {
+		return nil, errors.ErrValidation("warranty is " + status)
+	}
+	for _, c := range w.Claims {
+		if c.Status != models.WarrantyClaimResolved {
+			return nil, errors.ErrConflict("this device already has an open warranty claim")
+		}
+	}
+	issue := strings.TrimSpace(in.Issue)
+	if issue == "" {
+		return nil, errors.ErrValidation("describe the issue")
+	}
+	c := &models.WarrantyClaim{PharmacyID: pharmacyID, WarrantyID: w.ID, Status: models.WarrantyClaimIntake, Issue: issue, CreatedBy: actorID}
+	if err := s.repo.CreateClaim(ctx, c); err != nil {
+		return nil, errors.ErrInternal("failed to open warranty claim", err)
+	}
+	w.Claims = nil
+	c.Warranty = w
+	s.notify(ctx, c)
+	return c, nil
+}
+
+func (s *warrantyService) ListClaims(ctx context.Context, pharmacyID uuid.UUID, status models.WarrantyClaimStatus, limit, offset int) ([]*models.WarrantyClaim, int64, error) {
+	limit, offset = clampPage(limit, offset)
+	list, total, err := s.repo.ListClaims(ctx, pharmacyID, status, limit, offset)
+	if err != nil {
+		return nil, 0, errors.ErrInternal("failed to list warranty claims", err)
+	}
+	return list, total, nil
+}
+
+func (s *warrantyService) GetClaim(ctx context.Context, pharmacyID, id uuid.UUID) (*models.WarrantyClaim, error) {
+	c, err := s.repo.GetClaim(ctx, id)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load warranty claim", err)
+	}
+	if c == nil || c.PharmacyID != pharmacyID {
+		return nil, errors.ErrNotFound("warranty claim")
+	}
+	return c, nil
+}
+
+func (s *warrantyService) SendToVendor(ctx context.Context, pharmacyID, id uuid.UUID, in inbound.WarrantyVendorInput) (*models.WarrantyClaim, error) {
+	c, err := s.GetClaim(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status != models.WarrantyClaimIntake {
+		return nil, errors.ErrConflict("only claims in intake can be sent to the vendor")
+	}
+	now := s.now()
+	c.Status = models.WarrantyClaimSentToVendor
+	c.VendorReference = strings.TrimSpace(in.VendorReference)
+	c.Note = strings.TrimSpace(in.Note)
+	c.SentToVendorAt = &now
+	return s.move(ctx, c, models.WarrantyClaimIntake)
+}
+
+func (s *warrantyService) Resolve(ctx context.Context, pharmacyID, id, actorID uuid.UUID, in inbound.WarrantyResolveInput) (*models.WarrantyClaim, error) {
+	c, err := s.GetClaim(ctx, pharmacyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status == models.WarrantyClaimResolved {
+		return nil, errors.ErrConflict("claim is already resolved")
+	}
+	from := c.Status
+	now := s.now()
+	c.Status = models.WarrantyClaimResolved
+	c.Resolution = in.Resolution
+	c.Note = strings.TrimSpace(in.Note)
+	c.ResolvedBy = &actorID
+	c.ResolvedAt = &now
+	return s.move(ctx, c, from)
+}
+
+// move saves a status change made from status from and tells the customer.
+func (s *warrantyService) move(ctx context.Context, c *models.WarrantyClaim, from models.WarrantyClaimStatus) (*models.WarrantyClaim, error) {
+	ok, err := s.repo.UpdateClaim(ctx, c, from)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to update warranty claim", err)
+	}
+	if !ok {
+		return nil, errors.ErrConflict("claim was updated by someone else; reload and try again")
+	}
+	s.notify(ctx, c)
+	return c, nil
+}
+
+func (s *warrantyService) notify(ctx context.Context, c *models.WarrantyClaim) {
+	if s.smsNotifier != nil {
+		_ = s.smsNotifier.WarrantyClaimUpdated(ctx, c)
+	}
+	if s.mailer != nil {
+		_ = s.mailer.WarrantyClaimUpdated(ctx, c)
+	}
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// warrantyFixture keeps warranties and claims in memory and captures customer emails.
+type warrantyFixture struct {
+	pharmacyID uuid.UUID
+	now        time.Time
+	warranties []*models.Warranty
+	claims     map[uuid.UUID]*models.WarrantyClaim
+	emails     *captureSender
+	svc        *warrantyService
+}
+
+func newWarrantyFixture() *warrantyFixture {
+	f := &warrantyFixture{pharmacyID: uuid.New(), now: time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC), claims: map[uuid.UUID]*models.WarrantyClaim{}, emails: &captureSender{}}
+	repo := &mocks.MockWarrantyRepository{
+		CreateBatchFunc: func(ctx context.Context, warranties []*models.Warranty) error {
+			for _, w := range warranties {
+				w.ID = uuid.New()
+			}
+			f.warranties = append(f.warranties, warranties...)
+			return nil
+		},
+		GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Warranty, error) {
+			for _, w := range f.warranties {
+				if w.ID == id {
+					w.Claims = nil
+					for _, c := range f.claims {
+						if c.WarrantyID == id {
+							w.Claims = append(w.Claims, c)
+						}
+					}
+					return w, nil
+				}
+			}
+			return nil, nil
+		},
+		FindBySerialFunc: func(ctx context.Context, pharmacyID uuid.UUID, serialNumber string) ([]*models.Warranty, error) {
+			var out []*models.Warranty
+			for _, w := range f.warranties {
+				if w.PharmacyID == pharmacyID && w.SerialNumber == serialNumber {
+					out = append(out, w)
+				}
+			}
+			return out, nil
+		},
+		VoidOrderFunc: func(ctx context.Context, orderID uuid.UUID, at time.Time) error {
+			for _, w := range f.warranties {
+				if w.OrderID == orderID && w.VoidedAt == nil {
+					w.VoidedAt = &at
+				}
+			}
+			return nil
+		},
+		CreateClaimFunc: func(ctx context.Context, c *models.WarrantyClaim) error {
+			c.ID = uuid.New()
+			f.claims[c.ID] = c
+			return nil
+		},
+		GetClaimFunc: func(ctx context.Context, id uuid.UUID) (*models.WarrantyClaim, error) {
+			c := f.claims[id]
+			if c == nil {
+				return nil, nil
+			}
+			cp := *c
+			return &cp, nil
+		},
+		UpdateClaimFunc: func(ctx context.Context, c *models.WarrantyClaim, from models.WarrantyClaimStatus) (bool, error) {
+			if f.claims[c.ID].Status != from {
+				return false, nil
+			}
+			f.claims[c.ID] = c
+			return true, nil
+		},
+	}
+	mailer := NewMailerService(f.emails, nil, nil, nil, "", zap.NewNop())
+	f.svc = NewWarrantyService(repo, nil, mailer, zap.NewNop()).(*warrantyService)
+	f.svc.now = func() time.Time { return f.now }
+	return f
+}
+
+// sell registers warranties for a sale of the serials.
+func (f *warrantyFixture) sell(t *testing.T, product *models.Product, serials ...string) *models.Order {
+	t.Helper()
+	o := &models.Order{ID: uuid.New(), PharmacyID: f.pharmacyID, CustomerName: "Sita", CustomerEmail: "sita@example.com"}
+	item := &models.OrderItem{ID: uuid.New(), OrderID: o.ID, ProductID: product.ID, Quantity: len(serials)}
+	list := make([]*models.ProductSerial, 0, len(serials))
+	for _, n := range serials {
+		list = append(list, &models.ProductSerial{ID: uuid.New(), SerialNumber: n})
+	}
+	if err := f.svc.RegisterSale(context.Background(), o, item, product, list); err != nil {
+		t.Fatalf("RegisterSale: %v", err)
+	}
+	return o
+}
+
+func TestWarrantyService_RegisterSale_CoversProductMonthsAndVoidsOnCancel(t *testing.T) {
+	f := newWarrantyFixture()
+	ctx := context.Background()
+	meter := &models.Product{ID: uuid.New(), Name: "Glucometer", WarrantyMonths: 24}
+	strips := &models.Product{ID: uuid.New(), Name: "Strips"}
+
+	f.sell(t, strips, "S-1")
+	if len(f.warranties) != 0 {
+		t.Fatalf("registered %d warranties for a product without warranty months, want 0", len(f.warranties))
+	}
+	o := f.sell(t, meter, "GM-1", "GM-2")
+	if len(f.warranties) != 2 {
+		t.Fatalf("registered %d warranties, want 2", len(f.warranties))
+	}
+	if w := f.warranties[0]; !w.ExpiresAt.Equal(time.Date(2028, 3, 10, 9, 0, 0, 0, time.UTC)) || w.CustomerEmail != "sita@example.com" {
+		t.Errorf("warranty = %+v, want 24 months from the sale for the order's customer", w)
+	}
+
+	f.warranties[0].Product = meter
+	checks, err := f.svc.Check(ctx, f.pharmacyID, " GM-1 ")
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(checks) != 1 || checks[0].Status != "active" || checks[0].ProductName != "Glucometer" {
+		t.Errorf("checks = %+v, want one active Glucometer warranty", checks)
+	}
+	if _, err := f.svc.Check(ctx, uuid.New(), "GM-1"); err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeNotFound {
+		t.Errorf("err = %v, want not found for another pharmacy", err)
+	}
+
+	if err := f.svc.VoidOrder(ctx, o.ID); err != nil {
+		t.Fatalf("VoidOrder: %v", err)
+	}
+	checks, _ = f.svc.Check(ctx, f.pharmacyID, "GM-1")
+	if checks[0].Status != "void" {
+		t.Errorf("status = %s after cancellation, want void", checks[0].Status)
+	}
+}
+
+func TestWarrantyService_Claims_MoveThroughVendorAndNotifyCustomer(t *testing.T) {
+	f := newWarrantyFixture()
+	ctx := context.Background()
+	meter := &models.Product{ID: uuid.New(), Name: "BP monitor", WarrantyMonths: 12}
+	f.sell(t, meter, "BP-9")
+	w := f.warranties[0]
+	w.Product = meter
+	actorID := uuid.New()
+
+	c, err := f.svc.OpenClaim(ctx, f.pharmacyID, actorID, inbound.WarrantyClaimInput{WarrantyID: w.ID, Issue: "Cuff does not inflate"})
+	if err != nil {
+		t.Fatalf("OpenClaim: %v", err)
+	}
+	if c.Status != models.WarrantyClaimIntake {
+		t.Errorf("status = %s, want intake", c.Status)
+	}
+	if _, err := f.svc.OpenClaim(ctx, f.pharmacyID, actorID, inbound.WarrantyClaimInput{WarrantyID: w.ID, Issue: "Again"}); err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeConflict {
+		t.Errorf("err = %v, want conflict while a claim is open", err)
+	}
+
+	f.claims[c.ID].Warranty = w
+	c, err = f.svc.SendToVendor(ctx, f.pharmacyID, c.ID, inbound.WarrantyVendorInput{VendorReference: "RMA-1"})
+	if err != nil {
+		t.Fatalf("SendToVendor: %v", err)
+	}
+	if c.Status != models.WarrantyClaimSentToVendor || c.SentToVendorAt == nil || c.VendorReference != "RMA-1" {
+		t.Errorf("claim = %+v, want sent to the vendor under RMA-1", c)
+	}
+	if _, err := f.svc.Resolve(ctx, f.pharmacyID, c.ID, actorID, inbound.WarrantyResolveInput{Resolution: models.WarrantyReplaced}); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if _, err := f.svc.SendToVendor(ctx, f.pharmacyID, c.ID, inbound.WarrantyVendorInput{VendorReference: "RMA-1"}); err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeConflict {
+		t.Errorf("err = %v, want conflict sending a resolved claim to the vendor", err)
+	}
+	if len(f.emails.sent) != 3 {
+		t.Fatalf("sent %d emails, want one per step", len(f.emails.sent))
+	}
+	if body := f.emails.sent[2].TextBody; !strings.Contains(body, "BP monitor (S/N BP-9)") || !strings.Contains(body, "replaced") {
+		t.Errorf("resolution email = %q, want product, serial and outcome", body)
+	}
+
+	f.now = f.now.AddDate(1, 0, 1)
+	if _, err := f.svc.OpenClaim(ctx, f.pharmacyID, actorID, inbound.WarrantyClaimInput{WarrantyID: w.ID, Issue: "Broken screen"}); err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeValidation {
+		t.Errorf("err = %v, want validation error for an expired warranty", err)
+	}
+}
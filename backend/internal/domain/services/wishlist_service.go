@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/inbound"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// wishlistScanBatch bounds one ScanAlerts pass; the rest is picked up by the next run.
+const wishlistScanBatch = 500
+
+type wishlistService struct {
+	repo                outbound.WishlistRepository
+	productRepo         outbound.ProductRepository
+	notificationService inbound.NotificationService
+	now                 func() time.Time
+	logger              *zap.Logger
+}
+
+func NewWishlistService(repo outbound.WishlistRepository, productRepo outbound.ProductRepository, notificationService inbound.NotificationService, logger *zap.Logger) inbound.WishlistService {
+	return &wishlistService{repo: repo, productRepo: productRepo, notificationService: notificationService, now: time.Now, logger: logger}
+}
+
+func wishlistEntry(w *models.WishlistItem) *inbound.WishlistEntry {
+	e := &inbound.WishlistEntry{ProductID: w.ProductID, AddedPrice: w.AddedPrice, AddedAt: w.CreatedAt}
+	if p := w.Product; p != nil {
+		e.Name, e.SKU, e.UnitPrice, e.DiscountPercent, e.Currency = p.Name, p.SKU, p.UnitPrice, p.DiscountPercent, p.Currency
+		e.StockQuantity, e.InStock, e.Available = p.StockQuantity, p.StockQuantity > 0, p.IsActive && !p.DeletedAt.Valid
+		e.PriceDropped = p.UnitPrice < w.AddedPrice
+		for _, img := range p.Images {
+			if img.IsPrimary || e.Image == "" {
+				e.Image = img.LowURL
+				if e.Image == "" {
+					e.Image = img.URL
+				}
+			}
+		}
+	}
+	return e
+}
+
+func (s *wishlistService) Add(ctx context.Context, pharmacyID, userID, productID uuid.UUID) (*inbound.WishlistEntry, error) {
+	p, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil || p == nil || p.PharmacyID != pharmacyID || !p.IsActive {
+		return nil, errors.ErrNotFound("product")
+	}
+	w := &models.WishlistItem{
+		PharmacyID: pharmacyID, UserID: userID, ProductID: productID,
+		AddedPrice: p.UnitPrice, SeenPrice: p.UnitPrice, SeenInStock: p.StockQuantity > 0, CreatedAt: s.now(),
+	}
+	if err := s.repo.Add(ctx, w); err != nil {
+		return nil, errors.ErrInternal("failed to add to wishlist", err)
+	}
+	// Re-read so adding an existing product returns its original entry.
+	list, err := s.repo.ListByUser(ctx, pharmacyID, userID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load wishlist", err)
+	}
+	for _, item := range list {
+		if item.ProductID == productID {
+			return wishlistEntry(item), nil
+		}
+	}
+	w.Product = p
+	return wishlistEntry(w), nil
+}
+
+func (s *wishlistService) Remove(ctx context.Context, pharmacyID, userID, productID uuid.UUID) error {
+	ok, err := s.repo.Remove(ctx, userID, productID)
+	if err != nil {
+		return errors.ErrInternal("failed to remove from wishlist", err)
+	}
+	if !ok {
+		return errors.ErrNotFound("wishlist item")
+	}
+	return nil
+}
+
+func (s *wishlistService) List(ctx context.Context, pharmacyID, userID uuid.UUID) ([]*inbound.WishlistEntry, error) {
+	list, err := s.repo.ListByUser(ctx, pharmacyID, userID)
+	if err != nil {
+		return nil, errors.ErrInternal("failed to load wishlist", err)
+	}
+	out := make([]*inbound.WishlistEntry, 0, len(list))
+	for _, w := range list {
+		out = append(out, wishlistEntry(w))
+	}
+	return out, nil
+}
+
+func (s *wishlistService) ScanAlerts(ctx context.Context) error {
+	items, err := s.repo.ListChanged(ctx, wishlistScanBatch)
+	if err != nil {
+		return fmt.Errorf("list changed wishlist items: %w", err)
+	}
+	sent := 0
+	for _, w := range items {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		p := w.Product
+		if p == nil {
+			continue
+		}
+		inStock, was := p.StockQuantity > 0, w.SeenPrice
+		restocked := inStock && !w.SeenInStock
+		// Price drops matter only while the product can be bought.
+		dropped := inStock && p.UnitPrice < was
+		var notifiedAt *time.Time
+		if restocked || dropped {
+			now := s.now()
+			notifiedAt = &now
+		}
+		// Going out of stock or getting dearer is recorded silently, so the next restock or drop alerts again.
+		if err := s.repo.SetSeen(ctx, w.ID, inStock, p.UnitPrice, notifiedAt); err != nil {
+			return fmt.Errorf("update wishlist item: %w", err)
+		}
+		if notifiedAt == nil || s.notificationService == nil {
+			continue
+		}
+		title, message := wishlistAlert(p, was, restocked, dropped)
+		if _, err := s.notificationService.Create(ctx, w.PharmacyID, w.UserID, title, message, "wishlist"); err != nil {
+			s.logger.Warn("failed to send wishlist alert", zap.String("user_id", w.UserID.String()), zap.Error(err))
+			continue
+		}
+		sent++
+	}
+	if sent > 0 {
+		s.logger.Info("wishlist alerts sent", zap.Int("alerts", sent))
+	}
+	return nil
+}
+
+func wishlistAlert(p *models.Product, was float64, restocked, dropped bool) (title, message string) {
+	price := fmt.Sprintf("%s %.2f", p.Currency, p.UnitPrice)
+	switch {
+	case restocked && dropped:
+		return p.Name + " is back in stock", fmt.Sprintf("%s from your wishlist is back in stock and now costs %s (was %.2f).", p.Name, price, was)
+	case restocked:
+		return p.Name + " is back in stock", fmt.Sprintf("%s from your wishlist is back in stock at %s.", p.Name, price)
+	default:
+		return p.Name + " price dropped", fmt.Sprintf("%s from your wishlist now costs %s (was %.2f).", p.Name, price, was)
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	mocks "github.com/careplus/pharmacy-backend/internal/mocks/outbound"
+	pkgerrors "github.com/careplus/pharmacy-backend/pkg/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestWishlistService_Add_RejectsOtherPharmacyAndInactive(t *testing.T) {
+	pharmacyID := uuid.New()
+	products := map[uuid.UUID]*models.Product{}
+	own := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, Name: "Cetamol", UnitPrice: 50, StockQuantity: 0, IsActive: true}
+	other := &models.Product{ID: uuid.New(), PharmacyID: uuid.New(), IsActive: true}
+	inactive := &models.Product{ID: uuid.New(), PharmacyID: pharmacyID, IsActive: false}
+	for _, p := range []*models.Product{own, other, inactive} {
+		products[p.ID] = p
+	}
+	var added *models.WishlistItem
+	svc := &wishlistService{
+		repo: &mocks.MockWishlistRepository{AddFunc: func(ctx context.Context, w *models.WishlistItem) error {
+			added = w
+			return nil
+		}},
+		productRepo: &mocks.MockProductRepository{GetByIDFunc: func(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+			return products[id], nil
+		}},
+		now:    time.Now,
+		logger: zap.NewNop(),
+	}
+	ctx := context.Background()
+
+	for _, id := range []uuid.UUID{other.ID, inactive.ID, uuid.New()} {
+		if _, err := svc.Add(ctx, pharmacyID, uuid.New(), id); err == nil || pkgerrors.GetAppError(err).Code != pkgerrors.ErrCodeNotFound {
+			t.Errorf("Add(%s): err = %v, want not found", id, err)
+		}
+	}
+	e, err := svc.Add(ctx, pharmacyID, uuid.New(), own.ID)
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if added == nil || added.AddedPrice != 50 || added.SeenPrice != 50 || added.SeenInStock || e.InStock || !e.Available {
+		t.Errorf("added = %+v, entry = %+v", added, e)
+	}
+}
+
+func TestWishlistService_ScanAlerts_RestockAndPriceDrop(t *testing.T) {
+	p := &models.Product{ID: uuid.New(), Name: "Cetamol", Currency: "NPR", UnitPrice: 50, StockQuantity: 0, IsActive: true}
+	item := &models.WishlistItem{ID: uuid.New(), PharmacyID: uuid.New(), UserID: uuid.New(), ProductID: p.ID, AddedPrice: 50, SeenPrice: 50, Product: p}
+	repo := &mocks.MockWishlistRepository{
+		// Mirrors the repository filter: only items whose product changed since last seen.
+		ListChangedFunc: func(ctx context.Context, limit int) ([]*models.WishlistItem, error) {
+			if (p.StockQuantity > 0) != item.SeenInStock || p.UnitPrice != item.SeenPrice {
+				return []*models.WishlistItem{item}, nil
+			}
+			return nil, nil
+		},
+		SetSeenFunc: func(ctx context.Context, id uuid.UUID, inStock bool, price float64, notifiedAt *time.Time) error {
+			item.SeenInStock, item.SeenPrice = inStock, price
+			if notifiedAt != nil {
+				item.NotifiedAt = notifiedAt
+			}
+			return nil
+		},
+	}
+	notifier := &recordingNotifier{}
+	svc := &wishlistService{repo: repo, notificationService: notifier, now: time.Now, logger: zap.NewNop()}
+	ctx := context.Background()
+
+	p.StockQuantity = 12
+	if err := svc.ScanAlerts(ctx); err != nil {
+		t.Fatalf("ScanAlerts: %v", err)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].Title != "Cetamol is back in stock" || notifier.sent[0].Type != "wishlist" {
+		t.Fatalf("restock: sent %+v", notifier.sent)
+	}
+	_ = svc.ScanAlerts(ctx)
+	if len(notifier.sent) != 1 {
+		t.Fatalf("unchanged product notified again: %d", len(notifier.sent))
+	}
+	p.UnitPrice = 45
+	_ = svc.ScanAlerts(ctx)
+	if len(notifier.sent) != 2 || !strings.Contains(notifier.sent[1].Message, "NPR 45.00 (was 50.00)") {
+		t.Fatalf("price drop: sent %+v", notifier.sent)
+	}
+	// Selling out and a price rise are recorded silently; the next restock alerts again.
+	p.StockQuantity, p.UnitPrice = 0, 48
+	_ = svc.ScanAlerts(ctx)
+	if len(notifier.sent) != 2 || item.SeenInStock || item.SeenPrice != 48 {
+		t.Fatalf("sold out: sent %d, seen %v/%v", len(notifier.sent), item.SeenInStock, item.SeenPrice)
+	}
+	p.StockQuantity = 3
+	_ = svc.ScanAlerts(ctx)
+	if len(notifier.sent) != 3 {
+		t.Fatalf("second restock: sent %d", len(notifier.sent))
+	}
+}
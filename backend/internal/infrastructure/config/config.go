@@ -3,6 +3,8 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
+	"strconv"
 	"strings"
 	"time"
 
@@ -10,19 +12,199 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	CORS     CORSConfig
-	FS       FSConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	JWT       JWTConfig
+	CORS      CORSConfig
+	FS        FSConfig
+	LLM       LLMConfig
+	Email     EmailConfig
+	SMS       SMSConfig
+	Push      PushConfig
+	API       APIConfig
+	Ticketing TicketingConfig
+	Queue     QueueConfig
+	Webhook   WebhookConfig
+	Scheduler SchedulerConfig
+	RateLimit RateLimitConfig
+	Tracing   TracingConfig
+}
+
+// TracingConfig sets up OpenTelemetry tracing: a span per HTTP request, spans around the order path's service
+// methods and one per GORM query. TRACING_EXPORTER=none (default) leaves tracing off; otlp sends spans over
+// OTLP/HTTP to Endpoint (a collector, Jaeger or Tempo); stdout prints them, for local debugging.
+type TracingConfig struct {
+	Exporter    string  // "none", "otlp" or "stdout"
+	Endpoint    string  // OTLP/HTTP host:port, OTEL_EXPORTER_OTLP_ENDPOINT
+	Insecure    bool    // plain HTTP to the collector
+	ServiceName string  // OTEL_SERVICE_NAME
+	SampleRatio float64 // share of new traces kept, 0–1; requests carrying a sampled traceparent are always kept
+}
+
+// Enabled reports whether spans are exported.
+func (t TracingConfig) Enabled() bool { return t.Exporter != "none" }
+
+// RateLimitConfig holds the token-bucket limits on abuse-prone endpoints. RATE_LIMIT_STORE=memory (default) counts
+// per API instance; redis shares the buckets across instances. Rules are "<requests>/<window>", e.g. 10/1m;
+// "off" disables one. Exceeding a rule returns 429 with Retry-After.
+type RateLimitConfig struct {
+	Enabled       bool   // RATE_LIMIT_ENABLED=false turns all limits off
+	Store         string // "memory" or "redis"
+	RedisAddr     string // host:port
+	RedisPassword string
+	RedisDB       int
+	RedisTimeout  time.Duration
+	Login         RateLimitRule // per client IP
+	Register      RateLimitRule // per client IP
+	Catalog       RateLimitRule // public product listing and detail, per client IP
+	Chat          RateLimitRule // chat REST per user (or chat customer); the WebSocket handshake per client IP
+}
+
+// RateLimitRule allows Limit requests per Window, refilled evenly; a zero Limit disables the rule.
+type RateLimitRule struct {
+	Limit  int
+	Window time.Duration
+}
+
+// QueueConfig selects how email, SMS and webhook deliveries are queued. DELIVERY_QUEUE=database (default)
+// stores each delivery as a row that survives restarts and is retried with exponential backoff until
+// MaxAttempts, then kept as a dead letter; memory uses the in-process queues, which lose pending messages on exit.
+type QueueConfig struct {
+	Mode         string        // "database" or "memory"
+	Workers      int           // concurrent deliveries
+	PollInterval time.Duration // how often workers look for due jobs when not woken by a new one
+	MaxAttempts  int
+	BackoffBase  time.Duration // delay after the first failure; doubles per attempt
+	BackoffMax   time.Duration
+	Lease        time.Duration // a claimed job is handed to another worker after this long (crashed instance)
+}
+
+// SchedulerConfig controls the periodic background jobs. SCHEDULER_ENABLED=false turns them all off; only the
+// per-instance API usage flush keeps running. With leader election (the default) every instance may keep the
+// scheduler enabled: the instance holding a Postgres advisory lock runs the jobs and the others stand by.
+type SchedulerConfig struct {
+	Enabled                bool
+	InventoryAlertInterval   time.Duration // how often stock levels and batch expiry are scanned
+	ActivityLogPurgeInterval time.Duration // how often activity log entries past their retention are deleted
+	OutboxDispatchInterval   time.Duration // how often due outbox events are dispatched to subscribers
+	LeaderElection           bool          // SCHEDULER_LEADER_ELECTION=false runs the jobs on every enabled instance
+	LeaderCheckInterval      time.Duration // how often standbys try to take over and the leader writes its heartbeat
+}
+
+// WebhookConfig holds outgoing webhook delivery. Payloads are signed with SigningSecret (X-CarePlus-Signature).
+type WebhookConfig struct {
+	SigningSecret string // WEBHOOK_SIGNING_SECRET, shared with receivers; unset disables outgoing webhooks
+	Timeout       time.Duration
+}
+
+// TicketingConfig holds the external ticketing system that escalated chat conversations are sent to.
+// TICKETING_PROVIDER=none, webhook (generic JSON POST), jira or freshdesk. WebhookSecret signs outgoing
+// webhook payloads and authenticates status callbacks from the ticketing system.
+type TicketingConfig struct {
+	Provider        string // "none" (escalation stays in-app), "webhook", "jira" or "freshdesk"
+	WebhookURL      string
+	WebhookSecret   string
+	JiraBaseURL     string // e.g. https://acme.atlassian.net
+	JiraEmail       string
+	JiraAPIToken    string
+	JiraProjectKey  string
+	JiraIssueType   string
+	FreshdeskURL    string // e.g. https://acme.freshdesk.com
+	FreshdeskAPIKey string
+	Timeout         time.Duration
+}
+
+// APIConfig controls public API versioning. When V1DeprecatedAt is set, /api/v1 responses carry
+// Deprecation (and Sunset, when V1SunsetAt is set) headers so clients can plan the move to /api/v2.
+type APIConfig struct {
+	V1DeprecatedAt time.Time // API_V1_DEPRECATED_AT (YYYY-MM-DD or RFC 3339); zero = not deprecated
+	V1SunsetAt     time.Time // API_V1_SUNSET_AT; zero = no removal date announced
+}
+
+// PushConfig holds push notification delivery. PUSH_PROVIDER=none, log (development) or fcm.
+type PushConfig struct {
+	Provider           string // "none" (disabled), "log" or "fcm" (Firebase Cloud Messaging HTTP v1)
+	FCMCredentialsFile string // path to the Firebase service-account JSON key
+	FCMProjectID       string // optional; defaults to project_id from the key file
+	Timeout            time.Duration
+	QueueSize          int
+	Workers            int
+}
+
+// SMSConfig holds the SMS gateway. SMS_PROVIDER=none, log (development), twilio or sparrow.
+type SMSConfig struct {
+	Provider         string // "none" (disabled), "log", "twilio" or "sparrow" (Sparrow SMS, Nepal)
+	From             string // sender number (Twilio) or sender identity (Sparrow)
+	TwilioAccountSID string
+	TwilioAuthToken  string
+	SparrowToken     string
+	Timeout          time.Duration
+	QueueSize        int
+	Workers          int
+}
+
+// EmailConfig holds outbound mail settings. EMAIL_PROVIDER=log (development) or smtp.
+type EmailConfig struct {
+	Provider     string // "log" (write to application log) or "smtp"
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	From         string // envelope and header sender address
+	FromName     string
+	QueueSize    int // buffered messages waiting for delivery; sends beyond this are dropped
+	Workers      int
+}
+
+// LLMConfig holds the text-generation provider used for AI drafts. LLM_PROVIDER=none, stub or openai.
+type LLMConfig struct {
+	Provider     string // "none" (disabled), "stub" (canned text, dev) or "openai" (any OpenAI-compatible API)
+	BaseURL      string // e.g. https://api.openai.com/v1
+	APIKey       string
+	Model        string
+	Timeout      time.Duration
+	MonthlyQuota int // max generations per pharmacy per calendar month
 }
 
 // FSConfig holds file storage settings. FS_TYPE=local or s3.
+// Private files (CVs, prescriptions, return videos) are never served statically: locally they live in
+// LocalPrivateDir and are served at LocalPrivateURL through HMAC-signed links, on S3 under the private/ prefix
+// (of S3_PRIVATE_BUCKET when set) through presigned URLs. Either kind of link expires after SignedURLTTL.
 type FSConfig struct {
-	Type         string // "local" or "s3"
-	LocalBaseDir string // directory for local storage (e.g. ./uploads)
-	LocalBaseURL string // base URL to serve local files (e.g. /uploads)
-	S3           S3Config
+	Type            string // "local" or "s3"
+	LocalBaseDir    string // directory for local storage (e.g. ./uploads)
+	LocalBaseURL    string // base URL to serve local files (e.g. /uploads)
+	LocalPrivateDir string // FS_LOCAL_PRIVATE_DIR
+	LocalPrivateURL string // FS_LOCAL_PRIVATE_URL, the API route serving signed private files
+	SigningSecret   string // FS_SIGNING_SECRET, signs local private links; unset disables local private files
+	SignedURLTTL    time.Duration
+	OrphanGrace     time.Duration // FS_ORPHAN_GRACE, how long an unreleased file may sit unreferenced before it is swept
+	S3              S3Config
+	Image           ImageConfig
+	Upload          UploadConfig
+}
+
+// UploadConfig covers user uploads. Resumable uploads stage their chunks in StagingDir until the last one
+// arrives; with several API instances it must be shared, or chunks routed to one instance. UPLOAD_VIRUS_SCANNER
+// =clamav scans every upload with clamd before it is stored (none, the default, stores files unscanned).
+type UploadConfig struct {
+	StagingDir      string        // UPLOAD_STAGING_DIR
+	PharmacyQuotaMB int64         // UPLOAD_PHARMACY_QUOTA_MB, storage per pharmacy; 0 = unlimited
+	VirusScanner    string        // "none" or "clamav"
+	ClamAVAddr      string        // CLAMAV_ADDR, clamd host:port
+	ClamAVTimeout   time.Duration // CLAMAV_TIMEOUT, per scan
+}
+
+// ImageConfig sizes the variants made for product image uploads. Each variant fits within Size x Size pixels.
+// IMAGE_FORMAT=webp (default) encodes them with libwebp's cwebp (CWebPPath, looked up on PATH); when it is not
+// installed, or with IMAGE_FORMAT=jpeg, they are JPEG.
+type ImageConfig struct {
+	ThumbnailSize int    // IMAGE_THUMBNAIL_SIZE, list thumbnails (default 200)
+	MediumSize    int    // IMAGE_MEDIUM_SIZE, catalog cards (default 600)
+	LargeSize     int    // IMAGE_LARGE_SIZE, product detail (default 1200)
+	Quality       int    // IMAGE_QUALITY, 1-100
+	Format        string // IMAGE_FORMAT, "webp" or "jpeg"
+	CWebPPath     string // IMAGE_CWEBP_PATH
 }
 
 type S3Config struct {
@@ -31,11 +213,20 @@ type S3Config struct {
 	Key     string // AWS_ACCESS_KEY_ID / S3_ACCESS_KEY
 	Secret  string // AWS_SECRET_ACCESS_KEY / S3_SECRET_KEY
 	Endpoint string // optional, for MinIO or custom S3-compatible endpoint
+	// PrivateBucket holds private files (S3_PRIVATE_BUCKET); empty keeps them under private/ in Bucket, whose
+	// public-read policy must then exclude that prefix.
+	PrivateBucket string
 }
 
 type ServerConfig struct {
-	Port        string
-	Environment string
+	Port              string
+	Environment       string
+	PublicURL         string // base URL used in links sent by email (e.g. supplier reply links)
+	LinkSigningSecret string // HMAC secret for signed links; defaults to JWT_ACCESS_SECRET
+	CompressMinSize   int    // COMPRESS_MIN_SIZE: gzip responses from this many bytes (default 1024); negative disables
+	// TRUSTED_PROXIES: IPs or CIDRs allowed to set X-Forwarded-For; empty (default) trusts none, so the
+	// client IP is the connection's remote address.
+	TrustedProxies []string
 }
 
 type DatabaseConfig struct {
@@ -45,6 +236,9 @@ type DatabaseConfig struct {
 	Password string
 	Name     string
 	SSLMode  string
+	// MigrateOnStart applies pending schema migrations when a command connects (DB_MIGRATE_ON_START, default
+	// false). Otherwise commands refuse to start until `go run ./cmd/migrate up` has been run.
+	MigrateOnStart bool
 }
 
 type JWTConfig struct {
@@ -68,8 +262,11 @@ func LoadConfig() (*Config, error) {
 
 	cfg := &Config{
 		Server: ServerConfig{
-			Port:        getEnvOrDefault("PORT", "8090"),
-			Environment: getEnvOrDefault("ENVIRONMENT", "development"),
+			Port:            getEnvOrDefault("PORT", "8090"),
+			Environment:     getEnvOrDefault("ENVIRONMENT", "development"),
+			PublicURL:       getEnvOrDefault("APP_PUBLIC_URL", "http://localhost:8090"),
+			CompressMinSize: getEnvIntOrDefault("COMPRESS_MIN_SIZE", 1024),
+			TrustedProxies:  parseCSV(getEnvOrDefault("TRUSTED_PROXIES", "")),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnvOrDefault("DB_HOST", "localhost"),
@@ -78,6 +275,8 @@ func LoadConfig() (*Config, error) {
 			Password: getEnvOrDefault("DB_PASSWORD", "careplus"),
 			Name:     getEnvOrDefault("DB_NAME", "careplus_pharmacy_db"),
 			SSLMode:  getEnvOrDefault("DB_SSL_MODE", "disable"),
+
+			MigrateOnStart: getEnvOrDefault("DB_MIGRATE_ON_START", "false") == "true",
 		},
 		JWT: JWTConfig{
 			AccessSecret:  getEnvOrDefault("JWT_ACCESS_SECRET", "careplus-jwt-access-secret-min-32-chars"),
@@ -89,20 +288,153 @@ func LoadConfig() (*Config, error) {
 		CORS: CORSConfig{
 			AllowedOrigins: parseCSV(getEnvOrDefault("CORS_ALLOWED_ORIGINS", "http://localhost:5174")),
 			AllowedMethods: parseCSV(getEnvOrDefault("CORS_ALLOWED_METHODS", "GET,POST,PUT,DELETE,PATCH,OPTIONS")),
-			AllowedHeaders: parseCSV(getEnvOrDefault("CORS_ALLOWED_HEADERS", "Content-Type,Authorization")),
+			AllowedHeaders: parseCSV(getEnvOrDefault("CORS_ALLOWED_HEADERS", "Content-Type,Authorization,Idempotency-Key")),
 		},
 		FS: FSConfig{
-			Type:         getEnvOrDefault("FS_TYPE", "local"),
-			LocalBaseDir: getEnvOrDefault("FS_LOCAL_BASE_DIR", "./data/images"),
-			LocalBaseURL: getEnvOrDefault("FS_LOCAL_BASE_URL", "/data/images"),
+			Type:            getEnvOrDefault("FS_TYPE", "local"),
+			LocalBaseDir:    getEnvOrDefault("FS_LOCAL_BASE_DIR", "./data/images"),
+			LocalBaseURL:    getEnvOrDefault("FS_LOCAL_BASE_URL", "/data/images"),
+			LocalPrivateDir: getEnvOrDefault("FS_LOCAL_PRIVATE_DIR", "./data/private"),
+			LocalPrivateURL: getEnvOrDefault("FS_LOCAL_PRIVATE_URL", "/api/v1/files/private"),
+			SigningSecret:   getEnvOrDefault("FS_SIGNING_SECRET", ""),
+			SignedURLTTL:    parseDuration(getEnvOrDefault("FS_SIGNED_URL_TTL", "15m"), 15*time.Minute),
+			OrphanGrace:     parseDuration(getEnvOrDefault("FS_ORPHAN_GRACE", "7d"), 7*24*time.Hour),
 			S3: S3Config{
-				Bucket:   getEnvOrDefault("S3_BUCKET", ""),
-				Region:   getEnvOrDefault("S3_REGION", "us-east-1"),
-				Key:      getEnvOrDefault("S3_ACCESS_KEY", ""),
-				Secret:   getEnvOrDefault("S3_SECRET_KEY", ""),
-				Endpoint: getEnvOrDefault("S3_ENDPOINT", ""),
+				Bucket:        getEnvOrDefault("S3_BUCKET", ""),
+				Region:        getEnvOrDefault("S3_REGION", "us-east-1"),
+				Key:           getEnvOrDefault("S3_ACCESS_KEY", ""),
+				Secret:        getEnvOrDefault("S3_SECRET_KEY", ""),
+				Endpoint:      getEnvOrDefault("S3_ENDPOINT", ""),
+				PrivateBucket: getEnvOrDefault("S3_PRIVATE_BUCKET", ""),
+			},
+			Image: ImageConfig{
+				ThumbnailSize: getEnvIntOrDefault("IMAGE_THUMBNAIL_SIZE", 200),
+				MediumSize:    getEnvIntOrDefault("IMAGE_MEDIUM_SIZE", 600),
+				LargeSize:     getEnvIntOrDefault("IMAGE_LARGE_SIZE", 1200),
+				Quality:       getEnvIntOrDefault("IMAGE_QUALITY", 80),
+				Format:        getEnvOrDefault("IMAGE_FORMAT", "webp"),
+				CWebPPath:     getEnvOrDefault("IMAGE_CWEBP_PATH", "cwebp"),
 			},
+			Upload: UploadConfig{
+				StagingDir:      getEnvOrDefault("UPLOAD_STAGING_DIR", "./data/upload-staging"),
+				PharmacyQuotaMB: int64(getEnvIntOrDefault("UPLOAD_PHARMACY_QUOTA_MB", 10240)),
+				VirusScanner:    getEnvOrDefault("UPLOAD_VIRUS_SCANNER", "none"),
+				ClamAVAddr:      getEnvOrDefault("CLAMAV_ADDR", "localhost:3310"),
+				ClamAVTimeout:   parseDuration(getEnvOrDefault("CLAMAV_TIMEOUT", "60s"), 60*time.Second),
+			},
+		},
+		LLM: LLMConfig{
+			Provider:     getEnvOrDefault("LLM_PROVIDER", "none"),
+			BaseURL:      getEnvOrDefault("LLM_BASE_URL", "https://api.openai.com/v1"),
+			APIKey:       getEnvOrDefault("LLM_API_KEY", ""),
+			Model:        getEnvOrDefault("LLM_MODEL", "gpt-4o-mini"),
+			Timeout:      parseDuration(getEnvOrDefault("LLM_TIMEOUT", "30s"), 30*time.Second),
+			MonthlyQuota: getEnvIntOrDefault("LLM_MONTHLY_QUOTA", 200),
+		},
+		Email: EmailConfig{
+			Provider:     getEnvOrDefault("EMAIL_PROVIDER", "log"),
+			SMTPHost:     getEnvOrDefault("SMTP_HOST", ""),
+			SMTPPort:     getEnvIntOrDefault("SMTP_PORT", 587),
+			SMTPUsername: getEnvOrDefault("SMTP_USERNAME", ""),
+			SMTPPassword: getEnvOrDefault("SMTP_PASSWORD", ""),
+			From:         getEnvOrDefault("EMAIL_FROM", "no-reply@careplus.local"),
+			FromName:     getEnvOrDefault("EMAIL_FROM_NAME", "CarePlus Pharmacy"),
+			QueueSize:    getEnvIntOrDefault("EMAIL_QUEUE_SIZE", 500),
+			Workers:      getEnvIntOrDefault("EMAIL_WORKERS", 2),
+		},
+		SMS: SMSConfig{
+			Provider:         getEnvOrDefault("SMS_PROVIDER", "none"),
+			From:             getEnvOrDefault("SMS_FROM", ""),
+			TwilioAccountSID: getEnvOrDefault("TWILIO_ACCOUNT_SID", ""),
+			TwilioAuthToken:  getEnvOrDefault("TWILIO_AUTH_TOKEN", ""),
+			SparrowToken:     getEnvOrDefault("SPARROW_SMS_TOKEN", ""),
+			Timeout:          parseDuration(getEnvOrDefault("SMS_TIMEOUT", "10s"), 10*time.Second),
+			QueueSize:        getEnvIntOrDefault("SMS_QUEUE_SIZE", 500),
+			Workers:          getEnvIntOrDefault("SMS_WORKERS", 2),
+		},
+		Push: PushConfig{
+			Provider:           getEnvOrDefault("PUSH_PROVIDER", "none"),
+			FCMCredentialsFile: getEnvOrDefault("FCM_CREDENTIALS_FILE", ""),
+			FCMProjectID:       getEnvOrDefault("FCM_PROJECT_ID", ""),
+			Timeout:            parseDuration(getEnvOrDefault("PUSH_TIMEOUT", "10s"), 10*time.Second),
+			QueueSize:          getEnvIntOrDefault("PUSH_QUEUE_SIZE", 1000),
+			Workers:            getEnvIntOrDefault("PUSH_WORKERS", 4),
+		},
+		Ticketing: TicketingConfig{
+			Provider:        getEnvOrDefault("TICKETING_PROVIDER", "none"),
+			WebhookURL:      getEnvOrDefault("TICKETING_WEBHOOK_URL", ""),
+			WebhookSecret:   getEnvOrDefault("TICKETING_WEBHOOK_SECRET", ""),
+			JiraBaseURL:     strings.TrimRight(getEnvOrDefault("JIRA_BASE_URL", ""), "/"),
+			JiraEmail:       getEnvOrDefault("JIRA_EMAIL", ""),
+			JiraAPIToken:    getEnvOrDefault("JIRA_API_TOKEN", ""),
+			JiraProjectKey:  getEnvOrDefault("JIRA_PROJECT_KEY", ""),
+			JiraIssueType:   getEnvOrDefault("JIRA_ISSUE_TYPE", "Task"),
+			FreshdeskURL:    strings.TrimRight(getEnvOrDefault("FRESHDESK_URL", ""), "/"),
+			FreshdeskAPIKey: getEnvOrDefault("FRESHDESK_API_KEY", ""),
+			Timeout:         parseDuration(getEnvOrDefault("TICKETING_TIMEOUT", "10s"), 10*time.Second),
+		},
+		Queue: QueueConfig{
+			Mode:         getEnvOrDefault("DELIVERY_QUEUE", "database"),
+			Workers:      getEnvIntOrDefault("QUEUE_WORKERS", 4),
+			PollInterval: parseDuration(getEnvOrDefault("QUEUE_POLL_INTERVAL", "5s"), 5*time.Second),
+			MaxAttempts:  getEnvIntOrDefault("QUEUE_MAX_ATTEMPTS", 8),
+			BackoffBase:  parseDuration(getEnvOrDefault("QUEUE_BACKOFF_BASE", "30s"), 30*time.Second),
+			BackoffMax:   parseDuration(getEnvOrDefault("QUEUE_BACKOFF_MAX", "1h"), time.Hour),
+			Lease:        parseDuration(getEnvOrDefault("QUEUE_LEASE", "2m"), 2*time.Minute),
+		},
+		Webhook: WebhookConfig{
+			SigningSecret: getEnvOrDefault("WEBHOOK_SIGNING_SECRET", ""),
+			Timeout:       parseDuration(getEnvOrDefault("WEBHOOK_TIMEOUT", "10s"), 10*time.Second),
 		},
+		Scheduler: SchedulerConfig{
+			Enabled:                  getEnvOrDefault("SCHEDULER_ENABLED", "true") != "false",
+			InventoryAlertInterval:   parseDuration(getEnvOrDefault("INVENTORY_ALERT_INTERVAL", "1h"), time.Hour),
+			ActivityLogPurgeInterval: parseDuration(getEnvOrDefault("ACTIVITY_LOG_PURGE_INTERVAL", "24h"), 24*time.Hour),
+			OutboxDispatchInterval:   parseDuration(getEnvOrDefault("OUTBOX_DISPATCH_INTERVAL", "5s"), 5*time.Second),
+			LeaderElection:           getEnvOrDefault("SCHEDULER_LEADER_ELECTION", "true") != "false",
+			LeaderCheckInterval:      parseDuration(getEnvOrDefault("SCHEDULER_LEADER_CHECK_INTERVAL", "10s"), 10*time.Second),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:       getEnvOrDefault("RATE_LIMIT_ENABLED", "true") != "false",
+			Store:         getEnvOrDefault("RATE_LIMIT_STORE", "memory"),
+			RedisAddr:     getEnvOrDefault("REDIS_ADDR", "localhost:6379"),
+			RedisPassword: getEnvOrDefault("REDIS_PASSWORD", ""),
+			RedisDB:       getEnvIntOrDefault("REDIS_DB", 0),
+			RedisTimeout:  parseDuration(getEnvOrDefault("REDIS_TIMEOUT", "500ms"), 500*time.Millisecond),
+		},
+		Tracing: TracingConfig{
+			Exporter:    getEnvOrDefault("TRACING_EXPORTER", "none"),
+			Endpoint:    getEnvOrDefault("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4318"),
+			Insecure:    getEnvOrDefault("TRACING_INSECURE", "true") != "false",
+			ServiceName: getEnvOrDefault("OTEL_SERVICE_NAME", "careplus-api"),
+		},
+	}
+
+	cfg.Server.LinkSigningSecret = getEnvOrDefault("LINK_SIGNING_SECRET", cfg.JWT.AccessSecret)
+
+	var err error
+	rules := []struct {
+		env, def string
+		rule     *RateLimitRule
+	}{
+		{"RATE_LIMIT_LOGIN", "10/1m", &cfg.RateLimit.Login},
+		{"RATE_LIMIT_REGISTER", "5/10m", &cfg.RateLimit.Register},
+		{"RATE_LIMIT_CATALOG", "120/1m", &cfg.RateLimit.Catalog},
+		{"RATE_LIMIT_CHAT", "60/1m", &cfg.RateLimit.Chat},
+	}
+	for _, r := range rules {
+		if *r.rule, err = parseRateLimitRule(getEnvOrDefault(r.env, r.def)); err != nil {
+			return nil, fmt.Errorf("%s: %w", r.env, err)
+		}
+	}
+	if cfg.Tracing.SampleRatio, err = strconv.ParseFloat(getEnvOrDefault("TRACING_SAMPLE_RATIO", "1"), 64); err != nil {
+		return nil, fmt.Errorf("TRACING_SAMPLE_RATIO: %w", err)
+	}
+	if cfg.API.V1DeprecatedAt, err = parseDate(getEnvOrDefault("API_V1_DEPRECATED_AT", "")); err != nil {
+		return nil, fmt.Errorf("API_V1_DEPRECATED_AT: %w", err)
+	}
+	if cfg.API.V1SunsetAt, err = parseDate(getEnvOrDefault("API_V1_SUNSET_AT", "")); err != nil {
+		return nil, fmt.Errorf("API_V1_SUNSET_AT: %w", err)
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -118,6 +450,28 @@ func (c *Config) Validate() error {
 	if len(c.JWT.RefreshSecret) < 32 {
 		return errors.New("JWT_REFRESH_SECRET must be at least 32 characters")
 	}
+	// The webhook secret is handed to every receiver, so it must not be one that also signs tokens or links.
+	if s := c.Webhook.SigningSecret; s != "" {
+		if len(s) < 32 {
+			return errors.New("WEBHOOK_SIGNING_SECRET must be at least 32 characters")
+		}
+		if s == c.JWT.AccessSecret || s == c.JWT.RefreshSecret || s == c.Server.LinkSigningSecret {
+			return errors.New("WEBHOOK_SIGNING_SECRET must differ from the JWT and link signing secrets")
+		}
+	}
+	if s := c.FS.SigningSecret; s != "" {
+		if len(s) < 32 {
+			return errors.New("FS_SIGNING_SECRET must be at least 32 characters")
+		}
+		if s == c.JWT.AccessSecret || s == c.JWT.RefreshSecret || s == c.Server.LinkSigningSecret || s == c.Webhook.SigningSecret {
+			return errors.New("FS_SIGNING_SECRET must differ from the JWT, link and webhook signing secrets")
+		}
+	}
+	for _, p := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(p); err != nil && net.ParseIP(p) == nil {
+			return fmt.Errorf("TRUSTED_PROXIES: %q is not an IP or CIDR", p)
+		}
+	}
 	if c.Database.Host == "" || c.Database.User == "" || c.Database.Name == "" {
 		return errors.New("DB_HOST, DB_USER, DB_NAME are required")
 	}
@@ -132,6 +486,151 @@ func (c *Config) Validate() error {
 	if c.FS.Type == "s3" && (c.FS.S3.Bucket == "" || c.FS.S3.Key == "" || c.FS.S3.Secret == "") {
 		return errors.New("S3_BUCKET, S3_ACCESS_KEY, S3_SECRET_KEY are required when FS_TYPE=s3")
 	}
+	if img := c.FS.Image; img.ThumbnailSize <= 0 || img.MediumSize <= 0 || img.LargeSize <= 0 {
+		return errors.New("IMAGE_THUMBNAIL_SIZE, IMAGE_MEDIUM_SIZE and IMAGE_LARGE_SIZE must be positive")
+	}
+	if c.FS.Image.Quality < 1 || c.FS.Image.Quality > 100 {
+		return fmt.Errorf("IMAGE_QUALITY must be between 1 and 100, got %d", c.FS.Image.Quality)
+	}
+	if c.FS.Image.Format != "webp" && c.FS.Image.Format != "jpeg" {
+		return fmt.Errorf("IMAGE_FORMAT must be 'webp' or 'jpeg', got %q", c.FS.Image.Format)
+	}
+	if c.FS.Upload.VirusScanner != "none" && c.FS.Upload.VirusScanner != "clamav" {
+		return fmt.Errorf("UPLOAD_VIRUS_SCANNER must be 'none' or 'clamav', got %q", c.FS.Upload.VirusScanner)
+	}
+	if c.FS.Upload.PharmacyQuotaMB < 0 {
+		return errors.New("UPLOAD_PHARMACY_QUOTA_MB must not be negative")
+	}
+	switch c.LLM.Provider {
+	case "none", "stub", "openai":
+		// valid
+	case "":
+		c.LLM.Provider = "none"
+	default:
+		return fmt.Errorf("LLM_PROVIDER must be 'none', 'stub' or 'openai', got %q", c.LLM.Provider)
+	}
+	if c.LLM.Provider == "openai" && c.LLM.APIKey == "" {
+		return errors.New("LLM_API_KEY is required when LLM_PROVIDER=openai")
+	}
+	switch c.Email.Provider {
+	case "log", "smtp":
+		// valid
+	case "":
+		c.Email.Provider = "log"
+	default:
+		return fmt.Errorf("EMAIL_PROVIDER must be 'log' or 'smtp', got %q", c.Email.Provider)
+	}
+	if c.Email.Provider == "smtp" && (c.Email.SMTPHost == "" || c.Email.From == "") {
+		return errors.New("SMTP_HOST and EMAIL_FROM are required when EMAIL_PROVIDER=smtp")
+	}
+	switch c.SMS.Provider {
+	case "none", "log":
+		// valid
+	case "twilio":
+		if c.SMS.TwilioAccountSID == "" || c.SMS.TwilioAuthToken == "" || c.SMS.From == "" {
+			return errors.New("TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and SMS_FROM are required when SMS_PROVIDER=twilio")
+		}
+	case "sparrow":
+		if c.SMS.SparrowToken == "" || c.SMS.From == "" {
+			return errors.New("SPARROW_SMS_TOKEN and SMS_FROM are required when SMS_PROVIDER=sparrow")
+		}
+	case "":
+		c.SMS.Provider = "none"
+	default:
+		return fmt.Errorf("SMS_PROVIDER must be 'none', 'log', 'twilio' or 'sparrow', got %q", c.SMS.Provider)
+	}
+	switch c.Push.Provider {
+	case "none", "log":
+		// valid
+	case "fcm":
+		if c.Push.FCMCredentialsFile == "" {
+			return errors.New("FCM_CREDENTIALS_FILE is required when PUSH_PROVIDER=fcm")
+		}
+	case "":
+		c.Push.Provider = "none"
+	default:
+		return fmt.Errorf("PUSH_PROVIDER must be 'none', 'log' or 'fcm', got %q", c.Push.Provider)
+	}
+	switch c.Ticketing.Provider {
+	case "none":
+	case "":
+		c.Ticketing.Provider = "none"
+	case "webhook":
+		if c.Ticketing.WebhookURL == "" {
+			return errors.New("TICKETING_WEBHOOK_URL is required when TICKETING_PROVIDER=webhook")
+		}
+	case "jira":
+		if c.Ticketing.JiraBaseURL == "" || c.Ticketing.JiraEmail == "" || c.Ticketing.JiraAPIToken == "" || c.Ticketing.JiraProjectKey == "" {
+			return errors.New("JIRA_BASE_URL, JIRA_EMAIL, JIRA_API_TOKEN and JIRA_PROJECT_KEY are required when TICKETING_PROVIDER=jira")
+		}
+	case "freshdesk":
+		if c.Ticketing.FreshdeskURL == "" || c.Ticketing.FreshdeskAPIKey == "" {
+			return errors.New("FRESHDESK_URL and FRESHDESK_API_KEY are required when TICKETING_PROVIDER=freshdesk")
+		}
+	default:
+		return fmt.Errorf("TICKETING_PROVIDER must be 'none', 'webhook', 'jira' or 'freshdesk', got %q", c.Ticketing.Provider)
+	}
+	if c.Ticketing.Provider != "none" && len(c.Ticketing.WebhookSecret) < 16 {
+		return errors.New("TICKETING_WEBHOOK_SECRET (at least 16 characters) is required when a ticketing provider is set")
+	}
+	switch c.Queue.Mode {
+	case "database", "memory":
+	case "":
+		c.Queue.Mode = "database"
+	default:
+		return fmt.Errorf("DELIVERY_QUEUE must be 'database' or 'memory', got %q", c.Queue.Mode)
+	}
+	if c.Queue.MaxAttempts < 1 {
+		return errors.New("QUEUE_MAX_ATTEMPTS must be at least 1")
+	}
+	if c.Queue.BackoffBase <= 0 || c.Queue.BackoffMax < c.Queue.BackoffBase {
+		return errors.New("QUEUE_BACKOFF_BASE must be positive and QUEUE_BACKOFF_MAX not less than it")
+	}
+	if c.Scheduler.InventoryAlertInterval < time.Minute {
+		return errors.New("INVENTORY_ALERT_INTERVAL must be at least 1m")
+	}
+	if c.Scheduler.ActivityLogPurgeInterval < time.Hour {
+		return errors.New("ACTIVITY_LOG_PURGE_INTERVAL must be at least 1h")
+	}
+	if c.Scheduler.OutboxDispatchInterval < time.Second {
+		return errors.New("OUTBOX_DISPATCH_INTERVAL must be at least 1s")
+	}
+	if c.Scheduler.LeaderCheckInterval < time.Second || c.Scheduler.LeaderCheckInterval > 5*time.Minute {
+		return errors.New("SCHEDULER_LEADER_CHECK_INTERVAL must be between 1s and 5m")
+	}
+	switch c.RateLimit.Store {
+	case "memory":
+	case "":
+		c.RateLimit.Store = "memory"
+	case "redis":
+		if c.RateLimit.RedisAddr == "" {
+			return errors.New("REDIS_ADDR is required when RATE_LIMIT_STORE=redis")
+		}
+	default:
+		return fmt.Errorf("RATE_LIMIT_STORE must be 'memory' or 'redis', got %q", c.RateLimit.Store)
+	}
+	switch c.Tracing.Exporter {
+	case "none", "stdout":
+	case "":
+		c.Tracing.Exporter = "none"
+	case "otlp":
+		if c.Tracing.Endpoint == "" {
+			return errors.New("OTEL_EXPORTER_OTLP_ENDPOINT is required when TRACING_EXPORTER=otlp")
+		}
+	default:
+		return fmt.Errorf("TRACING_EXPORTER must be 'none', 'otlp' or 'stdout', got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return errors.New("TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
+	if !c.API.V1SunsetAt.IsZero() {
+		if c.API.V1DeprecatedAt.IsZero() {
+			return errors.New("API_V1_SUNSET_AT requires API_V1_DEPRECATED_AT")
+		}
+		if !c.API.V1SunsetAt.After(c.API.V1DeprecatedAt) {
+			return errors.New("API_V1_SUNSET_AT must be after API_V1_DEPRECATED_AT")
+		}
+	}
 	return nil
 }
 
@@ -167,6 +666,37 @@ func parseCSV(s string) []string {
 	}
 	return out
 }
+// parseDate accepts YYYY-MM-DD (midnight UTC) or RFC 3339; empty returns the zero time.
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+// parseRateLimitRule parses "<requests>/<window>" (e.g. 10/1m); "off" or "0" gives the zero (disabled) rule.
+func parseRateLimitRule(s string) (RateLimitRule, error) {
+	s = strings.TrimSpace(s)
+	if s == "off" || s == "0" {
+		return RateLimitRule{}, nil
+	}
+	n, w, ok := strings.Cut(s, "/")
+	if !ok {
+		return RateLimitRule{}, fmt.Errorf("want <requests>/<window>, got %q", s)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(n))
+	if err != nil || limit < 0 {
+		return RateLimitRule{}, fmt.Errorf("invalid request count in %q", s)
+	}
+	window := parseDuration(strings.TrimSpace(w), 0)
+	if window <= 0 {
+		return RateLimitRule{}, fmt.Errorf("invalid window in %q", s)
+	}
+	return RateLimitRule{Limit: limit, Window: window}, nil
+}
 func parseDuration(s string, defaultD time.Duration) time.Duration {
 	if strings.HasSuffix(s, "d") {
 		if d, err := time.ParseDuration(s[:len(s)-1] + "h"); err == nil {
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/careplus/pharmacy-backend/internal/infrastructure/database/migrations"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrSchemaOutdated is returned when the database is behind the migrations compiled into the binary.
+var ErrSchemaOutdated = errors.New("database schema is out of date")
+
+// NewMigrator returns a goose provider over the embedded migrations. Versions are tracked in
+// goose_db_version, and a Postgres advisory lock keeps concurrent runs (e.g. several instances with
+// DB_MIGRATE_ON_START) from applying the same migration twice.
+func NewMigrator(db *gorm.DB) (*goose.Provider, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying database: %w", err)
+	}
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, err
+	}
+	return goose.NewProvider(goose.DialectPostgres, sqlDB, migrations.FS, goose.WithSessionLocker(locker))
+}
+
+// ensureSchema applies pending migrations when migrate is set and otherwise fails with ErrSchemaOutdated
+// while any are pending. A database ahead of the binary (a newer release already migrated it) is allowed.
+func ensureSchema(ctx context.Context, db *gorm.DB, migrate bool, log *zap.Logger) error {
+	m, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	if migrate {
+		results, err := m.Up(ctx)
+		if err != nil {
+			return fmt.Errorf("migrate up failed: %w", err)
+		}
+		for _, r := range results {
+			log.Info("Applied migration", zap.String("migration", r.Source.Path), zap.Duration("duration", r.Duration))
+		}
+		return nil
+	}
+	current, target, err := m.GetVersions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	pending, err := m.HasPending(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check pending migrations: %w", err)
+	}
+	if pending {
+		return fmt.Errorf("%w: database is at version %d, this build needs %d; run `go run ./cmd/migrate up` or set DB_MIGRATE_ON_START=true", ErrSchemaOutdated, current, target)
+	}
+	if current > target {
+		log.Warn("Database schema is newer than this build", zap.Int64("version", current), zap.Int64("expected", target))
+	}
+	return nil
+}
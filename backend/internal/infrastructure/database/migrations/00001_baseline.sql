@@ -0,0 +1,2193 @@
+-- Baseline: the schema as GORM AutoMigrate left it before versioned migrations were introduced.
+-- Every statement is idempotent so the baseline can also be applied to a database AutoMigrate created:
+-- existing tables and indexes are kept, and foreign keys are re-created under the same names.
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "pharmacies" (
+    "id" uuid,
+    "name" varchar(255) NOT NULL,
+    "license_no" varchar(100),
+    "tenant_code" varchar(64),
+    "hostname_slug" varchar(128),
+    "business_type" varchar(32) DEFAULT 'pharmacy',
+    "group_code" varchar(64),
+    "organization_id" uuid,
+    "address" text,
+    "phone" varchar(50),
+    "email" varchar(255),
+    "is_active" boolean DEFAULT true,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_pharmacies_deleted_at" ON "pharmacies" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_pharmacies_organization_id" ON "pharmacies" ("organization_id");
+CREATE INDEX IF NOT EXISTS "idx_pharmacies_group_code" ON "pharmacies" ("group_code");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_pharmacies_hostname_slug" ON "pharmacies" ("hostname_slug");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_pharmacies_tenant_code" ON "pharmacies" ("tenant_code");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_pharmacies_license_no" ON "pharmacies" ("license_no");
+
+CREATE TABLE IF NOT EXISTS "pharmacy_configs" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "display_name" varchar(255),
+    "location" text,
+    "logo_url" varchar(512),
+    "banner_url" varchar(512),
+    "tagline" varchar(500),
+    "contact_phone" varchar(50),
+    "contact_email" varchar(255),
+    "primary_color" varchar(20),
+    "default_language" varchar(16) DEFAULT 'en',
+    "website_enabled" boolean DEFAULT true,
+    "feature_flags" jsonb,
+    "license_no" varchar(100),
+    "verified_at" timestamptz,
+    "established_year" bigint DEFAULT 0,
+    "return_refund_policy" text,
+    "chat_edit_window_minutes" bigint DEFAULT 10,
+    "tax_enabled" boolean DEFAULT false,
+    "tax_rates" jsonb,
+    "prices_include_tax" boolean DEFAULT false,
+    "tax_registration_no" varchar(100),
+    "expiry_discount" jsonb,
+    "business_hours" jsonb,
+    "return_rate_alert" jsonb,
+    "inventory_alerts" jsonb,
+    "delivery_fee" decimal(12,2) DEFAULT 0,
+    "delivery_otp_required" boolean DEFAULT false,
+    "order_fields" jsonb,
+    "product_attributes" jsonb,
+    "activity_log_retention_days" bigint DEFAULT 0,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_pharmacy_configs_deleted_at" ON "pharmacy_configs" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_pharmacy_configs_verified_at" ON "pharmacy_configs" ("verified_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_pharmacy_configs_pharmacy_id" ON "pharmacy_configs" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "email" varchar(255) NOT NULL,
+    "password_hash" varchar(255) NOT NULL,
+    "name" varchar(255),
+    "role" varchar(50) DEFAULT 'staff',
+    "points_balance" bigint DEFAULT 0,
+    "is_active" boolean DEFAULT true,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "license_number" varchar(100),
+    "qualification" varchar(255),
+    "cv_url" varchar(512),
+    "photo_url" varchar(512),
+    "date_of_birth" timestamptz,
+    "gender" varchar(50),
+    "phone" varchar(50),
+    "preferred_language" varchar(16),
+    "branch_id" uuid,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_users_branch_id" ON "users" ("branch_id");
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email");
+CREATE INDEX IF NOT EXISTS "idx_users_pharmacy_id" ON "users" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "categories" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "parent_id" uuid,
+    "name" varchar(100) NOT NULL,
+    "description" text,
+    "sort_order" bigint DEFAULT 0,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_categories_deleted_at" ON "categories" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_categories_parent_id" ON "categories" ("parent_id");
+CREATE INDEX IF NOT EXISTS "idx_categories_pharmacy_id" ON "categories" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "suppliers" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "name" varchar(255) NOT NULL,
+    "contact_name" varchar(255),
+    "email" varchar(255),
+    "phone" varchar(50),
+    "address" text,
+    "lead_time_days" bigint DEFAULT 3,
+    "is_active" boolean DEFAULT true,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_suppliers_deleted_at" ON "suppliers" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_suppliers_pharmacy_id" ON "suppliers" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "products" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "name" varchar(255) NOT NULL,
+    "description" text,
+    "sku" varchar(100) NOT NULL,
+    "category" varchar(100),
+    "category_id" uuid,
+    "supplier_id" uuid,
+    "unit_price" decimal(12,2) NOT NULL,
+    "discount_percent" decimal(5,2) DEFAULT 0,
+    "currency" varchar(10) DEFAULT 'NPR',
+    "stock_quantity" bigint DEFAULT 0,
+    "reorder_level" bigint,
+    "reorder_quantity" bigint,
+    "max_stock" bigint,
+    "unit" varchar(50) DEFAULT 'units',
+    "requires_rx" boolean DEFAULT false,
+    "is_active" boolean DEFAULT true,
+    "expiry_date" timestamptz,
+    "manufacturing_date" timestamptz,
+    "brand" varchar(150),
+    "barcode" varchar(100),
+    "storage_conditions" varchar(255),
+    "dosage_form" varchar(80),
+    "pack_size" varchar(80),
+    "generic_name" varchar(255),
+    "tax_class" varchar(30),
+    "preorder_enabled" boolean DEFAULT false,
+    "preorder_deposit_percent" decimal(5,2) DEFAULT 0,
+    "preorder_expected_at" timestamptz,
+    "tracks_serials" boolean DEFAULT false,
+    "warranty_months" bigint DEFAULT 0,
+    "hashtags" jsonb,
+    "labels" jsonb,
+    "attributes" jsonb,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_products_deleted_at" ON "products" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_products_attributes" ON "products" USING gin("attributes");
+CREATE INDEX IF NOT EXISTS "idx_products_barcode" ON "products" ("barcode");
+CREATE INDEX IF NOT EXISTS "idx_products_manufacturing_date" ON "products" ("manufacturing_date");
+CREATE INDEX IF NOT EXISTS "idx_products_expiry_date" ON "products" ("expiry_date");
+CREATE INDEX IF NOT EXISTS "idx_products_supplier_id" ON "products" ("supplier_id");
+CREATE INDEX IF NOT EXISTS "idx_products_category_id" ON "products" ("category_id");
+CREATE INDEX IF NOT EXISTS "idx_products_category" ON "products" ("category");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_products_sku" ON "products" ("sku");
+CREATE INDEX IF NOT EXISTS "idx_products_pharmacy_id" ON "products" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "product_images" (
+    "id" uuid,
+    "product_id" uuid NOT NULL,
+    "url" varchar(512) NOT NULL,
+    "low_url" varchar(512),
+    "is_primary" boolean DEFAULT false,
+    "sort_order" bigint DEFAULT 0,
+    "created_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_product_images_deleted_at" ON "product_images" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_product_images_product_id" ON "product_images" ("product_id");
+
+CREATE TABLE IF NOT EXISTS "product_units" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "name" varchar(100) NOT NULL,
+    "description" text,
+    "sort_order" bigint DEFAULT 0,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_product_units_deleted_at" ON "product_units" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_product_units_pharmacy_id" ON "product_units" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "memberships" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "name" varchar(100) NOT NULL,
+    "description" text,
+    "discount_percent" decimal DEFAULT 0,
+    "benefits" jsonb,
+    "is_active" boolean DEFAULT true,
+    "sort_order" bigint DEFAULT 0,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_memberships_deleted_at" ON "memberships" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_memberships_pharmacy_id" ON "memberships" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "product_reviews" (
+    "id" uuid,
+    "product_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "rating" bigint NOT NULL,
+    "title" varchar(200),
+    "body" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_product_reviews_deleted_at" ON "product_reviews" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_product_reviews_user_id" ON "product_reviews" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_product_reviews_product_id" ON "product_reviews" ("product_id");
+
+CREATE TABLE IF NOT EXISTS "review_likes" (
+    "id" uuid,
+    "review_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "created_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_review_likes_deleted_at" ON "review_likes" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_review_user" ON "review_likes" ("review_id","user_id");
+
+CREATE TABLE IF NOT EXISTS "review_comments" (
+    "id" uuid,
+    "review_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "body" text NOT NULL,
+    "parent_id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_review_comments_deleted_at" ON "review_comments" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_review_comments_parent_id" ON "review_comments" ("parent_id");
+CREATE INDEX IF NOT EXISTS "idx_review_comments_user_id" ON "review_comments" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_review_comments_review_id" ON "review_comments" ("review_id");
+
+CREATE TABLE IF NOT EXISTS "promo_codes" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "code" varchar(50) NOT NULL,
+    "discount_type" varchar(20) NOT NULL,
+    "discount_value" decimal(12,2) NOT NULL,
+    "min_order_amount" decimal(12,2) DEFAULT 0,
+    "valid_from" timestamptz NOT NULL,
+    "valid_until" timestamptz NOT NULL,
+    "max_uses" bigint DEFAULT 0,
+    "used_count" bigint DEFAULT 0,
+    "is_active" boolean DEFAULT true,
+    "first_order_only" boolean DEFAULT false,
+    "max_uses_per_user" bigint DEFAULT 0,
+    "product_ids" jsonb,
+    "category_ids" jsonb,
+    "min_item_quantity" bigint DEFAULT 0,
+    "stacking" varchar(20) DEFAULT 'combinable',
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_promo_codes_deleted_at" ON "promo_codes" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_promo_codes_is_active" ON "promo_codes" ("is_active");
+CREATE INDEX IF NOT EXISTS "idx_promo_codes_valid_until" ON "promo_codes" ("valid_until");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_pharmacy_promo_code" ON "promo_codes" ("pharmacy_id","code");
+
+CREATE TABLE IF NOT EXISTS "customers" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "name" varchar(255),
+    "phone" varchar(50) NOT NULL,
+    "email" varchar(255),
+    "referral_code" varchar(20) NOT NULL,
+    "points_balance" bigint NOT NULL DEFAULT 0,
+    "store_credit_balance" decimal(12,2) NOT NULL DEFAULT 0,
+    "referred_by_id" uuid,
+    "preferred_language" varchar(16),
+    "date_of_birth" date,
+    "birthday_gift_year" bigint NOT NULL DEFAULT 0,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_customers_deleted_at" ON "customers" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_customers_referred_by_id" ON "customers" ("referred_by_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_customers_pharmacy_referral" ON "customers" ("pharmacy_id","referral_code");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_customers_pharmacy_phone" ON "customers" ("pharmacy_id","phone");
+
+CREATE TABLE IF NOT EXISTS "customer_memberships" (
+    "id" uuid,
+    "customer_id" uuid NOT NULL,
+    "membership_id" uuid NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_customer_memberships_deleted_at" ON "customer_memberships" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_customer_memberships_membership_id" ON "customer_memberships" ("membership_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_customer_membership_customer" ON "customer_memberships" ("customer_id");
+
+CREATE TABLE IF NOT EXISTS "referral_points_configs" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "points_per_currency_unit" decimal(12,4) NOT NULL DEFAULT 0,
+    "currency_unit_for_points" decimal(12,2) NOT NULL DEFAULT 1,
+    "referral_reward_points" bigint NOT NULL DEFAULT 0,
+    "redemption_rate_points" bigint NOT NULL DEFAULT 100,
+    "redemption_rate_currency" decimal(12,2) NOT NULL DEFAULT 10,
+    "max_redeem_points_per_order" bigint NOT NULL DEFAULT 0,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_referral_points_configs_deleted_at" ON "referral_points_configs" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_referral_points_configs_pharmacy_id" ON "referral_points_configs" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "staff_points_configs" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "points_per_currency_unit" decimal(12,4) NOT NULL DEFAULT 0,
+    "currency_unit_for_points" decimal(12,2) NOT NULL DEFAULT 100,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_staff_points_configs_deleted_at" ON "staff_points_configs" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_staff_points_configs_pharmacy_id" ON "staff_points_configs" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "staff_points_transactions" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "order_id" uuid NOT NULL,
+    "order_number" varchar(50),
+    "sale_amount" decimal(12,2) NOT NULL,
+    "points" bigint NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_staff_points_transactions_order_id" ON "staff_points_transactions" ("order_id");
+CREATE INDEX IF NOT EXISTS "idx_staff_points_transactions_user_id" ON "staff_points_transactions" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_staff_points_pharmacy_created" ON "staff_points_transactions" ("pharmacy_id","created_at");
+
+CREATE TABLE IF NOT EXISTS "customer_segments" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "name" varchar(100) NOT NULL,
+    "description" varchar(500),
+    "criteria" jsonb,
+    "created_by" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_customer_segments_deleted_at" ON "customer_segments" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_customer_segments_pharmacy_id" ON "customer_segments" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "campaigns" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "segment_id" uuid NOT NULL,
+    "name" varchar(100) NOT NULL,
+    "channel" varchar(20) NOT NULL,
+    "subject" varchar(255),
+    "message" text NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'draft',
+    "criteria" jsonb,
+    "recipient_count" bigint NOT NULL DEFAULT 0,
+    "sent_count" bigint NOT NULL DEFAULT 0,
+    "failed_count" bigint NOT NULL DEFAULT 0,
+    "skipped_count" bigint NOT NULL DEFAULT 0,
+    "created_by" uuid,
+    "sent_at" timestamptz,
+    "completed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_campaigns_status" ON "campaigns" ("status");
+CREATE INDEX IF NOT EXISTS "idx_campaigns_segment_id" ON "campaigns" ("segment_id");
+CREATE INDEX IF NOT EXISTS "idx_campaigns_pharmacy_id" ON "campaigns" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "campaign_recipients" (
+    "id" uuid,
+    "campaign_id" uuid NOT NULL,
+    "customer_id" uuid NOT NULL,
+    "status" varchar(20) NOT NULL,
+    "error" varchar(500),
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_campaign_recipients_status" ON "campaign_recipients" ("status");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_campaign_recipient" ON "campaign_recipients" ("campaign_id","customer_id");
+
+CREATE TABLE IF NOT EXISTS "orders" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "order_number" varchar(50) NOT NULL,
+    "customer_name" varchar(255),
+    "customer_phone" varchar(50),
+    "customer_email" varchar(255),
+    "customer_id" uuid,
+    "branch_id" uuid,
+    "referral_code_used" varchar(50),
+    "points_redeemed" bigint DEFAULT 0,
+    "status" varchar(50) DEFAULT 'pending',
+    "sub_total" decimal(12,2) NOT NULL,
+    "tax_amount" decimal(12,2) DEFAULT 0,
+    "tax_inclusive" boolean DEFAULT false,
+    "discount_amount" decimal(12,2) DEFAULT 0,
+    "delivery_fee" decimal(12,2) DEFAULT 0,
+    "points_multiplier" decimal DEFAULT 1,
+    "birthday_bonus_points" bigint DEFAULT 0,
+    "promo_code_id" uuid,
+    "total_amount" decimal(12,2) NOT NULL,
+    "gift_card_id" uuid,
+    "gift_card_amount" decimal(12,2) DEFAULT 0,
+    "store_credit_amount" decimal(12,2) DEFAULT 0,
+    "currency" varchar(10) DEFAULT 'NPR',
+    "notes" text,
+    "delivery_address" text,
+    "custom_fields" jsonb,
+    "created_by" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "completed_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_orders_deleted_at" ON "orders" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_orders_created_by" ON "orders" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_orders_gift_card_id" ON "orders" ("gift_card_id");
+CREATE INDEX IF NOT EXISTS "idx_orders_promo_code_id" ON "orders" ("promo_code_id");
+CREATE INDEX IF NOT EXISTS "idx_orders_status" ON "orders" ("status");
+CREATE INDEX IF NOT EXISTS "idx_orders_branch_id" ON "orders" ("branch_id");
+CREATE INDEX IF NOT EXISTS "idx_orders_customer_id" ON "orders" ("customer_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_orders_order_number" ON "orders" ("order_number");
+CREATE INDEX IF NOT EXISTS "idx_orders_pharmacy_id" ON "orders" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "points_transactions" (
+    "id" uuid,
+    "customer_id" uuid NOT NULL,
+    "amount" bigint NOT NULL,
+    "type" varchar(30) NOT NULL,
+    "order_id" uuid,
+    "referral_customer_id" uuid,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_points_transactions_referral_customer_id" ON "points_transactions" ("referral_customer_id");
+CREATE INDEX IF NOT EXISTS "idx_points_transactions_order_id" ON "points_transactions" ("order_id");
+CREATE INDEX IF NOT EXISTS "idx_points_transactions_type" ON "points_transactions" ("type");
+CREATE INDEX IF NOT EXISTS "idx_points_transactions_customer_id" ON "points_transactions" ("customer_id");
+
+CREATE TABLE IF NOT EXISTS "order_items" (
+    "id" uuid,
+    "order_id" uuid NOT NULL,
+    "product_id" uuid NOT NULL,
+    "quantity" bigint NOT NULL,
+    "unit_price" decimal(12,2) NOT NULL,
+    "total_price" decimal(12,2) NOT NULL,
+    "tax_class" varchar(30),
+    "tax_rate" decimal(5,2) DEFAULT 0,
+    "tax_amount" decimal(12,2) DEFAULT 0,
+    "promo_discount" decimal(12,2) DEFAULT 0,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_order_items_product_id" ON "order_items" ("product_id");
+CREATE INDEX IF NOT EXISTS "idx_order_items_order_id" ON "order_items" ("order_id");
+
+CREATE TABLE IF NOT EXISTS "order_item_batches" (
+    "id" uuid,
+    "order_item_id" uuid NOT NULL,
+    "batch_id" uuid NOT NULL,
+    "product_id" uuid NOT NULL,
+    "batch_number" varchar(100) NOT NULL,
+    "expiry_date" timestamptz,
+    "quantity" bigint NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_order_item_batches_product_id" ON "order_item_batches" ("product_id");
+CREATE INDEX IF NOT EXISTS "idx_order_item_batches_batch_id" ON "order_item_batches" ("batch_id");
+CREATE INDEX IF NOT EXISTS "idx_order_item_batches_order_item_id" ON "order_item_batches" ("order_item_id");
+
+CREATE TABLE IF NOT EXISTS "order_feedbacks" (
+    "id" uuid,
+    "order_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "rating" bigint NOT NULL,
+    "comment" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "follow_up_status" varchar(20) NOT NULL DEFAULT 'none',
+    "follow_up_note" text,
+    "follow_up_by" uuid,
+    "follow_up_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_order_feedbacks_follow_up_status" ON "order_feedbacks" ("follow_up_status");
+CREATE INDEX IF NOT EXISTS "idx_order_feedbacks_deleted_at" ON "order_feedbacks" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_order_feedback_user" ON "order_feedbacks" ("order_id","user_id");
+
+CREATE TABLE IF NOT EXISTS "order_return_requests" (
+    "id" uuid,
+    "order_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "status" varchar(50) DEFAULT 'pending',
+    "video_url" text,
+    "photo_urls" text,
+    "notes" text,
+    "description" text,
+    "reason_code" varchar(40),
+    "product_ids" text,
+    "staff_note" text,
+    "reviewed_by" uuid,
+    "reviewed_at" timestamptz,
+    "refund_entry_id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_order_return_requests_reason_code" ON "order_return_requests" ("reason_code");
+CREATE INDEX IF NOT EXISTS "idx_order_return_requests_user_id" ON "order_return_requests" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_order_return_requests_order_id" ON "order_return_requests" ("order_id");
+
+CREATE TABLE IF NOT EXISTS "payment_gateways" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "code" varchar(50) NOT NULL,
+    "name" varchar(255) NOT NULL,
+    "is_active" boolean DEFAULT true,
+    "sort_order" bigint DEFAULT 0,
+    "qr_details" text,
+    "bank_details" text,
+    "qr_image_url" varchar(1024),
+    "client_id" varchar(255),
+    "secret_key" varchar(512),
+    "extra_config" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_payment_gateways_deleted_at" ON "payment_gateways" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_payment_gateways_is_active" ON "payment_gateways" ("is_active");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_pharmacy_code" ON "payment_gateways" ("code");
+CREATE INDEX IF NOT EXISTS "idx_payment_gateways_pharmacy_id" ON "payment_gateways" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "payments" (
+    "id" uuid,
+    "order_id" uuid NOT NULL,
+    "pharmacy_id" uuid NOT NULL,
+    "payment_gateway_id" uuid,
+    "amount" decimal(12,2) NOT NULL,
+    "currency" varchar(10) DEFAULT 'NPR',
+    "method" varchar(50) NOT NULL,
+    "status" varchar(50) DEFAULT 'pending',
+    "reference" varchar(255),
+    "paid_at" timestamptz,
+    "created_by" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_payments_deleted_at" ON "payments" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_payments_created_by" ON "payments" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_payments_status" ON "payments" ("status");
+CREATE INDEX IF NOT EXISTS "idx_payments_payment_gateway_id" ON "payments" ("payment_gateway_id");
+CREATE INDEX IF NOT EXISTS "idx_payments_pharmacy_id" ON "payments" ("pharmacy_id");
+CREATE INDEX IF NOT EXISTS "idx_payments_order_id" ON "payments" ("order_id");
+
+CREATE TABLE IF NOT EXISTS "invoices" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "order_id" uuid NOT NULL,
+    "invoice_number" varchar(50) NOT NULL,
+    "status" varchar(20) DEFAULT 'draft',
+    "issued_at" timestamptz,
+    "created_by" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_invoices_deleted_at" ON "invoices" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_invoices_created_by" ON "invoices" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_invoices_status" ON "invoices" ("status");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_invoices_order_id" ON "invoices" ("order_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_pharmacy_invoice" ON "invoices" ("pharmacy_id","invoice_number");
+
+CREATE TABLE IF NOT EXISTS "inventory_batches" (
+    "id" uuid,
+    "product_id" uuid NOT NULL,
+    "pharmacy_id" uuid NOT NULL,
+    "batch_number" varchar(100) NOT NULL,
+    "quantity" bigint NOT NULL,
+    "expiry_date" timestamptz,
+    "branch_id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_inventory_batches_branch_id" ON "inventory_batches" ("branch_id");
+CREATE INDEX IF NOT EXISTS "idx_inventory_batches_expiry_date" ON "inventory_batches" ("expiry_date");
+CREATE INDEX IF NOT EXISTS "idx_inventory_batches_pharmacy_id" ON "inventory_batches" ("pharmacy_id");
+CREATE INDEX IF NOT EXISTS "idx_inventory_batches_product_id" ON "inventory_batches" ("product_id");
+
+CREATE TABLE IF NOT EXISTS "inventory_alerts" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "kind" varchar(20) NOT NULL,
+    "ref_id" uuid NOT NULL,
+    "first_seen_at" timestamptz NOT NULL,
+    "last_seen_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_inventory_alert_ref" ON "inventory_alerts" ("kind","ref_id");
+CREATE INDEX IF NOT EXISTS "idx_inventory_alerts_pharmacy_id" ON "inventory_alerts" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "activity_logs" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "action" varchar(255) NOT NULL,
+    "description" varchar(512),
+    "entity_type" varchar(64),
+    "entity_id" varchar(64),
+    "details" text,
+    "ip_address" varchar(45),
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_activity_logs_user_id" ON "activity_logs" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_activity_logs_pharmacy_id" ON "activity_logs" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "notifications" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "title" varchar(255) NOT NULL,
+    "message" text,
+    "type" varchar(64) DEFAULT 'info',
+    "language" varchar(16),
+    "read_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_notifications_user_id" ON "notifications" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_notifications_pharmacy_id" ON "notifications" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "promos" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "type" varchar(32) NOT NULL,
+    "title" varchar(255) NOT NULL,
+    "description" text,
+    "image_url" varchar(512),
+    "images" jsonb,
+    "link_url" varchar(512),
+    "start_at" timestamptz,
+    "end_at" timestamptz,
+    "sort_order" bigint DEFAULT 0,
+    "placements" jsonb,
+    "category_ids" jsonb,
+    "schedule_days" jsonb,
+    "daily_start" varchar(5),
+    "daily_end" varchar(5),
+    "is_active" boolean DEFAULT true,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_promos_end_at" ON "promos" ("end_at");
+CREATE INDEX IF NOT EXISTS "idx_promos_start_at" ON "promos" ("start_at");
+CREATE INDEX IF NOT EXISTS "idx_promos_type" ON "promos" ("type");
+CREATE INDEX IF NOT EXISTS "idx_promos_pharmacy_id" ON "promos" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "duty_rosters" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "date" date NOT NULL,
+    "shift_type" varchar(20) NOT NULL,
+    "notes" varchar(500),
+    "status" varchar(20) NOT NULL DEFAULT 'published',
+    "published_at" timestamptz,
+    "revision" bigint NOT NULL DEFAULT 0,
+    "change_note" varchar(500),
+    "acknowledged_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_duty_rosters_deleted_at" ON "duty_rosters" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_duty_rosters_status" ON "duty_rosters" ("status");
+CREATE INDEX IF NOT EXISTS "idx_duty_rosters_date" ON "duty_rosters" ("date");
+CREATE INDEX IF NOT EXISTS "idx_duty_rosters_user_id" ON "duty_rosters" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_duty_rosters_pharmacy_id" ON "duty_rosters" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "shift_swaps" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "roster_id" uuid NOT NULL,
+    "offered_by" uuid NOT NULL,
+    "note" varchar(500),
+    "status" varchar(20) NOT NULL DEFAULT 'open',
+    "approved_request_id" uuid,
+    "decided_by" uuid,
+    "decided_at" timestamptz,
+    "decision_note" varchar(500),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_shift_swaps_status" ON "shift_swaps" ("status");
+CREATE INDEX IF NOT EXISTS "idx_shift_swaps_offered_by" ON "shift_swaps" ("offered_by");
+CREATE INDEX IF NOT EXISTS "idx_shift_swaps_roster_id" ON "shift_swaps" ("roster_id");
+CREATE INDEX IF NOT EXISTS "idx_shift_swaps_pharmacy_id" ON "shift_swaps" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "shift_swap_requests" (
+    "id" uuid,
+    "swap_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "note" varchar(500),
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_shift_swap_requests_user_id" ON "shift_swap_requests" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_shift_swap_requests_swap_id" ON "shift_swap_requests" ("swap_id");
+
+CREATE TABLE IF NOT EXISTS "shift_swap_events" (
+    "id" uuid,
+    "swap_id" uuid NOT NULL,
+    "action" varchar(20) NOT NULL,
+    "actor_id" uuid NOT NULL,
+    "request_id" uuid,
+    "note" varchar(500),
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_shift_swap_events_swap_id" ON "shift_swap_events" ("swap_id");
+
+CREATE TABLE IF NOT EXISTS "branches" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "code" varchar(30) NOT NULL,
+    "name" varchar(150) NOT NULL,
+    "address" text,
+    "phone" varchar(50),
+    "is_default" boolean DEFAULT false,
+    "is_active" boolean DEFAULT true,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_branches_deleted_at" ON "branches" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_branch_pharmacy_code" ON "branches" ("pharmacy_id","code");
+
+CREATE TABLE IF NOT EXISTS "branch_transfers" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "from_branch_id" uuid NOT NULL,
+    "to_branch_id" uuid NOT NULL,
+    "note" text,
+    "created_by" uuid,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_branch_transfers_created_by" ON "branch_transfers" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_branch_transfers_to_branch_id" ON "branch_transfers" ("to_branch_id");
+CREATE INDEX IF NOT EXISTS "idx_branch_transfers_from_branch_id" ON "branch_transfers" ("from_branch_id");
+CREATE INDEX IF NOT EXISTS "idx_branch_transfers_pharmacy_id" ON "branch_transfers" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "branch_transfer_items" (
+    "id" uuid,
+    "transfer_id" uuid NOT NULL,
+    "product_id" uuid NOT NULL,
+    "from_batch_id" uuid NOT NULL,
+    "to_batch_id" uuid NOT NULL,
+    "batch_number" varchar(100),
+    "expiry_date" timestamptz,
+    "quantity" bigint NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_branch_transfer_items_product_id" ON "branch_transfer_items" ("product_id");
+CREATE INDEX IF NOT EXISTS "idx_branch_transfer_items_transfer_id" ON "branch_transfer_items" ("transfer_id");
+
+CREATE TABLE IF NOT EXISTS "stock_transfers" (
+    "id" uuid,
+    "source_pharmacy_id" uuid NOT NULL,
+    "dest_pharmacy_id" uuid NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'requested',
+    "note" varchar(500),
+    "requested_by" uuid NOT NULL,
+    "approved_by" uuid,
+    "approved_at" timestamptz,
+    "dispatched_by" uuid,
+    "dispatched_at" timestamptz,
+    "received_by" uuid,
+    "received_at" timestamptz,
+    "closed_by" uuid,
+    "closed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_stock_transfers_status" ON "stock_transfers" ("status");
+CREATE INDEX IF NOT EXISTS "idx_stock_transfers_dest_pharmacy_id" ON "stock_transfers" ("dest_pharmacy_id");
+CREATE INDEX IF NOT EXISTS "idx_stock_transfers_source_pharmacy_id" ON "stock_transfers" ("source_pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "stock_transfer_items" (
+    "id" uuid,
+    "transfer_id" uuid NOT NULL,
+    "source_product_id" uuid NOT NULL,
+    "dest_product_id" uuid NOT NULL,
+    "sku" varchar(100),
+    "name" varchar(255),
+    "quantity" bigint NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_stock_transfer_items_transfer_id" ON "stock_transfer_items" ("transfer_id");
+
+CREATE TABLE IF NOT EXISTS "stock_transfer_item_batches" (
+    "id" uuid,
+    "item_id" uuid NOT NULL,
+    "from_batch_id" uuid,
+    "to_batch_id" uuid,
+    "batch_number" varchar(100),
+    "expiry_date" timestamptz,
+    "quantity" bigint NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_stock_transfer_item_batches_item_id" ON "stock_transfer_item_batches" ("item_id");
+
+CREATE TABLE IF NOT EXISTS "stock_transfer_events" (
+    "id" uuid,
+    "transfer_id" uuid NOT NULL,
+    "action" varchar(20) NOT NULL,
+    "actor_id" uuid NOT NULL,
+    "pharmacy_id" uuid NOT NULL,
+    "note" varchar(500),
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_stock_transfer_events_transfer_id" ON "stock_transfer_events" ("transfer_id");
+
+CREATE TABLE IF NOT EXISTS "hashtags" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "tag" varchar(50) NOT NULL,
+    "label" varchar(100),
+    "aliases" jsonb,
+    "is_blocked" boolean DEFAULT false,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_hashtags_is_blocked" ON "hashtags" ("is_blocked");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_hashtag_pharmacy_tag" ON "hashtags" ("pharmacy_id","tag");
+
+CREATE TABLE IF NOT EXISTS "product_daily_views" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "product_id" uuid NOT NULL,
+    "day" date NOT NULL,
+    "views" bigint NOT NULL DEFAULT 0,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_product_view_day" ON "product_daily_views" ("product_id","day");
+CREATE INDEX IF NOT EXISTS "idx_product_daily_views_pharmacy_id" ON "product_daily_views" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "order_status_histories" (
+    "id" uuid,
+    "order_id" uuid NOT NULL,
+    "from_status" varchar(50),
+    "to_status" varchar(50) NOT NULL,
+    "actor_id" uuid,
+    "actor_name" varchar(255),
+    "note" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_order_status_histories_created_at" ON "order_status_histories" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_order_status_histories_order_id" ON "order_status_histories" ("order_id");
+
+CREATE TABLE IF NOT EXISTS "gift_cards" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "code" varchar(32) NOT NULL,
+    "initial_amount" decimal(12,2) NOT NULL,
+    "balance" decimal(12,2) NOT NULL DEFAULT 0,
+    "currency" varchar(10) DEFAULT 'NPR',
+    "expires_at" timestamptz,
+    "is_active" boolean DEFAULT true,
+    "customer_id" uuid,
+    "note" text,
+    "issued_by" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_gift_cards_customer_id" ON "gift_cards" ("customer_id");
+CREATE INDEX IF NOT EXISTS "idx_gift_cards_expires_at" ON "gift_cards" ("expires_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_gift_card_pharmacy_code" ON "gift_cards" ("pharmacy_id","code");
+
+CREATE TABLE IF NOT EXISTS "gift_card_transactions" (
+    "id" uuid,
+    "gift_card_id" uuid NOT NULL,
+    "type" varchar(20) NOT NULL,
+    "amount" decimal(12,2) NOT NULL,
+    "balance_after" decimal(12,2) NOT NULL,
+    "order_id" uuid,
+    "created_by" uuid,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_gift_card_transactions_order_id" ON "gift_card_transactions" ("order_id");
+CREATE INDEX IF NOT EXISTS "idx_gift_card_transactions_gift_card_id" ON "gift_card_transactions" ("gift_card_id");
+
+CREATE TABLE IF NOT EXISTS "store_credit_entries" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "customer_id" uuid NOT NULL,
+    "type" varchar(20) NOT NULL,
+    "amount" decimal(12,2) NOT NULL,
+    "balance_after" decimal(12,2) NOT NULL,
+    "order_id" uuid,
+    "note" text,
+    "created_by" uuid,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_store_credit_entries_order_id" ON "store_credit_entries" ("order_id");
+CREATE INDEX IF NOT EXISTS "idx_store_credit_entries_customer_id" ON "store_credit_entries" ("customer_id");
+CREATE INDEX IF NOT EXISTS "idx_store_credit_entries_pharmacy_id" ON "store_credit_entries" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "credit_notes" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "invoice_id" uuid NOT NULL,
+    "order_id" uuid NOT NULL,
+    "sequence" bigint NOT NULL,
+    "credit_note_number" varchar(50) NOT NULL,
+    "source" varchar(20) NOT NULL,
+    "source_id" uuid,
+    "reason" text,
+    "taxable_amount" decimal(12,2) NOT NULL,
+    "tax_amount" decimal(12,2) NOT NULL DEFAULT 0,
+    "amount" decimal(12,2) NOT NULL,
+    "issued_at" timestamptz NOT NULL,
+    "created_by" uuid,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_credit_notes_issued_at" ON "credit_notes" ("issued_at");
+CREATE INDEX IF NOT EXISTS "idx_credit_notes_source_id" ON "credit_notes" ("source_id");
+CREATE INDEX IF NOT EXISTS "idx_credit_notes_source" ON "credit_notes" ("source");
+CREATE INDEX IF NOT EXISTS "idx_credit_notes_order_id" ON "credit_notes" ("order_id");
+CREATE INDEX IF NOT EXISTS "idx_credit_notes_invoice_id" ON "credit_notes" ("invoice_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_credit_note_number" ON "credit_notes" ("pharmacy_id","credit_note_number");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_credit_note_sequence" ON "credit_notes" ("pharmacy_id","sequence");
+
+CREATE TABLE IF NOT EXISTS "daily_logs" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "date" date NOT NULL,
+    "title" varchar(255) NOT NULL,
+    "description" text,
+    "status" varchar(20) DEFAULT 'open',
+    "created_by" uuid NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_daily_logs_deleted_at" ON "daily_logs" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_daily_logs_created_by" ON "daily_logs" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_daily_logs_date" ON "daily_logs" ("date");
+CREATE INDEX IF NOT EXISTS "idx_daily_logs_pharmacy_id" ON "daily_logs" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "conversations" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "customer_id" uuid,
+    "user_id" uuid,
+    "last_message_at" timestamptz,
+    "status" varchar(20) NOT NULL DEFAULT 'open',
+    "escalated_at" timestamptz,
+    "escalated_by" uuid,
+    "escalation_reason" varchar(500),
+    "ticket_provider" varchar(20),
+    "ticket_id" varchar(100),
+    "ticket_url" varchar(500),
+    "ticket_status" varchar(50),
+    "ticket_error" varchar(500),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_conversations_ticket" ON "conversations" ("ticket_provider","ticket_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_conversations_pharmacy_user" ON "conversations" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_conversations_pharmacy_customer" ON "conversations" ("customer_id");
+
+CREATE TABLE IF NOT EXISTS "chat_messages" (
+    "id" uuid,
+    "conversation_id" uuid NOT NULL,
+    "sender_type" varchar(20) NOT NULL,
+    "sender_id" uuid NOT NULL,
+    "body" text,
+    "attachment_url" varchar(1024),
+    "attachment_name" varchar(255),
+    "attachment_type" varchar(128),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_chat_messages_conversation_id" ON "chat_messages" ("conversation_id");
+
+CREATE TABLE IF NOT EXISTS "user_addresses" (
+    "id" uuid,
+    "user_id" uuid NOT NULL,
+    "label" varchar(100),
+    "line1" varchar(255) NOT NULL,
+    "line2" varchar(255),
+    "city" varchar(100) NOT NULL,
+    "state" varchar(100),
+    "postal_code" varchar(20),
+    "country" varchar(100) NOT NULL,
+    "phone" varchar(30),
+    "is_default" boolean DEFAULT false,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_user_addresses_deleted_at" ON "user_addresses" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_user_addresses_user_id" ON "user_addresses" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "announcements" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "type" varchar(32) NOT NULL,
+    "template" varchar(32) DEFAULT 'celebration',
+    "title" varchar(255) NOT NULL,
+    "body" text,
+    "image_url" varchar(512),
+    "images" jsonb,
+    "link_url" varchar(512),
+    "display_seconds" bigint NOT NULL DEFAULT 5,
+    "valid_days" bigint NOT NULL DEFAULT 7,
+    "show_terms" boolean DEFAULT false,
+    "terms_text" text,
+    "allow_skip_all" boolean DEFAULT true,
+    "start_at" timestamptz,
+    "end_at" timestamptz,
+    "sort_order" bigint DEFAULT 0,
+    "translations" jsonb,
+    "is_active" boolean DEFAULT true,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_announcements_end_at" ON "announcements" ("end_at");
+CREATE INDEX IF NOT EXISTS "idx_announcements_start_at" ON "announcements" ("start_at");
+CREATE INDEX IF NOT EXISTS "idx_announcements_type" ON "announcements" ("type");
+CREATE INDEX IF NOT EXISTS "idx_announcements_pharmacy_id" ON "announcements" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "announcement_acks" (
+    "id" uuid,
+    "user_id" uuid NOT NULL,
+    "announcement_id" uuid,
+    "acknowledged_at" timestamptz NOT NULL,
+    "skip_all" boolean DEFAULT false,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_announcement_acks_announcement_id" ON "announcement_acks" ("announcement_id");
+CREATE INDEX IF NOT EXISTS "idx_announcement_acks_user_id" ON "announcement_acks" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "blog_categories" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "parent_id" uuid,
+    "name" varchar(200) NOT NULL,
+    "slug" varchar(220) NOT NULL,
+    "description" text,
+    "sort_order" bigint DEFAULT 0,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_blog_categories_deleted_at" ON "blog_categories" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_blog_categories_parent_id" ON "blog_categories" ("parent_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_blog_category_pharmacy_slug" ON "blog_categories" ("pharmacy_id","slug");
+
+CREATE TABLE IF NOT EXISTS "blog_posts" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "category_id" uuid,
+    "author_id" uuid NOT NULL,
+    "title" varchar(500) NOT NULL,
+    "slug" varchar(520) NOT NULL,
+    "excerpt" text,
+    "body" text NOT NULL,
+    "status" varchar(32) NOT NULL DEFAULT 'draft',
+    "published_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_blog_posts_deleted_at" ON "blog_posts" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_blog_posts_status" ON "blog_posts" ("status");
+CREATE INDEX IF NOT EXISTS "idx_blog_posts_author_id" ON "blog_posts" ("author_id");
+CREATE INDEX IF NOT EXISTS "idx_blog_posts_category_id" ON "blog_posts" ("category_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_blog_post_pharmacy_slug" ON "blog_posts" ("pharmacy_id","slug");
+
+CREATE TABLE IF NOT EXISTS "blog_post_media" (
+    "id" uuid,
+    "post_id" uuid NOT NULL,
+    "media_type" varchar(20) NOT NULL,
+    "url" text NOT NULL,
+    "caption" varchar(500),
+    "sort_order" bigint DEFAULT 0,
+    "created_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_blog_post_media_deleted_at" ON "blog_post_media" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_blog_post_media_post_id" ON "blog_post_media" ("post_id");
+
+CREATE TABLE IF NOT EXISTS "blog_post_likes" (
+    "id" uuid,
+    "post_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "created_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_blog_post_likes_deleted_at" ON "blog_post_likes" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_blog_post_like_post_user" ON "blog_post_likes" ("post_id","user_id");
+
+CREATE TABLE IF NOT EXISTS "blog_post_comments" (
+    "id" uuid,
+    "post_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "parent_id" uuid,
+    "body" text NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_blog_post_comments_deleted_at" ON "blog_post_comments" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_blog_post_comments_parent_id" ON "blog_post_comments" ("parent_id");
+CREATE INDEX IF NOT EXISTS "idx_blog_post_comments_user_id" ON "blog_post_comments" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_blog_post_comments_post_id" ON "blog_post_comments" ("post_id");
+
+CREATE TABLE IF NOT EXISTS "blog_post_views" (
+    "id" uuid,
+    "post_id" uuid NOT NULL,
+    "user_id" uuid,
+    "viewed_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_blog_post_views_deleted_at" ON "blog_post_views" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_blog_post_views_user_id" ON "blog_post_views" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_blog_post_views_post_id" ON "blog_post_views" ("post_id");
+
+CREATE TABLE IF NOT EXISTS "ai_generations" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "requested_by" uuid NOT NULL,
+    "kind" varchar(40) NOT NULL,
+    "input" jsonb,
+    "output" text,
+    "status" varchar(20) NOT NULL DEFAULT 'draft',
+    "provider" varchar(40),
+    "model" varchar(100),
+    "prompt_tokens" bigint DEFAULT 0,
+    "completion_tokens" bigint DEFAULT 0,
+    "product_id" uuid,
+    "blog_post_id" uuid,
+    "reviewed_by" uuid,
+    "reviewed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_ai_generations_blog_post_id" ON "ai_generations" ("blog_post_id");
+CREATE INDEX IF NOT EXISTS "idx_ai_generations_product_id" ON "ai_generations" ("product_id");
+CREATE INDEX IF NOT EXISTS "idx_ai_generations_status" ON "ai_generations" ("status");
+CREATE INDEX IF NOT EXISTS "idx_ai_generations_kind" ON "ai_generations" ("kind");
+CREATE INDEX IF NOT EXISTS "idx_ai_generations_requested_by" ON "ai_generations" ("requested_by");
+CREATE INDEX IF NOT EXISTS "idx_ai_generation_pharmacy_created" ON "ai_generations" ("pharmacy_id","created_at");
+
+CREATE TABLE IF NOT EXISTS "purchase_orders" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "supplier_id" uuid NOT NULL,
+    "po_number" varchar(50) NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'draft',
+    "expected_delivery_date" date,
+    "confirmed_delivery_date" date,
+    "notes" text,
+    "supplier_note" text,
+    "sent_at" timestamptz,
+    "responded_at" timestamptz,
+    "received_at" timestamptz,
+    "created_by" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_purchase_orders_created_by" ON "purchase_orders" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_purchase_orders_status" ON "purchase_orders" ("status");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_purchase_orders_po_number" ON "purchase_orders" ("po_number");
+CREATE INDEX IF NOT EXISTS "idx_purchase_orders_supplier_id" ON "purchase_orders" ("supplier_id");
+CREATE INDEX IF NOT EXISTS "idx_purchase_orders_pharmacy_id" ON "purchase_orders" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "purchase_order_items" (
+    "id" uuid,
+    "purchase_order_id" uuid NOT NULL,
+    "product_id" uuid NOT NULL,
+    "quantity" bigint NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_purchase_order_items_product_id" ON "purchase_order_items" ("product_id");
+CREATE INDEX IF NOT EXISTS "idx_purchase_order_items_purchase_order_id" ON "purchase_order_items" ("purchase_order_id");
+
+CREATE TABLE IF NOT EXISTS "flash_sales" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "name" varchar(255) NOT NULL,
+    "description" text,
+    "starts_at" timestamptz NOT NULL,
+    "ends_at" timestamptz NOT NULL,
+    "is_active" boolean DEFAULT true,
+    "created_by" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_flash_sales_deleted_at" ON "flash_sales" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_flash_sales_is_active" ON "flash_sales" ("is_active");
+CREATE INDEX IF NOT EXISTS "idx_flash_sales_ends_at" ON "flash_sales" ("ends_at");
+CREATE INDEX IF NOT EXISTS "idx_flash_sales_starts_at" ON "flash_sales" ("starts_at");
+CREATE INDEX IF NOT EXISTS "idx_flash_sales_pharmacy_id" ON "flash_sales" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "flash_sale_items" (
+    "id" uuid,
+    "flash_sale_id" uuid NOT NULL,
+    "product_id" uuid NOT NULL,
+    "sale_price" decimal(12,2) NOT NULL,
+    "max_quantity" bigint DEFAULT 0,
+    "per_customer_limit" bigint DEFAULT 0,
+    "sold_quantity" bigint DEFAULT 0,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_flash_sale_items_product_id" ON "flash_sale_items" ("product_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_flash_sale_product" ON "flash_sale_items" ("flash_sale_id","product_id");
+CREATE INDEX IF NOT EXISTS "idx_flash_sale_items_flash_sale_id" ON "flash_sale_items" ("flash_sale_id");
+
+CREATE TABLE IF NOT EXISTS "flash_sale_redemptions" (
+    "id" uuid,
+    "flash_sale_item_id" uuid NOT NULL,
+    "customer_key" varchar(100) NOT NULL,
+    "order_id" uuid NOT NULL,
+    "quantity" bigint NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_flash_sale_redemptions_order_id" ON "flash_sale_redemptions" ("order_id");
+CREATE INDEX IF NOT EXISTS "idx_flash_redemption_customer" ON "flash_sale_redemptions" ("flash_sale_item_id","customer_key");
+
+CREATE TABLE IF NOT EXISTS "preorders" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "preorder_number" varchar(50) NOT NULL,
+    "product_id" uuid NOT NULL,
+    "quantity" bigint NOT NULL,
+    "unit_price" decimal(12,2) NOT NULL,
+    "total_amount" decimal(12,2) NOT NULL,
+    "deposit_amount" decimal(12,2) DEFAULT 0,
+    "amount_paid" decimal(12,2) DEFAULT 0,
+    "payment_gateway_id" uuid,
+    "payment_method" varchar(50),
+    "customer_name" varchar(255),
+    "customer_phone" varchar(50),
+    "customer_email" varchar(255),
+    "notes" text,
+    "status" varchar(20) DEFAULT 'pending',
+    "purchase_order_id" uuid,
+    "expected_at" timestamptz,
+    "order_id" uuid,
+    "converted_at" timestamptz,
+    "cancelled_at" timestamptz,
+    "created_by" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_preorders_deleted_at" ON "preorders" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_preorders_created_by" ON "preorders" ("created_by");
+CREATE INDEX IF NOT EXISTS "idx_preorders_order_id" ON "preorders" ("order_id");
+CREATE INDEX IF NOT EXISTS "idx_preorders_purchase_order_id" ON "preorders" ("purchase_order_id");
+CREATE INDEX IF NOT EXISTS "idx_preorders_status" ON "preorders" ("status");
+CREATE INDEX IF NOT EXISTS "idx_preorders_product_id" ON "preorders" ("product_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_preorders_preorder_number" ON "preorders" ("preorder_number");
+CREATE INDEX IF NOT EXISTS "idx_preorders_pharmacy_id" ON "preorders" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "sop_documents" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "title" varchar(255) NOT NULL,
+    "body" text,
+    "file_url" varchar(512),
+    "version" varchar(50),
+    "is_active" boolean DEFAULT true,
+    "created_by" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_sop_documents_deleted_at" ON "sop_documents" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_sop_documents_is_active" ON "sop_documents" ("is_active");
+CREATE INDEX IF NOT EXISTS "idx_sop_documents_pharmacy_id" ON "sop_documents" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "sop_acks" (
+    "id" uuid,
+    "sop_document_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "acknowledged_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_sop_ack_user" ON "sop_acks" ("sop_document_id","user_id");
+
+CREATE TABLE IF NOT EXISTS "training_quizzes" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "announcement_id" uuid,
+    "sop_document_id" uuid,
+    "title" varchar(255) NOT NULL,
+    "questions" jsonb,
+    "pass_percent" bigint DEFAULT 80,
+    "is_active" boolean DEFAULT true,
+    "created_by" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_training_quizzes_deleted_at" ON "training_quizzes" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_training_quizzes_is_active" ON "training_quizzes" ("is_active");
+CREATE INDEX IF NOT EXISTS "idx_training_quizzes_sop_document_id" ON "training_quizzes" ("sop_document_id");
+CREATE INDEX IF NOT EXISTS "idx_training_quizzes_announcement_id" ON "training_quizzes" ("announcement_id");
+CREATE INDEX IF NOT EXISTS "idx_training_quizzes_pharmacy_id" ON "training_quizzes" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "training_attempts" (
+    "id" uuid,
+    "quiz_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "pharmacy_id" uuid NOT NULL,
+    "answers" jsonb,
+    "correct" bigint,
+    "total" bigint,
+    "score_percent" bigint,
+    "passed" boolean,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_training_attempts_passed" ON "training_attempts" ("passed");
+CREATE INDEX IF NOT EXISTS "idx_training_attempts_pharmacy_id" ON "training_attempts" ("pharmacy_id");
+CREATE INDEX IF NOT EXISTS "idx_training_attempt_user" ON "training_attempts" ("quiz_id","user_id");
+
+CREATE TABLE IF NOT EXISTS "otp_codes" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "phone" varchar(50) NOT NULL,
+    "purpose" varchar(50) NOT NULL,
+    "code_hash" varchar(128) NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "attempts" bigint DEFAULT 0,
+    "consumed_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_otp_lookup" ON "otp_codes" ("pharmacy_id","phone","purpose");
+
+CREATE TABLE IF NOT EXISTS "password_reset_tokens" (
+    "id" uuid,
+    "user_id" uuid NOT NULL,
+    "token_hash" varchar(64) NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_password_reset_tokens_token_hash" ON "password_reset_tokens" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_password_reset_tokens_user_id" ON "password_reset_tokens" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "pharmacy_config_versions" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "version" bigint NOT NULL,
+    "action" varchar(20) NOT NULL,
+    "rolled_back_from" bigint,
+    "snapshot" jsonb,
+    "changed_by" uuid,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_config_version" ON "pharmacy_config_versions" ("pharmacy_id","version");
+
+CREATE TABLE IF NOT EXISTS "roles" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "name" varchar(32) NOT NULL,
+    "description" varchar(255),
+    "permissions" jsonb,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_role_pharmacy_name" ON "roles" ("pharmacy_id","name");
+
+CREATE TABLE IF NOT EXISTS "promo_daily_stats" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "promo_id" uuid NOT NULL,
+    "day" date NOT NULL,
+    "placement" varchar(32) NOT NULL DEFAULT '',
+    "impressions" bigint NOT NULL DEFAULT 0,
+    "clicks" bigint NOT NULL DEFAULT 0,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_promo_stat_day_placement" ON "promo_daily_stats" ("promo_id","day","placement");
+CREATE INDEX IF NOT EXISTS "idx_promo_daily_stats_pharmacy_id" ON "promo_daily_stats" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "carts" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'open',
+    "promo_code" varchar(50),
+    "referral_code" varchar(50),
+    "points_to_redeem" bigint DEFAULT 0,
+    "order_id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_cart_owner" ON "carts" ("pharmacy_id","user_id","status");
+
+CREATE TABLE IF NOT EXISTS "cart_items" (
+    "id" uuid,
+    "cart_id" uuid NOT NULL,
+    "product_id" uuid NOT NULL,
+    "quantity" bigint NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_cart_item_product" ON "cart_items" ("cart_id","product_id");
+
+CREATE TABLE IF NOT EXISTS "deliveries" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "order_id" uuid NOT NULL,
+    "status" varchar(32) NOT NULL,
+    "assigned_to" uuid,
+    "location_note" varchar(500),
+    "latitude" decimal,
+    "longitude" decimal,
+    "location_at" timestamptz,
+    "packed_at" timestamptz,
+    "dispatched_at" timestamptz,
+    "out_for_delivery_at" timestamptz,
+    "delivered_at" timestamptz,
+    "received_by" varchar(255),
+    "receiver_relation" varchar(20),
+    "signature_url" varchar(500),
+    "photo_url" varchar(500),
+    "otp_hash" varchar(128),
+    "otp_attempts" bigint NOT NULL DEFAULT 0,
+    "otp_sent_at" timestamptz,
+    "otp_verified_at" timestamptz,
+    "dest_latitude" decimal,
+    "dest_longitude" decimal,
+    "window_start" timestamptz,
+    "window_end" timestamptz,
+    "batch_id" uuid,
+    "stop_sequence" bigint NOT NULL DEFAULT 0,
+    "eta" timestamptz,
+    "created_by" uuid NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_deliveries_batch_id" ON "deliveries" ("batch_id");
+CREATE INDEX IF NOT EXISTS "idx_deliveries_assigned_to" ON "deliveries" ("assigned_to");
+CREATE INDEX IF NOT EXISTS "idx_deliveries_status" ON "deliveries" ("status");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_deliveries_order_id" ON "deliveries" ("order_id");
+CREATE INDEX IF NOT EXISTS "idx_deliveries_pharmacy_id" ON "deliveries" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "delivery_events" (
+    "id" uuid,
+    "delivery_id" uuid NOT NULL,
+    "kind" varchar(20) NOT NULL,
+    "status" varchar(32) NOT NULL,
+    "note" varchar(500),
+    "latitude" decimal,
+    "longitude" decimal,
+    "created_by" uuid NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_delivery_events_delivery_id" ON "delivery_events" ("delivery_id");
+
+CREATE TABLE IF NOT EXISTS "product_return_flags" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "product_id" uuid NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'open',
+    "units_sold" bigint,
+    "units_returned" bigint,
+    "return_rate" decimal(6,2),
+    "flagged_at" timestamptz,
+    "reviewed_by" uuid,
+    "reviewed_at" timestamptz,
+    "review_note" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_product_return_flags_status" ON "product_return_flags" ("status");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_product_return_flags_product_id" ON "product_return_flags" ("product_id");
+CREATE INDEX IF NOT EXISTS "idx_product_return_flags_pharmacy_id" ON "product_return_flags" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "device_tokens" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "token" varchar(512) NOT NULL,
+    "platform" varchar(20) NOT NULL DEFAULT 'web',
+    "last_seen_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_device_tokens_token" ON "device_tokens" ("token");
+CREATE INDEX IF NOT EXISTS "idx_device_tokens_user_id" ON "device_tokens" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_device_tokens_pharmacy_id" ON "device_tokens" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "delivery_jobs" (
+    "id" uuid,
+    "pharmacy_id" uuid,
+    "kind" varchar(20) NOT NULL,
+    "target" varchar(500),
+    "summary" varchar(255),
+    "payload" text NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "max_attempts" bigint NOT NULL,
+    "next_attempt_at" timestamptz NOT NULL,
+    "locked_until" timestamptz,
+    "last_error" varchar(1000),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_delivery_jobs_due" ON "delivery_jobs" ("status","next_attempt_at");
+CREATE INDEX IF NOT EXISTS "idx_delivery_jobs_kind" ON "delivery_jobs" ("kind");
+CREATE INDEX IF NOT EXISTS "idx_delivery_jobs_pharmacy_id" ON "delivery_jobs" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "outbox_events" (
+    "id" uuid,
+    "pharmacy_id" uuid,
+    "type" varchar(50) NOT NULL,
+    "aggregate_id" uuid,
+    "payload" text NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "next_attempt_at" timestamptz NOT NULL,
+    "locked_until" timestamptz,
+    "handled" jsonb,
+    "last_error" varchar(1000),
+    "dispatched_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_outbox_events_dispatched_at" ON "outbox_events" ("dispatched_at");
+CREATE INDEX IF NOT EXISTS "idx_outbox_due" ON "outbox_events" ("status","next_attempt_at");
+CREATE INDEX IF NOT EXISTS "idx_outbox_events_aggregate_id" ON "outbox_events" ("aggregate_id");
+CREATE INDEX IF NOT EXISTS "idx_outbox_events_type" ON "outbox_events" ("type");
+CREATE INDEX IF NOT EXISTS "idx_outbox_events_pharmacy_id" ON "outbox_events" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "idempotency_keys" (
+    "id" uuid,
+    "pharmacy_id" uuid,
+    "user_id" uuid NOT NULL,
+    "scope" varchar(120) NOT NULL,
+    "key" varchar(255) NOT NULL,
+    "request_hash" varchar(64) NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'in_progress',
+    "response_status" bigint,
+    "content_type" varchar(100),
+    "response_body" bytea,
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_idempotency_keys_expires_at" ON "idempotency_keys" ("expires_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_idempotency_user_scope_key" ON "idempotency_keys" ("user_id","scope","key");
+CREATE INDEX IF NOT EXISTS "idx_idempotency_keys_pharmacy_id" ON "idempotency_keys" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "dispatch_batches" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "rider_id" uuid NOT NULL,
+    "start_latitude" decimal,
+    "start_longitude" decimal,
+    "depart_at" timestamptz,
+    "finish_at" timestamptz,
+    "distance_km" decimal,
+    "stops" bigint NOT NULL DEFAULT 0,
+    "created_by" uuid NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_dispatch_batches_rider_id" ON "dispatch_batches" ("rider_id");
+CREATE INDEX IF NOT EXISTS "idx_dispatch_batches_pharmacy_id" ON "dispatch_batches" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "wallet_top_ups" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "customer_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "payment_gateway_id" uuid NOT NULL,
+    "amount" decimal(12,2) NOT NULL,
+    "currency" varchar(10) DEFAULT 'NPR',
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "reference" varchar(255),
+    "failure_reason" text,
+    "entry_id" uuid,
+    "settled_by" uuid,
+    "settled_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_wallet_top_ups_status" ON "wallet_top_ups" ("status");
+CREATE INDEX IF NOT EXISTS "idx_wallet_top_ups_user_id" ON "wallet_top_ups" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_wallet_top_ups_customer_id" ON "wallet_top_ups" ("customer_id");
+CREATE INDEX IF NOT EXISTS "idx_wallet_top_ups_pharmacy_id" ON "wallet_top_ups" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "clearance_actions" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "kind" varchar(20) NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'open',
+    "name" varchar(255) NOT NULL,
+    "note" varchar(500),
+    "promo_code_id" uuid,
+    "discount_percent" decimal(5,2) DEFAULT 0,
+    "supplier_id" uuid,
+    "stock_value" decimal(12,2) NOT NULL DEFAULT 0,
+    "created_by" uuid NOT NULL,
+    "closed_by" uuid,
+    "closed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_clearance_actions_supplier_id" ON "clearance_actions" ("supplier_id");
+CREATE INDEX IF NOT EXISTS "idx_clearance_actions_status" ON "clearance_actions" ("status");
+CREATE INDEX IF NOT EXISTS "idx_clearance_actions_kind" ON "clearance_actions" ("kind");
+CREATE INDEX IF NOT EXISTS "idx_clearance_actions_pharmacy_id" ON "clearance_actions" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "clearance_items" (
+    "id" uuid,
+    "action_id" uuid NOT NULL,
+    "product_id" uuid NOT NULL,
+    "name" varchar(255),
+    "sku" varchar(100),
+    "quantity" bigint NOT NULL,
+    "unit_price" decimal(12,2) NOT NULL,
+    "stock_value" decimal(12,2) NOT NULL,
+    "returned_quantity" bigint DEFAULT 0,
+    "credit_amount" decimal(12,2) DEFAULT 0,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_clearance_items_product_id" ON "clearance_items" ("product_id");
+CREATE INDEX IF NOT EXISTS "idx_clearance_items_action_id" ON "clearance_items" ("action_id");
+
+CREATE TABLE IF NOT EXISTS "product_serials" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "product_id" uuid NOT NULL,
+    "serial_number" varchar(100) NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'in_stock',
+    "purchase_order_id" uuid,
+    "received_by" uuid NOT NULL,
+    "received_at" timestamptz NOT NULL,
+    "order_id" uuid,
+    "order_item_id" uuid,
+    "sold_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_product_serials_order_item_id" ON "product_serials" ("order_item_id");
+CREATE INDEX IF NOT EXISTS "idx_product_serials_order_id" ON "product_serials" ("order_id");
+CREATE INDEX IF NOT EXISTS "idx_product_serials_purchase_order_id" ON "product_serials" ("purchase_order_id");
+CREATE INDEX IF NOT EXISTS "idx_product_serials_status" ON "product_serials" ("status");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_product_serial" ON "product_serials" ("pharmacy_id","product_id","serial_number");
+
+CREATE TABLE IF NOT EXISTS "warranties" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "product_id" uuid NOT NULL,
+    "serial_id" uuid NOT NULL,
+    "serial_number" varchar(100) NOT NULL,
+    "order_id" uuid NOT NULL,
+    "order_item_id" uuid NOT NULL,
+    "customer_id" uuid,
+    "customer_name" varchar(255),
+    "customer_phone" varchar(50),
+    "customer_email" varchar(255),
+    "starts_at" timestamptz NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "voided_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_warranties_expires_at" ON "warranties" ("expires_at");
+CREATE INDEX IF NOT EXISTS "idx_warranties_customer_id" ON "warranties" ("customer_id");
+CREATE INDEX IF NOT EXISTS "idx_warranties_order_id" ON "warranties" ("order_id");
+CREATE INDEX IF NOT EXISTS "idx_warranties_serial_number" ON "warranties" ("serial_number");
+CREATE INDEX IF NOT EXISTS "idx_warranties_serial_id" ON "warranties" ("serial_id");
+CREATE INDEX IF NOT EXISTS "idx_warranties_product_id" ON "warranties" ("product_id");
+CREATE INDEX IF NOT EXISTS "idx_warranties_pharmacy_id" ON "warranties" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "warranty_claims" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "warranty_id" uuid NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'intake',
+    "issue" text NOT NULL,
+    "vendor_reference" varchar(100),
+    "resolution" varchar(20),
+    "note" text,
+    "created_by" uuid NOT NULL,
+    "sent_to_vendor_at" timestamptz,
+    "resolved_by" uuid,
+    "resolved_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_warranty_claims_status" ON "warranty_claims" ("status");
+CREATE INDEX IF NOT EXISTS "idx_warranty_claims_warranty_id" ON "warranty_claims" ("warranty_id");
+CREATE INDEX IF NOT EXISTS "idx_warranty_claims_pharmacy_id" ON "warranty_claims" ("pharmacy_id");
+
+CREATE TABLE IF NOT EXISTS "organizations" (
+    "id" uuid,
+    "name" varchar(255) NOT NULL,
+    "code" varchar(64) NOT NULL,
+    "join_code" varchar(32) NOT NULL,
+    "created_by" uuid NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_organizations_deleted_at" ON "organizations" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_organizations_join_code" ON "organizations" ("join_code");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_organizations_code" ON "organizations" ("code");
+
+CREATE TABLE IF NOT EXISTS "organization_members" (
+    "id" uuid,
+    "organization_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "role" varchar(20) NOT NULL DEFAULT 'viewer',
+    "added_by" uuid NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_organization_members_user_id" ON "organization_members" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_org_member" ON "organization_members" ("organization_id","user_id");
+
+CREATE TABLE IF NOT EXISTS "consent_records" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "customer_id" uuid NOT NULL,
+    "channel" varchar(20) NOT NULL,
+    "granted" boolean NOT NULL,
+    "source" varchar(30) NOT NULL,
+    "note" varchar(500),
+    "recorded_by" uuid,
+    "ip_address" varchar(64),
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_consent_records_created_at" ON "consent_records" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_consent_customer_channel" ON "consent_records" ("customer_id","channel");
+CREATE INDEX IF NOT EXISTS "idx_consent_records_pharmacy_id" ON "consent_records" ("pharmacy_id");
+
+ALTER TABLE "pharmacies" DROP CONSTRAINT IF EXISTS "fk_organizations_pharmacies";
+ALTER TABLE "pharmacies" ADD CONSTRAINT "fk_organizations_pharmacies" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id");
+ALTER TABLE "pharmacy_configs" DROP CONSTRAINT IF EXISTS "fk_pharmacy_configs_pharmacy";
+ALTER TABLE "pharmacy_configs" ADD CONSTRAINT "fk_pharmacy_configs_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "users" DROP CONSTRAINT IF EXISTS "fk_users_pharmacy";
+ALTER TABLE "users" ADD CONSTRAINT "fk_users_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "categories" DROP CONSTRAINT IF EXISTS "fk_categories_pharmacy";
+ALTER TABLE "categories" ADD CONSTRAINT "fk_categories_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "categories" DROP CONSTRAINT IF EXISTS "fk_categories_parent";
+ALTER TABLE "categories" ADD CONSTRAINT "fk_categories_parent" FOREIGN KEY ("parent_id") REFERENCES "categories"("id");
+ALTER TABLE "products" DROP CONSTRAINT IF EXISTS "fk_products_supplier";
+ALTER TABLE "products" ADD CONSTRAINT "fk_products_supplier" FOREIGN KEY ("supplier_id") REFERENCES "suppliers"("id");
+ALTER TABLE "products" DROP CONSTRAINT IF EXISTS "fk_products_pharmacy";
+ALTER TABLE "products" ADD CONSTRAINT "fk_products_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "products" DROP CONSTRAINT IF EXISTS "fk_products_category_detail";
+ALTER TABLE "products" ADD CONSTRAINT "fk_products_category_detail" FOREIGN KEY ("category_id") REFERENCES "categories"("id");
+ALTER TABLE "product_images" DROP CONSTRAINT IF EXISTS "fk_products_images";
+ALTER TABLE "product_images" ADD CONSTRAINT "fk_products_images" FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE "product_units" DROP CONSTRAINT IF EXISTS "fk_product_units_pharmacy";
+ALTER TABLE "product_units" ADD CONSTRAINT "fk_product_units_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "memberships" DROP CONSTRAINT IF EXISTS "fk_memberships_pharmacy";
+ALTER TABLE "memberships" ADD CONSTRAINT "fk_memberships_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "product_reviews" DROP CONSTRAINT IF EXISTS "fk_product_reviews_product";
+ALTER TABLE "product_reviews" ADD CONSTRAINT "fk_product_reviews_product" FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE "product_reviews" DROP CONSTRAINT IF EXISTS "fk_product_reviews_user";
+ALTER TABLE "product_reviews" ADD CONSTRAINT "fk_product_reviews_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "review_likes" DROP CONSTRAINT IF EXISTS "fk_review_likes_review";
+ALTER TABLE "review_likes" ADD CONSTRAINT "fk_review_likes_review" FOREIGN KEY ("review_id") REFERENCES "product_reviews"("id");
+ALTER TABLE "review_likes" DROP CONSTRAINT IF EXISTS "fk_review_likes_user";
+ALTER TABLE "review_likes" ADD CONSTRAINT "fk_review_likes_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "review_comments" DROP CONSTRAINT IF EXISTS "fk_review_comments_review";
+ALTER TABLE "review_comments" ADD CONSTRAINT "fk_review_comments_review" FOREIGN KEY ("review_id") REFERENCES "product_reviews"("id");
+ALTER TABLE "review_comments" DROP CONSTRAINT IF EXISTS "fk_review_comments_user";
+ALTER TABLE "review_comments" ADD CONSTRAINT "fk_review_comments_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "promo_codes" DROP CONSTRAINT IF EXISTS "fk_promo_codes_pharmacy";
+ALTER TABLE "promo_codes" ADD CONSTRAINT "fk_promo_codes_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "customers" DROP CONSTRAINT IF EXISTS "fk_customers_pharmacy";
+ALTER TABLE "customers" ADD CONSTRAINT "fk_customers_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "customers" DROP CONSTRAINT IF EXISTS "fk_customers_referred_by";
+ALTER TABLE "customers" ADD CONSTRAINT "fk_customers_referred_by" FOREIGN KEY ("referred_by_id") REFERENCES "customers"("id");
+ALTER TABLE "customer_memberships" DROP CONSTRAINT IF EXISTS "fk_customer_memberships_customer";
+ALTER TABLE "customer_memberships" ADD CONSTRAINT "fk_customer_memberships_customer" FOREIGN KEY ("customer_id") REFERENCES "customers"("id");
+ALTER TABLE "customer_memberships" DROP CONSTRAINT IF EXISTS "fk_customer_memberships_membership";
+ALTER TABLE "customer_memberships" ADD CONSTRAINT "fk_customer_memberships_membership" FOREIGN KEY ("membership_id") REFERENCES "memberships"("id");
+ALTER TABLE "referral_points_configs" DROP CONSTRAINT IF EXISTS "fk_referral_points_configs_pharmacy";
+ALTER TABLE "referral_points_configs" ADD CONSTRAINT "fk_referral_points_configs_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "staff_points_configs" DROP CONSTRAINT IF EXISTS "fk_staff_points_configs_pharmacy";
+ALTER TABLE "staff_points_configs" ADD CONSTRAINT "fk_staff_points_configs_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "campaigns" DROP CONSTRAINT IF EXISTS "fk_campaigns_segment";
+ALTER TABLE "campaigns" ADD CONSTRAINT "fk_campaigns_segment" FOREIGN KEY ("segment_id") REFERENCES "customer_segments"("id");
+ALTER TABLE "orders" DROP CONSTRAINT IF EXISTS "fk_orders_pharmacy";
+ALTER TABLE "orders" ADD CONSTRAINT "fk_orders_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "orders" DROP CONSTRAINT IF EXISTS "fk_orders_promo_code";
+ALTER TABLE "orders" ADD CONSTRAINT "fk_orders_promo_code" FOREIGN KEY ("promo_code_id") REFERENCES "promo_codes"("id");
+ALTER TABLE "orders" DROP CONSTRAINT IF EXISTS "fk_orders_customer";
+ALTER TABLE "orders" ADD CONSTRAINT "fk_orders_customer" FOREIGN KEY ("customer_id") REFERENCES "customers"("id");
+ALTER TABLE "points_transactions" DROP CONSTRAINT IF EXISTS "fk_points_transactions_customer";
+ALTER TABLE "points_transactions" ADD CONSTRAINT "fk_points_transactions_customer" FOREIGN KEY ("customer_id") REFERENCES "customers"("id");
+ALTER TABLE "points_transactions" DROP CONSTRAINT IF EXISTS "fk_points_transactions_order";
+ALTER TABLE "points_transactions" ADD CONSTRAINT "fk_points_transactions_order" FOREIGN KEY ("order_id") REFERENCES "orders"("id");
+ALTER TABLE "points_transactions" DROP CONSTRAINT IF EXISTS "fk_points_transactions_referral_customer";
+ALTER TABLE "points_transactions" ADD CONSTRAINT "fk_points_transactions_referral_customer" FOREIGN KEY ("referral_customer_id") REFERENCES "customers"("id");
+ALTER TABLE "order_items" DROP CONSTRAINT IF EXISTS "fk_order_items_product";
+ALTER TABLE "order_items" ADD CONSTRAINT "fk_order_items_product" FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE "order_items" DROP CONSTRAINT IF EXISTS "fk_orders_items";
+ALTER TABLE "order_items" ADD CONSTRAINT "fk_orders_items" FOREIGN KEY ("order_id") REFERENCES "orders"("id");
+ALTER TABLE "order_item_batches" DROP CONSTRAINT IF EXISTS "fk_order_items_batches";
+ALTER TABLE "order_item_batches" ADD CONSTRAINT "fk_order_items_batches" FOREIGN KEY ("order_item_id") REFERENCES "order_items"("id");
+ALTER TABLE "order_feedbacks" DROP CONSTRAINT IF EXISTS "fk_order_feedbacks_user";
+ALTER TABLE "order_feedbacks" ADD CONSTRAINT "fk_order_feedbacks_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "order_feedbacks" DROP CONSTRAINT IF EXISTS "fk_order_feedbacks_order";
+ALTER TABLE "order_feedbacks" ADD CONSTRAINT "fk_order_feedbacks_order" FOREIGN KEY ("order_id") REFERENCES "orders"("id");
+ALTER TABLE "order_return_requests" DROP CONSTRAINT IF EXISTS "fk_order_return_requests_order";
+ALTER TABLE "order_return_requests" ADD CONSTRAINT "fk_order_return_requests_order" FOREIGN KEY ("order_id") REFERENCES "orders"("id");
+ALTER TABLE "order_return_requests" DROP CONSTRAINT IF EXISTS "fk_order_return_requests_user";
+ALTER TABLE "order_return_requests" ADD CONSTRAINT "fk_order_return_requests_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "payment_gateways" DROP CONSTRAINT IF EXISTS "fk_payment_gateways_pharmacy";
+ALTER TABLE "payment_gateways" ADD CONSTRAINT "fk_payment_gateways_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "payments" DROP CONSTRAINT IF EXISTS "fk_payments_payment_gateway";
+ALTER TABLE "payments" ADD CONSTRAINT "fk_payments_payment_gateway" FOREIGN KEY ("payment_gateway_id") REFERENCES "payment_gateways"("id");
+ALTER TABLE "payments" DROP CONSTRAINT IF EXISTS "fk_orders_payments";
+ALTER TABLE "payments" ADD CONSTRAINT "fk_orders_payments" FOREIGN KEY ("order_id") REFERENCES "orders"("id");
+ALTER TABLE "invoices" DROP CONSTRAINT IF EXISTS "fk_invoices_pharmacy";
+ALTER TABLE "invoices" ADD CONSTRAINT "fk_invoices_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "invoices" DROP CONSTRAINT IF EXISTS "fk_invoices_order";
+ALTER TABLE "invoices" ADD CONSTRAINT "fk_invoices_order" FOREIGN KEY ("order_id") REFERENCES "orders"("id");
+ALTER TABLE "inventory_batches" DROP CONSTRAINT IF EXISTS "fk_inventory_batches_product";
+ALTER TABLE "inventory_batches" ADD CONSTRAINT "fk_inventory_batches_product" FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE "activity_logs" DROP CONSTRAINT IF EXISTS "fk_activity_logs_user";
+ALTER TABLE "activity_logs" ADD CONSTRAINT "fk_activity_logs_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "activity_logs" DROP CONSTRAINT IF EXISTS "fk_activity_logs_pharmacy";
+ALTER TABLE "activity_logs" ADD CONSTRAINT "fk_activity_logs_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "notifications" DROP CONSTRAINT IF EXISTS "fk_notifications_user";
+ALTER TABLE "notifications" ADD CONSTRAINT "fk_notifications_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "notifications" DROP CONSTRAINT IF EXISTS "fk_notifications_pharmacy";
+ALTER TABLE "notifications" ADD CONSTRAINT "fk_notifications_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "promos" DROP CONSTRAINT IF EXISTS "fk_promos_pharmacy";
+ALTER TABLE "promos" ADD CONSTRAINT "fk_promos_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "duty_rosters" DROP CONSTRAINT IF EXISTS "fk_duty_rosters_user";
+ALTER TABLE "duty_rosters" ADD CONSTRAINT "fk_duty_rosters_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "shift_swaps" DROP CONSTRAINT IF EXISTS "fk_shift_swaps_roster";
+ALTER TABLE "shift_swaps" ADD CONSTRAINT "fk_shift_swaps_roster" FOREIGN KEY ("roster_id") REFERENCES "duty_rosters"("id");
+ALTER TABLE "shift_swaps" DROP CONSTRAINT IF EXISTS "fk_shift_swaps_offerer";
+ALTER TABLE "shift_swaps" ADD CONSTRAINT "fk_shift_swaps_offerer" FOREIGN KEY ("offered_by") REFERENCES "users"("id");
+ALTER TABLE "shift_swap_requests" DROP CONSTRAINT IF EXISTS "fk_shift_swap_requests_user";
+ALTER TABLE "shift_swap_requests" ADD CONSTRAINT "fk_shift_swap_requests_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "shift_swap_requests" DROP CONSTRAINT IF EXISTS "fk_shift_swaps_requests";
+ALTER TABLE "shift_swap_requests" ADD CONSTRAINT "fk_shift_swaps_requests" FOREIGN KEY ("swap_id") REFERENCES "shift_swaps"("id");
+ALTER TABLE "shift_swap_events" DROP CONSTRAINT IF EXISTS "fk_shift_swaps_events";
+ALTER TABLE "shift_swap_events" ADD CONSTRAINT "fk_shift_swaps_events" FOREIGN KEY ("swap_id") REFERENCES "shift_swaps"("id");
+ALTER TABLE "branch_transfers" DROP CONSTRAINT IF EXISTS "fk_branch_transfers_from_branch";
+ALTER TABLE "branch_transfers" ADD CONSTRAINT "fk_branch_transfers_from_branch" FOREIGN KEY ("from_branch_id") REFERENCES "branches"("id");
+ALTER TABLE "branch_transfers" DROP CONSTRAINT IF EXISTS "fk_branch_transfers_to_branch";
+ALTER TABLE "branch_transfers" ADD CONSTRAINT "fk_branch_transfers_to_branch" FOREIGN KEY ("to_branch_id") REFERENCES "branches"("id");
+ALTER TABLE "branch_transfer_items" DROP CONSTRAINT IF EXISTS "fk_branch_transfers_items";
+ALTER TABLE "branch_transfer_items" ADD CONSTRAINT "fk_branch_transfers_items" FOREIGN KEY ("transfer_id") REFERENCES "branch_transfers"("id");
+ALTER TABLE "branch_transfer_items" DROP CONSTRAINT IF EXISTS "fk_branch_transfer_items_product";
+ALTER TABLE "branch_transfer_items" ADD CONSTRAINT "fk_branch_transfer_items_product" FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE "stock_transfers" DROP CONSTRAINT IF EXISTS "fk_stock_transfers_source_pharmacy";
+ALTER TABLE "stock_transfers" ADD CONSTRAINT "fk_stock_transfers_source_pharmacy" FOREIGN KEY ("source_pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "stock_transfers" DROP CONSTRAINT IF EXISTS "fk_stock_transfers_dest_pharmacy";
+ALTER TABLE "stock_transfers" ADD CONSTRAINT "fk_stock_transfers_dest_pharmacy" FOREIGN KEY ("dest_pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "stock_transfer_items" DROP CONSTRAINT IF EXISTS "fk_stock_transfers_items";
+ALTER TABLE "stock_transfer_items" ADD CONSTRAINT "fk_stock_transfers_items" FOREIGN KEY ("transfer_id") REFERENCES "stock_transfers"("id");
+ALTER TABLE "stock_transfer_item_batches" DROP CONSTRAINT IF EXISTS "fk_stock_transfer_items_batches";
+ALTER TABLE "stock_transfer_item_batches" ADD CONSTRAINT "fk_stock_transfer_items_batches" FOREIGN KEY ("item_id") REFERENCES "stock_transfer_items"("id");
+ALTER TABLE "stock_transfer_events" DROP CONSTRAINT IF EXISTS "fk_stock_transfers_events";
+ALTER TABLE "stock_transfer_events" ADD CONSTRAINT "fk_stock_transfers_events" FOREIGN KEY ("transfer_id") REFERENCES "stock_transfers"("id");
+ALTER TABLE "credit_notes" DROP CONSTRAINT IF EXISTS "fk_credit_notes_invoice";
+ALTER TABLE "credit_notes" ADD CONSTRAINT "fk_credit_notes_invoice" FOREIGN KEY ("invoice_id") REFERENCES "invoices"("id");
+ALTER TABLE "daily_logs" DROP CONSTRAINT IF EXISTS "fk_daily_logs_creator";
+ALTER TABLE "daily_logs" ADD CONSTRAINT "fk_daily_logs_creator" FOREIGN KEY ("created_by") REFERENCES "users"("id");
+ALTER TABLE "conversations" DROP CONSTRAINT IF EXISTS "fk_conversations_pharmacy";
+ALTER TABLE "conversations" ADD CONSTRAINT "fk_conversations_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "conversations" DROP CONSTRAINT IF EXISTS "fk_conversations_customer";
+ALTER TABLE "conversations" ADD CONSTRAINT "fk_conversations_customer" FOREIGN KEY ("customer_id") REFERENCES "customers"("id");
+ALTER TABLE "conversations" DROP CONSTRAINT IF EXISTS "fk_conversations_user";
+ALTER TABLE "conversations" ADD CONSTRAINT "fk_conversations_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "chat_messages" DROP CONSTRAINT IF EXISTS "fk_chat_messages_conversation";
+ALTER TABLE "chat_messages" ADD CONSTRAINT "fk_chat_messages_conversation" FOREIGN KEY ("conversation_id") REFERENCES "conversations"("id");
+ALTER TABLE "user_addresses" DROP CONSTRAINT IF EXISTS "fk_user_addresses_user";
+ALTER TABLE "user_addresses" ADD CONSTRAINT "fk_user_addresses_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "announcements" DROP CONSTRAINT IF EXISTS "fk_announcements_pharmacy";
+ALTER TABLE "announcements" ADD CONSTRAINT "fk_announcements_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "blog_categories" DROP CONSTRAINT IF EXISTS "fk_blog_categories_pharmacy";
+ALTER TABLE "blog_categories" ADD CONSTRAINT "fk_blog_categories_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "blog_categories" DROP CONSTRAINT IF EXISTS "fk_blog_categories_parent";
+ALTER TABLE "blog_categories" ADD CONSTRAINT "fk_blog_categories_parent" FOREIGN KEY ("parent_id") REFERENCES "blog_categories"("id");
+ALTER TABLE "blog_posts" DROP CONSTRAINT IF EXISTS "fk_blog_posts_pharmacy";
+ALTER TABLE "blog_posts" ADD CONSTRAINT "fk_blog_posts_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+ALTER TABLE "blog_posts" DROP CONSTRAINT IF EXISTS "fk_blog_posts_category";
+ALTER TABLE "blog_posts" ADD CONSTRAINT "fk_blog_posts_category" FOREIGN KEY ("category_id") REFERENCES "blog_categories"("id");
+ALTER TABLE "blog_posts" DROP CONSTRAINT IF EXISTS "fk_blog_posts_author";
+ALTER TABLE "blog_posts" ADD CONSTRAINT "fk_blog_posts_author" FOREIGN KEY ("author_id") REFERENCES "users"("id");
+ALTER TABLE "blog_post_media" DROP CONSTRAINT IF EXISTS "fk_blog_post_media_post";
+ALTER TABLE "blog_post_media" ADD CONSTRAINT "fk_blog_post_media_post" FOREIGN KEY ("post_id") REFERENCES "blog_posts"("id");
+ALTER TABLE "blog_post_likes" DROP CONSTRAINT IF EXISTS "fk_blog_post_likes_post";
+ALTER TABLE "blog_post_likes" ADD CONSTRAINT "fk_blog_post_likes_post" FOREIGN KEY ("post_id") REFERENCES "blog_posts"("id");
+ALTER TABLE "blog_post_likes" DROP CONSTRAINT IF EXISTS "fk_blog_post_likes_user";
+ALTER TABLE "blog_post_likes" ADD CONSTRAINT "fk_blog_post_likes_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "blog_post_comments" DROP CONSTRAINT IF EXISTS "fk_blog_post_comments_user";
+ALTER TABLE "blog_post_comments" ADD CONSTRAINT "fk_blog_post_comments_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "blog_post_comments" DROP CONSTRAINT IF EXISTS "fk_blog_post_comments_parent";
+ALTER TABLE "blog_post_comments" ADD CONSTRAINT "fk_blog_post_comments_parent" FOREIGN KEY ("parent_id") REFERENCES "blog_post_comments"("id");
+ALTER TABLE "blog_post_comments" DROP CONSTRAINT IF EXISTS "fk_blog_post_comments_post";
+ALTER TABLE "blog_post_comments" ADD CONSTRAINT "fk_blog_post_comments_post" FOREIGN KEY ("post_id") REFERENCES "blog_posts"("id");
+ALTER TABLE "blog_post_views" DROP CONSTRAINT IF EXISTS "fk_blog_post_views_post";
+ALTER TABLE "blog_post_views" ADD CONSTRAINT "fk_blog_post_views_post" FOREIGN KEY ("post_id") REFERENCES "blog_posts"("id");
+ALTER TABLE "blog_post_views" DROP CONSTRAINT IF EXISTS "fk_blog_post_views_user";
+ALTER TABLE "blog_post_views" ADD CONSTRAINT "fk_blog_post_views_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "ai_generations" DROP CONSTRAINT IF EXISTS "fk_ai_generations_requester";
+ALTER TABLE "ai_generations" ADD CONSTRAINT "fk_ai_generations_requester" FOREIGN KEY ("requested_by") REFERENCES "users"("id");
+ALTER TABLE "purchase_orders" DROP CONSTRAINT IF EXISTS "fk_purchase_orders_supplier";
+ALTER TABLE "purchase_orders" ADD CONSTRAINT "fk_purchase_orders_supplier" FOREIGN KEY ("supplier_id") REFERENCES "suppliers"("id");
+ALTER TABLE "purchase_order_items" DROP CONSTRAINT IF EXISTS "fk_purchase_order_items_product";
+ALTER TABLE "purchase_order_items" ADD CONSTRAINT "fk_purchase_order_items_product" FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE "purchase_order_items" DROP CONSTRAINT IF EXISTS "fk_purchase_orders_items";
+ALTER TABLE "purchase_order_items" ADD CONSTRAINT "fk_purchase_orders_items" FOREIGN KEY ("purchase_order_id") REFERENCES "purchase_orders"("id");
+ALTER TABLE "flash_sale_items" DROP CONSTRAINT IF EXISTS "fk_flash_sale_items_product";
+ALTER TABLE "flash_sale_items" ADD CONSTRAINT "fk_flash_sale_items_product" FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE "flash_sale_items" DROP CONSTRAINT IF EXISTS "fk_flash_sales_items";
+ALTER TABLE "flash_sale_items" ADD CONSTRAINT "fk_flash_sales_items" FOREIGN KEY ("flash_sale_id") REFERENCES "flash_sales"("id");
+ALTER TABLE "preorders" DROP CONSTRAINT IF EXISTS "fk_preorders_product";
+ALTER TABLE "preorders" ADD CONSTRAINT "fk_preorders_product" FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE "cart_items" DROP CONSTRAINT IF EXISTS "fk_cart_items_product";
+ALTER TABLE "cart_items" ADD CONSTRAINT "fk_cart_items_product" FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE "cart_items" DROP CONSTRAINT IF EXISTS "fk_carts_items";
+ALTER TABLE "cart_items" ADD CONSTRAINT "fk_carts_items" FOREIGN KEY ("cart_id") REFERENCES "carts"("id");
+ALTER TABLE "deliveries" DROP CONSTRAINT IF EXISTS "fk_deliveries_assignee";
+ALTER TABLE "deliveries" ADD CONSTRAINT "fk_deliveries_assignee" FOREIGN KEY ("assigned_to") REFERENCES "users"("id");
+ALTER TABLE "deliveries" DROP CONSTRAINT IF EXISTS "fk_dispatch_batches_deliveries";
+ALTER TABLE "deliveries" ADD CONSTRAINT "fk_dispatch_batches_deliveries" FOREIGN KEY ("batch_id") REFERENCES "dispatch_batches"("id");
+ALTER TABLE "delivery_events" DROP CONSTRAINT IF EXISTS "fk_deliveries_events";
+ALTER TABLE "delivery_events" ADD CONSTRAINT "fk_deliveries_events" FOREIGN KEY ("delivery_id") REFERENCES "deliveries"("id");
+ALTER TABLE "product_return_flags" DROP CONSTRAINT IF EXISTS "fk_product_return_flags_product";
+ALTER TABLE "product_return_flags" ADD CONSTRAINT "fk_product_return_flags_product" FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE "dispatch_batches" DROP CONSTRAINT IF EXISTS "fk_dispatch_batches_rider";
+ALTER TABLE "dispatch_batches" ADD CONSTRAINT "fk_dispatch_batches_rider" FOREIGN KEY ("rider_id") REFERENCES "users"("id");
+ALTER TABLE "wallet_top_ups" DROP CONSTRAINT IF EXISTS "fk_wallet_top_ups_payment_gateway";
+ALTER TABLE "wallet_top_ups" ADD CONSTRAINT "fk_wallet_top_ups_payment_gateway" FOREIGN KEY ("payment_gateway_id") REFERENCES "payment_gateways"("id");
+ALTER TABLE "clearance_actions" DROP CONSTRAINT IF EXISTS "fk_clearance_actions_supplier";
+ALTER TABLE "clearance_actions" ADD CONSTRAINT "fk_clearance_actions_supplier" FOREIGN KEY ("supplier_id") REFERENCES "suppliers"("id");
+ALTER TABLE "clearance_actions" DROP CONSTRAINT IF EXISTS "fk_clearance_actions_promo_code";
+ALTER TABLE "clearance_actions" ADD CONSTRAINT "fk_clearance_actions_promo_code" FOREIGN KEY ("promo_code_id") REFERENCES "promo_codes"("id");
+ALTER TABLE "clearance_items" DROP CONSTRAINT IF EXISTS "fk_clearance_actions_items";
+ALTER TABLE "clearance_items" ADD CONSTRAINT "fk_clearance_actions_items" FOREIGN KEY ("action_id") REFERENCES "clearance_actions"("id");
+ALTER TABLE "product_serials" DROP CONSTRAINT IF EXISTS "fk_order_items_serials";
+ALTER TABLE "product_serials" ADD CONSTRAINT "fk_order_items_serials" FOREIGN KEY ("order_item_id") REFERENCES "order_items"("id");
+ALTER TABLE "product_serials" DROP CONSTRAINT IF EXISTS "fk_product_serials_product";
+ALTER TABLE "product_serials" ADD CONSTRAINT "fk_product_serials_product" FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE "product_serials" DROP CONSTRAINT IF EXISTS "fk_product_serials_order";
+ALTER TABLE "product_serials" ADD CONSTRAINT "fk_product_serials_order" FOREIGN KEY ("order_id") REFERENCES "orders"("id");
+ALTER TABLE "warranties" DROP CONSTRAINT IF EXISTS "fk_warranties_product";
+ALTER TABLE "warranties" ADD CONSTRAINT "fk_warranties_product" FOREIGN KEY ("product_id") REFERENCES "products"("id");
+ALTER TABLE "warranty_claims" DROP CONSTRAINT IF EXISTS "fk_warranties_claims";
+ALTER TABLE "warranty_claims" ADD CONSTRAINT "fk_warranties_claims" FOREIGN KEY ("warranty_id") REFERENCES "warranties"("id");
+ALTER TABLE "organization_members" DROP CONSTRAINT IF EXISTS "fk_organization_members_user";
+ALTER TABLE "organization_members" ADD CONSTRAINT "fk_organization_members_user" FOREIGN KEY ("user_id") REFERENCES "users"("id");
+ALTER TABLE "consent_records" DROP CONSTRAINT IF EXISTS "fk_consent_records_customer";
+ALTER TABLE "consent_records" ADD CONSTRAINT "fk_consent_records_customer" FOREIGN KEY ("customer_id") REFERENCES "customers"("id");
+
+-- +goose Down
+DROP TABLE IF EXISTS "consent_records" CASCADE;
+DROP TABLE IF EXISTS "organization_members" CASCADE;
+DROP TABLE IF EXISTS "organizations" CASCADE;
+DROP TABLE IF EXISTS "warranty_claims" CASCADE;
+DROP TABLE IF EXISTS "warranties" CASCADE;
+DROP TABLE IF EXISTS "product_serials" CASCADE;
+DROP TABLE IF EXISTS "clearance_items" CASCADE;
+DROP TABLE IF EXISTS "clearance_actions" CASCADE;
+DROP TABLE IF EXISTS "wallet_top_ups" CASCADE;
+DROP TABLE IF EXISTS "dispatch_batches" CASCADE;
+DROP TABLE IF EXISTS "idempotency_keys" CASCADE;
+DROP TABLE IF EXISTS "outbox_events" CASCADE;
+DROP TABLE IF EXISTS "delivery_jobs" CASCADE;
+DROP TABLE IF EXISTS "device_tokens" CASCADE;
+DROP TABLE IF EXISTS "product_return_flags" CASCADE;
+DROP TABLE IF EXISTS "delivery_events" CASCADE;
+DROP TABLE IF EXISTS "deliveries" CASCADE;
+DROP TABLE IF EXISTS "cart_items" CASCADE;
+DROP TABLE IF EXISTS "carts" CASCADE;
+DROP TABLE IF EXISTS "promo_daily_stats" CASCADE;
+DROP TABLE IF EXISTS "roles" CASCADE;
+DROP TABLE IF EXISTS "pharmacy_config_versions" CASCADE;
+DROP TABLE IF EXISTS "password_reset_tokens" CASCADE;
+DROP TABLE IF EXISTS "otp_codes" CASCADE;
+DROP TABLE IF EXISTS "training_attempts" CASCADE;
+DROP TABLE IF EXISTS "training_quizzes" CASCADE;
+DROP TABLE IF EXISTS "sop_acks" CASCADE;
+DROP TABLE IF EXISTS "sop_documents" CASCADE;
+DROP TABLE IF EXISTS "preorders" CASCADE;
+DROP TABLE IF EXISTS "flash_sale_redemptions" CASCADE;
+DROP TABLE IF EXISTS "flash_sale_items" CASCADE;
+DROP TABLE IF EXISTS "flash_sales" CASCADE;
+DROP TABLE IF EXISTS "purchase_order_items" CASCADE;
+DROP TABLE IF EXISTS "purchase_orders" CASCADE;
+DROP TABLE IF EXISTS "ai_generations" CASCADE;
+DROP TABLE IF EXISTS "blog_post_views" CASCADE;
+DROP TABLE IF EXISTS "blog_post_comments" CASCADE;
+DROP TABLE IF EXISTS "blog_post_likes" CASCADE;
+DROP TABLE IF EXISTS "blog_post_media" CASCADE;
+DROP TABLE IF EXISTS "blog_posts" CASCADE;
+DROP TABLE IF EXISTS "blog_categories" CASCADE;
+DROP TABLE IF EXISTS "announcement_acks" CASCADE;
+DROP TABLE IF EXISTS "announcements" CASCADE;
+DROP TABLE IF EXISTS "user_addresses" CASCADE;
+DROP TABLE IF EXISTS "chat_messages" CASCADE;
+DROP TABLE IF EXISTS "conversations" CASCADE;
+DROP TABLE IF EXISTS "daily_logs" CASCADE;
+DROP TABLE IF EXISTS "credit_notes" CASCADE;
+DROP TABLE IF EXISTS "store_credit_entries" CASCADE;
+DROP TABLE IF EXISTS "gift_card_transactions" CASCADE;
+DROP TABLE IF EXISTS "gift_cards" CASCADE;
+DROP TABLE IF EXISTS "order_status_histories" CASCADE;
+DROP TABLE IF EXISTS "product_daily_views" CASCADE;
+DROP TABLE IF EXISTS "hashtags" CASCADE;
+DROP TABLE IF EXISTS "stock_transfer_events" CASCADE;
+DROP TABLE IF EXISTS "stock_transfer_item_batches" CASCADE;
+DROP TABLE IF EXISTS "stock_transfer_items" CASCADE;
+DROP TABLE IF EXISTS "stock_transfers" CASCADE;
+DROP TABLE IF EXISTS "branch_transfer_items" CASCADE;
+DROP TABLE IF EXISTS "branch_transfers" CASCADE;
+DROP TABLE IF EXISTS "branches" CASCADE;
+DROP TABLE IF EXISTS "shift_swap_events" CASCADE;
+DROP TABLE IF EXISTS "shift_swap_requests" CASCADE;
+DROP TABLE IF EXISTS "shift_swaps" CASCADE;
+DROP TABLE IF EXISTS "duty_rosters" CASCADE;
+DROP TABLE IF EXISTS "promos" CASCADE;
+DROP TABLE IF EXISTS "notifications" CASCADE;
+DROP TABLE IF EXISTS "activity_logs" CASCADE;
+DROP TABLE IF EXISTS "inventory_alerts" CASCADE;
+DROP TABLE IF EXISTS "inventory_batches" CASCADE;
+DROP TABLE IF EXISTS "invoices" CASCADE;
+DROP TABLE IF EXISTS "payments" CASCADE;
+DROP TABLE IF EXISTS "payment_gateways" CASCADE;
+DROP TABLE IF EXISTS "order_return_requests" CASCADE;
+DROP TABLE IF EXISTS "order_feedbacks" CASCADE;
+DROP TABLE IF EXISTS "order_item_batches" CASCADE;
+DROP TABLE IF EXISTS "order_items" CASCADE;
+DROP TABLE IF EXISTS "points_transactions" CASCADE;
+DROP TABLE IF EXISTS "orders" CASCADE;
+DROP TABLE IF EXISTS "campaign_recipients" CASCADE;
+DROP TABLE IF EXISTS "campaigns" CASCADE;
+DROP TABLE IF EXISTS "customer_segments" CASCADE;
+DROP TABLE IF EXISTS "staff_points_transactions" CASCADE;
+DROP TABLE IF EXISTS "staff_points_configs" CASCADE;
+DROP TABLE IF EXISTS "referral_points_configs" CASCADE;
+DROP TABLE IF EXISTS "customer_memberships" CASCADE;
+DROP TABLE IF EXISTS "customers" CASCADE;
+DROP TABLE IF EXISTS "promo_codes" CASCADE;
+DROP TABLE IF EXISTS "review_comments" CASCADE;
+DROP TABLE IF EXISTS "review_likes" CASCADE;
+DROP TABLE IF EXISTS "product_reviews" CASCADE;
+DROP TABLE IF EXISTS "memberships" CASCADE;
+DROP TABLE IF EXISTS "product_units" CASCADE;
+DROP TABLE IF EXISTS "product_images" CASCADE;
+DROP TABLE IF EXISTS "products" CASCADE;
+DROP TABLE IF EXISTS "suppliers" CASCADE;
+DROP TABLE IF EXISTS "categories" CASCADE;
+DROP TABLE IF EXISTS "users" CASCADE;
+DROP TABLE IF EXISTS "pharmacy_configs" CASCADE;
+DROP TABLE IF EXISTS "pharmacies" CASCADE;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE "orders"
+    ADD COLUMN IF NOT EXISTS "estimated_ready_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "estimated_delivery_at" timestamptz;
+
+-- +goose Down
+ALTER TABLE "orders"
+    DROP COLUMN IF EXISTS "estimated_ready_at",
+    DROP COLUMN IF EXISTS "estimated_delivery_at";
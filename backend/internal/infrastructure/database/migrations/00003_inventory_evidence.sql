@@ -0,0 +1,43 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "stock_adjustments" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "batch_id" uuid NOT NULL,
+    "product_id" uuid NOT NULL,
+    "quantity" bigint NOT NULL,
+    "quantity_after" bigint NOT NULL,
+    "reason" varchar(30) NOT NULL,
+    "note" varchar(500),
+    "created_by" uuid NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_stock_adjustments_pharmacy_id" ON "stock_adjustments" ("pharmacy_id");
+CREATE INDEX IF NOT EXISTS "idx_stock_adjustments_batch_id" ON "stock_adjustments" ("batch_id");
+CREATE INDEX IF NOT EXISTS "idx_stock_adjustments_product_id" ON "stock_adjustments" ("product_id");
+
+CREATE TABLE IF NOT EXISTS "inventory_photos" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "batch_id" uuid NOT NULL,
+    "adjustment_id" uuid,
+    "clearance_action_id" uuid,
+    "caption" varchar(255),
+    "url" varchar(500) NOT NULL,
+    "uploaded_by" uuid NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_inventory_photos_pharmacy_id" ON "inventory_photos" ("pharmacy_id");
+CREATE INDEX IF NOT EXISTS "idx_inventory_photos_batch_id" ON "inventory_photos" ("batch_id");
+CREATE INDEX IF NOT EXISTS "idx_inventory_photos_adjustment_id" ON "inventory_photos" ("adjustment_id");
+CREATE INDEX IF NOT EXISTS "idx_inventory_photos_clearance_action_id" ON "inventory_photos" ("clearance_action_id");
+
+ALTER TABLE "inventory_photos" DROP CONSTRAINT IF EXISTS "fk_stock_adjustments_photos";
+ALTER TABLE "inventory_photos" ADD CONSTRAINT "fk_stock_adjustments_photos" FOREIGN KEY ("adjustment_id") REFERENCES "stock_adjustments"("id");
+ALTER TABLE "inventory_photos" DROP CONSTRAINT IF EXISTS "fk_clearance_actions_photos";
+ALTER TABLE "inventory_photos" ADD CONSTRAINT "fk_clearance_actions_photos" FOREIGN KEY ("clearance_action_id") REFERENCES "clearance_actions"("id");
+
+-- +goose Down
+DROP TABLE IF EXISTS "inventory_photos" CASCADE;
+DROP TABLE IF EXISTS "stock_adjustments" CASCADE;
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "api_usage_daily" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "day" date NOT NULL,
+    "method" varchar(10) NOT NULL,
+    "route" varchar(255) NOT NULL,
+    "requests" bigint NOT NULL DEFAULT 0,
+    "client_errors" bigint NOT NULL DEFAULT 0,
+    "server_errors" bigint NOT NULL DEFAULT 0,
+    "latency_ms_total" bigint NOT NULL DEFAULT 0,
+    "latency_ms_max" bigint NOT NULL DEFAULT 0,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_usage_day_route" ON "api_usage_daily" ("pharmacy_id","day","method","route");
+
+-- +goose Down
+DROP TABLE IF EXISTS "api_usage_daily" CASCADE;
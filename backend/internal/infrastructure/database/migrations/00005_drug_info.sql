@@ -0,0 +1,40 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "product_ingredients" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "product_id" uuid NOT NULL,
+    "ingredient" varchar(255) NOT NULL,
+    "strength" varchar(100),
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_product_ingredients_pharmacy_id" ON "product_ingredients" ("pharmacy_id");
+CREATE INDEX IF NOT EXISTS "idx_product_ingredients_ingredient" ON "product_ingredients" ("ingredient");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_product_ingredient" ON "product_ingredients" ("product_id", "ingredient");
+
+CREATE TABLE IF NOT EXISTS "drug_interactions" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "ingredient_a" varchar(255) NOT NULL,
+    "ingredient_b" varchar(255) NOT NULL,
+    "severity" varchar(20) NOT NULL,
+    "description" text,
+    "created_by" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_drug_interaction_pair" ON "drug_interactions" ("pharmacy_id", "ingredient_a", "ingredient_b");
+
+ALTER TABLE "orders"
+    ADD COLUMN IF NOT EXISTS "clinical_warnings" jsonb,
+    ADD COLUMN IF NOT EXISTS "warnings_acknowledged_by" uuid,
+    ADD COLUMN IF NOT EXISTS "warnings_acknowledged_at" timestamptz;
+
+-- +goose Down
+ALTER TABLE "orders"
+    DROP COLUMN IF EXISTS "clinical_warnings",
+    DROP COLUMN IF EXISTS "warnings_acknowledged_by",
+    DROP COLUMN IF EXISTS "warnings_acknowledged_at";
+DROP TABLE IF EXISTS "drug_interactions";
+DROP TABLE IF EXISTS "product_ingredients";
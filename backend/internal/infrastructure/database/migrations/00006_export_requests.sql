@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "export_requests" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "kind" varchar(50) NOT NULL,
+    "reason" text,
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "requested_by" uuid NOT NULL,
+    "reviewed_by" uuid,
+    "reviewed_at" timestamptz,
+    "review_note" text,
+    "expires_at" timestamptz,
+    "used_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_export_requests_pharmacy_id" ON "export_requests" ("pharmacy_id");
+CREATE INDEX IF NOT EXISTS "idx_export_requests_status" ON "export_requests" ("status");
+CREATE INDEX IF NOT EXISTS "idx_export_requests_requested_by" ON "export_requests" ("requested_by");
+
+-- +goose Down
+DROP TABLE IF EXISTS "export_requests";
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE "users"
+    ADD COLUMN IF NOT EXISTS "license_expires_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "license_reminder_days" bigint;
+ALTER TABLE "pharmacy_configs"
+    ADD COLUMN IF NOT EXISTS "license_policy" jsonb;
+
+-- +goose Down
+ALTER TABLE "pharmacy_configs"
+    DROP COLUMN IF EXISTS "license_policy";
+ALTER TABLE "users"
+    DROP COLUMN IF EXISTS "license_expires_at",
+    DROP COLUMN IF EXISTS "license_reminder_days";
@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "wishlist_items" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "product_id" uuid NOT NULL,
+    "added_price" decimal(12,2),
+    "seen_price" decimal(12,2),
+    "seen_in_stock" boolean,
+    "notified_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_wishlist_items_pharmacy_id" ON "wishlist_items" ("pharmacy_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_wishlist_user_product" ON "wishlist_items" ("user_id","product_id");
+CREATE INDEX IF NOT EXISTS "idx_wishlist_items_product_id" ON "wishlist_items" ("product_id");
+
+-- +goose Down
+DROP TABLE IF EXISTS "wishlist_items" CASCADE;
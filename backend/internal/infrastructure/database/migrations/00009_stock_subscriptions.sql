@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "stock_subscriptions" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "product_id" uuid NOT NULL,
+    "user_id" uuid,
+    "phone" varchar(50),
+    "email" varchar(255),
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "notified_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_stock_subscriptions_pharmacy_id" ON "stock_subscriptions" ("pharmacy_id");
+CREATE INDEX IF NOT EXISTS "idx_stock_subscriptions_product_status" ON "stock_subscriptions" ("product_id","status");
+CREATE INDEX IF NOT EXISTS "idx_stock_subscriptions_user_id" ON "stock_subscriptions" ("user_id");
+
+-- +goose Down
+DROP TABLE IF EXISTS "stock_subscriptions" CASCADE;
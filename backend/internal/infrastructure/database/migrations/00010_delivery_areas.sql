@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE "pharmacy_configs"
+    ADD COLUMN IF NOT EXISTS "delivery_areas" jsonb;
+
+-- +goose Down
+ALTER TABLE "pharmacy_configs"
+    DROP COLUMN IF EXISTS "delivery_areas";
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE "orders"
+    ADD COLUMN IF NOT EXISTS "conversation_id" uuid;
+CREATE INDEX IF NOT EXISTS "idx_orders_conversation_id" ON "orders" ("conversation_id");
+
+-- +goose Down
+DROP INDEX IF EXISTS "idx_orders_conversation_id";
+ALTER TABLE "orders"
+    DROP COLUMN IF EXISTS "conversation_id";
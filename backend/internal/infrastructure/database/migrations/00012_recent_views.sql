@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "recent_views" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "viewer" varchar(80) NOT NULL,
+    "user_id" uuid,
+    "product_id" uuid NOT NULL,
+    "viewed_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_recent_views_pharmacy_id" ON "recent_views" ("pharmacy_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_recent_view_viewer_product" ON "recent_views" ("viewer","product_id");
+CREATE INDEX IF NOT EXISTS "idx_recent_views_viewed_at" ON "recent_views" ("viewed_at");
+
+-- +goose Down
+DROP TABLE IF EXISTS "recent_views" CASCADE;
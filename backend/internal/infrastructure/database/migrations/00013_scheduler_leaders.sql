@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "scheduler_leaders" (
+    "name" varchar(100),
+    "instance_id" varchar(64) NOT NULL,
+    "hostname" varchar(255) NOT NULL DEFAULT '',
+    "acquired_at" timestamptz NOT NULL,
+    "heartbeat_at" timestamptz NOT NULL,
+    PRIMARY KEY ("name")
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS "scheduler_leaders";
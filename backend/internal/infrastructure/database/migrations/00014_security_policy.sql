@@ -0,0 +1,27 @@
+-- +goose Up
+ALTER TABLE "users"
+    ADD COLUMN IF NOT EXISTS "password_changed_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "password_history" jsonb;
+ALTER TABLE "pharmacy_configs"
+    ADD COLUMN IF NOT EXISTS "security_policy" jsonb;
+CREATE TABLE IF NOT EXISTS "login_challenges" (
+    "id" uuid,
+    "user_id" uuid NOT NULL,
+    "token_hash" varchar(64) NOT NULL,
+    "code_hash" varchar(64) NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "attempts" bigint DEFAULT 0,
+    "used_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_login_challenges_token_hash" ON "login_challenges" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_login_challenges_user_id" ON "login_challenges" ("user_id");
+
+-- +goose Down
+DROP TABLE IF EXISTS "login_challenges";
+ALTER TABLE "pharmacy_configs"
+    DROP COLUMN IF EXISTS "security_policy";
+ALTER TABLE "users"
+    DROP COLUMN IF EXISTS "password_changed_at",
+    DROP COLUMN IF EXISTS "password_history";
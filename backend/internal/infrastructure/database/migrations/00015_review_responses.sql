@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "review_responses" (
+    "id" uuid,
+    "review_id" uuid NOT NULL,
+    "pharmacy_id" uuid NOT NULL,
+    "body" text NOT NULL,
+    "created_by" uuid NOT NULL,
+    "updated_by" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_review_responses_review_id" ON "review_responses" ("review_id");
+CREATE INDEX IF NOT EXISTS "idx_review_responses_pharmacy_id" ON "review_responses" ("pharmacy_id");
+
+-- +goose Down
+DROP TABLE IF EXISTS "review_responses";
@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "content_reports" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "target_type" varchar(30) NOT NULL,
+    "target_id" uuid NOT NULL,
+    "reporter_id" uuid NOT NULL,
+    "author_id" uuid,
+    "reason" varchar(30) NOT NULL,
+    "note" text,
+    "excerpt" text,
+    "status" varchar(20) NOT NULL DEFAULT 'open',
+    "action" varchar(30),
+    "resolved_by" uuid,
+    "resolved_at" timestamptz,
+    "resolution_note" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_content_reports_pharmacy_id" ON "content_reports" ("pharmacy_id");
+CREATE INDEX IF NOT EXISTS "idx_content_reports_target" ON "content_reports" ("target_type", "target_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_content_reports_reporter" ON "content_reports" ("target_type", "target_id", "reporter_id");
+CREATE INDEX IF NOT EXISTS "idx_content_reports_author_id" ON "content_reports" ("author_id");
+CREATE INDEX IF NOT EXISTS "idx_content_reports_status" ON "content_reports" ("status");
+
+-- +goose Down
+DROP TABLE IF EXISTS "content_reports";
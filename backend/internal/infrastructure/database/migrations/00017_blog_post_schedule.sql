@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE "blog_posts" ADD COLUMN IF NOT EXISTS "scheduled_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_blog_posts_scheduled_at" ON "blog_posts" ("scheduled_at");
+
+-- +goose Down
+DROP INDEX IF EXISTS "idx_blog_posts_scheduled_at";
+ALTER TABLE "blog_posts" DROP COLUMN IF EXISTS "scheduled_at";
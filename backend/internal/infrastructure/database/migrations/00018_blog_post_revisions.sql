@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "blog_post_revisions" (
+    "id" uuid,
+    "post_id" uuid NOT NULL,
+    "revision" bigint NOT NULL,
+    "action" varchar(20) NOT NULL,
+    "restored_from" bigint,
+    "title" varchar(500) NOT NULL,
+    "excerpt" text,
+    "body" text NOT NULL,
+    "editor_id" uuid,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_blog_post_revision" ON "blog_post_revisions" ("post_id", "revision");
+
+-- +goose Down
+DROP TABLE IF EXISTS "blog_post_revisions";
@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "blog_tags" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "name" varchar(100) NOT NULL,
+    "slug" varchar(120) NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_blog_tag_pharmacy_slug" ON "blog_tags" ("pharmacy_id", "slug");
+
+CREATE TABLE IF NOT EXISTS "blog_post_tags" (
+    "post_id" uuid,
+    "tag_id" uuid,
+    "created_at" timestamptz,
+    PRIMARY KEY ("post_id", "tag_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_blog_post_tags_tag_id" ON "blog_post_tags" ("tag_id");
+
+-- +goose Down
+DROP TABLE IF EXISTS "blog_post_tags";
+DROP TABLE IF EXISTS "blog_tags";
@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE "pharmacy_configs" ADD COLUMN IF NOT EXISTS "site_hostname" varchar(255);
+
+CREATE TABLE IF NOT EXISTS "storefront_sitemaps" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "xml" text NOT NULL,
+    "url_count" bigint NOT NULL DEFAULT 0,
+    "base_url" varchar(300),
+    "fingerprint" varchar(200),
+    "generated_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_storefront_sitemaps_pharmacy_id" ON "storefront_sitemaps" ("pharmacy_id");
+
+-- +goose Down
+DROP TABLE IF EXISTS "storefront_sitemaps";
+ALTER TABLE "pharmacy_configs" DROP COLUMN IF EXISTS "site_hostname";
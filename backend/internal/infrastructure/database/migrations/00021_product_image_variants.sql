@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE "product_images" ADD COLUMN IF NOT EXISTS "thumbnail_url" varchar(512);
+ALTER TABLE "product_images" ADD COLUMN IF NOT EXISTS "medium_url" varchar(512);
+ALTER TABLE "product_images" ADD COLUMN IF NOT EXISTS "large_url" varchar(512);
+
+-- +goose Down
+ALTER TABLE "product_images" DROP COLUMN IF EXISTS "large_url";
+ALTER TABLE "product_images" DROP COLUMN IF EXISTS "medium_url";
+ALTER TABLE "product_images" DROP COLUMN IF EXISTS "thumbnail_url";
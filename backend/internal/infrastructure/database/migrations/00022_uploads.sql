@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "uploads" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "uploaded_by" uuid NOT NULL,
+    "purpose" varchar(30) NOT NULL,
+    "filename" varchar(255),
+    "content_type" varchar(120),
+    "size" bigint NOT NULL,
+    "received" bigint NOT NULL DEFAULT 0,
+    "status" varchar(20) NOT NULL,
+    "path" varchar(512),
+    "url" varchar(512),
+    "scan_result" varchar(255),
+    "expires_at" timestamptz,
+    "completed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_uploads_pharmacy_id" ON "uploads" ("pharmacy_id");
+CREATE INDEX IF NOT EXISTS "idx_uploads_uploaded_by" ON "uploads" ("uploaded_by");
+CREATE INDEX IF NOT EXISTS "idx_uploads_status" ON "uploads" ("status");
+
+-- +goose Down
+DROP TABLE IF EXISTS "uploads";
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "file_references" (
+    "id" uuid,
+    "url" varchar(512) NOT NULL,
+    "path" varchar(512) NOT NULL,
+    "private" boolean NOT NULL DEFAULT false,
+    "content_type" varchar(120),
+    "size" bigint NOT NULL DEFAULT 0,
+    "released_at" timestamptz,
+    "checked_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_file_references_url" ON "file_references" ("url");
+CREATE INDEX IF NOT EXISTS "idx_file_references_released_at" ON "file_references" ("released_at");
+
+-- +goose Down
+DROP TABLE IF EXISTS "file_references";
@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE "pharmacies" ADD COLUMN IF NOT EXISTS "status" varchar(20) NOT NULL DEFAULT 'active';
+ALTER TABLE "pharmacies" ADD COLUMN IF NOT EXISTS "license_document_url" varchar(512);
+ALTER TABLE "pharmacies" ADD COLUMN IF NOT EXISTS "review_note" text;
+ALTER TABLE "pharmacies" ADD COLUMN IF NOT EXISTS "reviewed_at" timestamptz;
+ALTER TABLE "pharmacies" ADD COLUMN IF NOT EXISTS "reviewed_by" uuid;
+CREATE INDEX IF NOT EXISTS "idx_pharmacies_status" ON "pharmacies" ("status");
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "platform_admin" boolean DEFAULT false;
+
+-- +goose Down
+ALTER TABLE "users" DROP COLUMN IF EXISTS "platform_admin";
+DROP INDEX IF EXISTS "idx_pharmacies_status";
+ALTER TABLE "pharmacies" DROP COLUMN IF EXISTS "reviewed_by";
+ALTER TABLE "pharmacies" DROP COLUMN IF EXISTS "reviewed_at";
+ALTER TABLE "pharmacies" DROP COLUMN IF EXISTS "review_note";
+ALTER TABLE "pharmacies" DROP COLUMN IF EXISTS "license_document_url";
+ALTER TABLE "pharmacies" DROP COLUMN IF EXISTS "status";
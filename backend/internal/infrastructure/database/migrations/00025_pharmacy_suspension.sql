@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE "pharmacies" ADD COLUMN IF NOT EXISTS "suspended_at" timestamptz;
+ALTER TABLE "pharmacies" ADD COLUMN IF NOT EXISTS "suspension_reason" text;
+
+-- +goose Down
+ALTER TABLE "pharmacies" DROP COLUMN IF EXISTS "suspension_reason";
+ALTER TABLE "pharmacies" DROP COLUMN IF EXISTS "suspended_at";
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "subscriptions" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "plan" varchar(20) NOT NULL,
+    "max_products" bigint NOT NULL DEFAULT 0,
+    "max_users" bigint NOT NULL DEFAULT 0,
+    "storage_mb" bigint NOT NULL DEFAULT 0,
+    "expires_at" timestamptz NOT NULL,
+    "renewed_at" timestamptz,
+    "renewed_by" uuid,
+    "reference" varchar(100),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "subscriptions" ADD CONSTRAINT "fk_subscriptions_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_subscriptions_pharmacy_id" ON "subscriptions" ("pharmacy_id");
+CREATE INDEX IF NOT EXISTS "idx_subscriptions_expires_at" ON "subscriptions" ("expires_at");
+
+-- +goose Down
+DROP TABLE IF EXISTS "subscriptions";
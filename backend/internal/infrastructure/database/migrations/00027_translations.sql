@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "translations" jsonb;
+ALTER TABLE "categories" ADD COLUMN IF NOT EXISTS "translations" jsonb;
+
+-- +goose Down
+ALTER TABLE "categories" DROP COLUMN IF EXISTS "translations";
+ALTER TABLE "products" DROP COLUMN IF EXISTS "translations";
@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE "pharmacy_configs" ADD COLUMN IF NOT EXISTS "base_currency" varchar(3) DEFAULT 'NPR';
+ALTER TABLE "pharmacy_configs" ADD COLUMN IF NOT EXISTS "display_currency" varchar(3);
+CREATE TABLE IF NOT EXISTS "exchange_rates" (
+    "id" uuid,
+    "pharmacy_id" uuid NOT NULL,
+    "base" varchar(3) NOT NULL,
+    "currency" varchar(3) NOT NULL,
+    "rate" decimal(18,8) NOT NULL,
+    "updated_by" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "exchange_rates" ADD CONSTRAINT "fk_exchange_rates_pharmacy" FOREIGN KEY ("pharmacy_id") REFERENCES "pharmacies"("id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_exchange_rates_pharmacy_currency" ON "exchange_rates" ("pharmacy_id", "currency");
+
+-- +goose Down
+DROP TABLE IF EXISTS "exchange_rates";
+ALTER TABLE "pharmacy_configs" DROP COLUMN IF EXISTS "display_currency";
+ALTER TABLE "pharmacy_configs" DROP COLUMN IF EXISTS "base_currency";
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS "flash_sale_customer_usage" (
+    "flash_sale_item_id" uuid NOT NULL,
+    "customer_key" varchar(100) NOT NULL,
+    "quantity" bigint NOT NULL DEFAULT 0,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("flash_sale_item_id", "customer_key")
+);
+INSERT INTO "flash_sale_customer_usage" ("flash_sale_item_id", "customer_key", "quantity", "updated_at")
+SELECT "flash_sale_item_id", "customer_key", SUM("quantity"), NOW()
+FROM "flash_sale_redemptions"
+WHERE "customer_key" <> ''
+GROUP BY "flash_sale_item_id", "customer_key"
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS "flash_sale_customer_usage";
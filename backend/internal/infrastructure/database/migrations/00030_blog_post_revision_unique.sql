@@ -0,0 +1,7 @@
+-- +goose Up
+-- Make (post_id, revision) a table constraint, not only a unique index, keeping the index 00018 created.
+ALTER TABLE "blog_post_revisions" ADD CONSTRAINT "idx_blog_post_revision" UNIQUE USING INDEX "idx_blog_post_revision";
+
+-- +goose Down
+ALTER TABLE "blog_post_revisions" DROP CONSTRAINT IF EXISTS "idx_blog_post_revision";
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_blog_post_revision" ON "blog_post_revisions" ("post_id", "revision");
@@ -0,0 +1,11 @@
+// Package migrations holds the versioned schema migrations, compiled into every binary.
+//
+// Files are goose SQL migrations named NNNNN_description.sql, each with a "-- +goose Up" and a
+// "-- +goose Down" section. Add a new file with the next number for every schema change; never edit a
+// migration that has been released.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -1,18 +1,34 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"time"
 
-	"github.com/careplus/pharmacy-backend/internal/domain/models"
 	"github.com/careplus/pharmacy-backend/internal/infrastructure/config"
 	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
 )
 
+// NewPostgresConnection connects and makes sure the schema is current: pending migrations are applied when
+// DB_MIGRATE_ON_START is set, and otherwise the connection fails with ErrSchemaOutdated.
 func NewPostgresConnection(cfg *config.Config, log *zap.Logger) (*gorm.DB, func(), error) {
+	db, cleanup, err := OpenPostgres(cfg, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ensureSchema(context.Background(), db, cfg.Database.MigrateOnStart, log); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return db, cleanup, nil
+}
+
+// OpenPostgres connects without checking the schema version; cmd/migrate uses it.
+func OpenPostgres(cfg *config.Config, log *zap.Logger) (*gorm.DB, func(), error) {
 	dsn := cfg.GetDSN()
 	gormConfig := &gorm.Config{}
 	if cfg.IsDevelopment() {
@@ -34,58 +50,19 @@ func NewPostgresConnection(cfg *config.Config, log *zap.Logger) (*gorm.DB, func(
 	sqlDB.SetMaxOpenConns(100)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
+	if cfg.Tracing.Enabled() {
+		// One span per query under the request's span; bind values stay out of the trace (they can hold patient data).
+		if err := db.Use(gormtracing.NewPlugin(gormtracing.WithoutMetrics(), gormtracing.WithoutQueryVariables())); err != nil {
+			return nil, nil, fmt.Errorf("failed to enable query tracing: %w", err)
+		}
+	}
+
 	if err := sqlDB.Ping(); err != nil {
 		return nil, nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
 	log.Info("Connected to PostgreSQL", zap.String("database", cfg.Database.Name))
 
-	if err := db.AutoMigrate(
-		&models.Pharmacy{},
-		&models.PharmacyConfig{},
-		&models.User{},
-		&models.Product{},
-		&models.ProductImage{},
-		&models.Category{},
-		&models.ProductUnit{},
-		&models.Membership{},
-		&models.ProductReview{},
-		&models.ReviewLike{},
-		&models.ReviewComment{},
-		&models.PromoCode{},
-		&models.Customer{},
-		&models.CustomerMembership{},
-		&models.ReferralPointsConfig{},
-		&models.StaffPointsConfig{},
-		&models.PointsTransaction{},
-		&models.Order{},
-		&models.OrderItem{},
-		&models.OrderFeedback{},
-		&models.OrderReturnRequest{},
-		&models.Payment{},
-		&models.PaymentGateway{},
-		&models.Invoice{},
-		&models.InventoryBatch{},
-		&models.ActivityLog{},
-		&models.Notification{},
-		&models.Promo{},
-		&models.DutyRoster{},
-		&models.DailyLog{},
-		&models.Conversation{},
-		&models.ChatMessage{},
-		&models.UserAddress{},
-		&models.Announcement{},
-		&models.AnnouncementAck{},
-		&models.BlogCategory{},
-		&models.BlogPost{},
-		&models.BlogPostMedia{},
-		&models.BlogPostLike{},
-		&models.BlogPostComment{},
-		&models.BlogPostView{},
-	); err != nil {
-		return nil, nil, fmt.Errorf("auto migrate failed: %w", err)
-	}
-
 	cleanup := func() {
 		if c, _ := db.DB(); c != nil {
 			_ = c.Close()
@@ -0,0 +1,188 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// LeaderStatus is this instance's view of the election, with the leader recorded in scheduler_leaders.
+type LeaderStatus struct {
+	Election   string      `json:"election"`
+	InstanceID string      `json:"instance_id"`
+	Hostname   string      `json:"hostname"`
+	IsLeader   bool        `json:"is_leader"`
+	Leader     *LeaderInfo `json:"leader,omitempty"`
+}
+
+// LeaderInfo is the last instance to take leadership. A HeartbeatAt older than a few check intervals means it
+// is gone and a standby is about to take over.
+type LeaderInfo struct {
+	InstanceID  string    `json:"instance_id"`
+	Hostname    string    `json:"hostname"`
+	AcquiredAt  time.Time `json:"acquired_at"`
+	HeartbeatAt time.Time `json:"heartbeat_at"`
+}
+
+// PostgresElector elects a leader with a session-level Postgres advisory lock held on a dedicated connection.
+// Postgres releases the lock when that session ends, so when the leader dies (or loses its connection) a standby
+// gets the lock on its next check. The leader writes a heartbeat over that connection on every check and steps
+// down when it fails, so two instances act as leader for at most one check interval.
+type PostgresElector struct {
+	db         *sql.DB
+	name       string
+	key        int64
+	interval   time.Duration
+	instanceID string
+	hostname   string
+	logger     *zap.Logger
+
+	mu     sync.Mutex
+	conn   *sql.Conn
+	leader bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPostgresElector competes for the election called name (one per job set), checking every interval.
+func NewPostgresElector(db *sql.DB, name string, interval time.Duration, logger *zap.Logger) *PostgresElector {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("careplus:scheduler:" + name))
+	hostname, _ := os.Hostname()
+	ctx, cancel := context.WithCancel(context.Background())
+	return &PostgresElector{
+		db: db, name: name, key: int64(h.Sum64()), interval: interval,
+		instanceID: uuid.NewString(), hostname: hostname, logger: logger,
+		ctx: ctx, cancel: cancel, done: make(chan struct{}),
+	}
+}
+
+func (e *PostgresElector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader
+}
+
+// Start checks right away, so a lone instance is leader before its jobs first run, then every interval.
+func (e *PostgresElector) Start() {
+	go func() {
+		defer close(e.done)
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		for {
+			e.check()
+			select {
+			case <-e.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (e *PostgresElector) check() {
+	ctx, cancel := context.WithTimeout(e.ctx, e.interval)
+	defer cancel()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.leader {
+		if _, err := e.conn.ExecContext(ctx, `UPDATE scheduler_leaders SET heartbeat_at = NOW() WHERE name = $1 AND instance_id = $2`, e.name, e.instanceID); err != nil {
+			if e.ctx.Err() != nil {
+				return // stopping; Stop releases the lock
+			}
+			e.logger.Warn("scheduler leadership lost", zap.String("election", e.name), zap.Error(err))
+			e.resign()
+		}
+		return
+	}
+	if err := e.campaign(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		e.logger.Warn("scheduler leader election failed", zap.String("election", e.name), zap.Error(err))
+	}
+}
+
+// campaign tries to take the lock; the connection is kept only when it did. Callers hold e.mu.
+func (e *PostgresElector) campaign(ctx context.Context) error {
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	var got bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, e.key).Scan(&got); err != nil || !got {
+		_ = conn.Close() // back to the pool: this session holds no lock
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, `
+		INSERT INTO scheduler_leaders (name, instance_id, hostname, acquired_at, heartbeat_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		ON CONFLICT (name) DO UPDATE SET instance_id = EXCLUDED.instance_id, hostname = EXCLUDED.hostname,
+			acquired_at = EXCLUDED.acquired_at, heartbeat_at = EXCLUDED.heartbeat_at`,
+		e.name, e.instanceID, e.hostname); err != nil {
+		// Ending the session releases the lock for another instance.
+		discard(conn)
+		return fmt.Errorf("record leader: %w", err)
+	}
+	e.conn, e.leader = conn, true
+	e.logger.Info("scheduler leadership acquired", zap.String("election", e.name), zap.String("instance_id", e.instanceID), zap.String("hostname", e.hostname))
+	return nil
+}
+
+// resign drops leadership and the session holding the lock. Callers hold e.mu.
+func (e *PostgresElector) resign() {
+	if e.conn != nil {
+		discard(e.conn)
+	}
+	e.conn, e.leader = nil, false
+}
+
+// discard closes conn's session rather than returning it to the pool, where it would keep holding the lock.
+func discard(conn *sql.Conn) {
+	_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+	_ = conn.Close()
+}
+
+// Stop ends the election. A leader unlocks first so a standby takes over on its next check.
+func (e *PostgresElector) Stop(ctx context.Context) error {
+	e.cancel()
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.leader {
+		return nil
+	}
+	if _, err := e.conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, e.key); err != nil {
+		e.logger.Warn("scheduler leadership not released", zap.String("election", e.name), zap.Error(err))
+	}
+	e.resign()
+	e.logger.Info("scheduler leadership released", zap.String("election", e.name))
+	return nil
+}
+
+func (e *PostgresElector) Status(ctx context.Context) (*LeaderStatus, error) {
+	out := &LeaderStatus{Election: e.name, InstanceID: e.instanceID, Hostname: e.hostname, IsLeader: e.IsLeader()}
+	var l LeaderInfo
+	err := e.db.QueryRowContext(ctx, `SELECT instance_id, hostname, acquired_at, heartbeat_at FROM scheduler_leaders WHERE name = $1`, e.name).
+		Scan(&l.InstanceID, &l.Hostname, &l.AcquiredAt, &l.HeartbeatAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return nil, err
+	default:
+		out.Leader = &l
+	}
+	return out, nil
+}
@@ -0,0 +1,283 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// lockServer is a stand-in for Postgres with just what PostgresElector uses: session-level advisory locks,
+// released when the session ends, and the scheduler_leaders table.
+type lockServer struct {
+	mu         sync.Mutex
+	locks      map[int64]*lockSession
+	leader     []driver.Value // instance_id, hostname, acquired_at, heartbeat_at
+	heartbeats int
+	failInsert bool
+}
+
+func newLockServer() *lockServer {
+	return &lockServer{locks: map[int64]*lockSession{}}
+}
+
+// open returns a database whose connections are sessions on the server, as two API instances would have.
+func (s *lockServer) open() *sql.DB {
+	return sql.OpenDB(lockConnector{s})
+}
+
+// dropLockHolder ends the session holding key, as when the leader's connection is cut.
+func (s *lockServer) dropLockHolder(key int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c := s.locks[key]; c != nil {
+		c.dead = true
+		s.releaseLocked(c)
+	}
+}
+
+func (s *lockServer) releaseLocked(c *lockSession) {
+	for k, holder := range s.locks {
+		if holder == c {
+			delete(s.locks, k)
+		}
+	}
+}
+
+type lockConnector struct{ s *lockServer }
+
+func (c lockConnector) Connect(context.Context) (driver.Conn, error) {
+	return &lockSession{s: c.s}, nil
+}
+
+func (c lockConnector) Driver() driver.Driver { return nil }
+
+type lockSession struct {
+	s    *lockServer
+	dead bool
+}
+
+func (c *lockSession) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (c *lockSession) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions not supported")
+}
+
+func (c *lockSession) Close() error {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	c.s.releaseLocked(c)
+	return nil
+}
+
+func (c *lockSession) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	if c.dead {
+		return nil, driver.ErrBadConn
+	}
+	now := time.Now()
+	switch {
+	case strings.Contains(query, "INSERT INTO scheduler_leaders"):
+		if c.s.failInsert {
+			return nil, errors.New("relation \"scheduler_leaders\" does not exist")
+		}
+		c.s.leader = []driver.Value{args[1].Value, args[2].Value, now, now}
+	case strings.Contains(query, "UPDATE scheduler_leaders SET heartbeat_at"):
+		if c.s.leader != nil && c.s.leader[0] == args[1].Value {
+			c.s.leader[3] = now
+			c.s.heartbeats++
+		}
+	default:
+		return nil, errors.New("unexpected exec: " + query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *lockSession) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	if c.dead {
+		return nil, driver.ErrBadConn
+	}
+	switch {
+	case strings.Contains(query, "pg_try_advisory_lock"):
+		key := args[0].Value.(int64)
+		holder := c.s.locks[key]
+		if holder == nil {
+			c.s.locks[key] = c
+		}
+		return &lockRows{cols: []string{"pg_try_advisory_lock"}, rows: [][]driver.Value{{holder == nil || holder == c}}}, nil
+	case strings.Contains(query, "pg_advisory_unlock"):
+		key := args[0].Value.(int64)
+		held := c.s.locks[key] == c
+		if held {
+			delete(c.s.locks, key)
+		}
+		return &lockRows{cols: []string{"pg_advisory_unlock"}, rows: [][]driver.Value{{held}}}, nil
+	case strings.Contains(query, "FROM scheduler_leaders"):
+		rows := &lockRows{cols: []string{"instance_id", "hostname", "acquired_at", "heartbeat_at"}}
+		if c.s.leader != nil {
+			rows.rows = [][]driver.Value{append([]driver.Value(nil), c.s.leader...)}
+		}
+		return rows, nil
+	}
+	return nil, errors.New("unexpected query: " + query)
+}
+
+type lockRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *lockRows) Columns() []string { return r.cols }
+func (r *lockRows) Close() error      { return nil }
+
+func (r *lockRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// newTestElectors returns two instances competing in one election on s.
+func newTestElectors(s *lockServer) (a, b *PostgresElector) {
+	a = NewPostgresElector(s.open(), "jobs", time.Hour, zap.NewNop())
+	b = NewPostgresElector(s.open(), "jobs", time.Hour, zap.NewNop())
+	return a, b
+}
+
+func TestPostgresElector_AcquireRenewAndLoss(t *testing.T) {
+	s := newLockServer()
+	a, b := newTestElectors(s)
+	ctx := context.Background()
+	step := func(name string, e *PostgresElector, wantA, wantB bool) {
+		t.Helper()
+		e.check()
+		if a.IsLeader() != wantA || b.IsLeader() != wantB {
+			t.Fatalf("%s: leaders a=%v b=%v, want a=%v b=%v", name, a.IsLeader(), b.IsLeader(), wantA, wantB)
+		}
+	}
+
+	step("a acquires", a, true, false)
+	step("b stands by while a holds the lock", b, true, false)
+	st, err := b.Status(ctx)
+	if err != nil || st.IsLeader || st.Leader == nil || st.Leader.InstanceID != a.instanceID {
+		t.Fatalf("status from b = %+v, %v; want a recorded as leader", st, err)
+	}
+
+	step("a renews", a, true, false)
+	step("a renews again", a, true, false)
+	if s.heartbeats != 2 {
+		t.Errorf("heartbeats = %d, want 2", s.heartbeats)
+	}
+	step("b still stands by", b, true, false)
+
+	// a's session is cut: Postgres drops its lock, and a must stop acting as leader before b takes over.
+	s.dropLockHolder(a.key)
+	step("a notices the loss", a, false, false)
+	step("b takes over", b, false, true)
+	step("a stands by", a, false, true)
+	if st, _ := a.Status(ctx); st.Leader == nil || st.Leader.InstanceID != b.instanceID {
+		t.Errorf("recorded leader = %+v, want b", st.Leader)
+	}
+}
+
+func TestPostgresElector_StopHandsOver(t *testing.T) {
+	s := newLockServer()
+	a, b := newTestElectors(s)
+	a.Start()
+	deadline := time.Now().Add(5 * time.Second)
+	for !a.IsLeader() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !a.IsLeader() {
+		t.Fatal("a did not become leader on start")
+	}
+	b.check()
+	if b.IsLeader() {
+		t.Fatal("b became leader while a holds the lock")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := a.Stop(ctx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if a.IsLeader() {
+		t.Error("a is still leader after Stop")
+	}
+	b.check()
+	if !b.IsLeader() {
+		t.Error("b did not take over after a stopped")
+	}
+}
+
+func TestPostgresElector_FailedRecordReleasesLock(t *testing.T) {
+	s := newLockServer()
+	a, b := newTestElectors(s)
+	s.failInsert = true
+	a.check()
+	if a.IsLeader() {
+		t.Fatal("a is leader without a recorded leadership")
+	}
+	// The session that took the lock must end, not go back to a's pool still holding it.
+	s.failInsert = false
+	b.check()
+	if !b.IsLeader() {
+		t.Error("b could not take the lock a failed to record")
+	}
+}
+
+// fakeElector is leader while leader is true.
+type fakeElector struct{ leader bool }
+
+func (f *fakeElector) Start()                     {}
+func (f *fakeElector) Stop(context.Context) error { return nil }
+func (f *fakeElector) IsLeader() bool             { return f.leader }
+func (f *fakeElector) Status(context.Context) (*LeaderStatus, error) {
+	return &LeaderStatus{IsLeader: f.leader}, nil
+}
+
+func TestScheduler_LeaderOnlyJobsRunOnOneInstance(t *testing.T) {
+	runs := map[string]int{}
+	var mu sync.Mutex
+	job := func(name string) Job {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			runs[name]++
+			mu.Unlock()
+			return nil
+		}
+	}
+	var schedulers []*Scheduler
+	for _, leader := range []bool{true, false} {
+		sch := New(zap.NewNop())
+		sch.SetElector(&fakeElector{leader: leader})
+		sch.Every("expire-reservations", time.Minute, job("expire-reservations"))
+		sch.EveryInstance("flush-usage", time.Minute, job("flush-usage"))
+		schedulers = append(schedulers, sch)
+	}
+	for _, sch := range schedulers {
+		for _, e := range sch.entries {
+			sch.runOnce(e)
+		}
+	}
+	if runs["expire-reservations"] != 1 {
+		t.Errorf("leader-only job ran %d times across two instances, want 1", runs["expire-reservations"])
+	}
+	if runs["flush-usage"] != 2 {
+		t.Errorf("every-instance job ran %d times, want 2", runs["flush-usage"])
+	}
+}
@@ -0,0 +1,180 @@
+// Package scheduler runs named background jobs at fixed intervals inside the API process.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// startDelay is how long after Start a job first runs, so a restart loop does not hammer the database.
+const startDelay = 30 * time.Second
+
+// Job is one run of a scheduled task. Its context is cancelled on Stop or when the run exceeds the interval.
+type Job func(ctx context.Context) error
+
+type entry struct {
+	name          string
+	interval      time.Duration
+	run           Job
+	everyInstance bool
+
+	mu      sync.Mutex
+	lastRun *time.Time
+	lastErr string
+}
+
+// Elector decides whether this instance is the leader. With an elector set, jobs registered with Every run only
+// on the leader; the others stand by and take over when it goes away.
+type Elector interface {
+	Start()
+	Stop(ctx context.Context) error
+	IsLeader() bool
+	Status(ctx context.Context) (*LeaderStatus, error)
+}
+
+// Scheduler runs each registered job in its own goroutine; a job never overlaps with itself.
+type Scheduler struct {
+	entries []*entry
+	elector Elector
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	once    sync.Once
+	logger  *zap.Logger
+}
+
+func New(logger *zap.Logger) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{ctx: ctx, cancel: cancel, logger: logger}
+}
+
+// Every registers fn to run every interval once the scheduler is started, on the leader only when an elector
+// is set. Register jobs before Start.
+func (s *Scheduler) Every(name string, interval time.Duration, fn Job) {
+	s.entries = append(s.entries, &entry{name: name, interval: interval, run: fn})
+}
+
+// EveryInstance registers fn to run on every instance, leader or not, e.g. to flush per-process buffers.
+func (s *Scheduler) EveryInstance(name string, interval time.Duration, fn Job) {
+	s.entries = append(s.entries, &entry{name: name, interval: interval, run: fn, everyInstance: true})
+}
+
+// SetElector makes the jobs registered with Every leader-only. Call it before Start.
+func (s *Scheduler) SetElector(e Elector) {
+	s.elector = e
+}
+
+func (s *Scheduler) Start() {
+	if s.elector != nil {
+		s.elector.Start()
+	}
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go s.loop(e)
+	}
+	s.logger.Info("scheduler started", zap.Int("jobs", len(s.entries)))
+}
+
+func (s *Scheduler) loop(e *entry) {
+	defer s.wg.Done()
+	delay := startDelay
+	if e.interval < delay {
+		delay = e.interval
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-timer.C:
+		}
+		s.runOnce(e)
+		timer.Reset(e.interval)
+	}
+}
+
+// runOnce runs a job with a deadline and turns a panic into a logged error so one bad run does not stop the job.
+// A leader-only job is skipped while this instance stands by.
+func (s *Scheduler) runOnce(e *entry) {
+	if !e.everyInstance && s.elector != nil && !s.elector.IsLeader() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, e.interval)
+	defer cancel()
+	start := time.Now()
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return e.run(ctx)
+	}()
+	e.mu.Lock()
+	e.lastRun, e.lastErr = &start, ""
+	if err != nil {
+		e.lastErr = err.Error()
+	}
+	e.mu.Unlock()
+	if err != nil {
+		s.logger.Error("scheduled job failed", zap.String("job", e.name), zap.Duration("took", time.Since(start)), zap.Error(err))
+		return
+	}
+	s.logger.Debug("scheduled job done", zap.String("job", e.name), zap.Duration("took", time.Since(start)))
+}
+
+// Stop cancels running jobs and waits for them to return, or for ctx to end. The elector is stopped last, so
+// leadership (and the jobs) move to a standby instance only once this one has stopped running them.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.once.Do(s.cancel)
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if s.elector != nil {
+		return s.elector.Stop(ctx)
+	}
+	return nil
+}
+
+// Status describes the scheduler on this instance: leadership (nil without an elector) and each job's last run here.
+type Status struct {
+	Leader *LeaderStatus `json:"leader,omitempty"`
+	Jobs   []JobStatus   `json:"jobs"`
+}
+
+type JobStatus struct {
+	Name          string     `json:"name"`
+	Interval      string     `json:"interval"`
+	EveryInstance bool       `json:"every_instance"` // runs on every instance, not only the leader
+	LastRunAt     *time.Time `json:"last_run_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+func (s *Scheduler) Status(ctx context.Context) (*Status, error) {
+	out := &Status{Jobs: make([]JobStatus, 0, len(s.entries))}
+	if s.elector != nil {
+		ls, err := s.elector.Status(ctx)
+		if err != nil {
+			return nil, err
+		}
+		out.Leader = ls
+	}
+	for _, e := range s.entries {
+		e.mu.Lock()
+		out.Jobs = append(out.Jobs, JobStatus{Name: e.name, Interval: e.interval.String(), EveryInstance: e.everyInstance, LastRunAt: e.lastRun, LastError: e.lastErr})
+		e.mu.Unlock()
+	}
+	return out, nil
+}
@@ -1,16 +1,21 @@
 package mocks
 
 import (
+	"time"
+
 	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 	"github.com/google/uuid"
 )
 
 // MockAuthProvider is a mock for AuthProvider for unit tests (no DB / no real JWT).
 type MockAuthProvider struct {
-	GenerateAccessTokenFunc  func(userID, pharmacyID uuid.UUID, role string) (string, error)
-	GenerateRefreshTokenFunc func(userID uuid.UUID) (string, error)
-	ValidateAccessTokenFunc  func(tokenString string) (*outbound.TokenClaims, error)
-	ValidateRefreshTokenFunc func(tokenString string) (uuid.UUID, error)
+	GenerateAccessTokenFunc        func(userID, pharmacyID uuid.UUID, role string) (string, error)
+	GenerateImpersonationTokenFunc func(userID, pharmacyID uuid.UUID, role string, impersonatorID uuid.UUID, ttl time.Duration) (string, error)
+	GenerateRefreshTokenFunc       func(userID uuid.UUID) (string, error)
+	ValidateAccessTokenFunc        func(tokenString string) (*outbound.TokenClaims, error)
+	ValidateRefreshTokenFunc       func(tokenString string) (uuid.UUID, time.Time, error)
+	GenerateChatCustomerTokenFunc  func(pharmacyID, customerID uuid.UUID) (string, error)
+	ValidateChatCustomerTokenFunc  func(tokenString string) (*outbound.ChatCustomerClaims, error)
 }
 
 func (m *MockAuthProvider) GenerateAccessToken(userID, pharmacyID uuid.UUID, role string) (string, error) {
@@ -20,6 +25,13 @@ func (m *MockAuthProvider) GenerateAccessToken(userID, pharmacyID uuid.UUID, rol
 	return "mock-access-token", nil
 }
 
+func (m *MockAuthProvider) GenerateImpersonationToken(userID, pharmacyID uuid.UUID, role string, impersonatorID uuid.UUID, ttl time.Duration) (string, error) {
+	if m.GenerateImpersonationTokenFunc != nil {
+		return m.GenerateImpersonationTokenFunc(userID, pharmacyID, role, impersonatorID, ttl)
+	}
+	return "mock-impersonation-token", nil
+}
+
 func (m *MockAuthProvider) GenerateRefreshToken(userID uuid.UUID) (string, error) {
 	if m.GenerateRefreshTokenFunc != nil {
 		return m.GenerateRefreshTokenFunc(userID)
@@ -34,9 +46,23 @@ func (m *MockAuthProvider) ValidateAccessToken(tokenString string) (*outbound.To
 	return nil, nil
 }
 
-func (m *MockAuthProvider) ValidateRefreshToken(tokenString string) (uuid.UUID, error) {
+func (m *MockAuthProvider) ValidateRefreshToken(tokenString string) (uuid.UUID, time.Time, error) {
 	if m.ValidateRefreshTokenFunc != nil {
 		return m.ValidateRefreshTokenFunc(tokenString)
 	}
-	return uuid.Nil, nil
+	return uuid.Nil, time.Time{}, nil
+}
+
+func (m *MockAuthProvider) GenerateChatCustomerToken(pharmacyID, customerID uuid.UUID) (string, error) {
+	if m.GenerateChatCustomerTokenFunc != nil {
+		return m.GenerateChatCustomerTokenFunc(pharmacyID, customerID)
+	}
+	return "mock-chat-token", nil
+}
+
+func (m *MockAuthProvider) ValidateChatCustomerToken(tokenString string) (*outbound.ChatCustomerClaims, error) {
+	if m.ValidateChatCustomerTokenFunc != nil {
+		return m.ValidateChatCustomerTokenFunc(tokenString)
+	}
+	return nil, nil
 }
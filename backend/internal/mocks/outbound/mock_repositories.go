@@ -2,18 +2,22 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // MockUserRepository is a mock for UserRepository for unit tests (no DB).
 type MockUserRepository struct {
-	CreateFunc          func(ctx context.Context, u *models.User) error
-	GetByIDFunc         func(ctx context.Context, id uuid.UUID) (*models.User, error)
-	GetByEmailFunc      func(ctx context.Context, email string) (*models.User, error)
-	GetByPharmacyIDFunc func(ctx context.Context, pharmacyID uuid.UUID) ([]*models.User, error)
-	UpdateFunc          func(ctx context.Context, u *models.User) error
+	CreateFunc                 func(ctx context.Context, u *models.User) error
+	GetByIDFunc                func(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetByEmailFunc             func(ctx context.Context, email string) (*models.User, error)
+	GetByPharmacyIDFunc        func(ctx context.Context, pharmacyID uuid.UUID) ([]*models.User, error)
+	UpdateFunc                 func(ctx context.Context, u *models.User) error
+	SetLicenseReminderDaysFunc func(ctx context.Context, id uuid.UUID, days int) error
 }
 
 func (m *MockUserRepository) Create(ctx context.Context, u *models.User) error {
@@ -51,12 +55,23 @@ func (m *MockUserRepository) Update(ctx context.Context, u *models.User) error {
 	return nil
 }
 
+func (m *MockUserRepository) SetLicenseReminderDays(ctx context.Context, id uuid.UUID, days int) error {
+	if m.SetLicenseReminderDaysFunc != nil {
+		return m.SetLicenseReminderDaysFunc(ctx, id, days)
+	}
+	return nil
+}
+
 // MockPharmacyRepository is a mock for PharmacyRepository for unit tests (no DB).
 type MockPharmacyRepository struct {
-	CreateFunc  func(ctx context.Context, p *models.Pharmacy) error
-	GetByIDFunc func(ctx context.Context, id uuid.UUID) (*models.Pharmacy, error)
-	UpdateFunc  func(ctx context.Context, p *models.Pharmacy) error
-	ListFunc    func(ctx context.Context) ([]*models.Pharmacy, error)
+	CreateFunc            func(ctx context.Context, p *models.Pharmacy) error
+	GetByIDFunc           func(ctx context.Context, id uuid.UUID) (*models.Pharmacy, error)
+	GetByHostnameSlugFunc func(ctx context.Context, hostnameSlug string) (*models.Pharmacy, error)
+	UpdateFunc            func(ctx context.Context, p *models.Pharmacy) error
+	ListFunc              func(ctx context.Context) ([]*models.Pharmacy, error)
+	ListByStatusFunc      func(ctx context.Context, status string) ([]*models.Pharmacy, error)
+	TakenFunc             func(ctx context.Context, licenseNo, hostnameSlug string) (bool, error)
+	ListUsageFunc         func(ctx context.Context) ([]*models.TenantUsage, error)
 }
 
 func (m *MockPharmacyRepository) Create(ctx context.Context, p *models.Pharmacy) error {
@@ -73,6 +88,13 @@ func (m *MockPharmacyRepository) GetByID(ctx context.Context, id uuid.UUID) (*mo
 	return nil, nil
 }
 
+func (m *MockPharmacyRepository) GetByHostnameSlug(ctx context.Context, hostnameSlug string) (*models.Pharmacy, error) {
+	if m.GetByHostnameSlugFunc != nil {
+		return m.GetByHostnameSlugFunc(ctx, hostnameSlug)
+	}
+	return nil, nil
+}
+
 func (m *MockPharmacyRepository) Update(ctx context.Context, p *models.Pharmacy) error {
 	if m.UpdateFunc != nil {
 		return m.UpdateFunc(ctx, p)
@@ -87,15 +109,48 @@ func (m *MockPharmacyRepository) List(ctx context.Context) ([]*models.Pharmacy,
 	return nil, nil
 }
 
+func (m *MockPharmacyRepository) ListByStatus(ctx context.Context, status string) ([]*models.Pharmacy, error) {
+	if m.ListByStatusFunc != nil {
+		return m.ListByStatusFunc(ctx, status)
+	}
+	return nil, nil
+}
+
+func (m *MockPharmacyRepository) Taken(ctx context.Context, licenseNo, hostnameSlug string) (bool, error) {
+	if m.TakenFunc != nil {
+		return m.TakenFunc(ctx, licenseNo, hostnameSlug)
+	}
+	return false, nil
+}
+
+func (m *MockPharmacyRepository) ListUsage(ctx context.Context) ([]*models.TenantUsage, error) {
+	if m.ListUsageFunc != nil {
+		return m.ListUsageFunc(ctx)
+	}
+	return nil, nil
+}
+
 // MockProductRepository is a mock for ProductRepository for unit tests (no DB).
 type MockProductRepository struct {
-	CreateFunc                    func(ctx context.Context, p *models.Product) error
-	GetByIDFunc                   func(ctx context.Context, id uuid.UUID) (*models.Product, error)
-	GetBySKUFunc                  func(ctx context.Context, pharmacyID uuid.UUID, sku string) (*models.Product, error)
-	ListByPharmacyFunc            func(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool) ([]*models.Product, error)
-	ListByPharmacyPaginatedFunc   func(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, limit, offset int) ([]*models.Product, int64, error)
-	UpdateFunc                    func(ctx context.Context, p *models.Product) error
-	DeleteFunc                    func(ctx context.Context, id uuid.UUID) error
+	CreateFunc                  func(ctx context.Context, p *models.Product) error
+	GetByIDFunc                 func(ctx context.Context, id uuid.UUID) (*models.Product, error)
+	GetBySKUFunc                func(ctx context.Context, pharmacyID uuid.UUID, sku string) (*models.Product, error)
+	GetByBarcodeFunc            func(ctx context.Context, pharmacyID uuid.UUID, barcode string) (*models.Product, error)
+	ListByPharmacyFunc          func(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool) ([]*models.Product, error)
+	ListByPharmacyPaginatedFunc func(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, limit, offset int) ([]*models.Product, int64, error)
+	ListByPharmacyCatalogFunc   func(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, searchQ string, sort outbound.CatalogSort, limit, offset int, filters *outbound.CatalogFilters) ([]*models.Product, int64, error)
+	CatalogFacetsFunc           func(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, searchQ string, filters *outbound.CatalogFilters, priceEdges []float64, facetLimit int) (*models.CatalogFacets, error)
+	ListChangedSinceFunc        func(ctx context.Context, pharmacyID uuid.UUID, since time.Time, afterID uuid.UUID, until time.Time, limit int) ([]*models.ProductDelta, error)
+	ListLowStockFunc            func(ctx context.Context, pharmacyID uuid.UUID, threshold int) ([]*models.Product, error)
+	ListBelowReorderLevelFunc   func(ctx context.Context, pharmacyID uuid.UUID, defaultLevel int) ([]*models.Product, error)
+	UnitsSoldFunc               func(ctx context.Context, pharmacyID uuid.UUID, since time.Time) (map[uuid.UUID]int, error)
+	SyncCategoryNameFunc        func(ctx context.Context, categoryID uuid.UUID, name string) (int64, error)
+	RenameBrandFunc             func(ctx context.Context, pharmacyID uuid.UUID, from, to string) (int64, error)
+	ListAlternativesFunc        func(ctx context.Context, p *models.Product, limit int) ([]*models.Product, error)
+	WithAlternativesFunc        func(ctx context.Context, productIDs []uuid.UUID) ([]uuid.UUID, error)
+	UpdateFunc                  func(ctx context.Context, p *models.Product) error
+	AdjustStockFunc             func(ctx context.Context, id uuid.UUID, delta int) (bool, error)
+	DeleteFunc                  func(ctx context.Context, id uuid.UUID) error
 }
 
 func (m *MockProductRepository) Create(ctx context.Context, p *models.Product) error {
@@ -119,6 +174,13 @@ func (m *MockProductRepository) GetBySKU(ctx context.Context, pharmacyID uuid.UU
 	return nil, nil
 }
 
+func (m *MockProductRepository) GetByBarcode(ctx context.Context, pharmacyID uuid.UUID, barcode string) (*models.Product, error) {
+	if m.GetByBarcodeFunc != nil {
+		return m.GetByBarcodeFunc(ctx, pharmacyID, barcode)
+	}
+	return nil, nil
+}
+
 func (m *MockProductRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool) ([]*models.Product, error) {
 	if m.ListByPharmacyFunc != nil {
 		return m.ListByPharmacyFunc(ctx, pharmacyID, category, inStockOnly)
@@ -133,6 +195,76 @@ func (m *MockProductRepository) ListByPharmacyPaginated(ctx context.Context, pha
 	return nil, 0, nil
 }
 
+func (m *MockProductRepository) ListByPharmacyCatalog(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, searchQ string, sort outbound.CatalogSort, limit, offset int, filters *outbound.CatalogFilters) ([]*models.Product, int64, error) {
+	if m.ListByPharmacyCatalogFunc != nil {
+		return m.ListByPharmacyCatalogFunc(ctx, pharmacyID, category, inStockOnly, searchQ, sort, limit, offset, filters)
+	}
+	return nil, 0, nil
+}
+
+func (m *MockProductRepository) CatalogFacets(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, searchQ string, filters *outbound.CatalogFilters, priceEdges []float64, facetLimit int) (*models.CatalogFacets, error) {
+	if m.CatalogFacetsFunc != nil {
+		return m.CatalogFacetsFunc(ctx, pharmacyID, category, inStockOnly, searchQ, filters, priceEdges, facetLimit)
+	}
+	return nil, nil
+}
+
+func (m *MockProductRepository) ListChangedSince(ctx context.Context, pharmacyID uuid.UUID, since time.Time, afterID uuid.UUID, until time.Time, limit int) ([]*models.ProductDelta, error) {
+	if m.ListChangedSinceFunc != nil {
+		return m.ListChangedSinceFunc(ctx, pharmacyID, since, afterID, until, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockProductRepository) ListLowStock(ctx context.Context, pharmacyID uuid.UUID, threshold int) ([]*models.Product, error) {
+	if m.ListLowStockFunc != nil {
+		return m.ListLowStockFunc(ctx, pharmacyID, threshold)
+	}
+	return nil, nil
+}
+
+func (m *MockProductRepository) ListBelowReorderLevel(ctx context.Context, pharmacyID uuid.UUID, defaultLevel int) ([]*models.Product, error) {
+	if m.ListBelowReorderLevelFunc != nil {
+		return m.ListBelowReorderLevelFunc(ctx, pharmacyID, defaultLevel)
+	}
+	return nil, nil
+}
+
+func (m *MockProductRepository) UnitsSold(ctx context.Context, pharmacyID uuid.UUID, since time.Time) (map[uuid.UUID]int, error) {
+	if m.UnitsSoldFunc != nil {
+		return m.UnitsSoldFunc(ctx, pharmacyID, since)
+	}
+	return nil, nil
+}
+
+func (m *MockProductRepository) SyncCategoryName(ctx context.Context, categoryID uuid.UUID, name string) (int64, error) {
+	if m.SyncCategoryNameFunc != nil {
+		return m.SyncCategoryNameFunc(ctx, categoryID, name)
+	}
+	return 0, nil
+}
+
+func (m *MockProductRepository) RenameBrand(ctx context.Context, pharmacyID uuid.UUID, from, to string) (int64, error) {
+	if m.RenameBrandFunc != nil {
+		return m.RenameBrandFunc(ctx, pharmacyID, from, to)
+	}
+	return 0, nil
+}
+
+func (m *MockProductRepository) ListAlternatives(ctx context.Context, p *models.Product, limit int) ([]*models.Product, error) {
+	if m.ListAlternativesFunc != nil {
+		return m.ListAlternativesFunc(ctx, p, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockProductRepository) WithAlternatives(ctx context.Context, productIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if m.WithAlternativesFunc != nil {
+		return m.WithAlternativesFunc(ctx, productIDs)
+	}
+	return nil, nil
+}
+
 func (m *MockProductRepository) Update(ctx context.Context, p *models.Product) error {
 	if m.UpdateFunc != nil {
 		return m.UpdateFunc(ctx, p)
@@ -140,6 +272,13 @@ func (m *MockProductRepository) Update(ctx context.Context, p *models.Product) e
 	return nil
 }
 
+func (m *MockProductRepository) AdjustStock(ctx context.Context, id uuid.UUID, delta int) (bool, error) {
+	if m.AdjustStockFunc != nil {
+		return m.AdjustStockFunc(ctx, id, delta)
+	}
+	return true, nil
+}
+
 func (m *MockProductRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	if m.DeleteFunc != nil {
 		return m.DeleteFunc(ctx, id)
@@ -149,11 +288,11 @@ func (m *MockProductRepository) Delete(ctx context.Context, id uuid.UUID) error
 
 // MockProductImageRepository is a mock for ProductImageRepository for unit tests (no DB).
 type MockProductImageRepository struct {
-	CreateFunc         func(ctx context.Context, img *models.ProductImage) error
-	GetByIDFunc        func(ctx context.Context, id uuid.UUID) (*models.ProductImage, error)
+	CreateFunc          func(ctx context.Context, img *models.ProductImage) error
+	GetByIDFunc         func(ctx context.Context, id uuid.UUID) (*models.ProductImage, error)
 	ListByProductIDFunc func(ctx context.Context, productID uuid.UUID) ([]*models.ProductImage, error)
-	UpdateFunc         func(ctx context.Context, img *models.ProductImage) error
-	DeleteFunc         func(ctx context.Context, id uuid.UUID) error
+	UpdateFunc          func(ctx context.Context, img *models.ProductImage) error
+	DeleteFunc          func(ctx context.Context, id uuid.UUID) error
 }
 
 func (m *MockProductImageRepository) Create(ctx context.Context, img *models.ProductImage) error {
@@ -190,3 +329,3214 @@ func (m *MockProductImageRepository) Delete(ctx context.Context, id uuid.UUID) e
 	}
 	return nil
 }
+
+// MockReportRepository is a mock for ReportRepository for unit tests (no DB).
+type MockReportRepository struct {
+	SalesByPeriodFunc          func(ctx context.Context, pharmacyID uuid.UUID, granularity string, from, to time.Time, branchID *uuid.UUID) ([]*models.SalesPeriodRow, error)
+	TopSellingProductsFunc     func(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time, branchID *uuid.UUID, limit int) ([]*models.TopProductRow, error)
+	RevenueByPaymentMethodFunc func(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.PaymentMethodRow, error)
+	LowStockFunc               func(ctx context.Context, pharmacyID uuid.UUID, threshold int) ([]*models.LowStockRow, error)
+	ExpiringStockFunc          func(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.ExpiringStockRow, error)
+	TaxByRateFunc              func(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.TaxLine, error)
+	SalesRegisterFunc          func(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.SalesRegisterRow, error)
+	DeadStockFunc              func(ctx context.Context, pharmacyID uuid.UUID, since time.Time, minValue float64, limit int) ([]*models.DeadStockRow, error)
+}
+
+func (m *MockReportRepository) SalesByPeriod(ctx context.Context, pharmacyID uuid.UUID, granularity string, from, to time.Time, branchID *uuid.UUID) ([]*models.SalesPeriodRow, error) {
+	if m.SalesByPeriodFunc != nil {
+		return m.SalesByPeriodFunc(ctx, pharmacyID, granularity, from, to, branchID)
+	}
+	return nil, nil
+}
+
+func (m *MockReportRepository) TopSellingProducts(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time, branchID *uuid.UUID, limit int) ([]*models.TopProductRow, error) {
+	if m.TopSellingProductsFunc != nil {
+		return m.TopSellingProductsFunc(ctx, pharmacyID, from, to, branchID, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockReportRepository) RevenueByPaymentMethod(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.PaymentMethodRow, error) {
+	if m.RevenueByPaymentMethodFunc != nil {
+		return m.RevenueByPaymentMethodFunc(ctx, pharmacyID, from, to)
+	}
+	return nil, nil
+}
+
+func (m *MockReportRepository) LowStock(ctx context.Context, pharmacyID uuid.UUID, threshold int) ([]*models.LowStockRow, error) {
+	if m.LowStockFunc != nil {
+		return m.LowStockFunc(ctx, pharmacyID, threshold)
+	}
+	return nil, nil
+}
+
+func (m *MockReportRepository) ExpiringStock(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.ExpiringStockRow, error) {
+	if m.ExpiringStockFunc != nil {
+		return m.ExpiringStockFunc(ctx, pharmacyID, from, to)
+	}
+	return nil, nil
+}
+
+func (m *MockReportRepository) TaxByRate(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.TaxLine, error) {
+	if m.TaxByRateFunc != nil {
+		return m.TaxByRateFunc(ctx, pharmacyID, from, to)
+	}
+	return nil, nil
+}
+
+func (m *MockReportRepository) SalesRegister(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.SalesRegisterRow, error) {
+	if m.SalesRegisterFunc != nil {
+		return m.SalesRegisterFunc(ctx, pharmacyID, from, to)
+	}
+	return nil, nil
+}
+
+func (m *MockReportRepository) DeadStock(ctx context.Context, pharmacyID uuid.UUID, since time.Time, minValue float64, limit int) ([]*models.DeadStockRow, error) {
+	if m.DeadStockFunc != nil {
+		return m.DeadStockFunc(ctx, pharmacyID, since, minValue, limit)
+	}
+	return nil, nil
+}
+
+// MockFlashSaleRepository is a mock for FlashSaleRepository for unit tests (no DB).
+type MockFlashSaleRepository struct {
+	ListLiveItemsFunc           func(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID, now, startsBy time.Time) ([]*models.FlashSaleItem, error)
+	CountOverlappingItemsFunc   func(ctx context.Context, pharmacyID, productID uuid.UUID, from, to time.Time, excludeSaleID uuid.UUID) (int64, error)
+	ReserveItemFunc             func(ctx context.Context, itemID uuid.UUID, qty int) (bool, error)
+	ReleaseItemFunc             func(ctx context.Context, itemID uuid.UUID, qty int) error
+	ReserveCustomerQuantityFunc func(ctx context.Context, itemID uuid.UUID, customerKey string, qty, limit int) (bool, error)
+	ReleaseCustomerQuantityFunc func(ctx context.Context, itemID uuid.UUID, customerKey string, qty int) error
+	ListRedemptionsByOrderFunc  func(ctx context.Context, orderID uuid.UUID) ([]*models.FlashSaleRedemption, error)
+	// Redemptions collects the rows passed to CreateRedemption.
+	Redemptions []*models.FlashSaleRedemption
+}
+
+func (m *MockFlashSaleRepository) Create(ctx context.Context, f *models.FlashSale) error { return nil }
+
+func (m *MockFlashSaleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.FlashSale, error) {
+	return nil, nil
+}
+
+func (m *MockFlashSaleRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.FlashSale, int64, error) {
+	return nil, 0, nil
+}
+
+func (m *MockFlashSaleRepository) Update(ctx context.Context, f *models.FlashSale) error { return nil }
+
+func (m *MockFlashSaleRepository) ReplaceItems(ctx context.Context, flashSaleID uuid.UUID, items []models.FlashSaleItem) error {
+	return nil
+}
+
+func (m *MockFlashSaleRepository) Delete(ctx context.Context, id uuid.UUID) error { return nil }
+
+func (m *MockFlashSaleRepository) ListLiveItems(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID, now, startsBy time.Time) ([]*models.FlashSaleItem, error) {
+	if m.ListLiveItemsFunc != nil {
+		return m.ListLiveItemsFunc(ctx, pharmacyID, productIDs, now, startsBy)
+	}
+	return nil, nil
+}
+
+func (m *MockFlashSaleRepository) CountOverlappingItems(ctx context.Context, pharmacyID, productID uuid.UUID, from, to time.Time, excludeSaleID uuid.UUID) (int64, error) {
+	if m.CountOverlappingItemsFunc != nil {
+		return m.CountOverlappingItemsFunc(ctx, pharmacyID, productID, from, to, excludeSaleID)
+	}
+	return 0, nil
+}
+
+func (m *MockFlashSaleRepository) ReserveItem(ctx context.Context, itemID uuid.UUID, qty int) (bool, error) {
+	if m.ReserveItemFunc != nil {
+		return m.ReserveItemFunc(ctx, itemID, qty)
+	}
+	return true, nil
+}
+
+func (m *MockFlashSaleRepository) ReleaseItem(ctx context.Context, itemID uuid.UUID, qty int) error {
+	if m.ReleaseItemFunc != nil {
+		return m.ReleaseItemFunc(ctx, itemID, qty)
+	}
+	return nil
+}
+
+func (m *MockFlashSaleRepository) ReserveCustomerQuantity(ctx context.Context, itemID uuid.UUID, customerKey string, qty, limit int) (bool, error) {
+	if m.ReserveCustomerQuantityFunc != nil {
+		return m.ReserveCustomerQuantityFunc(ctx, itemID, customerKey, qty, limit)
+	}
+	return true, nil
+}
+
+func (m *MockFlashSaleRepository) ReleaseCustomerQuantity(ctx context.Context, itemID uuid.UUID, customerKey string, qty int) error {
+	if m.ReleaseCustomerQuantityFunc != nil {
+		return m.ReleaseCustomerQuantityFunc(ctx, itemID, customerKey, qty)
+	}
+	return nil
+}
+
+func (m *MockFlashSaleRepository) CreateRedemption(ctx context.Context, r *models.FlashSaleRedemption) error {
+	m.Redemptions = append(m.Redemptions, r)
+	return nil
+}
+
+func (m *MockFlashSaleRepository) ListRedemptionsByOrder(ctx context.Context, orderID uuid.UUID) ([]*models.FlashSaleRedemption, error) {
+	if m.ListRedemptionsByOrderFunc != nil {
+		return m.ListRedemptionsByOrderFunc(ctx, orderID)
+	}
+	return nil, nil
+}
+
+func (m *MockFlashSaleRepository) DeleteRedemptionsByOrder(ctx context.Context, orderID uuid.UUID) error {
+	return nil
+}
+
+// MockTrainingRepository is a mock for TrainingRepository for unit tests (no DB).
+type MockTrainingRepository struct {
+	GetSOPByIDFunc       func(ctx context.Context, id uuid.UUID) (*models.SOPDocument, error)
+	CreateSOPAckFunc     func(ctx context.Context, a *models.SOPAck) error
+	GetActiveQuizForFunc func(ctx context.Context, announcementID, sopID *uuid.UUID) (*models.TrainingQuiz, error)
+	HasPassedFunc        func(ctx context.Context, quizID, userID uuid.UUID) (bool, error)
+}
+
+func (m *MockTrainingRepository) CreateSOP(ctx context.Context, d *models.SOPDocument) error {
+	return nil
+}
+
+func (m *MockTrainingRepository) GetSOPByID(ctx context.Context, id uuid.UUID) (*models.SOPDocument, error) {
+	if m.GetSOPByIDFunc != nil {
+		return m.GetSOPByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockTrainingRepository) ListSOPs(ctx context.Context, pharmacyID uuid.UUID, activeOnly bool) ([]*models.SOPDocument, error) {
+	return nil, nil
+}
+
+func (m *MockTrainingRepository) UpdateSOP(ctx context.Context, d *models.SOPDocument) error {
+	return nil
+}
+
+func (m *MockTrainingRepository) DeleteSOP(ctx context.Context, id uuid.UUID) error { return nil }
+
+func (m *MockTrainingRepository) CreateSOPAck(ctx context.Context, a *models.SOPAck) error {
+	if m.CreateSOPAckFunc != nil {
+		return m.CreateSOPAckFunc(ctx, a)
+	}
+	return nil
+}
+
+func (m *MockTrainingRepository) HasAckedSOP(ctx context.Context, userID, sopID uuid.UUID) (bool, error) {
+	return false, nil
+}
+
+func (m *MockTrainingRepository) ListSOPAcks(ctx context.Context, sopIDs []uuid.UUID) ([]*models.SOPAck, error) {
+	return nil, nil
+}
+
+func (m *MockTrainingRepository) CreateQuiz(ctx context.Context, q *models.TrainingQuiz) error {
+	return nil
+}
+
+func (m *MockTrainingRepository) GetQuizByID(ctx context.Context, id uuid.UUID) (*models.TrainingQuiz, error) {
+	return nil, nil
+}
+
+func (m *MockTrainingRepository) GetActiveQuizFor(ctx context.Context, announcementID, sopID *uuid.UUID) (*models.TrainingQuiz, error) {
+	if m.GetActiveQuizForFunc != nil {
+		return m.GetActiveQuizForFunc(ctx, announcementID, sopID)
+	}
+	return nil, nil
+}
+
+func (m *MockTrainingRepository) ListQuizzes(ctx context.Context, pharmacyID uuid.UUID, activeOnly bool) ([]*models.TrainingQuiz, error) {
+	return nil, nil
+}
+
+func (m *MockTrainingRepository) UpdateQuiz(ctx context.Context, q *models.TrainingQuiz) error {
+	return nil
+}
+
+func (m *MockTrainingRepository) DeleteQuiz(ctx context.Context, id uuid.UUID) error { return nil }
+
+func (m *MockTrainingRepository) CreateAttempt(ctx context.Context, a *models.TrainingAttempt) error {
+	return nil
+}
+
+func (m *MockTrainingRepository) HasPassed(ctx context.Context, quizID, userID uuid.UUID) (bool, error) {
+	if m.HasPassedFunc != nil {
+		return m.HasPassedFunc(ctx, quizID, userID)
+	}
+	return false, nil
+}
+
+func (m *MockTrainingRepository) AttemptStats(ctx context.Context, quizIDs []uuid.UUID) ([]*models.TrainingAttemptStat, error) {
+	return nil, nil
+}
+
+// MockPharmacyConfigRepository is a mock for PharmacyConfigRepository for unit tests (no DB).
+type MockPharmacyConfigRepository struct {
+	GetByPharmacyIDFunc func(ctx context.Context, pharmacyID uuid.UUID) (*models.PharmacyConfig, error)
+	CreateFunc          func(ctx context.Context, c *models.PharmacyConfig) error
+}
+
+func (m *MockPharmacyConfigRepository) GetByPharmacyID(ctx context.Context, pharmacyID uuid.UUID) (*models.PharmacyConfig, error) {
+	if m.GetByPharmacyIDFunc != nil {
+		return m.GetByPharmacyIDFunc(ctx, pharmacyID)
+	}
+	return nil, nil
+}
+
+func (m *MockPharmacyConfigRepository) Create(ctx context.Context, c *models.PharmacyConfig) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, c)
+	}
+	return nil
+}
+
+func (m *MockPharmacyConfigRepository) Update(ctx context.Context, c *models.PharmacyConfig) error {
+	return nil
+}
+
+// MockPasswordResetTokenRepository is a mock for PasswordResetTokenRepository for unit tests (no DB).
+type MockPasswordResetTokenRepository struct {
+	CreateFunc         func(ctx context.Context, t *models.PasswordResetToken) error
+	GetByTokenHashFunc func(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error)
+	CountSinceFunc     func(ctx context.Context, userID uuid.UUID, since time.Time) (int64, error)
+	ClaimFunc          func(ctx context.Context, id uuid.UUID, usedAt time.Time) (bool, error)
+	MarkAllUsedFunc    func(ctx context.Context, userID uuid.UUID, usedAt time.Time) error
+}
+
+func (m *MockPasswordResetTokenRepository) Create(ctx context.Context, t *models.PasswordResetToken) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, t)
+	}
+	return nil
+}
+
+func (m *MockPasswordResetTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	if m.GetByTokenHashFunc != nil {
+		return m.GetByTokenHashFunc(ctx, tokenHash)
+	}
+	return nil, nil
+}
+
+func (m *MockPasswordResetTokenRepository) CountSince(ctx context.Context, userID uuid.UUID, since time.Time) (int64, error) {
+	if m.CountSinceFunc != nil {
+		return m.CountSinceFunc(ctx, userID, since)
+	}
+	return 0, nil
+}
+
+func (m *MockPasswordResetTokenRepository) Claim(ctx context.Context, id uuid.UUID, usedAt time.Time) (bool, error) {
+	if m.ClaimFunc != nil {
+		return m.ClaimFunc(ctx, id, usedAt)
+	}
+	return true, nil
+}
+
+func (m *MockPasswordResetTokenRepository) MarkAllUsed(ctx context.Context, userID uuid.UUID, usedAt time.Time) error {
+	if m.MarkAllUsedFunc != nil {
+		return m.MarkAllUsedFunc(ctx, userID, usedAt)
+	}
+	return nil
+}
+
+// MockInventoryBatchRepository is a mock for InventoryBatchRepository for unit tests (no DB).
+type MockInventoryBatchRepository struct {
+	GetByIDFunc                func(ctx context.Context, id uuid.UUID) (*models.InventoryBatch, error)
+	ListByProductIDFunc        func(ctx context.Context, productID uuid.UUID) ([]*models.InventoryBatch, error)
+	ListExpiringByPharmacyFunc func(ctx context.Context, pharmacyID uuid.UUID, beforeOrOn time.Time) ([]*models.InventoryBatch, error)
+	UpdateFunc                 func(ctx context.Context, b *models.InventoryBatch) error
+	DrawFunc                   func(ctx context.Context, id uuid.UUID, quantity int) (bool, error)
+	CreateAllocationFunc       func(ctx context.Context, a *models.OrderItemBatch) error
+}
+
+func (m *MockInventoryBatchRepository) Create(ctx context.Context, b *models.InventoryBatch) error {
+	return nil
+}
+
+func (m *MockInventoryBatchRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.InventoryBatch, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockInventoryBatchRepository) ListByProductID(ctx context.Context, productID uuid.UUID) ([]*models.InventoryBatch, error) {
+	if m.ListByProductIDFunc != nil {
+		return m.ListByProductIDFunc(ctx, productID)
+	}
+	return nil, nil
+}
+
+func (m *MockInventoryBatchRepository) ListByPharmacyID(ctx context.Context, pharmacyID uuid.UUID) ([]*models.InventoryBatch, error) {
+	return nil, nil
+}
+
+func (m *MockInventoryBatchRepository) ListExpiringByPharmacy(ctx context.Context, pharmacyID uuid.UUID, beforeOrOn time.Time) ([]*models.InventoryBatch, error) {
+	if m.ListExpiringByPharmacyFunc != nil {
+		return m.ListExpiringByPharmacyFunc(ctx, pharmacyID, beforeOrOn)
+	}
+	return nil, nil
+}
+
+func (m *MockInventoryBatchRepository) Update(ctx context.Context, b *models.InventoryBatch) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, b)
+	}
+	return nil
+}
+
+func (m *MockInventoryBatchRepository) Draw(ctx context.Context, id uuid.UUID, quantity int) (bool, error) {
+	if m.DrawFunc != nil {
+		return m.DrawFunc(ctx, id, quantity)
+	}
+	return true, nil
+}
+
+func (m *MockInventoryBatchRepository) Delete(ctx context.Context, id uuid.UUID) error { return nil }
+
+func (m *MockInventoryBatchRepository) CreateAllocation(ctx context.Context, a *models.OrderItemBatch) error {
+	if m.CreateAllocationFunc != nil {
+		return m.CreateAllocationFunc(ctx, a)
+	}
+	return nil
+}
+
+func (m *MockInventoryBatchRepository) ListAllocationsByBatch(ctx context.Context, batchID uuid.UUID) ([]*models.OrderItemBatch, error) {
+	return nil, nil
+}
+
+// MockPharmacyConfigVersionRepository is a mock for PharmacyConfigVersionRepository for unit tests (no DB).
+type MockPharmacyConfigVersionRepository struct {
+	CreateFunc         func(ctx context.Context, v *models.PharmacyConfigVersion) error
+	ListByPharmacyFunc func(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.PharmacyConfigVersion, int64, error)
+	GetByVersionFunc   func(ctx context.Context, pharmacyID uuid.UUID, version int) (*models.PharmacyConfigVersion, error)
+	LatestVersionFunc  func(ctx context.Context, pharmacyID uuid.UUID) (int, error)
+}
+
+func (m *MockPharmacyConfigVersionRepository) Create(ctx context.Context, v *models.PharmacyConfigVersion) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, v)
+	}
+	return nil
+}
+
+func (m *MockPharmacyConfigVersionRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.PharmacyConfigVersion, int64, error) {
+	if m.ListByPharmacyFunc != nil {
+		return m.ListByPharmacyFunc(ctx, pharmacyID, limit, offset)
+	}
+	return nil, 0, nil
+}
+
+func (m *MockPharmacyConfigVersionRepository) GetByVersion(ctx context.Context, pharmacyID uuid.UUID, version int) (*models.PharmacyConfigVersion, error) {
+	if m.GetByVersionFunc != nil {
+		return m.GetByVersionFunc(ctx, pharmacyID, version)
+	}
+	return nil, nil
+}
+
+func (m *MockPharmacyConfigVersionRepository) LatestVersion(ctx context.Context, pharmacyID uuid.UUID) (int, error) {
+	if m.LatestVersionFunc != nil {
+		return m.LatestVersionFunc(ctx, pharmacyID)
+	}
+	return 0, nil
+}
+
+// MockPromoCodeRepository is a mock for PromoCodeRepository for unit tests (no DB).
+type MockPromoCodeRepository struct {
+	CreateFunc               func(ctx context.Context, p *models.PromoCode) error
+	GetByIDFunc              func(ctx context.Context, id uuid.UUID) (*models.PromoCode, error)
+	GetByPharmacyAndCodeFunc func(ctx context.Context, pharmacyID uuid.UUID, code string) (*models.PromoCode, error)
+	UpdateFunc               func(ctx context.Context, p *models.PromoCode) error
+}
+
+func (m *MockPromoCodeRepository) Create(ctx context.Context, p *models.PromoCode) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, p)
+	}
+	return nil
+}
+
+func (m *MockPromoCodeRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PromoCode, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockPromoCodeRepository) GetByPharmacyAndCode(ctx context.Context, pharmacyID uuid.UUID, code string) (*models.PromoCode, error) {
+	if m.GetByPharmacyAndCodeFunc != nil {
+		return m.GetByPharmacyAndCodeFunc(ctx, pharmacyID, code)
+	}
+	return nil, nil
+}
+
+func (m *MockPromoCodeRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.PromoCode, error) {
+	return nil, nil
+}
+
+func (m *MockPromoCodeRepository) Update(ctx context.Context, p *models.PromoCode) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, p)
+	}
+	return nil
+}
+
+func (m *MockPromoCodeRepository) IncrementUsedCount(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+// MockPromoRepository is a mock for PromoRepository for unit tests (no DB).
+type MockPromoRepository struct {
+	GetByIDFunc        func(ctx context.Context, id uuid.UUID) (*models.Promo, error)
+	ListByPharmacyFunc func(ctx context.Context, pharmacyID uuid.UUID, types []string, activeOnly bool) ([]*models.Promo, error)
+	UpdateFunc         func(ctx context.Context, p *models.Promo) error
+}
+
+func (m *MockPromoRepository) Create(ctx context.Context, p *models.Promo) error { return nil }
+
+func (m *MockPromoRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Promo, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockPromoRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, types []string, activeOnly bool) ([]*models.Promo, error) {
+	if m.ListByPharmacyFunc != nil {
+		return m.ListByPharmacyFunc(ctx, pharmacyID, types, activeOnly)
+	}
+	return nil, nil
+}
+
+func (m *MockPromoRepository) Update(ctx context.Context, p *models.Promo) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, p)
+	}
+	return nil
+}
+
+func (m *MockPromoRepository) Delete(ctx context.Context, id uuid.UUID) error { return nil }
+
+// MockPromoStatRepository is a mock for PromoStatRepository for unit tests (no DB).
+type MockPromoStatRepository struct {
+	IncrementFunc   func(ctx context.Context, pharmacyID, promoID uuid.UUID, day time.Time, placement string, impressions, clicks int64) error
+	ListByPromoFunc func(ctx context.Context, promoID uuid.UUID, from, to time.Time) ([]*models.PromoDailyStat, error)
+}
+
+func (m *MockPromoStatRepository) Increment(ctx context.Context, pharmacyID, promoID uuid.UUID, day time.Time, placement string, impressions, clicks int64) error {
+	if m.IncrementFunc != nil {
+		return m.IncrementFunc(ctx, pharmacyID, promoID, day, placement, impressions, clicks)
+	}
+	return nil
+}
+
+func (m *MockPromoStatRepository) ListByPromo(ctx context.Context, promoID uuid.UUID, from, to time.Time) ([]*models.PromoDailyStat, error) {
+	if m.ListByPromoFunc != nil {
+		return m.ListByPromoFunc(ctx, promoID, from, to)
+	}
+	return nil, nil
+}
+
+// MockRoleRepository is a mock for RoleRepository for unit tests (no DB).
+type MockRoleRepository struct {
+	CreateFunc         func(ctx context.Context, r *models.Role) error
+	GetByNameFunc      func(ctx context.Context, pharmacyID uuid.UUID, name string) (*models.Role, error)
+	ListByPharmacyFunc func(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Role, error)
+	UpdateFunc         func(ctx context.Context, r *models.Role) error
+	DeleteFunc         func(ctx context.Context, id uuid.UUID) error
+}
+
+func (m *MockRoleRepository) Create(ctx context.Context, r *models.Role) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, r)
+	}
+	return nil
+}
+
+func (m *MockRoleRepository) GetByName(ctx context.Context, pharmacyID uuid.UUID, name string) (*models.Role, error) {
+	if m.GetByNameFunc != nil {
+		return m.GetByNameFunc(ctx, pharmacyID, name)
+	}
+	return nil, nil
+}
+
+func (m *MockRoleRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Role, error) {
+	if m.ListByPharmacyFunc != nil {
+		return m.ListByPharmacyFunc(ctx, pharmacyID)
+	}
+	return nil, nil
+}
+
+func (m *MockRoleRepository) Update(ctx context.Context, r *models.Role) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, r)
+	}
+	return nil
+}
+
+func (m *MockRoleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil
+}
+
+// MockCartRepository is a mock for CartRepository for unit tests (no DB).
+type MockCartRepository struct {
+	CreateFunc           func(ctx context.Context, c *models.Cart) error
+	GetOpenFunc          func(ctx context.Context, pharmacyID, userID uuid.UUID) (*models.Cart, error)
+	UpdateCodesFunc      func(ctx context.Context, c *models.Cart) error
+	SetItemQuantityFunc  func(ctx context.Context, cartID, productID uuid.UUID, quantity int) error
+	DeleteItemFunc       func(ctx context.Context, cartID, productID uuid.UUID) error
+	DeleteItemsFunc      func(ctx context.Context, cartID uuid.UUID) error
+	TransitionStatusFunc func(ctx context.Context, cartID uuid.UUID, from, to string, orderID *uuid.UUID) (bool, error)
+}
+
+func (m *MockCartRepository) Create(ctx context.Context, c *models.Cart) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, c)
+	}
+	return nil
+}
+
+func (m *MockCartRepository) GetOpen(ctx context.Context, pharmacyID, userID uuid.UUID) (*models.Cart, error) {
+	if m.GetOpenFunc != nil {
+		return m.GetOpenFunc(ctx, pharmacyID, userID)
+	}
+	return nil, nil
+}
+
+func (m *MockCartRepository) UpdateCodes(ctx context.Context, c *models.Cart) error {
+	if m.UpdateCodesFunc != nil {
+		return m.UpdateCodesFunc(ctx, c)
+	}
+	return nil
+}
+
+func (m *MockCartRepository) SetItemQuantity(ctx context.Context, cartID, productID uuid.UUID, quantity int) error {
+	if m.SetItemQuantityFunc != nil {
+		return m.SetItemQuantityFunc(ctx, cartID, productID, quantity)
+	}
+	return nil
+}
+
+func (m *MockCartRepository) DeleteItem(ctx context.Context, cartID, productID uuid.UUID) error {
+	if m.DeleteItemFunc != nil {
+		return m.DeleteItemFunc(ctx, cartID, productID)
+	}
+	return nil
+}
+
+func (m *MockCartRepository) DeleteItems(ctx context.Context, cartID uuid.UUID) error {
+	if m.DeleteItemsFunc != nil {
+		return m.DeleteItemsFunc(ctx, cartID)
+	}
+	return nil
+}
+
+func (m *MockCartRepository) TransitionStatus(ctx context.Context, cartID uuid.UUID, from, to string, orderID *uuid.UUID) (bool, error) {
+	if m.TransitionStatusFunc != nil {
+		return m.TransitionStatusFunc(ctx, cartID, from, to, orderID)
+	}
+	return true, nil
+}
+
+// MockOrderRepository is a mock for OrderRepository for unit tests (no DB).
+type MockOrderRepository struct {
+	GetByIDFunc                 func(ctx context.Context, id uuid.UUID) (*models.Order, error)
+	UpdateFunc                  func(ctx context.Context, o *models.Order) error
+	GetItemsByOrderIDFunc       func(ctx context.Context, orderID uuid.UUID) ([]*models.OrderItem, error)
+	ListPageFunc                func(ctx context.Context, pharmacyID uuid.UUID, createdBy *uuid.UUID, status *string, branchID *uuid.UUID, limit, offset int) ([]*models.Order, int64, error)
+	ListPaginatedFunc           func(ctx context.Context, pharmacyID uuid.UUID, filter outbound.OrderFilter, limit, offset int) ([]*models.Order, int64, error)
+	ItemCountsFunc              func(ctx context.Context, orderIDs []uuid.UUID) ([]*models.OrderItemCountRow, error)
+	UpdateStatusFunc            func(ctx context.Context, o *models.Order, h *models.OrderStatusHistory) error
+	CountByPromoCodeAndUserFunc func(ctx context.Context, promoCodeID, createdBy uuid.UUID) (int64, error)
+	ListStatusHistoryFunc       func(ctx context.Context, orderIDs []uuid.UUID) ([]*models.OrderStatusHistory, error)
+	SetConversationFunc         func(ctx context.Context, orderID, conversationID uuid.UUID) error
+	// Events collects the outbox events passed to Create and UpdateStatus.
+	Events []*models.OutboxEvent
+}
+
+func (m *MockOrderRepository) Create(ctx context.Context, o *models.Order, events ...*models.OutboxEvent) error {
+	m.Events = append(m.Events, events...)
+	return nil
+}
+
+func (m *MockOrderRepository) SetConversation(ctx context.Context, orderID, conversationID uuid.UUID) error {
+	if m.SetConversationFunc != nil {
+		return m.SetConversationFunc(ctx, orderID, conversationID)
+	}
+	return nil
+}
+
+func (m *MockOrderRepository) CreateItem(ctx context.Context, item *models.OrderItem) error {
+	return nil
+}
+
+func (m *MockOrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Order, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockOrderRepository) GetByOrderNumber(ctx context.Context, pharmacyID uuid.UUID, orderNumber string) (*models.Order, error) {
+	return nil, nil
+}
+
+func (m *MockOrderRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, status *string) ([]*models.Order, error) {
+	return nil, nil
+}
+
+func (m *MockOrderRepository) ListByPharmacyAndCreatedBy(ctx context.Context, pharmacyID uuid.UUID, createdBy uuid.UUID, status *string) ([]*models.Order, error) {
+	return nil, nil
+}
+
+func (m *MockOrderRepository) ListPage(ctx context.Context, pharmacyID uuid.UUID, createdBy *uuid.UUID, status *string, branchID *uuid.UUID, limit, offset int) ([]*models.Order, int64, error) {
+	if m.ListPageFunc != nil {
+		return m.ListPageFunc(ctx, pharmacyID, createdBy, status, branchID, limit, offset)
+	}
+	return nil, 0, nil
+}
+
+func (m *MockOrderRepository) ListPaginated(ctx context.Context, pharmacyID uuid.UUID, filter outbound.OrderFilter, limit, offset int) ([]*models.Order, int64, error) {
+	if m.ListPaginatedFunc != nil {
+		return m.ListPaginatedFunc(ctx, pharmacyID, filter, limit, offset)
+	}
+	return nil, 0, nil
+}
+
+func (m *MockOrderRepository) ItemCounts(ctx context.Context, orderIDs []uuid.UUID) ([]*models.OrderItemCountRow, error) {
+	if m.ItemCountsFunc != nil {
+		return m.ItemCountsFunc(ctx, orderIDs)
+	}
+	return nil, nil
+}
+
+func (m *MockOrderRepository) UpdateStatus(ctx context.Context, o *models.Order, h *models.OrderStatusHistory, events ...*models.OutboxEvent) error {
+	if m.UpdateStatusFunc != nil {
+		if err := m.UpdateStatusFunc(ctx, o, h); err != nil {
+			return err
+		}
+	}
+	m.Events = append(m.Events, events...)
+	return nil
+}
+
+func (m *MockOrderRepository) CreateStatusHistory(ctx context.Context, h *models.OrderStatusHistory) error {
+	return nil
+}
+
+func (m *MockOrderRepository) ListStatusHistory(ctx context.Context, orderIDs []uuid.UUID) ([]*models.OrderStatusHistory, error) {
+	if m.ListStatusHistoryFunc != nil {
+		return m.ListStatusHistoryFunc(ctx, orderIDs)
+	}
+	return nil, nil
+}
+
+func (m *MockOrderRepository) Update(ctx context.Context, o *models.Order) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, o)
+	}
+	return nil
+}
+
+func (m *MockOrderRepository) GetItemsByOrderID(ctx context.Context, orderID uuid.UUID) ([]*models.OrderItem, error) {
+	if m.GetItemsByOrderIDFunc != nil {
+		return m.GetItemsByOrderIDFunc(ctx, orderID)
+	}
+	return nil, nil
+}
+
+func (m *MockOrderRepository) CountByCustomerIDAndStatus(ctx context.Context, customerID uuid.UUID, status string) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockOrderRepository) CountByCreatedByAndPharmacy(ctx context.Context, createdBy, pharmacyID uuid.UUID) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockOrderRepository) CountByPromoCodeAndUser(ctx context.Context, promoCodeID, createdBy uuid.UUID) (int64, error) {
+	if m.CountByPromoCodeAndUserFunc != nil {
+		return m.CountByPromoCodeAndUserFunc(ctx, promoCodeID, createdBy)
+	}
+	return 0, nil
+}
+
+func (m *MockOrderRepository) GetLatestCompletedOrderWithProduct(ctx context.Context, pharmacyID, userID, productID uuid.UUID) (*models.Order, error) {
+	return nil, nil
+}
+
+// MockDeliveryRepository is a mock for DeliveryRepository for unit tests (no DB).
+type MockDeliveryRepository struct {
+	CreateFunc       func(ctx context.Context, d *models.Delivery) error
+	GetByOrderIDFunc func(ctx context.Context, orderID uuid.UUID) (*models.Delivery, error)
+	UpdateFunc       func(ctx context.Context, d *models.Delivery) error
+	CreateEventFunc  func(ctx context.Context, e *models.DeliveryEvent) error
+	ListFunc         func(ctx context.Context, pharmacyID uuid.UUID, status *models.DeliveryStatus, assignedTo *uuid.UUID) ([]*models.Delivery, error)
+}
+
+func (m *MockDeliveryRepository) Create(ctx context.Context, d *models.Delivery) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, d)
+	}
+	return nil
+}
+
+func (m *MockDeliveryRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.Delivery, error) {
+	if m.GetByOrderIDFunc != nil {
+		return m.GetByOrderIDFunc(ctx, orderID)
+	}
+	return nil, nil
+}
+
+func (m *MockDeliveryRepository) Update(ctx context.Context, d *models.Delivery) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, d)
+	}
+	return nil
+}
+
+func (m *MockDeliveryRepository) CreateEvent(ctx context.Context, e *models.DeliveryEvent) error {
+	if m.CreateEventFunc != nil {
+		return m.CreateEventFunc(ctx, e)
+	}
+	return nil
+}
+
+func (m *MockDeliveryRepository) List(ctx context.Context, pharmacyID uuid.UUID, status *models.DeliveryStatus, assignedTo *uuid.UUID) ([]*models.Delivery, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, pharmacyID, status, assignedTo)
+	}
+	return nil, nil
+}
+
+// MockOrderFeedbackRepository is a mock for OrderFeedbackRepository for unit tests (no DB).
+type MockOrderFeedbackRepository struct {
+	CreateFunc             func(ctx context.Context, f *models.OrderFeedback) error
+	GetByOrderIDFunc       func(ctx context.Context, orderID uuid.UUID) (*models.OrderFeedback, error)
+	GetByIDFunc            func(ctx context.Context, id uuid.UUID) (*models.OrderFeedback, error)
+	UpdateFunc             func(ctx context.Context, f *models.OrderFeedback) error
+	ListByPharmacyFunc     func(ctx context.Context, pharmacyID uuid.UUID, filter outbound.FeedbackFilter, limit, offset int) ([]*models.OrderFeedback, int64, error)
+	RatingTrendFunc        func(ctx context.Context, pharmacyID uuid.UUID, granularity string, from, to time.Time) ([]*models.FeedbackTrendRow, error)
+	RatingByStaffFunc      func(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.FeedbackGroupRow, error)
+	RatingByFulfilmentFunc func(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.FeedbackGroupRow, error)
+}
+
+func (m *MockOrderFeedbackRepository) Create(ctx context.Context, f *models.OrderFeedback) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, f)
+	}
+	return nil
+}
+
+func (m *MockOrderFeedbackRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.OrderFeedback, error) {
+	if m.GetByOrderIDFunc != nil {
+		return m.GetByOrderIDFunc(ctx, orderID)
+	}
+	return nil, nil
+}
+
+func (m *MockOrderFeedbackRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.OrderFeedback, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockOrderFeedbackRepository) Update(ctx context.Context, f *models.OrderFeedback) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, f)
+	}
+	return nil
+}
+
+func (m *MockOrderFeedbackRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, filter outbound.FeedbackFilter, limit, offset int) ([]*models.OrderFeedback, int64, error) {
+	if m.ListByPharmacyFunc != nil {
+		return m.ListByPharmacyFunc(ctx, pharmacyID, filter, limit, offset)
+	}
+	return nil, 0, nil
+}
+
+func (m *MockOrderFeedbackRepository) RatingTrend(ctx context.Context, pharmacyID uuid.UUID, granularity string, from, to time.Time) ([]*models.FeedbackTrendRow, error) {
+	if m.RatingTrendFunc != nil {
+		return m.RatingTrendFunc(ctx, pharmacyID, granularity, from, to)
+	}
+	return nil, nil
+}
+
+func (m *MockOrderFeedbackRepository) RatingByStaff(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.FeedbackGroupRow, error) {
+	if m.RatingByStaffFunc != nil {
+		return m.RatingByStaffFunc(ctx, pharmacyID, from, to)
+	}
+	return nil, nil
+}
+
+func (m *MockOrderFeedbackRepository) RatingByFulfilment(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.FeedbackGroupRow, error) {
+	if m.RatingByFulfilmentFunc != nil {
+		return m.RatingByFulfilmentFunc(ctx, pharmacyID, from, to)
+	}
+	return nil, nil
+}
+
+// MockOrderReturnRequestRepository is a mock for OrderReturnRequestRepository for unit tests (no DB).
+type MockOrderReturnRequestRepository struct {
+	CreateFunc          func(ctx context.Context, r *models.OrderReturnRequest) error
+	GetByOrderIDFunc    func(ctx context.Context, orderID uuid.UUID) (*models.OrderReturnRequest, error)
+	GetByIDFunc         func(ctx context.Context, id uuid.UUID) (*models.OrderReturnRequest, error)
+	UpdateFunc          func(ctx context.Context, r *models.OrderReturnRequest) error
+	ListByPharmacyFunc  func(ctx context.Context, pharmacyID uuid.UUID, filter outbound.ReturnRequestFilter, limit, offset int) ([]*models.OrderReturnRequest, int64, error)
+	ReturnRatesFunc     func(ctx context.Context, pharmacyID uuid.UUID, groupBy string, from, to time.Time, productIDs []uuid.UUID) ([]*models.ReturnRateRow, error)
+	ReasonBreakdownFunc func(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.ReturnReasonRow, error)
+}
+
+func (m *MockOrderReturnRequestRepository) Create(ctx context.Context, r *models.OrderReturnRequest) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, r)
+	}
+	return nil
+}
+
+func (m *MockOrderReturnRequestRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.OrderReturnRequest, error) {
+	if m.GetByOrderIDFunc != nil {
+		return m.GetByOrderIDFunc(ctx, orderID)
+	}
+	return nil, nil
+}
+
+func (m *MockOrderReturnRequestRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.OrderReturnRequest, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockOrderReturnRequestRepository) Update(ctx context.Context, r *models.OrderReturnRequest) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, r)
+	}
+	return nil
+}
+
+func (m *MockOrderReturnRequestRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, filter outbound.ReturnRequestFilter, limit, offset int) ([]*models.OrderReturnRequest, int64, error) {
+	if m.ListByPharmacyFunc != nil {
+		return m.ListByPharmacyFunc(ctx, pharmacyID, filter, limit, offset)
+	}
+	return nil, 0, nil
+}
+
+func (m *MockOrderReturnRequestRepository) ReturnRates(ctx context.Context, pharmacyID uuid.UUID, groupBy string, from, to time.Time, productIDs []uuid.UUID) ([]*models.ReturnRateRow, error) {
+	if m.ReturnRatesFunc != nil {
+		return m.ReturnRatesFunc(ctx, pharmacyID, groupBy, from, to, productIDs)
+	}
+	return nil, nil
+}
+
+func (m *MockOrderReturnRequestRepository) ReasonBreakdown(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.ReturnReasonRow, error) {
+	if m.ReasonBreakdownFunc != nil {
+		return m.ReasonBreakdownFunc(ctx, pharmacyID, from, to)
+	}
+	return nil, nil
+}
+
+// MockProductReturnFlagRepository is a mock for ProductReturnFlagRepository for unit tests (no DB).
+type MockProductReturnFlagRepository struct {
+	GetByProductIDFunc func(ctx context.Context, productID uuid.UUID) (*models.ProductReturnFlag, error)
+	CreateFunc         func(ctx context.Context, f *models.ProductReturnFlag) error
+	UpdateFunc         func(ctx context.Context, f *models.ProductReturnFlag) error
+	ListByPharmacyFunc func(ctx context.Context, pharmacyID uuid.UUID, status string) ([]*models.ProductReturnFlag, error)
+}
+
+func (m *MockProductReturnFlagRepository) GetByProductID(ctx context.Context, productID uuid.UUID) (*models.ProductReturnFlag, error) {
+	if m.GetByProductIDFunc != nil {
+		return m.GetByProductIDFunc(ctx, productID)
+	}
+	return nil, nil
+}
+
+func (m *MockProductReturnFlagRepository) Create(ctx context.Context, f *models.ProductReturnFlag) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, f)
+	}
+	return nil
+}
+
+func (m *MockProductReturnFlagRepository) Update(ctx context.Context, f *models.ProductReturnFlag) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, f)
+	}
+	return nil
+}
+
+func (m *MockProductReturnFlagRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, status string) ([]*models.ProductReturnFlag, error) {
+	if m.ListByPharmacyFunc != nil {
+		return m.ListByPharmacyFunc(ctx, pharmacyID, status)
+	}
+	return nil, nil
+}
+
+// MockDeviceTokenRepository is a mock for DeviceTokenRepository for unit tests (no DB).
+type MockDeviceTokenRepository struct {
+	UpsertFunc        func(ctx context.Context, d *models.DeviceToken) error
+	ListByUserFunc    func(ctx context.Context, userID uuid.UUID) ([]*models.DeviceToken, error)
+	DeleteFunc        func(ctx context.Context, userID uuid.UUID, token string) error
+	DeleteByTokenFunc func(ctx context.Context, token string) error
+}
+
+func (m *MockDeviceTokenRepository) Upsert(ctx context.Context, d *models.DeviceToken) error {
+	if m.UpsertFunc != nil {
+		return m.UpsertFunc(ctx, d)
+	}
+	return nil
+}
+
+func (m *MockDeviceTokenRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.DeviceToken, error) {
+	if m.ListByUserFunc != nil {
+		return m.ListByUserFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *MockDeviceTokenRepository) Delete(ctx context.Context, userID uuid.UUID, token string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, userID, token)
+	}
+	return nil
+}
+
+func (m *MockDeviceTokenRepository) DeleteByToken(ctx context.Context, token string) error {
+	if m.DeleteByTokenFunc != nil {
+		return m.DeleteByTokenFunc(ctx, token)
+	}
+	return nil
+}
+
+// MockNotificationRepository is a mock for NotificationRepository for unit tests (no DB).
+type MockNotificationRepository struct {
+	CreateFunc            func(ctx context.Context, n *models.Notification) error
+	CountUnreadByUserFunc func(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+func (m *MockNotificationRepository) Create(ctx context.Context, n *models.Notification) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, n)
+	}
+	return nil
+}
+
+func (m *MockNotificationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Notification, error) {
+	return nil, nil
+}
+
+func (m *MockNotificationRepository) ListByUser(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]*models.Notification, error) {
+	return nil, nil
+}
+
+func (m *MockNotificationRepository) CountUnreadByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	if m.CountUnreadByUserFunc != nil {
+		return m.CountUnreadByUserFunc(ctx, userID)
+	}
+	return 0, nil
+}
+
+func (m *MockNotificationRepository) MarkRead(ctx context.Context, id, userID uuid.UUID) error {
+	return nil
+}
+
+func (m *MockNotificationRepository) MarkAllRead(ctx context.Context, userID uuid.UUID) error {
+	return nil
+}
+
+// MockConversationRepository is a mock for ConversationRepository for unit tests (no DB).
+type MockConversationRepository struct {
+	GetByIDFunc     func(ctx context.Context, id uuid.UUID) (*models.Conversation, error)
+	GetByTicketFunc func(ctx context.Context, provider, ticketID string) (*models.Conversation, error)
+	UpdateFunc      func(ctx context.Context, c *models.Conversation) error
+}
+
+func (m *MockConversationRepository) Create(ctx context.Context, c *models.Conversation) error {
+	return nil
+}
+
+func (m *MockConversationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Conversation, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockConversationRepository) GetByPharmacyAndCustomer(ctx context.Context, pharmacyID, customerID uuid.UUID) (*models.Conversation, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockConversationRepository) GetByPharmacyAndUser(ctx context.Context, pharmacyID, userID uuid.UUID) (*models.Conversation, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockConversationRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, userID *uuid.UUID, limit, offset int) ([]*models.Conversation, int64, error) {
+	return nil, 0, nil
+}
+
+func (m *MockConversationRepository) GetByTicket(ctx context.Context, provider, ticketID string) (*models.Conversation, error) {
+	if m.GetByTicketFunc != nil {
+		return m.GetByTicketFunc(ctx, provider, ticketID)
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockConversationRepository) Update(ctx context.Context, c *models.Conversation) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, c)
+	}
+	return nil
+}
+
+func (m *MockConversationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+// MockChatMessageRepository is a mock for ChatMessageRepository for unit tests (no DB).
+type MockChatMessageRepository struct {
+	CreateFunc               func(ctx context.Context, msg *models.ChatMessage) error
+	GetByIDFunc              func(ctx context.Context, id uuid.UUID) (*models.ChatMessage, error)
+	ListByConversationIDFunc func(ctx context.Context, conversationID uuid.UUID, limit, offset int) ([]*models.ChatMessage, int64, error)
+	UpdateFunc               func(ctx context.Context, msg *models.ChatMessage) error
+}
+
+func (m *MockChatMessageRepository) Create(ctx context.Context, msg *models.ChatMessage) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, msg)
+	}
+	return nil
+}
+
+func (m *MockChatMessageRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ChatMessage, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *MockChatMessageRepository) ListByConversationID(ctx context.Context, conversationID uuid.UUID, limit, offset int) ([]*models.ChatMessage, int64, error) {
+	if m.ListByConversationIDFunc != nil {
+		return m.ListByConversationIDFunc(ctx, conversationID, limit, offset)
+	}
+	return nil, 0, nil
+}
+
+func (m *MockChatMessageRepository) Update(ctx context.Context, msg *models.ChatMessage) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, msg)
+	}
+	return nil
+}
+
+func (m *MockChatMessageRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func (m *MockChatMessageRepository) DeleteByConversationID(ctx context.Context, conversationID uuid.UUID) error {
+	return nil
+}
+
+// MockDutyRosterRepository is a mock for DutyRosterRepository for unit tests (no DB).
+type MockDutyRosterRepository struct {
+	CreateFunc                     func(ctx context.Context, d *models.DutyRoster) error
+	GetByIDFunc                    func(ctx context.Context, id uuid.UUID) (*models.DutyRoster, error)
+	ListByPharmacyAndDateRangeFunc func(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.DutyRoster, error)
+	ListByStatusAndDateRangeFunc   func(ctx context.Context, pharmacyID uuid.UUID, status models.RosterStatus, from, to time.Time) ([]*models.DutyRoster, error)
+	ListByUserAndDateRangeFunc     func(ctx context.Context, pharmacyID, userID uuid.UUID, status models.RosterStatus, from, to time.Time) ([]*models.DutyRoster, error)
+	ListUnacknowledgedFunc         func(ctx context.Context, pharmacyID uuid.UUID, from time.Time) ([]*models.DutyRoster, error)
+	UpdateFunc                     func(ctx context.Context, d *models.DutyRoster) error
+	DeleteFunc                     func(ctx context.Context, id uuid.UUID) error
+}
+
+func (m *MockDutyRosterRepository) Create(ctx context.Context, d *models.DutyRoster) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, d)
+	}
+	return nil
+}
+
+func (m *MockDutyRosterRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DutyRoster, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockDutyRosterRepository) ListByPharmacyAndDateRange(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.DutyRoster, error) {
+	if m.ListByPharmacyAndDateRangeFunc != nil {
+		return m.ListByPharmacyAndDateRangeFunc(ctx, pharmacyID, from, to)
+	}
+	return nil, nil
+}
+
+func (m *MockDutyRosterRepository) ListByStatusAndDateRange(ctx context.Context, pharmacyID uuid.UUID, status models.RosterStatus, from, to time.Time) ([]*models.DutyRoster, error) {
+	if m.ListByStatusAndDateRangeFunc != nil {
+		return m.ListByStatusAndDateRangeFunc(ctx, pharmacyID, status, from, to)
+	}
+	return nil, nil
+}
+
+func (m *MockDutyRosterRepository) ListByUserAndDateRange(ctx context.Context, pharmacyID, userID uuid.UUID, status models.RosterStatus, from, to time.Time) ([]*models.DutyRoster, error) {
+	if m.ListByUserAndDateRangeFunc != nil {
+		return m.ListByUserAndDateRangeFunc(ctx, pharmacyID, userID, status, from, to)
+	}
+	return nil, nil
+}
+
+func (m *MockDutyRosterRepository) ListUnacknowledged(ctx context.Context, pharmacyID uuid.UUID, from time.Time) ([]*models.DutyRoster, error) {
+	if m.ListUnacknowledgedFunc != nil {
+		return m.ListUnacknowledgedFunc(ctx, pharmacyID, from)
+	}
+	return nil, nil
+}
+
+func (m *MockDutyRosterRepository) Update(ctx context.Context, d *models.DutyRoster) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, d)
+	}
+	return nil
+}
+
+func (m *MockDutyRosterRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil
+}
+
+// MockInventoryAlertRepository is a mock for InventoryAlertRepository for unit tests (no DB).
+type MockInventoryAlertRepository struct {
+	ListByPharmacyFunc func(ctx context.Context, pharmacyID uuid.UUID) ([]*models.InventoryAlert, error)
+	CreateFunc         func(ctx context.Context, a *models.InventoryAlert) error
+	MarkSeenFunc       func(ctx context.Context, ids []uuid.UUID, at time.Time) error
+	DeleteFunc         func(ctx context.Context, ids []uuid.UUID) error
+	// Events collects the outbox events passed to Create.
+	Events []*models.OutboxEvent
+}
+
+func (m *MockInventoryAlertRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.InventoryAlert, error) {
+	if m.ListByPharmacyFunc != nil {
+		return m.ListByPharmacyFunc(ctx, pharmacyID)
+	}
+	return nil, nil
+}
+
+func (m *MockInventoryAlertRepository) Create(ctx context.Context, a *models.InventoryAlert, events ...*models.OutboxEvent) error {
+	if m.CreateFunc != nil {
+		if err := m.CreateFunc(ctx, a); err != nil {
+			return err
+		}
+	}
+	m.Events = append(m.Events, events...)
+	return nil
+}
+
+func (m *MockInventoryAlertRepository) MarkSeen(ctx context.Context, ids []uuid.UUID, at time.Time) error {
+	if m.MarkSeenFunc != nil {
+		return m.MarkSeenFunc(ctx, ids, at)
+	}
+	return nil
+}
+
+func (m *MockInventoryAlertRepository) Delete(ctx context.Context, ids []uuid.UUID) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, ids)
+	}
+	return nil
+}
+
+type MockShiftSwapRepository struct {
+	CreateFunc          func(ctx context.Context, s *models.ShiftSwap, e *models.ShiftSwapEvent) error
+	GetByIDFunc         func(ctx context.Context, id uuid.UUID) (*models.ShiftSwap, error)
+	ListFunc            func(ctx context.Context, pharmacyID uuid.UUID, status models.ShiftSwapStatus) ([]*models.ShiftSwap, error)
+	GetOpenByRosterFunc func(ctx context.Context, rosterID uuid.UUID) (*models.ShiftSwap, error)
+	SaveFunc            func(ctx context.Context, s *models.ShiftSwap, roster *models.DutyRoster, e *models.ShiftSwapEvent) error
+}
+
+func (m *MockShiftSwapRepository) Create(ctx context.Context, s *models.ShiftSwap, e *models.ShiftSwapEvent) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, s, e)
+	}
+	return nil
+}
+
+func (m *MockShiftSwapRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ShiftSwap, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockShiftSwapRepository) List(ctx context.Context, pharmacyID uuid.UUID, status models.ShiftSwapStatus) ([]*models.ShiftSwap, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, pharmacyID, status)
+	}
+	return nil, nil
+}
+
+func (m *MockShiftSwapRepository) GetOpenByRoster(ctx context.Context, rosterID uuid.UUID) (*models.ShiftSwap, error) {
+	if m.GetOpenByRosterFunc != nil {
+		return m.GetOpenByRosterFunc(ctx, rosterID)
+	}
+	return nil, nil
+}
+
+func (m *MockShiftSwapRepository) Save(ctx context.Context, s *models.ShiftSwap, roster *models.DutyRoster, e *models.ShiftSwapEvent) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, s, roster, e)
+	}
+	return nil
+}
+
+// MockCategoryRepository is a mock for CategoryRepository for unit tests (no DB).
+type MockCategoryRepository struct {
+	CreateFunc         func(ctx context.Context, c *models.Category) error
+	GetByIDFunc        func(ctx context.Context, id uuid.UUID) (*models.Category, error)
+	ListByPharmacyFunc func(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Category, error)
+	ListByParentIDFunc func(ctx context.Context, pharmacyID uuid.UUID, parentID *uuid.UUID) ([]*models.Category, error)
+	UpdateFunc         func(ctx context.Context, c *models.Category) error
+	DeleteFunc         func(ctx context.Context, id uuid.UUID) error
+}
+
+func (m *MockCategoryRepository) Create(ctx context.Context, c *models.Category) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, c)
+	}
+	return nil
+}
+
+func (m *MockCategoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Category, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockCategoryRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Category, error) {
+	if m.ListByPharmacyFunc != nil {
+		return m.ListByPharmacyFunc(ctx, pharmacyID)
+	}
+	return nil, nil
+}
+
+func (m *MockCategoryRepository) ListByParentID(ctx context.Context, pharmacyID uuid.UUID, parentID *uuid.UUID) ([]*models.Category, error) {
+	if m.ListByParentIDFunc != nil {
+		return m.ListByParentIDFunc(ctx, pharmacyID, parentID)
+	}
+	return nil, nil
+}
+
+func (m *MockCategoryRepository) Update(ctx context.Context, c *models.Category) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, c)
+	}
+	return nil
+}
+
+func (m *MockCategoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil
+}
+
+// MockBranchRepository is a mock for BranchRepository for unit tests (no DB).
+type MockBranchRepository struct {
+	CreateFunc                 func(ctx context.Context, b *models.Branch) error
+	GetByIDFunc                func(ctx context.Context, id uuid.UUID) (*models.Branch, error)
+	ListByPharmacyFunc         func(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Branch, error)
+	GetDefaultFunc             func(ctx context.Context, pharmacyID uuid.UUID) (*models.Branch, error)
+	AdoptUnassignedBatchesFunc func(ctx context.Context, pharmacyID, branchID uuid.UUID) (int64, error)
+	StockFunc                  func(ctx context.Context, branchID uuid.UUID, today time.Time) ([]*models.BranchStockRow, error)
+	CreateTransferFunc         func(ctx context.Context, t *models.BranchTransfer, updated, created []*models.InventoryBatch) error
+	GetTransferFunc            func(ctx context.Context, id uuid.UUID) (*models.BranchTransfer, error)
+}
+
+func (m *MockBranchRepository) Create(ctx context.Context, b *models.Branch) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, b)
+	}
+	return nil
+}
+
+func (m *MockBranchRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Branch, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockBranchRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Branch, error) {
+	if m.ListByPharmacyFunc != nil {
+		return m.ListByPharmacyFunc(ctx, pharmacyID)
+	}
+	return nil, nil
+}
+
+func (m *MockBranchRepository) GetDefault(ctx context.Context, pharmacyID uuid.UUID) (*models.Branch, error) {
+	if m.GetDefaultFunc != nil {
+		return m.GetDefaultFunc(ctx, pharmacyID)
+	}
+	return nil, nil
+}
+
+func (m *MockBranchRepository) Update(ctx context.Context, b *models.Branch) error {
+	return nil
+}
+
+func (m *MockBranchRepository) SetDefault(ctx context.Context, pharmacyID, branchID uuid.UUID) error {
+	return nil
+}
+
+func (m *MockBranchRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func (m *MockBranchRepository) AdoptUnassignedBatches(ctx context.Context, pharmacyID, branchID uuid.UUID) (int64, error) {
+	if m.AdoptUnassignedBatchesFunc != nil {
+		return m.AdoptUnassignedBatchesFunc(ctx, pharmacyID, branchID)
+	}
+	return 0, nil
+}
+
+func (m *MockBranchRepository) Stock(ctx context.Context, branchID uuid.UUID, today time.Time) ([]*models.BranchStockRow, error) {
+	if m.StockFunc != nil {
+		return m.StockFunc(ctx, branchID, today)
+	}
+	return nil, nil
+}
+
+func (m *MockBranchRepository) CreateTransfer(ctx context.Context, t *models.BranchTransfer, updated, created []*models.InventoryBatch) error {
+	if m.CreateTransferFunc != nil {
+		return m.CreateTransferFunc(ctx, t, updated, created)
+	}
+	return nil
+}
+
+func (m *MockBranchRepository) GetTransfer(ctx context.Context, id uuid.UUID) (*models.BranchTransfer, error) {
+	if m.GetTransferFunc != nil {
+		return m.GetTransferFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockBranchRepository) ListTransfers(ctx context.Context, pharmacyID uuid.UUID, branchID *uuid.UUID, limit, offset int) ([]*models.BranchTransfer, int64, error) {
+	return nil, 0, nil
+}
+
+// MockStockTransferRepository is a mock for StockTransferRepository for unit tests (no DB).
+type MockStockTransferRepository struct {
+	CreateFunc     func(ctx context.Context, t *models.StockTransfer, e *models.StockTransferEvent) error
+	GetByIDFunc    func(ctx context.Context, id uuid.UUID) (*models.StockTransfer, error)
+	TransitionFunc func(ctx context.Context, t *models.StockTransfer, from models.StockTransferStatus, e *models.StockTransferEvent) (bool, error)
+	ReceiveFunc    func(ctx context.Context, t *models.StockTransfer, e *models.StockTransferEvent, debited, created []*models.InventoryBatch, stockDeltas map[uuid.UUID]int) (bool, error)
+}
+
+func (m *MockStockTransferRepository) Create(ctx context.Context, t *models.StockTransfer, e *models.StockTransferEvent) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, t, e)
+	}
+	return nil
+}
+
+func (m *MockStockTransferRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.StockTransfer, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockStockTransferRepository) List(ctx context.Context, pharmacyID uuid.UUID, direction string, status models.StockTransferStatus, limit, offset int) ([]*models.StockTransfer, int64, error) {
+	return nil, 0, nil
+}
+
+func (m *MockStockTransferRepository) Transition(ctx context.Context, t *models.StockTransfer, from models.StockTransferStatus, e *models.StockTransferEvent) (bool, error) {
+	if m.TransitionFunc != nil {
+		return m.TransitionFunc(ctx, t, from, e)
+	}
+	return true, nil
+}
+
+func (m *MockStockTransferRepository) Receive(ctx context.Context, t *models.StockTransfer, e *models.StockTransferEvent, debited, created []*models.InventoryBatch, stockDeltas map[uuid.UUID]int) (bool, error) {
+	if m.ReceiveFunc != nil {
+		return m.ReceiveFunc(ctx, t, e, debited, created, stockDeltas)
+	}
+	return true, nil
+}
+
+// MockHashtagRepository is a mock for HashtagRepository for unit tests (no DB).
+type MockHashtagRepository struct {
+	CreateFunc            func(ctx context.Context, h *models.Hashtag) error
+	GetByIDFunc           func(ctx context.Context, id uuid.UUID) (*models.Hashtag, error)
+	ListByPharmacyFunc    func(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Hashtag, error)
+	UsageFunc             func(ctx context.Context, pharmacyID uuid.UUID) ([]*models.HashtagUsageRow, error)
+	ReplaceOnProductsFunc func(ctx context.Context, pharmacyID uuid.UUID, from []string, to string) (int64, error)
+	MergeFunc             func(ctx context.Context, source, target *models.Hashtag) (int64, error)
+	TrendingFunc          func(ctx context.Context, pharmacyID uuid.UUID, since time.Time) ([]*models.HashtagTrend, error)
+}
+
+func (m *MockHashtagRepository) Create(ctx context.Context, h *models.Hashtag) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, h)
+	}
+	return nil
+}
+
+func (m *MockHashtagRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Hashtag, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockHashtagRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Hashtag, error) {
+	if m.ListByPharmacyFunc != nil {
+		return m.ListByPharmacyFunc(ctx, pharmacyID)
+	}
+	return nil, nil
+}
+
+func (m *MockHashtagRepository) Update(ctx context.Context, h *models.Hashtag) error { return nil }
+
+func (m *MockHashtagRepository) Delete(ctx context.Context, id uuid.UUID) error { return nil }
+
+func (m *MockHashtagRepository) Usage(ctx context.Context, pharmacyID uuid.UUID) ([]*models.HashtagUsageRow, error) {
+	if m.UsageFunc != nil {
+		return m.UsageFunc(ctx, pharmacyID)
+	}
+	return nil, nil
+}
+
+func (m *MockHashtagRepository) ReplaceOnProducts(ctx context.Context, pharmacyID uuid.UUID, from []string, to string) (int64, error) {
+	if m.ReplaceOnProductsFunc != nil {
+		return m.ReplaceOnProductsFunc(ctx, pharmacyID, from, to)
+	}
+	return 0, nil
+}
+
+func (m *MockHashtagRepository) Merge(ctx context.Context, source, target *models.Hashtag) (int64, error) {
+	if m.MergeFunc != nil {
+		return m.MergeFunc(ctx, source, target)
+	}
+	return 0, nil
+}
+
+func (m *MockHashtagRepository) Trending(ctx context.Context, pharmacyID uuid.UUID, since time.Time) ([]*models.HashtagTrend, error) {
+	if m.TrendingFunc != nil {
+		return m.TrendingFunc(ctx, pharmacyID, since)
+	}
+	return nil, nil
+}
+
+// MockCustomerRepository is a mock for CustomerRepository for unit tests (no DB).
+type MockCustomerRepository struct {
+	GetByIDFunc               func(ctx context.Context, id uuid.UUID) (*models.Customer, error)
+	GetByPharmacyAndPhoneFunc func(ctx context.Context, pharmacyID uuid.UUID, phone string) (*models.Customer, error)
+	AdjustPointsFunc          func(ctx context.Context, customerID uuid.UUID, delta int) (bool, error)
+	ClaimBirthdayGiftFunc     func(ctx context.Context, customerID uuid.UUID, year int) (bool, error)
+}
+
+func (m *MockCustomerRepository) Create(ctx context.Context, c *models.Customer) error { return nil }
+
+func (m *MockCustomerRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Customer, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockCustomerRepository) GetByPharmacyAndPhone(ctx context.Context, pharmacyID uuid.UUID, phone string) (*models.Customer, error) {
+	if m.GetByPharmacyAndPhoneFunc != nil {
+		return m.GetByPharmacyAndPhoneFunc(ctx, pharmacyID, phone)
+	}
+	return nil, nil
+}
+
+func (m *MockCustomerRepository) GetByPharmacyAndReferralCode(ctx context.Context, pharmacyID uuid.UUID, code string) (*models.Customer, error) {
+	return nil, nil
+}
+
+func (m *MockCustomerRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.Customer, int64, error) {
+	return nil, 0, nil
+}
+
+func (m *MockCustomerRepository) Update(ctx context.Context, c *models.Customer) error { return nil }
+
+func (m *MockCustomerRepository) AdjustPoints(ctx context.Context, customerID uuid.UUID, delta int) (bool, error) {
+	if m.AdjustPointsFunc != nil {
+		return m.AdjustPointsFunc(ctx, customerID, delta)
+	}
+	return true, nil
+}
+
+func (m *MockCustomerRepository) ClaimBirthdayGift(ctx context.Context, customerID uuid.UUID, year int) (bool, error) {
+	if m.ClaimBirthdayGiftFunc != nil {
+		return m.ClaimBirthdayGiftFunc(ctx, customerID, year)
+	}
+	return true, nil
+}
+
+func (m *MockCustomerRepository) ReleaseBirthdayGift(ctx context.Context, customerID uuid.UUID, year int) error {
+	return nil
+}
+
+// MockCustomerMembershipRepository is a mock for CustomerMembershipRepository for unit tests (no DB).
+type MockCustomerMembershipRepository struct {
+	GetByCustomerIDFunc func(ctx context.Context, customerID uuid.UUID) (*models.CustomerMembership, error)
+}
+
+func (m *MockCustomerMembershipRepository) Create(ctx context.Context, cm *models.CustomerMembership) error {
+	return nil
+}
+
+func (m *MockCustomerMembershipRepository) GetByCustomerID(ctx context.Context, customerID uuid.UUID) (*models.CustomerMembership, error) {
+	if m.GetByCustomerIDFunc != nil {
+		return m.GetByCustomerIDFunc(ctx, customerID)
+	}
+	return nil, nil
+}
+
+func (m *MockCustomerMembershipRepository) Update(ctx context.Context, cm *models.CustomerMembership) error {
+	return nil
+}
+
+func (m *MockCustomerMembershipRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+// MockGiftCardRepository is a mock for GiftCardRepository for unit tests (no DB).
+type MockGiftCardRepository struct {
+	CreateFunc                  func(ctx context.Context, card *models.GiftCard, issue *models.GiftCardTransaction) error
+	GetByCodeFunc               func(ctx context.Context, pharmacyID uuid.UUID, code string) (*models.GiftCard, error)
+	AdjustFunc                  func(ctx context.Context, t *models.GiftCardTransaction) (bool, error)
+	ListTransactionsByOrderFunc func(ctx context.Context, orderID uuid.UUID) ([]*models.GiftCardTransaction, error)
+}
+
+func (m *MockGiftCardRepository) Create(ctx context.Context, card *models.GiftCard, issue *models.GiftCardTransaction) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, card, issue)
+	}
+	return nil
+}
+
+func (m *MockGiftCardRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.GiftCard, error) {
+	return nil, nil
+}
+
+func (m *MockGiftCardRepository) GetByCode(ctx context.Context, pharmacyID uuid.UUID, code string) (*models.GiftCard, error) {
+	if m.GetByCodeFunc != nil {
+		return m.GetByCodeFunc(ctx, pharmacyID, code)
+	}
+	return nil, nil
+}
+
+func (m *MockGiftCardRepository) List(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.GiftCard, int64, error) {
+	return nil, 0, nil
+}
+
+func (m *MockGiftCardRepository) SetActive(ctx context.Context, id uuid.UUID, active bool) error {
+	return nil
+}
+
+func (m *MockGiftCardRepository) Adjust(ctx context.Context, t *models.GiftCardTransaction) (bool, error) {
+	if m.AdjustFunc != nil {
+		return m.AdjustFunc(ctx, t)
+	}
+	return true, nil
+}
+
+func (m *MockGiftCardRepository) ListTransactions(ctx context.Context, giftCardID uuid.UUID) ([]*models.GiftCardTransaction, error) {
+	return nil, nil
+}
+
+func (m *MockGiftCardRepository) ListTransactionsByOrder(ctx context.Context, orderID uuid.UUID) ([]*models.GiftCardTransaction, error) {
+	if m.ListTransactionsByOrderFunc != nil {
+		return m.ListTransactionsByOrderFunc(ctx, orderID)
+	}
+	return nil, nil
+}
+
+// MockStoreCreditRepository is a mock for StoreCreditRepository for unit tests (no DB).
+type MockStoreCreditRepository struct {
+	AdjustFunc      func(ctx context.Context, e *models.StoreCreditEntry) (bool, error)
+	ListByOrderFunc func(ctx context.Context, orderID uuid.UUID) ([]*models.StoreCreditEntry, error)
+}
+
+func (m *MockStoreCreditRepository) Adjust(ctx context.Context, e *models.StoreCreditEntry) (bool, error) {
+	if m.AdjustFunc != nil {
+		return m.AdjustFunc(ctx, e)
+	}
+	return true, nil
+}
+
+func (m *MockStoreCreditRepository) ListByCustomer(ctx context.Context, customerID uuid.UUID, limit, offset int) ([]*models.StoreCreditEntry, int64, error) {
+	return nil, 0, nil
+}
+
+func (m *MockStoreCreditRepository) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]*models.StoreCreditEntry, error) {
+	if m.ListByOrderFunc != nil {
+		return m.ListByOrderFunc(ctx, orderID)
+	}
+	return nil, nil
+}
+
+// MockInvoiceRepository is a mock for InvoiceRepository for unit tests (no DB).
+type MockInvoiceRepository struct {
+	CreateFunc       func(ctx context.Context, inv *models.Invoice) error
+	GetByOrderIDFunc func(ctx context.Context, orderID uuid.UUID) (*models.Invoice, error)
+	UpdateFunc       func(ctx context.Context, inv *models.Invoice) error
+}
+
+func (m *MockInvoiceRepository) Create(ctx context.Context, inv *models.Invoice) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, inv)
+	}
+	return nil
+}
+
+func (m *MockInvoiceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Invoice, error) {
+	return nil, nil
+}
+
+func (m *MockInvoiceRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.Invoice, error) {
+	if m.GetByOrderIDFunc != nil {
+		return m.GetByOrderIDFunc(ctx, orderID)
+	}
+	return nil, nil
+}
+
+func (m *MockInvoiceRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Invoice, error) {
+	return nil, nil
+}
+
+func (m *MockInvoiceRepository) Update(ctx context.Context, inv *models.Invoice) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, inv)
+	}
+	return nil
+}
+
+// MockCreditNoteRepository is a mock for CreditNoteRepository for unit tests (no DB).
+type MockCreditNoteRepository struct {
+	CreateFunc     func(ctx context.Context, n *models.CreditNote, maxTotal float64) (bool, error)
+	SumByOrderFunc func(ctx context.Context, orderID uuid.UUID) (float64, error)
+}
+
+func (m *MockCreditNoteRepository) Create(ctx context.Context, n *models.CreditNote, maxTotal float64) (bool, error) {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, n, maxTotal)
+	}
+	return true, nil
+}
+
+func (m *MockCreditNoteRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.CreditNote, error) {
+	return nil, nil
+}
+
+func (m *MockCreditNoteRepository) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]*models.CreditNote, error) {
+	return nil, nil
+}
+
+func (m *MockCreditNoteRepository) SumByOrder(ctx context.Context, orderID uuid.UUID) (float64, error) {
+	if m.SumByOrderFunc != nil {
+		return m.SumByOrderFunc(ctx, orderID)
+	}
+	return 0, nil
+}
+
+func (m *MockCreditNoteRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.CreditNote, int64, error) {
+	return nil, 0, nil
+}
+
+// MockStaffPointsConfigRepository is a mock for StaffPointsConfigRepository for unit tests (no DB).
+type MockStaffPointsConfigRepository struct {
+	Config *models.StaffPointsConfig
+}
+
+func (m *MockStaffPointsConfigRepository) GetByPharmacyID(ctx context.Context, pharmacyID uuid.UUID) (*models.StaffPointsConfig, error) {
+	return m.Config, nil
+}
+
+func (m *MockStaffPointsConfigRepository) GetOrCreateByPharmacyID(ctx context.Context, pharmacyID uuid.UUID) (*models.StaffPointsConfig, error) {
+	return m.Config, nil
+}
+
+func (m *MockStaffPointsConfigRepository) Update(ctx context.Context, c *models.StaffPointsConfig) error {
+	return nil
+}
+
+// MockStaffPointsTransactionRepository is a mock for StaffPointsTransactionRepository for unit tests (no DB).
+type MockStaffPointsTransactionRepository struct {
+	CreditFunc        func(ctx context.Context, t *models.StaffPointsTransaction) (bool, error)
+	MonthlyTotalsFunc func(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.StaffPointsMonthRow, error)
+}
+
+func (m *MockStaffPointsTransactionRepository) Credit(ctx context.Context, t *models.StaffPointsTransaction) (bool, error) {
+	if m.CreditFunc != nil {
+		return m.CreditFunc(ctx, t)
+	}
+	return true, nil
+}
+
+func (m *MockStaffPointsTransactionRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.StaffPointsTransaction, int64, error) {
+	return nil, 0, nil
+}
+
+func (m *MockStaffPointsTransactionRepository) MonthlyTotals(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.StaffPointsMonthRow, error) {
+	if m.MonthlyTotalsFunc != nil {
+		return m.MonthlyTotalsFunc(ctx, pharmacyID, from, to)
+	}
+	return nil, nil
+}
+
+// MockCustomerSegmentRepository is a mock for CustomerSegmentRepository for unit tests (no DB).
+type MockCustomerSegmentRepository struct {
+	GetByIDFunc func(ctx context.Context, id uuid.UUID) (*models.CustomerSegment, error)
+	MembersFunc func(ctx context.Context, pharmacyID uuid.UUID, c models.SegmentCriteria, limit int) ([]*models.SegmentMember, int64, error)
+}
+
+func (m *MockCustomerSegmentRepository) Create(ctx context.Context, s *models.CustomerSegment) error {
+	return nil
+}
+
+func (m *MockCustomerSegmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.CustomerSegment, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockCustomerSegmentRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.CustomerSegment, error) {
+	return nil, nil
+}
+
+func (m *MockCustomerSegmentRepository) Update(ctx context.Context, s *models.CustomerSegment) error {
+	return nil
+}
+
+func (m *MockCustomerSegmentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func (m *MockCustomerSegmentRepository) Members(ctx context.Context, pharmacyID uuid.UUID, c models.SegmentCriteria, limit int) ([]*models.SegmentMember, int64, error) {
+	if m.MembersFunc != nil {
+		return m.MembersFunc(ctx, pharmacyID, c, limit)
+	}
+	return nil, 0, nil
+}
+
+// MockCampaignRepository is a mock for CampaignRepository for unit tests (no DB).
+type MockCampaignRepository struct {
+	GetByIDFunc          func(ctx context.Context, id uuid.UUID) (*models.Campaign, error)
+	StartSendingFunc     func(ctx context.Context, c *models.Campaign) (bool, error)
+	RecordRecipientsFunc func(ctx context.Context, campaignID uuid.UUID, recipients []*models.CampaignRecipient) error
+	FinishSendingFunc    func(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+func (m *MockCampaignRepository) Create(ctx context.Context, c *models.Campaign) error {
+	return nil
+}
+
+func (m *MockCampaignRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Campaign, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockCampaignRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.Campaign, int64, error) {
+	return nil, 0, nil
+}
+
+func (m *MockCampaignRepository) Update(ctx context.Context, c *models.Campaign) error {
+	return nil
+}
+
+func (m *MockCampaignRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func (m *MockCampaignRepository) StartSending(ctx context.Context, c *models.Campaign) (bool, error) {
+	if m.StartSendingFunc != nil {
+		return m.StartSendingFunc(ctx, c)
+	}
+	return true, nil
+}
+
+func (m *MockCampaignRepository) RecordRecipients(ctx context.Context, campaignID uuid.UUID, recipients []*models.CampaignRecipient) error {
+	if m.RecordRecipientsFunc != nil {
+		return m.RecordRecipientsFunc(ctx, campaignID, recipients)
+	}
+	return nil
+}
+
+func (m *MockCampaignRepository) FinishSending(ctx context.Context, id uuid.UUID, at time.Time) error {
+	if m.FinishSendingFunc != nil {
+		return m.FinishSendingFunc(ctx, id, at)
+	}
+	return nil
+}
+
+func (m *MockCampaignRepository) ListRecipients(ctx context.Context, campaignID uuid.UUID, status string, limit, offset int) ([]*models.CampaignRecipient, int64, error) {
+	return nil, 0, nil
+}
+
+// MockActivityLogRepository is a mock for ActivityLogRepository for unit tests (no DB).
+type MockActivityLogRepository struct {
+	CreateFunc         func(ctx context.Context, a *models.ActivityLog) error
+	ListByPharmacyFunc func(ctx context.Context, pharmacyID uuid.UUID, filter outbound.ActivityLogFilter, limit, offset int) ([]*models.ActivityLog, error)
+	ListForExportFunc  func(ctx context.Context, pharmacyID uuid.UUID, filter outbound.ActivityLogFilter, limit int) ([]*models.ActivityLog, error)
+	DeleteBeforeFunc   func(ctx context.Context, pharmacyID uuid.UUID, cutoff time.Time) (int64, error)
+}
+
+func (m *MockActivityLogRepository) Create(ctx context.Context, a *models.ActivityLog) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, a)
+	}
+	return nil
+}
+
+func (m *MockActivityLogRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, filter outbound.ActivityLogFilter, limit, offset int) ([]*models.ActivityLog, error) {
+	if m.ListByPharmacyFunc != nil {
+		return m.ListByPharmacyFunc(ctx, pharmacyID, filter, limit, offset)
+	}
+	return nil, nil
+}
+
+func (m *MockActivityLogRepository) ListForExport(ctx context.Context, pharmacyID uuid.UUID, filter outbound.ActivityLogFilter, limit int) ([]*models.ActivityLog, error) {
+	if m.ListForExportFunc != nil {
+		return m.ListForExportFunc(ctx, pharmacyID, filter, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockActivityLogRepository) DeleteBefore(ctx context.Context, pharmacyID uuid.UUID, cutoff time.Time) (int64, error) {
+	if m.DeleteBeforeFunc != nil {
+		return m.DeleteBeforeFunc(ctx, pharmacyID, cutoff)
+	}
+	return 0, nil
+}
+
+// MockOutboxRepository is a mock for OutboxRepository for unit tests (no DB).
+type MockOutboxRepository struct {
+	AppendFunc                 func(ctx context.Context, events ...*models.OutboxEvent) error
+	ClaimDueFunc               func(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.OutboxEvent, error)
+	UpdateFunc                 func(ctx context.Context, e *models.OutboxEvent) error
+	DeleteDispatchedBeforeFunc func(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+func (m *MockOutboxRepository) Append(ctx context.Context, events ...*models.OutboxEvent) error {
+	if m.AppendFunc != nil {
+		return m.AppendFunc(ctx, events...)
+	}
+	return nil
+}
+
+func (m *MockOutboxRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.OutboxEvent, error) {
+	if m.ClaimDueFunc != nil {
+		return m.ClaimDueFunc(ctx, now, lease, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockOutboxRepository) Update(ctx context.Context, e *models.OutboxEvent) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, e)
+	}
+	return nil
+}
+
+func (m *MockOutboxRepository) DeleteDispatchedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	if m.DeleteDispatchedBeforeFunc != nil {
+		return m.DeleteDispatchedBeforeFunc(ctx, cutoff)
+	}
+	return 0, nil
+}
+
+// MockUnitOfWork runs fn without a database; Calls counts transactions and RolledBack those whose fn failed.
+type MockUnitOfWork struct {
+	Calls      int
+	RolledBack int
+}
+
+func (m *MockUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.Calls++
+	if err := fn(ctx); err != nil {
+		m.RolledBack++
+		return err
+	}
+	return nil
+}
+
+// MockIdempotencyKeyRepository implements outbound.IdempotencyKeyRepository for tests.
+type MockIdempotencyKeyRepository struct {
+	CreateFunc        func(ctx context.Context, k *models.IdempotencyKey) (bool, error)
+	GetFunc           func(ctx context.Context, userID uuid.UUID, scope, key string) (*models.IdempotencyKey, error)
+	UpdateFunc        func(ctx context.Context, k *models.IdempotencyKey) error
+	DeleteFunc        func(ctx context.Context, id uuid.UUID) error
+	DeleteExpiredFunc func(ctx context.Context, now time.Time) (int64, error)
+}
+
+func (m *MockIdempotencyKeyRepository) Create(ctx context.Context, k *models.IdempotencyKey) (bool, error) {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, k)
+	}
+	return true, nil
+}
+
+func (m *MockIdempotencyKeyRepository) Get(ctx context.Context, userID uuid.UUID, scope, key string) (*models.IdempotencyKey, error) {
+	if m.GetFunc != nil {
+		return m.GetFunc(ctx, userID, scope, key)
+	}
+	return nil, nil
+}
+
+func (m *MockIdempotencyKeyRepository) Update(ctx context.Context, k *models.IdempotencyKey) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, k)
+	}
+	return nil
+}
+
+func (m *MockIdempotencyKeyRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockIdempotencyKeyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	if m.DeleteExpiredFunc != nil {
+		return m.DeleteExpiredFunc(ctx, now)
+	}
+	return 0, nil
+}
+
+// MockDispatchBatchRepository implements outbound.DispatchBatchRepository for tests.
+type MockDispatchBatchRepository struct {
+	CreateFunc  func(ctx context.Context, b *models.DispatchBatch) error
+	GetByIDFunc func(ctx context.Context, id uuid.UUID) (*models.DispatchBatch, error)
+	ListFunc    func(ctx context.Context, pharmacyID uuid.UUID, riderID *uuid.UUID, limit, offset int) ([]*models.DispatchBatch, int64, error)
+}
+
+func (m *MockDispatchBatchRepository) Create(ctx context.Context, b *models.DispatchBatch) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, b)
+	}
+	return nil
+}
+
+func (m *MockDispatchBatchRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DispatchBatch, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockDispatchBatchRepository) List(ctx context.Context, pharmacyID uuid.UUID, riderID *uuid.UUID, limit, offset int) ([]*models.DispatchBatch, int64, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, pharmacyID, riderID, limit, offset)
+	}
+	return nil, 0, nil
+}
+
+// MockWalletTopUpRepository implements outbound.WalletTopUpRepository for tests.
+type MockWalletTopUpRepository struct {
+	CreateFunc  func(ctx context.Context, t *models.WalletTopUp) error
+	GetByIDFunc func(ctx context.Context, id uuid.UUID) (*models.WalletTopUp, error)
+	ListFunc    func(ctx context.Context, pharmacyID uuid.UUID, f outbound.WalletTopUpFilter, limit, offset int) ([]*models.WalletTopUp, int64, error)
+	SettleFunc  func(ctx context.Context, t *models.WalletTopUp) (bool, error)
+}
+
+func (m *MockWalletTopUpRepository) Create(ctx context.Context, t *models.WalletTopUp) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, t)
+	}
+	return nil
+}
+
+func (m *MockWalletTopUpRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.WalletTopUp, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockWalletTopUpRepository) List(ctx context.Context, pharmacyID uuid.UUID, f outbound.WalletTopUpFilter, limit, offset int) ([]*models.WalletTopUp, int64, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, pharmacyID, f, limit, offset)
+	}
+	return nil, 0, nil
+}
+
+func (m *MockWalletTopUpRepository) Settle(ctx context.Context, t *models.WalletTopUp) (bool, error) {
+	if m.SettleFunc != nil {
+		return m.SettleFunc(ctx, t)
+	}
+	return true, nil
+}
+
+// MockPaymentGatewayRepository implements outbound.PaymentGatewayRepository for tests.
+type MockPaymentGatewayRepository struct {
+	GetByIDFunc        func(ctx context.Context, id uuid.UUID) (*models.PaymentGateway, error)
+	ListByPharmacyFunc func(ctx context.Context, pharmacyID uuid.UUID, activeOnly bool) ([]*models.PaymentGateway, error)
+	CreateFunc         func(ctx context.Context, pg *models.PaymentGateway) error
+}
+
+func (m *MockPaymentGatewayRepository) Create(ctx context.Context, pg *models.PaymentGateway) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, pg)
+	}
+	return nil
+}
+
+func (m *MockPaymentGatewayRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PaymentGateway, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockPaymentGatewayRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, activeOnly bool) ([]*models.PaymentGateway, error) {
+	if m.ListByPharmacyFunc != nil {
+		return m.ListByPharmacyFunc(ctx, pharmacyID, activeOnly)
+	}
+	return nil, nil
+}
+
+func (m *MockPaymentGatewayRepository) Update(ctx context.Context, pg *models.PaymentGateway) error {
+	return nil
+}
+
+func (m *MockPaymentGatewayRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+// MockPointsTransactionRepository implements outbound.PointsTransactionRepository for tests.
+type MockPointsTransactionRepository struct {
+	CreateFunc func(ctx context.Context, p *models.PointsTransaction) error
+}
+
+func (m *MockPointsTransactionRepository) Create(ctx context.Context, p *models.PointsTransaction) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, p)
+	}
+	return nil
+}
+
+func (m *MockPointsTransactionRepository) ListByCustomer(ctx context.Context, customerID uuid.UUID, limit, offset int) ([]*models.PointsTransaction, error) {
+	return nil, nil
+}
+
+// MockClearanceRepository is a mock for ClearanceRepository for unit tests (no DB).
+type MockClearanceRepository struct {
+	CreateFunc         func(ctx context.Context, a *models.ClearanceAction) error
+	GetByIDFunc        func(ctx context.Context, id uuid.UUID) (*models.ClearanceAction, error)
+	ListFunc           func(ctx context.Context, pharmacyID uuid.UUID, f outbound.ClearanceFilter, limit, offset int) ([]*models.ClearanceAction, int64, error)
+	OpenProductIDsFunc func(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID) ([]uuid.UUID, error)
+	RecordReturnFunc   func(ctx context.Context, itemID uuid.UUID, quantity int, credit float64) (bool, error)
+	CloseFunc          func(ctx context.Context, a *models.ClearanceAction) (bool, error)
+	PromoRecoveryFunc  func(ctx context.Context, actionIDs []uuid.UUID) ([]*models.ClearanceRecoveryRow, error)
+}
+
+func (m *MockClearanceRepository) Create(ctx context.Context, a *models.ClearanceAction) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, a)
+	}
+	return nil
+}
+
+func (m *MockClearanceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ClearanceAction, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockClearanceRepository) List(ctx context.Context, pharmacyID uuid.UUID, f outbound.ClearanceFilter, limit, offset int) ([]*models.ClearanceAction, int64, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, pharmacyID, f, limit, offset)
+	}
+	return nil, 0, nil
+}
+
+func (m *MockClearanceRepository) OpenProductIDs(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if m.OpenProductIDsFunc != nil {
+		return m.OpenProductIDsFunc(ctx, pharmacyID, productIDs)
+	}
+	return nil, nil
+}
+
+func (m *MockClearanceRepository) RecordReturn(ctx context.Context, itemID uuid.UUID, quantity int, credit float64) (bool, error) {
+	if m.RecordReturnFunc != nil {
+		return m.RecordReturnFunc(ctx, itemID, quantity, credit)
+	}
+	return true, nil
+}
+
+func (m *MockClearanceRepository) Close(ctx context.Context, a *models.ClearanceAction) (bool, error) {
+	if m.CloseFunc != nil {
+		return m.CloseFunc(ctx, a)
+	}
+	return true, nil
+}
+
+func (m *MockClearanceRepository) PromoRecovery(ctx context.Context, actionIDs []uuid.UUID) ([]*models.ClearanceRecoveryRow, error) {
+	if m.PromoRecoveryFunc != nil {
+		return m.PromoRecoveryFunc(ctx, actionIDs)
+	}
+	return nil, nil
+}
+
+// MockSerialRepository is a mock for SerialRepository for unit tests (no DB).
+type MockSerialRepository struct {
+	CreateBatchFunc   func(ctx context.Context, serials []*models.ProductSerial) error
+	FindByNumbersFunc func(ctx context.Context, pharmacyID, productID uuid.UUID, numbers []string) ([]*models.ProductSerial, error)
+	CountInStockFunc  func(ctx context.Context, pharmacyID, productID uuid.UUID) (int64, error)
+	ListFunc          func(ctx context.Context, pharmacyID uuid.UUID, f outbound.SerialFilter, limit, offset int) ([]*models.ProductSerial, int64, error)
+	MarkSoldFunc      func(ctx context.Context, pharmacyID, productID uuid.UUID, numbers []string, orderID, orderItemID uuid.UUID, at time.Time) (int64, error)
+	ReleaseOrderFunc  func(ctx context.Context, orderID uuid.UUID) error
+}
+
+func (m *MockSerialRepository) CreateBatch(ctx context.Context, serials []*models.ProductSerial) error {
+	if m.CreateBatchFunc != nil {
+		return m.CreateBatchFunc(ctx, serials)
+	}
+	return nil
+}
+
+func (m *MockSerialRepository) FindByNumbers(ctx context.Context, pharmacyID, productID uuid.UUID, numbers []string) ([]*models.ProductSerial, error) {
+	if m.FindByNumbersFunc != nil {
+		return m.FindByNumbersFunc(ctx, pharmacyID, productID, numbers)
+	}
+	return nil, nil
+}
+
+func (m *MockSerialRepository) CountInStock(ctx context.Context, pharmacyID, productID uuid.UUID) (int64, error) {
+	if m.CountInStockFunc != nil {
+		return m.CountInStockFunc(ctx, pharmacyID, productID)
+	}
+	return 0, nil
+}
+
+func (m *MockSerialRepository) List(ctx context.Context, pharmacyID uuid.UUID, f outbound.SerialFilter, limit, offset int) ([]*models.ProductSerial, int64, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, pharmacyID, f, limit, offset)
+	}
+	return nil, 0, nil
+}
+
+func (m *MockSerialRepository) MarkSold(ctx context.Context, pharmacyID, productID uuid.UUID, numbers []string, orderID, orderItemID uuid.UUID, at time.Time) (int64, error) {
+	if m.MarkSoldFunc != nil {
+		return m.MarkSoldFunc(ctx, pharmacyID, productID, numbers, orderID, orderItemID, at)
+	}
+	return int64(len(numbers)), nil
+}
+
+func (m *MockSerialRepository) ReleaseOrder(ctx context.Context, orderID uuid.UUID) error {
+	if m.ReleaseOrderFunc != nil {
+		return m.ReleaseOrderFunc(ctx, orderID)
+	}
+	return nil
+}
+
+// MockWarrantyRepository is a mock for WarrantyRepository for unit tests (no DB).
+type MockWarrantyRepository struct {
+	CreateBatchFunc  func(ctx context.Context, warranties []*models.Warranty) error
+	GetByIDFunc      func(ctx context.Context, id uuid.UUID) (*models.Warranty, error)
+	FindBySerialFunc func(ctx context.Context, pharmacyID uuid.UUID, serialNumber string) ([]*models.Warranty, error)
+	VoidOrderFunc    func(ctx context.Context, orderID uuid.UUID, at time.Time) error
+	CreateClaimFunc  func(ctx context.Context, c *models.WarrantyClaim) error
+	GetClaimFunc     func(ctx context.Context, id uuid.UUID) (*models.WarrantyClaim, error)
+	ListClaimsFunc   func(ctx context.Context, pharmacyID uuid.UUID, status models.WarrantyClaimStatus, limit, offset int) ([]*models.WarrantyClaim, int64, error)
+	UpdateClaimFunc  func(ctx context.Context, c *models.WarrantyClaim, from models.WarrantyClaimStatus) (bool, error)
+}
+
+func (m *MockWarrantyRepository) CreateBatch(ctx context.Context, warranties []*models.Warranty) error {
+	if m.CreateBatchFunc != nil {
+		return m.CreateBatchFunc(ctx, warranties)
+	}
+	return nil
+}
+
+func (m *MockWarrantyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Warranty, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockWarrantyRepository) FindBySerial(ctx context.Context, pharmacyID uuid.UUID, serialNumber string) ([]*models.Warranty, error) {
+	if m.FindBySerialFunc != nil {
+		return m.FindBySerialFunc(ctx, pharmacyID, serialNumber)
+	}
+	return nil, nil
+}
+
+func (m *MockWarrantyRepository) VoidOrder(ctx context.Context, orderID uuid.UUID, at time.Time) error {
+	if m.VoidOrderFunc != nil {
+		return m.VoidOrderFunc(ctx, orderID, at)
+	}
+	return nil
+}
+
+func (m *MockWarrantyRepository) CreateClaim(ctx context.Context, c *models.WarrantyClaim) error {
+	if m.CreateClaimFunc != nil {
+		return m.CreateClaimFunc(ctx, c)
+	}
+	return nil
+}
+
+func (m *MockWarrantyRepository) GetClaim(ctx context.Context, id uuid.UUID) (*models.WarrantyClaim, error) {
+	if m.GetClaimFunc != nil {
+		return m.GetClaimFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockWarrantyRepository) ListClaims(ctx context.Context, pharmacyID uuid.UUID, status models.WarrantyClaimStatus, limit, offset int) ([]*models.WarrantyClaim, int64, error) {
+	if m.ListClaimsFunc != nil {
+		return m.ListClaimsFunc(ctx, pharmacyID, status, limit, offset)
+	}
+	return nil, 0, nil
+}
+
+func (m *MockWarrantyRepository) UpdateClaim(ctx context.Context, c *models.WarrantyClaim, from models.WarrantyClaimStatus) (bool, error) {
+	if m.UpdateClaimFunc != nil {
+		return m.UpdateClaimFunc(ctx, c, from)
+	}
+	return true, nil
+}
+
+// MockOrganizationRepository is a mock for OrganizationRepository for unit tests (no DB).
+type MockOrganizationRepository struct {
+	CreateFunc         func(ctx context.Context, o *models.Organization, owner *models.OrganizationMember, pharmacyID uuid.UUID) error
+	GetByIDFunc        func(ctx context.Context, id uuid.UUID) (*models.Organization, error)
+	GetByCodeFunc      func(ctx context.Context, code string) (*models.Organization, error)
+	GetByJoinCodeFunc  func(ctx context.Context, joinCode string) (*models.Organization, error)
+	ListByMemberFunc   func(ctx context.Context, userID uuid.UUID) ([]*models.Organization, error)
+	LinkPharmacyFunc   func(ctx context.Context, pharmacyID, organizationID uuid.UUID, groupCode string) error
+	UnlinkPharmacyFunc func(ctx context.Context, pharmacyID, organizationID uuid.UUID) error
+	GetMemberFunc      func(ctx context.Context, organizationID, userID uuid.UUID) (*models.OrganizationMember, error)
+	ListMembersFunc    func(ctx context.Context, organizationID uuid.UUID) ([]*models.OrganizationMember, error)
+	AddMemberFunc      func(ctx context.Context, m *models.OrganizationMember) error
+	RemoveMemberFunc   func(ctx context.Context, organizationID, userID uuid.UUID) error
+	SalesByPeriodFunc  func(ctx context.Context, pharmacyIDs []uuid.UUID, granularity string, from, to time.Time) ([]*models.SalesPeriodRow, error)
+	SalesByTenantFunc  func(ctx context.Context, pharmacyIDs []uuid.UUID, from, to time.Time) ([]*models.OrgTenantSalesRow, error)
+	TopProductsFunc    func(ctx context.Context, pharmacyIDs []uuid.UUID, from, to time.Time, limit int) ([]*models.OrgTopProductRow, error)
+	StockFunc          func(ctx context.Context, pharmacyIDs []uuid.UUID, query string, today time.Time, limit int) ([]*models.OrgStockRow, error)
+}
+
+func (m *MockOrganizationRepository) Create(ctx context.Context, o *models.Organization, owner *models.OrganizationMember, pharmacyID uuid.UUID) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, o, owner, pharmacyID)
+	}
+	return nil
+}
+
+func (m *MockOrganizationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Organization, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockOrganizationRepository) GetByCode(ctx context.Context, code string) (*models.Organization, error) {
+	if m.GetByCodeFunc != nil {
+		return m.GetByCodeFunc(ctx, code)
+	}
+	return nil, nil
+}
+
+func (m *MockOrganizationRepository) GetByJoinCode(ctx context.Context, joinCode string) (*models.Organization, error) {
+	if m.GetByJoinCodeFunc != nil {
+		return m.GetByJoinCodeFunc(ctx, joinCode)
+	}
+	return nil, nil
+}
+
+func (m *MockOrganizationRepository) ListByMember(ctx context.Context, userID uuid.UUID) ([]*models.Organization, error) {
+	if m.ListByMemberFunc != nil {
+		return m.ListByMemberFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *MockOrganizationRepository) LinkPharmacy(ctx context.Context, pharmacyID, organizationID uuid.UUID, groupCode string) error {
+	if m.LinkPharmacyFunc != nil {
+		return m.LinkPharmacyFunc(ctx, pharmacyID, organizationID, groupCode)
+	}
+	return nil
+}
+
+func (m *MockOrganizationRepository) UnlinkPharmacy(ctx context.Context, pharmacyID, organizationID uuid.UUID) error {
+	if m.UnlinkPharmacyFunc != nil {
+		return m.UnlinkPharmacyFunc(ctx, pharmacyID, organizationID)
+	}
+	return nil
+}
+
+func (m *MockOrganizationRepository) GetMember(ctx context.Context, organizationID, userID uuid.UUID) (*models.OrganizationMember, error) {
+	if m.GetMemberFunc != nil {
+		return m.GetMemberFunc(ctx, organizationID, userID)
+	}
+	return nil, nil
+}
+
+func (m *MockOrganizationRepository) ListMembers(ctx context.Context, organizationID uuid.UUID) ([]*models.OrganizationMember, error) {
+	if m.ListMembersFunc != nil {
+		return m.ListMembersFunc(ctx, organizationID)
+	}
+	return nil, nil
+}
+
+func (m *MockOrganizationRepository) AddMember(ctx context.Context, member *models.OrganizationMember) error {
+	if m.AddMemberFunc != nil {
+		return m.AddMemberFunc(ctx, member)
+	}
+	return nil
+}
+
+func (m *MockOrganizationRepository) RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error {
+	if m.RemoveMemberFunc != nil {
+		return m.RemoveMemberFunc(ctx, organizationID, userID)
+	}
+	return nil
+}
+
+func (m *MockOrganizationRepository) SalesByPeriod(ctx context.Context, pharmacyIDs []uuid.UUID, granularity string, from, to time.Time) ([]*models.SalesPeriodRow, error) {
+	if m.SalesByPeriodFunc != nil {
+		return m.SalesByPeriodFunc(ctx, pharmacyIDs, granularity, from, to)
+	}
+	return nil, nil
+}
+
+func (m *MockOrganizationRepository) SalesByTenant(ctx context.Context, pharmacyIDs []uuid.UUID, from, to time.Time) ([]*models.OrgTenantSalesRow, error) {
+	if m.SalesByTenantFunc != nil {
+		return m.SalesByTenantFunc(ctx, pharmacyIDs, from, to)
+	}
+	return nil, nil
+}
+
+func (m *MockOrganizationRepository) TopProducts(ctx context.Context, pharmacyIDs []uuid.UUID, from, to time.Time, limit int) ([]*models.OrgTopProductRow, error) {
+	if m.TopProductsFunc != nil {
+		return m.TopProductsFunc(ctx, pharmacyIDs, from, to, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockOrganizationRepository) Stock(ctx context.Context, pharmacyIDs []uuid.UUID, query string, today time.Time, limit int) ([]*models.OrgStockRow, error) {
+	if m.StockFunc != nil {
+		return m.StockFunc(ctx, pharmacyIDs, query, today, limit)
+	}
+	return nil, nil
+}
+
+// MockConsentRepository is a mock for ConsentRepository for unit tests (no DB).
+type MockConsentRepository struct {
+	CreateFunc  func(ctx context.Context, r *models.ConsentRecord) error
+	CurrentFunc func(ctx context.Context, customerID uuid.UUID) ([]*models.ConsentRecord, error)
+	HistoryFunc func(ctx context.Context, customerID uuid.UUID, limit int) ([]*models.ConsentRecord, error)
+	OptedInFunc func(ctx context.Context, pharmacyID uuid.UUID, channel models.ConsentChannel, customerIDs []uuid.UUID) ([]uuid.UUID, error)
+	ListFunc    func(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.ConsentRecord, error)
+}
+
+func (m *MockConsentRepository) Create(ctx context.Context, r *models.ConsentRecord) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, r)
+	}
+	return nil
+}
+
+func (m *MockConsentRepository) Current(ctx context.Context, customerID uuid.UUID) ([]*models.ConsentRecord, error) {
+	if m.CurrentFunc != nil {
+		return m.CurrentFunc(ctx, customerID)
+	}
+	return nil, nil
+}
+
+func (m *MockConsentRepository) History(ctx context.Context, customerID uuid.UUID, limit int) ([]*models.ConsentRecord, error) {
+	if m.HistoryFunc != nil {
+		return m.HistoryFunc(ctx, customerID, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockConsentRepository) OptedIn(ctx context.Context, pharmacyID uuid.UUID, channel models.ConsentChannel, customerIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if m.OptedInFunc != nil {
+		return m.OptedInFunc(ctx, pharmacyID, channel, customerIDs)
+	}
+	return nil, nil
+}
+
+func (m *MockConsentRepository) List(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.ConsentRecord, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, pharmacyID, from, to)
+	}
+	return nil, nil
+}
+
+// MockETARepository is a mock for ETARepository for unit tests (no DB).
+type MockETARepository struct {
+	StatsFunc        func(ctx context.Context, pharmacyID uuid.UUID, since time.Time) (*models.ETAStats, error)
+	OpenOrdersFunc   func(ctx context.Context, pharmacyID uuid.UUID, limit int) ([]*models.Order, error)
+	OrderVolumeFunc  func(ctx context.Context, pharmacyID uuid.UUID, at time.Time, window time.Duration, days int) (int64, int64, error)
+	SetEstimatesFunc func(ctx context.Context, orderID uuid.UUID, readyAt, deliveryAt *time.Time) error
+}
+
+func (m *MockETARepository) Stats(ctx context.Context, pharmacyID uuid.UUID, since time.Time) (*models.ETAStats, error) {
+	if m.StatsFunc != nil {
+		return m.StatsFunc(ctx, pharmacyID, since)
+	}
+	return &models.ETAStats{}, nil
+}
+
+func (m *MockETARepository) OpenOrders(ctx context.Context, pharmacyID uuid.UUID, limit int) ([]*models.Order, error) {
+	if m.OpenOrdersFunc != nil {
+		return m.OpenOrdersFunc(ctx, pharmacyID, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockETARepository) OrderVolume(ctx context.Context, pharmacyID uuid.UUID, at time.Time, window time.Duration, days int) (int64, int64, error) {
+	if m.OrderVolumeFunc != nil {
+		return m.OrderVolumeFunc(ctx, pharmacyID, at, window, days)
+	}
+	return 0, 0, nil
+}
+
+func (m *MockETARepository) SetEstimates(ctx context.Context, orderID uuid.UUID, readyAt, deliveryAt *time.Time) error {
+	if m.SetEstimatesFunc != nil {
+		return m.SetEstimatesFunc(ctx, orderID, readyAt, deliveryAt)
+	}
+	return nil
+}
+
+// MockInventoryEvidenceRepository is a mock for InventoryEvidenceRepository for unit tests (no DB).
+type MockInventoryEvidenceRepository struct {
+	CreateAdjustmentFunc func(ctx context.Context, a *models.StockAdjustment) error
+	GetAdjustmentFunc    func(ctx context.Context, id uuid.UUID) (*models.StockAdjustment, error)
+	ListAdjustmentsFunc  func(ctx context.Context, batchID uuid.UUID) ([]*models.StockAdjustment, error)
+	CreatePhotoFunc      func(ctx context.Context, p *models.InventoryPhoto) error
+	ListPhotosFunc       func(ctx context.Context, batchID uuid.UUID) ([]*models.InventoryPhoto, error)
+	GetPhotosFunc        func(ctx context.Context, pharmacyID uuid.UUID, ids []uuid.UUID) ([]*models.InventoryPhoto, error)
+	LinkPhotosFunc       func(ctx context.Context, ids []uuid.UUID, actionID uuid.UUID) error
+}
+
+func (m *MockInventoryEvidenceRepository) CreateAdjustment(ctx context.Context, a *models.StockAdjustment) error {
+	if m.CreateAdjustmentFunc != nil {
+		return m.CreateAdjustmentFunc(ctx, a)
+	}
+	return nil
+}
+
+func (m *MockInventoryEvidenceRepository) GetAdjustment(ctx context.Context, id uuid.UUID) (*models.StockAdjustment, error) {
+	if m.GetAdjustmentFunc != nil {
+		return m.GetAdjustmentFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockInventoryEvidenceRepository) ListAdjustments(ctx context.Context, batchID uuid.UUID) ([]*models.StockAdjustment, error) {
+	if m.ListAdjustmentsFunc != nil {
+		return m.ListAdjustmentsFunc(ctx, batchID)
+	}
+	return nil, nil
+}
+
+func (m *MockInventoryEvidenceRepository) CreatePhoto(ctx context.Context, p *models.InventoryPhoto) error {
+	if m.CreatePhotoFunc != nil {
+		return m.CreatePhotoFunc(ctx, p)
+	}
+	return nil
+}
+
+func (m *MockInventoryEvidenceRepository) ListPhotos(ctx context.Context, batchID uuid.UUID) ([]*models.InventoryPhoto, error) {
+	if m.ListPhotosFunc != nil {
+		return m.ListPhotosFunc(ctx, batchID)
+	}
+	return nil, nil
+}
+
+func (m *MockInventoryEvidenceRepository) GetPhotos(ctx context.Context, pharmacyID uuid.UUID, ids []uuid.UUID) ([]*models.InventoryPhoto, error) {
+	if m.GetPhotosFunc != nil {
+		return m.GetPhotosFunc(ctx, pharmacyID, ids)
+	}
+	return nil, nil
+}
+
+func (m *MockInventoryEvidenceRepository) LinkPhotos(ctx context.Context, ids []uuid.UUID, actionID uuid.UUID) error {
+	if m.LinkPhotosFunc != nil {
+		return m.LinkPhotosFunc(ctx, ids, actionID)
+	}
+	return nil
+}
+
+// MockAPIUsageRepository is a mock for APIUsageRepository for unit tests (no DB).
+type MockAPIUsageRepository struct {
+	AddFunc  func(ctx context.Context, rows []*models.APIUsageDaily) error
+	ListFunc func(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.APIUsageDaily, error)
+}
+
+func (m *MockAPIUsageRepository) Add(ctx context.Context, rows []*models.APIUsageDaily) error {
+	if m.AddFunc != nil {
+		return m.AddFunc(ctx, rows)
+	}
+	return nil
+}
+
+func (m *MockAPIUsageRepository) List(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.APIUsageDaily, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, pharmacyID, from, to)
+	}
+	return nil, nil
+}
+
+// MockDrugInfoRepository is a mock for DrugInfoRepository for unit tests (no DB).
+type MockDrugInfoRepository struct {
+	ReplaceIngredientsFunc func(ctx context.Context, productID uuid.UUID, rows []*models.ProductIngredient) error
+	ListIngredientsFunc    func(ctx context.Context, productIDs []uuid.UUID) ([]*models.ProductIngredient, error)
+	CreateInteractionFunc  func(ctx context.Context, rule *models.DrugInteraction) error
+	ListInteractionsFunc   func(ctx context.Context, pharmacyID uuid.UUID) ([]*models.DrugInteraction, error)
+	FindInteractionsFunc   func(ctx context.Context, pharmacyID uuid.UUID, ingredients []string) ([]*models.DrugInteraction, error)
+	DeleteInteractionFunc  func(ctx context.Context, pharmacyID, id uuid.UUID) (bool, error)
+}
+
+func (m *MockDrugInfoRepository) ReplaceIngredients(ctx context.Context, productID uuid.UUID, rows []*models.ProductIngredient) error {
+	if m.ReplaceIngredientsFunc != nil {
+		return m.ReplaceIngredientsFunc(ctx, productID, rows)
+	}
+	return nil
+}
+
+func (m *MockDrugInfoRepository) ListIngredients(ctx context.Context, productIDs []uuid.UUID) ([]*models.ProductIngredient, error) {
+	if m.ListIngredientsFunc != nil {
+		return m.ListIngredientsFunc(ctx, productIDs)
+	}
+	return nil, nil
+}
+
+func (m *MockDrugInfoRepository) CreateInteraction(ctx context.Context, rule *models.DrugInteraction) error {
+	if m.CreateInteractionFunc != nil {
+		return m.CreateInteractionFunc(ctx, rule)
+	}
+	return nil
+}
+
+func (m *MockDrugInfoRepository) ListInteractions(ctx context.Context, pharmacyID uuid.UUID) ([]*models.DrugInteraction, error) {
+	if m.ListInteractionsFunc != nil {
+		return m.ListInteractionsFunc(ctx, pharmacyID)
+	}
+	return nil, nil
+}
+
+func (m *MockDrugInfoRepository) FindInteractions(ctx context.Context, pharmacyID uuid.UUID, ingredients []string) ([]*models.DrugInteraction, error) {
+	if m.FindInteractionsFunc != nil {
+		return m.FindInteractionsFunc(ctx, pharmacyID, ingredients)
+	}
+	return nil, nil
+}
+
+func (m *MockDrugInfoRepository) DeleteInteraction(ctx context.Context, pharmacyID, id uuid.UUID) (bool, error) {
+	if m.DeleteInteractionFunc != nil {
+		return m.DeleteInteractionFunc(ctx, pharmacyID, id)
+	}
+	return false, nil
+}
+
+// MockExportRequestRepository is a mock for ExportRequestRepository for unit tests (no DB).
+type MockExportRequestRepository struct {
+	CreateFunc  func(ctx context.Context, r *models.ExportRequest) error
+	GetByIDFunc func(ctx context.Context, id uuid.UUID) (*models.ExportRequest, error)
+	ListFunc    func(ctx context.Context, pharmacyID uuid.UUID, requestedBy *uuid.UUID, status string, limit int) ([]*models.ExportRequest, error)
+	UpdateFunc  func(ctx context.Context, r *models.ExportRequest) error
+	ConsumeFunc func(ctx context.Context, id uuid.UUID, now time.Time) (bool, error)
+}
+
+func (m *MockExportRequestRepository) Create(ctx context.Context, r *models.ExportRequest) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, r)
+	}
+	return nil
+}
+
+func (m *MockExportRequestRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ExportRequest, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockExportRequestRepository) List(ctx context.Context, pharmacyID uuid.UUID, requestedBy *uuid.UUID, status string, limit int) ([]*models.ExportRequest, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, pharmacyID, requestedBy, status, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockExportRequestRepository) Update(ctx context.Context, r *models.ExportRequest) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, r)
+	}
+	return nil
+}
+
+func (m *MockExportRequestRepository) Consume(ctx context.Context, id uuid.UUID, now time.Time) (bool, error) {
+	if m.ConsumeFunc != nil {
+		return m.ConsumeFunc(ctx, id, now)
+	}
+	return false, nil
+}
+
+type MockWishlistRepository struct {
+	AddFunc         func(ctx context.Context, w *models.WishlistItem) error
+	RemoveFunc      func(ctx context.Context, userID, productID uuid.UUID) (bool, error)
+	ListByUserFunc  func(ctx context.Context, pharmacyID, userID uuid.UUID) ([]*models.WishlistItem, error)
+	ListChangedFunc func(ctx context.Context, limit int) ([]*models.WishlistItem, error)
+	SetSeenFunc     func(ctx context.Context, id uuid.UUID, inStock bool, price float64, notifiedAt *time.Time) error
+}
+
+func (m *MockWishlistRepository) Add(ctx context.Context, w *models.WishlistItem) error {
+	if m.AddFunc != nil {
+		return m.AddFunc(ctx, w)
+	}
+	return nil
+}
+
+func (m *MockWishlistRepository) Remove(ctx context.Context, userID, productID uuid.UUID) (bool, error) {
+	if m.RemoveFunc != nil {
+		return m.RemoveFunc(ctx, userID, productID)
+	}
+	return false, nil
+}
+
+func (m *MockWishlistRepository) ListByUser(ctx context.Context, pharmacyID, userID uuid.UUID) ([]*models.WishlistItem, error) {
+	if m.ListByUserFunc != nil {
+		return m.ListByUserFunc(ctx, pharmacyID, userID)
+	}
+	return nil, nil
+}
+
+func (m *MockWishlistRepository) ListChanged(ctx context.Context, limit int) ([]*models.WishlistItem, error) {
+	if m.ListChangedFunc != nil {
+		return m.ListChangedFunc(ctx, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockWishlistRepository) SetSeen(ctx context.Context, id uuid.UUID, inStock bool, price float64, notifiedAt *time.Time) error {
+	if m.SetSeenFunc != nil {
+		return m.SetSeenFunc(ctx, id, inStock, price, notifiedAt)
+	}
+	return nil
+}
+
+type MockStockSubscriptionRepository struct {
+	CreateFunc        func(ctx context.Context, s *models.StockSubscription) error
+	FindPendingFunc   func(ctx context.Context, productID uuid.UUID, userID *uuid.UUID, phone, email string) (*models.StockSubscription, error)
+	ListByProductFunc func(ctx context.Context, pharmacyID, productID uuid.UUID, status string) ([]*models.StockSubscription, error)
+	ListDueFunc       func(ctx context.Context, limit int) ([]*models.StockSubscription, error)
+	MarkNotifiedFunc  func(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+}
+
+func (m *MockStockSubscriptionRepository) Create(ctx context.Context, s *models.StockSubscription) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, s)
+	}
+	return nil
+}
+
+func (m *MockStockSubscriptionRepository) FindPending(ctx context.Context, productID uuid.UUID, userID *uuid.UUID, phone, email string) (*models.StockSubscription, error) {
+	if m.FindPendingFunc != nil {
+		return m.FindPendingFunc(ctx, productID, userID, phone, email)
+	}
+	return nil, nil
+}
+
+func (m *MockStockSubscriptionRepository) ListByProduct(ctx context.Context, pharmacyID, productID uuid.UUID, status string) ([]*models.StockSubscription, error) {
+	if m.ListByProductFunc != nil {
+		return m.ListByProductFunc(ctx, pharmacyID, productID, status)
+	}
+	return nil, nil
+}
+
+func (m *MockStockSubscriptionRepository) ListDue(ctx context.Context, limit int) ([]*models.StockSubscription, error) {
+	if m.ListDueFunc != nil {
+		return m.ListDueFunc(ctx, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockStockSubscriptionRepository) MarkNotified(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	if m.MarkNotifiedFunc != nil {
+		return m.MarkNotifiedFunc(ctx, id, at)
+	}
+	return false, nil
+}
+
+type MockRecommendationRepository struct {
+	TouchViewFunc         func(ctx context.Context, v *models.RecentView) error
+	PruneViewsFunc        func(ctx context.Context, viewer string, keep int) error
+	ListViewsFunc         func(ctx context.Context, pharmacyID uuid.UUID, viewer string, limit int) ([]*models.RecentView, error)
+	DeleteViewsBeforeFunc func(ctx context.Context, before time.Time) (int64, error)
+	BoughtTogetherFunc    func(ctx context.Context, pharmacyID uuid.UUID, productIDs, exclude []uuid.UUID, since time.Time, limit int) ([]*models.ProductScore, error)
+	SameCategoryFunc      func(ctx context.Context, pharmacyID uuid.UUID, categories []string, exclude []uuid.UUID, since time.Time, limit int) ([]*models.Product, error)
+}
+
+func (m *MockRecommendationRepository) TouchView(ctx context.Context, v *models.RecentView) error {
+	if m.TouchViewFunc != nil {
+		return m.TouchViewFunc(ctx, v)
+	}
+	return nil
+}
+
+func (m *MockRecommendationRepository) PruneViews(ctx context.Context, viewer string, keep int) error {
+	if m.PruneViewsFunc != nil {
+		return m.PruneViewsFunc(ctx, viewer, keep)
+	}
+	return nil
+}
+
+func (m *MockRecommendationRepository) ListViews(ctx context.Context, pharmacyID uuid.UUID, viewer string, limit int) ([]*models.RecentView, error) {
+	if m.ListViewsFunc != nil {
+		return m.ListViewsFunc(ctx, pharmacyID, viewer, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockRecommendationRepository) DeleteViewsBefore(ctx context.Context, before time.Time) (int64, error) {
+	if m.DeleteViewsBeforeFunc != nil {
+		return m.DeleteViewsBeforeFunc(ctx, before)
+	}
+	return 0, nil
+}
+
+func (m *MockRecommendationRepository) BoughtTogether(ctx context.Context, pharmacyID uuid.UUID, productIDs, exclude []uuid.UUID, since time.Time, limit int) ([]*models.ProductScore, error) {
+	if m.BoughtTogetherFunc != nil {
+		return m.BoughtTogetherFunc(ctx, pharmacyID, productIDs, exclude, since, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockRecommendationRepository) SameCategory(ctx context.Context, pharmacyID uuid.UUID, categories []string, exclude []uuid.UUID, since time.Time, limit int) ([]*models.Product, error) {
+	if m.SameCategoryFunc != nil {
+		return m.SameCategoryFunc(ctx, pharmacyID, categories, exclude, since, limit)
+	}
+	return nil, nil
+}
+
+// MockLoginChallengeRepository is a mock for LoginChallengeRepository for unit tests (no DB).
+type MockLoginChallengeRepository struct {
+	CreateFunc         func(ctx context.Context, c *models.LoginChallenge) error
+	GetByTokenHashFunc func(ctx context.Context, tokenHash string) (*models.LoginChallenge, error)
+	CountSinceFunc     func(ctx context.Context, userID uuid.UUID, since time.Time) (int64, error)
+	UpdateFunc         func(ctx context.Context, c *models.LoginChallenge) error
+}
+
+func (m *MockLoginChallengeRepository) Create(ctx context.Context, c *models.LoginChallenge) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, c)
+	}
+	return nil
+}
+
+func (m *MockLoginChallengeRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.LoginChallenge, error) {
+	if m.GetByTokenHashFunc != nil {
+		return m.GetByTokenHashFunc(ctx, tokenHash)
+	}
+	return nil, nil
+}
+
+func (m *MockLoginChallengeRepository) CountSince(ctx context.Context, userID uuid.UUID, since time.Time) (int64, error) {
+	if m.CountSinceFunc != nil {
+		return m.CountSinceFunc(ctx, userID, since)
+	}
+	return 0, nil
+}
+
+func (m *MockLoginChallengeRepository) Update(ctx context.Context, c *models.LoginChallenge) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, c)
+	}
+	return nil
+}
+
+// MockContentReportRepository is a mock for ContentReportRepository for unit tests (no DB).
+type MockContentReportRepository struct {
+	CreateFunc           func(ctx context.Context, r *models.ContentReport) error
+	ExistsByReporterFunc func(ctx context.Context, reporterID uuid.UUID, targetType string, targetID uuid.UUID) (bool, error)
+	GetByIDFunc          func(ctx context.Context, id uuid.UUID) (*models.ContentReport, error)
+	ListByPharmacyFunc   func(ctx context.Context, pharmacyID uuid.UUID, filter outbound.ContentReportFilter, limit, offset int) ([]*models.ContentReport, int64, error)
+	ResolveTargetFunc    func(ctx context.Context, pharmacyID uuid.UUID, targetType string, targetID uuid.UUID, action, note string, resolvedBy uuid.UUID, at time.Time) (int64, error)
+}
+
+func (m *MockContentReportRepository) Create(ctx context.Context, r *models.ContentReport) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, r)
+	}
+	return nil
+}
+
+func (m *MockContentReportRepository) ExistsByReporter(ctx context.Context, reporterID uuid.UUID, targetType string, targetID uuid.UUID) (bool, error) {
+	if m.ExistsByReporterFunc != nil {
+		return m.ExistsByReporterFunc(ctx, reporterID, targetType, targetID)
+	}
+	return false, nil
+}
+
+func (m *MockContentReportRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ContentReport, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockContentReportRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, filter outbound.ContentReportFilter, limit, offset int) ([]*models.ContentReport, int64, error) {
+	if m.ListByPharmacyFunc != nil {
+		return m.ListByPharmacyFunc(ctx, pharmacyID, filter, limit, offset)
+	}
+	return nil, 0, nil
+}
+
+func (m *MockContentReportRepository) ResolveTarget(ctx context.Context, pharmacyID uuid.UUID, targetType string, targetID uuid.UUID, action, note string, resolvedBy uuid.UUID, at time.Time) (int64, error) {
+	if m.ResolveTargetFunc != nil {
+		return m.ResolveTargetFunc(ctx, pharmacyID, targetType, targetID, action, note, resolvedBy, at)
+	}
+	return 0, nil
+}
+
+// MockSitemapRepository is a mock for SitemapRepository for unit tests (no DB).
+type MockSitemapRepository struct {
+	GetFunc         func(ctx context.Context, pharmacyID uuid.UUID) (*models.StorefrontSitemap, error)
+	SaveFunc        func(ctx context.Context, s *models.StorefrontSitemap) error
+	FingerprintFunc func(ctx context.Context, pharmacyID uuid.UUID) (string, error)
+	SourcesFunc     func(ctx context.Context, pharmacyID uuid.UUID, limit int) ([]*models.SitemapSource, error)
+}
+
+func (m *MockSitemapRepository) Get(ctx context.Context, pharmacyID uuid.UUID) (*models.StorefrontSitemap, error) {
+	if m.GetFunc != nil {
+		return m.GetFunc(ctx, pharmacyID)
+	}
+	return nil, nil
+}
+
+func (m *MockSitemapRepository) Save(ctx context.Context, s *models.StorefrontSitemap) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, s)
+	}
+	return nil
+}
+
+func (m *MockSitemapRepository) Fingerprint(ctx context.Context, pharmacyID uuid.UUID) (string, error) {
+	if m.FingerprintFunc != nil {
+		return m.FingerprintFunc(ctx, pharmacyID)
+	}
+	return "", nil
+}
+
+func (m *MockSitemapRepository) Sources(ctx context.Context, pharmacyID uuid.UUID, limit int) ([]*models.SitemapSource, error) {
+	if m.SourcesFunc != nil {
+		return m.SourcesFunc(ctx, pharmacyID, limit)
+	}
+	return nil, nil
+}
+
+// MockUploadRepository is a mock for UploadRepository for unit tests (no DB).
+type MockUploadRepository struct {
+	CreateFunc      func(ctx context.Context, u *models.Upload) error
+	GetByIDFunc     func(ctx context.Context, id uuid.UUID) (*models.Upload, error)
+	UpdateFunc      func(ctx context.Context, u *models.Upload) error
+	DeleteFunc      func(ctx context.Context, id uuid.UUID) error
+	AdvanceFunc     func(ctx context.Context, id uuid.UUID, from, to int64) (bool, error)
+	UsageBytesFunc  func(ctx context.Context, pharmacyID uuid.UUID) (int64, error)
+	ListExpiredFunc func(ctx context.Context, before time.Time, limit int) ([]*models.Upload, error)
+}
+
+func (m *MockUploadRepository) Create(ctx context.Context, u *models.Upload) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, u)
+	}
+	return nil
+}
+
+func (m *MockUploadRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Upload, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockUploadRepository) Update(ctx context.Context, u *models.Upload) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, u)
+	}
+	return nil
+}
+
+func (m *MockUploadRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockUploadRepository) Advance(ctx context.Context, id uuid.UUID, from, to int64) (bool, error) {
+	if m.AdvanceFunc != nil {
+		return m.AdvanceFunc(ctx, id, from, to)
+	}
+	return false, nil
+}
+
+func (m *MockUploadRepository) UsageBytes(ctx context.Context, pharmacyID uuid.UUID) (int64, error) {
+	if m.UsageBytesFunc != nil {
+		return m.UsageBytesFunc(ctx, pharmacyID)
+	}
+	return 0, nil
+}
+
+func (m *MockUploadRepository) ListExpired(ctx context.Context, before time.Time, limit int) ([]*models.Upload, error) {
+	if m.ListExpiredFunc != nil {
+		return m.ListExpiredFunc(ctx, before, limit)
+	}
+	return nil, nil
+}
+
+// MockFileReferenceRepository is a mock for FileReferenceRepository for unit tests (no DB).
+type MockFileReferenceRepository struct {
+	RecordFunc              func(ctx context.Context, f *models.FileReference) error
+	ReleaseFunc             func(ctx context.Context, urls []string, at time.Time) error
+	ListSweepCandidatesFunc func(ctx context.Context, createdBefore time.Time, limit int) ([]*models.FileReference, error)
+	ReferencedFunc          func(ctx context.Context, urls []string) (map[string]bool, error)
+	MarkCheckedFunc         func(ctx context.Context, ids []uuid.UUID, at time.Time) error
+	DeleteFunc              func(ctx context.Context, id uuid.UUID) error
+}
+
+func (m *MockFileReferenceRepository) Record(ctx context.Context, f *models.FileReference) error {
+	if m.RecordFunc != nil {
+		return m.RecordFunc(ctx, f)
+	}
+	return nil
+}
+
+func (m *MockFileReferenceRepository) Release(ctx context.Context, urls []string, at time.Time) error {
+	if m.ReleaseFunc != nil {
+		return m.ReleaseFunc(ctx, urls, at)
+	}
+	return nil
+}
+
+func (m *MockFileReferenceRepository) ListSweepCandidates(ctx context.Context, createdBefore time.Time, limit int) ([]*models.FileReference, error) {
+	if m.ListSweepCandidatesFunc != nil {
+		return m.ListSweepCandidatesFunc(ctx, createdBefore, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockFileReferenceRepository) Referenced(ctx context.Context, urls []string) (map[string]bool, error) {
+	if m.ReferencedFunc != nil {
+		return m.ReferencedFunc(ctx, urls)
+	}
+	return map[string]bool{}, nil
+}
+
+func (m *MockFileReferenceRepository) MarkChecked(ctx context.Context, ids []uuid.UUID, at time.Time) error {
+	if m.MarkCheckedFunc != nil {
+		return m.MarkCheckedFunc(ctx, ids, at)
+	}
+	return nil
+}
+
+func (m *MockFileReferenceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, id)
+	}
+	return nil
+}
+
+// MockSubscriptionRepository is a mock for SubscriptionRepository for unit tests (no DB).
+type MockSubscriptionRepository struct {
+	GetByPharmacyIDFunc func(ctx context.Context, pharmacyID uuid.UUID) (*models.Subscription, error)
+	SaveFunc            func(ctx context.Context, s *models.Subscription) error
+	UsageFunc           func(ctx context.Context, pharmacyID uuid.UUID) (*models.SubscriptionUsage, error)
+}
+
+func (m *MockSubscriptionRepository) GetByPharmacyID(ctx context.Context, pharmacyID uuid.UUID) (*models.Subscription, error) {
+	if m.GetByPharmacyIDFunc != nil {
+		return m.GetByPharmacyIDFunc(ctx, pharmacyID)
+	}
+	return nil, nil
+}
+
+func (m *MockSubscriptionRepository) Save(ctx context.Context, s *models.Subscription) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, s)
+	}
+	return nil
+}
+
+func (m *MockSubscriptionRepository) Usage(ctx context.Context, pharmacyID uuid.UUID) (*models.SubscriptionUsage, error) {
+	if m.UsageFunc != nil {
+		return m.UsageFunc(ctx, pharmacyID)
+	}
+	return &models.SubscriptionUsage{}, nil
+}
+
+// MockExchangeRateRepository is a mock for ExchangeRateRepository for unit tests (no DB).
+type MockExchangeRateRepository struct {
+	ListByPharmacyFunc func(ctx context.Context, pharmacyID uuid.UUID) ([]*models.ExchangeRate, error)
+	GetFunc            func(ctx context.Context, pharmacyID uuid.UUID, currency string) (*models.ExchangeRate, error)
+	SaveFunc           func(ctx context.Context, r *models.ExchangeRate) error
+	DeleteFunc         func(ctx context.Context, pharmacyID uuid.UUID, currency string) error
+}
+
+func (m *MockExchangeRateRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.ExchangeRate, error) {
+	if m.ListByPharmacyFunc != nil {
+		return m.ListByPharmacyFunc(ctx, pharmacyID)
+	}
+	return nil, nil
+}
+
+func (m *MockExchangeRateRepository) Get(ctx context.Context, pharmacyID uuid.UUID, currency string) (*models.ExchangeRate, error) {
+	if m.GetFunc != nil {
+		return m.GetFunc(ctx, pharmacyID, currency)
+	}
+	return nil, nil
+}
+
+func (m *MockExchangeRateRepository) Save(ctx context.Context, r *models.ExchangeRate) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(ctx, r)
+	}
+	return nil
+}
+
+func (m *MockExchangeRateRepository) Delete(ctx context.Context, pharmacyID uuid.UUID, currency string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, pharmacyID, currency)
+	}
+	return nil
+}
+
+// MockPreorderRepository implements outbound.PreorderRepository for tests.
+type MockPreorderRepository struct {
+	CreateFunc               func(ctx context.Context, p *models.Preorder) error
+	GetByIDFunc              func(ctx context.Context, id uuid.UUID) (*models.Preorder, error)
+	ListPendingByProductFunc func(ctx context.Context, productID uuid.UUID) ([]*models.Preorder, error)
+	UpdateFunc               func(ctx context.Context, p *models.Preorder) error
+}
+
+func (m *MockPreorderRepository) Create(ctx context.Context, p *models.Preorder) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, p)
+	}
+	return nil
+}
+
+func (m *MockPreorderRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Preorder, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockPreorderRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, status *models.PreorderStatus, productID, createdBy *uuid.UUID, limit, offset int) ([]*models.Preorder, int64, error) {
+	return nil, 0, nil
+}
+
+func (m *MockPreorderRepository) ListPendingByProduct(ctx context.Context, productID uuid.UUID) ([]*models.Preorder, error) {
+	if m.ListPendingByProductFunc != nil {
+		return m.ListPendingByProductFunc(ctx, productID)
+	}
+	return nil, nil
+}
+
+func (m *MockPreorderRepository) Update(ctx context.Context, p *models.Preorder) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, p)
+	}
+	return nil
+}
+
+// MockPurchaseOrderRepository implements outbound.PurchaseOrderRepository for tests.
+type MockPurchaseOrderRepository struct {
+	GetByIDFunc func(ctx context.Context, id uuid.UUID) (*models.PurchaseOrder, error)
+}
+
+func (m *MockPurchaseOrderRepository) Create(ctx context.Context, po *models.PurchaseOrder) error {
+	return nil
+}
+
+func (m *MockPurchaseOrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PurchaseOrder, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockPurchaseOrderRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, status *models.PurchaseOrderStatus, supplierID *uuid.UUID, limit, offset int) ([]*models.PurchaseOrder, int64, error) {
+	return nil, 0, nil
+}
+
+func (m *MockPurchaseOrderRepository) Update(ctx context.Context, po *models.PurchaseOrder) error {
+	return nil
+}
+
+// MockAIGenerationRepository is a mock for AIGenerationRepository for unit tests (no DB). Rows saved by Create
+// and Update are kept in Generations, and Create applies the quota to them as the real repository does.
+type MockAIGenerationRepository struct {
+	Generations []*models.AIGeneration
+	CreateErr   error
+}
+
+func (m *MockAIGenerationRepository) Create(ctx context.Context, g *models.AIGeneration, since time.Time, quota int) (bool, error) {
+	if m.CreateErr != nil {
+		return false, m.CreateErr
+	}
+	if used, _ := m.CountByPharmacySince(ctx, g.PharmacyID, since); quota > 0 && used >= int64(quota) {
+		return false, nil
+	}
+	if g.ID == uuid.Nil {
+		g.ID = uuid.New()
+	}
+	g.CreatedAt = time.Now()
+	cp := *g
+	m.Generations = append(m.Generations, &cp)
+	return true, nil
+}
+
+func (m *MockAIGenerationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.AIGeneration, error) {
+	for _, g := range m.Generations {
+		if g.ID == id {
+			cp := *g
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockAIGenerationRepository) ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, kind, status *string, limit, offset int) ([]*models.AIGeneration, int64, error) {
+	return nil, 0, nil
+}
+
+func (m *MockAIGenerationRepository) CountByPharmacySince(ctx context.Context, pharmacyID uuid.UUID, since time.Time) (int64, error) {
+	var n int64
+	for _, g := range m.Generations {
+		if g.PharmacyID == pharmacyID && !g.CreatedAt.Before(since) && g.Status != models.AIGenerationStatusFailed {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *MockAIGenerationRepository) Update(ctx context.Context, g *models.AIGeneration) error {
+	for i, stored := range m.Generations {
+		if stored.ID == g.ID {
+			cp := *g
+			m.Generations[i] = &cp
+		}
+	}
+	return nil
+}
@@ -2,9 +2,11 @@ package inbound
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/careplus/pharmacy-backend/internal/ports/outbound"
 	"github.com/google/uuid"
 )
 
@@ -13,8 +15,30 @@ type AuthService interface {
 	Login(ctx context.Context, email, password string) (accessToken, refreshToken string, user *models.User, err error)
 	RefreshToken(ctx context.Context, refreshToken string) (accessToken string, err error)
 	GetCurrentUser(ctx context.Context, userID uuid.UUID) (*models.User, error)
-	UpdateProfile(ctx context.Context, userID uuid.UUID, name string, phone *string, photoURL *string) (*models.User, error)
+	// UpdateProfile changes the caller's own profile; a non-nil preferredLanguage must be supported ("" = pharmacy default).
+	UpdateProfile(ctx context.Context, userID uuid.UUID, name string, phone *string, photoURL *string, preferredLanguage *string) (*models.User, error)
 	ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error
+	// ForgotPassword sends a reset link by email (and SMS when enabled). Unknown or inactive accounts are
+	// ignored without error so the endpoint does not reveal which emails are registered.
+	ForgotPassword(ctx context.Context, email string) error
+	// ResetPassword sets a new password using a token from ForgotPassword. Tokens are single-use and expire.
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	// VerifyLoginCode completes a login that failed with ErrCodeTwoFactorRequired, using the challenge token from
+	// that error and the code emailed to the user.
+	VerifyLoginCode(ctx context.Context, challengeToken, code string) (accessToken, refreshToken string, user *models.User, err error)
+	// ChangeExpiredPassword replaces a password the security policy has expired, then logs in with the new one
+	// (which may in turn require two-factor).
+	ChangeExpiredPassword(ctx context.Context, email, currentPassword, newPassword string) (accessToken, refreshToken string, user *models.User, err error)
+	// PasswordPolicy returns the pharmacy's password rules, checked against password (which may be empty).
+	PasswordPolicy(ctx context.Context, pharmacyID uuid.UUID, password string) (*PasswordPolicyCheck, error)
+}
+
+// PasswordPolicyCheck is what the pharmacy's security policy asks of a password, for sign-up and password forms.
+type PasswordPolicyCheck struct {
+	Requirements []models.PasswordRequirement `json:"requirements"`
+	Valid        bool                         `json:"valid"`       // the password meets every requirement
+	ExpiryDays   int                          `json:"expiry_days"` // 0 = passwords do not expire
+	ReuseCount   int                          `json:"reuse_count"` // recent passwords that cannot be used again; 0 = any
 }
 
 // UserAddressService manages addresses for the logged-in user (profile settings).
@@ -29,6 +53,8 @@ type UserAddressService interface {
 // PharmacistProfileInput is optional profile data when creating or updating a pharmacist.
 type PharmacistProfileInput struct {
 	LicenseNumber *string
+	// LicenseExpiresAt sets the last valid day of the license; a zero time clears it.
+	LicenseExpiresAt *time.Time
 	Qualification *string
 	CVURL         *string
 	PhotoURL      *string
@@ -39,9 +65,11 @@ type PharmacistProfileInput struct {
 
 // UserService is for admin/manager to manage staff (create/update/deactivate). List/Get enforce role scope.
 type UserService interface {
-	// List returns users for the pharmacy; if actorRole is manager, only pharmacists are returned.
+	// List returns users for the pharmacy; actors other than admin (manager or a custom role with users.manage)
+	// only see pharmacists.
 	List(ctx context.Context, pharmacyID uuid.UUID, actorRole string) ([]*models.User, error)
-	// Create creates a user; admin can set role manager|pharmacist|staff, manager can only pharmacist. Pharmacist profile optional when role is pharmacist.
+	// Create creates a user; admin can set role manager|pharmacist|staff or a custom role of the pharmacy, other actors
+	// only pharmacist. Pharmacist profile optional when role is pharmacist.
 	Create(ctx context.Context, pharmacyID uuid.UUID, actorRole string, email, password, name, role string, pharmacist *PharmacistProfileInput) (*models.User, error)
 	GetByID(ctx context.Context, pharmacyID uuid.UUID, actorRole string, userID uuid.UUID) (*models.User, error)
 	Update(ctx context.Context, pharmacyID uuid.UUID, actorRole string, userID uuid.UUID, name string, role *string, isActive *bool, pharmacist *PharmacistProfileInput) (*models.User, error)
@@ -55,6 +83,13 @@ type DutyRosterService interface {
 	ListByDateRange(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.DutyRoster, error)
 	Update(ctx context.Context, pharmacyID uuid.UUID, id uuid.UUID, userID *uuid.UUID, date *time.Time, shiftType *models.ShiftType, notes *string) (*models.DutyRoster, error)
 	Delete(ctx context.Context, pharmacyID uuid.UUID, id uuid.UUID) error
+	// Publish makes the draft entries in [from, to] visible and notifies each affected pharmacist once.
+	Publish(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.DutyRoster, error)
+	// ListMine returns the caller's published entries in [from, to].
+	ListMine(ctx context.Context, pharmacyID, userID uuid.UUID, from, to time.Time) ([]*models.DutyRoster, error)
+	Acknowledge(ctx context.Context, pharmacyID, userID, id uuid.UUID) (*models.DutyRoster, error)
+	// ListUnacknowledged returns upcoming published entries (new or changed) not yet acknowledged by their pharmacist.
+	ListUnacknowledged(ctx context.Context, pharmacyID uuid.UUID) ([]*models.DutyRoster, error)
 }
 
 type DailyLogService interface {
@@ -66,6 +101,17 @@ type DailyLogService interface {
 	Delete(ctx context.Context, pharmacyID uuid.UUID, id uuid.UUID) error
 }
 
+// DocumentService renders printable PDFs (A4) headed with the pharmacy's name, contact line and primary color.
+type DocumentService interface {
+	// DutyRosterPDF lists the published shifts in [from, to], at most 62 days.
+	DutyRosterPDF(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]byte, error)
+	// DailyLogPDF is the day sheet: the day's log entries with their status and a sign-off line.
+	DailyLogPDF(ctx context.Context, pharmacyID uuid.UUID, date time.Time) ([]byte, error)
+	// InvoicePDF is the tax invoice with its lines, VAT breakdown and any credit notes against it.
+	InvoicePDF(ctx context.Context, pharmacyID, invoiceID uuid.UUID) ([]byte, error)
+	CreditNotePDF(ctx context.Context, pharmacyID, creditNoteID uuid.UUID) ([]byte, error)
+}
+
 type PharmacyService interface {
 	Create(ctx context.Context, p *models.Pharmacy) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Pharmacy, error)
@@ -83,12 +129,27 @@ const (
 	CatalogSortNewest    CatalogSort = "newest"
 )
 
-// CatalogFilters are optional filters for the product catalog (hashtag, brand, label key-value).
+// CatalogFilters are optional filters for the product catalog (hashtag, brand, label key-value, dosage form, price range,
+// custom attributes). Attribute values are raw query strings, typed by the service.
 type CatalogFilters struct {
 	Hashtag    *string
 	Brand      *string
 	LabelKey   *string
 	LabelValue *string
+	DosageForm *string
+	MinPrice   *float64 // inclusive
+	MaxPrice   *float64 // exclusive, so price buckets can be selected without overlap
+	Attributes map[string]string // product attribute key -> value; all must match
+}
+
+// ProductDeltaPage is one page of the catalog delta feed. Clients pass NextSince (and NextAfter when set) back
+// as the cursor and keep fetching while HasMore is true.
+type ProductDeltaPage struct {
+	Items      []*models.ProductDelta `json:"items"`
+	NextSince  time.Time              `json:"next_since"`
+	NextAfter  *uuid.UUID             `json:"next_after,omitempty"`
+	HasMore    bool                   `json:"has_more"`
+	ServerTime time.Time              `json:"server_time"`
 }
 
 type ProductService interface {
@@ -100,39 +161,191 @@ type ProductService interface {
 	ListPaginated(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, limit, offset int) ([]*models.Product, int64, error)
 	// ListCatalog returns a page of products with search, sort, and optional filters (hashtag, brand, label) for the public catalog (active only).
 	ListCatalog(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, searchQ string, sort CatalogSort, limit, offset int, filters *CatalogFilters) ([]*models.Product, int64, error)
+	// CatalogFacets returns filter-sidebar counts for the same selection ListCatalog takes.
+	CatalogFacets(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, searchQ string, filters *CatalogFilters) (*models.CatalogFacets, error)
+	// Delta returns price, stock and active-status changes after the cursor (zero since = full snapshot).
+	Delta(ctx context.Context, pharmacyID uuid.UUID, since time.Time, afterID uuid.UUID, limit int) (*ProductDeltaPage, error)
 	Update(ctx context.Context, p *models.Product) error
+	// RenameBrand renames a brand on all of the pharmacy's products (matched ignoring case and surrounding spaces)
+	// and returns how many products changed.
+	RenameBrand(ctx context.Context, pharmacyID uuid.UUID, from, to string) (int64, error)
+	// Alternatives returns an active product and up to limit cheaper in-stock substitutes with the same generic
+	// name and dosage form, cheapest first (limit defaults to 10, max 50).
+	Alternatives(ctx context.Context, productID uuid.UUID, limit int) (*models.Product, []*models.Product, error)
+	// AlternativeFlags reports which of the products have a cheaper substitute (the catalog's
+	// "alternatives available" badge).
+	AlternativeFlags(ctx context.Context, productIDs []uuid.UUID) (map[uuid.UUID]bool, error)
 	UpdateStock(ctx context.Context, productID uuid.UUID, quantity int) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	AddImage(ctx context.Context, productID uuid.UUID, url string, isPrimary bool) (*models.ProductImage, error)
+	// AddImage attaches an image saved by ProductImageProcessor.Store, with its variant URLs.
+	AddImage(ctx context.Context, productID uuid.UUID, stored *models.ProductImage, isPrimary bool) (*models.ProductImage, error)
 	SetPrimaryImage(ctx context.Context, productID, imageID uuid.UUID) error
 	ReorderImages(ctx context.Context, productID uuid.UUID, imageIDs []uuid.UUID) error
 	DeleteImage(ctx context.Context, productID, imageID uuid.UUID) error
 }
 
 type OrderService interface {
-	Create(ctx context.Context, pharmacyID, createdBy uuid.UUID, customerName, customerPhone, customerEmail string, items []OrderItemInput, notes string, deliveryAddress string, discountAmount *float64, promoCode *string, referralCode *string, pointsToRedeem *int, paymentGatewayID *uuid.UUID) (*models.Order, error)
+	// Create places the order. tender, when set, pays part of the total with a gift card and/or store credit.
+	// customFields are checked against the pharmacy's order fields; nil skips them (system-created orders).
+	Create(ctx context.Context, pharmacyID, createdBy uuid.UUID, customerName, customerPhone, customerEmail string, items []OrderItemInput, notes string, deliveryAddress string, discountAmount *float64, promoCode *string, referralCode *string, pointsToRedeem *int, paymentGatewayID *uuid.UUID, tender *OrderTender, customFields map[string]any) (*models.Order, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Order, error)
-	List(ctx context.Context, pharmacyID uuid.UUID, createdBy *uuid.UUID, status *string) ([]*models.Order, error)
-	UpdateStatus(ctx context.Context, orderID uuid.UUID, status models.OrderStatus) (*models.Order, error)
-	Accept(ctx context.Context, orderID uuid.UUID) (*models.Order, error)
+	// List lists the pharmacy's orders; branchID, when set, keeps only orders taken at that branch.
+	List(ctx context.Context, pharmacyID uuid.UUID, createdBy *uuid.UUID, status *string, branchID *uuid.UUID) ([]*models.Order, error)
+	// ListPage is the paginated list used by /api/v2; limit defaults to 20 (max 100).
+	ListPage(ctx context.Context, pharmacyID uuid.UUID, createdBy *uuid.UUID, status *string, branchID *uuid.UUID, limit, offset int) ([]*models.Order, int64, error)
+	// ListPaginated pages through orders with date-range and order-number filters; limit defaults to 20 (max 100).
+	ListPaginated(ctx context.Context, pharmacyID uuid.UUID, q OrderListQuery) ([]*models.Order, int64, error)
+	// ListMine is the end user's order history: their own orders as summaries rather than full rows.
+	ListMine(ctx context.Context, pharmacyID, userID uuid.UUID, q OrderListQuery) ([]*OrderSummary, int64, error)
+	// UpdateStatus moves the order to status and records the change with the actor and an optional note.
+	UpdateStatus(ctx context.Context, orderID uuid.UUID, status models.OrderStatus, actorID uuid.UUID, note string) (*models.Order, error)
+	// Accept confirms a pending order; orders with clinical warnings must have them acknowledged first.
+	Accept(ctx context.Context, orderID, actorID uuid.UUID, note string) (*models.Order, error)
+	// AcknowledgeWarnings records that the pharmacist reviewed the order's clinical warnings.
+	AcknowledgeWarnings(ctx context.Context, orderID, actorID uuid.UUID) (*models.Order, error)
+	// History returns the order's status changes, oldest first. viewerID, when set, limits it to that user's own orders.
+	History(ctx context.Context, pharmacyID, orderID uuid.UUID, viewerID *uuid.UUID) ([]*models.OrderStatusHistory, error)
+}
+
+// OrderListQuery filters order lists; zero values match everything. From is inclusive and To exclusive.
+type OrderListQuery struct {
+	CreatedBy   *uuid.UUID
+	BranchID    *uuid.UUID
+	Status      string
+	From        time.Time
+	To          time.Time
+	OrderNumber string
+	Limit       int
+	Offset      int
+}
+
+// OrderSummary is one row of a customer's order history.
+type OrderSummary struct {
+	ID          uuid.UUID           `json:"id"`
+	OrderNumber string              `json:"order_number"`
+	Status      models.OrderStatus  `json:"status"`
+	ItemCount   int                 `json:"item_count"`
+	UnitCount   int                 `json:"unit_count"`
+	TotalAmount float64             `json:"total_amount"`
+	Currency    string              `json:"currency"`
+	CreatedAt   time.Time           `json:"created_at"`
+	CompletedAt *time.Time          `json:"completed_at,omitempty"`
+	Timeline    []OrderTimelineStep `json:"timeline"`
+}
+
+// OrderTimelineStep is one stage of an order's progress; At is set where the time is known.
+type OrderTimelineStep struct {
+	Status  models.OrderStatus `json:"status"`
+	Reached bool               `json:"reached"`
+	At      *time.Time         `json:"at,omitempty"`
 }
 
 // OrderFeedbackService allows the order creator (end user) to submit feedback on completed orders.
+// Low ratings notify the pharmacy's admins and managers and start a follow-up that staff record with UpdateFollowUp.
 type OrderFeedbackService interface {
 	Create(ctx context.Context, orderID, userID uuid.UUID, rating int, comment string) (*models.OrderFeedback, error)
 	GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.OrderFeedback, error)
+	List(ctx context.Context, pharmacyID uuid.UUID, q FeedbackListQuery) ([]*models.OrderFeedback, int64, error)
+	// Summary aggregates feedback created in [from, to): overall, trend by granularity, by staff member and by fulfilment.
+	Summary(ctx context.Context, pharmacyID uuid.UUID, granularity string, from, to time.Time) (*FeedbackSummary, error)
+	UpdateFollowUp(ctx context.Context, pharmacyID, feedbackID, actorID uuid.UUID, status, note string) (*models.OrderFeedback, error)
+}
+
+// FeedbackListQuery filters the feedback list; zero values match everything.
+type FeedbackListQuery struct {
+	FollowUpStatus string
+	MaxRating      int
+	Limit          int
+	Offset         int
+}
+
+// FeedbackSummary is the order-feedback analytics report.
+type FeedbackSummary struct {
+	From             time.Time                  `json:"from"`
+	To               time.Time                  `json:"to"`
+	Granularity      string                     `json:"granularity"`
+	Count            int64                      `json:"count"`
+	AverageRating    float64                    `json:"average_rating"`
+	LowCount         int64                      `json:"low_count"`
+	PendingFollowUps int64                      `json:"pending_follow_ups"` // all time, not limited to the range
+	Trend            []*models.FeedbackTrendRow `json:"trend"`
+	ByStaff          []*models.FeedbackGroupRow `json:"by_staff"`
+	ByFulfilment     []*models.FeedbackGroupRow `json:"by_fulfilment"`
 }
 
 // OrderReturnRequestService allows the order creator to submit a return request (defect) within 3 days of completion.
+// Staff review and classify requests, see return rates and work through products flagged for high returns.
 type OrderReturnRequestService interface {
-	Create(ctx context.Context, orderID, userID uuid.UUID, videoURL string, photoURLs []string, notes, description string) (*models.OrderReturnRequest, error)
+	Create(ctx context.Context, orderID, userID uuid.UUID, in ReturnRequestInput) (*models.OrderReturnRequest, error)
 	GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.OrderReturnRequest, error)
+	// Reasons lists the defect-reason taxonomy in display order.
+	Reasons() []ReturnReasonInfo
+	List(ctx context.Context, pharmacyID uuid.UUID, q ReturnRequestListQuery) ([]*models.OrderReturnRequest, int64, error)
+	// Review sets status, reason and staff note; nil fields are left unchanged. With RefundToWallet an approved
+	// request is also refunded to the customer's wallet, once.
+	Review(ctx context.Context, pharmacyID, id, actorID uuid.UUID, in ReturnReviewInput) (*models.OrderReturnRequest, error)
+	// Analytics returns return rates grouped by "product", "brand" or "supplier", plus a reason breakdown.
+	Analytics(ctx context.Context, pharmacyID uuid.UUID, groupBy string, from, to time.Time) (*ReturnAnalytics, error)
+	ListFlags(ctx context.Context, pharmacyID uuid.UUID, status string) ([]*models.ProductReturnFlag, error)
+	// ReviewFlag marks a product's flag as reviewed by purchasing.
+	ReviewFlag(ctx context.Context, pharmacyID, productID, actorID uuid.UUID, note string) (*models.ProductReturnFlag, error)
+}
+
+// ReturnRequestInput is a customer's return request. ReasonCode and ProductIDs are optional.
+type ReturnRequestInput struct {
+	VideoURL    string      `json:"video_url"`
+	PhotoURLs   []string    `json:"photo_urls"`
+	Notes       string      `json:"notes"`
+	Description string      `json:"description"`
+	ReasonCode  string      `json:"reason_code"`
+	ProductIDs  []uuid.UUID `json:"product_ids"`
+}
+
+type ReturnReasonInfo struct {
+	Code  string `json:"code"`
+	Label string `json:"label"`
+}
+
+type ReturnRequestListQuery struct {
+	Status     string
+	ReasonCode string
+	Limit      int
+	Offset     int
+}
+
+type ReturnReviewInput struct {
+	Status     *models.ReturnRequestStatus `json:"status"`
+	ReasonCode *string                     `json:"reason_code"`
+	StaffNote  *string                     `json:"staff_note"`
+	// RefundToWallet refunds the returned amount to the customer's wallet (store credit) with the approval.
+	RefundToWallet bool `json:"refund_to_wallet"`
+}
+
+// ReturnAnalytics is return activity on completed orders in a range.
+type ReturnAnalytics struct {
+	From          time.Time                 `json:"from"`
+	To            time.Time                 `json:"to"`
+	GroupBy       string                    `json:"group_by"`
+	UnitsSold     int64                     `json:"units_sold"`
+	UnitsReturned int64                     `json:"units_returned"`
+	ReturnRate    float64                   `json:"return_rate"` // percent
+	Rows          []*models.ReturnRateRow   `json:"rows"`
+	Reasons       []*models.ReturnReasonRow `json:"reasons"`
+}
+
+// OrderTender is stored value put towards an order. The gift card covers as much of the total as its balance
+// allows; StoreCredit (capped at what is left) needs a known customer.
+type OrderTender struct {
+	GiftCardCode string
+	StoreCredit  float64
 }
 
 type OrderItemInput struct {
 	ProductID uuid.UUID `json:"product_id" binding:"required"`
 	Quantity  int       `json:"quantity" binding:"required,min=1"`
 	UnitPrice float64   `json:"unit_price" binding:"required,min=0"`
+	// SerialNumbers picks the units sold for a serial-tracked product; when given, one per unit.
+	SerialNumbers []string `json:"serial_numbers,omitempty" binding:"omitempty,max=500,dive,required,max=100"`
 }
 
 type PaymentService interface {
@@ -167,13 +380,31 @@ type AppConfigResponse struct {
 	ContactPhone   string          `json:"contact_phone,omitempty"`
 	ContactEmail   string          `json:"contact_email,omitempty"`
 	VerifiedAt     *string         `json:"verified_at,omitempty"`
+	Currency       string          `json:"currency"`                   // base currency of prices and orders
+	DisplayCurrency string         `json:"display_currency,omitempty"` // storefront also shows prices in it, converted
 }
 
 type PharmacyConfigService interface {
 	GetByPharmacyID(ctx context.Context, pharmacyID uuid.UUID) (*models.PharmacyConfig, error)
 	GetOrCreateByPharmacyID(ctx context.Context, pharmacyID uuid.UUID) (*models.PharmacyConfig, error)
 	GetAppConfigByHostname(ctx context.Context, hostname string) (*AppConfigResponse, error)
-	Upsert(ctx context.Context, pharmacyID uuid.UUID, c *models.PharmacyConfig) (*models.PharmacyConfig, error)
+	// Upsert validates and saves the config, recording a history version changed by the given user.
+	Upsert(ctx context.Context, pharmacyID, changedBy uuid.UUID, c *models.PharmacyConfig) (*models.PharmacyConfig, error)
+	// Validate checks a config update without saving it (dry run): field errors, warnings and changed fields.
+	Validate(ctx context.Context, pharmacyID uuid.UUID, c *models.PharmacyConfig) (*ConfigValidationResult, error)
+	ListHistory(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.PharmacyConfigVersion, int64, error)
+	GetVersion(ctx context.Context, pharmacyID uuid.UUID, version int) (*models.PharmacyConfigVersion, error)
+	// Rollback restores the config saved as the given version and records it as a new version.
+	Rollback(ctx context.Context, pharmacyID, changedBy uuid.UUID, version int) (*models.PharmacyConfig, error)
+}
+
+// ConfigValidationResult is the outcome of validating a config update. Errors are keyed by JSON field path
+// (e.g. "primary_color", "business_hours[2].close"); warnings do not block saving.
+type ConfigValidationResult struct {
+	Valid    bool              `json:"valid"`
+	Errors   map[string]string `json:"errors,omitempty"`
+	Warnings []string          `json:"warnings"`
+	Changes  []string          `json:"changes"` // top-level fields that differ from the saved config
 }
 
 type CategoryService interface {
@@ -181,6 +412,7 @@ type CategoryService interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Category, error)
 	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Category, error)
 	ListByParentID(ctx context.Context, pharmacyID uuid.UUID, parentID *uuid.UUID) ([]*models.Category, error)
+	// Update saves the category; a rename re-syncs the category string on its products in the background.
 	Update(ctx context.Context, c *models.Category) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -193,20 +425,55 @@ type ProductUnitService interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
+// ActivityLogQuery filters the activity log; zero values match everything. Action matches a substring ignoring
+// case; To is exclusive.
+type ActivityLogQuery struct {
+	UserID     *uuid.UUID
+	Action     string
+	EntityType string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
 type ActivityLogService interface {
 	Create(ctx context.Context, pharmacyID, userID uuid.UUID, action, description, entityType, entityID, details, ipAddress string) error
-	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.ActivityLog, error)
+	List(ctx context.Context, pharmacyID uuid.UUID, q ActivityLogQuery) ([]*models.ActivityLog, error)
+	// Export returns every matching entry, oldest first, for a compliance CSV (Limit and Offset are ignored).
+	Export(ctx context.Context, pharmacyID uuid.UUID, q ActivityLogQuery) ([]*models.ActivityLog, error)
+	// PurgeExpired deletes entries older than each pharmacy's retention period (scheduled job).
+	PurgeExpired(ctx context.Context) error
 }
 
 // PromoCodeValidateResult is returned when validating a promo code for billing.
 type PromoCodeValidateResult struct {
-	Code           string  `json:"code"`
-	DiscountAmount float64 `json:"discount_amount"`
-	PromoCodeID    uuid.UUID `json:"promo_code_id"`
+	Code             string               `json:"code"`
+	DiscountAmount   float64              `json:"discount_amount"`
+	PromoCodeID      uuid.UUID            `json:"promo_code_id"`
+	Stacking         models.PromoStacking `json:"stacking"`
+	EligibleSubTotal float64              `json:"eligible_sub_total"` // the part of the subtotal the code applies to
+	Lines            []PromoLineDiscount  `json:"lines,omitempty"`    // split per product; empty when validated without lines
+}
+
+// PromoLine is one order or cart line checked against a promo code's product and category scope.
+type PromoLine struct {
+	ProductID   uuid.UUID
+	CategoryIDs []uuid.UUID // the product's category and its parent
+	Quantity    int
+	Amount      float64 // line total at the effective price
+}
+
+// PromoLineDiscount is the share of a promo discount given to one product.
+type PromoLineDiscount struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Amount    float64   `json:"amount"`
 }
 
 type PromoCodeService interface {
-	Validate(ctx context.Context, pharmacyID uuid.UUID, code string, subTotal float64, userID *uuid.UUID) (*PromoCodeValidateResult, error)
+	// Validate checks code against the order. Without lines, codes scoped to products or categories or with a
+	// minimum item quantity are rejected, since they cannot be checked on a subtotal alone.
+	Validate(ctx context.Context, pharmacyID uuid.UUID, code string, subTotal float64, lines []PromoLine, userID *uuid.UUID) (*PromoCodeValidateResult, error)
 	Create(ctx context.Context, pharmacyID uuid.UUID, p *models.PromoCode) (*models.PromoCode, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*models.PromoCode, error)
 	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.PromoCode, error)
@@ -218,6 +485,12 @@ type InvoiceService interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*InvoiceView, error)
 	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Invoice, error)
 	Issue(ctx context.Context, invoiceID uuid.UUID) (*models.Invoice, error)
+	// IssueCreditNote credits the order's invoice after a return or refund, issuing the invoice first if the
+	// order has none. Amount 0 credits everything not yet credited and larger amounts are capped at that; the
+	// result is nil when nothing is left to credit.
+	IssueCreditNote(ctx context.Context, pharmacyID, orderID, actorID uuid.UUID, in CreditNoteInput) (*models.CreditNote, error)
+	ListCreditNotes(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.CreditNote, int64, error)
+	GetCreditNote(ctx context.Context, pharmacyID, id uuid.UUID) (*CreditNoteView, error)
 }
 
 // InvoiceView is the full invoice response (invoice + order + items + payments).
@@ -225,34 +498,70 @@ type InvoiceView struct {
 	Invoice *models.Invoice   `json:"invoice"`
 	Order   *models.Order     `json:"order"`
 	Payments []*models.Payment `json:"payments"`
+	TaxLines []models.TaxLine  `json:"tax_lines,omitempty"` // VAT breakdown by class and rate
+	TaxRegistrationNo string   `json:"tax_registration_no,omitempty"`
+	CustomFields   []models.OrderFieldValue `json:"custom_fields,omitempty"` // order fields marked show_on_invoice
+	DeliveryProof  *models.DeliveryProof    `json:"delivery_proof,omitempty"`
+	CreditNotes    []*models.CreditNote `json:"credit_notes"`
+	InvoiceTotal   float64              `json:"invoice_total"`   // everything the customer paid, incl. gift card and store credit
+	CreditedAmount float64              `json:"credited_amount"` // sum of credit notes
+	NetAmount      float64              `json:"net_amount"`      // invoice_total - credited_amount
+}
+
+type CreditNoteInput struct {
+	Source   models.CreditNoteSource
+	SourceID *uuid.UUID
+	Amount   float64
+	Reason   string
+}
+
+// CreditNoteView is a credit note with the invoice and order it reduces.
+type CreditNoteView struct {
+	CreditNote        *models.CreditNote `json:"credit_note"`
+	Invoice           *models.Invoice    `json:"invoice"`
+	Order             *models.Order      `json:"order"`
+	TaxRegistrationNo string             `json:"tax_registration_no,omitempty"`
 }
 
 type NotificationService interface {
 	Create(ctx context.Context, pharmacyID, userID uuid.UUID, title, message, notifType string) (*models.Notification, error)
+	// CreateLocalized stores the translation in the recipient's preferred language (or, without a preference, the
+	// pharmacy default language); title and message are used when there is no such translation.
+	CreateLocalized(ctx context.Context, pharmacyID, userID uuid.UUID, title, message string, translations models.Translations, notifType string) (*models.Notification, error)
 	ListByUser(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]*models.Notification, error)
 	CountUnreadByUser(ctx context.Context, userID uuid.UUID) (int64, error)
 	MarkRead(ctx context.Context, id, userID uuid.UUID) error
 	MarkAllRead(ctx context.Context, userID uuid.UUID) error
+	// SendUnreadCount sends an "unread_count" event to the user's open WebSocket connections.
+	SendUnreadCount(ctx context.Context, userID uuid.UUID)
 }
 
 type InventoryService interface {
-	AddBatch(ctx context.Context, pharmacyID, productID uuid.UUID, batchNumber string, quantity int, expiryDate *time.Time) (*models.InventoryBatch, error)
+	// AddBatch stocks the batch at branchID, or at the pharmacy's default branch when nil.
+	AddBatch(ctx context.Context, pharmacyID, productID uuid.UUID, batchNumber string, quantity int, expiryDate *time.Time, branchID *uuid.UUID) (*models.InventoryBatch, error)
 	ListBatchesByProduct(ctx context.Context, productID uuid.UUID) ([]*models.InventoryBatch, error)
-	ListBatchesByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.InventoryBatch, error)
+	// ListBatchesByPharmacy lists the pharmacy's batches, only those at branchID when set.
+	ListBatchesByPharmacy(ctx context.Context, pharmacyID uuid.UUID, branchID *uuid.UUID) ([]*models.InventoryBatch, error)
 	ListExpiringSoon(ctx context.Context, pharmacyID uuid.UUID, withinDays int) ([]*models.InventoryBatch, error)
 	GetBatch(ctx context.Context, id uuid.UUID) (*models.InventoryBatch, error)
 	UpdateBatch(ctx context.Context, id uuid.UUID, quantity *int, expiryDate *time.Time) (*models.InventoryBatch, error)
 	DeleteBatch(ctx context.Context, id uuid.UUID) error
-	Consume(ctx context.Context, productID uuid.UUID, quantity int) error
+	// Consume deducts the item's quantity FEFO from unexpired batches and records the draw on item.Batches.
+	Consume(ctx context.Context, item *models.OrderItem) error
+	// ConsumeAt is Consume drawing only from branchID's batches (the whole pharmacy when nil).
+	ConsumeAt(ctx context.Context, item *models.OrderItem, branchID *uuid.UUID) error
 	HasBatches(ctx context.Context, productID uuid.UUID) (bool, error)
+	// ListBatchAllocations lists the order items that drew from a batch (for recalls).
+	ListBatchAllocations(ctx context.Context, pharmacyID, batchID uuid.UUID) ([]*models.OrderItemBatch, error)
 }
 
-// ProductReviewWithMeta is a review with like count, user_liked, and comment count.
+// ProductReviewWithMeta is a review with like count, user_liked, comment count and the pharmacy's response.
 type ProductReviewWithMeta struct {
 	*models.ProductReview
-	LikeCount    int64 `json:"like_count"`
-	UserLiked    bool  `json:"user_liked"`
-	CommentCount int64 `json:"comment_count"`
+	LikeCount        int64                  `json:"like_count"`
+	UserLiked        bool                   `json:"user_liked"`
+	CommentCount     int64                  `json:"comment_count"`
+	PharmacyResponse *models.ReviewResponse `json:"pharmacy_response"` // nil when the pharmacy has not responded
 }
 
 type ReviewService interface {
@@ -266,6 +575,11 @@ type ReviewService interface {
 	CreateComment(ctx context.Context, reviewID, userID uuid.UUID, body string, parentID *uuid.UUID) (*models.ReviewComment, error)
 	ListComments(ctx context.Context, reviewID uuid.UUID, limit, offset int) ([]*models.ReviewComment, error)
 	DeleteComment(ctx context.Context, commentID, userID uuid.UUID) error
+	// Respond posts the pharmacy's official response to a review of one of its products; a review has at most one.
+	Respond(ctx context.Context, pharmacyID, reviewID, actorID uuid.UUID, body string) (*models.ReviewResponse, error)
+	// UpdateResponse replaces the text of the pharmacy's response.
+	UpdateResponse(ctx context.Context, pharmacyID, reviewID, actorID uuid.UUID, body string) (*models.ReviewResponse, error)
+	DeleteResponse(ctx context.Context, pharmacyID, reviewID uuid.UUID) error
 }
 
 type MembershipService interface {
@@ -282,6 +596,42 @@ type PromoService interface {
 	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, types []string, activeOnly bool) ([]*models.Promo, error)
 	Update(ctx context.Context, pharmacyID uuid.UUID, p *models.Promo) (*models.Promo, error)
 	Delete(ctx context.Context, pharmacyID, id uuid.UUID) error
+	// ListPublic returns promos live right now for the storefront, filtered by placement/category and paginated.
+	ListPublic(ctx context.Context, pharmacyID uuid.UUID, q PublicPromoQuery) ([]*models.Promo, int64, error)
+	// RecordImpressions counts one impression for each promo id; ids not belonging to the pharmacy are ignored.
+	RecordImpressions(ctx context.Context, pharmacyID uuid.UUID, promoIDs []uuid.UUID, placement string) error
+	RecordClick(ctx context.Context, pharmacyID, promoID uuid.UUID, placement string) error
+	Performance(ctx context.Context, pharmacyID, promoID uuid.UUID, from, to time.Time) (*PromoPerformance, error)
+}
+
+// PublicPromoQuery filters the public promo list. Zero values match everything; Limit 0 returns all.
+type PublicPromoQuery struct {
+	Types      []string
+	Placement  string
+	CategoryID *uuid.UUID
+	Limit      int
+	Offset     int
+}
+
+// PromoStatRow is one line of a promo performance report (a day or a placement).
+type PromoStatRow struct {
+	Day         string  `json:"day,omitempty"`
+	Placement   string  `json:"placement,omitempty"`
+	Impressions int64   `json:"impressions"`
+	Clicks      int64   `json:"clicks"`
+	CTR         float64 `json:"ctr"` // clicks / impressions, 0 when no impressions
+}
+
+// PromoPerformance is the per-promo impression/click report.
+type PromoPerformance struct {
+	PromoID     uuid.UUID      `json:"promo_id"`
+	From        string         `json:"from"`
+	To          string         `json:"to"`
+	Impressions int64          `json:"impressions"`
+	Clicks      int64          `json:"clicks"`
+	CTR         float64        `json:"ctr"`
+	ByDay       []PromoStatRow `json:"by_day"`
+	ByPlacement []PromoStatRow `json:"by_placement"`
 }
 
 // ReferralCodeValidateResult is returned when validating a referral code (public).
@@ -330,16 +680,59 @@ type ReferralPointsService interface {
 	ListPointsTransactions(ctx context.Context, customerID uuid.UUID, limit, offset int) ([]*models.PointsTransaction, error)
 	// GetMyCustomerProfile returns the customer profile for the logged-in user (matched by user phone), for end-user profile: referral code, points, membership, earned from purchases.
 	GetMyCustomerProfile(ctx context.Context, userID, pharmacyID uuid.UUID) (*MyCustomerProfileResponse, error)
+	// SetCustomerLanguage sets the language of the customer's order emails and SMS ("" = pharmacy default).
+	SetCustomerLanguage(ctx context.Context, pharmacyID, customerID uuid.UUID, language string) (*models.Customer, error)
+	// SetCustomerDateOfBirth records the birthday used for the membership birthday gift (nil clears it).
+	SetCustomerDateOfBirth(ctx context.Context, pharmacyID, customerID uuid.UUID, dob *time.Time) (*models.Customer, error)
 }
 
 // MyCustomerProfileResponse is the payload for GET /auth/me/customer-profile (end-user rewards/loyalty).
 type MyCustomerProfileResponse struct {
 	Customer                  *models.Customer             `json:"customer,omitempty"`                    // nil if user has no phone or no customer found
 	Membership                *MembershipInfo              `json:"membership,omitempty"`                  // set when customer has an active membership
+	Benefits                  *CustomerBenefits            `json:"benefits,omitempty"`                    // perks of the membership tier, for the storefront to advertise
 	PointsEarnedFromPurchases int                          `json:"points_earned_from_purchases"`          // sum of earn_purchase transaction amounts
 	PointsTransactions       []*models.PointsTransaction  `json:"points_transactions,omitempty"`         // recent history (e.g. last 20)
 }
 
+// BenefitsEngine applies the perks of a customer's membership tier (free delivery, flash-sale early access,
+// points multiplier, birthday gift) at checkout.
+type BenefitsEngine interface {
+	// ForCustomer returns what the customer's active tier grants at now; a customer without a tier gets none.
+	ForCustomer(ctx context.Context, customerID uuid.UUID, now time.Time) (*CustomerBenefits, error)
+	// Evaluate applies the benefits to one checkout. customerID may be nil (walk-in), which still prices delivery.
+	// A birthday gift in the result has been claimed for the year; call ReleaseBirthdayGift if the order is not created.
+	Evaluate(ctx context.Context, pharmacyID uuid.UUID, customerID *uuid.UUID, in BenefitsCheckout) (*BenefitsEvaluation, error)
+	ReleaseBirthdayGift(ctx context.Context, customerID uuid.UUID, year int)
+}
+
+// CustomerBenefits is a customer's tier and the perks it currently grants.
+type CustomerBenefits struct {
+	Membership            *MembershipInfo           `json:"membership,omitempty"`
+	Benefits              models.MembershipBenefits `json:"benefits"`
+	BirthdayGiftAvailable bool                      `json:"birthday_gift_available"` // birthday month and this year's gift not yet used
+	Perks                 []string                  `json:"perks"`                   // short labels, e.g. "Free delivery"
+}
+
+type BenefitsCheckout struct {
+	SubTotal float64 // after line prices, before discounts
+	Delivery bool    // the order has a delivery address
+	// ExclusivePromo is set when an exclusive promo code is applied; membership discounts, and so the birthday gift, are skipped.
+	ExclusivePromo bool
+	Now            time.Time
+}
+
+// BenefitsEvaluation is what the tier changes on one order.
+type BenefitsEvaluation struct {
+	CustomerBenefits
+	DeliveryFee         float64 // charged on the order
+	DeliveryFeeWaived   float64 // pharmacy fee waived by free delivery
+	BirthdayDiscount    float64
+	BirthdayBonusPoints int     // credited when the order completes
+	BirthdayYear        int     // year claimed for the birthday gift; 0 when none applies
+	PointsMultiplier    float64 // at least 1
+}
+
 type AnnouncementService interface {
 	Create(ctx context.Context, pharmacyID uuid.UUID, a *models.Announcement) (*models.Announcement, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Announcement, error)
@@ -347,9 +740,11 @@ type AnnouncementService interface {
 	Update(ctx context.Context, pharmacyID uuid.UUID, a *models.Announcement) (*models.Announcement, error)
 	Delete(ctx context.Context, pharmacyID, id uuid.UUID) error
 	// ListActiveForUser returns announcements to show on dashboard (not yet acked, within dates, and user has not "skip all" in last 24h).
-	ListActiveForUser(ctx context.Context, pharmacyID, userID uuid.UUID) ([]*models.Announcement, error)
-	// Acknowledge records that user dismissed one announcement or chose "skip all".
-	Acknowledge(ctx context.Context, userID, announcementID uuid.UUID, skipAll bool) error
+	// For team roles, announcements with a training quiz that is not yet passed are shown even after "skip all".
+	ListActiveForUser(ctx context.Context, pharmacyID, userID uuid.UUID, role string) ([]*models.Announcement, error)
+	// Acknowledge records that user dismissed one announcement or chose "skip all". Team members must pass the
+	// announcement's training quiz first (passing acknowledges it automatically).
+	Acknowledge(ctx context.Context, userID, announcementID uuid.UUID, role string, skipAll bool) error
 }
 
 type ChatService interface {
@@ -396,17 +791,40 @@ type BlogService interface {
 	UpdateCategory(ctx context.Context, pharmacyID, id uuid.UUID, name, description *string, parentID *uuid.UUID, sortOrder *int) (*models.BlogCategory, error)
 	DeleteCategory(ctx context.Context, pharmacyID, id uuid.UUID) error
 
-	// Posts: author/company/pharmacist creates with status draft or pending_approval; manager approves to published
-	CreatePost(ctx context.Context, pharmacyID, authorID uuid.UUID, title, excerpt, body string, categoryID *uuid.UUID, status string, media []BlogPostMediaInput) (*models.BlogPost, error)
+	// Tags: posts are tagged by name; unknown names create the tag. ListTags counts all posts per tag, TagCloud
+	// only published ones, most used first.
+	ListTags(ctx context.Context, pharmacyID uuid.UUID) ([]*models.BlogTag, error)
+	TagCloud(ctx context.Context, pharmacyID uuid.UUID, limit int) ([]*models.BlogTag, error)
+	CreateTag(ctx context.Context, pharmacyID uuid.UUID, name string) (*models.BlogTag, error)
+	UpdateTag(ctx context.Context, pharmacyID, id uuid.UUID, name string) (*models.BlogTag, error)
+	DeleteTag(ctx context.Context, pharmacyID, id uuid.UUID) error
+
+	// Posts: author/company/pharmacist creates with status draft or pending_approval; manager approves to published or scheduled
+	CreatePost(ctx context.Context, pharmacyID, authorID uuid.UUID, title, excerpt, body string, categoryID *uuid.UUID, status string, media []BlogPostMediaInput, tags []string) (*models.BlogPost, error)
 	GetPost(ctx context.Context, postID uuid.UUID, userID *uuid.UUID, recordView bool) (*BlogPostWithMeta, error)
 	GetPostBySlug(ctx context.Context, pharmacyID uuid.UUID, slug string, userID *uuid.UUID, recordView bool) (*BlogPostWithMeta, error)
-	ListPosts(ctx context.Context, pharmacyID uuid.UUID, status *string, categoryID *uuid.UUID, limit, offset int) ([]*BlogPostWithMeta, int64, error)
+	// ListPosts filters by tag slug when tag is set.
+	ListPosts(ctx context.Context, pharmacyID uuid.UUID, status *string, categoryID *uuid.UUID, tag string, limit, offset int) ([]*BlogPostWithMeta, int64, error)
 	ListPendingPosts(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*BlogPostWithMeta, int64, error)
-	UpdatePost(ctx context.Context, pharmacyID, userID, postID uuid.UUID, title, excerpt, body *string, categoryID *uuid.UUID, status *string, media []BlogPostMediaInput) (*models.BlogPost, error)
+	UpdatePost(ctx context.Context, pharmacyID, userID, postID uuid.UUID, title, excerpt, body *string, categoryID *uuid.UUID, status *string, media []BlogPostMediaInput, tags []string) (*models.BlogPost, error)
 	DeletePost(ctx context.Context, pharmacyID, userID, postID uuid.UUID) error
-	ApprovePost(ctx context.Context, pharmacyID, postID uuid.UUID) (*models.BlogPost, error)
+	// ApprovePost publishes a pending post now, or schedules it when publishAt is in the future. A scheduled post
+	// can be approved again to move its time or publish it now.
+	ApprovePost(ctx context.Context, pharmacyID, postID uuid.UUID, publishAt *time.Time) (*models.BlogPost, error)
+	// PublishScheduled publishes scheduled posts whose time has come (scheduler job).
+	PublishScheduled(ctx context.Context) error
+
+	// Revisions: title, excerpt and body are snapshotted on create and after each edit or restore. Authors work
+	// with their own posts' revisions; canManage (blog.approve) allows any post of the pharmacy.
+	ListRevisions(ctx context.Context, pharmacyID, userID, postID uuid.UUID, canManage bool, limit, offset int) ([]*models.BlogPostRevision, int64, error)
+	// RestoreRevision puts a revision's content back as a new revision. Authors can restore drafts and pending
+	// posts; managers any post.
+	RestoreRevision(ctx context.Context, pharmacyID, userID, postID, revisionID uuid.UUID, canManage bool) (*models.BlogPost, error)
 	SubmitForApproval(ctx context.Context, pharmacyID, userID, postID uuid.UUID) (*models.BlogPost, error)
 
+	// Feed returns the latest published posts of an active pharmacy for its RSS/Atom feed.
+	Feed(ctx context.Context, pharmacyID uuid.UUID) (*BlogFeed, error)
+
 	// Engagement
 	LikePost(ctx context.Context, postID, userID uuid.UUID) error
 	UnlikePost(ctx context.Context, postID, userID uuid.UUID) error
@@ -420,9 +838,1921 @@ type BlogService interface {
 	GetAnalytics(ctx context.Context, pharmacyID uuid.UUID, limit int) ([]*BlogAnalytics, error)
 }
 
+// BlogFeed is a pharmacy's public blog feed, newest post first. Updated is the latest change to the pharmacy or a listed post.
+type BlogFeed struct {
+	PharmacyID uuid.UUID
+	Title      string
+	Link       string // the web app's blog page
+	SelfLink   string // the feed's own URL
+	Updated    time.Time
+	Items      []*BlogFeedItem
+}
+
+// BlogFeedItem is one post in the feed with its web app link.
+type BlogFeedItem struct {
+	Post      *models.BlogPost
+	Link      string
+	Published time.Time
+}
+
 type BlogPostMediaInput struct {
 	MediaType string `json:"media_type"`
 	URL       string `json:"url"`
 	Caption   string `json:"caption"`
 	SortOrder int    `json:"sort_order"`
 }
+
+// AIContentService generates draft product descriptions and blog outlines via the configured LLM.
+// Every generation is stored as a draft that staff must approve before it touches a product or the blog.
+type AIContentService interface {
+	GenerateProductDescription(ctx context.Context, pharmacyID, userID uuid.UUID, in ProductDescriptionInput) (*models.AIGeneration, error)
+	GenerateBlogOutline(ctx context.Context, pharmacyID, userID uuid.UUID, topic, audience string) (*models.AIGeneration, error)
+	ListGenerations(ctx context.Context, pharmacyID uuid.UUID, kind, status *string, limit, offset int) ([]*models.AIGeneration, int64, error)
+	GetGeneration(ctx context.Context, pharmacyID, id uuid.UUID) (*models.AIGeneration, error)
+	// Review approves (optionally with edited text) or rejects a draft. Approving a product description updates the product;
+	// approving a blog outline creates a blog post in draft status authored by the reviewer.
+	Review(ctx context.Context, pharmacyID, reviewerID, id uuid.UUID, approve bool, editedOutput *string) (*models.AIGeneration, error)
+	GetUsage(ctx context.Context, pharmacyID uuid.UUID) (*AIUsage, error)
+}
+
+// ProductDescriptionInput holds structured product fields for description generation.
+// When ProductID is set, blank fields are filled from the product.
+type ProductDescriptionInput struct {
+	ProductID   *uuid.UUID `json:"product_id"`
+	Name        string     `json:"name"`
+	GenericName string     `json:"generic_name"`
+	Brand       string     `json:"brand"`
+	Category    string     `json:"category"`
+	DosageForm  string     `json:"dosage_form"`
+	PackSize    string     `json:"pack_size"`
+	Highlights  []string   `json:"highlights"`
+	Language    string     `json:"language"` // e.g. en, ne; default en
+}
+
+// AIUsage is the current month's generation count against the quota (0 = unlimited).
+type AIUsage struct {
+	Used        int64     `json:"used"`
+	Quota       int       `json:"quota"`
+	PeriodStart time.Time `json:"period_start"`
+	Enabled     bool      `json:"enabled"`
+}
+
+// ReportService serves sales and inventory reports. Date ranges are [from, to); zero values default to the last 30 days.
+type ReportService interface {
+	SalesSummary(ctx context.Context, pharmacyID uuid.UUID, granularity string, from, to time.Time, branchID *uuid.UUID) (*SalesSummaryReport, error)
+	TopSellingProducts(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time, branchID *uuid.UUID, limit int) ([]*models.TopProductRow, error)
+	RevenueByPaymentMethod(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.PaymentMethodRow, error)
+	LowStock(ctx context.Context, pharmacyID uuid.UUID, threshold int) ([]*models.LowStockRow, error)
+	ExpiringStock(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) (*ExpiringStockReport, error)
+	TaxSummary(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) (*TaxSummaryReport, error)
+	// SalesRegister lists invoices and credit notes issued in the range with net totals.
+	SalesRegister(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) (*SalesRegisterReport, error)
+	// DeadStock lists products with stock and no sales in the last days (default 90), worth at least minValue.
+	DeadStock(ctx context.Context, pharmacyID uuid.UUID, days int, minValue float64, limit int) (*DeadStockReport, error)
+}
+
+// DeadStockReport lists unsold stock with the total quantity and value tied up in it.
+type DeadStockReport struct {
+	Days          int                    `json:"days"`
+	Since         time.Time              `json:"since"`
+	Rows          []*models.DeadStockRow `json:"rows"`
+	TotalQuantity int                    `json:"total_quantity"`
+	TotalValue    float64                `json:"total_value"`
+	Currency      string                 `json:"currency"` // the pharmacy's base currency
+}
+
+// SalesRegisterReport is the sales register: issued invoices less credit notes.
+type SalesRegisterReport struct {
+	From          time.Time                  `json:"from"`
+	To            time.Time                  `json:"to"`
+	Rows          []*models.SalesRegisterRow `json:"rows"`
+	TotalTaxable  float64                    `json:"total_taxable"`
+	TotalTax      float64                    `json:"total_tax"`
+	TotalAmount   float64                    `json:"total_amount"`
+	CreditedTotal float64                    `json:"credited_total"` // credit notes in the range, as a positive amount
+	OrderFields   []models.OrderFieldDefinition `json:"order_fields,omitempty"` // the pharmacy's order fields, one export column each
+	Currency      string                     `json:"currency"` // the pharmacy's base currency
+}
+
+// TaxSummaryReport is the VAT collected in a range, grouped by class and rate.
+type TaxSummaryReport struct {
+	From         time.Time         `json:"from"`
+	To           time.Time         `json:"to"`
+	Rows         []*models.TaxLine `json:"rows"`
+	TotalTaxable float64           `json:"total_taxable"`
+	TotalTax     float64           `json:"total_tax"`
+	Currency     string            `json:"currency"` // the pharmacy's base currency
+}
+
+// SalesSummaryReport is the sales summary with per-period rows and range totals.
+type SalesSummaryReport struct {
+	Granularity  string                   `json:"granularity"` // day, week, month
+	From         time.Time                `json:"from"`
+	To           time.Time                `json:"to"`
+	Rows         []*models.SalesPeriodRow `json:"rows"`
+	TotalOrders  int64                    `json:"total_orders"`
+	TotalRevenue float64                  `json:"total_revenue"`
+}
+
+// ExpiringStockReport lists batches expiring in the range with total quantity and value at risk.
+type ExpiringStockReport struct {
+	From          time.Time                  `json:"from"`
+	To            time.Time                  `json:"to"`
+	Rows          []*models.ExpiringStockRow `json:"rows"`
+	TotalQuantity int                        `json:"total_quantity"`
+	TotalValue    float64                    `json:"total_value"`
+}
+
+type SupplierService interface {
+	Create(ctx context.Context, pharmacyID uuid.UUID, s *models.Supplier) (*models.Supplier, error)
+	GetByID(ctx context.Context, pharmacyID, id uuid.UUID) (*models.Supplier, error)
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, activeOnly bool) ([]*models.Supplier, error)
+	Update(ctx context.Context, pharmacyID uuid.UUID, s *models.Supplier) (*models.Supplier, error)
+	Delete(ctx context.Context, pharmacyID, id uuid.UUID) error
+}
+
+// PurchaseOrderService turns reorder suggestions into purchase requests emailed to suppliers and tracks their replies.
+type PurchaseOrderService interface {
+	// ReorderSuggestions groups products at or below their reorder level (threshold when unset) by mapped supplier;
+	// unmapped products have Supplier nil. Quantities follow sales on completed orders over the last salesDays.
+	ReorderSuggestions(ctx context.Context, pharmacyID uuid.UUID, threshold, salesDays int) ([]*ReorderSuggestion, error)
+	// CreateReorderRequest records a draft purchase order for the supplier and emails it with a signed reply link.
+	// When items is empty, the supplier's current reorder suggestions are used.
+	CreateReorderRequest(ctx context.Context, pharmacyID, createdBy, supplierID uuid.UUID, items []PurchaseOrderItemInput, expectedDelivery *time.Time, notes string) (*models.PurchaseOrder, error)
+	// DraftFromSuggestions saves the supplier's reorder suggestions as a draft purchase order without emailing it;
+	// Resend sends it once reviewed.
+	DraftFromSuggestions(ctx context.Context, pharmacyID, createdBy, supplierID uuid.UUID, threshold, salesDays int, notes string) (*models.PurchaseOrder, error)
+	GetByID(ctx context.Context, pharmacyID, id uuid.UUID) (*models.PurchaseOrder, error)
+	List(ctx context.Context, pharmacyID uuid.UUID, status *models.PurchaseOrderStatus, supplierID *uuid.UUID, limit, offset int) ([]*models.PurchaseOrder, int64, error)
+	// Resend emails the purchase request again (e.g. after a delivery failure); only draft orders.
+	Resend(ctx context.Context, pharmacyID, id uuid.UUID) (*models.PurchaseOrder, error)
+	Cancel(ctx context.Context, pharmacyID, id uuid.UUID) (*models.PurchaseOrder, error)
+	MarkReceived(ctx context.Context, pharmacyID, id uuid.UUID) (*models.PurchaseOrder, error)
+
+	// Supplier side (no login): token comes from the signed link in the email.
+	GetForSupplier(ctx context.Context, id uuid.UUID, token string) (*models.PurchaseOrder, error)
+	RespondAsSupplier(ctx context.Context, id uuid.UUID, token string, accept bool, note string, deliveryDate *time.Time) (*models.PurchaseOrder, error)
+}
+
+type PurchaseOrderItemInput struct {
+	ProductID uuid.UUID `json:"product_id" binding:"required"`
+	Quantity  int       `json:"quantity" binding:"required,gt=0"`
+}
+
+type ReorderSuggestion struct {
+	Supplier *models.Supplier        `json:"supplier"`
+	Items    []ReorderSuggestionItem `json:"items"`
+}
+
+type ReorderSuggestionItem struct {
+	ProductID         uuid.UUID `json:"product_id"`
+	Name              string    `json:"name"`
+	SKU               string    `json:"sku"`
+	StockQuantity     int       `json:"stock_quantity"`
+	ReorderLevel      int       `json:"reorder_level"`
+	ReorderQuantity   *int      `json:"reorder_quantity,omitempty"`
+	MaxStock          *int      `json:"max_stock,omitempty"`
+	UnitsSold         int       `json:"units_sold"`              // completed-order units in the sales window
+	DailySales        float64   `json:"daily_sales"`
+	DaysOfStock       *float64  `json:"days_of_stock,omitempty"` // at current velocity; nil without sales
+	SuggestedQuantity int       `json:"suggested_quantity"`
+}
+
+type FlashSaleService interface {
+	Create(ctx context.Context, pharmacyID, createdBy uuid.UUID, input FlashSaleInput) (*models.FlashSale, error)
+	GetByID(ctx context.Context, pharmacyID, id uuid.UUID) (*models.FlashSale, error)
+	List(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.FlashSale, int64, error)
+	// Update changes sale fields; items can only be replaced before the sale starts.
+	Update(ctx context.Context, pharmacyID, id uuid.UUID, input FlashSaleInput) (*models.FlashSale, error)
+	Delete(ctx context.Context, pharmacyID, id uuid.UUID) error
+	// ListLiveOffers returns offers running now with remaining quantity and countdown; productIDs filters when non-empty.
+	// earlyAccess (a membership benefit) also includes sales opening within that window.
+	ListLiveOffers(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID, earlyAccess time.Duration) ([]*FlashSaleOffer, error)
+
+	// ReserveForOrder takes units from live sale caps for the order lines and enforces per-customer limits.
+	// The result is aligned with items (nil where no sale applies). Release on failure, commit once the order exists.
+	// earlyAccess lets members buy from sales opening within that window.
+	ReserveForOrder(ctx context.Context, pharmacyID uuid.UUID, customerKey string, earlyAccess time.Duration, items []OrderItemInput) ([]*FlashSaleReservation, error)
+	ReleaseReservations(ctx context.Context, reservations []*FlashSaleReservation)
+	CommitReservations(ctx context.Context, orderID uuid.UUID, reservations []*FlashSaleReservation) error
+	// ReleaseForOrder returns a cancelled order's units to the sale caps and per-customer counts.
+	ReleaseForOrder(ctx context.Context, orderID uuid.UUID) error
+}
+
+type FlashSaleInput struct {
+	Name        string               `json:"name" binding:"required"`
+	Description string               `json:"description"`
+	StartsAt    time.Time            `json:"starts_at" binding:"required"`
+	EndsAt      time.Time            `json:"ends_at" binding:"required"`
+	IsActive    *bool                `json:"is_active"`
+	Items       []FlashSaleItemInput `json:"items" binding:"dive"`
+}
+
+type FlashSaleItemInput struct {
+	ProductID        uuid.UUID `json:"product_id" binding:"required"`
+	SalePrice        float64   `json:"sale_price" binding:"gt=0"`
+	MaxQuantity      int       `json:"max_quantity" binding:"gte=0"`       // 0 = unlimited
+	PerCustomerLimit int       `json:"per_customer_limit" binding:"gte=0"` // 0 = unlimited
+}
+
+// FlashSaleOffer is a live flash-sale price for one product, with countdown metadata for the storefront.
+type FlashSaleOffer struct {
+	FlashSaleID      uuid.UUID       `json:"flash_sale_id"`
+	FlashSaleItemID  uuid.UUID       `json:"flash_sale_item_id"`
+	Name             string          `json:"name"`
+	ProductID        uuid.UUID       `json:"product_id"`
+	Product          *models.Product `json:"product,omitempty"`
+	SalePrice        float64         `json:"sale_price"`
+	RegularPrice     float64         `json:"regular_price"`
+	StartsAt         time.Time       `json:"starts_at"`
+	EndsAt           time.Time       `json:"ends_at"`
+	EndsInSeconds    int64           `json:"ends_in_seconds"`
+	Remaining        *int            `json:"remaining,omitempty"` // nil when the sale has no cap
+	PerCustomerLimit int             `json:"per_customer_limit,omitempty"`
+	EarlyAccess      bool            `json:"early_access,omitempty"` // not yet open to everyone; offered through a membership benefit
+}
+
+type FlashSaleReservation struct {
+	FlashSaleItemID uuid.UUID
+	ProductID       uuid.UUID
+	Quantity        int
+	SalePrice       float64
+	CustomerKey     string // set when the units were also counted against the per-customer limit
+}
+
+type PreorderService interface {
+	// Create places a preorder for a product with preorder enabled. With a payment gateway the deposit
+	// (or full amount when PayFull) is recorded as paid, like mock payments on orders.
+	Create(ctx context.Context, pharmacyID, createdBy uuid.UUID, input PreorderInput) (*models.Preorder, error)
+	// GetByID returns the preorder; when createdBy is set it must match (end users see only their own).
+	GetByID(ctx context.Context, pharmacyID, id uuid.UUID, createdBy *uuid.UUID) (*models.Preorder, error)
+	List(ctx context.Context, pharmacyID uuid.UUID, status *models.PreorderStatus, productID, createdBy *uuid.UUID, limit, offset int) ([]*models.Preorder, int64, error)
+	Cancel(ctx context.Context, pharmacyID, id uuid.UUID, createdBy *uuid.UUID) (*models.Preorder, error)
+
+	// LinkPurchaseOrder attaches pending, unlinked preorders for the PO's products and refreshes their ETA.
+	LinkPurchaseOrder(ctx context.Context, po *models.PurchaseOrder) error
+	// RefreshETAs recomputes the ETA of pending preorders for a product and notifies customers whose ETA changed.
+	RefreshETAs(ctx context.Context, pharmacyID, productID uuid.UUID) error
+	// ConvertForPurchaseOrder turns pending preorders for the received PO's products into orders, oldest first, while stock lasts.
+	ConvertForPurchaseOrder(ctx context.Context, po *models.PurchaseOrder) error
+}
+
+type PreorderInput struct {
+	ProductID        uuid.UUID  `json:"product_id" binding:"required"`
+	Quantity         int        `json:"quantity" binding:"required,min=1"`
+	CustomerName     string     `json:"customer_name"`
+	CustomerPhone    string     `json:"customer_phone"`
+	CustomerEmail    string     `json:"customer_email"`
+	Notes            string     `json:"notes"`
+	PayFull          bool       `json:"pay_full"` // pay the whole amount now instead of the deposit
+	PaymentGatewayID *uuid.UUID `json:"payment_gateway_id"`
+}
+
+// MailerService renders and sends transactional emails. Messages go through the configured EmailSender,
+// which queues them in production, so callers may invoke these inline without adding request latency.
+type MailerService interface {
+	// OrderConfirmation emails the order summary to order.CustomerEmail; no-op when it is empty.
+	OrderConfirmation(ctx context.Context, order *models.Order) error
+	// OrderStatusChanged tells the customer the order's current status; no-op without a customer email.
+	OrderStatusChanged(ctx context.Context, order *models.Order) error
+	InvoiceIssued(ctx context.Context, invoice *models.Invoice, order *models.Order) error
+	PasswordReset(ctx context.Context, user *models.User, resetURL string, expiresAt time.Time) error
+	// LoginCode emails the one-time code that completes a two-factor login.
+	LoginCode(ctx context.Context, user *models.User, code string, expiresAt time.Time) error
+	// StaffAccountCreated welcomes a new user and links to the sign-in page; the password is never included.
+	StaffAccountCreated(ctx context.Context, user *models.User) error
+	// CampaignMessage emails a campaign message in a greeting of the recipient's language; no-op without an address.
+	CampaignMessage(ctx context.Context, pharmacyID uuid.UUID, to, name, language, subject, message string) error
+	// WarrantyClaimUpdated emails the claim's status to the warranty's customer; no-op without an email. The claim
+	// must carry its warranty and product.
+	WarrantyClaimUpdated(ctx context.Context, claim *models.WarrantyClaim) error
+}
+
+// TrainingService manages SOP documents and the short quizzes attached to announcements or SOPs. Team members
+// (managers and pharmacists) must pass a quiz for the announcement/SOP to count as acknowledged.
+type TrainingService interface {
+	CreateSOP(ctx context.Context, pharmacyID, createdBy uuid.UUID, input SOPInput) (*models.SOPDocument, error)
+	GetSOP(ctx context.Context, pharmacyID, id uuid.UUID) (*models.SOPDocument, error)
+	ListSOPs(ctx context.Context, pharmacyID uuid.UUID, activeOnly bool) ([]*models.SOPDocument, error)
+	UpdateSOP(ctx context.Context, pharmacyID, id uuid.UUID, input SOPInput) (*models.SOPDocument, error)
+	DeleteSOP(ctx context.Context, pharmacyID, id uuid.UUID) error
+	// AcknowledgeSOP records that the user read the SOP; fails while an attached quiz is not passed.
+	AcknowledgeSOP(ctx context.Context, pharmacyID, userID, sopID uuid.UUID) error
+
+	CreateQuiz(ctx context.Context, pharmacyID, createdBy uuid.UUID, input TrainingQuizInput) (*models.TrainingQuiz, error)
+	// GetQuiz returns the quiz including correct answers (managers).
+	GetQuiz(ctx context.Context, pharmacyID, id uuid.UUID) (*models.TrainingQuiz, error)
+	ListQuizzes(ctx context.Context, pharmacyID uuid.UUID) ([]*models.TrainingQuiz, error)
+	UpdateQuiz(ctx context.Context, pharmacyID, id uuid.UUID, input TrainingQuizInput) (*models.TrainingQuiz, error)
+	DeleteQuiz(ctx context.Context, pharmacyID, id uuid.UUID) error
+
+	// GetQuizForUser returns the quiz without answers, plus whether the user has already passed it.
+	GetQuizForUser(ctx context.Context, pharmacyID, userID, quizID uuid.UUID) (*TrainingQuizView, error)
+	// SubmitAttempt scores the answers; a passing attempt acknowledges the linked announcement or SOP.
+	SubmitAttempt(ctx context.Context, pharmacyID, userID, quizID uuid.UUID, answers []int) (*models.TrainingAttempt, error)
+	// MyTraining returns the caller's status on every active quiz and SOP.
+	MyTraining(ctx context.Context, pharmacyID, userID uuid.UUID) (*StaffTrainingCompliance, error)
+	// Compliance reports each active team member's status on every active quiz and SOP.
+	Compliance(ctx context.Context, pharmacyID uuid.UUID) (*TrainingComplianceReport, error)
+}
+
+type SOPInput struct {
+	Title    string `json:"title" binding:"required"`
+	Body     string `json:"body"`
+	FileURL  string `json:"file_url"`
+	Version  string `json:"version"`
+	IsActive *bool  `json:"is_active"`
+}
+
+// TrainingQuizInput attaches a quiz to exactly one of AnnouncementID or SOPDocumentID.
+type TrainingQuizInput struct {
+	AnnouncementID *uuid.UUID            `json:"announcement_id"`
+	SOPDocumentID  *uuid.UUID            `json:"sop_document_id"`
+	Title          string                `json:"title" binding:"required"`
+	Questions      []models.QuizQuestion `json:"questions" binding:"required,min=1"`
+	PassPercent    int                   `json:"pass_percent" binding:"omitempty,min=1,max=100"` // default 80
+	IsActive       *bool                 `json:"is_active"`
+}
+
+type TrainingQuizView struct {
+	ID             uuid.UUID                  `json:"id"`
+	Title          string                     `json:"title"`
+	AnnouncementID *uuid.UUID                 `json:"announcement_id,omitempty"`
+	SOPDocumentID  *uuid.UUID                 `json:"sop_document_id,omitempty"`
+	PassPercent    int                        `json:"pass_percent"`
+	Questions      []TrainingQuizQuestionView `json:"questions"`
+	Passed         bool                       `json:"passed"`
+}
+
+type TrainingQuizQuestionView struct {
+	Prompt  string   `json:"prompt"`
+	Options []string `json:"options"`
+}
+
+// TrainingItem is one required piece of training: a quiz, or an SOP without a quiz (read and acknowledge).
+type TrainingItem struct {
+	Kind           string     `json:"kind"` // "quiz" or "sop"
+	ID             uuid.UUID  `json:"id"`
+	Title          string     `json:"title"`
+	AnnouncementID *uuid.UUID `json:"announcement_id,omitempty"`
+	SOPDocumentID  *uuid.UUID `json:"sop_document_id,omitempty"`
+}
+
+type TrainingItemStatus struct {
+	ItemID       uuid.UUID  `json:"item_id"`
+	Status       string     `json:"status"` // passed, failed, acknowledged, not_started
+	Attempts     int        `json:"attempts,omitempty"`
+	BestScore    int        `json:"best_score,omitempty"`
+	LastActivity *time.Time `json:"last_activity,omitempty"`
+}
+
+type StaffTrainingCompliance struct {
+	UserID    uuid.UUID             `json:"user_id"`
+	Name      string                `json:"name"`
+	Email     string                `json:"email"`
+	Role      string                `json:"role"`
+	Required  int                   `json:"required"`
+	Completed int                   `json:"completed"`
+	Compliant bool                  `json:"compliant"`
+	Items     []*TrainingItemStatus `json:"items"`
+}
+
+type TrainingComplianceReport struct {
+	Items []*TrainingItem            `json:"items"`
+	Staff []*StaffTrainingCompliance `json:"staff"`
+	// CompliantCount is the number of team members who completed every item.
+	CompliantCount int `json:"compliant_count"`
+}
+
+// SMSNotificationService texts customers about their orders when the pharmacy enables the sms_order_updates flag.
+type SMSNotificationService interface {
+	// OrderStatusChanged sends an SMS when the order is confirmed, ready or completed; no-op for other
+	// statuses, orders without a customer phone, or when the flag is off.
+	OrderStatusChanged(ctx context.Context, order *models.Order) error
+	// PasswordReset texts the reset link to the user's phone when the pharmacy enables the sms_otp flag.
+	PasswordReset(ctx context.Context, user *models.User, resetURL string) error
+	// WarrantyClaimUpdated texts the claim's status to the warranty's customer when the pharmacy enables the
+	// sms_order_updates flag. The claim must carry its warranty and product.
+	WarrantyClaimUpdated(ctx context.Context, claim *models.WarrantyClaim) error
+}
+
+// OTPService issues and checks one-time codes sent by SMS (requires the sms_otp flag).
+type OTPService interface {
+	Send(ctx context.Context, pharmacyID uuid.UUID, phone, purpose string) (*OTPSendResult, error)
+	// Verify consumes the latest code for the phone and purpose; wrong codes count towards an attempt limit.
+	Verify(ctx context.Context, pharmacyID uuid.UUID, phone, purpose, code string) error
+}
+
+type OTPSendResult struct {
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ExpiryDiscountService applies the pharmacy's near-expiry markdown policy (PharmacyConfig.ExpiryDiscount).
+type ExpiryDiscountService interface {
+	// ListOffers returns the current short-expiry discount per product, soonest expiry first; productIDs filters when non-empty.
+	ListOffers(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID) ([]*ExpiryDiscountOffer, error)
+}
+
+// ExpiryDiscountOffer is the automatic markdown for one product, driven by its earliest unexpired batch.
+type ExpiryDiscountOffer struct {
+	ProductID       uuid.UUID       `json:"product_id"`
+	Product         *models.Product `json:"product,omitempty"`
+	BatchNumber     string          `json:"batch_number"`
+	ExpiryDate      time.Time       `json:"expiry_date"`
+	DaysToExpiry    int             `json:"days_to_expiry"`
+	DiscountPercent float64         `json:"discount_percent"`
+	RegularPrice    float64         `json:"regular_price"`
+	SalePrice       float64         `json:"sale_price"`
+	Label           string          `json:"label"` // e.g. "Short expiry: best before 2026-11-30"
+}
+
+// PromoImageService turns an uploaded image into banner and thumbnail renditions, stores them and attaches
+// the resulting image set to a promo or announcement.
+type PromoImageService interface {
+	SetPromoImage(ctx context.Context, pharmacyID, promoID uuid.UUID, body io.Reader) (*models.Promo, error)
+	SetAnnouncementImage(ctx context.Context, pharmacyID, announcementID uuid.UUID, body io.Reader) (*models.Announcement, error)
+}
+
+// RoleService manages a pharmacy's roles and resolves which permissions a role grants.
+type RoleService interface {
+	ListPermissions() []PermissionInfo
+	// ListRoles returns the built-in roles followed by the pharmacy's custom roles.
+	ListRoles(ctx context.Context, pharmacyID uuid.UUID) ([]*RoleView, error)
+	CreateRole(ctx context.Context, pharmacyID uuid.UUID, input RoleInput) (*RoleView, error)
+	// UpdateRole changes a custom role, or customizes the manager or pharmacist permission set.
+	UpdateRole(ctx context.Context, pharmacyID uuid.UUID, name string, input RoleInput) (*RoleView, error)
+	// DeleteRole removes a custom role that no user holds; for manager or pharmacist it restores the defaults.
+	DeleteRole(ctx context.Context, pharmacyID uuid.UUID, name string) error
+	// Permissions returns the permission set of a role in a pharmacy. Results are cached for a short time
+	// and dropped whenever the pharmacy's roles change.
+	Permissions(ctx context.Context, pharmacyID uuid.UUID, role string) (map[string]bool, error)
+	// IsAssignable reports whether users of the pharmacy may be given this role (built-in team roles or a custom role).
+	IsAssignable(ctx context.Context, pharmacyID uuid.UUID, role string) (bool, error)
+}
+
+type PermissionInfo struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+}
+
+type RoleInput struct {
+	Name        string   `json:"name"` // create only; lowercase letters, digits, "_" and "-"
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// RoleView is a role with its effective permissions.
+type RoleView struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	BuiltIn     bool     `json:"built_in"`
+	Customized  bool     `json:"customized"` // built-in role whose permissions the pharmacy changed
+	Editable    bool     `json:"editable"`
+}
+
+// CartService manages the logged-in user's server-side cart and turns it into an order.
+// Every method returns the cart with totals recomputed from current prices.
+type CartService interface {
+	Get(ctx context.Context, pharmacyID, userID uuid.UUID) (*CartView, error)
+	// AddItem adds quantity to the product's line, creating the cart and line as needed.
+	AddItem(ctx context.Context, pharmacyID, userID, productID uuid.UUID, quantity int) (*CartView, error)
+	// UpdateItem sets the line quantity; 0 removes the line.
+	UpdateItem(ctx context.Context, pharmacyID, userID, productID uuid.UUID, quantity int) (*CartView, error)
+	RemoveItem(ctx context.Context, pharmacyID, userID, productID uuid.UUID) (*CartView, error)
+	Clear(ctx context.Context, pharmacyID, userID uuid.UUID) (*CartView, error)
+	// ApplyCodes validates and stores the promo code, referral code and points to redeem. Nil fields are left
+	// unchanged; an empty string or 0 removes the value.
+	ApplyCodes(ctx context.Context, pharmacyID, userID uuid.UUID, input CartCodesInput) (*CartView, error)
+	// Checkout places an order from the cart. The cart is claimed first so a double submit cannot create two orders.
+	Checkout(ctx context.Context, pharmacyID, userID uuid.UUID, input CartCheckoutInput) (*models.Order, error)
+	// ValidateCheckout reports every problem an order from the cart (or from input.Items) would run into, with
+	// the final totals, without reserving or changing anything.
+	ValidateCheckout(ctx context.Context, pharmacyID, userID uuid.UUID, input CheckoutValidationInput) (*CheckoutValidation, error)
+}
+
+// CheckoutValidationInput is the checkout being prepared. Items, PromoCode and PointsToRedeem default to the
+// user's open cart.
+type CheckoutValidationInput struct {
+	Items            []CheckoutItemInput `json:"items" binding:"omitempty,max=100,dive"`
+	DeliveryAddress  string              `json:"delivery_address"`
+	PromoCode        *string             `json:"promo_code"`
+	PointsToRedeem   *int                `json:"points_to_redeem" binding:"omitempty,min=0"`
+	PaymentGatewayID *uuid.UUID          `json:"payment_gateway_id"`
+}
+
+type CheckoutItemInput struct {
+	ProductID uuid.UUID `json:"product_id" binding:"required"`
+	Quantity  int       `json:"quantity" binding:"required,min=1"`
+}
+
+// Checkout issue severities: errors make POST /orders fail; warnings do not.
+const (
+	CheckoutIssueError   = "error"
+	CheckoutIssueWarning = "warning"
+)
+
+// CheckoutIssue is one problem found by ValidateCheckout.
+type CheckoutIssue struct {
+	Code      string     `json:"code"` // empty_cart, unavailable, out_of_stock, insufficient_stock, prescription_required, promo_code, points, delivery_area, payment_method
+	Severity  string     `json:"severity"`
+	ProductID *uuid.UUID `json:"product_id,omitempty"`
+	Message   string     `json:"message"`
+}
+
+// CheckoutValidation is the consolidated result of ValidateCheckout. Valid is false while any issue is an error.
+type CheckoutValidation struct {
+	Valid                bool                  `json:"valid"`
+	Issues               []CheckoutIssue       `json:"issues"`
+	Cart                 *CartView             `json:"cart"`
+	RequiresPrescription bool                  `json:"requires_prescription"`
+	Delivery             CheckoutDeliveryCheck `json:"delivery"`
+	Payment              CheckoutPaymentCheck  `json:"payment"`
+	TotalAmount          float64               `json:"total_amount"` // cart total plus delivery fee
+}
+
+type CheckoutDeliveryCheck struct {
+	Requested bool    `json:"requested"` // a delivery address was given
+	Available bool    `json:"available"`
+	Fee       float64 `json:"fee"`
+	FeeWaived float64 `json:"fee_waived"` // waived by the customer's membership tier
+}
+
+type CheckoutPaymentCheck struct {
+	GatewayID *uuid.UUID              `json:"gateway_id,omitempty"`
+	Available bool                    `json:"available"` // the selected gateway can be used; true when none is selected
+	Methods   []CheckoutPaymentMethod `json:"methods"`   // active gateways to choose from
+}
+
+type CheckoutPaymentMethod struct {
+	ID   uuid.UUID `json:"id"`
+	Code string    `json:"code"`
+	Name string    `json:"name"`
+}
+
+type CartCodesInput struct {
+	PromoCode      *string `json:"promo_code"`
+	ReferralCode   *string `json:"referral_code"`
+	PointsToRedeem *int    `json:"points_to_redeem"`
+}
+
+// CartCheckoutInput holds order details not kept on the cart. Name and phone default to the user's profile.
+type CartCheckoutInput struct {
+	CustomerName     string     `json:"customer_name"`
+	CustomerPhone    string     `json:"customer_phone"`
+	CustomerEmail    string     `json:"customer_email"`
+	DeliveryAddress  string     `json:"delivery_address"`
+	Notes            string     `json:"notes"`
+	PaymentGatewayID *uuid.UUID `json:"payment_gateway_id"`
+	GiftCardCode     string     `json:"gift_card_code" binding:"max=40"`
+	StoreCredit      float64    `json:"store_credit" binding:"min=0"`
+	CustomFields     map[string]any `json:"custom_fields"` // customer-visible order fields
+}
+
+// CartLine is one cart line priced at the current effective price.
+type CartLine struct {
+	ProductID    uuid.UUID `json:"product_id"`
+	Name         string    `json:"name"`
+	SKU          string    `json:"sku"`
+	Quantity     int       `json:"quantity"`
+	UnitPrice    float64   `json:"unit_price"`    // effective price
+	RegularPrice float64   `json:"regular_price"` // product unit_price
+	PriceSource  string    `json:"price_source"`  // regular, flash_sale, short_expiry
+	LineTotal    float64   `json:"line_total"`
+	Available    int       `json:"available"`
+	Problem      string    `json:"problem,omitempty"` // e.g. "out of stock", "unavailable"; checkout fails while set
+}
+
+// CartView is a cart with server-computed totals. Discounts mirror what order creation will apply.
+type CartView struct {
+	ID                 *uuid.UUID `json:"id,omitempty"` // nil until the first item is added
+	Items              []CartLine `json:"items"`
+	ItemCount          int        `json:"item_count"`
+	PromoCode          string     `json:"promo_code,omitempty"`
+	PromoError         string     `json:"promo_error,omitempty"` // set when the stored code no longer applies
+	ReferralCode       string     `json:"referral_code,omitempty"`
+	PointsToRedeem     int        `json:"points_to_redeem"`
+	PointsBalance      int        `json:"points_balance"`
+	MaxRedeemable      int        `json:"max_redeemable"`
+	SubTotal           float64    `json:"sub_total"`
+	MembershipName     string     `json:"membership_name,omitempty"`
+	MembershipDiscount float64    `json:"membership_discount"`
+	PromoDiscount      float64    `json:"promo_discount"`
+	PromoItems         []PromoLineDiscount `json:"promo_items,omitempty"` // products the promo discount applies to
+	PointsDiscount     float64    `json:"points_discount"`
+	DiscountAmount     float64    `json:"discount_amount"` // total, capped at sub_total
+	TaxAmount          float64    `json:"tax_amount"`
+	TaxInclusive       bool       `json:"tax_inclusive"`
+	TotalAmount        float64    `json:"total_amount"`
+	Currency           string     `json:"currency"`
+}
+
+// DeliveryService tracks order deliveries from packing to hand-over and notifies the customer at each stage.
+// Staff with deliveries.manage can act on any delivery; the assigned delivery person can update their own.
+type DeliveryService interface {
+	// Create starts delivery tracking for an order in the packed stage.
+	Create(ctx context.Context, pharmacyID, orderID, actorID uuid.UUID, assignedTo *uuid.UUID, note string) (*models.Delivery, error)
+	GetByOrder(ctx context.Context, pharmacyID, orderID uuid.UUID) (*models.Delivery, error)
+	List(ctx context.Context, pharmacyID uuid.UUID, status *models.DeliveryStatus, assignedTo *uuid.UUID) ([]*models.Delivery, error)
+	Assign(ctx context.Context, pharmacyID, orderID, actorID, assigneeID uuid.UUID) (*models.Delivery, error)
+	// UpdateStatus moves the delivery forward (stages may be skipped, never reversed).
+	UpdateStatus(ctx context.Context, pharmacyID, orderID, actorID uuid.UUID, canManage bool, input DeliveryUpdateInput) (*models.Delivery, error)
+	// UpdateLocation records a GPS position and/or location note without changing the stage.
+	UpdateLocation(ctx context.Context, pharmacyID, orderID, actorID uuid.UUID, canManage bool, input DeliveryUpdateInput) (*models.Delivery, error)
+	// AttachProof stores the recipient's signature or a doorstep photo (kind "signature" or "photo") on a
+	// delivery that is not yet delivered, replacing an earlier one of the same kind.
+	AttachProof(ctx context.Context, pharmacyID, orderID, actorID uuid.UUID, canManage bool, kind string, body io.Reader) (*models.Delivery, error)
+	// SendOTP (re)sends the delivery code to the customer, invalidating earlier codes. It is sent automatically
+	// when a delivery goes out for delivery and the pharmacy requires codes.
+	SendOTP(ctx context.Context, pharmacyID, orderID, actorID uuid.UUID, canManage bool) (*models.Delivery, error)
+	// Track returns the customer-facing view. When customerUserID is set the order must belong to that user.
+	Track(ctx context.Context, pharmacyID, orderID uuid.UUID, customerUserID *uuid.UUID) (*DeliveryTracking, error)
+	// SetDestination records the drop-off coordinates and optional delivery window used for route planning.
+	SetDestination(ctx context.Context, pharmacyID, orderID uuid.UUID, input DeliveryDestinationInput) (*models.Delivery, error)
+}
+
+type DeliveryDestinationInput struct {
+	Latitude    *float64   `json:"latitude" binding:"required"`
+	Longitude   *float64   `json:"longitude" binding:"required"`
+	WindowStart *time.Time `json:"window_start"`
+	WindowEnd   *time.Time `json:"window_end"`
+}
+
+type DeliveryUpdateInput struct {
+	Status    models.DeliveryStatus `json:"status"`
+	Note      string                `json:"note"`
+	Latitude  *float64              `json:"latitude"`
+	Longitude *float64              `json:"longitude"`
+	// Proof recorded when Status is delivered. OTP is required when the pharmacy requires delivery codes.
+	ReceivedBy       string `json:"received_by"`
+	ReceiverRelation string `json:"receiver_relation"`
+	OTP              string `json:"otp"`
+}
+
+// DeliveryTracking is what the customer sees: stage, timestamps, courier first name and last known location.
+type DeliveryTracking struct {
+	OrderID          uuid.UUID               `json:"order_id"`
+	OrderNumber      string                  `json:"order_number"`
+	Status           models.DeliveryStatus   `json:"status"`
+	CourierName      string                  `json:"courier_name,omitempty"`
+	LocationNote     string                  `json:"location_note,omitempty"`
+	Latitude         *float64                `json:"latitude,omitempty"`
+	Longitude        *float64                `json:"longitude,omitempty"`
+	LocationAt       *time.Time              `json:"location_at,omitempty"`
+	PackedAt         *time.Time              `json:"packed_at,omitempty"`
+	DispatchedAt     *time.Time              `json:"dispatched_at,omitempty"`
+	OutForDeliveryAt *time.Time              `json:"out_for_delivery_at,omitempty"`
+	DeliveredAt      *time.Time              `json:"delivered_at,omitempty"`
+	Proof            *models.DeliveryProof   `json:"proof,omitempty"`
+	Timeline         []DeliveryTrackingEvent `json:"timeline"`
+}
+
+type DeliveryTrackingEvent struct {
+	Status    models.DeliveryStatus `json:"status"`
+	Note      string                `json:"note,omitempty"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+// DispatchService groups packed deliveries of ready orders into one batch per rider and orders each batch's
+// stops with a nearest-neighbour heuristic that respects delivery windows. Riders get the manifest with
+// per-stop ETAs pushed to their app.
+type DispatchService interface {
+	// PlanBatches plans routes for the given riders. Without DryRun the batches are saved, each delivery is
+	// assigned to its rider with stop number and ETA, and the riders are notified.
+	PlanBatches(ctx context.Context, pharmacyID, actorID uuid.UUID, input DispatchPlanInput) (*DispatchPlan, error)
+	// GetBatch returns a batch's manifest. When riderID is set the batch must be that rider's.
+	GetBatch(ctx context.Context, pharmacyID, batchID uuid.UUID, riderID *uuid.UUID) (*DispatchManifest, error)
+	// ListBatches returns batches newest first (without stops), only riderID's when set.
+	ListBatches(ctx context.Context, pharmacyID uuid.UUID, riderID *uuid.UUID, limit, offset int) ([]*models.DispatchBatch, int64, error)
+}
+
+// DispatchPlanInput selects riders and deliveries and tunes the travel estimate. Without OrderIDs every packed
+// delivery of a ready order that is not yet in a batch is planned; listing an order re-plans it.
+type DispatchPlanInput struct {
+	RiderIDs       []uuid.UUID `json:"rider_ids" binding:"required,min=1"`
+	StartLatitude  *float64    `json:"start_latitude" binding:"required"`
+	StartLongitude *float64    `json:"start_longitude" binding:"required"`
+	DepartAt       *time.Time  `json:"depart_at"`       // default now
+	OrderIDs       []uuid.UUID `json:"order_ids"`       // optional subset
+	MaxStops       int         `json:"max_stops"`       // per rider, default 20, at most 50
+	SpeedKmh       float64     `json:"speed_kmh"`       // average travel speed, default 20
+	ServiceMinutes int         `json:"service_minutes"` // time spent at each stop, default 5
+	DryRun         bool        `json:"dry_run"`
+}
+
+// DispatchPlan is the result of planning: one manifest per rider that got stops, and the deliveries left out.
+type DispatchPlan struct {
+	Batches  []*DispatchManifest `json:"batches"`
+	Unrouted []DispatchSkip      `json:"unrouted"`
+}
+
+// DispatchManifest is a rider's route: the batch summary and its stops in visiting order.
+type DispatchManifest struct {
+	Batch *models.DispatchBatch `json:"batch"`
+	Stops []DispatchStop        `json:"stops"`
+}
+
+type DispatchStop struct {
+	Sequence      int                   `json:"sequence"`
+	OrderID       uuid.UUID             `json:"order_id"`
+	OrderNumber   string                `json:"order_number"`
+	CustomerName  string                `json:"customer_name"`
+	CustomerPhone string                `json:"customer_phone"`
+	Address       string                `json:"address"`
+	Latitude      float64               `json:"latitude"`
+	Longitude     float64               `json:"longitude"`
+	LegKm         float64               `json:"leg_km"` // from the previous stop (or the start point)
+	ETA           *time.Time            `json:"eta,omitempty"`
+	WindowStart   *time.Time            `json:"window_start,omitempty"`
+	WindowEnd     *time.Time            `json:"window_end,omitempty"`
+	Late          bool                  `json:"late"` // ETA is after the window end
+	Status        models.DeliveryStatus `json:"status"`
+}
+
+// DispatchSkip is a delivery that was not planned and why.
+type DispatchSkip struct {
+	OrderID     uuid.UUID `json:"order_id"`
+	OrderNumber string    `json:"order_number,omitempty"`
+	Reason      string    `json:"reason"`
+}
+
+// PushNotificationService keeps users' device registrations and fans notifications out to them through
+// the PushSender port. Fan-out is best effort: failures are logged and never fail the caller's action.
+type PushNotificationService interface {
+	RegisterDevice(ctx context.Context, pharmacyID, userID uuid.UUID, token, platform string) (*models.DeviceToken, error)
+	UnregisterDevice(ctx context.Context, userID uuid.UUID, token string) error
+	ListDevices(ctx context.Context, userID uuid.UUID) ([]*models.DeviceToken, error)
+	// NotifyUser pushes to every registered device of the user. path is the app route opened on click.
+	NotifyUser(ctx context.Context, userID uuid.UUID, title, body, path string, data map[string]string) error
+	// OrderStatusChanged pushes confirmed, ready, completed and cancelled updates to the buyer who placed the order.
+	OrderStatusChanged(ctx context.Context, order *models.Order) error
+	// ChatMessage pushes a new message to the other side: the buyer when staff reply, otherwise the pharmacy team.
+	ChatMessage(ctx context.Context, conv *models.Conversation, msg *models.ChatMessage) error
+	// AnnouncementPublished pushes a live, active announcement to every active user of the pharmacy.
+	AnnouncementPublished(ctx context.Context, a *models.Announcement) error
+}
+
+// ChatEscalationService hands chat conversations over to an external ticketing system. Escalation creates a
+// ticket (when a provider is configured), stores its id on the conversation and posts system chat messages;
+// the ticketing system reports status changes and comments back through HandleTicketWebhook.
+type ChatEscalationService interface {
+	// Escalate marks the conversation escalated and opens a ticket. Calling it again after a failed ticket
+	// creation retries; while a ticket is open it returns a conflict, and after resolution it opens a new one.
+	Escalate(ctx context.Context, pharmacyID, conversationID, actorID uuid.UUID, reason string) (*models.Conversation, error)
+	// HandleTicketWebhook authenticates the shared secret, applies the update and posts it to the conversation.
+	HandleTicketWebhook(ctx context.Context, secret string, body []byte) error
+}
+
+// DeliveryQueueService is the durable outbound queue for email, SMS and webhooks. Enqueued messages are stored
+// before the call returns and delivered by background workers; failures are retried with exponential backoff
+// and, after the maximum attempts, kept as dead letters until retried manually.
+type DeliveryQueueService interface {
+	EnqueueEmail(ctx context.Context, msg *outbound.EmailMessage) error
+	EnqueueSMS(ctx context.Context, msg *outbound.SMSMessage) error
+	EnqueueWebhook(ctx context.Context, msg *outbound.WebhookMessage) error
+	// ProcessDue delivers the jobs that are due now and returns how many were attempted.
+	ProcessDue(ctx context.Context) (int, error)
+	ListDeadLetters(ctx context.Context, pharmacyID uuid.UUID, kind string, limit, offset int) ([]*models.DeliveryJob, int64, error)
+	// Retry puts a dead job of the pharmacy back in the queue with a fresh set of attempts.
+	Retry(ctx context.Context, pharmacyID, jobID uuid.UUID) (*models.DeliveryJob, error)
+	// Start runs the workers; Close stops them, waiting for in-flight deliveries or until ctx expires.
+	Start()
+	Close(ctx context.Context) error
+}
+
+// EventHandler handles one domain event. Returning an error makes the event bus retry it later, so handlers
+// should be safe to run again for the same event.
+type EventHandler func(ctx context.Context, e *models.OutboxEvent) error
+
+// EventBus dispatches outbox events to subscribers after the change that raised them has committed. Each
+// subscriber that succeeds is recorded on the event, so a retry only re-runs the subscribers that failed.
+type EventBus interface {
+	// Subscribe registers h for eventType; subscriber names h and must be unique per event type.
+	Subscribe(eventType, subscriber string, h EventHandler)
+	// DispatchDue delivers the events that are due now.
+	DispatchDue(ctx context.Context) error
+	// PurgeDispatched deletes events that were dispatched successfully more than a week ago.
+	PurgeDispatched(ctx context.Context) error
+}
+
+// IdempotencyService makes retried create requests safe: the first request with an Idempotency-Key runs, and
+// a retry with the same key and body gets the stored response instead of running again.
+type IdempotencyService interface {
+	// Begin claims key for the user's request to scope. It returns the stored record with replay true when the
+	// request already finished; a conflict while the first request is still running; and a validation error
+	// when the key was used for a different body (requestHash) or is malformed.
+	Begin(ctx context.Context, pharmacyID, userID uuid.UUID, scope, key, requestHash string) (rec *models.IdempotencyKey, replay bool, err error)
+	// Complete stores the response for replay. Server errors (5xx) release the key instead so the client can retry.
+	Complete(ctx context.Context, rec *models.IdempotencyKey, status int, contentType string, body []byte) error
+	// Release forgets the key, e.g. when the handler panicked.
+	Release(ctx context.Context, rec *models.IdempotencyKey) error
+	// PurgeExpired deletes keys past their 24-hour lifetime.
+	PurgeExpired(ctx context.Context) error
+}
+
+// IntegrityService is the data doctor: it scans for cross-tenant references, orphaned records, ledger drift
+// and missing files, and optionally applies the fixes that are safe to automate.
+type IntegrityService interface {
+	// Run checks one pharmacy (or all when pharmacyID is nil). With applyFixes, fixable issues are repaired
+	// and the report counts what was fixed; the other issues are only reported.
+	Run(ctx context.Context, pharmacyID *uuid.UUID, applyFixes bool) (*models.IntegrityReport, error)
+}
+
+// InventoryAlertService finds products at or below their reorder level and batches close to (or past) expiry.
+// The scheduler calls ScanAll; managers are notified once per new alert.
+type InventoryAlertService interface {
+	// Digest returns the pharmacy's current alerts, computed live.
+	Digest(ctx context.Context, pharmacyID uuid.UUID) (*models.InventoryAlertDigest, error)
+	// Scan refreshes the stored alerts of one pharmacy, notifies its managers about new ones and returns how many were new.
+	Scan(ctx context.Context, pharmacyID uuid.UUID) (int, error)
+	// ScanAll scans every active pharmacy whose inventory alerts are enabled.
+	ScanAll(ctx context.Context) error
+}
+
+// ShiftSwapService lets staff offer a published shift to colleagues with the same role; a manager approves one
+// request and the roster entry moves to that colleague.
+type ShiftSwapService interface {
+	// Offer puts the caller's own upcoming published shift up for swap and tells eligible colleagues.
+	Offer(ctx context.Context, pharmacyID, userID, rosterID uuid.UUID, note string) (*models.ShiftSwap, error)
+	Get(ctx context.Context, pharmacyID, id uuid.UUID) (*models.ShiftSwap, error)
+	// List returns the pharmacy's swaps; an empty status returns all.
+	List(ctx context.Context, pharmacyID uuid.UUID, status models.ShiftSwapStatus) ([]*models.ShiftSwap, error)
+	// Request asks to take an open offer; the offerer and managers are notified.
+	Request(ctx context.Context, pharmacyID, userID, swapID uuid.UUID, note string) (*models.ShiftSwap, error)
+	// Withdraw takes back the caller's pending request.
+	Withdraw(ctx context.Context, pharmacyID, userID, swapID uuid.UUID) (*models.ShiftSwap, error)
+	// Cancel closes the caller's own open offer.
+	Cancel(ctx context.Context, pharmacyID, userID, swapID uuid.UUID) (*models.ShiftSwap, error)
+	// Approve gives the shift to the requester in one transaction and declines the other requests.
+	Approve(ctx context.Context, pharmacyID, managerID, swapID, requestID uuid.UUID, note string) (*models.ShiftSwap, error)
+	// Reject closes an open offer without changing the roster.
+	Reject(ctx context.Context, pharmacyID, managerID, swapID uuid.UUID, note string) (*models.ShiftSwap, error)
+}
+
+// BranchService manages the locations of a pharmacy, who works where, per-branch stock and transfers between branches.
+type BranchService interface {
+	// Create adds a branch. The pharmacy's first branch becomes the default and takes over its unassigned batches.
+	Create(ctx context.Context, pharmacyID uuid.UUID, input BranchInput) (*models.Branch, error)
+	Get(ctx context.Context, pharmacyID, id uuid.UUID) (*models.Branch, error)
+	List(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Branch, error)
+	Update(ctx context.Context, pharmacyID, id uuid.UUID, input BranchInput) (*models.Branch, error)
+	// Delete removes a branch without stock; its team members become unassigned. The default branch can only
+	// be deleted when it is the last one.
+	Delete(ctx context.Context, pharmacyID, id uuid.UUID) error
+	// AssignUser sets the team member's branch, or clears it when branchID is nil.
+	AssignUser(ctx context.Context, pharmacyID, userID uuid.UUID, branchID *uuid.UUID) (*models.User, error)
+	// Stock returns per-product batch stock at the branch.
+	Stock(ctx context.Context, pharmacyID, branchID uuid.UUID) ([]*models.BranchStockRow, error)
+	// Transfer moves unexpired stock FEFO from one branch to another in one transaction.
+	Transfer(ctx context.Context, pharmacyID, createdBy uuid.UUID, input BranchTransferInput) (*models.BranchTransfer, error)
+	GetTransfer(ctx context.Context, pharmacyID, id uuid.UUID) (*models.BranchTransfer, error)
+	// ListTransfers returns transfers newest first; branchID limits to transfers into or out of that branch.
+	ListTransfers(ctx context.Context, pharmacyID uuid.UUID, branchID *uuid.UUID, limit, offset int) ([]*models.BranchTransfer, int64, error)
+}
+
+type BranchInput struct {
+	Code      string `json:"code" binding:"required,max=30"`
+	Name      string `json:"name" binding:"required,max=150"`
+	Address   string `json:"address"`
+	Phone     string `json:"phone" binding:"max=50"`
+	IsDefault bool   `json:"is_default"`
+	IsActive  *bool  `json:"is_active"`
+}
+
+type BranchTransferInput struct {
+	FromBranchID uuid.UUID                 `json:"from_branch_id" binding:"required"`
+	ToBranchID   uuid.UUID                 `json:"to_branch_id" binding:"required"`
+	Items        []BranchTransferItemInput `json:"items" binding:"required,min=1,dive"`
+	Note         string                    `json:"note"`
+}
+
+type BranchTransferItemInput struct {
+	ProductID uuid.UUID `json:"product_id" binding:"required"`
+	Quantity  int       `json:"quantity" binding:"required,gt=0"`
+}
+
+// StockTransferService runs stock transfers between pharmacies of one group. The destination requests, the source
+// approves or rejects and then dispatches, and the destination receives; inventory moves only on receipt. Every
+// step is recorded as a transfer event and in both pharmacies' activity logs.
+type StockTransferService interface {
+	// Request creates a transfer into pharmacyID; items name the destination's own products.
+	Request(ctx context.Context, pharmacyID, userID uuid.UUID, input StockTransferInput) (*models.StockTransfer, error)
+	Get(ctx context.Context, pharmacyID, id uuid.UUID) (*models.StockTransfer, error)
+	// List returns transfers the pharmacy takes part in; direction is "incoming", "outgoing" or "" for both.
+	List(ctx context.Context, pharmacyID uuid.UUID, direction string, status models.StockTransferStatus, limit, offset int) ([]*models.StockTransfer, int64, error)
+	// Approve, Reject and Dispatch are the source pharmacy's steps; Receive and Cancel the destination's.
+	Approve(ctx context.Context, pharmacyID, userID, id uuid.UUID, note string) (*models.StockTransfer, error)
+	Reject(ctx context.Context, pharmacyID, userID, id uuid.UUID, note string) (*models.StockTransfer, error)
+	Dispatch(ctx context.Context, pharmacyID, userID, id uuid.UUID, note string) (*models.StockTransfer, error)
+	Receive(ctx context.Context, pharmacyID, userID, id uuid.UUID, note string) (*models.StockTransfer, error)
+	Cancel(ctx context.Context, pharmacyID, userID, id uuid.UUID, note string) (*models.StockTransfer, error)
+}
+
+type StockTransferInput struct {
+	SourcePharmacyID uuid.UUID                `json:"source_pharmacy_id" binding:"required"`
+	Items            []StockTransferItemInput `json:"items" binding:"required,min=1,dive"`
+	Note             string                   `json:"note" binding:"max=500"`
+}
+
+type StockTransferItemInput struct {
+	ProductID uuid.UUID `json:"product_id" binding:"required"`
+	Quantity  int       `json:"quantity" binding:"required,gt=0"`
+}
+
+// HashtagService keeps the per-pharmacy hashtag registry: canonical tags, merged aliases and a blocklist applied
+// to product hashtags, plus trending tags for storefront discovery.
+type HashtagService interface {
+	// List returns registered tags with product counts; tags used on products but not registered come last with a
+	// zero id, so they can be registered or merged.
+	List(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Hashtag, error)
+	Create(ctx context.Context, pharmacyID uuid.UUID, input HashtagInput) (*models.Hashtag, error)
+	Update(ctx context.Context, pharmacyID, id uuid.UUID, input HashtagInput) (*models.Hashtag, error)
+	// Delete removes the registry entry; products keep the tag.
+	Delete(ctx context.Context, pharmacyID, id uuid.UUID) error
+	// Merge folds source into target: source's tag and aliases become target aliases and are rewritten on products.
+	Merge(ctx context.Context, pharmacyID, sourceID, targetID uuid.UUID) (*models.Hashtag, int64, error)
+	// Normalize canonicalizes tags for a product save: aliases map to their tag, blocked and empty tags are dropped,
+	// duplicates removed. New tags are registered.
+	Normalize(ctx context.Context, pharmacyID uuid.UUID, tags []string) ([]string, error)
+	// Sync rewrites every product's hashtags through Normalize and returns the number of products changed.
+	Sync(ctx context.Context, pharmacyID uuid.UUID) (int64, error)
+	// Trending ranks unblocked tags by views or units sold ("views"|"sales") over the last days.
+	Trending(ctx context.Context, pharmacyID uuid.UUID, days int, by string, limit int) ([]*models.HashtagTrend, error)
+	// RecordProductView counts a storefront view of an active product.
+	RecordProductView(ctx context.Context, pharmacyID, productID uuid.UUID) error
+}
+
+// HashtagInput creates or updates a registry entry; Tag and Aliases are canonicalized.
+type HashtagInput struct {
+	Tag       string   `json:"tag" binding:"required,max=60"`
+	Label     string   `json:"label" binding:"max=100"`
+	Aliases   []string `json:"aliases" binding:"max=50"`
+	IsBlocked bool     `json:"is_blocked"`
+}
+
+// ProductImageProcessor saves product image uploads through FileStorage: the original with its metadata
+// stripped, thumbnail, medium and large variants, and the low-bandwidth rendition.
+type ProductImageProcessor interface {
+	// Store saves the upload under photos/products/<productID>/<yyyy/mm>/ and returns the image with its URLs,
+	// not yet attached to the product. Formats it cannot decode (WebP, SVG) are stored as uploaded.
+	Store(ctx context.Context, productID uuid.UUID, data []byte, ext, contentType string) (*models.ProductImage, error)
+}
+
+// ProductImageImportService attaches many product photos in one go, matching files to products by the SKU in
+// the file name ("SKU123.jpg", "SKU123_2.jpg").
+type ProductImageImportService interface {
+	// Import matches every file, then stores and attaches the matched ones in file-name order after each product's
+	// existing images. With dryRun nothing is stored; the result shows what would happen.
+	Import(ctx context.Context, pharmacyID uuid.UUID, files []ImageUpload, dryRun bool) (*ImageImportResult, error)
+}
+
+// ImageUpload is one uploaded file, from a multipart form or a zip entry.
+type ImageUpload struct {
+	Name string
+	Size int64
+	Open func() (io.ReadCloser, error)
+}
+
+// ImageImportResult reports each file as matched (with the image created, unless a dry run) or unmatched.
+type ImageImportResult struct {
+	DryRun        bool                 `json:"dry_run"`
+	ImagesCreated int                  `json:"images_created"`
+	Products      int                  `json:"products"`
+	Matched       []*ImageImportMatch  `json:"matched"`
+	Unmatched     []*ImageImportReject `json:"unmatched"`
+}
+
+type ImageImportMatch struct {
+	File      string     `json:"file"`
+	SKU       string     `json:"sku"`
+	ProductID uuid.UUID  `json:"product_id"`
+	Position  int        `json:"position"` // number after the SKU; 0 when the name has none
+	ImageID   *uuid.UUID `json:"image_id,omitempty"`
+	URL       string     `json:"url,omitempty"`
+	IsPrimary bool       `json:"is_primary"`
+}
+
+type ImageImportReject struct {
+	File   string `json:"file"`
+	Reason string `json:"reason"`
+}
+
+// GiftCardService issues gift cards and moves their balance. Redemptions tied to an order are reversed when the
+// order is cancelled.
+type GiftCardService interface {
+	Issue(ctx context.Context, pharmacyID, issuedBy uuid.UUID, in GiftCardInput) (*models.GiftCard, error)
+	List(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.GiftCard, int64, error)
+	// Get returns the card with its transactions, newest first.
+	Get(ctx context.Context, pharmacyID, id uuid.UUID) (*GiftCardView, error)
+	// Check looks a code up for a customer or cashier; an unknown code is ErrNotFound.
+	Check(ctx context.Context, pharmacyID uuid.UUID, code string) (*GiftCardBalance, error)
+	// Redeem takes amount off the card; it fails rather than going below zero.
+	Redeem(ctx context.Context, pharmacyID uuid.UUID, code string, amount float64, orderID *uuid.UUID, actorID uuid.UUID) (*models.GiftCardTransaction, error)
+	Deactivate(ctx context.Context, pharmacyID, id uuid.UUID) (*models.GiftCard, error)
+	// ReverseForOrder returns whatever the order still holds from any card.
+	ReverseForOrder(ctx context.Context, orderID, actorID uuid.UUID) error
+}
+
+// GiftCardInput issues a card; an empty Code is generated.
+type GiftCardInput struct {
+	Code       string     `json:"code" binding:"omitempty,min=4,max=32"`
+	Amount     float64    `json:"amount" binding:"required,gt=0"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	CustomerID *uuid.UUID `json:"customer_id"`
+	Note       string     `json:"note" binding:"max=500"`
+}
+
+type GiftCardView struct {
+	Card         *models.GiftCard              `json:"card"`
+	Transactions []*models.GiftCardTransaction `json:"transactions"`
+}
+
+// GiftCardBalance is what checking a code shows; Reason explains why an unusable card cannot be redeemed.
+type GiftCardBalance struct {
+	Code      string     `json:"code"`
+	Balance   float64    `json:"balance"`
+	Currency  string     `json:"currency"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Usable    bool       `json:"usable"`
+	Reason    string     `json:"reason,omitempty"`
+}
+
+// StoreCreditService keeps each customer's store credit ledger. Refunds can be paid into it instead of back
+// through the payment gateway, and it can be spent on later orders.
+type StoreCreditService interface {
+	Ledger(ctx context.Context, pharmacyID, customerID uuid.UUID, limit, offset int) (*StoreCreditLedger, error)
+	// Adjust is a manual credit (positive) or debit (negative) with a required note.
+	Adjust(ctx context.Context, pharmacyID, customerID, actorID uuid.UUID, amount float64, note string) (*models.StoreCreditEntry, error)
+	// RefundOrder credits a completed order's customer. amount 0 refunds everything not yet refunded; more than
+	// that is rejected.
+	RefundOrder(ctx context.Context, pharmacyID, orderID, actorID uuid.UUID, amount float64, note string) (*models.StoreCreditEntry, error)
+	// Use spends credit on an order; it fails rather than going below zero.
+	Use(ctx context.Context, pharmacyID, customerID, orderID, actorID uuid.UUID, amount float64) (*models.StoreCreditEntry, error)
+	// ReverseForOrder returns whatever credit the order still holds.
+	ReverseForOrder(ctx context.Context, orderID, actorID uuid.UUID) error
+}
+
+type StoreCreditLedger struct {
+	CustomerID uuid.UUID                  `json:"customer_id"`
+	Balance    float64                    `json:"balance"`
+	Entries    []*models.StoreCreditEntry `json:"items"`
+	Total      int64                      `json:"total"`
+}
+
+// WalletService is the customer-facing side of store credit: the signed-in user's wallet is the store credit of
+// the customer matching their phone. Customers top it up through a payment gateway and spend it as the
+// store_credit tender on orders and cart checkout.
+type WalletService interface {
+	// Get returns the user's balance and ledger, newest first; a user with no customer record has an empty wallet.
+	Get(ctx context.Context, pharmacyID, userID uuid.UUID, limit, offset int) (*StoreCreditLedger, error)
+	// StartTopUp records a pending top-up to be paid through the gateway, creating the customer if needed.
+	StartTopUp(ctx context.Context, pharmacyID, userID uuid.UUID, in WalletTopUpInput) (*models.WalletTopUp, error)
+	ListMyTopUps(ctx context.Context, pharmacyID, userID uuid.UUID, limit, offset int) ([]*models.WalletTopUp, int64, error)
+	ListTopUps(ctx context.Context, pharmacyID uuid.UUID, q WalletTopUpQuery) ([]*models.WalletTopUp, int64, error)
+	// CompleteTopUp confirms the payment and credits the wallet; a top-up is settled at most once.
+	CompleteTopUp(ctx context.Context, pharmacyID, id, actorID uuid.UUID, reference string) (*models.WalletTopUp, error)
+	// FailTopUp closes a pending top-up without crediting anything.
+	FailTopUp(ctx context.Context, pharmacyID, id, actorID uuid.UUID, reason string) (*models.WalletTopUp, error)
+}
+
+type WalletTopUpInput struct {
+	Amount           float64   `json:"amount" binding:"required,gt=0"`
+	PaymentGatewayID uuid.UUID `json:"payment_gateway_id" binding:"required"`
+}
+
+// WalletTopUpQuery filters the staff list of top-ups; zero values match everything.
+type WalletTopUpQuery struct {
+	CustomerID *uuid.UUID
+	Status     models.WalletTopUpStatus
+	Limit      int
+	Offset     int
+}
+
+// StaffPointsService credits team members for completed sales and reports the points for incentive payouts.
+type StaffPointsService interface {
+	// CreditSale credits the order's creator per the pharmacy's staff points rules; an order is credited once.
+	CreditSale(ctx context.Context, o *models.Order) error
+	// History is the user's balance and credited sales, newest first.
+	History(ctx context.Context, userID uuid.UUID, limit, offset int) (*StaffPointsHistory, error)
+	// MonthlyReport sums points per team member per calendar month in [from, to), ranked within each month,
+	// with a leaderboard over the whole range.
+	MonthlyReport(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) (*StaffPointsReport, error)
+}
+
+type StaffPointsHistory struct {
+	PointsBalance int                              `json:"points_balance"`
+	Items         []*models.StaffPointsTransaction `json:"items"`
+	Total         int64                            `json:"total"`
+	Limit         int                              `json:"limit"`
+	Offset        int                              `json:"offset"`
+}
+
+type StaffPointsReport struct {
+	From        time.Time                     `json:"from"`
+	To          time.Time                     `json:"to"`
+	Months      []*models.StaffPointsMonthRow `json:"months"`
+	Leaderboard []*StaffPointsLeader          `json:"leaderboard"`
+}
+
+// StaffPointsLeader is a team member's total over the report range.
+type StaffPointsLeader struct {
+	Rank       int       `json:"rank"`
+	UserID     uuid.UUID `json:"user_id"`
+	Name       string    `json:"name"`
+	Role       string    `json:"role"`
+	SalesCount int64     `json:"sales_count"`
+	SaleAmount float64   `json:"sale_amount"`
+	Points     int64     `json:"points"`
+}
+
+// CampaignService lets admins define customer segments and send a one-off message to a segment by in-app
+// notification, SMS or email, tracking the outcome per recipient.
+type CampaignService interface {
+	CreateSegment(ctx context.Context, pharmacyID, actorID uuid.UUID, in SegmentInput) (*models.CustomerSegment, error)
+	ListSegments(ctx context.Context, pharmacyID uuid.UUID) ([]*models.CustomerSegment, error)
+	UpdateSegment(ctx context.Context, pharmacyID, id uuid.UUID, in SegmentInput) (*models.CustomerSegment, error)
+	DeleteSegment(ctx context.Context, pharmacyID, id uuid.UUID) error
+	// PreviewSegment counts the customers the segment matches now, with the first few of them.
+	PreviewSegment(ctx context.Context, pharmacyID, id uuid.UUID) (*SegmentPreview, error)
+
+	Create(ctx context.Context, pharmacyID, actorID uuid.UUID, in CampaignInput) (*models.Campaign, error)
+	List(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.Campaign, int64, error)
+	Get(ctx context.Context, pharmacyID, id uuid.UUID) (*models.Campaign, error)
+	// Update and Delete only apply to drafts.
+	Update(ctx context.Context, pharmacyID, id uuid.UUID, in CampaignInput) (*models.Campaign, error)
+	Delete(ctx context.Context, pharmacyID, id uuid.UUID) error
+	// Send starts sending a draft to the segment's current members and returns it in the sending state; the
+	// messages go out in the background and the counts grow as they do.
+	Send(ctx context.Context, pharmacyID, id uuid.UUID) (*models.Campaign, error)
+	// ListRecipients pages through the outcomes, optionally only one status.
+	ListRecipients(ctx context.Context, pharmacyID, id uuid.UUID, status string, limit, offset int) ([]*models.CampaignRecipient, int64, error)
+}
+
+type SegmentInput struct {
+	Name        string                 `json:"name" binding:"required,max=100"`
+	Description string                 `json:"description" binding:"max=500"`
+	Criteria    models.SegmentCriteria `json:"criteria"`
+}
+
+type SegmentPreview struct {
+	Count  int64                   `json:"count"`
+	Sample []*models.SegmentMember `json:"sample"`
+}
+
+// CampaignInput defines a campaign. Message may contain {name}, replaced by the customer's name. Subject is
+// required for email and used as the notification title.
+type CampaignInput struct {
+	Name      string                 `json:"name" binding:"required,max=100"`
+	SegmentID uuid.UUID              `json:"segment_id" binding:"required"`
+	Channel   models.CampaignChannel `json:"channel" binding:"required,oneof=notification sms email"`
+	Subject   string                 `json:"subject" binding:"max=255"`
+	Message   string                 `json:"message" binding:"required,max=2000"`
+}
+
+// ClearanceService clears dead stock through discount promos and supplier returns and tracks the value each
+// action recovers. A product can be in only one open action at a time.
+type ClearanceService interface {
+	// SupplierCandidates groups dead stock that is not already in an open action by supplier, most valuable
+	// first. Products without a supplier are left out.
+	SupplierCandidates(ctx context.Context, pharmacyID uuid.UUID, days int, minValue float64) ([]*SupplierReturnCandidate, error)
+	// Create opens an action. A promo action creates a percent promo code scoped to the products; a supplier
+	// return needs products that all map to the same supplier.
+	Create(ctx context.Context, pharmacyID, actorID uuid.UUID, in ClearanceInput) (*models.ClearanceAction, error)
+	List(ctx context.Context, pharmacyID uuid.UUID, kind models.ClearanceKind, status models.ClearanceStatus, limit, offset int) ([]*models.ClearanceAction, int64, error)
+	Get(ctx context.Context, pharmacyID, id uuid.UUID) (*models.ClearanceAction, error)
+	// RecordReturn records units sent back to the supplier and the credit received, taking them out of stock.
+	RecordReturn(ctx context.Context, pharmacyID, id uuid.UUID, in ClearanceReturnInput) (*models.ClearanceAction, error)
+	// Complete and Cancel close an open action; a promo action's code is deactivated either way.
+	Complete(ctx context.Context, pharmacyID, id, actorID uuid.UUID) (*models.ClearanceAction, error)
+	Cancel(ctx context.Context, pharmacyID, id, actorID uuid.UUID) (*models.ClearanceAction, error)
+}
+
+// ClearanceInput opens a clearance action. DiscountPercent is required for promos; the promo runs ValidDays
+// (default 30) under Code, generated when empty.
+type ClearanceInput struct {
+	Kind            models.ClearanceKind `json:"kind" binding:"required,oneof=promo supplier_return"`
+	Name            string               `json:"name" binding:"required,max=255"`
+	Note            string               `json:"note" binding:"max=500"`
+	ProductIDs      []uuid.UUID          `json:"product_ids" binding:"required,min=1,max=200"`
+	DiscountPercent float64              `json:"discount_percent" binding:"omitempty,gt=0,lte=90"`
+	ValidDays       int                  `json:"valid_days" binding:"omitempty,min=1,max=365"`
+	Code            string               `json:"code" binding:"max=50"`
+}
+
+// ClearanceReturnInput records one shipment back to the supplier.
+type ClearanceReturnInput struct {
+	Lines []ClearanceReturnLine `json:"lines" binding:"required,min=1,dive"`
+}
+
+type ClearanceReturnLine struct {
+	ProductID    uuid.UUID `json:"product_id" binding:"required"`
+	Quantity     int       `json:"quantity" binding:"required,gt=0"`
+	CreditAmount float64   `json:"credit_amount" binding:"gte=0"`
+}
+
+// SupplierReturnCandidate is one supplier's dead stock, a ready list for a supplier return.
+type SupplierReturnCandidate struct {
+	SupplierID    uuid.UUID              `json:"supplier_id"`
+	SupplierName  string                 `json:"supplier_name"`
+	Products      []*models.DeadStockRow `json:"products"`
+	StockQuantity int                    `json:"stock_quantity"`
+	StockValue    float64                `json:"stock_value"`
+}
+
+// SerialService tracks serial numbers of high-value products (devices) from goods receipt to sale, so a unit
+// brought back under warranty can be traced to its order.
+type SerialService interface {
+	// Receive records serials for units already in stock; the product must track serials and its in-stock
+	// serials cannot outnumber its stock.
+	Receive(ctx context.Context, pharmacyID, actorID uuid.UUID, in SerialReceiveInput) ([]*models.ProductSerial, error)
+	List(ctx context.Context, pharmacyID uuid.UUID, productID *uuid.UUID, status models.SerialStatus, limit, offset int) ([]*models.ProductSerial, int64, error)
+	// Search finds serials containing q (at least 3 characters) with the product and the order they were sold on.
+	Search(ctx context.Context, pharmacyID uuid.UUID, q string) ([]*models.ProductSerial, error)
+	// AssignToItem marks in-stock serials as sold on an order item and registers their warranties; it runs inside
+	// the caller's transaction when there is one. The count must match the item quantity.
+	AssignToItem(ctx context.Context, o *models.Order, item *models.OrderItem, numbers []string) error
+	// AssignToOrder picks serials for a line of an existing order that has none yet (e.g. an online order at packing).
+	AssignToOrder(ctx context.Context, pharmacyID, orderID, orderItemID uuid.UUID, numbers []string) (*models.OrderItem, error)
+	// ReleaseOrder puts a cancelled order's serials back in stock and voids their warranties.
+	ReleaseOrder(ctx context.Context, orderID uuid.UUID) error
+}
+
+// SerialReceiveInput records serials for a product, optionally against the purchase order they arrived on.
+type SerialReceiveInput struct {
+	ProductID       uuid.UUID  `json:"product_id" binding:"required"`
+	PurchaseOrderID *uuid.UUID `json:"purchase_order_id"`
+	SerialNumbers   []string   `json:"serial_numbers" binding:"required,min=1,max=500,dive,required,max=100"`
+}
+
+// SerialAssignInput picks serials for an order line.
+type SerialAssignInput struct {
+	OrderID       uuid.UUID `json:"order_id" binding:"required"`
+	OrderItemID   uuid.UUID `json:"order_item_id" binding:"required"`
+	SerialNumbers []string  `json:"serial_numbers" binding:"required,min=1,max=500,dive,required,max=100"`
+}
+
+// WarrantyService registers warranties for serial-tracked sales and runs claims against them: intake at the
+// counter, optionally sent to the vendor, then resolved. The customer is told by SMS and email at each step.
+type WarrantyService interface {
+	// RegisterSale creates one warranty per serial sold on the item when the product has warranty months; it runs
+	// inside the caller's transaction.
+	RegisterSale(ctx context.Context, o *models.Order, item *models.OrderItem, product *models.Product, serials []*models.ProductSerial) error
+	// VoidOrder voids a cancelled order's warranties.
+	VoidOrder(ctx context.Context, orderID uuid.UUID) error
+	// Check is the public lookup by exact serial number: product, dates and status, without customer details.
+	Check(ctx context.Context, pharmacyID uuid.UUID, serialNumber string) ([]*WarrantyCheck, error)
+	// FindBySerial returns the warranties for the exact serial number with the customer and claims, newest first.
+	FindBySerial(ctx context.Context, pharmacyID uuid.UUID, serialNumber string) ([]*models.Warranty, error)
+	Get(ctx context.Context, pharmacyID, id uuid.UUID) (*models.Warranty, error)
+	// OpenClaim takes a device in on an active warranty without an unresolved claim.
+	OpenClaim(ctx context.Context, pharmacyID, actorID uuid.UUID, in WarrantyClaimInput) (*models.WarrantyClaim, error)
+	ListClaims(ctx context.Context, pharmacyID uuid.UUID, status models.WarrantyClaimStatus, limit, offset int) ([]*models.WarrantyClaim, int64, error)
+	GetClaim(ctx context.Context, pharmacyID, id uuid.UUID) (*models.WarrantyClaim, error)
+	// SendToVendor moves a claim in intake to the vendor.
+	SendToVendor(ctx context.Context, pharmacyID, id uuid.UUID, in WarrantyVendorInput) (*models.WarrantyClaim, error)
+	// Resolve closes a claim that is in intake or with the vendor.
+	Resolve(ctx context.Context, pharmacyID, id, actorID uuid.UUID, in WarrantyResolveInput) (*models.WarrantyClaim, error)
+}
+
+// WarrantyCheck is what the public warranty check shows for a serial.
+type WarrantyCheck struct {
+	ProductName  string                     `json:"product_name"`
+	SerialNumber string                     `json:"serial_number"`
+	PurchasedAt  time.Time                  `json:"purchased_at"`
+	ExpiresAt    time.Time                  `json:"expires_at"`
+	Status       string                     `json:"status"`                 // active, expired or void
+	ClaimStatus  models.WarrantyClaimStatus `json:"claim_status,omitempty"` // latest claim, if any
+}
+
+type WarrantyClaimInput struct {
+	WarrantyID uuid.UUID `json:"warranty_id" binding:"required"`
+	Issue      string    `json:"issue" binding:"required,max=2000"`
+}
+
+type WarrantyVendorInput struct {
+	VendorReference string `json:"vendor_reference" binding:"max=100"`
+	Note            string `json:"note" binding:"max=2000"`
+}
+
+type WarrantyResolveInput struct {
+	Resolution models.WarrantyResolution `json:"resolution" binding:"required,oneof=repaired replaced refunded rejected"`
+	Note       string                    `json:"note" binding:"max=2000"`
+}
+
+// OrganizationService links pharmacy tenants of one owner into an organization and reports across them.
+// Organization access comes from membership, not from the pharmacy the caller signed in to: non-members get
+// not found, and only owners manage tenants and members. Reports take an optional pharmacyID to drill down to
+// one tenant of the organization.
+type OrganizationService interface {
+	// Create starts an organization with the caller's pharmacy as its first tenant and the caller as owner.
+	Create(ctx context.Context, pharmacyID, userID uuid.UUID, input OrganizationInput) (*models.Organization, error)
+	// Join links the caller's pharmacy to the organization with that join code and makes the caller a viewer.
+	Join(ctx context.Context, pharmacyID, userID uuid.UUID, joinCode string) (*models.Organization, error)
+	// ListMine returns the caller's organizations with their tenants.
+	ListMine(ctx context.Context, userID uuid.UUID) ([]*models.Organization, error)
+	Get(ctx context.Context, organizationID, userID uuid.UUID) (*models.Organization, error)
+	// RemovePharmacy takes a tenant out of the organization (owners only); its users lose their membership.
+	RemovePharmacy(ctx context.Context, organizationID, userID, pharmacyID uuid.UUID) error
+	ListMembers(ctx context.Context, organizationID, userID uuid.UUID) ([]*models.OrganizationMember, error)
+	// AddMember gives a user of one of the tenants access to the organization (owners only).
+	AddMember(ctx context.Context, organizationID, userID uuid.UUID, input OrganizationMemberInput) (*models.OrganizationMember, error)
+	// RemoveMember revokes a member (owners only); the last owner cannot be removed.
+	RemoveMember(ctx context.Context, organizationID, userID, memberUserID uuid.UUID) error
+	// Dashboard sums sales per tenant with the top products in the range (default last 30 days).
+	Dashboard(ctx context.Context, organizationID, userID uuid.UUID, from, to time.Time, pharmacyID *uuid.UUID) (*OrganizationDashboard, error)
+	// Sales is the consolidated sales summary by day, week or month.
+	Sales(ctx context.Context, organizationID, userID uuid.UUID, granularity string, from, to time.Time, pharmacyID *uuid.UUID) (*SalesSummaryReport, error)
+	TopProducts(ctx context.Context, organizationID, userID uuid.UUID, from, to time.Time, pharmacyID *uuid.UUID, limit int) ([]*models.OrgTopProductRow, error)
+	// Stock lists sellable stock per product across tenants and branches.
+	Stock(ctx context.Context, organizationID, userID uuid.UUID, query string, pharmacyID *uuid.UUID, limit int) ([]*OrganizationStockItem, error)
+}
+
+type OrganizationInput struct {
+	Name string `json:"name" binding:"required,max=255"`
+	Code string `json:"code" binding:"required,max=64"` // becomes the group code of every tenant
+}
+
+type OrganizationMemberInput struct {
+	Email string                  `json:"email" binding:"required,email"`
+	Role  models.OrganizationRole `json:"role" binding:"omitempty,oneof=owner viewer"`
+}
+
+// OrganizationDashboard is the roll-up of one range: totals, each tenant's part and the best sellers.
+type OrganizationDashboard struct {
+	From        time.Time                   `json:"from"`
+	To          time.Time                   `json:"to"`
+	Tenants     []*models.OrgTenantSalesRow `json:"tenants"`
+	TopProducts []*models.OrgTopProductRow  `json:"top_products"`
+	OrdersCount int64                       `json:"orders_count"`
+	Revenue     float64                     `json:"revenue"`
+}
+
+// OrganizationStockItem is one product's sellable stock across the organization with where it is held.
+type OrganizationStockItem struct {
+	SKU       string               `json:"sku"`
+	Name      string               `json:"name"`
+	Quantity  int                  `json:"quantity"`
+	Value     float64              `json:"value"`
+	Locations []*models.OrgStockRow `json:"locations"`
+}
+
+// ConsentService records customers' marketing opt-ins and opt-outs per channel and answers whether a customer
+// may be sent marketing on a channel. Only an explicit opt-in allows it; transactional messages (order updates,
+// codes, receipts) do not depend on consent.
+type ConsentService interface {
+	// Record appends an opt-in or opt-out for one of the pharmacy's customers, captured by staff.
+	Record(ctx context.Context, pharmacyID, customerID, actorID uuid.UUID, in ConsentInput) (*models.ConsentRecord, error)
+	// Get returns the customer's current consent on every channel with the recent history.
+	Get(ctx context.Context, pharmacyID, customerID uuid.UUID) (*CustomerConsent, error)
+	// GetForUser is Get for the signed-in user's own customer record (same pharmacy and phone); every channel is
+	// opted out while there is none.
+	GetForUser(ctx context.Context, pharmacyID, userID uuid.UUID) (*CustomerConsent, error)
+	// RecordForUser records the user's own decision with source "app", creating their customer record from
+	// their profile when needed.
+	RecordForUser(ctx context.Context, pharmacyID, userID uuid.UUID, in ConsentInput) (*models.ConsentRecord, error)
+	// OptedIn returns which of customerIDs opted in to the channel.
+	OptedIn(ctx context.Context, pharmacyID uuid.UUID, channel models.ConsentChannel, customerIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+	// UserOptedIn reports whether the user's customer record opted in to the channel; false without one or on error.
+	UserOptedIn(ctx context.Context, pharmacyID, userID uuid.UUID, channel models.ConsentChannel) bool
+	// Export returns the records created in the range (default last 30 days), oldest first, for audits.
+	Export(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.ConsentRecord, error)
+}
+
+type ConsentInput struct {
+	Channel models.ConsentChannel `json:"channel" binding:"required,oneof=sms email whatsapp push"`
+	Granted *bool                 `json:"granted" binding:"required"`
+	// Source defaults to in_store for staff; self-service records are always "app".
+	Source    string `json:"source" binding:"omitempty,oneof=in_store paper_form phone website import"`
+	Note      string `json:"note" binding:"max=500"`
+	IPAddress string `json:"-"`
+}
+
+// CustomerConsent is a customer's consent on every channel.
+type CustomerConsent struct {
+	CustomerID *uuid.UUID               `json:"customer_id,omitempty"`
+	Channels   []*ChannelConsent        `json:"channels"`
+	History    []*models.ConsentRecord `json:"history"`
+}
+
+// ChannelConsent is the current state of one channel; UpdatedAt is nil when nothing was ever recorded.
+type ChannelConsent struct {
+	Channel   models.ConsentChannel `json:"channel"`
+	Granted   bool                  `json:"granted"`
+	Source    string                `json:"source,omitempty"`
+	UpdatedAt *time.Time            `json:"updated_at,omitempty"`
+}
+
+// ETAService estimates when orders will be ready for pickup and, for delivery orders, delivered. Estimates come
+// from the live queue of open orders, the staff on today's published roster and the pharmacy's recent average
+// preparation and delivery times.
+type ETAService interface {
+	// Estimate is the ETA of an order placed now, shown at checkout.
+	Estimate(ctx context.Context, pharmacyID uuid.UUID, delivery bool) (*ETAEstimate, error)
+	// ForOrder is the current ETA of an order for its tracking page. customerUserID limits it to the customer's
+	// own order.
+	ForOrder(ctx context.Context, pharmacyID, orderID uuid.UUID, customerUserID *uuid.UUID) (*OrderETA, error)
+	// OnOrderCreated stores the new order's ETA and, when order volume spikes, recalculates the stored ETA of
+	// every open order.
+	OnOrderCreated(ctx context.Context, order *models.Order) error
+}
+
+type ETAEstimate struct {
+	ReadyAt           *time.Time `json:"ready_at,omitempty"` // unset once the order is ready
+	ReadyInMinutes    int        `json:"ready_in_minutes"`
+	DeliveryAt        *time.Time `json:"delivery_at,omitempty"` // delivery orders only
+	DeliveryInMinutes *int       `json:"delivery_in_minutes,omitempty"`
+	QueueDepth        int        `json:"queue_depth"` // open orders ahead
+	StaffOnDuty       int        `json:"staff_on_duty"`
+	PrepMinutes       float64    `json:"prep_minutes"` // average per order, from recent history or the default
+	Busy              bool       `json:"busy"`         // order volume is well above normal for this time of day
+}
+
+type OrderETA struct {
+	OrderID     uuid.UUID          `json:"order_id"`
+	OrderNumber string             `json:"order_number"`
+	Status      models.OrderStatus `json:"status"`
+	Done        bool               `json:"done"` // completed, cancelled or delivered: no ETA
+	ETAEstimate
+}
+
+// InventoryEvidenceService documents batches with photos and keeps the stock ledger of write-offs, so damage
+// can be shown to suppliers. Photos are stored through FileStorage.
+type InventoryEvidenceService interface {
+	// WriteOff takes units off the batch and product stock and records the reason in the batch's ledger.
+	WriteOff(ctx context.Context, pharmacyID, batchID, actorID uuid.UUID, in StockWriteOffInput) (*models.StockAdjustment, error)
+	// AddPhoto stores an image of the batch, as evidence for the write-off adjustmentID when set.
+	AddPhoto(ctx context.Context, pharmacyID, batchID, actorID uuid.UUID, adjustmentID *uuid.UUID, caption string, body io.Reader) (*models.InventoryPhoto, error)
+	// Ledger returns the batch with its write-offs and their photos, and the photos not tied to a write-off.
+	Ledger(ctx context.Context, pharmacyID, batchID uuid.UUID) (*BatchLedger, error)
+	// AttachToReturn links photos of products in an open supplier return to it, for the supplier dispute.
+	AttachToReturn(ctx context.Context, pharmacyID, actionID uuid.UUID, photoIDs []uuid.UUID) ([]*models.InventoryPhoto, error)
+}
+
+// StockWriteOffInput removes Quantity units from a batch.
+type StockWriteOffInput struct {
+	Quantity int                          `json:"quantity" binding:"required,min=1"`
+	Reason   models.StockAdjustmentReason `json:"reason" binding:"required"`
+	Note     string                       `json:"note" binding:"max=500"`
+}
+
+// BatchLedger is a batch's stock ledger with the photos documenting it.
+type BatchLedger struct {
+	Batch       *models.InventoryBatch    `json:"batch"`
+	Adjustments []*models.StockAdjustment `json:"adjustments"`
+	Photos      []*models.InventoryPhoto  `json:"photos"`
+}
+
+// APIUsageService meters each tenant's API traffic. Record only counts in memory; Flush writes the counts to
+// the database and runs every minute.
+type APIUsageService interface {
+	Record(pharmacyID uuid.UUID, method, route string, status int, latency time.Duration)
+	Flush(ctx context.Context) error
+	// Report summarizes the pharmacy's traffic for days in [from, to], at most a year.
+	Report(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) (*APIUsageReport, error)
+}
+
+// DrugInfoService manages active ingredients and interaction rules, and checks a basket of products
+// for interactions and duplicate therapy. Checks only apply to pharmacy business types.
+type DrugInfoService interface {
+	// SetIngredients replaces the product's active ingredients; names are normalized and deduplicated.
+	SetIngredients(ctx context.Context, pharmacyID, productID uuid.UUID, ingredients []IngredientInput) ([]*models.ProductIngredient, error)
+	// Ingredients returns the product's stored ingredients, or those parsed from its generic name when none are stored.
+	Ingredients(ctx context.Context, pharmacyID, productID uuid.UUID) ([]*models.ProductIngredient, error)
+	AddInteraction(ctx context.Context, pharmacyID, actorID uuid.UUID, in DrugInteractionInput) (*models.DrugInteraction, error)
+	ListInteractions(ctx context.Context, pharmacyID uuid.UUID) ([]*models.DrugInteraction, error)
+	DeleteInteraction(ctx context.Context, pharmacyID, id uuid.UUID) error
+	// Check returns the warnings for products ordered together; nil for non-pharmacy businesses.
+	Check(ctx context.Context, pharmacyID uuid.UUID, products []*models.Product) ([]models.ClinicalWarning, error)
+	// CheckProducts loads the products and runs Check (pharmacist lookup before an order is placed).
+	CheckProducts(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID) ([]models.ClinicalWarning, error)
+}
+
+type IngredientInput struct {
+	Name     string `json:"name" binding:"required"`
+	Strength string `json:"strength"`
+}
+
+type DrugInteractionInput struct {
+	IngredientA string                     `json:"ingredient_a" binding:"required"`
+	IngredientB string                     `json:"ingredient_b" binding:"required"`
+	Severity    models.InteractionSeverity `json:"severity" binding:"required"`
+	Description string                     `json:"description"`
+}
+
+// APIUsageRow is one line of a usage report: a day, or an endpoint (method and route).
+type APIUsageRow struct {
+	Day          string  `json:"day,omitempty"`
+	Method       string  `json:"method,omitempty"`
+	Route        string  `json:"route,omitempty"`
+	Requests     int64   `json:"requests"`
+	ClientErrors int64   `json:"client_errors"`
+	ServerErrors int64   `json:"server_errors"`
+	ErrorRate    float64 `json:"error_rate"` // (client + server errors) / requests, 0 when no requests
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	MaxLatencyMs int64   `json:"max_latency_ms"`
+}
+
+// APIUsageReport is a tenant's API usage: totals, one row per day with traffic, and the busiest endpoints.
+type APIUsageReport struct {
+	From         string         `json:"from"`
+	To           string         `json:"to"`
+	Totals       APIUsageRow    `json:"totals"`
+	ByDay        []*APIUsageRow `json:"by_day"`
+	TopEndpoints []*APIUsageRow `json:"top_endpoints"`
+}
+
+// ExportApprovalService gates exports containing personal data (models.PIIExportKinds). Members with
+// exports.approve download them directly; others ask for a manager's approval, which covers one download.
+type ExportApprovalService interface {
+	Request(ctx context.Context, pharmacyID, userID uuid.UUID, kind, reason string) (*models.ExportRequest, error)
+	// List returns the pharmacy's requests; requestedBy, when set, keeps only that member's.
+	List(ctx context.Context, pharmacyID uuid.UUID, requestedBy *uuid.UUID, status string) ([]*models.ExportRequest, error)
+	// Review approves or rejects a pending request. Members cannot review their own requests.
+	Review(ctx context.Context, pharmacyID, id, reviewerID uuid.UUID, approve bool, note string) (*models.ExportRequest, error)
+	// Authorize allows one download of kind and returns its watermark. canApprove skips the request;
+	// otherwise requestID must name the caller's approved, unexpired request for kind, which is used up.
+	Authorize(ctx context.Context, pharmacyID, userID uuid.UUID, kind string, canApprove bool, requestID *uuid.UUID) (*ExportGrant, error)
+	// RecordDownload writes the download, with its row count, to the activity log.
+	RecordDownload(ctx context.Context, g *ExportGrant, filename, ipAddress string) error
+}
+
+// ExportGrant is one authorized download of a PII export. Rows is filled in by whoever writes the file.
+type ExportGrant struct {
+	ID         uuid.UUID  `json:"id"` // printed in the file as its watermark
+	PharmacyID uuid.UUID  `json:"pharmacy_id"`
+	Kind       string     `json:"kind"`
+	UserID     uuid.UUID  `json:"user_id"`
+	UserName   string     `json:"user_name"`
+	UserEmail  string     `json:"user_email"`
+	RequestID  *uuid.UUID `json:"request_id,omitempty"`
+	IssuedAt   time.Time  `json:"issued_at"`
+	Rows       int        `json:"rows"`
+}
+
+// Watermark is the line written at the top of the exported file.
+func (g *ExportGrant) Watermark() string {
+	who := g.UserEmail
+	if g.UserName != "" {
+		who = g.UserName + " <" + g.UserEmail + ">"
+	}
+	return "# CONFIDENTIAL export " + g.ID.String() + " for " + who + " (user " + g.UserID.String() + ") at " + g.IssuedAt.UTC().Format(time.RFC3339) + ". Contains personal data; do not share."
+}
+
+// LicenseComplianceService tracks pharmacist license expiry (models.LicensePolicy): the compliance overview,
+// the scheduled reminders and the expired-license check on prescription dispensing.
+type LicenseComplianceService interface {
+	Overview(ctx context.Context, pharmacyID uuid.UUID) (*LicenseComplianceOverview, error)
+	// SendReminders notifies license holders and their pharmacy's admins as each reminder threshold is crossed,
+	// and once more when the license has expired. Each notice is sent once per expiry date.
+	SendReminders(ctx context.Context) error
+	// CheckDispense returns a forbidden error when the pharmacy blocks expired licenses and the user's has expired.
+	CheckDispense(ctx context.Context, pharmacyID, userID uuid.UUID) error
+}
+
+// LicenseComplianceRow is one team member in the license overview.
+type LicenseComplianceRow struct {
+	UserID        uuid.UUID  `json:"user_id"`
+	Name          string     `json:"name"`
+	Email         string     `json:"email"`
+	Role          string     `json:"role"`
+	LicenseNumber string     `json:"license_number"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	DaysLeft      *int       `json:"days_left"`
+	Status        string     `json:"status"` // models.LicenseStatus*
+}
+
+// LicenseComplianceOverview lists pharmacists and any other members with license details, most urgent first.
+type LicenseComplianceOverview struct {
+	GeneratedAt    time.Time               `json:"generated_at"`
+	ReminderDays   []int                   `json:"reminder_days"`
+	BlockExpiredRx bool                    `json:"block_expired_rx"`
+	Counts         map[string]int          `json:"counts"` // by status
+	Members        []*LicenseComplianceRow `json:"members"`
+}
+
+// WishlistService keeps a buyer's favorite products and alerts them when one comes back in stock or gets cheaper.
+type WishlistService interface {
+	// Add puts an active product of the pharmacy on the user's wishlist; adding it again is a no-op.
+	Add(ctx context.Context, pharmacyID, userID, productID uuid.UUID) (*WishlistEntry, error)
+	Remove(ctx context.Context, pharmacyID, userID, productID uuid.UUID) error
+	// List returns the user's wishlist with current prices and stock, newest first.
+	List(ctx context.Context, pharmacyID, userID uuid.UUID) ([]*WishlistEntry, error)
+	// ScanAlerts notifies users whose wishlisted products came back in stock or dropped in price since they last
+	// saw them (scheduled).
+	ScanAlerts(ctx context.Context) error
+}
+
+// WishlistEntry is a wishlisted product as it is now.
+type WishlistEntry struct {
+	ProductID       uuid.UUID `json:"product_id"`
+	Name            string    `json:"name"`
+	SKU             string    `json:"sku"`
+	Image           string    `json:"image,omitempty"`
+	UnitPrice       float64   `json:"unit_price"`
+	DiscountPercent float64   `json:"discount_percent"`
+	Currency        string    `json:"currency"`
+	StockQuantity   int       `json:"stock_quantity"`
+	InStock         bool      `json:"in_stock"`
+	Available       bool      `json:"available"`   // false once the product is deactivated or deleted
+	AddedPrice      float64   `json:"added_price"` // unit price when it was added
+	PriceDropped    bool      `json:"price_dropped"`
+	AddedAt         time.Time `json:"added_at"`
+}
+
+// BackInStockService lets customers ask to be told when an out-of-stock product is back, and sends that notice
+// once stock becomes positive.
+type BackInStockService interface {
+	// Subscribe registers interest in an out-of-stock product. A subscription of the same user, phone or email
+	// still pending for the product is returned instead of adding another.
+	Subscribe(ctx context.Context, productID uuid.UUID, in BackInStockInput) (*models.StockSubscription, error)
+	// Subscribers lists the product's subscriptions for staff; status "" means all.
+	Subscribers(ctx context.Context, pharmacyID, productID uuid.UUID, status string) (*StockSubscriberList, error)
+	// Dispatch notifies pending subscribers of products that are in stock again (scheduled).
+	Dispatch(ctx context.Context) error
+}
+
+// BackInStockInput identifies the subscriber: a signed-in user (UserID, PharmacyID from the token) or a phone
+// and/or email.
+type BackInStockInput struct {
+	UserID     *uuid.UUID `json:"-"`
+	PharmacyID *uuid.UUID `json:"-"`
+	Phone      string     `json:"phone"`
+	Email      string     `json:"email"`
+}
+
+// StockSubscriberList is a product's subscribers with the number still waiting.
+type StockSubscriberList struct {
+	ProductID   uuid.UUID                   `json:"product_id"`
+	Pending     int                         `json:"pending"`
+	Subscribers []*models.StockSubscription `json:"subscribers"`
+}
+
+// ChatOrderService turns a chat conversation into an order. The draft pre-fills the customer from the
+// conversation and suggests the products shared in the thread; creating the order links it to the conversation
+// and posts a system message there.
+type ChatOrderService interface {
+	Draft(ctx context.Context, pharmacyID, conversationID uuid.UUID) (*ChatOrderDraft, error)
+	// CreateOrder places the order; empty fields of in are taken from the draft, and no items means the
+	// draft's available suggested items.
+	CreateOrder(ctx context.Context, pharmacyID, conversationID, actorID uuid.UUID, in ChatOrderInput) (*models.Order, error)
+}
+
+// ChatOrderDraft is the order the conversation suggests; nothing is stored.
+type ChatOrderDraft struct {
+	ConversationID uuid.UUID            `json:"conversation_id"`
+	CustomerID     *uuid.UUID           `json:"customer_id,omitempty"`
+	CustomerName   string               `json:"customer_name"`
+	CustomerPhone  string               `json:"customer_phone"`
+	CustomerEmail  string               `json:"customer_email"`
+	Items          []ChatOrderDraftItem `json:"items"`
+}
+
+// ChatOrderDraftItem is a product shared in the conversation, at quantity 1 and the current price.
+type ChatOrderDraftItem struct {
+	ProductID       uuid.UUID `json:"product_id"`
+	Name            string    `json:"name"`
+	SKU             string    `json:"sku"`
+	Quantity        int       `json:"quantity"`
+	UnitPrice       float64   `json:"unit_price"`
+	StockQuantity   int       `json:"stock_quantity"`
+	RequiresRx      bool      `json:"requires_rx"`
+	Available       bool      `json:"available"`
+	SourceMessageID uuid.UUID `json:"source_message_id"`
+}
+
+type ChatOrderInput struct {
+	CustomerName    string           `json:"customer_name" binding:"omitempty,max=255"`
+	CustomerPhone   string           `json:"customer_phone" binding:"omitempty,max=50"`
+	CustomerEmail   string           `json:"customer_email" binding:"omitempty,max=255"`
+	DeliveryAddress string           `json:"delivery_address" binding:"omitempty,max=1000"`
+	Notes           string           `json:"notes" binding:"omitempty,max=1000"`
+	Items           []OrderItemInput `json:"items" binding:"omitempty,max=100,dive"`
+}
+
+// RecommendationService tracks what storefront viewers open and suggests products: those frequently bought
+// together with the viewed ones, then best sellers from the same categories.
+type RecommendationService interface {
+	// RecordView counts the view for trending and, when the viewer is known, adds it to their recently viewed.
+	RecordView(ctx context.Context, pharmacyID, productID uuid.UUID, viewer StorefrontViewer) error
+	// Recommended returns the viewer's recently viewed products and suggestions seeded by productID (when set)
+	// and those views.
+	Recommended(ctx context.Context, pharmacyID uuid.UUID, viewer StorefrontViewer, productID *uuid.UUID, limit int) (*ProductRecommendations, error)
+	// PurgeViews removes recently viewed entries past the retention period.
+	PurgeViews(ctx context.Context) error
+}
+
+// StorefrontViewer identifies a storefront visitor: a signed-in user of the pharmacy, or else the client's
+// session id. Both empty means anonymous; nothing is remembered then.
+type StorefrontViewer struct {
+	UserID    *uuid.UUID
+	SessionID string
+}
+
+// Recommendation reasons.
+const (
+	RecommendationBoughtTogether = "bought_together"
+	RecommendationSameCategory   = "same_category"
+)
+
+type ProductRecommendations struct {
+	RecentlyViewed []*models.Product     `json:"recently_viewed"`
+	Recommended    []*RecommendedProduct `json:"recommended"`
+}
+
+type RecommendedProduct struct {
+	Product *models.Product `json:"product"`
+	Reason  string          `json:"reason"`
+	// Score is the number of orders that had the product together with a seed (bought_together only).
+	Score int64 `json:"score,omitempty"`
+}
+
+// ModerationService lets users report reviews, review comments, blog comments and chat messages, and lets staff
+// work through the reports. Every moderation action is written to the activity log.
+type ModerationService interface {
+	// Report files the user's report of content in their pharmacy; each user reports a piece of content once.
+	Report(ctx context.Context, pharmacyID, reporterID uuid.UUID, role string, in ContentReportInput) (*models.ContentReport, error)
+	// Queue lists the pharmacy's reports, newest first.
+	Queue(ctx context.Context, pharmacyID uuid.UUID, q ModerationQuery) ([]*models.ContentReport, int64, error)
+	// Act applies a moderation action to the reported content and resolves every open report on it.
+	// canDeactivate is whether the moderator may deactivate accounts.
+	Act(ctx context.Context, pharmacyID, reportID, moderatorID uuid.UUID, in ModerationActionInput, canDeactivate bool, ipAddress string) (*models.ContentReport, error)
+}
+
+type ContentReportInput struct {
+	TargetType string    `json:"target_type"` // models.ReportTarget*
+	TargetID   uuid.UUID `json:"target_id"`
+	Reason     string    `json:"reason"` // a key of models.ReportReasons
+	Note       string    `json:"note"`
+}
+
+// ModerationQuery filters the moderation queue; Status defaults to open.
+type ModerationQuery struct {
+	Status     string
+	TargetType string
+	AuthorID   *uuid.UUID
+	Limit      int
+	Offset     int
+}
+
+type ModerationActionInput struct {
+	Action string `json:"action"` // models.Moderation*
+	Note   string `json:"note"`   // kept on the resolved reports
+	// Message replaces the default warning sent by warn_user.
+	Message string `json:"message"`
+	// HideContent also hides the content when warning or deactivating the author.
+	HideContent bool `json:"hide_content"`
+}
+
+// SitemapService keeps each tenant storefront's sitemap.xml: static pages, categories, published blog posts and
+// active products, linked under the config's site_hostname (or the app's public URL). The stored sitemap is rebuilt
+// when its content changes and at least daily.
+type SitemapService interface {
+	// Get returns the stored sitemap, building it first when there is none. Pharmacies that are inactive or have
+	// the website disabled have no sitemap.
+	Get(ctx context.Context, pharmacyID uuid.UUID) (*models.StorefrontSitemap, error)
+	// Regenerate rebuilds the sitemap now.
+	Regenerate(ctx context.Context, pharmacyID uuid.UUID) (*models.StorefrontSitemap, error)
+	// RefreshAll rebuilds the sitemaps whose content changed or that are older than a day (scheduler job).
+	RefreshAll(ctx context.Context) error
+}
+
+// UploadService stores user files, whole or in resumable chunks for large ones (CVs, chat and return request
+// videos). Content types are sniffed from the bytes rather than trusted from the client, files are virus scanned
+// when a scanner is configured, and each pharmacy has a storage quota.
+type UploadService interface {
+	// Upload checks and stores a file sent in one request.
+	Upload(ctx context.Context, pharmacyID, uploadedBy uuid.UUID, purpose, filename string, data []byte) (*models.Upload, error)
+	// StartUpload opens a resumable upload and reserves its size against the pharmacy's quota.
+	StartUpload(ctx context.Context, pharmacyID, uploadedBy uuid.UUID, in StartUploadInput) (*models.Upload, error)
+	// UploadChunk writes body at offset, which must equal the bytes received so far. The chunk that completes the
+	// file has it checked and stored; an empty chunk at offset = size retries that step.
+	UploadChunk(ctx context.Context, pharmacyID, uploadedBy, id uuid.UUID, offset int64, body io.Reader) (*models.Upload, error)
+	// GetUpload returns the caller's upload, e.g. to find the offset to resume from.
+	GetUpload(ctx context.Context, pharmacyID, uploadedBy, id uuid.UUID) (*models.Upload, error)
+	// CancelUpload drops a pending upload and releases its quota.
+	CancelUpload(ctx context.Context, pharmacyID, uploadedBy, id uuid.UUID) error
+	// ExpireStale drops resumable uploads that did not finish within models.UploadSessionTTL (scheduler job).
+	ExpireStale(ctx context.Context) error
+}
+
+// StartUploadInput describes a file about to be sent in chunks.
+type StartUploadInput struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"` // as declared; the stored type is sniffed
+	Size        int64  `json:"size"`
+	Purpose     string `json:"purpose"` // models.UploadPurpose*; default file
+}
+
+// FileCleanupService removes stored files nothing refers to any more, using the file ledger.
+type FileCleanupService interface {
+	// Release tells the sweep that the owner of these files was deleted, so they go at the next sweep instead of
+	// after the grace period, unless something else still refers to them. Empty URLs are ignored.
+	Release(ctx context.Context, urls ...string) error
+	// Sweep checks a batch of ledger entries and deletes the orphans among them; a dry run only reports them.
+	Sweep(ctx context.Context, dryRun bool) (*models.FileSweepReport, error)
+	// SweepOrphans runs a sweep that deletes (scheduler job).
+	SweepOrphans(ctx context.Context) error
+}
+
+// PharmacySignupService lets pharmacies register themselves. A signup creates a pending pharmacy with an
+// inactive owner admin; a platform admin approves it (activating both and seeding default config, categories and
+// payment gateways) or rejects it.
+type PharmacySignupService interface {
+	Signup(ctx context.Context, in PharmacySignupInput) (*PharmacySignup, error)
+	// List returns signups in the status (models.PharmacyStatus*), oldest first; default pending.
+	List(ctx context.Context, status string) ([]*PharmacySignup, error)
+	Get(ctx context.Context, pharmacyID uuid.UUID) (*PharmacySignup, error)
+	Approve(ctx context.Context, pharmacyID, reviewerID uuid.UUID) (*PharmacySignup, error)
+	Reject(ctx context.Context, pharmacyID, reviewerID uuid.UUID, reason string) (*PharmacySignup, error)
+}
+
+// PharmacySignupInput is a self-registration: the pharmacy, its owner's admin account and the license document.
+type PharmacySignupInput struct {
+	Name          string
+	LicenseNo     string
+	HostnameSlug  string // also the tenant code
+	BusinessType  string // models.BusinessType*; default pharmacy
+	Address       string
+	Phone         string
+	Email         string
+	OwnerName     string
+	OwnerEmail    string
+	OwnerPassword string
+	// LicenseFilename and License are the license document (PDF or image), required.
+	LicenseFilename string
+	License         []byte
+}
+
+// PharmacySignup is a signed-up pharmacy with its owner, for review.
+type PharmacySignup struct {
+	Pharmacy *models.Pharmacy `json:"pharmacy"`
+	Owner    *models.User     `json:"owner,omitempty"`
+	// LicenseDocumentURL is a short-lived signed link to the license document.
+	LicenseDocumentURL string `json:"license_document_url,omitempty"`
+}
+
+// PlatformService lets platform admins run the tenants: usage across pharmacies, suspension and impersonation.
+// Callers audit every action into the target pharmacy's activity log.
+type PlatformService interface {
+	ListTenants(ctx context.Context) ([]*models.TenantUsage, error)
+	// Suspend blocks the pharmacy's logins and hides its public catalog until it is reactivated; reason is required.
+	Suspend(ctx context.Context, pharmacyID, actorID uuid.UUID, reason string) (*models.Pharmacy, error)
+	Reactivate(ctx context.Context, pharmacyID, actorID uuid.UUID) (*models.Pharmacy, error)
+	// Impersonate issues the actor a short-lived token acting as a staff member of the pharmacy: userID, or the
+	// pharmacy's owner admin when nil. The token never carries platform admin rights.
+	Impersonate(ctx context.Context, pharmacyID, actorID uuid.UUID, userID *uuid.UUID) (*Impersonation, error)
+}
+
+// Impersonation is an access token a platform admin uses to act as a pharmacy user.
+type Impersonation struct {
+	AccessToken string       `json:"access_token"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+	User        *models.User `json:"user"`
+}
+
+// SubscriptionService runs pharmacies' subscription plans: limits on products, staff and storage, the features a
+// plan includes, and expiry with a grace period. Pharmacies without a subscription are not limited.
+type SubscriptionService interface {
+	Get(ctx context.Context, pharmacyID uuid.UUID) (*SubscriptionStatus, error)
+	// CheckWritable fails with errors.ErrCodePaymentRequired once the subscription is past its grace period.
+	CheckWritable(ctx context.Context, pharmacyID uuid.UUID) error
+	// CheckLimit fails with errors.ErrCodePlanLimit when the pharmacy already uses all of the limit
+	// (models.PlanLimit*).
+	CheckLimit(ctx context.Context, pharmacyID uuid.UUID, limit string) error
+	// CheckFeature fails with errors.ErrCodePlanLimit when the plan does not include the feature flag.
+	CheckFeature(ctx context.Context, pharmacyID uuid.UUID, feature string) error
+	// Renew puts the pharmacy on the plan for months more, counted from expiry or from now when already expired.
+	// Billing calls it once a payment is in; reference is the payment's reference.
+	Renew(ctx context.Context, pharmacyID, actorID uuid.UUID, plan string, months int, reference string) (*models.Subscription, error)
+}
+
+// SubscriptionStatus is a pharmacy's subscription with its state and usage; Subscription is nil when unlimited.
+type SubscriptionStatus struct {
+	Subscription *models.Subscription      `json:"subscription"`
+	Plan         *models.Plan              `json:"plan,omitempty"`
+	State        string                    `json:"state,omitempty"` // models.Subscription*
+	GraceEndsAt  *time.Time                `json:"grace_ends_at,omitempty"`
+	Usage        *models.SubscriptionUsage `json:"usage"`
+}
+
+// CurrencyService keeps a pharmacy's exchange rates from its base currency (PharmacyConfig.BaseCurrency) and
+// picks the rate storefront prices are shown with. Rates stored for a former base currency are ignored.
+type CurrencyService interface {
+	ListRates(ctx context.Context, pharmacyID uuid.UUID) ([]*models.ExchangeRate, error)
+	// SetRate sets how many units of currency one unit of the base currency is worth.
+	SetRate(ctx context.Context, pharmacyID, userID uuid.UUID, currency string, rate float64) (*models.ExchangeRate, error)
+	DeleteRate(ctx context.Context, pharmacyID uuid.UUID, currency string) error
+	// Storefront returns the currencies the public storefront can show prices in.
+	Storefront(ctx context.Context, pharmacyID uuid.UUID) (*StorefrontCurrencies, error)
+	// DisplayRate returns the rate to show storefront prices with: the requested currency, else the configured
+	// display currency. nil when that is empty or the base currency; a validation error when it has no rate.
+	DisplayRate(ctx context.Context, pharmacyID uuid.UUID, requested string) (*models.ExchangeRate, error)
+}
+
+// StorefrontCurrencies is the storefront's base currency, its default display currency and the rates it can
+// convert with.
+type StorefrontCurrencies struct {
+	Base    models.Currency        `json:"base"`
+	Display string                 `json:"display,omitempty"`
+	Rates   []*models.ExchangeRate `json:"rates"`
+}
@@ -14,6 +14,8 @@ type TokenClaims struct {
 	PharmacyID uuid.UUID
 	Role       string
 	ExpiresAt  time.Time
+	// ImpersonatorID is the platform admin acting as this user; nil for the user's own sessions.
+	ImpersonatorID *uuid.UUID
 }
 
 // ChatCustomerClaims is used for customer chat access (short-lived token).
@@ -25,9 +27,12 @@ type ChatCustomerClaims struct {
 
 type AuthProvider interface {
 	GenerateAccessToken(userID, pharmacyID uuid.UUID, role string) (string, error)
+	// GenerateImpersonationToken issues a platform admin an access token acting as the user, valid for ttl.
+	GenerateImpersonationToken(userID, pharmacyID uuid.UUID, role string, impersonatorID uuid.UUID, ttl time.Duration) (string, error)
 	GenerateRefreshToken(userID uuid.UUID) (string, error)
 	ValidateAccessToken(tokenString string) (*TokenClaims, error)
-	ValidateRefreshToken(tokenString string) (userID uuid.UUID, err error)
+	// ValidateRefreshToken returns the token's user and when it was issued (the login it belongs to).
+	ValidateRefreshToken(tokenString string) (userID uuid.UUID, issuedAt time.Time, err error)
 	GenerateChatCustomerToken(pharmacyID, customerID uuid.UUID) (string, error)
 	ValidateChatCustomerToken(tokenString string) (*ChatCustomerClaims, error)
 }
@@ -0,0 +1,22 @@
+package outbound
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// EmailMessage is a single outgoing email. HTMLBody is optional; TextBody is always sent.
+// PharmacyID (when known) scopes the message in the delivery queue's dead letters.
+type EmailMessage struct {
+	PharmacyID uuid.UUID
+	To         []string
+	Subject    string
+	TextBody   string
+	HTMLBody   string
+}
+
+// EmailSender delivers email. Implementations: log-only (development) or SMTP.
+type EmailSender interface {
+	Send(ctx context.Context, msg *EmailMessage) error
+}
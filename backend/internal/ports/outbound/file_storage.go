@@ -2,7 +2,9 @@ package outbound
 
 import (
 	"context"
+	"image"
 	"io"
+	"os"
 )
 
 // FileStorage saves files and returns a URL or path to access them.
@@ -12,3 +14,63 @@ type FileStorage interface {
 	// Returns the URL or path used to access the file (e.g. /uploads/photos/... or S3 URL).
 	Save(ctx context.Context, path string, body io.Reader, contentType string) (url string, err error)
 }
+
+// FileChecker reports whether a stored file still exists. URLs the storage did not issue (e.g. external
+// image links) are reported as existing, since they cannot be checked.
+type FileChecker interface {
+	Exists(ctx context.Context, url string) (bool, error)
+}
+
+// WebPEncoder encodes processed images as WebP. The standard library has no WebP encoder, so the
+// implementation shells out to libwebp's cwebp; without one, image variants are stored as JPEG.
+type WebPEncoder interface {
+	EncodeWebP(ctx context.Context, img image.Image, quality int) ([]byte, error)
+}
+
+// UploadStaging holds the chunks of resumable uploads until the last one arrives. Implementations: local
+// directory (shared by all API instances, or with sticky sessions).
+type UploadStaging interface {
+	// WriteAt writes body at offset, dropping anything already staged past it, and returns the bytes written.
+	WriteAt(ctx context.Context, id string, offset int64, body io.Reader) (int64, error)
+	Open(ctx context.Context, id string) (io.ReadCloser, error)
+	// Remove drops the staged chunks; removing an unknown id is not an error.
+	Remove(ctx context.Context, id string) error
+}
+
+// VirusScanner checks uploaded content for malware before it is stored. Implementations: ClamAV (clamd).
+type VirusScanner interface {
+	// Scan returns the detected signature name, or "" when the content is clean.
+	Scan(ctx context.Context, r io.Reader) (signature string, err error)
+}
+
+// PrivateFileRefPrefix marks a stored reference to a private file ("private:<path>"). References are kept in
+// place of URLs and only ever leave the API as signed links.
+const PrivateFileRefPrefix = "private:"
+
+// PrivateFileStorage keeps files that must not be publicly reachable (pharmacist CVs, prescriptions, return
+// request videos). Implementations: local (a directory outside the static files, served through HMAC-signed
+// API links) or S3 (a private bucket or prefix, served through presigned URLs).
+type PrivateFileStorage interface {
+	// SavePrivate stores the file at path and returns its reference ("private:<path>").
+	SavePrivate(ctx context.Context, path string, body io.Reader, contentType string) (ref string, err error)
+	// SignedURL returns a link to a private reference that works for the configured TTL. Any other URL is
+	// returned unchanged.
+	SignedURL(ctx context.Context, ref string) (string, error)
+	// PrivateRef maps a link issued by SignedURL back to its reference, so clients can send back what they
+	// were shown; ok is false for any other URL.
+	PrivateRef(url string) (ref string, ok bool)
+}
+
+// PrivateFileOpener opens private files for the signed links the API serves itself. Implementations: local
+// (S3 presigned URLs are served by S3).
+type PrivateFileOpener interface {
+	// OpenPrivate fails with signing.ErrInvalidToken or signing.ErrExpiredToken unless token is a valid
+	// signature for path.
+	OpenPrivate(path, token string) (*os.File, error)
+}
+
+// FileDeleter removes files the storage issued, by the URL or private reference Save or SavePrivate returned.
+// Deleting a file that is already gone is not an error.
+type FileDeleter interface {
+	Delete(ctx context.Context, url string) error
+}
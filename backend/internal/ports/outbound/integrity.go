@@ -0,0 +1,29 @@
+package outbound
+
+import (
+	"context"
+
+	"github.com/careplus/pharmacy-backend/internal/domain/models"
+	"github.com/google/uuid"
+)
+
+// IntegrityRepository runs the data doctor's consistency queries. pharmacyID limits a check to one tenant;
+// nil scans every pharmacy.
+type IntegrityRepository interface {
+	// CrossTenantOrderItems returns order items whose product belongs to a different pharmacy than the order.
+	CrossTenantOrderItems(ctx context.Context, pharmacyID *uuid.UUID) ([]models.IntegrityIssue, error)
+	// PaymentsWithoutOrder returns live payments whose order does not exist or is deleted.
+	PaymentsWithoutOrder(ctx context.Context, pharmacyID *uuid.UUID) ([]models.IntegrityIssue, error)
+	// PointsLedgerMismatches returns customers whose points balance differs from the sum of their points transactions.
+	PointsLedgerMismatches(ctx context.Context, pharmacyID *uuid.UUID) ([]models.IntegrityIssue, error)
+	// ProductImages returns every live product image; Detail is the image URL.
+	ProductImages(ctx context.Context, pharmacyID *uuid.UUID) ([]models.IntegrityIssue, error)
+	// ProductCategoryDrift returns live products whose category string differs from the name of their live category.
+	ProductCategoryDrift(ctx context.Context, pharmacyID *uuid.UUID) ([]models.IntegrityIssue, error)
+	// ResetPointsFromLedger sets the customers' balances to the sum of their points transactions.
+	ResetPointsFromLedger(ctx context.Context, customerIDs []uuid.UUID) (int64, error)
+	// SoftDeleteProductImages soft-deletes the images (they can be restored by clearing deleted_at).
+	SoftDeleteProductImages(ctx context.Context, imageIDs []uuid.UUID) (int64, error)
+	// ResyncProductCategories copies the category name onto the products' category string.
+	ResyncProductCategories(ctx context.Context, productIDs []uuid.UUID) (int64, error)
+}
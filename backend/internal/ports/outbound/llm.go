@@ -0,0 +1,17 @@
+package outbound
+
+import "context"
+
+// LLMCompletion is the text returned by a language model plus token usage for quota/audit.
+type LLMCompletion struct {
+	Text             string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// LLMProvider generates text from a system + user prompt.
+// Implementations: OpenAI-compatible HTTP API, or a stub for development.
+type LLMProvider interface {
+	Complete(ctx context.Context, systemPrompt, userPrompt string) (*LLMCompletion, error)
+}
@@ -0,0 +1,25 @@
+package outbound
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrPushTokenInvalid is returned by PushSender when the provider reports the device token as
+// unregistered or malformed; the token should be forgotten.
+var ErrPushTokenInvalid = errors.New("push token is no longer valid")
+
+// PushMessage is a single notification for one device. Data values are delivered to the app as-is;
+// Link is opened when a web push notification is clicked.
+type PushMessage struct {
+	Token string
+	Title string
+	Body  string
+	Data  map[string]string
+	Link  string
+}
+
+// PushSender delivers push notifications. Implementations: log-only (development) or FCM.
+type PushSender interface {
+	Send(ctx context.Context, msg *PushMessage) error
+}
@@ -0,0 +1,20 @@
+package outbound
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimitDecision is the outcome of taking one token from a bucket. RetryAfter is how long until the next
+// token is available; it is zero when the request was allowed.
+type RateLimitDecision struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// RateLimiter is a token bucket per key holding up to limit tokens and refilling limit tokens per window.
+// Implementations: in-process memory (one instance) and Redis (shared across instances).
+type RateLimiter interface {
+	Take(ctx context.Context, key string, limit int, window time.Duration) (RateLimitDecision, error)
+}
@@ -0,0 +1,17 @@
+package outbound
+
+import "github.com/google/uuid"
+
+// RealtimeNotifier delivers events to a user's open WebSocket connections. Delivery is best effort:
+// users without a connection simply miss the event and catch up through the REST API.
+type RealtimeNotifier interface {
+	SendToUser(userID uuid.UUID, event string, data interface{})
+}
+
+// PresenceTracker reports who currently has an open WebSocket connection.
+type PresenceTracker interface {
+	IsUserOnline(userID uuid.UUID) bool
+	IsCustomerOnline(customerID uuid.UUID) bool
+	// OnlineStaff returns the pharmacy team members (admin, manager, pharmacist) that are online.
+	OnlineStaff(pharmacyID uuid.UUID) []uuid.UUID
+}
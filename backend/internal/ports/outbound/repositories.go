@@ -17,6 +17,12 @@ type PharmacyRepository interface {
 	GetByHostnameSlug(ctx context.Context, hostnameSlug string) (*models.Pharmacy, error)
 	Update(ctx context.Context, p *models.Pharmacy) error
 	List(ctx context.Context) ([]*models.Pharmacy, error)
+	// ListByStatus returns pharmacies in the status, oldest first.
+	ListByStatus(ctx context.Context, status string) ([]*models.Pharmacy, error)
+	// Taken reports whether a pharmacy, deleted ones included, already has the license number or hostname slug.
+	Taken(ctx context.Context, licenseNo, hostnameSlug string) (bool, error)
+	// ListUsage returns every pharmacy with its order, user and storage totals, newest pharmacy first.
+	ListUsage(ctx context.Context) ([]*models.TenantUsage, error)
 }
 
 type UserRepository interface {
@@ -25,16 +31,36 @@ type UserRepository interface {
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	GetByPharmacyID(ctx context.Context, pharmacyID uuid.UUID) ([]*models.User, error)
 	Update(ctx context.Context, u *models.User) error
+	// SetLicenseReminderDays records the license reminder threshold last sent to the user.
+	SetLicenseReminderDays(ctx context.Context, id uuid.UUID, days int) error
 }
 
 type DutyRosterRepository interface {
 	Create(ctx context.Context, d *models.DutyRoster) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.DutyRoster, error)
 	ListByPharmacyAndDateRange(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.DutyRoster, error)
+	ListByStatusAndDateRange(ctx context.Context, pharmacyID uuid.UUID, status models.RosterStatus, from, to time.Time) ([]*models.DutyRoster, error)
+	ListByUserAndDateRange(ctx context.Context, pharmacyID, userID uuid.UUID, status models.RosterStatus, from, to time.Time) ([]*models.DutyRoster, error)
+	// ListUnacknowledged returns published entries dated on or after from that their staff have not acknowledged.
+	ListUnacknowledged(ctx context.Context, pharmacyID uuid.UUID, from time.Time) ([]*models.DutyRoster, error)
 	Update(ctx context.Context, d *models.DutyRoster) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
+// ShiftSwapRepository stores shift swap offers with their requests and audit trail.
+type ShiftSwapRepository interface {
+	// Create stores a new offer together with its first audit event.
+	Create(ctx context.Context, s *models.ShiftSwap, e *models.ShiftSwapEvent) error
+	// GetByID preloads the roster entry, offerer, requests (with users) and events.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ShiftSwap, error)
+	// List returns a pharmacy's swaps, newest first; an empty status returns all.
+	List(ctx context.Context, pharmacyID uuid.UUID, status models.ShiftSwapStatus) ([]*models.ShiftSwap, error)
+	// GetOpenByRoster returns the open offer for a roster entry, or nil when there is none.
+	GetOpenByRoster(ctx context.Context, rosterID uuid.UUID) (*models.ShiftSwap, error)
+	// Save writes the swap, its requests, the roster entry (when not nil) and the event in one transaction.
+	Save(ctx context.Context, s *models.ShiftSwap, roster *models.DutyRoster, e *models.ShiftSwapEvent) error
+}
+
 type DailyLogRepository interface {
 	Create(ctx context.Context, d *models.DailyLog) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.DailyLog, error)
@@ -44,12 +70,17 @@ type DailyLogRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
-// CatalogFilters are optional filters for the product catalog (hashtag, brand, label key-value).
+// CatalogFilters are optional filters for the product catalog (hashtag, brand, label key-value, dosage form, price range,
+// custom attributes).
 type CatalogFilters struct {
 	Hashtag    *string
 	Brand      *string
 	LabelKey   *string
 	LabelValue *string
+	DosageForm *string
+	MinPrice   *float64 // inclusive
+	MaxPrice   *float64 // exclusive, so price buckets can be selected without overlap
+	Attributes map[string]any // product attribute key -> value; all must match
 }
 
 // CatalogSort defines sort options for product catalog listing.
@@ -72,7 +103,35 @@ type ProductRepository interface {
 	ListByPharmacyPaginated(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, limit, offset int) ([]*models.Product, int64, error)
 	// ListByPharmacyCatalog returns a page of products with optional search (q), sort, and catalog filters (hashtag, brand, label).
 	ListByPharmacyCatalog(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, searchQ string, sort CatalogSort, limit, offset int, filters *CatalogFilters) ([]*models.Product, int64, error)
+	// CatalogFacets counts active catalog products per category, brand, dosage form, price bucket and hashtag with
+	// grouped queries. priceEdges are ascending bucket boundaries; at most facetLimit values are returned per facet.
+	CatalogFacets(ctx context.Context, pharmacyID uuid.UUID, category *string, inStockOnly *bool, searchQ string, filters *CatalogFilters, priceEdges []float64, facetLimit int) (*models.CatalogFacets, error)
+	// ListChangedSince returns products (including soft-deleted ones) whose change time is after the (since, afterID)
+	// cursor and before until, ordered by change time then id.
+	ListChangedSince(ctx context.Context, pharmacyID uuid.UUID, since time.Time, afterID uuid.UUID, until time.Time, limit int) ([]*models.ProductDelta, error)
+	// ListLowStock returns active products with stock_quantity <= threshold, preloading Supplier.
+	ListLowStock(ctx context.Context, pharmacyID uuid.UUID, threshold int) ([]*models.Product, error)
+	// ListBelowReorderLevel returns active products with stock_quantity <= their reorder_level, or <= defaultLevel
+	// when they have none, preloading Supplier.
+	ListBelowReorderLevel(ctx context.Context, pharmacyID uuid.UUID, defaultLevel int) ([]*models.Product, error)
+	// UnitsSold sums order item quantities per product on completed orders created at or after since.
+	UnitsSold(ctx context.Context, pharmacyID uuid.UUID, since time.Time) (map[uuid.UUID]int, error)
+	// SyncCategoryName sets the denormalized category string on the category's products that differ from name.
+	SyncCategoryName(ctx context.Context, categoryID uuid.UUID, name string) (int64, error)
+	// RenameBrand sets brand to "to" on the pharmacy's products whose brand matches "from" ignoring case and
+	// surrounding spaces.
+	RenameBrand(ctx context.Context, pharmacyID uuid.UUID, from, to string) (int64, error)
+	// ListAlternatives returns the pharmacy's active, in-stock products with p's generic name and dosage form
+	// (ignoring case and surrounding spaces) that cost less than p, cheapest first.
+	ListAlternatives(ctx context.Context, p *models.Product, limit int) ([]*models.Product, error)
+	// WithAlternatives returns which of productIDs have at least one product ListAlternatives would return.
+	WithAlternatives(ctx context.Context, productIDs []uuid.UUID) ([]uuid.UUID, error)
+	// Update saves everything but stock_quantity, which only changes through AdjustStock so a stale copy
+	// cannot overwrite a concurrent sale.
 	Update(ctx context.Context, p *models.Product) error
+	// AdjustStock adds delta to stock_quantity in one statement; false, changing nothing, when stock would go
+	// below zero.
+	AdjustStock(ctx context.Context, id uuid.UUID, delta int) (bool, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
@@ -85,30 +144,102 @@ type ProductImageRepository interface {
 }
 
 type OrderRepository interface {
-	Create(ctx context.Context, o *models.Order) error
+	// Create inserts the order and appends events to the outbox in one transaction.
+	Create(ctx context.Context, o *models.Order, events ...*models.OutboxEvent) error
 	CreateItem(ctx context.Context, item *models.OrderItem) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Order, error)
 	GetByOrderNumber(ctx context.Context, pharmacyID uuid.UUID, orderNumber string) (*models.Order, error)
 	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, status *string) ([]*models.Order, error)
 	// ListByPharmacyAndCreatedBy returns orders for the pharmacy placed by the given user (for end-user "my orders").
 	ListByPharmacyAndCreatedBy(ctx context.Context, pharmacyID uuid.UUID, createdBy uuid.UUID, status *string) ([]*models.Order, error)
+	// ListPage returns one page of orders, newest first, and the total matching; createdBy limits to one user's orders
+	// and branchID to one branch's.
+	ListPage(ctx context.Context, pharmacyID uuid.UUID, createdBy *uuid.UUID, status *string, branchID *uuid.UUID, limit, offset int) ([]*models.Order, int64, error)
+	// ListPaginated is ListPage with the full OrderFilter (date range and order-number search).
+	ListPaginated(ctx context.Context, pharmacyID uuid.UUID, filter OrderFilter, limit, offset int) ([]*models.Order, int64, error)
+	// ItemCounts returns line and unit counts for the given orders; orders without items are left out.
+	ItemCounts(ctx context.Context, orderIDs []uuid.UUID) ([]*models.OrderItemCountRow, error)
 	Update(ctx context.Context, o *models.Order) error
+	// SetConversation links the order to the chat conversation it was created from.
+	SetConversation(ctx context.Context, orderID, conversationID uuid.UUID) error
+	// UpdateStatus saves the order, appends the status change to its history and events to the outbox in one
+	// transaction.
+	UpdateStatus(ctx context.Context, o *models.Order, h *models.OrderStatusHistory, events ...*models.OutboxEvent) error
+	CreateStatusHistory(ctx context.Context, h *models.OrderStatusHistory) error
+	// ListStatusHistory returns the status changes of the given orders, oldest first.
+	ListStatusHistory(ctx context.Context, orderIDs []uuid.UUID) ([]*models.OrderStatusHistory, error)
 	GetItemsByOrderID(ctx context.Context, orderID uuid.UUID) ([]*models.OrderItem, error)
 	CountByCustomerIDAndStatus(ctx context.Context, customerID uuid.UUID, status string) (int64, error)
 	// CountByCreatedByAndPharmacy returns the number of orders placed by this user at this pharmacy (for first-order-only promo).
 	CountByCreatedByAndPharmacy(ctx context.Context, createdBy, pharmacyID uuid.UUID) (int64, error)
+	// CountByPromoCodeAndUser returns the user's non-cancelled orders that used the promo code (per-user promo limit).
+	CountByPromoCodeAndUser(ctx context.Context, promoCodeID, createdBy uuid.UUID) (int64, error)
 	// GetLatestCompletedOrderWithProduct returns the most recent completed order by this user at this pharmacy that contains the given product (for 7-day review window).
 	GetLatestCompletedOrderWithProduct(ctx context.Context, pharmacyID, userID, productID uuid.UUID) (*models.Order, error)
 }
 
+// OrderFilter narrows OrderRepository.ListPaginated; zero values match everything. From is inclusive and To
+// exclusive on created_at; OrderNumber matches case-insensitively anywhere in the number.
+type OrderFilter struct {
+	CreatedBy   *uuid.UUID
+	BranchID    *uuid.UUID
+	Status      string
+	From        time.Time
+	To          time.Time
+	OrderNumber string
+}
+
 type OrderFeedbackRepository interface {
 	Create(ctx context.Context, f *models.OrderFeedback) error
 	GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.OrderFeedback, error)
+	// GetByID returns the feedback with its order; nil, nil when not found.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.OrderFeedback, error)
+	Update(ctx context.Context, f *models.OrderFeedback) error
+	// ListByPharmacy returns feedback on the pharmacy's orders, newest first, with order and user.
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, filter FeedbackFilter, limit, offset int) ([]*models.OrderFeedback, int64, error)
+	// RatingTrend buckets feedback created in [from, to) by granularity: "day", "week" or "month".
+	RatingTrend(ctx context.Context, pharmacyID uuid.UUID, granularity string, from, to time.Time) ([]*models.FeedbackTrendRow, error)
+	// RatingByStaff groups by the delivery assignee, or else the order creator when that is a team member.
+	RatingByStaff(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.FeedbackGroupRow, error)
+	// RatingByFulfilment groups into "delivery" (delivery record or address) and "pickup".
+	RatingByFulfilment(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.FeedbackGroupRow, error)
+}
+
+// FeedbackFilter narrows ListByPharmacy; zero values match everything.
+type FeedbackFilter struct {
+	FollowUpStatus string
+	MaxRating      int
 }
 
 type OrderReturnRequestRepository interface {
 	Create(ctx context.Context, r *models.OrderReturnRequest) error
 	GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.OrderReturnRequest, error)
+	// GetByID returns the request with its order; nil, nil when not found.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.OrderReturnRequest, error)
+	Update(ctx context.Context, r *models.OrderReturnRequest) error
+	// ListByPharmacy returns requests on the pharmacy's orders, newest first, with order and user.
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, filter ReturnRequestFilter, limit, offset int) ([]*models.OrderReturnRequest, int64, error)
+	// ReturnRates compares units sold on completed orders created in [from, to) with the units covered by
+	// non-rejected return requests on those orders. groupBy is "product", "brand" or "supplier";
+	// productIDs, when set, limits the rows to those products.
+	ReturnRates(ctx context.Context, pharmacyID uuid.UUID, groupBy string, from, to time.Time, productIDs []uuid.UUID) ([]*models.ReturnRateRow, error)
+	// ReasonBreakdown counts non-rejected return requests created in [from, to) by reason code.
+	ReasonBreakdown(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.ReturnReasonRow, error)
+}
+
+// ReturnRequestFilter narrows OrderReturnRequestRepository.ListByPharmacy; zero values match everything.
+type ReturnRequestFilter struct {
+	Status     string
+	ReasonCode string
+}
+
+type ProductReturnFlagRepository interface {
+	// GetByProductID returns the product's flag; nil, nil when it was never flagged.
+	GetByProductID(ctx context.Context, productID uuid.UUID) (*models.ProductReturnFlag, error)
+	Create(ctx context.Context, f *models.ProductReturnFlag) error
+	Update(ctx context.Context, f *models.ProductReturnFlag) error
+	// ListByPharmacy returns flags with their product, highest return rate first; empty status lists all.
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, status string) ([]*models.ProductReturnFlag, error)
 }
 
 type PaymentRepository interface {
@@ -116,7 +247,8 @@ type PaymentRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Payment, error)
 	ListByOrderID(ctx context.Context, orderID uuid.UUID) ([]*models.Payment, error)
 	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Payment, error)
-	Update(ctx context.Context, p *models.Payment) error
+	// Update saves the payment and appends events to the outbox in one transaction.
+	Update(ctx context.Context, p *models.Payment, events ...*models.OutboxEvent) error
 }
 
 type PaymentGatewayRepository interface {
@@ -153,7 +285,22 @@ type ProductUnitRepository interface {
 
 type ActivityLogRepository interface {
 	Create(ctx context.Context, a *models.ActivityLog) error
-	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.ActivityLog, error)
+	// ListByPharmacy returns matching entries with their user, newest first.
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, filter ActivityLogFilter, limit, offset int) ([]*models.ActivityLog, error)
+	// ListForExport returns up to limit matching entries with their user, oldest first.
+	ListForExport(ctx context.Context, pharmacyID uuid.UUID, filter ActivityLogFilter, limit int) ([]*models.ActivityLog, error)
+	// DeleteBefore removes the pharmacy's entries created before cutoff and returns how many were removed.
+	DeleteBefore(ctx context.Context, pharmacyID uuid.UUID, cutoff time.Time) (int64, error)
+}
+
+// ActivityLogFilter narrows activity log queries; zero values match everything. Action matches a substring
+// ignoring case; To is exclusive.
+type ActivityLogFilter struct {
+	UserID     *uuid.UUID
+	Action     string
+	EntityType string
+	From       *time.Time
+	To         *time.Time
 }
 
 type InvoiceRepository interface {
@@ -164,6 +311,16 @@ type InvoiceRepository interface {
 	Update(ctx context.Context, inv *models.Invoice) error
 }
 
+type CreditNoteRepository interface {
+	// Create assigns the next number in the pharmacy's sequence and saves n, unless the order's credit notes
+	// would then exceed maxTotal (returns false).
+	Create(ctx context.Context, n *models.CreditNote, maxTotal float64) (bool, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.CreditNote, error)
+	ListByOrder(ctx context.Context, orderID uuid.UUID) ([]*models.CreditNote, error)
+	SumByOrder(ctx context.Context, orderID uuid.UUID) (float64, error)
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.CreditNote, int64, error)
+}
+
 type NotificationRepository interface {
 	Create(ctx context.Context, n *models.Notification) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Notification, error)
@@ -180,7 +337,63 @@ type InventoryBatchRepository interface {
 	ListByPharmacyID(ctx context.Context, pharmacyID uuid.UUID) ([]*models.InventoryBatch, error)
 	ListExpiringByPharmacy(ctx context.Context, pharmacyID uuid.UUID, beforeOrOn time.Time) ([]*models.InventoryBatch, error)
 	Update(ctx context.Context, b *models.InventoryBatch) error
+	// Draw takes quantity from the batch in one statement; false, changing nothing, when it holds less.
+	Draw(ctx context.Context, id uuid.UUID, quantity int) (bool, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	CreateAllocation(ctx context.Context, a *models.OrderItemBatch) error
+	// ListAllocationsByBatch returns the order items that drew from a batch, newest first, with their order item.
+	ListAllocationsByBatch(ctx context.Context, batchID uuid.UUID) ([]*models.OrderItemBatch, error)
+}
+
+// BranchRepository stores a pharmacy's branches and the stock transfers between them.
+type BranchRepository interface {
+	Create(ctx context.Context, b *models.Branch) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Branch, error)
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Branch, error)
+	// GetDefault returns the pharmacy's default branch; nil, nil when it has none.
+	GetDefault(ctx context.Context, pharmacyID uuid.UUID) (*models.Branch, error)
+	Update(ctx context.Context, b *models.Branch) error
+	// SetDefault makes the branch the pharmacy's only default branch.
+	SetDefault(ctx context.Context, pharmacyID, branchID uuid.UUID) error
+	// Delete soft-deletes the branch and unassigns its team members.
+	Delete(ctx context.Context, id uuid.UUID) error
+	// AdoptUnassignedBatches moves the pharmacy's batches that have no branch to branchID.
+	AdoptUnassignedBatches(ctx context.Context, pharmacyID, branchID uuid.UUID) (int64, error)
+	// Stock sums the branch's batch quantities per product; batches that expired before today count as Expired.
+	Stock(ctx context.Context, branchID uuid.UUID, today time.Time) ([]*models.BranchStockRow, error)
+	// CreateTransfer saves the changed batches (debited sources, credited existing destinations), inserts the new
+	// destination batches and inserts the transfer with its items, in one transaction.
+	CreateTransfer(ctx context.Context, t *models.BranchTransfer, updated, created []*models.InventoryBatch) error
+	// GetTransfer returns the transfer with branches and items (with products).
+	GetTransfer(ctx context.Context, id uuid.UUID) (*models.BranchTransfer, error)
+	// ListTransfers returns transfers newest first; branchID limits to transfers into or out of that branch.
+	ListTransfers(ctx context.Context, pharmacyID uuid.UUID, branchID *uuid.UUID, limit, offset int) ([]*models.BranchTransfer, int64, error)
+}
+
+// StockTransferRepository stores inter-pharmacy stock transfers with their items and audit events.
+type StockTransferRepository interface {
+	// Create stores the transfer and its items together with the first audit event.
+	Create(ctx context.Context, t *models.StockTransfer, e *models.StockTransferEvent) error
+	// GetByID preloads both pharmacies, items (with batch records) and events.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.StockTransfer, error)
+	// List returns transfers the pharmacy takes part in, newest first. direction "incoming" or "outgoing" limits
+	// to one side; an empty status returns all.
+	List(ctx context.Context, pharmacyID uuid.UUID, direction string, status models.StockTransferStatus, limit, offset int) ([]*models.StockTransfer, int64, error)
+	// Transition saves the transfer's status and actor fields and the event, only if the status is still from;
+	// returns false otherwise.
+	Transition(ctx context.Context, t *models.StockTransfer, from models.StockTransferStatus, e *models.StockTransferEvent) (bool, error)
+	// Receive is Transition from in_transit that also, in the same transaction, saves the debited source batches,
+	// inserts the destination batches, adds stockDeltas to products' stock_quantity and stores the items' batch records.
+	Receive(ctx context.Context, t *models.StockTransfer, e *models.StockTransferEvent, debited, created []*models.InventoryBatch, stockDeltas map[uuid.UUID]int) (bool, error)
+}
+
+// InventoryAlertRepository stores the open alerts of the scheduled inventory scan.
+type InventoryAlertRepository interface {
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.InventoryAlert, error)
+	// Create inserts the alert and appends events to the outbox in one transaction.
+	Create(ctx context.Context, a *models.InventoryAlert, events ...*models.OutboxEvent) error
+	MarkSeen(ctx context.Context, ids []uuid.UUID, at time.Time) error
+	Delete(ctx context.Context, ids []uuid.UUID) error
 }
 
 // RatingStats holds aggregate rating for a product.
@@ -190,7 +403,8 @@ type RatingStats struct {
 }
 
 type ProductReviewRepository interface {
-	Create(ctx context.Context, r *models.ProductReview) error
+	// Create inserts the review and appends events to the outbox in one transaction.
+	Create(ctx context.Context, r *models.ProductReview, events ...*models.OutboxEvent) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.ProductReview, error)
 	ListByProductID(ctx context.Context, productID uuid.UUID, limit, offset int) ([]*models.ProductReview, error)
 	Update(ctx context.Context, r *models.ProductReview) error
@@ -198,6 +412,13 @@ type ProductReviewRepository interface {
 	ExistsByProductAndUser(ctx context.Context, productID, userID uuid.UUID) (bool, error)
 	// GetRatingStatsByProductIDs returns avg rating and review count per product (for catalog display).
 	GetRatingStatsByProductIDs(ctx context.Context, productIDs []uuid.UUID) (map[uuid.UUID]RatingStats, error)
+	// GetResponse returns the pharmacy's response to the review, or nil when there is none.
+	GetResponse(ctx context.Context, reviewID uuid.UUID) (*models.ReviewResponse, error)
+	ListResponses(ctx context.Context, reviewIDs []uuid.UUID) ([]*models.ReviewResponse, error)
+	// SaveResponse creates or updates the response.
+	SaveResponse(ctx context.Context, r *models.ReviewResponse) error
+	// DeleteResponse removes the review's response and reports whether there was one.
+	DeleteResponse(ctx context.Context, reviewID uuid.UUID) (bool, error)
 }
 
 type ReviewLikeRepository interface {
@@ -238,6 +459,40 @@ type PromoRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
+// HashtagRepository is the per-pharmacy hashtag registry and the bulk rewrites of product hashtags.
+type HashtagRepository interface {
+	Create(ctx context.Context, h *models.Hashtag) error
+	// GetByID returns nil, nil when not found.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Hashtag, error)
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Hashtag, error)
+	Update(ctx context.Context, h *models.Hashtag) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Usage counts the pharmacy's products per tag value exactly as stored.
+	Usage(ctx context.Context, pharmacyID uuid.UUID) ([]*models.HashtagUsageRow, error)
+	// ReplaceOnProducts rewrites the tags in from to `to` on the pharmacy's products, keeping order and dropping
+	// duplicates; an empty `to` removes them. It returns the number of products changed.
+	ReplaceOnProducts(ctx context.Context, pharmacyID uuid.UUID, from []string, to string) (int64, error)
+	// Merge saves target (with source's tags added to its aliases), deletes source and rewrites source's tags to
+	// target's on products, in one transaction.
+	Merge(ctx context.Context, source, target *models.Hashtag) (int64, error)
+	// Trending sums views and non-cancelled units sold since `since` per tag on active products; tags with
+	// neither are left out.
+	Trending(ctx context.Context, pharmacyID uuid.UUID, since time.Time) ([]*models.HashtagTrend, error)
+}
+
+// ProductViewRepository stores daily storefront product view counters.
+type ProductViewRepository interface {
+	// Increment adds one view to the (product, day) row, creating it when missing.
+	Increment(ctx context.Context, pharmacyID, productID uuid.UUID, day time.Time) error
+}
+
+// PromoStatRepository stores daily promo impression/click counters.
+type PromoStatRepository interface {
+	// Increment adds impressions and clicks to the (promo, day, placement) row, creating it when missing.
+	Increment(ctx context.Context, pharmacyID, promoID uuid.UUID, day time.Time, placement string, impressions, clicks int64) error
+	ListByPromo(ctx context.Context, promoID uuid.UUID, from, to time.Time) ([]*models.PromoDailyStat, error)
+}
+
 type PromoCodeRepository interface {
 	Create(ctx context.Context, p *models.PromoCode) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.PromoCode, error)
@@ -254,6 +509,13 @@ type CustomerRepository interface {
 	GetByPharmacyAndReferralCode(ctx context.Context, pharmacyID uuid.UUID, code string) (*models.Customer, error)
 	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.Customer, int64, error)
 	Update(ctx context.Context, c *models.Customer) error
+	// AdjustPoints adds delta to points_balance in one statement; false, changing nothing, when the balance
+	// would go below zero.
+	AdjustPoints(ctx context.Context, customerID uuid.UUID, delta int) (bool, error)
+	// ClaimBirthdayGift records year as the customer's birthday gift year; false when it is already.
+	ClaimBirthdayGift(ctx context.Context, customerID uuid.UUID, year int) (bool, error)
+	// ReleaseBirthdayGift undoes a claim for year (order not created).
+	ReleaseBirthdayGift(ctx context.Context, customerID uuid.UUID, year int) error
 }
 
 type PointsTransactionRepository interface {
@@ -273,12 +535,50 @@ type StaffPointsConfigRepository interface {
 	Update(ctx context.Context, c *models.StaffPointsConfig) error
 }
 
+type CustomerSegmentRepository interface {
+	Create(ctx context.Context, s *models.CustomerSegment) error
+	// GetByID returns nil, nil when the segment does not exist.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.CustomerSegment, error)
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.CustomerSegment, error)
+	Update(ctx context.Context, s *models.CustomerSegment) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Members returns up to limit customers matching the criteria (all when limit is 0) and how many match.
+	Members(ctx context.Context, pharmacyID uuid.UUID, c models.SegmentCriteria, limit int) ([]*models.SegmentMember, int64, error)
+}
+
+type CampaignRepository interface {
+	Create(ctx context.Context, c *models.Campaign) error
+	// GetByID returns the campaign with its segment, or nil, nil when it does not exist.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Campaign, error)
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.Campaign, int64, error)
+	Update(ctx context.Context, c *models.Campaign) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// StartSending moves a draft to sending with its criteria snapshot, recipient count and sent_at; false when
+	// it is no longer a draft.
+	StartSending(ctx context.Context, c *models.Campaign) (bool, error)
+	// RecordRecipients stores a batch of outcomes and adds them to the campaign's counts.
+	RecordRecipients(ctx context.Context, campaignID uuid.UUID, recipients []*models.CampaignRecipient) error
+	FinishSending(ctx context.Context, id uuid.UUID, at time.Time) error
+	ListRecipients(ctx context.Context, campaignID uuid.UUID, status string, limit, offset int) ([]*models.CampaignRecipient, int64, error)
+}
+
+type StaffPointsTransactionRepository interface {
+	// Credit records the transaction and adds its points to the user's balance in one transaction. It returns
+	// false when the order was already credited.
+	Credit(ctx context.Context, t *models.StaffPointsTransaction) (bool, error)
+	ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.StaffPointsTransaction, int64, error)
+	// MonthlyTotals sums points per user per calendar month in [from, to).
+	MonthlyTotals(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.StaffPointsMonthRow, error)
+}
+
 type ConversationRepository interface {
 	Create(ctx context.Context, c *models.Conversation) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Conversation, error)
 	GetByPharmacyAndCustomer(ctx context.Context, pharmacyID, customerID uuid.UUID) (*models.Conversation, error)
 	GetByPharmacyAndUser(ctx context.Context, pharmacyID, userID uuid.UUID) (*models.Conversation, error)
 	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, userID *uuid.UUID, limit, offset int) ([]*models.Conversation, int64, error)
+	// GetByTicket finds the conversation escalated to the given external ticket (gorm.ErrRecordNotFound when none).
+	GetByTicket(ctx context.Context, provider, ticketID string) (*models.Conversation, error)
 	Update(ctx context.Context, c *models.Conversation) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -328,10 +628,90 @@ type BlogPostRepository interface {
 	Create(ctx context.Context, p *models.BlogPost) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.BlogPost, error)
 	GetByPharmacyAndSlug(ctx context.Context, pharmacyID uuid.UUID, slug string) (*models.BlogPost, error)
-	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, status *string, categoryID *uuid.UUID, limit, offset int) ([]*models.BlogPost, int64, error)
+	// ListByPharmacy filters by status, category and tag when given.
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, status *string, categoryID, tagID *uuid.UUID, limit, offset int) ([]*models.BlogPost, int64, error)
 	ListPendingByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.BlogPost, int64, error)
 	Update(ctx context.Context, p *models.BlogPost) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	// PublishScheduled publishes scheduled posts whose time is at or before now, each with its scheduled time as
+	// the published time, and returns how many it published.
+	PublishScheduled(ctx context.Context, now time.Time) (int64, error)
+}
+
+// BlogPostRevisionRepository stores snapshots of blog post content.
+type BlogPostRevisionRepository interface {
+	// Create numbers r as the post's next revision and saves it, in one transaction under a per-post lock. A
+	// baseline is only saved as the first revision and is skipped (r.Revision stays 0) when the post has any.
+	Create(ctx context.Context, r *models.BlogPostRevision) error
+	// GetByID returns the revision with its editor; nil, nil when not found.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.BlogPostRevision, error)
+	// ListByPostID returns the post's revisions, newest first, with editors and the total count.
+	ListByPostID(ctx context.Context, postID uuid.UUID, limit, offset int) ([]*models.BlogPostRevision, int64, error)
+	// LatestRevision returns 0 when the post has no revisions yet.
+	LatestRevision(ctx context.Context, postID uuid.UUID) (int, error)
+}
+
+// BlogTagRepository stores blog tags and which posts carry them.
+type BlogTagRepository interface {
+	Create(ctx context.Context, t *models.BlogTag) error
+	// GetByID and GetBySlug return nil, nil when not found.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.BlogTag, error)
+	GetBySlug(ctx context.Context, pharmacyID uuid.UUID, slug string) (*models.BlogTag, error)
+	// ListByPharmacy returns the tags by name with PostCount set. publishedOnly counts published posts only and
+	// leaves out tags without any.
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, publishedOnly bool) ([]*models.BlogTag, error)
+	Update(ctx context.Context, t *models.BlogTag) error
+	// Delete removes the tag and its links to posts.
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListByPostID(ctx context.Context, postID uuid.UUID) ([]*models.BlogTag, error)
+	// SetPostTags replaces the post's tags.
+	SetPostTags(ctx context.Context, postID uuid.UUID, tagIDs []uuid.UUID) error
+}
+
+// SitemapRepository stores generated storefront sitemaps and reads the content they list.
+type SitemapRepository interface {
+	// Get returns nil, nil when the pharmacy has no sitemap yet.
+	Get(ctx context.Context, pharmacyID uuid.UUID) (*models.StorefrontSitemap, error)
+	// Save inserts or replaces the pharmacy's sitemap.
+	Save(ctx context.Context, s *models.StorefrontSitemap) error
+	// Fingerprint summarizes the counts and latest changes of active products, categories, published posts and
+	// the config; it changes whenever the sitemap would.
+	Fingerprint(ctx context.Context, pharmacyID uuid.UUID) (string, error)
+	// Sources lists active products, categories and published posts, most recently changed first, up to limit in total.
+	Sources(ctx context.Context, pharmacyID uuid.UUID, limit int) ([]*models.SitemapSource, error)
+}
+
+// UploadRepository stores upload records: resumable upload sessions and the files they produced.
+type UploadRepository interface {
+	Create(ctx context.Context, u *models.Upload) error
+	// GetByID returns nil, nil when the upload does not exist.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Upload, error)
+	Update(ctx context.Context, u *models.Upload) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Advance moves a pending upload's received offset from from to to; it reports false when another request
+	// moved it first.
+	Advance(ctx context.Context, id uuid.UUID, from, to int64) (bool, error)
+	// UsageBytes sums the declared size of the pharmacy's pending and completed uploads.
+	UsageBytes(ctx context.Context, pharmacyID uuid.UUID) (int64, error)
+	// ListExpired returns pending uploads whose expiry is before the given time, oldest first.
+	ListExpired(ctx context.Context, before time.Time, limit int) ([]*models.Upload, error)
+}
+
+// FileReferenceRepository is the ledger of stored files.
+type FileReferenceRepository interface {
+	// Record adds a stored file; a URL already in the ledger is overwritten and no longer counts as released.
+	Record(ctx context.Context, f *models.FileReference) error
+	// Release marks the files behind these URLs as released by their owner; URLs not in the ledger are ignored.
+	Release(ctx context.Context, urls []string, at time.Time) error
+	// ListSweepCandidates returns released files and files created before createdBefore, released first, then
+	// the ones checked longest ago.
+	ListSweepCandidates(ctx context.Context, createdBefore time.Time, limit int) ([]*models.FileReference, error)
+	// Referenced returns which of the URLs still appear in any text column of a live row (soft-deleted rows, the
+	// ledger itself, uploads and the activity log do not count).
+	Referenced(ctx context.Context, urls []string) (map[string]bool, error)
+	// MarkChecked records that the files were found still referenced, and clears their release.
+	MarkChecked(ctx context.Context, ids []uuid.UUID, at time.Time) error
+	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 type BlogPostMediaRepository interface {
@@ -363,3 +743,554 @@ type BlogPostViewRepository interface {
 	CountByPostID(ctx context.Context, postID uuid.UUID) (int64, error)
 	CountByPostIDSince(ctx context.Context, postID uuid.UUID, since time.Time) (int64, error)
 }
+
+type AIGenerationRepository interface {
+	// Create saves g unless the pharmacy already has quota generations counted since since (returns false);
+	// quota <= 0 means unlimited.
+	Create(ctx context.Context, g *models.AIGeneration, since time.Time, quota int) (bool, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.AIGeneration, error)
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, kind, status *string, limit, offset int) ([]*models.AIGeneration, int64, error)
+	// CountByPharmacySince counts generations against the quota: every row except failed ones.
+	CountByPharmacySince(ctx context.Context, pharmacyID uuid.UUID, since time.Time) (int64, error)
+	Update(ctx context.Context, g *models.AIGeneration) error
+}
+
+// ReportRepository runs aggregate queries for the reporting module. Ranges are [from, to).
+type ReportRepository interface {
+	// SalesByPeriod groups non-cancelled orders by granularity: "day", "week" or "month". A non-nil branchID
+	// limits both sales reports to orders taken at that branch.
+	SalesByPeriod(ctx context.Context, pharmacyID uuid.UUID, granularity string, from, to time.Time, branchID *uuid.UUID) ([]*models.SalesPeriodRow, error)
+	TopSellingProducts(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time, branchID *uuid.UUID, limit int) ([]*models.TopProductRow, error)
+	RevenueByPaymentMethod(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.PaymentMethodRow, error)
+	LowStock(ctx context.Context, pharmacyID uuid.UUID, threshold int) ([]*models.LowStockRow, error)
+	ExpiringStock(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.ExpiringStockRow, error)
+	// TaxByRate sums order-line VAT of non-cancelled orders grouped by tax class and rate.
+	TaxByRate(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.TaxLine, error)
+	// SalesRegister lists issued invoices and credit notes in the range, oldest first.
+	SalesRegister(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.SalesRegisterRow, error)
+	// DeadStock lists active products in stock that were added before since and have had no non-cancelled sale
+	// since then, worth at least minValue, most valuable first.
+	DeadStock(ctx context.Context, pharmacyID uuid.UUID, since time.Time, minValue float64, limit int) ([]*models.DeadStockRow, error)
+}
+
+type SupplierRepository interface {
+	Create(ctx context.Context, s *models.Supplier) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Supplier, error)
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, activeOnly bool) ([]*models.Supplier, error)
+	Update(ctx context.Context, s *models.Supplier) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type PurchaseOrderRepository interface {
+	// Create inserts the purchase order and its items.
+	Create(ctx context.Context, po *models.PurchaseOrder) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.PurchaseOrder, error)
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, status *models.PurchaseOrderStatus, supplierID *uuid.UUID, limit, offset int) ([]*models.PurchaseOrder, int64, error)
+	Update(ctx context.Context, po *models.PurchaseOrder) error
+}
+
+type FlashSaleRepository interface {
+	// Create inserts the sale and its items.
+	Create(ctx context.Context, f *models.FlashSale) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.FlashSale, error)
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.FlashSale, int64, error)
+	// Update saves sale fields only (items are replaced via ReplaceItems).
+	Update(ctx context.Context, f *models.FlashSale) error
+	ReplaceItems(ctx context.Context, flashSaleID uuid.UUID, items []models.FlashSaleItem) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ListLiveItems returns items of enabled sales running at now, with FlashSale and Product preloaded. Sales
+	// starting by startsBy (at or after now; later for early-access members) count as running.
+	// When productIDs is non-empty only those products are returned.
+	ListLiveItems(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID, now, startsBy time.Time) ([]*models.FlashSaleItem, error)
+	// CountOverlappingItems counts items for productID in other enabled sales overlapping [from, to).
+	CountOverlappingItems(ctx context.Context, pharmacyID, productID uuid.UUID, from, to time.Time, excludeSaleID uuid.UUID) (int64, error)
+	// ReserveItem atomically adds qty to sold_quantity if the cap allows; false when the cap would be exceeded.
+	ReserveItem(ctx context.Context, itemID uuid.UUID, qty int) (bool, error)
+	ReleaseItem(ctx context.Context, itemID uuid.UUID, qty int) error
+	// ReserveCustomerQuantity atomically adds qty to the customer's count for the item if it stays within limit;
+	// false when the limit would be exceeded.
+	ReserveCustomerQuantity(ctx context.Context, itemID uuid.UUID, customerKey string, qty, limit int) (bool, error)
+	ReleaseCustomerQuantity(ctx context.Context, itemID uuid.UUID, customerKey string, qty int) error
+	CreateRedemption(ctx context.Context, r *models.FlashSaleRedemption) error
+	ListRedemptionsByOrder(ctx context.Context, orderID uuid.UUID) ([]*models.FlashSaleRedemption, error)
+	DeleteRedemptionsByOrder(ctx context.Context, orderID uuid.UUID) error
+}
+
+type PreorderRepository interface {
+	Create(ctx context.Context, p *models.Preorder) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Preorder, error)
+	// ListByPharmacy filters by status, product and creator when non-nil; newest first.
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, status *models.PreorderStatus, productID, createdBy *uuid.UUID, limit, offset int) ([]*models.Preorder, int64, error)
+	// ListPendingByProduct returns pending preorders oldest first (conversion order).
+	ListPendingByProduct(ctx context.Context, productID uuid.UUID) ([]*models.Preorder, error)
+	Update(ctx context.Context, p *models.Preorder) error
+}
+
+// TrainingRepository stores SOP documents, training quizzes, quiz attempts and SOP acknowledgements.
+type TrainingRepository interface {
+	CreateSOP(ctx context.Context, d *models.SOPDocument) error
+	GetSOPByID(ctx context.Context, id uuid.UUID) (*models.SOPDocument, error)
+	ListSOPs(ctx context.Context, pharmacyID uuid.UUID, activeOnly bool) ([]*models.SOPDocument, error)
+	UpdateSOP(ctx context.Context, d *models.SOPDocument) error
+	DeleteSOP(ctx context.Context, id uuid.UUID) error
+	// CreateSOPAck is idempotent: acknowledging twice keeps the first acknowledgement.
+	CreateSOPAck(ctx context.Context, a *models.SOPAck) error
+	HasAckedSOP(ctx context.Context, userID, sopID uuid.UUID) (bool, error)
+	ListSOPAcks(ctx context.Context, sopIDs []uuid.UUID) ([]*models.SOPAck, error)
+
+	CreateQuiz(ctx context.Context, q *models.TrainingQuiz) error
+	GetQuizByID(ctx context.Context, id uuid.UUID) (*models.TrainingQuiz, error)
+	// GetActiveQuizFor returns the active quiz attached to the announcement or SOP, or nil when there is none.
+	GetActiveQuizFor(ctx context.Context, announcementID, sopID *uuid.UUID) (*models.TrainingQuiz, error)
+	ListQuizzes(ctx context.Context, pharmacyID uuid.UUID, activeOnly bool) ([]*models.TrainingQuiz, error)
+	UpdateQuiz(ctx context.Context, q *models.TrainingQuiz) error
+	DeleteQuiz(ctx context.Context, id uuid.UUID) error
+
+	CreateAttempt(ctx context.Context, a *models.TrainingAttempt) error
+	HasPassed(ctx context.Context, quizID, userID uuid.UUID) (bool, error)
+	// AttemptStats aggregates attempts per (quiz, user) for the given quizzes.
+	AttemptStats(ctx context.Context, quizIDs []uuid.UUID) ([]*models.TrainingAttemptStat, error)
+}
+
+type OTPRepository interface {
+	Create(ctx context.Context, o *models.OTPCode) error
+	// GetLatest returns the newest unconsumed code for the phone and purpose, or nil when there is none.
+	GetLatest(ctx context.Context, pharmacyID uuid.UUID, phone, purpose string) (*models.OTPCode, error)
+	// CountSince counts codes issued to the phone since the given time (send rate limiting).
+	CountSince(ctx context.Context, pharmacyID uuid.UUID, phone string, since time.Time) (int64, error)
+	Update(ctx context.Context, o *models.OTPCode) error
+}
+
+type PasswordResetTokenRepository interface {
+	Create(ctx context.Context, t *models.PasswordResetToken) error
+	// GetByTokenHash returns nil when no token matches.
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error)
+	// CountSince counts tokens issued to the user since the given time (request rate limiting).
+	CountSince(ctx context.Context, userID uuid.UUID, since time.Time) (int64, error)
+	// Claim marks the token used if it still is unused; false when another reset already claimed it.
+	Claim(ctx context.Context, id uuid.UUID, usedAt time.Time) (bool, error)
+	// MarkAllUsed marks every unused token of the user as used (after a successful reset).
+	MarkAllUsed(ctx context.Context, userID uuid.UUID, usedAt time.Time) error
+}
+
+type PharmacyConfigVersionRepository interface {
+	Create(ctx context.Context, v *models.PharmacyConfigVersion) error
+	// ListByPharmacy returns versions newest first with the total count.
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.PharmacyConfigVersion, int64, error)
+	// GetByVersion returns nil when the version does not exist.
+	GetByVersion(ctx context.Context, pharmacyID uuid.UUID, version int) (*models.PharmacyConfigVersion, error)
+	// LatestVersion returns 0 when the pharmacy has no history yet.
+	LatestVersion(ctx context.Context, pharmacyID uuid.UUID) (int, error)
+}
+
+type RoleRepository interface {
+	Create(ctx context.Context, r *models.Role) error
+	// GetByName returns nil when the pharmacy has no role (or built-in override) with that name.
+	GetByName(ctx context.Context, pharmacyID uuid.UUID, name string) (*models.Role, error)
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.Role, error)
+	Update(ctx context.Context, r *models.Role) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// CartRepository stores shopping carts and their lines.
+type CartRepository interface {
+	Create(ctx context.Context, c *models.Cart) error
+	// GetOpen returns the user's open cart with items and products preloaded; nil, nil when there is none.
+	GetOpen(ctx context.Context, pharmacyID, userID uuid.UUID) (*models.Cart, error)
+	// UpdateCodes saves the promo code, referral code and points to redeem.
+	UpdateCodes(ctx context.Context, c *models.Cart) error
+	// SetItemQuantity inserts the line or replaces its quantity.
+	SetItemQuantity(ctx context.Context, cartID, productID uuid.UUID, quantity int) error
+	DeleteItem(ctx context.Context, cartID, productID uuid.UUID) error
+	DeleteItems(ctx context.Context, cartID uuid.UUID) error
+	// TransitionStatus moves the cart from one status to another only if it is still in from; returns false otherwise.
+	TransitionStatus(ctx context.Context, cartID uuid.UUID, from, to string, orderID *uuid.UUID) (bool, error)
+}
+
+// DeliveryRepository stores order deliveries and their event timeline.
+type DeliveryRepository interface {
+	Create(ctx context.Context, d *models.Delivery) error
+	// GetByOrderID returns the delivery with assignee and events (oldest first); nil, nil when the order has none.
+	GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.Delivery, error)
+	Update(ctx context.Context, d *models.Delivery) error
+	CreateEvent(ctx context.Context, e *models.DeliveryEvent) error
+	// List returns deliveries for a pharmacy, newest first; status and assignedTo filter when set.
+	List(ctx context.Context, pharmacyID uuid.UUID, status *models.DeliveryStatus, assignedTo *uuid.UUID) ([]*models.Delivery, error)
+}
+
+// DispatchBatchRepository stores riders' planned delivery runs.
+type DispatchBatchRepository interface {
+	Create(ctx context.Context, b *models.DispatchBatch) error
+	// GetByID returns the batch with rider and deliveries in stop order; nil, nil when it does not exist.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.DispatchBatch, error)
+	// List returns the pharmacy's batches newest first, only riderID's when set, with the total count.
+	List(ctx context.Context, pharmacyID uuid.UUID, riderID *uuid.UUID, limit, offset int) ([]*models.DispatchBatch, int64, error)
+}
+
+type DeviceTokenRepository interface {
+	// Upsert stores the token for d.UserID, taking it over from another user if needed, and refreshes LastSeenAt.
+	Upsert(ctx context.Context, d *models.DeviceToken) error
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.DeviceToken, error)
+	// Delete removes the user's registration of token; removing an unknown token is not an error.
+	Delete(ctx context.Context, userID uuid.UUID, token string) error
+	// DeleteByToken forgets a token regardless of owner (e.g. reported invalid by the push provider).
+	DeleteByToken(ctx context.Context, token string) error
+}
+
+// OutboxRepository stores domain events for the event bus. Events are normally appended by the repository
+// that saves the originating change, inside its transaction; Append is for changes without such a repository.
+type OutboxRepository interface {
+	Append(ctx context.Context, events ...*models.OutboxEvent) error
+	// ClaimDue marks up to limit due events as running until now+lease and returns them, oldest first. Due
+	// means pending with NextAttemptAt <= now, or running with an expired lease. Concurrent callers, including
+	// other instances, never claim the same event.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.OutboxEvent, error)
+	Update(ctx context.Context, e *models.OutboxEvent) error
+	// DeleteDispatchedBefore removes done events dispatched before cutoff and returns how many were removed.
+	DeleteDispatchedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// IdempotencyKeyRepository stores Idempotency-Key records and the responses they replay.
+type IdempotencyKeyRepository interface {
+	// Create inserts k unless the user already has the key for the scope; it reports whether k was inserted.
+	Create(ctx context.Context, k *models.IdempotencyKey) (bool, error)
+	// Get returns nil, nil when the user has no such key for the scope.
+	Get(ctx context.Context, userID uuid.UUID, scope, key string) (*models.IdempotencyKey, error)
+	Update(ctx context.Context, k *models.IdempotencyKey) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// DeleteExpired removes keys that expired before now and returns how many were removed.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// DeliveryJobRepository stores the durable delivery queue.
+type DeliveryJobRepository interface {
+	Create(ctx context.Context, j *models.DeliveryJob) error
+	// GetByID returns nil, nil when the job does not exist.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.DeliveryJob, error)
+	// ClaimDue marks up to limit due jobs as running until now+lease and returns them. Due means pending with
+	// NextAttemptAt <= now, or running with an expired lease (the worker holding it died). Concurrent callers,
+	// including other instances, never claim the same job.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.DeliveryJob, error)
+	Update(ctx context.Context, j *models.DeliveryJob) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ListDead returns dead jobs of a pharmacy, newest first; kind filters when set.
+	ListDead(ctx context.Context, pharmacyID uuid.UUID, kind string, limit, offset int) ([]*models.DeliveryJob, int64, error)
+}
+
+// GiftCardRepository stores gift cards and their balance movements. Adjust applies t.Amount to the card's
+// balance and records t in one transaction; it reports false, writing nothing, when the balance would go negative.
+type GiftCardRepository interface {
+	Create(ctx context.Context, card *models.GiftCard, issue *models.GiftCardTransaction) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.GiftCard, error)
+	GetByCode(ctx context.Context, pharmacyID uuid.UUID, code string) (*models.GiftCard, error)
+	List(ctx context.Context, pharmacyID uuid.UUID, limit, offset int) ([]*models.GiftCard, int64, error)
+	SetActive(ctx context.Context, id uuid.UUID, active bool) error
+	Adjust(ctx context.Context, t *models.GiftCardTransaction) (bool, error)
+	ListTransactions(ctx context.Context, giftCardID uuid.UUID) ([]*models.GiftCardTransaction, error)
+	ListTransactionsByOrder(ctx context.Context, orderID uuid.UUID) ([]*models.GiftCardTransaction, error)
+}
+
+// StoreCreditRepository is the per-customer store credit ledger. Adjust applies e.Amount to the customer's
+// balance and records e in one transaction; it reports false, writing nothing, when the balance would go negative.
+type StoreCreditRepository interface {
+	Adjust(ctx context.Context, e *models.StoreCreditEntry) (bool, error)
+	ListByCustomer(ctx context.Context, customerID uuid.UUID, limit, offset int) ([]*models.StoreCreditEntry, int64, error)
+	ListByOrder(ctx context.Context, orderID uuid.UUID) ([]*models.StoreCreditEntry, error)
+}
+
+// WalletTopUpRepository stores wallet top-ups. Settle saves t's outcome only while the stored row is still
+// pending, reporting false when another request settled it first.
+type WalletTopUpRepository interface {
+	Create(ctx context.Context, t *models.WalletTopUp) error
+	// GetByID returns the top-up with its gateway; nil, nil when it does not exist.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.WalletTopUp, error)
+	List(ctx context.Context, pharmacyID uuid.UUID, f WalletTopUpFilter, limit, offset int) ([]*models.WalletTopUp, int64, error)
+	Settle(ctx context.Context, t *models.WalletTopUp) (bool, error)
+}
+
+// WalletTopUpFilter narrows WalletTopUpRepository.List; zero values match everything.
+type WalletTopUpFilter struct {
+	CustomerID *uuid.UUID
+	UserID     *uuid.UUID
+	Status     models.WalletTopUpStatus
+}
+
+// ClearanceRepository stores dead-stock clearance actions with their items.
+type ClearanceRepository interface {
+	// Create saves the action and its items.
+	Create(ctx context.Context, a *models.ClearanceAction) error
+	// GetByID returns the action with its items, promo code, supplier and evidence photos; nil, nil when it does
+	// not exist.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ClearanceAction, error)
+	List(ctx context.Context, pharmacyID uuid.UUID, f ClearanceFilter, limit, offset int) ([]*models.ClearanceAction, int64, error)
+	// OpenProductIDs returns which of productIDs are already in one of the pharmacy's open actions.
+	OpenProductIDs(ctx context.Context, pharmacyID uuid.UUID, productIDs []uuid.UUID) ([]uuid.UUID, error)
+	// RecordReturn adds quantity and credit to the item while its returned total stays within its quantity,
+	// reporting false otherwise.
+	RecordReturn(ctx context.Context, itemID uuid.UUID, quantity int, credit float64) (bool, error)
+	// Close saves a's status and closing fields only while the stored action is still open, reporting false when
+	// another request closed it first.
+	Close(ctx context.Context, a *models.ClearanceAction) (bool, error)
+	// PromoRecovery sums, per promo action, its products sold on non-cancelled orders that used its promo code
+	// after the action was created. Actions with no sales have no row.
+	PromoRecovery(ctx context.Context, actionIDs []uuid.UUID) ([]*models.ClearanceRecoveryRow, error)
+}
+
+// ClearanceFilter narrows ClearanceRepository.List; zero values match everything.
+type ClearanceFilter struct {
+	Kind   models.ClearanceKind
+	Status models.ClearanceStatus
+}
+
+// SerialRepository stores serial numbers of serial-tracked products.
+type SerialRepository interface {
+	// CreateBatch saves the serials in one statement.
+	CreateBatch(ctx context.Context, serials []*models.ProductSerial) error
+	// FindByNumbers returns the product's serials among numbers; numbers that are not recorded have no entry.
+	FindByNumbers(ctx context.Context, pharmacyID, productID uuid.UUID, numbers []string) ([]*models.ProductSerial, error)
+	CountInStock(ctx context.Context, pharmacyID, productID uuid.UUID) (int64, error)
+	// List returns serials with their product and order, most recently received first.
+	List(ctx context.Context, pharmacyID uuid.UUID, f SerialFilter, limit, offset int) ([]*models.ProductSerial, int64, error)
+	// MarkSold moves the in-stock serials among numbers to the order item and returns how many moved.
+	MarkSold(ctx context.Context, pharmacyID, productID uuid.UUID, numbers []string, orderID, orderItemID uuid.UUID, at time.Time) (int64, error)
+	// ReleaseOrder puts the serials sold on the order back in stock.
+	ReleaseOrder(ctx context.Context, orderID uuid.UUID) error
+}
+
+// SerialFilter narrows SerialRepository.List; zero values match everything. Query matches part of the serial
+// number, case-insensitively.
+type SerialFilter struct {
+	ProductID *uuid.UUID
+	Status    models.SerialStatus
+	Query     string
+}
+
+// WarrantyRepository stores device warranties and their claims.
+type WarrantyRepository interface {
+	CreateBatch(ctx context.Context, warranties []*models.Warranty) error
+	// GetByID returns the warranty with its product and claims; nil, nil when it does not exist.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Warranty, error)
+	// FindBySerial returns the pharmacy's warranties for the exact serial number with their product and claims,
+	// newest first.
+	FindBySerial(ctx context.Context, pharmacyID uuid.UUID, serialNumber string) ([]*models.Warranty, error)
+	// VoidOrder voids the order's warranties that are not void yet.
+	VoidOrder(ctx context.Context, orderID uuid.UUID, at time.Time) error
+	CreateClaim(ctx context.Context, c *models.WarrantyClaim) error
+	// GetClaim returns the claim with its warranty and product; nil, nil when it does not exist.
+	GetClaim(ctx context.Context, id uuid.UUID) (*models.WarrantyClaim, error)
+	ListClaims(ctx context.Context, pharmacyID uuid.UUID, status models.WarrantyClaimStatus, limit, offset int) ([]*models.WarrantyClaim, int64, error)
+	// UpdateClaim saves c only while the stored claim is still in status from, reporting false when another
+	// request moved it first.
+	UpdateClaim(ctx context.Context, c *models.WarrantyClaim, from models.WarrantyClaimStatus) (bool, error)
+}
+
+// OrganizationRepository stores organizations (pharmacy chains), their members and the roll-up queries that
+// span the organization's tenants.
+type OrganizationRepository interface {
+	// Create inserts the organization, its first owner and links the creating pharmacy to it.
+	Create(ctx context.Context, o *models.Organization, owner *models.OrganizationMember, pharmacyID uuid.UUID) error
+	// GetByID returns the organization with its active and inactive tenants; nil, nil when it does not exist.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Organization, error)
+	GetByCode(ctx context.Context, code string) (*models.Organization, error)
+	GetByJoinCode(ctx context.Context, joinCode string) (*models.Organization, error)
+	// ListByMember returns the organizations the user is a member of, with their tenants.
+	ListByMember(ctx context.Context, userID uuid.UUID) ([]*models.Organization, error)
+	// LinkPharmacy puts the pharmacy in the organization and sets its group code to the organization code.
+	LinkPharmacy(ctx context.Context, pharmacyID, organizationID uuid.UUID, groupCode string) error
+	// UnlinkPharmacy takes the pharmacy out of the organization, clears its group code and removes the
+	// organization's members who are users of that pharmacy.
+	UnlinkPharmacy(ctx context.Context, pharmacyID, organizationID uuid.UUID) error
+	// GetMember returns the user's membership; nil, nil when the user is not a member.
+	GetMember(ctx context.Context, organizationID, userID uuid.UUID) (*models.OrganizationMember, error)
+	// ListMembers returns the members with their users, owners first.
+	ListMembers(ctx context.Context, organizationID uuid.UUID) ([]*models.OrganizationMember, error)
+	AddMember(ctx context.Context, m *models.OrganizationMember) error
+	RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error
+	// SalesByPeriod is ReportRepository.SalesByPeriod summed over the pharmacies.
+	SalesByPeriod(ctx context.Context, pharmacyIDs []uuid.UUID, granularity string, from, to time.Time) ([]*models.SalesPeriodRow, error)
+	// SalesByTenant sums non-cancelled orders per pharmacy, highest revenue first (Share is left to the caller).
+	SalesByTenant(ctx context.Context, pharmacyIDs []uuid.UUID, from, to time.Time) ([]*models.OrgTenantSalesRow, error)
+	// TopProducts ranks products sold across the pharmacies, matched by SKU (or name without one).
+	TopProducts(ctx context.Context, pharmacyIDs []uuid.UUID, from, to time.Time, limit int) ([]*models.OrgTopProductRow, error)
+	// Stock returns sellable batch quantities (not expired by today) of active products per pharmacy and branch,
+	// for the limit products (by SKU or name) holding the most units. Query matches part of the name or SKU.
+	Stock(ctx context.Context, pharmacyIDs []uuid.UUID, query string, today time.Time, limit int) ([]*models.OrgStockRow, error)
+}
+
+// ConsentRepository stores customers' marketing consent records. Records are append-only.
+type ConsentRepository interface {
+	Create(ctx context.Context, r *models.ConsentRecord) error
+	// Current returns the customer's latest record per channel.
+	Current(ctx context.Context, customerID uuid.UUID) ([]*models.ConsentRecord, error)
+	// History returns the customer's records, newest first.
+	History(ctx context.Context, customerID uuid.UUID, limit int) ([]*models.ConsentRecord, error)
+	// OptedIn returns those of customerIDs whose latest record for the channel is an opt-in.
+	OptedIn(ctx context.Context, pharmacyID uuid.UUID, channel models.ConsentChannel, customerIDs []uuid.UUID) ([]uuid.UUID, error)
+	// List returns the pharmacy's records created in [from, to) with their customers, oldest first, for export.
+	List(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.ConsentRecord, error)
+}
+
+// ETARepository reads the order volume and handling times behind order ETAs and stores the estimates.
+type ETARepository interface {
+	// Stats averages handling times of orders placed since since, ignoring outliers over four hours.
+	Stats(ctx context.Context, pharmacyID uuid.UUID, since time.Time) (*models.ETAStats, error)
+	// OpenOrders returns the pharmacy's orders in models.ETAOpenStatuses, oldest first, at most limit.
+	OpenOrders(ctx context.Context, pharmacyID uuid.UUID, limit int) ([]*models.Order, error)
+	// OrderVolume counts orders placed in (at-window, at] and, summed, in the same window on each of the
+	// previous days days.
+	OrderVolume(ctx context.Context, pharmacyID uuid.UUID, at time.Time, window time.Duration, days int) (recent, previous int64, err error)
+	SetEstimates(ctx context.Context, orderID uuid.UUID, readyAt, deliveryAt *time.Time) error
+}
+
+// InventoryEvidenceRepository stores batch write-offs (the stock ledger) and the photos documenting batches.
+type InventoryEvidenceRepository interface {
+	CreateAdjustment(ctx context.Context, a *models.StockAdjustment) error
+	// GetAdjustment returns the write-off; nil, nil when it does not exist.
+	GetAdjustment(ctx context.Context, id uuid.UUID) (*models.StockAdjustment, error)
+	// ListAdjustments returns the batch's write-offs with their photos, newest first.
+	ListAdjustments(ctx context.Context, batchID uuid.UUID) ([]*models.StockAdjustment, error)
+	CreatePhoto(ctx context.Context, p *models.InventoryPhoto) error
+	// ListPhotos returns the batch's photos that do not belong to a write-off, newest first.
+	ListPhotos(ctx context.Context, batchID uuid.UUID) ([]*models.InventoryPhoto, error)
+	// GetPhotos returns the pharmacy's photos among ids; unknown ids are left out.
+	GetPhotos(ctx context.Context, pharmacyID uuid.UUID, ids []uuid.UUID) ([]*models.InventoryPhoto, error)
+	// LinkPhotos attaches the photos to a supplier return (clearance action).
+	LinkPhotos(ctx context.Context, ids []uuid.UUID, actionID uuid.UUID) error
+}
+
+// APIUsageRepository stores per-tenant API request counters by day and route.
+type APIUsageRepository interface {
+	// Add adds each row's counters to the stored row of its pharmacy, day, method and route (creating it), and
+	// raises the stored maximum latency.
+	Add(ctx context.Context, rows []*models.APIUsageDaily) error
+	// List returns the pharmacy's rows for days in [from, to], by day.
+	List(ctx context.Context, pharmacyID uuid.UUID, from, to time.Time) ([]*models.APIUsageDaily, error)
+}
+
+// DrugInfoRepository stores product active ingredients and drug interaction rules.
+type DrugInfoRepository interface {
+	// ReplaceIngredients replaces the product's ingredients with rows.
+	ReplaceIngredients(ctx context.Context, productID uuid.UUID, rows []*models.ProductIngredient) error
+	// ListIngredients returns the ingredients of the given products.
+	ListIngredients(ctx context.Context, productIDs []uuid.UUID) ([]*models.ProductIngredient, error)
+	// CreateInteraction stores the rule, or updates severity and description of the pharmacy's rule for the same pair.
+	CreateInteraction(ctx context.Context, rule *models.DrugInteraction) error
+	ListInteractions(ctx context.Context, pharmacyID uuid.UUID) ([]*models.DrugInteraction, error)
+	// FindInteractions returns the pharmacy's rules whose both ingredients are in ingredients.
+	FindInteractions(ctx context.Context, pharmacyID uuid.UUID, ingredients []string) ([]*models.DrugInteraction, error)
+	// DeleteInteraction reports whether the pharmacy had the rule.
+	DeleteInteraction(ctx context.Context, pharmacyID, id uuid.UUID) (bool, error)
+}
+
+// ExportRequestRepository stores requests to download exports containing personal data.
+type ExportRequestRepository interface {
+	Create(ctx context.Context, r *models.ExportRequest) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ExportRequest, error)
+	// List returns the pharmacy's requests, newest first, preloading the requester; requestedBy and status
+	// filter when set.
+	List(ctx context.Context, pharmacyID uuid.UUID, requestedBy *uuid.UUID, status string, limit int) ([]*models.ExportRequest, error)
+	Update(ctx context.Context, r *models.ExportRequest) error
+	// Consume marks an approved, unexpired request used; false when it was not (already used, expired or
+	// not approved).
+	Consume(ctx context.Context, id uuid.UUID, now time.Time) (bool, error)
+}
+
+// WishlistRepository stores users' favorite products.
+type WishlistRepository interface {
+	// Add stores the item; a product already on the user's wishlist is left as it is.
+	Add(ctx context.Context, w *models.WishlistItem) error
+	// Remove reports whether the product was on the user's wishlist.
+	Remove(ctx context.Context, userID, productID uuid.UUID) (bool, error)
+	// ListByUser returns the user's items at the pharmacy, newest first, with products preloaded.
+	ListByUser(ctx context.Context, pharmacyID, userID uuid.UUID) ([]*models.WishlistItem, error)
+	// ListChanged returns up to limit items whose active product's stock state or price differs from what the user
+	// last saw, with products preloaded.
+	ListChanged(ctx context.Context, limit int) ([]*models.WishlistItem, error)
+	// SetSeen records the stock state and price the user has now seen; notifiedAt is set when they were alerted.
+	SetSeen(ctx context.Context, id uuid.UUID, inStock bool, price float64, notifiedAt *time.Time) error
+}
+
+// StockSubscriptionRepository stores back-in-stock subscriptions.
+type StockSubscriptionRepository interface {
+	Create(ctx context.Context, s *models.StockSubscription) error
+	// FindPending returns the pending subscription for the product matching the user, or else the phone or email
+	// (empty values never match), or nil.
+	FindPending(ctx context.Context, productID uuid.UUID, userID *uuid.UUID, phone, email string) (*models.StockSubscription, error)
+	// ListByProduct returns the product's subscriptions, newest first; status "" means all.
+	ListByProduct(ctx context.Context, pharmacyID, productID uuid.UUID, status string) ([]*models.StockSubscription, error)
+	// ListDue returns up to limit pending subscriptions whose active product is in stock, oldest first, with
+	// products preloaded.
+	ListDue(ctx context.Context, limit int) ([]*models.StockSubscription, error)
+	// MarkNotified moves a pending subscription to notified and reports whether it was still pending.
+	MarkNotified(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+}
+
+// RecommendationRepository stores viewers' recently viewed products and reads the order history behind
+// storefront recommendations.
+type RecommendationRepository interface {
+	// TouchView records that the viewer opened the product now, keeping one row per viewer and product.
+	TouchView(ctx context.Context, v *models.RecentView) error
+	// PruneViews deletes the viewer's views beyond the newest keep.
+	PruneViews(ctx context.Context, viewer string, keep int) error
+	// ListViews returns the viewer's views at the pharmacy, newest first, with active products preloaded; views
+	// of deleted or inactive products are left out.
+	ListViews(ctx context.Context, pharmacyID uuid.UUID, viewer string, limit int) ([]*models.RecentView, error)
+	// DeleteViewsBefore removes views older than before and returns how many were removed.
+	DeleteViewsBefore(ctx context.Context, before time.Time) (int64, error)
+	// BoughtTogether ranks in-stock active products by the number of non-cancelled orders since `since` that
+	// contained them together with any of productIDs; productIDs themselves and exclude are left out.
+	BoughtTogether(ctx context.Context, pharmacyID uuid.UUID, productIDs, exclude []uuid.UUID, since time.Time, limit int) ([]*models.ProductScore, error)
+	// SameCategory returns in-stock active products in the categories, best sellers since `since` first.
+	SameCategory(ctx context.Context, pharmacyID uuid.UUID, categories []string, exclude []uuid.UUID, since time.Time, limit int) ([]*models.Product, error)
+}
+
+// LoginChallengeRepository stores the pending second step of two-factor logins.
+type LoginChallengeRepository interface {
+	Create(ctx context.Context, c *models.LoginChallenge) error
+	// GetByTokenHash returns nil when no challenge matches.
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.LoginChallenge, error)
+	// CountSince counts challenges issued to the user since the given time (code email rate limiting).
+	CountSince(ctx context.Context, userID uuid.UUID, since time.Time) (int64, error)
+	Update(ctx context.Context, c *models.LoginChallenge) error
+}
+
+// ContentReportRepository stores users' reports of reviews, comments and chat messages.
+type ContentReportRepository interface {
+	Create(ctx context.Context, r *models.ContentReport) error
+	// ExistsByReporter reports whether the user already reported the target.
+	ExistsByReporter(ctx context.Context, reporterID uuid.UUID, targetType string, targetID uuid.UUID) (bool, error)
+	// GetByID returns the report with reporter and author; nil, nil when not found.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ContentReport, error)
+	// ListByPharmacy returns the pharmacy's reports, newest first, with reporter and author.
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID, filter ContentReportFilter, limit, offset int) ([]*models.ContentReport, int64, error)
+	// ResolveTarget resolves every open report on the target with the action and returns how many it resolved.
+	ResolveTarget(ctx context.Context, pharmacyID uuid.UUID, targetType string, targetID uuid.UUID, action, note string, resolvedBy uuid.UUID, at time.Time) (int64, error)
+}
+
+// ContentReportFilter narrows ContentReportRepository.ListByPharmacy; zero values match everything.
+type ContentReportFilter struct {
+	Status     string
+	TargetType string
+	AuthorID   *uuid.UUID
+}
+
+// SubscriptionRepository stores pharmacies' subscription plans.
+type SubscriptionRepository interface {
+	// GetByPharmacyID returns nil, nil when the pharmacy has no subscription.
+	GetByPharmacyID(ctx context.Context, pharmacyID uuid.UUID) (*models.Subscription, error)
+	// Save creates or updates the pharmacy's subscription.
+	Save(ctx context.Context, s *models.Subscription) error
+	// Usage counts the pharmacy's products, active staff accounts and upload bytes.
+	Usage(ctx context.Context, pharmacyID uuid.UUID) (*models.SubscriptionUsage, error)
+}
+
+// ExchangeRateRepository stores pharmacies' exchange rates from their base currency.
+type ExchangeRateRepository interface {
+	// ListByPharmacy returns the pharmacy's rates ordered by currency.
+	ListByPharmacy(ctx context.Context, pharmacyID uuid.UUID) ([]*models.ExchangeRate, error)
+	// Get returns nil, nil when the pharmacy has no rate for the currency.
+	Get(ctx context.Context, pharmacyID uuid.UUID, currency string) (*models.ExchangeRate, error)
+	// Save creates or updates a rate.
+	Save(ctx context.Context, r *models.ExchangeRate) error
+	Delete(ctx context.Context, pharmacyID uuid.UUID, currency string) error
+}
@@ -0,0 +1,20 @@
+package outbound
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// SMSMessage is a single outgoing text message. To is a phone number as entered by the customer;
+// adapters normalise it for their gateway. PharmacyID (when known) scopes it in the delivery queue.
+type SMSMessage struct {
+	PharmacyID uuid.UUID
+	To         string
+	Body       string
+}
+
+// SMSSender delivers SMS. Implementations: log-only (development), Twilio, Sparrow SMS.
+type SMSSender interface {
+	Send(ctx context.Context, msg *SMSMessage) error
+}
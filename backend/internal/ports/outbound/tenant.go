@@ -0,0 +1,31 @@
+package outbound
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrCrossTenant is returned by repositories asked, under a tenant scope, to write a row of another pharmacy.
+var ErrCrossTenant = errors.New("record belongs to another pharmacy")
+
+type tenantKey struct{}
+
+// WithTenant scopes ctx to the pharmacy: repositories called with it only read, update and delete that pharmacy's
+// rows, and refuse to create or save rows of another. The auth middleware scopes every signed-in request.
+func WithTenant(ctx context.Context, pharmacyID uuid.UUID) context.Context {
+	return context.WithValue(ctx, tenantKey{}, pharmacyID)
+}
+
+// WithoutTenant lifts the scope, for flows that work across pharmacies by design (platform admins, organizations,
+// stock transfers) and check access themselves.
+func WithoutTenant(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantKey{}, uuid.Nil)
+}
+
+// TenantFrom returns the pharmacy ctx is scoped to; false when it is not scoped (jobs, public routes).
+func TenantFrom(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(tenantKey{}).(uuid.UUID)
+	return id, ok && id != uuid.Nil
+}
@@ -0,0 +1,48 @@
+package outbound
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// TicketRequest describes an escalated chat conversation to open as an external ticket.
+type TicketRequest struct {
+	ConversationID  uuid.UUID
+	PharmacyID      uuid.UUID
+	PharmacyName    string
+	Subject         string
+	Description     string // reason plus a plain-text transcript of recent messages
+	Reason          string
+	RequesterName   string
+	RequesterPhone  string
+	RequesterEmail  string
+	EscalatedBy     string
+	ConversationURL string
+}
+
+// TicketRef identifies a ticket in the external system.
+type TicketRef struct {
+	ID     string
+	URL    string
+	Status string
+}
+
+// TicketUpdate is a status change or public comment reported by the external system's webhook.
+// Resolved is true when the status means the issue is done (resolved, closed).
+type TicketUpdate struct {
+	TicketID string
+	Status   string
+	Comment  string
+	Resolved bool
+}
+
+// TicketingProvider creates tickets in an external system and parses its callbacks.
+// Implementations: generic webhook, Jira, Freshdesk.
+type TicketingProvider interface {
+	// Name is stored on the conversation with the ticket id ("webhook", "jira", "freshdesk").
+	Name() string
+	CreateTicket(ctx context.Context, req *TicketRequest) (*TicketRef, error)
+	// ParseWebhook decodes a callback body; it returns nil, nil for events that carry no update.
+	ParseWebhook(body []byte) (*TicketUpdate, error)
+}
@@ -0,0 +1,10 @@
+package outbound
+
+import "context"
+
+// UnitOfWork runs a group of repository writes atomically. Repositories called with the ctx passed to fn
+// join the transaction; fn returning an error (or panicking) rolls everything back.
+type UnitOfWork interface {
+	// Do runs fn in a transaction. A nested Do on a ctx that is already in a transaction joins it.
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,23 @@
+package outbound
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// WebhookMessage is an outgoing event POSTed as {"id", "event", "data"} to URL. ID stays the same across
+// retries so receivers can drop duplicates.
+type WebhookMessage struct {
+	ID         uuid.UUID       `json:"id"`
+	PharmacyID uuid.UUID       `json:"pharmacy_id"`
+	URL        string          `json:"url"`
+	Event      string          `json:"event"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// WebhookSender delivers webhook events. A non-2xx answer is an error so queued deliveries are retried.
+type WebhookSender interface {
+	Send(ctx context.Context, msg *WebhookMessage) error
+}
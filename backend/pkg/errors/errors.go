@@ -14,6 +14,11 @@ const (
 	ErrCodeConflict           = "CONFLICT"
 	ErrCodeBadRequest         = "BAD_REQUEST"
 	ErrCodeInvalidCredentials = "INVALID_CREDENTIALS"
+	ErrCodeTooManyRequests    = "TOO_MANY_REQUESTS"
+	ErrCodeTwoFactorRequired  = "TWO_FACTOR_REQUIRED" // password accepted; Details carry the challenge to answer with the emailed code
+	ErrCodePasswordExpired    = "PASSWORD_EXPIRED"    // the security policy requires a new password before signing in
+	ErrCodePaymentRequired    = "PAYMENT_REQUIRED"    // the pharmacy's subscription ran out past its grace period
+	ErrCodePlanLimit          = "PLAN_LIMIT"          // the pharmacy's plan does not allow more of something, or a feature
 )
 
 type AppError struct {
@@ -47,6 +52,9 @@ func ErrForbidden(message string) *AppError { return New(ErrCodeForbidden, messa
 func ErrConflict(message string) *AppError   { return New(ErrCodeConflict, message) }
 func ErrInternal(message string, err error) *AppError { return Wrap(err, ErrCodeInternal, message) }
 func ErrInvalidCredentials() *AppError { return New(ErrCodeInvalidCredentials, "Invalid email or password") }
+func ErrTooManyRequests(message string) *AppError { return New(ErrCodeTooManyRequests, message) }
+func ErrPaymentRequired(message string) *AppError { return New(ErrCodePaymentRequired, message) }
+func ErrPlanLimit(message string) *AppError       { return New(ErrCodePlanLimit, message) }
 
 func IsAppError(err error) bool {
 	var appErr *AppError
@@ -0,0 +1,142 @@
+// Package i18n translates the API's English error and validation messages. Messages are looked up whole, and
+// patterns cover those built around a value ("product not found", "must be at least 3"). A message without a
+// translation stays in English.
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage is the language messages are written in.
+const DefaultLanguage = "en"
+
+// messages holds whole-message translations per language.
+var messages = map[string]map[string]string{
+	"ne": {
+		"unauthorized":                             "अनधिकृत",
+		"authentication required":                  "लगइन आवश्यक छ",
+		"Missing authorization header":             "प्रमाणीकरण हेडर छैन",
+		"Invalid authorization header":             "प्रमाणीकरण हेडर अमान्य छ",
+		"Invalid or expired token":                 "टोकन अमान्य छ वा यसको म्याद सकियो",
+		"User not found or inactive":               "प्रयोगकर्ता फेला परेन वा निष्क्रिय छ",
+		"Invalid email or password":                "इमेल वा पासवर्ड मिलेन",
+		"Login failed":                             "लगइन असफल भयो",
+		"account is inactive":                      "खाता निष्क्रिय छ",
+		"email already registered":                 "यो इमेल पहिले नै दर्ता भइसकेको छ",
+		"platform admin only":                      "प्लेटफर्म एडमिनका लागि मात्र",
+		"pharmacy_id not set":                      "फार्मेसी पहिचान हुन सकेन",
+		"user_id not set":                          "प्रयोगकर्ता पहिचान हुन सकेन",
+		"invalid context":                          "अनुरोध अमान्य छ",
+		"Invalid input":                            "इनपुट अमान्य छ",
+		"too many requests, try again later":       "धेरै अनुरोध भए, केही बेरपछि फेरि प्रयास गर्नुहोस्",
+		"product does not belong to your pharmacy": "यो उत्पादन तपाईंको फार्मेसीको होइन",
+		"you can only view your own orders":        "तपाईं आफ्नै अर्डर मात्र हेर्न सक्नुहुन्छ",
+		"quantity must be positive":                "परिमाण शून्यभन्दा बढी हुनुपर्छ",
+		"item quantity must be positive":           "सामानको परिमाण शून्यभन्दा बढी हुनुपर्छ",
+		"amount must be positive":                  "रकम शून्यभन्दा बढी हुनुपर्छ",
+		"at least one item is required":            "कम्तीमा एउटा सामान चाहिन्छ",
+		"code is invalid or has expired":           "कोड अमान्य छ वा यसको म्याद सकियो",
+		"reset link is invalid or has expired":     "रिसेट लिङ्क अमान्य छ वा यसको म्याद सकियो",
+		"rating must be 1-5":                       "रेटिङ १ देखि ५ सम्म हुनुपर्छ",
+		"from must be before to":                   "सुरु मिति अन्तिम मितिभन्दा अघि हुनुपर्छ",
+		"to must not be before from":               "अन्तिम मिति सुरु मितिभन्दा अघि हुनु हुँदैन",
+		"missing file in form":                     "फारममा फाइल छैन",
+		"failed to read file":                      "फाइल पढ्न सकिएन",
+		"file too large (max 10MB)":                "फाइल धेरै ठूलो छ (बढीमा १० MB)",
+		"is required":                              "आवश्यक छ",
+		"must be a valid email":                    "मान्य इमेल हुनुपर्छ",
+		"must be zero or greater":                  "शून्य वा बढी हुनुपर्छ",
+		"invalid value":                            "मान अमान्य छ",
+		"must differ from the base currency":       "आधार मुद्राभन्दा फरक हुनुपर्छ",
+	},
+}
+
+// patterns holds translations of messages built around one value, marked %s. The value is translated too when
+// it is one of nouns.
+var patterns = map[string][][2]string{
+	"ne": {
+		{"%s not found", "%s फेला परेन"},
+		{"invalid %s", "%s अमान्य छ"},
+		{"%s is required", "%s आवश्यक छ"},
+		{"missing permission %s", "%s अनुमति छैन"},
+		{"must be at least %s", "कम्तीमा %s हुनुपर्छ"},
+		{"must be at most %s", "बढीमा %s हुनुपर्छ"},
+		{"must be exactly %s characters", "ठीक %s अक्षरको हुनुपर्छ"},
+		{"unsupported language %s", "%s भाषा समर्थित छैन"},
+		{"translation %s needs a title", "%s अनुवादमा शीर्षक चाहिन्छ"},
+		{"unsupported currency %s", "%s मुद्रा समर्थित छैन"},
+		{"no exchange rate for %s", "%s को विनिमय दर छैन"},
+	},
+}
+
+// nouns translates the values patterns are built around.
+var nouns = map[string]map[string]string{
+	"ne": {
+		"id": "आईडी", "pharmacy id": "फार्मेसी आईडी", "product id": "उत्पादन आईडी", "order id": "अर्डर आईडी",
+		"user id": "प्रयोगकर्ता आईडी", "customer id": "ग्राहक आईडी", "status": "स्थिति",
+		"product": "उत्पादन", "product image": "उत्पादनको तस्बिर", "category": "श्रेणी", "user": "प्रयोगकर्ता",
+		"order": "अर्डर", "pharmacy": "फार्मेसी", "customer": "ग्राहक", "conversation": "कुराकानी",
+		"message": "सन्देश", "review": "समीक्षा", "comment": "टिप्पणी", "promo": "प्रोमो", "post": "पोस्ट",
+		"announcement": "सूचना", "address": "ठेगाना", "invoice": "बिल", "supplier": "आपूर्तिकर्ता",
+		"purchase order": "खरिद अर्डर", "inventory batch": "स्टक ब्याच", "gift card": "गिफ्ट कार्ड",
+		"payment gateway": "भुक्तानी गेटवे", "organization": "संस्था", "file": "फाइल", "duty roster": "ड्युटी रोस्टर",
+		"daily log": "दैनिक लग", "training quiz": "तालिम क्विज", "name": "नाम", "title": "शीर्षक",
+		"reason": "कारण", "email": "इमेल", "password": "पासवर्ड", "exchange rate": "विनिमय दर",
+	},
+}
+
+// Translate returns msg in lang, or msg itself when lang is the default or has no translation for it.
+func Translate(lang, msg string) string {
+	if lang == "" || lang == DefaultLanguage || msg == "" {
+		return msg
+	}
+	if t, ok := messages[lang][msg]; ok {
+		return t
+	}
+	for _, p := range patterns[lang] {
+		prefix, suffix, _ := strings.Cut(p[0], "%s")
+		if len(msg) <= len(prefix)+len(suffix) || !strings.HasPrefix(msg, prefix) || !strings.HasSuffix(msg, suffix) {
+			continue
+		}
+		value := msg[len(prefix) : len(msg)-len(suffix)]
+		if t, ok := nouns[lang][value]; ok {
+			value = t
+		}
+		return strings.Replace(p[1], "%s", value, 1)
+	}
+	return msg
+}
+
+// ParseAcceptLanguage returns the primary language codes of an Accept-Language header ("ne-NP;q=0.9, en;q=0.8"),
+// most preferred first. Wildcards and languages with q=0 are left out.
+func ParseAcceptLanguage(header string) []string {
+	type lang struct {
+		code string
+		q    float64
+	}
+	var langs []lang
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		code, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if code == "" || code == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			langs = append(langs, lang{code, q})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+	out := make([]string, len(langs))
+	for i, l := range langs {
+		out[i] = l.code
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package i18n
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTranslate(t *testing.T) {
+	cases := []struct {
+		lang, msg, want string
+	}{
+		{"ne", "unauthorized", "अनधिकृत"},
+		{"ne", "product not found", "उत्पादन फेला परेन"},
+		{"ne", "invalid pharmacy id", "फार्मेसी आईडी अमान्य छ"},
+		{"ne", "must be at least 8", "कम्तीमा 8 हुनुपर्छ"},
+		{"ne", "widget not found", "widget फेला परेन"},
+		{"ne", "the basic plan allows 5 users", "the basic plan allows 5 users"},
+		{"ne", " not found", " not found"},
+		{"en", "product not found", "product not found"},
+		{"fr", "product not found", "product not found"},
+		{"", "unauthorized", "unauthorized"},
+	}
+	for _, tc := range cases {
+		if got := Translate(tc.lang, tc.msg); got != tc.want {
+			t.Errorf("Translate(%q, %q) = %q, want %q", tc.lang, tc.msg, got, tc.want)
+		}
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	cases := map[string][]string{
+		"":                               {},
+		"ne":                             {"ne"},
+		"en-US,en;q=0.9,ne-NP;q=0.95":    {"en", "ne", "en"},
+		"fr;q=0.5, ne-NP , *;q=0.1":      {"ne", "fr"},
+		"de;q=0, ne;q=0.3, en;q=garbage": {"en", "ne"},
+	}
+	for header, want := range cases {
+		if got := ParseAcceptLanguage(header); !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseAcceptLanguage(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Package imaging decodes uploaded images and produces fixed-size renditions (banners, thumbnails)
+// using only the standard library, so no image toolchain is needed on the server.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+
+	_ "image/gif" // register decoders
+	_ "image/png"
+)
+
+// MaxPixels bounds decoded images (about 40 megapixels) so a small compressed file cannot exhaust memory.
+const MaxPixels = 40_000_000
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format (use JPEG, PNG or GIF)")
+	ErrTooLarge          = errors.New("image dimensions too large")
+)
+
+// Decode reads a JPEG, PNG or GIF image and returns it with its format name.
+func Decode(r io.Reader) (image.Image, string, error) {
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		return nil, "", err
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return nil, "", ErrUnsupportedFormat
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, "", ErrTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return nil, "", ErrUnsupportedFormat
+	}
+	return img, format, nil
+}
+
+// Cover scales img to fill exactly width x height, cropping the overflow evenly from both sides
+// (like CSS object-fit: cover). Transparent areas are flattened onto white.
+func Cover(img image.Image, width, height int) *image.RGBA {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	// Crop the source to the target aspect ratio first.
+	crop := b
+	if sw*height > sh*width {
+		cw := sh * width / height
+		crop.Min.X += (sw - cw) / 2
+		crop.Max.X = crop.Min.X + cw
+	} else {
+		ch := sw * height / width
+		crop.Min.Y += (sh - ch) / 2
+		crop.Max.Y = crop.Min.Y + ch
+	}
+	src := image.NewRGBA(image.Rect(0, 0, crop.Dx(), crop.Dy()))
+	draw.Draw(src, src.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(src, src.Bounds(), img, crop.Min, draw.Over)
+	return resize(src, width, height)
+}
+
+// Fit scales img down to fit within maxWidth x maxHeight, keeping its aspect ratio; smaller images keep their
+// size. Transparent areas are flattened onto white.
+func Fit(img image.Image, maxWidth, maxHeight int) *image.RGBA {
+	b := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Over)
+	w, h := b.Dx(), b.Dy()
+	if w <= maxWidth && h <= maxHeight {
+		return src
+	}
+	if w*maxHeight > h*maxWidth {
+		w, h = maxWidth, max(h*maxWidth/w, 1)
+	} else {
+		w, h = max(w*maxHeight/h, 1), maxHeight
+	}
+	return resize(src, w, h)
+}
+
+// resize scales src to width x height by averaging the source pixels under each target pixel (box filter),
+// which keeps downscaled photos free of aliasing; upscaling repeats the nearest pixel.
+func resize(src *image.RGBA, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	for y := 0; y < height; y++ {
+		y0 := y * sh / height
+		y1 := max((y+1)*sh/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := x * sw / width
+			x1 := max((x+1)*sw/width, x0+1)
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint32(p[0])
+					g += uint32(p[1])
+					b += uint32(p[2])
+					a += uint32(p[3])
+					n++
+				}
+			}
+			o := dst.PixOffset(x, y)
+			dst.Pix[o] = uint8(r / n)
+			dst.Pix[o+1] = uint8(g / n)
+			dst.Pix[o+2] = uint8(b / n)
+			dst.Pix[o+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// Shrink decodes data and re-encodes it as a JPEG that fits within size x size at the given quality, for
+// low-bandwidth clients.
+func Shrink(data []byte, size, quality int) ([]byte, error) {
+	img, _, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return EncodeJPEG(Fit(img, size, size), quality)
+}
+
+// EncodeJPEG encodes img as a JPEG at the given quality (1-100).
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,91 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestDecode_RejectsNonImage(t *testing.T) {
+	if _, _, err := Decode(bytes.NewReader([]byte("not an image"))); err != ErrUnsupportedFormat {
+		t.Fatalf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestCover_CropsToTargetAspect(t *testing.T) {
+	// 300x100: red left third, green middle, blue right third.
+	src := image.NewRGBA(image.Rect(0, 0, 300, 100))
+	for x := 0; x < 300; x++ {
+		c := color.RGBA{255, 0, 0, 255}
+		if x >= 100 && x < 200 {
+			c = color.RGBA{0, 255, 0, 255}
+		} else if x >= 200 {
+			c = color.RGBA{0, 0, 255, 255}
+		}
+		for y := 0; y < 100; y++ {
+			src.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+	img, format, err := Decode(&buf)
+	if err != nil || format != "png" {
+		t.Fatalf("Decode: %v %s", err, format)
+	}
+	out := Cover(img, 50, 50) // square crop keeps only the green middle
+	if out.Bounds().Dx() != 50 || out.Bounds().Dy() != 50 {
+		t.Fatalf("unexpected size %v", out.Bounds())
+	}
+	if got := out.RGBAAt(25, 25); got != (color.RGBA{0, 255, 0, 255}) {
+		t.Errorf("expected centre crop to be green, got %v", got)
+	}
+	if _, err := EncodeJPEG(out, 85); err != nil {
+		t.Errorf("EncodeJPEG: %v", err)
+	}
+}
+
+func TestFit_KeepsAspectAndSmallImages(t *testing.T) {
+	out := Fit(image.NewRGBA(image.Rect(0, 0, 1000, 500)), 320, 320)
+	if out.Bounds().Dx() != 320 || out.Bounds().Dy() != 160 {
+		t.Errorf("expected 320x160, got %v", out.Bounds())
+	}
+	small := Fit(image.NewRGBA(image.Rect(0, 0, 100, 200)), 320, 320)
+	if small.Bounds().Dx() != 100 || small.Bounds().Dy() != 200 {
+		t.Errorf("expected small image to keep 100x200, got %v", small.Bounds())
+	}
+}
+
+func TestStripMetadata_RemovesEXIFAndKeepsImage(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	plain, err := EncodeJPEG(src, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Big-endian TIFF block with one IFD entry: orientation (0x0112) = 6, rotate 90° clockwise.
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01\x01\x12\x00\x03\x00\x00\x00\x01\x00\x06\x00\x00\x00\x00\x00\x00")
+	exif := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := append([]byte{0xFF, 0xE1, 0, byte(len(exif) + 2)}, exif...)
+	tagged := append(append(append([]byte{}, plain[:2]...), app1...), plain[2:]...)
+
+	if got := Orientation(tagged); got != 6 {
+		t.Fatalf("Orientation = %d, want 6", got)
+	}
+	stripped := StripMetadata(tagged)
+	if bytes.Contains(stripped, []byte("Exif")) || len(stripped) != len(plain) {
+		t.Errorf("EXIF not stripped: %d bytes, want %d", len(stripped), len(plain))
+	}
+	if Orientation(stripped) != 1 {
+		t.Error("stripped image still reports an orientation")
+	}
+	img, _, err := Decode(bytes.NewReader(stripped))
+	if err != nil {
+		t.Fatalf("Decode stripped: %v", err)
+	}
+	if b := Orient(img, 6).Bounds(); b.Dx() != 20 || b.Dy() != 40 {
+		t.Errorf("oriented size = %v, want 20x40", b)
+	}
+}
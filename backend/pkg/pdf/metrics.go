@@ -0,0 +1,71 @@
+package pdf
+
+import "strings"
+
+// Glyph widths (1/1000 em) of Helvetica and Helvetica-Bold for ' ' through '~', from the standard AFM files.
+// Other characters are measured as a digit.
+var widths = [2][95]int{
+	{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+// TextWidth is the width of s in points at the given size.
+func TextWidth(f Font, size float64, s string) float64 {
+	w := 0
+	for _, c := range encode(s) {
+		if c >= 0x20 && c < 0x7f {
+			w += widths[f][c-0x20]
+		} else {
+			w += 556
+		}
+	}
+	return float64(w) * size / 1000
+}
+
+// Wrap breaks s into lines no wider than width, keeping explicit line breaks. A word longer than a line
+// is split.
+func Wrap(f Font, size, width float64, s string) []string {
+	var lines []string
+	for _, para := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			for TextWidth(f, size, word) > width {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				n := len([]rune(word)) - 1
+				for n > 1 && TextWidth(f, size, string([]rune(word)[:n])) > width {
+					n--
+				}
+				lines = append(lines, string([]rune(word)[:n]))
+				word = string([]rune(word)[n:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case TextWidth(f, size, line+" "+word) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,285 @@
+// Package pdf writes simple printable documents (headings, wrapped text, tables) as PDF using only the
+// standard library. Text is set in the built-in Helvetica fonts, so no font files are embedded; characters
+// outside Windows-1252 (e.g. Devanagari) are printed as "?".
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A4 portrait in points, with the margin used by the layout helpers.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+	Margin     = 40.0
+)
+
+// Font is one of the two built-in fonts.
+type Font int
+
+const (
+	Regular Font = iota
+	Bold
+)
+
+// Color is an RGB color with components in [0, 1].
+type Color struct{ R, G, B float64 }
+
+var (
+	Black     = Color{0, 0, 0}
+	White     = Color{1, 1, 1}
+	Gray      = Color{0.45, 0.45, 0.45}
+	LightGray = Color{0.92, 0.92, 0.92}
+)
+
+// ParseHexColor reads "#rrggbb" or "rrggbb".
+func ParseHexColor(s string) (Color, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) != 6 {
+		return Color{}, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return Color{}, false
+	}
+	return Color{float64(v>>16&0xff) / 255, float64(v>>8&0xff) / 255, float64(v&0xff) / 255}, true
+}
+
+// Document is a PDF being written page by page. Coordinates passed to its methods are in points from the
+// top-left corner of the page. Cursor-based helpers (Heading, Paragraph, Table) flow down the page and
+// start a new one when the content does not fit.
+type Document struct {
+	title  string
+	pages  []*bytes.Buffer
+	page   *bytes.Buffer
+	y      float64
+	onPage func(d *Document)
+	inHook bool
+}
+
+// New starts an empty document. onPage, when set, draws the header of every page and leaves the cursor
+// below it.
+func New(title string, onPage func(d *Document)) *Document {
+	d := &Document{title: title, onPage: onPage}
+	d.AddPage()
+	return d
+}
+
+// AddPage starts a new page and runs the page hook.
+func (d *Document) AddPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+	d.y = Margin
+	if d.onPage != nil && !d.inHook {
+		d.inHook = true
+		d.onPage(d)
+		d.inHook = false
+	}
+}
+
+// PageCount is the number of pages so far.
+func (d *Document) PageCount() int { return len(d.pages) }
+
+// Y is the cursor position from the top of the page.
+func (d *Document) Y() float64 { return d.y }
+
+// SetY moves the cursor.
+func (d *Document) SetY(y float64) { d.y = y }
+
+// Ensure starts a new page unless h points still fit above the bottom margin.
+func (d *Document) Ensure(h float64) {
+	if d.y+h > PageHeight-Margin && d.y > Margin {
+		d.AddPage()
+	}
+}
+
+// Rect fills a rectangle.
+func (d *Document) Rect(x, y, w, h float64, fill Color) {
+	fmt.Fprintf(d.page, "%s rg %s %s %s %s re f\n", rgb(fill), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// Line strokes a line of the given width.
+func (d *Document) Line(x1, y1, x2, y2, width float64, c Color) {
+	fmt.Fprintf(d.page, "%s RG %s w %s %s m %s %s l S\n", rgb(c), num(width), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// Text writes s with its baseline at y.
+func (d *Document) Text(x, y float64, f Font, size float64, c Color, s string) {
+	fmt.Fprintf(d.page, "BT /F%d %s Tf %s rg %s %s Td (%s) Tj ET\n", f+1, num(size), rgb(c), num(x), num(PageHeight-y), escape(s))
+}
+
+// Heading writes a bold line at the cursor and moves below it.
+func (d *Document) Heading(s string, size float64, c Color) {
+	d.Ensure(size * 1.6)
+	d.Text(Margin, d.y+size, Bold, size, c, s)
+	d.y += size * 1.6
+}
+
+// Paragraph writes s wrapped to the page width at the cursor and moves below it.
+func (d *Document) Paragraph(s string, f Font, size float64, c Color) {
+	lead := size * 1.35
+	for _, line := range Wrap(f, size, PageWidth-2*Margin, s) {
+		d.Ensure(lead)
+		d.Text(Margin, d.y+size, f, size, c, line)
+		d.y += lead
+	}
+}
+
+// Space moves the cursor down.
+func (d *Document) Space(h float64) { d.y += h }
+
+// Column is a table column; Width is a share of the page width (shares are normalized).
+type Column struct {
+	Title string
+	Width float64
+}
+
+// Table draws a table at the cursor with a shaded header row, wrapping cell text. Rows that do not fit
+// continue on a new page, with the header repeated.
+func (d *Document) Table(cols []Column, rows [][]string, header Color) {
+	const size, pad = 9.0, 4.0
+	lead := size * 1.3
+	total := 0.0
+	for _, c := range cols {
+		total += c.Width
+	}
+	widths := make([]float64, len(cols))
+	for i, c := range cols {
+		widths[i] = (PageWidth - 2*Margin) * c.Width / total
+	}
+	drawHeader := func() {
+		h := lead + 2*pad
+		d.Ensure(h + lead + 2*pad)
+		d.Rect(Margin, d.y, PageWidth-2*Margin, h, header)
+		x := Margin
+		for i, c := range cols {
+			d.Text(x+pad, d.y+pad+size, Bold, size, White, c.Title)
+			x += widths[i]
+		}
+		d.y += h
+	}
+	drawHeader()
+	for _, row := range rows {
+		cells := make([][]string, len(cols))
+		lines := 1
+		for i := range cols {
+			v := ""
+			if i < len(row) {
+				v = row[i]
+			}
+			cells[i] = Wrap(Regular, size, widths[i]-2*pad, v)
+			if len(cells[i]) > lines {
+				lines = len(cells[i])
+			}
+		}
+		h := float64(lines)*lead + 2*pad
+		if d.y+h > PageHeight-Margin {
+			d.AddPage()
+			drawHeader()
+		}
+		x := Margin
+		for i := range cols {
+			for j, line := range cells[i] {
+				d.Text(x+pad, d.y+pad+size+float64(j)*lead, Regular, size, Black, line)
+			}
+			x += widths[i]
+		}
+		d.y += h
+		d.Line(Margin, d.y, PageWidth-Margin, d.y, 0.5, LightGray)
+	}
+}
+
+// Footer writes s and "Page n of m" at the bottom of every page. Call it once, after the content.
+func (d *Document) Footer(s string) {
+	for i, p := range d.pages {
+		y := PageHeight - Margin/2
+		fmt.Fprintf(p, "BT /F1 8 Tf %s rg %s %s Td (%s) Tj ET\n", rgb(Gray), num(Margin), num(PageHeight-y), escape(s))
+		label := fmt.Sprintf("Page %d of %d", i+1, len(d.pages))
+		x := PageWidth - Margin - TextWidth(Regular, 8, label)
+		fmt.Fprintf(p, "BT /F1 8 Tf %s rg %s %s Td (%s) Tj ET\n", rgb(Gray), num(x), num(PageHeight-y), escape(label))
+	}
+}
+
+// Bytes assembles the PDF file.
+func (d *Document) Bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	// 1 catalog, 2 page tree, 3-4 fonts, 5 info, then a page and a content stream per page.
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	obj(fmt.Sprintf("<< /Title (%s) /Producer (CarePlus) >>", escape(d.title)))
+	for i, p := range d.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			num(PageWidth), num(PageHeight), 7+2*i))
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		zw.Write(p.Bytes())
+		zw.Close()
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", len(offsets), z.Len())
+		out.Write(z.Bytes())
+		out.WriteString("\nendstream\nendobj\n")
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, o := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", o)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+func num(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+
+func rgb(c Color) string { return num(c.R) + " " + num(c.G) + " " + num(c.B) }
+
+// winAnsi maps the non-Latin-1 characters of Windows-1252 that documents commonly contain.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+}
+
+// encode converts s to Windows-1252, replacing what it cannot represent with '?'.
+func encode(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			out = append(out, ' ')
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			out = append(out, byte(r))
+		default:
+			if b, ok := winAnsi[r]; ok {
+				out = append(out, b)
+			} else if r >= 0x20 {
+				out = append(out, '?')
+			}
+		}
+	}
+	return out
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	for _, c := range encode(s) {
+		if c == '\\' || c == '(' || c == ')' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
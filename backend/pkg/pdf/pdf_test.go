@@ -0,0 +1,75 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	lines := Wrap(Regular, 10, 60, "Count the controlled drugs\nsupercalifragilistic")
+	for _, l := range lines {
+		if w := TextWidth(Regular, 10, l); w > 60 {
+			t.Errorf("line %q is %.1fpt wide, want at most 60", l, w)
+		}
+	}
+	if got := strings.Join(lines, "|"); !strings.HasPrefix(got, "Count the|controlled|drugs|") {
+		t.Errorf("lines = %q", got)
+	}
+}
+
+func TestDocument_TableFlowsOntoNewPagesWithValidXref(t *testing.T) {
+	headers := 0
+	d := New("Roster (test)", func(d *Document) {
+		headers++
+		d.Heading("Duty roster", 16, Black)
+	})
+	rows := make([][]string, 120)
+	for i := range rows {
+		rows[i] = []string{fmt.Sprint(i), "Morning", "Sita Sharma – pharmacist", "Opens the store"}
+	}
+	d.Table([]Column{{"#", 1}, {"Shift", 1}, {"Staff", 2}, {"Notes", 3}}, rows, Color{0, 0.4, 0.4})
+	d.Footer("Printed today")
+	out := d.Bytes()
+
+	if d.PageCount() < 2 || headers != d.PageCount() {
+		t.Fatalf("pages %d, headers drawn %d; want several pages each with a header", d.PageCount(), headers)
+	}
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatal("missing PDF header or trailer")
+	}
+	// Every xref entry must point at its object.
+	m := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(out)
+	start, _ := strconv.Atoi(string(m[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[start:], -1)
+	if len(entries) != 5+2*d.PageCount() {
+		t.Fatalf("xref has %d objects, want %d", len(entries), 5+2*d.PageCount())
+	}
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if want := fmt.Sprintf("%d 0 obj", i+1); !bytes.HasPrefix(out[off:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, out[off:off+10])
+		}
+	}
+	// Text is WinAnsi-encoded with PDF string escapes.
+	first := inflate(t, out)
+	if !strings.Contains(first, "(Sita Sharma \x96 pharmacist)") || !strings.Contains(first, "Page 1 of ") {
+		t.Errorf("first page content missing expected text")
+	}
+}
+
+func inflate(t *testing.T, out []byte) string {
+	t.Helper()
+	i := bytes.Index(out, []byte("stream\n"))
+	r, err := zlib.NewReader(bytes.NewReader(out[i+len("stream\n"):]))
+	if err != nil {
+		t.Fatalf("zlib: %v", err)
+	}
+	b, _ := io.ReadAll(r)
+	return string(b)
+}
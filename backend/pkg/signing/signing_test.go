@@ -0,0 +1,28 @@
+package signing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret := []byte("test-secret")
+	now := time.Now()
+	token := Sign(secret, "po:123", now.Add(time.Hour))
+
+	if err := Verify(secret, "po:123", token, now); err != nil {
+		t.Fatalf("expected valid token, got %v", err)
+	}
+	if err := Verify(secret, "po:456", token, now); err != ErrInvalidToken {
+		t.Errorf("expected ErrInvalidToken for other payload, got %v", err)
+	}
+	if err := Verify([]byte("other"), "po:123", token, now); err != ErrInvalidToken {
+		t.Errorf("expected ErrInvalidToken for other secret, got %v", err)
+	}
+	if err := Verify(secret, "po:123", token, now.Add(2*time.Hour)); err != ErrExpiredToken {
+		t.Errorf("expected ErrExpiredToken, got %v", err)
+	}
+	if err := Verify(secret, "po:123", "garbage", now); err != ErrInvalidToken {
+		t.Errorf("expected ErrInvalidToken for malformed token, got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+// Package tracing opens OpenTelemetry spans for service methods. Spans go to the globally registered tracer
+// provider; until one is installed (TRACING_EXPORTER=none) they are no-ops, so services can call it unconditionally.
+package tracing
+
+import (
+	"context"
+
+	"github.com/careplus/pharmacy-backend/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentation names the tracer, so backends can tell these spans from the HTTP and GORM ones.
+const instrumentation = "github.com/careplus/pharmacy-backend"
+
+// Start opens a span named name (Service.Method) as a child of the span in ctx and returns the context carrying
+// it. Close it with End.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentation).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends the span, recording *errp when set. Application errors such as validation or not found are normal
+// outcomes: they are tagged with their code but do not mark the span as failed; internal and unknown errors do.
+func End(span trace.Span, errp *error) {
+	defer span.End()
+	if errp == nil || *errp == nil {
+		return
+	}
+	err := *errp
+	if appErr := errors.GetAppError(err); appErr != nil && appErr.Code != errors.ErrCodeInternal {
+		span.SetAttributes(attribute.String("error.code", appErr.Code))
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -884,11 +884,17 @@ export const usersApi = {
   deactivate: (id: string) => api<User>(`/users/${id}/deactivate`, { method: 'PATCH' }),
 };
 
-/** Upload a file (staff only). Returns { url, path, filename }. Use for CV, photo, etc. */
-export function uploadFile(file: File): Promise<{ url: string; path: string; filename: string }> {
+/** Upload a file (staff only). Returns { url, path, filename }. Use for CV, photo, etc.
+ * Private purposes (cv, prescription, return_video) return a "private:" reference as url, to save on the record,
+ * and a short-lived preview_url to show. */
+export function uploadFile(
+  file: File,
+  purpose?: 'file' | 'cv' | 'prescription' | 'chat_video' | 'return_video'
+): Promise<{ url: string; path: string; filename: string; preview_url?: string }> {
   const form = new FormData();
   form.append('file', file);
-  return apiUpload<{ url: string; path: string; filename: string }>('/upload', form);
+  if (purpose) form.append('purpose', purpose);
+  return apiUpload<{ url: string; path: string; filename: string; preview_url?: string }>('/upload', form);
 }
 
 export type ShiftType = 'morning' | 'evening' | 'full';
@@ -148,11 +148,11 @@ export default function OrderDetailPage() {
       let videoUrl = '';
       const photoUrls: string[] = [];
       if (returnVideoFile) {
-        const res = await uploadFile(returnVideoFile);
+        const res = await uploadFile(returnVideoFile, 'return_video');
         videoUrl = res.url;
       }
       for (const f of returnPhotoFiles) {
-        const res = await uploadFile(f);
+        const res = await uploadFile(f, 'return_video');
         photoUrls.push(res.url);
       }
       const created = await orderApi.createReturnRequest(id, {
@@ -506,7 +506,8 @@ export default function ProductsPage() {
     setImageError('');
     try {
       if (isEdit) {
-        await productApi.update(editingProduct.id, fullProductPayload());
+        // Stock changes go through batches or the stock adjustment, not the product update.
+        await productApi.update(editingProduct.id, { ...fullProductPayload(), stock_quantity: undefined });
         for (let i = 0; i < pendingFiles.length; i++) {
           const isPrimary = uploadedImages.length === 0 && i === 0;
           await productApi.addImage(editingProduct.id, pendingFiles[i], isPrimary);
@@ -1418,10 +1419,13 @@ export default function ProductsPage() {
                           onChange={(e) => { handleChange(e); setProductFieldErrors((p) => ({ ...p, stock_quantity: '', batch_number: '' })); }}
                           className={`w-full px-3 py-2 border rounded-lg focus:ring-2 focus:ring-careplus-primary focus:border-careplus-primary ${productFieldErrors.stock_quantity ? 'border-red-500' : 'border-gray-300'}`}
                           placeholder="0"
+                          disabled={!!editingProduct}
                           aria-invalid={!!productFieldErrors.stock_quantity}
                         />
                         {productFieldErrors.stock_quantity && <p className="mt-1 text-sm text-red-600">{productFieldErrors.stock_quantity}</p>}
-                        <p className="text-xs text-gray-500 mt-0.5">Used as initial batch quantity when batch number is set.</p>
+                        <p className="text-xs text-gray-500 mt-0.5">
+                          {editingProduct ? 'Adjust stock from the stock or inventory batch actions.' : 'Used as initial batch quantity when batch number is set.'}
+                        </p>
                       </div>
                       <div>
                         <label htmlFor="unit" className="block text-sm font-medium text-gray-700 mb-1">
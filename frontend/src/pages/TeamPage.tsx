@@ -143,7 +143,7 @@ export default function TeamPage() {
       let photoUrl = form.photo_url;
       if (form.role === 'pharmacist') {
         if (cvFile) {
-          const res = await uploadFile(cvFile);
+          const res = await uploadFile(cvFile, 'cv');
           cvUrl = res.url;
         }
         if (photoFile) {
@@ -418,7 +418,7 @@ export default function TeamPage() {
                               if (!file) return;
                               setUploadingFile('cv');
                               try {
-                                const res = await uploadFile(file);
+                                const res = await uploadFile(file, 'cv');
                                 setEditForm((p) => ({ ...p, cv_url: res.url }));
                               } finally {
                                 setUploadingFile(null);